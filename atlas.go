@@ -0,0 +1,82 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
+)
+
+// AtlasOptions represents options for the internal texture atlases.
+//
+// Ebitengine puts multiple images on one big texture called an atlas to reduce draw calls.
+// The default values work well for most games. AtlasOptions is for games with unusual workloads,
+// e.g. games with huge images or games creating and disposing images frequently.
+type AtlasOptions struct {
+	// MinSourcePageSize is the initial width and height of an atlas for images used as rendering sources.
+	// An atlas is extended when it doesn't have enough space.
+	// The value is rounded down to a power of 2.
+	//
+	// The default (zero) value is 1024.
+	MinSourcePageSize int
+
+	// MinDestinationPageSize is the initial width and height of an atlas for images used as rendering destinations.
+	// The value is rounded down to a power of 2.
+	//
+	// The default (zero) value is 16.
+	MinDestinationPageSize int
+
+	// MaxPageSize is the maximum width and height of an atlas.
+	// An image bigger than this size is not put on an atlas and has its own texture.
+	// The value is rounded down to a power of 2, and is capped by the maximum texture size of the environment.
+	//
+	// The default (zero) value means the maximum texture size of the environment.
+	MaxPageSize int
+
+	// PaddingSize is the padding size in pixels around an image on an atlas.
+	// A padding prevents the adjacent images from bleeding with linear filtering.
+	//
+	// If PaddingSize is negative, no padding is used.
+	//
+	// The default (zero) value means 1.
+	PaddingSize int
+
+	// FramesToPutOnAtlas is the number of frames when an image that is used only as a rendering source is put onto
+	// an atlas for rendering sources.
+	// The number of frames is doubled every time the image is used as a rendering destination.
+	//
+	// The default (zero) value is 10.
+	FramesToPutOnAtlas int
+}
+
+func (a *AtlasOptions) internalPolicy() atlas.Policy {
+	return atlas.Policy{
+		MinSourceSize:                 a.MinSourcePageSize,
+		MinDestinationSize:            a.MinDestinationPageSize,
+		MaxPageSize:                   a.MaxPageSize,
+		PaddingSize:                   a.PaddingSize,
+		BaseCountToPutOnSourceBackend: a.FramesToPutOnAtlas,
+	}
+}
+
+// CompactAtlas requests to compact the internal texture atlases.
+//
+// After CompactAtlas is called, images are moved from the current atlases into new atlases when the images are used.
+// An old atlas is released when all the images on it are moved or disposed.
+// This is useful to reduce fragmentation after many images are created and disposed.
+//
+// CompactAtlas is concurrent-safe.
+func CompactAtlas() {
+	atlas.Compact()
+}
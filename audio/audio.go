@@ -66,6 +66,8 @@ type Context struct {
 
 	playingPlayers map[*playerImpl]struct{}
 
+	listener Listener
+
 	m         sync.Mutex
 	semaphore chan struct{}
 }
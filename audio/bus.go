@@ -0,0 +1,429 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+)
+
+// busOutputDisabledForTesting indicates whether a top-level bus doesn't create an underlying player for testing.
+var busOutputDisabledForTesting bool
+
+// Bus is a group of players and child buses, which are mixed and then processed together.
+//
+// A bus is useful to control a category of sounds like sound effects, music, and voices at once.
+// For example, ducking, which lowers the music while a voice is playing, can be implemented by changing the volume of a bus for music.
+type Bus struct {
+	parent  *Bus
+	volume  float64
+	muted   bool
+	effects effectChain
+
+	// sources are the players and the child buses mixed into this bus.
+	sources []busSource
+
+	// gain is the gain applied at the end of the last mixing.
+	gain   float64
+	inited bool
+
+	samples []float32
+
+	// output is the underlying player to play a top-level bus.
+	output player
+
+	m sync.Mutex
+}
+
+// busSource is a source of a bus.
+type busSource interface {
+	// mix adds the samples to dst.
+	// dst is 32bit float values of 2 channels (stereo), which are interleaved.
+	mix(dst []float32)
+}
+
+// NewBus creates a new bus.
+//
+// If parent is not nil, the bus is mixed into parent. Otherwise, the bus is played directly.
+func NewBus(parent *Bus) *Bus {
+	b := &Bus{
+		parent: parent,
+		volume: 1,
+	}
+	if parent != nil {
+		parent.addSource(b)
+	}
+	return b
+}
+
+// Parent returns the parent bus. If the bus is a top-level bus, Parent returns nil.
+func (b *Bus) Parent() *Bus {
+	return b.parent
+}
+
+// Volume returns the current volume of the bus [0-1].
+//
+// Volume is concurrent-safe.
+func (b *Bus) Volume() float64 {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.volume
+}
+
+// SetVolume sets the volume of the bus.
+// The volume is multiplied by the volumes of the players and the ancestor buses.
+//
+// volume must be in between 0 and 1. SetVolume panics otherwise.
+//
+// The volume is changed smoothly to avoid clicks.
+//
+// SetVolume is concurrent-safe.
+func (b *Bus) SetVolume(volume float64) {
+	if volume < 0 || 1 < volume {
+		panic("audio: volume must be in between 0 and 1")
+	}
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.volume = volume
+}
+
+// IsMuted reports whether the bus is muted.
+//
+// IsMuted is concurrent-safe.
+func (b *Bus) IsMuted() bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.muted
+}
+
+// SetMuted sets whether the bus is muted.
+// Even while the bus is muted, the players in the bus keep playing.
+//
+// SetMuted is concurrent-safe.
+func (b *Bus) SetMuted(muted bool) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.muted = muted
+}
+
+// SetEffects sets the effects applied to the mixed sound of the bus in order.
+// The effects are applied before the volume of the bus.
+// If effects is empty, no effects are applied. No effects are applied by default.
+//
+// An Effect object must not be shared by multiple players and buses, as an Effect has an internal state.
+//
+// SetEffects is concurrent-safe. The effects can be changed while the bus is playing.
+func (b *Bus) SetEffects(effects []Effect) {
+	b.effects.set(effects)
+}
+
+// SetBus attaches the player to the bus.
+// If bus is nil, the player is detached from the current bus and is played directly.
+// A player is not attached to any bus by default.
+//
+// If the player is already playing, a part of the buffered sound might be skipped.
+//
+// SetBus is concurrent-safe.
+func (p *Player) SetBus(bus *Bus) {
+	p.p.setBus(bus)
+}
+
+// Bus returns the bus the player is attached to. If the player is not attached to any bus, Bus returns nil.
+func (p *Player) Bus() *Bus {
+	return p.p.currentBus()
+}
+
+func (b *Bus) addSource(source busSource) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.sources = append(b.sources, source)
+}
+
+func (b *Bus) removeSource(source busSource) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	for i, s := range b.sources {
+		if s == source {
+			b.sources = append(b.sources[:i], b.sources[i+1:]...)
+			return
+		}
+	}
+}
+
+// ensureOutput creates and plays the underlying player for the top-level bus if needed.
+func (b *Bus) ensureOutput(factory *playerFactory) {
+	if b.parent != nil {
+		b.parent.ensureOutput(factory)
+		return
+	}
+
+	if busOutputDisabledForTesting {
+		return
+	}
+
+	b.m.Lock()
+	if b.output != nil {
+		b.m.Unlock()
+		return
+	}
+	output := factory.context.NewPlayer(&busStream{bus: b})
+	b.output = output
+	b.m.Unlock()
+
+	// Play reads the stream, which locks the mutex.
+	output.Play()
+}
+
+// bufferedSize returns the buffered size in bytes of the underlying player of the top-level bus.
+func (b *Bus) bufferedSize() int {
+	if b.parent != nil {
+		return b.parent.bufferedSize()
+	}
+
+	b.m.Lock()
+	output := b.output
+	b.m.Unlock()
+
+	if output == nil {
+		return 0
+	}
+	return output.BufferedSize()
+}
+
+// newPlayer creates a player to play src in the bus.
+func (b *Bus) newPlayer(factory *playerFactory, src io.Reader) player {
+	p := &busPlayer{
+		bus:    b,
+		src:    src,
+		volume: 1,
+	}
+	b.addSource(p)
+	b.ensureOutput(factory)
+	return p
+}
+
+func (b *Bus) mix(dst []float32) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if cap(b.samples) < len(dst) {
+		b.samples = make([]float32, len(dst))
+	}
+	samples := b.samples[:len(dst)]
+	for i := range samples {
+		samples[i] = 0
+	}
+	for _, s := range b.sources {
+		s.mix(samples)
+	}
+	b.effects.process(samples)
+
+	gain := b.volume
+	if b.muted {
+		gain = 0
+	}
+	if !b.inited {
+		b.gain = gain
+		b.inited = true
+	}
+	addWithGain(dst, samples, b.gain, gain)
+	b.gain = gain
+}
+
+// addWithGain adds src multiplied by the gain to dst.
+// The gain is interpolated from prevGain to gain to avoid clicks.
+func addWithGain(dst, src []float32, prevGain, gain float64) {
+	frames := len(src) / channelCount
+	for i := 0; i < frames; i++ {
+		t := float64(i+1) / float64(frames)
+		g := float32(prevGain + (gain-prevGain)*t)
+		dst[2*i] += src[2*i] * g
+		dst[2*i+1] += src[2*i+1] * g
+	}
+}
+
+// busStream is a stream of the mixed sound of a top-level bus in 32bit float, little endian, 2 channels (stereo) format.
+// busStream never ends.
+type busStream struct {
+	bus     *Bus
+	samples []float32
+}
+
+// Read is implementation of io.Reader's Read.
+func (s *busStream) Read(buf []byte) (int, error) {
+	const bytesPerFrame = bitDepthInBytesFloat32 * channelCount
+
+	n := len(buf) / bytesPerFrame * channelCount
+	if cap(s.samples) < n {
+		s.samples = make([]float32, n)
+	}
+	samples := s.samples[:n]
+	for i := range samples {
+		samples[i] = 0
+	}
+	s.bus.mix(samples)
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return n * bitDepthInBytesFloat32, nil
+}
+
+// busPlayer is a player in a bus.
+// busPlayer implements the player interface, and its sound is mixed by the bus instead of the audio driver.
+type busPlayer struct {
+	bus *Bus
+	src io.Reader
+
+	playing bool
+	volume  float64
+	err     error
+
+	// gain is the gain applied at the end of the last mixing.
+	gain   float64
+	inited bool
+
+	buf []byte
+
+	// extra is the remainder in the case when the read byte sizes are not multiple of the frame size.
+	extra []byte
+
+	samples []float32
+
+	m sync.Mutex
+}
+
+func (p *busPlayer) Pause() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.playing = false
+}
+
+func (p *busPlayer) Play() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if p.err != nil {
+		return
+	}
+	p.playing = true
+}
+
+func (p *busPlayer) IsPlaying() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.playing
+}
+
+func (p *busPlayer) Volume() float64 {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.volume
+}
+
+func (p *busPlayer) SetVolume(volume float64) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.volume = volume
+}
+
+func (p *busPlayer) BufferedSize() int {
+	// The sound read from the source is buffered in the underlying player of the top-level bus.
+	return p.bus.bufferedSize()
+}
+
+func (p *busPlayer) Err() error {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.err
+}
+
+func (p *busPlayer) SetBufferSize(bufferSize int) {
+	// The buffer size is determined by the underlying player of the top-level bus.
+}
+
+func (p *busPlayer) Seek(offset int64, whence int) (int64, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.extra = p.extra[:0]
+	return p.src.(io.Seeker).Seek(offset, whence)
+}
+
+func (p *busPlayer) Close() error {
+	p.m.Lock()
+	p.playing = false
+	p.m.Unlock()
+
+	p.bus.removeSource(p)
+	return nil
+}
+
+func (p *busPlayer) mix(dst []float32) {
+	const bytesPerFrame = bitDepthInBytesFloat32 * channelCount
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if !p.playing {
+		return
+	}
+
+	size := len(dst) * bitDepthInBytesFloat32
+	if cap(p.buf) < size {
+		p.buf = make([]byte, size)
+	}
+	buf := p.buf[:size]
+	n := copy(buf, p.extra)
+	p.extra = p.extra[:0]
+	for n < len(buf) {
+		m, err := p.src.Read(buf[n:])
+		n += m
+		if err != nil {
+			if err != io.EOF {
+				p.err = err
+			}
+			p.playing = false
+			break
+		}
+		if m == 0 {
+			break
+		}
+	}
+
+	// Keep the remainder for the next mixing.
+	rem := n % bytesPerFrame
+	p.extra = append(p.extra, buf[n-rem:n]...)
+	n -= rem
+
+	if cap(p.samples) < len(dst) {
+		p.samples = make([]float32, len(dst))
+	}
+	samples := p.samples[:len(dst)]
+	for i := range samples {
+		if i < n/bitDepthInBytesFloat32 {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+			continue
+		}
+		samples[i] = 0
+	}
+
+	if !p.inited {
+		p.gain = p.volume
+		p.inited = true
+	}
+	addWithGain(dst, samples, p.gain, p.volume)
+	p.gain = p.volume
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+type gainEffect struct {
+	gain float32
+}
+
+func (g *gainEffect) Process(samples []float32) {
+	for i := range samples {
+		samples[i] *= g.gain
+	}
+}
+
+func (g *gainEffect) Reset() {
+}
+
+// mixBus mixes the bus and returns the first and the last values of the left channel.
+func mixBus(bus *audio.Bus) (float32, float32) {
+	samples := make([]float32, 2*256)
+	audio.MixBusForTesting(bus, samples)
+	return samples[0], samples[len(samples)-2]
+}
+
+func expectBusOutput(t *testing.T, bus *audio.Bus, want float32) {
+	t.Helper()
+	// Mix twice since a volume change is interpolated over one mixing.
+	mixBus(bus)
+	first, last := mixBus(bus)
+	if math.Abs(float64(first-want)) > 1e-6 || math.Abs(float64(last-want)) > 1e-6 {
+		t.Errorf("got: (%f, %f), want: %f", first, last, want)
+	}
+}
+
+func TestBus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	master := audio.NewBus(nil)
+	music := audio.NewBus(master)
+	sfx := audio.NewBus(master)
+	if music.Parent() != master {
+		t.Errorf("Parent(): got: %p, want: %p", music.Parent(), master)
+	}
+
+	p0, err := context.NewPlayerF32(bytes.NewReader(constantF32(48000, 0.5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p0.SetBus(music)
+	p0.Play()
+	if p0.Bus() != music {
+		t.Errorf("Bus(): got: %p, want: %p", p0.Bus(), music)
+	}
+
+	p1, err := context.NewPlayerF32(bytes.NewReader(constantF32(48000, 0.25)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1.SetBus(sfx)
+	p1.Play()
+
+	expectBusOutput(t, master, 0.75)
+
+	music.SetVolume(0.5)
+	expectBusOutput(t, master, 0.5)
+
+	master.SetVolume(0.5)
+	expectBusOutput(t, master, 0.25)
+	master.SetVolume(1)
+
+	sfx.SetMuted(true)
+	if !sfx.IsMuted() {
+		t.Errorf("IsMuted(): got: false, want: true")
+	}
+	expectBusOutput(t, master, 0.25)
+	sfx.SetMuted(false)
+
+	sfx.SetEffects([]audio.Effect{&gainEffect{gain: 2}})
+	expectBusOutput(t, master, 0.75)
+	sfx.SetEffects(nil)
+
+	p1.SetVolume(0.5)
+	expectBusOutput(t, master, 0.375)
+
+	p1.Pause()
+	expectBusOutput(t, master, 0.25)
+
+	// A detached player is not mixed.
+	p0.SetBus(nil)
+	expectBusOutput(t, master, 0)
+}
+
+func TestBusVolumeInterpolation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	bus := audio.NewBus(nil)
+	p, err := context.NewPlayerF32(bytes.NewReader(constantF32(48000, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetBus(bus)
+	p.Play()
+	mixBus(bus)
+
+	bus.SetVolume(0)
+	first, last := mixBus(bus)
+	if first <= 0.9 || first >= 1 {
+		t.Errorf("first: got: %f, want: in (0.9, 1)", first)
+	}
+	if last != 0 {
+		t.Errorf("last: got: %f, want: 0", last)
+	}
+}
+
+func TestBusPlayerEnd(t *testing.T) {
+	setup()
+	defer teardown()
+
+	bus := audio.NewBus(nil)
+	p, err := context.NewPlayerF32(bytes.NewReader(constantF32(100, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetBus(bus)
+	p.Play()
+
+	samples := make([]float32, 2*256)
+	audio.MixBusForTesting(bus, samples)
+	if got, want := samples[2*99], float32(1); got != want {
+		t.Errorf("samples[2*99]: got: %f, want: %f", got, want)
+	}
+	if got, want := samples[2*100], float32(0); got != want {
+		t.Errorf("samples[2*100]: got: %f, want: %f", got, want)
+	}
+	if p.IsPlaying() {
+		t.Errorf("IsPlaying(): got: true, want: false")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	stdcontext "context"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+)
+
+// captureDriver is a platform-specific driver to record audio input.
+//
+// A captureDriver is created by newCaptureDriver(sampleRate int, write func([]float32), fail func(error)).
+// write is called with interleaved samples of the recorded audio, and fail is called when recording fails.
+type captureDriver interface {
+	Close() error
+}
+
+var newCaptureDriverForTesting func(sampleRate int, write func([]float32), fail func(error)) (captureDriver, error)
+
+// NewCapture starts recording the default audio input device like a microphone, and returns a stream of the recorded audio.
+//
+// sampleRate specifies the sample rate of the stream. The platform converts the sample rate of the device if needed.
+//
+// The stream format is linear PCM (32bit float, little endian, 2 channel stereo), which is the same as NewPlayerF32's.
+// A monaural input is duplicated into the two channels.
+// Read of the stream blocks until recorded data is available.
+//
+// The recording continues until ctx is done. After that, Read returns ctx's error.
+// If the stream is not read for a while, the oldest recorded data is discarded.
+//
+// The user might be asked for a permission to use the device.
+//
+// NewCapture is supported only on Linux and BSD with ALSA, and on browsers with getUserMedia.
+// On the other platforms including Windows, macOS, iOS and Android, NewCapture always returns an error.
+func NewCapture(ctx stdcontext.Context, sampleRate int) (io.Reader, error) {
+	r := newCaptureReader(sampleRate)
+	newDriver := newCaptureDriver
+	if newCaptureDriverForTesting != nil {
+		newDriver = newCaptureDriverForTesting
+	}
+	d, err := newDriver(sampleRate, r.write, r.closeWithError)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		err := d.Close()
+		if err == nil {
+			err = ctx.Err()
+		}
+		r.closeWithError(err)
+	}()
+	return r, nil
+}
+
+// captureReader is a buffer of recorded audio.
+type captureReader struct {
+	buf     []byte
+	maxSize int
+	err     error
+
+	cond *sync.Cond
+	m    sync.Mutex
+}
+
+func newCaptureReader(sampleRate int) *captureReader {
+	r := &captureReader{
+		// Keep recorded data for one second at most.
+		maxSize: sampleRate * channelCount * bitDepthInBytesFloat32,
+	}
+	r.cond = sync.NewCond(&r.m)
+	return r
+}
+
+// write appends recorded samples. samples are interleaved samples of channelCount channels.
+func (r *captureReader) write(samples []float32) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	for _, s := range samples {
+		r.buf = binary.LittleEndian.AppendUint32(r.buf, math.Float32bits(s))
+	}
+	if len(r.buf) > r.maxSize {
+		// Discard the oldest data, keeping the alignment of the frames.
+		n := len(r.buf) - r.maxSize
+		n += (channelCount*bitDepthInBytesFloat32 - n%(channelCount*bitDepthInBytesFloat32)) % (channelCount * bitDepthInBytesFloat32)
+		r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+	}
+	r.cond.Broadcast()
+}
+
+func (r *captureReader) closeWithError(err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.err != nil {
+		return
+	}
+	r.err = err
+	r.cond.Broadcast()
+}
+
+// Read implements io.Reader.
+func (r *captureReader) Read(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	for len(r.buf) == 0 && r.err == nil {
+		r.cond.Wait()
+	}
+	if len(r.buf) == 0 {
+		return 0, r.err
+	}
+	n := copy(buf, r.buf)
+	r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+	return n, nil
+}
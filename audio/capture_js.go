@@ -0,0 +1,136 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"syscall/js"
+)
+
+type jsCaptureDriver struct {
+	context js.Value
+	stream  js.Value
+	source  js.Value
+	node    js.Value
+	closed  bool
+
+	onAudioProcess js.Func
+	onStream       js.Func
+	onError        js.Func
+
+	m sync.Mutex
+}
+
+func newCaptureDriver(sampleRate int, write func([]float32), fail func(error)) (captureDriver, error) {
+	mediaDevices := js.Global().Get("navigator").Get("mediaDevices")
+	if !mediaDevices.Truthy() || !mediaDevices.Get("getUserMedia").Truthy() {
+		return nil, errors.New("audio: getUserMedia is not available")
+	}
+	class := js.Global().Get("AudioContext")
+	if !class.Truthy() {
+		class = js.Global().Get("webkitAudioContext")
+	}
+	if !class.Truthy() {
+		return nil, errors.New("audio: AudioContext is not available")
+	}
+
+	d := &jsCaptureDriver{
+		context: class.New(map[string]any{
+			"sampleRate": sampleRate,
+		}),
+	}
+
+	var bs []byte
+	var samples []float32
+	d.onAudioProcess = js.FuncOf(func(this js.Value, args []js.Value) any {
+		// The input is monaural. Duplicate it into the channels.
+		in := args[0].Get("inputBuffer").Call("getChannelData", 0)
+		n := in.Get("length").Int()
+		if len(bs) < 4*n {
+			bs = make([]byte, 4*n)
+		}
+		js.CopyBytesToGo(bs, js.Global().Get("Uint8Array").New(in.Get("buffer"), in.Get("byteOffset"), in.Get("byteLength")))
+		samples = samples[:0]
+		for i := 0; i < n; i++ {
+			v := math.Float32frombits(binary.LittleEndian.Uint32(bs[4*i:]))
+			for j := 0; j < channelCount; j++ {
+				samples = append(samples, v)
+			}
+		}
+		write(samples)
+		return nil
+	})
+	d.onStream = js.FuncOf(func(this js.Value, args []js.Value) any {
+		d.m.Lock()
+		defer d.m.Unlock()
+
+		d.stream = args[0]
+		if d.closed {
+			d.stopTracks()
+			return nil
+		}
+		// ScriptProcessorNode is deprecated, but AudioWorklet requires a separate script file.
+		d.source = d.context.Call("createMediaStreamSource", d.stream)
+		d.node = d.context.Call("createScriptProcessor", 4096, 1, 1)
+		d.node.Set("onaudioprocess", d.onAudioProcess)
+		d.source.Call("connect", d.node)
+		// A ScriptProcessorNode works only when it is connected to the destination. The output is silent.
+		d.node.Call("connect", d.context.Get("destination"))
+		return nil
+	})
+	d.onError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		fail(fmt.Errorf("audio: getUserMedia failed: %s", args[0].Call("toString").String()))
+		return nil
+	})
+
+	mediaDevices.Call("getUserMedia", map[string]any{
+		"audio": true,
+	}).Call("then", d.onStream).Call("catch", d.onError)
+	return d, nil
+}
+
+func (d *jsCaptureDriver) stopTracks() {
+	tracks := d.stream.Call("getTracks")
+	for i := 0; i < tracks.Length(); i++ {
+		tracks.Index(i).Call("stop")
+	}
+}
+
+func (d *jsCaptureDriver) Close() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	if d.node.Truthy() {
+		d.node.Set("onaudioprocess", nil)
+		d.source.Call("disconnect")
+		d.node.Call("disconnect")
+	}
+	if d.stream.Truthy() {
+		d.stopTracks()
+	}
+	d.context.Call("close")
+	d.onAudioProcess.Release()
+	// onStream and onError might be called later. Don't release them.
+	return nil
+}
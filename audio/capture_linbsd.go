@@ -0,0 +1,127 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build (freebsd || linux || netbsd || openbsd) && !android && !nintendosdk && !playstation5
+
+package audio
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+const (
+	_SND_PCM_STREAM_CAPTURE        = 1
+	_SND_PCM_FORMAT_FLOAT_LE       = 14
+	_SND_PCM_ACCESS_RW_INTERLEAVED = 3
+)
+
+var (
+	alsaOnce sync.Once
+	alsaErr  error
+
+	snd_pcm_open       func(pcm *uintptr, name string, stream int32, mode int32) int32
+	snd_pcm_set_params func(pcm uintptr, format int32, access int32, channels uint32, rate uint32, softResample int32, latency uint32) int32
+	snd_pcm_readi      func(pcm uintptr, buffer unsafe.Pointer, size uint) int
+	snd_pcm_recover    func(pcm uintptr, err int32, silent int32) int32
+	snd_pcm_close      func(pcm uintptr) int32
+	snd_strerror       func(errnum int32) string
+)
+
+func loadALSA() error {
+	alsaOnce.Do(func() {
+		var lib uintptr
+		// TODO: Use multiple %w-s as of Go 1.20.
+		var errors []string
+		for _, name := range []string{"libasound.so.2", "libasound.so"} {
+			l, err := purego.Dlopen(name, purego.RTLD_LAZY|purego.RTLD_GLOBAL)
+			if err == nil {
+				lib = l
+				break
+			}
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+		}
+		if lib == 0 {
+			alsaErr = fmt.Errorf("audio: failed to load libasound.so: %s", strings.Join(errors, ", "))
+			return
+		}
+		purego.RegisterLibFunc(&snd_pcm_open, lib, "snd_pcm_open")
+		purego.RegisterLibFunc(&snd_pcm_set_params, lib, "snd_pcm_set_params")
+		purego.RegisterLibFunc(&snd_pcm_readi, lib, "snd_pcm_readi")
+		purego.RegisterLibFunc(&snd_pcm_recover, lib, "snd_pcm_recover")
+		purego.RegisterLibFunc(&snd_pcm_close, lib, "snd_pcm_close")
+		purego.RegisterLibFunc(&snd_strerror, lib, "snd_strerror")
+	})
+	return alsaErr
+}
+
+type alsaCaptureDriver struct {
+	pcm    uintptr
+	closed atomic.Bool
+	done   chan struct{}
+}
+
+func newCaptureDriver(sampleRate int, write func([]float32), fail func(error)) (captureDriver, error) {
+	if err := loadALSA(); err != nil {
+		return nil, err
+	}
+
+	d := &alsaCaptureDriver{
+		done: make(chan struct{}),
+	}
+	if code := snd_pcm_open(&d.pcm, "default", _SND_PCM_STREAM_CAPTURE, 0); code < 0 {
+		return nil, fmt.Errorf("audio: snd_pcm_open failed: %s", snd_strerror(code))
+	}
+	// Let ALSA convert the channels and the sample rate. The latency is 0.1[s].
+	if code := snd_pcm_set_params(d.pcm, _SND_PCM_FORMAT_FLOAT_LE, _SND_PCM_ACCESS_RW_INTERLEAVED, channelCount, uint32(sampleRate), 1, 100000); code < 0 {
+		snd_pcm_close(d.pcm)
+		return nil, fmt.Errorf("audio: snd_pcm_set_params failed: %s", snd_strerror(code))
+	}
+
+	go d.loop(write, fail)
+	return d, nil
+}
+
+func (d *alsaCaptureDriver) loop(write func([]float32), fail func(error)) {
+	defer close(d.done)
+
+	const frames = 1024
+	buf := make([]float32, frames*channelCount)
+	for !d.closed.Load() {
+		n := snd_pcm_readi(d.pcm, unsafe.Pointer(&buf[0]), frames)
+		if n < 0 {
+			// Recover from an overrun or a suspension.
+			if code := snd_pcm_recover(d.pcm, int32(n), 1); code < 0 {
+				fail(fmt.Errorf("audio: snd_pcm_readi failed: %s", snd_strerror(code)))
+				return
+			}
+			continue
+		}
+		write(buf[:n*channelCount])
+	}
+}
+
+func (d *alsaCaptureDriver) Close() error {
+	d.closed.Store(true)
+	<-d.done
+	if code := snd_pcm_close(d.pcm); code < 0 {
+		return fmt.Errorf("audio: snd_pcm_close failed: %s", snd_strerror(code))
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !((freebsd || linux || netbsd || openbsd) && !android && !nintendosdk && !playstation5) && !js
+
+package audio
+
+import (
+	"errors"
+)
+
+func newCaptureDriver(sampleRate int, write func([]float32), fail func(error)) (captureDriver, error) {
+	return nil, errors.New("audio: capturing audio is supported only with ALSA and on browsers")
+}
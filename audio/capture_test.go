@@ -0,0 +1,84 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	stdcontext "context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+func TestCapture(t *testing.T) {
+	write := audio.SetUpCaptureForTesting()
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	defer cancel()
+
+	r, err := audio.NewCapture(ctx, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float32{0.5, -0.5, 0.25, -0.25}
+	write(want)
+
+	buf := make([]byte, 4*len(want))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range want {
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])); got != w {
+			t.Errorf("sample %d: got: %f, want: %f", i, got, w)
+		}
+	}
+
+	cancel()
+	if _, err := r.Read(buf); !errors.Is(err, stdcontext.Canceled) {
+		t.Errorf("Read after cancel: got: %v, want: %v", err, stdcontext.Canceled)
+	}
+}
+
+func TestCaptureDiscardsOldData(t *testing.T) {
+	write := audio.SetUpCaptureForTesting()
+
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	defer cancel()
+
+	const sampleRate = 100
+	r, err := audio.NewCapture(ctx, sampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write 1.5 seconds of samples. Only the last one second should be kept.
+	samples := make([]float32, 2*sampleRate*3/2)
+	for i := range samples {
+		samples[i] = float32(i / 2)
+	}
+	write(samples)
+
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := math.Float32frombits(binary.LittleEndian.Uint32(buf)), float32(sampleRate/2); got != want {
+		t.Errorf("the first sample: got: %f, want: %f", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"sync"
+)
+
+// Effect is an audio effect like a filter or a reverb.
+//
+// For actual effects, see the package audio/effects.
+type Effect interface {
+	// Process processes the samples in place.
+	// samples are 32bit float values of 2 channels (stereo), which are interleaved.
+	//
+	// Process is called from a goroutine for audio, which is different from the game's goroutine.
+	Process(samples []float32)
+
+	// Reset resets the internal state like the tail of a reverb.
+	// Reset is called when the stream position is changed.
+	Reset()
+}
+
+// SetEffects sets the effects applied to the player's sound in order.
+// If effects is empty, no effects are applied. No effects are applied by default.
+//
+// An Effect object must not be shared by multiple players, as an Effect has an internal state.
+//
+// SetEffects is concurrent-safe. The effects can be changed while the player is playing.
+func (p *Player) SetEffects(effects []Effect) {
+	p.p.effects.set(effects)
+}
+
+// effectChain is a processor applying effects in order.
+type effectChain struct {
+	effects []Effect
+	m       sync.Mutex
+}
+
+func (e *effectChain) set(effects []Effect) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	e.effects = append(e.effects[:0:0], effects...)
+}
+
+func (e *effectChain) process(samples []float32) {
+	e.m.Lock()
+	defer e.m.Unlock()
+	for _, effect := range e.effects {
+		effect.Process(samples)
+	}
+}
+
+func (e *effectChain) reset() {
+	e.m.Lock()
+	defer e.m.Unlock()
+	for _, effect := range e.effects {
+		effect.Reset()
+	}
+}
+
+func (e *effectChain) active() bool {
+	e.m.Lock()
+	defer e.m.Unlock()
+	return len(e.effects) > 0
+}
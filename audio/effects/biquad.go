@@ -0,0 +1,215 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effects
+
+import (
+	"math"
+	"sync"
+)
+
+// FilterType represents a type of BiquadFilter.
+type FilterType int
+
+const (
+	// FilterTypeLowPass passes frequencies lower than the frequency.
+	FilterTypeLowPass FilterType = iota
+
+	// FilterTypeHighPass passes frequencies higher than the frequency.
+	FilterTypeHighPass
+
+	// FilterTypeBandPass passes frequencies around the frequency.
+	FilterTypeBandPass
+
+	// FilterTypeNotch cuts frequencies around the frequency.
+	FilterTypeNotch
+
+	// FilterTypePeaking boosts or cuts frequencies around the frequency by the gain.
+	FilterTypePeaking
+
+	// FilterTypeLowShelf boosts or cuts frequencies lower than the frequency by the gain.
+	FilterTypeLowShelf
+
+	// FilterTypeHighShelf boosts or cuts frequencies higher than the frequency by the gain.
+	FilterTypeHighShelf
+)
+
+// BiquadFilter is a second-order filter.
+// The coefficients are based on Robert Bristow-Johnson's Audio EQ Cookbook.
+//
+// For example, a low-pass filter with a low frequency like 500[Hz] makes a sound like under water.
+type BiquadFilter struct {
+	sampleRate int
+	filterType FilterType
+	frequency  float64
+	q          float64
+	gain       float64
+
+	// b0, b1, b2, a1, and a2 are the coefficients normalized by a0.
+	b0, b1, b2, a1, a2 float64
+
+	// x1, x2, y1, and y2 are the last inputs and outputs for each channel.
+	x1, x2, y1, y2 [channelCount]float64
+
+	m sync.Mutex
+}
+
+// NewBiquadFilter creates a new BiquadFilter.
+//
+// sampleRate is the sample rate of the stream.
+// frequency is the cutoff or the center frequency in Hz.
+// q is the quality factor. 1/sqrt(2) (about 0.707) is a usual value for low-pass and high-pass filters.
+// The gain is 0[dB] initially.
+func NewBiquadFilter(sampleRate int, filterType FilterType, frequency, q float64) *BiquadFilter {
+	f := &BiquadFilter{
+		sampleRate: sampleRate,
+		filterType: filterType,
+		frequency:  frequency,
+		q:          q,
+	}
+	f.updateCoefficients()
+	return f
+}
+
+// SetFrequency sets the cutoff or the center frequency in Hz.
+//
+// SetFrequency is concurrent-safe.
+func (f *BiquadFilter) SetFrequency(frequency float64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.frequency = frequency
+	f.updateCoefficients()
+}
+
+// SetQ sets the quality factor.
+//
+// SetQ is concurrent-safe.
+func (f *BiquadFilter) SetQ(q float64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.q = q
+	f.updateCoefficients()
+}
+
+// SetGain sets the gain in dB.
+// The gain is used only for FilterTypePeaking, FilterTypeLowShelf, and FilterTypeHighShelf.
+//
+// SetGain is concurrent-safe.
+func (f *BiquadFilter) SetGain(gain float64) {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.gain = gain
+	f.updateCoefficients()
+}
+
+func (f *BiquadFilter) updateCoefficients() {
+	// Keep the frequency less than the Nyquist frequency.
+	freq := math.Min(math.Max(f.frequency, 1), float64(f.sampleRate)/2*0.999)
+	w0 := 2 * math.Pi * freq / float64(f.sampleRate)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * math.Max(f.q, 1e-4))
+	a := math.Pow(10, f.gain/40)
+	sqrtA2Alpha := 2 * math.Sqrt(a) * alpha
+
+	var b0, b1, b2, a0, a1, a2 float64
+	switch f.filterType {
+	case FilterTypeLowPass:
+		b0 = (1 - cos) / 2
+		b1 = 1 - cos
+		b2 = (1 - cos) / 2
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+	case FilterTypeHighPass:
+		b0 = (1 + cos) / 2
+		b1 = -(1 + cos)
+		b2 = (1 + cos) / 2
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+	case FilterTypeBandPass:
+		b0 = alpha
+		b1 = 0
+		b2 = -alpha
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+	case FilterTypeNotch:
+		b0 = 1
+		b1 = -2 * cos
+		b2 = 1
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+	case FilterTypePeaking:
+		b0 = 1 + alpha*a
+		b1 = -2 * cos
+		b2 = 1 - alpha*a
+		a0 = 1 + alpha/a
+		a1 = -2 * cos
+		a2 = 1 - alpha/a
+	case FilterTypeLowShelf:
+		b0 = a * ((a + 1) - (a-1)*cos + sqrtA2Alpha)
+		b1 = 2 * a * ((a - 1) - (a+1)*cos)
+		b2 = a * ((a + 1) - (a-1)*cos - sqrtA2Alpha)
+		a0 = (a + 1) + (a-1)*cos + sqrtA2Alpha
+		a1 = -2 * ((a - 1) + (a+1)*cos)
+		a2 = (a + 1) + (a-1)*cos - sqrtA2Alpha
+	case FilterTypeHighShelf:
+		b0 = a * ((a + 1) + (a-1)*cos + sqrtA2Alpha)
+		b1 = -2 * a * ((a - 1) + (a+1)*cos)
+		b2 = a * ((a + 1) + (a-1)*cos - sqrtA2Alpha)
+		a0 = (a + 1) - (a-1)*cos + sqrtA2Alpha
+		a1 = 2 * ((a - 1) - (a+1)*cos)
+		a2 = (a + 1) - (a-1)*cos - sqrtA2Alpha
+	default:
+		// Pass through.
+		b0 = 1
+		a0 = 1
+	}
+
+	f.b0 = b0 / a0
+	f.b1 = b1 / a0
+	f.b2 = b2 / a0
+	f.a1 = a1 / a0
+	f.a2 = a2 / a0
+}
+
+// Process implements audio.Effect.
+func (f *BiquadFilter) Process(samples []float32) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for i := 0; i < len(samples)/channelCount; i++ {
+		for ch := 0; ch < channelCount; ch++ {
+			x := float64(samples[channelCount*i+ch])
+			y := f.b0*x + f.b1*f.x1[ch] + f.b2*f.x2[ch] - f.a1*f.y1[ch] - f.a2*f.y2[ch]
+			f.x2[ch] = f.x1[ch]
+			f.x1[ch] = x
+			f.y2[ch] = f.y1[ch]
+			f.y1[ch] = y
+			samples[channelCount*i+ch] = float32(y)
+		}
+	}
+}
+
+// Reset implements audio.Effect.
+func (f *BiquadFilter) Reset() {
+	f.m.Lock()
+	defer f.m.Unlock()
+	f.x1 = [channelCount]float64{}
+	f.x2 = [channelCount]float64{}
+	f.y1 = [channelCount]float64{}
+	f.y2 = [channelCount]float64{}
+}
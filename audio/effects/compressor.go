@@ -0,0 +1,146 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effects
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Compressor reduces the volume of loud sounds.
+//
+// The levels of the two channels are linked so that the stereo image is kept.
+type Compressor struct {
+	sampleRate int
+	threshold  float64
+	ratio      float64
+	attack     float64
+	release    float64
+	makeup     float64
+
+	// reduction is the current gain reduction in dB.
+	reduction float64
+
+	m sync.Mutex
+}
+
+// NewCompressor creates a new Compressor.
+//
+// sampleRate is the sample rate of the stream.
+// threshold is the level in dB (e.g. -24) above which the volume is reduced.
+// ratio is the rate of the input level change to the output level change above the threshold (e.g. 4), and must be 1 or more.
+// attack and release are the times to respond to increasing and decreasing levels.
+func NewCompressor(sampleRate int, threshold, ratio float64, attack, release time.Duration) *Compressor {
+	c := &Compressor{
+		sampleRate: sampleRate,
+		threshold:  threshold,
+		ratio:      ratio,
+	}
+	c.attack = c.coefficient(attack)
+	c.release = c.coefficient(release)
+	return c
+}
+
+// coefficient returns the smoothing coefficient for the given time.
+func (c *Compressor) coefficient(t time.Duration) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (t.Seconds() * float64(c.sampleRate)))
+}
+
+// SetThreshold sets the level in dB above which the volume is reduced.
+//
+// SetThreshold is concurrent-safe.
+func (c *Compressor) SetThreshold(threshold float64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.threshold = threshold
+}
+
+// SetRatio sets the rate of the input level change to the output level change above the threshold.
+//
+// SetRatio is concurrent-safe.
+func (c *Compressor) SetRatio(ratio float64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.ratio = ratio
+}
+
+// SetAttack sets the time to respond to increasing levels.
+//
+// SetAttack is concurrent-safe.
+func (c *Compressor) SetAttack(attack time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.attack = c.coefficient(attack)
+}
+
+// SetRelease sets the time to respond to decreasing levels.
+//
+// SetRelease is concurrent-safe.
+func (c *Compressor) SetRelease(release time.Duration) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.release = c.coefficient(release)
+}
+
+// SetMakeupGain sets the gain in dB applied after the compression. The default value is 0.
+//
+// SetMakeupGain is concurrent-safe.
+func (c *Compressor) SetMakeupGain(gain float64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.makeup = gain
+}
+
+// Process implements audio.Effect.
+func (c *Compressor) Process(samples []float32) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	ratio := math.Max(c.ratio, 1)
+	for i := 0; i < len(samples)/channelCount; i++ {
+		var level float64
+		for ch := 0; ch < channelCount; ch++ {
+			level = math.Max(level, math.Abs(float64(samples[channelCount*i+ch])))
+		}
+
+		var target float64
+		if level > 0 {
+			if over := 20*math.Log10(level) - c.threshold; over > 0 {
+				target = over * (1 - 1/ratio)
+			}
+		}
+		coeff := c.release
+		if target > c.reduction {
+			coeff = c.attack
+		}
+		c.reduction = target + coeff*(c.reduction-target)
+
+		gain := math.Pow(10, (c.makeup-c.reduction)/20)
+		for ch := 0; ch < channelCount; ch++ {
+			samples[channelCount*i+ch] = float32(float64(samples[channelCount*i+ch]) * gain)
+		}
+	}
+}
+
+// Reset implements audio.Effect.
+func (c *Compressor) Reset() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.reduction = 0
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effects
+
+import (
+	"sync"
+	"time"
+)
+
+// Delay is an echo effect.
+type Delay struct {
+	sampleRate int
+	delay      int
+	feedback   float64
+	mix        float64
+
+	// line is the delay line of interleaved samples.
+	line []float32
+	pos  int
+
+	m sync.Mutex
+}
+
+// NewDelay creates a new Delay.
+//
+// sampleRate is the sample rate of the stream.
+// delay is the time until an echo is heard.
+// feedback is the rate of the next echo's volume to the previous echo's volume, and must be in [0, 1).
+// mix is the rate of the echo in the output, and must be in [0, 1].
+func NewDelay(sampleRate int, delay time.Duration, feedback, mix float64) *Delay {
+	d := &Delay{
+		sampleRate: sampleRate,
+	}
+	d.setDelay(delay)
+	d.feedback = feedback
+	d.mix = mix
+	return d
+}
+
+// SetDelay sets the time until an echo is heard.
+// If the delay gets longer, the echoes in the delay line are cleared.
+//
+// SetDelay is concurrent-safe.
+func (d *Delay) SetDelay(delay time.Duration) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.setDelay(delay)
+}
+
+func (d *Delay) setDelay(delay time.Duration) {
+	n := int(int64(delay) * int64(d.sampleRate) / int64(time.Second))
+	if n < 1 {
+		n = 1
+	}
+	d.delay = n
+	if len(d.line) < n*channelCount {
+		d.line = make([]float32, n*channelCount)
+		d.pos = 0
+	}
+}
+
+// SetFeedback sets the rate of the next echo's volume to the previous echo's volume.
+//
+// SetFeedback is concurrent-safe.
+func (d *Delay) SetFeedback(feedback float64) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.feedback = feedback
+}
+
+// SetMix sets the rate of the echo in the output.
+//
+// SetMix is concurrent-safe.
+func (d *Delay) SetMix(mix float64) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.mix = mix
+}
+
+// Process implements audio.Effect.
+func (d *Delay) Process(samples []float32) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	frames := len(d.line) / channelCount
+	for i := 0; i < len(samples)/channelCount; i++ {
+		// The line is used as a ring buffer, and the delayed frame is d.delay frames before the current position.
+		readPos := (d.pos - d.delay + frames) % frames
+		for ch := 0; ch < channelCount; ch++ {
+			x := float64(samples[channelCount*i+ch])
+			delayed := float64(d.line[channelCount*readPos+ch])
+			d.line[channelCount*d.pos+ch] = float32(x + d.feedback*delayed)
+			samples[channelCount*i+ch] = float32((1-d.mix)*x + d.mix*delayed)
+		}
+		d.pos = (d.pos + 1) % frames
+	}
+}
+
+// Reset implements audio.Effect.
+func (d *Delay) Reset() {
+	d.m.Lock()
+	defer d.m.Unlock()
+	for i := range d.line {
+		d.line[i] = 0
+	}
+	d.pos = 0
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package effects provides audio effects like filters, a reverb, a delay, and a compressor.
+//
+// An effect implements audio.Effect, and processes 32bit float, little endian, 2 channel (stereo) samples.
+// An effect can be applied to a player by (*audio.Player).SetEffects, or to a stream by NewReader.
+// Effects are composable: multiple effects can be applied in order.
+//
+// An effect object has an internal state and must not be shared by multiple streams.
+// The parameters of an effect can be changed while the sound is playing.
+package effects
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const (
+	channelCount           = 2
+	bitDepthInBytesFloat32 = 4
+	bytesPerFrame          = channelCount * bitDepthInBytesFloat32
+)
+
+// NewReader returns a stream that applies the effects to src in order.
+//
+// src is a 32bit float little endian stream, 2 channels (stereo).
+// The returned stream has the same format.
+func NewReader(src io.Reader, effects ...audio.Effect) io.Reader {
+	return &reader{
+		src:     src,
+		effects: effects,
+	}
+}
+
+// NewReadSeeker returns a seekable stream that applies the effects to src in order.
+//
+// src is a 32bit float little endian stream, 2 channels (stereo).
+// The returned stream has the same format.
+//
+// When the stream is seeked, the effects are reset.
+func NewReadSeeker(src io.ReadSeeker, effects ...audio.Effect) io.ReadSeeker {
+	return &readSeeker{
+		reader: reader{
+			src:     src,
+			effects: effects,
+		},
+	}
+}
+
+type reader struct {
+	src     io.Reader
+	effects []audio.Effect
+
+	// extra is the remainder in the case when the read byte sizes are not multiple of the frame size.
+	extra []byte
+
+	samples []float32
+}
+
+// Read is implementation of io.Reader's Read.
+func (r *reader) Read(buf []byte) (int, error) {
+	n := copy(buf, r.extra)
+	r.extra = r.extra[:0]
+	m, err := r.src.Read(buf[n:])
+	n += m
+
+	// Keep the remainder for the next read.
+	rem := n % bytesPerFrame
+	r.extra = append(r.extra, buf[n-rem:n]...)
+	n -= rem
+
+	if cap(r.samples) < n/bitDepthInBytesFloat32 {
+		r.samples = make([]float32, n/bitDepthInBytesFloat32)
+	}
+	samples := r.samples[:n/bitDepthInBytesFloat32]
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	for _, e := range r.effects {
+		e.Process(samples)
+	}
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+
+	return n, err
+}
+
+type readSeeker struct {
+	reader
+}
+
+// Seek is implementation of io.Seeker's Seek.
+func (r *readSeeker) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := r.src.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("effects: the source must be io.Seeker when seeking but not")
+	}
+	r.extra = r.extra[:0]
+	for _, e := range r.effects {
+		e.Reset()
+	}
+	return seeker.Seek(offset, whence)
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effects_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/effects"
+)
+
+const sampleRate = 48000
+
+// sine returns stereo samples of a sine wave.
+func sine(frequency float64, frames int) []float32 {
+	samples := make([]float32, 2*frames)
+	for i := 0; i < frames; i++ {
+		v := float32(math.Sin(2 * math.Pi * frequency * float64(i) / sampleRate))
+		samples[2*i] = v
+		samples[2*i+1] = v
+	}
+	return samples
+}
+
+// peak returns the maximum absolute value of the samples after the given frame.
+func peak(samples []float32, from int) float64 {
+	var p float64
+	for _, v := range samples[2*from:] {
+		p = math.Max(p, math.Abs(float64(v)))
+	}
+	return p
+}
+
+func TestBiquadFilterLowPass(t *testing.T) {
+	low := sine(100, sampleRate/10)
+	f := effects.NewBiquadFilter(sampleRate, effects.FilterTypeLowPass, 1000, 1/math.Sqrt2)
+	f.Process(low)
+	if got := peak(low, sampleRate/20); math.Abs(got-1) > 0.05 {
+		t.Errorf("peak of a low frequency: got: %f, want: 1", got)
+	}
+
+	high := sine(10000, sampleRate/10)
+	f = effects.NewBiquadFilter(sampleRate, effects.FilterTypeLowPass, 1000, 1/math.Sqrt2)
+	f.Process(high)
+	if got := peak(high, sampleRate/20); got > 0.05 {
+		t.Errorf("peak of a high frequency: got: %f, want: < 0.05", got)
+	}
+}
+
+func TestBiquadFilterPeaking(t *testing.T) {
+	samples := sine(1000, sampleRate/10)
+	f := effects.NewBiquadFilter(sampleRate, effects.FilterTypePeaking, 1000, 1)
+	f.SetGain(6)
+	f.Process(samples)
+	if got, want := peak(samples, sampleRate/20), math.Pow(10, 6.0/20); math.Abs(got-want) > 0.05 {
+		t.Errorf("peak: got: %f, want: %f", got, want)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	// An impulse.
+	samples := make([]float32, 2*1000)
+	samples[0] = 1
+	samples[1] = 1
+
+	d := effects.NewDelay(1000, 100*time.Millisecond, 0.5, 0.5)
+	d.Process(samples)
+
+	for i := 0; i < 1000; i++ {
+		// The echoes are attenuated by the feedback.
+		var want float32
+		switch {
+		case i == 0:
+			want = 0.5
+		case i%100 == 0:
+			want = 0.5 * float32(math.Pow(0.5, float64(i/100-1)))
+		}
+		if got := samples[2*i]; math.Abs(float64(got-want)) > 1e-6 {
+			t.Errorf("frame %d: got: %f, want: %f", i, got, want)
+		}
+	}
+}
+
+func TestCompressor(t *testing.T) {
+	samples := sine(100, sampleRate/2)
+	c := effects.NewCompressor(sampleRate, -12, 4, time.Millisecond, 100*time.Millisecond)
+	c.Process(samples)
+
+	// The input level is 0[dB], which is 12[dB] over the threshold. The output level should be -12+12/4 = -9[dB].
+	if got, want := peak(samples, sampleRate/4), math.Pow(10, -9.0/20); math.Abs(got-want) > 0.03 {
+		t.Errorf("peak: got: %f, want: %f", got, want)
+	}
+
+	// A quiet sound is not compressed.
+	quiet := sine(100, sampleRate/2)
+	for i := range quiet {
+		quiet[i] *= 0.1
+	}
+	c = effects.NewCompressor(sampleRate, -12, 4, time.Millisecond, 100*time.Millisecond)
+	c.Process(quiet)
+	if got, want := peak(quiet, 0), 0.1; math.Abs(got-want) > 1e-3 {
+		t.Errorf("peak: got: %f, want: %f", got, want)
+	}
+}
+
+func TestReverb(t *testing.T) {
+	samples := make([]float32, 2*sampleRate)
+	copy(samples, sine(440, sampleRate/10))
+
+	r := effects.NewReverb(sampleRate, 0.8, 0.5, 0.5)
+	r.Process(samples)
+
+	// The reverberation continues after the sound ends.
+	if got := peak(samples, sampleRate/5); got == 0 {
+		t.Errorf("peak after the sound: got: 0, want: non-zero")
+	}
+
+	r.Reset()
+	silence := make([]float32, 2*100)
+	r.Process(silence)
+	if got := peak(silence, 0); got != 0 {
+		t.Errorf("peak after Reset: got: %f, want: 0", got)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	const frames = 1000
+	samples := make([]float32, 2*frames)
+	samples[0] = 1
+	samples[1] = 1
+	src := make([]byte, 4*len(samples))
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(src[4*i:], math.Float32bits(v))
+	}
+
+	d0 := effects.NewDelay(1000, 100*time.Millisecond, 0, 1)
+	d1 := effects.NewDelay(1000, 200*time.Millisecond, 0, 1)
+	r := effects.NewReadSeeker(bytes.NewReader(src), d0, d1)
+
+	// Read with an odd size to test the remainders.
+	var out []byte
+	buf := make([]byte, 13)
+	for {
+		n, err := r.Read(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := len(out), len(src); got != want {
+		t.Fatalf("len(out): got: %d, want: %d", got, want)
+	}
+	for i := 0; i < frames; i++ {
+		var want float32
+		if i == 300 {
+			want = 1
+		}
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(out[8*i:])); got != want {
+			t.Errorf("frame %d: got: %f, want: %f", i, got, want)
+		}
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := effects.NewReader(bytes.NewReader(src)).(io.Seeker); ok {
+		t.Errorf("NewReader must not return an io.Seeker")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package effects
+
+import (
+	"sync"
+)
+
+// The tunings of Freeverb for 44100[Hz].
+var (
+	reverbCombLengths    = [...]int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllpassLengths = [...]int{556, 441, 341, 225}
+)
+
+const (
+	// reverbStereoSpread is the difference of the lengths between the left and the right channels.
+	reverbStereoSpread = 23
+
+	reverbInputGain = 0.015
+	reverbWetScale  = 3
+)
+
+type reverbComb struct {
+	buf   []float64
+	pos   int
+	store float64
+}
+
+func (c *reverbComb) process(x, feedback, damp float64) float64 {
+	y := c.buf[c.pos]
+	// A low-pass filter in the feedback loop damps high frequencies.
+	c.store = y*(1-damp) + c.store*damp
+	c.buf[c.pos] = x + c.store*feedback
+	c.pos = (c.pos + 1) % len(c.buf)
+	return y
+}
+
+type reverbAllpass struct {
+	buf []float64
+	pos int
+}
+
+func (a *reverbAllpass) process(x float64) float64 {
+	b := a.buf[a.pos]
+	a.buf[a.pos] = x + b*0.5
+	a.pos = (a.pos + 1) % len(a.buf)
+	return b - x
+}
+
+// Reverb is a reverberation effect based on Freeverb, which simulates the reflections in a room.
+type Reverb struct {
+	roomSize float64
+	damping  float64
+	mix      float64
+
+	combs     [channelCount][len(reverbCombLengths)]reverbComb
+	allpasses [channelCount][len(reverbAllpassLengths)]reverbAllpass
+
+	m sync.Mutex
+}
+
+// NewReverb creates a new Reverb.
+//
+// sampleRate is the sample rate of the stream.
+// roomSize is the size of the room, and must be in [0, 1]. A larger room has a longer reverberation.
+// damping is how much the walls absorb high frequencies, and must be in [0, 1].
+// mix is the rate of the reverberation in the output, and must be in [0, 1].
+func NewReverb(sampleRate int, roomSize, damping, mix float64) *Reverb {
+	r := &Reverb{
+		roomSize: roomSize,
+		damping:  damping,
+		mix:      mix,
+	}
+	scale := func(n int) int {
+		n = n * sampleRate / 44100
+		if n < 1 {
+			n = 1
+		}
+		return n
+	}
+	for ch := 0; ch < channelCount; ch++ {
+		for i, n := range reverbCombLengths {
+			r.combs[ch][i].buf = make([]float64, scale(n+ch*reverbStereoSpread))
+		}
+		for i, n := range reverbAllpassLengths {
+			r.allpasses[ch][i].buf = make([]float64, scale(n+ch*reverbStereoSpread))
+		}
+	}
+	return r
+}
+
+// SetRoomSize sets the size of the room.
+//
+// SetRoomSize is concurrent-safe.
+func (r *Reverb) SetRoomSize(roomSize float64) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.roomSize = roomSize
+}
+
+// SetDamping sets how much the walls absorb high frequencies.
+//
+// SetDamping is concurrent-safe.
+func (r *Reverb) SetDamping(damping float64) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.damping = damping
+}
+
+// SetMix sets the rate of the reverberation in the output.
+//
+// SetMix is concurrent-safe.
+func (r *Reverb) SetMix(mix float64) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.mix = mix
+}
+
+// Process implements audio.Effect.
+func (r *Reverb) Process(samples []float32) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	feedback := r.roomSize*0.28 + 0.7
+	damp := r.damping * 0.4
+	for i := 0; i < len(samples)/channelCount; i++ {
+		// The input is monaural, and the outputs are different for the channels by the stereo spread.
+		var in float64
+		for ch := 0; ch < channelCount; ch++ {
+			in += float64(samples[channelCount*i+ch])
+		}
+		in *= reverbInputGain
+
+		for ch := 0; ch < channelCount; ch++ {
+			var out float64
+			for j := range r.combs[ch] {
+				out += r.combs[ch][j].process(in, feedback, damp)
+			}
+			for j := range r.allpasses[ch] {
+				out = r.allpasses[ch][j].process(out)
+			}
+			x := float64(samples[channelCount*i+ch])
+			samples[channelCount*i+ch] = float32((1-r.mix)*x + r.mix*reverbWetScale*out)
+		}
+	}
+}
+
+// Reset implements audio.Effect.
+func (r *Reverb) Reset() {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for ch := 0; ch < channelCount; ch++ {
+		for j := range r.combs[ch] {
+			c := &r.combs[ch][j]
+			for k := range c.buf {
+				c.buf[k] = 0
+			}
+			c.pos = 0
+			c.store = 0
+		}
+		for j := range r.allpasses[ch] {
+			a := &r.allpasses[ch][j]
+			for k := range a.buf {
+				a.buf[k] = 0
+			}
+			a.pos = 0
+		}
+	}
+}
@@ -119,6 +119,7 @@ func (p *dummyPlayer) Close() error {
 
 func init() {
 	driverForTesting = &dummyContext{}
+	busOutputDisabledForTesting = true
 }
 
 type dummyHook struct {
@@ -163,3 +164,52 @@ func ResetContextForTesting() {
 func (i *InfiniteLoop) SetNoBlendForTesting(value bool) {
 	i.noBlendForTesting = value
 }
+
+type dummyCaptureDriver struct{}
+
+func (dummyCaptureDriver) Close() error {
+	return nil
+}
+
+// SetUpCaptureForTesting makes NewCapture use a dummy driver, and returns a function to write recorded samples.
+func SetUpCaptureForTesting() func(samples []float32) {
+	var write func([]float32)
+	var m sync.Mutex
+	newCaptureDriverForTesting = func(sampleRate int, w func([]float32), fail func(error)) (captureDriver, error) {
+		m.Lock()
+		write = w
+		m.Unlock()
+		return dummyCaptureDriver{}, nil
+	}
+	return func(samples []float32) {
+		m.Lock()
+		w := write
+		m.Unlock()
+		w(samples)
+	}
+}
+
+func NewPannerReaderForTesting(context *Context, src io.Reader, options *PannerOptions, x, y, z float64) io.Reader {
+	if options == nil {
+		options = &PannerOptions{}
+	}
+	p := newPanner(context, context.sampleRate, options)
+	p.setPosition(x, y, z)
+	return newProcessStream(src, p)
+}
+
+func NewPitchShifterReaderForTesting(src io.Reader, sampleRate int, semitones float64) io.Reader {
+	p := &pitchShifter{
+		sampleRate: sampleRate,
+	}
+	p.setSemitones(semitones)
+	return newProcessStream(src, p)
+}
+
+// MixBusForTesting mixes the sound of the bus into samples.
+func MixBusForTesting(bus *Bus, samples []float32) {
+	for i := range samples {
+		samples[i] = 0
+	}
+	bus.mix(samples)
+}
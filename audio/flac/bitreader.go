@@ -0,0 +1,165 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flac
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+)
+
+// bitReader reads bits from a byte stream in big endian, calculating the CRCs of the read bytes.
+type bitReader struct {
+	r *bufio.Reader
+
+	// x holds the last n bits that are not read yet.
+	x uint64
+	n uint
+
+	crc8  byte
+	crc16 uint16
+}
+
+func newBitReader(r io.Reader) *bitReader {
+	return &bitReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// reset discards the buffered data and starts reading from r.
+func (b *bitReader) reset(r io.Reader) {
+	b.r.Reset(r)
+	b.x = 0
+	b.n = 0
+}
+
+// resetCRC resets the CRCs. resetCRC must be called at a byte boundary.
+func (b *bitReader) resetCRC() {
+	b.crc8 = 0
+	b.crc16 = 0
+}
+
+func (b *bitReader) loadByte() error {
+	c, err := b.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	b.crc8 = crc8Table[b.crc8^c]
+	b.crc16 = b.crc16<<8 ^ crc16Table[byte(b.crc16>>8)^c]
+	b.x = b.x<<8 | uint64(c)
+	b.n += 8
+	return nil
+}
+
+// readBits reads n bits as an unsigned integer. n must be 32 or less.
+func (b *bitReader) readBits(n uint) (uint64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	for b.n < n {
+		if err := b.loadByte(); err != nil {
+			return 0, err
+		}
+	}
+	b.n -= n
+	return (b.x >> b.n) & (1<<n - 1), nil
+}
+
+// readSigned reads n bits as a two's complement signed integer. n must be 32 or less.
+func (b *bitReader) readSigned(n uint) (int32, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := b.readBits(n)
+	if err != nil {
+		return 0, err
+	}
+	return int32(int64(v<<(64-n)) >> (64 - n)), nil
+}
+
+// readUnary reads the number of 0 bits before a 1 bit.
+func (b *bitReader) readUnary() (uint64, error) {
+	var q uint64
+	for {
+		if b.n == 0 {
+			if err := b.loadByte(); err != nil {
+				return 0, err
+			}
+		}
+		x := b.x & (1<<b.n - 1)
+		if x == 0 {
+			q += uint64(b.n)
+			b.n = 0
+			continue
+		}
+		z := uint(bits.LeadingZeros64(x)) - (64 - b.n)
+		q += uint64(z)
+		b.n -= z + 1
+		return q, nil
+	}
+}
+
+// align discards the bits to the next byte boundary.
+func (b *bitReader) align() {
+	b.n -= b.n % 8
+}
+
+// readByte reads one byte. readByte returns io.EOF only when no byte is available at a byte boundary.
+func (b *bitReader) readByte() (byte, error) {
+	if b.n == 0 {
+		c, err := b.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		b.crc8 = crc8Table[b.crc8^c]
+		b.crc16 = b.crc16<<8 ^ crc16Table[byte(b.crc16>>8)^c]
+		return c, nil
+	}
+	v, err := b.readBits(8)
+	if err != nil {
+		return 0, err
+	}
+	return byte(v), nil
+}
+
+var (
+	crc8Table  [256]byte
+	crc16Table [256]uint16
+)
+
+func init() {
+	// The CRC-8 polynomial is x^8 + x^2 + x^1 + x^0, and the CRC-16 polynomial is x^16 + x^15 + x^2 + x^0.
+	for i := 0; i < 256; i++ {
+		c8 := byte(i)
+		c16 := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if c8&0x80 != 0 {
+				c8 = c8<<1 ^ 0x07
+			} else {
+				c8 <<= 1
+			}
+			if c16&0x8000 != 0 {
+				c16 = c16<<1 ^ 0x8005
+			} else {
+				c16 <<= 1
+			}
+		}
+		crc8Table[i] = c8
+		crc16Table[i] = c16
+	}
+}
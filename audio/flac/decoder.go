@@ -0,0 +1,647 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flac
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	metadataTypeStreamInfo = 0
+	metadataTypeSeekTable  = 3
+)
+
+type streamInfo struct {
+	maxBlockSize  int
+	sampleRate    int
+	channelCount  int
+	bitsPerSample int
+
+	// totalSamples is the number of samples per channel. 0 means unknown.
+	totalSamples int64
+}
+
+// seekPoint is a point in the SEEKTABLE metadata.
+type seekPoint struct {
+	// sample is the index of the first sample of the target frame.
+	sample int64
+
+	// offset is the offset in bytes of the target frame from the first frame.
+	offset int64
+}
+
+// decoder decodes FLAC frames.
+type decoder struct {
+	src io.Reader
+	br  *bitReader
+
+	info       streamInfo
+	seekPoints []seekPoint
+
+	// firstFrameOffset is the offset in bytes of the first frame from the head of src.
+	firstFrameOffset int64
+
+	// samples is the decoded samples of the last frame for each channel.
+	samples [][]int32
+
+	// nextSample is the index of the first sample of the next frame.
+	nextSample int64
+}
+
+func newDecoder(src io.Reader) (*decoder, error) {
+	d := &decoder{
+		src: src,
+	}
+
+	// Count the header size to seek to the first frame later.
+	cr := &countingReader{r: src}
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(cr, header); err != nil {
+		return nil, err
+	}
+
+	// Skip an ID3v2 tag if exists.
+	if string(header[:3]) == "ID3" {
+		buf := make([]byte, 6)
+		if _, err := io.ReadFull(cr, buf); err != nil {
+			return nil, err
+		}
+		size := int64(buf[2]&0x7f)<<21 | int64(buf[3]&0x7f)<<14 | int64(buf[4]&0x7f)<<7 | int64(buf[5]&0x7f)
+		if buf[1]&0x10 != 0 {
+			// Footer
+			size += 10
+		}
+		if _, err := io.CopyN(io.Discard, cr, size); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(cr, header); err != nil {
+			return nil, err
+		}
+	}
+	if string(header) != "fLaC" {
+		return nil, fmt.Errorf("flac: invalid header: 'fLaC' not found")
+	}
+
+	var streamInfoFound bool
+	for {
+		var buf [4]byte
+		if _, err := io.ReadFull(cr, buf[:]); err != nil {
+			return nil, err
+		}
+		last := buf[0]&0x80 != 0
+		typ := buf[0] & 0x7f
+		size := int(buf[1])<<16 | int(buf[2])<<8 | int(buf[3])
+
+		switch typ {
+		case metadataTypeStreamInfo:
+			if size < 34 {
+				return nil, fmt.Errorf("flac: invalid STREAMINFO")
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(cr, data); err != nil {
+				return nil, err
+			}
+			d.info = streamInfo{
+				maxBlockSize:  int(binary.BigEndian.Uint16(data[2:4])),
+				sampleRate:    int(data[10])<<12 | int(data[11])<<4 | int(data[12])>>4,
+				channelCount:  int(data[12]>>1&0x7) + 1,
+				bitsPerSample: int(data[12]&0x1)<<4 | int(data[13]>>4) + 1,
+				totalSamples:  int64(data[13]&0xf)<<32 | int64(binary.BigEndian.Uint32(data[14:18])),
+			}
+			streamInfoFound = true
+		case metadataTypeSeekTable:
+			data := make([]byte, size)
+			if _, err := io.ReadFull(cr, data); err != nil {
+				return nil, err
+			}
+			for i := 0; i+18 <= len(data); i += 18 {
+				sample := binary.BigEndian.Uint64(data[i : i+8])
+				// Skip placeholder points.
+				if sample == 0xffffffffffffffff {
+					continue
+				}
+				d.seekPoints = append(d.seekPoints, seekPoint{
+					sample: int64(sample),
+					offset: int64(binary.BigEndian.Uint64(data[i+8 : i+16])),
+				})
+			}
+		default:
+			if _, err := io.CopyN(io.Discard, cr, int64(size)); err != nil {
+				return nil, err
+			}
+		}
+
+		if last {
+			break
+		}
+	}
+
+	if !streamInfoFound {
+		return nil, fmt.Errorf("flac: invalid header: STREAMINFO not found")
+	}
+	if d.info.sampleRate == 0 {
+		return nil, fmt.Errorf("flac: invalid sample rate: 0")
+	}
+	if d.info.channelCount != 1 && d.info.channelCount != 2 {
+		return nil, fmt.Errorf("flac: number of channels must be 1 or 2 but was %d", d.info.channelCount)
+	}
+	if d.info.bitsPerSample < 4 || d.info.bitsPerSample > 24 {
+		return nil, fmt.Errorf("flac: bits per sample must be in [4, 24] but was %d", d.info.bitsPerSample)
+	}
+
+	d.firstFrameOffset = cr.n
+	d.br = newBitReader(src)
+	d.samples = make([][]int32, d.info.channelCount)
+	return d, nil
+}
+
+// seek decodes the frame that includes the sample at the given index and stores the samples in d.samples.
+// seek returns the index of the first sample of the frame.
+//
+// If the given index is out of the stream, d.samples becomes empty.
+//
+// seek is available only when src is an io.Seeker.
+func (d *decoder) seek(sample int64) (int64, error) {
+	seeker, ok := d.src.(io.Seeker)
+	if !ok {
+		panic("flac: d.src must be io.Seeker but not")
+	}
+
+	var p seekPoint
+	for _, sp := range d.seekPoints {
+		if sp.sample > sample {
+			break
+		}
+		p = sp
+	}
+	if _, err := seeker.Seek(d.firstFrameOffset+p.offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	d.br.reset(d.src)
+	d.nextSample = p.sample
+
+	// As a frame header doesn't have the frame size, decode the frames one by one to find the target frame.
+	for {
+		pos := d.nextSample
+		if _, err := d.decodeFrame(); err != nil {
+			if err == io.EOF {
+				for ch := range d.samples {
+					d.samples[ch] = d.samples[ch][:0]
+				}
+				return pos, nil
+			}
+			return 0, err
+		}
+		if d.nextSample > sample {
+			return pos, nil
+		}
+	}
+}
+
+// decodeFrame decodes the next frame and stores the samples in d.samples.
+// decodeFrame returns the block size of the frame.
+//
+// decodeFrame returns io.EOF when there are no more frames.
+func (d *decoder) decodeFrame() (int, error) {
+	if d.info.totalSamples > 0 && d.nextSample >= d.info.totalSamples {
+		return 0, io.EOF
+	}
+
+	br := d.br
+	br.align()
+	br.resetCRC()
+
+	// Frame header
+	sync0, err := br.readByte()
+	if err != nil {
+		return 0, err
+	}
+	sync1, err := br.readByte()
+	if err != nil {
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	if sync0 != 0xff || sync1&0xfe != 0xf8 {
+		return 0, fmt.Errorf("flac: invalid frame sync code")
+	}
+
+	v, err := br.readBits(16)
+	if err != nil {
+		return 0, err
+	}
+	blockSizeCode := v >> 12
+	sampleRateCode := v >> 8 & 0xf
+	channelAssignment := v >> 4 & 0xf
+	sampleSizeCode := v >> 1 & 0x7
+
+	// Skip the coded frame or sample number.
+	c, err := br.readByte()
+	if err != nil {
+		return 0, err
+	}
+	for c&0xc0 == 0xc0 {
+		if _, err := br.readByte(); err != nil {
+			return 0, err
+		}
+		c <<= 1
+	}
+
+	var blockSize int
+	switch {
+	case blockSizeCode == 0:
+		return 0, fmt.Errorf("flac: invalid block size")
+	case blockSizeCode == 1:
+		blockSize = 192
+	case blockSizeCode <= 5:
+		blockSize = 576 << (blockSizeCode - 2)
+	case blockSizeCode == 6:
+		v, err := br.readBits(8)
+		if err != nil {
+			return 0, err
+		}
+		blockSize = int(v) + 1
+	case blockSizeCode == 7:
+		v, err := br.readBits(16)
+		if err != nil {
+			return 0, err
+		}
+		blockSize = int(v) + 1
+	default:
+		blockSize = 256 << (blockSizeCode - 8)
+	}
+
+	// The sample rate of each frame is ignored as the sample rate in STREAMINFO is used.
+	switch sampleRateCode {
+	case 12:
+		if _, err := br.readBits(8); err != nil {
+			return 0, err
+		}
+	case 13, 14:
+		if _, err := br.readBits(16); err != nil {
+			return 0, err
+		}
+	case 15:
+		return 0, fmt.Errorf("flac: invalid sample rate")
+	}
+
+	bitsPerSample := d.info.bitsPerSample
+	switch sampleSizeCode {
+	case 0:
+	case 1:
+		bitsPerSample = 8
+	case 2:
+		bitsPerSample = 12
+	case 4:
+		bitsPerSample = 16
+	case 5:
+		bitsPerSample = 20
+	case 6:
+		bitsPerSample = 24
+	default:
+		return 0, fmt.Errorf("flac: invalid sample size")
+	}
+	if bitsPerSample != d.info.bitsPerSample {
+		return 0, fmt.Errorf("flac: bits per sample must not be changed in a stream")
+	}
+
+	crc8 := br.crc8
+	v, err = br.readBits(8)
+	if err != nil {
+		return 0, err
+	}
+	if byte(v) != crc8 {
+		return 0, fmt.Errorf("flac: frame header CRC mismatch")
+	}
+
+	channelCount := d.info.channelCount
+	switch {
+	case channelAssignment < 8:
+		if int(channelAssignment)+1 != channelCount {
+			return 0, fmt.Errorf("flac: number of channels must not be changed in a stream")
+		}
+	case channelAssignment <= 10:
+		if channelCount != 2 {
+			return 0, fmt.Errorf("flac: number of channels must not be changed in a stream")
+		}
+	default:
+		return 0, fmt.Errorf("flac: invalid channel assignment")
+	}
+
+	// Subframes
+	for ch := 0; ch < channelCount; ch++ {
+		if cap(d.samples[ch]) < blockSize {
+			d.samples[ch] = make([]int32, blockSize)
+		}
+		d.samples[ch] = d.samples[ch][:blockSize]
+
+		bps := bitsPerSample
+		// The side channel has one more bit.
+		if (channelAssignment == 8 || channelAssignment == 10) && ch == 1 || channelAssignment == 9 && ch == 0 {
+			bps++
+		}
+		if err := d.decodeSubframe(d.samples[ch], bps); err != nil {
+			return 0, err
+		}
+	}
+
+	// Stereo decorrelation
+	switch channelAssignment {
+	case 8:
+		// Left and side
+		l, s := d.samples[0], d.samples[1]
+		for i := range s {
+			s[i] = l[i] - s[i]
+		}
+	case 9:
+		// Side and right
+		s, r := d.samples[0], d.samples[1]
+		for i := range s {
+			s[i] += r[i]
+		}
+	case 10:
+		// Mid and side
+		m, s := d.samples[0], d.samples[1]
+		for i := range m {
+			mid := m[i]<<1 | s[i]&1
+			side := s[i]
+			m[i] = (mid + side) >> 1
+			s[i] = (mid - side) >> 1
+		}
+	}
+
+	// Frame footer
+	br.align()
+	crc16 := br.crc16
+	v, err = br.readBits(16)
+	if err != nil {
+		return 0, err
+	}
+	if uint16(v) != crc16 {
+		return 0, fmt.Errorf("flac: frame CRC mismatch")
+	}
+
+	d.nextSample += int64(blockSize)
+	return blockSize, nil
+}
+
+func (d *decoder) decodeSubframe(samples []int32, bitsPerSample int) error {
+	br := d.br
+
+	v, err := br.readBits(8)
+	if err != nil {
+		return err
+	}
+	if v&0x80 != 0 {
+		return fmt.Errorf("flac: invalid subframe header")
+	}
+	typ := v >> 1 & 0x3f
+
+	var wasted int
+	if v&1 != 0 {
+		n, err := br.readUnary()
+		if err != nil {
+			return err
+		}
+		wasted = int(n) + 1
+		if wasted >= bitsPerSample {
+			return fmt.Errorf("flac: invalid wasted bits")
+		}
+		bitsPerSample -= wasted
+	}
+	bps := uint(bitsPerSample)
+
+	switch {
+	case typ == 0:
+		// CONSTANT
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range samples {
+			samples[i] = v
+		}
+	case typ == 1:
+		// VERBATIM
+		for i := range samples {
+			v, err := br.readSigned(bps)
+			if err != nil {
+				return err
+			}
+			samples[i] = v
+		}
+	case typ >= 8 && typ <= 12:
+		// FIXED
+		order := int(typ - 8)
+		if err := d.decodeFixed(samples, bps, order); err != nil {
+			return err
+		}
+	case typ >= 32:
+		// LPC
+		order := int(typ-32) + 1
+		if err := d.decodeLPC(samples, bps, order); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("flac: invalid subframe type: %d", typ)
+	}
+
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+func (d *decoder) readWarmUp(samples []int32, bitsPerSample uint, order int) error {
+	if order > len(samples) {
+		return fmt.Errorf("flac: predictor order is larger than the block size")
+	}
+	for i := 0; i < order; i++ {
+		v, err := d.br.readSigned(bitsPerSample)
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+	return nil
+}
+
+func (d *decoder) decodeFixed(samples []int32, bitsPerSample uint, order int) error {
+	if err := d.readWarmUp(samples, bitsPerSample, order); err != nil {
+		return err
+	}
+	if err := d.decodeResidual(samples, order); err != nil {
+		return err
+	}
+
+	switch order {
+	case 1:
+		for i := 1; i < len(samples); i++ {
+			samples[i] += samples[i-1]
+		}
+	case 2:
+		for i := 2; i < len(samples); i++ {
+			samples[i] += 2*samples[i-1] - samples[i-2]
+		}
+	case 3:
+		for i := 3; i < len(samples); i++ {
+			samples[i] += 3*samples[i-1] - 3*samples[i-2] + samples[i-3]
+		}
+	case 4:
+		for i := 4; i < len(samples); i++ {
+			samples[i] += 4*samples[i-1] - 6*samples[i-2] + 4*samples[i-3] - samples[i-4]
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeLPC(samples []int32, bitsPerSample uint, order int) error {
+	br := d.br
+
+	if err := d.readWarmUp(samples, bitsPerSample, order); err != nil {
+		return err
+	}
+
+	v, err := br.readBits(4)
+	if err != nil {
+		return err
+	}
+	if v == 0xf {
+		return fmt.Errorf("flac: invalid LPC precision")
+	}
+	precision := uint(v) + 1
+
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("flac: invalid LPC shift: %d", shift)
+	}
+
+	coeffs := make([]int32, order)
+	for i := range coeffs {
+		c, err := br.readSigned(precision)
+		if err != nil {
+			return err
+		}
+		coeffs[i] = c
+	}
+
+	if err := d.decodeResidual(samples, order); err != nil {
+		return err
+	}
+
+	for i := order; i < len(samples); i++ {
+		var sum int64
+		for j, c := range coeffs {
+			sum += int64(c) * int64(samples[i-j-1])
+		}
+		samples[i] += int32(sum >> shift)
+	}
+	return nil
+}
+
+// decodeResidual decodes the residual of a predictor and stores them in samples[order:].
+func (d *decoder) decodeResidual(samples []int32, order int) error {
+	br := d.br
+
+	method, err := br.readBits(2)
+	if err != nil {
+		return err
+	}
+	var paramBits uint
+	switch method {
+	case 0:
+		paramBits = 4
+	case 1:
+		paramBits = 5
+	default:
+		return fmt.Errorf("flac: invalid residual coding method: %d", method)
+	}
+	escape := uint64(1)<<paramBits - 1
+
+	partitionOrder, err := br.readBits(4)
+	if err != nil {
+		return err
+	}
+	partitionCount := 1 << partitionOrder
+	if len(samples)%partitionCount != 0 || len(samples)>>partitionOrder < order {
+		return fmt.Errorf("flac: invalid partition order: %d", partitionOrder)
+	}
+
+	i := order
+	for p := 0; p < partitionCount; p++ {
+		n := len(samples) >> partitionOrder
+		if p == 0 {
+			n -= order
+		}
+
+		param, err := br.readBits(paramBits)
+		if err != nil {
+			return err
+		}
+
+		if param == escape {
+			// The residuals are not Rice-coded.
+			bits, err := br.readBits(5)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < n; j++ {
+				v, err := br.readSigned(uint(bits))
+				if err != nil {
+					return err
+				}
+				samples[i] = v
+				i++
+			}
+			continue
+		}
+
+		k := uint(param)
+		for j := 0; j < n; j++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			r, err := br.readBits(k)
+			if err != nil {
+				return err
+			}
+			u := q<<k | r
+			samples[i] = int32(u>>1) ^ -int32(u&1)
+			i++
+		}
+	}
+	return nil
+}
+
+// countingReader is an io.Reader that counts the read bytes.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,273 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package flac provides FLAC decoder.
+//
+// The decoder is written in pure Go and supports 1 or 2 channels with 4 to 24 bits per sample.
+package flac
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/internal/convert"
+)
+
+const (
+	bitDepthInBytesInt16   = 2
+	bitDepthInBytesFloat32 = 4
+)
+
+// Stream is a decoded stream.
+type Stream struct {
+	readSeeker io.ReadSeeker
+	length     int64
+	sampleRate int
+}
+
+// Read is implementation of io.Reader's Read.
+func (s *Stream) Read(buf []byte) (int, error) {
+	return s.readSeeker.Read(buf)
+}
+
+// Seek is implementation of io.Seeker's Seek.
+//
+// Note that Seek can take long since the frames from the nearest seek point must be decoded.
+func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	return s.readSeeker.Seek(offset, whence)
+}
+
+// Length returns the size of decoded stream in bytes.
+//
+// If the total number of samples is unknown in the FLAC header, Length returns 0.
+func (s *Stream) Length() int64 {
+	return s.length
+}
+
+// SampleRate returns the sample rate of the decoded stream.
+func (s *Stream) SampleRate() int {
+	return s.sampleRate
+}
+
+// DecodeF32 decodes FLAC data to playable stream in 32bit float, little endian, 2 channels (stereo) format.
+//
+// DecodeF32 returns error when decoding fails or IO error happens.
+//
+// The returned Stream's Seek is available only when src is an io.Seeker.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+func DecodeF32(src io.Reader) (*Stream, error) {
+	s, err := decode(src, bitDepthInBytesFloat32)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{
+		readSeeker: s,
+		length:     s.length(),
+		sampleRate: s.decoder.info.sampleRate,
+	}, nil
+}
+
+// DecodeWithoutResampling decodes FLAC data to playable stream in signed 16bit integer, little endian, 2 channels (stereo) format.
+//
+// DecodeWithoutResampling returns error when decoding fails or IO error happens.
+//
+// The returned Stream's Seek is available only when src is an io.Seeker.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+func DecodeWithoutResampling(src io.Reader) (*Stream, error) {
+	s, err := decode(src, bitDepthInBytesInt16)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{
+		readSeeker: s,
+		length:     s.length(),
+		sampleRate: s.decoder.info.sampleRate,
+	}, nil
+}
+
+// DecodeWithSampleRate decodes FLAC data to playable stream in signed 16bit integer, little endian, 2 channels (stereo) format.
+//
+// DecodeWithSampleRate returns error when decoding fails or IO error happens.
+//
+// DecodeWithSampleRate automatically resamples the stream to fit with sampleRate if necessary.
+//
+// The returned Stream's Seek is available only when src is an io.Seeker.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+//
+// Resampling can be a very heavy task. Stream has a cache for resampling, but the size is limited.
+// Do not expect that Stream has a resampling cache even after whole data is played.
+func DecodeWithSampleRate(sampleRate int, src io.Reader) (*Stream, error) {
+	s, err := decode(src, bitDepthInBytesInt16)
+	if err != nil {
+		return nil, err
+	}
+
+	if sampleRate == s.decoder.info.sampleRate {
+		return &Stream{
+			readSeeker: s,
+			length:     s.length(),
+			sampleRate: sampleRate,
+		}, nil
+	}
+
+	r := convert.NewResampling(s, s.length(), s.decoder.info.sampleRate, sampleRate, bitDepthInBytesInt16)
+	return &Stream{
+		readSeeker: r,
+		length:     r.Length(),
+		sampleRate: sampleRate,
+	}, nil
+}
+
+// stream is a decoded stream in 2 channels.
+type stream struct {
+	decoder         *decoder
+	bitDepthInBytes int
+
+	// blockPos is the index of the next sample in the current frame.
+	blockPos int
+
+	posInBytes int64
+
+	// rest is the remaining bytes of a sample that is partially read.
+	rest    []byte
+	restBuf [2 * bitDepthInBytesFloat32]byte
+}
+
+func decode(src io.Reader, bitDepthInBytes int) (*stream, error) {
+	d, err := newDecoder(src)
+	if err != nil {
+		return nil, err
+	}
+	return &stream{
+		decoder:         d,
+		bitDepthInBytes: bitDepthInBytes,
+	}, nil
+}
+
+func (s *stream) bytesPerSample() int {
+	return 2 * s.bitDepthInBytes
+}
+
+func (s *stream) length() int64 {
+	return s.decoder.info.totalSamples * int64(s.bytesPerSample())
+}
+
+// Read is implementation of io.Reader's Read.
+func (s *stream) Read(buf []byte) (int, error) {
+	var n int
+	for len(buf) > 0 {
+		if len(s.rest) > 0 {
+			m := copy(buf, s.rest)
+			s.rest = s.rest[m:]
+			buf = buf[m:]
+			n += m
+			continue
+		}
+
+		block := len(s.decoder.samples[0])
+		if s.blockPos >= block {
+			if _, err := s.decoder.decodeFrame(); err != nil {
+				s.posInBytes += int64(n)
+				if err == io.EOF && n > 0 {
+					return n, nil
+				}
+				return n, err
+			}
+			s.blockPos = 0
+			continue
+		}
+
+		if len(buf) < s.bytesPerSample() {
+			s.encode(s.restBuf[:], s.blockPos)
+			s.blockPos++
+			s.rest = s.restBuf[:s.bytesPerSample()]
+			continue
+		}
+
+		m := len(buf) / s.bytesPerSample()
+		if m > block-s.blockPos {
+			m = block - s.blockPos
+		}
+		for i := 0; i < m; i++ {
+			s.encode(buf[i*s.bytesPerSample():], s.blockPos+i)
+		}
+		s.blockPos += m
+		buf = buf[m*s.bytesPerSample():]
+		n += m * s.bytesPerSample()
+	}
+	s.posInBytes += int64(n)
+	return n, nil
+}
+
+// encode encodes the sample at the given index in the current frame to dst.
+func (s *stream) encode(dst []byte, index int) {
+	samples := s.decoder.samples
+	bitsPerSample := s.decoder.info.bitsPerSample
+	for ch := 0; ch < 2; ch++ {
+		var v int32
+		if len(samples) == 1 {
+			v = samples[0][index]
+		} else {
+			v = samples[ch][index]
+		}
+
+		switch s.bitDepthInBytes {
+		case bitDepthInBytesInt16:
+			if bitsPerSample > 16 {
+				v >>= bitsPerSample - 16
+			} else {
+				v <<= 16 - bitsPerSample
+			}
+			binary.LittleEndian.PutUint16(dst[ch*2:], uint16(int16(v)))
+		case bitDepthInBytesFloat32:
+			f := float32(v) / float32(int32(1)<<(bitsPerSample-1))
+			binary.LittleEndian.PutUint32(dst[ch*4:], math.Float32bits(f))
+		}
+	}
+}
+
+// Seek is implementation of io.Seeker's Seek.
+//
+// If the source is not an io.Seeker, Seek panics.
+func (s *stream) Seek(offset int64, whence int) (int64, error) {
+	next := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		next += s.posInBytes
+	case io.SeekEnd:
+		next += s.length()
+	}
+	if next < 0 {
+		return 0, fmt.Errorf("flac: negative position: %d", next)
+	}
+
+	sample := next / int64(s.bytesPerSample())
+	start, err := s.decoder.seek(sample)
+	if err != nil {
+		return 0, err
+	}
+	s.blockPos = int(sample - start)
+	s.rest = nil
+	s.posInBytes = sample * int64(s.bytesPerSample())
+	return s.posInBytes, nil
+}
@@ -0,0 +1,374 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flac_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/flac"
+)
+
+type bitWriter struct {
+	buf []byte
+	x   uint64
+	n   uint
+}
+
+func (b *bitWriter) writeBits(v uint64, n uint) {
+	for i := int(n) - 1; i >= 0; i-- {
+		b.x = b.x<<1 | (v>>uint(i))&1
+		b.n++
+		if b.n == 8 {
+			b.buf = append(b.buf, byte(b.x))
+			b.x = 0
+			b.n = 0
+		}
+	}
+}
+
+func (b *bitWriter) writeSigned(v int32, n uint) {
+	b.writeBits(uint64(v)&(1<<n-1), n)
+}
+
+func (b *bitWriter) align() {
+	for b.n != 0 {
+		b.writeBits(0, 1)
+	}
+}
+
+func crc8(bs []byte) byte {
+	var c byte
+	for _, b := range bs {
+		c ^= b
+		for i := 0; i < 8; i++ {
+			if c&0x80 != 0 {
+				c = c<<1 ^ 0x07
+			} else {
+				c <<= 1
+			}
+		}
+	}
+	return c
+}
+
+func crc16(bs []byte) uint16 {
+	var c uint16
+	for _, b := range bs {
+		c ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if c&0x8000 != 0 {
+				c = c<<1 ^ 0x8005
+			} else {
+				c <<= 1
+			}
+		}
+	}
+	return c
+}
+
+func writeResidual(w *bitWriter, residual []int32) {
+	const k = 6
+	// Rice coding, partition order 0
+	w.writeBits(0, 2)
+	w.writeBits(0, 4)
+	w.writeBits(k, 4)
+	for _, e := range residual {
+		u := uint64(uint32(e<<1 ^ e>>31))
+		for i := uint64(0); i < u>>k; i++ {
+			w.writeBits(0, 1)
+		}
+		w.writeBits(1, 1)
+		w.writeBits(u, k)
+	}
+}
+
+// writeSubframe writes a subframe. kind is 0 for VERBATIM, 1 for FIXED, and 2 for LPC.
+func writeSubframe(w *bitWriter, samples []int32, bitsPerSample uint, kind int) {
+	constant := true
+	for _, v := range samples {
+		if v != samples[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		w.writeBits(0, 8)
+		w.writeSigned(samples[0], bitsPerSample)
+		return
+	}
+
+	switch kind {
+	case 0:
+		w.writeBits(1<<1, 8)
+		for _, v := range samples {
+			w.writeSigned(v, bitsPerSample)
+		}
+	case 1:
+		// Order 2
+		w.writeBits(10<<1, 8)
+		w.writeSigned(samples[0], bitsPerSample)
+		w.writeSigned(samples[1], bitsPerSample)
+		var residual []int32
+		for i := 2; i < len(samples); i++ {
+			residual = append(residual, samples[i]-(2*samples[i-1]-samples[i-2]))
+		}
+		writeResidual(w, residual)
+	case 2:
+		// Order 2, precision 3, shift 1, coefficients 3 and -1.
+		w.writeBits(33<<1, 8)
+		w.writeSigned(samples[0], bitsPerSample)
+		w.writeSigned(samples[1], bitsPerSample)
+		w.writeBits(2, 4)
+		w.writeSigned(1, 5)
+		w.writeSigned(3, 3)
+		w.writeSigned(-1, 3)
+		var residual []int32
+		for i := 2; i < len(samples); i++ {
+			residual = append(residual, samples[i]-(3*samples[i-1]-samples[i-2])>>1)
+		}
+		writeResidual(w, residual)
+	}
+}
+
+// encode encodes 16bit samples into FLAC. The stereo channel assignment and the subframe type vary by frames.
+func encode(channels [][]int32, sampleRate int, blockSize int, seekTable bool) []byte {
+	total := len(channels[0])
+	var frames []byte
+	var offsets []int
+	for i, start := 0, 0; start < total; i, start = i+1, start+blockSize {
+		end := start + blockSize
+		if end > total {
+			end = total
+		}
+		offsets = append(offsets, len(frames))
+
+		w := &bitWriter{}
+		w.writeBits(0xfff8, 16)
+		assignment := uint64(len(channels) - 1)
+		if len(channels) == 2 {
+			assignment = []uint64{1, 8, 9, 10}[i%4]
+		}
+		// The block size is at the end of the header, 16bit sample size.
+		w.writeBits(7, 4)
+		w.writeBits(0, 4)
+		w.writeBits(assignment, 4)
+		w.writeBits(4, 3)
+		w.writeBits(0, 1)
+		w.writeBits(uint64(i), 8)
+		w.writeBits(uint64(end-start-1), 16)
+		w.writeBits(uint64(crc8(w.buf)), 8)
+
+		subframes := make([][]int32, len(channels))
+		for ch := range channels {
+			subframes[ch] = channels[ch][start:end]
+		}
+		bps := []uint{16, 16}
+		if len(channels) == 2 {
+			l, r := subframes[0], subframes[1]
+			side := make([]int32, len(l))
+			for j := range l {
+				side[j] = l[j] - r[j]
+			}
+			switch assignment {
+			case 8:
+				subframes[1] = side
+				bps[1]++
+			case 9:
+				subframes[0] = side
+				bps[0]++
+			case 10:
+				mid := make([]int32, len(l))
+				for j := range l {
+					mid[j] = (l[j] + r[j]) >> 1
+				}
+				subframes[0] = mid
+				subframes[1] = side
+				bps[1]++
+			}
+		}
+		for ch, s := range subframes {
+			writeSubframe(w, s, bps[ch], (i+ch)%3)
+		}
+		w.align()
+		w.writeBits(uint64(crc16(w.buf)), 16)
+		frames = append(frames, w.buf...)
+	}
+
+	var buf []byte
+	buf = append(buf, "fLaC"...)
+
+	lastFlag := byte(0x80)
+	if seekTable {
+		lastFlag = 0
+	}
+	buf = append(buf, lastFlag|0, 0, 0, 34)
+	info := make([]byte, 34)
+	binary.BigEndian.PutUint16(info[0:], uint16(blockSize))
+	binary.BigEndian.PutUint16(info[2:], uint16(blockSize))
+	binary.BigEndian.PutUint64(info[10:], uint64(sampleRate)<<44|uint64(len(channels)-1)<<41|uint64(16-1)<<36|uint64(total))
+	buf = append(buf, info...)
+
+	if seekTable {
+		size := 18 * (len(offsets) + 1)
+		buf = append(buf, 0x80|3, byte(size>>16), byte(size>>8), byte(size))
+		for i, offset := range offsets {
+			buf = binary.BigEndian.AppendUint64(buf, uint64(i*blockSize))
+			buf = binary.BigEndian.AppendUint64(buf, uint64(offset))
+			buf = binary.BigEndian.AppendUint16(buf, uint16(blockSize))
+		}
+		// Placeholder
+		buf = binary.BigEndian.AppendUint64(buf, math.MaxUint64)
+		buf = append(buf, make([]byte, 10)...)
+	}
+
+	return append(buf, frames...)
+}
+
+func testSamples(n int) [][]int32 {
+	l := make([]int32, n)
+	r := make([]int32, n)
+	for i := range l {
+		// Keep silence at the head to test CONSTANT subframes.
+		if i < 300 {
+			continue
+		}
+		l[i] = int32(10000 * math.Sin(2*math.Pi*float64(i)/100))
+		r[i] = int32(-8000 * math.Sin(2*math.Pi*float64(i)/37))
+	}
+	return [][]int32{l, r}
+}
+
+func toI16Bytes(channels [][]int32) []byte {
+	var buf []byte
+	for i := range channels[0] {
+		for ch := 0; ch < 2; ch++ {
+			v := channels[0][i]
+			if len(channels) == 2 {
+				v = channels[ch][i]
+			}
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(int16(v)))
+		}
+	}
+	return buf
+}
+
+func TestDecodeStereo(t *testing.T) {
+	samples := testSamples(5000)
+	s, err := flac.DecodeWithoutResampling(bytes.NewReader(encode(samples, 44100, 256, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.SampleRate(), 44100; got != want {
+		t.Errorf("s.SampleRate(): got: %d, want: %d", got, want)
+	}
+	if got, want := s.Length(), int64(5000*2*2); got != want {
+		t.Errorf("s.Length(): got: %d, want: %d", got, want)
+	}
+
+	// Read with an odd size buffer to test partial reads.
+	var got []byte
+	buf := make([]byte, 333)
+	for {
+		n, err := s.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := toI16Bytes(samples); !bytes.Equal(got, want) {
+		t.Errorf("decoded samples mismatch")
+	}
+}
+
+func TestDecodeF32Mono(t *testing.T) {
+	samples := testSamples(1000)[:1]
+	s, err := flac.DecodeF32(bytes.NewReader(encode(samples, 22050, 192, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Length(), int64(1000*2*4); got != want {
+		t.Errorf("s.Length(): got: %d, want: %d", got, want)
+	}
+
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1000*2*4 {
+		t.Fatalf("len(got): got: %d, want: %d", len(got), 1000*2*4)
+	}
+	for i, v := range samples[0] {
+		want := float32(v) / (1 << 15)
+		l := math.Float32frombits(binary.LittleEndian.Uint32(got[8*i:]))
+		r := math.Float32frombits(binary.LittleEndian.Uint32(got[8*i+4:]))
+		if l != want || r != want {
+			t.Errorf("sample %d: got: (%f, %f), want: (%f, %f)", i, l, r, want, want)
+		}
+	}
+}
+
+func TestSeek(t *testing.T) {
+	samples := testSamples(5000)
+	want := toI16Bytes(samples)
+	for _, seekTable := range []bool{false, true} {
+		s, err := flac.DecodeWithoutResampling(bytes.NewReader(encode(samples, 44100, 256, seekTable)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pos := range []int64{3000 * 4, 0, 257 * 4, 4999 * 4} {
+			n, err := s.Seek(pos, io.SeekStart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != pos {
+				t.Errorf("seek table: %t: s.Seek(%d): got: %d, want: %d", seekTable, pos, n, pos)
+			}
+			buf := make([]byte, 400)
+			n2, err := io.ReadFull(s, buf)
+			if err != nil && err != io.ErrUnexpectedEOF {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf[:n2], want[pos:pos+int64(n2)]) {
+				t.Errorf("seek table: %t: decoded samples after seeking to %d mismatch", seekTable, pos)
+			}
+		}
+	}
+}
+
+func TestCRCMismatch(t *testing.T) {
+	bs := encode(testSamples(1000), 44100, 256, false)
+	// Corrupt the last frame.
+	bs[len(bs)-10] ^= 0xff
+	s, err := flac.DecodeWithoutResampling(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(s); err == nil {
+		t.Errorf("io.ReadAll must return an error for a corrupted stream")
+	}
+}
+
+func TestInvalidHeader(t *testing.T) {
+	if _, err := flac.DecodeF32(bytes.NewReader([]byte("RIFF0000WAVE"))); err == nil {
+		t.Errorf("DecodeF32 must return an error for a non-FLAC stream")
+	}
+}
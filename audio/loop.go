@@ -18,8 +18,12 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sync"
 )
 
+// defaultCrossfadeLengthInSamples is the default length of the crossfade at the loop joint.
+const defaultCrossfadeLengthInSamples = 256
+
 // InfiniteLoop represents a looped stream which never ends.
 type InfiniteLoop struct {
 	src             io.ReadSeeker
@@ -29,16 +33,27 @@ type InfiniteLoop struct {
 	bitDepthInBytes int
 	bytesPerSample  int
 
+	// crossfadeLength is the length of the crossfade in bytes.
+	crossfadeLength int64
+
 	// extra is the remainder in the case when the read byte sizes are not multiple of the bit depth.
 	extra []byte
 
 	// afterLoop is data after the loop.
 	afterLoop []byte
 
+	// afterLoopPos is the position where afterLoop starts.
+	afterLoopPos int64
+
 	// blending represents whether the loop start and afterLoop are blended or not.
 	blending bool
 
+	// blendStart is the loop start position where afterLoop is blended.
+	blendStart int64
+
 	noBlendForTesting bool
+
+	m sync.Mutex
 }
 
 // NewInfiniteLoop creates a new infinite loop stream with a source stream and length in bytes.
@@ -49,7 +64,7 @@ type InfiniteLoop struct {
 // This noise can be heard especially when src is decoded from a lossy compression format like Ogg/Vorbis and MP3.
 // In this case, try to add more (about 0.1[s]) data to src after the loop end.
 // If src has data after the loop end, an InfiniteLoop uses part of the data to blend with the loop start
-// to make the loop joint smooth.
+// to make the loop joint smooth. The length of the blending can be changed by SetCrossfadeLength.
 func NewInfiniteLoop(src io.ReadSeeker, length int64) *InfiniteLoop {
 	return newInfiniteLoopWithIntro(src, 0, length, bitDepthInBytesInt16)
 }
@@ -62,7 +77,7 @@ func NewInfiniteLoop(src io.ReadSeeker, length int64) *InfiniteLoop {
 // This noise can be heard especially when src is decoded from a lossy compression format like Ogg/Vorbis and MP3.
 // In this case, try to add more (about 0.1[s]) data to src after the loop end.
 // If src has data after the loop end, an InfiniteLoop uses part of the data to blend with the loop start
-// to make the loop joint smooth.
+// to make the loop joint smooth. The length of the blending can be changed by SetCrossfadeLength.
 func NewInfiniteLoopF32(src io.ReadSeeker, length int64) *InfiniteLoop {
 	return newInfiniteLoopWithIntro(src, 0, length, bitDepthInBytesFloat32)
 }
@@ -76,7 +91,7 @@ func NewInfiniteLoopF32(src io.ReadSeeker, length int64) *InfiniteLoop {
 // This noise can be heard especially when src is decoded from a lossy compression format like Ogg/Vorbis and MP3.
 // In this case, try to add more (about 0.1[s]) data to src after the loop end.
 // If src has data after the loop end, an InfiniteLoop uses part of the data to blend with the loop start
-// to make the loop joint smooth.
+// to make the loop joint smooth. The length of the blending can be changed by SetCrossfadeLength.
 func NewInfiniteLoopWithIntro(src io.ReadSeeker, introLength int64, loopLength int64) *InfiniteLoop {
 	return newInfiniteLoopWithIntro(src, introLength, loopLength, bitDepthInBytesInt16)
 }
@@ -90,7 +105,7 @@ func NewInfiniteLoopWithIntro(src io.ReadSeeker, introLength int64, loopLength i
 // This noise can be heard especially when src is decoded from a lossy compression format like Ogg/Vorbis and MP3.
 // In this case, try to add more (about 0.1[s]) data to src after the loop end.
 // If src has data after the loop end, an InfiniteLoop uses part of the data to blend with the loop start
-// to make the loop joint smooth.
+// to make the loop joint smooth. The length of the blending can be changed by SetCrossfadeLength.
 func NewInfiniteLoopWithIntroF32(src io.ReadSeeker, introLength int64, loopLength int64) *InfiniteLoop {
 	return newInfiniteLoopWithIntro(src, introLength, loopLength, bitDepthInBytesFloat32)
 }
@@ -104,9 +119,69 @@ func newInfiniteLoopWithIntro(src io.ReadSeeker, introLength int64, loopLength i
 		pos:             -1,
 		bitDepthInBytes: bitDepthInBytes,
 		bytesPerSample:  bytesPerSample,
+		crossfadeLength: defaultCrossfadeLengthInSamples * int64(bytesPerSample),
 	}
 }
 
+// SetCrossfadeLength sets the length of the crossfade at the loop joint in bytes.
+// The length is rounded down to a multiple of the sample size, so the crossfade is sample-accurate.
+//
+// At the loop joint, the data after the loop end fades out while the loop start fades in.
+// If src doesn't have enough data after the loop end, the crossfade is shortened.
+// If length is 0, no crossfade is applied.
+//
+// The default length is 256 samples.
+//
+// SetCrossfadeLength is concurrent-safe.
+func (i *InfiniteLoop) SetCrossfadeLength(length int64) {
+	if length < 0 {
+		panic(fmt.Sprintf("audio: crossfade length must be >= 0 but %d", length))
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.crossfadeLength = length / int64(i.bytesPerSample) * int64(i.bytesPerSample)
+	i.afterLoop = nil
+}
+
+// SetLoopPoints sets the loop start and the loop end in bytes.
+// start is the new intro length, and end - start is the new loop length.
+//
+// SetLoopPoints can be called during playing to move between sections of the music.
+// If the current position is before end, the stream keeps playing until end and then goes back to start.
+// Otherwise, the stream goes to start immediately.
+// In both cases, the crossfade set by SetCrossfadeLength is applied.
+//
+// SetLoopPoints panics when start is negative or end is not greater than start.
+//
+// SetLoopPoints is concurrent-safe.
+func (i *InfiniteLoop) SetLoopPoints(start, end int64) {
+	if start < 0 {
+		panic(fmt.Sprintf("audio: loop start must be >= 0 but %d", start))
+	}
+	start = start / int64(i.bytesPerSample) * int64(i.bytesPerSample)
+	end = end / int64(i.bytesPerSample) * int64(i.bytesPerSample)
+	if end <= start {
+		panic(fmt.Sprintf("audio: loop end must be greater than the loop start but start: %d, end: %d", start, end))
+	}
+
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	i.lstart = start
+	i.llength = end - start
+}
+
+// LoopPoints returns the loop start and the loop end in bytes.
+//
+// LoopPoints is concurrent-safe.
+func (i *InfiniteLoop) LoopPoints() (start, end int64) {
+	i.m.Lock()
+	defer i.m.Unlock()
+	return i.lstart, i.lstart + i.llength
+}
+
 func (i *InfiniteLoop) length() int64 {
 	return i.lstart + i.llength
 }
@@ -127,23 +202,68 @@ func (i *InfiniteLoop) ensurePos() error {
 }
 
 func (i *InfiniteLoop) blendRate(pos int64) float32 {
-	if pos < i.lstart {
+	if pos < i.blendStart {
 		return 0
 	}
-	if pos >= i.lstart+int64(len(i.afterLoop)) {
+	if pos >= i.blendStart+int64(len(i.afterLoop)) {
 		return 0
 	}
-	p := (pos - i.lstart) / int64(i.bytesPerSample)
+	p := (pos - i.blendStart) / int64(i.bytesPerSample)
 	l := len(i.afterLoop) / i.bytesPerSample
 	return 1 - float32(p)/float32(l)
 }
 
+// prepareBlending reads the data from the current position as afterLoop to blend with the loop start.
+func (i *InfiniteLoop) prepareBlending() error {
+	if i.afterLoop == nil || i.afterLoopPos != i.pos {
+		buflen := i.crossfadeLength
+		if buflen > i.length() {
+			buflen = i.length()
+		}
+
+		buf := make([]byte, buflen)
+		pos := 0
+		for pos < len(buf) {
+			n, err := i.src.Read(buf[pos:])
+			if err != nil && err != io.EOF {
+				return err
+			}
+			pos += n
+			if err == io.EOF {
+				break
+			}
+		}
+		i.afterLoop = buf[:pos]
+		i.afterLoopPos = i.pos
+	}
+	if len(i.afterLoop) > 0 {
+		i.blending = true
+		i.blendStart = i.lstart
+	}
+	return nil
+}
+
 // Read is implementation of ReadSeeker's Read.
 func (i *InfiniteLoop) Read(b []byte) (int, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
 	if err := i.ensurePos(); err != nil {
 		return 0, err
 	}
 
+	// The loop points were changed and the current position is already after the loop end.
+	if i.pos >= i.length() {
+		i.extra = i.extra[:0]
+		if err := i.prepareBlending(); err != nil {
+			return 0, err
+		}
+		if _, err := i.src.Seek(i.lstart, io.SeekStart); err != nil {
+			return 0, err
+		}
+		i.pos = i.lstart
+	}
+
 	if i.pos+int64(len(b)) > i.length() {
 		b = b[:i.length()-i.pos]
 	}
@@ -168,7 +288,7 @@ func (i *InfiniteLoop) Read(b []byte) (int, error) {
 
 	// Blend afterLoop and the loop start to reduce noises (#1888).
 	// Ideally, afterLoop and the loop start should be identical, but they can have very slight differences.
-	if !i.noBlendForTesting && i.blending && i.pos >= i.lstart && i.pos-int64(n) < i.lstart+int64(len(i.afterLoop)) {
+	if !i.noBlendForTesting && i.blending && i.pos >= i.blendStart && i.pos-int64(n) < i.blendStart+int64(len(i.afterLoop)) {
 		if n%i.bitDepthInBytes != 0 {
 			panic(fmt.Sprintf("audio: n must be a multiple of bit depth %d [bytes] but not: %d", i.bitDepthInBytes, n))
 		}
@@ -179,7 +299,7 @@ func (i *InfiniteLoop) Read(b []byte) (int, error) {
 				continue
 			}
 
-			relpos := abspos - i.lstart
+			relpos := abspos - i.blendStart
 			switch i.bitDepthInBytes {
 			case 2:
 				afterLoop := int16(i.afterLoop[relpos]) | (int16(i.afterLoop[relpos+1]) << 8)
@@ -208,28 +328,8 @@ func (i *InfiniteLoop) Read(b []byte) (int, error) {
 
 	// Read the afterLoop part if necessary.
 	if i.pos == i.length() && err == nil {
-		if i.afterLoop == nil {
-			buflen := int64(256 * i.bytesPerSample)
-			if buflen > i.length() {
-				buflen = i.length()
-			}
-
-			buf := make([]byte, buflen)
-			pos := 0
-			for pos < len(buf) {
-				n, err := i.src.Read(buf[pos:])
-				if err != nil && err != io.EOF {
-					return 0, err
-				}
-				pos += n
-				if err == io.EOF {
-					break
-				}
-			}
-			i.afterLoop = buf[:pos]
-		}
-		if len(i.afterLoop) > 0 {
-			i.blending = true
+		if err := i.prepareBlending(); err != nil {
+			return 0, err
 		}
 	}
 
@@ -246,6 +346,9 @@ func (i *InfiniteLoop) Read(b []byte) (int, error) {
 
 // Seek is implementation of ReadSeeker's Seek.
 func (i *InfiniteLoop) Seek(offset int64, whence int) (int64, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
 	i.blending = false
 	if err := i.ensurePos(); err != nil {
 		return 0, err
@@ -16,6 +16,7 @@ package audio_test
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io"
 	"math"
 	"testing"
@@ -257,3 +258,90 @@ func TestInfiniteLoopWithSlowSource(t *testing.T) {
 		t.Errorf("got: %d, want: %d", got, want)
 	}
 }
+
+func TestInfiniteLoopCrossfade(t *testing.T) {
+	const (
+		loopSamples      = 100
+		crossfadeSamples = 10
+	)
+
+	// The loop part is 1000 and the part after the loop is -1000.
+	src := make([]byte, 4*2*loopSamples)
+	for i := 0; i < 2*loopSamples; i++ {
+		v := int16(1000)
+		if i >= loopSamples {
+			v = -1000
+		}
+		binary.LittleEndian.PutUint16(src[4*i:], uint16(v))
+		binary.LittleEndian.PutUint16(src[4*i+2:], uint16(v))
+	}
+	loop := audio.NewInfiniteLoop(bytes.NewReader(src), 4*loopSamples)
+	loop.SetCrossfadeLength(4*crossfadeSamples + 3)
+
+	buf := make([]byte, 4*2*loopSamples)
+	if _, err := io.ReadFull(loop, buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2*loopSamples; i++ {
+		got := int16(binary.LittleEndian.Uint16(buf[4*i:]))
+		want := 1000.0
+		if i >= loopSamples && i < loopSamples+crossfadeSamples {
+			// The part after the loop fades out and the loop start fades in.
+			rate := 1 - float64(i-loopSamples)/crossfadeSamples
+			want = -1000*rate + 1000*(1-rate)
+		}
+		if math.Abs(float64(got)-want) > 1 {
+			t.Errorf("sample %d: got: %d, want: %f", i, got, want)
+		}
+	}
+}
+
+func TestInfiniteLoopSetLoopPoints(t *testing.T) {
+	src := make([]byte, 1024)
+	for i := 0; i < len(src)/2; i++ {
+		binary.LittleEndian.PutUint16(src[2*i:], uint16(i))
+	}
+	indexAt := func(buf []byte, i int) int {
+		return int(binary.LittleEndian.Uint16(buf[2*i:]))
+	}
+
+	loop := audio.NewInfiniteLoop(bytes.NewReader(src), int64(len(src)))
+	loop.SetCrossfadeLength(0)
+
+	buf := make([]byte, 256)
+	if _, err := io.ReadFull(loop, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The current position is before the new loop end.
+	loop.SetLoopPoints(512, 768)
+	if start, end := loop.LoopPoints(); start != 512 || end != 768 {
+		t.Errorf("LoopPoints(): got: (%d, %d), want: (512, 768)", start, end)
+	}
+	buf = make([]byte, 768)
+	if _, err := io.ReadFull(loop, buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(buf)/2; i++ {
+		want := 128 + i
+		if want >= 384 {
+			want = 256 + (want-384)%128
+		}
+		if got := indexAt(buf, i); got != want {
+			t.Errorf("index %d: got: %d, want: %d", i, got, want)
+		}
+	}
+
+	// The current position is after the new loop end.
+	loop.SetLoopPoints(0, 128)
+	buf = make([]byte, 256)
+	if _, err := io.ReadFull(loop, buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(buf)/2; i++ {
+		want := i % 64
+		if got := indexAt(buf, i); got != want {
+			t.Errorf("index %d: got: %d, want: %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+)
+
+// webCodecsDecoder is a decoder with WebCodecs' AudioDecoder.
+type webCodecsDecoder struct {
+	decoder      js.Value
+	config       js.Value
+	channelCount int
+	timestamp    int
+
+	samples []float32
+	bs      []byte
+	err     error
+
+	onOutput js.Func
+	onError  js.Func
+}
+
+func newPacketDecoder(channelCount int) (packetDecoder, error) {
+	class := js.Global().Get("AudioDecoder")
+	if !class.Truthy() {
+		return nil, errors.New("opus: AudioDecoder is not available")
+	}
+
+	d := &webCodecsDecoder{
+		config: js.ValueOf(map[string]any{
+			"codec":            "opus",
+			"sampleRate":       sampleRate,
+			"numberOfChannels": channelCount,
+		}),
+		channelCount: channelCount,
+	}
+	d.onOutput = js.FuncOf(func(this js.Value, args []js.Value) any {
+		data := args[0]
+		defer data.Call("close")
+
+		n := data.Get("numberOfFrames").Int()
+		if len(d.bs) < 4*n {
+			d.bs = make([]byte, 4*n)
+		}
+		arr := js.Global().Get("Float32Array").New(n)
+		bs := js.Global().Get("Uint8Array").New(arr.Get("buffer"))
+		start := len(d.samples)
+		d.samples = append(d.samples, make([]float32, n*channelCount)...)
+		for ch := 0; ch < channelCount; ch++ {
+			data.Call("copyTo", arr, map[string]any{
+				"planeIndex": ch,
+				"format":     "f32-planar",
+			})
+			js.CopyBytesToGo(d.bs, bs)
+			for i := 0; i < n; i++ {
+				d.samples[start+i*channelCount+ch] = math.Float32frombits(binary.LittleEndian.Uint32(d.bs[4*i:]))
+			}
+		}
+		return nil
+	})
+	d.onError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		d.err = fmt.Errorf("opus: AudioDecoder failed: %s", args[0].Call("toString").String())
+		return nil
+	})
+	d.decoder = class.New(map[string]any{
+		"output": d.onOutput,
+		"error":  d.onError,
+	})
+	d.decoder.Call("configure", d.config)
+	return d, nil
+}
+
+func (d *webCodecsDecoder) decode(packets [][]byte) ([]float32, error) {
+	d.samples = d.samples[:0]
+	if len(packets) == 0 {
+		return d.samples, nil
+	}
+
+	for _, p := range packets {
+		data := js.Global().Get("Uint8Array").New(len(p))
+		js.CopyBytesToJS(data, p)
+		d.decoder.Call("decode", js.Global().Get("EncodedAudioChunk").New(map[string]any{
+			"type":      "key",
+			"timestamp": d.timestamp,
+			"data":      data,
+		}))
+		// The timestamp is in microseconds. This is used only to keep the order.
+		d.timestamp += 20000
+	}
+
+	// Wait for the outputs. This blocks the current goroutine, but not the JavaScript event loop.
+	ch := make(chan struct{})
+	then := js.FuncOf(func(this js.Value, args []js.Value) any {
+		close(ch)
+		return nil
+	})
+	defer then.Release()
+	d.decoder.Call("flush").Call("then", then, then)
+	<-ch
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.samples, nil
+}
+
+func (d *webCodecsDecoder) reset() error {
+	if d.err != nil {
+		return d.err
+	}
+	// reset makes the decoder unconfigured.
+	d.decoder.Call("reset")
+	d.decoder.Call("configure", d.config)
+	d.timestamp = 0
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ((darwin || freebsd || linux || netbsd || openbsd) && !android && !ios && !nintendosdk && !playstation5) || windows
+
+package opus
+
+const (
+	_OPUS_OK          = 0
+	_OPUS_RESET_STATE = 4028
+
+	// maxFrameSize is the maximum number of samples per channel in a packet, which is 120 [ms].
+	maxFrameSize = sampleRate * 120 / 1000
+)
@@ -0,0 +1,124 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build (darwin || freebsd || linux || netbsd || openbsd) && !android && !ios && !nintendosdk && !playstation5
+
+package opus
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/ebitengine/purego"
+)
+
+var (
+	libopusOnce sync.Once
+	libopusErr  error
+
+	opus_decoder_create  func(fs int32, channels int32, err *int32) uintptr
+	opus_decode_float    func(st uintptr, data *byte, len int32, pcm *float32, frameSize int32, decodeFEC int32) int32
+	opus_decoder_ctl     func(st uintptr, request int32) int32
+	opus_decoder_destroy func(st uintptr)
+	opus_strerror        func(err int32) string
+)
+
+func libopusNames() []string {
+	if runtime.GOOS == "darwin" {
+		return []string{"libopus.0.dylib", "/opt/homebrew/lib/libopus.0.dylib", "/usr/local/lib/libopus.0.dylib"}
+	}
+	return []string{"libopus.so.0", "libopus.so"}
+}
+
+func loadLibopus() error {
+	libopusOnce.Do(func() {
+		var lib uintptr
+		// TODO: Use multiple %w-s as of Go 1.20.
+		var errors []string
+		for _, name := range libopusNames() {
+			l, err := purego.Dlopen(name, purego.RTLD_LAZY|purego.RTLD_GLOBAL)
+			if err == nil {
+				lib = l
+				break
+			}
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+		}
+		if lib == 0 {
+			libopusErr = fmt.Errorf("opus: failed to load libopus: %s", strings.Join(errors, ", "))
+			return
+		}
+		purego.RegisterLibFunc(&opus_decoder_create, lib, "opus_decoder_create")
+		purego.RegisterLibFunc(&opus_decode_float, lib, "opus_decode_float")
+		purego.RegisterLibFunc(&opus_decoder_ctl, lib, "opus_decoder_ctl")
+		purego.RegisterLibFunc(&opus_decoder_destroy, lib, "opus_decoder_destroy")
+		purego.RegisterLibFunc(&opus_strerror, lib, "opus_strerror")
+	})
+	return libopusErr
+}
+
+type libopusDecoder struct {
+	decoder      uintptr
+	channelCount int
+	pcm          []float32
+	samples      []float32
+}
+
+func newPacketDecoder(channelCount int) (packetDecoder, error) {
+	if err := loadLibopus(); err != nil {
+		return nil, err
+	}
+
+	var code int32
+	st := opus_decoder_create(sampleRate, int32(channelCount), &code)
+	if code != _OPUS_OK {
+		return nil, fmt.Errorf("opus: opus_decoder_create failed: %s", opus_strerror(code))
+	}
+	d := &libopusDecoder{
+		decoder:      st,
+		channelCount: channelCount,
+		pcm:          make([]float32, maxFrameSize*channelCount),
+	}
+	runtime.SetFinalizer(d, (*libopusDecoder).destroy)
+	return d, nil
+}
+
+func (d *libopusDecoder) decode(packets [][]byte) ([]float32, error) {
+	d.samples = d.samples[:0]
+	for _, p := range packets {
+		var data *byte
+		if len(p) > 0 {
+			data = &p[0]
+		}
+		n := opus_decode_float(d.decoder, data, int32(len(p)), &d.pcm[0], maxFrameSize, 0)
+		if n < 0 {
+			return nil, fmt.Errorf("opus: opus_decode_float failed: %s", opus_strerror(n))
+		}
+		d.samples = append(d.samples, d.pcm[:int(n)*d.channelCount]...)
+	}
+	runtime.KeepAlive(packets)
+	return d.samples, nil
+}
+
+func (d *libopusDecoder) reset() error {
+	if code := opus_decoder_ctl(d.decoder, _OPUS_RESET_STATE); code != _OPUS_OK {
+		return fmt.Errorf("opus: opus_decoder_ctl failed: %s", opus_strerror(code))
+	}
+	return nil
+}
+
+func (d *libopusDecoder) destroy() {
+	opus_decoder_destroy(d.decoder)
+}
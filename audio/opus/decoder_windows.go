@@ -0,0 +1,126 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	libopusOnce sync.Once
+	libopusErr  error
+
+	procOpusDecoderCreate  *windows.LazyProc
+	procOpusDecodeFloat    *windows.LazyProc
+	procOpusDecoderCtl     *windows.LazyProc
+	procOpusDecoderDestroy *windows.LazyProc
+	procOpusStrerror       *windows.LazyProc
+)
+
+func loadLibopus() error {
+	libopusOnce.Do(func() {
+		var dll *windows.LazyDLL
+		// TODO: Use multiple %w-s as of Go 1.20.
+		var errors []string
+		for _, name := range []string{"opus.dll", "libopus-0.dll"} {
+			d := windows.NewLazyDLL(name)
+			if err := d.Load(); err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			dll = d
+			break
+		}
+		if dll == nil {
+			libopusErr = fmt.Errorf("opus: failed to load opus.dll: %s", strings.Join(errors, ", "))
+			return
+		}
+		procOpusDecoderCreate = dll.NewProc("opus_decoder_create")
+		procOpusDecodeFloat = dll.NewProc("opus_decode_float")
+		procOpusDecoderCtl = dll.NewProc("opus_decoder_ctl")
+		procOpusDecoderDestroy = dll.NewProc("opus_decoder_destroy")
+		procOpusStrerror = dll.NewProc("opus_strerror")
+	})
+	return libopusErr
+}
+
+func opus_strerror(code int32) string {
+	r, _, _ := procOpusStrerror.Call(uintptr(code))
+	// r is a pointer to a static string in libopus, which is not managed by Go.
+	// Reinterpret the value via a pointer to avoid converting a uintptr to an unsafe.Pointer directly.
+	return windows.BytePtrToString(*(**byte)(unsafe.Pointer(&r)))
+}
+
+type libopusDecoder struct {
+	decoder      uintptr
+	channelCount int
+	pcm          []float32
+	samples      []float32
+}
+
+func newPacketDecoder(channelCount int) (packetDecoder, error) {
+	if err := loadLibopus(); err != nil {
+		return nil, err
+	}
+
+	var code int32
+	st, _, _ := procOpusDecoderCreate.Call(sampleRate, uintptr(channelCount), uintptr(unsafe.Pointer(&code)))
+	if code != _OPUS_OK {
+		return nil, fmt.Errorf("opus: opus_decoder_create failed: %s", opus_strerror(code))
+	}
+	d := &libopusDecoder{
+		decoder:      st,
+		channelCount: channelCount,
+		pcm:          make([]float32, maxFrameSize*channelCount),
+	}
+	runtime.SetFinalizer(d, (*libopusDecoder).destroy)
+	return d, nil
+}
+
+func (d *libopusDecoder) decode(packets [][]byte) ([]float32, error) {
+	d.samples = d.samples[:0]
+	for _, p := range packets {
+		var data *byte
+		if len(p) > 0 {
+			data = &p[0]
+		}
+		r, _, _ := procOpusDecodeFloat.Call(d.decoder, uintptr(unsafe.Pointer(data)), uintptr(len(p)), uintptr(unsafe.Pointer(&d.pcm[0])), maxFrameSize, 0)
+		runtime.KeepAlive(p)
+		n := int32(r)
+		if n < 0 {
+			return nil, fmt.Errorf("opus: opus_decode_float failed: %s", opus_strerror(n))
+		}
+		d.samples = append(d.samples, d.pcm[:int(n)*d.channelCount]...)
+	}
+	return d.samples, nil
+}
+
+func (d *libopusDecoder) reset() error {
+	r, _, _ := procOpusDecoderCtl.Call(d.decoder, _OPUS_RESET_STATE)
+	if code := int32(r); code != _OPUS_OK {
+		return fmt.Errorf("opus: opus_decoder_ctl failed: %s", opus_strerror(code))
+	}
+	return nil
+}
+
+func (d *libopusDecoder) destroy() {
+	_, _, _ = procOpusDecoderDestroy.Call(d.decoder)
+}
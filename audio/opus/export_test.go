@@ -0,0 +1,44 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus
+
+import (
+	"encoding/binary"
+)
+
+// fakeDecoder treats a packet as interleaved signed 16bit integer samples.
+type fakeDecoder struct{}
+
+func (fakeDecoder) decode(packets [][]byte) ([]float32, error) {
+	var samples []float32
+	for _, p := range packets {
+		for i := 0; i+1 < len(p); i += 2 {
+			samples = append(samples, float32(int16(binary.LittleEndian.Uint16(p[i:])))/(1<<15))
+		}
+	}
+	return samples, nil
+}
+
+func (fakeDecoder) reset() error {
+	return nil
+}
+
+// SetUpFakeDecoderForTesting replaces the Opus decoder with a fake decoder
+// that treats a packet as interleaved signed 16bit integer samples.
+func SetUpFakeDecoderForTesting() {
+	newPacketDecoderForTesting = func(channelCount int) (packetDecoder, error) {
+		return fakeDecoder{}, nil
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	pageHeaderTypeContinued = 0x01
+	pageHeaderTypeBOS       = 0x02
+	pageHeaderTypeEOS       = 0x04
+)
+
+// page is an Ogg page.
+type page struct {
+	headerType byte
+	granule    int64
+	serial     uint32
+
+	// packets is the packets completed in the page.
+	packets [][]byte
+}
+
+// oggReader reads Ogg pages of one logical stream.
+type oggReader struct {
+	r io.Reader
+
+	serial    uint32
+	serialSet bool
+
+	// partial is the packet that continues to the next page.
+	partial []byte
+
+	header [27]byte
+	buf    []byte
+}
+
+func newOggReader(r io.Reader) *oggReader {
+	return &oggReader{
+		r: r,
+	}
+}
+
+// reset discards the state except for the serial number.
+func (o *oggReader) reset() {
+	o.partial = nil
+}
+
+// readPage reads the next page of the logical stream.
+// readPage returns io.EOF when there are no more pages.
+func (o *oggReader) readPage() (*page, error) {
+	for {
+		if _, err := io.ReadFull(o.r, o.header[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("opus: unexpected EOF in an Ogg page header")
+			}
+			return nil, err
+		}
+		h := o.header[:]
+		if string(h[:4]) != "OggS" {
+			return nil, fmt.Errorf("opus: invalid Ogg page: 'OggS' not found")
+		}
+		if h[4] != 0 {
+			return nil, fmt.Errorf("opus: unsupported Ogg version: %d", h[4])
+		}
+
+		segmentCount := int(h[26])
+		segments := make([]byte, segmentCount)
+		if _, err := io.ReadFull(o.r, segments); err != nil {
+			return nil, err
+		}
+		var size int
+		for _, s := range segments {
+			size += int(s)
+		}
+		if cap(o.buf) < size {
+			o.buf = make([]byte, size)
+		}
+		data := o.buf[:size]
+		if _, err := io.ReadFull(o.r, data); err != nil {
+			return nil, err
+		}
+
+		crc := binary.LittleEndian.Uint32(h[22:26])
+		h[22], h[23], h[24], h[25] = 0, 0, 0, 0
+		if got := oggCRC(oggCRC(oggCRC(0, h), segments), data); got != crc {
+			return nil, fmt.Errorf("opus: Ogg page CRC mismatch")
+		}
+
+		p := &page{
+			headerType: h[5],
+			granule:    int64(binary.LittleEndian.Uint64(h[6:14])),
+			serial:     binary.LittleEndian.Uint32(h[14:18]),
+		}
+		if !o.serialSet {
+			o.serial = p.serial
+			o.serialSet = true
+		}
+		// Skip the pages of other logical streams.
+		if p.serial != o.serial {
+			continue
+		}
+
+		if p.headerType&pageHeaderTypeContinued == 0 {
+			o.partial = nil
+		}
+		var offset int
+		for _, s := range segments {
+			o.partial = append(o.partial, data[offset:offset+int(s)]...)
+			offset += int(s)
+			if s < 255 {
+				p.packets = append(p.packets, o.partial)
+				o.partial = nil
+			}
+		}
+		return p, nil
+	}
+}
+
+// lastGranule returns the granule position of the last page of the logical stream.
+// lastGranule changes the position of r.
+func (o *oggReader) lastGranule(r io.ReadSeeker) (int64, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	// The maximum page size is 65307 bytes.
+	const maxPageSize = 27 + 255 + 255*255
+	start := end - 2*maxPageSize
+	if start < 0 {
+		start = 0
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(buf) - 27; i >= 0; i-- {
+		if string(buf[i:i+4]) != "OggS" || buf[i+4] != 0 {
+			continue
+		}
+		if binary.LittleEndian.Uint32(buf[i+14:i+18]) != o.serial {
+			continue
+		}
+		granule := int64(binary.LittleEndian.Uint64(buf[i+6 : i+14]))
+		// A page that doesn't complete any packet has the granule position -1.
+		if granule == -1 {
+			continue
+		}
+		return granule, nil
+	}
+	return 0, fmt.Errorf("opus: the last Ogg page is not found")
+}
+
+var oggCRCTable [256]uint32
+
+func init() {
+	for i := range oggCRCTable {
+		c := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04c11db7
+			} else {
+				c <<= 1
+			}
+		}
+		oggCRCTable[i] = c
+	}
+}
+
+func oggCRC(crc uint32, bs []byte) uint32 {
+	for _, b := range bs {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
@@ -0,0 +1,442 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package opus provides Ogg/Opus decoder.
+//
+// Ogg/Opus streams are parsed in pure Go, and Opus packets are decoded by a platform decoder:
+// libopus on desktops, and WebCodecs' AudioDecoder on browsers.
+// On Linux, macOS, and BSDs, libopus is loaded dynamically without Cgo. On Windows, opus.dll is loaded.
+//
+// libopus is a runtime dependency and is not bundled with this package.
+// An application using this package must make libopus available on the user's machine,
+// e.g. by the libopus0 package on Debian and Ubuntu, by the opus formula of Homebrew on macOS,
+// or by putting opus.dll next to the executable on Windows.
+// If the platform decoder is not available, the decoding functions return an error.
+// The decoding functions always return an error on the other platforms like Android, iOS, and consoles.
+//
+// The sample rate of a decoded stream is always 48000 [Hz], which is the sample rate of Opus.
+package opus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/internal/convert"
+)
+
+const (
+	bitDepthInBytesInt16   = 2
+	bitDepthInBytesFloat32 = 4
+
+	// sampleRate is the sample rate of decoded Opus streams.
+	sampleRate = 48000
+
+	// preRoll is the number of samples to decode before a seeking position to converge the decoder state.
+	preRoll = sampleRate * 80 / 1000
+)
+
+// packetDecoder decodes Opus packets.
+type packetDecoder interface {
+	// decode decodes the packets and returns the interleaved samples.
+	decode(packets [][]byte) ([]float32, error)
+
+	// reset resets the decoder state for seeking.
+	reset() error
+}
+
+// newPacketDecoderForTesting is used to replace the platform decoder for testing.
+var newPacketDecoderForTesting func(channelCount int) (packetDecoder, error)
+
+// Stream is a decoded stream.
+type Stream struct {
+	readSeeker io.ReadSeeker
+	length     int64
+	sampleRate int
+}
+
+// Read is implementation of io.Reader's Read.
+func (s *Stream) Read(buf []byte) (int, error) {
+	return s.readSeeker.Read(buf)
+}
+
+// Seek is implementation of io.Seeker's Seek.
+//
+// Note that Seek can take long since the pages before the position must be scanned.
+func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	return s.readSeeker.Seek(offset, whence)
+}
+
+// Length returns the size of decoded stream in bytes.
+//
+// If the source is not io.Seeker, Length returns 0.
+func (s *Stream) Length() int64 {
+	return s.length
+}
+
+// SampleRate returns the sample rate of the decoded stream.
+func (s *Stream) SampleRate() int {
+	return s.sampleRate
+}
+
+// DecodeF32 decodes Ogg/Opus data to playable stream in 32bit float, little endian, 2 channels (stereo) format.
+//
+// DecodeF32 returns error when decoding fails or IO error happens.
+//
+// The returned Stream's Seek is available only when src is an io.Seeker.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+func DecodeF32(src io.Reader) (*Stream, error) {
+	s, err := decode(src, bitDepthInBytesFloat32)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{
+		readSeeker: s,
+		length:     s.length(),
+		sampleRate: sampleRate,
+	}, nil
+}
+
+// DecodeWithoutResampling decodes Ogg/Opus data to playable stream in signed 16bit integer, little endian, 2 channels (stereo) format.
+//
+// DecodeWithoutResampling returns error when decoding fails or IO error happens.
+//
+// The returned Stream's Seek is available only when src is an io.Seeker.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+func DecodeWithoutResampling(src io.Reader) (*Stream, error) {
+	s, err := decode(src, bitDepthInBytesInt16)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{
+		readSeeker: s,
+		length:     s.length(),
+		sampleRate: sampleRate,
+	}, nil
+}
+
+// DecodeWithSampleRate decodes Ogg/Opus data to playable stream in signed 16bit integer, little endian, 2 channels (stereo) format.
+//
+// DecodeWithSampleRate returns error when decoding fails or IO error happens.
+//
+// DecodeWithSampleRate automatically resamples the stream to fit with sampleRate if necessary.
+//
+// The returned Stream's Seek is available only when src is an io.Seeker.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+//
+// Resampling can be a very heavy task. Stream has a cache for resampling, but the size is limited.
+// Do not expect that Stream has a resampling cache even after whole data is played.
+func DecodeWithSampleRate(sr int, src io.Reader) (*Stream, error) {
+	s, err := decode(src, bitDepthInBytesInt16)
+	if err != nil {
+		return nil, err
+	}
+
+	if sr == sampleRate {
+		return &Stream{
+			readSeeker: s,
+			length:     s.length(),
+			sampleRate: sr,
+		}, nil
+	}
+
+	r := convert.NewResampling(s, s.length(), sampleRate, sr, bitDepthInBytesInt16)
+	return &Stream{
+		readSeeker: r,
+		length:     r.Length(),
+		sampleRate: sr,
+	}, nil
+}
+
+// stream is a decoded stream in 2 channels.
+type stream struct {
+	src             io.Reader
+	ogg             *oggReader
+	decoder         packetDecoder
+	bitDepthInBytes int
+
+	channelCount int
+	preSkip      int64
+	gain         float32
+
+	// dataOffset is the offset in bytes of the first audio page from the head of src.
+	dataOffset int64
+
+	// totalSamples is the number of samples per channel excluding the pre-skip. 0 means unknown.
+	totalSamples int64
+
+	// pos is the position in samples including the pre-skip at the end of the decoded samples.
+	pos int64
+
+	// discardUntil is the position in samples including the pre-skip. The samples before this are discarded.
+	discardUntil int64
+
+	// buf is the decoded bytes not read yet.
+	buf []byte
+
+	posInBytes int64
+	eof        bool
+}
+
+func decode(src io.Reader, bitDepthInBytes int) (*stream, error) {
+	cr := &countingReader{r: src}
+	o := newOggReader(cr)
+
+	// The first packet is the identification header, and the second packet is the comment header.
+	var packets [][]byte
+	for len(packets) < 2 {
+		p, err := o.readPage()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("opus: unexpected EOF in the headers")
+			}
+			return nil, err
+		}
+		packets = append(packets, p.packets...)
+	}
+	if len(packets) > 2 {
+		return nil, fmt.Errorf("opus: invalid headers: an audio packet must start with a new page")
+	}
+
+	head := packets[0]
+	if len(head) < 19 || string(head[:8]) != "OpusHead" {
+		return nil, fmt.Errorf("opus: invalid header: 'OpusHead' not found")
+	}
+	if head[8]>>4 != 0 {
+		return nil, fmt.Errorf("opus: unsupported version: %d", head[8])
+	}
+	channelCount := int(head[9])
+	if channelCount != 1 && channelCount != 2 {
+		return nil, fmt.Errorf("opus: number of channels must be 1 or 2 but was %d", channelCount)
+	}
+	if family := head[18]; family != 0 {
+		// Only one stream is supported for the other mapping families.
+		if len(head) < 21 || head[19] != 1 {
+			return nil, fmt.Errorf("opus: unsupported channel mapping family: %d", family)
+		}
+	}
+	if len(packets[1]) < 8 || string(packets[1][:8]) != "OpusTags" {
+		return nil, fmt.Errorf("opus: invalid header: 'OpusTags' not found")
+	}
+
+	var d packetDecoder
+	var err error
+	if newPacketDecoderForTesting != nil {
+		d, err = newPacketDecoderForTesting(channelCount)
+	} else {
+		d, err = newPacketDecoder(channelCount)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &stream{
+		src:             src,
+		ogg:             o,
+		decoder:         d,
+		bitDepthInBytes: bitDepthInBytes,
+		channelCount:    channelCount,
+		preSkip:         int64(binary.LittleEndian.Uint16(head[10:12])),
+		// The output gain is in Q7.8 dB.
+		gain:       float32(math.Pow(10, float64(int16(binary.LittleEndian.Uint16(head[16:18])))/(20*256))),
+		dataOffset: cr.n,
+	}
+	s.discardUntil = s.preSkip
+
+	if seeker, ok := src.(io.ReadSeeker); ok {
+		granule, err := o.lastGranule(seeker)
+		if err != nil {
+			return nil, err
+		}
+		if granule > s.preSkip {
+			s.totalSamples = granule - s.preSkip
+		}
+		if _, err := seeker.Seek(s.dataOffset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *stream) bytesPerSample() int {
+	return 2 * s.bitDepthInBytes
+}
+
+func (s *stream) length() int64 {
+	return s.totalSamples * int64(s.bytesPerSample())
+}
+
+// Read is implementation of io.Reader's Read.
+func (s *stream) Read(buf []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.eof {
+			return 0, io.EOF
+		}
+		if err := s.decodePage(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(buf, s.buf)
+	s.buf = s.buf[n:]
+	s.posInBytes += int64(n)
+	return n, nil
+}
+
+// decodePage reads the next page and appends the decoded bytes to s.buf.
+func (s *stream) decodePage() error {
+	p, err := s.ogg.readPage()
+	if err == io.EOF {
+		s.eof = true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if p.headerType&pageHeaderTypeEOS != 0 {
+		s.eof = true
+	}
+
+	samples, err := s.decoder.decode(p.packets)
+	if err != nil {
+		return err
+	}
+
+	n := int64(len(samples) / s.channelCount)
+	start := s.pos
+	end := start + n
+	s.pos = end
+
+	// Trim the end of the stream.
+	if s.eof && p.granule != -1 && p.granule < end {
+		end = p.granule
+	}
+	if s.totalSamples > 0 && end > s.totalSamples+s.preSkip {
+		end = s.totalSamples + s.preSkip
+	}
+	from := start
+	if from < s.discardUntil {
+		from = s.discardUntil
+	}
+	if from >= end {
+		return nil
+	}
+
+	for i := from - start; i < end-start; i++ {
+		for ch := 0; ch < 2; ch++ {
+			v := samples[int(i)*s.channelCount+ch%s.channelCount] * s.gain
+			switch s.bitDepthInBytes {
+			case bitDepthInBytesInt16:
+				v *= 1 << 15
+				if v > math.MaxInt16 {
+					v = math.MaxInt16
+				} else if v < math.MinInt16 {
+					v = math.MinInt16
+				}
+				s.buf = binary.LittleEndian.AppendUint16(s.buf, uint16(int16(v)))
+			case bitDepthInBytesFloat32:
+				s.buf = binary.LittleEndian.AppendUint32(s.buf, math.Float32bits(v))
+			}
+		}
+	}
+	return nil
+}
+
+// Seek is implementation of io.Seeker's Seek.
+//
+// If the source is not an io.Seeker, Seek panics.
+func (s *stream) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := s.src.(io.Seeker)
+	if !ok {
+		panic("opus: s.src must be io.Seeker but not")
+	}
+
+	next := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		next += s.posInBytes
+	case io.SeekEnd:
+		next += s.length()
+	}
+	if next < 0 {
+		return 0, fmt.Errorf("opus: negative position: %d", next)
+	}
+
+	if _, err := seeker.Seek(s.dataOffset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := s.decoder.reset(); err != nil {
+		return 0, err
+	}
+	s.ogg.reset()
+	s.pos = 0
+	s.buf = s.buf[:0]
+	s.eof = false
+
+	sample := next / int64(s.bytesPerSample())
+	s.discardUntil = sample + s.preSkip
+
+	// Skip the pages that end before the pre-roll without decoding.
+	for {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		partial := s.ogg.partial
+		p, err := s.ogg.readPage()
+		if err == io.EOF {
+			s.eof = true
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		// A page that doesn't complete any packet has the granule position -1.
+		if p.granule == -1 || p.granule < s.discardUntil-preRoll && p.headerType&pageHeaderTypeEOS == 0 {
+			if p.granule != -1 {
+				s.pos = p.granule
+			}
+			continue
+		}
+		// Go back to the head of the page to decode it.
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+		s.ogg.partial = partial
+		break
+	}
+
+	s.posInBytes = sample * int64(s.bytesPerSample())
+	return s.posInBytes, nil
+}
+
+// countingReader is an io.Reader that counts the read bytes.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
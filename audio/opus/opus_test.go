@@ -0,0 +1,295 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opus_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/opus"
+)
+
+func oggCRC(bs []byte) uint32 {
+	var crc uint32
+	for _, b := range bs {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+type oggWriter struct {
+	buf []byte
+	seq uint32
+}
+
+func (w *oggWriter) writePage(headerType byte, granule int64, segments []byte, data []byte) {
+	page := []byte("OggS")
+	page = append(page, 0, headerType)
+	page = binary.LittleEndian.AppendUint64(page, uint64(granule))
+	page = binary.LittleEndian.AppendUint32(page, 1)
+	page = binary.LittleEndian.AppendUint32(page, w.seq)
+	page = append(page, 0, 0, 0, 0, byte(len(segments)))
+	page = append(page, segments...)
+	page = append(page, data...)
+	binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+	w.buf = append(w.buf, page...)
+	w.seq++
+}
+
+// writePackets writes packets into pages with at most maxSegments segments, so that a packet can span pages.
+// The granule position of the last page is lastGranule.
+func (w *oggWriter) writePackets(packets [][]byte, channelCount int, preSkip int, maxSegments int, lastGranule int64) {
+	type segment struct {
+		data []byte
+		// samples is the number of samples of the packet if the segment is the last one of the packet.
+		samples int
+	}
+	var segments []segment
+	for _, p := range packets {
+		for i := 0; ; i += 255 {
+			if len(p)-i < 255 {
+				segments = append(segments, segment{data: p[i:], samples: len(p) / 2 / channelCount})
+				break
+			}
+			segments = append(segments, segment{data: p[i : i+255], samples: -1})
+		}
+	}
+
+	granule := int64(0)
+	var continued bool
+	for len(segments) > 0 {
+		n := maxSegments
+		if n > len(segments) {
+			n = len(segments)
+		}
+		var table []byte
+		var data []byte
+		pageGranule := int64(-1)
+		for _, s := range segments[:n] {
+			table = append(table, byte(len(s.data)))
+			data = append(data, s.data...)
+			if s.samples >= 0 {
+				granule += int64(s.samples)
+				pageGranule = granule
+			}
+		}
+		var headerType byte
+		if continued {
+			headerType |= 0x01
+		}
+		segments = segments[n:]
+		if len(segments) == 0 {
+			headerType |= 0x04
+			pageGranule = lastGranule
+		}
+		w.writePage(headerType, pageGranule, table, data)
+		continued = table[n-1] == 255
+	}
+}
+
+func opusHead(channelCount int, preSkip int, gain int16) []byte {
+	head := []byte("OpusHead")
+	head = append(head, 1, byte(channelCount))
+	head = binary.LittleEndian.AppendUint16(head, uint16(preSkip))
+	head = binary.LittleEndian.AppendUint32(head, 44100)
+	head = binary.LittleEndian.AppendUint16(head, uint16(gain))
+	head = append(head, 0)
+	return head
+}
+
+// encode creates an Ogg/Opus-like stream for the fake decoder.
+func encode(samples [][]int16, preSkip int, gain int16, samplesPerPacket int) []byte {
+	channelCount := len(samples)
+	w := &oggWriter{}
+	head := opusHead(channelCount, preSkip, gain)
+	w.writePage(0x02, 0, []byte{byte(len(head))}, head)
+	tags := append([]byte("OpusTags"), 0, 0, 0, 0, 0, 0, 0, 0)
+	w.writePage(0, 0, []byte{byte(len(tags))}, tags)
+
+	// Prepend the pre-skip samples and pad the last packet.
+	total := len(samples[0])
+	var packets [][]byte
+	var packet []byte
+	for i := -preSkip; ; i++ {
+		if len(packet) == samplesPerPacket*2*channelCount {
+			packets = append(packets, packet)
+			packet = nil
+			if i >= total {
+				break
+			}
+		}
+		for ch := 0; ch < channelCount; ch++ {
+			var v int16
+			if i >= 0 && i < total {
+				v = samples[ch][i]
+			} else if i >= total {
+				v = 12345
+			}
+			packet = binary.LittleEndian.AppendUint16(packet, uint16(v))
+		}
+	}
+	w.writePackets(packets, channelCount, preSkip, 4, int64(preSkip+total))
+	return w.buf
+}
+
+func testSamples(channelCount int, n int) [][]int16 {
+	samples := make([][]int16, channelCount)
+	for ch := range samples {
+		samples[ch] = make([]int16, n)
+		for i := range samples[ch] {
+			samples[ch][i] = int16(10000 * math.Sin(2*math.Pi*float64(i)/float64(100+ch*37)))
+		}
+	}
+	return samples
+}
+
+func toStereoI16Bytes(samples [][]int16) []byte {
+	var buf []byte
+	for i := range samples[0] {
+		for ch := 0; ch < 2; ch++ {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(samples[ch%len(samples)][i]))
+		}
+	}
+	return buf
+}
+
+func TestDecode(t *testing.T) {
+	opus.SetUpFakeDecoderForTesting()
+
+	for _, channelCount := range []int{1, 2} {
+		samples := testSamples(channelCount, 5000)
+		s, err := opus.DecodeWithoutResampling(bytes.NewReader(encode(samples, 312, 0, 480)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := s.SampleRate(), 48000; got != want {
+			t.Errorf("channels: %d: s.SampleRate(): got: %d, want: %d", channelCount, got, want)
+		}
+		if got, want := s.Length(), int64(5000*2*2); got != want {
+			t.Errorf("channels: %d: s.Length(): got: %d, want: %d", channelCount, got, want)
+		}
+		got, err := io.ReadAll(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := toStereoI16Bytes(samples); !bytes.Equal(got, want) {
+			t.Errorf("channels: %d: decoded samples mismatch: len(got): %d, len(want): %d", channelCount, len(got), len(want))
+		}
+	}
+}
+
+func TestDecodeWithoutSeeker(t *testing.T) {
+	opus.SetUpFakeDecoderForTesting()
+
+	samples := testSamples(2, 3000)
+	s, err := opus.DecodeWithoutResampling(struct{ io.Reader }{bytes.NewReader(encode(samples, 100, 0, 960))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Length(), int64(0); got != want {
+		t.Errorf("s.Length(): got: %d, want: %d", got, want)
+	}
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The end is trimmed by the granule position of the last page.
+	if want := toStereoI16Bytes(samples); !bytes.Equal(got, want) {
+		t.Errorf("decoded samples mismatch: len(got): %d, len(want): %d", len(got), len(want))
+	}
+}
+
+func TestSeek(t *testing.T) {
+	opus.SetUpFakeDecoderForTesting()
+
+	samples := testSamples(2, 48000)
+	want := toStereoI16Bytes(samples)
+	s, err := opus.DecodeWithoutResampling(bytes.NewReader(encode(samples, 312, 0, 480)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pos := range []int64{30000 * 4, 0, 4801 * 4, 47999 * 4, 48000 * 4} {
+		n, err := s.Seek(pos, io.SeekStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != pos {
+			t.Errorf("s.Seek(%d): got: %d, want: %d", pos, n, pos)
+		}
+		buf := make([]byte, 4000)
+		n2, err := io.ReadFull(s, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf[:n2], want[pos:pos+int64(n2)]) {
+			t.Errorf("decoded samples after seeking to %d mismatch", pos)
+		}
+		if wantN := int64(len(want)) - pos; wantN < int64(len(buf)) && int64(n2) != wantN {
+			t.Errorf("the number of bytes after seeking to %d: got: %d, want: %d", pos, n2, wantN)
+		}
+	}
+}
+
+func TestGain(t *testing.T) {
+	opus.SetUpFakeDecoderForTesting()
+
+	samples := [][]int16{{1000, -2000, 3000}}
+	// +6.0206 [dB] doubles the amplitude.
+	s, err := opus.DecodeF32(bytes.NewReader(encode(samples, 0, int16(math.Round(20*math.Log10(2)*256)), 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3*2*4 {
+		t.Fatalf("len(got): got: %d, want: %d", len(got), 3*2*4)
+	}
+	for i, v := range samples[0] {
+		want := 2 * float64(v) / (1 << 15)
+		for ch := 0; ch < 2; ch++ {
+			got := float64(math.Float32frombits(binary.LittleEndian.Uint32(got[8*i+4*ch:])))
+			if math.Abs(got-want) > 1e-3 {
+				t.Errorf("sample %d, channel %d: got: %f, want: %f", i, ch, got, want)
+			}
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	opus.SetUpFakeDecoderForTesting()
+
+	if _, err := opus.DecodeF32(bytes.NewReader([]byte("RIFF0000WAVE"))); err == nil {
+		t.Errorf("DecodeF32 must return an error for a non-Ogg stream")
+	}
+
+	// A corrupted page
+	bs := encode(testSamples(1, 100), 0, 0, 50)
+	bs[40] ^= 0xff
+	if _, err := opus.DecodeF32(bytes.NewReader(bs)); err == nil {
+		t.Errorf("DecodeF32 must return an error for a corrupted stream")
+	}
+}
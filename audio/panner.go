@@ -0,0 +1,364 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"io"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// Listener represents the position and the orientation of the listener of positional audio.
+type Listener struct {
+	// X, Y, and Z are the position of the listener.
+	X float64
+	Y float64
+	Z float64
+
+	// ForwardX, ForwardY, and ForwardZ are the direction the listener faces.
+	// If the forward vector is zero, (0, 0, -1) is used.
+	ForwardX float64
+	ForwardY float64
+	ForwardZ float64
+
+	// UpX, UpY, and UpZ are the upward direction of the listener's head.
+	// If the up vector is zero, (0, 1, 0) is used.
+	UpX float64
+	UpY float64
+	UpZ float64
+}
+
+// SetListener sets the listener for the positional audio of PannerPlayers.
+//
+// The default listener is at (0, 0, 0), faces (0, 0, -1), and the up vector is (0, 1, 0).
+//
+// SetListener is concurrent-safe.
+func (c *Context) SetListener(listener *Listener) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.listener = *listener
+}
+
+func (c *Context) currentListener() Listener {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.listener
+}
+
+// DistanceModel represents how the volume is attenuated by the distance between a source and the listener.
+type DistanceModel int
+
+const (
+	// DistanceModelInverse attenuates the volume by refDistance / (refDistance + rolloffFactor * (distance - refDistance)).
+	DistanceModelInverse DistanceModel = iota
+
+	// DistanceModelLinear attenuates the volume by 1 - rolloffFactor * (distance - refDistance) / (maxDistance - refDistance).
+	DistanceModelLinear
+
+	// DistanceModelExponential attenuates the volume by (distance / refDistance) ^ -rolloffFactor.
+	DistanceModelExponential
+
+	// DistanceModelNone doesn't attenuate the volume.
+	DistanceModelNone
+)
+
+// PannerOptions represents options for NewPannerPlayer.
+type PannerOptions struct {
+	// DistanceModel is the model of the attenuation by the distance.
+	// The default (zero) value is DistanceModelInverse.
+	DistanceModel DistanceModel
+
+	// RefDistance is the distance where the volume starts to be attenuated.
+	// The distance is clamped to RefDistance and MaxDistance.
+	// The default (zero) value is treated as 1.
+	RefDistance float64
+
+	// MaxDistance is the distance where the volume stops to be attenuated.
+	// The default (zero) value is treated as 10000.
+	MaxDistance float64
+
+	// RolloffFactor is how quickly the volume is attenuated.
+	// The default (zero) value is treated as 1.
+	RolloffFactor float64
+
+	// HRTF indicates whether the panner simulates the head of the listener.
+	//
+	// With HRTF, the sound to the ear farther from a source is delayed and muffled, which makes the direction more perceivable.
+	// This is a simple approximation with a spherical head model, and doesn't use measured head-related transfer functions.
+	//
+	// The default (zero) value is false, which uses equal-power panning.
+	HRTF bool
+}
+
+// PannerPlayer is a player whose sound is spatialized by the position relative to the listener.
+//
+// The source is mixed down to mono and then panned to the stereo channels.
+type PannerPlayer struct {
+	player *Player
+	panner *panner
+}
+
+// NewPannerPlayer creates a new player with the given stream, which sound is spatialized.
+//
+// src's format must be linear PCM (32bit float, little endian, 2 channel stereo)
+// without a header (e.g. RIFF header).
+// The sample rate must be same as that of the audio context.
+//
+// If options is nil, the default options are used.
+//
+// The other conditions for src are the same as NewPlayerF32.
+func (c *Context) NewPannerPlayer(src io.Reader, options *PannerOptions) (*PannerPlayer, error) {
+	if options == nil {
+		options = &PannerOptions{}
+	}
+
+	_, seekable := src.(io.Seeker)
+	p := newPanner(c, c.sampleRate, options)
+	pi, err := c.playerFactory.newPlayer(c, newProcessStream(src, p), seekable, src, bitDepthInBytesFloat32)
+	if err != nil {
+		return nil, err
+	}
+
+	player := &Player{pi}
+	runtime.SetFinalizer(player, (*Player).finalize)
+
+	return &PannerPlayer{
+		player: player,
+		panner: p,
+	}, nil
+}
+
+// Player returns the underlying player to play, pause, and set the volume.
+func (p *PannerPlayer) Player() *Player {
+	return p.player
+}
+
+// SetPosition sets the position of the source.
+// The default position is (0, 0, 0).
+//
+// SetPosition is concurrent-safe.
+func (p *PannerPlayer) SetPosition(x, y, z float64) {
+	p.panner.setPosition(x, y, z)
+}
+
+// Position returns the position of the source.
+func (p *PannerPlayer) Position() (x, y, z float64) {
+	return p.panner.position()
+}
+
+// maxITD is the maximum interaural time difference in seconds for a spherical head with the radius 0.0875[m].
+const maxITD = 0.0875 / 343 * (math.Pi/2 + 1)
+
+type panner struct {
+	listener   func() Listener
+	sampleRate int
+	options    PannerOptions
+
+	x, y, z float64
+
+	// The gains and the ITD delays of the left and the right channels at the end of the last process.
+	gainL  float64
+	gainR  float64
+	delayL int
+	delayR int
+	inited bool
+
+	// lines are the delay lines for the ITD.
+	lineL []float32
+	lineR []float32
+	pos   int
+
+	// The states of the low-pass filters for the head shadow.
+	lowL float64
+	lowR float64
+
+	m sync.Mutex
+}
+
+func newPanner(context *Context, sampleRate int, options *PannerOptions) *panner {
+	p := &panner{
+		listener:   context.currentListener,
+		sampleRate: sampleRate,
+		options:    *options,
+	}
+	if p.options.RefDistance == 0 {
+		p.options.RefDistance = 1
+	}
+	if p.options.MaxDistance == 0 {
+		p.options.MaxDistance = 10000
+	}
+	if p.options.RolloffFactor == 0 {
+		p.options.RolloffFactor = 1
+	}
+	if p.options.HRTF {
+		n := int(math.Ceil(maxITD*float64(sampleRate))) + 1
+		p.lineL = make([]float32, n)
+		p.lineR = make([]float32, n)
+	}
+	return p
+}
+
+func (p *panner) setPosition(x, y, z float64) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.x, p.y, p.z = x, y, z
+}
+
+func (p *panner) position() (x, y, z float64) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.x, p.y, p.z
+}
+
+// distanceGain returns the attenuation by the distance.
+func (p *panner) distanceGain(distance float64) float64 {
+	o := &p.options
+	switch o.DistanceModel {
+	case DistanceModelInverse:
+		d := math.Max(distance, o.RefDistance)
+		return o.RefDistance / (o.RefDistance + o.RolloffFactor*(d-o.RefDistance))
+	case DistanceModelLinear:
+		if o.MaxDistance <= o.RefDistance {
+			return 1
+		}
+		d := math.Min(math.Max(distance, o.RefDistance), o.MaxDistance)
+		return math.Max(1-o.RolloffFactor*(d-o.RefDistance)/(o.MaxDistance-o.RefDistance), 0)
+	case DistanceModelExponential:
+		d := math.Max(distance, o.RefDistance)
+		return math.Pow(d/o.RefDistance, -o.RolloffFactor)
+	}
+	return 1
+}
+
+// azimuthAndDistance returns the azimuth in radians and the distance of the source from the listener.
+// The azimuth is in [-pi/2, pi/2], where a negative value is left and a positive value is right.
+// A source behind the listener is mirrored to the front.
+func azimuthAndDistance(l Listener, x, y, z float64) (float64, float64) {
+	fx, fy, fz := l.ForwardX, l.ForwardY, l.ForwardZ
+	if fx == 0 && fy == 0 && fz == 0 {
+		fz = -1
+	}
+	ux, uy, uz := l.UpX, l.UpY, l.UpZ
+	if ux == 0 && uy == 0 && uz == 0 {
+		uy = 1
+	}
+
+	// right = forward x up
+	rx := fy*uz - fz*uy
+	ry := fz*ux - fx*uz
+	rz := fx*uy - fy*ux
+
+	dx, dy, dz := x-l.X, y-l.Y, z-l.Z
+	distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
+
+	right := (dx*rx + dy*ry + dz*rz) / math.Max(math.Sqrt(rx*rx+ry*ry+rz*rz), 1e-9)
+	front := (dx*fx + dy*fy + dz*fz) / math.Sqrt(fx*fx+fy*fy+fz*fz)
+	if right == 0 && front == 0 {
+		return 0, distance
+	}
+	// Use the absolute value of front to mirror a source behind the listener to the front.
+	return math.Atan2(right, math.Abs(front)), distance
+}
+
+// targets returns the gains and the ITD delays in samples of the left and the right channels for the current position.
+func (p *panner) targets() (gainL, gainR float64, delayL, delayR int) {
+	p.m.Lock()
+	x, y, z := p.x, p.y, p.z
+	p.m.Unlock()
+
+	azimuth, distance := azimuthAndDistance(p.listener(), x, y, z)
+	g := p.distanceGain(distance)
+
+	// Equal-power panning.
+	pan := (azimuth/(math.Pi/2) + 1) / 2
+	gainL = g * math.Cos(pan*math.Pi/2)
+	gainR = g * math.Sin(pan*math.Pi/2)
+
+	if p.options.HRTF {
+		// Woodworth's formula of the interaural time difference.
+		a := math.Abs(azimuth)
+		d := int(math.Round(0.0875 / 343 * (a + math.Sin(a)) * float64(p.sampleRate)))
+		if azimuth > 0 {
+			delayL = d
+		} else {
+			delayR = d
+		}
+	}
+	return
+}
+
+func (p *panner) process(samples []float32) {
+	gainL, gainR, delayL, delayR := p.targets()
+	if !p.inited {
+		p.gainL, p.gainR = gainL, gainR
+		p.inited = true
+	}
+	// The ITD delays are updated per process call.
+	p.delayL, p.delayR = delayL, delayR
+
+	// Cutoff coefficients of the head shadow. The farther ear's sound is more muffled.
+	var coeffL, coeffR float64
+	if p.options.HRTF {
+		coeffL = headShadowCoeff(float64(delayL) / (maxITD * float64(p.sampleRate)))
+		coeffR = headShadowCoeff(float64(delayR) / (maxITD * float64(p.sampleRate)))
+	}
+
+	frames := len(samples) / channelCount
+	for i := 0; i < frames; i++ {
+		// Interpolate the gains to avoid clicks.
+		t := float64(i+1) / float64(frames)
+		gl := p.gainL + (gainL-p.gainL)*t
+		gr := p.gainR + (gainR-p.gainR)*t
+
+		mono := (samples[2*i] + samples[2*i+1]) / 2
+		l, r := float64(mono), float64(mono)
+		if p.options.HRTF {
+			n := len(p.lineL)
+			p.lineL[p.pos] = mono
+			p.lineR[p.pos] = mono
+			l = float64(p.lineL[(p.pos-p.delayL+n)%n])
+			r = float64(p.lineR[(p.pos-p.delayR+n)%n])
+			p.pos = (p.pos + 1) % n
+
+			p.lowL += coeffL * (l - p.lowL)
+			p.lowR += coeffR * (r - p.lowR)
+			l, r = p.lowL, p.lowR
+		}
+		samples[2*i] = float32(l * gl)
+		samples[2*i+1] = float32(r * gr)
+	}
+	p.gainL, p.gainR = gainL, gainR
+}
+
+// headShadowCoeff returns the coefficient of the one-pole low-pass filter for an ear.
+// rate is in [0, 1], where 0 means the ear faces the source and 1 means the ear is on the opposite side.
+func headShadowCoeff(rate float64) float64 {
+	return 1 - 0.75*rate
+}
+
+func (p *panner) active() bool {
+	return true
+}
+
+func (p *panner) reset() {
+	for i := range p.lineL {
+		p.lineL[i] = 0
+		p.lineR[i] = 0
+	}
+	p.pos = 0
+	p.lowL = 0
+	p.lowR = 0
+}
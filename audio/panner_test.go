@@ -0,0 +1,171 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// constantF32 returns a 32bit float stereo stream of the given number of frames with a constant value.
+func constantF32(frames int, value float32) []byte {
+	b := make([]byte, 8*frames)
+	for i := 0; i < 2*frames; i++ {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(value))
+	}
+	return b
+}
+
+// readF32 reads all the samples as 32bit float values.
+func readF32(t *testing.T, r io.Reader) []float32 {
+	t.Helper()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs := make([]float32, len(b)/4)
+	for i := range vs {
+		vs[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return vs
+}
+
+func TestPanner(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cases := []struct {
+		Name    string
+		X, Y, Z float64
+		Options *audio.PannerOptions
+		Left    float64
+		Right   float64
+	}{
+		{
+			Name:  "front",
+			Z:     -1,
+			Left:  0.5 * math.Sqrt2 / 2,
+			Right: 0.5 * math.Sqrt2 / 2,
+		},
+		{
+			Name:  "right",
+			X:     1,
+			Left:  0,
+			Right: 0.5,
+		},
+		{
+			Name:  "behind left",
+			X:     -1,
+			Z:     1,
+			Left:  0.5 * 1 / (1 + (math.Sqrt2 - 1)) * math.Cos(math.Pi/8),
+			Right: 0.5 * 1 / (1 + (math.Sqrt2 - 1)) * math.Sin(math.Pi/8),
+		},
+		{
+			Name:  "inverse",
+			X:     -2,
+			Left:  0.25,
+			Right: 0,
+		},
+		{
+			Name: "linear",
+			X:    2,
+			Options: &audio.PannerOptions{
+				DistanceModel: audio.DistanceModelLinear,
+				MaxDistance:   3,
+			},
+			Left:  0,
+			Right: 0.25,
+		},
+		{
+			Name: "exponential",
+			X:    4,
+			Options: &audio.PannerOptions{
+				DistanceModel: audio.DistanceModelExponential,
+				RolloffFactor: 0.5,
+			},
+			Left:  0,
+			Right: 0.25,
+		},
+		{
+			Name: "none",
+			X:    100,
+			Options: &audio.PannerOptions{
+				DistanceModel: audio.DistanceModelNone,
+			},
+			Left:  0,
+			Right: 0.5,
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			r := audio.NewPannerReaderForTesting(context, bytes.NewReader(constantF32(16, 0.5)), c.Options, c.X, c.Y, c.Z)
+			vs := readF32(t, r)
+			if got, want := len(vs), 32; got != want {
+				t.Fatalf("len(samples): got: %d, want: %d", got, want)
+			}
+			for i := 0; i < len(vs)/2; i++ {
+				if math.Abs(float64(vs[2*i])-c.Left) > 1e-5 || math.Abs(float64(vs[2*i+1])-c.Right) > 1e-5 {
+					t.Errorf("frame %d: got: (%f, %f), want: (%f, %f)", i, vs[2*i], vs[2*i+1], c.Left, c.Right)
+				}
+			}
+		})
+	}
+}
+
+func TestPannerListener(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// The listener faces +X. The source on +Z is on the right.
+	context.SetListener(&audio.Listener{
+		ForwardX: 1,
+	})
+	r := audio.NewPannerReaderForTesting(context, bytes.NewReader(constantF32(16, 0.5)), nil, 0, 0, 1)
+	vs := readF32(t, r)
+	if got, want := vs[0], float32(0); math.Abs(float64(got-want)) > 1e-5 {
+		t.Errorf("left: got: %f, want: %f", got, want)
+	}
+	if got, want := vs[1], float32(0.5); math.Abs(float64(got-want)) > 1e-5 {
+		t.Errorf("right: got: %f, want: %f", got, want)
+	}
+}
+
+func TestPannerHRTF(t *testing.T) {
+	setup()
+	defer teardown()
+
+	r := audio.NewPannerReaderForTesting(context, bytes.NewReader(constantF32(1024, 0.5)), &audio.PannerOptions{
+		HRTF: true,
+	}, -1, 0, 0)
+	vs := readF32(t, r)
+
+	// The sound to the right ear is delayed.
+	if vs[0] == 0 {
+		t.Errorf("left at the first frame: got: 0, want: non-zero")
+	}
+	if vs[1] != 0 {
+		t.Errorf("right at the first frame: got: %f, want: 0", vs[1])
+	}
+	// The sound to the left ear converges to the original sound.
+	if got, want := vs[len(vs)-2], float32(0.5); math.Abs(float64(got-want)) > 1e-3 {
+		t.Errorf("left at the last frame: got: %f, want: %f", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"sync"
+)
+
+// SetPitch shifts the pitch of the player's sound by the given semitones without changing the playback speed.
+// For example, 12 raises the pitch by one octave, and -12 lowers the pitch by one octave.
+// The default value is 0.
+//
+// The pitch is shifted by mixing two delayed sounds, so a small delay (about 50[ms]) and some artifacts are added.
+//
+// SetPitch is concurrent-safe. The pitch can be changed while the player is playing.
+func (p *Player) SetPitch(semitones float64) {
+	p.p.pitch.setSemitones(semitones)
+}
+
+// Pitch returns the semitones to shift the pitch.
+func (p *Player) Pitch() float64 {
+	return p.p.pitch.currentSemitones()
+}
+
+// pitchShifterWindow is the length of the delay to shift the pitch in seconds.
+const pitchShifterWindow = 0.05
+
+// pitchShifter is a processor to shift the pitch with two read heads on a delay line.
+//
+// The delay of each head changes linearly like a sawtooth, which changes the pitch by the Doppler effect.
+// The heads are half a window apart, and crossfaded so that the jumps of the delays are not heard.
+type pitchShifter struct {
+	sampleRate int
+	semitones  float64
+
+	// line is the delay line of interleaved samples.
+	line []float32
+	pos  int
+
+	// phase is the phase of the first head's delay in [0, 1).
+	phase float64
+
+	m sync.Mutex
+}
+
+func (p *pitchShifter) setSemitones(semitones float64) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.semitones = semitones
+}
+
+func (p *pitchShifter) currentSemitones() float64 {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.semitones
+}
+
+func (p *pitchShifter) active() bool {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.semitones != 0
+}
+
+func (p *pitchShifter) process(samples []float32) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	window := pitchShifterWindow * float64(p.sampleRate)
+	// The delay line must be longer than the window for the interpolation.
+	frames := int(window) + 2
+	if len(p.line) != frames*channelCount {
+		p.line = make([]float32, frames*channelCount)
+		p.pos = 0
+	}
+
+	ratio := math.Pow(2, p.semitones/12)
+	// When the pitch is higher, the delay decreases and the heads read the line faster than the source.
+	step := (1 - ratio) / window
+
+	for i := 0; i < len(samples)/channelCount; i++ {
+		for ch := 0; ch < channelCount; ch++ {
+			p.line[channelCount*p.pos+ch] = samples[channelCount*i+ch]
+		}
+
+		var outs [channelCount]float64
+		for _, phase := range []float64{p.phase, math.Mod(p.phase+0.5, 1)} {
+			// Triangular windows of the two heads, whose sum is always 1.
+			gain := 1 - math.Abs(2*phase-1)
+			delay := phase * window
+			pos := float64(p.pos) - delay
+			if pos < 0 {
+				pos += float64(frames)
+			}
+			i0 := int(pos)
+			i1 := (i0 + 1) % frames
+			t := pos - float64(i0)
+			for ch := 0; ch < channelCount; ch++ {
+				v := float64(p.line[channelCount*i0+ch])*(1-t) + float64(p.line[channelCount*i1+ch])*t
+				outs[ch] += gain * v
+			}
+		}
+		for ch := 0; ch < channelCount; ch++ {
+			samples[channelCount*i+ch] = float32(outs[ch])
+		}
+
+		p.pos = (p.pos + 1) % frames
+		p.phase = math.Mod(p.phase+step+1, 1)
+	}
+}
+
+func (p *pitchShifter) reset() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	for i := range p.line {
+		p.line[i] = 0
+	}
+	p.pos = 0
+	p.phase = 0
+}
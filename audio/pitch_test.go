@@ -0,0 +1,68 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// sineF32 returns a 32bit float stereo stream of a sine wave.
+func sineF32(frequency float64, sampleRate int, frames int) []byte {
+	b := make([]byte, 8*frames)
+	for i := 0; i < frames; i++ {
+		v := math.Float32bits(float32(math.Sin(2 * math.Pi * frequency * float64(i) / float64(sampleRate))))
+		binary.LittleEndian.PutUint32(b[8*i:], v)
+		binary.LittleEndian.PutUint32(b[8*i+4:], v)
+	}
+	return b
+}
+
+// zeroCrossings returns the number of the zero crossings of the left channel.
+func zeroCrossings(samples []float32) int {
+	var n int
+	for i := 2; i < len(samples); i += 2 {
+		if (samples[i-2] < 0) != (samples[i] < 0) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPitchShifter(t *testing.T) {
+	const (
+		sampleRate = 48000
+		frequency  = 440
+	)
+
+	for _, semitones := range []float64{-12, -5, 7, 12} {
+		r := audio.NewPitchShifterReaderForTesting(bytes.NewReader(sineF32(frequency, sampleRate, sampleRate)), sampleRate, semitones)
+		vs := readF32(t, r)
+		if got, want := len(vs), 2*sampleRate; got != want {
+			t.Fatalf("len(samples): got: %d, want: %d", got, want)
+		}
+
+		// Skip the first part including the initial delay.
+		got := float64(zeroCrossings(vs[sampleRate/5:])) / 2 / 0.9
+		want := frequency * math.Pow(2, semitones/12)
+		if math.Abs(got-want)/want > 0.05 {
+			t.Errorf("frequency with %f semitones: got: %f, want: %f", semitones, got, want)
+		}
+	}
+}
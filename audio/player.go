@@ -72,6 +72,9 @@ type playerImpl struct {
 	factory        *playerFactory
 	initBufferSize int
 	bytesPerSample int
+	pitch          pitchShifter
+	effects        effectChain
+	bus            *Bus
 
 	// adjustedPosition is the player's more accurate position.
 	// The underlying buffer might not be changed even if the player is playing.
@@ -93,7 +96,6 @@ func (f *playerFactory) newPlayer(context *Context, src io.Reader, seekable bool
 	defer f.m.Unlock()
 
 	p := &playerImpl{
-		src:            src,
 		seekable:       seekable,
 		srcIdent:       srcIdent,
 		context:        context,
@@ -101,6 +103,9 @@ func (f *playerFactory) newPlayer(context *Context, src io.Reader, seekable bool
 		lastSamples:    -1,
 		bytesPerSample: bitDepthInBytes * channelCount,
 	}
+	p.pitch.sampleRate = f.sampleRate
+	// Shift the pitch before the effects so that the effects like a reverb are not affected.
+	p.src = newProcessStream(newProcessStream(src, &p.pitch), &p.effects)
 	runtime.SetFinalizer(p, (*playerImpl).Close)
 	return p, nil
 }
@@ -176,7 +181,11 @@ func (p *playerImpl) ensurePlayer() error {
 		p.stream = s
 	}
 	if p.player == nil {
-		p.player = p.factory.context.NewPlayer(p.stream)
+		if p.bus != nil {
+			p.player = p.bus.newPlayer(p.factory, p.stream)
+		} else {
+			p.player = p.factory.context.NewPlayer(p.stream)
+		}
 		if p.initBufferSize != 0 {
 			p.player.SetBufferSize(p.initBufferSize)
 			p.initBufferSize = 0
@@ -268,6 +277,45 @@ func (p *playerImpl) Close() error {
 	return nil
 }
 
+func (p *playerImpl) currentBus() *Bus {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.bus
+}
+
+func (p *playerImpl) setBus(bus *Bus) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.bus == bus {
+		return
+	}
+	p.bus = bus
+
+	if p.player == nil {
+		return
+	}
+
+	// Recreate the underlying player for the new bus.
+	playing := p.player.IsPlaying()
+	volume := p.player.Volume()
+	p.player.Pause()
+	if err := p.player.Close(); err != nil {
+		p.context.setError(err)
+		return
+	}
+	p.player = nil
+
+	if err := p.ensurePlayer(); err != nil {
+		p.context.setError(err)
+		return
+	}
+	p.player.SetVolume(volume)
+	if playing {
+		p.player.Play()
+	}
+}
+
 func (p *playerImpl) Position() time.Duration {
 	p.m.Lock()
 	defer p.m.Unlock()
@@ -0,0 +1,97 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// processor processes 32bit float samples of 2 channels in place.
+type processor interface {
+	// process processes the interleaved samples.
+	process(samples []float32)
+
+	// reset resets the internal state like delay lines. reset is called when the stream is seeked.
+	reset()
+
+	// active reports whether process needs to be called.
+	// If active returns false, the samples are passed through without conversions.
+	active() bool
+}
+
+// processStream is a 32bit float stereo stream that processes the samples of a source stream with a processor.
+type processStream struct {
+	src       io.Reader
+	processor processor
+
+	// extra is the remainder in the case when the read byte sizes are not multiple of the frame size.
+	extra []byte
+
+	samples []float32
+}
+
+func newProcessStream(src io.Reader, processor processor) *processStream {
+	return &processStream{
+		src:       src,
+		processor: processor,
+	}
+}
+
+// Read is implementation of io.Reader's Read.
+func (s *processStream) Read(buf []byte) (int, error) {
+	const bytesPerFrame = bitDepthInBytesFloat32 * channelCount
+
+	if len(s.extra) == 0 && !s.processor.active() {
+		return s.src.Read(buf)
+	}
+
+	n := copy(buf, s.extra)
+	s.extra = s.extra[:0]
+	m, err := s.src.Read(buf[n:])
+	n += m
+
+	// Keep the remainder for the next read.
+	rem := n % bytesPerFrame
+	s.extra = append(s.extra, buf[n-rem:n]...)
+	n -= rem
+
+	if cap(s.samples) < n/bitDepthInBytesFloat32 {
+		s.samples = make([]float32, n/bitDepthInBytesFloat32)
+	}
+	samples := s.samples[:n/bitDepthInBytesFloat32]
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	s.processor.process(samples)
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+
+	return n, err
+}
+
+// Seek is implementation of io.Seeker's Seek.
+func (s *processStream) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := s.src.(io.Seeker)
+	if !ok {
+		return 0, fmt.Errorf("audio: the source must be io.Seeker when seeking but not")
+	}
+	s.extra = s.extra[:0]
+	s.processor.reset()
+	return seeker.Seek(offset, whence)
+}
@@ -0,0 +1,739 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"math"
+)
+
+// rampRate is the rate to change the gains per frame to avoid clicks.
+const rampRate = 0.01
+
+type channel struct {
+	instrument *instrument
+	sample     *sample
+
+	active   bool
+	pos      float64
+	backward bool
+
+	period       float64
+	targetPeriod float64
+
+	// volume is in [0, 64].
+	volume int
+
+	// panning is in [0, 255].
+	panning int
+
+	// channelVolume is in [0, 64].
+	channelVolume int
+
+	keyOn               bool
+	fading              bool
+	fadeoutVolume       float64
+	volumeEnvelopeTick  int
+	panningEnvelopeTick int
+
+	// cell is the cell of the current row.
+	cell cell
+
+	// delayTick is the tick to trigger the cell of the current row. -1 means no delay.
+	delayTick int
+
+	patternLoopRow   int
+	patternLoopCount int
+
+	// The effect memories.
+	arpeggio            byte
+	portaUp             byte
+	portaDown           byte
+	finePortaUp         byte
+	finePortaDown       byte
+	extraFinePortaUp    byte
+	extraFinePortaDown  byte
+	tonePortaSpeed      byte
+	volumeSlide         byte
+	fineVolumeSlideUp   byte
+	fineVolumeSlideDown byte
+	globalVolumeSlide   byte
+	panningSlide        byte
+	channelVolumeSlide  byte
+	sampleOffset        byte
+	retrigger           byte
+
+	vibratoSpeed    int
+	vibratoDepth    int
+	vibratoPos      int
+	vibratoWaveform int
+	tremoloSpeed    int
+	tremoloDepth    int
+	tremoloPos      int
+	tremoloWaveform int
+
+	// The modifiers of the current tick.
+	vibratoDelta   float64
+	tremoloDelta   int
+	arpeggioOffset int
+
+	// The outputs of the current tick.
+	step  float64
+	gainL float64
+	gainR float64
+
+	// The current gains that follow the output gains.
+	currentGainL float64
+	currentGainR float64
+}
+
+// memory returns the effect parameter, or the memorized parameter if the parameter is 0.
+// The memory is available only for XM and IT.
+func memory(m *module, mem *byte, param byte) byte {
+	if param != 0 || !m.effectMemory {
+		*mem = param
+		return param
+	}
+	return *mem
+}
+
+func (ch *channel) setVolume(v int) {
+	ch.volume = clampInt(v, 0, 64)
+}
+
+func (ch *channel) setPanning(v int) {
+	ch.panning = clampInt(v, 0, 255)
+}
+
+func (ch *channel) setPeriod(m *module, period float64) {
+	period = m.clampPeriod(period)
+	if !m.linear && period < 1 {
+		period = 1
+	}
+	ch.period = period
+}
+
+// startRow starts a new row.
+func (ch *channel) startRow(p *player, c cell) {
+	ch.cell = c
+	ch.delayTick = -1
+	ch.vibratoDelta = 0
+	ch.tremoloDelta = 0
+	ch.arpeggioOffset = 0
+
+	if c.effect == effectNoteDelay && c.param > 0 {
+		// A note delayed over the row is ignored.
+		if int(c.param) < p.speed {
+			ch.delayTick = int(c.param)
+		}
+		return
+	}
+	ch.trigger(p, c)
+}
+
+// trigger processes the note, the instrument, and the effects at the first tick of the cell.
+func (ch *channel) trigger(p *player, c cell) {
+	m := p.module
+
+	if c.instrument > 0 {
+		idx := int(c.instrument) - 1
+		if idx < len(m.instruments) {
+			ch.instrument = m.instruments[idx]
+		} else {
+			ch.instrument = nil
+		}
+	}
+
+	tonePorta := c.effect == effectTonePorta || c.effect == effectTonePortaVolumeSlide || c.volumeCommand == volumeTonePorta
+	var triggered bool
+
+	switch {
+	case c.note <= maxNote:
+		if ch.instrument == nil {
+			break
+		}
+		mapping := ch.instrument.samples[c.note]
+		if mapping.sample < 0 || mapping.sample >= len(m.samples) {
+			break
+		}
+		s := m.samples[mapping.sample]
+		period := m.periodFromFrequency(noteFrequency(mapping.note, s))
+		if tonePorta && ch.active && ch.sample != nil {
+			ch.targetPeriod = period
+			break
+		}
+
+		ch.sample = s
+		ch.period = period
+		ch.targetPeriod = period
+		ch.pos = 0
+		ch.backward = false
+		ch.active = len(s.data) > 0
+		ch.restartEnvelopes()
+		if ch.vibratoWaveform < 4 {
+			ch.vibratoPos = 0
+		}
+		if ch.tremoloWaveform < 4 {
+			ch.tremoloPos = 0
+		}
+		triggered = true
+
+		if c.effect == effectSampleOffset {
+			offset := int(memory(m, &ch.sampleOffset, c.param)) * 256
+			ch.pos = float64(offset)
+			if offset >= len(s.data) {
+				if s.loopType != loopNone {
+					ch.pos = float64(s.loopStart)
+				} else {
+					ch.active = false
+				}
+			}
+		}
+	case c.note == noteOff:
+		ch.keyOff(m)
+	case c.note == noteCut:
+		ch.active = false
+	case c.note == noteFade:
+		ch.fading = true
+	}
+
+	if c.instrument > 0 && ch.sample != nil {
+		ch.volume = ch.sample.volume
+		switch {
+		case ch.sample.panning >= 0:
+			ch.panning = ch.sample.panning
+		case ch.instrument != nil && ch.instrument.panning >= 0:
+			ch.panning = ch.instrument.panning
+		}
+		// An instrument without a note resets the envelopes.
+		if !triggered && c.note != noteOff {
+			ch.restartEnvelopes()
+		}
+	}
+
+	ch.processVolumeColumnAtFirstTick(m, c)
+	ch.processEffectAtFirstTick(p, c)
+}
+
+func (ch *channel) restartEnvelopes() {
+	ch.keyOn = true
+	ch.fading = false
+	ch.fadeoutVolume = 1
+	ch.volumeEnvelopeTick = 0
+	ch.panningEnvelopeTick = 0
+}
+
+func (ch *channel) keyOff(m *module) {
+	ch.keyOn = false
+	ch.fading = true
+	// Without the volume envelope, a key-off cuts the note in MOD and XM.
+	if !m.it && (ch.instrument == nil || !ch.instrument.volumeEnvelope.enabled) {
+		ch.volume = 0
+	}
+}
+
+func (ch *channel) processVolumeColumnAtFirstTick(m *module, c cell) {
+	param := int(c.volumeParam)
+	switch c.volumeCommand {
+	case volumeSet:
+		ch.setVolume(param)
+	case volumeFineSlideUp:
+		ch.setVolume(ch.volume + param)
+	case volumeFineSlideDown:
+		ch.setVolume(ch.volume - param)
+	case volumeVibratoSpeed:
+		if param > 0 {
+			ch.vibratoSpeed = param
+		}
+	case volumeVibratoDepth:
+		if param > 0 {
+			ch.vibratoDepth = param
+		}
+	case volumeSetPanning:
+		ch.setPanning(param)
+	case volumeTonePorta:
+		if param > 0 {
+			ch.tonePortaSpeed = byte(param)
+		}
+	}
+}
+
+func (ch *channel) processVolumeColumn(m *module, c cell) {
+	param := int(c.volumeParam)
+	switch c.volumeCommand {
+	case volumeSlideUp:
+		ch.setVolume(ch.volume + param)
+	case volumeSlideDown:
+		ch.setVolume(ch.volume - param)
+	case volumeVibratoDepth:
+		ch.doVibrato(m, false)
+	case volumePanningSlideLeft:
+		ch.setPanning(ch.panning - param)
+	case volumePanningSlideRight:
+		ch.setPanning(ch.panning + param)
+	case volumeTonePorta:
+		ch.doTonePorta(m)
+	case volumePortaUp:
+		ch.setPeriod(m, ch.period-m.slideUnits(param))
+	case volumePortaDown:
+		ch.setPeriod(m, ch.period+m.slideUnits(param))
+	}
+}
+
+func (ch *channel) processEffectAtFirstTick(p *player, c cell) {
+	m := p.module
+	param := c.param
+	switch c.effect {
+	case effectArpeggio:
+		memory(m, &ch.arpeggio, param)
+	case effectPortaUp, effectPortaDown:
+		mem := &ch.portaUp
+		if c.effect == effectPortaDown || m.it {
+			// IT shares the memory between Exx and Fxx.
+			mem = &ch.portaDown
+		}
+		v := memory(m, mem, param)
+		if !m.it {
+			break
+		}
+		// IT's fine and extra fine slides.
+		var units float64
+		switch v >> 4 {
+		case 0xf:
+			units = m.slideUnits(int(v & 0xf))
+		case 0xe:
+			units = m.extraFineSlideUnits(int(v & 0xf))
+		default:
+			return
+		}
+		if c.effect == effectPortaUp {
+			units = -units
+		}
+		ch.setPeriod(m, ch.period+units)
+	case effectFinePortaUp:
+		v := memory(m, &ch.finePortaUp, param)
+		ch.setPeriod(m, ch.period-m.slideUnits(int(v)))
+	case effectFinePortaDown:
+		v := memory(m, &ch.finePortaDown, param)
+		ch.setPeriod(m, ch.period+m.slideUnits(int(v)))
+	case effectExtraFinePortaUp:
+		v := memory(m, &ch.extraFinePortaUp, param)
+		ch.setPeriod(m, ch.period-m.extraFineSlideUnits(int(v)))
+	case effectExtraFinePortaDown:
+		v := memory(m, &ch.extraFinePortaDown, param)
+		ch.setPeriod(m, ch.period+m.extraFineSlideUnits(int(v)))
+	case effectTonePorta:
+		if param != 0 {
+			ch.tonePortaSpeed = param
+		}
+	case effectVibrato, effectFineVibrato:
+		if param>>4 != 0 {
+			ch.vibratoSpeed = int(param >> 4)
+		}
+		if param&0xf != 0 {
+			ch.vibratoDepth = int(param & 0xf)
+		}
+	case effectTremolo:
+		if param>>4 != 0 {
+			ch.tremoloSpeed = int(param >> 4)
+		}
+		if param&0xf != 0 {
+			ch.tremoloDepth = int(param & 0xf)
+		}
+	case effectVolumeSlide, effectTonePortaVolumeSlide, effectVibratoVolumeSlide:
+		v := memory(m, &ch.volumeSlide, param)
+		if !m.it {
+			break
+		}
+		// IT's fine volume slides.
+		if x, y := int(v>>4), int(v&0xf); y == 0xf && x != 0 {
+			ch.setVolume(ch.volume + x)
+		} else if x == 0xf && y != 0 {
+			ch.setVolume(ch.volume - y)
+		}
+	case effectSetPanning:
+		ch.setPanning(int(param))
+	case effectFineVolumeSlideUp:
+		v := memory(m, &ch.fineVolumeSlideUp, param)
+		ch.setVolume(ch.volume + int(v))
+	case effectFineVolumeSlideDown:
+		v := memory(m, &ch.fineVolumeSlideDown, param)
+		ch.setVolume(ch.volume - int(v))
+	case effectSetChannelVolume:
+		ch.channelVolume = clampInt(int(param), 0, 64)
+	case effectChannelVolumeSlide:
+		v := memory(m, &ch.channelVolumeSlide, param)
+		if x, y := int(v>>4), int(v&0xf); y == 0xf && x != 0 {
+			ch.channelVolume = clampInt(ch.channelVolume+x, 0, 64)
+		} else if x == 0xf && y != 0 {
+			ch.channelVolume = clampInt(ch.channelVolume-y, 0, 64)
+		}
+	case effectGlobalVolumeSlide:
+		v := memory(m, &ch.globalVolumeSlide, param)
+		if !m.it {
+			break
+		}
+		if x, y := int(v>>4), int(v&0xf); y == 0xf && x != 0 {
+			p.setGlobalVolume(p.globalVolume + x)
+		} else if x == 0xf && y != 0 {
+			p.setGlobalVolume(p.globalVolume - y)
+		}
+	case effectPanningSlide:
+		v := memory(m, &ch.panningSlide, param)
+		if !m.it {
+			break
+		}
+		if x, y := int(v>>4), int(v&0xf); y == 0xf && x != 0 {
+			ch.setPanning(ch.panning - x*4)
+		} else if x == 0xf && y != 0 {
+			ch.setPanning(ch.panning + y*4)
+		}
+	case effectRetrigger:
+		memory(m, &ch.retrigger, param)
+	case effectNoteCut:
+		if param == 0 {
+			ch.cut(m)
+		}
+	case effectKeyOff:
+		if param == 0 {
+			ch.keyOff(m)
+		}
+	case effectSetVibratoWaveform:
+		ch.vibratoWaveform = int(param)
+	case effectSetTremoloWaveform:
+		ch.tremoloWaveform = int(param)
+	}
+}
+
+// processTick processes the effects at a tick other than the first tick of a row.
+func (ch *channel) processTick(p *player, tick int) {
+	if ch.delayTick >= 0 {
+		if tick == ch.delayTick {
+			ch.delayTick = -1
+			ch.trigger(p, ch.cell)
+		}
+		return
+	}
+
+	m := p.module
+	c := ch.cell
+	ch.vibratoDelta = 0
+	ch.tremoloDelta = 0
+	ch.arpeggioOffset = 0
+
+	ch.processVolumeColumn(m, c)
+
+	switch c.effect {
+	case effectArpeggio:
+		switch v := ch.arpeggio; tick % 3 {
+		case 1:
+			ch.arpeggioOffset = int(v >> 4)
+		case 2:
+			ch.arpeggioOffset = int(v & 0xf)
+		}
+	case effectPortaUp, effectPortaDown:
+		v := ch.portaUp
+		if c.effect == effectPortaDown || m.it {
+			v = ch.portaDown
+		}
+		if m.it && v>>4 >= 0xe {
+			// Fine slides are processed only at the first tick.
+			break
+		}
+		units := m.slideUnits(int(v))
+		if c.effect == effectPortaUp {
+			units = -units
+		}
+		ch.setPeriod(m, ch.period+units)
+	case effectTonePorta:
+		ch.doTonePorta(m)
+	case effectVibrato:
+		ch.doVibrato(m, false)
+	case effectFineVibrato:
+		ch.doVibrato(m, true)
+	case effectTonePortaVolumeSlide:
+		ch.doTonePorta(m)
+		ch.doVolumeSlide(m)
+	case effectVibratoVolumeSlide:
+		ch.doVibrato(m, false)
+		ch.doVolumeSlide(m)
+	case effectTremolo:
+		ch.tremoloPos += ch.tremoloSpeed
+		ch.tremoloDelta = waveValue(ch.tremoloWaveform, ch.tremoloPos) * ch.tremoloDepth / 64
+	case effectVolumeSlide:
+		ch.doVolumeSlide(m)
+	case effectRetrigger:
+		ch.doRetrigger(tick)
+	case effectNoteCut:
+		if tick == int(c.param) {
+			ch.cut(m)
+		}
+	case effectKeyOff:
+		if tick == int(c.param) {
+			ch.keyOff(m)
+		}
+	case effectGlobalVolumeSlide:
+		x, y := int(ch.globalVolumeSlide>>4), int(ch.globalVolumeSlide&0xf)
+		if m.it && (x == 0xf && y != 0 || y == 0xf && x != 0) {
+			break
+		}
+		// XM's global volume is in [0, 64].
+		scale := 2
+		if m.it {
+			scale = 1
+		}
+		if x != 0 {
+			p.setGlobalVolume(p.globalVolume + x*scale)
+		} else {
+			p.setGlobalVolume(p.globalVolume - y*scale)
+		}
+	case effectPanningSlide:
+		x, y := int(ch.panningSlide>>4), int(ch.panningSlide&0xf)
+		if m.it {
+			if x == 0xf && y != 0 || y == 0xf && x != 0 {
+				break
+			}
+			// IT's Px0 slides to the left, and P0x slides to the right.
+			if x != 0 {
+				ch.setPanning(ch.panning - x*4)
+			} else {
+				ch.setPanning(ch.panning + y*4)
+			}
+			break
+		}
+		if x != 0 {
+			ch.setPanning(ch.panning + x)
+		} else {
+			ch.setPanning(ch.panning - y)
+		}
+	case effectChannelVolumeSlide:
+		x, y := int(ch.channelVolumeSlide>>4), int(ch.channelVolumeSlide&0xf)
+		if x == 0xf && y != 0 || y == 0xf && x != 0 {
+			break
+		}
+		if x != 0 {
+			ch.channelVolume = clampInt(ch.channelVolume+x, 0, 64)
+		} else {
+			ch.channelVolume = clampInt(ch.channelVolume-y, 0, 64)
+		}
+	}
+}
+
+func (ch *channel) cut(m *module) {
+	if m.it {
+		ch.active = false
+		return
+	}
+	ch.volume = 0
+}
+
+func (ch *channel) doTonePorta(m *module) {
+	speed := m.slideUnits(int(ch.tonePortaSpeed))
+	if ch.period < ch.targetPeriod {
+		ch.period = math.Min(ch.period+speed, ch.targetPeriod)
+	} else if ch.period > ch.targetPeriod {
+		ch.period = math.Max(ch.period-speed, ch.targetPeriod)
+	}
+}
+
+func (ch *channel) doVibrato(m *module, fine bool) {
+	ch.vibratoPos += ch.vibratoSpeed
+	delta := float64(waveValue(ch.vibratoWaveform, ch.vibratoPos) * ch.vibratoDepth)
+	if m.linear {
+		delta /= 32
+	} else {
+		delta /= 128
+	}
+	if fine {
+		delta /= 4
+	}
+	if m.fineVibrato {
+		delta /= 2
+	}
+	ch.vibratoDelta = delta
+}
+
+func (ch *channel) doVolumeSlide(m *module) {
+	x, y := int(ch.volumeSlide>>4), int(ch.volumeSlide&0xf)
+	if m.it && (x == 0xf && y != 0 || y == 0xf && x != 0) {
+		// Fine slides are processed only at the first tick.
+		return
+	}
+	if x != 0 {
+		ch.setVolume(ch.volume + x)
+	} else {
+		ch.setVolume(ch.volume - y)
+	}
+}
+
+func (ch *channel) doRetrigger(tick int) {
+	x, interval := int(ch.retrigger>>4), int(ch.retrigger&0xf)
+	if interval == 0 || tick%interval != 0 {
+		return
+	}
+	ch.pos = 0
+	ch.backward = false
+	if ch.sample != nil && len(ch.sample.data) > 0 {
+		ch.active = true
+	}
+
+	switch x {
+	case 0x1, 0x2, 0x3, 0x4, 0x5:
+		ch.setVolume(ch.volume - 1<<(x-1))
+	case 0x6:
+		ch.setVolume(ch.volume * 2 / 3)
+	case 0x7:
+		ch.setVolume(ch.volume / 2)
+	case 0x9, 0xa, 0xb, 0xc, 0xd:
+		ch.setVolume(ch.volume + 1<<(x-9))
+	case 0xe:
+		ch.setVolume(ch.volume * 3 / 2)
+	case 0xf:
+		ch.setVolume(ch.volume * 2)
+	}
+}
+
+// updateOutput calculates the output of the current tick and advances the envelopes.
+func (ch *channel) updateOutput(p *player) {
+	if !ch.active || ch.sample == nil {
+		ch.step = 0
+		ch.gainL = 0
+		ch.gainR = 0
+		return
+	}
+
+	m := p.module
+	freq := m.frequencyFromPeriod(m.clampPeriod(ch.period + ch.vibratoDelta))
+	if ch.arpeggioOffset != 0 {
+		freq *= math.Exp2(float64(ch.arpeggioOffset) / 12)
+	}
+	ch.step = freq / float64(p.sampleRate)
+
+	vol := float64(clampInt(ch.volume+ch.tremoloDelta, 0, 64)) / 64
+	pan := float64(ch.panning)
+	if inst := ch.instrument; inst != nil {
+		if inst.volumeEnvelope.enabled {
+			vol *= inst.volumeEnvelope.valueAt(ch.volumeEnvelopeTick) / 64
+		}
+		vol *= float64(inst.globalVolume) / 128
+		if inst.panningEnvelope.enabled {
+			// The panning envelope is in [-32, 32].
+			e := inst.panningEnvelope.valueAt(ch.panningEnvelopeTick)
+			pan += e / 32 * (128 - math.Abs(pan-128))
+		}
+
+		ch.volumeEnvelopeTick = ch.advanceEnvelope(&inst.volumeEnvelope, ch.volumeEnvelopeTick)
+		ch.panningEnvelopeTick = ch.advanceEnvelope(&inst.panningEnvelope, ch.panningEnvelopeTick)
+		if ch.fading {
+			vol *= ch.fadeoutVolume
+			ch.fadeoutVolume = math.Max(ch.fadeoutVolume-inst.fadeout, 0)
+		}
+	}
+	vol *= float64(p.globalVolume) / 128
+	vol *= float64(ch.sample.globalVolume) / 64
+	vol *= float64(ch.channelVolume) / 64
+	vol *= m.mixGain
+
+	l, r := panGains(pan)
+	ch.gainL = vol * l
+	ch.gainR = vol * r
+}
+
+func (ch *channel) advanceEnvelope(e *envelope, tick int) int {
+	if !e.enabled {
+		return tick
+	}
+	tick++
+	switch {
+	case e.sustain && ch.keyOn:
+		if tick > e.points[e.sustainEnd].tick {
+			tick = e.points[e.sustainStart].tick
+		}
+	case e.loop:
+		if tick > e.points[e.loopEnd].tick {
+			tick = e.points[e.loopStart].tick
+		}
+	}
+	return tick
+}
+
+// mix adds the frames of the channel to dst in 2 channels.
+// If dst is nil, mix only advances the position.
+func (ch *channel) mix(dst []float32, frames int, sampleRate int) {
+	if !ch.active || ch.sample == nil || ch.step == 0 {
+		ch.currentGainL = 0
+		ch.currentGainR = 0
+		return
+	}
+
+	if dst == nil {
+		ch.currentGainL = ch.gainL
+		ch.currentGainR = ch.gainR
+	}
+
+	s := ch.sample
+	data := s.data
+	loop, loopStart, loopEnd := s.loopType, s.loopStart, s.loopEnd
+	if s.sustainLoopType != loopNone && ch.keyOn {
+		loop, loopStart, loopEnd = s.sustainLoopType, s.sustainLoopStart, s.sustainLoopEnd
+	}
+
+	for i := 0; i < frames; i++ {
+		idx := int(ch.pos)
+		if idx >= len(data) || idx < 0 {
+			ch.active = false
+			return
+		}
+
+		if dst != nil {
+			v0 := data[idx]
+			v1 := v0
+			if idx+1 < len(data) {
+				v1 = data[idx+1]
+			}
+			v := float64(v0) + float64(v1-v0)*(ch.pos-float64(idx))
+			ch.currentGainL += (ch.gainL - ch.currentGainL) * rampRate
+			ch.currentGainR += (ch.gainR - ch.currentGainR) * rampRate
+			dst[2*i] += float32(v * ch.currentGainL)
+			dst[2*i+1] += float32(v * ch.currentGainR)
+		}
+
+		if ch.backward {
+			ch.pos -= ch.step
+			if ch.pos < float64(loopStart) {
+				ch.pos = math.Min(2*float64(loopStart)-ch.pos, float64(loopEnd-1))
+				ch.backward = false
+			}
+			continue
+		}
+
+		ch.pos += ch.step
+		switch loop {
+		case loopForward:
+			if ch.pos >= float64(loopEnd) {
+				ch.pos = float64(loopStart) + math.Mod(ch.pos-float64(loopStart), float64(loopEnd-loopStart))
+			}
+		case loopPingPong:
+			if ch.pos >= float64(loopEnd-1) {
+				ch.pos = math.Max(2*float64(loopEnd-1)-ch.pos, float64(loopStart))
+				ch.backward = true
+			}
+		default:
+			if ch.pos >= float64(len(data)) {
+				ch.active = false
+				return
+			}
+		}
+	}
+}
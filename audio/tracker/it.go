@@ -0,0 +1,710 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+func isIT(data []byte) bool {
+	return len(data) >= 0xc0 && string(data[:4]) == "IMPM"
+}
+
+const (
+	itOrderSkip = -2
+	itOrderEnd  = -1
+)
+
+func loadIT(data []byte) (*module, error) {
+	if !isIT(data) {
+		return nil, fmt.Errorf("tracker: invalid IT header")
+	}
+	r := &byteReader{data: data}
+
+	r.pos = 0x20
+	orderCount := int(r.uint16())
+	instrumentCount := int(r.uint16())
+	sampleCount := int(r.uint16())
+	patternCount := int(r.uint16())
+	r.uint16() // Created with tracker version
+	compatibleVersion := r.uint16()
+	flags := r.uint16()
+	r.uint16() // Special
+	globalVolume := int(r.uint8())
+	mixVolume := int(r.uint8())
+	speed := int(r.uint8())
+	tempo := int(r.uint8())
+
+	r.pos = 0x40
+	pannings := r.bytes(64)
+	volumes := r.bytes(64)
+	orders := r.bytes(orderCount)
+	instrumentOffsets := make([]int, instrumentCount)
+	for i := range instrumentOffsets {
+		instrumentOffsets[i] = int(r.uint32())
+	}
+	sampleOffsets := make([]int, sampleCount)
+	for i := range sampleOffsets {
+		sampleOffsets[i] = int(r.uint32())
+	}
+	patternOffsets := make([]int, patternCount)
+	for i := range patternOffsets {
+		patternOffsets[i] = int(r.uint32())
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	useInstruments := flags&0x04 != 0
+	if useInstruments && compatibleVersion < 0x200 {
+		return nil, fmt.Errorf("tracker: IT instruments of the old format are not supported")
+	}
+	if globalVolume > 128 {
+		globalVolume = 128
+	}
+	if mixVolume > 128 {
+		mixVolume = 128
+	}
+
+	m := &module{
+		title:               trimString(data[4:30]),
+		initialSpeed:        speed,
+		initialTempo:        tempo,
+		initialGlobalVolume: globalVolume,
+		linear:              flags&0x08 != 0,
+		fineVibrato:         flags&0x10 == 0,
+		effectMemory:        true,
+		it:                  true,
+	}
+	if m.initialSpeed == 0 {
+		m.initialSpeed = 6
+	}
+	if m.initialTempo < 0x20 {
+		m.initialTempo = 125
+	}
+	for _, o := range orders {
+		switch o {
+		case 0xfe:
+			m.orders = append(m.orders, itOrderSkip)
+		case 0xff:
+			m.orders = append(m.orders, itOrderEnd)
+		default:
+			m.orders = append(m.orders, int(o))
+		}
+	}
+
+	for i, offset := range patternOffsets {
+		p, err := loadITPattern(data, offset)
+		if err != nil {
+			return nil, fmt.Errorf("tracker: invalid IT pattern %d: %w", i, err)
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	for _, p := range m.patterns {
+		for _, row := range p.rows {
+			if len(row) > m.channelCount {
+				m.channelCount = len(row)
+			}
+		}
+	}
+	if m.channelCount == 0 {
+		m.channelCount = 1
+	}
+	// Make all the rows have the same number of channels.
+	for _, p := range m.patterns {
+		for i, row := range p.rows {
+			for len(row) < m.channelCount {
+				row = append(row, cell{note: noteNone})
+			}
+			p.rows[i] = row
+		}
+	}
+
+	for ch := 0; ch < m.channelCount; ch++ {
+		pan := int(pannings[ch])
+		vol := int(volumes[ch])
+		if vol > 64 {
+			vol = 64
+		}
+		switch {
+		case pan >= 128:
+			// A disabled channel
+			pan = 0x80
+			vol = 0
+		case pan > 64 || flags&0x01 == 0:
+			// Surround or mono
+			pan = 0x80
+		default:
+			pan *= 4
+			if pan > 0xff {
+				pan = 0xff
+			}
+		}
+		m.initialPannings = append(m.initialPannings, pan)
+		m.initialChannelVolumes = append(m.initialChannelVolumes, vol)
+	}
+
+	for i, offset := range sampleOffsets {
+		s, err := loadITSample(data, offset)
+		if err != nil {
+			return nil, fmt.Errorf("tracker: invalid IT sample %d: %w", i, err)
+		}
+		m.samples = append(m.samples, s)
+	}
+
+	if useInstruments {
+		for i, offset := range instrumentOffsets {
+			inst, err := loadITInstrument(data, offset, len(m.samples))
+			if err != nil {
+				return nil, fmt.Errorf("tracker: invalid IT instrument %d: %w", i, err)
+			}
+			m.instruments = append(m.instruments, inst)
+		}
+	} else {
+		for i, s := range m.samples {
+			m.instruments = append(m.instruments, sampleInstrument(i, s.panning))
+		}
+	}
+
+	m.mixGain = defaultMixGain(m.channelCount) * float64(mixVolume) / 64
+	return m, nil
+}
+
+func loadITPattern(data []byte, offset int) (*pattern, error) {
+	// An offset 0 means an empty pattern with 64 rows.
+	if offset == 0 {
+		return &pattern{
+			rows: make([][]cell, 64),
+		}, nil
+	}
+
+	r := &byteReader{data: data, pos: offset}
+	size := int(r.uint16())
+	rowCount := int(r.uint16())
+	r.bytes(4)
+	pr := &byteReader{data: r.bytes(size)}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	p := &pattern{
+		rows: make([][]cell, rowCount),
+	}
+	var lastMasks [64]byte
+	var lastCells [64]cell
+	for i := range lastCells {
+		lastCells[i].note = noteNone
+	}
+	for row := 0; row < rowCount; row++ {
+		for {
+			channelVariable := pr.uint8()
+			if pr.err != nil {
+				return nil, pr.err
+			}
+			if channelVariable == 0 {
+				break
+			}
+			ch := int(channelVariable-1) & 63
+			mask := lastMasks[ch]
+			if channelVariable&0x80 != 0 {
+				mask = pr.uint8()
+				lastMasks[ch] = mask
+			}
+
+			c := cell{
+				note: noteNone,
+			}
+			last := &lastCells[ch]
+			if mask&0x01 != 0 {
+				last.note = convertITNote(pr.uint8())
+				c.note = last.note
+			}
+			if mask&0x02 != 0 {
+				last.instrument = pr.uint8()
+				c.instrument = last.instrument
+			}
+			if mask&0x04 != 0 {
+				last.volumeCommand, last.volumeParam = convertITVolume(pr.uint8())
+				c.volumeCommand, c.volumeParam = last.volumeCommand, last.volumeParam
+			}
+			if mask&0x08 != 0 {
+				command := pr.uint8()
+				param := pr.uint8()
+				last.effect, last.param = convertITEffect(command, param)
+				c.effect, c.param = last.effect, last.param
+			}
+			if mask&0x10 != 0 {
+				c.note = last.note
+			}
+			if mask&0x20 != 0 {
+				c.instrument = last.instrument
+			}
+			if mask&0x40 != 0 {
+				c.volumeCommand, c.volumeParam = last.volumeCommand, last.volumeParam
+			}
+			if mask&0x80 != 0 {
+				c.effect, c.param = last.effect, last.param
+			}
+
+			for len(p.rows[row]) <= ch {
+				p.rows[row] = append(p.rows[row], cell{note: noteNone})
+			}
+			p.rows[row][ch] = c
+		}
+	}
+	if pr.err != nil {
+		return nil, pr.err
+	}
+	return p, nil
+}
+
+func convertITNote(n byte) byte {
+	switch {
+	case n <= maxNote:
+		return n
+	case n == 0xff:
+		return noteOff
+	case n == 0xfe:
+		return noteCut
+	default:
+		return noteFade
+	}
+}
+
+// convertITVolume converts a value in the IT volume column.
+func convertITVolume(v byte) (volumeCommand, byte) {
+	switch {
+	case v <= 64:
+		return volumeSet, v
+	case v <= 74:
+		return volumeFineSlideUp, v - 65
+	case v <= 84:
+		return volumeFineSlideDown, v - 75
+	case v <= 94:
+		return volumeSlideUp, v - 85
+	case v <= 104:
+		return volumeSlideDown, v - 95
+	case v <= 114:
+		return volumePortaDown, (v - 105) * 4
+	case v <= 124:
+		return volumePortaUp, (v - 115) * 4
+	case v >= 128 && v <= 192:
+		p := int(v-128) * 4
+		if p > 0xff {
+			p = 0xff
+		}
+		return volumeSetPanning, byte(p)
+	case v >= 193 && v <= 202:
+		return volumeTonePorta, []byte{0x00, 0x01, 0x04, 0x08, 0x10, 0x20, 0x40, 0x60, 0x80, 0xff}[v-193]
+	case v >= 203 && v <= 212:
+		return volumeVibratoDepth, v - 203
+	}
+	return volumeNone, 0
+}
+
+// convertITEffect converts an IT effect to an effect.
+func convertITEffect(command, param byte) (effect, byte) {
+	x, y := param>>4, param&0x0f
+	switch command {
+	case 'A' - '@':
+		if param == 0 {
+			return effectNone, 0
+		}
+		return effectSetSpeed, param
+	case 'B' - '@':
+		return effectPositionJump, param
+	case 'C' - '@':
+		return effectPatternBreak, param
+	case 'D' - '@':
+		return effectVolumeSlide, param
+	case 'E' - '@':
+		return effectPortaDown, param
+	case 'F' - '@':
+		return effectPortaUp, param
+	case 'G' - '@':
+		return effectTonePorta, param
+	case 'H' - '@':
+		return effectVibrato, param
+	case 'J' - '@':
+		return effectArpeggio, param
+	case 'K' - '@':
+		return effectVibratoVolumeSlide, param
+	case 'L' - '@':
+		return effectTonePortaVolumeSlide, param
+	case 'M' - '@':
+		if param > 64 {
+			param = 64
+		}
+		return effectSetChannelVolume, param
+	case 'N' - '@':
+		return effectChannelVolumeSlide, param
+	case 'O' - '@':
+		return effectSampleOffset, param
+	case 'P' - '@':
+		return effectPanningSlide, param
+	case 'Q' - '@':
+		return effectRetrigger, param
+	case 'R' - '@':
+		return effectTremolo, param
+	case 'S' - '@':
+		switch x {
+		case 0x3:
+			return effectSetVibratoWaveform, y
+		case 0x4:
+			return effectSetTremoloWaveform, y
+		case 0x8:
+			return effectSetPanning, y * 0x11
+		case 0xb:
+			return effectPatternLoop, y
+		case 0xc:
+			return effectNoteCut, y
+		case 0xd:
+			return effectNoteDelay, y
+		case 0xe:
+			return effectPatternDelay, y
+		}
+	case 'T' - '@':
+		if param < 0x20 {
+			// Tempo slides are not supported.
+			return effectNone, 0
+		}
+		return effectSetTempo, param
+	case 'U' - '@':
+		return effectFineVibrato, param
+	case 'V' - '@':
+		if param > 128 {
+			param = 128
+		}
+		return effectSetGlobalVolume, param
+	case 'W' - '@':
+		return effectGlobalVolumeSlide, param
+	case 'X' - '@':
+		return effectSetPanning, param
+	}
+	return effectNone, 0
+}
+
+func loadITSample(data []byte, offset int) (*sample, error) {
+	r := &byteReader{data: data, pos: offset}
+	if string(r.bytes(4)) != "IMPS" {
+		return nil, fmt.Errorf("'IMPS' not found")
+	}
+	r.bytes(13) // File name
+	globalVolume := int(r.uint8())
+	flags := r.uint8()
+	volume := int(r.uint8())
+	r.bytes(26) // Name
+	convert := r.uint8()
+	panning := r.uint8()
+	length := int(r.uint32())
+	loopStart := int(r.uint32())
+	loopEnd := int(r.uint32())
+	c5Speed := int(r.uint32())
+	sustainLoopStart := int(r.uint32())
+	sustainLoopEnd := int(r.uint32())
+	pointer := int(r.uint32())
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	s := &sample{
+		volume:           volume,
+		globalVolume:     globalVolume,
+		panning:          -1,
+		c5Speed:          float64(c5Speed),
+		loopStart:        loopStart,
+		loopEnd:          loopEnd,
+		sustainLoopStart: sustainLoopStart,
+		sustainLoopEnd:   sustainLoopEnd,
+	}
+	if s.volume > 64 {
+		s.volume = 64
+	}
+	if s.globalVolume > 64 {
+		s.globalVolume = 64
+	}
+	if s.c5Speed == 0 {
+		s.c5Speed = 8363
+	}
+	if panning&0x80 != 0 {
+		p := int(panning&0x7f) * 4
+		if p > 0xff {
+			p = 0xff
+		}
+		s.panning = p
+	}
+	if flags&0x10 != 0 {
+		s.loopType = loopForward
+		if flags&0x40 != 0 {
+			s.loopType = loopPingPong
+		}
+	}
+	if flags&0x20 != 0 {
+		s.sustainLoopType = loopForward
+		if flags&0x80 != 0 {
+			s.sustainLoopType = loopPingPong
+		}
+	}
+
+	if flags&0x01 == 0 || length == 0 {
+		return s, nil
+	}
+
+	sixteen := flags&0x02 != 0
+	channelCount := 1
+	if flags&0x04 != 0 {
+		channelCount = 2
+	}
+	r.pos = pointer
+	channels := make([][]float32, channelCount)
+	for ch := range channels {
+		if flags&0x08 != 0 {
+			// IT 2.15 compression uses the double delta.
+			channels[ch] = decompressITSample(r, length, sixteen, convert&0x04 != 0)
+			continue
+		}
+		channels[ch] = make([]float32, length)
+		if sixteen {
+			bs := r.bytes(2 * length)
+			for i := range channels[ch] {
+				v := binary.LittleEndian.Uint16(bs[2*i:])
+				if convert&0x01 == 0 {
+					v ^= 0x8000
+				}
+				channels[ch][i] = float32(int16(v)) / (1 << 15)
+			}
+		} else {
+			bs := r.bytes(length)
+			for i := range channels[ch] {
+				v := bs[i]
+				if convert&0x01 == 0 {
+					v ^= 0x80
+				}
+				channels[ch][i] = float32(int8(v)) / (1 << 7)
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	s.data = channels[0]
+	if channelCount == 2 {
+		for i := range s.data {
+			s.data[i] = (s.data[i] + channels[1][i]) / 2
+		}
+	}
+	fixLoop(s)
+	return s, nil
+}
+
+// decompressITSample decompresses a sample compressed with IT 2.14 or IT 2.15.
+func decompressITSample(r *byteReader, length int, sixteen bool, it215 bool) []float32 {
+	samples := make([]float32, 0, length)
+
+	blockLength := 0x8000
+	defaultWidth := 9
+	if sixteen {
+		blockLength = 0x4000
+		defaultWidth = 17
+	}
+
+	for len(samples) < length && r.err == nil {
+		size := int(r.uint16())
+		br := &lsbBitReader{data: r.bytes(size)}
+
+		n := length - len(samples)
+		if n > blockLength {
+			n = blockLength
+		}
+
+		width := defaultWidth
+		var d1, d2 int32
+		for i := 0; i < n && !br.eof; {
+			v := br.readBits(width)
+
+			switch {
+			case width < 7:
+				// Method 1: 1 to 6 bits
+				if v == 1<<(width-1) {
+					if sixteen {
+						v = br.readBits(4) + 1
+					} else {
+						v = br.readBits(3) + 1
+					}
+					if v >= width {
+						v++
+					}
+					width = v
+					continue
+				}
+			case width < defaultWidth:
+				// Method 2: 7 to 8 (or 16) bits
+				border := (0xff>>(9-width) - 4)
+				rng := 8
+				if sixteen {
+					border = (0xffff>>(17-width) - 8)
+					rng = 16
+				}
+				if v > border && v <= border+rng {
+					v -= border
+					if v >= width {
+						v++
+					}
+					width = v
+					continue
+				}
+			default:
+				// Method 3: 9 (or 17) bits
+				if v&(1<<(defaultWidth-1)) != 0 {
+					width = (v + 1) & 0xff
+					continue
+				}
+			}
+
+			// Sign-extend the value.
+			var d int32
+			bits := 8
+			if sixteen {
+				bits = 16
+			}
+			if width < bits {
+				shift := 32 - width
+				d = int32(uint32(v)<<shift) >> shift
+			} else {
+				shift := 32 - bits
+				d = int32(uint32(v)<<shift) >> shift
+			}
+			d1 += d
+			d2 += d1
+			out := d1
+			if it215 {
+				out = d2
+			}
+			if sixteen {
+				samples = append(samples, float32(int16(out))/(1<<15))
+			} else {
+				samples = append(samples, float32(int8(out))/(1<<7))
+			}
+			i++
+		}
+	}
+	for len(samples) < length {
+		samples = append(samples, 0)
+	}
+	return samples
+}
+
+// lsbBitReader reads bits from the least significant bit.
+type lsbBitReader struct {
+	data []byte
+	pos  int
+	x    uint32
+	n    int
+	eof  bool
+}
+
+func (b *lsbBitReader) readBits(n int) int {
+	for b.n < n {
+		if b.pos >= len(b.data) {
+			b.eof = true
+			return 0
+		}
+		b.x |= uint32(b.data[b.pos]) << b.n
+		b.pos++
+		b.n += 8
+	}
+	v := b.x & (1<<n - 1)
+	b.x >>= n
+	b.n -= n
+	return int(v)
+}
+
+func loadITInstrument(data []byte, offset int, sampleCount int) (*instrument, error) {
+	r := &byteReader{data: data, pos: offset}
+	if string(r.bytes(4)) != "IMPI" {
+		return nil, fmt.Errorf("'IMPI' not found")
+	}
+	r.bytes(13) // File name
+	r.bytes(3)  // NNA, DCT, and DCA
+	fadeout := int(r.uint16())
+	r.bytes(2) // Pitch-pan separation and center
+	globalVolume := int(r.uint8())
+	panning := r.uint8()
+	r.bytes(2)  // Random volume and panning
+	r.bytes(4)  // Tracker version, number of samples, and reserved
+	r.bytes(26) // Name
+	r.bytes(6)  // Filter and MIDI
+	keyboard := r.bytes(240)
+	volumeEnvelope := r.bytes(82)
+	panningEnvelope := r.bytes(82)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	inst := &instrument{
+		fadeout:      float64(fadeout) / 1024,
+		globalVolume: globalVolume,
+		panning:      -1,
+	}
+	if inst.globalVolume > 128 {
+		inst.globalVolume = 128
+	}
+	if panning&0x80 == 0 {
+		p := int(panning) * 4
+		if p > 0xff {
+			p = 0xff
+		}
+		inst.panning = p
+	}
+	for n := range inst.samples {
+		note := int(keyboard[2*n])
+		s := int(keyboard[2*n+1]) - 1
+		if s >= sampleCount || note > maxNote {
+			s = -1
+		}
+		inst.samples[n].sample = s
+		inst.samples[n].note = note
+	}
+	inst.volumeEnvelope = itEnvelope(volumeEnvelope)
+	inst.panningEnvelope = itEnvelope(panningEnvelope)
+	return inst, nil
+}
+
+func itEnvelope(data []byte) envelope {
+	flags := data[0]
+	count := int(data[1])
+	if count > 25 {
+		count = 25
+	}
+	e := envelope{
+		enabled:      flags&0x01 != 0 && count > 0,
+		loop:         flags&0x02 != 0,
+		sustain:      flags&0x04 != 0,
+		loopStart:    int(data[2]),
+		loopEnd:      int(data[3]),
+		sustainStart: int(data[4]),
+		sustainEnd:   int(data[5]),
+	}
+	for i := 0; i < count; i++ {
+		e.points = append(e.points, envelopePoint{
+			value: int(int8(data[6+3*i])),
+			tick:  int(binary.LittleEndian.Uint16(data[6+3*i+1:])),
+		})
+	}
+	validateEnvelope(&e)
+	return e
+}
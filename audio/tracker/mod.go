@@ -0,0 +1,301 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func isMOD(data []byte) bool {
+	_, ok := modChannelCount(data)
+	return ok
+}
+
+func modChannelCount(data []byte) (int, bool) {
+	if len(data) < 1084 {
+		return 0, false
+	}
+	sig := string(data[1080:1084])
+	switch sig {
+	case "M.K.", "M!K!", "M&K!", "FLT4", "4CHN", "N.T.":
+		return 4, true
+	case "FLT8", "OKTA", "OCTA", "CD81":
+		return 8, true
+	}
+	if strings.HasSuffix(sig, "CHN") {
+		if n, err := strconv.Atoi(sig[:1]); err == nil && n > 0 {
+			return n, true
+		}
+	}
+	if strings.HasSuffix(sig, "CH") || strings.HasSuffix(sig, "CN") {
+		if n, err := strconv.Atoi(sig[:2]); err == nil && n > 0 && n <= 32 {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// modNoteFromPeriod returns the note for an Amiga period in a MOD pattern.
+// The period 428 (C-2 in ProTracker) is C-5.
+func modNoteFromPeriod(period int) byte {
+	n := int(math.Round(noteC5 - 12*math.Log2(float64(period)/428)))
+	if n < 0 {
+		return 0
+	}
+	if n > maxNote {
+		return maxNote
+	}
+	return byte(n)
+}
+
+func loadMOD(data []byte) (*module, error) {
+	channelCount, ok := modChannelCount(data)
+	if !ok {
+		return nil, fmt.Errorf("tracker: invalid MOD header")
+	}
+
+	m := &module{
+		title:               trimString(data[:20]),
+		channelCount:        channelCount,
+		initialSpeed:        6,
+		initialTempo:        125,
+		initialGlobalVolume: 128,
+		amigaLimits:         channelCount == 4,
+	}
+
+	const sampleCount = 31
+	type sampleHeader struct {
+		length    int
+		finetune  int
+		volume    int
+		loopStart int
+		loopLen   int
+	}
+	headers := make([]sampleHeader, sampleCount)
+	for i := range headers {
+		h := data[20+30*i : 20+30*(i+1)]
+		headers[i] = sampleHeader{
+			length:    2 * (int(h[22])<<8 | int(h[23])),
+			finetune:  int(int8(h[24]<<4) >> 4),
+			volume:    int(h[25]),
+			loopStart: 2 * (int(h[26])<<8 | int(h[27])),
+			loopLen:   2 * (int(h[28])<<8 | int(h[29])),
+		}
+	}
+
+	songLength := int(data[950])
+	if songLength == 0 || songLength > 128 {
+		return nil, fmt.Errorf("tracker: invalid MOD song length: %d", songLength)
+	}
+	restart := int(data[951])
+	if restart >= songLength {
+		restart = 0
+	}
+	m.restart = restart
+
+	var patternCount int
+	for _, o := range data[952 : 952+128] {
+		if int(o)+1 > patternCount {
+			patternCount = int(o) + 1
+		}
+	}
+	for _, o := range data[952 : 952+songLength] {
+		m.orders = append(m.orders, int(o))
+	}
+
+	offset := 1084
+	for i := 0; i < patternCount; i++ {
+		size := 64 * channelCount * 4
+		if offset+size > len(data) {
+			return nil, fmt.Errorf("tracker: unexpected EOF in MOD patterns")
+		}
+		p := &pattern{
+			rows: make([][]cell, 64),
+		}
+		for r := range p.rows {
+			p.rows[r] = make([]cell, channelCount)
+			for ch := range p.rows[r] {
+				b := data[offset+4*(r*channelCount+ch):]
+				period := int(b[0]&0x0f)<<8 | int(b[1])
+				c := cell{
+					note:       noteNone,
+					instrument: b[0]&0xf0 | b[2]>>4,
+				}
+				if period != 0 {
+					c.note = modNoteFromPeriod(period)
+				}
+				c.effect, c.param = convertMODEffect(b[2]&0x0f, b[3])
+				if c.effect == effectSetVolume {
+					c.effect = effectNone
+					c.volumeCommand = volumeSet
+					c.volumeParam = c.param
+					if c.volumeParam > 64 {
+						c.volumeParam = 64
+					}
+				}
+				p.rows[r][ch] = c
+			}
+		}
+		m.patterns = append(m.patterns, p)
+		offset += size
+	}
+
+	for _, h := range headers {
+		s := &sample{
+			volume:       h.volume,
+			globalVolume: 64,
+			panning:      -1,
+			c5Speed:      8363 * math.Exp2(float64(h.finetune)/(12*8)),
+		}
+		if s.volume > 64 {
+			s.volume = 64
+		}
+		length := h.length
+		if offset+length > len(data) {
+			// Some MOD files are truncated.
+			length = len(data) - offset
+			if length < 0 {
+				length = 0
+			}
+		}
+		s.data = make([]float32, length)
+		for i := range s.data {
+			s.data[i] = float32(int8(data[offset+i])) / (1 << 7)
+		}
+		offset += length
+
+		if h.loopLen > 2 && h.loopStart < len(s.data) {
+			s.loopType = loopForward
+			s.loopStart = h.loopStart
+			s.loopEnd = h.loopStart + h.loopLen
+			if s.loopEnd > len(s.data) {
+				s.loopEnd = len(s.data)
+			}
+		}
+		m.samples = append(m.samples, s)
+		m.instruments = append(m.instruments, sampleInstrument(len(m.samples)-1, -1))
+	}
+
+	// Amiga's channels are panned LRRL.
+	for ch := 0; ch < channelCount; ch++ {
+		if ch%4 == 0 || ch%4 == 3 {
+			m.initialPannings = append(m.initialPannings, 0x40)
+		} else {
+			m.initialPannings = append(m.initialPannings, 0xc0)
+		}
+		m.initialChannelVolumes = append(m.initialChannelVolumes, 64)
+	}
+	m.mixGain = defaultMixGain(channelCount)
+
+	return m, nil
+}
+
+// convertMODEffect converts a MOD or XM effect to an effect.
+func convertMODEffect(command, param byte) (effect, byte) {
+	x, y := param>>4, param&0x0f
+	switch command {
+	case 0x0:
+		if param == 0 {
+			return effectNone, 0
+		}
+		return effectArpeggio, param
+	case 0x1:
+		return effectPortaUp, param
+	case 0x2:
+		return effectPortaDown, param
+	case 0x3:
+		return effectTonePorta, param
+	case 0x4:
+		return effectVibrato, param
+	case 0x5:
+		return effectTonePortaVolumeSlide, param
+	case 0x6:
+		return effectVibratoVolumeSlide, param
+	case 0x7:
+		return effectTremolo, param
+	case 0x8:
+		return effectSetPanning, param
+	case 0x9:
+		return effectSampleOffset, param
+	case 0xa:
+		return effectVolumeSlide, param
+	case 0xb:
+		return effectPositionJump, param
+	case 0xc:
+		return effectSetVolume, param
+	case 0xd:
+		// The row is in BCD.
+		return effectPatternBreak, x*10 + y
+	case 0xe:
+		switch x {
+		case 0x1:
+			return effectFinePortaUp, y
+		case 0x2:
+			return effectFinePortaDown, y
+		case 0x4:
+			return effectSetVibratoWaveform, y
+		case 0x6:
+			return effectPatternLoop, y
+		case 0x7:
+			return effectSetTremoloWaveform, y
+		case 0x8:
+			return effectSetPanning, y * 0x11
+		case 0x9:
+			return effectRetrigger, y
+		case 0xa:
+			return effectFineVolumeSlideUp, y
+		case 0xb:
+			return effectFineVolumeSlideDown, y
+		case 0xc:
+			return effectNoteCut, y
+		case 0xd:
+			return effectNoteDelay, y
+		case 0xe:
+			return effectPatternDelay, y
+		}
+	case 0xf:
+		if param == 0 {
+			return effectNone, 0
+		}
+		if param < 0x20 {
+			return effectSetSpeed, param
+		}
+		return effectSetTempo, param
+	}
+	return effectNone, 0
+}
+
+// sampleInstrument returns an instrument that plays the sample for all the notes.
+func sampleInstrument(sample int, panning int) *instrument {
+	i := &instrument{
+		globalVolume: 128,
+		panning:      panning,
+	}
+	for n := range i.samples {
+		i.samples[n].sample = sample
+		i.samples[n].note = n
+	}
+	return i
+}
+
+func trimString(bs []byte) string {
+	if i := strings.IndexByte(string(bs), 0); i >= 0 {
+		bs = bs[:i]
+	}
+	return strings.TrimRight(string(bs), " ")
+}
@@ -0,0 +1,301 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"math"
+)
+
+// The notes are numbered from C-0 (0) to B-9 (119).
+const (
+	noteNone = 0xff
+	noteOff  = 0xfe
+	noteCut  = 0xfd
+	noteFade = 0xfc
+
+	maxNote = 119
+
+	// noteC5 is the note where a sample is played at its c5Speed.
+	noteC5 = 60
+)
+
+// effect is an effect normalized from the format-specific commands.
+type effect byte
+
+const (
+	effectNone effect = iota
+	effectArpeggio
+	effectPortaUp
+	effectPortaDown
+	effectFinePortaUp
+	effectFinePortaDown
+	effectExtraFinePortaUp
+	effectExtraFinePortaDown
+	effectTonePorta
+	effectVibrato
+	effectFineVibrato
+	effectTonePortaVolumeSlide
+	effectVibratoVolumeSlide
+	effectTremolo
+	effectSetPanning
+	effectSampleOffset
+	effectVolumeSlide
+	effectFineVolumeSlideUp
+	effectFineVolumeSlideDown
+	effectPositionJump
+	effectSetVolume
+	effectPatternBreak
+	effectSetSpeed
+	effectSetTempo
+	effectPatternLoop
+	effectNoteCut
+	effectNoteDelay
+	effectPatternDelay
+	effectRetrigger
+	effectSetGlobalVolume
+	effectGlobalVolumeSlide
+	effectKeyOff
+	effectPanningSlide
+	effectSetChannelVolume
+	effectChannelVolumeSlide
+	effectSetVibratoWaveform
+	effectSetTremoloWaveform
+)
+
+// volumeCommand is a command in the volume column of XM and IT.
+type volumeCommand byte
+
+const (
+	volumeNone volumeCommand = iota
+	volumeSet
+	volumeSlideUp
+	volumeSlideDown
+	volumeFineSlideUp
+	volumeFineSlideDown
+	volumeVibratoSpeed
+	volumeVibratoDepth
+	volumeSetPanning
+	volumePanningSlideLeft
+	volumePanningSlideRight
+	volumeTonePorta
+	volumePortaUp
+	volumePortaDown
+)
+
+type cell struct {
+	note       byte
+	instrument byte
+
+	volumeCommand volumeCommand
+	volumeParam   byte
+
+	effect effect
+	param  byte
+}
+
+type pattern struct {
+	rows [][]cell
+}
+
+type loopType int
+
+const (
+	loopNone loopType = iota
+	loopForward
+	loopPingPong
+)
+
+type sample struct {
+	data []float32
+
+	loopType  loopType
+	loopStart int
+	loopEnd   int
+
+	// The sustain loop is available only for IT.
+	sustainLoopType  loopType
+	sustainLoopStart int
+	sustainLoopEnd   int
+
+	// volume is in [0, 64].
+	volume int
+
+	// globalVolume is in [0, 64].
+	globalVolume int
+
+	// panning is in [0, 255]. -1 means the sample doesn't specify the panning.
+	panning int
+
+	// c5Speed is the sample rate in [Hz] when the sample is played at C-5.
+	c5Speed float64
+}
+
+type envelopePoint struct {
+	tick  int
+	value int
+}
+
+type envelope struct {
+	enabled bool
+	points  []envelopePoint
+
+	loop      bool
+	loopStart int
+	loopEnd   int
+
+	sustain      bool
+	sustainStart int
+	sustainEnd   int
+}
+
+// valueAt returns the interpolated value at the tick.
+func (e *envelope) valueAt(tick int) float64 {
+	ps := e.points
+	if len(ps) == 0 {
+		return 0
+	}
+	if tick <= ps[0].tick {
+		return float64(ps[0].value)
+	}
+	for i := 1; i < len(ps); i++ {
+		if tick > ps[i].tick {
+			continue
+		}
+		p0, p1 := ps[i-1], ps[i]
+		if p1.tick == p0.tick {
+			return float64(p1.value)
+		}
+		t := float64(tick-p0.tick) / float64(p1.tick-p0.tick)
+		return float64(p0.value) + float64(p1.value-p0.value)*t
+	}
+	return float64(ps[len(ps)-1].value)
+}
+
+type instrument struct {
+	// samples maps a note to a sample index and a note to play.
+	// A negative sample index means no sample.
+	samples [maxNote + 1]struct {
+		sample int
+		note   int
+	}
+
+	volumeEnvelope  envelope
+	panningEnvelope envelope
+
+	// fadeout is the amount to decrease the volume in [0, 1] per tick after the note is released.
+	fadeout float64
+
+	// globalVolume is in [0, 128].
+	globalVolume int
+
+	// panning is in [0, 255]. -1 means the instrument doesn't specify the panning.
+	panning int
+}
+
+type module struct {
+	title string
+
+	channelCount int
+	orders       []int
+	restart      int
+	patterns     []*pattern
+	instruments  []*instrument
+	samples      []*sample
+
+	initialSpeed int
+	initialTempo int
+
+	// initialGlobalVolume is in [0, 128].
+	initialGlobalVolume int
+
+	// initialPannings are in [0, 255].
+	initialPannings []int
+
+	// initialChannelVolumes are in [0, 64].
+	initialChannelVolumes []int
+
+	// linear indicates whether the pitch slides are linear. If false, the slides are based on Amiga periods.
+	linear bool
+
+	// amigaLimits indicates whether the Amiga periods are limited to the range of ProTracker.
+	amigaLimits bool
+
+	// fineVibrato indicates whether the vibrato depth is halved, which is IT's behavior without the old effects.
+	fineVibrato bool
+
+	// effectMemory indicates whether an effect with the parameter 0 uses the last parameter.
+	effectMemory bool
+
+	// it indicates whether the module is IT, where some effects have different semantics.
+	it bool
+
+	// mixGain is the gain to mix the channels.
+	mixGain float64
+}
+
+// amigaClock is the clock to convert an Amiga period to a frequency such that the period 428 is 8363 [Hz].
+const amigaClock = 428 * 8363
+
+func (m *module) periodFromFrequency(freq float64) float64 {
+	if m.linear {
+		return -768 * math.Log2(freq)
+	}
+	return amigaClock / freq
+}
+
+func (m *module) frequencyFromPeriod(period float64) float64 {
+	if m.linear {
+		return math.Exp2(-period / 768)
+	}
+	if period <= 0 {
+		return 0
+	}
+	return amigaClock / period
+}
+
+// noteFrequency returns the frequency of the note for the sample.
+func noteFrequency(note int, s *sample) float64 {
+	return s.c5Speed * math.Exp2(float64(note-noteC5)/12)
+}
+
+// clampPeriod clamps the Amiga period within the range of ProTracker (C-4 to B-6 in the module notes).
+func (m *module) clampPeriod(period float64) float64 {
+	if m.linear || !m.amigaLimits {
+		return period
+	}
+	if period < 113 {
+		return 113
+	}
+	if period > 856 {
+		return 856
+	}
+	return period
+}
+
+// slideUnits returns the units to slide the period for a porta parameter.
+func (m *module) slideUnits(param int) float64 {
+	if m.linear {
+		return float64(param) * 4
+	}
+	return float64(param)
+}
+
+// extraFineSlideUnits returns the units to slide the period for an extra fine porta parameter.
+func (m *module) extraFineSlideUnits(param int) float64 {
+	if m.linear {
+		return float64(param)
+	}
+	return float64(param) / 4
+}
@@ -0,0 +1,400 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"math"
+)
+
+// maxSongLengthInSeconds is the maximum length to calculate the song length.
+const maxSongLengthInSeconds = 60 * 60
+
+type position struct {
+	order int
+	row   int
+}
+
+// player plays a module tick by tick.
+type player struct {
+	module     *module
+	sampleRate int
+	channels   []channel
+
+	order int
+	row   int
+	tick  int
+	speed int
+	tempo int
+
+	// globalVolume is in [0, 128].
+	globalVolume int
+
+	// patternDelay is the number of rows to repeat the current row.
+	patternDelay int
+
+	// jump is the position to go after the current row. jump.order is -1 if there is no jump.
+	jump position
+
+	// visited is the set of the played rows to detect the end of the song.
+	visited map[position]struct{}
+
+	loop  bool
+	ended bool
+
+	// framesInTick is the number of the frames remaining in the current tick.
+	framesInTick int
+
+	// frameError is the accumulated error of the frames per tick.
+	frameError float64
+}
+
+func newPlayer(m *module, sampleRate int) *player {
+	p := &player{
+		module:       m,
+		sampleRate:   sampleRate,
+		channels:     make([]channel, m.channelCount),
+		speed:        m.initialSpeed,
+		tempo:        m.initialTempo,
+		globalVolume: m.initialGlobalVolume,
+		jump:         position{order: -1},
+		visited:      map[position]struct{}{},
+	}
+	for i := range p.channels {
+		ch := &p.channels[i]
+		ch.panning = m.initialPannings[i]
+		ch.channelVolume = m.initialChannelVolumes[i]
+		ch.delayTick = -1
+	}
+	p.order = p.validOrder(0)
+	if p.order < 0 {
+		p.ended = true
+	}
+	return p
+}
+
+// validOrder returns the first playable order at or after the given order. If there is no such order, validOrder returns -1.
+func (p *player) validOrder(order int) int {
+	for ; order < len(p.module.orders); order++ {
+		switch p.module.orders[order] {
+		case itOrderSkip:
+			continue
+		case itOrderEnd:
+			return -1
+		}
+		return order
+	}
+	return -1
+}
+
+func (p *player) pattern(order int) *pattern {
+	idx := p.module.orders[order]
+	if idx < 0 || idx >= len(p.module.patterns) {
+		return nil
+	}
+	return p.module.patterns[idx]
+}
+
+func (p *player) rowCount(order int) int {
+	pat := p.pattern(order)
+	if pat == nil {
+		// A non-existing pattern is treated as an empty pattern with 64 rows.
+		return 64
+	}
+	return len(pat.rows)
+}
+
+// setPosition sets the position to play the next.
+func (p *player) setPosition(order, row int) {
+	p.order = order
+	p.row = row
+	p.tick = 0
+	p.patternDelay = 0
+	p.framesInTick = 0
+	p.jump = position{order: -1}
+	p.visited = map[position]struct{}{}
+	p.ended = false
+	for i := range p.channels {
+		p.channels[i].delayTick = -1
+		p.channels[i].patternLoopRow = 0
+		p.channels[i].patternLoopCount = 0
+	}
+}
+
+// framesPerTick returns the number of frames in one tick.
+func (p *player) framesPerTick() int {
+	// One tick is 2.5 / tempo [s].
+	f := float64(p.sampleRate)*2.5/float64(p.tempo) + p.frameError
+	n := int(f)
+	p.frameError = f - float64(n)
+	return n
+}
+
+// render renders the frames into dst in 2 channels and returns the number of the rendered frames.
+// If dst is nil, render only advances the position by frames.
+// render returns a smaller number than frames only when the song ends.
+func (p *player) render(dst []float32, frames int) int {
+	var n int
+	for n < frames {
+		if p.framesInTick == 0 {
+			if !p.processTick() {
+				break
+			}
+			p.framesInTick = p.framesPerTick()
+		}
+		m := frames - n
+		if m > p.framesInTick {
+			m = p.framesInTick
+		}
+		var d []float32
+		if dst != nil {
+			d = dst[2*n : 2*(n+m)]
+			for i := range d {
+				d[i] = 0
+			}
+		}
+		for i := range p.channels {
+			p.channels[i].mix(d, m, p.sampleRate)
+		}
+		if d != nil {
+			for i, v := range d {
+				if v > 1 {
+					d[i] = 1
+				} else if v < -1 {
+					d[i] = -1
+				}
+			}
+		}
+		p.framesInTick -= m
+		n += m
+	}
+	return n
+}
+
+// songLength returns the number of the frames of the song without looping.
+func (p *player) songLength() int64 {
+	var n int64
+	for p.processTick() {
+		n += int64(p.framesPerTick())
+		if n > int64(p.sampleRate)*maxSongLengthInSeconds {
+			break
+		}
+	}
+	return n
+}
+
+// processTick processes one tick. processTick returns false when the song ends.
+func (p *player) processTick() bool {
+	if p.ended {
+		return false
+	}
+
+	if p.tick == 0 && p.patternDelay == 0 {
+		if !p.startRow() {
+			p.ended = true
+			return false
+		}
+	} else {
+		for i := range p.channels {
+			p.channels[i].processTick(p, p.tick)
+		}
+	}
+
+	for i := range p.channels {
+		p.channels[i].updateOutput(p)
+	}
+
+	p.tick++
+	if p.tick >= p.speed {
+		p.tick = 0
+		if p.patternDelay > 0 {
+			p.patternDelay--
+		}
+		if p.patternDelay == 0 {
+			p.advanceRow()
+		}
+	}
+	return true
+}
+
+// advanceRow moves the position to the next row.
+func (p *player) advanceRow() {
+	if p.jump.order >= 0 {
+		p.order = p.jump.order
+		p.row = p.jump.row
+		p.jump = position{order: -1}
+	} else {
+		p.row++
+	}
+	if p.order < len(p.module.orders) && p.module.orders[p.order] >= 0 && p.row >= p.rowCount(p.order) {
+		p.order++
+		p.row = 0
+	}
+}
+
+// startRow starts the current row. startRow returns false when the song ends.
+func (p *player) startRow() bool {
+	order := p.validOrder(p.order)
+	if order != p.order {
+		p.row = 0
+	}
+	if order < 0 {
+		if !p.loop {
+			return false
+		}
+		order = p.validOrder(p.module.restart)
+		if order < 0 {
+			order = p.validOrder(0)
+		}
+		if order < 0 {
+			return false
+		}
+		p.row = 0
+		p.visited = map[position]struct{}{}
+	}
+	p.order = order
+	if p.row >= p.rowCount(p.order) {
+		p.row = 0
+	}
+
+	pos := position{order: p.order, row: p.row}
+	if _, ok := p.visited[pos]; ok {
+		if !p.loop {
+			return false
+		}
+		p.visited = map[position]struct{}{}
+	}
+	p.visited[pos] = struct{}{}
+
+	pat := p.pattern(p.order)
+	for i := range p.channels {
+		c := cell{note: noteNone}
+		if pat != nil {
+			c = pat.rows[p.row][i]
+		}
+		p.processGlobalEffect(&p.channels[i], c)
+		p.channels[i].startRow(p, c)
+	}
+	return true
+}
+
+// processGlobalEffect processes the effects that affect the whole song at the head of a row.
+func (p *player) processGlobalEffect(ch *channel, c cell) {
+	param := int(c.param)
+	switch c.effect {
+	case effectSetSpeed:
+		if param > 0 {
+			p.speed = param
+		}
+	case effectSetTempo:
+		// IT's T0x and T1x are tempo slides, which are not supported.
+		if param >= 0x20 {
+			p.tempo = param
+		}
+	case effectPositionJump:
+		row := 0
+		if p.jump.order >= 0 && p.jump.order == p.order+1 {
+			// A pattern break in a preceding channel.
+			row = p.jump.row
+		}
+		p.jump = position{order: param, row: row}
+	case effectPatternBreak:
+		order := p.order + 1
+		if p.jump.order >= 0 && p.jump.order != p.order+1 {
+			// A position jump in a preceding channel.
+			order = p.jump.order
+		}
+		p.jump = position{order: order, row: param}
+	case effectPatternDelay:
+		if p.patternDelay == 0 && param > 0 {
+			// The current row is repeated param times.
+			p.patternDelay = param + 1
+		}
+	case effectPatternLoop:
+		if param == 0 {
+			ch.patternLoopRow = p.row
+			return
+		}
+		if ch.patternLoopCount == 0 {
+			ch.patternLoopCount = param
+		} else {
+			ch.patternLoopCount--
+		}
+		if ch.patternLoopCount > 0 {
+			p.jump = position{order: p.order, row: ch.patternLoopRow}
+			// The looped rows are played again. Don't treat them as the end of the song.
+			for r := ch.patternLoopRow; r <= p.row; r++ {
+				delete(p.visited, position{order: p.order, row: r})
+			}
+		}
+	case effectSetGlobalVolume:
+		p.globalVolume = param
+	}
+}
+
+func (p *player) setGlobalVolume(v int) {
+	if v < 0 {
+		v = 0
+	}
+	if v > 128 {
+		v = 128
+	}
+	p.globalVolume = v
+}
+
+// sineTable is the vibrato and tremolo table of the positive half of the sine wave.
+var sineTable = [32]int{
+	0, 24, 49, 74, 97, 120, 141, 161, 180, 197, 212, 224, 235, 244, 250, 253,
+	255, 253, 250, 244, 235, 224, 212, 197, 180, 161, 141, 120, 97, 74, 49, 24,
+}
+
+// waveValue returns the value of the waveform in [-255, 255] at the position in [0, 64).
+func waveValue(waveform int, pos int) int {
+	pos &= 63
+	switch waveform & 3 {
+	case 1:
+		// Ramp down
+		return 255 - pos*8
+	case 2:
+		// Square
+		if pos < 32 {
+			return 255
+		}
+		return -255
+	case 3:
+		// Random
+		return int((uint32(pos)*1103515245+12345)>>16&0x1ff) - 255
+	}
+	v := sineTable[pos&31]
+	if pos >= 32 {
+		v = -v
+	}
+	return v
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// panGains returns the gains of the left and the right channels for the panning in [0, 255].
+func panGains(panning float64) (float64, float64) {
+	t := panning / 255 * math.Pi / 2
+	return math.Cos(t), math.Sin(t)
+}
@@ -0,0 +1,280 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracker provides a player of tracker modules: MOD, XM, and IT.
+//
+// A module is rendered to PCM on the fly. The player is written in pure Go and supports the common effects of the formats.
+// Some features are not supported yet, e.g. IT's new note actions (a new note always cuts the previous note in the channel)
+// and IT's resonant filters.
+package tracker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	bitDepthInBytesInt16   = 2
+	bitDepthInBytesFloat32 = 4
+)
+
+// Stream is a rendered stream of a module.
+type Stream struct {
+	module          *module
+	sampleRate      int
+	bitDepthInBytes int
+	player          *player
+	length          int64
+	posInBytes      int64
+	loop            bool
+
+	buf []float32
+
+	// rest is the remaining bytes of a frame that is partially read.
+	rest    []byte
+	restBuf [2 * bitDepthInBytesFloat32]byte
+
+	m sync.Mutex
+}
+
+// Read is implementation of io.Reader's Read.
+//
+// When looping is enabled by SetLoop, Read never returns io.EOF.
+func (s *Stream) Read(buf []byte) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var n int
+	for len(buf) > 0 {
+		if len(s.rest) > 0 {
+			m := copy(buf, s.rest)
+			s.rest = s.rest[m:]
+			buf = buf[m:]
+			n += m
+			continue
+		}
+
+		frames := len(buf) / s.bytesPerFrame()
+		if frames == 0 {
+			frames = 1
+		}
+		if len(s.buf) < 2*frames {
+			s.buf = make([]float32, 2*frames)
+		}
+		m := s.player.render(s.buf[:2*frames], frames)
+		if m == 0 {
+			break
+		}
+		if len(buf) < s.bytesPerFrame() {
+			s.encode(s.restBuf[:], s.buf[:2])
+			s.rest = s.restBuf[:s.bytesPerFrame()]
+			continue
+		}
+		s.encode(buf, s.buf[:2*m])
+		buf = buf[m*s.bytesPerFrame():]
+		n += m * s.bytesPerFrame()
+	}
+	s.posInBytes += int64(n)
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (s *Stream) bytesPerFrame() int {
+	return 2 * s.bitDepthInBytes
+}
+
+func (s *Stream) encode(dst []byte, samples []float32) {
+	for i, v := range samples {
+		switch s.bitDepthInBytes {
+		case bitDepthInBytesInt16:
+			binary.LittleEndian.PutUint16(dst[2*i:], uint16(int16(v*(1<<15-1))))
+		case bitDepthInBytesFloat32:
+			binary.LittleEndian.PutUint32(dst[4*i:], math.Float32bits(v))
+		}
+	}
+}
+
+// Seek is implementation of io.Seeker's Seek.
+//
+// Note that Seek can take long since the module must be processed from the head to the position.
+func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	next := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		next += s.posInBytes
+	case io.SeekEnd:
+		next += s.length
+	}
+	if next < 0 {
+		return 0, fmt.Errorf("tracker: negative position: %d", next)
+	}
+
+	frame := next / int64(s.bytesPerFrame())
+	s.player = newPlayer(s.module, s.sampleRate)
+	s.player.loop = s.loop
+	for f := frame; f > 0; {
+		n := int(f)
+		if n > s.sampleRate {
+			n = s.sampleRate
+		}
+		m := s.player.render(nil, n)
+		if m == 0 {
+			break
+		}
+		f -= int64(m)
+	}
+	s.rest = nil
+	s.posInBytes = frame * int64(s.bytesPerFrame())
+	return s.posInBytes, nil
+}
+
+// Length returns the size of the rendered stream in bytes, playing the song once without looping.
+func (s *Stream) Length() int64 {
+	return s.length
+}
+
+// SampleRate returns the sample rate of the rendered stream.
+func (s *Stream) SampleRate() int {
+	return s.sampleRate
+}
+
+// Title returns the title of the module.
+func (s *Stream) Title() string {
+	return s.module.title
+}
+
+// SetLoop sets whether the song loops.
+//
+// If loop is true, the song goes back to the restart position when the song ends,
+// and Read never returns io.EOF.
+// The end of a song is detected either by the end of the orders or by a row played again by a position jump.
+//
+// The default value is false.
+//
+// SetLoop is concurrent-safe.
+func (s *Stream) SetLoop(loop bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.loop = loop
+	s.player.loop = loop
+}
+
+// OrderCount returns the number of the orders, which is the number of the patterns in the song's sequence.
+func (s *Stream) OrderCount() int {
+	return len(s.module.orders)
+}
+
+// Position returns the current order and row.
+//
+// The position is of the rendering, which is ahead of the actual playback by the buffer sizes.
+//
+// Position is concurrent-safe.
+func (s *Stream) Position() (order, row int) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.player.order, s.player.row
+}
+
+// SetPosition jumps to the given order and row.
+// The channels keep playing the current notes, and the next row is the given row.
+//
+// SetPosition is useful to switch the sections of a song, e.g. to the battle part of a stage theme.
+//
+// SetPosition returns an error when order or row is out of range.
+//
+// SetPosition is concurrent-safe.
+func (s *Stream) SetPosition(order, row int) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if order < 0 || order >= len(s.module.orders) {
+		return fmt.Errorf("tracker: order out of range: %d", order)
+	}
+	if s.module.orders[order] < 0 {
+		return fmt.Errorf("tracker: order %d is not a pattern", order)
+	}
+	if row < 0 || row >= s.player.rowCount(order) {
+		return fmt.Errorf("tracker: row out of range: %d", row)
+	}
+	s.player.setPosition(order, row)
+	return nil
+}
+
+// DecodeWithSampleRate decodes a MOD, XM, or IT module to playable stream in signed 16bit integer, little endian, 2 channels (stereo) format.
+//
+// DecodeWithSampleRate returns error when decoding fails or IO error happens.
+//
+// The format is detected by the content of src.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+func DecodeWithSampleRate(sampleRate int, src io.Reader) (*Stream, error) {
+	return decode(src, sampleRate, bitDepthInBytesInt16)
+}
+
+// DecodeF32WithSampleRate decodes a MOD, XM, or IT module to playable stream in 32bit float, little endian, 2 channels (stereo) format.
+//
+// DecodeF32WithSampleRate returns error when decoding fails or IO error happens.
+//
+// The format is detected by the content of src.
+//
+// A Stream doesn't close src even if src implements io.Closer.
+// Closing the source is src owner's responsibility.
+func DecodeF32WithSampleRate(sampleRate int, src io.Reader) (*Stream, error) {
+	return decode(src, sampleRate, bitDepthInBytesFloat32)
+}
+
+func decode(src io.Reader, sampleRate int, bitDepthInBytes int) (*Stream, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("tracker: invalid sample rate: %d", sampleRate)
+	}
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	m, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream{
+		module:          m,
+		sampleRate:      sampleRate,
+		bitDepthInBytes: bitDepthInBytes,
+		player:          newPlayer(m, sampleRate),
+		length:          newPlayer(m, sampleRate).songLength() * int64(2*bitDepthInBytes),
+	}, nil
+}
+
+func load(data []byte) (*module, error) {
+	switch {
+	case isXM(data):
+		return loadXM(data)
+	case isIT(data):
+		return loadIT(data)
+	case isMOD(data):
+		return loadMOD(data)
+	}
+	return nil, fmt.Errorf("tracker: unsupported format")
+}
@@ -0,0 +1,464 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/tracker"
+)
+
+const (
+	testSampleRate = 44100
+
+	// testFramesPerRow is the number of frames in one row at the speed 6 and the tempo 125.
+	testFramesPerRow = 6 * testSampleRate * 2 / 100
+)
+
+// testCell is a cell in a test module.
+// effect is one of 'B' (position jump), 'D' (pattern break), and 'F' (set speed) in the MOD style.
+type testCell struct {
+	pattern int
+	row     int
+	channel int
+	note    bool
+	effect  byte
+	param   byte
+}
+
+// sineSample returns a sample of one cycle of the sine wave.
+func sineSample() []int8 {
+	s := make([]int8, 64)
+	for i := range s {
+		s[i] = int8(math.Round(100 * math.Sin(2*math.Pi*float64(i)/float64(len(s)))))
+	}
+	return s
+}
+
+func modEffect(effect, param byte) (byte, byte) {
+	switch effect {
+	case 'B':
+		return 0xb, param
+	case 'D':
+		// The row of a pattern break is in BCD.
+		return 0xd, param/10<<4 | param%10
+	case 'F':
+		return 0xf, param
+	}
+	return 0, 0
+}
+
+func buildMOD(orders []byte, patternCount int, cells []testCell, smp []int8) []byte {
+	const channelCount = 4
+
+	data := make([]byte, 1084)
+	copy(data, "test")
+	h := data[20:]
+	binary.BigEndian.PutUint16(h[22:], uint16(len(smp)/2))
+	h[25] = 64
+	binary.BigEndian.PutUint16(h[26:], 0)
+	binary.BigEndian.PutUint16(h[28:], uint16(len(smp)/2))
+	data[950] = byte(len(orders))
+	copy(data[952:], orders)
+	copy(data[1080:], "M.K.")
+
+	patterns := make([]byte, patternCount*64*channelCount*4)
+	for _, c := range cells {
+		b := patterns[4*((c.pattern*64+c.row)*channelCount+c.channel):]
+		if c.note {
+			binary.BigEndian.PutUint16(b, 428)
+			b[2] = 1 << 4
+		}
+		e, p := modEffect(c.effect, c.param)
+		b[2] |= e
+		b[3] = p
+	}
+	data = append(data, patterns...)
+	for _, v := range smp {
+		data = append(data, byte(v))
+	}
+	return data
+}
+
+func buildXM(orders []byte, patternCount int, cells []testCell, smp []int8) []byte {
+	const channelCount = 4
+
+	var data []byte
+	data = append(data, "Extended Module: test"...)
+	data = append(data, make([]byte, 60-len(data))...)
+	data[37] = 0x1a
+	data = binary.LittleEndian.AppendUint32(data, 20+256)
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(orders)))
+	data = binary.LittleEndian.AppendUint16(data, 0) // Restart
+	data = binary.LittleEndian.AppendUint16(data, channelCount)
+	data = binary.LittleEndian.AppendUint16(data, uint16(patternCount))
+	data = binary.LittleEndian.AppendUint16(data, 1) // Instruments
+	data = binary.LittleEndian.AppendUint16(data, 1) // Linear frequency
+	data = binary.LittleEndian.AppendUint16(data, 6)
+	data = binary.LittleEndian.AppendUint16(data, 125)
+	o := make([]byte, 256)
+	copy(o, orders)
+	data = append(data, o...)
+
+	for i := 0; i < patternCount; i++ {
+		// Unpacked cells: note, instrument, volume, effect, and parameter.
+		p := make([]byte, 64*channelCount*5)
+		for _, c := range cells {
+			if c.pattern != i {
+				continue
+			}
+			b := p[5*(c.row*channelCount+c.channel):]
+			if c.note {
+				// C-4 in XM
+				b[0] = 49
+				b[1] = 1
+			}
+			b[3], b[4] = modEffect(c.effect, c.param)
+		}
+		data = binary.LittleEndian.AppendUint32(data, 9)
+		data = append(data, 0)
+		data = binary.LittleEndian.AppendUint16(data, 64)
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(p)))
+		data = append(data, p...)
+	}
+
+	// Instrument
+	inst := make([]byte, 263)
+	binary.LittleEndian.PutUint32(inst, uint32(len(inst)))
+	binary.LittleEndian.PutUint16(inst[27:], 1)  // Samples
+	binary.LittleEndian.PutUint32(inst[29:], 40) // Sample header size
+	data = append(data, inst...)
+
+	// Sample header
+	sh := make([]byte, 40)
+	binary.LittleEndian.PutUint32(sh, uint32(len(smp)))
+	binary.LittleEndian.PutUint32(sh[4:], 0)
+	binary.LittleEndian.PutUint32(sh[8:], uint32(len(smp)))
+	sh[12] = 64
+	sh[14] = 1 // Forward loop
+	sh[15] = 0x80
+	data = append(data, sh...)
+
+	// Sample data in delta values
+	var prev int8
+	for _, v := range smp {
+		data = append(data, byte(v-prev))
+		prev = v
+	}
+	return data
+}
+
+func buildIT(orders []byte, patternCount int, cells []testCell, smp []int8) []byte {
+	const (
+		headerSize       = 0xc0
+		sampleHeaderSize = 0x50
+	)
+
+	data := make([]byte, headerSize)
+	copy(data, "IMPMtest")
+	binary.LittleEndian.PutUint16(data[0x20:], uint16(len(orders)))
+	binary.LittleEndian.PutUint16(data[0x22:], 0) // Instruments
+	binary.LittleEndian.PutUint16(data[0x24:], 1) // Samples
+	binary.LittleEndian.PutUint16(data[0x26:], uint16(patternCount))
+	binary.LittleEndian.PutUint16(data[0x28:], 0x214)
+	binary.LittleEndian.PutUint16(data[0x2a:], 0x200)
+	binary.LittleEndian.PutUint16(data[0x2c:], 0x01|0x08) // Stereo and linear slides
+	data[0x30] = 128                                      // Global volume
+	data[0x31] = 64                                       // Mix volume
+	data[0x32] = 6
+	data[0x33] = 125
+	for i := 0; i < 64; i++ {
+		data[0x40+i] = 32
+		data[0x80+i] = 64
+	}
+	data = append(data, orders...)
+
+	offsetsPos := len(data)
+	data = append(data, make([]byte, 4*(1+patternCount))...)
+
+	binary.LittleEndian.PutUint32(data[offsetsPos:], uint32(len(data)))
+	sh := make([]byte, sampleHeaderSize)
+	copy(sh, "IMPS")
+	sh[0x11] = 64          // Global volume
+	sh[0x12] = 0x01 | 0x10 // Sample data and loop
+	sh[0x13] = 64
+	sh[0x2e] = 0x01 // Signed
+	binary.LittleEndian.PutUint32(sh[0x30:], uint32(len(smp)))
+	binary.LittleEndian.PutUint32(sh[0x34:], 0)
+	binary.LittleEndian.PutUint32(sh[0x38:], uint32(len(smp)))
+	binary.LittleEndian.PutUint32(sh[0x3c:], 8363)
+	binary.LittleEndian.PutUint32(sh[0x48:], uint32(len(data)+len(sh)))
+	data = append(data, sh...)
+	for _, v := range smp {
+		data = append(data, byte(v))
+	}
+
+	for i := 0; i < patternCount; i++ {
+		var p []byte
+		for row := 0; row < 64; row++ {
+			for _, c := range cells {
+				if c.pattern != i || c.row != row {
+					continue
+				}
+				var mask byte
+				var values []byte
+				if c.note {
+					mask |= 0x03
+					values = append(values, 60, 1)
+				}
+				if c.effect != 0 {
+					mask |= 0x08
+					switch c.effect {
+					case 'B':
+						values = append(values, 'B'-'@', c.param)
+					case 'D':
+						values = append(values, 'C'-'@', c.param)
+					case 'F':
+						values = append(values, 'A'-'@', c.param)
+					}
+				}
+				p = append(p, byte(c.channel+1)|0x80, mask)
+				p = append(p, values...)
+			}
+			p = append(p, 0)
+		}
+		binary.LittleEndian.PutUint32(data[offsetsPos+4*(1+i):], uint32(len(data)))
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(p)))
+		data = binary.LittleEndian.AppendUint16(data, 64)
+		data = append(data, 0, 0, 0, 0)
+		data = append(data, p...)
+	}
+	return data
+}
+
+var builders = []struct {
+	name  string
+	build func(orders []byte, patternCount int, cells []testCell, smp []int8) []byte
+}{
+	{name: "MOD", build: buildMOD},
+	{name: "XM", build: buildXM},
+	{name: "IT", build: buildIT},
+}
+
+func TestLength(t *testing.T) {
+	testCases := []struct {
+		name   string
+		orders []byte
+		cells  []testCell
+		rows   int
+	}{
+		{
+			name:   "plain",
+			orders: []byte{0},
+			cells: []testCell{
+				{note: true},
+			},
+			rows: 64 * 6,
+		},
+		{
+			name:   "set speed",
+			orders: []byte{0},
+			cells: []testCell{
+				{note: true, effect: 'F', param: 3},
+			},
+			// Count the rows at the speed 6.
+			rows: 64 * 3,
+		},
+		{
+			name:   "position jump",
+			orders: []byte{0, 1},
+			cells: []testCell{
+				{pattern: 0, row: 3, effect: 'B', param: 1},
+				// Jumping back to the visited row ends the song.
+				{pattern: 1, row: 1, effect: 'B', param: 0},
+			},
+			rows: 6 * 6,
+		},
+		{
+			name:   "pattern break",
+			orders: []byte{0, 1},
+			cells: []testCell{
+				{pattern: 0, row: 1, channel: 2, effect: 'D', param: 32},
+			},
+			rows: (2 + 32) * 6,
+		},
+	}
+
+	for _, b := range builders {
+		for _, tc := range testCases {
+			t.Run(b.name+" "+tc.name, func(t *testing.T) {
+				data := b.build(tc.orders, 2, tc.cells, sineSample())
+				s, err := tracker.DecodeWithSampleRate(testSampleRate, bytes.NewReader(data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := int64(tc.rows * testFramesPerRow / 6 * 4)
+				if got := s.Length(); got != want {
+					t.Errorf("Length(): got: %d, want: %d", got, want)
+				}
+				bs, err := io.ReadAll(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := int64(len(bs)); got != want {
+					t.Errorf("len(ReadAll()): got: %d, want: %d", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestPitch(t *testing.T) {
+	for _, b := range builders {
+		t.Run(b.name, func(t *testing.T) {
+			data := b.build([]byte{0}, 1, []testCell{{note: true}}, sineSample())
+			s, err := tracker.DecodeF32WithSampleRate(testSampleRate, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			bs := make([]byte, testSampleRate*8)
+			if _, err := io.ReadFull(s, bs); err != nil {
+				t.Fatal(err)
+			}
+
+			// Count the zero crossings of the left channel in one second.
+			var count int
+			var peak float32
+			prev := float32(0)
+			for i := 0; i < testSampleRate; i++ {
+				v := math.Float32frombits(binary.LittleEndian.Uint32(bs[8*i:]))
+				if prev < 0 && v >= 0 {
+					count++
+				}
+				if v > peak {
+					peak = v
+				}
+				prev = v
+			}
+			if peak < 0.2 || peak > 0.8 {
+				t.Errorf("peak: got: %f, want: in [0.2, 0.8]", peak)
+			}
+			// C-5 plays the sample at 8363 [Hz].
+			want := 8363 / 64
+			if count < want-1 || count > want+1 {
+				t.Errorf("frequency: got: %d, want: %d", count, want)
+			}
+		})
+	}
+}
+
+func TestLoop(t *testing.T) {
+	data := buildMOD([]byte{0}, 1, []testCell{{note: true}}, sineSample())
+	s, err := tracker.DecodeWithSampleRate(testSampleRate, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetLoop(true)
+
+	bs := make([]byte, s.Length()*2+1)
+	if _, err := io.ReadFull(s, bs); err != nil {
+		t.Fatal(err)
+	}
+	if order, row := s.Position(); order != 0 || row != 0 {
+		t.Errorf("Position(): got: (%d, %d), want: (0, 0)", order, row)
+	}
+}
+
+func TestSetPosition(t *testing.T) {
+	for _, b := range builders {
+		t.Run(b.name, func(t *testing.T) {
+			data := b.build([]byte{0, 1}, 2, []testCell{{note: true}}, sineSample())
+			s, err := tracker.DecodeWithSampleRate(testSampleRate, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := s.OrderCount(), 2; got != want {
+				t.Errorf("OrderCount(): got: %d, want: %d", got, want)
+			}
+
+			if err := s.SetPosition(1, 10); err != nil {
+				t.Fatal(err)
+			}
+			if order, row := s.Position(); order != 1 || row != 10 {
+				t.Errorf("Position(): got: (%d, %d), want: (1, 10)", order, row)
+			}
+			if _, err := io.ReadFull(s, make([]byte, testFramesPerRow*4)); err != nil {
+				t.Fatal(err)
+			}
+			if order, row := s.Position(); order != 1 || row != 11 {
+				t.Errorf("Position(): got: (%d, %d), want: (1, 11)", order, row)
+			}
+
+			// The song ends at the end of the order 1.
+			bs, err := io.ReadAll(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := len(bs), (64-11)*testFramesPerRow*4; got != want {
+				t.Errorf("len(ReadAll()): got: %d, want: %d", got, want)
+			}
+
+			if err := s.SetPosition(2, 0); err == nil {
+				t.Errorf("SetPosition(2, 0) must return an error")
+			}
+			if err := s.SetPosition(0, 64); err == nil {
+				t.Errorf("SetPosition(0, 64) must return an error")
+			}
+		})
+	}
+}
+
+func TestSeek(t *testing.T) {
+	for _, b := range builders {
+		t.Run(b.name, func(t *testing.T) {
+			data := b.build([]byte{0}, 1, []testCell{{note: true}, {row: 32, channel: 1, note: true}}, sineSample())
+			s, err := tracker.DecodeF32WithSampleRate(testSampleRate, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			all, err := io.ReadAll(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			const offset = 40 * testFramesPerRow * 8
+			if _, err := s.Seek(offset, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]byte, 4096)
+			if _, err := io.ReadFull(s, got); err != nil {
+				t.Fatal(err)
+			}
+			want := all[offset : offset+len(got)]
+			for i := 0; i < len(got); i += 4 {
+				g := math.Float32frombits(binary.LittleEndian.Uint32(got[i:]))
+				w := math.Float32frombits(binary.LittleEndian.Uint32(want[i:]))
+				if math.Abs(float64(g-w)) > 1e-4 {
+					t.Fatalf("sample %d: got: %f, want: %f", i/4, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := tracker.DecodeWithSampleRate(testSampleRate, bytes.NewReader(make([]byte, 2048))); err == nil {
+		t.Errorf("DecodeWithSampleRate must return an error for an unknown format")
+	}
+}
@@ -0,0 +1,462 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const xmSignature = "Extended Module: "
+
+func isXM(data []byte) bool {
+	return len(data) >= 60 && string(data[:17]) == xmSignature
+}
+
+func loadXM(data []byte) (*module, error) {
+	if !isXM(data) {
+		return nil, fmt.Errorf("tracker: invalid XM header")
+	}
+	r := &byteReader{data: data}
+
+	title := trimString(data[17:37])
+	r.pos = 60
+	headerSize := int(r.uint32())
+	headerEnd := 60 + headerSize
+	songLength := int(r.uint16())
+	restart := int(r.uint16())
+	channelCount := int(r.uint16())
+	patternCount := int(r.uint16())
+	instrumentCount := int(r.uint16())
+	flags := r.uint16()
+	speed := int(r.uint16())
+	tempo := int(r.uint16())
+	orders := r.bytes(256)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if channelCount == 0 || channelCount > 64 {
+		return nil, fmt.Errorf("tracker: invalid XM channel count: %d", channelCount)
+	}
+	if songLength > 256 {
+		songLength = 256
+	}
+
+	m := &module{
+		title:               title,
+		channelCount:        channelCount,
+		initialSpeed:        speed,
+		initialTempo:        tempo,
+		initialGlobalVolume: 128,
+		linear:              flags&1 != 0,
+		effectMemory:        true,
+	}
+	if m.initialSpeed == 0 {
+		m.initialSpeed = 6
+	}
+	if m.initialTempo < 0x20 {
+		m.initialTempo = 125
+	}
+	if restart < songLength {
+		m.restart = restart
+	}
+	for _, o := range orders[:songLength] {
+		m.orders = append(m.orders, int(o))
+	}
+	for ch := 0; ch < channelCount; ch++ {
+		m.initialPannings = append(m.initialPannings, 0x80)
+		m.initialChannelVolumes = append(m.initialChannelVolumes, 64)
+	}
+
+	r.pos = headerEnd
+	for i := 0; i < patternCount; i++ {
+		start := r.pos
+		size := int(r.uint32())
+		r.uint8() // Packing type
+		rowCount := int(r.uint16())
+		dataSize := int(r.uint16())
+		if r.err != nil {
+			return nil, r.err
+		}
+		r.pos = start + size
+
+		p := &pattern{
+			rows: make([][]cell, rowCount),
+		}
+		pr := &byteReader{data: r.bytes(dataSize)}
+		for row := range p.rows {
+			p.rows[row] = make([]cell, channelCount)
+			for ch := range p.rows[row] {
+				c := cell{
+					note: noteNone,
+				}
+				if dataSize == 0 {
+					p.rows[row][ch] = c
+					continue
+				}
+				var note, inst, vol, command, param byte
+				b := pr.uint8()
+				if b&0x80 != 0 {
+					if b&0x01 != 0 {
+						note = pr.uint8()
+					}
+					if b&0x02 != 0 {
+						inst = pr.uint8()
+					}
+					if b&0x04 != 0 {
+						vol = pr.uint8()
+					}
+					if b&0x08 != 0 {
+						command = pr.uint8()
+					}
+					if b&0x10 != 0 {
+						param = pr.uint8()
+					}
+				} else {
+					note = b
+					inst = pr.uint8()
+					vol = pr.uint8()
+					command = pr.uint8()
+					param = pr.uint8()
+				}
+
+				switch {
+				case note == 97:
+					c.note = noteOff
+				case note > 0 && note < 97:
+					c.note = note - 1 + 12
+				}
+				c.instrument = inst
+				c.volumeCommand, c.volumeParam = convertXMVolume(vol)
+				c.effect, c.param = convertXMEffect(command, param)
+				if c.effect == effectSetVolume {
+					if c.volumeCommand == volumeNone || c.volumeCommand == volumeSet {
+						c.volumeCommand = volumeSet
+						c.volumeParam = c.param
+						if c.volumeParam > 64 {
+							c.volumeParam = 64
+						}
+					}
+					c.effect = effectNone
+					c.param = 0
+				}
+				p.rows[row][ch] = c
+			}
+		}
+		if pr.err != nil {
+			return nil, fmt.Errorf("tracker: invalid XM pattern %d", i)
+		}
+		m.patterns = append(m.patterns, p)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for i := 0; i < instrumentCount; i++ {
+		start := r.pos
+		size := int(r.uint32())
+		r.bytes(22) // Name
+		r.uint8()   // Type
+		sampleCount := int(r.uint16())
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		inst := &instrument{
+			globalVolume: 128,
+			panning:      -1,
+		}
+		for n := range inst.samples {
+			inst.samples[n].sample = -1
+		}
+		m.instruments = append(m.instruments, inst)
+
+		if sampleCount == 0 {
+			r.pos = start + size
+			continue
+		}
+
+		r.uint32() // Sample header size
+		keymap := r.bytes(96)
+		volumePoints := r.bytes(48)
+		panningPoints := r.bytes(48)
+		volumePointCount := int(r.uint8())
+		panningPointCount := int(r.uint8())
+		volumeSustain := int(r.uint8())
+		volumeLoopStart := int(r.uint8())
+		volumeLoopEnd := int(r.uint8())
+		panningSustain := int(r.uint8())
+		panningLoopStart := int(r.uint8())
+		panningLoopEnd := int(r.uint8())
+		volumeType := r.uint8()
+		panningType := r.uint8()
+		r.bytes(4) // Vibrato
+		fadeout := int(r.uint16())
+		if r.err != nil {
+			return nil, r.err
+		}
+		r.pos = start + size
+
+		inst.volumeEnvelope = xmEnvelope(volumePoints, volumePointCount, volumeType, volumeSustain, volumeLoopStart, volumeLoopEnd, 0)
+		inst.panningEnvelope = xmEnvelope(panningPoints, panningPointCount, panningType, panningSustain, panningLoopStart, panningLoopEnd, -32)
+		inst.fadeout = float64(fadeout) / 32768
+
+		sampleBase := len(m.samples)
+		lengths := make([]int, sampleCount)
+		sixteens := make([]bool, sampleCount)
+		for j := 0; j < sampleCount; j++ {
+			length := int(r.uint32())
+			loopStart := int(r.uint32())
+			loopLength := int(r.uint32())
+			volume := int(r.uint8())
+			finetune := int(int8(r.uint8()))
+			typ := r.uint8()
+			panning := int(r.uint8())
+			relativeNote := int(int8(r.uint8()))
+			r.uint8()   // Reserved
+			r.bytes(22) // Name
+			if r.err != nil {
+				return nil, r.err
+			}
+
+			sixteen := typ&0x10 != 0
+			if sixteen {
+				length /= 2
+				loopStart /= 2
+				loopLength /= 2
+			}
+			lengths[j] = length
+			sixteens[j] = sixteen
+
+			s := &sample{
+				volume:       volume,
+				globalVolume: 64,
+				panning:      panning,
+				// XM's C-4 is C-5 in the module notes, and is played at 8363 [Hz] without the relative note.
+				c5Speed: 8363 * math.Exp2((float64(relativeNote)+float64(finetune)/128)/12),
+			}
+			if s.volume > 64 {
+				s.volume = 64
+			}
+			switch typ & 0x3 {
+			case 1:
+				s.loopType = loopForward
+			case 2:
+				s.loopType = loopPingPong
+			}
+			if loopLength == 0 {
+				s.loopType = loopNone
+			}
+			s.loopStart = loopStart
+			s.loopEnd = loopStart + loopLength
+			m.samples = append(m.samples, s)
+		}
+
+		for j := 0; j < sampleCount; j++ {
+			s := m.samples[sampleBase+j]
+			if sixteens[j] {
+				bs := r.bytes(lengths[j] * 2)
+				s.data = make([]float32, len(bs)/2)
+				var v int16
+				for k := range s.data {
+					v += int16(binary.LittleEndian.Uint16(bs[2*k:]))
+					s.data[k] = float32(v) / (1 << 15)
+				}
+			} else {
+				bs := r.bytes(lengths[j])
+				s.data = make([]float32, len(bs))
+				var v int8
+				for k := range s.data {
+					v += int8(bs[k])
+					s.data[k] = float32(v) / (1 << 7)
+				}
+			}
+			if r.err != nil {
+				return nil, r.err
+			}
+			fixLoop(s)
+		}
+
+		for n := range inst.samples {
+			// XM's keymap covers 96 notes from C-0 in XM, which is C-1 in the module notes.
+			k := n - 12
+			if k < 0 || k >= len(keymap) {
+				continue
+			}
+			if idx := int(keymap[k]); idx < sampleCount {
+				inst.samples[n].sample = sampleBase + idx
+				inst.samples[n].note = n
+			}
+		}
+	}
+
+	m.mixGain = defaultMixGain(channelCount)
+	return m, nil
+}
+
+func xmEnvelope(points []byte, count int, typ byte, sustain, loopStart, loopEnd int, offset int) envelope {
+	if count > 12 {
+		count = 12
+	}
+	e := envelope{
+		enabled:      typ&1 != 0 && count > 0,
+		sustain:      typ&2 != 0,
+		sustainStart: sustain,
+		sustainEnd:   sustain,
+		loop:         typ&4 != 0,
+		loopStart:    loopStart,
+		loopEnd:      loopEnd,
+	}
+	for i := 0; i < count; i++ {
+		e.points = append(e.points, envelopePoint{
+			tick:  int(binary.LittleEndian.Uint16(points[4*i:])),
+			value: int(binary.LittleEndian.Uint16(points[4*i+2:])) + offset,
+		})
+	}
+	validateEnvelope(&e)
+	return e
+}
+
+// convertXMVolume converts a value in the XM volume column.
+func convertXMVolume(v byte) (volumeCommand, byte) {
+	x := v & 0x0f
+	switch v >> 4 {
+	case 0x1, 0x2, 0x3, 0x4:
+		return volumeSet, v - 0x10
+	case 0x5:
+		if v == 0x50 {
+			return volumeSet, 64
+		}
+	case 0x6:
+		return volumeSlideDown, x
+	case 0x7:
+		return volumeSlideUp, x
+	case 0x8:
+		return volumeFineSlideDown, x
+	case 0x9:
+		return volumeFineSlideUp, x
+	case 0xa:
+		return volumeVibratoSpeed, x
+	case 0xb:
+		return volumeVibratoDepth, x
+	case 0xc:
+		return volumeSetPanning, x * 0x11
+	case 0xd:
+		return volumePanningSlideLeft, x
+	case 0xe:
+		return volumePanningSlideRight, x
+	case 0xf:
+		return volumeTonePorta, x << 4
+	}
+	return volumeNone, 0
+}
+
+// convertXMEffect converts an XM effect to an effect.
+func convertXMEffect(command, param byte) (effect, byte) {
+	if command < 0x10 {
+		return convertMODEffect(command, param)
+	}
+	x, y := param>>4, param&0x0f
+	switch command {
+	case 'G' - 'A' + 10:
+		// The global volume is in [0, 64] in XM.
+		if param > 64 {
+			param = 64
+		}
+		return effectSetGlobalVolume, param * 2
+	case 'H' - 'A' + 10:
+		return effectGlobalVolumeSlide, param
+	case 'K' - 'A' + 10:
+		return effectKeyOff, param
+	case 'P' - 'A' + 10:
+		return effectPanningSlide, param
+	case 'R' - 'A' + 10:
+		return effectRetrigger, param
+	case 'X' - 'A' + 10:
+		switch x {
+		case 1:
+			return effectExtraFinePortaUp, y
+		case 2:
+			return effectExtraFinePortaDown, y
+		}
+	}
+	return effectNone, 0
+}
+
+// fixLoop fixes the loop points of the sample to be in the sample data.
+func fixLoop(s *sample) {
+	if s.loopEnd > len(s.data) {
+		s.loopEnd = len(s.data)
+	}
+	if s.loopStart >= s.loopEnd {
+		s.loopType = loopNone
+	}
+	if s.sustainLoopEnd > len(s.data) {
+		s.sustainLoopEnd = len(s.data)
+	}
+	if s.sustainLoopStart >= s.sustainLoopEnd {
+		s.sustainLoopType = loopNone
+	}
+}
+
+// validateEnvelope fixes the loop and the sustain points of the envelope to be in the points.
+func validateEnvelope(e *envelope) {
+	n := len(e.points)
+	if e.loopStart >= n || e.loopEnd >= n || e.loopStart > e.loopEnd {
+		e.loop = false
+	}
+	if e.sustainStart >= n || e.sustainEnd >= n || e.sustainStart > e.sustainEnd {
+		e.sustain = false
+	}
+}
+
+// defaultMixGain returns the gain to mix the channels.
+func defaultMixGain(channelCount int) float64 {
+	return 1 / math.Sqrt(float64(channelCount))
+}
+
+// byteReader reads little endian values from a byte slice.
+// After an error, byteReader returns zero values.
+type byteReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if n < 0 || r.pos+n > len(r.data) || r.pos < 0 {
+		r.err = fmt.Errorf("tracker: unexpected EOF")
+		return make([]byte, n)
+	}
+	bs := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return bs
+}
+
+func (r *byteReader) uint8() byte {
+	return r.bytes(1)[0]
+}
+
+func (r *byteReader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.bytes(2))
+}
+
+func (r *byteReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.bytes(4))
+}
@@ -143,6 +143,31 @@ const (
 	//     1 - (destination alpha)
 	BlendFactorOneMinusDestinationAlpha
 
+	// BlendFactorSourceColor1 is a factor:
+	//
+	//     (secondary source RGBA)
+	//
+	// The secondary source color is the second color returned by a Kage shader's Fragment function.
+	// A blend with a secondary source factor is called dual-source blending.
+	// Dual-source blending is available only with a shader returning two colors, and with OpenGL (not OpenGL ES), Metal and DirectX.
+	// Use IsGraphicsFeatureAvailable with GraphicsFeatureDualSourceBlending to check whether dual-source blending is available.
+	BlendFactorSourceColor1
+
+	// BlendFactorOneMinusSourceColor1 is a factor:
+	//
+	//     1 - (secondary source RGBA)
+	BlendFactorOneMinusSourceColor1
+
+	// BlendFactorSourceAlpha1 is a factor:
+	//
+	//     (secondary source alpha)
+	BlendFactorSourceAlpha1
+
+	// BlendFactorOneMinusSourceAlpha1 is a factor:
+	//
+	//     1 - (secondary source alpha)
+	BlendFactorOneMinusSourceAlpha1
+
 	// TODO: Add BlendFactorSourceAlphaSaturated. This might not work well on some platforms like Steam SDK (#2382).
 )
 
@@ -174,6 +199,14 @@ func (b BlendFactor) internalBlendFactor(source bool) graphicsdriver.BlendFactor
 		return graphicsdriver.BlendFactorDestinationAlpha
 	case BlendFactorOneMinusDestinationAlpha:
 		return graphicsdriver.BlendFactorOneMinusDestinationAlpha
+	case BlendFactorSourceColor1:
+		return graphicsdriver.BlendFactorSourceColor1
+	case BlendFactorOneMinusSourceColor1:
+		return graphicsdriver.BlendFactorOneMinusSourceColor1
+	case BlendFactorSourceAlpha1:
+		return graphicsdriver.BlendFactorSourceAlpha1
+	case BlendFactorOneMinusSourceAlpha1:
+		return graphicsdriver.BlendFactorOneMinusSourceAlpha1
 	default:
 		panic(fmt.Sprintf("ebiten: invalid blend factor: %d", b))
 	}
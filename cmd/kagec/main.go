@@ -0,0 +1,220 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// kagec precompiles Kage shaders to binaries for the graphics libraries.
+//
+// Usage:
+//
+//	kagec [flags] file.go...
+//
+// The binaries are written to the output directory, and are loaded by ebiten.SetShaderCache at runtime.
+// For example, embed the directory and call ebiten.SetShaderCache with it:
+//
+//	//go:generate go run github.com/hajimehoshi/ebiten/v2/cmd/kagec -target metal -o shadercache shaders/*.go
+//
+//	//go:embed shadercache
+//	var shaderCache embed.FS
+//
+//	func init() {
+//		sub, _ := fs.Sub(shaderCache, "shadercache")
+//		ebiten.SetShaderCache(sub)
+//	}
+//
+// The targets are:
+//
+//	metal:   Metal libraries (.metallib). This requires Xcode's command line tools (xcrun).
+//	directx: FXC binaries for DirectX 11/12 (.vs.fxc and .ps.fxc). This requires fxc.exe in the Windows SDK.
+//
+// The other graphics libraries like OpenGL don't have portable binary formats, and are not targets.
+//
+// The binaries depend on the Ebitengine version. Generate the binaries again when updating Ebitengine.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+	"github.com/hajimehoshi/ebiten/v2/internal/shader"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/hlsl"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/msl"
+)
+
+var (
+	flagO         string // -o
+	flagTarget    string // -target
+	flagImportDir string // -importdir
+	flagMetalSDK  string // -metalsdk
+	flagV         bool   // -v
+)
+
+// The profiles and the entry points must match with the DirectX driver's.
+const (
+	fxcVertexShaderProfile    = "vs_4_0"
+	fxcPixelShaderProfile     = "ps_4_0"
+	fxcVertexShaderEntryPoint = "VSMain"
+	fxcPixelShaderEntryPoint  = "PSMain"
+)
+
+func main() {
+	flag.StringVar(&flagO, "o", ".", "output directory")
+	flag.StringVar(&flagTarget, "target", "", "comma-separated targets: metal, directx")
+	flag.StringVar(&flagImportDir, "importdir", "", "directory to resolve //kage:import; a path p is resolved to the file p.go in the directory")
+	flag.StringVar(&flagMetalSDK, "metalsdk", "macosx", "SDK for Metal: macosx, iphoneos, or iphonesimulator")
+	flag.BoolVar(&flagV, "v", false, "print the names of the generated files")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: kagec [flags] file.go...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(files []string) error {
+	if len(files) == 0 {
+		flag.Usage()
+		return fmt.Errorf("kagec: no input files")
+	}
+	if flagTarget == "" {
+		return fmt.Errorf("kagec: -target must be specified")
+	}
+	targets := strings.Split(flagTarget, ",")
+	for _, t := range targets {
+		if t != "metal" && t != "directx" {
+			return fmt.Errorf("kagec: invalid target: %s", t)
+		}
+	}
+
+	if err := os.MkdirAll(flagO, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp("", "kagec-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		ir, err := compile(src, flagImportDir)
+		if err != nil {
+			return fmt.Errorf("kagec: %s: %w", file, err)
+		}
+		for _, t := range targets {
+			var err error
+			switch t {
+			case "metal":
+				err = compileMetal(ir, tmp, flagO, flagMetalSDK)
+			case "directx":
+				err = compileFXC(ir, tmp, flagO)
+			}
+			if err != nil {
+				return fmt.Errorf("kagec: %s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compile compiles a Kage source in the same way as ebiten.NewShader.
+func compile(src []byte, importDir string) (*shaderir.Program, error) {
+	var resolve shader.ImportResolver
+	if importDir != "" {
+		resolve = func(path string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(importDir, filepath.FromSlash(path)+".go"))
+		}
+	}
+	src, err := shader.ExpandImports(src, resolve)
+	if err != nil {
+		return nil, err
+	}
+	return graphics.CompileShader(src)
+}
+
+func compileMetal(ir *shaderir.Program, tmp, out, sdk string) error {
+	name := ir.SourceHash.String()
+	metal := filepath.Join(tmp, name+".metal")
+	if err := os.WriteFile(metal, []byte(msl.Compile(ir)), 0644); err != nil {
+		return err
+	}
+	air := filepath.Join(tmp, name+".air")
+	if err := execCommand("xcrun", "-sdk", sdk, "metal", "-c", metal, "-o", air); err != nil {
+		return err
+	}
+	return execCommand("xcrun", "-sdk", sdk, "metallib", air, "-o", outputPath(out, ir, graphicsdriver.ShaderCacheMetalLibrarySuffix))
+}
+
+func compileFXC(ir *shaderir.Program, tmp, out string) error {
+	name := ir.SourceHash.String()
+	vs, ps, _ := hlsl.Compile(ir)
+	for _, s := range []struct {
+		src     string
+		profile string
+		entry   string
+		suffix  string
+	}{
+		{
+			src:     vs,
+			profile: fxcVertexShaderProfile,
+			entry:   fxcVertexShaderEntryPoint,
+			suffix:  graphicsdriver.ShaderCacheFXCVertexSuffix,
+		},
+		{
+			src:     ps,
+			profile: fxcPixelShaderProfile,
+			entry:   fxcPixelShaderEntryPoint,
+			suffix:  graphicsdriver.ShaderCacheFXCPixelSuffix,
+		},
+	} {
+		path := filepath.Join(tmp, name+s.suffix+".hlsl")
+		if err := os.WriteFile(path, []byte(s.src), 0644); err != nil {
+			return err
+		}
+		// /O3 corresponds to D3DCOMPILE_OPTIMIZATION_LEVEL3 used by the DirectX driver.
+		if err := execCommand("fxc", "/nologo", "/O3", "/T", s.profile, "/E", s.entry, "/Fo", outputPath(out, ir, s.suffix), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func outputPath(out string, ir *shaderir.Program, suffix string) string {
+	path := filepath.Join(out, ir.SourceHash.String()+suffix)
+	if flagV {
+		fmt.Println(path)
+	}
+	return path
+}
+
+func execCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w", strings.Join(append([]string{name}, args...), " "), err)
+	}
+	return nil
+}
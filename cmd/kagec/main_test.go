@@ -0,0 +1,69 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/shader"
+)
+
+func TestSourceHash(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	module := []byte(`package lib
+
+func Gray(c vec4) vec4 {
+	v := dot(c.rgb, vec3(0.299, 0.587, 0.114))
+	return vec4(v, v, v, c.a)
+}
+`)
+	if err := os.WriteFile(filepath.Join(dir, "lib", "gray.go"), module, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := []byte(`package main
+
+//kage:import "lib/gray"
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Gray(imageSrc0At(srcPos))
+}
+`)
+	ir, err := compile(src, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hash must be the same as the hash of the shader compiled at runtime.
+	expanded, err := shader.ExpandImports(src, func(path string) ([]byte, error) {
+		return module, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := graphics.CalcSourceHash(expanded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ir.SourceHash; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
@@ -158,8 +158,10 @@ func uniforms(c ColorM) map[string]any {
 }
 
 type builtinShaderKey struct {
-	filter  builtinshader.Filter
-	address builtinshader.Address
+	filter       builtinshader.Filter
+	addressX     builtinshader.Address
+	addressY     builtinshader.Address
+	gammaCorrect bool
 }
 
 var (
@@ -167,19 +169,21 @@ var (
 	builtinShadersM sync.Mutex
 )
 
-func builtinShader(filter builtinshader.Filter, address builtinshader.Address) *ebiten.Shader {
+func builtinShader(filter builtinshader.Filter, addressX, addressY builtinshader.Address, gammaCorrect bool) *ebiten.Shader {
 	builtinShadersM.Lock()
 	defer builtinShadersM.Unlock()
 
 	key := builtinShaderKey{
-		filter:  filter,
-		address: address,
+		filter:       filter,
+		addressX:     addressX,
+		addressY:     addressY,
+		gammaCorrect: gammaCorrect,
 	}
 	if s, ok := builtinShaders[key]; ok {
 		return s
 	}
 
-	src := builtinshader.ShaderSource(filter, address, true)
+	src := builtinshader.ShaderSource(filter, addressX, addressY, true, gammaCorrect)
 	s, err := ebiten.NewShader(src)
 	if err != nil {
 		panic(fmt.Sprintf("colorm: NewShader for a built-in shader failed: %v", err))
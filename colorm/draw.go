@@ -32,6 +32,11 @@ type DrawImageOptions struct {
 	// Filter is a type of texture filter.
 	// The default (zero) value is ebiten.FilterNearest.
 	Filter ebiten.Filter
+
+	// GammaCorrect indicates whether the filter blends the source colors in the linear space.
+	// See ebiten.DrawImageOptions.GammaCorrect for details.
+	// The default (zero) value is false.
+	GammaCorrect bool
 }
 
 // DrawImage draws src onto dst.
@@ -48,7 +53,7 @@ func DrawImage(dst, src *ebiten.Image, colorM ColorM, op *DrawImageOptions) {
 	opShader.Blend = op.Blend
 	opShader.Uniforms = uniforms(colorM)
 	opShader.Images[0] = src
-	s := builtinShader(builtinshader.Filter(op.Filter), builtinshader.AddressUnsafe)
+	s := builtinShader(builtinshader.Filter(op.Filter), builtinshader.AddressUnsafe, builtinshader.AddressUnsafe, op.GammaCorrect)
 	dst.DrawRectShader(src.Bounds().Dx(), src.Bounds().Dy(), s, opShader)
 }
 
@@ -70,6 +75,17 @@ type DrawTrianglesOptions struct {
 	// The default (zero) value is ebiten.AddressUnsafe.
 	Address ebiten.Address
 
+	// AddressX and AddressY are sampler address modes for the X and Y axes.
+	// AddressX and AddressY are used only when Address is ebiten.AddressUnsafe.
+	// The default (zero) values are ebiten.AddressUnsafe.
+	AddressX ebiten.Address
+	AddressY ebiten.Address
+
+	// GammaCorrect indicates whether the filter blends the source colors in the linear space.
+	// See ebiten.DrawTrianglesOptions.GammaCorrect for details.
+	// The default (zero) value is false.
+	GammaCorrect bool
+
 	// FillRule indicates the rule how an overlapped region is rendered.
 	//
 	// The rules FileRuleNonZero and FillRuleEvenOdd are useful when you want to render a complex polygon.
@@ -115,6 +131,10 @@ func DrawTriangles(dst *ebiten.Image, vertices []ebiten.Vertex, indices []uint16
 	opShader.AntiAlias = op.AntiAlias
 	opShader.Uniforms = uniforms(colorM)
 	opShader.Images[0] = img
-	s := builtinShader(builtinshader.Filter(op.Filter), builtinshader.Address(op.Address))
+	addressX, addressY := builtinshader.Address(op.Address), builtinshader.Address(op.Address)
+	if op.Address == ebiten.AddressUnsafe {
+		addressX, addressY = builtinshader.Address(op.AddressX), builtinshader.Address(op.AddressY)
+	}
+	s := builtinShader(builtinshader.Filter(op.Filter), addressX, addressY, op.GammaCorrect)
 	dst.DrawTrianglesShader(vertices, indices, s, opShader)
 }
@@ -0,0 +1,156 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/ui"
+)
+
+// ComputeShader represents a compiled compute kernel.
+type ComputeShader struct {
+	shader *ui.Shader
+
+	// storageImageCount is the number of the storage images the kernel accesses.
+	storageImageCount int
+
+	tmpUniforms []uint32
+}
+
+// NewComputeShader compiles a compute kernel in the shading language Kage, and returns the result.
+//
+// A compute kernel must have the //kage:compute directive with the workgroup size, and an entry point function Compute:
+//
+//	//kage:compute 8 8 1
+//
+//	package main
+//
+//	func Compute(id ivec3, localID ivec3, groupID ivec3) {
+//		imageStore(0, id.xy, vec4(1))
+//	}
+//
+// The parameters of Compute are the global invocation ID, the local invocation ID in the workgroup, and the workgroup ID.
+// The parameters can be omitted from the end.
+//
+// A compute kernel reads and writes the pixels of the storage images with imageLoad(index, pos) and
+// imageStore(index, pos, color), where index is a constant storage image index and pos is an ivec2 position.
+// The behavior of imageLoad and imageStore out of the image bounds is undefined.
+// A global variable with the //kage:shared directive is shared among the invocations in the same workgroup.
+// barrier() synchronizes the invocations in the same workgroup.
+//
+// Compute kernels are available only with some graphics libraries. See GraphicsFeatureCompute.
+// With DirectX, a kernel cannot both read and write the same storage image, and NewComputeShader returns an error in this case.
+//
+// If the compilation fails, NewComputeShader returns an error.
+func NewComputeShader(src []byte) (*ComputeShader, error) {
+	ir, err := graphics.CompileComputeShader(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(ir.RuntimeSizedUniforms) > 0 {
+		return nil, fmt.Errorf("ebiten: a compute kernel cannot have a runtime-sized uniform variable")
+	}
+	return &ComputeShader{
+		shader:            ui.NewShader(ir),
+		storageImageCount: ir.StorageImageCount,
+	}, nil
+}
+
+// Deallocate deallocates the internal state of the compute kernel.
+// Even after Deallocate is called, the compute kernel is still available.
+// In this case, the compute kernel's internal state is allocated again.
+func (s *ComputeShader) Deallocate() {
+	s.shader.Deallocate()
+}
+
+// DispatchComputeOptions represents options for DispatchCompute.
+type DispatchComputeOptions struct {
+	// Uniforms is a set of uniform variables for the compute kernel.
+	// The rules are the same as DrawTrianglesShaderOptions's Uniforms.
+	Uniforms map[string]any
+
+	// Images is a set of the additional storage images.
+	// Images[k] is the storage image k+1. The storage image 0 is the receiver of DispatchCompute.
+	// All the images' sizes can be different.
+	Images []*Image
+}
+
+// DispatchCompute executes the compute kernel on the GPU with x * y * z workgroups.
+//
+// The image i is the storage image 0, and options.Images are the following storage images.
+// The number of the storage images must match with the indices the kernel accesses.
+// The position given to imageLoad and imageStore is relative to the image's upper-left corner.
+//
+// DispatchCompute is executed as a part of the regular rendering commands,
+// so the results are visible to the following draw calls and pixel reads.
+//
+// When compute kernels are not available with the current graphics library, DispatchCompute does nothing.
+// See GraphicsFeatureCompute.
+//
+// If x, y, or z is not positive, DispatchCompute does nothing.
+//
+// If an image is a sub-image, the screen, a multisampled image, or an image with a format other than FormatRGBA8,
+// DispatchCompute panics.
+// If the same image is given more than once, DispatchCompute panics.
+//
+// When the given image is disposed, DispatchCompute panics.
+//
+// When the image i is disposed, DispatchCompute does nothing.
+func (i *Image) DispatchCompute(shader *ComputeShader, x, y, z int, options *DispatchComputeOptions) {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
+	}
+
+	if options == nil {
+		options = &DispatchComputeOptions{}
+	}
+
+	if n := 1 + len(options.Images); n != shader.storageImageCount {
+		panic(fmt.Sprintf("ebiten: the number of the storage images must be %d but %d", shader.storageImageCount, n))
+	}
+
+	imgs := make([]*ui.Image, 0, 1+len(options.Images))
+	for _, img := range append([]*Image{i}, options.Images...) {
+		if img == nil {
+			panic("ebiten: the given image to DispatchCompute must not be nil")
+		}
+		if img.isDisposed() {
+			panic("ebiten: the given image to DispatchCompute must not be disposed")
+		}
+		if img.isSubImage() {
+			panic("ebiten: a sub-image cannot be a storage image at DispatchCompute")
+		}
+		if img.pixelFormat() != FormatRGBA8 {
+			panic("ebiten: the format of a storage image must be FormatRGBA8 at DispatchCompute")
+		}
+		for _, u := range imgs {
+			if u == img.image {
+				panic("ebiten: the same image cannot be given more than once to DispatchCompute")
+			}
+		}
+		imgs = append(imgs, img.image)
+	}
+
+	if x <= 0 || y <= 0 || z <= 0 {
+		return
+	}
+
+	shader.tmpUniforms = shader.shader.AppendUniforms(shader.tmpUniforms[:0], options.Uniforms)
+	ui.DispatchCompute(shader.shader, imgs, shader.tmpUniforms, x, y, z)
+}
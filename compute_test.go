@@ -0,0 +1,262 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func skipIfComputeIsNotAvailable(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureCompute) {
+		t.Skip("compute kernels are not available")
+	}
+}
+
+func TestImageDispatchCompute(t *testing.T) {
+	skipIfComputeIsNotAvailable(t)
+
+	s, err := ebiten.NewComputeShader([]byte(`//kage:compute 8 8 1
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(float(id.x)/255, float(id.y)/255, 0, 1))
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const w, h = 16, 16
+	dst := ebiten.NewImage(w, h)
+	dst.Fill(color.White)
+
+	dst.DispatchCompute(s, w/8, h/8, 1, nil)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{R: byte(i), G: byte(j), A: 0xff}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDispatchComputeWithUniforms(t *testing.T) {
+	skipIfComputeIsNotAvailable(t)
+
+	s, err := ebiten.NewComputeShader([]byte(`//kage:compute 4 4 1
+
+package main
+
+var Color vec4
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, Color)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const w, h = 16, 16
+	dst := ebiten.NewImage(w, h)
+
+	// Dispatch only a part of the image.
+	dst.DispatchCompute(s, 2, 1, 1, &ebiten.DispatchComputeOptions{
+		Uniforms: map[string]any{
+			"Color": []float32{0, 0, 1, 1},
+		},
+	})
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if i < 8 && j < 4 {
+				want = color.RGBA{B: 0xff, A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDispatchComputeWithSourceImage(t *testing.T) {
+	skipIfComputeIsNotAvailable(t)
+
+	s, err := ebiten.NewComputeShader([]byte(`//kage:compute 8 8 1
+
+package main
+
+func Compute(id ivec3) {
+	// Flip the source image horizontally.
+	imageStore(0, id.xy, imageLoad(1, ivec2(15-id.x, id.y)))
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const w, h = 16, 16
+	src := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (j*w + i)
+			pix[idx] = byte(i)
+			pix[idx+1] = byte(j)
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix)
+
+	dst := ebiten.NewImage(w, h)
+	dst.DispatchCompute(s, w/8, h/8, 1, &ebiten.DispatchComputeOptions{
+		Images: []*ebiten.Image{src},
+	})
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j)
+			want := src.At(w-i-1, j)
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDispatchComputeFallback(t *testing.T) {
+	if ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureCompute) {
+		t.Skip("compute kernels are available")
+	}
+
+	s, err := ebiten.NewComputeShader([]byte(`//kage:compute 8 8 1
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(16, 16)
+
+	// Without compute kernels, DispatchCompute does nothing.
+	dst.DispatchCompute(s, 2, 2, 1, nil)
+	if got, want := dst.At(0, 0), (color.RGBA{}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageDispatchComputeInvalidImages(t *testing.T) {
+	s, err := ebiten.NewComputeShader([]byte(`//kage:compute 8 8 1
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, imageLoad(1, id.xy))
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(16, 16)
+	src := ebiten.NewImage(16, 16)
+
+	for _, tc := range []struct {
+		name    string
+		dst     *ebiten.Image
+		options *ebiten.DispatchComputeOptions
+	}{
+		{
+			name: "too few images",
+			dst:  dst,
+		},
+		{
+			name: "too many images",
+			dst:  dst,
+			options: &ebiten.DispatchComputeOptions{
+				Images: []*ebiten.Image{src, ebiten.NewImage(16, 16)},
+			},
+		},
+		{
+			name: "sub-image",
+			dst:  dst.SubImage(image.Rect(0, 0, 8, 8)).(*ebiten.Image),
+			options: &ebiten.DispatchComputeOptions{
+				Images: []*ebiten.Image{src},
+			},
+		},
+		{
+			name: "same images",
+			dst:  dst,
+			options: &ebiten.DispatchComputeOptions{
+				Images: []*ebiten.Image{dst},
+			},
+		},
+		{
+			name: "RGBA16 image",
+			dst:  dst,
+			options: &ebiten.DispatchComputeOptions{
+				Images: []*ebiten.Image{ebiten.NewImageWithOptions(image.Rect(0, 0, 16, 16), &ebiten.NewImageOptions{
+					Format: ebiten.FormatRGBA16,
+				})},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("DispatchCompute must panic but not")
+				}
+			}()
+			tc.dst.DispatchCompute(s, 2, 2, 1, tc.options)
+		})
+	}
+}
+
+func TestNewComputeShaderWithoutEntryPoint(t *testing.T) {
+	if _, err := ebiten.NewComputeShader([]byte(`//kage:compute 8 8 1
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)); err == nil {
+		t.Errorf("NewComputeShader must return an error but not")
+	}
+}
+
+func TestNewComputeShaderWithoutDirective(t *testing.T) {
+	if _, err := ebiten.NewComputeShader([]byte(`package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}
+`)); err == nil {
+		t.Errorf("NewComputeShader must return an error but not")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+)
+
+// ClearDepth resets the depth buffer of the image with the farthest value.
+// The colors of the image are not changed.
+//
+// ClearDepth affects only the image's bounds. If the image is a sub-image, only the sub-image's region is reset.
+//
+// If the image doesn't have a depth buffer, ClearDepth panics.
+//
+// When the image is disposed, ClearDepth does nothing.
+func (i *Image) ClearDepth() {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
+	}
+	if !i.hasDepth() {
+		panic("ebiten: ClearDepth cannot be called on an image without a depth buffer")
+	}
+
+	i.image.ClearDepth(i.adjustedBounds())
+}
+
+func (i *Image) hasDepth() bool {
+	if i.isSubImage() {
+		return i.original.depth
+	}
+	return i.depth
+}
+
+// depthMode returns the internal depth mode for rendering onto the image.
+//
+// depthMode panics if the depth buffer cannot be used with the given options.
+func (i *Image) depthMode(test, write bool, fillRule FillRule, antialias bool) graphicsdriver.DepthMode {
+	if !test && !write {
+		return graphicsdriver.DepthModeNone
+	}
+
+	if !i.hasDepth() {
+		panic("ebiten: DepthTest and DepthWrite are available only for an image with a depth buffer")
+	}
+	if fillRule != FillRuleFillAll {
+		panic("ebiten: DepthTest and DepthWrite cannot be used with FillRuleNonZero or FillRuleEvenOdd")
+	}
+	if antialias {
+		panic("ebiten: DepthTest and DepthWrite cannot be used with AntiAlias")
+	}
+
+	switch {
+	case test && write:
+		return graphicsdriver.DepthModeTestAndWrite
+	case test:
+		return graphicsdriver.DepthModeTest
+	default:
+		return graphicsdriver.DepthModeWrite
+	}
+}
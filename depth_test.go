@@ -0,0 +1,116 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func skipIfDepthIsNotAvailable(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureDepth) {
+		t.Skip("depth buffers are not available")
+	}
+}
+
+func drawQuadWithDepth(dst *ebiten.Image, clr color.RGBA, depth float32, op *ebiten.DrawTrianglesOptions) {
+	src := ebiten.NewImage(1, 1)
+	src.Fill(clr)
+
+	vs := quadVertices(0, 0, 16, 16)
+	for i := range vs {
+		vs[i].Custom0 = depth
+	}
+	dst.DrawTriangles(vs, []uint16{0, 1, 2, 1, 2, 3}, src, op)
+}
+
+func TestImageDepth(t *testing.T) {
+	skipIfDepthIsNotAvailable(t)
+
+	dst := ebiten.NewImageWithOptions(image.Rect(0, 0, 16, 16), &ebiten.NewImageOptions{
+		Depth: true,
+	})
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	green := color.RGBA{G: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+
+	op := &ebiten.DrawTrianglesOptions{
+		DepthTest:  true,
+		DepthWrite: true,
+	}
+	drawQuadWithDepth(dst, red, 0.5, op)
+	drawQuadWithDepth(dst, green, 0.75, op)
+	if got, want := dst.At(8, 8), red; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	drawQuadWithDepth(dst, blue, 0.25, op)
+	if got, want := dst.At(8, 8), blue; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	dst.ClearDepth()
+	drawQuadWithDepth(dst, green, 0.75, op)
+	if got, want := dst.At(8, 8), green; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// Without DepthTest, the depth buffer is ignored.
+	drawQuadWithDepth(dst, red, 1, nil)
+	if got, want := dst.At(8, 8), red; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageDepthFallback(t *testing.T) {
+	if ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureDepth) {
+		t.Skip("depth buffers are available")
+	}
+
+	dst := ebiten.NewImageWithOptions(image.Rect(0, 0, 16, 16), &ebiten.NewImageOptions{
+		Depth: true,
+	})
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	green := color.RGBA{G: 0xff, A: 0xff}
+
+	// Without depth buffers, DepthTest and DepthWrite are ignored and the last triangles are rendered on top.
+	op := &ebiten.DrawTrianglesOptions{
+		DepthTest:  true,
+		DepthWrite: true,
+	}
+	drawQuadWithDepth(dst, red, 0.5, op)
+	drawQuadWithDepth(dst, green, 0.75, op)
+	if got, want := dst.At(8, 8), green; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageDepthWithoutDepthBuffer(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawTriangles must panic but not")
+		}
+	}()
+
+	dst := ebiten.NewImage(16, 16)
+	drawQuadWithDepth(dst, color.RGBA{R: 0xff, A: 0xff}, 0.5, &ebiten.DrawTrianglesOptions{
+		DepthTest: true,
+	})
+}
@@ -54,6 +54,12 @@
 // DeviceScaleFactor) that must be called on the main thread under some conditions (typically, before ebiten.RunGame
 // is called).
 //
+// # Profiling
+//
+// Ebitengine's game loop sets the pprof label "ebitengine" to the phase being executed: "update", "draw", "atlas",
+// "flush" or "present". You can filter a CPU profile by these labels, e.g. `go tool pprof -tagfocus=ebitengine=draw`.
+// With runtime/trace, each frame is recorded as a task "ebitengine.frame", and the phases are recorded as regions.
+//
 // # Environment variables
 //
 // `EBITENGINE_SCREENSHOT_KEY` environment variable specifies the key
@@ -0,0 +1,170 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ShaderWatcher watches Kage shader files in a file system, and reloads the shaders when the files are modified.
+//
+// A `//kage:import "p"` directive in a shader file is resolved to the file p.go in the same file system.
+// A shader is reloaded also when its imported files are modified.
+//
+// ShaderWatcher is useful to iterate shaders without restarting the game.
+type ShaderWatcher struct {
+	fsys    fs.FS
+	shaders map[string]*watchedShader
+
+	// Interval is the interval to check the files. If Interval is 0, the files are checked every second.
+	Interval time.Duration
+
+	lastChecked time.Time
+}
+
+type watchedShader struct {
+	shader *ebiten.Shader
+
+	// files is the modification states of the shader file and the imported files.
+	files map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewShaderWatcher creates a new ShaderWatcher for the shader files in the directory.
+func NewShaderWatcher(dir string) *ShaderWatcher {
+	return NewShaderWatcherFromFileSystem(os.DirFS(dir))
+}
+
+// NewShaderWatcherFromFileSystem creates a new ShaderWatcher for the shader files in the file system.
+//
+// The file system must report the modification times of the files by Stat to detect modifications.
+func NewShaderWatcherFromFileSystem(fsys fs.FS) *ShaderWatcher {
+	return &ShaderWatcher{
+		fsys:    fsys,
+		shaders: map[string]*watchedShader{},
+	}
+}
+
+// Shader returns the shader for the file at the given path, compiling it at the first call.
+//
+// The returned shader is kept the same even after the file is modified and the shader is reloaded.
+func (w *ShaderWatcher) Shader(path string) (*ebiten.Shader, error) {
+	if s, ok := w.shaders[path]; ok {
+		return s.shader, nil
+	}
+
+	src, files, err := w.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ws := &watchedShader{
+		files: files,
+	}
+	s, err := ebiten.NewShaderWithOptions(src, &ebiten.NewShaderOptions{
+		ImportResolver: w.importResolver(ws),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ws.shader = s
+	w.shaders[path] = ws
+	return s, nil
+}
+
+// Update checks the shader files, and reloads the shaders whose files are modified.
+// Update should be called every tick, e.g., in Game's Update.
+//
+// If reloading a shader fails, the shader is kept as it is and Update returns the error.
+// The error is returned only once for each modification.
+func (w *ShaderWatcher) Update() error {
+	interval := w.Interval
+	if interval == 0 {
+		interval = time.Second
+	}
+	now := time.Now()
+	if now.Sub(w.lastChecked) < interval {
+		return nil
+	}
+	w.lastChecked = now
+
+	for path, s := range w.shaders {
+		if !w.modified(s) {
+			continue
+		}
+		src, files, err := w.readFile(path)
+		// Update the states even on failures not to report the same error again.
+		// The import resolver adds the states of the imported files to s.files.
+		s.files = files
+		if err != nil {
+			return err
+		}
+		if err := ebiten.ReplaceShader(s.shader, src); err != nil {
+			return fmt.Errorf("ebitenutil: reloading the shader %s failed: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (w *ShaderWatcher) modified(s *watchedShader) bool {
+	for path, st := range s.files {
+		if w.stat(path) != st {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *ShaderWatcher) stat(path string) fileState {
+	fi, err := fs.Stat(w.fsys, path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+	}
+}
+
+// readFile reads a file, and returns the file content and the states of the read files.
+func (w *ShaderWatcher) readFile(path string) ([]byte, map[string]fileState, error) {
+	files := map[string]fileState{
+		path: w.stat(path),
+	}
+	src, err := fs.ReadFile(w.fsys, path)
+	if err != nil {
+		return nil, files, err
+	}
+	return src, files, nil
+}
+
+// importResolver returns an import resolver reading files from the file system.
+// The states of the imported files are recorded to the shader's files.
+func (w *ShaderWatcher) importResolver(s *watchedShader) func(string) ([]byte, error) {
+	return func(p string) ([]byte, error) {
+		name := path.Clean(p) + ".go"
+		s.files[name] = w.stat(name)
+		return fs.ReadFile(w.fsys, name)
+	}
+}
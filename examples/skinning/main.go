@@ -0,0 +1,235 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/examples/resources/images"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	screenWidth  = 640
+	screenHeight = 480
+
+	// boneCount must be the same as the length of Bones in the shader.
+	boneCount = 16
+
+	stripWidth  = 480
+	stripHeight = 120
+	stripX      = (screenWidth - stripWidth) / 2
+	stripY      = (screenHeight - stripHeight) / 2
+
+	boneLength = stripWidth / boneCount
+
+	// columnCount is the number of the vertex columns of the strip mesh.
+	columnCount = boneCount*4 + 1
+)
+
+var (
+	//go:embed skinning.go
+	skinning_go []byte
+
+	gophersImage *ebiten.Image
+)
+
+func init() {
+	img, _, err := image.Decode(bytes.NewReader(images.Gophers_jpg))
+	if err != nil {
+		log.Fatal(err)
+	}
+	gophersImage = ebiten.NewImageFromImage(img)
+}
+
+type Game struct {
+	shader *ebiten.Shader
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+
+	// bones is the bone matrices in column-major order, passed to the shader as they are.
+	bones  [][16]float32
+	joints [boneCount + 1][2]float32
+
+	time      int
+	showBones bool
+}
+
+func NewGame() (*Game, error) {
+	s, err := ebiten.NewShader(skinning_go)
+	if err != nil {
+		return nil, err
+	}
+	g := &Game{
+		shader: s,
+		bones:  make([][16]float32, boneCount),
+	}
+	g.initMesh()
+	return g, nil
+}
+
+// initMesh creates a strip mesh in the bind pose.
+// Each vertex is bound to the two nearest bones with the weights in Custom4-Custom7.
+func (g *Game) initMesh() {
+	b := gophersImage.Bounds()
+	// Use the center part of the image with the same aspect ratio as the strip.
+	sw := float32(b.Dx())
+	sh := sw * stripHeight / stripWidth
+	sy := (float32(b.Dy()) - sh) / 2
+
+	for i := 0; i < columnCount; i++ {
+		r := float32(i) / (columnCount - 1)
+		x := r * stripWidth
+
+		// Blend the bones linearly between their centers.
+		u := x/boneLength - 0.5
+		b0 := int(math.Floor(float64(u)))
+		if b0 < 0 {
+			b0 = 0
+		}
+		if b0 > boneCount-1 {
+			b0 = boneCount - 1
+		}
+		b1 := b0 + 1
+		if b1 > boneCount-1 {
+			b1 = boneCount - 1
+		}
+		w1 := u - float32(b0)
+		if w1 < 0 {
+			w1 = 0
+		}
+		if w1 > 1 {
+			w1 = 1
+		}
+
+		for j := 0; j < 2; j++ {
+			g.vertices = append(g.vertices, ebiten.Vertex{
+				DstX:    stripX + x,
+				DstY:    stripY + float32(j)*stripHeight,
+				SrcX:    r * sw,
+				SrcY:    sy + float32(j)*sh,
+				ColorR:  1,
+				ColorG:  1,
+				ColorB:  1,
+				ColorA:  1,
+				Custom4: float32(b0),
+				Custom5: float32(b1),
+				Custom6: 1 - w1,
+				Custom7: w1,
+			})
+		}
+		if i > 0 {
+			n := uint16(2 * i)
+			g.indices = append(g.indices, n-2, n-1, n, n-1, n, n+1)
+		}
+	}
+}
+
+func (g *Game) Update() error {
+	g.time++
+	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		g.showBones = !g.showBones
+	}
+
+	// Update the bone chain.
+	// The skinning matrix of a bone transforms a position in the bind pose to the current pose.
+	var parent ebiten.GeoM
+	parent.Translate(stripX, stripY+stripHeight/2)
+	g.joints[0] = [2]float32{stripX, stripY + stripHeight/2}
+	for i := 0; i < boneCount; i++ {
+		t := float64(g.time) / 60
+		angle := 0.12 * math.Sin(2*t-float64(i)*0.4)
+
+		var world ebiten.GeoM
+		world.Rotate(angle)
+		if i > 0 {
+			world.Translate(boneLength, 0)
+		}
+		world.Concat(parent)
+		parent = world
+
+		var skin ebiten.GeoM
+		skin.Translate(-(stripX + float64(i)*boneLength), -(stripY + stripHeight/2))
+		skin.Concat(world)
+		g.bones[i] = geoMToMat4(&skin)
+
+		x, y := world.Apply(boneLength, 0)
+		g.joints[i+1] = [2]float32{float32(x), float32(y)}
+	}
+	return nil
+}
+
+// geoMToMat4 converts a GeoM to a 4x4 matrix in column-major order.
+func geoMToMat4(g *ebiten.GeoM) [16]float32 {
+	a := float32(g.Element(0, 0))
+	b := float32(g.Element(0, 1))
+	c := float32(g.Element(1, 0))
+	d := float32(g.Element(1, 1))
+	tx := float32(g.Element(0, 2))
+	ty := float32(g.Element(1, 2))
+	return [16]float32{
+		a, c, 0, 0,
+		b, d, 0, 0,
+		0, 0, 1, 0,
+		tx, ty, 0, 1,
+	}
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	op := &ebiten.DrawTrianglesShaderOptions{}
+	op.Images[0] = gophersImage
+	op.Uniforms = map[string]any{
+		// [][16]float32 is passed to the shader without reflection.
+		"Bones": g.bones,
+	}
+	screen.DrawTrianglesShader(g.vertices, g.indices, g.shader, op)
+
+	if g.showBones {
+		for i := 0; i < boneCount; i++ {
+			j0, j1 := g.joints[i], g.joints[i+1]
+			vector.StrokeLine(screen, j0[0], j0[1], j1[0], j1[1], 2, color.RGBA{0xff, 0, 0, 0xff}, true)
+		}
+	}
+
+	msg := fmt.Sprintf("TPS: %0.2f\nFPS: %0.2f\nBones: %d\nPress B to toggle the bones", ebiten.ActualTPS(), ebiten.ActualFPS(), boneCount)
+	ebitenutil.DebugPrint(screen, msg)
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
+}
+
+func main() {
+	g, err := NewGame()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle("Skinning (Ebitengine Demo)")
+	if err := ebiten.RunGame(g); err != nil {
+		log.Fatal(err)
+	}
+}
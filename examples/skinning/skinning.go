@@ -0,0 +1,32 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+//kage:unit pixels
+
+package main
+
+var Bones [16]mat4
+
+// VertexPosition blends the positions transformed by two bones.
+// custom1.xy is the bone indices and custom1.zw is their weights.
+func VertexPosition(pos vec2, custom vec4, custom1 vec4) vec2 {
+	p := vec4(pos, 0, 1)
+	return (Bones[int(custom1.x)]*p*custom1.z + Bones[int(custom1.y)]*p*custom1.w).xy
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return imageSrc0At(srcPos) * color
+}
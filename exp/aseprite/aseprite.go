@@ -0,0 +1,375 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aseprite provides a loader of Aseprite (https://www.aseprite.org/) files (.aseprite and .ase).
+// This package is experimental and the API might be changed in the future.
+//
+// The loader composes the visible layers into an image for each frame, and also provides the layers, the cels,
+// the tags and the slices as they are.
+//
+// Only the normal blend mode is supported for composition, and the other blend modes are treated as normal.
+// Tilemap layers are not composed.
+package aseprite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// File is a decoded Aseprite file.
+type File struct {
+	// Width and Height are the size of the sprite in pixels.
+	Width  int
+	Height int
+
+	// Layers is the layers from the bottom to the top, including group layers.
+	Layers []*Layer
+
+	// Frames is the frames of the sprite.
+	Frames []*Frame
+
+	// Tags is the animation tags.
+	Tags []*Tag
+
+	// Slices is the slices.
+	Slices []*Slice
+
+	// Palette is the palette of the sprite, or nil if the sprite has no palette.
+	Palette color.Palette
+}
+
+// Tag returns the tag with the name, or nil if there is no such tag.
+func (f *File) Tag(name string) *Tag {
+	for _, t := range f.Tags {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Slice returns the slice with the name, or nil if there is no such slice.
+func (f *File) Slice(name string) *Slice {
+	for _, s := range f.Slices {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// LayerKind represents a kind of a layer.
+type LayerKind int
+
+const (
+	// LayerKindNormal is a normal image layer.
+	LayerKindNormal LayerKind = iota
+
+	// LayerKindGroup is a group layer.
+	LayerKindGroup
+
+	// LayerKindTilemap is a tilemap layer.
+	LayerKindTilemap
+)
+
+// Layer is a layer.
+type Layer struct {
+	Name string
+	Kind LayerKind
+
+	// Visible reports whether the layer itself is visible.
+	// A layer is not composed when any of its parent groups is invisible even if Visible is true.
+	Visible bool
+
+	// Background reports whether the layer is a background layer.
+	Background bool
+
+	// Opacity is the opacity of the layer.
+	Opacity uint8
+
+	// BlendMode is the blend mode of the layer as the value in the Aseprite file format. 0 is normal.
+	BlendMode int
+
+	// Parent is the parent group layer, or nil.
+	Parent *Layer
+}
+
+func (l *Layer) composed() bool {
+	for l := l; l != nil; l = l.Parent {
+		if !l.Visible {
+			return false
+		}
+	}
+	return l.Kind == LayerKindNormal
+}
+
+// Frame is a frame.
+type Frame struct {
+	// Duration is the duration of the frame.
+	Duration time.Duration
+
+	// Cels is the cels of the frame.
+	Cels []*Cel
+
+	// Image is the image composed from the cels of the visible layers.
+	// The size is the same as the sprite's.
+	Image *ebiten.Image
+}
+
+// Cel is an image of a layer in a frame.
+type Cel struct {
+	// Layer is the index of the layer in File.Layers.
+	Layer int
+
+	// X and Y are the position of the cel in the sprite.
+	X int
+	Y int
+
+	// Opacity is the opacity of the cel.
+	Opacity uint8
+
+	// ZIndex is the z-index of the cel relative to the layer.
+	ZIndex int
+
+	// Image is the image of the cel, or nil for a tilemap cel.
+	// Linked cels share the same image.
+	Image *ebiten.Image
+
+	img *image.NRGBA
+}
+
+// Direction represents an animation direction of a tag.
+type Direction int
+
+const (
+	// DirectionForward plays the frames from From to To.
+	DirectionForward Direction = iota
+
+	// DirectionReverse plays the frames from To to From.
+	DirectionReverse
+
+	// DirectionPingPong plays the frames from From to To, and then back to From.
+	DirectionPingPong
+
+	// DirectionPingPongReverse plays the frames from To to From, and then back to To.
+	DirectionPingPongReverse
+)
+
+// Tag is an animation tag.
+type Tag struct {
+	Name string
+
+	// From and To are the indices of the first and the last frames, inclusive.
+	From int
+	To   int
+
+	Direction Direction
+
+	// Repeat is the number of the repetitions of the animation. 0 means infinite.
+	Repeat int
+}
+
+// Slice is a slice.
+type Slice struct {
+	Name string
+
+	// Keys is the keys of the slice in the order of the frames.
+	Keys []SliceKey
+}
+
+// KeyAt returns the key for the frame index.
+// KeyAt returns false if the slice is not defined at the frame.
+func (s *Slice) KeyAt(frame int) (SliceKey, bool) {
+	var key SliceKey
+	var found bool
+	for _, k := range s.Keys {
+		if k.Frame > frame {
+			break
+		}
+		key = k
+		found = true
+	}
+	// A key with an empty size means the slice is removed from the frame.
+	if !found || key.Bounds.Empty() {
+		return SliceKey{}, false
+	}
+	return key, true
+}
+
+// SliceKey is a key of a slice from a frame.
+type SliceKey struct {
+	// Frame is the index of the first frame of the key.
+	Frame int
+
+	// Bounds is the bounds of the slice in the sprite.
+	Bounds image.Rectangle
+
+	// Center is the center region of a 9-patch slice relative to Bounds, or an empty rectangle.
+	Center image.Rectangle
+
+	// Pivot is the pivot point relative to Bounds. Pivot is valid only if HasPivot is true.
+	Pivot    image.Point
+	HasPivot bool
+}
+
+// FrameAt returns the index of the frame to show at the elapsed time from the start of the animation of the tag.
+// If tag is nil, the whole frames are played forward repeatedly.
+//
+// When the tag's repetitions finish, FrameAt returns the last frame of the animation.
+func (f *File) FrameAt(tag *Tag, elapsed time.Duration) int {
+	if len(f.Frames) == 0 {
+		return 0
+	}
+	var seq []int
+	var repeat int
+	if tag == nil {
+		for i := range f.Frames {
+			seq = append(seq, i)
+		}
+	} else {
+		seq = tag.sequence()
+		repeat = tag.Repeat
+	}
+
+	var total time.Duration
+	for _, i := range seq {
+		total += f.Frames[i].Duration
+	}
+	if total <= 0 {
+		return seq[0]
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if repeat > 0 && elapsed >= total*time.Duration(repeat) {
+		return seq[len(seq)-1]
+	}
+	elapsed %= total
+	for _, i := range seq {
+		if elapsed < f.Frames[i].Duration {
+			return i
+		}
+		elapsed -= f.Frames[i].Duration
+	}
+	return seq[len(seq)-1]
+}
+
+// sequence returns the frame indices of one iteration of the tag's animation.
+func (t *Tag) sequence() []int {
+	var forward []int
+	for i := t.From; i <= t.To; i++ {
+		forward = append(forward, i)
+	}
+	reverse := make([]int, len(forward))
+	for i, v := range forward {
+		reverse[len(forward)-1-i] = v
+	}
+	switch t.Direction {
+	case DirectionReverse:
+		return reverse
+	case DirectionPingPong:
+		// The end frames are not repeated: 0, 1, 2, 1.
+		if len(forward) > 2 {
+			return append(forward, reverse[1:len(reverse)-1]...)
+		}
+		return forward
+	case DirectionPingPongReverse:
+		if len(reverse) > 2 {
+			return append(reverse, forward[1:len(forward)-1]...)
+		}
+		return reverse
+	default:
+		return forward
+	}
+}
+
+// Load loads an Aseprite file at the path in fsys.
+func Load(fsys fs.FS, name string) (*File, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return Decode(f)
+}
+
+// Decode decodes an Aseprite file from r.
+func Decode(r io.Reader) (*File, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := decode(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, frame := range f.Frames {
+		frame.Image = ebiten.NewImageFromImage(f.compose(frame))
+	}
+	for _, frame := range f.Frames {
+		for _, c := range frame.Cels {
+			if c.Image != nil || c.img == nil {
+				continue
+			}
+			img := ebiten.NewImageFromImage(c.img)
+			// Linked cels share the same image.
+			for _, frame := range f.Frames {
+				for _, c2 := range frame.Cels {
+					if c2.img == c.img {
+						c2.Image = img
+					}
+				}
+			}
+		}
+	}
+	return f, nil
+}
+
+// compose composes the cels of the frame.
+func (f *File) compose(frame *Frame) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, f.Width, f.Height))
+
+	cels := make([]*Cel, 0, len(frame.Cels))
+	for _, c := range frame.Cels {
+		if c.img == nil || c.Layer >= len(f.Layers) || !f.Layers[c.Layer].composed() {
+			continue
+		}
+		cels = append(cels, c)
+	}
+	// The order is determined by the layer index plus the z-index, and then the z-index.
+	sort.SliceStable(cels, func(i, j int) bool {
+		oi, oj := cels[i].Layer+cels[i].ZIndex, cels[j].Layer+cels[j].ZIndex
+		if oi != oj {
+			return oi < oj
+		}
+		return cels[i].ZIndex < cels[j].ZIndex
+	})
+
+	for _, c := range cels {
+		opacity := uint32(c.Opacity) * uint32(f.Layers[c.Layer].Opacity) / 0xff
+		b := c.img.Bounds().Add(image.Pt(c.X, c.Y))
+		draw.DrawMask(dst, b, c.img, c.img.Bounds().Min, image.NewUniform(color.Alpha{A: uint8(opacity)}), image.Point{}, draw.Over)
+	}
+	return dst
+}
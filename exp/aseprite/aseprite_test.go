@@ -0,0 +1,306 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aseprite_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/exp/aseprite"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+// aseWriter writes values in the Aseprite file format.
+type aseWriter struct {
+	bytes.Buffer
+}
+
+func (w *aseWriter) byte(v uint8) {
+	w.WriteByte(v)
+}
+
+func (w *aseWriter) word(v uint16) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *aseWriter) dword(v uint32) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *aseWriter) zeros(n int) {
+	w.Write(make([]byte, n))
+}
+
+func (w *aseWriter) string(s string) {
+	w.word(uint16(len(s)))
+	w.WriteString(s)
+}
+
+func (w *aseWriter) chunk(chunkType uint16, data []byte) {
+	w.dword(uint32(len(data) + 6))
+	w.word(chunkType)
+	w.Write(data)
+}
+
+func layerChunk(name string, flags uint16, kind uint16, childLevel uint16, opacity uint8) []byte {
+	var w aseWriter
+	w.word(flags)
+	w.word(kind)
+	w.word(childLevel)
+	w.word(0)
+	w.word(0)
+	w.word(0) // Blend mode
+	w.byte(opacity)
+	w.zeros(3)
+	w.string(name)
+	return w.Bytes()
+}
+
+func celHeader(w *aseWriter, layer uint16, x, y int16, opacity uint8, celType uint16) {
+	w.word(layer)
+	w.word(uint16(x))
+	w.word(uint16(y))
+	w.byte(opacity)
+	w.word(celType)
+	w.word(0) // Z-index
+	w.zeros(5)
+}
+
+func rawCelChunk(layer uint16, x, y int16, width, height int, c color.NRGBA) []byte {
+	var w aseWriter
+	celHeader(&w, layer, x, y, 0xff, 0)
+	w.word(uint16(width))
+	w.word(uint16(height))
+	for i := 0; i < width*height; i++ {
+		w.Write([]byte{c.R, c.G, c.B, c.A})
+	}
+	return w.Bytes()
+}
+
+func compressedCelChunk(layer uint16, x, y int16, opacity uint8, width, height int, c color.NRGBA) []byte {
+	var w aseWriter
+	celHeader(&w, layer, x, y, opacity, 2)
+	w.word(uint16(width))
+	w.word(uint16(height))
+	zw := zlib.NewWriter(&w)
+	for i := 0; i < width*height; i++ {
+		_, _ = zw.Write([]byte{c.R, c.G, c.B, c.A})
+	}
+	_ = zw.Close()
+	return w.Bytes()
+}
+
+func linkedCelChunk(layer uint16, frame uint16) []byte {
+	var w aseWriter
+	celHeader(&w, layer, 0, 0, 0xff, 1)
+	w.word(frame)
+	return w.Bytes()
+}
+
+func tagsChunk() []byte {
+	var w aseWriter
+	w.word(1)
+	w.zeros(8)
+	w.word(0)
+	w.word(2)
+	w.byte(2) // Ping-pong
+	w.word(0)
+	w.zeros(6)
+	w.zeros(3)
+	w.zeros(1)
+	w.string("walk")
+	return w.Bytes()
+}
+
+func sliceChunk() []byte {
+	var w aseWriter
+	w.dword(1)
+	w.dword(2) // Pivot
+	w.dword(0)
+	w.string("hitbox")
+	w.dword(0)
+	w.dword(1)
+	w.dword(2)
+	w.dword(3)
+	w.dword(4)
+	w.dword(1)
+	w.dword(2)
+	return w.Bytes()
+}
+
+func frame(w *aseWriter, duration uint16, chunks ...[]byte) {
+	var f aseWriter
+	for _, c := range chunks {
+		f.chunk(binary.LittleEndian.Uint16(c[4:]), c[6:])
+	}
+	w.dword(uint32(16 + f.Len()))
+	w.word(0xf1fa)
+	w.word(uint16(len(chunks)))
+	w.word(duration)
+	w.zeros(2)
+	w.dword(uint32(len(chunks)))
+	w.Write(f.Bytes())
+}
+
+func withType(chunkType uint16, data []byte) []byte {
+	var w aseWriter
+	w.chunk(chunkType, data)
+	return w.Bytes()
+}
+
+var (
+	red   = color.NRGBA{0xff, 0, 0, 0xff}
+	green = color.NRGBA{0, 0xff, 0, 0xff}
+	blue  = color.NRGBA{0, 0, 0xff, 0xff}
+)
+
+func testFile() []byte {
+	var body aseWriter
+	frame(&body, 100,
+		withType(0x2004, layerChunk("background", 1, 0, 0, 0xff)),
+		withType(0x2004, layerChunk("group", 1, 1, 0, 0xff)),
+		withType(0x2004, layerChunk("child", 1, 0, 1, 0xff)),
+		withType(0x2004, layerChunk("hidden", 0, 0, 0, 0xff)),
+		withType(0x2005, rawCelChunk(0, 0, 0, 4, 4, red)),
+		withType(0x2005, compressedCelChunk(2, 2, 2, 0xff, 2, 2, green)),
+		withType(0x2005, rawCelChunk(3, 0, 0, 4, 4, blue)),
+		withType(0x2018, tagsChunk()),
+		withType(0x2022, sliceChunk()),
+	)
+	frame(&body, 200,
+		withType(0x2005, linkedCelChunk(0, 0)),
+		withType(0x2005, compressedCelChunk(2, 0, 0, 0x80, 1, 1, green)),
+	)
+	frame(&body, 300,
+		withType(0x2005, linkedCelChunk(0, 0)),
+	)
+
+	var w aseWriter
+	w.dword(uint32(128 + body.Len()))
+	w.word(0xa5e0)
+	w.word(3)  // Frames
+	w.word(4)  // Width
+	w.word(4)  // Height
+	w.word(32) // Color depth
+	w.dword(1) // Flags
+	w.word(100)
+	w.zeros(8)
+	w.byte(0)
+	w.zeros(3)
+	w.word(0)
+	w.zeros(128 - w.Len())
+	w.Write(body.Bytes())
+	return w.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	f, err := aseprite.Decode(bytes.NewReader(testFile()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := image.Pt(f.Width, f.Height), image.Pt(4, 4); got != want {
+		t.Errorf("size: got: %v, want: %v", got, want)
+	}
+	if got, want := len(f.Layers), 4; got != want {
+		t.Fatalf("len(Layers): got: %d, want: %d", got, want)
+	}
+	if got, want := f.Layers[2].Parent, f.Layers[1]; got != want {
+		t.Errorf("Parent: got: %v, want: %v", got, want)
+	}
+	if got, want := len(f.Frames), 3; got != want {
+		t.Fatalf("len(Frames): got: %d, want: %d", got, want)
+	}
+	if got, want := f.Frames[1].Duration, 200*time.Millisecond; got != want {
+		t.Errorf("Duration: got: %v, want: %v", got, want)
+	}
+	if f.Frames[1].Cels[0].Image != f.Frames[0].Cels[0].Image {
+		t.Errorf("linked cels must share the image")
+	}
+
+	for _, tc := range []struct {
+		frame int
+		x, y  int
+		want  color.Color
+	}{
+		{0, 0, 0, color.RGBA{0xff, 0, 0, 0xff}},
+		{0, 3, 3, color.RGBA{0, 0xff, 0, 0xff}},
+		{1, 0, 0, color.RGBA{0x7f, 0x80, 0, 0xff}},
+		{1, 3, 3, color.RGBA{0xff, 0, 0, 0xff}},
+		{2, 3, 3, color.RGBA{0xff, 0, 0, 0xff}},
+	} {
+		if got := f.Frames[tc.frame].Image.At(tc.x, tc.y); got != tc.want {
+			t.Errorf("frame %d: At(%d, %d): got: %v, want: %v", tc.frame, tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	tag := f.Tag("walk")
+	if tag == nil {
+		t.Fatal("Tag must not return nil")
+	}
+	if got, want := tag.Direction, aseprite.DirectionPingPong; got != want {
+		t.Errorf("Direction: got: %v, want: %v", got, want)
+	}
+	// The ping-pong sequence is 0, 1, 2, 1 with the durations 100ms, 200ms, 300ms and 200ms.
+	for _, tc := range []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 0},
+		{150 * time.Millisecond, 1},
+		{350 * time.Millisecond, 2},
+		{650 * time.Millisecond, 1},
+		{800 * time.Millisecond, 0},
+	} {
+		if got := f.FrameAt(tag, tc.elapsed); got != tc.want {
+			t.Errorf("FrameAt(%v): got: %d, want: %d", tc.elapsed, got, tc.want)
+		}
+	}
+
+	s := f.Slice("hitbox")
+	if s == nil {
+		t.Fatal("Slice must not return nil")
+	}
+	key, ok := s.KeyAt(2)
+	if !ok {
+		t.Fatal("KeyAt must return true")
+	}
+	if got, want := key.Bounds, image.Rect(1, 2, 4, 6); got != want {
+		t.Errorf("Bounds: got: %v, want: %v", got, want)
+	}
+	if got, want := key.Pivot, image.Pt(1, 2); !key.HasPivot || got != want {
+		t.Errorf("Pivot: got: %v (%t), want: %v (true)", got, key.HasPivot, want)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	bs := testFile()
+	if _, err := aseprite.Decode(bytes.NewReader(bs[:200])); err == nil {
+		t.Errorf("Decode must return an error for truncated data")
+	}
+	bs[4] = 0
+	if _, err := aseprite.Decode(bytes.NewReader(bs)); err == nil {
+		t.Errorf("Decode must return an error for an invalid magic number")
+	}
+}
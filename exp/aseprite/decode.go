@@ -0,0 +1,446 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aseprite
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"time"
+)
+
+// The file format is described at https://github.com/aseprite/aseprite/blob/main/docs/ase-file-specs.md.
+
+const (
+	headerMagic = 0xa5e0
+	frameMagic  = 0xf1fa
+
+	chunkOldPalette  = 0x0004
+	chunkOldPalette2 = 0x0011
+	chunkLayer       = 0x2004
+	chunkCel         = 0x2005
+	chunkPalette     = 0x2019
+	chunkTags        = 0x2018
+	chunkSlice       = 0x2022
+)
+
+// headerFlagsOpacity indicates that the layer opacity is valid.
+const headerFlagsOpacity = 1
+
+// reader reads little endian values from a byte slice.
+// Reading beyond the end sets err and returns zero values.
+type reader struct {
+	bs  []byte
+	pos int
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.bs) {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	bs := r.bs[r.pos : r.pos+n]
+	r.pos += n
+	return bs
+}
+
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *reader) byte() uint8 {
+	bs := r.bytes(1)
+	if bs == nil {
+		return 0
+	}
+	return bs[0]
+}
+
+func (r *reader) word() uint16 {
+	bs := r.bytes(2)
+	if bs == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(bs)
+}
+
+func (r *reader) short() int16 {
+	return int16(r.word())
+}
+
+func (r *reader) dword() uint32 {
+	bs := r.bytes(4)
+	if bs == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(bs)
+}
+
+func (r *reader) long() int32 {
+	return int32(r.dword())
+}
+
+func (r *reader) string() string {
+	n := r.word()
+	return string(r.bytes(int(n)))
+}
+
+type header struct {
+	frames           int
+	width            int
+	height           int
+	colorDepth       int
+	flags            uint32
+	transparentIndex uint8
+}
+
+func decode(bs []byte) (*File, error) {
+	r := &reader{bs: bs}
+
+	var h header
+	r.dword() // File size
+	if magic := r.word(); r.err == nil && magic != headerMagic {
+		return nil, fmt.Errorf("aseprite: invalid magic number: 0x%04x", magic)
+	}
+	h.frames = int(r.word())
+	h.width = int(r.word())
+	h.height = int(r.word())
+	h.colorDepth = int(r.word())
+	h.flags = r.dword()
+	r.word()  // Speed (deprecated)
+	r.skip(8) // Reserved
+	h.transparentIndex = r.byte()
+	r.skip(3)
+	r.word() // Number of colors
+	r.skip(128 - r.pos)
+	if r.err != nil {
+		return nil, r.err
+	}
+	switch h.colorDepth {
+	case 8, 16, 32:
+	default:
+		return nil, fmt.Errorf("aseprite: invalid color depth: %d", h.colorDepth)
+	}
+
+	f := &File{
+		Width:  h.width,
+		Height: h.height,
+	}
+	var hasNewPalette bool
+	for i := 0; i < h.frames; i++ {
+		start := r.pos
+		size := int(r.dword())
+		if magic := r.word(); r.err == nil && magic != frameMagic {
+			return nil, fmt.Errorf("aseprite: invalid frame magic number: 0x%04x", magic)
+		}
+		chunks := int(r.word())
+		duration := r.word()
+		r.skip(2)
+		if n := int(r.dword()); n != 0 {
+			chunks = n
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		frame := &Frame{
+			Duration: time.Duration(duration) * time.Millisecond,
+		}
+		f.Frames = append(f.Frames, frame)
+
+		for j := 0; j < chunks; j++ {
+			chunkStart := r.pos
+			chunkSize := int(r.dword())
+			chunkType := r.word()
+			data := r.bytes(chunkSize - 6)
+			if r.err != nil {
+				return nil, r.err
+			}
+			cr := &reader{bs: data}
+
+			switch chunkType {
+			case chunkOldPalette, chunkOldPalette2:
+				if hasNewPalette {
+					break
+				}
+				f.Palette = decodeOldPalette(cr, f.Palette, chunkType == chunkOldPalette2)
+			case chunkPalette:
+				hasNewPalette = true
+				f.Palette = decodePalette(cr, f.Palette)
+			case chunkLayer:
+				f.Layers = append(f.Layers, decodeLayer(cr, f.Layers, h.flags&headerFlagsOpacity != 0))
+			case chunkCel:
+				c, err := decodeCel(cr, f, &h)
+				if err != nil {
+					return nil, err
+				}
+				frame.Cels = append(frame.Cels, c)
+			case chunkTags:
+				f.Tags = append(f.Tags, decodeTags(cr)...)
+			case chunkSlice:
+				f.Slices = append(f.Slices, decodeSlice(cr))
+			}
+			if cr.err != nil {
+				return nil, fmt.Errorf("aseprite: decoding chunk 0x%04x at %d failed: %w", chunkType, chunkStart, cr.err)
+			}
+		}
+		// Skip the rest of the frame if any.
+		if rest := start + size - r.pos; rest > 0 {
+			r.skip(rest)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+	}
+	return f, nil
+}
+
+func decodeOldPalette(r *reader, palette color.Palette, sixBits bool) color.Palette {
+	packets := int(r.word())
+	index := 0
+	for i := 0; i < packets; i++ {
+		index += int(r.byte())
+		n := int(r.byte())
+		if n == 0 {
+			n = 256
+		}
+		for j := 0; j < n; j++ {
+			cr, cg, cb := r.byte(), r.byte(), r.byte()
+			if sixBits {
+				cr, cg, cb = cr<<2|cr>>4, cg<<2|cg>>4, cb<<2|cb>>4
+			}
+			palette = setPaletteColor(palette, index, color.NRGBA{R: cr, G: cg, B: cb, A: 0xff})
+			index++
+		}
+	}
+	return palette
+}
+
+func decodePalette(r *reader, palette color.Palette) color.Palette {
+	r.dword() // New palette size
+	first := int(r.dword())
+	last := int(r.dword())
+	r.skip(8)
+	for i := first; i <= last && r.err == nil; i++ {
+		flags := r.word()
+		c := color.NRGBA{R: r.byte(), G: r.byte(), B: r.byte(), A: r.byte()}
+		if flags&1 != 0 {
+			r.string() // Name
+		}
+		palette = setPaletteColor(palette, i, c)
+	}
+	return palette
+}
+
+func setPaletteColor(palette color.Palette, index int, c color.Color) color.Palette {
+	for len(palette) <= index {
+		palette = append(palette, color.NRGBA{})
+	}
+	palette[index] = c
+	return palette
+}
+
+func decodeLayer(r *reader, layers []*Layer, opacityValid bool) *Layer {
+	flags := r.word()
+	kind := r.word()
+	childLevel := int(r.word())
+	r.word() // Default width
+	r.word() // Default height
+	blendMode := r.word()
+	opacity := r.byte()
+	r.skip(3)
+	name := r.string()
+
+	l := &Layer{
+		Name:       name,
+		Kind:       LayerKind(kind),
+		Visible:    flags&1 != 0,
+		Background: flags&8 != 0,
+		Opacity:    0xff,
+		BlendMode:  int(blendMode),
+	}
+	if opacityValid {
+		l.Opacity = opacity
+	}
+
+	// The parent is the last layer whose child level is one less than this layer's.
+	if childLevel > 0 {
+		level := childLevel
+		for i := len(layers) - 1; i >= 0; i-- {
+			if layerLevel(layers[i]) == level-1 && layers[i].Kind == LayerKindGroup {
+				l.Parent = layers[i]
+				break
+			}
+		}
+	}
+	return l
+}
+
+func layerLevel(l *Layer) int {
+	var level int
+	for p := l.Parent; p != nil; p = p.Parent {
+		level++
+	}
+	return level
+}
+
+func decodeCel(r *reader, f *File, h *header) (*Cel, error) {
+	c := &Cel{
+		Layer: int(r.word()),
+		X:     int(r.short()),
+		Y:     int(r.short()),
+	}
+	c.Opacity = r.byte()
+	celType := r.word()
+	c.ZIndex = int(r.short())
+	r.skip(5)
+	if r.err != nil {
+		return c, nil
+	}
+
+	switch celType {
+	case 0, 2:
+		w := int(r.word())
+		height := int(r.word())
+		pixels := r.bytes(len(r.bs) - r.pos)
+		if r.err != nil {
+			return c, nil
+		}
+		if celType == 2 {
+			zr, err := zlib.NewReader(bytes.NewReader(pixels))
+			if err != nil {
+				return nil, fmt.Errorf("aseprite: decompressing a cel failed: %w", err)
+			}
+			decompressed, err := io.ReadAll(zr)
+			if err != nil {
+				return nil, fmt.Errorf("aseprite: decompressing a cel failed: %w", err)
+			}
+			pixels = decompressed
+		}
+		img, err := decodePixels(pixels, w, height, h, f.Palette, f.layerIsBackground(c.Layer))
+		if err != nil {
+			return nil, err
+		}
+		c.img = img
+	case 1:
+		// A linked cel shares the image with the cel of the same layer in the frame.
+		frame := int(r.word())
+		if frame >= len(f.Frames) {
+			return nil, fmt.Errorf("aseprite: invalid linked frame: %d", frame)
+		}
+		for _, linked := range f.Frames[frame].Cels {
+			if linked.Layer == c.Layer {
+				c.img = linked.img
+				break
+			}
+		}
+	case 3:
+		// Tilemap cels are not supported.
+	}
+	return c, nil
+}
+
+func (f *File) layerIsBackground(index int) bool {
+	if index < 0 || index >= len(f.Layers) {
+		return false
+	}
+	return f.Layers[index].Background
+}
+
+func decodePixels(pixels []byte, width, height int, h *header, palette color.Palette, background bool) (*image.NRGBA, error) {
+	bpp := h.colorDepth / 8
+	if len(pixels) < width*height*bpp {
+		return nil, io.ErrUnexpectedEOF
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < width*height; i++ {
+		var c color.NRGBA
+		switch h.colorDepth {
+		case 32:
+			c = color.NRGBA{R: pixels[4*i], G: pixels[4*i+1], B: pixels[4*i+2], A: pixels[4*i+3]}
+		case 16:
+			v := pixels[2*i]
+			c = color.NRGBA{R: v, G: v, B: v, A: pixels[2*i+1]}
+		case 8:
+			index := pixels[i]
+			if index == h.transparentIndex && !background {
+				break
+			}
+			if int(index) < len(palette) {
+				c = color.NRGBAModel.Convert(palette[index]).(color.NRGBA)
+			}
+		}
+		img.Pix[4*i] = c.R
+		img.Pix[4*i+1] = c.G
+		img.Pix[4*i+2] = c.B
+		img.Pix[4*i+3] = c.A
+	}
+	return img, nil
+}
+
+func decodeTags(r *reader) []*Tag {
+	n := int(r.word())
+	r.skip(8)
+	var tags []*Tag
+	for i := 0; i < n && r.err == nil; i++ {
+		t := &Tag{
+			From: int(r.word()),
+			To:   int(r.word()),
+		}
+		t.Direction = Direction(r.byte())
+		t.Repeat = int(r.word())
+		r.skip(6)
+		r.skip(3) // Tag color (deprecated)
+		r.skip(1)
+		t.Name = r.string()
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+func decodeSlice(r *reader) *Slice {
+	n := int(r.dword())
+	flags := r.dword()
+	r.dword()
+	s := &Slice{
+		Name: r.string(),
+	}
+	for i := 0; i < n && r.err == nil; i++ {
+		var k SliceKey
+		k.Frame = int(r.dword())
+		x, y := int(r.long()), int(r.long())
+		w, h := int(r.dword()), int(r.dword())
+		k.Bounds = image.Rect(x, y, x+w, y+h)
+		if flags&1 != 0 {
+			cx, cy := int(r.long()), int(r.long())
+			cw, ch := int(r.dword()), int(r.dword())
+			k.Center = image.Rect(cx, cy, cx+cw, cy+ch)
+		}
+		if flags&2 != 0 {
+			k.Pivot = image.Pt(int(r.long()), int(r.long()))
+			k.HasPivot = true
+		}
+		s.Keys = append(s.Keys, k)
+	}
+	return s
+}
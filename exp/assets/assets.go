@@ -0,0 +1,537 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package assets provides a manager to load assets like images, audio, fonts and shaders asynchronously.
+// This package is experimental and the API might be changed in the future.
+//
+// Image decoders must be imported when loading images. For example,
+// if you want to load a PNG image, you'd need to add `_ "image/png"` to the import section.
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/flac"
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
+	"github.com/hajimehoshi/ebiten/v2/audio/opus"
+	"github.com/hajimehoshi/ebiten/v2/audio/tracker"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// State represents the loading state of an asset.
+type State int
+
+const (
+	// StateLoading indicates that the asset is being loaded.
+	StateLoading State = iota
+
+	// StateLoaded indicates that the asset is loaded successfully.
+	StateLoaded
+
+	// StateFailed indicates that loading the asset failed.
+	StateFailed
+)
+
+// ManagerOptions represents options for NewManager.
+type ManagerOptions struct {
+	// AudioContext is the audio context to decode audio assets.
+	// AudioContext is required to load audio assets.
+	AudioContext *audio.Context
+
+	// Concurrency is the maximum number of assets loaded at the same time.
+	//
+	// The default (zero) value is the number of the logical CPUs.
+	Concurrency int
+
+	// HotReload reports whether assets are reloaded automatically when their files are modified.
+	// HotReload is intended for development, and works only with a file system reporting modification times, like os.DirFS.
+	//
+	// The default (zero) value is false.
+	HotReload bool
+
+	// HotReloadInterval is the interval to check the modification of the files.
+	//
+	// The default (zero) value is one second.
+	HotReloadInterval time.Duration
+}
+
+// Manager loads and holds assets from a file system.
+//
+// Assets are identified by their kinds and paths.
+// Loading the same asset multiple times shares the same data, and the data is released when all the handles are released.
+//
+// Manager's functions are concurrent-safe.
+type Manager struct {
+	fsys         fs.FS
+	audioContext *audio.Context
+	semaphore    chan struct{}
+
+	entries map[entryKey]*entry
+
+	done chan struct{}
+	m    sync.Mutex
+}
+
+type kind int
+
+const (
+	kindImage kind = iota
+	kindAudio
+	kindFont
+	kindShader
+)
+
+type entryKey struct {
+	kind kind
+	path string
+}
+
+type entry struct {
+	manager  *Manager
+	key      entryKey
+	refCount int
+
+	state   State
+	value   any
+	err     error
+	modTime time.Time
+
+	reloading bool
+	loaded    chan struct{}
+}
+
+// NewManager creates a new Manager to load assets from fsys.
+//
+// If options is nil, the default options are used.
+func NewManager(fsys fs.FS, options *ManagerOptions) *Manager {
+	if options == nil {
+		options = &ManagerOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	m := &Manager{
+		fsys:         fsys,
+		audioContext: options.AudioContext,
+		semaphore:    make(chan struct{}, concurrency),
+		entries:      map[entryKey]*entry{},
+		done:         make(chan struct{}),
+	}
+	if options.HotReload {
+		interval := options.HotReloadInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		go m.watch(interval)
+	}
+	return m
+}
+
+// Close stops the hot reloading.
+// The loaded assets are still available after Close.
+//
+// Close must not be called more than once.
+func (m *Manager) Close() {
+	close(m.done)
+}
+
+// Progress returns the number of the assets whose first loading is finished, and the total number of the assets.
+// Failed assets are counted as finished.
+//
+// Progress is useful to show a loading screen.
+func (m *Manager) Progress() (finished, total int) {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	for _, e := range m.entries {
+		total++
+		if e.state != StateLoading {
+			finished++
+		}
+	}
+	return
+}
+
+// LoadImage starts loading an image at the path and returns its handle.
+func (m *Manager) LoadImage(path string) *Image {
+	return &Image{Asset: m.load(kindImage, path)}
+}
+
+// LoadAudio starts loading an audio at the path and returns its handle.
+// The audio is decoded into the memory based on its extension: .wav, .mp3, .ogg, .flac, .opus, .mod, .xm or .it.
+// A tracker module (.mod, .xm or .it) is rendered once without looping.
+//
+// LoadAudio requires ManagerOptions.AudioContext.
+func (m *Manager) LoadAudio(path string) *Audio {
+	return &Audio{Asset: m.load(kindAudio, path)}
+}
+
+// LoadFont starts loading a font at the path and returns its handle.
+func (m *Manager) LoadFont(path string) *Font {
+	return &Font{Asset: m.load(kindFont, path)}
+}
+
+// LoadShader starts loading a Kage shader at the path and returns its handle.
+func (m *Manager) LoadShader(path string) *Shader {
+	return &Shader{Asset: m.load(kindShader, path)}
+}
+
+func (m *Manager) load(kind kind, path string) Asset {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	key := entryKey{kind: kind, path: path}
+	if e, ok := m.entries[key]; ok {
+		e.refCount++
+		return Asset{entry: e}
+	}
+
+	e := &entry{
+		manager:  m,
+		key:      key,
+		refCount: 1,
+		loaded:   make(chan struct{}),
+	}
+	m.entries[key] = e
+	go e.load()
+	return Asset{entry: e}
+}
+
+func (e *entry) load() {
+	m := e.manager
+	m.semaphore <- struct{}{}
+	defer func() {
+		<-m.semaphore
+	}()
+
+	value, modTime, err := m.read(e.key)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	defer close(e.loaded)
+	if err != nil {
+		e.state = StateFailed
+		e.err = err
+		return
+	}
+	e.state = StateLoaded
+	e.value = value
+	e.modTime = modTime
+	if e.refCount == 0 {
+		// All the handles were released during loading.
+		e.deallocate()
+	}
+}
+
+func (e *entry) reload() {
+	m := e.manager
+	m.semaphore <- struct{}{}
+	defer func() {
+		<-m.semaphore
+	}()
+
+	// Skip the loading when nothing references the asset.
+	m.m.Lock()
+	released := e.refCount == 0
+	if released {
+		e.reloading = false
+	}
+	m.m.Unlock()
+	if released {
+		return
+	}
+
+	value, modTime, err := m.read(e.key)
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	e.reloading = false
+	if err != nil {
+		// Keep the current value so that a broken file in editing doesn't break the game.
+		e.err = err
+		return
+	}
+	if e.refCount == 0 {
+		// All the handles were released during loading.
+		deallocateValue(value)
+		return
+	}
+	// The old value is not deallocated explicitly, as it might still be used in the current frame.
+	e.state = StateLoaded
+	e.value = value
+	e.err = nil
+	e.modTime = modTime
+}
+
+// deallocate deallocates the value of the entry.
+// deallocate must be called with the manager's lock held.
+func (e *entry) deallocate() {
+	deallocateValue(e.value)
+	e.value = nil
+}
+
+func deallocateValue(value any) {
+	switch v := value.(type) {
+	case *ebiten.Image:
+		v.Deallocate()
+	case *ebiten.Shader:
+		v.Deallocate()
+	}
+}
+
+func (m *Manager) read(key entryKey) (any, time.Time, error) {
+	var modTime time.Time
+	if fi, err := fs.Stat(m.fsys, key.path); err == nil {
+		modTime = fi.ModTime()
+	}
+
+	bs, err := fs.ReadFile(m.fsys, key.path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	switch key.kind {
+	case kindImage:
+		img, _, err := image.Decode(bytes.NewReader(bs))
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("assets: decoding %s failed: %w", key.path, err)
+		}
+		return ebiten.NewImageFromImage(img), modTime, nil
+	case kindAudio:
+		if m.audioContext == nil {
+			return nil, time.Time{}, fmt.Errorf("assets: AudioContext is required to load %s", key.path)
+		}
+		var s io.Reader
+		sampleRate := m.audioContext.SampleRate()
+		switch ext := strings.ToLower(path.Ext(key.path)); ext {
+		case ".wav":
+			s, err = wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(bs))
+		case ".mp3":
+			s, err = mp3.DecodeWithSampleRate(sampleRate, bytes.NewReader(bs))
+		case ".ogg":
+			s, err = vorbis.DecodeWithSampleRate(sampleRate, bytes.NewReader(bs))
+		case ".flac":
+			s, err = flac.DecodeWithSampleRate(sampleRate, bytes.NewReader(bs))
+		case ".opus":
+			s, err = opus.DecodeWithSampleRate(sampleRate, bytes.NewReader(bs))
+		case ".mod", ".xm", ".it":
+			s, err = tracker.DecodeWithSampleRate(sampleRate, bytes.NewReader(bs))
+		default:
+			return nil, time.Time{}, fmt.Errorf("assets: unsupported audio format: %s", ext)
+		}
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("assets: decoding %s failed: %w", key.path, err)
+		}
+		pcm, err := io.ReadAll(s)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("assets: decoding %s failed: %w", key.path, err)
+		}
+		return pcm, modTime, nil
+	case kindFont:
+		f, err := text.NewGoTextFaceSource(bytes.NewReader(bs))
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("assets: parsing %s failed: %w", key.path, err)
+		}
+		return f, modTime, nil
+	case kindShader:
+		s, err := ebiten.NewShader(bs)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("assets: compiling %s failed: %w", key.path, err)
+		}
+		return s, modTime, nil
+	default:
+		panic(fmt.Sprintf("assets: invalid kind: %d", key.kind))
+	}
+}
+
+func (m *Manager) watch(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-t.C:
+		}
+
+		m.m.Lock()
+		var entries []*entry
+		for _, e := range m.entries {
+			if e.state != StateLoaded || e.reloading {
+				continue
+			}
+			entries = append(entries, e)
+		}
+		m.m.Unlock()
+
+		for _, e := range entries {
+			fi, err := fs.Stat(m.fsys, e.key.path)
+			if err != nil {
+				continue
+			}
+			m.m.Lock()
+			if fi.ModTime().After(e.modTime) && !e.reloading {
+				e.reloading = true
+				go e.reload()
+			}
+			m.m.Unlock()
+		}
+	}
+}
+
+// Asset is a handle of an asset.
+type Asset struct {
+	entry    *entry
+	released bool
+}
+
+// Path returns the path of the asset.
+func (a *Asset) Path() string {
+	return a.entry.key.path
+}
+
+// State returns the loading state of the asset.
+func (a *Asset) State() State {
+	a.entry.manager.m.Lock()
+	defer a.entry.manager.m.Unlock()
+	return a.entry.state
+}
+
+// Err returns the error of the last loading or reloading.
+// Err returns nil if the asset is being loaded or the loading succeeded.
+func (a *Asset) Err() error {
+	a.entry.manager.m.Lock()
+	defer a.entry.manager.m.Unlock()
+	return a.entry.err
+}
+
+// Wait blocks until the first loading of the asset is finished, and returns the error of the loading.
+func (a *Asset) Wait() error {
+	<-a.entry.loaded
+	return a.Err()
+}
+
+// Release releases the handle.
+// When all the handles of the same asset are released, the asset's data is released.
+// After Release, the handle must not be used.
+//
+// Release does nothing if the handle is already released.
+func (a *Asset) Release() {
+	if a.released {
+		return
+	}
+	a.released = true
+
+	m := a.entry.manager
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	e := a.entry
+	e.refCount--
+	if e.refCount > 0 {
+		return
+	}
+	delete(m.entries, e.key)
+	if e.state == StateLoaded {
+		e.deallocate()
+	}
+}
+
+func (a *Asset) value() any {
+	if a.released {
+		panic(fmt.Sprintf("assets: the asset %s is already released", a.entry.key.path))
+	}
+	a.entry.manager.m.Lock()
+	defer a.entry.manager.m.Unlock()
+	return a.entry.value
+}
+
+// Image is a handle of an image asset.
+type Image struct {
+	Asset
+}
+
+// Image returns the loaded image.
+// Image returns nil if the image is not loaded yet or the loading failed.
+//
+// With hot reloading, Image might return a different image after the file is modified.
+func (i *Image) Image() *ebiten.Image {
+	img, _ := i.value().(*ebiten.Image)
+	return img
+}
+
+// Audio is a handle of an audio asset.
+type Audio struct {
+	Asset
+}
+
+// Bytes returns the decoded audio data in 16-bit little endian 2 channels (stereo) format.
+// Bytes returns nil if the audio is not loaded yet or the loading failed.
+func (a *Audio) Bytes() []byte {
+	bs, _ := a.value().([]byte)
+	return bs
+}
+
+// NewPlayer creates a new player of the audio.
+// NewPlayer returns nil if the audio is not loaded yet or the loading failed.
+func (a *Audio) NewPlayer() *audio.Player {
+	bs := a.Bytes()
+	if bs == nil {
+		return nil
+	}
+	return a.entry.manager.audioContext.NewPlayerFromBytes(bs)
+}
+
+// Font is a handle of a font asset.
+type Font struct {
+	Asset
+}
+
+// FaceSource returns the loaded font face source.
+// FaceSource returns nil if the font is not loaded yet or the loading failed.
+func (f *Font) FaceSource() *text.GoTextFaceSource {
+	s, _ := f.value().(*text.GoTextFaceSource)
+	return s
+}
+
+// Shader is a handle of a shader asset.
+type Shader struct {
+	Asset
+}
+
+// Shader returns the compiled shader.
+// Shader returns nil if the shader is not loaded yet or the loading failed.
+//
+// With hot reloading, Shader might return a different shader after the file is modified.
+func (s *Shader) Shader() *ebiten.Shader {
+	shader, _ := s.value().(*ebiten.Shader)
+	return shader
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hajimehoshi/ebiten/v2/exp/assets"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+func newTestFS(t *testing.T) fstest.MapFS {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	src.Set(1, 1, color.RGBA{0xff, 0, 0, 0xff})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	return fstest.MapFS{
+		"image.png":  &fstest.MapFile{Data: buf.Bytes()},
+		"broken.png": &fstest.MapFile{Data: []byte("not a png")},
+		"shader.kage": &fstest.MapFile{Data: []byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)},
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	m := assets.NewManager(newTestFS(t), nil)
+
+	img := m.LoadImage("image.png")
+	if err := img.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.State(), assets.StateLoaded; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := img.Image().Bounds().Size(), image.Pt(4, 3); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := img.Image().At(1, 1), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// Loading the same asset shares the data.
+	img2 := m.LoadImage("image.png")
+	if err := img2.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if img.Image() != img2.Image() {
+		t.Errorf("the images must be shared")
+	}
+	if finished, total := m.Progress(); finished != 1 || total != 1 {
+		t.Errorf("Progress(): got: (%d, %d), want: (1, 1)", finished, total)
+	}
+
+	img.Release()
+	if finished, total := m.Progress(); finished != 1 || total != 1 {
+		t.Errorf("Progress(): got: (%d, %d), want: (1, 1)", finished, total)
+	}
+	img2.Release()
+	if finished, total := m.Progress(); finished != 0 || total != 0 {
+		t.Errorf("Progress(): got: (%d, %d), want: (0, 0)", finished, total)
+	}
+}
+
+func TestLoadFailure(t *testing.T) {
+	m := assets.NewManager(newTestFS(t), nil)
+
+	for _, path := range []string{"broken.png", "missing.png"} {
+		img := m.LoadImage(path)
+		if err := img.Wait(); err == nil {
+			t.Errorf("%s: Wait must return an error", path)
+		}
+		if got, want := img.State(), assets.StateFailed; got != want {
+			t.Errorf("%s: got: %v, want: %v", path, got, want)
+		}
+		if img.Image() != nil {
+			t.Errorf("%s: Image must return nil", path)
+		}
+	}
+
+	// Loading audio without an audio context fails.
+	a := m.LoadAudio("sound.wav")
+	if err := a.Wait(); err == nil {
+		t.Errorf("Wait must return an error")
+	}
+}
+
+func TestLoadShader(t *testing.T) {
+	m := assets.NewManager(newTestFS(t), nil)
+
+	s := m.LoadShader("shader.kage")
+	if err := s.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Shader() == nil {
+		t.Errorf("Shader must not return nil")
+	}
+	s.Release()
+}
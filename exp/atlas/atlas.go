@@ -0,0 +1,309 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package atlas provides a loader of texture atlases in the JSON formats of TexturePacker and free-texture-packer.
+// This package is experimental and the API might be changed in the future.
+//
+// Both the JSON (Hash) and the JSON (Array) formats are supported.
+//
+// Image decoders must be imported when loading atlases. For example,
+// if the atlas image is a PNG image, you'd need to add `_ "image/png"` to the import section.
+package atlas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Atlas is a texture atlas.
+type Atlas struct {
+	// Image is the atlas image.
+	Image *ebiten.Image
+
+	// Sprites is the sprites in the atlas.
+	// For the JSON (Hash) format, the sprites are sorted by their names.
+	Sprites []*Sprite
+
+	sprites map[string]*Sprite
+}
+
+// Sprite is a sprite in an atlas.
+type Sprite struct {
+	// Name is the name of the sprite, which is usually the file name of the original image.
+	Name string
+
+	// Image is the sub-image of the atlas image for the sprite.
+	// If Rotated is true, Image is rotated 90 degrees clockwise.
+	// Use Draw to draw the sprite as the original image.
+	Image *ebiten.Image
+
+	// Rotated reports whether the sprite is rotated 90 degrees clockwise in the atlas.
+	Rotated bool
+
+	// Trim is the region of the trimmed sprite in the original image.
+	// If the sprite is not trimmed, Trim is the whole region of the original image.
+	Trim image.Rectangle
+
+	// SourceWidth and SourceHeight are the size of the original image.
+	SourceWidth  int
+	SourceHeight int
+
+	// PivotX and PivotY are the pivot point normalized to the original image size.
+	// (0, 0) is the upper-left corner and (1, 1) is the lower-right corner.
+	PivotX float64
+	PivotY float64
+}
+
+// Pivot returns the pivot point in pixels in the original image.
+func (s *Sprite) Pivot() (float64, float64) {
+	return s.PivotX * float64(s.SourceWidth), s.PivotY * float64(s.SourceHeight)
+}
+
+// GeoM returns the geometry matrix to draw Image as the original image at (0, 0).
+// The matrix restores the rotation and the trimmed offset.
+func (s *Sprite) GeoM() ebiten.GeoM {
+	var g ebiten.GeoM
+	if s.Rotated {
+		// Rotate counterclockwise to restore the rotation.
+		g.Rotate(-math.Pi / 2)
+		g.Translate(0, float64(s.Trim.Dy()))
+	}
+	g.Translate(float64(s.Trim.Min.X), float64(s.Trim.Min.Y))
+	return g
+}
+
+// Draw draws the sprite onto dst as if the original image is drawn with options.
+// If options is nil, the default options are used.
+func (s *Sprite) Draw(dst *ebiten.Image, options *ebiten.DrawImageOptions) {
+	op := &ebiten.DrawImageOptions{}
+	if options != nil {
+		*op = *options
+	}
+	op.GeoM = s.GeoM()
+	if options != nil {
+		op.GeoM.Concat(options.GeoM)
+	}
+	dst.DrawImage(s.Image, op)
+}
+
+// Load loads an atlas JSON file at the path in fsys, and its image specified in the JSON file.
+// The image path is relative to the JSON file.
+func Load(fsys fs.FS, name string) (*Atlas, error) {
+	bs, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Meta struct {
+			Image string `json:"image"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(bs, &meta); err != nil {
+		return nil, fmt.Errorf("atlas: parsing %s failed: %w", name, err)
+	}
+	if meta.Meta.Image == "" {
+		return nil, fmt.Errorf("atlas: no image is specified in %s", name)
+	}
+
+	imgPath := path.Join(path.Dir(name), meta.Meta.Image)
+	f, err := fsys.Open(imgPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("atlas: decoding %s failed: %w", imgPath, err)
+	}
+
+	a, err := newAtlas(bs, ebiten.NewImageFromImage(img))
+	if err != nil {
+		return nil, fmt.Errorf("atlas: parsing %s failed: %w", name, err)
+	}
+	return a, nil
+}
+
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type jsonFrame struct {
+	Filename         string   `json:"filename"`
+	Frame            jsonRect `json:"frame"`
+	Rotated          bool     `json:"rotated"`
+	Trimmed          bool     `json:"trimmed"`
+	SpriteSourceSize jsonRect `json:"spriteSourceSize"`
+	SourceSize       struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"sourceSize"`
+	Pivot *struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"pivot"`
+}
+
+// NewAtlas creates an atlas from the JSON data and the atlas image.
+// The image specified in the JSON data is ignored.
+func NewAtlas(jsonData []byte, img *ebiten.Image) (*Atlas, error) {
+	a, err := newAtlas(jsonData, img)
+	if err != nil {
+		return nil, fmt.Errorf("atlas: %w", err)
+	}
+	return a, nil
+}
+
+func newAtlas(jsonData []byte, img *ebiten.Image) (*Atlas, error) {
+	var data struct {
+		Frames json.RawMessage `json:"frames"`
+	}
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, err
+	}
+
+	var frames []jsonFrame
+	if bytes.HasPrefix(bytes.TrimSpace(data.Frames), []byte("[")) {
+		if err := json.Unmarshal(data.Frames, &frames); err != nil {
+			return nil, err
+		}
+	} else {
+		var m map[string]jsonFrame
+		if err := json.Unmarshal(data.Frames, &m); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f := m[name]
+			f.Filename = name
+			frames = append(frames, f)
+		}
+	}
+
+	a := &Atlas{
+		Image:   img,
+		sprites: map[string]*Sprite{},
+	}
+	b := img.Bounds()
+	for _, f := range frames {
+		// For a rotated sprite, the size in the atlas is swapped.
+		w, h := f.Frame.W, f.Frame.H
+		if f.Rotated {
+			w, h = h, w
+		}
+		r := image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+w, f.Frame.Y+h).Add(b.Min)
+		if !r.In(b) {
+			return nil, fmt.Errorf("the sprite %s is out of the atlas image", f.Filename)
+		}
+
+		s := &Sprite{
+			Name:         f.Filename,
+			Image:        img.SubImage(r).(*ebiten.Image),
+			Rotated:      f.Rotated,
+			Trim:         image.Rect(0, 0, f.Frame.W, f.Frame.H),
+			SourceWidth:  f.SourceSize.W,
+			SourceHeight: f.SourceSize.H,
+		}
+		if f.Trimmed {
+			ss := f.SpriteSourceSize
+			s.Trim = image.Rect(ss.X, ss.Y, ss.X+ss.W, ss.Y+ss.H)
+		}
+		if s.SourceWidth == 0 || s.SourceHeight == 0 {
+			s.SourceWidth, s.SourceHeight = s.Trim.Max.X, s.Trim.Max.Y
+		}
+		if f.Pivot != nil {
+			s.PivotX, s.PivotY = f.Pivot.X, f.Pivot.Y
+		}
+		a.Sprites = append(a.Sprites, s)
+		a.sprites[s.Name] = s
+	}
+	return a, nil
+}
+
+// Sprite returns the sprite with the name, or nil if there is no such sprite.
+func (a *Atlas) Sprite(name string) *Sprite {
+	return a.sprites[name]
+}
+
+// SpritesWithPrefix returns the sprites whose names start with the prefix, sorted by their names.
+// SpritesWithPrefix is useful to get frames of an animation like "walk_00.png", "walk_01.png", and so on.
+func (a *Atlas) SpritesWithPrefix(prefix string) []*Sprite {
+	return spritesWithPrefix(a.Sprites, prefix)
+}
+
+func spritesWithPrefix(sprites []*Sprite, prefix string) []*Sprite {
+	var result []*Sprite
+	for _, s := range sprites {
+		if strings.HasPrefix(s.Name, prefix) {
+			result = append(result, s)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Registry is a collection of sprites from multiple atlases, like a multipack atlas.
+//
+// Registry's functions are not concurrent-safe.
+type Registry struct {
+	sprites []*Sprite
+	byName  map[string]*Sprite
+}
+
+// Add adds the sprites of the atlas to the registry.
+// Add returns an error if a sprite with the same name is already registered.
+func (r *Registry) Add(atlas *Atlas) error {
+	if r.byName == nil {
+		r.byName = map[string]*Sprite{}
+	}
+	for _, s := range atlas.Sprites {
+		if _, ok := r.byName[s.Name]; ok {
+			return fmt.Errorf("atlas: the sprite %s is already registered", s.Name)
+		}
+	}
+	for _, s := range atlas.Sprites {
+		r.byName[s.Name] = s
+		r.sprites = append(r.sprites, s)
+	}
+	return nil
+}
+
+// Sprite returns the sprite with the name, or nil if there is no such sprite.
+func (r *Registry) Sprite(name string) *Sprite {
+	return r.byName[name]
+}
+
+// SpritesWithPrefix returns the sprites whose names start with the prefix, sorted by their names.
+func (r *Registry) SpritesWithPrefix(prefix string) []*Sprite {
+	return spritesWithPrefix(r.sprites, prefix)
+}
@@ -0,0 +1,185 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atlas_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/exp/atlas"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+const hashJSON = `{
+	"frames": {
+		"walk_01.png": {
+			"frame": {"x": 0, "y": 0, "w": 4, "h": 2},
+			"rotated": false,
+			"trimmed": true,
+			"spriteSourceSize": {"x": 1, "y": 2, "w": 4, "h": 2},
+			"sourceSize": {"w": 8, "h": 6},
+			"pivot": {"x": 0.5, "y": 1}
+		},
+		"walk_00.png": {
+			"frame": {"x": 4, "y": 0, "w": 2, "h": 3},
+			"rotated": true,
+			"trimmed": false,
+			"spriteSourceSize": {"x": 0, "y": 0, "w": 2, "h": 3},
+			"sourceSize": {"w": 2, "h": 3}
+		}
+	},
+	"meta": {"image": "atlas.png", "size": {"w": 8, "h": 8}}
+}`
+
+const arrayJSON = `{
+	"frames": [
+		{
+			"filename": "idle.png",
+			"frame": {"x": 0, "y": 4, "w": 2, "h": 2},
+			"rotated": false,
+			"trimmed": false,
+			"spriteSourceSize": {"x": 0, "y": 0, "w": 2, "h": 2},
+			"sourceSize": {"w": 2, "h": 2}
+		}
+	],
+	"meta": {"image": "atlas.png"}
+}`
+
+func newAtlasImage() *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 8; i++ {
+			img.Set(i, j, color.RGBA{uint8(i * 0x10), uint8(j * 0x10), 0, 0xff})
+		}
+	}
+	return ebiten.NewImageFromImage(img)
+}
+
+func TestNewAtlasHash(t *testing.T) {
+	a, err := atlas.NewAtlas([]byte(hashJSON), newAtlasImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(a.Sprites), 2; got != want {
+		t.Fatalf("len(Sprites): got: %d, want: %d", got, want)
+	}
+	if got, want := a.Sprites[0].Name, "walk_00.png"; got != want {
+		t.Errorf("Sprites[0].Name: got: %q, want: %q", got, want)
+	}
+
+	s := a.Sprite("walk_01.png")
+	if s == nil {
+		t.Fatal("Sprite must not return nil")
+	}
+	if got, want := s.Trim, image.Rect(1, 2, 5, 4); got != want {
+		t.Errorf("Trim: got: %v, want: %v", got, want)
+	}
+	if x, y := s.Pivot(); x != 4 || y != 6 {
+		t.Errorf("Pivot: got: (%v, %v), want: (4, 6)", x, y)
+	}
+	g := s.GeoM()
+	if x, y := g.Apply(0, 0); x != 1 || y != 2 {
+		t.Errorf("GeoM.Apply(0, 0): got: (%v, %v), want: (1, 2)", x, y)
+	}
+
+	// The rotated sprite occupies 3x2 pixels in the atlas.
+	r := a.Sprite("walk_00.png")
+	if got, want := r.Image.Bounds(), image.Rect(4, 0, 7, 2); got != want {
+		t.Errorf("Bounds: got: %v, want: %v", got, want)
+	}
+	g = r.GeoM()
+	for _, tc := range []struct {
+		u, v float64
+		x, y float64
+	}{
+		// The upper-right corner in the atlas is the upper-left corner of the original image.
+		{3, 0, 0, 0},
+		{3, 2, 2, 0},
+		{0, 0, 0, 3},
+	} {
+		x, y := g.Apply(tc.u, tc.v)
+		if math.Abs(x-tc.x) > 1e-9 || math.Abs(y-tc.y) > 1e-9 {
+			t.Errorf("GeoM.Apply(%v, %v): got: (%v, %v), want: (%v, %v)", tc.u, tc.v, x, y, tc.x, tc.y)
+		}
+	}
+
+	if got, want := len(a.SpritesWithPrefix("walk_")), 2; got != want {
+		t.Errorf("len(SpritesWithPrefix): got: %d, want: %d", got, want)
+	}
+}
+
+func TestNewAtlasArray(t *testing.T) {
+	a, err := atlas.NewAtlas([]byte(arrayJSON), newAtlasImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := a.Sprite("idle.png")
+	if s == nil {
+		t.Fatal("Sprite must not return nil")
+	}
+	if got, want := s.Image.Bounds(), image.Rect(0, 4, 2, 6); got != want {
+		t.Errorf("Bounds: got: %v, want: %v", got, want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	a0, err := atlas.NewAtlas([]byte(hashJSON), newAtlasImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1, err := atlas.NewAtlas([]byte(arrayJSON), newAtlasImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r atlas.Registry
+	if err := r.Add(a0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(a1); err != nil {
+		t.Fatal(err)
+	}
+	if r.Sprite("idle.png") != a1.Sprite("idle.png") {
+		t.Errorf("Sprite must return the sprite of the added atlas")
+	}
+	if err := r.Add(a0); err == nil {
+		t.Errorf("Add must return an error for duplicated names")
+	}
+}
+
+func TestSpriteDraw(t *testing.T) {
+	a, err := atlas.NewAtlas([]byte(hashJSON), newAtlasImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(2, 3)
+	a.Sprite("walk_00.png").Draw(dst, nil)
+	// The pixel (0, 0) of the original image is the pixel (6, 0) in the atlas.
+	if got, want := dst.At(0, 0), (color.RGBA{0x60, 0, 0, 0xff}); got != want {
+		t.Errorf("At(0, 0): got: %v, want: %v", got, want)
+	}
+	// The pixel (0, 2) of the original image is the pixel (4, 0) in the atlas.
+	if got, want := dst.At(0, 2), (color.RGBA{0x40, 0, 0, 0xff}); got != want {
+		t.Errorf("At(0, 2): got: %v, want: %v", got, want)
+	}
+}
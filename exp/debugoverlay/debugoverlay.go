@@ -0,0 +1,343 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package debugoverlay provides an overlay showing performance statistics.
+// This package is experimental and the API might be changed in the future.
+//
+// The overlay shows FPS and TPS graphs, a frame-time histogram, the numbers of draw calls and image uploads,
+// GC statistics, and user-defined counters.
+//
+// Call Overlay.Update at the beginning of the game's Update, and Overlay.Draw at the end of the game's Draw.
+// The statistics of the graphics commands are the ones of the last completed frame, and include the overlay's own draw calls.
+package debugoverlay
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
+)
+
+var (
+	whiteImage    = ebiten.NewImage(3, 3)
+	whiteSubImage = whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+)
+
+func init() {
+	b := whiteImage.Bounds()
+	pix := make([]byte, 4*b.Dx()*b.Dy())
+	for i := range pix {
+		pix[i] = 0xff
+	}
+	// This is hacky, but WritePixels is better than Fill in term of automatic texture packing.
+	whiteImage.WritePixels(pix)
+}
+
+const (
+	sampleCount = 120
+
+	// memStatsInterval is the interval to read runtime.MemStats, which stops the world.
+	memStatsInterval = 500 * time.Millisecond
+
+	panelX          = 4
+	panelY          = 4
+	panelWidth      = 2*sampleCount + 16
+	padding         = 8
+	lineHeight      = 16
+	graphHeight     = 32
+	histogramHeight = 32
+)
+
+// histogramBuckets is the upper bounds of the frame-time histogram buckets in milliseconds.
+var histogramBuckets = [...]float64{4, 8, 12, 17, 25, 33, 50, math.Inf(1)}
+
+// Overlay is a debug overlay.
+//
+// Overlay's functions are not concurrent-safe.
+type Overlay struct {
+	visible   bool
+	toggleKey ebiten.Key
+
+	frameTimes ring
+	tpsSamples ring
+	lastDraw   time.Time
+
+	memStats     runtime.MemStats
+	lastMemStats time.Time
+
+	counters     map[string]float64
+	counterNames []string
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+	sb       strings.Builder
+}
+
+// NewOverlay creates a new Overlay. The overlay is initially invisible, and the toggle key is ebiten.KeyF12.
+func NewOverlay() *Overlay {
+	return &Overlay{
+		toggleKey: ebiten.KeyF12,
+		counters:  map[string]float64{},
+	}
+}
+
+// SetToggleKey sets the key to toggle the visibility.
+func (o *Overlay) SetToggleKey(key ebiten.Key) {
+	o.toggleKey = key
+}
+
+// SetVisible sets the visibility.
+func (o *Overlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible reports whether the overlay is visible.
+func (o *Overlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetCounter sets the value of the user-defined counter with the name.
+func (o *Overlay) SetCounter(name string, value float64) {
+	if _, ok := o.counters[name]; !ok {
+		o.counterNames = append(o.counterNames, name)
+		sort.Strings(o.counterNames)
+	}
+	o.counters[name] = value
+}
+
+// Counter returns the value of the user-defined counter with the name.
+func (o *Overlay) Counter(name string) float64 {
+	return o.counters[name]
+}
+
+// AddCounter adds delta to the value of the user-defined counter with the name.
+func (o *Overlay) AddCounter(name string, delta float64) {
+	o.SetCounter(name, o.counters[name]+delta)
+}
+
+// RemoveCounter removes the user-defined counter with the name.
+func (o *Overlay) RemoveCounter(name string) {
+	if _, ok := o.counters[name]; !ok {
+		return
+	}
+	delete(o.counters, name)
+	for i, n := range o.counterNames {
+		if n == name {
+			o.counterNames = append(o.counterNames[:i], o.counterNames[i+1:]...)
+			break
+		}
+	}
+}
+
+// Update toggles the visibility by the toggle key and records the statistics.
+func (o *Overlay) Update() {
+	if inpututil.IsKeyJustPressed(o.toggleKey) {
+		o.visible = !o.visible
+	}
+	o.tpsSamples.push(ebiten.ActualTPS())
+}
+
+// Draw records the frame time and draws the overlay onto screen if the overlay is visible.
+func (o *Overlay) Draw(screen *ebiten.Image) {
+	now := time.Now()
+	if !o.lastDraw.IsZero() {
+		o.frameTimes.push(float64(now.Sub(o.lastDraw)) / float64(time.Millisecond))
+	}
+	o.lastDraw = now
+
+	if !o.visible {
+		return
+	}
+
+	if now.Sub(o.lastMemStats) >= memStatsInterval {
+		runtime.ReadMemStats(&o.memStats)
+		o.lastMemStats = now
+	}
+
+	text := o.text()
+	lines := strings.Count(text, "\n") + 1
+
+	o.vertices = o.vertices[:0]
+	o.indices = o.indices[:0]
+
+	x := float32(panelX)
+	y := float32(panelY)
+	textHeight := float32(lines * lineHeight)
+	height := padding + textHeight + padding + graphHeight + padding + histogramHeight + padding
+	o.appendRect(x, y, panelWidth, height, 0, 0, 0, 0.6)
+
+	gx := x + padding
+	gy := y + padding + textHeight + padding
+	o.appendGraph(&o.frameTimes, gx, gy, func(v float64) float64 {
+		// Convert a frame time to FPS.
+		if v <= 0 {
+			return 0
+		}
+		return 1000 / v
+	}, 0.2, 0.9, 0.2)
+	o.appendGraph(&o.tpsSamples, gx, gy, nil, 0.2, 0.6, 1)
+
+	hy := gy + graphHeight + padding
+	o.appendHistogram(gx, hy)
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	screen.DrawTriangles(o.vertices, o.indices, whiteSubImage, op)
+
+	ebitenutil.DebugPrintAt(screen, text, panelX+padding, panelY+padding)
+}
+
+func (o *Overlay) text() string {
+	o.sb.Reset()
+
+	ft := o.frameTimes.last()
+	fmt.Fprintf(&o.sb, "FPS: %0.2f  TPS: %0.2f\n", ebiten.ActualFPS(), ebiten.ActualTPS())
+	fmt.Fprintf(&o.sb, "Frame: %0.2f ms (max: %0.2f ms)\n", ft, o.frameTimes.max())
+
+	s := graphicscommand.LastFrameStats()
+	fmt.Fprintf(&o.sb, "Draw calls: %d  Vertices: %d\n", s.DrawCalls, s.Vertices)
+	fmt.Fprintf(&o.sb, "Uploads: %d (%0.1f KiB)\n", s.ImageUploads, float64(s.UploadedBytes)/1024)
+
+	m := &o.memStats
+	var pause float64
+	if m.NumGC > 0 {
+		pause = float64(m.PauseNs[(m.NumGC+255)%256]) / float64(time.Millisecond)
+	}
+	fmt.Fprintf(&o.sb, "Heap: %0.1f MiB  GC: %d (%0.2f ms)", float64(m.HeapAlloc)/(1<<20), m.NumGC, pause)
+
+	for _, name := range o.counterNames {
+		fmt.Fprintf(&o.sb, "\n%s: %g", name, o.counters[name])
+	}
+	return o.sb.String()
+}
+
+// appendGraph appends bars of the samples to the vertices.
+// The bars are scaled by the maximum value of the samples, and with the translucent colors the graphs can overlap.
+func (o *Overlay) appendGraph(samples *ring, x, y float32, conv func(float64) float64, r, g, b float32) {
+	value := func(i int) float64 {
+		v := samples.at(i)
+		if conv != nil {
+			v = conv(v)
+		}
+		return v
+	}
+
+	var maxValue float64
+	for i := 0; i < samples.len(); i++ {
+		maxValue = math.Max(maxValue, value(i))
+	}
+	if maxValue <= 0 {
+		return
+	}
+	// Align the graph to the right so that the latest sample is always at the same position.
+	offset := sampleCount - samples.len()
+	for i := 0; i < samples.len(); i++ {
+		h := float32(value(i) / maxValue * graphHeight)
+		o.appendRect(x+float32(2*(offset+i)), y+graphHeight-h, 2, h, r*0.5, g*0.5, b*0.5, 0.5)
+	}
+}
+
+func (o *Overlay) appendHistogram(x, y float32) {
+	var counts [len(histogramBuckets)]int
+	for i := 0; i < o.frameTimes.len(); i++ {
+		v := o.frameTimes.at(i)
+		for j, b := range histogramBuckets {
+			if v < b {
+				counts[j]++
+				break
+			}
+		}
+	}
+	n := o.frameTimes.len()
+	if n == 0 {
+		return
+	}
+
+	w := float32(2*sampleCount) / float32(len(counts))
+	for i, c := range counts {
+		h := float32(c) / float32(n) * histogramHeight
+		r, g, b := float32(0.9), float32(0.9), float32(0.2)
+		// Frame times longer than 60 FPS are red.
+		if histogramBuckets[i] > 17 {
+			g = 0.2
+		}
+		o.appendRect(x+float32(i)*w+1, y+histogramHeight-h, w-2, h, r, g, b, 1)
+	}
+}
+
+func (o *Overlay) appendRect(x, y, width, height float32, r, g, b, a float32) {
+	base := uint16(len(o.vertices))
+	for _, p := range [...][2]float32{{x, y}, {x + width, y}, {x, y + height}, {x + width, y + height}} {
+		o.vertices = append(o.vertices, ebiten.Vertex{
+			DstX:   p[0],
+			DstY:   p[1],
+			SrcX:   1,
+			SrcY:   1,
+			ColorR: r,
+			ColorG: g,
+			ColorB: b,
+			ColorA: a,
+		})
+	}
+	o.indices = append(o.indices, base, base+1, base+2, base+1, base+3, base+2)
+}
+
+// ring is a fixed-size ring buffer of samples.
+type ring struct {
+	values [sampleCount]float64
+	start  int
+	n      int
+}
+
+func (r *ring) push(v float64) {
+	if r.n < len(r.values) {
+		r.values[(r.start+r.n)%len(r.values)] = v
+		r.n++
+		return
+	}
+	r.values[r.start] = v
+	r.start = (r.start + 1) % len(r.values)
+}
+
+func (r *ring) len() int {
+	return r.n
+}
+
+// at returns the i-th oldest sample.
+func (r *ring) at(i int) float64 {
+	return r.values[(r.start+i)%len(r.values)]
+}
+
+func (r *ring) last() float64 {
+	if r.n == 0 {
+		return 0
+	}
+	return r.at(r.n - 1)
+}
+
+func (r *ring) max() float64 {
+	var m float64
+	for i := 0; i < r.n; i++ {
+		m = math.Max(m, r.at(i))
+	}
+	return m
+}
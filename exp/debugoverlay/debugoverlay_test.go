@@ -0,0 +1,66 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debugoverlay_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/exp/debugoverlay"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+func TestCounters(t *testing.T) {
+	o := debugoverlay.NewOverlay()
+	o.SetCounter("enemies", 3)
+	o.AddCounter("enemies", 2)
+	o.AddCounter("bullets", 1)
+	if got, want := o.Counter("enemies"), 5.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := o.Counter("bullets"), 1.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	o.RemoveCounter("enemies")
+	if got, want := o.Counter("enemies"), 0.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	o := debugoverlay.NewOverlay()
+	o.SetCounter("counter", 1)
+
+	dst := ebiten.NewImage(320, 240)
+	for i := 0; i < 3; i++ {
+		o.Update()
+		o.Draw(dst)
+	}
+	// The overlay is invisible by default.
+	if got, want := dst.At(4, 4), (color.RGBA{}); got != want {
+		t.Errorf("At(4, 4): got: %v, want: %v", got, want)
+	}
+
+	o.SetVisible(true)
+	o.Draw(dst)
+	if got := dst.At(4, 4).(color.RGBA); got.A == 0 {
+		t.Errorf("At(4, 4): got: %v, want: non-transparent", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package particles provides a particle system.
+// This package is experimental and the API might be changed in the future.
+//
+// Particles are simulated on CPU, and all the particles of an emitter are rendered with one DrawTriangles call.
+package particles
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	whiteImage    = ebiten.NewImage(3, 3)
+	whiteSubImage = whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+)
+
+func init() {
+	b := whiteImage.Bounds()
+	pix := make([]byte, 4*b.Dx()*b.Dy())
+	for i := range pix {
+		pix[i] = 0xff
+	}
+	// This is hacky, but WritePixels is better than Fill in term of automatic texture packing.
+	whiteImage.WritePixels(pix)
+}
+
+// Range is a range of values. A value is chosen randomly and uniformly in [Min, Max].
+type Range struct {
+	Min float64
+	Max float64
+}
+
+func (r Range) random(rnd *rand.Rand) float64 {
+	if r.Min == r.Max {
+		return r.Min
+	}
+	return r.Min + (r.Max-r.Min)*rnd.Float64()
+}
+
+// Emitter emits and simulates particles.
+//
+// The fields of Emitter can be modified at any time, and the modifications affect new particles.
+// The zero value of Emitter emits nothing until Rate is set or Burst is called.
+type Emitter struct {
+	// X and Y are the position of the emitter.
+	X float64
+	Y float64
+
+	// SpawnRadius is the radius of the circle around (X, Y) where particles are spawned.
+	SpawnRadius float64
+
+	// Rate is the number of particles emitted per second continuously.
+	Rate float64
+
+	// MaxParticles is the maximum number of the living particles. 0 means no limit.
+	MaxParticles int
+
+	// Lifetime is the lifetime of a particle in seconds.
+	Lifetime Range
+
+	// Speed is the initial speed of a particle in pixels per second.
+	Speed Range
+
+	// Direction is the initial direction of a particle in radians. 0 is the positive X direction, and π/2 is the positive Y direction.
+	Direction Range
+
+	// GravityX and GravityY are the acceleration in pixels per second squared.
+	GravityX float64
+	GravityY float64
+
+	// Damping is the ratio of the velocity lost per second, in [0, 1].
+	Damping float64
+
+	// Rotation is the initial rotation of a particle in radians.
+	Rotation Range
+
+	// AngularVelocity is the angular velocity of a particle in radians per second.
+	AngularVelocity Range
+
+	// StartScale and EndScale are the scales of a particle at the birth and the death, interpolated linearly.
+	// The size of a particle is the size of Image multiplied by the scale.
+	// If both are 0, the scale is 1.
+	StartScale float64
+	EndScale   float64
+
+	// StartColor and EndColor are the colors of a particle at the birth and the death, interpolated linearly.
+	// If StartColor is nil, white is used. If EndColor is nil, StartColor is used.
+	StartColor color.Color
+	EndColor   color.Color
+
+	// Image is the image of a particle. The center of the image is placed at the particle position.
+	// If Image is nil, a 1x1 white image is used.
+	Image *ebiten.Image
+
+	// Rand is the random number generator. If Rand is nil, a generator seeded with the current time is used.
+	Rand *rand.Rand
+
+	particles []particle
+	remainder float64
+	vertices  []ebiten.Vertex
+	indices   []uint16
+}
+
+type particle struct {
+	x, y       float64
+	vx, vy     float64
+	rotation   float64
+	angularVel float64
+	age        float64
+	lifetime   float64
+	startScale float64
+	endScale   float64
+	startColor [4]float32
+	endColor   [4]float32
+}
+
+// Len returns the number of the living particles.
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// Clear removes all the particles.
+func (e *Emitter) Clear() {
+	e.particles = e.particles[:0]
+	e.remainder = 0
+}
+
+// Burst emits n particles immediately.
+func (e *Emitter) Burst(n int) {
+	for i := 0; i < n; i++ {
+		if e.MaxParticles > 0 && len(e.particles) >= e.MaxParticles {
+			return
+		}
+		e.particles = append(e.particles, e.newParticle())
+	}
+}
+
+func (e *Emitter) random() *rand.Rand {
+	if e.Rand == nil {
+		e.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return e.Rand
+}
+
+func (e *Emitter) newParticle() particle {
+	rnd := e.random()
+
+	x, y := e.X, e.Y
+	if e.SpawnRadius > 0 {
+		// Choose a point uniformly in the circle.
+		r := e.SpawnRadius * math.Sqrt(rnd.Float64())
+		s, c := math.Sincos(2 * math.Pi * rnd.Float64())
+		x += r * c
+		y += r * s
+	}
+	speed := e.Speed.random(rnd)
+	s, c := math.Sincos(e.Direction.random(rnd))
+
+	startScale, endScale := e.StartScale, e.EndScale
+	if startScale == 0 && endScale == 0 {
+		startScale, endScale = 1, 1
+	}
+	startColor := colorToFloats(e.StartColor, color.White)
+	endColor := startColor
+	if e.EndColor != nil {
+		endColor = colorToFloats(e.EndColor, color.White)
+	}
+
+	return particle{
+		x:          x,
+		y:          y,
+		vx:         speed * c,
+		vy:         speed * s,
+		rotation:   e.Rotation.random(rnd),
+		angularVel: e.AngularVelocity.random(rnd),
+		lifetime:   e.Lifetime.random(rnd),
+		startScale: startScale,
+		endScale:   endScale,
+		startColor: startColor,
+		endColor:   endColor,
+	}
+}
+
+func colorToFloats(clr color.Color, defaultColor color.Color) [4]float32 {
+	if clr == nil {
+		clr = defaultColor
+	}
+	r, g, b, a := clr.RGBA()
+	return [4]float32{float32(r) / 0xffff, float32(g) / 0xffff, float32(b) / 0xffff, float32(a) / 0xffff}
+}
+
+// Update emits new particles and advances the simulation by one tick.
+// The time of a tick is determined by ebiten.TPS.
+func (e *Emitter) Update() {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	e.Advance(1 / float64(tps))
+}
+
+// Advance emits new particles and advances the simulation by dt seconds.
+func (e *Emitter) Advance(dt float64) {
+	// Update the existing particles first so that new particles start at the emitter position.
+	damping := 1.0
+	if e.Damping > 0 {
+		damping = math.Pow(1-math.Min(e.Damping, 1), dt)
+	}
+	alive := e.particles[:0]
+	for _, p := range e.particles {
+		p.age += dt
+		if p.age >= p.lifetime {
+			continue
+		}
+		p.vx = (p.vx + e.GravityX*dt) * damping
+		p.vy = (p.vy + e.GravityY*dt) * damping
+		p.x += p.vx * dt
+		p.y += p.vy * dt
+		p.rotation += p.angularVel * dt
+		alive = append(alive, p)
+	}
+	e.particles = alive
+
+	if e.Rate > 0 {
+		e.remainder += e.Rate * dt
+		n := int(e.remainder)
+		e.remainder -= float64(n)
+		e.Burst(n)
+	}
+}
+
+// DrawOptions represents options for Emitter.Draw.
+type DrawOptions struct {
+	// GeoM is a geometry matrix to transform the particle positions.
+	//
+	// The default (zero) value is identity.
+	GeoM ebiten.GeoM
+
+	// ColorScale is a scale of colors.
+	//
+	// The default (zero) value is identity, which is (1, 1, 1, 1).
+	ColorScale ebiten.ColorScale
+
+	// Blend is a blending way of the source color and the destination color.
+	// ebiten.BlendLighter is useful for glowing particles like fire.
+	//
+	// The default (zero) value is the regular alpha blending.
+	Blend ebiten.Blend
+
+	// Filter is a type of texture filter.
+	//
+	// The default (zero) value is ebiten.FilterNearest.
+	Filter ebiten.Filter
+}
+
+// Draw draws the living particles onto dst.
+func (e *Emitter) Draw(dst *ebiten.Image, options *DrawOptions) {
+	if len(e.particles) == 0 {
+		return
+	}
+	if options == nil {
+		options = &DrawOptions{}
+	}
+
+	img := e.Image
+	if img == nil {
+		img = whiteSubImage
+	}
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	sx0, sy0, sx1, sy1 := float32(b.Min.X), float32(b.Min.Y), float32(b.Max.X), float32(b.Max.Y)
+	cr, cg, cb, ca := options.ColorScale.R(), options.ColorScale.G(), options.ColorScale.B(), options.ColorScale.A()
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	op.Blend = options.Blend
+	op.Filter = options.Filter
+
+	e.vertices = e.vertices[:0]
+	e.indices = e.indices[:0]
+	for _, p := range e.particles {
+		if len(e.vertices)+4 > math.MaxUint16+1 {
+			dst.DrawTriangles(e.vertices, e.indices, img, op)
+			e.vertices = e.vertices[:0]
+			e.indices = e.indices[:0]
+		}
+
+		t := p.age / p.lifetime
+		scale := p.startScale + (p.endScale-p.startScale)*t
+		var clr [4]float32
+		for i := range clr {
+			clr[i] = p.startColor[i] + (p.endColor[i]-p.startColor[i])*float32(t)
+		}
+
+		var g ebiten.GeoM
+		g.Translate(-w/2, -h/2)
+		g.Scale(scale, scale)
+		g.Rotate(p.rotation)
+		g.Translate(p.x, p.y)
+		g.Concat(options.GeoM)
+
+		base := uint16(len(e.vertices))
+		for _, c := range [...][4]float64{
+			{0, 0, float64(sx0), float64(sy0)},
+			{w, 0, float64(sx1), float64(sy0)},
+			{0, h, float64(sx0), float64(sy1)},
+			{w, h, float64(sx1), float64(sy1)},
+		} {
+			x, y := g.Apply(c[0], c[1])
+			e.vertices = append(e.vertices, ebiten.Vertex{
+				DstX:   float32(x),
+				DstY:   float32(y),
+				SrcX:   float32(c[2]),
+				SrcY:   float32(c[3]),
+				ColorR: clr[0] * cr,
+				ColorG: clr[1] * cg,
+				ColorB: clr[2] * cb,
+				ColorA: clr[3] * ca,
+			})
+		}
+		e.indices = append(e.indices, base, base+1, base+2, base+1, base+3, base+2)
+	}
+	dst.DrawTriangles(e.vertices, e.indices, img, op)
+}
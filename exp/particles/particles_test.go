@@ -0,0 +1,108 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package particles_test
+
+import (
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/exp/particles"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+func TestRate(t *testing.T) {
+	e := &particles.Emitter{
+		Rate:     10,
+		Lifetime: particles.Range{Min: 10, Max: 10},
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+	for i := 0; i < 10; i++ {
+		e.Advance(0.25)
+	}
+	// 10 particles per second for 2.5 seconds.
+	if got, want := e.Len(), 25; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+}
+
+func TestLifetime(t *testing.T) {
+	e := &particles.Emitter{
+		Lifetime: particles.Range{Min: 1, Max: 2},
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+	e.Burst(100)
+	if got, want := e.Len(), 100; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+	e.Advance(0.5)
+	if got, want := e.Len(), 100; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+	e.Advance(1)
+	if got := e.Len(); got <= 0 || got >= 100 {
+		t.Errorf("got: %d, want: (0, 100)", got)
+	}
+	e.Advance(1)
+	if got, want := e.Len(), 0; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+}
+
+func TestMaxParticles(t *testing.T) {
+	e := &particles.Emitter{
+		Rate:         1000,
+		MaxParticles: 30,
+		Lifetime:     particles.Range{Min: 10, Max: 10},
+		Rand:         rand.New(rand.NewSource(1)),
+	}
+	e.Burst(20)
+	e.Advance(1)
+	if got, want := e.Len(), 30; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+	e.Clear()
+	if got, want := e.Len(), 0; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	e := &particles.Emitter{
+		X:          1.5,
+		Y:          1.5,
+		Speed:      particles.Range{Min: 2, Max: 2},
+		Lifetime:   particles.Range{Min: 10, Max: 10},
+		StartColor: color.RGBA{0xff, 0, 0, 0xff},
+		Rand:       rand.New(rand.NewSource(1)),
+	}
+	e.Burst(1)
+	// The particle moves (1, 0) in the positive X direction.
+	e.Advance(0.5)
+
+	dst := ebiten.NewImage(4, 4)
+	e.Draw(dst, nil)
+	if got, want := dst.At(2, 1), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("At(2, 1): got: %v, want: %v", got, want)
+	}
+	if got, want := dst.At(1, 1), (color.RGBA{}); got != want {
+		t.Errorf("At(1, 1): got: %v, want: %v", got, want)
+	}
+}
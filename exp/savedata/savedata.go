@@ -0,0 +1,253 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package savedata provides a storage of save data and settings in the right per-platform location.
+// This package is experimental and the API might be changed in the future.
+//
+// On desktops and mobiles, the data is stored as files in a directory for the application under os.UserConfigDir.
+// On browsers, the data is stored in localStorage.
+//
+// Writing is atomic: a crash while writing never leaves half-written data.
+// The previous data is kept as a backup, and is used when the current data is corrupted.
+package savedata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"strings"
+)
+
+// ErrCorrupted is returned when both the data and its backup are corrupted.
+var ErrCorrupted = errors.New("savedata: the data is corrupted")
+
+// Format is an encoding format of values.
+type Format int
+
+const (
+	// FormatJSON encodes values with encoding/json.
+	FormatJSON Format = iota
+
+	// FormatGob encodes values with encoding/gob.
+	FormatGob
+)
+
+// Options represents options for Store.Save and Store.Load.
+type Options struct {
+	// Format is the encoding format.
+	//
+	// The default (zero) value is FormatJSON.
+	Format Format
+
+	// Version is the version of the data format.
+	// Save records Version with the data, and Load migrates older data to Version with Migrate.
+	//
+	// The default (zero) value is 0.
+	Version int
+
+	// Migrate converts encoded data of the version to the next version.
+	// Load calls Migrate repeatedly until the data reaches Version.
+	// If Migrate is nil, Load returns an error for older data.
+	Migrate func(version int, data []byte) ([]byte, error)
+}
+
+// Store is a storage of save data for an application.
+//
+// Store's functions are concurrent-safe as long as different names are used.
+type Store struct {
+	storage storage
+}
+
+// NewStore creates a new Store for the application.
+// appName is used as the directory name or the key prefix, and must be a valid file name.
+func NewStore(appName string) (*Store, error) {
+	if err := validateName(appName); err != nil {
+		return nil, err
+	}
+	s, err := newStorage(appName)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		storage: s,
+	}, nil
+}
+
+// Location returns the directory path or the key prefix where the data is stored.
+func (s *Store) Location() string {
+	return s.storage.location()
+}
+
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\:`) {
+		return fmt.Errorf("savedata: invalid name: %q", name)
+	}
+	return nil
+}
+
+// WriteFile writes the data with the name atomically.
+// The previous data with the name is kept as a backup.
+func (s *Store) WriteFile(name string, data []byte) error {
+	return s.write(name, 0, data)
+}
+
+// ReadFile reads the data with the name.
+// If the data is corrupted, ReadFile reads the backup instead.
+//
+// If neither the data nor its backup exists, ReadFile returns an error that satisfies errors.Is(err, fs.ErrNotExist).
+func (s *Store) ReadFile(name string) ([]byte, error) {
+	_, data, err := s.read(name)
+	return data, err
+}
+
+// Remove removes the data with the name and its backup.
+func (s *Store) Remove(name string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	return s.storage.remove(name)
+}
+
+// Save encodes v and writes it with the name atomically.
+// If options is nil, the default options are used.
+func (s *Store) Save(name string, v any, options *Options) error {
+	if options == nil {
+		options = &Options{}
+	}
+	var buf bytes.Buffer
+	switch options.Format {
+	case FormatJSON:
+		if err := json.NewEncoder(&buf).Encode(v); err != nil {
+			return fmt.Errorf("savedata: encoding %s failed: %w", name, err)
+		}
+	case FormatGob:
+		if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+			return fmt.Errorf("savedata: encoding %s failed: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("savedata: invalid format: %d", options.Format)
+	}
+	return s.write(name, options.Version, buf.Bytes())
+}
+
+// Load reads the data with the name and decodes it into v.
+// If the data is older than options.Version, the data is migrated with options.Migrate.
+// If the data is corrupted, Load reads the backup instead.
+// If options is nil, the default options are used.
+//
+// If neither the data nor its backup exists, Load returns an error that satisfies errors.Is(err, fs.ErrNotExist).
+func (s *Store) Load(name string, v any, options *Options) error {
+	if options == nil {
+		options = &Options{}
+	}
+	version, data, err := s.read(name)
+	if err != nil {
+		return err
+	}
+	if version > options.Version {
+		return fmt.Errorf("savedata: the version of %s is %d, which is newer than %d", name, version, options.Version)
+	}
+	for ; version < options.Version; version++ {
+		if options.Migrate == nil {
+			return fmt.Errorf("savedata: the version of %s is %d, but Migrate is not specified", name, version)
+		}
+		data, err = options.Migrate(version, data)
+		if err != nil {
+			return fmt.Errorf("savedata: migrating %s from the version %d failed: %w", name, version, err)
+		}
+	}
+
+	switch options.Format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("savedata: decoding %s failed: %w", name, err)
+		}
+	case FormatGob:
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+			return fmt.Errorf("savedata: decoding %s failed: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("savedata: invalid format: %d", options.Format)
+	}
+	return nil
+}
+
+func (s *Store) write(name string, version int, data []byte) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	return s.storage.write(name, encodeEnvelope(version, data))
+}
+
+func (s *Store) read(name string) (int, []byte, error) {
+	if err := validateName(name); err != nil {
+		return 0, nil, err
+	}
+
+	current, backup, err := s.storage.read(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	if current == nil && backup == nil {
+		return 0, nil, fmt.Errorf("savedata: %s: %w", name, fs.ErrNotExist)
+	}
+	if current != nil {
+		if version, data, ok := decodeEnvelope(current); ok {
+			return version, data, nil
+		}
+	}
+	if backup != nil {
+		if version, data, ok := decodeEnvelope(backup); ok {
+			return version, data, nil
+		}
+	}
+	return 0, nil, ErrCorrupted
+}
+
+// envelopeMagic is the magic number at the beginning of the stored data.
+const envelopeMagic = "EBSD"
+
+// envelopeHeaderSize is the size of the header: the magic number, the version, the length, and the CRC-32 checksum of the data.
+const envelopeHeaderSize = len(envelopeMagic) + 4 + 4 + 4
+
+func encodeEnvelope(version int, data []byte) []byte {
+	bs := make([]byte, envelopeHeaderSize+len(data))
+	copy(bs, envelopeMagic)
+	binary.LittleEndian.PutUint32(bs[4:], uint32(version))
+	binary.LittleEndian.PutUint32(bs[8:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(bs[12:], crc32.ChecksumIEEE(data))
+	copy(bs[envelopeHeaderSize:], data)
+	return bs
+}
+
+func decodeEnvelope(bs []byte) (int, []byte, bool) {
+	if len(bs) < envelopeHeaderSize || string(bs[:4]) != envelopeMagic {
+		return 0, nil, false
+	}
+	version := int(binary.LittleEndian.Uint32(bs[4:]))
+	n := int(binary.LittleEndian.Uint32(bs[8:]))
+	if len(bs)-envelopeHeaderSize != n {
+		return 0, nil, false
+	}
+	data := bs[envelopeHeaderSize:]
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(bs[12:]) {
+		return 0, nil, false
+	}
+	return version, data, true
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package savedata_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/exp/savedata"
+)
+
+type settings struct {
+	Volume float64
+	Name   string
+}
+
+func TestSaveLoad(t *testing.T) {
+	for _, format := range []savedata.Format{savedata.FormatJSON, savedata.FormatGob} {
+		s := savedata.NewStoreForTesting(t.TempDir())
+		op := &savedata.Options{Format: format}
+		want := settings{Volume: 0.5, Name: "player"}
+		if err := s.Save("settings", &want, op); err != nil {
+			t.Fatal(err)
+		}
+		var got settings
+		if err := s.Load("settings", &got, op); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("format: %d: got: %v, want: %v", format, got, want)
+		}
+	}
+}
+
+func TestNotExist(t *testing.T) {
+	s := savedata.NewStoreForTesting(t.TempDir())
+	var v settings
+	if err := s.Load("settings", &v, nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got: %v, want: %v", err, fs.ErrNotExist)
+	}
+	if err := s.Remove("settings"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidName(t *testing.T) {
+	s := savedata.NewStoreForTesting(t.TempDir())
+	for _, name := range []string{"", ".", "..", "a/b", `a\b`} {
+		if err := s.WriteFile(name, nil); err == nil {
+			t.Errorf("WriteFile(%q) must return an error", name)
+		}
+	}
+}
+
+func TestCorruption(t *testing.T) {
+	dir := t.TempDir()
+	s := savedata.NewStoreForTesting(dir)
+	if err := s.WriteFile("data", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteFile("data", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.ReadFile("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("new"); !bytes.Equal(got, want) {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+
+	// Corrupt the current data. The backup is used instead.
+	path := filepath.Join(dir, "data")
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs[len(bs)-1] ^= 0xff
+	if err := os.WriteFile(path, bs, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.ReadFile("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("old"); !bytes.Equal(got, want) {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+
+	// Corrupt the backup too.
+	if err := os.WriteFile(path+".bak", []byte("broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ReadFile("data"); !errors.Is(err, savedata.ErrCorrupted) {
+		t.Errorf("got: %v, want: %v", err, savedata.ErrCorrupted)
+	}
+
+	// If only the backup exists, the backup is used.
+	if err := s.Remove("data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteFile("data", []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(path, path+".bak"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.ReadFile("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("first"); !bytes.Equal(got, want) {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestMigration(t *testing.T) {
+	s := savedata.NewStoreForTesting(t.TempDir())
+	if err := s.WriteFile("settings", []byte(`{"Vol": 50}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	var v settings
+	if err := s.Load("settings", &v, &savedata.Options{Version: 1}); err == nil {
+		t.Errorf("Load without Migrate must return an error")
+	}
+
+	var versions []int
+	op := &savedata.Options{
+		Version: 2,
+		Migrate: func(version int, data []byte) ([]byte, error) {
+			versions = append(versions, version)
+			switch version {
+			case 0:
+				// Rename the field and change the scale.
+				return []byte(`{"Volume": 0.5}`), nil
+			case 1:
+				return bytes.Replace(data, []byte("}"), []byte(`, "Name": "default"}`), 1), nil
+			}
+			return nil, errors.New("unexpected version")
+		},
+	}
+	if err := s.Load("settings", &v, op); err != nil {
+		t.Fatal(err)
+	}
+	if want := (settings{Volume: 0.5, Name: "default"}); v != want {
+		t.Errorf("got: %v, want: %v", v, want)
+	}
+	if len(versions) != 2 || versions[0] != 0 || versions[1] != 1 {
+		t.Errorf("got: %v, want: [0 1]", versions)
+	}
+
+	// Newer data cannot be loaded.
+	if err := s.Save("settings", &v, &savedata.Options{Version: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load("settings", &v, op); err == nil {
+		t.Errorf("Load must return an error for newer data")
+	}
+}
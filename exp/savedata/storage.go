@@ -0,0 +1,120 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package savedata
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type storage interface {
+	location() string
+
+	// read reads the current data and the backup data. A nil slice means the data doesn't exist.
+	read(name string) (current, backup []byte, err error)
+
+	// write writes the data atomically, and keeps the current data as the backup.
+	write(name string, data []byte) error
+
+	// remove removes the current data and the backup data.
+	remove(name string) error
+}
+
+const backupSuffix = ".bak"
+
+// fileStorage is a storage with files in a directory.
+type fileStorage struct {
+	dir string
+}
+
+func (f *fileStorage) location() string {
+	return f.dir
+}
+
+func (f *fileStorage) read(name string) ([]byte, []byte, error) {
+	current, err := readFileIfExists(filepath.Join(f.dir, name))
+	if err != nil {
+		return nil, nil, err
+	}
+	backup, err := readFileIfExists(filepath.Join(f.dir, name+backupSuffix))
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, backup, nil
+}
+
+func readFileIfExists(path string) ([]byte, error) {
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bs == nil {
+		bs = []byte{}
+	}
+	return bs, nil
+}
+
+func (f *fileStorage) write(name string, data []byte) (err error) {
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return err
+	}
+
+	// Write the data to a temporary file in the same directory, so that renaming is atomic.
+	tmp, err := os.CreateTemp(f.dir, name+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// If the process crashes between the two renamings, the backup is still available.
+	path := filepath.Join(f.dir, name)
+	if err := os.Rename(path, path+backupSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *fileStorage) remove(name string) error {
+	path := filepath.Join(f.dir, name)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(path + backupSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package savedata
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+// localStorage is a storage with the localStorage of the browser.
+// As localStorage can store only strings, the data is encoded in Base64.
+type localStorage struct {
+	prefix  string
+	storage js.Value
+}
+
+func newStorage(appName string) (storage, error) {
+	s := js.Global().Get("localStorage")
+	if !s.Truthy() {
+		return nil, errors.New("savedata: localStorage is not available")
+	}
+	return &localStorage{
+		prefix:  appName + "/",
+		storage: s,
+	}, nil
+}
+
+func (l *localStorage) location() string {
+	return l.prefix
+}
+
+func (l *localStorage) getItem(key string) []byte {
+	v := l.storage.Call("getItem", key)
+	if v.IsNull() {
+		return nil
+	}
+	bs, err := base64.StdEncoding.DecodeString(v.String())
+	if err != nil {
+		// Treat undecodable data as corrupted data.
+		return []byte{}
+	}
+	return bs
+}
+
+func (l *localStorage) read(name string) ([]byte, []byte, error) {
+	return l.getItem(l.prefix + name), l.getItem(l.prefix + name + backupSuffix), nil
+}
+
+func (l *localStorage) write(name string, data []byte) (err error) {
+	// setItem throws an exception when the quota is exceeded.
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(js.Error); ok {
+				err = fmt.Errorf("savedata: writing %s failed: %w", name, e)
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	key := l.prefix + name
+	// Each setItem is atomic. If writing the current data fails, the backup is still available.
+	if v := l.storage.Call("getItem", key); !v.IsNull() {
+		l.storage.Call("setItem", key+backupSuffix, v)
+	}
+	l.storage.Call("setItem", key, base64.StdEncoding.EncodeToString(data))
+	return nil
+}
+
+func (l *localStorage) remove(name string) error {
+	key := l.prefix + name
+	l.storage.Call("removeItem", key)
+	l.storage.Call("removeItem", key+backupSuffix)
+	return nil
+}
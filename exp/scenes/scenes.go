@@ -0,0 +1,231 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scenes provides a manager of a scene stack with transitions.
+// This package is experimental and the API might be changed in the future.
+//
+// A Manager is expected to be called from the Update and Draw functions of the game.
+// Only the top scene of the stack is updated and drawn.
+package scenes
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Scene is a scene of a game.
+type Scene interface {
+	// Update updates the scene by one tick.
+	Update() error
+
+	// Draw draws the scene onto the screen.
+	Draw(screen *ebiten.Image)
+}
+
+// Enterer is an optional interface of a Scene.
+// Enter is called when the scene becomes the top of the stack.
+type Enterer interface {
+	Enter()
+}
+
+// Exiter is an optional interface of a Scene.
+// Exit is called when the scene is no longer the top of the stack.
+type Exiter interface {
+	Exit()
+}
+
+// Transition renders a transition between two scenes.
+type Transition interface {
+	// Duration returns the duration of the transition.
+	Duration() time.Duration
+
+	// Draw draws the transition onto dst.
+	// from and to are the rendering results of the previous scene and the next scene, and have the same size as dst.
+	// progress is in [0, 1].
+	Draw(dst, from, to *ebiten.Image, progress float64)
+}
+
+// Manager manages a stack of scenes.
+//
+// Manager's functions are not concurrent-safe.
+type Manager struct {
+	stack []Scene
+
+	transition Transition
+	from       Scene
+	elapsed    time.Duration
+
+	fromImage *ebiten.Image
+	toImage   *ebiten.Image
+}
+
+// NewManager creates a new Manager with the initial scene.
+func NewManager(initial Scene) *Manager {
+	m := &Manager{}
+	m.stack = append(m.stack, initial)
+	enter(initial)
+	return m
+}
+
+// Current returns the top scene of the stack, or nil if the stack is empty.
+func (m *Manager) Current() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// Len returns the number of the scenes in the stack.
+func (m *Manager) Len() int {
+	return len(m.stack)
+}
+
+// Transitioning reports whether a transition is in progress.
+func (m *Manager) Transitioning() bool {
+	return m.transition != nil
+}
+
+// Push pushes the scene onto the stack with the transition.
+// If transition is nil, the scene is switched immediately.
+func (m *Manager) Push(scene Scene, transition Transition) {
+	from := m.Current()
+	exit(from)
+	m.stack = append(m.stack, scene)
+	enter(scene)
+	m.startTransition(from, transition)
+}
+
+// Pop pops the top scene from the stack with the transition, and returns the popped scene.
+// If transition is nil, the scene is switched immediately.
+//
+// Pop panics if the stack is empty.
+func (m *Manager) Pop(transition Transition) Scene {
+	if len(m.stack) == 0 {
+		panic("scenes: the scene stack is empty")
+	}
+	from := m.stack[len(m.stack)-1]
+	m.stack[len(m.stack)-1] = nil
+	m.stack = m.stack[:len(m.stack)-1]
+	exit(from)
+	enter(m.Current())
+	m.startTransition(from, transition)
+	return from
+}
+
+// Replace replaces the top scene of the stack with the scene with the transition, and returns the replaced scene.
+// If the stack is empty, Replace pushes the scene.
+// If transition is nil, the scene is switched immediately.
+func (m *Manager) Replace(scene Scene, transition Transition) Scene {
+	if len(m.stack) == 0 {
+		m.Push(scene, transition)
+		return nil
+	}
+	from := m.stack[len(m.stack)-1]
+	exit(from)
+	m.stack[len(m.stack)-1] = scene
+	enter(scene)
+	m.startTransition(from, transition)
+	return from
+}
+
+func (m *Manager) startTransition(from Scene, transition Transition) {
+	// A new transition cancels the current transition.
+	m.transition = nil
+	m.from = nil
+	m.elapsed = 0
+	if transition == nil || transition.Duration() <= 0 {
+		return
+	}
+	m.transition = transition
+	m.from = from
+}
+
+// Update updates the top scene.
+// While a transition is in progress, Update advances the transition and doesn't update any scenes.
+func (m *Manager) Update() error {
+	if m.transition != nil {
+		m.elapsed += tick()
+		if m.elapsed >= m.transition.Duration() {
+			m.transition = nil
+			m.from = nil
+			m.elapsed = 0
+		}
+		return nil
+	}
+	s := m.Current()
+	if s == nil {
+		return nil
+	}
+	return s.Update()
+}
+
+// tick returns the duration of one tick.
+func tick() time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	return time.Second / time.Duration(tps)
+}
+
+// Draw draws the top scene onto the screen.
+// While a transition is in progress, Draw renders the previous and the next scenes onto offscreens and draws the transition.
+func (m *Manager) Draw(screen *ebiten.Image) {
+	if m.transition == nil {
+		if s := m.Current(); s != nil {
+			s.Draw(screen)
+		}
+		return
+	}
+
+	m.fromImage = ensureOffscreen(m.fromImage, screen)
+	m.toImage = ensureOffscreen(m.toImage, screen)
+	if m.from != nil {
+		m.from.Draw(m.fromImage)
+	}
+	if s := m.Current(); s != nil {
+		s.Draw(m.toImage)
+	}
+
+	progress := float64(m.elapsed) / float64(m.transition.Duration())
+	if progress > 1 {
+		progress = 1
+	}
+	m.transition.Draw(screen, m.fromImage, m.toImage, progress)
+}
+
+func ensureOffscreen(img *ebiten.Image, screen *ebiten.Image) *ebiten.Image {
+	b := screen.Bounds()
+	if img != nil {
+		if img.Bounds().Dx() == b.Dx() && img.Bounds().Dy() == b.Dy() {
+			img.Clear()
+			return img
+		}
+		img.Deallocate()
+	}
+	return ebiten.NewImage(b.Dx(), b.Dy())
+}
+
+func enter(scene Scene) {
+	if e, ok := scene.(Enterer); ok {
+		e.Enter()
+	}
+}
+
+func exit(scene Scene) {
+	if e, ok := scene.(Exiter); ok {
+		e.Exit()
+	}
+}
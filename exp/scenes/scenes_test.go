@@ -0,0 +1,171 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scenes_test
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/exp/scenes"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+type testScene struct {
+	name    string
+	color   color.Color
+	updates int
+	log     *[]string
+}
+
+func (s *testScene) Update() error {
+	s.updates++
+	return nil
+}
+
+func (s *testScene) Draw(screen *ebiten.Image) {
+	screen.Fill(s.color)
+}
+
+func (s *testScene) Enter() {
+	*s.log = append(*s.log, "enter "+s.name)
+}
+
+func (s *testScene) Exit() {
+	*s.log = append(*s.log, "exit "+s.name)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStack(t *testing.T) {
+	var log []string
+	a := &testScene{name: "a", log: &log}
+	b := &testScene{name: "b", log: &log}
+	c := &testScene{name: "c", log: &log}
+
+	m := scenes.NewManager(a)
+	m.Push(b, nil)
+	if got := m.Replace(c, nil); got != b {
+		t.Errorf("Replace: got: %v, want: %v", got, b)
+	}
+	if got, want := m.Len(), 2; got != want {
+		t.Errorf("Len: got: %d, want: %d", got, want)
+	}
+	if got := m.Pop(nil); got != c {
+		t.Errorf("Pop: got: %v, want: %v", got, c)
+	}
+	if got := m.Current(); got != a {
+		t.Errorf("Current: got: %v, want: %v", got, a)
+	}
+
+	want := []string{"enter a", "exit a", "enter b", "exit b", "enter c", "exit c", "enter a"}
+	if !equalStrings(log, want) {
+		t.Errorf("got: %v, want: %v", log, want)
+	}
+
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.updates, 1; got != want {
+		t.Errorf("updates: got: %d, want: %d", got, want)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	var log []string
+	a := &testScene{name: "a", log: &log}
+	b := &testScene{name: "b", log: &log}
+
+	m := scenes.NewManager(a)
+	// The duration is 10 ticks.
+	m.Push(b, &scenes.Fade{Length: 10 * (time.Second / time.Duration(ebiten.DefaultTPS))})
+	if !m.Transitioning() {
+		t.Fatal("Transitioning must return true")
+	}
+	for i := 0; i < 10; i++ {
+		if err := m.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m.Transitioning() {
+		t.Errorf("Transitioning must return false")
+	}
+	// The scenes are not updated during the transition.
+	if a.updates != 0 || b.updates != 0 {
+		t.Errorf("updates: got: (%d, %d), want: (0, 0)", a.updates, b.updates)
+	}
+
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.updates, 1; got != want {
+		t.Errorf("updates: got: %d, want: %d", got, want)
+	}
+}
+
+func TestFadeDraw(t *testing.T) {
+	from := ebiten.NewImage(4, 4)
+	from.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	to := ebiten.NewImage(4, 4)
+	to.Fill(color.RGBA{0, 0, 0xff, 0xff})
+
+	for _, tc := range []struct {
+		fade     *scenes.Fade
+		progress float64
+		want     color.RGBA
+	}{
+		{&scenes.Fade{}, 0, color.RGBA{0xff, 0, 0, 0xff}},
+		{&scenes.Fade{}, 1, color.RGBA{0, 0, 0xff, 0xff}},
+		{&scenes.Fade{Color: color.White}, 0.5, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{&scenes.Fade{Color: color.White}, 1, color.RGBA{0, 0, 0xff, 0xff}},
+	} {
+		dst := ebiten.NewImage(4, 4)
+		tc.fade.Draw(dst, from, to, tc.progress)
+		if got := dst.At(0, 0); got != tc.want {
+			t.Errorf("progress: %v: got: %v, want: %v", tc.progress, got, tc.want)
+		}
+	}
+}
+
+func TestSlideDraw(t *testing.T) {
+	from := ebiten.NewImage(4, 4)
+	from.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	to := ebiten.NewImage(4, 4)
+	to.Fill(color.RGBA{0, 0, 0xff, 0xff})
+
+	dst := ebiten.NewImage(4, 4)
+	(&scenes.Slide{Direction: scenes.SlideDirectionLeft}).Draw(dst, from, to, 0.5)
+	if got, want := dst.At(1, 0), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("At(1, 0): got: %v, want: %v", got, want)
+	}
+	if got, want := dst.At(2, 0), (color.RGBA{0, 0, 0xff, 0xff}); got != want {
+		t.Errorf("At(2, 0): got: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scenes
+
+import (
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Fade is a transition that fades scenes.
+type Fade struct {
+	// Length is the duration of the transition.
+	Length time.Duration
+
+	// Color is the color to fade through.
+	// The previous scene fades out to Color in the first half, and the next scene fades in from Color in the second half.
+	// If Color is nil, the scenes are cross-faded.
+	Color color.Color
+}
+
+// Duration implements Transition.
+func (f *Fade) Duration() time.Duration {
+	return f.Length
+}
+
+// Draw implements Transition.
+func (f *Fade) Draw(dst, from, to *ebiten.Image, progress float64) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(dst.Bounds().Min.X), float64(dst.Bounds().Min.Y))
+
+	if f.Color == nil {
+		dst.DrawImage(from, op)
+		op.ColorScale.ScaleAlpha(float32(progress))
+		dst.DrawImage(to, op)
+		return
+	}
+
+	dst.Fill(f.Color)
+	if progress < 0.5 {
+		op.ColorScale.ScaleAlpha(float32(1 - progress*2))
+		dst.DrawImage(from, op)
+		return
+	}
+	op.ColorScale.ScaleAlpha(float32(progress*2 - 1))
+	dst.DrawImage(to, op)
+}
+
+// SlideDirection represents the direction of a Slide transition.
+type SlideDirection int
+
+const (
+	// SlideDirectionLeft moves the scenes to the left. The next scene comes from the right edge.
+	SlideDirectionLeft SlideDirection = iota
+
+	// SlideDirectionRight moves the scenes to the right. The next scene comes from the left edge.
+	SlideDirectionRight
+
+	// SlideDirectionUp moves the scenes upward. The next scene comes from the bottom edge.
+	SlideDirectionUp
+
+	// SlideDirectionDown moves the scenes downward. The next scene comes from the top edge.
+	SlideDirectionDown
+)
+
+// Slide is a transition that slides the next scene in while sliding the previous scene out.
+type Slide struct {
+	// Length is the duration of the transition.
+	Length time.Duration
+
+	// Direction is the direction of the slide.
+	Direction SlideDirection
+}
+
+// Duration implements Transition.
+func (s *Slide) Duration() time.Duration {
+	return s.Length
+}
+
+// Draw implements Transition.
+func (s *Slide) Draw(dst, from, to *ebiten.Image, progress float64) {
+	b := dst.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+
+	var dx, dy float64
+	switch s.Direction {
+	case SlideDirectionLeft:
+		dx = -w
+	case SlideDirectionRight:
+		dx = w
+	case SlideDirectionUp:
+		dy = -h
+	case SlideDirectionDown:
+		dy = h
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(b.Min.X)+dx*progress, float64(b.Min.Y)+dy*progress)
+	dst.DrawImage(from, op)
+
+	op.GeoM.Reset()
+	op.GeoM.Translate(float64(b.Min.X)+dx*(progress-1), float64(b.Min.Y)+dy*(progress-1))
+	dst.DrawImage(to, op)
+}
+
+// ShaderWipe is a transition rendered by a Kage shader.
+//
+// The shader takes the previous scene as imageSrc0 and the next scene as imageSrc1,
+// and a float uniform variable Progress in [0, 1].
+type ShaderWipe struct {
+	// Length is the duration of the transition.
+	Length time.Duration
+
+	// Shader is the shader to render the transition.
+	// If Shader is nil, a horizontal wipe with a soft edge from left to right is used.
+	Shader *ebiten.Shader
+
+	// Uniforms is a set of additional uniform variables for the shader.
+	Uniforms map[string]any
+}
+
+// Duration implements Transition.
+func (s *ShaderWipe) Duration() time.Duration {
+	return s.Length
+}
+
+// Draw implements Transition.
+func (s *ShaderWipe) Draw(dst, from, to *ebiten.Image, progress float64) {
+	shader := s.Shader
+	if shader == nil {
+		shader = defaultWipeShader()
+	}
+
+	b := dst.Bounds()
+	op := &ebiten.DrawRectShaderOptions{}
+	op.GeoM.Translate(float64(b.Min.X), float64(b.Min.Y))
+	op.Images[0] = from
+	op.Images[1] = to
+	op.Uniforms = map[string]any{}
+	for k, v := range s.Uniforms {
+		op.Uniforms[k] = v
+	}
+	op.Uniforms["Progress"] = float32(progress)
+	dst.DrawRectShader(b.Dx(), b.Dy(), shader, op)
+}
+
+var (
+	wipeShader     *ebiten.Shader
+	wipeShaderOnce sync.Once
+)
+
+func defaultWipeShader() *ebiten.Shader {
+	wipeShaderOnce.Do(func() {
+		s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+var Progress float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	const edge = 0.05
+	x := (srcPos.x - imageSrc0Origin().x) / imageSrc0Size().x
+	p := Progress * (1 + edge)
+	t := 1 - smoothstep(p-edge, p, x)
+	return mix(imageSrc0At(srcPos), imageSrc1At(srcPos), t)
+}
+`))
+		if err != nil {
+			panic("scenes: compiling the wipe shader failed: " + err.Error())
+		}
+		wipeShader = s
+	})
+	return wipeShader
+}
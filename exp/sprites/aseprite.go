@@ -0,0 +1,80 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprites
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/exp/aseprite"
+)
+
+// AnimationsFromAseprite creates animations from the tags of an Aseprite file.
+// If the file has no tags, AnimationsFromAseprite returns one animation of all the frames with an empty name.
+//
+// The directions of the tags are converted into the orders of the frames and the loop modes.
+// A tag repeated once has LoopModeOnce, and a tag repeated other times is repeated infinitely.
+//
+// If pivotSlice is not empty, the pivot of the slice with the name is used as the pivots of the frames.
+func AnimationsFromAseprite(file *aseprite.File, pivotSlice string) []*Animation {
+	frames := make([]*Frame, len(file.Frames))
+	for i, f := range file.Frames {
+		frames[i] = &Frame{
+			Image:    f.Image,
+			Duration: f.Duration,
+		}
+		if pivotSlice == "" {
+			continue
+		}
+		if s := file.Slice(pivotSlice); s != nil {
+			if k, ok := s.KeyAt(i); ok && k.HasPivot {
+				frames[i].PivotX = float64(k.Bounds.Min.X + k.Pivot.X)
+				frames[i].PivotY = float64(k.Bounds.Min.Y + k.Pivot.Y)
+			}
+		}
+	}
+
+	if len(file.Tags) == 0 {
+		return []*Animation{
+			{
+				Frames: frames,
+			},
+		}
+	}
+
+	var animations []*Animation
+	for _, t := range file.Tags {
+		a := &Animation{
+			Name: t.Name,
+		}
+		if t.Repeat == 1 {
+			a.LoopMode = LoopModeOnce
+		}
+		switch t.Direction {
+		case aseprite.DirectionReverse, aseprite.DirectionPingPongReverse:
+			for i := t.To; i >= t.From; i-- {
+				a.Frames = append(a.Frames, frames[i])
+			}
+		default:
+			for i := t.From; i <= t.To; i++ {
+				a.Frames = append(a.Frames, frames[i])
+			}
+		}
+		if t.Direction == aseprite.DirectionPingPong || t.Direction == aseprite.DirectionPingPongReverse {
+			if a.LoopMode != LoopModeOnce {
+				a.LoopMode = LoopModePingPong
+			}
+		}
+		animations = append(animations, a)
+	}
+	return animations
+}
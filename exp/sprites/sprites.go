@@ -0,0 +1,314 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sprites provides frame-based sprite animations.
+// This package is experimental and the API might be changed in the future.
+//
+// An animation advances by Player.Update, which is expected to be called in every Update of the game.
+// The time of a tick is determined by ebiten.TPS.
+package sprites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// LoopMode represents how an animation is repeated.
+type LoopMode int
+
+const (
+	// LoopModeLoop repeats the animation from the first frame.
+	LoopModeLoop LoopMode = iota
+
+	// LoopModeOnce plays the animation once, and stops at the last frame.
+	LoopModeOnce
+
+	// LoopModePingPong plays the animation forward and backward repeatedly.
+	LoopModePingPong
+)
+
+// Frame is a frame of an animation.
+type Frame struct {
+	// Image is the image of the frame.
+	Image *ebiten.Image
+
+	// Duration is the duration of the frame.
+	// A non-positive duration is treated as one tick.
+	Duration time.Duration
+
+	// PivotX and PivotY are the pivot point in pixels relative to the upper-left corner of Image.
+	// The pivot is placed at the origin of DrawOptions.GeoM, and is the center of flipping.
+	PivotX float64
+	PivotY float64
+}
+
+// Animation is a named sequence of frames.
+type Animation struct {
+	// Name is the name of the animation.
+	Name string
+
+	// Frames is the frames of the animation.
+	Frames []*Frame
+
+	// LoopMode is how the animation is repeated.
+	LoopMode LoopMode
+}
+
+// Player plays animations.
+type Player struct {
+	animations map[string]*Animation
+	current    *Animation
+	frame      int
+	elapsed    time.Duration
+	backward   bool
+	finished   bool
+	speed      float64
+
+	frameHandlers  map[frameKey][]func()
+	finishHandlers []func(animation string)
+}
+
+type frameKey struct {
+	animation string
+	frame     int
+}
+
+// NewPlayer creates a new Player with the animations.
+// The first animation is played initially.
+//
+// NewPlayer panics if an animation has no frames or if names are duplicated.
+func NewPlayer(animations ...*Animation) *Player {
+	p := &Player{
+		animations:    map[string]*Animation{},
+		speed:         1,
+		frameHandlers: map[frameKey][]func(){},
+	}
+	for _, a := range animations {
+		if len(a.Frames) == 0 {
+			panic(fmt.Sprintf("sprites: the animation %q has no frames", a.Name))
+		}
+		if _, ok := p.animations[a.Name]; ok {
+			panic(fmt.Sprintf("sprites: the animation %q is duplicated", a.Name))
+		}
+		p.animations[a.Name] = a
+	}
+	if len(animations) > 0 {
+		p.current = animations[0]
+	}
+	return p
+}
+
+// Animation returns the animation with the name, or nil if there is no such animation.
+func (p *Player) Animation(name string) *Animation {
+	return p.animations[name]
+}
+
+// Play starts playing the animation with the name from the first frame.
+// If the animation is already being played, Play does nothing. Use Restart to play it from the first frame again.
+//
+// Play panics if there is no such animation.
+func (p *Player) Play(name string) {
+	if p.current != nil && p.current.Name == name {
+		return
+	}
+	a, ok := p.animations[name]
+	if !ok {
+		panic(fmt.Sprintf("sprites: the animation %q is not found", name))
+	}
+	p.current = a
+	p.Restart()
+}
+
+// Restart plays the current animation from the first frame.
+func (p *Player) Restart() {
+	p.frame = 0
+	p.elapsed = 0
+	p.backward = false
+	p.finished = false
+	p.enterFrame()
+}
+
+// SetSpeed sets the speed multiplier of the playback. The default speed is 1.
+func (p *Player) SetSpeed(speed float64) {
+	p.speed = speed
+}
+
+// Current returns the current animation.
+func (p *Player) Current() *Animation {
+	return p.current
+}
+
+// FrameIndex returns the index of the current frame in the current animation.
+func (p *Player) FrameIndex() int {
+	return p.frame
+}
+
+// Frame returns the current frame.
+func (p *Player) Frame() *Frame {
+	if p.current == nil {
+		return nil
+	}
+	return p.current.Frames[p.frame]
+}
+
+// Finished reports whether the current animation with LoopModeOnce reaches the end.
+func (p *Player) Finished() bool {
+	return p.finished
+}
+
+// OnFrame registers a function called when the frame of the animation starts.
+func (p *Player) OnFrame(animation string, frame int, f func()) {
+	k := frameKey{animation: animation, frame: frame}
+	p.frameHandlers[k] = append(p.frameHandlers[k], f)
+}
+
+// OnFinish registers a function called when an animation with LoopModeOnce finishes.
+func (p *Player) OnFinish(f func(animation string)) {
+	p.finishHandlers = append(p.finishHandlers, f)
+}
+
+// Update advances the current animation by one tick.
+func (p *Player) Update() {
+	p.Advance(tick())
+}
+
+// tick returns the duration of one tick.
+func tick() time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	return time.Second / time.Duration(tps)
+}
+
+// Advance advances the current animation by d multiplied by the speed.
+func (p *Player) Advance(d time.Duration) {
+	if p.current == nil || p.finished {
+		return
+	}
+	p.elapsed += time.Duration(float64(d) * p.speed)
+	for !p.finished {
+		dur := p.current.Frames[p.frame].Duration
+		if dur <= 0 {
+			dur = tick()
+		}
+		if p.elapsed < dur {
+			break
+		}
+		p.elapsed -= dur
+		p.next()
+	}
+}
+
+func (p *Player) next() {
+	n := len(p.current.Frames)
+	switch p.current.LoopMode {
+	case LoopModeOnce:
+		if p.frame == n-1 {
+			p.finished = true
+			p.elapsed = 0
+			for _, f := range p.finishHandlers {
+				f(p.current.Name)
+			}
+			return
+		}
+		p.frame++
+	case LoopModePingPong:
+		if n == 1 {
+			break
+		}
+		if p.backward {
+			p.frame--
+			if p.frame == 0 {
+				p.backward = false
+			}
+		} else {
+			p.frame++
+			if p.frame == n-1 {
+				p.backward = true
+			}
+		}
+	default:
+		p.frame = (p.frame + 1) % n
+	}
+	p.enterFrame()
+}
+
+func (p *Player) enterFrame() {
+	if p.current == nil {
+		return
+	}
+	for _, f := range p.frameHandlers[frameKey{animation: p.current.Name, frame: p.frame}] {
+		f()
+	}
+}
+
+// DrawOptions represents options for Player.Draw.
+type DrawOptions struct {
+	// GeoM is a geometry matrix applied after the pivot and the flips.
+	//
+	// The default (zero) value is identity.
+	GeoM ebiten.GeoM
+
+	// ColorScale is a scale of colors.
+	//
+	// The default (zero) value is identity, which is (1, 1, 1, 1).
+	ColorScale ebiten.ColorScale
+
+	// Blend is a blending way of the source color and the destination color.
+	//
+	// The default (zero) value is the regular alpha blending.
+	Blend ebiten.Blend
+
+	// Filter is a type of texture filter.
+	//
+	// The default (zero) value is ebiten.FilterNearest.
+	Filter ebiten.Filter
+
+	// FlipX and FlipY report whether the sprite is flipped horizontally and vertically around the pivot.
+	//
+	// The default (zero) values are false.
+	FlipX bool
+	FlipY bool
+}
+
+// Draw draws the current frame onto dst.
+// The pivot of the frame is placed at the origin of options.GeoM.
+func (p *Player) Draw(dst *ebiten.Image, options *DrawOptions) {
+	f := p.Frame()
+	if f == nil || f.Image == nil {
+		return
+	}
+	if options == nil {
+		options = &DrawOptions{}
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-f.PivotX, -f.PivotY)
+	sx, sy := 1.0, 1.0
+	if options.FlipX {
+		sx = -1
+	}
+	if options.FlipY {
+		sy = -1
+	}
+	op.GeoM.Scale(sx, sy)
+	op.GeoM.Concat(options.GeoM)
+	op.ColorScale = options.ColorScale
+	op.Blend = options.Blend
+	op.Filter = options.Filter
+	dst.DrawImage(f.Image, op)
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprites_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/exp/sprites"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+func newAnimation(name string, n int, mode sprites.LoopMode) *sprites.Animation {
+	a := &sprites.Animation{
+		Name:     name,
+		LoopMode: mode,
+	}
+	for i := 0; i < n; i++ {
+		a.Frames = append(a.Frames, &sprites.Frame{Duration: 100 * time.Millisecond})
+	}
+	return a
+}
+
+func frameIndices(p *sprites.Player, count int) []int {
+	var indices []int
+	for i := 0; i < count; i++ {
+		indices = append(indices, p.FrameIndex())
+		p.Advance(100 * time.Millisecond)
+	}
+	return indices
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLoopModes(t *testing.T) {
+	for _, tc := range []struct {
+		mode sprites.LoopMode
+		want []int
+	}{
+		{sprites.LoopModeLoop, []int{0, 1, 2, 0, 1, 2, 0}},
+		{sprites.LoopModeOnce, []int{0, 1, 2, 2, 2, 2, 2}},
+		{sprites.LoopModePingPong, []int{0, 1, 2, 1, 0, 1, 2}},
+	} {
+		p := sprites.NewPlayer(newAnimation("a", 3, tc.mode))
+		if got := frameIndices(p, len(tc.want)); !equalInts(got, tc.want) {
+			t.Errorf("mode %d: got: %v, want: %v", tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	p := sprites.NewPlayer(newAnimation("a", 3, sprites.LoopModeLoop))
+	p.Advance(99 * time.Millisecond)
+	if got, want := p.FrameIndex(), 0; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+	p.Advance(1 * time.Millisecond)
+	if got, want := p.FrameIndex(), 1; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+	// A long step skips frames.
+	p.Advance(200 * time.Millisecond)
+	if got, want := p.FrameIndex(), 0; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+
+	p.SetSpeed(2)
+	p.Advance(50 * time.Millisecond)
+	if got, want := p.FrameIndex(), 1; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	p := sprites.NewPlayer(newAnimation("idle", 2, sprites.LoopModeLoop), newAnimation("attack", 3, sprites.LoopModeOnce))
+
+	var hits int
+	p.OnFrame("attack", 1, func() {
+		hits++
+	})
+	var finished []string
+	p.OnFinish(func(animation string) {
+		finished = append(finished, animation)
+	})
+
+	p.Play("attack")
+	for i := 0; i < 10; i++ {
+		p.Advance(100 * time.Millisecond)
+	}
+	if got, want := hits, 1; got != want {
+		t.Errorf("hits: got: %d, want: %d", got, want)
+	}
+	if len(finished) != 1 || finished[0] != "attack" {
+		t.Errorf("finished: got: %v, want: [attack]", finished)
+	}
+	if !p.Finished() {
+		t.Errorf("Finished must return true")
+	}
+
+	// Playing the current animation does nothing, but Restart does.
+	p.Play("attack")
+	if !p.Finished() {
+		t.Errorf("Finished must return true")
+	}
+	p.Restart()
+	if p.Finished() {
+		t.Errorf("Finished must return false")
+	}
+}
+
+func TestStateMachine(t *testing.T) {
+	p := sprites.NewPlayer(
+		newAnimation("idle", 2, sprites.LoopModeLoop),
+		newAnimation("run", 2, sprites.LoopModeLoop),
+		newAnimation("jump", 1, sprites.LoopModeOnce),
+	)
+	var running, jumping bool
+	s := sprites.NewStateMachine(p, "idle")
+	s.AddTransition("", "jump", func() bool { return jumping })
+	s.AddTransition("idle", "run", func() bool { return running })
+	s.AddTransition("run", "idle", func() bool { return !running })
+	s.AddTransitionOnFinish("jump", "idle")
+
+	s.Update()
+	if got, want := s.State(), "idle"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	running = true
+	s.Update()
+	if got, want := s.State(), "run"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	jumping = true
+	s.Update()
+	if got, want := s.State(), "jump"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	jumping = false
+	running = false
+	// The jump animation finishes after its duration.
+	for i := 0; i < 10; i++ {
+		s.Update()
+	}
+	if got, want := s.State(), "idle"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
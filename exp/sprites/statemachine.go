@@ -0,0 +1,89 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprites
+
+// StateMachine switches animations of a player by conditions.
+// A state is identified by the name of an animation.
+type StateMachine struct {
+	player      *Player
+	transitions []transition
+}
+
+type transition struct {
+	from      string
+	to        string
+	condition func() bool
+	onFinish  bool
+}
+
+// NewStateMachine creates a new StateMachine for the player, and plays the initial animation.
+func NewStateMachine(player *Player, initial string) *StateMachine {
+	player.Play(initial)
+	return &StateMachine{
+		player: player,
+	}
+}
+
+// AddTransition adds a transition from the state from to the state to when condition returns true.
+// If from is an empty string, the transition is applied to any states except to.
+//
+// Transitions are evaluated in the order of the additions, and the first satisfied one is applied.
+func (s *StateMachine) AddTransition(from, to string, condition func() bool) {
+	s.transitions = append(s.transitions, transition{
+		from:      from,
+		to:        to,
+		condition: condition,
+	})
+}
+
+// AddTransitionOnFinish adds a transition from the state from to the state to when the animation of from finishes.
+// The animation of from should have LoopModeOnce.
+func (s *StateMachine) AddTransitionOnFinish(from, to string) {
+	s.transitions = append(s.transitions, transition{
+		from:     from,
+		to:       to,
+		onFinish: true,
+	})
+}
+
+// State returns the current state.
+func (s *StateMachine) State() string {
+	if s.player.current == nil {
+		return ""
+	}
+	return s.player.current.Name
+}
+
+// Update applies a satisfied transition if any, and then advances the player by one tick.
+func (s *StateMachine) Update() {
+	state := s.State()
+	for _, t := range s.transitions {
+		if t.from != "" && t.from != state {
+			continue
+		}
+		if t.to == state {
+			continue
+		}
+		if t.onFinish && !s.player.Finished() {
+			continue
+		}
+		if t.condition != nil && !t.condition() {
+			continue
+		}
+		s.player.Play(t.to)
+		break
+	}
+	s.player.Update()
+}
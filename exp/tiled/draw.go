@@ -0,0 +1,332 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiled
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DrawOptions represents options for Map.Draw and Map.DrawLayer.
+type DrawOptions struct {
+	// GeoM is a geometry matrix to transform the map coordinates into the destination coordinates.
+	//
+	// The default (zero) value is identity.
+	GeoM ebiten.GeoM
+
+	// ColorScale is a scale of colors.
+	//
+	// The default (zero) value is identity, which is (1, 1, 1, 1).
+	ColorScale ebiten.ColorScale
+
+	// Blend is a blending way of the source color and the destination color.
+	//
+	// The default (zero) value is the regular alpha blending.
+	Blend ebiten.Blend
+
+	// Filter is a type of texture filter.
+	//
+	// The default (zero) value is ebiten.FilterNearest.
+	Filter ebiten.Filter
+
+	// Time is the elapsed time to select the frames of animated tiles.
+	//
+	// The default (zero) value is 0, which selects the first frames.
+	Time time.Duration
+}
+
+// Draw draws all the visible layers of the map onto dst.
+//
+// Tiles of the same tileset image in a layer are rendered with one DrawTriangles call.
+// Tiles out of dst are skipped.
+// Object layers draw only tile objects. The parallax factors and the repeat modes of the layers are not applied.
+//
+// Draw supports only orthogonal maps, and panics for other orientations.
+// Draw must not be called concurrently for the same map.
+func (m *Map) Draw(dst *ebiten.Image, options *DrawOptions) {
+	if options == nil {
+		options = &DrawOptions{}
+	}
+	for _, l := range m.Layers {
+		if !l.Visible {
+			continue
+		}
+		m.drawLayer(dst, l, options, options.GeoM, options.ColorScale)
+	}
+}
+
+// DrawLayer draws the layer of the map onto dst, even if the layer is invisible.
+// The child layers of a group layer are drawn only when they are visible.
+//
+// DrawLayer has the same limitations as Draw.
+func (m *Map) DrawLayer(dst *ebiten.Image, layer *Layer, options *DrawOptions) {
+	if options == nil {
+		options = &DrawOptions{}
+	}
+	m.drawLayer(dst, layer, options, options.GeoM, options.ColorScale)
+}
+
+func (m *Map) drawLayer(dst *ebiten.Image, layer *Layer, options *DrawOptions, geoM ebiten.GeoM, colorScale ebiten.ColorScale) {
+	if m.Orientation != "" && m.Orientation != "orthogonal" {
+		panic(fmt.Sprintf("tiled: unsupported orientation: %s", m.Orientation))
+	}
+
+	var g ebiten.GeoM
+	g.Translate(layer.OffsetX, layer.OffsetY)
+	g.Concat(geoM)
+	colorScale.ScaleAlpha(float32(layer.Opacity))
+	if layer.TintColor != nil {
+		colorScale.ScaleWithColor(layer.TintColor)
+	}
+
+	switch layer.Kind {
+	case LayerKindTile:
+		m.drawTileLayer(dst, layer, options, g, colorScale)
+	case LayerKindObject:
+		m.drawObjectLayer(dst, layer, options, g, colorScale)
+	case LayerKindImage:
+		if layer.Image == nil {
+			return
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM = g
+		op.ColorScale = colorScale
+		op.Blend = options.Blend
+		op.Filter = options.Filter
+		dst.DrawImage(layer.Image, op)
+	case LayerKindGroup:
+		for _, l := range layer.Layers {
+			if !l.Visible {
+				continue
+			}
+			m.drawLayer(dst, l, options, g, colorScale)
+		}
+	}
+}
+
+func (m *Map) drawTileLayer(dst *ebiten.Image, layer *Layer, options *DrawOptions, geoM ebiten.GeoM, colorScale ebiten.ColorScale) {
+	x0, y0, x1, y1 := 0, 0, layer.Width, layer.Height
+	if inv := geoM; inv.IsInvertible() && m.TileWidth > 0 && m.TileHeight > 0 {
+		// Calculate the range of the visible tiles.
+		inv.Invert()
+		b := dst.Bounds()
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, p := range [...]image.Point{b.Min, {b.Max.X, b.Min.Y}, {b.Min.X, b.Max.Y}, b.Max} {
+			x, y := inv.Apply(float64(p.X), float64(p.Y))
+			minX, minY = math.Min(minX, x), math.Min(minY, y)
+			maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+		}
+		// Tiles of tilesets can be bigger than the grid and have offsets.
+		margin := m.tileMargin()
+		tw, th := float64(m.TileWidth), float64(m.TileHeight)
+		x0 = maxInt(x0, int(math.Floor((minX-margin)/tw)))
+		y0 = maxInt(y0, int(math.Floor((minY-margin)/th)))
+		x1 = minInt(x1, int(math.Ceil((maxX+margin)/tw)))
+		y1 = minInt(y1, int(math.Ceil((maxY+margin)/th)))
+	}
+
+	var d drawer
+	d.init(m, dst, options, colorScale)
+	for j := y0; j < y1; j++ {
+		y := j
+		if m.RenderOrder == "right-up" || m.RenderOrder == "left-up" {
+			y = y0 + y1 - 1 - j
+		}
+		for i := x0; i < x1; i++ {
+			x := i
+			if m.RenderOrder == "left-down" || m.RenderOrder == "left-up" {
+				x = x0 + x1 - 1 - i
+			}
+			gid := layer.Tiles[y*layer.Width+x]
+			ts, id := m.TilesetForGID(gid)
+			if ts == nil {
+				continue
+			}
+			img, src := tileImage(ts, id, options.Time)
+			if img == nil {
+				continue
+			}
+			// Tiles are aligned to the bottom-left corners of the grid cells.
+			var g ebiten.GeoM
+			g.Translate(float64(x*m.TileWidth+ts.TileOffsetX), float64((y+1)*m.TileHeight-src.Dy()+ts.TileOffsetY))
+			g.Concat(geoM)
+			d.drawTile(img, src, gid, g)
+		}
+	}
+	d.flush()
+}
+
+func (m *Map) drawObjectLayer(dst *ebiten.Image, layer *Layer, options *DrawOptions, geoM ebiten.GeoM, colorScale ebiten.ColorScale) {
+	var d drawer
+	d.init(m, dst, options, colorScale)
+	for _, o := range layer.Objects {
+		if !o.Visible {
+			continue
+		}
+		ts, id := m.TilesetForGID(o.GID)
+		if ts == nil {
+			continue
+		}
+		img, src := tileImage(ts, id, options.Time)
+		if img == nil {
+			continue
+		}
+		// A tile object is placed at its bottom-left corner, and rotated around it.
+		var g ebiten.GeoM
+		if o.Width > 0 && o.Height > 0 {
+			g.Scale(o.Width/float64(src.Dx()), o.Height/float64(src.Dy()))
+		}
+		h := o.Height
+		if h <= 0 {
+			h = float64(src.Dy())
+		}
+		g.Translate(float64(ts.TileOffsetX), -h+float64(ts.TileOffsetY))
+		g.Rotate(o.Rotation * math.Pi / 180)
+		g.Translate(o.X, o.Y)
+		g.Concat(geoM)
+		d.drawTile(img, src, o.GID, g)
+	}
+	d.flush()
+}
+
+func (m *Map) tileMargin() float64 {
+	var margin int
+	for _, ts := range m.Tilesets {
+		w, h := ts.TileWidth, ts.TileHeight
+		for _, t := range ts.Tiles {
+			if t.Image != nil {
+				w = maxInt(w, t.Image.Bounds().Dx())
+				h = maxInt(h, t.Image.Bounds().Dy())
+			}
+		}
+		margin = maxInt(margin, w+absInt(ts.TileOffsetX))
+		margin = maxInt(margin, h+absInt(ts.TileOffsetY))
+	}
+	return float64(margin)
+}
+
+// tileImage returns the image and the source region to draw the tile of the local ID at the time t.
+func tileImage(ts *Tileset, id int, t time.Duration) (*ebiten.Image, image.Rectangle) {
+	if tile := ts.tiles[id]; tile != nil && len(tile.Animation) > 0 {
+		id = tile.FrameAt(t)
+	}
+	if ts.Image != nil {
+		return ts.Image, ts.TileBounds(id)
+	}
+	if tile := ts.tiles[id]; tile != nil && tile.Image != nil {
+		return tile.Image, tile.Image.Bounds()
+	}
+	return nil, image.Rectangle{}
+}
+
+// drawer batches tiles of the same image into one DrawTriangles call.
+type drawer struct {
+	m          *Map
+	dst        *ebiten.Image
+	options    *DrawOptions
+	colorScale ebiten.ColorScale
+	img        *ebiten.Image
+}
+
+func (d *drawer) init(m *Map, dst *ebiten.Image, options *DrawOptions, colorScale ebiten.ColorScale) {
+	d.m = m
+	d.dst = dst
+	d.options = options
+	d.colorScale = colorScale
+}
+
+func (d *drawer) drawTile(img *ebiten.Image, src image.Rectangle, gid GID, geoM ebiten.GeoM) {
+	if d.img != img || len(d.m.vertices)+4 > math.MaxUint16+1 {
+		d.flush()
+		d.img = img
+	}
+
+	// The source corners in the order of the upper-left, the upper-right, the lower-left and the lower-right.
+	s := [4][2]float32{
+		{float32(src.Min.X), float32(src.Min.Y)},
+		{float32(src.Max.X), float32(src.Min.Y)},
+		{float32(src.Min.X), float32(src.Max.Y)},
+		{float32(src.Max.X), float32(src.Max.Y)},
+	}
+	// The diagonal flip is applied first, then the horizontal and the vertical flips are applied.
+	if gid.FlippedDiagonally() {
+		s[1], s[2] = s[2], s[1]
+	}
+	if gid.FlippedHorizontally() {
+		s[0], s[1] = s[1], s[0]
+		s[2], s[3] = s[3], s[2]
+	}
+	if gid.FlippedVertically() {
+		s[0], s[2] = s[2], s[0]
+		s[1], s[3] = s[3], s[1]
+	}
+
+	w, h := float64(src.Dx()), float64(src.Dy())
+	r, g, b, a := d.colorScale.R(), d.colorScale.G(), d.colorScale.B(), d.colorScale.A()
+	base := uint16(len(d.m.vertices))
+	for i, p := range [...][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		x, y := geoM.Apply(p[0], p[1])
+		d.m.vertices = append(d.m.vertices, ebiten.Vertex{
+			DstX:   float32(x),
+			DstY:   float32(y),
+			SrcX:   s[i][0],
+			SrcY:   s[i][1],
+			ColorR: r,
+			ColorG: g,
+			ColorB: b,
+			ColorA: a,
+		})
+	}
+	d.m.indices = append(d.m.indices, base, base+1, base+2, base+1, base+3, base+2)
+}
+
+func (d *drawer) flush() {
+	if len(d.m.indices) > 0 {
+		op := &ebiten.DrawTrianglesOptions{}
+		op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+		op.Blend = d.options.Blend
+		op.Filter = d.options.Filter
+		d.dst.DrawTriangles(d.m.vertices, d.m.indices, d.img, op)
+	}
+	d.m.vertices = d.m.vertices[:0]
+	d.m.indices = d.m.indices[:0]
+	d.img = nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
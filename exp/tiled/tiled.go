@@ -0,0 +1,1073 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tiled provides a loader and a renderer of Tiled (https://www.mapeditor.org/) maps in the TMX and TSX formats.
+// This package is experimental and the API might be changed in the future.
+//
+// Image decoders must be imported when loading maps. For example,
+// if the tilesets use PNG images, you'd need to add `_ "image/png"` to the import section.
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// GID is a global tile ID with flip flags.
+// 0 means an empty tile.
+type GID uint32
+
+const (
+	gidFlippedHorizontally GID = 0x80000000
+	gidFlippedVertically   GID = 0x40000000
+	gidFlippedDiagonally   GID = 0x20000000
+	gidRotatedHexagonal120 GID = 0x10000000
+
+	gidFlags = gidFlippedHorizontally | gidFlippedVertically | gidFlippedDiagonally | gidRotatedHexagonal120
+)
+
+// ID returns the global tile ID without the flip flags.
+func (g GID) ID() uint32 {
+	return uint32(g &^ gidFlags)
+}
+
+// FlippedHorizontally reports whether the tile is flipped horizontally.
+func (g GID) FlippedHorizontally() bool {
+	return g&gidFlippedHorizontally != 0
+}
+
+// FlippedVertically reports whether the tile is flipped vertically.
+func (g GID) FlippedVertically() bool {
+	return g&gidFlippedVertically != 0
+}
+
+// FlippedDiagonally reports whether the tile is flipped diagonally, i.e. the X and Y axes are swapped.
+func (g GID) FlippedDiagonally() bool {
+	return g&gidFlippedDiagonally != 0
+}
+
+// Map is a Tiled map.
+type Map struct {
+	// Orientation is the orientation of the map: "orthogonal", "isometric", "staggered" or "hexagonal".
+	// Only "orthogonal" is supported for drawing.
+	Orientation string
+
+	// RenderOrder is the order of rendering tiles: "right-down", "right-up", "left-down" or "left-up".
+	RenderOrder string
+
+	// Width and Height are the size of the map in tiles.
+	Width  int
+	Height int
+
+	// TileWidth and TileHeight are the size of a grid cell in pixels.
+	TileWidth  int
+	TileHeight int
+
+	// BackgroundColor is the background color of the map, or nil if it is not specified.
+	BackgroundColor color.Color
+
+	// Properties is the custom properties of the map.
+	Properties Properties
+
+	// Tilesets is the tilesets of the map in the order of their first GIDs.
+	Tilesets []*Tileset
+
+	// Layers is the top-level layers in the drawing order.
+	Layers []*Layer
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// TilesetForGID returns the tileset including the tile of gid, and the local tile ID in the tileset.
+// TilesetForGID returns nil if gid is empty or doesn't belong to any tileset.
+func (m *Map) TilesetForGID(gid GID) (*Tileset, int) {
+	id := gid.ID()
+	if id == 0 {
+		return nil, 0
+	}
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		ts := m.Tilesets[i]
+		if ts.FirstGID <= id {
+			return ts, int(id - ts.FirstGID)
+		}
+	}
+	return nil, 0
+}
+
+// LayerByName returns the first layer with the name, including layers in groups.
+// LayerByName returns nil if there is no such layer.
+func (m *Map) LayerByName(name string) *Layer {
+	return layerByName(m.Layers, name)
+}
+
+func layerByName(layers []*Layer, name string) *Layer {
+	for _, l := range layers {
+		if l.Name == name {
+			return l
+		}
+		if l := layerByName(l.Layers, name); l != nil {
+			return l
+		}
+	}
+	return nil
+}
+
+// Tileset is a Tiled tileset.
+type Tileset struct {
+	// FirstGID is the first global tile ID of the tileset in the map.
+	FirstGID uint32
+
+	// Source is the path of the external TSX file, or an empty string if the tileset is embedded in the map.
+	Source string
+
+	Name       string
+	TileWidth  int
+	TileHeight int
+	Spacing    int
+	Margin     int
+	TileCount  int
+	Columns    int
+
+	// TileOffsetX and TileOffsetY are the offset in pixels to draw the tiles.
+	TileOffsetX int
+	TileOffsetY int
+
+	// ImageSource is the path of the tileset image relative to the file system root,
+	// or an empty string if the tileset is a collection of images.
+	ImageSource string
+
+	// Image is the loaded tileset image, or nil if the tileset is a collection of images.
+	Image *ebiten.Image
+
+	// Properties is the custom properties of the tileset.
+	Properties Properties
+
+	// Tiles is the tiles with additional data like properties, animations and individual images.
+	Tiles []*TilesetTile
+
+	tiles map[int]*TilesetTile
+}
+
+// Tile returns the tile data for the local tile ID, or nil if the tile has no additional data.
+func (t *Tileset) Tile(id int) *TilesetTile {
+	return t.tiles[id]
+}
+
+// TileBounds returns the region of the tile for the local tile ID in the tileset image.
+// For a collection of images, TileBounds returns the bounds of the tile's own image.
+func (t *Tileset) TileBounds(id int) image.Rectangle {
+	if t.Image == nil {
+		if tile := t.tiles[id]; tile != nil && tile.Image != nil {
+			return tile.Image.Bounds()
+		}
+		return image.Rectangle{}
+	}
+	columns := t.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	x := t.Margin + (id%columns)*(t.TileWidth+t.Spacing)
+	y := t.Margin + (id/columns)*(t.TileHeight+t.Spacing)
+	return image.Rect(x, y, x+t.TileWidth, y+t.TileHeight)
+}
+
+// TilesetTile is a tile with additional data in a tileset.
+type TilesetTile struct {
+	// ID is the local tile ID.
+	ID int
+
+	// Class is the class of the tile.
+	Class string
+
+	// Properties is the custom properties of the tile.
+	Properties Properties
+
+	// Animation is the frames of the tile's animation, or nil if the tile is not animated.
+	Animation []Frame
+
+	// ImageSource is the path of the tile's own image relative to the file system root, for a collection of images.
+	ImageSource string
+
+	// Image is the tile's own image for a collection of images.
+	Image *ebiten.Image
+
+	// ObjectGroup is the collision shapes of the tile, or nil.
+	ObjectGroup *Layer
+}
+
+// FrameAt returns the local tile ID to draw at the given time for an animated tile.
+// If the tile is not animated, FrameAt returns the tile's ID.
+func (t *TilesetTile) FrameAt(at time.Duration) int {
+	var total time.Duration
+	for _, f := range t.Animation {
+		total += f.Duration
+	}
+	if total <= 0 {
+		return t.ID
+	}
+	at %= total
+	if at < 0 {
+		at += total
+	}
+	for _, f := range t.Animation {
+		if at < f.Duration {
+			return f.TileID
+		}
+		at -= f.Duration
+	}
+	return t.Animation[len(t.Animation)-1].TileID
+}
+
+// Frame is a frame of a tile animation.
+type Frame struct {
+	// TileID is the local tile ID in the same tileset.
+	TileID int
+
+	// Duration is the duration of the frame.
+	Duration time.Duration
+}
+
+// LayerKind represents a kind of a layer.
+type LayerKind int
+
+const (
+	// LayerKindTile is a tile layer.
+	LayerKindTile LayerKind = iota
+
+	// LayerKindObject is an object layer (object group).
+	LayerKindObject
+
+	// LayerKindImage is an image layer.
+	LayerKindImage
+
+	// LayerKindGroup is a group layer.
+	LayerKindGroup
+)
+
+// Layer is a layer of a map.
+// Which fields are used depends on Kind.
+type Layer struct {
+	Kind LayerKind
+
+	ID      int
+	Name    string
+	Class   string
+	Visible bool
+	Opacity float64
+
+	// OffsetX and OffsetY are the offset of the layer in pixels.
+	OffsetX float64
+	OffsetY float64
+
+	// ParallaxX and ParallaxY are the parallax factors of the layer.
+	ParallaxX float64
+	ParallaxY float64
+
+	// TintColor is the color multiplied with the layer, or nil if it is not specified.
+	TintColor color.Color
+
+	// Properties is the custom properties of the layer.
+	Properties Properties
+
+	// Width and Height are the size of a tile layer in tiles.
+	Width  int
+	Height int
+
+	// Tiles is the tiles of a tile layer in the row-major order. The length is Width*Height.
+	Tiles []GID
+
+	// Objects is the objects of an object layer.
+	Objects []*Object
+
+	// ImageSource is the path of the image of an image layer relative to the file system root.
+	ImageSource string
+
+	// Image is the loaded image of an image layer.
+	Image *ebiten.Image
+
+	// RepeatX and RepeatY report whether the image of an image layer is repeated.
+	RepeatX bool
+	RepeatY bool
+
+	// Layers is the child layers of a group layer.
+	Layers []*Layer
+}
+
+// TileAt returns the tile at (x, y) in tiles of a tile layer.
+// TileAt returns 0 if (x, y) is out of the layer.
+func (l *Layer) TileAt(x, y int) GID {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// Object is an object in an object layer.
+type Object struct {
+	ID       int
+	Name     string
+	Class    string
+	X        float64
+	Y        float64
+	Width    float64
+	Height   float64
+	Rotation float64
+	Visible  bool
+
+	// GID is the tile of a tile object, or 0 otherwise.
+	GID GID
+
+	// Ellipse reports whether the object is an ellipse.
+	Ellipse bool
+
+	// Point reports whether the object is a point.
+	Point bool
+
+	// Polygon is the points of a polygon object relative to (X, Y), or nil.
+	Polygon []Point
+
+	// Polyline is the points of a polyline object relative to (X, Y), or nil.
+	Polyline []Point
+
+	// Text is the text of a text object.
+	Text string
+
+	// Template is the path of the object template file, if any. Templates are not applied.
+	Template string
+
+	// Properties is the custom properties of the object.
+	Properties Properties
+}
+
+// Point is a point of a polygon or a polyline.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Property is a custom property.
+type Property struct {
+	Name string
+
+	// Type is the type of the property: "string", "int", "float", "bool", "color", "file", "object" or "class".
+	Type string
+
+	// Value is the raw value of the property.
+	Value string
+
+	// Properties is the members of a class property.
+	Properties Properties
+}
+
+// Properties is a list of custom properties.
+type Properties []Property
+
+// Get returns the property with the name.
+func (p Properties) Get(name string) (Property, bool) {
+	for _, prop := range p {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return Property{}, false
+}
+
+// String returns the value of the property with the name.
+func (p Properties) String(name string) (string, bool) {
+	prop, ok := p.Get(name)
+	if !ok {
+		return "", false
+	}
+	return prop.Value, true
+}
+
+// Int returns the value of the property with the name as an integer.
+// Int returns false if the property doesn't exist or is not an integer.
+func (p Properties) Int(name string) (int, bool) {
+	prop, ok := p.Get(name)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.Atoi(prop.Value)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Float returns the value of the property with the name as a floating point number.
+// Float returns false if the property doesn't exist or is not a number.
+func (p Properties) Float(name string) (float64, bool) {
+	prop, ok := p.Get(name)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(prop.Value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Bool returns the value of the property with the name as a boolean.
+// Bool returns false if the property doesn't exist or is not a boolean.
+func (p Properties) Bool(name string) (bool, bool) {
+	prop, ok := p.Get(name)
+	if !ok {
+		return false, false
+	}
+	v, err := strconv.ParseBool(prop.Value)
+	if err != nil {
+		return false, false
+	}
+	return v, true
+}
+
+// Color returns the value of the property with the name as a color.
+// Color returns false if the property doesn't exist or is not a color.
+func (p Properties) Color(name string) (color.Color, bool) {
+	prop, ok := p.Get(name)
+	if !ok {
+		return nil, false
+	}
+	c, err := parseColor(prop.Value)
+	if err != nil || c == nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// Load loads a TMX map at the path in fsys, including its external tilesets and images.
+func Load(fsys fs.FS, name string) (*Map, error) {
+	m, err := parseMap(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.loadImages(fsys); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadTileset loads a TSX tileset at the path in fsys, including its images.
+// FirstGID of the returned tileset is 0.
+func LoadTileset(fsys fs.FS, name string) (*Tileset, error) {
+	ts, err := parseTilesetFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.loadImages(fsys); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+func parseMap(fsys fs.FS, name string) (*Map, error) {
+	bs, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var x xmlMap
+	if err := xml.Unmarshal(bs, &x); err != nil {
+		return nil, fmt.Errorf("tiled: parsing %s failed: %w", name, err)
+	}
+	if x.Infinite != 0 {
+		return nil, fmt.Errorf("tiled: infinite maps are not supported: %s", name)
+	}
+
+	dir := path.Dir(name)
+	bg, err := parseColor(x.BackgroundColor)
+	if err != nil {
+		return nil, fmt.Errorf("tiled: parsing %s failed: %w", name, err)
+	}
+	m := &Map{
+		Orientation:     x.Orientation,
+		RenderOrder:     x.RenderOrder,
+		Width:           x.Width,
+		Height:          x.Height,
+		TileWidth:       x.TileWidth,
+		TileHeight:      x.TileHeight,
+		BackgroundColor: bg,
+		Properties:      toProperties(x.Properties),
+	}
+	if m.RenderOrder == "" {
+		m.RenderOrder = "right-down"
+	}
+
+	for _, xts := range x.Tilesets {
+		var ts *Tileset
+		if xts.Source != "" {
+			src := path.Join(dir, xts.Source)
+			t, err := parseTilesetFile(fsys, src)
+			if err != nil {
+				return nil, err
+			}
+			ts = t
+			ts.Source = src
+		} else {
+			t, err := toTileset(&xts, dir)
+			if err != nil {
+				return nil, fmt.Errorf("tiled: parsing %s failed: %w", name, err)
+			}
+			ts = t
+		}
+		ts.FirstGID = xts.FirstGID
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	layers, err := toLayers(x.Layers, dir)
+	if err != nil {
+		return nil, fmt.Errorf("tiled: parsing %s failed: %w", name, err)
+	}
+	m.Layers = layers
+	return m, nil
+}
+
+func parseTilesetFile(fsys fs.FS, name string) (*Tileset, error) {
+	bs, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var x xmlTileset
+	if err := xml.Unmarshal(bs, &x); err != nil {
+		return nil, fmt.Errorf("tiled: parsing %s failed: %w", name, err)
+	}
+	ts, err := toTileset(&x, path.Dir(name))
+	if err != nil {
+		return nil, fmt.Errorf("tiled: parsing %s failed: %w", name, err)
+	}
+	return ts, nil
+}
+
+func (m *Map) loadImages(fsys fs.FS) error {
+	for _, ts := range m.Tilesets {
+		if err := ts.loadImages(fsys); err != nil {
+			return err
+		}
+	}
+	return loadLayerImages(fsys, m.Layers)
+}
+
+func (t *Tileset) loadImages(fsys fs.FS) error {
+	if t.ImageSource != "" {
+		img, err := loadImage(fsys, t.ImageSource)
+		if err != nil {
+			return err
+		}
+		t.Image = img
+	}
+	for _, tile := range t.Tiles {
+		if tile.ImageSource == "" {
+			continue
+		}
+		img, err := loadImage(fsys, tile.ImageSource)
+		if err != nil {
+			return err
+		}
+		tile.Image = img
+	}
+	return nil
+}
+
+func loadLayerImages(fsys fs.FS, layers []*Layer) error {
+	for _, l := range layers {
+		if l.ImageSource != "" {
+			img, err := loadImage(fsys, l.ImageSource)
+			if err != nil {
+				return err
+			}
+			l.Image = img
+		}
+		if err := loadLayerImages(fsys, l.Layers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadImage(fsys fs.FS, name string) (*ebiten.Image, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("tiled: decoding %s failed: %w", name, err)
+	}
+	return ebiten.NewImageFromImage(img), nil
+}
+
+type xmlMap struct {
+	Orientation     string        `xml:"orientation,attr"`
+	RenderOrder     string        `xml:"renderorder,attr"`
+	Width           int           `xml:"width,attr"`
+	Height          int           `xml:"height,attr"`
+	TileWidth       int           `xml:"tilewidth,attr"`
+	TileHeight      int           `xml:"tileheight,attr"`
+	Infinite        int           `xml:"infinite,attr"`
+	BackgroundColor string        `xml:"backgroundcolor,attr"`
+	Properties      []xmlProperty `xml:"properties>property"`
+	Tilesets        []xmlTileset  `xml:"tileset"`
+
+	// Layers keeps the order of the layers of different kinds.
+	Layers []xmlLayer `xml:",any"`
+}
+
+type xmlTileset struct {
+	FirstGID   uint32 `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Margin     int    `xml:"margin,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+	TileOffset struct {
+		X int `xml:"x,attr"`
+		Y int `xml:"y,attr"`
+	} `xml:"tileoffset"`
+	Image      *xmlImage     `xml:"image"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Tiles      []xmlTile     `xml:"tile"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type xmlTile struct {
+	ID          int           `xml:"id,attr"`
+	Type        string        `xml:"type,attr"`
+	Class       string        `xml:"class,attr"`
+	Properties  []xmlProperty `xml:"properties>property"`
+	Image       *xmlImage     `xml:"image"`
+	ObjectGroup *xmlLayer     `xml:"objectgroup"`
+	Animation   []struct {
+		TileID   int `xml:"tileid,attr"`
+		Duration int `xml:"duration,attr"`
+	} `xml:"animation>frame"`
+}
+
+type xmlLayer struct {
+	XMLName    xml.Name
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Opacity    string        `xml:"opacity,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	ParallaxX  string        `xml:"parallaxx,attr"`
+	ParallaxY  string        `xml:"parallaxy,attr"`
+	TintColor  string        `xml:"tintcolor,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	RepeatX    int           `xml:"repeatx,attr"`
+	RepeatY    int           `xml:"repeaty,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Data       *xmlData      `xml:"data"`
+	Objects    []xmlObject   `xml:"object"`
+	Image      *xmlImage     `xml:"image"`
+	Layers     []xmlLayer    `xml:",any"`
+}
+
+type xmlData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Tiles       []struct {
+		GID GID `xml:"gid,attr"`
+	} `xml:"tile"`
+	Chunks []struct{} `xml:"chunk"`
+	Text   string     `xml:",chardata"`
+}
+
+type xmlObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        GID           `xml:"gid,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Template   string        `xml:"template,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *xmlPoints    `xml:"polygon"`
+	Polyline   *xmlPoints    `xml:"polyline"`
+	Text       *struct {
+		Text string `xml:",chardata"`
+	} `xml:"text"`
+}
+
+type xmlPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type xmlProperty struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Value      *string       `xml:"value,attr"`
+	Text       string        `xml:",chardata"`
+	Properties []xmlProperty `xml:"properties>property"`
+}
+
+func toProperties(xs []xmlProperty) Properties {
+	if len(xs) == 0 {
+		return nil
+	}
+	ps := make(Properties, 0, len(xs))
+	for _, x := range xs {
+		p := Property{
+			Name:       x.Name,
+			Type:       x.Type,
+			Properties: toProperties(x.Properties),
+		}
+		if p.Type == "" {
+			p.Type = "string"
+		}
+		// A multi-line string is stored as the text instead of the attribute.
+		if x.Value != nil {
+			p.Value = *x.Value
+		} else {
+			p.Value = x.Text
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+func toTileset(x *xmlTileset, dir string) (*Tileset, error) {
+	ts := &Tileset{
+		Name:        x.Name,
+		TileWidth:   x.TileWidth,
+		TileHeight:  x.TileHeight,
+		Spacing:     x.Spacing,
+		Margin:      x.Margin,
+		TileCount:   x.TileCount,
+		Columns:     x.Columns,
+		TileOffsetX: x.TileOffset.X,
+		TileOffsetY: x.TileOffset.Y,
+		Properties:  toProperties(x.Properties),
+		tiles:       map[int]*TilesetTile{},
+	}
+	if x.Image != nil && x.Image.Source != "" {
+		ts.ImageSource = path.Join(dir, x.Image.Source)
+	}
+	for _, xt := range x.Tiles {
+		t := &TilesetTile{
+			ID:         xt.ID,
+			Class:      xt.Class,
+			Properties: toProperties(xt.Properties),
+		}
+		if t.Class == "" {
+			t.Class = xt.Type
+		}
+		for _, f := range xt.Animation {
+			t.Animation = append(t.Animation, Frame{
+				TileID:   f.TileID,
+				Duration: time.Duration(f.Duration) * time.Millisecond,
+			})
+		}
+		if xt.Image != nil && xt.Image.Source != "" {
+			t.ImageSource = path.Join(dir, xt.Image.Source)
+		}
+		if xt.ObjectGroup != nil {
+			l, err := toLayer(xt.ObjectGroup, LayerKindObject, dir)
+			if err != nil {
+				return nil, err
+			}
+			t.ObjectGroup = l
+		}
+		ts.Tiles = append(ts.Tiles, t)
+		ts.tiles[t.ID] = t
+	}
+	return ts, nil
+}
+
+func toLayers(xs []xmlLayer, dir string) ([]*Layer, error) {
+	var layers []*Layer
+	for i := range xs {
+		var kind LayerKind
+		switch xs[i].XMLName.Local {
+		case "layer":
+			kind = LayerKindTile
+		case "objectgroup":
+			kind = LayerKindObject
+		case "imagelayer":
+			kind = LayerKindImage
+		case "group":
+			kind = LayerKindGroup
+		default:
+			// Ignore other elements like editorsettings.
+			continue
+		}
+		l, err := toLayer(&xs[i], kind, dir)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+func toLayer(x *xmlLayer, kind LayerKind, dir string) (*Layer, error) {
+	tint, err := parseColor(x.TintColor)
+	if err != nil {
+		return nil, err
+	}
+	l := &Layer{
+		Kind:       kind,
+		ID:         x.ID,
+		Name:       x.Name,
+		Class:      x.Class,
+		Visible:    x.Visible != "0",
+		Opacity:    1,
+		OffsetX:    x.OffsetX,
+		OffsetY:    x.OffsetY,
+		ParallaxX:  1,
+		ParallaxY:  1,
+		TintColor:  tint,
+		Properties: toProperties(x.Properties),
+		Width:      x.Width,
+		Height:     x.Height,
+		RepeatX:    x.RepeatX != 0,
+		RepeatY:    x.RepeatY != 0,
+	}
+	for _, v := range []struct {
+		src string
+		dst *float64
+	}{
+		{x.Opacity, &l.Opacity},
+		{x.ParallaxX, &l.ParallaxX},
+		{x.ParallaxY, &l.ParallaxY},
+	} {
+		if v.src == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v.src, 64)
+		if err != nil {
+			return nil, err
+		}
+		*v.dst = f
+	}
+
+	switch kind {
+	case LayerKindTile:
+		if x.Data != nil {
+			tiles, err := decodeTiles(x.Data, x.Width*x.Height)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", x.Name, err)
+			}
+			l.Tiles = tiles
+		} else {
+			l.Tiles = make([]GID, x.Width*x.Height)
+		}
+	case LayerKindObject:
+		for _, xo := range x.Objects {
+			o, err := toObject(&xo)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", x.Name, err)
+			}
+			l.Objects = append(l.Objects, o)
+		}
+	case LayerKindImage:
+		if x.Image != nil && x.Image.Source != "" {
+			l.ImageSource = path.Join(dir, x.Image.Source)
+		}
+	case LayerKindGroup:
+		layers, err := toLayers(x.Layers, dir)
+		if err != nil {
+			return nil, err
+		}
+		l.Layers = layers
+	}
+	return l, nil
+}
+
+func toObject(x *xmlObject) (*Object, error) {
+	o := &Object{
+		ID:         x.ID,
+		Name:       x.Name,
+		Class:      x.Class,
+		X:          x.X,
+		Y:          x.Y,
+		Width:      x.Width,
+		Height:     x.Height,
+		Rotation:   x.Rotation,
+		Visible:    x.Visible != "0",
+		GID:        x.GID,
+		Ellipse:    x.Ellipse != nil,
+		Point:      x.Point != nil,
+		Template:   x.Template,
+		Properties: toProperties(x.Properties),
+	}
+	if o.Class == "" {
+		o.Class = x.Type
+	}
+	if x.Text != nil {
+		o.Text = x.Text.Text
+	}
+	if x.Polygon != nil {
+		ps, err := parsePoints(x.Polygon.Points)
+		if err != nil {
+			return nil, err
+		}
+		o.Polygon = ps
+	}
+	if x.Polyline != nil {
+		ps, err := parsePoints(x.Polyline.Points)
+		if err != nil {
+			return nil, err
+		}
+		o.Polyline = ps
+	}
+	return o, nil
+}
+
+func parsePoints(str string) ([]Point, error) {
+	var ps []Point
+	for _, token := range strings.Fields(str) {
+		xy := strings.Split(token, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("invalid point: %q", token)
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, Point{X: x, Y: y})
+	}
+	return ps, nil
+}
+
+func decodeTiles(data *xmlData, count int) ([]GID, error) {
+	if len(data.Chunks) > 0 {
+		return nil, fmt.Errorf("chunks are not supported")
+	}
+
+	tiles := make([]GID, 0, count)
+	switch data.Encoding {
+	case "":
+		for _, t := range data.Tiles {
+			tiles = append(tiles, t.GID)
+		}
+	case "csv":
+		for _, token := range strings.Split(data.Text, ",") {
+			token = strings.TrimSpace(token)
+			if token == "" {
+				continue
+			}
+			v, err := strconv.ParseUint(token, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			tiles = append(tiles, GID(v))
+		}
+	case "base64":
+		bs, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data.Text))
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = bytes.NewReader(bs)
+		switch data.Compression {
+		case "":
+		case "zlib":
+			zr, err := zlib.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				_ = zr.Close()
+			}()
+			r = zr
+		case "gzip":
+			gr, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			defer func() {
+				_ = gr.Close()
+			}()
+			r = gr
+		default:
+			return nil, fmt.Errorf("unsupported compression: %s", data.Compression)
+		}
+		bs, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(bs); i += 4 {
+			tiles = append(tiles, GID(binary.LittleEndian.Uint32(bs[i:])))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", data.Encoding)
+	}
+
+	if len(tiles) != count {
+		return nil, fmt.Errorf("the number of tiles must be %d but %d", count, len(tiles))
+	}
+	return tiles, nil
+}
+
+// parseColor parses a color in the form of #RRGGBB or #AARRGGBB.
+// parseColor returns nil for an empty string.
+func parseColor(str string) (color.Color, error) {
+	if str == "" {
+		return nil, nil
+	}
+	s := strings.TrimPrefix(str, "#")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color: %q", str)
+	}
+	switch len(s) {
+	case 6:
+		return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+	case 8:
+		return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: uint8(v >> 24)}, nil
+	default:
+		return nil, fmt.Errorf("invalid color: %q", str)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tiled_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/exp/tiled"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="4" tileheight="4" infinite="0" backgroundcolor="#80112233">
+ <properties>
+  <property name="title" value="test"/>
+  <property name="level" type="int" value="3"/>
+  <property name="note">line1
+line2</property>
+ </properties>
+ <tileset firstgid="1" source="tiles.tsx"/>
+ <layer id="1" name="ground" width="3" height="2">
+  <data encoding="csv">
+1,2,0,
+2147483650,1,4
+</data>
+ </layer>
+ <objectgroup id="2" name="objects" offsetx="1" offsety="2">
+  <object id="1" name="player" type="actor" x="4" y="8" width="4" height="4" gid="1"/>
+  <object id="2" name="area" x="0" y="0" width="8" height="4">
+   <properties>
+    <property name="solid" type="bool" value="true"/>
+   </properties>
+  </object>
+  <object id="3" x="1" y="1">
+   <polygon points="0,0 4,0 4,4"/>
+  </object>
+ </objectgroup>
+ <group id="3" name="group" opacity="0.5">
+  <layer id="4" name="base64" width="3" height="2" visible="0">
+   <data encoding="base64" compression="zlib">BASE64</data>
+  </layer>
+ </group>
+</map>
+`
+
+const testTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="tiles" tilewidth="4" tileheight="4" tilecount="4" columns="2">
+ <image source="tiles.png" width="8" height="8"/>
+ <tile id="3">
+  <properties>
+   <property name="kind" value="water"/>
+  </properties>
+  <animation>
+   <frame tileid="3" duration="100"/>
+   <frame tileid="0" duration="200"/>
+  </animation>
+ </tile>
+</tileset>
+`
+
+var (
+	testColor0 = color.RGBA{0xff, 0, 0, 0xff}
+	testColor1 = color.RGBA{0, 0xff, 0, 0xff}
+	testColor2 = color.RGBA{0, 0, 0xff, 0xff}
+	testColor3 = color.RGBA{0xff, 0xff, 0, 0xff}
+)
+
+func newTestFS(t *testing.T) fstest.MapFS {
+	// Tiles are filled with colors except that the upper-left pixels are white.
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 8; i++ {
+			c := []color.RGBA{testColor0, testColor1, testColor2, testColor3}[(j/4)*2+i/4]
+			if i%4 == 0 && j%4 == 0 {
+				c = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			img.Set(i, j, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	var data bytes.Buffer
+	w := zlib.NewWriter(&data)
+	for _, gid := range []uint32{4, 3, 2, 1, 0, 0} {
+		if err := binary.Write(w, binary.LittleEndian, gid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tmx := bytes.Replace([]byte(testTMX), []byte("BASE64"), []byte(base64.StdEncoding.EncodeToString(data.Bytes())), 1)
+
+	return fstest.MapFS{
+		"maps/test.tmx":  &fstest.MapFile{Data: tmx},
+		"maps/tiles.tsx": &fstest.MapFile{Data: []byte(testTSX)},
+		"maps/tiles.png": &fstest.MapFile{Data: buf.Bytes()},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	m, err := tiled.Load(newTestFS(t), "maps/test.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := m.Width, 3; got != want {
+		t.Errorf("Width: got: %d, want: %d", got, want)
+	}
+	if got, want := m.BackgroundColor, (color.NRGBA{0x11, 0x22, 0x33, 0x80}); got != want {
+		t.Errorf("BackgroundColor: got: %v, want: %v", got, want)
+	}
+	if got, ok := m.Properties.String("title"); !ok || got != "test" {
+		t.Errorf("title: got: %q, %t, want: %q, true", got, ok, "test")
+	}
+	if got, ok := m.Properties.Int("level"); !ok || got != 3 {
+		t.Errorf("level: got: %d, %t, want: %d, true", got, ok, 3)
+	}
+	if got, ok := m.Properties.String("note"); !ok || got != "line1\nline2" {
+		t.Errorf("note: got: %q, %t, want: %q, true", got, ok, "line1\nline2")
+	}
+	if _, ok := m.Properties.Int("title"); ok {
+		t.Errorf("title must not be an integer")
+	}
+
+	// Tilesets
+	if got, want := len(m.Tilesets), 1; got != want {
+		t.Fatalf("len(Tilesets): got: %d, want: %d", got, want)
+	}
+	ts := m.Tilesets[0]
+	if got, want := ts.Source, "maps/tiles.tsx"; got != want {
+		t.Errorf("Source: got: %q, want: %q", got, want)
+	}
+	if got, want := ts.ImageSource, "maps/tiles.png"; got != want {
+		t.Errorf("ImageSource: got: %q, want: %q", got, want)
+	}
+	if got, want := ts.TileBounds(3), image.Rect(4, 4, 8, 8); got != want {
+		t.Errorf("TileBounds(3): got: %v, want: %v", got, want)
+	}
+	tile := ts.Tile(3)
+	if tile == nil {
+		t.Fatalf("Tile(3) must not be nil")
+	}
+	if got, ok := tile.Properties.String("kind"); !ok || got != "water" {
+		t.Errorf("kind: got: %q, %t, want: %q, true", got, ok, "water")
+	}
+	for _, tc := range []struct {
+		at   time.Duration
+		want int
+	}{
+		{0, 3},
+		{99 * time.Millisecond, 3},
+		{100 * time.Millisecond, 0},
+		{299 * time.Millisecond, 0},
+		{300 * time.Millisecond, 3},
+	} {
+		if got := tile.FrameAt(tc.at); got != tc.want {
+			t.Errorf("FrameAt(%v): got: %d, want: %d", tc.at, got, tc.want)
+		}
+	}
+
+	// Layers
+	if got, want := len(m.Layers), 3; got != want {
+		t.Fatalf("len(Layers): got: %d, want: %d", got, want)
+	}
+	ground := m.Layers[0]
+	if got, want := ground.Kind, tiled.LayerKindTile; got != want {
+		t.Errorf("Kind: got: %v, want: %v", got, want)
+	}
+	if got, want := ground.TileAt(1, 0).ID(), uint32(2); got != want {
+		t.Errorf("TileAt(1, 0): got: %d, want: %d", got, want)
+	}
+	if gid := ground.TileAt(0, 1); gid.ID() != 2 || !gid.FlippedHorizontally() || gid.FlippedVertically() {
+		t.Errorf("TileAt(0, 1): got: %d (%t, %t), want: 2 (true, false)", gid.ID(), gid.FlippedHorizontally(), gid.FlippedVertically())
+	}
+	if ts, id := m.TilesetForGID(ground.TileAt(2, 1)); ts != m.Tilesets[0] || id != 3 {
+		t.Errorf("TilesetForGID: got: %p, %d, want: %p, %d", ts, id, m.Tilesets[0], 3)
+	}
+
+	objects := m.Layers[1]
+	if got, want := objects.Kind, tiled.LayerKindObject; got != want {
+		t.Errorf("Kind: got: %v, want: %v", got, want)
+	}
+	if got, want := len(objects.Objects), 3; got != want {
+		t.Fatalf("len(Objects): got: %d, want: %d", got, want)
+	}
+	if o := objects.Objects[0]; o.Name != "player" || o.Class != "actor" || o.GID != 1 {
+		t.Errorf("Objects[0]: got: %q, %q, %d, want: %q, %q, %d", o.Name, o.Class, o.GID, "player", "actor", 1)
+	}
+	if got, ok := objects.Objects[1].Properties.Bool("solid"); !ok || !got {
+		t.Errorf("solid: got: %t, %t, want: true, true", got, ok)
+	}
+	if got, want := objects.Objects[2].Polygon, []tiled.Point{{0, 0}, {4, 0}, {4, 4}}; len(got) != len(want) || got[2] != want[2] {
+		t.Errorf("Polygon: got: %v, want: %v", got, want)
+	}
+
+	b64 := m.LayerByName("base64")
+	if b64 == nil {
+		t.Fatalf("LayerByName must not return nil")
+	}
+	if b64.Visible {
+		t.Errorf("Visible must be false")
+	}
+	if got, want := b64.TileAt(0, 0).ID(), uint32(4); got != want {
+		t.Errorf("TileAt(0, 0): got: %d, want: %d", got, want)
+	}
+	if got, want := m.Layers[2].Opacity, 0.5; got != want {
+		t.Errorf("Opacity: got: %v, want: %v", got, want)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	m, err := tiled.Load(newTestFS(t), "maps/test.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(12, 8)
+	m.DrawLayer(dst, m.Layers[0], nil)
+
+	for _, tc := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{2, 2, testColor0},
+		{6, 2, testColor1},
+		{10, 2, color.RGBA{}},
+		// The flipped tile has the white pixel at the upper-right corner.
+		{3, 4, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{0, 4, testColor1},
+		{6, 6, testColor0},
+		{10, 6, testColor3},
+	} {
+		if got := dst.At(tc.x, tc.y); got != tc.want {
+			t.Errorf("At(%d, %d): got: %v, want: %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	// The animated tile shows the next frame.
+	dst.Clear()
+	m.DrawLayer(dst, m.Layers[0], &tiled.DrawOptions{Time: 150 * time.Millisecond})
+	if got, want := dst.At(10, 6), testColor0; got != want {
+		t.Errorf("At(10, 6): got: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tween
+
+import (
+	"math"
+)
+
+// EaseFunc is an easing function.
+// An easing function takes a progress in [0, 1] and returns an eased progress.
+// The returned value should be 0 for 0 and 1 for 1, but can be out of [0, 1] in between, like InBack.
+type EaseFunc func(t float64) float64
+
+// Linear is the identity easing function.
+func Linear(t float64) float64 {
+	return t
+}
+
+// InQuad is a quadratic easing function accelerating from zero velocity.
+func InQuad(t float64) float64 {
+	return t * t
+}
+
+// OutQuad is a quadratic easing function decelerating to zero velocity.
+func OutQuad(t float64) float64 {
+	return 1 - InQuad(1-t)
+}
+
+// InOutQuad is a quadratic easing function accelerating until halfway, then decelerating.
+func InOutQuad(t float64) float64 {
+	return inOut(InQuad, t)
+}
+
+// InCubic is a cubic easing function accelerating from zero velocity.
+func InCubic(t float64) float64 {
+	return t * t * t
+}
+
+// OutCubic is a cubic easing function decelerating to zero velocity.
+func OutCubic(t float64) float64 {
+	return 1 - InCubic(1-t)
+}
+
+// InOutCubic is a cubic easing function accelerating until halfway, then decelerating.
+func InOutCubic(t float64) float64 {
+	return inOut(InCubic, t)
+}
+
+// InQuart is a quartic easing function accelerating from zero velocity.
+func InQuart(t float64) float64 {
+	return t * t * t * t
+}
+
+// OutQuart is a quartic easing function decelerating to zero velocity.
+func OutQuart(t float64) float64 {
+	return 1 - InQuart(1-t)
+}
+
+// InOutQuart is a quartic easing function accelerating until halfway, then decelerating.
+func InOutQuart(t float64) float64 {
+	return inOut(InQuart, t)
+}
+
+// InSine is a sinusoidal easing function accelerating from zero velocity.
+func InSine(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+// OutSine is a sinusoidal easing function decelerating to zero velocity.
+func OutSine(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+// InOutSine is a sinusoidal easing function accelerating until halfway, then decelerating.
+func InOutSine(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+// InExpo is an exponential easing function accelerating from zero velocity.
+func InExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Pow(2, 10*(t-1))
+}
+
+// OutExpo is an exponential easing function decelerating to zero velocity.
+func OutExpo(t float64) float64 {
+	return 1 - InExpo(1-t)
+}
+
+// InOutExpo is an exponential easing function accelerating until halfway, then decelerating.
+func InOutExpo(t float64) float64 {
+	return inOut(InExpo, t)
+}
+
+// InBack is an easing function overshooting backward at the beginning.
+func InBack(t float64) float64 {
+	const s = 1.70158
+	return t * t * ((s+1)*t - s)
+}
+
+// OutBack is an easing function overshooting forward at the end.
+func OutBack(t float64) float64 {
+	return 1 - InBack(1-t)
+}
+
+// InOutBack is an easing function overshooting at both ends.
+func InOutBack(t float64) float64 {
+	return inOut(InBack, t)
+}
+
+// InElastic is an easing function oscillating like a spring at the beginning.
+func InElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return -math.Pow(2, 10*(t-1)) * math.Sin((t-1.075)*2*math.Pi/0.3)
+}
+
+// OutElastic is an easing function oscillating like a spring at the end.
+func OutElastic(t float64) float64 {
+	return 1 - InElastic(1-t)
+}
+
+// InOutElastic is an easing function oscillating like a spring at both ends.
+func InOutElastic(t float64) float64 {
+	return inOut(InElastic, t)
+}
+
+// OutBounce is an easing function bouncing at the end.
+func OutBounce(t float64) float64 {
+	const (
+		n = 7.5625
+		d = 2.75
+	)
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// InBounce is an easing function bouncing at the beginning.
+func InBounce(t float64) float64 {
+	return 1 - OutBounce(1-t)
+}
+
+// InOutBounce is an easing function bouncing at both ends.
+func InOutBounce(t float64) float64 {
+	return inOut(InBounce, t)
+}
+
+// inOut makes an in-out easing function from an in easing function.
+func inOut(in EaseFunc, t float64) float64 {
+	if t < 0.5 {
+		return in(t*2) / 2
+	}
+	return 1 - in((1-t)*2)/2
+}
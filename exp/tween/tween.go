@@ -0,0 +1,351 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tween provides tweens, easing functions, and compositions of them.
+// This package is experimental and the API might be changed in the future.
+//
+// An animation advances by Update, which is expected to be called in every Update of the game,
+// or by Advance with an arbitrary duration like a real elapsed time.
+// The time of a tick is determined by ebiten.TPS, so the speed of animations doesn't depend on TPS.
+package tween
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Animation is a time-based animation.
+type Animation interface {
+	// Advance advances the animation by d.
+	// Advance returns the part of d remaining after the animation finishes, or 0 if the animation is not finished.
+	Advance(d time.Duration) time.Duration
+
+	// Finished reports whether the animation is finished.
+	Finished() bool
+
+	// Reset rewinds the animation to the beginning.
+	Reset()
+}
+
+// tick returns the duration of one tick.
+func tick() time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	return time.Second / time.Duration(tps)
+}
+
+// Tween interpolates a value from From to To over Duration.
+type Tween struct {
+	// From and To are the start and the end values.
+	From float64
+	To   float64
+
+	// Duration is the duration of the tween.
+	Duration time.Duration
+
+	// Ease is the easing function. If Ease is nil, Linear is used.
+	Ease EaseFunc
+
+	// OnUpdate is called with the current value whenever the tween advances, if not nil.
+	OnUpdate func(value float64)
+
+	// OnFinish is called when the tween finishes, if not nil.
+	OnFinish func()
+
+	elapsed  time.Duration
+	finished bool
+}
+
+// Value returns the current value.
+func (t *Tween) Value() float64 {
+	ease := t.Ease
+	if ease == nil {
+		ease = Linear
+	}
+	return t.From + (t.To-t.From)*ease(t.Progress())
+}
+
+// Progress returns the current progress in [0, 1] before easing.
+func (t *Tween) Progress() float64 {
+	if t.finished {
+		return 1
+	}
+	if t.Duration <= 0 {
+		return 0
+	}
+	return float64(t.elapsed) / float64(t.Duration)
+}
+
+// Update advances the tween by one tick.
+func (t *Tween) Update() {
+	t.Advance(tick())
+}
+
+// Advance implements Animation.
+func (t *Tween) Advance(d time.Duration) time.Duration {
+	if t.finished {
+		return d
+	}
+	t.elapsed += d
+	var rest time.Duration
+	if t.elapsed >= t.Duration {
+		rest = t.elapsed - t.Duration
+		t.elapsed = t.Duration
+		t.finished = true
+	}
+	if t.OnUpdate != nil {
+		t.OnUpdate(t.Value())
+	}
+	if t.finished && t.OnFinish != nil {
+		t.OnFinish()
+	}
+	return rest
+}
+
+// Finished implements Animation.
+func (t *Tween) Finished() bool {
+	return t.finished
+}
+
+// Reset implements Animation.
+func (t *Tween) Reset() {
+	t.elapsed = 0
+	t.finished = false
+}
+
+// Delay is an animation that does nothing for Duration.
+// Delay is useful to make a pause in a Sequence.
+type Delay struct {
+	// Duration is the duration of the delay.
+	Duration time.Duration
+
+	elapsed  time.Duration
+	finished bool
+}
+
+// Update advances the delay by one tick.
+func (d *Delay) Update() {
+	d.Advance(tick())
+}
+
+// Advance implements Animation.
+func (d *Delay) Advance(dt time.Duration) time.Duration {
+	if d.finished {
+		return dt
+	}
+	d.elapsed += dt
+	if d.elapsed < d.Duration {
+		return 0
+	}
+	d.finished = true
+	return d.elapsed - d.Duration
+}
+
+// Finished implements Animation.
+func (d *Delay) Finished() bool {
+	return d.finished
+}
+
+// Reset implements Animation.
+func (d *Delay) Reset() {
+	d.elapsed = 0
+	d.finished = false
+}
+
+// Callback is an animation that calls Func and finishes immediately.
+type Callback struct {
+	// Func is the function to be called.
+	Func func()
+
+	finished bool
+}
+
+// Update calls the function if the callback is not finished.
+func (c *Callback) Update() {
+	c.Advance(0)
+}
+
+// Advance implements Animation.
+func (c *Callback) Advance(d time.Duration) time.Duration {
+	if !c.finished {
+		c.finished = true
+		if c.Func != nil {
+			c.Func()
+		}
+	}
+	return d
+}
+
+// Finished implements Animation.
+func (c *Callback) Finished() bool {
+	return c.finished
+}
+
+// Reset implements Animation.
+func (c *Callback) Reset() {
+	c.finished = false
+}
+
+// Sequence is an animation that plays animations one after another.
+type Sequence struct {
+	animations []Animation
+	index      int
+}
+
+// NewSequence creates a new Sequence with the animations.
+func NewSequence(animations ...Animation) *Sequence {
+	return &Sequence{
+		animations: animations,
+	}
+}
+
+// Update advances the sequence by one tick.
+func (s *Sequence) Update() {
+	s.Advance(tick())
+}
+
+// Advance implements Animation.
+// The remaining time of an animation is carried over to the next animation.
+func (s *Sequence) Advance(d time.Duration) time.Duration {
+	for s.index < len(s.animations) {
+		a := s.animations[s.index]
+		d = a.Advance(d)
+		if !a.Finished() {
+			return 0
+		}
+		s.index++
+	}
+	return d
+}
+
+// Finished implements Animation.
+func (s *Sequence) Finished() bool {
+	return s.index >= len(s.animations)
+}
+
+// Reset implements Animation.
+func (s *Sequence) Reset() {
+	s.index = 0
+	for _, a := range s.animations {
+		a.Reset()
+	}
+}
+
+// Parallel is an animation that plays animations at the same time.
+// Parallel finishes when all the animations finish.
+type Parallel struct {
+	animations []Animation
+}
+
+// NewParallel creates a new Parallel with the animations.
+func NewParallel(animations ...Animation) *Parallel {
+	return &Parallel{
+		animations: animations,
+	}
+}
+
+// Update advances the animations by one tick.
+func (p *Parallel) Update() {
+	p.Advance(tick())
+}
+
+// Advance implements Animation.
+func (p *Parallel) Advance(d time.Duration) time.Duration {
+	rest := d
+	for _, a := range p.animations {
+		if a.Finished() {
+			continue
+		}
+		if r := a.Advance(d); r < rest {
+			rest = r
+		}
+	}
+	if !p.Finished() {
+		return 0
+	}
+	return rest
+}
+
+// Finished implements Animation.
+func (p *Parallel) Finished() bool {
+	for _, a := range p.animations {
+		if !a.Finished() {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset implements Animation.
+func (p *Parallel) Reset() {
+	for _, a := range p.animations {
+		a.Reset()
+	}
+}
+
+// Repeat is an animation that plays an animation repeatedly.
+type Repeat struct {
+	animation Animation
+	count     int
+	done      int
+}
+
+// NewRepeat creates a new Repeat playing the animation count times.
+// If count is negative, the animation is repeated forever.
+func NewRepeat(animation Animation, count int) *Repeat {
+	return &Repeat{
+		animation: animation,
+		count:     count,
+	}
+}
+
+// Update advances the animation by one tick.
+func (r *Repeat) Update() {
+	r.Advance(tick())
+}
+
+// Advance implements Animation.
+func (r *Repeat) Advance(d time.Duration) time.Duration {
+	for !r.Finished() {
+		start := d
+		d = r.animation.Advance(d)
+		if !r.animation.Finished() {
+			return 0
+		}
+		r.done++
+		if r.Finished() {
+			break
+		}
+		r.animation.Reset()
+		// Stop if no time remains, or if the animation consumes no time to avoid an infinite loop.
+		if d <= 0 || d == start {
+			return 0
+		}
+	}
+	return d
+}
+
+// Finished implements Animation.
+func (r *Repeat) Finished() bool {
+	return r.count >= 0 && r.done >= r.count
+}
+
+// Reset implements Animation.
+func (r *Repeat) Reset() {
+	r.done = 0
+	r.animation.Reset()
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tween_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/exp/tween"
+	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
+)
+
+func TestMain(m *testing.M) {
+	t.MainWithRunLoop(m)
+}
+
+func TestEaseFuncs(t *testing.T) {
+	for name, f := range map[string]tween.EaseFunc{
+		"Linear":       tween.Linear,
+		"InQuad":       tween.InQuad,
+		"OutQuad":      tween.OutQuad,
+		"InOutQuad":    tween.InOutQuad,
+		"InCubic":      tween.InCubic,
+		"OutCubic":     tween.OutCubic,
+		"InOutCubic":   tween.InOutCubic,
+		"InQuart":      tween.InQuart,
+		"OutQuart":     tween.OutQuart,
+		"InOutQuart":   tween.InOutQuart,
+		"InSine":       tween.InSine,
+		"OutSine":      tween.OutSine,
+		"InOutSine":    tween.InOutSine,
+		"InExpo":       tween.InExpo,
+		"OutExpo":      tween.OutExpo,
+		"InOutExpo":    tween.InOutExpo,
+		"InBack":       tween.InBack,
+		"OutBack":      tween.OutBack,
+		"InOutBack":    tween.InOutBack,
+		"InElastic":    tween.InElastic,
+		"OutElastic":   tween.OutElastic,
+		"InOutElastic": tween.InOutElastic,
+		"InBounce":     tween.InBounce,
+		"OutBounce":    tween.OutBounce,
+		"InOutBounce":  tween.InOutBounce,
+	} {
+		if got := f(0); math.Abs(got) > 1e-9 {
+			t.Errorf("%s(0): got: %v, want: 0", name, got)
+		}
+		if got := f(1); math.Abs(got-1) > 1e-9 {
+			t.Errorf("%s(1): got: %v, want: 1", name, got)
+		}
+	}
+}
+
+func TestTween(t *testing.T) {
+	var values []float64
+	var finished int
+	tw := &tween.Tween{
+		From:     10,
+		To:       20,
+		Duration: time.Second,
+		OnUpdate: func(value float64) {
+			values = append(values, value)
+		},
+		OnFinish: func() {
+			finished++
+		},
+	}
+	if got := tw.Advance(500 * time.Millisecond); got != 0 {
+		t.Errorf("Advance: got: %v, want: 0", got)
+	}
+	if got, want := tw.Value(), 15.0; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+	if got, want := tw.Advance(700*time.Millisecond), 200*time.Millisecond; got != want {
+		t.Errorf("Advance: got: %v, want: %v", got, want)
+	}
+	if !tw.Finished() {
+		t.Errorf("Finished must return true")
+	}
+	if got, want := tw.Value(), 20.0; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+	tw.Advance(time.Second)
+	if got, want := len(values), 2; got != want {
+		t.Errorf("len(values): got: %d, want: %d", got, want)
+	}
+	if got, want := finished, 1; got != want {
+		t.Errorf("finished: got: %d, want: %d", got, want)
+	}
+
+	tw.Reset()
+	if got, want := tw.Value(), 10.0; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var called bool
+	a := &tween.Tween{From: 0, To: 1, Duration: time.Second}
+	b := &tween.Tween{From: 0, To: 1, Duration: time.Second}
+	s := tween.NewSequence(a, &tween.Delay{Duration: time.Second}, b, &tween.Callback{Func: func() { called = true }})
+
+	// The remaining time of a is carried over to the delay.
+	s.Advance(1500 * time.Millisecond)
+	if !a.Finished() {
+		t.Errorf("a must be finished")
+	}
+	s.Advance(time.Second)
+	if got, want := b.Value(), 0.5; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+	if called {
+		t.Errorf("the callback must not be called yet")
+	}
+	if got, want := s.Advance(time.Second), 500*time.Millisecond; got != want {
+		t.Errorf("Advance: got: %v, want: %v", got, want)
+	}
+	if !called {
+		t.Errorf("the callback must be called")
+	}
+	if !s.Finished() {
+		t.Errorf("Finished must return true")
+	}
+}
+
+func TestParallel(t *testing.T) {
+	a := &tween.Tween{From: 0, To: 1, Duration: time.Second}
+	b := &tween.Tween{From: 0, To: 1, Duration: 2 * time.Second}
+	p := tween.NewParallel(a, b)
+
+	p.Advance(1500 * time.Millisecond)
+	if !a.Finished() || b.Finished() {
+		t.Errorf("Finished: got: (%v, %v), want: (true, false)", a.Finished(), b.Finished())
+	}
+	if got, want := p.Advance(time.Second), 500*time.Millisecond; got != want {
+		t.Errorf("Advance: got: %v, want: %v", got, want)
+	}
+	if !p.Finished() {
+		t.Errorf("Finished must return true")
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	var n int
+	a := &tween.Tween{From: 0, To: 1, Duration: time.Second, OnFinish: func() { n++ }}
+	r := tween.NewRepeat(a, 3)
+	r.Advance(2500 * time.Millisecond)
+	if got, want := n, 2; got != want {
+		t.Errorf("n: got: %d, want: %d", got, want)
+	}
+	if got, want := a.Value(), 0.5; got != want {
+		t.Errorf("Value: got: %v, want: %v", got, want)
+	}
+	if got, want := r.Advance(time.Second), 500*time.Millisecond; got != want {
+		t.Errorf("Advance: got: %v, want: %v", got, want)
+	}
+	if !r.Finished() {
+		t.Errorf("Finished must return true")
+	}
+
+	// Repeating a callback forever must not hang.
+	var calls int
+	forever := tween.NewRepeat(&tween.Callback{Func: func() { calls++ }}, -1)
+	forever.Advance(time.Second)
+	if got, want := calls, 1; got != want {
+		t.Errorf("calls: got: %d, want: %d", got, want)
+	}
+}
@@ -14,6 +14,80 @@
 
 package ebiten
 
+import (
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
+)
+
 var (
-	ImageToBytes = imageToBytes
+	ImageToBytes   = imageToBytes
+	ImageToUint16s = imageToUint16s
 )
+
+var CallReadPixelsAsyncCallbacks = graphicscommand.CallReadPixelsAsyncCallbacks
+
+// DrawFinalScreenForTesting renders offscreen onto screen with the given screen filter in the same way as the final screen.
+func DrawFinalScreenForTesting(screen, offscreen *Image, filter ScreenFilter, scale float64) {
+	g := newGameForUI(nil, false, &RunGameOptions{
+		ScreenFilter: filter,
+	})
+	g.screen = screen
+	g.offscreen = offscreen
+	g.DrawFinalScreen(scale, 0, 0)
+}
+
+// RunImageDumperConcurrentlyForTesting calls the image dumper's update and dump concurrently n times each
+// in the same way as the pipelined update mode, where the screenshot key is pressed at every other update.
+// RunImageDumperConcurrentlyForTesting returns the number of the handled screenshot requests.
+func RunImageDumperConcurrentlyForTesting(n int) int {
+	var pressed bool
+	d := &imageDumper{
+		keyState:         map[Key]int{},
+		hasScreenshotKey: true,
+		screenshotKey:    KeyA,
+		isKeyPressed: func(key Key) bool {
+			pressed = !pressed
+			return pressed
+		},
+	}
+
+	var count int
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			_ = d.update()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if screenshot, _ := d.takeRequests(); screenshot {
+				count++
+			}
+		}
+	}()
+	wg.Wait()
+
+	if screenshot, _ := d.takeRequests(); screenshot {
+		count++
+	}
+	return count
+}
+
+// DrawOffscreenForTesting calls the game's Draw for offscreen in the same way as a frame,
+// where the damaged region reported by the game is respected.
+func DrawOffscreenForTesting(game Game, offscreen *Image) error {
+	g := newGameForUI(game, false, nil)
+	g.offscreen = offscreen
+	region := offscreen.Bounds()
+	if r, ok := g.DamagedRegion(); ok {
+		region = r.Intersect(region)
+	}
+	if region.Empty() {
+		return nil
+	}
+	return g.DrawOffscreen(region)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"unsafe"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+)
+
+// Format represents a pixel format of an image.
+type Format int
+
+const (
+	// FormatRGBA8 is a format with 8-bit unsigned normalized integers for each channel.
+	// The values are in [0, 1].
+	FormatRGBA8 Format = Format(graphicsdriver.PixelFormatRGBA8)
+
+	// FormatRGBA16F is a format with 16-bit floating point values for each channel.
+	// The values can be out of [0, 1]. This is useful for HDR rendering.
+	//
+	// FormatRGBA16F is available only with OpenGL (including WebGL) so far.
+	// With Metal, DirectX and PlayStation 5, an image with FormatRGBA16F keeps 8-bit values as FormatRGBA8 does.
+	FormatRGBA16F Format = Format(graphicsdriver.PixelFormatRGBA16F)
+
+	// FormatRGBA32F is a format with 32-bit floating point values for each channel.
+	// The values can be out of [0, 1]. FormatRGBA32F is more precise than FormatRGBA16F but uses twice as much memory.
+	//
+	// FormatRGBA32F is available only with OpenGL (including WebGL) so far.
+	// With Metal, DirectX and PlayStation 5, an image with FormatRGBA32F keeps 8-bit values as FormatRGBA8 does.
+	FormatRGBA32F Format = Format(graphicsdriver.PixelFormatRGBA32F)
+
+	// FormatRGBA16 is a format with 16-bit unsigned normalized integers for each channel.
+	// The values are in [0, 1].
+	// Use WritePixels16, ReadPixels16 and RGBA64At to access the pixels without losing precision.
+	//
+	// FormatRGBA16 is available only with OpenGL (including WebGL) so far.
+	// With Metal, DirectX and PlayStation 5, an image with FormatRGBA16 keeps 8-bit values,
+	// and the lower 8 bits of each value are lost.
+	FormatRGBA16 Format = Format(graphicsdriver.PixelFormatRGBA16)
+
+	// FormatSRGBA8 is a format with 8-bit sRGB-encoded values for each color channel and a linear alpha channel.
+	// The colors are decoded to the linear space when the image is sampled, and encoded to sRGB when the image is rendered.
+	// Then, filtering and alpha blending happen in the linear space.
+	//
+	// WritePixels, ReadPixels, At and Set treat pixels as sRGB-encoded 8-bit values without any conversions.
+	//
+	// FormatSRGBA8 is available only with OpenGL (including WebGL) so far.
+	// With Metal, DirectX and PlayStation 5, creating an image with FormatSRGBA8 causes an error.
+	FormatSRGBA8 Format = Format(graphicsdriver.PixelFormatSRGBA8)
+)
+
+func (f Format) isValid() bool {
+	switch f {
+	case FormatRGBA8, FormatRGBA16F, FormatRGBA32F, FormatRGBA16, FormatSRGBA8:
+		return true
+	}
+	return false
+}
+
+func (i *Image) pixelFormat() Format {
+	if i.isSubImage() {
+		return i.original.format
+	}
+	return i.format
+}
+
+// uint16sToBytes returns a byte slice sharing the same memory with the given uint16 slice.
+// The byte order is the native order, which the internal packages expect for 16-bit pixels.
+func uint16sToBytes(s []uint16) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*2)
+}
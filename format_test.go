@@ -0,0 +1,298 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func skipIfSRGBA8IsNotAvailable(t *testing.T) {
+	var info ebiten.DebugInfo
+	ebiten.ReadDebugInfo(&info)
+	if info.GraphicsLibrary != ebiten.GraphicsLibraryOpenGL {
+		t.Skip("FormatSRGBA8 is available only with OpenGL")
+	}
+}
+
+func TestImageFloatFormat(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureFloatFormats) {
+		t.Skip("floating point formats are not available")
+	}
+
+	for _, format := range []ebiten.Format{ebiten.FormatRGBA16F, ebiten.FormatRGBA32F} {
+		format := format
+		t.Run(fmt.Sprintf("format %d", format), func(t *testing.T) {
+			const w, h = 16, 16
+
+			hdr := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+				Format: format,
+			})
+			s0, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(3, 3, 3, 3)
+}
+`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			op := &ebiten.DrawRectShaderOptions{}
+			op.Blend = ebiten.BlendCopy
+			hdr.DrawRectShader(w, h, s0, op)
+
+			// The values out of [0, 1] are clamped when reading pixels.
+			if got, want := hdr.At(0, 0), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+				t.Errorf("got: %v, want: %v", got, want)
+			}
+
+			// Tone mapping.
+			s1, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return imageSrc0At(srcPos) / 4
+}
+`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dst := ebiten.NewImage(w, h)
+			op = &ebiten.DrawRectShaderOptions{}
+			op.Images[0] = hdr
+			dst.DrawRectShader(w, h, s1, op)
+
+			for j := 0; j < h; j++ {
+				for i := 0; i < w; i++ {
+					got := dst.At(i, j).(color.RGBA)
+					want := color.RGBA{R: 0xbf, G: 0xbf, B: 0xbf, A: 0xbf}
+					if !sameColors(got, want, 1) {
+						t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImageFloatFormatFallback(t *testing.T) {
+	if ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureFloatFormats) {
+		t.Skip("floating point formats are available")
+	}
+
+	const w, h = 16, 16
+
+	img := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+		Format: ebiten.FormatRGBA16F,
+	})
+
+	// Without floating point formats, the image works as an image with FormatRGBA8.
+	pix := make([]byte, 4*w*h)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	img.WritePixels(pix)
+	got := make([]byte, 4*w*h)
+	img.ReadPixels(got)
+	for i := range got {
+		if got[i] != pix[i] {
+			t.Fatalf("pixels[%d]: got: %d, want: %d", i, got[i], pix[i])
+		}
+	}
+}
+
+func TestImageFormatRGBA16(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureRGBA16) {
+		t.Skip("FormatRGBA16 is not available")
+	}
+
+	const w, h = 16, 16
+
+	img := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+		Format: ebiten.FormatRGBA16,
+	})
+
+	pix := make([]uint16, 4*w*h)
+	for i := range pix {
+		pix[i] = uint16(i * 0x101)
+	}
+	img.WritePixels16(pix)
+
+	got := make([]uint16, 4*w*h)
+	img.ReadPixels16(got)
+	for i := range got {
+		if got[i] != pix[i] {
+			t.Errorf("got[%d]: got: %d, want: %d", i, got[i], pix[i])
+		}
+	}
+
+	if got, want := img.RGBA64At(1, 0), (color.RGBA64{R: 0x404, G: 0x505, B: 0x606, A: 0x707}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := img.At(1, 0), (color.RGBA{R: 0x04, G: 0x05, B: 0x06, A: 0x07}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	clr := color.RGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xdef0}
+	img.Set(2, 3, clr)
+	if got, want := img.RGBA64At(2, 3), clr; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageFormatRGBA16SubImage(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureRGBA16) {
+		t.Skip("FormatRGBA16 is not available")
+	}
+
+	img := ebiten.NewImageWithOptions(image.Rect(0, 0, 16, 16), &ebiten.NewImageOptions{
+		Format: ebiten.FormatRGBA16,
+	})
+	sub := img.SubImage(image.Rect(4, 4, 12, 12)).(*ebiten.Image)
+
+	clr := color.RGBA64{R: 0x1234, G: 0x5678, B: 0x9abc, A: 0xdef0}
+	sub.Set(5, 6, clr)
+	if got, want := sub.RGBA64At(5, 6), clr; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := img.RGBA64At(5, 6), clr; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := sub.At(5, 6), (color.RGBA{R: 0x12, G: 0x56, B: 0x9a, A: 0xde}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// A position out of the sub-image is not changed.
+	sub.Set(2, 2, clr)
+	if got, want := img.RGBA64At(2, 2), (color.RGBA64{}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageFormatRGBA16Fallback(t *testing.T) {
+	if ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureRGBA16) {
+		t.Skip("FormatRGBA16 is available")
+	}
+
+	img := ebiten.NewImageWithOptions(image.Rect(0, 0, 1, 1), &ebiten.NewImageOptions{
+		Format: ebiten.FormatRGBA16,
+	})
+
+	// Without FormatRGBA16, the lower 8 bits of each value are lost.
+	img.WritePixels16([]uint16{0x1234, 0x5678, 0x9abc, 0xdef0})
+	got := make([]uint16, 4)
+	img.ReadPixels16(got)
+	want := []uint16{0x1212, 0x5656, 0x9a9a, 0xdede}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got[%d]: got: %#x, want: %#x", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewImageFromImageRGBA64(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureRGBA16) {
+		t.Skip("FormatRGBA16 is not available")
+	}
+
+	const w, h = 16, 16
+
+	src := image.NewRGBA64(image.Rect(0, 0, w, h))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			v := uint16((i + j*w) * 0x101)
+			src.SetRGBA64(i, j, color.RGBA64{R: v, G: v, B: v, A: 0xffff})
+		}
+	}
+
+	img := ebiten.NewImageFromImageWithOptions(src, &ebiten.NewImageFromImageOptions{
+		Format: ebiten.FormatRGBA16,
+	})
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := img.RGBA64At(i, j)
+			want := src.RGBA64At(i, j)
+			if got != want {
+				t.Errorf("img.RGBA64At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageFormatSRGBA8(t *testing.T) {
+	skipIfSRGBA8IsNotAvailable(t)
+
+	const w, h = 16, 16
+	img := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+		Format: ebiten.FormatSRGBA8,
+	})
+
+	// The pixels are written and read without any conversions.
+	pix := make([]byte, 4*w*h)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	img.WritePixels(pix)
+	got := make([]byte, 4*w*h)
+	img.ReadPixels(got)
+	for i := range got {
+		if got[i] != pix[i] {
+			t.Fatalf("pixels[%d]: got: %d, want: %d", i, got[i], pix[i])
+		}
+	}
+
+	// A rendered color is encoded to sRGB.
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0.5, 0.5, 0.5, 1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Blend = ebiten.BlendCopy
+	img.DrawRectShader(w, h, s, op)
+
+	abs := func(x int) int {
+		if x < 0 {
+			return -x
+		}
+		return x
+	}
+	// 0.5 in the linear space is about 0.735 in sRGB.
+	r, _, _, _ := img.At(0, 0).RGBA()
+	if got, want := int(r>>8), 0xbc; abs(got-want) > 1 {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+
+	// A sampled color is decoded to the linear space.
+	dst := ebiten.NewImage(w, h)
+	dst.DrawImage(img, nil)
+	r, _, _, _ = dst.At(0, 0).RGBA()
+	if got, want := int(r>>8), 0x80; abs(got-want) > 1 {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+}
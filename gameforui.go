@@ -36,17 +36,28 @@ type gameForUI struct {
 	offscreen    *Image
 	screen       *Image
 	screenShader *Shader
+	screenFilter ScreenFilter
 	imageDumper  imageDumper
 	transparent  bool
+
+	// postEffectImages are the intermediate images for the post effects.
+	postEffectImages [2]*Image
+
+	// linear indicates whether the offscreen is rendered in the linear space.
+	linear bool
 }
 
-func newGameForUI(game Game, transparent bool) *gameForUI {
+func newGameForUI(game Game, transparent bool, options *RunGameOptions) *gameForUI {
 	g := &gameForUI{
 		game:        game,
 		transparent: transparent,
 	}
+	if options != nil {
+		g.screenFilter = options.ScreenFilter
+		g.linear = options.ColorSpace == ColorSpaceLinearSRGB
+	}
 
-	s, err := NewShader(builtinshader.ScreenShaderSource)
+	s, err := NewShader(builtinshader.ScreenShaderSource(g.screenFilter.builtinScreenFilter(), g.linear))
 	if err != nil {
 		panic(fmt.Sprintf("ebiten: compiling the screen shader failed: %v", err))
 	}
@@ -60,13 +71,18 @@ func (g *gameForUI) NewOffscreenImage(width, height int) *ui.Image {
 		g.offscreen.Deallocate()
 		g.offscreen = nil
 	}
+	g.deallocatePostEffectImages()
 
 	// Keep the offscreen an unmanaged image that is always isolated from an atlas (#1938).
 	// The shader program for the screen is special and doesn't work well with an image on an atlas.
 	// An image on an atlas is surrounded by a transparent edge,
 	// and the shader program unexpectedly picks the pixel on the edges.
 	imageType := atlas.ImageTypeUnmanaged
-	g.offscreen = newImage(image.Rect(0, 0, width, height), imageType)
+	format := FormatRGBA8
+	if g.linear {
+		format = FormatSRGBA8
+	}
+	g.offscreen = newImage(image.Rect(0, 0, width, height), imageType, format)
 	return g.offscreen.image
 }
 
@@ -76,7 +92,7 @@ func (g *gameForUI) NewScreenImage(width, height int) *ui.Image {
 		g.screen = nil
 	}
 
-	g.screen = newImage(image.Rect(0, 0, width, height), atlas.ImageTypeScreen)
+	g.screen = newImage(image.Rect(0, 0, width, height), atlas.ImageTypeScreen, FormatRGBA8)
 	return g.screen.image
 }
 
@@ -114,8 +130,27 @@ func (g *gameForUI) Update() error {
 	return nil
 }
 
-func (g *gameForUI) DrawOffscreen() error {
-	g.game.Draw(g.offscreen)
+func (g *gameForUI) CommitDrawState() {
+	if c, ok := g.game.(DrawStateCommitter); ok {
+		c.CommitDrawState()
+	}
+}
+
+func (g *gameForUI) DamagedRegion() (image.Rectangle, bool) {
+	d, ok := g.game.(DamageReporter)
+	if !ok {
+		return image.Rectangle{}, false
+	}
+	return d.Damage(), true
+}
+
+func (g *gameForUI) DrawOffscreen(region image.Rectangle) error {
+	if region == g.offscreen.Bounds() {
+		g.game.Draw(g.offscreen)
+	} else {
+		// Drawing to the sub-image is clipped to the region, while the coordinates are the same as the offscreen's.
+		g.game.Draw(g.offscreen.SubImage(region).(*Image))
+	}
 	if err := g.imageDumper.dump(g.offscreen, g.transparent); err != nil {
 		return err
 	}
@@ -127,26 +162,50 @@ func (g *gameForUI) DrawFinalScreen(scale, offsetX, offsetY float64) {
 	geoM.Scale(scale, scale)
 	geoM.Translate(offsetX, offsetY)
 
+	offscreen := g.applyPostEffects()
+
 	if d, ok := g.game.(FinalScreenDrawer); ok {
-		d.DrawFinalScreen(g.screen, g.offscreen, geoM)
+		d.DrawFinalScreen(g.screen, offscreen, geoM)
 		return
 	}
 
 	switch {
-	case !screenFilterEnabled.Load(), math.Floor(scale) == scale:
+	case g.linear:
+		// The offscreen colors are decoded to the linear space when sampled. Encode them to sRGB again.
+		op := &DrawRectShaderOptions{}
+		op.Images[0] = offscreen
+		op.GeoM = geoM
+		w, h := offscreen.Bounds().Dx(), offscreen.Bounds().Dy()
+		g.screen.DrawRectShader(w, h, g.screenShader, op)
+	case g.usesNearestFilter(scale):
 		op := &DrawImageOptions{}
 		op.GeoM = geoM
-		g.screen.DrawImage(g.offscreen, op)
+		g.screen.DrawImage(offscreen, op)
 	case scale < 1:
 		op := &DrawImageOptions{}
 		op.GeoM = geoM
 		op.Filter = FilterLinear
-		g.screen.DrawImage(g.offscreen, op)
+		g.screen.DrawImage(offscreen, op)
 	default:
 		op := &DrawRectShaderOptions{}
-		op.Images[0] = g.offscreen
+		op.Images[0] = offscreen
 		op.GeoM = geoM
-		w, h := g.offscreen.Bounds().Dx(), g.offscreen.Bounds().Dy()
+		w, h := offscreen.Bounds().Dx(), offscreen.Bounds().Dy()
 		g.screen.DrawRectShader(w, h, g.screenShader, op)
 	}
 }
+
+// usesNearestFilter reports whether the offscreen can be rendered with the nearest filter without the screen shader.
+func (g *gameForUI) usesNearestFilter(scale float64) bool {
+	// Any filters are the same as the nearest filter without scaling.
+	if scale == 1 {
+		return true
+	}
+	switch g.screenFilter {
+	case ScreenFilterDefault:
+		return !screenFilterEnabled.Load() || math.Floor(scale) == scale
+	case ScreenFilterIntegerNearest:
+		return math.Floor(scale) == scale
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestScreenFilter(t *testing.T) {
+	const size = 4
+
+	offscreen := ebiten.NewImage(size, size)
+	pix := make([]byte, 4*size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			idx := 4 * (j*size + i)
+			if (i+j)%2 == 0 {
+				pix[idx] = 0xff
+				pix[idx+1] = 0xff
+				pix[idx+2] = 0xff
+			}
+			pix[idx+3] = 0xff
+		}
+	}
+	offscreen.WritePixels(pix)
+
+	for _, filter := range []ebiten.ScreenFilter{
+		ebiten.ScreenFilterDefault,
+		ebiten.ScreenFilterBicubic,
+		ebiten.ScreenFilterLanczos,
+		ebiten.ScreenFilterIntegerNearest,
+	} {
+		filter := filter
+		t.Run(fmt.Sprintf("filter%d", filter), func(t *testing.T) {
+			// Without scaling, the result should be the same as the offscreen.
+			screen := ebiten.NewImage(size, size)
+			ebiten.DrawFinalScreenForTesting(screen, offscreen, filter, 1)
+			for j := 0; j < size; j++ {
+				for i := 0; i < size; i++ {
+					got := screen.At(i, j)
+					want := offscreen.At(i, j)
+					if got != want {
+						t.Errorf("screen.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+
+			screen = ebiten.NewImage(3*size, 3*size)
+			ebiten.DrawFinalScreenForTesting(screen, offscreen, filter, 3)
+			var blended bool
+			for j := 0; j < 3*size; j++ {
+				for i := 0; i < 3*size; i++ {
+					got := screen.At(i, j).(color.RGBA)
+					if got.A != 0xff {
+						t.Errorf("screen.At(%d, %d).A: got: %d, want: 0xff", i, j, got.A)
+					}
+					if got.R != 0 && got.R != 0xff {
+						blended = true
+					}
+				}
+			}
+			// At an integer scale, only the smoothing filters blend the pixels.
+			switch filter {
+			case ebiten.ScreenFilterBicubic, ebiten.ScreenFilterLanczos:
+				if !blended {
+					t.Errorf("no pixels are blended")
+				}
+			default:
+				if blended {
+					t.Errorf("some pixels are blended")
+				}
+			}
+		})
+	}
+}
+
+func TestPostEffects(t *testing.T) {
+	invert, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0At(srcPos)
+	return vec4(c.a-c.rgb, c.a)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	add, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+var Red float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return imageSrc0At(srcPos) + vec4(Red, 0, 0, 0)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const w, h = 16, 16
+	offscreen := ebiten.NewImage(w, h)
+	offscreen.Fill(color.RGBA{R: 0x40, A: 0xff})
+
+	defer ebiten.SetPostEffects(nil)
+	ebiten.SetPostEffects([]ebiten.PostEffect{
+		{
+			Shader: add,
+			Uniforms: map[string]any{
+				"Red": float32(0x40) / 0xff,
+			},
+		},
+		{
+			Shader: invert,
+		},
+	})
+
+	screen := ebiten.NewImage(w, h)
+	ebiten.DrawFinalScreenForTesting(screen, offscreen, ebiten.ScreenFilterDefault, 1)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := screen.At(i, j).(color.RGBA)
+			want := color.RGBA{R: 0x7f, G: 0xff, B: 0xff, A: 0xff}
+			if !sameColors(got, want, 1) {
+				t.Errorf("screen.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// The offscreen should not be modified.
+	if got, want := offscreen.At(0, 0), (color.RGBA{R: 0x40, A: 0xff}); got != want {
+		t.Errorf("offscreen.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+type damageReportingGame struct {
+	damage    image.Rectangle
+	drawCount int
+}
+
+func (g *damageReportingGame) Update() error {
+	return nil
+}
+
+func (g *damageReportingGame) Draw(screen *ebiten.Image) {
+	g.drawCount++
+	screen.Fill(color.White)
+}
+
+func (g *damageReportingGame) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return outsideWidth, outsideHeight
+}
+
+func (g *damageReportingGame) Damage() image.Rectangle {
+	return g.damage
+}
+
+func TestDamageReporter(t *testing.T) {
+	const size = 4
+
+	offscreen := ebiten.NewImage(size, size)
+	g := &damageReportingGame{
+		damage: image.Rect(1, 1, 3, 3),
+	}
+	if err := ebiten.DrawOffscreenForTesting(g, offscreen); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.drawCount, 1; got != want {
+		t.Errorf("drawCount: got: %d, want: %d", got, want)
+	}
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			got := offscreen.At(i, j)
+			want := color.RGBA{}
+			if image.Pt(i, j).In(g.damage) {
+				want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}
+			if got != want {
+				t.Errorf("offscreen.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// An empty damaged region skips Draw.
+	g.damage = image.Rectangle{}
+	if err := ebiten.DrawOffscreenForTesting(g, offscreen); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.drawCount, 1; got != want {
+		t.Errorf("drawCount: got: %d, want: %d", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
+)
+
+// GPUFrameTime returns the time spent on the GPU in the last measured frame.
+//
+// The time is the duration between the start of the first graphics command and the end of the last graphics command
+// in a frame, measured with timestamp queries of the graphics library.
+// Comparing this with the time spent on the CPU tells whether a frame is CPU-bound or GPU-bound.
+//
+// The results are read without stalling the GPU, so the returned value is a few frames old.
+// The measurement starts when GPUFrameTime is called first. GPUFrameTime returns 0 until the first result is available.
+//
+// GPU timing is available with OpenGL 3.3 or later (not OpenGL ES nor WebGL), DirectX 11 and 12 (not Xbox),
+// and Metal on macOS 10.15, iOS 10.3 or later.
+// With Metal, the time is measured at the boundaries of command buffers, and measuring splits the command buffers.
+// Otherwise, GPUFrameTime always returns 0.
+//
+// GPUFrameTime is concurrent-safe.
+func GPUFrameTime() time.Duration {
+	return graphicscommand.GPUFrameTime()
+}
+
+// BeginGPUScope starts a GPU timing scope with the given name.
+//
+// The time spent on the GPU for the graphics commands between BeginGPUScope and EndGPUScope is measured,
+// and can be obtained by GPUScopeTime with the same name.
+// Scopes can be nested.
+//
+// If name is empty, BeginGPUScope panics.
+//
+// GPU timing is available in the same environments as GPUFrameTime.
+// Otherwise, BeginGPUScope and EndGPUScope do nothing.
+func BeginGPUScope(name string) {
+	if name == "" {
+		panic("ebiten: name must not be empty at BeginGPUScope")
+	}
+	atlas.BeginGPUScope(name)
+}
+
+// EndGPUScope ends the GPU timing scope started by the last BeginGPUScope.
+//
+// If there is no scope to end, EndGPUScope panics.
+func EndGPUScope() {
+	atlas.EndGPUScope()
+}
+
+// GPUScopeTime returns the time spent on the GPU in the last measured scope with the given name.
+//
+// Like GPUFrameTime, the returned value is a few frames old.
+// If a scope with the name is measured multiple times in a frame, the last one is returned.
+// GPUScopeTime returns 0 if no scope with the name has been measured yet.
+//
+// GPUScopeTime is concurrent-safe.
+func GPUScopeTime(name string) time.Duration {
+	return graphicscommand.GPUScopeTime(name)
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestGPUScope(t *testing.T) {
+	if got := ebiten.GPUFrameTime(); got < 0 {
+		t.Errorf("GPUFrameTime(): got: %v, want: >= 0", got)
+	}
+
+	dst := ebiten.NewImage(16, 16)
+	ebiten.BeginGPUScope("outer")
+	ebiten.BeginGPUScope("inner")
+	dst.Fill(color.White)
+	ebiten.EndGPUScope()
+	ebiten.EndGPUScope()
+
+	// Flush the commands.
+	if got, want := dst.At(0, 0), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	if got := ebiten.GPUScopeTime("inner"); got < 0 {
+		t.Errorf("GPUScopeTime(%q): got: %v, want: >= 0", "inner", got)
+	}
+	if got := ebiten.GPUScopeTime("unknown"); got != 0 {
+		t.Errorf("GPUScopeTime(%q): got: %v, want: 0", "unknown", got)
+	}
+}
+
+func TestGPUScopeWithoutBegin(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("EndGPUScope must panic but not")
+		}
+	}()
+	ebiten.EndGPUScope()
+}
@@ -15,7 +15,9 @@
 package ebiten
 
 import (
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
 	"github.com/hajimehoshi/ebiten/v2/internal/builtinshader"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
 	"github.com/hajimehoshi/ebiten/v2/internal/ui"
 )
 
@@ -28,8 +30,77 @@ const (
 
 	// FilterLinear represents linear filter
 	FilterLinear Filter = Filter(builtinshader.FilterLinear)
+
+	// FilterAnisotropic represents anisotropic filter.
+	//
+	// FilterAnisotropic uses the anisotropic filtering of the graphics driver, which takes multiple linear samples
+	// along the direction in which the image is scaled down most.
+	// FilterAnisotropic is useful for rotated or perspective-skewed images, which look blurry or aliased with FilterLinear.
+	// The maximum anisotropy is 16. If the graphics driver doesn't support anisotropic filtering, FilterAnisotropic works like FilterLinear.
+	//
+	// As the samples are taken by the graphics driver, the samples near the edges of the source image might be taken from outside the image.
+	FilterAnisotropic Filter = Filter(builtinshader.FilterAnisotropic)
+
+	// FilterBicubic represents bicubic filter with the Catmull-Rom spline.
+	//
+	// FilterBicubic takes 4x4 samples per pixel, and results in sharper images than FilterLinear.
+	// FilterBicubic is useful for scaled photos and backgrounds.
+	//
+	// With AddressUnsafe, the samples might be taken from outside the source region.
+	FilterBicubic Filter = Filter(builtinshader.FilterBicubic)
+
+	// FilterPixelArt represents sharp bilinear filter for pixel art.
+	//
+	// FilterPixelArt is the same as FilterNearest at integer scales, and blends only the pixels on the edges of texels otherwise.
+	// This keeps pixel art sharp without uneven texel sizes of FilterNearest at non-integer scales.
+	// When the image is scaled down, FilterPixelArt is the same as FilterLinear.
+	FilterPixelArt Filter = Filter(builtinshader.FilterPixelArt)
+)
+
+// ScreenFilter represents the type of filter to render the offscreen onto the final screen.
+//
+// A screen filter is not used when the game implements FinalScreenDrawer.
+type ScreenFilter int
+
+const (
+	// ScreenFilterDefault represents the default screen filter.
+	//
+	// ScreenFilterDefault is a box filter from game to display resolution.
+	// ScreenFilterDefault keeps pixels sharp and their sizes uniform, and blends only the pixels on the edges of texels.
+	ScreenFilterDefault ScreenFilter = iota
+
+	// ScreenFilterBicubic represents bicubic filter with the Catmull-Rom spline.
+	//
+	// ScreenFilterBicubic is smoother than ScreenFilterDefault.
+	ScreenFilterBicubic
+
+	// ScreenFilterLanczos represents Lanczos filter with 4x4 samples.
+	//
+	// ScreenFilterLanczos is sharper than ScreenFilterBicubic, but might cause ringing artifacts around edges.
+	ScreenFilterLanczos
+
+	// ScreenFilterIntegerNearest represents nearest filter with integer scales.
+	//
+	// With ScreenFilterIntegerNearest, the offscreen is scaled by the largest integer scale that fits the screen,
+	// and the rest of the screen is filled with black.
+	// This is useful for pixel art games that require all the pixels to have the same size.
+	//
+	// If the screen is smaller than the offscreen, the offscreen is scaled down with linear filter.
+	ScreenFilterIntegerNearest
 )
 
+func (s ScreenFilter) builtinScreenFilter() builtinshader.ScreenFilter {
+	switch s {
+	case ScreenFilterBicubic:
+		return builtinshader.ScreenFilterBicubic
+	case ScreenFilterLanczos:
+		return builtinshader.ScreenFilterLanczos
+	default:
+		// With ScreenFilterIntegerNearest, the box filter is the same as the nearest filter as the scale is an integer.
+		return builtinshader.ScreenFilterBox
+	}
+}
+
 // GraphicsLibrary represents graphics libraries supported by the engine.
 type GraphicsLibrary int
 
@@ -73,6 +144,66 @@ func ReadDebugInfo(d *DebugInfo) {
 	d.GraphicsLibrary = GraphicsLibrary(ui.Get().GraphicsLibrary())
 }
 
+// GraphicsFeature represents an optional feature of the graphics library.
+type GraphicsFeature int
+
+const (
+	// GraphicsFeatureDepth represents depth buffers (NewImageOptions.Depth).
+	//
+	// When depth buffers are not available, DepthTest and DepthWrite are ignored,
+	// and the triangles are rendered in the order of the draw calls.
+	GraphicsFeatureDepth GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureDepth)
+
+	// GraphicsFeatureStencil represents stencil buffers (NewImageOptions.Stencil).
+	//
+	// When stencil buffers are not available, StencilFunc and StencilOp are ignored,
+	// and all the fragments are rendered as if the stencil test always passed.
+	GraphicsFeatureStencil GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureStencil)
+
+	// GraphicsFeatureFloatFormats represents FormatRGBA16F and FormatRGBA32F.
+	//
+	// When floating point formats are not available, an image with such a format keeps 8-bit values instead,
+	// and color values out of [0, 1] are clamped when rendered.
+	GraphicsFeatureFloatFormats GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureFloatFormats)
+
+	// GraphicsFeatureRGBA16 represents FormatRGBA16.
+	//
+	// When FormatRGBA16 is not available, an image with FormatRGBA16 keeps 8-bit values instead.
+	// WritePixels16, ReadPixels16 and RGBA64At still work, but the lower 8 bits of each value are lost.
+	GraphicsFeatureRGBA16 GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureRGBA16)
+
+	// GraphicsFeatureMultisampling represents multisampled images (NewImageOptions.Samples).
+	//
+	// Multisampling is available only with OpenGL (including OpenGL ES and WebGL) when the device supports multiple samples.
+	// Multisampling is not available with Metal nor DirectX.
+	//
+	// When multisampling is not available, a multisampled image is rendered with one sample per pixel.
+	GraphicsFeatureMultisampling GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureMultisampling)
+
+	// GraphicsFeatureDualSourceBlending represents dual-source blending (BlendFactorSourceColor1 and so on).
+	//
+	// When dual-source blending is not available, only the first color of a shader is rendered,
+	// and the secondary source factors work as the corresponding primary source factors, e.g.,
+	// BlendFactorSourceColor1 works as BlendFactorSourceColor.
+	GraphicsFeatureDualSourceBlending GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureDualSourceBlending)
+
+	// GraphicsFeatureCompute represents compute kernels (NewComputeShader and Image.DispatchCompute).
+	//
+	// Compute kernels are available with OpenGL 4.3 or later (not OpenGL ES nor WebGL), Metal,
+	// and DirectX 11 with the feature level 11.0.
+	// When compute kernels are not available, DispatchCompute does nothing.
+	GraphicsFeatureCompute GraphicsFeature = GraphicsFeature(graphicsdriver.FeatureCompute)
+)
+
+// IsGraphicsFeatureAvailable reports whether the optional feature is available with the current graphics library.
+//
+// IsGraphicsFeatureAvailable returns false before the main loop starts.
+//
+// IsGraphicsFeatureAvailable is concurrent-safe.
+func IsGraphicsFeatureAvailable(feature GraphicsFeature) bool {
+	return atlas.IsFeatureAvailable(graphicsdriver.Feature(feature))
+}
+
 // ColorSpace represents the color space of the screen.
 type ColorSpace int
 
@@ -84,5 +215,43 @@ const (
 	ColorSpaceSRGB
 
 	// ColorSpaceDisplayP3 represents the Display P3 color space (https://en.wikipedia.org/wiki/DCI-P3).
+	//
+	// ColorSpaceDisplayP3 is available only with Metal (macOS, and iOS 16.0 or later) and WebGL so far.
 	ColorSpaceDisplayP3
+
+	// ColorSpaceLinearSRGB represents the sRGB color space where the rendering to the screen is gamma-correct.
+	//
+	// With ColorSpaceLinearSRGB, the screen image passed to Game's Draw has FormatSRGBA8.
+	// Filtering and alpha blending on the screen image happen in the linear space,
+	// and the result is encoded to sRGB when it is shown.
+	// Source images should also have FormatSRGBA8 so that their colors are decoded to the linear space when sampled.
+	// If the game implements FinalScreenDrawer, the game is responsible to encode the colors to sRGB at DrawFinalScreen.
+	//
+	// ColorSpaceLinearSRGB is available only with OpenGL (including WebGL 2) so far.
+	ColorSpaceLinearSRGB
 )
+
+// ScreenColorSpace returns the color space actually used for the screen.
+//
+// The result might differ from RunGameOptions's ColorSpace when the environment doesn't support the requested color space.
+// For example, Display P3 is not available with DirectX, and then ScreenColorSpace returns ColorSpaceSRGB.
+//
+// ScreenColorSpace returns ColorSpaceDefault when the color space is unknown, or before the game starts.
+//
+// ScreenColorSpace is concurrent-safe.
+func ScreenColorSpace() ColorSpace {
+	return ColorSpace(ui.Get().ScreenColorSpace())
+}
+
+func (c ColorSpace) internalColorSpace() graphicsdriver.ColorSpace {
+	switch c {
+	case ColorSpaceDefault:
+		return graphicsdriver.ColorSpaceDefault
+	case ColorSpaceSRGB, ColorSpaceLinearSRGB:
+		return graphicsdriver.ColorSpaceSRGB
+	case ColorSpaceDisplayP3:
+		return graphicsdriver.ColorSpaceDisplayP3
+	default:
+		return graphicsdriver.ColorSpaceDefault
+	}
+}
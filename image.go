@@ -50,6 +50,22 @@ type Image struct {
 	// tmpUniforms must not be reused until ui.Image.Draw* is called.
 	tmpUniforms []uint32
 
+	// mipmapPolicy is the policy of mipmaps when the image is used as a rendering source.
+	// mipmapPolicy is valid only for an original image, not a sub-image.
+	mipmapPolicy MipmapPolicy
+
+	// depth reports whether the image has a depth buffer.
+	// depth is valid only for an original image, not a sub-image.
+	depth bool
+
+	// stencil reports whether the image has a stencil buffer.
+	// stencil is valid only for an original image, not a sub-image.
+	stencil bool
+
+	// format is the pixel format of the image.
+	// format is valid only for an original image, not a sub-image.
+	format Format
+
 	// Do not add a 'buffering' member that are resolved lazily.
 	// This tends to forget resolving the buffer easily (#2362).
 }
@@ -101,13 +117,74 @@ func (i *Image) Fill(clr color.Color) {
 	i.image.Fill(crf, cgf, cbf, caf, i.adjustedBounds())
 }
 
-func canSkipMipmap(geom GeoM, filter builtinshader.Filter) bool {
-	if filter != builtinshader.FilterLinear {
+// MipmapPolicy represents a policy of mipmaps for an image used as a rendering source.
+type MipmapPolicy int
+
+const (
+	// MipmapAuto uses mipmaps when the image is scaled down with a filter other than FilterNearest.
+	MipmapAuto MipmapPolicy = iota
+
+	// MipmapOff never uses mipmaps. This saves GPU memory for mipmaps.
+	MipmapOff
+
+	// MipmapForce uses mipmaps whenever the image is scaled down, regardless of the filter.
+	MipmapForce
+)
+
+// SetMipmapPolicy sets the policy of mipmaps when the image is used as a rendering source.
+//
+// The policy is shared among the image and its sub-images.
+//
+// The default policy is MipmapAuto.
+func (i *Image) SetMipmapPolicy(policy MipmapPolicy) {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
+	}
+
+	orig := i
+	if i.isSubImage() {
+		orig = i.original
+	}
+	orig.mipmapPolicy = policy
+
+	if policy == MipmapOff {
+		i.image.DeallocateMipmaps()
+	}
+}
+
+// MipmapPolicy returns the policy of mipmaps when the image is used as a rendering source.
+func (i *Image) MipmapPolicy() MipmapPolicy {
+	if i.isSubImage() {
+		return i.original.mipmapPolicy
+	}
+	return i.mipmapPolicy
+}
+
+func (i *Image) canSkipMipmap(geom GeoM, filter builtinshader.Filter) bool {
+	switch i.MipmapPolicy() {
+	case MipmapOff:
+		return true
+	case MipmapForce:
+		return false
+	}
+	if filter == builtinshader.FilterNearest {
 		return true
 	}
 	return geom.det2x2() >= 0.999
 }
 
+func (i *Image) canSkipMipmapForTriangles(filter builtinshader.Filter) bool {
+	switch i.MipmapPolicy() {
+	case MipmapOff:
+		return true
+	case MipmapForce:
+		return false
+	}
+	return filter == builtinshader.FilterNearest
+}
+
 // DrawImageOptions represents options for DrawImage.
 type DrawImageOptions struct {
 	// GeoM is a geometry matrix to draw.
@@ -143,6 +220,36 @@ type DrawImageOptions struct {
 	// Filter is a type of texture filter.
 	// The default (zero) value is FilterNearest.
 	Filter Filter
+
+	// Address is a sampler address mode.
+	// Address affects the texels sampled outside of the image by filters like FilterLinear at the image's edges.
+	// The default (zero) value is AddressUnsafe.
+	Address Address
+
+	// AddressX and AddressY are sampler address modes for the X and Y axes.
+	// AddressX and AddressY are used only when Address is AddressUnsafe.
+	// The default (zero) values are AddressUnsafe.
+	AddressX Address
+	AddressY Address
+
+	// GammaCorrect indicates whether the filter blends the source colors in the linear space.
+	// When GammaCorrect is true, the source colors are regarded as sRGB-encoded, and are decoded to the linear space
+	// before being blended and encoded to sRGB again after that.
+	// This avoids dark fringes that appear when bright texels are blended with dark texels.
+	//
+	// GammaCorrect doesn't affect FilterNearest.
+	// GammaCorrect should not be used with a source image with FormatSRGBA8, whose colors are already decoded when sampled.
+	//
+	// The default (zero) value is false.
+	GammaCorrect bool
+
+	// ClipRect is a clipping rectangle in the destination image's coordinate.
+	// Only the pixels in ClipRect are rendered.
+	// ClipRect is applied as a scissor rectangle, so clipping doesn't need an extra render target like a sub-image.
+	// If ClipRect is empty, e.g., ClipRect doesn't overlap with the destination image, nothing is rendered.
+	//
+	// The default (zero) value is nil, which means that no clipping is applied.
+	ClipRect *image.Rectangle
 }
 
 // adjustPosition converts the position in the *ebiten.Image coordinate to the *ui.Image coordinate.
@@ -181,6 +288,20 @@ func (i *Image) adjustedBounds() image.Rectangle {
 	return image.Rect(x, y, x+b.Dx(), y+b.Dy())
 }
 
+// clippedBounds returns adjustedBounds clipped by the given rectangle in the *ebiten.Image coordinate.
+// If clip is nil, clippedBounds returns adjustedBounds as it is.
+func (i *Image) clippedBounds(clip *image.Rectangle) image.Rectangle {
+	if clip == nil {
+		return i.adjustedBounds()
+	}
+	b := i.Bounds().Intersect(*clip)
+	if b.Empty() {
+		return image.Rectangle{}
+	}
+	x, y := i.adjustPosition(b.Min.X, b.Min.Y)
+	return image.Rect(x, y, x+b.Dx(), y+b.Dy())
+}
+
 // DrawImage draws the given image on the image i.
 //
 // DrawImage accepts the options. For details, see the document of
@@ -234,6 +355,14 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) {
 	} else {
 		blend = options.CompositeMode.blend().internalBlend()
 	}
+	if blend.IsDualSource() {
+		panic("ebiten: dual-source blend factors are not available at DrawImage; use a shader returning two colors instead")
+	}
+
+	dstRegion := i.clippedBounds(options.ClipRect)
+	if dstRegion.Empty() {
+		return
+	}
 	filter := builtinshader.Filter(options.Filter)
 
 	geoM := options.GeoM
@@ -254,7 +383,8 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) {
 	srcs := [graphics.ShaderSrcImageCount]*ui.Image{img.image}
 
 	useColorM := !colorm.IsIdentity()
-	shader := builtinShader(filter, builtinshader.AddressUnsafe, useColorM)
+	addressX, addressY := addressesPerAxis(options.Address, options.AddressX, options.AddressY)
+	shader := builtinShader(filter, addressX, addressY, useColorM, options.GammaCorrect)
 	i.tmpUniforms = i.tmpUniforms[:0]
 	if useColorM {
 		var body [16]float32
@@ -266,7 +396,7 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) {
 		})
 	}
 
-	i.image.DrawTriangles(srcs, vs, is, blend, i.adjustedBounds(), [graphics.ShaderSrcImageCount]image.Rectangle{img.adjustedBounds()}, shader.shader, i.tmpUniforms, graphicsdriver.FillRuleFillAll, canSkipMipmap(geoM, filter), false)
+	i.image.DrawTriangles(srcs, vs, is, blend, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{img.adjustedBounds()}, shader.shader, i.tmpUniforms, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, img.canSkipMipmap(geoM, filter), false)
 }
 
 // Vertex represents a vertex passed to DrawTriangles.
@@ -305,6 +435,20 @@ type Vertex struct {
 	Custom1 float32
 	Custom2 float32
 	Custom3 float32
+
+	// Custom4/Custom5/Custom6/Custom7 represents more general-purpose values passed to the shader.
+	// In order to use them, Fragment must have one more vec4 argument after the vec4 argument for Custom0-Custom3:
+	//
+	//	func Fragment(dstPos vec4, srcPos vec2, color vec4, custom0 vec4, custom1 vec4) vec4
+	//
+	// Like Custom0-Custom3, these values are interpolated linearly and independently of each other.
+	//
+	// These values are valid only when DrawTrianglesShader is used.
+	// In other cases, these values are ignored.
+	Custom4 float32
+	Custom5 float32
+	Custom6 float32
+	Custom7 float32
 }
 
 var _ [0]byte = [unsafe.Sizeof(Vertex{}) - unsafe.Sizeof(float32(0))*graphics.VertexFloatCount]byte{}
@@ -321,8 +465,21 @@ const (
 
 	// AddressRepeat means that texture coordinates wrap to the other side of the texture.
 	AddressRepeat Address = Address(builtinshader.AddressRepeat)
+
+	// AddressMirrorRepeat means that texture coordinates wrap to the other side of the texture with mirroring.
+	// The texture is mirrored at every other repetition.
+	AddressMirrorRepeat Address = Address(builtinshader.AddressMirrorRepeat)
 )
 
+// addressesPerAxis returns the address modes for the X and Y axes.
+// If address is not AddressUnsafe, address is used for both axes.
+func addressesPerAxis(address, addressX, addressY Address) (builtinshader.Address, builtinshader.Address) {
+	if address != AddressUnsafe {
+		return builtinshader.Address(address), builtinshader.Address(address)
+	}
+	return builtinshader.Address(addressX), builtinshader.Address(addressY)
+}
+
 // FillRule is the rule whether an overlapped region is rendered with DrawTriangles(Shader).
 type FillRule int
 
@@ -404,6 +561,24 @@ type DrawTrianglesOptions struct {
 	// The default (zero) value is AddressUnsafe.
 	Address Address
 
+	// AddressX and AddressY are sampler address modes for the X and Y axes.
+	// AddressX and AddressY are used only when Address is AddressUnsafe.
+	// For example, AddressX = AddressRepeat and AddressY = AddressClampToZero repeat the image only horizontally.
+	// The default (zero) values are AddressUnsafe.
+	AddressX Address
+	AddressY Address
+
+	// GammaCorrect indicates whether the filter blends the source colors in the linear space.
+	// When GammaCorrect is true, the source colors are regarded as sRGB-encoded, and are decoded to the linear space
+	// before being blended and encoded to sRGB again after that.
+	// This avoids dark fringes that appear when bright texels are blended with dark texels.
+	//
+	// GammaCorrect doesn't affect FilterNearest.
+	// GammaCorrect should not be used with a source image with FormatSRGBA8, whose colors are already decoded when sampled.
+	//
+	// The default (zero) value is false.
+	GammaCorrect bool
+
 	// FillRule indicates the rule how an overlapped region is rendered.
 	//
 	// The rules FillRuleNonZero and FillRuleEvenOdd are useful when you want to render a complex polygon.
@@ -413,6 +588,41 @@ type DrawTrianglesOptions struct {
 	// The default (zero) value is FillRuleFillAll.
 	FillRule FillRule
 
+	// DepthTest indicates whether a fragment is rendered only when its depth value is less than the value in the depth buffer.
+	// The depth value of a vertex is Custom0 of the vertex, and must be in [0, 1]. 0 is the nearest and 1 is the farthest.
+	//
+	// DepthTest and DepthWrite are available only when the destination image is created with NewImageOptions.Depth.
+	// DepthTest and DepthWrite cannot be used with AntiAlias or FillRules other than FillRuleFillAll.
+	// DepthTest and DepthWrite are ignored when GraphicsFeatureDepth is not available.
+	//
+	// The default (zero) value is false.
+	DepthTest bool
+
+	// DepthWrite indicates whether the depth values of the rendered fragments are written to the depth buffer.
+	//
+	// The default (zero) value is false.
+	DepthWrite bool
+
+	// StencilFunc is a comparison function for the stencil test.
+	// A fragment is rendered only when the comparison between StencilRef and the value in the stencil buffer is true.
+	//
+	// StencilFunc and StencilOp are available only when the destination image is created with NewImageOptions.Stencil.
+	// StencilFunc and StencilOp cannot be used with AntiAlias or FillRules other than FillRuleFillAll.
+	// StencilFunc and StencilOp are ignored when GraphicsFeatureStencil is not available.
+	//
+	// The default (zero) value is StencilFuncAlways.
+	StencilFunc StencilFunc
+
+	// StencilOp is an operation to update the stencil buffer when a fragment passes the stencil test.
+	//
+	// The default (zero) value is StencilOpKeep.
+	StencilOp StencilOp
+
+	// StencilRef is the reference value for the stencil test and StencilOpReplace.
+	//
+	// The default (zero) value is 0.
+	StencilRef uint8
+
 	// AntiAlias indicates whether the rendering uses anti-alias or not.
 	// AntiAlias is useful especially when you pass vertices from the vector package.
 	//
@@ -421,6 +631,14 @@ type DrawTrianglesOptions struct {
 	//
 	// The default (zero) value is false.
 	AntiAlias bool
+
+	// ClipRect is a clipping rectangle in the destination image's coordinate.
+	// Only the pixels in ClipRect are rendered.
+	// ClipRect is applied as a scissor rectangle, so clipping doesn't need an extra render target like a sub-image.
+	// If ClipRect is empty, e.g., ClipRect doesn't overlap with the destination image, nothing is rendered.
+	//
+	// The default (zero) value is nil, which means that no clipping is applied.
+	ClipRect *image.Rectangle
 }
 
 // MaxIndicesCount is the maximum number of indices for DrawTriangles and DrawTrianglesShader.
@@ -476,15 +694,48 @@ func (i *Image) DrawTriangles(vertices []Vertex, indices []uint16, img *Image, o
 		// The last part cannot be specified by indices. Just omit them.
 		vertices = vertices[:graphicscommand.MaxVertexCount]
 	}
-	if len(indices)%3 != 0 {
-		panic("ebiten: len(indices) % 3 must be 0")
+	validateIndices(indices, len(vertices))
+
+	i.drawTriangles(vertices, i.copyIndices16(indices), img, options)
+}
+
+// DrawTriangles32 draws triangles with the specified vertices and their indices.
+//
+// DrawTriangles32 works in the same way as DrawTriangles, but accepts uint32 indices.
+// This is useful for a mesh with more than 65536 vertices, which cannot be specified by uint16 indices.
+//
+// If len(vertices) is more than MaxVertexCount, the exceeding part is ignored.
+//
+// If len(indices) is not multiple of 3, DrawTriangles32 panics.
+//
+// If a value in indices is out of range of vertices, or not less than MaxVertexCount, DrawTriangles32 panics.
+//
+// When the given image is disposed, DrawTriangles32 panics.
+//
+// When the image i is disposed, DrawTriangles32 does nothing.
+func (i *Image) DrawTriangles32(vertices []Vertex, indices []uint32, img *Image, options *DrawTrianglesOptions) {
+	i.copyCheck()
+
+	if img != nil && img.isDisposed() {
+		panic("ebiten: the given image to DrawTriangles32 must not be disposed")
 	}
-	for i, idx := range indices {
-		if int(idx) >= len(vertices) {
-			panic(fmt.Sprintf("ebiten: indices[%d] must be less than len(vertices) (%d) but was %d", i, len(vertices), idx))
-		}
+	if i.isDisposed() {
+		return
 	}
 
+	if len(vertices) > graphicscommand.MaxVertexCount {
+		// The last part cannot be specified by indices. Just omit them.
+		vertices = vertices[:graphicscommand.MaxVertexCount]
+	}
+	validateIndices(indices, len(vertices))
+
+	i.drawTriangles(vertices, i.copyIndices32(indices), img, options)
+}
+
+// drawTriangles draws triangles without validating the vertices and the indices.
+//
+// is must be the internal indices converted by copyIndices16 or copyIndices32.
+func (i *Image) drawTriangles(vertices []Vertex, is []uint32, img *Image, options *DrawTrianglesOptions) {
 	if options == nil {
 		options = &DrawTrianglesOptions{}
 	}
@@ -495,8 +746,11 @@ func (i *Image) DrawTriangles(vertices []Vertex, indices []uint16, img *Image, o
 	} else {
 		blend = options.CompositeMode.blend().internalBlend()
 	}
+	if blend.IsDualSource() {
+		panic("ebiten: dual-source blend factors are not available at DrawTriangles; use a shader returning two colors instead")
+	}
 
-	address := builtinshader.Address(options.Address)
+	addressX, addressY := addressesPerAxis(options.Address, options.AddressX, options.AddressY)
 	filter := builtinshader.Filter(options.Filter)
 
 	colorm, cr, cg, cb, ca := colorMToScale(options.ColorM.affineColorM())
@@ -515,6 +769,7 @@ func (i *Image) DrawTriangles(vertices []Vertex, indices []uint16, img *Image, o
 			vs[i*graphics.VertexFloatCount+5] = v.ColorG * v.ColorA * cg
 			vs[i*graphics.VertexFloatCount+6] = v.ColorB * v.ColorA * cb
 			vs[i*graphics.VertexFloatCount+7] = v.ColorA * ca
+			vs[i*graphics.VertexFloatCount+8] = v.Custom0
 		}
 	} else {
 		for i, v := range vertices {
@@ -528,17 +783,23 @@ func (i *Image) DrawTriangles(vertices []Vertex, indices []uint16, img *Image, o
 			vs[i*graphics.VertexFloatCount+5] = v.ColorG * cg
 			vs[i*graphics.VertexFloatCount+6] = v.ColorB * cb
 			vs[i*graphics.VertexFloatCount+7] = v.ColorA * ca
+			vs[i*graphics.VertexFloatCount+8] = v.Custom0
 		}
 	}
-	is := i.ensureTmpIndices(len(indices))
-	for i := range is {
-		is[i] = uint32(indices[i])
+	i.submitTriangles(vs, is, img, blend, filter, addressX, addressY, colorm, options)
+}
+
+// submitTriangles draws the triangles with the given internal vertices and indices with the builtin shader.
+func (i *Image) submitTriangles(vs []float32, is []uint32, img *Image, blend graphicsdriver.Blend, filter builtinshader.Filter, addressX, addressY builtinshader.Address, colorm affine.ColorM, options *DrawTrianglesOptions) {
+	dstRegion := i.clippedBounds(options.ClipRect)
+	if dstRegion.Empty() {
+		return
 	}
 
 	srcs := [graphics.ShaderSrcImageCount]*ui.Image{img.image}
 
 	useColorM := !colorm.IsIdentity()
-	shader := builtinShader(filter, address, useColorM)
+	shader := builtinShader(filter, addressX, addressY, useColorM, options.GammaCorrect)
 	i.tmpUniforms = i.tmpUniforms[:0]
 	if useColorM {
 		var body [16]float32
@@ -550,7 +811,7 @@ func (i *Image) DrawTriangles(vertices []Vertex, indices []uint16, img *Image, o
 		})
 	}
 
-	i.image.DrawTriangles(srcs, vs, is, blend, i.adjustedBounds(), [graphics.ShaderSrcImageCount]image.Rectangle{img.adjustedBounds()}, shader.shader, i.tmpUniforms, graphicsdriver.FillRule(options.FillRule), filter != builtinshader.FilterLinear, options.AntiAlias)
+	i.image.DrawTriangles(srcs, vs, is, blend, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{img.adjustedBounds()}, shader.shader, i.tmpUniforms, graphicsdriver.FillRule(options.FillRule), i.depthMode(options.DepthTest, options.DepthWrite, options.FillRule, options.AntiAlias), i.stencilState(options.StencilFunc, options.StencilOp, options.StencilRef, options.FillRule, options.AntiAlias), img.canSkipMipmapForTriangles(filter), options.AntiAlias)
 }
 
 // DrawTrianglesShaderOptions represents options for DrawTrianglesShader.
@@ -572,6 +833,15 @@ type DrawTrianglesShaderOptions struct {
 	// If the uniform variable type is an array, a vector or a matrix,
 	// you have to specify linearly flattened values as a slice or an array.
 	// For example, if the uniform variable type is [4]vec4, the length will be 16.
+	// The values of an array variable can also be specified as a slice or an array of arrays.
+	// For example, if the uniform variable type is [64]mat4, the value can be [][16]float32 or [64][16]float32
+	// where each matrix is in column-major order.
+	// []float32 and [][16]float32 values are copied without reflection, and are efficient for large arrays like bone matrices.
+	//
+	// If the uniform variable type is a struct, the value must be a struct or a map[string]any
+	// whose fields or keys are the struct's field names.
+	// If the uniform variable type is an array of a struct, the value must be a slice or an array of them.
+	// A missing field is treated as if zero values are specified.
 	//
 	// If a uniform variable's name doesn't exist in Uniforms, this is treated as if zero values are specified.
 	Uniforms map[string]any
@@ -580,6 +850,11 @@ type DrawTrianglesShaderOptions struct {
 	// All the images' sizes must be the same.
 	Images [4]*Image
 
+	// ExtraImages is a set of the additional source images following Images.
+	// ExtraImages[i] is the source image i+4, e.g., ExtraImages[0] is read with imageSrc4At in a shader.
+	// All the images' sizes must be the same as Images'.
+	ExtraImages [4]*Image
+
 	// FillRule indicates the rule how an overlapped region is rendered.
 	//
 	// The rules FillRuleNonZero and FillRuleEvenOdd are useful when you want to render a complex polygon.
@@ -589,6 +864,42 @@ type DrawTrianglesShaderOptions struct {
 	// The default (zero) value is FillRuleFillAll.
 	FillRule FillRule
 
+	// DepthTest indicates whether a fragment is rendered only when its depth value is less than the value in the depth buffer.
+	// The depth value of a vertex is Custom0 of the vertex, and must be in [0, 1]. 0 is the nearest and 1 is the farthest.
+	// In a Kage shader, the interpolated depth value is available as dstPos.z.
+	//
+	// DepthTest and DepthWrite are available only when the destination image is created with NewImageOptions.Depth.
+	// DepthTest and DepthWrite cannot be used with AntiAlias or FillRules other than FillRuleFillAll.
+	// DepthTest and DepthWrite are ignored when GraphicsFeatureDepth is not available.
+	//
+	// The default (zero) value is false.
+	DepthTest bool
+
+	// DepthWrite indicates whether the depth values of the rendered fragments are written to the depth buffer.
+	//
+	// The default (zero) value is false.
+	DepthWrite bool
+
+	// StencilFunc is a comparison function for the stencil test.
+	// A fragment is rendered only when the comparison between StencilRef and the value in the stencil buffer is true.
+	//
+	// StencilFunc and StencilOp are available only when the destination image is created with NewImageOptions.Stencil.
+	// StencilFunc and StencilOp cannot be used with AntiAlias or FillRules other than FillRuleFillAll.
+	// StencilFunc and StencilOp are ignored when GraphicsFeatureStencil is not available.
+	//
+	// The default (zero) value is StencilFuncAlways.
+	StencilFunc StencilFunc
+
+	// StencilOp is an operation to update the stencil buffer when a fragment passes the stencil test.
+	//
+	// The default (zero) value is StencilOpKeep.
+	StencilOp StencilOp
+
+	// StencilRef is the reference value for the stencil test and StencilOpReplace.
+	//
+	// The default (zero) value is 0.
+	StencilRef uint8
+
 	// AntiAlias indicates whether the rendering uses anti-alias or not.
 	// AntiAlias is useful especially when you pass vertices from the vector package.
 	//
@@ -597,10 +908,24 @@ type DrawTrianglesShaderOptions struct {
 	//
 	// The default (zero) value is false.
 	AntiAlias bool
+
+	// AdditionalDstImages is a set of the destination images rendered at once with the destination image.
+	// AdditionalDstImages is used with a shader whose Fragment function returns a struct of multiple vec4 members.
+	// The i-th member is rendered to the destination image when i is 0, or to AdditionalDstImages[i-1] otherwise.
+	// The number of the non-nil images must be the number of the members minus 1, and nil must not precede a non-nil image.
+	//
+	// All the images must have the same bounds as the destination image, and must be different from the destination image and the source images.
+	// AdditionalDstImages cannot be used with AntiAlias, FillRules other than FillRuleFillAll, the depth tests, or the stencil tests.
+	//
+	// Multiple render targets are currently available only with OpenGL (including OpenGL ES and WebGL) and DirectX 11.
+	// If the graphics library doesn't support multiple render targets, e.g. Metal or DirectX 12,
+	// the triangles are rendered for each image one by one with the same result.
+	AdditionalDstImages [3]*Image
 }
 
 // Check the number of images.
-var _ [len(DrawTrianglesShaderOptions{}.Images) - graphics.ShaderSrcImageCount]struct{} = [0]struct{}{}
+var _ [len(DrawTrianglesShaderOptions{}.Images) + len(DrawTrianglesShaderOptions{}.ExtraImages) - graphics.ShaderSrcImageCount]struct{} = [0]struct{}{}
+var _ [len(DrawTrianglesShaderOptions{}.AdditionalDstImages) - (graphics.ShaderDstImageCount - 1)]struct{} = [0]struct{}{}
 
 // DrawTrianglesShader draws triangles with the specified vertices and their indices with the specified shader.
 //
@@ -641,15 +966,49 @@ func (i *Image) DrawTrianglesShader(vertices []Vertex, indices []uint16, shader
 		// The last part cannot be specified by indices. Just omit them.
 		vertices = vertices[:graphicscommand.MaxVertexCount]
 	}
-	if len(indices)%3 != 0 {
-		panic("ebiten: len(indices) % 3 must be 0")
+	validateIndices(indices, len(vertices))
+
+	i.drawTrianglesShader(vertices, i.copyIndices16(indices), shader, options)
+}
+
+// DrawTrianglesShader32 draws triangles with the specified vertices and their indices with the specified shader.
+//
+// DrawTrianglesShader32 works in the same way as DrawTrianglesShader, but accepts uint32 indices.
+// This is useful for a mesh with more than 65536 vertices, which cannot be specified by uint16 indices.
+//
+// If len(vertices) is more than MaxVertexCount, the exceeding part is ignored.
+//
+// If len(indices) is not multiple of 3, DrawTrianglesShader32 panics.
+//
+// If a value in indices is out of range of vertices, or not less than MaxVertexCount, DrawTrianglesShader32 panics.
+//
+// When a specified image is non-nil and is disposed, DrawTrianglesShader32 panics.
+//
+// When the image i is disposed, DrawTrianglesShader32 does nothing.
+func (i *Image) DrawTrianglesShader32(vertices []Vertex, indices []uint32, shader *Shader, options *DrawTrianglesShaderOptions) {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
 	}
-	for i, idx := range indices {
-		if int(idx) >= len(vertices) {
-			panic(fmt.Sprintf("ebiten: indices[%d] must be less than len(vertices) (%d) but was %d", i, len(vertices), idx))
-		}
+
+	if shader.isDisposed() {
+		panic("ebiten: the given shader to DrawTrianglesShader32 must not be disposed")
 	}
 
+	if len(vertices) > graphicscommand.MaxVertexCount {
+		// The last part cannot be specified by indices. Just omit them.
+		vertices = vertices[:graphicscommand.MaxVertexCount]
+	}
+	validateIndices(indices, len(vertices))
+
+	i.drawTrianglesShader(vertices, i.copyIndices32(indices), shader, options)
+}
+
+// drawTrianglesShader draws triangles with the shader without validating the vertices and the indices.
+//
+// is must be the internal indices converted by copyIndices16 or copyIndices32.
+func (i *Image) drawTrianglesShader(vertices []Vertex, is []uint32, shader *Shader, options *DrawTrianglesShaderOptions) {
 	if options == nil {
 		options = &DrawTrianglesShaderOptions{}
 	}
@@ -660,10 +1019,15 @@ func (i *Image) DrawTrianglesShader(vertices []Vertex, indices []uint16, shader
 	} else {
 		blend = options.CompositeMode.blend().internalBlend()
 	}
+	if blend.IsDualSource() && !shader.dualSource {
+		panic("ebiten: dual-source blend factors are available only with a shader returning two colors at DrawTrianglesShader")
+	}
+	srcs := sourceImages(options.Images, options.ExtraImages)
+	additionalDsts := i.additionalDstImages(shader, srcs, options)
 
 	vs := i.ensureTmpVertices(len(vertices) * graphics.VertexFloatCount)
 	dst := i
-	src := options.Images[0]
+	src := srcs[0]
 	for i, v := range vertices {
 		dx, dy := dst.adjustPositionF32(v.DstX, v.DstY)
 		vs[i*graphics.VertexFloatCount] = dx
@@ -682,16 +1046,15 @@ func (i *Image) DrawTrianglesShader(vertices []Vertex, indices []uint16, shader
 		vs[i*graphics.VertexFloatCount+9] = v.Custom1
 		vs[i*graphics.VertexFloatCount+10] = v.Custom2
 		vs[i*graphics.VertexFloatCount+11] = v.Custom3
-	}
-
-	is := i.ensureTmpIndices(len(indices))
-	for i := range is {
-		is[i] = uint32(indices[i])
+		vs[i*graphics.VertexFloatCount+12] = v.Custom4
+		vs[i*graphics.VertexFloatCount+13] = v.Custom5
+		vs[i*graphics.VertexFloatCount+14] = v.Custom6
+		vs[i*graphics.VertexFloatCount+15] = v.Custom7
 	}
 
 	var imgs [graphics.ShaderSrcImageCount]*ui.Image
 	var imgSize image.Point
-	for i, img := range options.Images {
+	for i, img := range srcs {
 		if img == nil {
 			continue
 		}
@@ -712,17 +1075,74 @@ func (i *Image) DrawTrianglesShader(vertices []Vertex, indices []uint16, shader
 	}
 
 	var srcRegions [graphics.ShaderSrcImageCount]image.Rectangle
-	for i, img := range options.Images {
+	for i, img := range srcs {
 		if img == nil {
 			continue
 		}
 		srcRegions[i] = img.adjustedBounds()
 	}
 
+	shader = shader.variant(options.Uniforms)
 	i.tmpUniforms = i.tmpUniforms[:0]
 	i.tmpUniforms = shader.appendUniforms(i.tmpUniforms, options.Uniforms)
 
-	i.image.DrawTriangles(imgs, vs, is, blend, i.adjustedBounds(), srcRegions, shader.shader, i.tmpUniforms, graphicsdriver.FillRule(options.FillRule), true, options.AntiAlias)
+	// Debug before drawing as drawing might modify the vertices.
+	shader.debug(i, imgs, vs, is, srcRegions, i.tmpUniforms)
+	if additionalDsts[0] != nil {
+		i.image.DrawTrianglesToMultipleTargets(additionalDsts, imgs, vs, is, blend, i.adjustedBounds(), srcRegions, shader.shader, i.tmpUniforms, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, true)
+		return
+	}
+	i.image.DrawTriangles(imgs, vs, is, blend, i.adjustedBounds(), srcRegions, shader.shader, i.tmpUniforms, graphicsdriver.FillRule(options.FillRule), i.depthMode(options.DepthTest, options.DepthWrite, options.FillRule, options.AntiAlias), i.stencilState(options.StencilFunc, options.StencilOp, options.StencilRef, options.FillRule, options.AntiAlias), true, options.AntiAlias)
+}
+
+// additionalDstImages validates options.AdditionalDstImages and returns their internal images.
+func (i *Image) additionalDstImages(shader *Shader, srcs [graphics.ShaderSrcImageCount]*Image, options *DrawTrianglesShaderOptions) [graphics.ShaderDstImageCount - 1]*ui.Image {
+	var dsts [graphics.ShaderDstImageCount - 1]*ui.Image
+	count := 1
+	for k, img := range options.AdditionalDstImages {
+		if img == nil {
+			continue
+		}
+		if k > 0 && options.AdditionalDstImages[k-1] == nil {
+			panic("ebiten: nil must not precede a non-nil image in AdditionalDstImages")
+		}
+		if img.isDisposed() {
+			panic("ebiten: the given additional destination image to DrawTrianglesShader must not be disposed")
+		}
+		if img.Bounds() != i.Bounds() || img.adjustedBounds() != i.adjustedBounds() {
+			panic("ebiten: all the additional destination images must have the same bounds as the destination image")
+		}
+		if img.image == i.image {
+			panic("ebiten: the additional destination images must be different from the destination image")
+		}
+		for _, d := range dsts[:k] {
+			if img.image == d {
+				panic("ebiten: the additional destination images must be different from each other")
+			}
+		}
+		for _, src := range srcs {
+			if src != nil && img.image == src.image {
+				panic("ebiten: the additional destination images must be different from the source images")
+			}
+		}
+		dsts[k] = img.image
+		count++
+	}
+	if count != shader.renderTargetCount {
+		panic(fmt.Sprintf("ebiten: the shader renders to %d images but %d images are given at DrawTrianglesShader", shader.renderTargetCount, count))
+	}
+	if count > 1 {
+		if options.AntiAlias {
+			panic("ebiten: AdditionalDstImages cannot be used with AntiAlias")
+		}
+		if options.FillRule != FillRuleFillAll {
+			panic("ebiten: AdditionalDstImages cannot be used with FillRules other than FillRuleFillAll")
+		}
+		if options.DepthTest || options.DepthWrite || options.StencilFunc != StencilFuncAlways || options.StencilOp != StencilOpKeep {
+			panic("ebiten: AdditionalDstImages cannot be used with the depth tests or the stencil tests")
+		}
+	}
+	return dsts
 }
 
 // DrawRectShaderOptions represents options for DrawRectShader.
@@ -753,6 +1173,15 @@ type DrawRectShaderOptions struct {
 	// If the uniform variable type is an array, a vector or a matrix,
 	// you have to specify linearly flattened values as a slice or an array.
 	// For example, if the uniform variable type is [4]vec4, the length will be 16.
+	// The values of an array variable can also be specified as a slice or an array of arrays.
+	// For example, if the uniform variable type is [64]mat4, the value can be [][16]float32 or [64][16]float32
+	// where each matrix is in column-major order.
+	// []float32 and [][16]float32 values are copied without reflection, and are efficient for large arrays like bone matrices.
+	//
+	// If the uniform variable type is a struct, the value must be a struct or a map[string]any
+	// whose fields or keys are the struct's field names.
+	// If the uniform variable type is an array of a struct, the value must be a slice or an array of them.
+	// A missing field is treated as if zero values are specified.
 	//
 	// If a uniform variable's name doesn't exist in Uniforms, this is treated as if zero values are specified.
 	Uniforms map[string]any
@@ -760,10 +1189,23 @@ type DrawRectShaderOptions struct {
 	// Images is a set of the source images.
 	// All the images' sizes must be the same.
 	Images [4]*Image
+
+	// ExtraImages is a set of the additional source images following Images.
+	// ExtraImages[i] is the source image i+4, e.g., ExtraImages[0] is read with imageSrc4At in a shader.
+	// All the images' sizes must be the same as Images'.
+	ExtraImages [4]*Image
 }
 
 // Check the number of images.
-var _ [len(DrawRectShaderOptions{}.Images)]struct{} = [graphics.ShaderSrcImageCount]struct{}{}
+var _ [len(DrawRectShaderOptions{}.Images) + len(DrawRectShaderOptions{}.ExtraImages)]struct{} = [graphics.ShaderSrcImageCount]struct{}{}
+
+// sourceImages returns the source images for a shader, concatenating images and extraImages.
+func sourceImages(images, extraImages [4]*Image) [graphics.ShaderSrcImageCount]*Image {
+	var srcs [graphics.ShaderSrcImageCount]*Image
+	copy(srcs[:], images[:])
+	copy(srcs[len(images):], extraImages[:])
+	return srcs
+}
 
 // DrawRectShader draws a rectangle with the specified width and height with the specified shader.
 //
@@ -809,9 +1251,16 @@ func (i *Image) DrawRectShader(width, height int, shader *Shader, options *DrawR
 	} else {
 		blend = options.CompositeMode.blend().internalBlend()
 	}
+	if blend.IsDualSource() && !shader.dualSource {
+		panic("ebiten: dual-source blend factors are available only with a shader returning two colors at DrawRectShader")
+	}
+	if shader.renderTargetCount > 1 {
+		panic("ebiten: a shader rendering to multiple images is available only at DrawTrianglesShader with AdditionalDstImages")
+	}
 
+	srcs := sourceImages(options.Images, options.ExtraImages)
 	var imgs [graphics.ShaderSrcImageCount]*ui.Image
-	for i, img := range options.Images {
+	for i, img := range srcs {
 		if img == nil {
 			continue
 		}
@@ -825,7 +1274,7 @@ func (i *Image) DrawRectShader(width, height int, shader *Shader, options *DrawR
 	}
 
 	var srcRegions [graphics.ShaderSrcImageCount]image.Rectangle
-	for i, img := range options.Images {
+	for i, img := range srcs {
 		if img == nil {
 			if shader.unit == shaderir.Pixels && i == 0 {
 				// Give the source size as pixels only when the unit is pixels so that users can get the source size via imageSrc0Size (#2166).
@@ -852,10 +1301,13 @@ func (i *Image) DrawRectShader(width, height int, shader *Shader, options *DrawR
 		a, b, c, d, tx, ty, cr, cg, cb, ca)
 	is := graphics.QuadIndices()
 
+	shader = shader.variant(options.Uniforms)
 	i.tmpUniforms = i.tmpUniforms[:0]
 	i.tmpUniforms = shader.appendUniforms(i.tmpUniforms, options.Uniforms)
 
-	i.image.DrawTriangles(imgs, vs, is, blend, i.adjustedBounds(), srcRegions, shader.shader, i.tmpUniforms, graphicsdriver.FillRuleFillAll, true, false)
+	// Debug before drawing as drawing might modify the vertices.
+	shader.debug(i, imgs, vs, is, srcRegions, i.tmpUniforms)
+	i.image.DrawTriangles(imgs, vs, is, blend, i.adjustedBounds(), srcRegions, shader.shader, i.tmpUniforms, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, true, false)
 }
 
 // SubImage returns an image representing the portion of the image p visible through r.
@@ -945,9 +1397,100 @@ func (i *Image) ReadPixels(pixels []byte) {
 		return
 	}
 
+	if i.pixelFormat() == FormatRGBA16 {
+		pix16 := make([]uint16, len(pixels))
+		i.image.ReadPixels(uint16sToBytes(pix16), i.adjustedBounds())
+		for idx, v := range pix16 {
+			pixels[idx] = byte(v >> 8)
+		}
+		return
+	}
+
 	i.image.ReadPixels(pixels, i.adjustedBounds())
 }
 
+// ReadPixels16 reads the image's pixels from the image as 16-bit values.
+//
+// ReadPixels16 is the same as ReadPixels except that each channel is a 16-bit value.
+// With FormatRGBA16, ReadPixels16 reads the pixels without losing precision.
+// With the other formats, the values are converted from 8-bit values.
+//
+// len(pixels) must be 4 * (bounds width) * (bounds height).
+// If len(pixels) is not correct, ReadPixels16 panics.
+//
+// ReadPixels16 can't be called outside the main loop (ebiten.Run's updating function) starts.
+func (i *Image) ReadPixels16(pixels []uint16) {
+	b := i.Bounds()
+	if got, want := len(pixels), 4*b.Dx()*b.Dy(); got != want {
+		panic(fmt.Sprintf("ebiten: len(pixels) must be %d but %d at ReadPixels16", want, got))
+	}
+
+	if i.isDisposed() {
+		for i := range pixels {
+			pixels[i] = 0
+		}
+		return
+	}
+
+	if i.pixelFormat() == FormatRGBA16 {
+		i.image.ReadPixels(uint16sToBytes(pixels), i.adjustedBounds())
+		return
+	}
+
+	pix := make([]byte, len(pixels))
+	i.image.ReadPixels(pix, i.adjustedBounds())
+	for idx, v := range pix {
+		pixels[idx] = uint16(v) * 0x101
+	}
+}
+
+// ReadPixelsAsync starts reading the image's pixels without stalling the GPU pipeline.
+//
+// ReadPixelsAsync is the same as ReadPixels except that ReadPixelsAsync doesn't wait for the GPU to finish rendering.
+// This is useful to read the pixels every frame, e.g. for screenshots or color picking.
+//
+// callback is called after the pixels are written to pixels, at the beginning of a later frame on the same goroutine
+// as the game's Update. If reading the pixels fails, callback is called with the error.
+// pixels must not be accessed until callback is called.
+//
+// If the image is disposed, ReadPixelsAsync sets transparent colors and calls callback immediately.
+//
+// len(pixels) must be 4 * (bounds width) * (bounds height).
+// If len(pixels) is not correct, ReadPixelsAsync panics.
+//
+// The pixels are read asynchronously only with OpenGL (including OpenGL ES and WebGL) and DirectX 11.
+// With Metal and DirectX 12, the pixels are read synchronously when the rendering commands are flushed,
+// and callback is still called at a later frame.
+//
+// ReadPixelsAsync can't be called outside the main loop (ebiten.Run's updating function) starts.
+func (i *Image) ReadPixelsAsync(pixels []byte, callback func(err error)) {
+	b := i.Bounds()
+	if got, want := len(pixels), 4*b.Dx()*b.Dy(); got != want {
+		panic(fmt.Sprintf("ebiten: len(pixels) must be %d but %d at ReadPixelsAsync", want, got))
+	}
+
+	if i.isDisposed() {
+		for i := range pixels {
+			pixels[i] = 0
+		}
+		callback(nil)
+		return
+	}
+
+	if i.pixelFormat() == FormatRGBA16 {
+		pix16 := make([]uint16, len(pixels))
+		i.image.ReadPixelsAsync(uint16sToBytes(pix16), i.adjustedBounds(), func(err error) {
+			for idx, v := range pix16 {
+				pixels[idx] = byte(v >> 8)
+			}
+			callback(err)
+		})
+		return
+	}
+
+	i.image.ReadPixelsAsync(pixels, i.adjustedBounds(), callback)
+}
+
 // At returns the color of the image at (x, y).
 //
 // At implements the standard image.Image's At.
@@ -977,11 +1520,20 @@ func (i *Image) At(x, y int) color.Color {
 //
 // RGBA64At can't be called outside the main loop (ebiten.Run's updating function) starts.
 func (i *Image) RGBA64At(x, y int) color.RGBA64 {
+	if i.pixelFormat() == FormatRGBA16 {
+		r, g, b, a := i.at16(x, y)
+		return color.RGBA64{R: r, G: g, B: b, A: a}
+	}
 	r, g, b, a := i.at(x, y)
 	return color.RGBA64{R: uint16(r) * 0x101, G: uint16(g) * 0x101, B: uint16(b) * 0x101, A: uint16(a) * 0x101}
 }
 
 func (i *Image) at(x, y int) (r, g, b, a byte) {
+	if i.pixelFormat() == FormatRGBA16 {
+		r, g, b, a := i.at16(x, y)
+		return byte(r >> 8), byte(g >> 8), byte(b >> 8), byte(a >> 8)
+	}
+
 	if i.isDisposed() {
 		return 0, 0, 0, 0
 	}
@@ -995,6 +1547,21 @@ func (i *Image) at(x, y int) (r, g, b, a byte) {
 	return pix[0], pix[1], pix[2], pix[3]
 }
 
+// at16 returns the 16-bit color values at (x, y). at16 is available only for FormatRGBA16.
+func (i *Image) at16(x, y int) (r, g, b, a uint16) {
+	if i.isDisposed() {
+		return 0, 0, 0, 0
+	}
+	if !image.Pt(x, y).In(i.Bounds()) {
+		return 0, 0, 0, 0
+	}
+
+	x, y = i.adjustPosition(x, y)
+	var pix [4]uint16
+	i.image.ReadPixels(uint16sToBytes(pix[:]), image.Rect(x, y, x+1, y+1))
+	return pix[0], pix[1], pix[2], pix[3]
+}
+
 // Set sets the color at (x, y).
 //
 // Set implements the standard draw.Image's Set.
@@ -1017,6 +1584,10 @@ func (i *Image) Set(x, y int, clr color.Color) {
 
 	dx, dy := i.adjustPosition(x, y)
 	cr, cg, cb, ca := clr.RGBA()
+	if i.pixelFormat() == FormatRGBA16 {
+		i.image.WritePixels(uint16sToBytes([]uint16{uint16(cr), uint16(cg), uint16(cb), uint16(ca)}), image.Rect(dx, dy, dx+1, dy+1))
+		return
+	}
 	i.image.WritePixels([]byte{byte(cr >> 8), byte(cg >> 8), byte(cb >> 8), byte(ca >> 8)}, image.Rect(dx, dy, dx+1, dy+1))
 }
 
@@ -1067,6 +1638,115 @@ func (i *Image) Deallocate() {
 	i.image.Deallocate()
 }
 
+// PinToAtlas puts the image onto an internal automatic texture atlas immediately,
+// and keeps the image on the atlas whenever possible.
+//
+// A regular image is moved out of the atlas when it is used as a rendering destination,
+// and is put back onto the atlas only after it is used as a rendering source for a while.
+// PinToAtlas skips the waiting for the image, which is useful for an image that is rendered once or rarely
+// and then used as a source many times, like a pre-rendered sprite sheet.
+// For an offscreen image re-rendered frequently, use NewImageOptions.Unmanaged instead.
+//
+// PinToAtlas does nothing for an image that cannot be on an atlas, like an unmanaged image or a huge image.
+//
+// If the image is a sub-image, PinToAtlas pins the original image.
+//
+// If the image is disposed, PinToAtlas does nothing.
+func (i *Image) PinToAtlas() {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
+	}
+	if i.isSubImage() {
+		i.original.PinToAtlas()
+		return
+	}
+	i.image.PinToAtlas()
+}
+
+// CopyFrom copies the pixels in srcRect of src to the image at dstPoint.
+//
+// CopyFrom copies the pixels on the GPU without the rendering pipeline when possible,
+// so CopyFrom is faster than DrawImage for pure copying, e.g., double buffering or keeping history textures.
+// The copy without the rendering pipeline is currently available only with OpenGL (including OpenGL ES and WebGL) and DirectX 11.
+// Otherwise, e.g. with Metal or DirectX 12, or when the image is the screen, CopyFrom renders the pixels instead
+// with the same result.
+// Unlike DrawImage, no blending, filtering or color conversions are applied, and the destination pixels are replaced.
+//
+// dstPoint is in the image's coordinate and srcRect is in src's coordinate.
+// The region out of the image's bounds or src's bounds is not copied.
+//
+// If the image and src share the same original image, CopyFrom panics.
+//
+// When the given image is disposed, CopyFrom panics.
+//
+// When the image i is disposed, CopyFrom does nothing.
+func (i *Image) CopyFrom(src *Image, dstPoint image.Point, srcRect image.Rectangle) {
+	i.copyCheck()
+
+	if src.isDisposed() {
+		panic("ebiten: the given image to CopyFrom must not be disposed")
+	}
+	if i.isDisposed() {
+		return
+	}
+	if i.image == src.image {
+		panic("ebiten: the given image to CopyFrom must be different from the receiver")
+	}
+
+	// Clip the source region by the source bounds and the destination bounds.
+	sr := srcRect.Intersect(src.Bounds())
+	dstPoint = dstPoint.Add(sr.Min.Sub(srcRect.Min))
+	dr := image.Rectangle{Min: dstPoint, Max: dstPoint.Add(sr.Size())}.Intersect(i.Bounds())
+	if dr.Empty() {
+		return
+	}
+	sr = image.Rectangle{Min: sr.Min.Add(dr.Min.Sub(dstPoint)), Max: sr.Min.Add(dr.Max.Sub(dstPoint))}
+
+	dx, dy := i.adjustPosition(dr.Min.X, dr.Min.Y)
+	sx, sy := src.adjustPosition(sr.Min.X, sr.Min.Y)
+	i.image.CopyFrom(src.image, image.Pt(dx, dy), image.Rect(sx, sy, sx+sr.Dx(), sy+sr.Dy()))
+}
+
+// Resize changes the size of the image to the given width and height.
+//
+// Resize reallocates the internal texture while keeping the identity of the image,
+// so the image can be resized without recreating it, e.g., when an offscreen layer follows the window size.
+// The bounds' minimum point is kept, and the options given at NewImageWithOptions are kept.
+// The pixels in the region shared by the old size and the new size are preserved, and the other pixels are cleared.
+//
+// Sub-images of the image created before Resize must not be used after Resize.
+// The image given at Game's Draw must not be resized.
+//
+// If width or height is not positive, Resize panics.
+//
+// If the image is a sub-image, Resize panics.
+//
+// When the image is disposed, Resize does nothing.
+func (i *Image) Resize(width, height int) {
+	i.copyCheck()
+
+	if width <= 0 {
+		panic(fmt.Sprintf("ebiten: width at Resize must be positive but %d", width))
+	}
+	if height <= 0 {
+		panic(fmt.Sprintf("ebiten: height at Resize must be positive but %d", height))
+	}
+	if i.isDisposed() {
+		return
+	}
+	if i.isSubImage() {
+		panic("ebiten: Resize cannot be called on a sub-image")
+	}
+	if i.bounds.Dx() == width && i.bounds.Dy() == height {
+		return
+	}
+
+	i.image.Resize(width, height)
+	i.bounds.Max = i.bounds.Min.Add(image.Pt(width, height))
+}
+
 // WritePixels replaces the pixels of the image.
 //
 // The given pixels are treated as RGBA pre-multiplied alpha values.
@@ -1087,12 +1767,55 @@ func (i *Image) WritePixels(pixels []byte) {
 		return
 	}
 
+	if i.pixelFormat() == FormatRGBA16 {
+		pix16 := make([]uint16, len(pixels))
+		for idx, v := range pixels {
+			pix16[idx] = uint16(v) * 0x101
+		}
+		i.image.WritePixels(uint16sToBytes(pix16), i.adjustedBounds())
+		return
+	}
+
 	// Do not need to copy pixels here.
 	// * In internal/mipmap, pixels are copied when necessary.
 	// * In internal/atlas, pixels are copied to make its paddings.
 	i.image.WritePixels(pixels, i.adjustedBounds())
 }
 
+// WritePixels16 replaces the pixels of the image with 16-bit values.
+//
+// WritePixels16 is the same as WritePixels except that each channel is a 16-bit value.
+// With FormatRGBA16, WritePixels16 writes the pixels without losing precision.
+// With the other formats, the values are converted to 8-bit values.
+//
+// len(pixels) must be 4 * (bounds width) * (bounds height).
+// If len(pixels) is not correct, WritePixels16 panics.
+//
+// When the image is disposed, WritePixels16 does nothing.
+func (i *Image) WritePixels16(pixels []uint16) {
+	i.copyCheck()
+
+	b := i.Bounds()
+	if got, want := len(pixels), 4*b.Dx()*b.Dy(); got != want {
+		panic(fmt.Sprintf("ebiten: len(pixels) must be %d but %d at WritePixels16", want, got))
+	}
+
+	if i.isDisposed() {
+		return
+	}
+
+	if i.pixelFormat() == FormatRGBA16 {
+		i.image.WritePixels(uint16sToBytes(pixels), i.adjustedBounds())
+		return
+	}
+
+	pix := make([]byte, len(pixels))
+	for idx, v := range pixels {
+		pix[idx] = byte(v >> 8)
+	}
+	i.image.WritePixels(pix, i.adjustedBounds())
+}
+
 // ReplacePixels replaces the pixels of the image.
 //
 // Deprecated: as of v2.4. Use WritePixels instead.
@@ -1110,7 +1833,7 @@ func (i *Image) ReplacePixels(pixels []byte) {
 //
 // NewImage panics if RunGame already finishes.
 func NewImage(width, height int) *Image {
-	return newImage(image.Rect(0, 0, width, height), atlas.ImageTypeRegular)
+	return newImage(image.Rect(0, 0, width, height), atlas.ImageTypeRegular, FormatRGBA8)
 }
 
 // NewImageOptions represents options for NewImage.
@@ -1122,6 +1845,69 @@ type NewImageOptions struct {
 	// A regular image is a part of an internal texture atlas, and locating them is done automatically in Ebitengine.
 	// Unmanaged is useful when you want finer controls over the image for performance and memory reasons.
 	Unmanaged bool
+
+	// Depth represents whether the image has a depth buffer or not.
+	// The default (zero) value is false.
+	//
+	// An image with a depth buffer can be used as a destination of DrawTriangles and DrawTrianglesShader
+	// with DepthTest or DepthWrite. Such an image is never on an internal automatic texture atlas, like an unmanaged image.
+	// The depth buffer is initialized with the farthest value. Use ClearDepth to reset the depth buffer.
+	//
+	// Depth buffers are supported with OpenGL (including WebGL), Metal and DirectX.
+	// Use IsGraphicsFeatureAvailable with GraphicsFeatureDepth to check whether depth buffers are available.
+	// When they are not available, DepthTest and DepthWrite are ignored without errors.
+	Depth bool
+
+	// Stencil represents whether the image has a stencil buffer or not.
+	// The default (zero) value is false.
+	//
+	// An image with a stencil buffer can be used as a destination of DrawTriangles and DrawTrianglesShader
+	// with StencilFunc and StencilOp. This is useful for masking with arbitrary shapes.
+	// Such an image is never on an internal automatic texture atlas, like an unmanaged image.
+	// The stencil buffer is initialized with 0. Use ClearStencil to reset the stencil buffer.
+	// Note that rendering with FillRuleNonZero or FillRuleEvenOdd also resets the stencil buffer.
+	//
+	// Stencil buffers are supported with OpenGL (including WebGL), Metal and DirectX, same as depth buffers.
+	// Use IsGraphicsFeatureAvailable with GraphicsFeatureStencil to check whether stencil buffers are available.
+	// When they are not available, StencilFunc and StencilOp are ignored without errors.
+	Stencil bool
+
+	// Format is the pixel format of the image.
+	// The default (zero) value is FormatRGBA8.
+	//
+	// With FormatRGBA16F or FormatRGBA32F, the image can hold color values above 1.0 without banding.
+	// This is useful for HDR lighting and bloom. Such an image is never on an internal automatic texture atlas,
+	// like an unmanaged image. To show the result on the screen, draw the image with a shader for tone mapping.
+	//
+	// WritePixels, ReadPixels, At and Set still treat pixels as 8-bit values.
+	// Values out of [0, 1] are clamped when the pixels are read.
+	// Use IsGraphicsFeatureAvailable with GraphicsFeatureFloatFormats to check whether these formats are available.
+	// When they are not available, the image keeps 8-bit values as FormatRGBA8 does, without errors.
+	//
+	// With FormatRGBA16, the image keeps 16-bit values for each channel.
+	// Use WritePixels16, ReadPixels16 and RGBA64At to access the pixels without losing precision.
+	// Use IsGraphicsFeatureAvailable with GraphicsFeatureRGBA16 to check whether FormatRGBA16 is available.
+	// When it is not available, the image keeps 8-bit values, and the lower 8 bits of each value are lost, without errors.
+	//
+	// With FormatSRGBA8, the image keeps sRGB-encoded values, and filtering and alpha blending happen in the linear space.
+	//
+	// Formats other than FormatRGBA8 are currently supported only with OpenGL (including WebGL).
+	// See the documents of the Format constants for the behavior with other graphics libraries.
+	Format Format
+
+	// Samples is the number of samples per pixel for multisample anti-aliasing (MSAA).
+	// The default (zero) value is 0, that means the image is not multisampled. 1 also means the same thing.
+	//
+	// A multisampled image smooths the edges of rendered triangles, including DrawTriangles with FillRuleNonZero
+	// or FillRuleEvenOdd, without AntiAlias options. This is useful for vector-heavy layers.
+	// The rendering result is resolved automatically when the image is used as a source or its pixels are read.
+	// Such an image is never on an internal automatic texture atlas, like an unmanaged image.
+	// If Samples exceeds the maximum number the device supports, the maximum number is used instead.
+	//
+	// Multisampled images are currently supported only with OpenGL (including WebGL).
+	// Use IsGraphicsFeatureAvailable with GraphicsFeatureMultisampling to check whether multisampled images are available.
+	// When they are not available, the image is rendered with one sample per pixel without errors.
+	Samples int
 }
 
 // NewImageWithOptions returns an empty image with the given bounds and the options.
@@ -1141,13 +1927,31 @@ type NewImageOptions struct {
 // NewImageWithOptions panics if RunGame already finishes.
 func NewImageWithOptions(bounds image.Rectangle, options *NewImageOptions) *Image {
 	imageType := atlas.ImageTypeRegular
-	if options != nil && options.Unmanaged {
-		imageType = atlas.ImageTypeUnmanaged
+	format := FormatRGBA8
+	if options != nil {
+		if !options.Format.isValid() {
+			panic(fmt.Sprintf("ebiten: invalid format: %d", options.Format))
+		}
+		format = options.Format
+		if options.Samples < 0 {
+			panic(fmt.Sprintf("ebiten: Samples must be non-negative but %d", options.Samples))
+		}
+		if options.Unmanaged || options.Depth || options.Stencil || format != FormatRGBA8 || options.Samples > 1 {
+			imageType = atlas.ImageTypeUnmanaged
+		}
 	}
-	return newImage(bounds, imageType)
+	i := newImage(bounds, imageType, format)
+	if options != nil {
+		i.depth = options.Depth
+		i.stencil = options.Stencil
+		if options.Samples > 1 {
+			i.image.SetSamples(options.Samples)
+		}
+	}
+	return i
 }
 
-func newImage(bounds image.Rectangle, imageType atlas.ImageType) *Image {
+func newImage(bounds image.Rectangle, imageType atlas.ImageType, format Format) *Image {
 	if isRunGameEnded() {
 		panic(fmt.Sprintf("ebiten: NewImage cannot be called after RunGame finishes"))
 	}
@@ -1161,8 +1965,9 @@ func newImage(bounds image.Rectangle, imageType atlas.ImageType) *Image {
 	}
 
 	i := &Image{
-		image:  ui.Get().NewImage(width, height, imageType),
+		image:  ui.Get().NewImage(width, height, imageType, graphicsdriver.PixelFormat(format)),
 		bounds: bounds,
+		format: format,
 	}
 	i.addr = i
 	return i
@@ -1196,6 +2001,13 @@ type NewImageFromImageOptions struct {
 	// PreserveBounds represents whether the new image's bounds are the same as the given image.
 	// The default (zero) value is false, that means the new image's upper-left position is adjusted to (0, 0).
 	PreserveBounds bool
+
+	// Format is the pixel format of the new image. See also NewImageOptions.Format.
+	// The default (zero) value is FormatRGBA8.
+	//
+	// With FormatRGBA16, the pixels of the source image are kept in 16-bit precision,
+	// e.g. for an *image.RGBA64 or an *image.Gray16 source.
+	Format Format
 }
 
 // NewImageFromImageWithOptions creates a new image with the given image (source) with the given options.
@@ -1223,6 +2035,7 @@ func NewImageFromImageWithOptions(source image.Image, options *NewImageFromImage
 	}
 	i := NewImageWithOptions(r, &NewImageOptions{
 		Unmanaged: options.Unmanaged,
+		Format:    options.Format,
 	})
 
 	// If the given image is an Ebitengine image, use DrawImage instead of reading pixels from the source.
@@ -1237,10 +2050,49 @@ func NewImageFromImageWithOptions(source image.Image, options *NewImageFromImage
 		return i
 	}
 
+	if options.Format == FormatRGBA16 {
+		i.WritePixels16(imageToUint16s(source))
+		return i
+	}
+
 	i.WritePixels(imageToBytes(source))
 	return i
 }
 
+// NewImageFromNativeTexture creates a new image referring to the given native texture of the graphics library.
+//
+// handle depends on the graphics library:
+//
+//   - OpenGL: a texture name (GLuint) of GL_TEXTURE_2D
+//   - Metal: a pointer to an id<MTLTexture>
+//
+// The texture must have an 8-bit RGBA format with premultiplied alpha and the given width and height,
+// and must be available from the graphics library's context or device Ebitengine uses.
+// The pixels are not copied, so the changes of the texture by other libraries like video decoders are reflected
+// when the image is used as a rendering source.
+// Note that At and ReadPixels might return cached pixels.
+//
+// The texture is not deleted when the image is disposed. The texture must be alive while the image is used.
+// For OpenGL, the texture's filters and wrap modes are changed to GL_NEAREST and GL_CLAMP_TO_EDGE.
+//
+// The image is an unmanaged image and cannot be resized.
+//
+// Native textures are currently available only with OpenGL (not WebGL) and Metal.
+// DirectX textures (ID3D11Texture2D and ID3D12Resource) are not supported,
+// and RunGame returns an error when the image is used with DirectX or the other graphics libraries.
+//
+// If handle is 0, or width or height is not positive, NewImageFromNativeTexture panics.
+//
+// NewImageFromNativeTexture panics if RunGame already finishes.
+func NewImageFromNativeTexture(handle uintptr, width, height int) *Image {
+	if handle == 0 {
+		panic("ebiten: handle at NewImageFromNativeTexture must not be 0")
+	}
+	i := newImage(image.Rect(0, 0, width, height), atlas.ImageTypeUnmanaged, FormatRGBA8)
+	i.image.SetNativeTexture(handle)
+	return i
+}
+
 // colorMToScale returns a new color matrix and color scales that equal to the given matrix in terms of the effect.
 //
 // If the given matrix is merely a scaling matrix, colorMToScale returns
@@ -1292,6 +2144,34 @@ func (i *Image) ensureTmpIndices(n int) []uint32 {
 	return i.tmpIndices[:n]
 }
 
+// copyIndices16 copies the given indices to the temporary internal indices.
+func (i *Image) copyIndices16(indices []uint16) []uint32 {
+	is := i.ensureTmpIndices(len(indices))
+	for i := range is {
+		is[i] = uint32(indices[i])
+	}
+	return is
+}
+
+// copyIndices32 copies the given indices to the temporary internal indices.
+func (i *Image) copyIndices32(indices []uint32) []uint32 {
+	is := i.ensureTmpIndices(len(indices))
+	copy(is, indices)
+	return is
+}
+
+// validateIndices panics if the number of the indices is not multiple of 3 or an index is out of range of the vertices.
+func validateIndices[T uint16 | uint32](indices []T, vertexCount int) {
+	if len(indices)%3 != 0 {
+		panic("ebiten: len(indices) % 3 must be 0")
+	}
+	for i, idx := range indices {
+		if uint64(idx) >= uint64(vertexCount) {
+			panic(fmt.Sprintf("ebiten: indices[%d] must be less than len(vertices) (%d) but was %d", i, vertexCount, idx))
+		}
+	}
+}
+
 // private implements FinalScreen.
 func (*Image) private() {
 }
@@ -1411,6 +1411,181 @@ func TestImageAddressRepeat(t *testing.T) {
 	}
 }
 
+func TestImageAddressMirrorRepeat(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(w, h)
+	dst := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (i + j*w)
+			if 4 <= i && i < 8 && 4 <= j && j < 8 {
+				pix[idx] = byte(i-4) * 0x10
+				pix[idx+1] = byte(j-4) * 0x10
+				pix[idx+2] = 0
+				pix[idx+3] = 0xff
+			} else {
+				pix[idx] = 0
+				pix[idx+1] = 0
+				pix[idx+2] = 0xff
+				pix[idx+3] = 0xff
+			}
+		}
+	}
+	src.WritePixels(pix)
+
+	vs := []ebiten.Vertex{
+		{
+			DstX:   0,
+			DstY:   0,
+			SrcX:   0,
+			SrcY:   0,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+		{
+			DstX:   w,
+			DstY:   0,
+			SrcX:   w,
+			SrcY:   0,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+		{
+			DstX:   0,
+			DstY:   h,
+			SrcX:   0,
+			SrcY:   h,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+		{
+			DstX:   w,
+			DstY:   h,
+			SrcX:   w,
+			SrcY:   h,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+	}
+	is := []uint16{0, 1, 2, 1, 2, 3}
+	op := &ebiten.DrawTrianglesOptions{}
+	op.Address = ebiten.AddressMirrorRepeat
+	dst.DrawTriangles(vs, is, src.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image), op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			// The source region is mirrored at every other repetition.
+			x, y := i%8, j%8
+			if x >= 4 {
+				x = 7 - x
+			}
+			if y >= 4 {
+				y = 7 - y
+			}
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{R: byte(x) * 0x10, G: byte(y) * 0x10, A: 0xff}
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageAddressPerAxis(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(w, h)
+	dst := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (i + j*w)
+			if 4 <= i && i < 8 && 4 <= j && j < 8 {
+				pix[idx] = byte(i-4) * 0x10
+				pix[idx+1] = byte(j-4) * 0x10
+				pix[idx+2] = 0
+				pix[idx+3] = 0xff
+			} else {
+				pix[idx] = 0
+				pix[idx+1] = 0
+				pix[idx+2] = 0xff
+				pix[idx+3] = 0xff
+			}
+		}
+	}
+	src.WritePixels(pix)
+
+	vs := []ebiten.Vertex{
+		{
+			DstX:   0,
+			DstY:   0,
+			SrcX:   0,
+			SrcY:   0,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+		{
+			DstX:   w,
+			DstY:   0,
+			SrcX:   w,
+			SrcY:   0,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+		{
+			DstX:   0,
+			DstY:   h,
+			SrcX:   0,
+			SrcY:   h,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+		{
+			DstX:   w,
+			DstY:   h,
+			SrcX:   w,
+			SrcY:   h,
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		},
+	}
+	is := []uint16{0, 1, 2, 1, 2, 3}
+	op := &ebiten.DrawTrianglesOptions{}
+	op.AddressX = ebiten.AddressRepeat
+	op.AddressY = ebiten.AddressClampToZero
+	dst.DrawTriangles(vs, is, src.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image), op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			// The source region is repeated only horizontally.
+			var want color.RGBA
+			if 4 <= j && j < 8 {
+				want = color.RGBA{R: byte(i%4) * 0x10, G: byte(j%4) * 0x10, A: 0xff}
+			}
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
 func TestImageAddressRepeatNegativePosition(t *testing.T) {
 	const w, h = 16, 16
 	src := ebiten.NewImage(w, h)
@@ -4676,3 +4851,622 @@ func TestImageInvalidPremultipliedAlphaColor(t *testing.T) {
 		}
 	}
 }
+
+func TestImageMipmapPolicy(t *testing.T) {
+	img := ebiten.NewImage(16, 16)
+	sub := img.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image)
+
+	if got, want := sub.MipmapPolicy(), ebiten.MipmapAuto; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	sub.SetMipmapPolicy(ebiten.MipmapOff)
+	if got, want := img.MipmapPolicy(), ebiten.MipmapOff; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageMipmapPolicyForce(t *testing.T) {
+	const size = 64
+
+	// Create a checkerboard with 1x1 pixel cells.
+	pix := make([]byte, 4*size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			if (i+j)%2 == 0 {
+				continue
+			}
+			idx := 4 * (i + j*size)
+			pix[idx] = 0xff
+			pix[idx+1] = 0xff
+			pix[idx+2] = 0xff
+			pix[idx+3] = 0xff
+		}
+	}
+	src := ebiten.NewImage(size, size)
+	src.WritePixels(pix)
+	src.SetMipmapPolicy(ebiten.MipmapForce)
+
+	dst := ebiten.NewImage(size/4, size/4)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(0.25, 0.25)
+	op.Filter = ebiten.FilterNearest
+	dst.DrawImage(src, op)
+
+	// With mipmaps, the checkerboard should be averaged even with FilterNearest.
+	for j := 0; j < size/4; j++ {
+		for i := 0; i < size/4; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			if got.A == 0 || got.A == 0xff {
+				t.Errorf("dst.At(%d, %d).A: got: %d, want: neither 0 nor 0xff", i, j, got.A)
+			}
+		}
+	}
+}
+
+func TestImageFilterAnisotropicWithoutScaling(t *testing.T) {
+	const size = 16
+
+	src := ebiten.NewImage(size, size)
+	pix := make([]byte, 4*size*size)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	src.WritePixels(pix)
+
+	dst0 := ebiten.NewImage(size, size)
+	op := &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterLinear
+	dst0.DrawImage(src, op)
+
+	dst1 := ebiten.NewImage(size, size)
+	op.Filter = ebiten.FilterAnisotropic
+	dst1.DrawImage(src, op)
+
+	// Without scaling, FilterAnisotropic should be the same as FilterLinear.
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			got := dst1.At(i, j)
+			want := dst0.At(i, j)
+			if got != want {
+				t.Errorf("dst1.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageFilterBicubic(t *testing.T) {
+	const size = 16
+
+	src := ebiten.NewImage(size, size)
+	pix := make([]byte, 4*size*size)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	src.WritePixels(pix)
+
+	dst0 := ebiten.NewImage(size, size)
+	op := &ebiten.DrawImageOptions{}
+	op.Filter = ebiten.FilterNearest
+	dst0.DrawImage(src, op)
+
+	dst1 := ebiten.NewImage(size, size)
+	op.Filter = ebiten.FilterBicubic
+	dst1.DrawImage(src, op)
+
+	// Without scaling, FilterBicubic should be the same as FilterNearest.
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			got := dst1.At(i, j)
+			want := dst0.At(i, j)
+			if got != want {
+				t.Errorf("dst1.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// An edge between black and white should not overshoot.
+	src.Fill(color.Black)
+	src.SubImage(image.Rect(size/2, 0, size, size)).(*ebiten.Image).Fill(color.White)
+	dst2 := ebiten.NewImage(4*size, 4*size)
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(4, 4)
+	op.Filter = ebiten.FilterBicubic
+	dst2.DrawImage(src, op)
+
+	// With AddressUnsafe, pixels at the edges might be affected by outside of the source region.
+	var prev uint8
+	for i := 4; i < 4*size-4; i++ {
+		got := dst2.At(i, 2*size).(color.RGBA)
+		if got.A != 0xff {
+			t.Errorf("dst2.At(%d, %d).A: got: %d, want: 0xff", i, 2*size, got.A)
+		}
+		if got.R < prev {
+			t.Errorf("dst2.At(%d, %d).R: got: %d, want: >= %d", i, 2*size, got.R, prev)
+		}
+		prev = got.R
+	}
+}
+
+func TestImageFilterPixelArt(t *testing.T) {
+	const size = 4
+
+	src := ebiten.NewImage(size, size)
+	pix := make([]byte, 4*size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			idx := 4 * (j*size + i)
+			if (i+j)%2 == 0 {
+				pix[idx] = 0xff
+				pix[idx+1] = 0xff
+				pix[idx+2] = 0xff
+			}
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix)
+
+	// At an integer scale, FilterPixelArt should be the same as FilterNearest.
+	dst0 := ebiten.NewImage(3*size, 3*size)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(3, 3)
+	op.Filter = ebiten.FilterNearest
+	dst0.DrawImage(src, op)
+
+	dst1 := ebiten.NewImage(3*size, 3*size)
+	op.Filter = ebiten.FilterPixelArt
+	dst1.DrawImage(src, op)
+
+	for j := 0; j < 3*size; j++ {
+		for i := 0; i < 3*size; i++ {
+			got := dst1.At(i, j)
+			want := dst0.At(i, j)
+			if got != want {
+				t.Errorf("dst1.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// At a non-integer scale, only the pixels on the texel edges should be blended.
+	dst2 := ebiten.NewImage(10, 10)
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(2.5, 2.5)
+	op.Filter = ebiten.FilterPixelArt
+	dst2.DrawImage(src, op)
+
+	var blended int
+	for i := 0; i < 10; i++ {
+		got := dst2.At(i, 0).(color.RGBA)
+		if got.R != 0 && got.R != 0xff {
+			blended++
+		}
+	}
+	// The texel edges are at x = 2.5 and 7.5.
+	if got, want := blended, 2; got != want {
+		t.Errorf("the number of blended pixels: got: %d, want: %d", got, want)
+	}
+}
+
+func TestImageGammaCorrect(t *testing.T) {
+	src := ebiten.NewImage(2, 1)
+	src.WritePixels([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0xff})
+
+	encode := func(v float64) uint8 {
+		if v <= 0.0031308 {
+			v *= 12.92
+		} else {
+			v = 1.055*math.Pow(v, 1/2.4) - 0.055
+		}
+		return uint8(math.Round(v * 0xff))
+	}
+
+	for _, gammaCorrect := range []bool{false, true} {
+		gammaCorrect := gammaCorrect
+		t.Run(fmt.Sprintf("gammaCorrect=%t", gammaCorrect), func(t *testing.T) {
+			dst := ebiten.NewImage(4, 1)
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(2, 1)
+			op.Filter = ebiten.FilterLinear
+			op.GammaCorrect = gammaCorrect
+			dst.DrawImage(src, op)
+
+			// The pixels at x = 1 and 2 blend the white and black texels with the rates 0.25 and 0.75.
+			for i, rate := range []float64{0.25, 0.75} {
+				x := i + 1
+				got := dst.At(x, 0).(color.RGBA)
+				want := uint8(math.Round((1 - rate) * 0xff))
+				if gammaCorrect {
+					want = encode(1 - rate)
+				}
+				if !sameColors(got, color.RGBA{R: want, G: want, B: want, A: 0xff}, 2) {
+					t.Errorf("dst.At(%d, 0): got: %v, want: %v", x, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestImageReadPixelsAsync(t *testing.T) {
+	const w, h = 16, 16
+	img := ebiten.NewImage(w, h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			img.Set(i, j, color.RGBA{R: byte(i * 0x10), G: byte(j * 0x10), B: 0x80, A: 0xff})
+		}
+	}
+
+	// Read a sub-image to test the region is adjusted correctly.
+	sub := img.SubImage(image.Rect(4, 4, 12, 12)).(*ebiten.Image)
+	got := make([]byte, 4*8*8)
+	var called bool
+	var readErr error
+	sub.ReadPixelsAsync(got, func(err error) {
+		called = true
+		readErr = err
+	})
+
+	flusher := ebiten.NewImage(1, 1)
+	src := ebiten.NewImage(1, 1)
+	for i := 0; !called; i++ {
+		if i >= 100 {
+			t.Fatal("the callback of ReadPixelsAsync must be called but not")
+		}
+		// Reading pixels synchronously flushes the graphics commands, and the asynchronous reading is checked there.
+		flusher.DrawImage(src, nil)
+		flusher.ReadPixels(make([]byte, 4))
+		ebiten.CallReadPixelsAsyncCallbacks()
+		if !called {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	want := make([]byte, 4*8*8)
+	sub.ReadPixels(want)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageReadPixelsAsyncAfterDisposing(t *testing.T) {
+	img := ebiten.NewImage(16, 16)
+	img.Fill(color.White)
+	img.Dispose()
+
+	pix := make([]byte, 4*16*16)
+	for i := range pix {
+		pix[i] = 0xff
+	}
+	var called bool
+	img.ReadPixelsAsync(pix, func(err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		called = true
+	})
+	if !called {
+		t.Errorf("the callback of ReadPixelsAsync must be called immediately for a disposed image but not")
+	}
+	for i, v := range pix {
+		if v != 0 {
+			t.Errorf("pix[%d]: got: %d, want: 0", i, v)
+			break
+		}
+	}
+}
+
+func TestImageClipRect(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(w, h)
+	src.Fill(color.White)
+
+	clip := image.Rect(4, 6, 10, 12)
+	for _, sub := range []bool{false, true} {
+		sub := sub
+		t.Run(fmt.Sprintf("sub=%t", sub), func(t *testing.T) {
+			dst := ebiten.NewImage(w, h)
+			if sub {
+				dst = dst.SubImage(image.Rect(2, 2, 12, 12)).(*ebiten.Image)
+			}
+
+			op := &ebiten.DrawImageOptions{}
+			op.ClipRect = &clip
+			dst.DrawImage(src, op)
+
+			for j := 0; j < h; j++ {
+				for i := 0; i < w; i++ {
+					got := dst.At(i, j)
+					want := color.RGBA{}
+					if image.Pt(i, j).In(clip.Intersect(dst.Bounds())) {
+						want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+					}
+					if got != want {
+						t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImageClipRectDrawTriangles(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(1, 1)
+	src.Fill(color.White)
+
+	dst := ebiten.NewImage(w, h)
+	vs := []ebiten.Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: 0, SrcX: 1, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: 0, DstY: h, SrcX: 0, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: h, SrcX: 1, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+	is := []uint16{0, 1, 2, 1, 2, 3}
+
+	clip := image.Rect(-4, 8, 4, 20)
+	op := &ebiten.DrawTrianglesOptions{}
+	op.ClipRect = &clip
+	dst.DrawTriangles(vs, is, src, op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{}
+			if image.Pt(i, j).In(clip) {
+				want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// A clipping rectangle out of the image renders nothing.
+	dst.Clear()
+	op.ClipRect = &image.Rectangle{Min: image.Pt(20, 20), Max: image.Pt(30, 30)}
+	dst.DrawTriangles(vs, is, src, op)
+	if got, want := dst.At(0, 0), (color.RGBA{}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageClipRectOutside(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(w, h)
+	src.Fill(color.White)
+
+	dst := ebiten.NewImage(w, h)
+	for _, clip := range []image.Rectangle{
+		image.Rect(20, 20, 30, 30),
+		image.Rect(-10, -10, -2, -2),
+		// An empty intersection is the zero rectangle, which must not be treated as no clipping.
+		image.Rect(20, 20, 30, 30).Intersect(dst.Bounds()),
+		{},
+	} {
+		clip := clip
+		dst.Clear()
+		op := &ebiten.DrawImageOptions{}
+		op.ClipRect = &clip
+		dst.DrawImage(src, op)
+		for j := 0; j < h; j++ {
+			for i := 0; i < w; i++ {
+				if got, want := dst.At(i, j), (color.RGBA{}); got != want {
+					t.Errorf("clip: %v, dst.At(%d, %d): got: %v, want: %v", clip, i, j, got, want)
+				}
+			}
+		}
+	}
+
+	// Without ClipRect, the whole image is rendered.
+	dst.Clear()
+	dst.DrawImage(src, nil)
+	if got, want := dst.At(0, 0), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestImagePinToAtlas(t *testing.T) {
+	const w, h = 16, 16
+	img := ebiten.NewImage(w, h)
+	img.Fill(color.RGBA{R: 0xff, A: 0xff})
+	img.PinToAtlas()
+	if got, want := img.At(0, 0), (color.RGBA{R: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// A pinned image can still be a rendering destination.
+	img.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image).Fill(color.RGBA{G: 0xff, A: 0xff})
+	dst := ebiten.NewImage(w, h)
+	dst.DrawImage(img, nil)
+	if got, want := dst.At(0, 0), (color.RGBA{R: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := dst.At(5, 5), (color.RGBA{G: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// Pinning an unmanaged image does nothing.
+	unmanaged := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+		Unmanaged: true,
+	})
+	unmanaged.Fill(color.RGBA{B: 0xff, A: 0xff})
+	unmanaged.PinToAtlas()
+	if got, want := unmanaged.At(0, 0), (color.RGBA{B: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageDrawTriangles32(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(1, 1)
+	src.Fill(color.White)
+
+	// Put dummy vertices first so that the used vertices cannot be specified by uint16 indices.
+	const offset = 1 << 16
+	vs := make([]ebiten.Vertex, offset+4)
+	copy(vs[offset:], []ebiten.Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: 0, SrcX: 1, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: 0, DstY: h, SrcX: 0, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: h, SrcX: 1, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	})
+	is := []uint32{offset, offset + 1, offset + 2, offset + 1, offset + 2, offset + 3}
+
+	dst := ebiten.NewImage(w, h)
+	dst.DrawTriangles32(vs, is, src, nil)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if got, want := dst.At(i, j), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	shader, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0, 0, 1, 1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst.Clear()
+	dst.DrawTrianglesShader32(vs, is, shader, nil)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if got, want := dst.At(i, j), (color.RGBA{B: 0xff, A: 0xff}); got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDrawTriangles32WithGreaterIndexThanVerticesCount(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawTriangles32 must panic but not")
+		}
+	}()
+
+	const w, h = 16, 16
+	dst := ebiten.NewImage(w, h)
+	src := ebiten.NewImage(w, h)
+
+	vs := make([]ebiten.Vertex, 4)
+	is := []uint32{0, 1, 2, 1, 2, 4}
+	dst.DrawTriangles32(vs, is, src, nil)
+}
+
+func TestImageCopyFrom(t *testing.T) {
+	const w, h = 16, 16
+	src := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (i + j*w)
+			pix[idx] = byte(i)
+			pix[idx+1] = byte(j)
+			pix[idx+2] = 0
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix)
+
+	dst := ebiten.NewImage(w, h)
+	dst.Fill(color.RGBA{B: 0xff, A: 0xff})
+	// The source region is partially out of the source bounds, and the destination region is partially out of the destination bounds.
+	dst.CopyFrom(src, image.Pt(-2, 10), image.Rect(-1, 4, 6, 12))
+
+	// The source pixels in (0, 4)-(6, 10) are copied to (-1, 10)-(5, 16), and the destination region is clipped.
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{B: 0xff, A: 0xff}
+			if image.Pt(i, j).In(image.Rect(0, 10, 5, 16)) {
+				want = color.RGBA{R: byte(i + 1), G: byte(j - 6), A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// Copy between sub-images. The source region is clipped by the source sub-image bounds.
+	dst.Clear()
+	dst.SubImage(image.Rect(8, 8, 16, 16)).(*ebiten.Image).CopyFrom(src.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image), image.Pt(8, 8), image.Rect(4, 4, 12, 12))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if image.Pt(i, j).In(image.Rect(8, 8, 12, 12)) {
+				want = color.RGBA{R: byte(i - 4), G: byte(j - 4), A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageResize(t *testing.T) {
+	for _, unmanaged := range []bool{false, true} {
+		unmanaged := unmanaged
+		t.Run(fmt.Sprintf("unmanaged=%t", unmanaged), func(t *testing.T) {
+			const w, h = 16, 16
+			img := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+				Unmanaged: unmanaged,
+			})
+			img.Fill(color.RGBA{R: 0xff, A: 0xff})
+
+			// Enlarge the image. The existing pixels are preserved and the new pixels are cleared.
+			img.Resize(2*w, h/2)
+			if got, want := img.Bounds(), image.Rect(0, 0, 2*w, h/2); got != want {
+				t.Errorf("img.Bounds(): got: %v, want: %v", got, want)
+			}
+			for j := 0; j < h/2; j++ {
+				for i := 0; i < 2*w; i++ {
+					got := img.At(i, j)
+					var want color.RGBA
+					if i < w {
+						want = color.RGBA{R: 0xff, A: 0xff}
+					}
+					if got != want {
+						t.Errorf("img.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+
+			// The resized image is still available as a rendering destination and a rendering source.
+			img.Fill(color.RGBA{G: 0xff, A: 0xff})
+			dst := ebiten.NewImage(2*w, h/2)
+			dst.DrawImage(img, nil)
+			if got, want := dst.At(2*w-1, h/2-1), (color.RGBA{G: 0xff, A: 0xff}); got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", 2*w-1, h/2-1, got, want)
+			}
+		})
+	}
+}
+
+func TestImageResizeSubImage(t *testing.T) {
+	img := ebiten.NewImage(16, 16)
+	sub := img.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Resize on a sub-image must panic but not")
+		}
+	}()
+	sub.Resize(8, 8)
+}
+
+func TestNewImageFromNativeTextureWithZeroHandle(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("NewImageFromNativeTexture with 0 must panic but not")
+		}
+	}()
+	ebiten.NewImageFromNativeTexture(0, 16, 16)
+}
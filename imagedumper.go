@@ -19,6 +19,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/debug"
@@ -70,9 +71,15 @@ func dumpInternalImages() error {
 	return nil
 }
 
+// imageDumper takes a screenshot or dumps the internal images when the specified key is pressed.
+//
+// update is called at Update and dump is called at Draw. In the pipelined update mode, they are called concurrently.
 type imageDumper struct {
 	keyState map[Key]int
 
+	// isKeyPressed reports whether the key is pressed. If isKeyPressed is nil, IsKeyPressed is used.
+	isKeyPressed func(key Key) bool
+
 	hasScreenshotKey bool
 	screenshotKey    Key
 	toTakeScreenshot bool
@@ -82,6 +89,8 @@ type imageDumper struct {
 	toDumpInternalImages     bool
 
 	err error
+
+	m sync.Mutex
 }
 
 func envScreenshotKey() string {
@@ -101,6 +110,9 @@ func envInternalImagesKey() string {
 }
 
 func (i *imageDumper) update() error {
+	i.m.Lock()
+	defer i.m.Unlock()
+
 	if i.err != nil {
 		return i.err
 	}
@@ -136,8 +148,12 @@ func (i *imageDumper) update() error {
 		keys[i.dumpInternalImagesKey] = struct{}{}
 	}
 
+	isKeyPressed := i.isKeyPressed
+	if isKeyPressed == nil {
+		isKeyPressed = IsKeyPressed
+	}
 	for key := range keys {
-		if IsKeyPressed(key) {
+		if isKeyPressed(key) {
 			i.keyState[key]++
 			if i.keyState[key] == 1 {
 				if i.hasScreenshotKey && key == i.screenshotKey {
@@ -155,15 +171,15 @@ func (i *imageDumper) update() error {
 }
 
 func (i *imageDumper) dump(screen *Image, transparent bool) error {
-	if i.toTakeScreenshot {
-		i.toTakeScreenshot = false
+	screenshot, internalImages := i.takeRequests()
+
+	if screenshot {
 		if err := takeScreenshot(screen, transparent); err != nil {
 			return err
 		}
 	}
 
-	if i.toDumpInternalImages {
-		i.toDumpInternalImages = false
+	if internalImages {
 		if err := dumpInternalImages(); err != nil {
 			return err
 		}
@@ -171,3 +187,14 @@ func (i *imageDumper) dump(screen *Image, transparent bool) error {
 
 	return nil
 }
+
+// takeRequests returns and resets the requests to take a screenshot and to dump the internal images.
+func (i *imageDumper) takeRequests() (screenshot, internalImages bool) {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	screenshot, internalImages = i.toTakeScreenshot, i.toDumpInternalImages
+	i.toTakeScreenshot = false
+	i.toDumpInternalImages = false
+	return
+}
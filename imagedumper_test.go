@@ -0,0 +1,32 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestImageDumperConcurrency tests the image dumper used from Update and Draw concurrently in the pipelined update mode.
+// Run this test with -race to detect data races.
+func TestImageDumperConcurrency(t *testing.T) {
+	const n = 1000
+	count := ebiten.RunImageDumperConcurrentlyForTesting(n)
+	// The key is pressed n/2 times. Requests might be merged when they are not handled in time.
+	if count < 1 || count > n/2 {
+		t.Errorf("got: %d, want: in [1, %d]", count, n/2)
+	}
+}
@@ -75,6 +75,31 @@ func imageToBytes(img image.Image) []byte {
 	}
 }
 
+// imageToUint16s gets RGBA 16-bit values from img.
+//
+// If img is *image.RGBA64, the values are converted from its Pix directly.
+func imageToUint16s(img image.Image) []uint16 {
+	size := img.Bounds().Size()
+	w, h := size.X, size.Y
+
+	src, ok := img.(*image.RGBA64)
+	if !ok {
+		src = image.NewRGBA64(image.Rect(0, 0, w, h))
+		draw.Draw(src, image.Rect(0, 0, w, h), img, img.Bounds().Min, draw.Src)
+	}
+
+	us := make([]uint16, 4*w*h)
+	b := src.Bounds()
+	for j := 0; j < h; j++ {
+		// image.RGBA64's Pix is in big endian.
+		row := src.Pix[src.PixOffset(b.Min.X, b.Min.Y+j):]
+		for i := 0; i < 4*w; i++ {
+			us[4*w*j+i] = uint16(row[2*i])<<8 | uint16(row[2*i+1])
+		}
+	}
+	return us
+}
+
 func imageToBytesSlow(img image.Image) []byte {
 	size := img.Bounds().Size()
 	w, h := size.X, size.Y
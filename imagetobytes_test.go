@@ -101,6 +101,63 @@ func TestImageToBytes(t *testing.T) {
 	}
 }
 
+func TestImageToUint16s(t *testing.T) {
+	cases := []struct {
+		In  image.Image
+		Out []uint16
+	}{
+		{
+			In: &image.RGBA64{
+				Pix:    []uint8{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+				Stride: 8,
+				Rect:   image.Rect(0, 0, 1, 1),
+			},
+			Out: []uint16{0x1234, 0x5678, 0x9abc, 0xdef0},
+		},
+		{
+			In: (&image.RGBA64{
+				Pix: []uint8{
+					0, 0, 0, 0, 0, 0, 0, 0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				},
+				Stride: 16,
+				Rect:   image.Rect(0, 0, 2, 2),
+			}).SubImage(image.Rect(1, 0, 2, 1)),
+			Out: []uint16{0x1234, 0x5678, 0x9abc, 0xdef0},
+		},
+		{
+			In: &image.Gray16{
+				Pix:    []uint8{0x12, 0x34},
+				Stride: 2,
+				Rect:   image.Rect(0, 0, 1, 1),
+			},
+			Out: []uint16{0x1234, 0x1234, 0x1234, 0xffff},
+		},
+		{
+			In: &image.RGBA{
+				Pix:    []uint8{0x12, 0x34, 0x56, 0x78},
+				Stride: 4,
+				Rect:   image.Rect(0, 0, 1, 1),
+			},
+			Out: []uint16{0x1212, 0x3434, 0x5656, 0x7878},
+		},
+	}
+	for i, c := range cases {
+		got := ebiten.ImageToUint16s(c.In)
+		want := c.Out
+		if len(got) != len(want) {
+			t.Errorf("Test %d: len(got): %d, len(want): %d", i, len(got), len(want))
+			continue
+		}
+		for j := range got {
+			if got[j] != want[j] {
+				t.Errorf("Test %d: got: %v, want: %v", i, got, want)
+				break
+			}
+		}
+	}
+}
+
 func BenchmarkImageToBytesRGBA(b *testing.B) {
 	img := image.NewRGBA(image.Rect(0, 0, 4096, 4096))
 	b.ResetTimer()
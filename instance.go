@@ -0,0 +1,124 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/builtinshader"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+)
+
+// Instance represents per-instance data for DrawTrianglesInstanced.
+type Instance struct {
+	// GeoM is a geometry matrix applied to the destination positions of the vertices.
+	// The matrix is applied before the position adjustment for a sub-image destination.
+	GeoM GeoM
+
+	// ColorScale is a scale of color applied to the vertex colors.
+	// ColorScale is applied to the premultiplied-alpha vertex colors.
+	ColorScale ColorScale
+}
+
+// DrawTrianglesInstanced draws the triangles specified by vertices and indices for each instance.
+//
+// DrawTrianglesInstanced works as if DrawTriangles is called for each instance with the vertices
+// transformed by the instance's GeoM and ColorScale, but all the instances are submitted as one draw command.
+// As the mesh is shared by all the instances, you don't have to build a big vertex slice for all the instances every frame.
+//
+// The instances are expanded on CPU, so the number of vertices internally is len(vertices) * len(instances).
+// If the number exceeds MaxVertexCount, DrawTrianglesInstanced panics.
+//
+// If len(indices) is not multiple of 3, DrawTrianglesInstanced panics.
+//
+// If a value in indices is out of range of vertices, DrawTrianglesInstanced panics.
+//
+// When the given image is disposed, DrawTrianglesInstanced panics.
+//
+// When the image i is disposed, DrawTrianglesInstanced does nothing.
+func (i *Image) DrawTrianglesInstanced(vertices []Vertex, indices []uint16, img *Image, instances []Instance, options *DrawTrianglesOptions) {
+	i.copyCheck()
+
+	if img != nil && img.isDisposed() {
+		panic("ebiten: the given image to DrawTrianglesInstanced must not be disposed")
+	}
+	if i.isDisposed() {
+		return
+	}
+
+	validateIndices(indices, len(vertices))
+	if len(instances) > 0 && len(vertices) > MaxVertexCount/len(instances) {
+		panic(fmt.Sprintf("ebiten: len(vertices) * len(instances) must be less than or equal to MaxVertexCount but was %d * %d", len(vertices), len(instances)))
+	}
+
+	if options == nil {
+		options = &DrawTrianglesOptions{}
+	}
+
+	var blend graphicsdriver.Blend
+	if options.CompositeMode == CompositeModeCustom {
+		blend = options.Blend.internalBlend()
+	} else {
+		blend = options.CompositeMode.blend().internalBlend()
+	}
+	if blend.IsDualSource() {
+		panic("ebiten: dual-source blend factors are not available at DrawTrianglesInstanced; use a shader returning two colors instead")
+	}
+
+	addressX, addressY := addressesPerAxis(options.Address, options.AddressX, options.AddressY)
+	filter := builtinshader.Filter(options.Filter)
+
+	colorm, cr, cg, cb, ca := colorMToScale(options.ColorM.affineColorM())
+
+	vs := i.ensureTmpVertices(len(instances) * len(vertices) * graphics.VertexFloatCount)
+	is := i.ensureTmpIndices(len(instances) * len(indices))
+	straightAlpha := options.ColorScaleMode == ColorScaleModeStraightAlpha
+	dst := i
+	for k := range instances {
+		inst := &instances[k]
+		a, b, c, d, tx, ty := inst.GeoM.elements32()
+		ir, ig, ib, ia := inst.ColorScale.elements()
+
+		vs := vs[k*len(vertices)*graphics.VertexFloatCount:]
+		for i, v := range vertices {
+			x := a*v.DstX + b*v.DstY + tx
+			y := c*v.DstX + d*v.DstY + ty
+			dx, dy := dst.adjustPositionF32(x, y)
+			vs[i*graphics.VertexFloatCount] = dx
+			vs[i*graphics.VertexFloatCount+1] = dy
+			sx, sy := img.adjustPositionF32(v.SrcX, v.SrcY)
+			vs[i*graphics.VertexFloatCount+2] = sx
+			vs[i*graphics.VertexFloatCount+3] = sy
+			r, g, b := v.ColorR, v.ColorG, v.ColorB
+			if straightAlpha {
+				r, g, b = r*v.ColorA, g*v.ColorA, b*v.ColorA
+			}
+			vs[i*graphics.VertexFloatCount+4] = r * cr * ir
+			vs[i*graphics.VertexFloatCount+5] = g * cg * ig
+			vs[i*graphics.VertexFloatCount+6] = b * cb * ib
+			vs[i*graphics.VertexFloatCount+7] = v.ColorA * ca * ia
+			vs[i*graphics.VertexFloatCount+8] = v.Custom0
+		}
+
+		is := is[k*len(indices):]
+		offset := uint32(k * len(vertices))
+		for i, idx := range indices {
+			is[i] = uint32(idx) + offset
+		}
+	}
+
+	i.submitTriangles(vs, is, img, blend, filter, addressX, addressY, colorm, options)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestImageDrawTrianglesInstanced(t *testing.T) {
+	src := ebiten.NewImage(1, 1)
+	src.Fill(color.White)
+
+	vs := quadVertices(0, 0, 4, 4)
+	is := []uint16{0, 1, 2, 1, 2, 3}
+
+	instances := make([]ebiten.Instance, 4)
+	for i := range instances {
+		instances[i].GeoM.Translate(float64(4*i), float64(4*i))
+	}
+	instances[1].ColorScale.Scale(1, 0, 0, 1)
+
+	dst := ebiten.NewImage(16, 16)
+	dst.DrawTrianglesInstanced(vs, is, src, instances, nil)
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if i/4 == j/4 {
+				want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+				if i/4 == 1 {
+					want = color.RGBA{R: 0xff, A: 0xff}
+				}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDrawTrianglesInstancedOutOfRange(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawTrianglesInstanced must panic but not")
+		}
+	}()
+
+	dst := ebiten.NewImage(16, 16)
+	src := ebiten.NewImage(16, 16)
+	vs := make([]ebiten.Vertex, 4)
+	is := []uint16{0, 1, 2, 1, 2, 4}
+	dst.DrawTrianglesInstanced(vs, is, src, make([]ebiten.Instance, 2), nil)
+}
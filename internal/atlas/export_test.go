@@ -15,7 +15,7 @@
 package atlas
 
 const (
-	BaseCountToPutOnSourceBackend = baseCountToPutOnSourceBackend
+	BaseCountToPutOnSourceBackend = defaultBaseCountToPutOnSourceBackend
 )
 
 func PutImagesOnSourceBackendForTesting() {
@@ -54,6 +54,18 @@ func (i *Image) IsOnSourceBackendForTesting() bool {
 	return i.isOnSourceBackend()
 }
 
+func (i *Image) IsOnEvacuatedBackendForTesting() bool {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	return i.isOnEvacuatedBackend()
+}
+
+func EvacuateImagesForTesting() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	evacuateImages()
+}
+
 func (i *Image) EnsureIsolatedFromSourceForTesting(backends []*backend) {
 	backendsM.Lock()
 	defer backendsM.Unlock()
@@ -62,7 +62,9 @@ func flushDeferred() {
 
 // baseCountToPutOnSourceBackend represents the base time duration when the image can be put onto an atlas.
 // Actual time duration is increased in an exponential way for each usage as a rendering target.
-const baseCountToPutOnSourceBackend = 10
+var baseCountToPutOnSourceBackend int64 = defaultBaseCountToPutOnSourceBackend
+
+const defaultBaseCountToPutOnSourceBackend = 10
 
 func putImagesOnSourceBackend() {
 	// The counter usedAsDestinationCount is updated at most once per frame (#2676).
@@ -79,7 +81,8 @@ func putImagesOnSourceBackend() {
 		if i.usedAsSourceCount < math.MaxInt {
 			i.usedAsSourceCount++
 		}
-		if i.usedAsSourceCount >= baseCountToPutOnSourceBackend*(1<<uint(min(i.usedAsDestinationCount, 31))) {
+		// A pinned image is put onto an atlas without waiting.
+		if i.pinned || i.usedAsSourceCount >= baseCountToPutOnSourceBackend*(1<<uint(min(i.usedAsDestinationCount, 31))) {
 			i.putOnSourceBackend()
 			i.usedAsSourceCount = 0
 		}
@@ -109,10 +112,14 @@ type backend struct {
 	// sourceInThisFrame reports whether this backend is used as a source in this frame.
 	// sourceInThisFrame is reset every frame.
 	sourceInThisFrame bool
+
+	// evacuated reports whether the images on this backend should be moved to other backends.
+	// No new image is allocated on an evacuated backend.
+	evacuated bool
 }
 
 func (b *backend) tryAlloc(width, height int) (*packing.Node, bool) {
-	if b.page == nil {
+	if b.page == nil || b.evacuated {
 		return nil, false
 	}
 	n := b.page.Alloc(width, height)
@@ -135,7 +142,7 @@ func (b *backend) extendIfNeeded(width, height int) {
 	}
 
 	// Assume that the screen image is never extended.
-	newImg := newClearedImage(width, height, false)
+	newImg := newClearedImage(width, height, false, graphicsdriver.PixelFormatRGBA8, 0)
 
 	srcs := [graphics.ShaderSrcImageCount]*graphicscommand.Image{b.image}
 	sw, sh := b.image.InternalSize()
@@ -143,7 +150,7 @@ func (b *backend) extendIfNeeded(width, height int) {
 	graphics.QuadVerticesFromDstAndSrc(vs, 0, 0, float32(sw), float32(sh), 0, 0, float32(sw), float32(sh), 1, 1, 1, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, sw, sh)
-	newImg.DrawTriangles(srcs, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader.ensureShader(), nil, graphicsdriver.FillRuleFillAll)
+	newImg.DrawTriangles(srcs, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader.ensureShader(), nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	b.image.Dispose()
 
 	b.image = newImg
@@ -152,10 +159,16 @@ func (b *backend) extendIfNeeded(width, height int) {
 }
 
 // newClearedImage creates an emtpy image with the given size.
+// If samples is more than 1, the image is multisampled.
 //
 // Note that Dispose is not called automatically.
-func newClearedImage(width, height int, screen bool) *graphicscommand.Image {
-	i := graphicscommand.NewImage(width, height, screen)
+func newClearedImage(width, height int, screen bool, format graphicsdriver.PixelFormat, samples int) *graphicscommand.Image {
+	var i *graphicscommand.Image
+	if samples > 1 {
+		i = graphicscommand.NewMultisampledImage(width, height, format, samples)
+	} else {
+		i = graphicscommand.NewImage(width, height, screen, format)
+	}
 
 	// This needs to use 'InternalSize' to render the whole region, or edges are unexpectedly cleared on some
 	// devices.
@@ -168,7 +181,7 @@ func clearImage(i *graphicscommand.Image, region image.Rectangle) {
 	vs := make([]float32, 4*graphics.VertexFloatCount)
 	graphics.QuadVerticesFromDstAndSrc(vs, float32(region.Min.X), float32(region.Min.Y), float32(region.Max.X), float32(region.Max.Y), 0, 0, 0, 0, 0, 0, 0, 0)
 	is := graphics.QuadIndices()
-	i.DrawTriangles([graphics.ShaderSrcImageCount]*graphicscommand.Image{}, vs, is, graphicsdriver.BlendClear, region, [graphics.ShaderSrcImageCount]image.Rectangle{}, clearShader.ensureShader(), nil, graphicsdriver.FillRuleFillAll)
+	i.DrawTriangles([graphics.ShaderSrcImageCount]*graphicscommand.Image{}, vs, is, graphicsdriver.BlendClear, region, [graphics.ShaderSrcImageCount]image.Rectangle{}, clearShader.ensureShader(), nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 }
 
 func (b *backend) clearPixels(region image.Rectangle) {
@@ -205,8 +218,19 @@ var (
 
 	imagesUsedAsDestination smallImageSet
 
+	imagesToEvacuate smallImageSet
+
 	graphicsDriverInitialized bool
 
+	// imageCopyAvailable reports whether the graphics driver can copy pixels between images without rendering.
+	imageCopyAvailable bool
+
+	// multipleRenderTargetsAvailable reports whether the graphics driver can render to multiple images at once.
+	multipleRenderTargetsAvailable bool
+
+	// featuresAvailable reports whether the graphics driver supports each optional feature.
+	featuresAvailable [graphicsdriver.FeatureCount]bool
+
 	deferred []func()
 
 	// deferredM is a mutex for the slice operations. This must not be used for other usages.
@@ -233,6 +257,15 @@ type Image struct {
 	width     int
 	height    int
 	imageType ImageType
+	format    graphicsdriver.PixelFormat
+
+	// samples is the number of samples per pixel for multisample anti-aliasing.
+	// samples is valid only for an unmanaged image.
+	samples int
+
+	// nativeTexture is a handle of a native texture that the image refers to.
+	// nativeTexture is valid only for an unmanaged image.
+	nativeTexture uintptr
 
 	backend                   *backend
 	backendCreatedInThisFrame bool
@@ -257,6 +290,15 @@ type Image struct {
 	//
 	// usedAsDestinationCount is never reset.
 	usedAsDestinationCount int
+
+	// pinned reports whether the image should be on a source backend whenever possible.
+	// A pinned image is put onto a source backend again at the end of the frame after it is modified,
+	// regardless of usedAsSourceCount.
+	pinned bool
+
+	// offAtlas reports whether the image is kept off atlases.
+	// An image rendered with multiple render targets must not be on an atlas so that all the targets have the same regions.
+	offAtlas bool
 }
 
 // moveTo moves its content to the given image dst.
@@ -264,8 +306,14 @@ type Image struct {
 //
 // moveTo is similar to C++'s move semantics.
 func (i *Image) moveTo(dst *Image) {
+	// pinned and offAtlas are not contents but properties of dst.
+	pinned := dst.pinned
+	offAtlas := dst.offAtlas
+
 	dst.deallocate()
 	*dst = *i
+	dst.pinned = pinned
+	dst.offAtlas = offAtlas
 
 	// i is no longer available but the finalizer must not be called
 	// since i and dst share the same backend and the same node.
@@ -283,6 +331,10 @@ func (i *Image) isOnSourceBackend() bool {
 	return i.backend.source
 }
 
+func (i *Image) isOnEvacuatedBackend() bool {
+	return i.isOnAtlas() && i.backend.evacuated
+}
+
 func (i *Image) resetUsedAsSourceCount() {
 	i.usedAsSourceCount = 0
 	imagesToPutOnSourceBackend.remove(i)
@@ -290,7 +342,7 @@ func (i *Image) resetUsedAsSourceCount() {
 
 func (i *Image) paddingSize() int {
 	if i.imageType == ImageTypeRegular {
-		return 1
+		return paddingSizeForRegularImages
 	}
 	return 0
 }
@@ -331,7 +383,7 @@ func (i *Image) ensureIsolatedFromSource(backends []*backend) {
 		return
 	}
 
-	newI := NewImage(i.width, i.height, i.imageType)
+	newI := NewImage(i.width, i.height, i.imageType, i.format)
 
 	// Call allocate explicitly in order to have an isolated backend from the specified backends.
 	// `sourceInThisFrame` of `backends` should be true, so `backends` should be in `bs`.
@@ -349,7 +401,7 @@ func (i *Image) ensureIsolatedFromSource(backends []*backend) {
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, i.width, i.height)
 
-	newI.drawTriangles([graphics.ShaderSrcImageCount]*Image{i}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	newI.drawTriangles([graphics.ShaderSrcImageCount]*Image{i}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	newI.moveTo(i)
 }
 
@@ -371,7 +423,7 @@ func (i *Image) putOnSourceBackend() {
 		panic(fmt.Sprintf("atlas: the image type must be ImageTypeRegular but %d", i.imageType))
 	}
 
-	newI := NewImage(i.width, i.height, ImageTypeRegular)
+	newI := NewImage(i.width, i.height, ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	newI.allocate(nil, true)
 
 	w, h := float32(i.width), float32(i.height)
@@ -379,7 +431,7 @@ func (i *Image) putOnSourceBackend() {
 	graphics.QuadVerticesFromDstAndSrc(vs, 0, 0, w, h, 0, 0, w, h, 1, 1, 1, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, i.width, i.height)
-	newI.drawTriangles([graphics.ShaderSrcImageCount]*Image{i}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	newI.drawTriangles([graphics.ShaderSrcImageCount]*Image{i}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	newI.moveTo(i)
 	i.usedAsSourceCount = 0
@@ -389,6 +441,30 @@ func (i *Image) putOnSourceBackend() {
 	}
 }
 
+// evacuate moves the image from an evacuated backend to another backend.
+func (i *Image) evacuate() {
+	if !i.isOnEvacuatedBackend() {
+		return
+	}
+
+	newI := NewImage(i.width, i.height, i.imageType, i.format)
+	newI.allocate(nil, i.backend.source)
+
+	w, h := float32(i.width), float32(i.height)
+	vs := make([]float32, 4*graphics.VertexFloatCount)
+	graphics.QuadVerticesFromDstAndSrc(vs, 0, 0, w, h, 0, 0, w, h, 1, 1, 1, 1)
+	is := graphics.QuadIndices()
+	dr := image.Rect(0, 0, i.width, i.height)
+	newI.drawTriangles([graphics.ShaderSrcImageCount]*Image{i}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+
+	// Keep the counters, as evacuation is not a usage of the image.
+	usedAsSourceCount := i.usedAsSourceCount
+	usedAsDestinationCount := i.usedAsDestinationCount
+	newI.moveTo(i)
+	i.usedAsSourceCount = usedAsSourceCount
+	i.usedAsDestinationCount = usedAsDestinationCount
+}
+
 func (i *Image) regionWithPadding() image.Rectangle {
 	if i.backend == nil {
 		panic("atlas: backend must not be nil: not allocated yet?")
@@ -411,7 +487,33 @@ func (i *Image) regionWithPadding() image.Rectangle {
 //	5: Color G
 //	6: Color B
 //	7: Color Y
-func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) {
+func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		vs := make([]float32, len(vertices))
+		copy(vs, vertices)
+		is := make([]uint32, len(indices))
+		copy(is, indices)
+		us := make([]uint32, len(uniforms))
+		copy(us, uniforms)
+
+		appendDeferred(func() {
+			i.drawTriangles(srcs, vs, is, blend, dstRegion, srcRegions, shader, us, fillRule, depthMode, stencil)
+		})
+		return
+	}
+
+	i.drawTriangles(srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+}
+
+// DrawTrianglesToMultipleTargets draws triangles to the image and the additional images at once.
+// The k-th output of the shader's fragment entry point is rendered to the image when k is 0, or to additionalDsts[k-1] otherwise.
+//
+// If the graphics driver doesn't support multiple render targets, or the images are not suitable for them,
+// DrawTrianglesToMultipleTargets draws triangles for each image one by one.
+func (i *Image) DrawTrianglesToMultipleTargets(additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
 	backendsM.Lock()
 	defer backendsM.Unlock()
 
@@ -424,19 +526,56 @@ func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 		copy(us, uniforms)
 
 		appendDeferred(func() {
-			i.drawTriangles(srcs, vs, is, blend, dstRegion, srcRegions, shader, us, fillRule)
+			i.drawTrianglesToMultipleTargets(additionalDsts, srcs, vs, is, blend, dstRegion, srcRegions, shader, us, fillRule, depthMode, stencil)
 		})
 		return
 	}
 
-	i.drawTriangles(srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule)
+	i.drawTrianglesToMultipleTargets(additionalDsts, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
 }
 
-func (i *Image) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) {
+func (i *Image) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
+	i.drawTrianglesToMultipleTargets([graphics.ShaderDstImageCount - 1]*Image{}, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+}
+
+func (i *Image) drawTrianglesToMultipleTargets(additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
 	if len(vertices) == 0 {
 		return
 	}
 
+	if shader.isDualSource() && !featuresAvailable[graphicsdriver.FeatureDualSourceBlending] {
+		// Render only the primary source color, and use the primary source color instead of the secondary one for blending.
+		// The shader returning two colors is not used at all, as it might not be compiled in this environment.
+		shader = shader.fragmentOutputShader(0)
+		blend = blend.PrimarySourceOnly()
+	}
+
+	// This slice is not escaped to the heap. This can be checked by `go build -gcflags=-m`.
+	dsts := make([]*Image, 0, graphics.ShaderDstImageCount)
+	dsts = append(dsts, i)
+	for _, dst := range additionalDsts {
+		if dst == nil {
+			break
+		}
+		dsts = append(dsts, dst)
+	}
+
+	if len(dsts) > 1 {
+		if !i.canDrawToMultipleTargets(dsts[1:]) {
+			// Render each output of the shader one by one.
+			for k, dst := range dsts {
+				// Drawing modifies the vertices. Use a copy.
+				vs := make([]float32, len(vertices))
+				copy(vs, vertices)
+				dst.drawTriangles(srcs, vs, indices, blend, dstRegion, srcRegions, shader.fragmentOutputShader(k), uniforms, fillRule, depthMode, stencil)
+			}
+			return
+		}
+		for _, dst := range dsts {
+			dst.ensureOffAtlas()
+		}
+	}
+
 	// This slice is not escaped to the heap. This can be checked by `go build -gcflags=-m`.
 	backends := make([]*backend, 0, len(srcs))
 	for _, src := range srcs {
@@ -452,13 +591,20 @@ func (i *Image) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 		src.backend.sourceInThisFrame = true
 	}
 
-	i.ensureIsolatedFromSource(backends)
+	for _, dst := range dsts {
+		dst.ensureIsolatedFromSource(backends)
+	}
 
 	for _, src := range srcs {
 		// Compare i and source images after ensuring i is not on an atlas, or
 		// i and a source image might share the same atlas even though i != src.
-		if src != nil && i.backend.image == src.backend.image {
-			panic("atlas: Image.DrawTriangles: source must be different from the receiver")
+		if src == nil {
+			continue
+		}
+		for _, dst := range dsts {
+			if dst.backend.image == src.backend.image {
+				panic("atlas: Image.DrawTriangles: source must be different from the receiver")
+			}
 		}
 	}
 
@@ -518,7 +664,15 @@ func (i *Image) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 		imgs[i] = src.backend.image
 	}
 
-	i.backend.image.DrawTriangles(imgs, vertices, indices, blend, dstRegion, srcRegions, shader.ensureShader(), uniforms, fillRule)
+	if len(dsts) > 1 {
+		var dstImgs [graphics.ShaderDstImageCount - 1]*graphicscommand.Image
+		for k, dst := range dsts[1:] {
+			dstImgs[k] = dst.backend.image
+		}
+		i.backend.image.DrawTrianglesToMultipleTargets(dstImgs, imgs, vertices, indices, blend, dstRegion, srcRegions, shader.ensureShader(), uniforms, fillRule, depthMode, stencil)
+	} else {
+		i.backend.image.DrawTriangles(imgs, vertices, indices, blend, dstRegion, srcRegions, shader.ensureShader(), uniforms, fillRule, depthMode, stencil)
+	}
 
 	for _, src := range srcs {
 		if src == nil {
@@ -528,7 +682,162 @@ func (i *Image) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 			// src might already registered, but assigning it again is not harmful.
 			imagesToPutOnSourceBackend.add(src)
 		}
+		if src.isOnEvacuatedBackend() {
+			imagesToEvacuate.add(src)
+		}
+	}
+	for _, dst := range dsts {
+		if dst.isOnEvacuatedBackend() {
+			imagesToEvacuate.add(dst)
+		}
+	}
+}
+
+// canDrawToMultipleTargets reports whether the image and the additional images can be rendered at once with multiple render targets.
+func (i *Image) canDrawToMultipleTargets(additionalDsts []*Image) bool {
+	if !multipleRenderTargetsAvailable {
+		return false
+	}
+	if i.imageType == ImageTypeScreen || i.samples > 1 || i.nativeTexture != 0 {
+		return false
+	}
+	for _, dst := range additionalDsts {
+		// The backends must have the same size.
+		if dst.width != i.width || dst.height != i.height || dst.imageType != i.imageType || dst.format != i.format {
+			return false
+		}
+		if dst.samples > 1 || dst.nativeTexture != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureOffAtlas moves the image to its own backend if the image is on an atlas, and keeps the image off atlases after that.
+func (i *Image) ensureOffAtlas() {
+	if i.offAtlas {
+		return
+	}
+	i.offAtlas = true
+	imagesToPutOnSourceBackend.remove(i)
+
+	if i.backend == nil || !i.isOnAtlas() {
+		return
+	}
+
+	newI := NewImage(i.width, i.height, i.imageType, i.format)
+	newI.offAtlas = true
+	newI.allocate(nil, false)
+
+	w, h := float32(i.width), float32(i.height)
+	vs := make([]float32, 4*graphics.VertexFloatCount)
+	graphics.QuadVerticesFromDstAndSrc(vs, 0, 0, w, h, 0, 0, w, h, 1, 1, 1, 1)
+	is := graphics.QuadIndices()
+	dr := image.Rect(0, 0, i.width, i.height)
+
+	newI.drawTriangles([graphics.ShaderSrcImageCount]*Image{i}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+	newI.moveTo(i)
+}
+
+// CopyFrom copies the pixels in srcRegion of src to the image at dstPoint.
+//
+// If the graphics driver can copy pixels without rendering, CopyFrom uses it. Otherwise, CopyFrom renders the pixels.
+func (i *Image) CopyFrom(src *Image, dstPoint image.Point, srcRegion image.Rectangle) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			i.copyFrom(src, dstPoint, srcRegion)
+		})
+		return
+	}
+
+	i.copyFrom(src, dstPoint, srcRegion)
+}
+
+func (i *Image) copyFrom(src *Image, dstPoint image.Point, srcRegion image.Rectangle) {
+	if srcRegion.Empty() {
+		return
+	}
+
+	if !imageCopyAvailable || i.imageType == ImageTypeScreen || i.samples > 1 || i.format != src.format {
+		dx0, dy0 := float32(dstPoint.X), float32(dstPoint.Y)
+		dx1, dy1 := dx0+float32(srcRegion.Dx()), dy0+float32(srcRegion.Dy())
+		sx0, sy0 := float32(srcRegion.Min.X), float32(srcRegion.Min.Y)
+		sx1, sy1 := float32(srcRegion.Max.X), float32(srcRegion.Max.Y)
+		vs := make([]float32, 4*graphics.VertexFloatCount)
+		graphics.QuadVerticesFromDstAndSrc(vs, dx0, dy0, dx1, dy1, sx0, sy0, sx1, sy1, 1, 1, 1, 1)
+		is := graphics.QuadIndices()
+		dr := image.Rectangle{Min: dstPoint, Max: dstPoint.Add(srcRegion.Size())}
+		i.drawTriangles([graphics.ShaderSrcImageCount]*Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+		return
+	}
+
+	if src.backend == nil {
+		src.allocate(nil, true)
+	}
+	src.backend.sourceInThisFrame = true
+
+	i.ensureIsolatedFromSource([]*backend{src.backend})
+
+	if i.backend.image == src.backend.image {
+		panic("atlas: Image.CopyFrom: source must be different from the receiver")
+	}
+
+	dstPoint = dstPoint.Add(i.regionWithPadding().Min)
+	srcRegion = srcRegion.Add(src.regionWithPadding().Min)
+	i.backend.image.CopyFrom(src.backend.image, dstPoint, srcRegion)
+
+	if !src.isOnSourceBackend() && src.canBePutOnAtlas() {
+		imagesToPutOnSourceBackend.add(src)
 	}
+	if src.isOnEvacuatedBackend() {
+		imagesToEvacuate.add(src)
+	}
+	if i.isOnEvacuatedBackend() {
+		imagesToEvacuate.add(i)
+	}
+}
+
+// DispatchCompute executes the compute kernel shader with x * y * z workgroups.
+// images are bound to the storage images of the kernel in order.
+//
+// A storage image is accessed with the coordinates of its texture, so the images are kept off atlases.
+// If compute kernels are not available with the graphics driver, DispatchCompute does nothing.
+func DispatchCompute(shader *Shader, images []*Image, uniforms []uint32, x, y, z int) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		// The slices might be reused by the caller.
+		images = append([]*Image(nil), images...)
+		uniforms = append([]uint32(nil), uniforms...)
+		appendDeferred(func() {
+			dispatchCompute(shader, images, uniforms, x, y, z)
+		})
+		return
+	}
+
+	dispatchCompute(shader, images, uniforms, x, y, z)
+}
+
+func dispatchCompute(shader *Shader, images []*Image, uniforms []uint32, x, y, z int) {
+	if !featuresAvailable[graphicsdriver.FeatureCompute] {
+		return
+	}
+
+	imgs := make([]*graphicscommand.Image, len(images))
+	for k, img := range images {
+		if img.imageType == ImageTypeScreen || img.samples > 1 || img.nativeTexture != 0 || img.format != graphicsdriver.PixelFormatRGBA8 {
+			panic("atlas: a storage image must be an RGBA8 image that is neither the screen, multisampled, nor a native texture")
+		}
+		img.ensureOffAtlas()
+		img.ensureIsolatedFromSource(nil)
+		imgs[k] = img.backend.image
+	}
+
+	graphicscommand.DispatchCompute(shader.ensureShader(), imgs, uniforms, x, y, z)
 }
 
 // WritePixels replaces the pixels on the image.
@@ -550,7 +859,8 @@ func (i *Image) WritePixels(pix []byte, region image.Rectangle) {
 }
 
 func (i *Image) writePixels(pix []byte, region image.Rectangle) {
-	if l := 4 * region.Dx() * region.Dy(); len(pix) != l {
+	bpp := i.format.BytesPerPixel()
+	if l := bpp * region.Dx() * region.Dy(); len(pix) != l {
 		panic(fmt.Sprintf("atlas: len(p) must be %d but %d", l, len(pix)))
 	}
 
@@ -564,6 +874,11 @@ func (i *Image) writePixels(pix []byte, region image.Rectangle) {
 		i.allocate(nil, true)
 	}
 
+	if i.samples > 1 {
+		i.writePixelsByDrawing(pix, region)
+		return
+	}
+
 	r := i.regionWithPadding()
 
 	if !region.Eq(image.Rect(0, 0, i.width, i.height)) || i.paddingSize() == 0 {
@@ -582,34 +897,48 @@ func (i *Image) writePixels(pix []byte, region image.Rectangle) {
 		return
 	}
 
-	// TODO: These loops assume that paddingSize is 1.
 	// TODO: Is clearing edges explicitly really needed?
-	const paddingSize = 1
-	if paddingSize != i.paddingSize() {
-		panic(fmt.Sprintf("atlas: writePixels assumes the padding is always 1 but the actual padding was %d", i.paddingSize()))
-	}
-
-	pixb := graphics.NewManagedBytes(4*r.Dx()*r.Dy(), func(bs []byte) {
-		// Clear the edges. bs might not be zero-cleared.
-		rowPixels := 4 * r.Dx()
-		for i := 0; i < rowPixels; i++ {
-			bs[rowPixels*(r.Dy()-1)+i] = 0
-		}
-		for j := 1; j < r.Dy(); j++ {
-			bs[rowPixels*j-4] = 0
-			bs[rowPixels*j-3] = 0
-			bs[rowPixels*j-2] = 0
-			bs[rowPixels*j-1] = 0
-		}
-
-		// Copy the content.
+	pixb := graphics.NewManagedBytes(bpp*r.Dx()*r.Dy(), func(bs []byte) {
+		// Copy the content and clear the edges. bs might not be zero-cleared.
+		rowPixels := bpp * r.Dx()
 		for j := 0; j < region.Dy(); j++ {
-			copy(bs[4*j*r.Dx():], pix[4*j*region.Dx():4*(j+1)*region.Dx()])
+			copy(bs[rowPixels*j:], pix[bpp*j*region.Dx():bpp*(j+1)*region.Dx()])
+			for i := rowPixels*j + bpp*region.Dx(); i < rowPixels*(j+1); i++ {
+				bs[i] = 0
+			}
+		}
+		for i := rowPixels * region.Dy(); i < len(bs); i++ {
+			bs[i] = 0
 		}
 	})
 	i.backend.writePixels(pixb, r)
 }
 
+// writePixelsByDrawing writes the pixels to a temporary image and draws it onto the image.
+// This is used for a multisampled image, where pixels cannot be written directly.
+func (i *Image) writePixelsByDrawing(pix []byte, region image.Rectangle) {
+	region = region.Add(i.regionWithPadding().Min)
+
+	if pix == nil {
+		i.backend.clearPixels(region)
+		return
+	}
+
+	tmp := graphicscommand.NewImage(region.Dx(), region.Dy(), false, i.format)
+	// Copy pixels in the case when pix is modified before the graphics command is executed.
+	pix2 := graphics.NewManagedBytes(len(pix), func(bs []byte) {
+		copy(bs, pix)
+	})
+	tmp.WritePixels(pix2, image.Rect(0, 0, region.Dx(), region.Dy()))
+
+	srcs := [graphics.ShaderSrcImageCount]*graphicscommand.Image{tmp}
+	vs := make([]float32, 4*graphics.VertexFloatCount)
+	graphics.QuadVerticesFromDstAndSrc(vs, float32(region.Min.X), float32(region.Min.Y), float32(region.Max.X), float32(region.Max.Y), 0, 0, float32(region.Dx()), float32(region.Dy()), 1, 1, 1, 1)
+	is := graphics.QuadIndices()
+	i.backend.image.DrawTriangles(srcs, vs, is, graphicsdriver.BlendCopy, region, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader.ensureShader(), nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+	tmp.Dispose()
+}
+
 func (i *Image) ReadPixels(graphicsDriver graphicsdriver.Graphics, pixels []byte, region image.Rectangle) (ok bool, err error) {
 	backendsM.Lock()
 	defer backendsM.Unlock()
@@ -648,6 +977,37 @@ func (i *Image) readPixels(graphicsDriver graphicsdriver.Graphics, pixels []byte
 	return nil
 }
 
+// ReadPixelsAsync starts reading the pixels without waiting for the GPU.
+//
+// callback is called after the pixels are read. pixels must not be accessed until then.
+func (i *Image) ReadPixelsAsync(pixels []byte, region image.Rectangle, callback func(err error)) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			i.readPixelsAsync(pixels, region, callback)
+		})
+		return
+	}
+
+	i.readPixelsAsync(pixels, region, callback)
+}
+
+func (i *Image) readPixelsAsync(pixels []byte, region image.Rectangle, callback func(err error)) {
+	if i.backend == nil {
+		// Allocate the image so that the pixels are read in the same way as an allocated image.
+		i.allocate(nil, true)
+	}
+
+	i.backend.image.ReadPixelsAsync([]graphicsdriver.PixelsArgs{
+		{
+			Pixels: pixels,
+			Region: region.Add(i.regionWithPadding().Min),
+		},
+	}, callback)
+}
+
 // Deallocate deallocates the internal state.
 // Even after this call, the image is still available as a new cleared image.
 func (i *Image) Deallocate() {
@@ -675,6 +1035,7 @@ func (i *Image) deallocate() {
 	i.resetUsedAsSourceCount()
 	i.usedAsDestinationCount = 0
 	imagesUsedAsDestination.remove(i)
+	imagesToEvacuate.remove(i)
 
 	if i.backend == nil {
 		// Not allocated yet.
@@ -706,13 +1067,82 @@ func (i *Image) deallocate() {
 	panic("atlas: backend not found at an image being deallocated")
 }
 
-func NewImage(width, height int, imageType ImageType) *Image {
+func NewImage(width, height int, imageType ImageType, format graphicsdriver.PixelFormat) *Image {
+	if format != graphicsdriver.PixelFormatRGBA8 && imageType == ImageTypeRegular {
+		panic(fmt.Sprintf("atlas: an image with %s cannot be on an atlas", format))
+	}
+
 	// Actual allocation is done lazily, and the lock is not needed.
 	return &Image{
 		width:     width,
 		height:    height,
 		imageType: imageType,
+		format:    format,
+	}
+}
+
+// SetSamples sets the number of samples per pixel for multisample anti-aliasing.
+//
+// SetSamples must be called on an unmanaged image before the image is used.
+//
+// If the graphics driver doesn't support FeatureMultisampling, e.g. Metal and DirectX,
+// the image is rendered with one sample per pixel.
+func (i *Image) SetSamples(samples int) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if i.imageType != ImageTypeUnmanaged {
+		panic("atlas: SetSamples is available only for an unmanaged image")
+	}
+	if i.backend != nil {
+		panic("atlas: SetSamples must be called before the image is allocated")
+	}
+	i.samples = samples
+}
+
+// SetNativeTexture makes the image refer to the given native texture instead of allocating a new texture.
+//
+// SetNativeTexture must be called on an unmanaged image before the image is used.
+func (i *Image) SetNativeTexture(handle uintptr) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if i.imageType != ImageTypeUnmanaged {
+		panic("atlas: SetNativeTexture is available only for an unmanaged image")
+	}
+	if i.format != graphicsdriver.PixelFormatRGBA8 {
+		panic("atlas: SetNativeTexture is available only for an image with PixelFormatRGBA8")
+	}
+	if i.backend != nil {
+		panic("atlas: SetNativeTexture must be called before the image is allocated")
 	}
+	i.nativeTexture = handle
+}
+
+// PinToAtlas puts the image onto a source backend immediately, and keeps the image on it whenever possible.
+//
+// PinToAtlas does nothing if the image cannot be on an atlas.
+func (i *Image) PinToAtlas() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			i.pinToAtlas()
+		})
+		return
+	}
+
+	i.pinToAtlas()
+}
+
+func (i *Image) pinToAtlas() {
+	if !i.canBePutOnAtlas() {
+		return
+	}
+	i.pinned = true
+	i.putOnSourceBackend()
+	i.resetUsedAsSourceCount()
 }
 
 func (i *Image) canBePutOnAtlas() bool {
@@ -722,7 +1152,10 @@ func (i *Image) canBePutOnAtlas() bool {
 	if i.imageType != ImageTypeRegular {
 		return false
 	}
-	return i.width+i.paddingSize() <= maxSize && i.height+i.paddingSize() <= maxSize
+	if i.offAtlas {
+		return false
+	}
+	return i.width+i.paddingSize() <= maxAtlasSize() && i.height+i.paddingSize() <= maxAtlasSize()
 }
 
 func (i *Image) finalize() {
@@ -753,8 +1186,14 @@ func (i *Image) allocate(forbiddenBackends []*backend, asSource bool) {
 			panic(fmt.Sprintf("atlas: the image being put on an atlas is too big: width: %d, height: %d", i.width, i.height))
 		}
 
+		var img *graphicscommand.Image
+		if i.nativeTexture != 0 {
+			img = graphicscommand.NewImageFromNativeTexture(i.nativeTexture, wp, hp)
+		} else {
+			img = newClearedImage(wp, hp, i.imageType == ImageTypeScreen, i.format, i.samples)
+		}
 		i.backend = &backend{
-			image:  newClearedImage(wp, hp, i.imageType == ImageTypeScreen),
+			image:  img,
 			width:  wp,
 			height: hp,
 			source: asSource && i.imageType == ImageTypeRegular,
@@ -782,30 +1221,31 @@ loop:
 		}
 	}
 
+	maxAtlasSize := maxAtlasSize()
 	var width, height int
 	if asSource {
-		width, height = minSourceSize, minSourceSize
+		width, height = min(minSourceSize, maxAtlasSize), min(minSourceSize, maxAtlasSize)
 	} else {
-		width, height = minDestinationSize, minDestinationSize
+		width, height = min(minDestinationSize, maxAtlasSize), min(minDestinationSize, maxAtlasSize)
 	}
 	for wp > width {
-		if width == maxSize {
+		if width >= maxAtlasSize {
 			panic(fmt.Sprintf("atlas: the image being put on an atlas is too big: width: %d, height: %d", i.width, i.height))
 		}
 		width *= 2
 	}
 	for hp > height {
-		if height == maxSize {
+		if height >= maxAtlasSize {
 			panic(fmt.Sprintf("atlas: the image being put on an atlas is too big: width: %d, height: %d", i.width, i.height))
 		}
 		height *= 2
 	}
 
 	b := &backend{
-		image:  newClearedImage(width, height, false),
+		image:  newClearedImage(width, height, false, graphicsdriver.PixelFormatRGBA8, 0),
 		width:  width,
 		height: height,
-		page:   packing.NewPage(width, height, maxSize),
+		page:   packing.NewPage(width, height, maxAtlasSize),
 		source: asSource,
 	}
 	theBackends = append(theBackends, b)
@@ -909,6 +1349,12 @@ func BeginFrame(graphicsDriver graphicsdriver.Graphics) error {
 			maxSize = floorPowerOf2(graphicscommand.MaxImageSize(graphicsDriver))
 		}
 
+		imageCopyAvailable = graphicscommand.IsImageCopyAvailable(graphicsDriver)
+		multipleRenderTargetsAvailable = graphicscommand.IsMultipleRenderTargetsAvailable(graphicsDriver)
+		for f := range featuresAvailable {
+			featuresAvailable[f] = graphicscommand.IsFeatureAvailable(graphicsDriver, graphicsdriver.Feature(f))
+		}
+
 		graphicsDriverInitialized = true
 	})
 	if err != nil {
@@ -917,10 +1363,83 @@ func BeginFrame(graphicsDriver graphicsdriver.Graphics) error {
 
 	flushDeferred()
 	putImagesOnSourceBackend()
+	evacuateImages()
 
 	return nil
 }
 
+// IsFeatureAvailable reports whether the optional feature is available with the graphics driver.
+// IsFeatureAvailable returns false before the graphics driver is initialized at the first BeginFrame.
+func IsFeatureAvailable(feature graphicsdriver.Feature) bool {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !graphicsDriverInitialized {
+		return false
+	}
+	return featuresAvailable[feature]
+}
+
+// BeginGPUScope starts a GPU timing scope with the given name.
+func BeginGPUScope(name string) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			graphicscommand.BeginGPUScope(name)
+		})
+		return
+	}
+
+	graphicscommand.BeginGPUScope(name)
+}
+
+// EndGPUScope ends the last GPU timing scope.
+func EndGPUScope() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			graphicscommand.EndGPUScope()
+		})
+		return
+	}
+
+	graphicscommand.EndGPUScope()
+}
+
+// BeginOcclusionQuery starts counting the samples rendered for the query.
+func BeginOcclusionQuery(query *graphicscommand.OcclusionQuery) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			graphicscommand.BeginOcclusionQuery(query)
+		})
+		return
+	}
+
+	graphicscommand.BeginOcclusionQuery(query)
+}
+
+// EndOcclusionQuery ends the active occlusion query.
+func EndOcclusionQuery() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if !inFrame {
+		appendDeferred(func() {
+			graphicscommand.EndOcclusionQuery()
+		})
+		return
+	}
+
+	graphicscommand.EndOcclusionQuery()
+}
+
 func DumpImages(graphicsDriver graphicsdriver.Graphics, dir string) (string, error) {
 	backendsM.Lock()
 	defer backendsM.Unlock()
@@ -15,6 +15,7 @@
 package atlas_test
 
 import (
+	"bytes"
 	"image"
 	"image/color"
 	"runtime"
@@ -60,25 +61,25 @@ const bigSize = 2049
 func TestEnsureIsolatedFromSourceBackend(t *testing.T) {
 	// Create img1 and img2 with this size so that the next images are allocated
 	// with non-upper-left location.
-	img1 := atlas.NewImage(bigSize, 100, atlas.ImageTypeRegular)
+	img1 := atlas.NewImage(bigSize, 100, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img1.Deallocate()
 	// Ensure img1's region is allocated.
 	img1.WritePixels(make([]byte, 4*bigSize*100), image.Rect(0, 0, bigSize, 100))
 
-	img2 := atlas.NewImage(100, bigSize, atlas.ImageTypeRegular)
+	img2 := atlas.NewImage(100, bigSize, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img2.Deallocate()
 	img2.WritePixels(make([]byte, 4*100*bigSize), image.Rect(0, 0, 100, bigSize))
 
 	const size = 32
 
-	img3 := atlas.NewImage(size/2, size/2, atlas.ImageTypeRegular)
+	img3 := atlas.NewImage(size/2, size/2, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img3.Deallocate()
 	img3.WritePixels(make([]byte, (size/2)*(size/2)*4), image.Rect(0, 0, size/2, size/2))
 
-	img4 := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	img4 := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img4.Deallocate()
 
-	img5 := atlas.NewImage(size/2, size/2, atlas.ImageTypeRegular)
+	img5 := atlas.NewImage(size/2, size/2, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img3.Deallocate()
 
 	pix := make([]byte, size*size*4)
@@ -102,7 +103,7 @@ func TestEnsureIsolatedFromSourceBackend(t *testing.T) {
 	vs := quadVertices(size/2, size/2, size/4, size/4, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, size, size)
-	img4.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img3}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img4.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img3}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	if got, want := img4.IsOnSourceBackendForTesting(), false; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
@@ -110,7 +111,7 @@ func TestEnsureIsolatedFromSourceBackend(t *testing.T) {
 	// img5 is not allocated now, but is allocated at DrawTriangles.
 	vs = quadVertices(0, 0, size/2, size/2, 1)
 	dr = image.Rect(0, 0, size/2, size/2)
-	img3.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img5}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img3.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img5}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	if got, want := img3.IsOnSourceBackendForTesting(), false; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
@@ -144,24 +145,24 @@ func TestEnsureIsolatedFromSourceBackend(t *testing.T) {
 	// Check further drawing doesn't cause panic.
 	// This bug was fixed by 03dcd948.
 	vs = quadVertices(0, 0, size/2, size/2, 1)
-	img4.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img3}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img4.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img3}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 }
 
 func TestReputOnSourceBackend(t *testing.T) {
 	const size = 16
 
-	img0 := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	img0 := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img0.Deallocate()
 	img0.WritePixels(make([]byte, 4*size*size), image.Rect(0, 0, size, size))
 
-	img1 := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	img1 := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img1.Deallocate()
 	img1.WritePixels(make([]byte, 4*size*size), image.Rect(0, 0, size, size))
 	if got, want := img1.IsOnSourceBackendForTesting(), true; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
 
-	img2 := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	img2 := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img2.Deallocate()
 	pix := make([]byte, 4*size*size)
 	for j := 0; j < size; j++ {
@@ -175,7 +176,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	img2.WritePixels(pix, image.Rect(0, 0, size, size))
 
 	// Create an unmanaged image. This should always be on a non-source backend.
-	img3 := atlas.NewImage(size, size, atlas.ImageTypeUnmanaged)
+	img3 := atlas.NewImage(size, size, atlas.ImageTypeUnmanaged, graphicsdriver.PixelFormatRGBA8)
 	defer img3.Deallocate()
 	img3.WritePixels(make([]byte, 4*size*size), image.Rect(0, 0, size, size))
 	if got, want := img3.IsOnSourceBackendForTesting(), false; got != want {
@@ -188,7 +189,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	// Render onto img1. The count should not matter.
 	for i := 0; i < 5; i++ {
 		vs := quadVertices(size, size, 0, 0, 1)
-		img1.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		img1.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := img1.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -200,7 +201,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	for i := 0; i < atlas.BaseCountToPutOnSourceBackend*2; i++ {
 		atlas.PutImagesOnSourceBackendForTesting()
 		vs := quadVertices(size, size, 0, 0, 1)
-		img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := img1.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -208,7 +209,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	// Finally, img1 is on a source backend.
 	atlas.PutImagesOnSourceBackendForTesting()
 	vs := quadVertices(size, size, 0, 0, 1)
-	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	if got, want := img1.IsOnSourceBackendForTesting(), true; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
@@ -237,7 +238,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	}
 
 	vs = quadVertices(size, size, 0, 0, 1)
-	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	if got, want := img1.IsOnSourceBackendForTesting(), true; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
@@ -267,7 +268,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	// Use img1 as a render target again. The count should not matter.
 	for i := 0; i < 5; i++ {
 		vs := quadVertices(size, size, 0, 0, 1)
-		img1.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		img1.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := img1.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -279,7 +280,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 		atlas.PutImagesOnSourceBackendForTesting()
 		img1.WritePixels(make([]byte, 4*size*size), image.Rect(0, 0, size, size))
 		vs := quadVertices(size, size, 0, 0, 1)
-		img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := img1.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -288,7 +289,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 
 	// img1 is not on an atlas due to WritePixels.
 	vs = quadVertices(size, size, 0, 0, 1)
-	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	if got, want := img1.IsOnSourceBackendForTesting(), false; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
@@ -297,7 +298,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 	for i := 0; i < atlas.BaseCountToPutOnSourceBackend*2; i++ {
 		atlas.PutImagesOnSourceBackendForTesting()
 		vs := quadVertices(size, size, 0, 0, 1)
-		img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img3}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img3}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := img3.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -308,7 +309,7 @@ func TestReputOnSourceBackend(t *testing.T) {
 
 func TestExtend(t *testing.T) {
 	const w0, h0 = 100, 100
-	img0 := atlas.NewImage(w0, h0, atlas.ImageTypeRegular)
+	img0 := atlas.NewImage(w0, h0, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img0.Deallocate()
 
 	p0 := make([]byte, 4*w0*h0)
@@ -321,7 +322,7 @@ func TestExtend(t *testing.T) {
 	img0.WritePixels(p0, image.Rect(0, 0, w0, h0))
 
 	const w1, h1 = minSourceImageSizeForTesting + 1, 100
-	img1 := atlas.NewImage(w1, h1, atlas.ImageTypeRegular)
+	img1 := atlas.NewImage(w1, h1, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img1.Deallocate()
 
 	p1 := make([]byte, 4*w1*h1)
@@ -383,9 +384,9 @@ func TestExtend(t *testing.T) {
 
 func TestWritePixelsAfterDrawTriangles(t *testing.T) {
 	const w, h = 256, 256
-	src := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	src := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
-	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst.Deallocate()
 
 	pix := make([]byte, 4*w*h)
@@ -400,7 +401,7 @@ func TestWritePixelsAfterDrawTriangles(t *testing.T) {
 	vs := quadVertices(w, h, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	dst.WritePixels(pix, image.Rect(0, 0, w, h))
 
 	pix = make([]byte, 4*w*h)
@@ -430,9 +431,9 @@ func TestWritePixelsAfterDrawTriangles(t *testing.T) {
 // Issue #887
 func TestSmallImages(t *testing.T) {
 	const w, h = 4, 8
-	src := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	src := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
-	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst.Deallocate()
 
 	pix := make([]byte, 4*w*h)
@@ -447,7 +448,7 @@ func TestSmallImages(t *testing.T) {
 	vs := quadVertices(w, h, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendSourceOver, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendSourceOver, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	pix = make([]byte, 4*w*h)
 	ok, err := dst.ReadPixels(ui.Get().GraphicsDriverForTesting(), pix, image.Rect(0, 0, w, h))
@@ -474,11 +475,11 @@ func TestSmallImages(t *testing.T) {
 // Issue #887
 func TestLongImages(t *testing.T) {
 	const w, h = 1, 6
-	src := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	src := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
 
 	const dstW, dstH = 256, 256
-	dst := atlas.NewImage(dstW, dstH, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(dstW, dstH, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst.Deallocate()
 
 	pix := make([]byte, 4*w*h)
@@ -494,7 +495,7 @@ func TestLongImages(t *testing.T) {
 	vs := quadVertices(w, h, 0, 0, scale)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, dstW, dstH)
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendSourceOver, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendSourceOver, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	pix = make([]byte, 4*dstW*dstH)
 	ok, err := dst.ReadPixels(ui.Get().GraphicsDriverForTesting(), pix, image.Rect(0, 0, dstW, dstH))
@@ -521,11 +522,11 @@ func TestLongImages(t *testing.T) {
 func TestDeallocateImmediately(t *testing.T) {
 	// This tests ClearPixels is called but WritePixels is not called.
 
-	img0 := atlas.NewImage(16, 16, atlas.ImageTypeRegular)
+	img0 := atlas.NewImage(16, 16, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	img0.EnsureIsolatedFromSourceForTesting(nil)
 	defer img0.Deallocate()
 
-	img1 := atlas.NewImage(16, 16, atlas.ImageTypeRegular)
+	img1 := atlas.NewImage(16, 16, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	img1.EnsureIsolatedFromSourceForTesting(nil)
 	defer img1.Deallocate()
 
@@ -534,12 +535,12 @@ func TestDeallocateImmediately(t *testing.T) {
 
 // Issue #1028
 func TestExtendWithBigImage(t *testing.T) {
-	img0 := atlas.NewImage(1, 1, atlas.ImageTypeRegular)
+	img0 := atlas.NewImage(1, 1, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img0.Deallocate()
 
 	img0.WritePixels(make([]byte, 4*1*1), image.Rect(0, 0, 1, 1))
 
-	img1 := atlas.NewImage(minSourceImageSizeForTesting+1, minSourceImageSizeForTesting+1, atlas.ImageTypeRegular)
+	img1 := atlas.NewImage(minSourceImageSizeForTesting+1, minSourceImageSizeForTesting+1, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img1.Deallocate()
 
 	img1.WritePixels(make([]byte, 4*(minSourceImageSizeForTesting+1)*(minSourceImageSizeForTesting+1)), image.Rect(0, 0, minSourceImageSizeForTesting+1, minSourceImageSizeForTesting+1))
@@ -547,13 +548,13 @@ func TestExtendWithBigImage(t *testing.T) {
 
 // Issue #1217
 func TestMaxImageSize(t *testing.T) {
-	img0 := atlas.NewImage(1, 1, atlas.ImageTypeRegular)
+	img0 := atlas.NewImage(1, 1, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img0.Deallocate()
 	paddingSize := img0.PaddingSizeForTesting()
 
 	// This tests that a too-big image is allocated correctly.
 	s := maxImageSizeForTesting - 2*paddingSize
-	img1 := atlas.NewImage(s, s, atlas.ImageTypeRegular)
+	img1 := atlas.NewImage(s, s, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img1.Deallocate()
 	img1.WritePixels(make([]byte, 4*s*s), image.Rect(0, 0, s, s))
 }
@@ -566,7 +567,7 @@ func Disable_TestMinImageSize(t *testing.T) {
 	// This tests that extending a backend works correctly.
 	// Though the image size is minimum size of the backend, extending the backend happens due to the paddings.
 	s := minSourceImageSizeForTesting
-	img := atlas.NewImage(s, s, atlas.ImageTypeRegular)
+	img := atlas.NewImage(s, s, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img.Deallocate()
 	img.WritePixels(make([]byte, 4*s*s), image.Rect(0, 0, s, s))
 }
@@ -575,7 +576,7 @@ func TestMaxImageSizeJust(t *testing.T) {
 	s := maxImageSizeForTesting
 	// An unmanaged image never belongs to an atlas and doesn't have its paddings.
 	// TODO: Should we allow such this size for ImageTypeRegular?
-	img := atlas.NewImage(s, s, atlas.ImageTypeUnmanaged)
+	img := atlas.NewImage(s, s, atlas.ImageTypeUnmanaged, graphicsdriver.PixelFormatRGBA8)
 	defer img.Deallocate()
 	img.WritePixels(make([]byte, 4*s*s), image.Rect(0, 0, s, s))
 }
@@ -583,7 +584,7 @@ func TestMaxImageSizeJust(t *testing.T) {
 func TestMaxImageSizeExceeded(t *testing.T) {
 	// This tests that a too-big image is allocated correctly.
 	s := maxImageSizeForTesting
-	img := atlas.NewImage(s+1, s, atlas.ImageTypeRegular)
+	img := atlas.NewImage(s+1, s, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer img.Deallocate()
 
 	defer func() {
@@ -599,18 +600,18 @@ func TestMaxImageSizeExceeded(t *testing.T) {
 func TestDeallocatedAndReputOnSourceBackend(t *testing.T) {
 	const size = 16
 
-	src := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	src := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
-	src2 := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	src2 := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src2.Deallocate()
-	dst := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst.Deallocate()
 
 	// Use src as a render target so that src is not on an atlas.
 	vs := quadVertices(size, size, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, size, size)
-	src.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	src.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	if got, want := src.IsOnSourceBackendForTesting(), false; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
@@ -619,7 +620,7 @@ func TestDeallocatedAndReputOnSourceBackend(t *testing.T) {
 	for i := 0; i < atlas.BaseCountToPutOnSourceBackend/2; i++ {
 		atlas.PutImagesOnSourceBackendForTesting()
 		vs := quadVertices(size, size, 0, 0, 1)
-		dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := src.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -636,11 +637,11 @@ func TestDeallocatedAndReputOnSourceBackend(t *testing.T) {
 func TestImageIsNotReputOnSourceBackendWithoutUsingAsSource(t *testing.T) {
 	const size = 16
 
-	src := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	src := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
-	src2 := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	src2 := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src2.Deallocate()
-	dst := atlas.NewImage(size, size, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst.Deallocate()
 
 	// Use src as a render target so that src is not on an atlas.
@@ -653,7 +654,7 @@ func TestImageIsNotReputOnSourceBackendWithoutUsingAsSource(t *testing.T) {
 	// Call DrawTriangles multiple times.
 	// The number of DrawTriangles doesn't matter as long as these are called in one frame.
 	for i := 0; i < 2; i++ {
-		src2.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		src2.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	}
 	if got, want := src2.IsOnSourceBackendForTesting(), false; got != want {
 		t.Errorf("got: %v, want: %v", got, want)
@@ -672,7 +673,7 @@ func TestImageIsNotReputOnSourceBackendWithoutUsingAsSource(t *testing.T) {
 	for i := 0; i < atlas.BaseCountToPutOnSourceBackend; i++ {
 		atlas.PutImagesOnSourceBackendForTesting()
 		vs := quadVertices(size, size, 0, 0, 1)
-		dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src2}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		if got, want := src2.IsOnSourceBackendForTesting(), false; got != want {
 			t.Errorf("got: %v, want: %v", got, want)
 		}
@@ -687,7 +688,7 @@ func TestImageIsNotReputOnSourceBackendWithoutUsingAsSource(t *testing.T) {
 func TestImageWritePixelsModify(t *testing.T) {
 	for _, typ := range []atlas.ImageType{atlas.ImageTypeRegular, atlas.ImageTypeRegular, atlas.ImageTypeUnmanaged} {
 		const size = 16
-		img := atlas.NewImage(size, size, typ)
+		img := atlas.NewImage(size, size, typ, graphicsdriver.PixelFormatRGBA8)
 		defer img.Deallocate()
 		pix := make([]byte, 4*size*size)
 		for j := 0; j < size; j++ {
@@ -735,6 +736,48 @@ func TestImageWritePixelsModify(t *testing.T) {
 	}
 }
 
+func TestImageCompact(t *testing.T) {
+	const size = 16
+
+	src := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	defer src.Deallocate()
+	dst := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	defer dst.Deallocate()
+
+	pix := make([]byte, 4*size*size)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	src.WritePixels(pix, image.Rect(0, 0, size, size))
+
+	atlas.Compact()
+	if got, want := src.IsOnEvacuatedBackendForTesting(), true; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// An image on an evacuated backend is moved when the image is used.
+	vs := quadVertices(size, size, 0, 0, 1)
+	is := graphics.QuadIndices()
+	dr := image.Rect(0, 0, size, size)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+	atlas.EvacuateImagesForTesting()
+	if got, want := src.IsOnEvacuatedBackendForTesting(), false; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	got := make([]byte, 4*size*size)
+	ok, err := src.ReadPixels(ui.Get().GraphicsDriverForTesting(), got, image.Rect(0, 0, size, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("ReadPixels failed")
+	}
+	if !bytes.Equal(got, pix) {
+		t.Errorf("the pixels must be kept after the evacuation")
+	}
+}
+
 func TestPowerOf2(t *testing.T) {
 	testCases := []struct {
 		In  int
@@ -785,11 +828,11 @@ func TestPowerOf2(t *testing.T) {
 
 func TestDestinationCountOverflow(t *testing.T) {
 	const w, h = 256, 256
-	src := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	src := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
-	dst0 := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst0 := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst0.Deallocate()
-	dst1 := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst1 := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst1.Deallocate()
 
 	vs := quadVertices(w, h, 0, 0, 1)
@@ -798,14 +841,14 @@ func TestDestinationCountOverflow(t *testing.T) {
 
 	// Use dst0 as a destination for a while.
 	for i := 0; i < 31; i++ {
-		dst0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		dst0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		atlas.PutImagesOnSourceBackendForTesting()
 	}
 
 	// Use dst0 as a source for a while.
 	// As dst0 is used as a destination too many times (31 is a maximum), dst0's backend should never be a source backend.
 	for i := 0; i < 100; i++ {
-		dst1.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{dst0}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		dst1.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{dst0}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		atlas.PutImagesOnSourceBackendForTesting()
 		if dst0.IsOnSourceBackendForTesting() {
 			t.Errorf("dst0 cannot be on a source backend: %d", i)
@@ -816,14 +859,14 @@ func TestDestinationCountOverflow(t *testing.T) {
 // Issue #2729
 func TestIteratingImagesToPutOnSourceBackend(t *testing.T) {
 	const w, h = 16, 16
-	src := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	src := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer src.Deallocate()
 	srcs := make([]*atlas.Image, 10)
 	for i := range srcs {
-		srcs[i] = atlas.NewImage(w, h, atlas.ImageTypeRegular)
+		srcs[i] = atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 		defer srcs[i].Deallocate()
 	}
-	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	defer dst.Deallocate()
 
 	// Use srcs as destinations once.
@@ -831,7 +874,7 @@ func TestIteratingImagesToPutOnSourceBackend(t *testing.T) {
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
 	for _, img := range srcs {
-		img.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+		img.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	}
 	atlas.PutImagesOnSourceBackendForTesting()
 
@@ -839,7 +882,7 @@ func TestIteratingImagesToPutOnSourceBackend(t *testing.T) {
 	// Check iterating the registered image works correctly.
 	for i := 0; i < 100; i++ {
 		for _, src := range srcs {
-			dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+			dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 		}
 		atlas.PutImagesOnSourceBackendForTesting()
 	}
@@ -861,7 +904,7 @@ func ensureGC() {
 }
 
 func TestGC(t *testing.T) {
-	img := atlas.NewImage(16, 16, atlas.ImageTypeRegular)
+	img := atlas.NewImage(16, 16, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	img.WritePixels(make([]byte, 4*16*16), image.Rect(0, 0, 16, 16))
 
 	// Ensure other objects are GCed, as GC appends deferred functions for collected objects.
@@ -880,14 +923,14 @@ func TestGC(t *testing.T) {
 
 func TestDallocateUnmanagedImageBackends(t *testing.T) {
 	const w, h = 16, 16
-	img0 := atlas.NewImage(w, h, atlas.ImageTypeUnmanaged)
-	img1 := atlas.NewImage(w, h, atlas.ImageTypeUnmanaged)
+	img0 := atlas.NewImage(w, h, atlas.ImageTypeUnmanaged, graphicsdriver.PixelFormatRGBA8)
+	img1 := atlas.NewImage(w, h, atlas.ImageTypeUnmanaged, graphicsdriver.PixelFormatRGBA8)
 
 	// Call DrawTriangles to ensure the images are on backends.
 	vs := quadVertices(w, h, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
-	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	img0.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	// Get the difference of the number of backends before and after the images are deallocated.
 	c := atlas.BackendCountForTesting()
@@ -901,3 +944,100 @@ func TestDallocateUnmanagedImageBackends(t *testing.T) {
 }
 
 // TODO: Add tests to extend image on an atlas out of the main loop
+
+func TestPinToAtlas(t *testing.T) {
+	const size = 16
+
+	src := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	defer src.Deallocate()
+	src.WritePixels(make([]byte, 4*size*size), image.Rect(0, 0, size, size))
+	img := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	defer img.Deallocate()
+	dst := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	defer dst.Deallocate()
+
+	vs := quadVertices(size, size, 0, 0, 1)
+	is := graphics.QuadIndices()
+	dr := image.Rect(0, 0, size, size)
+
+	// Use img as a rendering target so that img is not on a source backend.
+	img.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+	if got, want := img.IsOnSourceBackendForTesting(), false; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// A pinned image is put on a source backend immediately.
+	img.PinToAtlas()
+	if got, want := img.IsOnSourceBackendForTesting(), true; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// Use img as a rendering target again.
+	for i := 0; i < 5; i++ {
+		vs := quadVertices(size, size, 0, 0, 1)
+		img.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+		atlas.PutImagesOnSourceBackendForTesting()
+	}
+
+	// A pinned image is put on a source backend again as soon as it is used as a rendering source.
+	vs = quadVertices(size, size, 0, 0, 1)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{img}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
+	atlas.PutImagesOnSourceBackendForTesting()
+	if got, want := img.IsOnSourceBackendForTesting(), true; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// An unmanaged image cannot be pinned.
+	unmanaged := atlas.NewImage(size, size, atlas.ImageTypeUnmanaged, graphicsdriver.PixelFormatRGBA8)
+	defer unmanaged.Deallocate()
+	unmanaged.PinToAtlas()
+	if got, want := unmanaged.IsOnSourceBackendForTesting(), false; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	const size = 16
+
+	src := atlas.NewImage(size, size, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	defer src.Deallocate()
+	pix := make([]byte, 4*size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			idx := 4 * (i + j*size)
+			pix[idx] = byte(i)
+			pix[idx+1] = byte(j)
+			pix[idx+2] = 0
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix, image.Rect(0, 0, size, size))
+
+	for _, imageType := range []atlas.ImageType{atlas.ImageTypeRegular, atlas.ImageTypeUnmanaged} {
+		dst := atlas.NewImage(size, size, imageType, graphicsdriver.PixelFormatRGBA8)
+		dst.CopyFrom(src, image.Pt(2, 3), image.Rect(4, 5, 10, 12))
+
+		pix := make([]byte, 4*size*size)
+		ok, err := dst.ReadPixels(ui.Get().GraphicsDriverForTesting(), pix, image.Rect(0, 0, size, size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatal("ReadPixels failed")
+		}
+		for j := 0; j < size; j++ {
+			for i := 0; i < size; i++ {
+				var want color.RGBA
+				if image.Pt(i, j).In(image.Rect(2, 3, 8, 10)) {
+					want = color.RGBA{R: byte(i + 2), G: byte(j + 2), A: 0xff}
+				}
+				idx := 4 * (i + j*size)
+				got := color.RGBA{R: pix[idx], G: pix[idx+1], B: pix[idx+2], A: pix[idx+3]}
+				if got != want {
+					t.Errorf("image type: %d, At(%d, %d): got: %v, want: %v", imageType, i, j, got, want)
+				}
+			}
+		}
+		dst.Deallocate()
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atlas
+
+import (
+	"fmt"
+)
+
+// Policy represents a configuration of texture atlases.
+// A zero value in each field means the default value.
+type Policy struct {
+	// MinSourceSize is the initial size of a backend for images used as rendering sources.
+	MinSourceSize int
+
+	// MinDestinationSize is the initial size of a backend for images used as rendering destinations.
+	MinDestinationSize int
+
+	// MaxPageSize is the maximum size of a backend that can have multiple images.
+	// An image that doesn't fit with this size is not put on an atlas.
+	MaxPageSize int
+
+	// PaddingSize is the padding size around a regular image on an atlas.
+	// A negative value means zero.
+	PaddingSize int
+
+	// BaseCountToPutOnSourceBackend is the base number of frames when an image can be put onto a source backend.
+	BaseCountToPutOnSourceBackend int
+}
+
+var (
+	// maxPageSize is the maximum size of a backend with a page. 0 means maxSize.
+	maxPageSize = 0
+
+	paddingSizeForRegularImages = 1
+)
+
+// SetPolicy sets the configuration of texture atlases.
+//
+// SetPolicy must be called before the first BeginFrame.
+func SetPolicy(policy *Policy) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	if graphicsDriverInitialized {
+		panic("atlas: SetPolicy must be called before the graphics driver is initialized")
+	}
+
+	for _, s := range []int{policy.MinSourceSize, policy.MinDestinationSize, policy.MaxPageSize} {
+		if s < 0 {
+			panic(fmt.Sprintf("atlas: sizes in Policy must not be negative but %d", s))
+		}
+	}
+
+	// The sizes must be powers of 2 so that an atlas can be extended by doubling the size.
+	if policy.MinSourceSize > 0 {
+		minSourceSize = floorPowerOf2(policy.MinSourceSize)
+	}
+	if policy.MinDestinationSize > 0 {
+		minDestinationSize = floorPowerOf2(policy.MinDestinationSize)
+	}
+	if policy.MaxPageSize > 0 {
+		maxPageSize = floorPowerOf2(policy.MaxPageSize)
+	}
+
+	if policy.PaddingSize > 0 {
+		paddingSizeForRegularImages = policy.PaddingSize
+	} else if policy.PaddingSize < 0 {
+		paddingSizeForRegularImages = 0
+	}
+
+	if policy.BaseCountToPutOnSourceBackend > 0 {
+		baseCountToPutOnSourceBackend = int64(policy.BaseCountToPutOnSourceBackend)
+	}
+}
+
+// maxAtlasSize returns the maximum size of a backend with a page.
+func maxAtlasSize() int {
+	if maxPageSize == 0 || maxPageSize > maxSize {
+		return maxSize
+	}
+	return maxPageSize
+}
+
+// Compact marks all the current atlases as evacuated.
+//
+// No new image is allocated on an evacuated atlas, and an image on an evacuated atlas is moved to another atlas
+// when the image is used for rendering.
+// An evacuated atlas is disposed when all the images on it are moved or deallocated.
+// Then, the images being used are packed into fewer atlases, while unused images don't cost anything.
+func Compact() {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+
+	for _, b := range theBackends {
+		if b.page == nil {
+			continue
+		}
+		b.evacuated = true
+	}
+}
+
+func evacuateImages() {
+	imagesToEvacuate.forEach(func(i *Image) {
+		i.evacuate()
+	})
+	imagesToEvacuate.clear()
+}
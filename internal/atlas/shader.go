@@ -29,6 +29,10 @@ import (
 type Shader struct {
 	ir     *shaderir.Program
 	shader *graphicscommand.Shader
+
+	// fragmentOutputShaders is the shaders rendering only one output of the fragment entry point.
+	// fragmentOutputShaders is used when multiple render targets or dual-source blending are not available.
+	fragmentOutputShaders [graphics.ShaderDstImageCount]*Shader
 }
 
 func NewShader(ir *shaderir.Program) *Shader {
@@ -55,6 +59,22 @@ func (s *Shader) ensureShader() *graphicscommand.Shader {
 	return s.shader
 }
 
+// fragmentOutputShader returns a shader rendering only the index-th output of the fragment entry point.
+func (s *Shader) fragmentOutputShader(index int) *Shader {
+	if s.ir.FragmentFunc.OutputCount <= 1 {
+		return s
+	}
+	if s.fragmentOutputShaders[index] == nil {
+		s.fragmentOutputShaders[index] = NewShader(s.ir.FragmentOutputProgram(index))
+	}
+	return s.fragmentOutputShaders[index]
+}
+
+// isDualSource reports whether the shader returns a secondary source color for dual-source blending.
+func (s *Shader) isDualSource() bool {
+	return s.ir.FragmentFunc.OutputCount > 1 && !s.ir.FragmentFunc.MultipleRenderTargets
+}
+
 // Deallocate deallocates the internal state.
 func (s *Shader) Deallocate() {
 	backendsM.Lock()
@@ -72,6 +92,13 @@ func (s *Shader) Deallocate() {
 
 func (s *Shader) deallocate() {
 	runtime.SetFinalizer(s, nil)
+	for i, fs := range s.fragmentOutputShaders {
+		if fs == nil {
+			continue
+		}
+		fs.deallocate()
+		s.fragmentOutputShaders[i] = nil
+	}
 	if s.shader == nil {
 		return
 	}
@@ -88,7 +115,7 @@ var (
 func init() {
 	var wg errgroup.Group
 	wg.Go(func() error {
-		ir, err := graphics.CompileShader([]byte(builtinshader.ShaderSource(builtinshader.FilterNearest, builtinshader.AddressUnsafe, false)))
+		ir, err := graphics.CompileShader([]byte(builtinshader.ShaderSource(builtinshader.FilterNearest, builtinshader.AddressUnsafe, builtinshader.AddressUnsafe, false, false)))
 		if err != nil {
 			return fmt.Errorf("atlas: compiling the nearest shader failed: %w", err)
 		}
@@ -96,7 +123,7 @@ func init() {
 		return nil
 	})
 	wg.Go(func() error {
-		ir, err := graphics.CompileShader([]byte(builtinshader.ShaderSource(builtinshader.FilterLinear, builtinshader.AddressUnsafe, false)))
+		ir, err := graphics.CompileShader([]byte(builtinshader.ShaderSource(builtinshader.FilterLinear, builtinshader.AddressUnsafe, builtinshader.AddressUnsafe, false, false)))
 		if err != nil {
 			return fmt.Errorf("atlas: compiling the linear shader failed: %w", err)
 		}
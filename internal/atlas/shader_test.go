@@ -30,19 +30,19 @@ import (
 func TestShaderFillTwice(t *testing.T) {
 	const w, h = 1, 1
 
-	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 
 	vs := quadVertices(w, h, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
 	g := ui.Get().GraphicsDriverForTesting()
 	s0 := atlas.NewShader(etesting.ShaderProgramFill(0xff, 0xff, 0xff, 0xff))
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, s0, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, s0, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	// Vertices must be recreated (#1755)
 	vs = quadVertices(w, h, 0, 0, 1)
 	s1 := atlas.NewShader(etesting.ShaderProgramFill(0x80, 0x80, 0x80, 0xff))
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, s1, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, s1, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	pix := make([]byte, 4*w*h)
 	ok, err := dst.ReadPixels(g, pix, image.Rect(0, 0, w, h))
@@ -60,20 +60,20 @@ func TestShaderFillTwice(t *testing.T) {
 func TestImageDrawTwice(t *testing.T) {
 	const w, h = 1, 1
 
-	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular)
-	src0 := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
+	src0 := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	src0.WritePixels([]byte{0xff, 0xff, 0xff, 0xff}, image.Rect(0, 0, w, h))
-	src1 := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	src1 := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	src1.WritePixels([]byte{0x80, 0x80, 0x80, 0xff}, image.Rect(0, 0, w, h))
 
 	vs := quadVertices(w, h, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src0}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src0}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	// Vertices must be recreated (#1755)
 	vs = quadVertices(w, h, 0, 0, 1)
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{src1}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	pix := make([]byte, 4*w*h)
 	ok, err := dst.ReadPixels(ui.Get().GraphicsDriverForTesting(), pix, image.Rect(0, 0, w, h))
@@ -93,11 +93,11 @@ func TestGCShader(t *testing.T) {
 
 	// Use the shader to initialize it.
 	const w, h = 1, 1
-	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular)
+	dst := atlas.NewImage(w, h, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	vs := quadVertices(w, h, 0, 0, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, w, h)
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, s, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*atlas.Image{}, vs, is, graphicsdriver.BlendCopy, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, s, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	// Ensure other objects are GCed, as GC appends deferred functions for collected objects.
 	ensureGC()
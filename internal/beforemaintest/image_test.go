@@ -24,6 +24,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
 	"github.com/hajimehoshi/ebiten/v2/internal/buffered"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
 )
 
 var gameUpdateCh = make(chan func())
@@ -311,7 +312,7 @@ func TestWritePixelsAndModifyBeforeMain(t *testing.T) {
 var imageGCedCh = make(chan struct{})
 
 func init() {
-	img := buffered.NewImage(1, 1, atlas.ImageTypeRegular)
+	img := buffered.NewImage(1, 1, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	runtime.SetFinalizer(img, func(*buffered.Image) {
 		close(imageGCedCh)
 	})
@@ -26,7 +26,7 @@ import (
 var whiteImage *Image
 
 func init() {
-	whiteImage = NewImage(3, 3, atlas.ImageTypeRegular)
+	whiteImage = NewImage(3, 3, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	pix := make([]byte, 4*3*3)
 	for i := range pix {
 		pix[i] = 0xff
@@ -38,6 +38,7 @@ type Image struct {
 	img    *atlas.Image
 	width  int
 	height int
+	format graphicsdriver.PixelFormat
 
 	// dotsBuffer is a buffer for drawing a lot of dots.
 	// An entry in this map is the primary data of pixels for ReadPixels.
@@ -52,11 +53,12 @@ type Image struct {
 	pixelsUnsynced bool
 }
 
-func NewImage(width, height int, imageType atlas.ImageType) *Image {
+func NewImage(width, height int, imageType atlas.ImageType, format graphicsdriver.PixelFormat) *Image {
 	return &Image{
-		img:    atlas.NewImage(width, height, imageType),
+		img:    atlas.NewImage(width, height, imageType, format),
 		width:  width,
 		height: height,
+		format: format,
 	}
 }
 
@@ -68,6 +70,11 @@ func (i *Image) Deallocate() {
 }
 
 func (i *Image) ReadPixels(graphicsDriver graphicsdriver.Graphics, pixels []byte, region image.Rectangle) (bool, error) {
+	// The pixel cache works only with 4-byte pixels. Read the pixels directly for other formats.
+	if i.format.BytesPerPixel() != 4 {
+		return i.img.ReadPixels(graphicsDriver, pixels, region)
+	}
+
 	// Do not call flushDotsBufferIfNeeded here. This would slow (image/draw).Draw.
 	// See ebiten.TestImageDrawOver.
 
@@ -112,6 +119,29 @@ func (i *Image) ReadPixels(graphicsDriver graphicsdriver.Graphics, pixels []byte
 	return true, nil
 }
 
+// ReadPixelsAsync starts reading the pixels without waiting for the GPU.
+//
+// callback is called after the pixels are read. pixels must not be accessed until then.
+func (i *Image) ReadPixelsAsync(pixels []byte, region image.Rectangle, callback func(err error)) {
+	i.syncPixelsIfNeeded()
+	i.img.ReadPixelsAsync(pixels, region, callback)
+}
+
+// SetSamples sets the number of samples per pixel for multisample anti-aliasing.
+func (i *Image) SetSamples(samples int) {
+	i.img.SetSamples(samples)
+}
+
+// SetNativeTexture makes the image refer to the given native texture.
+func (i *Image) SetNativeTexture(handle uintptr) {
+	i.img.SetNativeTexture(handle)
+}
+
+// PinToAtlas puts the image onto a texture atlas and keeps it there whenever possible.
+func (i *Image) PinToAtlas() {
+	i.img.PinToAtlas()
+}
+
 func (i *Image) DumpScreenshot(graphicsDriver graphicsdriver.Graphics, name string, blackbg bool) (string, error) {
 	i.syncPixelsIfNeeded()
 	return i.img.DumpScreenshot(graphicsDriver, name, blackbg)
@@ -119,10 +149,16 @@ func (i *Image) DumpScreenshot(graphicsDriver graphicsdriver.Graphics, name stri
 
 // WritePixels replaces the pixels at the specified region.
 func (i *Image) WritePixels(pix []byte, region image.Rectangle) {
-	if l := 4 * region.Dx() * region.Dy(); len(pix) != l {
+	if l := i.format.BytesPerPixel() * region.Dx() * region.Dy(); len(pix) != l {
 		panic(fmt.Sprintf("buffered: len(pix) was %d but must be %d", len(pix), l))
 	}
 
+	// The pixel cache works only with 4-byte pixels. Write the pixels directly for other formats.
+	if i.format.BytesPerPixel() != 4 {
+		i.img.WritePixels(pix, region)
+		return
+	}
+
 	// Writing one pixel is a special case.
 	// Do not write pixels in GPU, as (image/draw).Image's functions might call WritePixels with pixels one by one.
 	if region.Dx() == 1 && region.Dy() == 1 {
@@ -183,7 +219,7 @@ func (i *Image) WritePixels(pix []byte, region image.Rectangle) {
 // DrawTriangles draws the src image with the given vertices.
 //
 // Copying vertices and indices is the caller's responsibility.
-func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *atlas.Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) {
+func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *atlas.Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
 	for _, src := range srcs {
 		if i == src {
 			panic("buffered: Image.DrawTriangles: source images must be different from the receiver")
@@ -205,12 +241,91 @@ func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 		imgs[i] = img.img
 	}
 
-	i.img.DrawTriangles(imgs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule)
+	i.img.DrawTriangles(imgs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
 
 	// After rendering, the pixel cache is no longer valid.
 	i.pixels = nil
 }
 
+// DrawTrianglesToMultipleTargets draws triangles to the image and the additional images at once.
+func (i *Image) DrawTrianglesToMultipleTargets(additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *atlas.Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
+	for _, src := range srcs {
+		if src == nil {
+			continue
+		}
+		if i == src {
+			panic("buffered: Image.DrawTrianglesToMultipleTargets: source images must be different from the receiver")
+		}
+		for _, dst := range additionalDsts {
+			if dst == src {
+				panic("buffered: Image.DrawTrianglesToMultipleTargets: source images must be different from the destination images")
+			}
+		}
+		src.syncPixelsIfNeeded()
+	}
+
+	i.syncPixelsIfNeeded()
+
+	var dsts [graphics.ShaderDstImageCount - 1]*atlas.Image
+	for i, dst := range additionalDsts {
+		if dst == nil {
+			continue
+		}
+		dst.syncPixelsIfNeeded()
+		dsts[i] = dst.img
+	}
+
+	var imgs [graphics.ShaderSrcImageCount]*atlas.Image
+	for i, img := range srcs {
+		if img == nil {
+			continue
+		}
+		imgs[i] = img.img
+	}
+
+	i.img.DrawTrianglesToMultipleTargets(dsts, imgs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+
+	// After rendering, the pixel caches are no longer valid.
+	i.pixels = nil
+	for _, dst := range additionalDsts {
+		if dst == nil {
+			continue
+		}
+		dst.pixels = nil
+	}
+}
+
+// CopyFrom copies the pixels in srcRegion of src to the image at dstPoint.
+func (i *Image) CopyFrom(src *Image, dstPoint image.Point, srcRegion image.Rectangle) {
+	if i == src {
+		panic("buffered: Image.CopyFrom: the source image must be different from the receiver")
+	}
+	src.syncPixelsIfNeeded()
+	i.syncPixelsIfNeeded()
+
+	i.img.CopyFrom(src.img, dstPoint, srcRegion)
+
+	// After copying, the pixel cache is no longer valid.
+	i.pixels = nil
+}
+
+// DispatchCompute executes the compute kernel shader with x * y * z workgroups.
+// images are bound to the storage images of the kernel in order.
+func DispatchCompute(shader *atlas.Shader, images []*Image, uniforms []uint32, x, y, z int) {
+	imgs := make([]*atlas.Image, len(images))
+	for k, img := range images {
+		img.syncPixelsIfNeeded()
+		imgs[k] = img.img
+	}
+
+	atlas.DispatchCompute(shader, imgs, uniforms, x, y, z)
+
+	// After dispatching, the pixel caches are no longer valid.
+	for _, img := range images {
+		img.pixels = nil
+	}
+}
+
 // syncPixelsIfNeeded syncs the pixels between CPU and GPU.
 // After syncPixelsIfNeeded, dotsBuffer is cleared, but pixels might remain.
 func (i *Image) syncPixelsIfNeeded() {
@@ -298,7 +413,7 @@ func (i *Image) syncPixelsIfNeeded() {
 	srcs := [graphics.ShaderSrcImageCount]*atlas.Image{whiteImage.img}
 	dr := image.Rect(0, 0, i.width, i.height)
 	blend := graphicsdriver.BlendCopy
-	i.img.DrawTriangles(srcs, vs, is, blend, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	i.img.DrawTriangles(srcs, vs, is, blend, dr, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	// TODO: Use clear if Go 1.21 is available.
 	for pos := range i.dotsBuffer {
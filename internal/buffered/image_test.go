@@ -31,7 +31,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestUnsyncedPixels(t *testing.T) {
-	dst := buffered.NewImage(16, 16, atlas.ImageTypeRegular)
+	dst := buffered.NewImage(16, 16, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 
 	// Add an entry for dotsBuffer at (0, 0).
 	dst.WritePixels([]byte{0xff, 0xff, 0xff, 0xff}, image.Rect(0, 0, 1, 1))
@@ -50,13 +50,13 @@ func TestUnsyncedPixels(t *testing.T) {
 	dst.WritePixels(make([]byte, 4*2*2), image.Rect(1, 1, 3, 3))
 
 	// Flush unsynced pixel cache.
-	src := buffered.NewImage(16, 16, atlas.ImageTypeRegular)
+	src := buffered.NewImage(16, 16, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	vs := make([]float32, 4*graphics.VertexFloatCount)
 	graphics.QuadVerticesFromDstAndSrc(vs, 0, 0, 16, 16, 0, 0, 16, 16, 1, 1, 1, 1)
 	is := graphics.QuadIndices()
 	dr := image.Rect(0, 0, 16, 16)
 	sr := [graphics.ShaderSrcImageCount]image.Rectangle{image.Rect(0, 0, 16, 16)}
-	dst.DrawTriangles([graphics.ShaderSrcImageCount]*buffered.Image{src}, vs, is, graphicsdriver.BlendSourceOver, dr, sr, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	dst.DrawTriangles([graphics.ShaderSrcImageCount]*buffered.Image{src}, vs, is, graphicsdriver.BlendSourceOver, dr, sr, atlas.NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 
 	// Check the result is correct.
 	var got [4]byte
@@ -26,9 +26,12 @@ type Filter int
 const (
 	FilterNearest Filter = iota
 	FilterLinear
+	FilterAnisotropic
+	FilterBicubic
+	FilterPixelArt
 )
 
-const FilterCount = 2
+const FilterCount = 5
 
 type Address int
 
@@ -36,9 +39,10 @@ const (
 	AddressUnsafe Address = iota
 	AddressClampToZero
 	AddressRepeat
+	AddressMirrorRepeat
 )
 
-const AddressCount = 3
+const AddressCount = 4
 
 const (
 	UniformColorMBody        = "ColorMBody"
@@ -46,7 +50,7 @@ const (
 )
 
 var (
-	shaders  [FilterCount][AddressCount][2][]byte
+	shaders  [FilterCount][AddressCount][AddressCount][2][2][]byte
 	shadersM sync.Mutex
 )
 
@@ -59,46 +63,164 @@ var ColorMBody mat4
 var ColorMTranslation vec4
 {{end}}
 
-{{if eq .Address .AddressRepeat}}
-func adjustTexelForAddressRepeat(p vec2) vec2 {
+{{if .AdjustsTexel}}
+{{if .UsesMirrorRepeat}}
+// mirrorRepeat returns the position in [0, 2*size) where the second half is mirrored.
+func mirrorRepeat(x float, size float) float {
+	q := mod(x, 2*size)
+	if q >= size {
+		// In the mirrored part, reflect the texel index and keep the position in the texel
+		// so that the texel at the edge is repeated twice.
+		q = 2*size - 1 - floor(q) + fract(q)
+	}
+	return q
+}
+{{end}}
+
+func adjustTexel(p vec2) vec2 {
 	origin := imageSrc0Origin()
 	size := imageSrc0Size()
-	return mod(p - origin, size) + origin
+{{if eq .AddressX .AddressRepeat}}
+	p.x = mod(p.x-origin.x, size.x) + origin.x
+{{else if eq .AddressX .AddressMirrorRepeat}}
+	p.x = mirrorRepeat(p.x-origin.x, size.x) + origin.x
+{{end}}
+{{if eq .AddressY .AddressRepeat}}
+	p.y = mod(p.y-origin.y, size.y) + origin.y
+{{else if eq .AddressY .AddressMirrorRepeat}}
+	p.y = mirrorRepeat(p.y-origin.y, size.y) + origin.y
+{{end}}
+	return p
 }
 {{end}}
 
-func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
-{{if eq .Filter .FilterNearest}}
-{{if eq .Address .AddressUnsafe}}
-	clr := imageSrc0UnsafeAt(srcPos)
-{{else if eq .Address .AddressClampToZero}}
-	clr := imageSrc0At(srcPos)
-{{else if eq .Address .AddressRepeat}}
-	clr := imageSrc0At(adjustTexelForAddressRepeat(srcPos))
+{{if .UseLinearColorSpace}}
+// toLinear decodes an sRGB-encoded premultiplied-alpha color to the linear space.
+func toLinear(c vec4) vec4 {
+	if c.a == 0 {
+		return vec4(0)
+	}
+	rgb := clamp(c.rgb/c.a, 0, 1)
+	lo := rgb / 12.92
+	hi := pow((rgb+0.055)/1.055, vec3(2.4))
+	rgb = mix(lo, hi, step(vec3(0.04045), rgb))
+	return vec4(rgb*c.a, c.a)
+}
+
+// toSRGB encodes a linear premultiplied-alpha color to sRGB.
+func toSRGB(c vec4) vec4 {
+	if c.a == 0 {
+		return vec4(0)
+	}
+	rgb := clamp(c.rgb/c.a, 0, 1)
+	lo := rgb * 12.92
+	hi := 1.055*pow(rgb, vec3(1/2.4)) - 0.055
+	rgb = mix(lo, hi, step(vec3(0.0031308), rgb))
+	return vec4(rgb*c.a, c.a)
+}
 {{end}}
-{{else if eq .Filter .FilterLinear}}
-	p0 := srcPos - 1/2.0
-	p1 := srcPos + 1/2.0
 
-{{if eq .Address .AddressRepeat}}
-	p0 = adjustTexelForAddressRepeat(p0)
-	p1 = adjustTexelForAddressRepeat(p1)
+func texelAt(p vec2) vec4 {
+{{if .AdjustsTexel}}
+	p = adjustTexel(p)
+{{end}}
+{{if .Unsafe}}
+	c := imageSrc0UnsafeAt(p)
+{{else}}
+	c := imageSrc0At(p)
 {{end}}
+{{if .UseLinearColorSpace}}
+	// Blend the colors in the linear space.
+	return toLinear(c)
+{{else}}
+	return c
+{{end}}
+}
 
-{{if eq .Address .AddressUnsafe}}
-	c0 := imageSrc0UnsafeAt(p0)
-	c1 := imageSrc0UnsafeAt(vec2(p1.x, p0.y))
-	c2 := imageSrc0UnsafeAt(vec2(p0.x, p1.y))
-	c3 := imageSrc0UnsafeAt(p1)
+{{if eq .Filter .FilterAnisotropic}}
+// anisotropicAt samples the source texture with the anisotropic sampler of the graphics driver.
+func anisotropicAt(p vec2) vec4 {
+{{if .AdjustsTexel}}
+	p = adjustTexel(p)
+{{end}}
+	c := __texelAtAnisotropic(__t0, p/__imageSrcTextureSizes[0])
+{{if not .Unsafe}}
+	origin := imageSrc0Origin()
+	in := step(origin, p) - step(origin+imageSrc0Size(), p)
+	c *= in.x * in.y
+{{end}}
+{{if .UseLinearColorSpace}}
+	// The sampler blends the colors in the sRGB space.
+	return toLinear(c)
 {{else}}
-	c0 := imageSrc0At(p0)
-	c1 := imageSrc0At(vec2(p1.x, p0.y))
-	c2 := imageSrc0At(vec2(p0.x, p1.y))
-	c3 := imageSrc0At(p1)
+	return c
+{{end}}
+}
 {{end}}
 
+{{if eq .Filter .FilterBicubic}}
+// catmullRomWeights returns the weights of the four texels for the Catmull-Rom spline.
+// t is the position between the second and the third texels.
+func catmullRomWeights(t float) vec4 {
+	return vec4(
+		t*(-0.5+t*(1-0.5*t)),
+		1+t*t*(-2.5+1.5*t),
+		t*(0.5+t*(2-1.5*t)),
+		t*t*(-0.5+0.5*t),
+	)
+}
+
+// cubicRowAt returns the weighted sum of the four texels in a row starting at p.
+func cubicRowAt(p vec2, w vec4) vec4 {
+	return w.x*texelAt(p) + w.y*texelAt(p+vec2(1, 0)) + w.z*texelAt(p+vec2(2, 0)) + w.w*texelAt(p+vec2(3, 0))
+}
+{{end}}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+{{if eq .Filter .FilterNearest}}
+	clr := texelAt(srcPos)
+{{else if eq .Filter .FilterLinear}}
+	p0 := srcPos - 1/2.0
+	p1 := srcPos + 1/2.0
+	c0 := texelAt(p0)
+	c1 := texelAt(vec2(p1.x, p0.y))
+	c2 := texelAt(vec2(p0.x, p1.y))
+	c3 := texelAt(p1)
 	rate := fract(p1)
 	clr := mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y)
+{{else if eq .Filter .FilterAnisotropic}}
+	clr := anisotropicAt(srcPos)
+{{else if eq .Filter .FilterBicubic}}
+	// Take 4x4 texels around the position, and interpolate them with the Catmull-Rom spline.
+	p := srcPos - 1/2.0
+	rate := fract(p)
+	// p0 is the center of the upper-left texel of the 4x4 texels.
+	p0 := floor(p) - 1/2.0
+	wx := catmullRomWeights(rate.x)
+	wy := catmullRomWeights(rate.y)
+	clr := wy.x*cubicRowAt(p0, wx) + wy.y*cubicRowAt(p0+vec2(0, 1), wx) + wy.z*cubicRowAt(p0+vec2(0, 2), wx) + wy.w*cubicRowAt(p0+vec2(0, 3), wx)
+	// The Catmull-Rom spline can overshoot. Clamp the color to a valid premultiplied-alpha color.
+	clr = clamp(clr, 0, 1)
+	clr.rgb = min(clr.rgb, clr.a)
+{{else if eq .Filter .FilterPixelArt}}
+	// Blend source colors in a square region, which size is the size of a destination pixel in the source image.
+	// When the image is scaled up, only the destination pixels on the texel edges are blended.
+	// When the image is scaled down, this is the same as the linear filter.
+	// The lower bound avoids division by zero for degenerate triangles.
+	size := clamp(fwidth(srcPos), 1/1024.0, 1)
+	p0 := srcPos - size/2
+	p1 := srcPos + size/2
+	c0 := texelAt(p0)
+	c1 := texelAt(vec2(p1.x, p0.y))
+	c2 := texelAt(vec2(p0.x, p1.y))
+	c3 := texelAt(p1)
+	// rate is the p1 value in one pixel assuming that the pixel's upper-left is (0, 0) and the lower-right is (1, 1).
+	rate := clamp(fract(p1)/size, 0, 1)
+	clr := mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y)
+{{end}}
+
+{{if .UseLinearColorSpace}}
+	clr = toSRGB(clr)
 {{end}}
 
 {{if .UseColorM}}
@@ -125,8 +247,14 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 
 // ShaderSource returns the built-in shader source based on the given parameters.
 //
+// addressX and addressY are the address modes for the X and Y axes.
+//
+// If useLinearColorSpace is true, the source colors are regarded as sRGB-encoded,
+// and are decoded to the linear space before being blended by the filter and encoded to sRGB again after that.
+// useLinearColorSpace doesn't affect FilterNearest, which doesn't blend colors.
+//
 // The returned shader always uses a color matrix so far.
-func ShaderSource(filter Filter, address Address, useColorM bool) []byte {
+func ShaderSource(filter Filter, addressX, addressY Address, useColorM, useLinearColorSpace bool) []byte {
 	shadersM.Lock()
 	defer shadersM.Unlock()
 
@@ -134,43 +262,123 @@ func ShaderSource(filter Filter, address Address, useColorM bool) []byte {
 	if useColorM {
 		c = 1
 	}
-	if s := shaders[filter][address][c]; s != nil {
+	var l int
+	if useLinearColorSpace {
+		l = 1
+	}
+	if s := shaders[filter][addressX][addressY][c][l]; s != nil {
 		return s
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, struct {
-		Filter             Filter
-		FilterNearest      Filter
-		FilterLinear       Filter
-		Address            Address
-		AddressUnsafe      Address
-		AddressClampToZero Address
-		AddressRepeat      Address
-		UseColorM          bool
+		Filter              Filter
+		FilterNearest       Filter
+		FilterLinear        Filter
+		FilterAnisotropic   Filter
+		FilterBicubic       Filter
+		FilterPixelArt      Filter
+		AddressX            Address
+		AddressY            Address
+		AdjustsTexel        bool
+		UsesMirrorRepeat    bool
+		Unsafe              bool
+		AddressUnsafe       Address
+		AddressClampToZero  Address
+		AddressRepeat       Address
+		AddressMirrorRepeat Address
+		UseColorM           bool
+		UseLinearColorSpace bool
 	}{
-		Filter:             filter,
-		FilterNearest:      FilterNearest,
-		FilterLinear:       FilterLinear,
-		Address:            address,
-		AddressUnsafe:      AddressUnsafe,
-		AddressClampToZero: AddressClampToZero,
-		AddressRepeat:      AddressRepeat,
-		UseColorM:          useColorM,
+		Filter:              filter,
+		FilterNearest:       FilterNearest,
+		FilterLinear:        FilterLinear,
+		FilterAnisotropic:   FilterAnisotropic,
+		FilterBicubic:       FilterBicubic,
+		FilterPixelArt:      FilterPixelArt,
+		AddressX:            addressX,
+		AddressY:            addressY,
+		AdjustsTexel:        addressX == AddressRepeat || addressX == AddressMirrorRepeat || addressY == AddressRepeat || addressY == AddressMirrorRepeat,
+		UsesMirrorRepeat:    addressX == AddressMirrorRepeat || addressY == AddressMirrorRepeat,
+		Unsafe:              addressX == AddressUnsafe && addressY == AddressUnsafe,
+		AddressUnsafe:       AddressUnsafe,
+		AddressClampToZero:  AddressClampToZero,
+		AddressRepeat:       AddressRepeat,
+		AddressMirrorRepeat: AddressMirrorRepeat,
+		UseColorM:           useColorM,
+		UseLinearColorSpace: useLinearColorSpace && filter != FilterNearest,
 	}); err != nil {
 		panic(fmt.Sprintf("builtinshader: tmpl.Execute failed: %v", err))
 	}
 
 	b := buf.Bytes()
-	shaders[filter][address][c] = b
+	shaders[filter][addressX][addressY][c][l] = b
 	return b
 }
 
-var ScreenShaderSource = []byte(`//kage:unit pixels
+type ScreenFilter int
+
+const (
+	ScreenFilterBox ScreenFilter = iota
+	ScreenFilterBicubic
+	ScreenFilterLanczos
+)
+
+const ScreenFilterCount = 3
+
+var (
+	screenShaders  [ScreenFilterCount][2][]byte
+	screenShadersM sync.Mutex
+)
+
+var screenTmpl = template.Must(template.New("screenTmpl").Parse(`//kage:unit pixels
 
 package main
 
+{{if ne .Filter .ScreenFilterBox}}
+func texelAt(p vec2) vec4 {
+	// Clamp the position to the edge texels, as the samples can be out of the source image.
+	origin := imageSrc0Origin()
+	return imageSrc0UnsafeAt(clamp(p, origin+1/2.0, origin+imageSrc0Size()-1/2.0))
+}
+
+{{if eq .Filter .ScreenFilterBicubic}}
+// weights returns the weights of the four texels for the Catmull-Rom spline.
+// t is the position between the second and the third texels.
+func weights(t float) vec4 {
+	return vec4(
+		t*(-0.5+t*(1-0.5*t)),
+		1+t*t*(-2.5+1.5*t),
+		t*(0.5+t*(2-1.5*t)),
+		t*t*(-0.5+0.5*t),
+	)
+}
+{{else if eq .Filter .ScreenFilterLanczos}}
+// lanczos returns the value of the Lanczos kernel with a = 2.
+func lanczos(x float) float {
+	if x == 0 {
+		return 1
+	}
+	px := 3.14159265358979 * x
+	return 2 * sin(px) * sin(px/2) / (px * px)
+}
+
+// weights returns the normalized weights of the four texels for the Lanczos filter.
+// t is the position between the second and the third texels.
+func weights(t float) vec4 {
+	w := vec4(lanczos(t+1), lanczos(t), lanczos(1-t), lanczos(2-t))
+	return w / (w.x + w.y + w.z + w.w)
+}
+{{end}}
+
+// rowAt returns the weighted sum of the four texels in a row starting at p.
+func rowAt(p vec2, w vec4) vec4 {
+	return w.x*texelAt(p) + w.y*texelAt(p+vec2(1, 0)) + w.z*texelAt(p+vec2(2, 0)) + w.w*texelAt(p+vec2(3, 0))
+}
+{{end}}
+
 func Fragment(dstPos vec4, srcPos vec2) vec4 {
+{{if eq .Filter .ScreenFilterBox}}
 	// Blend source colors in a square region, which size is 1/scale.
 	scale := imageDstSize()/imageSrc0Size()
 	pos := srcPos
@@ -185,9 +393,74 @@ func Fragment(dstPos vec4, srcPos vec2) vec4 {
 
 	// p is the p1 value in one pixel assuming that the pixel's upper-left is (0, 0) and the lower-right is (1, 1).
 	rate := clamp(fract(p1)*scale, 0, 1)
-	return mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y)
+	c := mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y)
+{{else}}
+	// Take 4x4 texels around the position, and interpolate them.
+	p := srcPos - 1/2.0
+	rate := fract(p)
+	// p0 is the center of the upper-left texel of the 4x4 texels.
+	p0 := floor(p) - 1/2.0
+	wx := weights(rate.x)
+	wy := weights(rate.y)
+	c := wy.x*rowAt(p0, wx) + wy.y*rowAt(p0+vec2(0, 1), wx) + wy.z*rowAt(p0+vec2(0, 2), wx) + wy.w*rowAt(p0+vec2(0, 3), wx)
+	// The filter can overshoot. Clamp the color to a valid premultiplied-alpha color.
+	c = clamp(c, 0, 1)
+	c.rgb = min(c.rgb, c.a)
+{{end}}
+
+{{if .SRGB}}
+	if c.a == 0 {
+		return vec4(0)
+	}
+
+	// Encode the un-premultiplied color to sRGB.
+	rgb := clamp(c.rgb/c.a, 0, 1)
+	lo := rgb * 12.92
+	hi := 1.055*pow(rgb, vec3(1/2.4)) - 0.055
+	rgb = mix(lo, hi, step(vec3(0.0031308), rgb))
+	return vec4(rgb*c.a, c.a)
+{{else}}
+	return c
+{{end}}
+}
+`))
+
+// ScreenShaderSource returns the shader source to render the offscreen onto the screen with the given filter.
+//
+// If srgb is true, the source image is in the linear space and the result is encoded to sRGB.
+func ScreenShaderSource(filter ScreenFilter, srgb bool) []byte {
+	screenShadersM.Lock()
+	defer screenShadersM.Unlock()
+
+	var c int
+	if srgb {
+		c = 1
+	}
+	if s := screenShaders[filter][c]; s != nil {
+		return s
+	}
+
+	var buf bytes.Buffer
+	if err := screenTmpl.Execute(&buf, struct {
+		Filter              ScreenFilter
+		ScreenFilterBox     ScreenFilter
+		ScreenFilterBicubic ScreenFilter
+		ScreenFilterLanczos ScreenFilter
+		SRGB                bool
+	}{
+		Filter:              filter,
+		ScreenFilterBox:     ScreenFilterBox,
+		ScreenFilterBicubic: ScreenFilterBicubic,
+		ScreenFilterLanczos: ScreenFilterLanczos,
+		SRGB:                srgb,
+	}); err != nil {
+		panic(fmt.Sprintf("builtinshader: screenTmpl.Execute failed: %v", err))
+	}
+
+	b := buf.Bytes()
+	screenShaders[filter][c] = b
+	return b
 }
-`)
 
 var ClearShaderSource = []byte(`//kage:unit pixels
 
@@ -200,10 +473,17 @@ func Fragment() vec4 {
 
 func AppendShaderSources(sources [][]byte) [][]byte {
 	for filter := Filter(0); filter < FilterCount; filter++ {
-		for address := Address(0); address < AddressCount; address++ {
-			sources = append(sources, ShaderSource(filter, address, false), ShaderSource(filter, address, true))
+		for addressX := Address(0); addressX < AddressCount; addressX++ {
+			for addressY := Address(0); addressY < AddressCount; addressY++ {
+				for _, useColorM := range []bool{false, true} {
+					sources = append(sources, ShaderSource(filter, addressX, addressY, useColorM, false), ShaderSource(filter, addressX, addressY, useColorM, true))
+				}
+			}
 		}
 	}
-	sources = append(sources, ScreenShaderSource, ClearShaderSource)
+	for filter := ScreenFilter(0); filter < ScreenFilterCount; filter++ {
+		sources = append(sources, ScreenShaderSource(filter, false), ScreenShaderSource(filter, true))
+	}
+	sources = append(sources, ClearShaderSource)
 	return sources
 }
@@ -0,0 +1,66 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Phase represents a phase of the game loop.
+//
+// A phase is used as a value of the pprof label "ebitengine" and as a runtime/trace region type.
+type Phase string
+
+const (
+	PhaseUpdate  Phase = "update"
+	PhaseDraw    Phase = "draw"
+	PhaseAtlas   Phase = "atlas"
+	PhaseFlush   Phase = "flush"
+	PhasePresent Phase = "present"
+)
+
+const profileLabelKey = "ebitengine"
+
+// frameTaskType is the runtime/trace task type for one frame.
+const frameTaskType = "ebitengine.frame"
+
+// StartFrameTask starts a runtime/trace task for one frame.
+// The returned context should be passed to Do for the phases in the frame.
+// The returned function must be called at the end of the frame.
+//
+// If tracing is not enabled, StartFrameTask does nothing and returns ctx as it is.
+func StartFrameTask(ctx context.Context) (context.Context, func()) {
+	if !trace.IsEnabled() {
+		return ctx, func() {}
+	}
+	ctx, task := trace.NewTask(ctx, frameTaskType)
+	return ctx, task.End
+}
+
+// Do calls f with the pprof label and the runtime/trace region for the given phase.
+//
+// The pprof label is set only for the current goroutine while f is running.
+func Do(ctx context.Context, phase Phase, f func() error) error {
+	var err error
+	pprof.Do(ctx, pprof.Labels(profileLabelKey, string(phase)), func(ctx context.Context) {
+		if trace.IsEnabled() {
+			defer trace.StartRegion(ctx, string(phase)).End()
+		}
+		err = f()
+	})
+	return err
+}
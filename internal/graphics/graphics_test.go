@@ -79,3 +79,83 @@ func BenchmarkAdjustPixel(b *testing.B) {
 		graphics.AdjustDestinationPixelForTesting(float32(i) / 17)
 	}
 }
+
+func TestCompileComputeShader(t *testing.T) {
+	if _, err := graphics.CompileComputeShader([]byte(`//kage:compute 8 8
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}
+`)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := graphics.CompileComputeShader([]byte(`//kage:compute 8 8
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)); err == nil {
+		t.Errorf("CompileComputeShader must return an error without the entry point but not")
+	}
+
+	if _, err := graphics.CompileComputeShader([]byte(`package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}
+`)); err == nil {
+		t.Errorf("CompileComputeShader must return an error without //kage:compute but not")
+	}
+}
+
+func TestCompileShaderVertexPosition(t *testing.T) {
+	ir, err := graphics.CompileShader([]byte(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ir.VertexFunc.TransformsPosition {
+		t.Errorf("TransformsPosition must be false without VertexPosition but not")
+	}
+
+	ir, err = graphics.CompileShader([]byte(`package main
+
+var Bones [4]mat4
+
+func VertexPosition(pos vec2, custom vec4, custom1 vec4) vec2 {
+	return (Bones[int(custom1.x)] * vec4(pos, 0, 1)).xy
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ir.VertexFunc.TransformsPosition {
+		t.Errorf("TransformsPosition must be true with VertexPosition but not")
+	}
+
+	if _, err := graphics.CompileShader([]byte(`package main
+
+func VertexPosition(pos vec2) vec2 {
+	return pos
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)); err == nil {
+		t.Errorf("CompileShader must return an error with a wrong VertexPosition signature but not")
+	}
+}
@@ -17,12 +17,18 @@ package graphics
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shader"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 )
 
-func shaderSuffix(unit shaderir.Unit) (string, error) {
+// vertexPositionFunc is the name of an optional function to transform the destination positions of the vertices.
+const vertexPositionFunc = "VertexPosition"
+
+func shaderSuffix(unit shaderir.Unit, vertexPosition bool) (string, error) {
 	shaderSuffix := fmt.Sprintf(`
 var __imageDstTextureSize vec2
 
@@ -127,6 +133,20 @@ func imageSrc%[1]dUnsafeAt(pos vec2) vec4 {
 	return __texelAt(__t%[1]d, %[2]s)
 }
 `, i, pos)
+		// The origin in pixels is needed to fetch a texel by an integer position.
+		origin := fmt.Sprintf("__imageSrcRegionOrigins[%d]", i)
+		if unit == shaderir.Texels {
+			origin = fmt.Sprintf("floor(__imageSrcRegionOrigins[%[1]d] * __imageSrcTextureSizes[%[1]d] + 0.5)", i)
+		}
+		shaderSuffix += fmt.Sprintf(`
+// imageSrc%[1]dTexelAt returns the source image's texel at the given integer position without filtering.
+// pos is the position in pixels from the source image's origin.
+// pos is not adjusted even if it is out of the image, and then the result is undefined.
+func imageSrc%[1]dTexelAt(pos ivec2) vec4 {
+	return __texelFetch(__t%[1]d, pos + ivec2(%[2]s))
+}
+`, i, origin)
+
 		switch unit {
 		case shaderir.Pixels:
 			shaderSuffix += fmt.Sprintf(`
@@ -149,24 +169,63 @@ func imageSrc%[1]dAt(pos vec2) vec4 {
 }
 `, i, pos)
 		}
+
+		// With the texel mode, all the source region sizes are the same (#1870).
+		size := fmt.Sprintf("__imageSrcRegionSizes[%d]", i)
+		if unit == shaderir.Texels {
+			size = "__imageSrcRegionSizes[0]"
+		}
+		shaderSuffix += fmt.Sprintf(`
+// imageSrc%[1]dAtLod is the same as imageSrc%[1]dAt except for sampling the texture at the explicit level of detail lod.
+// As imageSrc%[1]dAtLod doesn't depend on implicit derivatives, imageSrc%[1]dAtLod is available in non-uniform control flow.
+// The source textures don't have mipmap levels, and lod doesn't affect the result as of now.
+func imageSrc%[1]dAtLod(pos vec2, lod float) vec4 {
+	in := step(__imageSrcRegionOrigins[0], pos) - step(__imageSrcRegionOrigins[0] + %[3]s, pos)
+	return __texelAtLod(__t%[1]d, %[2]s, lod) * in.x * in.y
+}
+`, i, pos, size)
 	}
 
 	shaderSuffix += `
 var __projectionMatrix mat4
+`
 
-func __vertex(dstPos vec2, srcPos vec2, color vec4, custom vec4) (vec4, vec2, vec4, vec4) {
-	return __projectionMatrix * vec4(dstPos, 0, 1), srcPos, color, custom
+	if !vertexPosition {
+		shaderSuffix += `
+// The first custom value is used as the z value, which is a depth value.
+// The projection matrix ignores the z value unless the depth buffer is used.
+func __vertex(dstPos vec2, srcPos vec2, color vec4, custom vec4, custom1 vec4) (vec4, vec2, vec4, vec4, vec4) {
+	return __projectionMatrix * vec4(dstPos, custom.x, 1), srcPos, color, custom, custom1
 }
 `
+		return shaderSuffix, nil
+	}
+
+	// The position given to VertexPosition is relative to the destination image's origin in pixels.
+	origin := "__imageDstRegionOrigin"
+	if unit == shaderir.Texels {
+		origin = "__imageDstRegionOrigin * __imageDstTextureSize"
+	}
+	shaderSuffix += fmt.Sprintf(`
+// The first custom value is used as the z value, which is a depth value.
+// The projection matrix ignores the z value unless the depth buffer is used.
+func __vertex(dstPos vec2, srcPos vec2, color vec4, custom vec4, custom1 vec4) (vec4, vec2, vec4, vec4, vec4) {
+	origin := %[1]s
+	pos := %[2]s(dstPos - origin, custom, custom1) + origin
+	return __projectionMatrix * vec4(pos, custom.x, 1), srcPos, color, custom, custom1
+}
+`, origin, vertexPositionFunc)
 	return shaderSuffix, nil
 }
 
-func completeShaderSource(fragmentSrc []byte) ([]byte, error) {
+// completeShaderSource appends the built-in functions and the vertex entry point to the source.
+// If vertexPosition is true, the vertex entry point calls the function VertexPosition in the source.
+func completeShaderSource(fragmentSrc []byte, vertexPosition bool) ([]byte, error) {
 	unit, err := shader.ParseCompilerDirectives(fragmentSrc)
 	if err != nil {
 		return nil, err
 	}
-	suffix, err := shaderSuffix(unit)
+	suffix, err := shaderSuffix(unit, vertexPosition)
 	if err != nil {
 		return nil, err
 	}
@@ -179,32 +238,97 @@ func completeShaderSource(fragmentSrc []byte) ([]byte, error) {
 }
 
 func CompileShader(fragmentSrc []byte) (*shaderir.Program, error) {
-	src, err := completeShaderSource(fragmentSrc)
+	return CompileShaderWithArrayLengths(fragmentSrc, nil)
+}
+
+// CompileShaderWithArrayLengths compiles a shader with the lengths of the runtime-sized uniform arrays.
+func CompileShaderWithArrayLengths(fragmentSrc []byte, arrayLengths map[string]int) (*shaderir.Program, error) {
+	vertexPosition := hasFunc(fragmentSrc, vertexPositionFunc)
+	src, err := completeShaderSource(fragmentSrc, vertexPosition)
 	if err != nil {
 		return nil, err
 	}
 
-	const (
-		vert = "__vertex"
-		frag = "Fragment"
-	)
-	ir, err := shader.Compile(src, vert, frag, ShaderSrcImageCount)
+	ir, err := shader.CompileWithArrayLengths(src, vertexEntry, fragmentEntry, ShaderSrcImageCount, arrayLengths)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateEntryPoints(ir); err != nil {
+		return nil, err
+	}
+	ir.VertexFunc.TransformsPosition = vertexPosition
+	return ir, nil
+}
 
+// CompileShaderForDebug compiles a shader to inspect the values of the Debugf calls.
+//
+// component is the index of the value component to inspect. See shader.CompileForDebug for the details.
+func CompileShaderForDebug(fragmentSrc []byte, arrayLengths map[string]int, component int) (*shaderir.Program, error) {
+	vertexPosition := hasFunc(fragmentSrc, vertexPositionFunc)
+	src, err := completeShaderSource(fragmentSrc, vertexPosition)
+	if err != nil {
+		return nil, err
+	}
+
+	ir, err := shader.CompileForDebug(src, vertexEntry, fragmentEntry, ShaderSrcImageCount, arrayLengths, component)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEntryPoints(ir); err != nil {
+		return nil, err
+	}
+	ir.VertexFunc.TransformsPosition = vertexPosition
+	return ir, nil
+}
+
+const (
+	vertexEntry   = "__vertex"
+	fragmentEntry = "Fragment"
+)
+
+func validateEntryPoints(ir *shaderir.Program) error {
 	if ir.VertexFunc.Block == nil {
-		return nil, fmt.Errorf("graphics: vertex shader entry point '%s' is missing", vert)
+		return fmt.Errorf("graphics: vertex shader entry point '%s' is missing", vertexEntry)
 	}
 	if ir.FragmentFunc.Block == nil {
-		return nil, fmt.Errorf("graphics: fragment shader entry point '%s' is missing", frag)
+		return fmt.Errorf("graphics: fragment shader entry point '%s' is missing", fragmentEntry)
 	}
+	return nil
+}
 
+// computeEntry is the entry point of a compute kernel.
+const computeEntry = "Compute"
+
+// CompileComputeShader compiles a compute kernel with the //kage:compute directive.
+// A compute kernel has an entry point 'Compute' taking invocation IDs as ivec3 values.
+func CompileComputeShader(computeSrc []byte) (*shaderir.Program, error) {
+	ir, err := shader.CompileCompute(computeSrc, computeEntry)
+	if err != nil {
+		return nil, err
+	}
+	if ir.ComputeFunc.Block == nil {
+		return nil, fmt.Errorf("graphics: compute kernel entry point '%s' is missing", computeEntry)
+	}
 	return ir, nil
 }
 
+// hasFunc reports whether src has a top-level function with the given name.
+// If src cannot be parsed, hasFunc returns true so that the compiler reports the error.
+func hasFunc(src []byte, name string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return true
+	}
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func CalcSourceHash(fragmentSrc []byte) (shaderir.SourceHash, error) {
-	src, err := completeShaderSource(fragmentSrc)
+	src, err := completeShaderSource(fragmentSrc, hasFunc(fragmentSrc, vertexPositionFunc))
 	if err != nil {
 		return shaderir.SourceHash{}, err
 	}
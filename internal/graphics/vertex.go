@@ -14,8 +14,15 @@
 
 package graphics
 
+import (
+	"github.com/hajimehoshi/ebiten/v2/internal/shader"
+)
+
 const (
-	ShaderSrcImageCount = 4
+	ShaderSrcImageCount = 8
+
+	// ShaderDstImageCount is the maximum number of the destination images for multiple render targets.
+	ShaderDstImageCount = shader.MaxRenderTargetCount
 
 	// PreservedUniformVariablesCount represents the number of preserved uniform variables.
 	// Any shaders in Ebitengine must have these uniform variables.
@@ -39,7 +46,7 @@ const (
 )
 
 const (
-	VertexFloatCount = 12
+	VertexFloatCount = 16
 )
 
 var (
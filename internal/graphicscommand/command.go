@@ -15,6 +15,7 @@
 package graphicscommand
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"math"
@@ -61,7 +62,11 @@ func (p *drawTrianglesCommandPool) put(v *drawTrianglesCommand) {
 
 // drawTrianglesCommand represents a drawing command to draw an image on another image.
 type drawTrianglesCommand struct {
-	dst        *Image
+	dst *Image
+
+	// additionalDsts is the additional destination images for multiple render targets.
+	additionalDsts [graphics.ShaderDstImageCount - 1]*Image
+
 	srcs       [graphics.ShaderSrcImageCount]*Image
 	vertices   []float32
 	blend      graphicsdriver.Blend
@@ -69,6 +74,8 @@ type drawTrianglesCommand struct {
 	shader     *Shader
 	uniforms   []uint32
 	fillRule   graphicsdriver.FillRule
+	depthMode  graphicsdriver.DepthMode
+	stencil    graphicsdriver.Stencil
 }
 
 func (c *drawTrianglesCommand) String() string {
@@ -85,6 +92,12 @@ func (c *drawTrianglesCommand) String() string {
 	if c.dst.screen {
 		dst += " (screen)"
 	}
+	for _, d := range c.additionalDsts {
+		if d == nil {
+			continue
+		}
+		dst += fmt.Sprintf(", %d", d.id)
+	}
 
 	var srcstrs [graphics.ShaderSrcImageCount]string
 	for i, src := range c.srcs {
@@ -98,7 +111,7 @@ func (c *drawTrianglesCommand) String() string {
 		}
 	}
 
-	return fmt.Sprintf("draw-triangles: dst: %s <- src: [%s], num of dst regions: %d, num of indices: %d, blend: %s, fill rule: %s, shader id: %d", dst, strings.Join(srcstrs[:], ", "), len(c.dstRegions), c.numIndices(), blend, c.fillRule, c.shader.id)
+	return fmt.Sprintf("draw-triangles: dst: %s <- src: [%s], num of dst regions: %d, num of indices: %d, blend: %s, fill rule: %s, depth mode: %s, stencil: %s, shader id: %d", dst, strings.Join(srcstrs[:], ", "), len(c.dstRegions), c.numIndices(), blend, c.fillRule, c.depthMode, c.stencil, c.shader.id)
 }
 
 // Exec executes the drawTrianglesCommand.
@@ -108,6 +121,17 @@ func (c *drawTrianglesCommand) Exec(commandQueue *commandQueue, graphicsDriver g
 		return nil
 	}
 
+	depthMode := c.depthMode
+	if depthMode != graphicsdriver.DepthModeNone && !isFeatureAvailable(graphicsDriver, graphicsdriver.FeatureDepth) {
+		// Ignore the depth buffer. The triangles are rendered in the order of the commands.
+		depthMode = graphicsdriver.DepthModeNone
+	}
+	stencil := c.stencil
+	if !stencil.IsZero() && !isFeatureAvailable(graphicsDriver, graphicsdriver.FeatureStencil) {
+		// Ignore the stencil buffer. All the fragments pass the stencil test.
+		stencil = graphicsdriver.Stencil{}
+	}
+
 	var imgs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID
 	for i, src := range c.srcs {
 		if src == nil {
@@ -117,7 +141,18 @@ func (c *drawTrianglesCommand) Exec(commandQueue *commandQueue, graphicsDriver g
 		imgs[i] = src.image.ID()
 	}
 
-	return graphicsDriver.DrawTriangles(c.dst.image.ID(), imgs, c.shader.shader.ID(), c.dstRegions, indexOffset, c.blend, c.uniforms, c.fillRule)
+	if c.additionalDsts[0] != nil {
+		dsts := [graphics.ShaderDstImageCount]graphicsdriver.ImageID{c.dst.image.ID()}
+		for i, d := range c.additionalDsts {
+			if d == nil {
+				continue
+			}
+			dsts[i+1] = d.image.ID()
+		}
+		return graphicsDriver.(graphicsdriver.MultipleRenderTargetsDrawer).DrawTrianglesToMultipleTargets(dsts, imgs, c.shader.shader.ID(), c.dstRegions, indexOffset, c.blend, c.uniforms, c.fillRule, depthMode, stencil)
+	}
+
+	return graphicsDriver.DrawTriangles(c.dst.image.ID(), imgs, c.shader.shader.ID(), c.dstRegions, indexOffset, c.blend, c.uniforms, c.fillRule, depthMode, stencil)
 }
 
 func (c *drawTrianglesCommand) NeedsSync() bool {
@@ -142,7 +177,7 @@ func (c *drawTrianglesCommand) setVertices(vertices []float32) {
 
 // CanMergeWithDrawTrianglesCommand returns a boolean value indicating whether the other drawTrianglesCommand can be merged
 // with the drawTrianglesCommand c.
-func (c *drawTrianglesCommand) CanMergeWithDrawTrianglesCommand(dst *Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, blend graphicsdriver.Blend, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) bool {
+func (c *drawTrianglesCommand) CanMergeWithDrawTrianglesCommand(dst *Image, additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, blend graphicsdriver.Blend, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) bool {
 	if c.shader != shader {
 		return false
 	}
@@ -157,6 +192,9 @@ func (c *drawTrianglesCommand) CanMergeWithDrawTrianglesCommand(dst *Image, srcs
 	if c.dst != dst {
 		return false
 	}
+	if c.additionalDsts != additionalDsts {
+		return false
+	}
 	if c.srcs != srcs {
 		return false
 	}
@@ -166,7 +204,13 @@ func (c *drawTrianglesCommand) CanMergeWithDrawTrianglesCommand(dst *Image, srcs
 	if c.fillRule != fillRule {
 		return false
 	}
-	if c.fillRule != graphicsdriver.FillRuleFillAll && mightOverlapDstRegions(c.vertices, vertices) {
+	if c.depthMode != depthMode {
+		return false
+	}
+	if c.stencil != stencil {
+		return false
+	}
+	if c.fillRule != graphicsdriver.FillRuleFillAll && (shader.ir.VertexFunc.TransformsPosition || mightOverlapDstRegions(c.vertices, vertices)) {
 		return false
 	}
 	return true
@@ -242,7 +286,7 @@ func (c *writePixelsCommand) Exec(commandQueue *commandQueue, graphicsDriver gra
 			Region: a.region,
 		})
 	}
-	if err := c.dst.image.WritePixels(args); err != nil {
+	if err := c.dst.writeDriverPixels(args); err != nil {
 		return err
 	}
 	return nil
@@ -259,7 +303,7 @@ type readPixelsCommand struct {
 
 // Exec executes a readPixelsCommand.
 func (c *readPixelsCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
-	if err := c.img.image.ReadPixels(c.args); err != nil {
+	if err := c.img.readDriverPixels(c.args); err != nil {
 		return err
 	}
 	return nil
@@ -273,6 +317,90 @@ func (c *readPixelsCommand) String() string {
 	return fmt.Sprintf("read-pixels: image: %d", c.img.id)
 }
 
+// copyImageCommand represents a command to copy pixels between images without the rendering pipeline.
+type copyImageCommand struct {
+	dst       *Image
+	src       *Image
+	dstPoint  image.Point
+	srcRegion image.Rectangle
+}
+
+// Exec executes a copyImageCommand.
+func (c *copyImageCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
+	return graphicsDriver.(graphicsdriver.ImageCopier).CopyImage(c.dst.image.ID(), c.src.image.ID(), c.dstPoint, c.srcRegion)
+}
+
+func (c *copyImageCommand) NeedsSync() bool {
+	return false
+}
+
+func (c *copyImageCommand) String() string {
+	return fmt.Sprintf("copy-image: dst: %d, src: %d, dst point: %s, src region: %s", c.dst.id, c.src.id, c.dstPoint, c.srcRegion)
+}
+
+// dispatchComputeCommand represents a command to execute a compute kernel.
+type dispatchComputeCommand struct {
+	shader   *Shader
+	images   []*Image
+	uniforms []uint32
+	x        int
+	y        int
+	z        int
+}
+
+// Exec executes a dispatchComputeCommand.
+func (c *dispatchComputeCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
+	imgs := make([]graphicsdriver.ImageID, len(c.images))
+	for i, img := range c.images {
+		imgs[i] = img.image.ID()
+	}
+	return graphicsDriver.(graphicsdriver.ComputeDispatcher).DispatchCompute(c.shader.shader.ID(), imgs, c.uniforms, c.x, c.y, c.z)
+}
+
+func (c *dispatchComputeCommand) NeedsSync() bool {
+	return false
+}
+
+func (c *dispatchComputeCommand) String() string {
+	ids := make([]string, len(c.images))
+	for i, img := range c.images {
+		ids[i] = fmt.Sprintf("%d", img.id)
+	}
+	return fmt.Sprintf("dispatch-compute: shader: %d, images: [%s], workgroups: (%d, %d, %d)", c.shader.id, strings.Join(ids, ", "), c.x, c.y, c.z)
+}
+
+// readPixelsAsyncCommand represents a command to start reading pixels asynchronously.
+type readPixelsAsyncCommand struct {
+	img      *Image
+	args     []graphicsdriver.PixelsArgs
+	callback func(err error)
+}
+
+// Exec executes a readPixelsAsyncCommand.
+func (c *readPixelsAsyncCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
+	r, ok := c.img.image.(graphicsdriver.AsyncPixelsReader)
+	if !ok || c.img.rgba16Emulated {
+		// The graphics driver cannot read pixels asynchronously. Read them synchronously instead.
+		theAsyncPixelsReader.finish(c.callback, c.img.readDriverPixels(c.args))
+		return nil
+	}
+	p, err := r.ReadPixelsAsync(c.args)
+	if err != nil {
+		theAsyncPixelsReader.finish(c.callback, err)
+		return nil
+	}
+	theAsyncPixelsReader.add(p, c.callback)
+	return nil
+}
+
+func (c *readPixelsAsyncCommand) NeedsSync() bool {
+	return false
+}
+
+func (c *readPixelsAsyncCommand) String() string {
+	return fmt.Sprintf("read-pixels-async: image: %d", c.img.id)
+}
+
 // disposeImageCommand represents a command to dispose an image.
 type disposeImageCommand struct {
 	target *Image
@@ -313,25 +441,58 @@ func (c *disposeShaderCommand) NeedsSync() bool {
 
 // newImageCommand represents a command to create an empty image with given width and height.
 type newImageCommand struct {
-	result *Image
-	width  int
-	height int
-	screen bool
+	result        *Image
+	width         int
+	height        int
+	screen        bool
+	format        graphicsdriver.PixelFormat
+	samples       int
+	nativeTexture uintptr
 }
 
 func (c *newImageCommand) String() string {
-	return fmt.Sprintf("new-image: result: %d, width: %d, height: %d, screen: %t", c.result.id, c.width, c.height, c.screen)
+	return fmt.Sprintf("new-image: result: %d, width: %d, height: %d, screen: %t, format: %s, samples: %d", c.result.id, c.width, c.height, c.screen, c.format, c.samples)
 }
 
 // Exec executes a newImageCommand.
 func (c *newImageCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
 	var err error
-	if c.screen {
+	if c.nativeTexture != 0 {
+		n, ok := graphicsDriver.(graphicsdriver.NativeTextureImporter)
+		if !ok {
+			return errors.New("graphicscommand: native textures are not supported with the current graphics driver")
+		}
+		c.result.image, err = n.NewImageFromNativeTexture(c.nativeTexture, c.width, c.height)
+	} else if c.screen {
 		c.result.image, err = graphicsDriver.NewScreenFramebufferImage(c.width, c.height)
 	} else {
-		c.result.image, err = graphicsDriver.NewImage(c.width, c.height)
+		format := c.format
+		if format.IsFloat() && !isFeatureAvailable(graphicsDriver, graphicsdriver.FeatureFloatFormats) {
+			// Use 8-bit values instead. The pixels are still transferred as 8-bit values, and values out of [0, 1] are clamped.
+			format = graphicsdriver.PixelFormatRGBA8
+		}
+		if format == graphicsdriver.PixelFormatRGBA16 && !isFeatureAvailable(graphicsDriver, graphicsdriver.FeatureRGBA16) {
+			// Use 8-bit values instead. The pixels are converted when they are transferred.
+			format = graphicsdriver.PixelFormatRGBA8
+			c.result.rgba16Emulated = true
+		}
+		c.result.image, err = graphicsDriver.NewImage(c.width, c.height, format)
+	}
+	if err != nil {
+		return err
 	}
-	return err
+
+	if c.samples > 1 && isFeatureAvailable(graphicsDriver, graphicsdriver.FeatureMultisampling) {
+		m, ok := c.result.image.(graphicsdriver.MultisampledImage)
+		if !ok {
+			return errors.New("graphicscommand: multisampled images are not supported with the current graphics driver")
+		}
+		if err := m.SetSamples(c.samples); err != nil {
+			return err
+		}
+	}
+	// Without multisampling, the image is rendered with one sample per pixel.
+	return nil
 }
 
 func (c *newImageCommand) NeedsSync() bool {
@@ -350,7 +511,17 @@ func (c *newShaderCommand) String() string {
 
 // Exec executes a newShaderCommand.
 func (c *newShaderCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
-	s, err := graphicsDriver.NewShader(c.ir)
+	var s graphicsdriver.Shader
+	var err error
+	if c.ir.IsCompute() {
+		d, ok := graphicsDriver.(graphicsdriver.ComputeDispatcher)
+		if !ok {
+			return errors.New("graphicscommand: compute kernels are not supported with the current graphics driver")
+		}
+		s, err = d.NewComputeShader(c.ir)
+	} else {
+		s, err = graphicsDriver.NewShader(c.ir)
+	}
 	if err != nil {
 		return err
 	}
@@ -381,6 +552,44 @@ func ResetGraphicsDriverState(graphicsDriver graphicsdriver.Graphics) (err error
 	return nil
 }
 
+// IsMultipleRenderTargetsAvailable reports whether Image.DrawTrianglesToMultipleTargets is available with the graphics driver.
+func IsMultipleRenderTargetsAvailable(graphicsDriver graphicsdriver.Graphics) bool {
+	d, ok := graphicsDriver.(graphicsdriver.MultipleRenderTargetsDrawer)
+	if !ok {
+		return false
+	}
+	var available bool
+	runOnRenderThread(func() {
+		available = d.IsMultipleRenderTargetsAvailable()
+	}, true)
+	return available
+}
+
+// IsFeatureAvailable reports whether the optional feature is available with the graphics driver.
+func IsFeatureAvailable(graphicsDriver graphicsdriver.Graphics, feature graphicsdriver.Feature) bool {
+	var available bool
+	runOnRenderThread(func() {
+		available = isFeatureAvailable(graphicsDriver, feature)
+	}, true)
+	return available
+}
+
+// isFeatureAvailable reports whether the optional feature is available with the graphics driver.
+// isFeatureAvailable must be called on the render thread.
+func isFeatureAvailable(graphicsDriver graphicsdriver.Graphics, feature graphicsdriver.Feature) bool {
+	r, ok := graphicsDriver.(graphicsdriver.FeatureReporter)
+	if !ok {
+		return false
+	}
+	return r.IsFeatureAvailable(feature)
+}
+
+// IsImageCopyAvailable reports whether Image.CopyFrom is available with the graphics driver.
+func IsImageCopyAvailable(graphicsDriver graphicsdriver.Graphics) bool {
+	_, ok := graphicsDriver.(graphicsdriver.ImageCopier)
+	return ok
+}
+
 // MaxImageSize returns the maximum size of an image.
 func MaxImageSize(graphicsDriver graphicsdriver.Graphics) int {
 	var size int
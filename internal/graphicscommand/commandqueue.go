@@ -15,6 +15,7 @@
 package graphicscommand
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"math"
@@ -105,7 +106,7 @@ func mustUseDifferentVertexBuffer(nextNumVertexFloats int) bool {
 }
 
 // EnqueueDrawTrianglesCommand enqueues a drawing-image command.
-func (q *commandQueue) EnqueueDrawTrianglesCommand(dst *Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) {
+func (q *commandQueue) EnqueueDrawTrianglesCommand(dst *Image, additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
 	if len(vertices) > maxVertexFloatCount {
 		panic(fmt.Sprintf("graphicscommand: len(vertices) must equal to or less than %d but was %d", maxVertexFloatCount, len(vertices)))
 	}
@@ -125,7 +126,7 @@ func (q *commandQueue) EnqueueDrawTrianglesCommand(dst *Image, srcs [graphics.Sh
 	// prependPreservedUniforms not only prepends values to the given slice but also creates a new slice.
 	// Allocating a new slice is necessary to make EnqueueDrawTrianglesCommand safe so far.
 	// TODO: This might cause a performance issue (#2601).
-	uniforms = q.prependPreservedUniforms(uniforms, shader, dst, srcs, dstRegion, srcRegions)
+	uniforms = q.prependPreservedUniforms(uniforms, shader, dst, srcs, dstRegion, srcRegions, depthMode)
 
 	// Remove unused uniform variables so that more commands can be merged.
 	shader.ir.FilterUniformVariables(uniforms)
@@ -133,7 +134,7 @@ func (q *commandQueue) EnqueueDrawTrianglesCommand(dst *Image, srcs [graphics.Sh
 	// TODO: If dst is the screen, reorder the command to be the last.
 	if !split && 0 < len(q.commands) {
 		if last, ok := q.commands[len(q.commands)-1].(*drawTrianglesCommand); ok {
-			if last.CanMergeWithDrawTrianglesCommand(dst, srcs, vertices, blend, shader, uniforms, fillRule) {
+			if last.CanMergeWithDrawTrianglesCommand(dst, additionalDsts, srcs, vertices, blend, shader, uniforms, fillRule, depthMode, stencil) {
 				last.setVertices(q.lastVertices(len(vertices) + last.numVertices()))
 				if last.dstRegions[len(last.dstRegions)-1].Region == dstRegion {
 					last.dstRegions[len(last.dstRegions)-1].IndexCount += len(indices)
@@ -150,6 +151,7 @@ func (q *commandQueue) EnqueueDrawTrianglesCommand(dst *Image, srcs [graphics.Sh
 
 	c := q.drawTrianglesCommandPool.get()
 	c.dst = dst
+	c.additionalDsts = additionalDsts
 	c.srcs = srcs
 	c.vertices = q.lastVertices(len(vertices))
 	c.blend = blend
@@ -162,6 +164,8 @@ func (q *commandQueue) EnqueueDrawTrianglesCommand(dst *Image, srcs [graphics.Sh
 	c.shader = shader
 	c.uniforms = uniforms
 	c.fillRule = fillRule
+	c.depthMode = depthMode
+	c.stencil = stencil
 	q.commands = append(q.commands, c)
 }
 
@@ -203,7 +207,10 @@ func (q *commandQueue) Flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 	runOnRenderThread(func() {
 		defer logger.Flush()
 
-		if err := q.flush(graphicsDriver, endFrame, logger); err != nil {
+		// The render thread might be a different goroutine, so set the profiling label here.
+		if err := debug.Do(context.Background(), debug.PhaseFlush, func() error {
+			return q.flush(graphicsDriver, endFrame, logger)
+		}); err != nil {
 			if sync {
 				flushErr = err
 				return
@@ -222,6 +229,17 @@ func (q *commandQueue) Flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 	return nil
 }
 
+// endGraphicsDriver calls End of the graphics driver.
+// If endFrame is true, End presents the framebuffer, and the time is attributed to the present phase.
+func endGraphicsDriver(graphicsDriver graphicsdriver.Graphics, endFrame bool) error {
+	if !endFrame {
+		return graphicsDriver.End(false)
+	}
+	return debug.Do(context.Background(), debug.PhasePresent, func() error {
+		return graphicsDriver.End(true)
+	})
+}
+
 // flush must be called the render thread.
 func (q *commandQueue) flush(graphicsDriver graphicsdriver.Graphics, endFrame bool, logger debug.FrameLogger) (err error) {
 	// If endFrame is true, Begin/End should be called to ensure the framebuffer is swapped.
@@ -239,7 +257,7 @@ func (q *commandQueue) flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 
 	defer func() {
 		// Call End even if an error causes, or the graphics driver's state might be stale (#2388).
-		if err1 := graphicsDriver.End(endFrame); err1 != nil && err == nil {
+		if err1 := endGraphicsDriver(graphicsDriver, endFrame); err1 != nil && err == nil {
 			err = err1
 		}
 
@@ -258,6 +276,7 @@ func (q *commandQueue) flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 		q.tmpNumVertexFloats = 0
 
 		if endFrame {
+			endFrameStats()
 			q.uint32sBuffer.reset()
 			for i, f := range q.finalizers {
 				f()
@@ -267,6 +286,10 @@ func (q *commandQueue) flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 		}
 	}()
 
+	if err := theGPUTimer.beginFrame(graphicsDriver); err != nil {
+		return err
+	}
+
 	cs := q.commands
 	for len(cs) > 0 {
 		nv := 0
@@ -295,6 +318,7 @@ func (q *commandQueue) flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 				return err
 			}
 			logger.FrameLogf("  %s\n", c)
+			recordCommand(c)
 			// TODO: indexOffset should be reset if the command type is different
 			// from the previous one. This fix is needed when another drawing command is
 			// introduced than drawTrianglesCommand.
@@ -305,6 +329,20 @@ func (q *commandQueue) flush(graphicsDriver graphicsdriver.Graphics, endFrame bo
 		cs = cs[nc:]
 	}
 
+	if endFrame {
+		if err := theGPUTimer.endFrame(graphicsDriver); err != nil {
+			return err
+		}
+	}
+
+	theAsyncPixelsReader.poll()
+	if err := theGPUTimer.poll(); err != nil {
+		return err
+	}
+	if err := theOcclusionQuerier.poll(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -324,7 +362,7 @@ func imageRectangleToRectangleF32(r image.Rectangle) rectangleF32 {
 	}
 }
 
-func (q *commandQueue) prependPreservedUniforms(uniforms []uint32, shader *Shader, dst *Image, srcs [graphics.ShaderSrcImageCount]*Image, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle) []uint32 {
+func (q *commandQueue) prependPreservedUniforms(uniforms []uint32, shader *Shader, dst *Image, srcs [graphics.ShaderSrcImageCount]*Image, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, depthMode graphicsdriver.DepthMode) []uint32 {
 	origUniforms := uniforms
 	uniforms = q.uint32sBuffer.alloc(len(origUniforms) + graphics.PreservedUniformUint32Count)
 	copy(uniforms[graphics.PreservedUniformUint32Count:], origUniforms)
@@ -408,7 +446,13 @@ func (q *commandQueue) prependPreservedUniforms(uniforms []uint32, shader *Shade
 	uniforms[uniformIndex+7] = 0
 	uniforms[uniformIndex+8] = 0
 	uniforms[uniformIndex+9] = 0
-	uniforms[uniformIndex+10] = math.Float32bits(1)
+	// The z value of a vertex is used as a depth value only when the depth buffer is used.
+	// Otherwise, the z value is always 0.
+	if depthMode != graphicsdriver.DepthModeNone {
+		uniforms[uniformIndex+10] = math.Float32bits(1)
+	} else {
+		uniforms[uniformIndex+10] = 0
+	}
 	uniforms[uniformIndex+11] = 0
 	uniforms[uniformIndex+12] = math.Float32bits(-1)
 	uniforms[uniformIndex+13] = math.Float32bits(-1)
@@ -469,11 +513,11 @@ func (c *commandQueueManager) putCommandQueue(commandQueue *commandQueue) {
 	c.pool.put(commandQueue)
 }
 
-func (c *commandQueueManager) enqueueDrawTrianglesCommand(dst *Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) {
+func (c *commandQueueManager) enqueueDrawTrianglesCommand(dst *Image, additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
 	if c.current == nil {
 		c.current, _ = c.pool.get()
 	}
-	c.current.EnqueueDrawTrianglesCommand(dst, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule)
+	c.current.EnqueueDrawTrianglesCommand(dst, additionalDsts, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
 }
 
 func (c *commandQueueManager) flush(graphicsDriver graphicsdriver.Graphics, endFrame bool) error {
@@ -494,6 +538,73 @@ func (c *commandQueueManager) flush(graphicsDriver graphicsdriver.Graphics, endF
 	return nil
 }
 
+// pendingPixels is a pair of pixels being read asynchronously and its callback.
+type pendingPixels struct {
+	pixels   graphicsdriver.PendingPixels
+	callback func(err error)
+}
+
+// asyncPixelsReader manages the pixels being read asynchronously.
+type asyncPixelsReader struct {
+	// pendings is accessed only from the render thread.
+	pendings []pendingPixels
+
+	callbacks []func()
+	m         sync.Mutex
+}
+
+var theAsyncPixelsReader asyncPixelsReader
+
+// add must be called from the render thread.
+func (a *asyncPixelsReader) add(pixels graphicsdriver.PendingPixels, callback func(err error)) {
+	a.pendings = append(a.pendings, pendingPixels{
+		pixels:   pixels,
+		callback: callback,
+	})
+}
+
+// finish can be called from any goroutines.
+func (a *asyncPixelsReader) finish(callback func(err error), err error) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	a.callbacks = append(a.callbacks, func() {
+		callback(err)
+	})
+}
+
+// poll must be called from the render thread.
+func (a *asyncPixelsReader) poll() {
+	var cur int
+	for _, p := range a.pendings {
+		done, err := p.pixels.TryFinish()
+		if !done && err == nil {
+			a.pendings[cur] = p
+			cur++
+			continue
+		}
+		a.finish(p.callback, err)
+	}
+	for i := cur; i < len(a.pendings); i++ {
+		a.pendings[i] = pendingPixels{}
+	}
+	a.pendings = a.pendings[:cur]
+}
+
+// CallReadPixelsAsyncCallbacks calls the callbacks of ReadPixelsAsync whose pixels have been read.
+//
+// CallReadPixelsAsyncCallbacks should be called from the game goroutine.
+func CallReadPixelsAsyncCallbacks() {
+	a := &theAsyncPixelsReader
+	a.m.Lock()
+	callbacks := a.callbacks
+	a.callbacks = nil
+	a.m.Unlock()
+
+	for _, f := range callbacks {
+		f()
+	}
+}
+
 // uint32sBuffer is a reusable buffer to allocate []uint32.
 type uint32sBuffer struct {
 	buf []uint32
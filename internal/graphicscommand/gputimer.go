@@ -0,0 +1,261 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphicscommand
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+)
+
+// gpuTimer manages the timestamps recorded on the GPU.
+type gpuTimer struct {
+	// frameTimerEnabled reports whether the time of each frame is measured.
+	frameTimerEnabled atomic.Bool
+
+	// scopeDepth is the depth of the scopes enqueued from the game goroutine.
+	scopeDepth int
+
+	// The members below are accessed only from the render thread.
+
+	// frameBegin is the timestamp at the beginning of the current frame.
+	frameBegin graphicsdriver.GPUTimestamp
+
+	// scopes is a stack of the scopes being recorded.
+	scopes []gpuScope
+
+	// pendings is the scopes whose timestamps are not available yet.
+	pendings []*pendingGPUScope
+
+	// The members below are protected by m.
+
+	frameTime  time.Duration
+	scopeTimes map[string]time.Duration
+	m          sync.Mutex
+}
+
+var theGPUTimer gpuTimer
+
+type gpuScope struct {
+	name  string
+	begin graphicsdriver.GPUTimestamp
+}
+
+type pendingGPUScope struct {
+	// name is the scope name. An empty name means a whole frame.
+	name string
+
+	begin graphicsdriver.GPUTimestamp
+	end   graphicsdriver.GPUTimestamp
+
+	beginNS   uint64
+	endNS     uint64
+	beginDone bool
+	endDone   bool
+}
+
+func recordGPUTimestamp(graphicsDriver graphicsdriver.Graphics) (graphicsdriver.GPUTimestamp, error) {
+	t, ok := graphicsDriver.(graphicsdriver.GPUTimer)
+	if !ok || !t.IsGPUTimerAvailable() {
+		return nil, nil
+	}
+	return t.RecordGPUTimestamp()
+}
+
+// beginFrame must be called from the render thread.
+func (g *gpuTimer) beginFrame(graphicsDriver graphicsdriver.Graphics) error {
+	if !g.frameTimerEnabled.Load() {
+		return nil
+	}
+	if g.frameBegin != nil {
+		return nil
+	}
+	t, err := recordGPUTimestamp(graphicsDriver)
+	if err != nil {
+		return err
+	}
+	g.frameBegin = t
+	return nil
+}
+
+// endFrame must be called from the render thread.
+func (g *gpuTimer) endFrame(graphicsDriver graphicsdriver.Graphics) error {
+	if g.frameBegin == nil {
+		return nil
+	}
+	t, err := recordGPUTimestamp(graphicsDriver)
+	if err != nil {
+		return err
+	}
+	g.pendings = append(g.pendings, &pendingGPUScope{
+		begin: g.frameBegin,
+		end:   t,
+	})
+	g.frameBegin = nil
+	return nil
+}
+
+// beginScope must be called from the render thread.
+func (g *gpuTimer) beginScope(graphicsDriver graphicsdriver.Graphics, name string) error {
+	t, err := recordGPUTimestamp(graphicsDriver)
+	if err != nil {
+		return err
+	}
+	g.scopes = append(g.scopes, gpuScope{
+		name:  name,
+		begin: t,
+	})
+	return nil
+}
+
+// endScope must be called from the render thread.
+func (g *gpuTimer) endScope(graphicsDriver graphicsdriver.Graphics) error {
+	s := g.scopes[len(g.scopes)-1]
+	g.scopes = g.scopes[:len(g.scopes)-1]
+	if s.begin == nil {
+		return nil
+	}
+	t, err := recordGPUTimestamp(graphicsDriver)
+	if err != nil {
+		return err
+	}
+	g.pendings = append(g.pendings, &pendingGPUScope{
+		name:  s.name,
+		begin: s.begin,
+		end:   t,
+	})
+	return nil
+}
+
+// poll must be called from the render thread.
+func (g *gpuTimer) poll() error {
+	var cur int
+	for _, p := range g.pendings {
+		if !p.beginDone {
+			ns, done, err := p.begin.TryFinish()
+			if err != nil {
+				return err
+			}
+			p.beginNS = ns
+			p.beginDone = done
+		}
+		if !p.endDone {
+			ns, done, err := p.end.TryFinish()
+			if err != nil {
+				return err
+			}
+			p.endNS = ns
+			p.endDone = done
+		}
+		if !p.beginDone || !p.endDone {
+			g.pendings[cur] = p
+			cur++
+			continue
+		}
+
+		var d time.Duration
+		if p.endNS > p.beginNS {
+			d = time.Duration(p.endNS - p.beginNS)
+		}
+		g.m.Lock()
+		if p.name == "" {
+			g.frameTime = d
+		} else {
+			if g.scopeTimes == nil {
+				g.scopeTimes = map[string]time.Duration{}
+			}
+			g.scopeTimes[p.name] = d
+		}
+		g.m.Unlock()
+	}
+	for i := cur; i < len(g.pendings); i++ {
+		g.pendings[i] = nil
+	}
+	g.pendings = g.pendings[:cur]
+	return nil
+}
+
+// GPUFrameTime returns the time spent on the GPU in the last measured frame.
+//
+// The measurement starts when GPUFrameTime is called first.
+func GPUFrameTime() time.Duration {
+	g := &theGPUTimer
+	g.frameTimerEnabled.Store(true)
+
+	g.m.Lock()
+	defer g.m.Unlock()
+	return g.frameTime
+}
+
+// GPUScopeTime returns the time spent on the GPU in the last measured scope with the given name.
+func GPUScopeTime(name string) time.Duration {
+	g := &theGPUTimer
+	g.m.Lock()
+	defer g.m.Unlock()
+	return g.scopeTimes[name]
+}
+
+// BeginGPUScope enqueues a command to start a GPU timing scope.
+//
+// BeginGPUScope must be called from the game goroutine.
+func BeginGPUScope(name string) {
+	if name == "" {
+		panic("graphicscommand: the scope name must not be empty")
+	}
+	theGPUTimer.scopeDepth++
+	theCommandQueueManager.enqueueCommand(&gpuScopeCommand{
+		name:  name,
+		begin: true,
+	})
+}
+
+// EndGPUScope enqueues a command to end the last GPU timing scope.
+//
+// EndGPUScope must be called from the game goroutine.
+func EndGPUScope() {
+	if theGPUTimer.scopeDepth == 0 {
+		panic("graphicscommand: EndGPUScope must be called after BeginGPUScope")
+	}
+	theGPUTimer.scopeDepth--
+	theCommandQueueManager.enqueueCommand(&gpuScopeCommand{})
+}
+
+// gpuScopeCommand represents a command to begin or end a GPU timing scope.
+type gpuScopeCommand struct {
+	name  string
+	begin bool
+}
+
+func (c *gpuScopeCommand) String() string {
+	if c.begin {
+		return fmt.Sprintf("begin-gpu-scope: %q", c.name)
+	}
+	return "end-gpu-scope"
+}
+
+// Exec executes a gpuScopeCommand.
+func (c *gpuScopeCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
+	if c.begin {
+		return theGPUTimer.beginScope(graphicsDriver, c.name)
+	}
+	return theGPUTimer.endScope(graphicsDriver)
+}
+
+func (c *gpuScopeCommand) NeedsSync() bool {
+	return false
+}
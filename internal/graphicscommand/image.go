@@ -21,6 +21,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unsafe"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/debug"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
@@ -36,6 +37,14 @@ type Image struct {
 	internalWidth  int
 	internalHeight int
 	screen         bool
+	format         graphicsdriver.PixelFormat
+
+	// rgba16Emulated reports whether the image with PixelFormatRGBA16 is emulated with an 8-bit texture
+	// as the graphics driver doesn't support PixelFormatRGBA16.
+	// The pixels are converted between 16-bit and 8-bit values when they are transferred.
+	//
+	// rgba16Emulated is accessed only on the render thread.
+	rgba16Emulated bool
 
 	// id is an identifier for the image. This is used only when dumping the information.
 	//
@@ -57,11 +66,12 @@ func genNextImageID() int {
 // NewImage returns a new image.
 //
 // Note that the image is not initialized yet.
-func NewImage(width, height int, screenFramebuffer bool) *Image {
+func NewImage(width, height int, screenFramebuffer bool, format graphicsdriver.PixelFormat) *Image {
 	i := &Image{
 		width:  width,
 		height: height,
 		screen: screenFramebuffer,
+		format: format,
 		id:     genNextImageID(),
 	}
 	c := &newImageCommand{
@@ -69,6 +79,56 @@ func NewImage(width, height int, screenFramebuffer bool) *Image {
 		width:  width,
 		height: height,
 		screen: screenFramebuffer,
+		format: format,
+	}
+	theCommandQueueManager.enqueueCommand(c)
+	return i
+}
+
+// NewMultisampledImage returns a new image with multisample anti-aliasing.
+// samples is the number of samples per pixel.
+//
+// The rendering result of the image is resolved automatically when the image is used as a source or its pixels are read.
+// Pixels cannot be written to the image directly by WritePixels.
+//
+// Note that the image is not initialized yet.
+func NewMultisampledImage(width, height int, format graphicsdriver.PixelFormat, samples int) *Image {
+	i := &Image{
+		width:  width,
+		height: height,
+		format: format,
+		id:     genNextImageID(),
+	}
+	c := &newImageCommand{
+		result:  i,
+		width:   width,
+		height:  height,
+		format:  format,
+		samples: samples,
+	}
+	theCommandQueueManager.enqueueCommand(c)
+	return i
+}
+
+// NewImageFromNativeTexture returns a new image referring to the given native texture.
+//
+// The native texture is not released when the image is disposed.
+func NewImageFromNativeTexture(handle uintptr, width, height int) *Image {
+	i := &Image{
+		width:  width,
+		height: height,
+		// A native texture doesn't have extra regions for a power of 2.
+		internalWidth:  width,
+		internalHeight: height,
+		format:         graphicsdriver.PixelFormatRGBA8,
+		id:             genNextImageID(),
+	}
+	c := &newImageCommand{
+		result:        i,
+		width:         width,
+		height:        height,
+		format:        graphicsdriver.PixelFormatRGBA8,
+		nativeTexture: handle,
 	}
 	theCommandQueueManager.enqueueCommand(c)
 	return i
@@ -130,7 +190,7 @@ func (i *Image) InternalSize() (int, int) {
 //
 // If the source image is not specified, i.e., src is nil and there is no image in the uniform variables, the
 // elements for the source image are not used.
-func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule) {
+func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
 	for _, src := range srcs {
 		if src == nil {
 			continue
@@ -142,7 +202,80 @@ func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 	}
 	i.flushBufferedWritePixels()
 
-	theCommandQueueManager.enqueueDrawTrianglesCommand(i, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule)
+	theCommandQueueManager.enqueueDrawTrianglesCommand(i, [graphics.ShaderDstImageCount - 1]*Image{}, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+}
+
+// DrawTrianglesToMultipleTargets draws triangles onto the image and the additional images at once.
+//
+// The first color of the shader is rendered onto the image, and the (i+1)-th color is rendered onto additionalDsts[i].
+// The images must have the same size and the same pixel format.
+//
+// DrawTrianglesToMultipleTargets is available only when IsMultipleRenderTargetsAvailable returns true.
+func (i *Image) DrawTrianglesToMultipleTargets(additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
+	for _, src := range srcs {
+		if src == nil {
+			continue
+		}
+		if src.screen {
+			panic("graphicscommand: the screen image cannot be the rendering source")
+		}
+		src.flushBufferedWritePixels()
+	}
+	if i.screen {
+		panic("graphicscommand: the screen image cannot be one of multiple render targets")
+	}
+	i.flushBufferedWritePixels()
+	for _, dst := range additionalDsts {
+		if dst == nil {
+			continue
+		}
+		if dst.screen {
+			panic("graphicscommand: the screen image cannot be one of multiple render targets")
+		}
+		dst.flushBufferedWritePixels()
+	}
+
+	theCommandQueueManager.enqueueDrawTrianglesCommand(i, additionalDsts, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+}
+
+// CopyFrom copies the pixels in srcRegion of src to the image at dstPoint without the rendering pipeline.
+//
+// CopyFrom is available only when IsImageCopyAvailable returns true.
+func (i *Image) CopyFrom(src *Image, dstPoint image.Point, srcRegion image.Rectangle) {
+	if src.screen {
+		panic("graphicscommand: the screen image cannot be the copying source")
+	}
+	src.flushBufferedWritePixels()
+	i.flushBufferedWritePixels()
+
+	theCommandQueueManager.enqueueCommand(&copyImageCommand{
+		dst:       i,
+		src:       src,
+		dstPoint:  dstPoint,
+		srcRegion: srcRegion,
+	})
+}
+
+// DispatchCompute executes the compute kernel shader with x * y * z workgroups.
+// images are bound to the storage images of the kernel in order.
+//
+// DispatchCompute is available only when the graphics driver has graphicsdriver.FeatureCompute.
+func DispatchCompute(shader *Shader, images []*Image, uniforms []uint32, x, y, z int) {
+	for _, img := range images {
+		if img.screen {
+			panic("graphicscommand: the screen image cannot be a storage image")
+		}
+		img.flushBufferedWritePixels()
+	}
+
+	theCommandQueueManager.enqueueCommand(&dispatchComputeCommand{
+		shader:   shader,
+		images:   append([]*Image(nil), images...),
+		uniforms: append([]uint32(nil), uniforms...),
+		x:        x,
+		y:        y,
+		z:        z,
+	})
 }
 
 // ReadPixels reads the image's pixels.
@@ -160,6 +293,20 @@ func (i *Image) ReadPixels(graphicsDriver graphicsdriver.Graphics, args []graphi
 	return nil
 }
 
+// ReadPixelsAsync starts reading the pixels without waiting for the GPU.
+//
+// callback is called by CallReadPixelsAsyncCallbacks after the pixels are written to args' Pixels.
+// args' Pixels must not be accessed until then.
+func (i *Image) ReadPixelsAsync(args []graphicsdriver.PixelsArgs, callback func(err error)) {
+	i.flushBufferedWritePixels()
+	c := &readPixelsAsyncCommand{
+		img:      i,
+		args:     args,
+		callback: callback,
+	}
+	theCommandQueueManager.enqueueCommand(c)
+}
+
 func (i *Image) WritePixels(pixels *graphics.ManagedBytes, region image.Rectangle) {
 	// Release the previous pixels if the region is included by the new region.
 	// Successive WritePixels calls might accumulate the pixels and never release,
@@ -197,7 +344,7 @@ func (i *Image) dumpTo(w io.Writer, graphicsDriver graphicsdriver.Graphics, blac
 		return fmt.Errorf("graphicscommand: a screen image cannot be dumped")
 	}
 
-	pix := make([]byte, 4*i.width*i.height)
+	pix := make([]byte, i.format.BytesPerPixel()*i.width*i.height)
 	if err := i.ReadPixels(graphicsDriver, []graphicsdriver.PixelsArgs{
 		{
 			Pixels: pix,
@@ -207,6 +354,15 @@ func (i *Image) dumpTo(w io.Writer, graphicsDriver graphicsdriver.Graphics, blac
 		return err
 	}
 
+	if i.format == graphicsdriver.PixelFormatRGBA16 {
+		// Take the higher bytes of the 16-bit values in the native byte order.
+		pix16 := unsafe.Slice((*uint16)(unsafe.Pointer(&pix[0])), len(pix)/2)
+		for idx, v := range pix16 {
+			pix[idx] = byte(v >> 8)
+		}
+		pix = pix[:len(pix16)]
+	}
+
 	if blackbg {
 		for i := 0; i < len(pix)/4; i++ {
 			pix[4*i+3] = 0xff
@@ -237,3 +393,57 @@ func LogImagesInfo(images []*Image) {
 		debug.FrameLogf("  %d: (%d, %d)%s\n", i.id, w, h, screen)
 	}
 }
+
+// writeDriverPixels writes the pixels to the graphics driver's image.
+// writeDriverPixels must be called on the render thread.
+func (i *Image) writeDriverPixels(args []graphicsdriver.PixelsArgs) error {
+	if !i.rgba16Emulated {
+		return i.image.WritePixels(args)
+	}
+
+	args8 := make([]graphicsdriver.PixelsArgs, len(args))
+	for idx, a := range args {
+		pix8 := make([]byte, len(a.Pixels)/2)
+		if len(a.Pixels) > 0 {
+			// Take the higher bytes of the 16-bit values in the native byte order.
+			pix16 := unsafe.Slice((*uint16)(unsafe.Pointer(&a.Pixels[0])), len(a.Pixels)/2)
+			for j, v := range pix16 {
+				pix8[j] = byte(v >> 8)
+			}
+		}
+		args8[idx] = graphicsdriver.PixelsArgs{
+			Pixels: pix8,
+			Region: a.Region,
+		}
+	}
+	return i.image.WritePixels(args8)
+}
+
+// readDriverPixels reads the pixels from the graphics driver's image.
+// readDriverPixels must be called on the render thread.
+func (i *Image) readDriverPixels(args []graphicsdriver.PixelsArgs) error {
+	if !i.rgba16Emulated {
+		return i.image.ReadPixels(args)
+	}
+
+	args8 := make([]graphicsdriver.PixelsArgs, len(args))
+	for idx, a := range args {
+		args8[idx] = graphicsdriver.PixelsArgs{
+			Pixels: make([]byte, len(a.Pixels)/2),
+			Region: a.Region,
+		}
+	}
+	if err := i.image.ReadPixels(args8); err != nil {
+		return err
+	}
+	for idx, a := range args {
+		if len(a.Pixels) == 0 {
+			continue
+		}
+		pix16 := unsafe.Slice((*uint16)(unsafe.Pointer(&a.Pixels[0])), len(a.Pixels)/2)
+		for j, v := range args8[idx].Pixels {
+			pix16[j] = uint16(v) * 0x101
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphicscommand
+
+import (
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+)
+
+// OcclusionQuery represents a query to count the samples rendered on the GPU.
+type OcclusionQuery struct {
+	// query is accessed only from the render thread.
+	query graphicsdriver.OcclusionQuery
+
+	samples atomic.Int64
+	done    atomic.Bool
+}
+
+// Result returns the number of the samples and reports whether the result is available.
+//
+// If occlusion queries are not available, Result returns -1 as the number of the samples.
+//
+// Result is concurrent-safe.
+func (q *OcclusionQuery) Result() (int64, bool) {
+	if !q.done.Load() {
+		return 0, false
+	}
+	return q.samples.Load(), true
+}
+
+func (q *OcclusionQuery) finish(samples int64) {
+	q.samples.Store(samples)
+	q.done.Store(true)
+}
+
+// occlusionQuerier manages the occlusion queries waiting for their results.
+type occlusionQuerier struct {
+	// active is the query being recorded. active is accessed only from the game goroutine.
+	active *OcclusionQuery
+
+	// pendings is accessed only from the render thread.
+	pendings []*OcclusionQuery
+}
+
+var theOcclusionQuerier occlusionQuerier
+
+// poll must be called from the render thread.
+func (o *occlusionQuerier) poll() error {
+	var cur int
+	for _, q := range o.pendings {
+		samples, done, err := q.query.TryFinish()
+		if err != nil {
+			return err
+		}
+		if !done {
+			o.pendings[cur] = q
+			cur++
+			continue
+		}
+		q.query = nil
+		q.finish(int64(samples))
+	}
+	for i := cur; i < len(o.pendings); i++ {
+		o.pendings[i] = nil
+	}
+	o.pendings = o.pendings[:cur]
+	return nil
+}
+
+// BeginOcclusionQuery enqueues a command to start counting the samples for the query.
+//
+// BeginOcclusionQuery must be called from the game goroutine.
+func BeginOcclusionQuery(query *OcclusionQuery) {
+	if theOcclusionQuerier.active != nil {
+		panic("graphicscommand: occlusion queries cannot be nested")
+	}
+	theOcclusionQuerier.active = query
+	theCommandQueueManager.enqueueCommand(&occlusionQueryCommand{
+		query: query,
+		begin: true,
+	})
+}
+
+// EndOcclusionQuery enqueues a command to end counting the samples for the active query.
+//
+// EndOcclusionQuery must be called from the game goroutine.
+func EndOcclusionQuery() {
+	query := theOcclusionQuerier.active
+	if query == nil {
+		panic("graphicscommand: EndOcclusionQuery must be called after BeginOcclusionQuery")
+	}
+	theOcclusionQuerier.active = nil
+	theCommandQueueManager.enqueueCommand(&occlusionQueryCommand{
+		query: query,
+	})
+}
+
+// occlusionQueryCommand represents a command to begin or end an occlusion query.
+type occlusionQueryCommand struct {
+	query *OcclusionQuery
+	begin bool
+}
+
+func (c *occlusionQueryCommand) String() string {
+	if c.begin {
+		return "begin-occlusion-query"
+	}
+	return "end-occlusion-query"
+}
+
+// Exec executes an occlusionQueryCommand.
+func (c *occlusionQueryCommand) Exec(commandQueue *commandQueue, graphicsDriver graphicsdriver.Graphics, indexOffset int) error {
+	o, ok := graphicsDriver.(graphicsdriver.OcclusionQuerier)
+	if !ok {
+		c.query.finish(-1)
+		return nil
+	}
+
+	if c.begin {
+		q, err := o.BeginOcclusionQuery()
+		if err != nil {
+			return err
+		}
+		c.query.query = q
+		return nil
+	}
+
+	if err := o.EndOcclusionQuery(); err != nil {
+		return err
+	}
+	theOcclusionQuerier.pendings = append(theOcclusionQuerier.pendings, c.query)
+	return nil
+}
+
+func (c *occlusionQueryCommand) NeedsSync() bool {
+	return false
+}
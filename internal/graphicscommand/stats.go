@@ -0,0 +1,73 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphicscommand
+
+import (
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+)
+
+// FrameStats is statistics of the graphics commands executed in a frame.
+type FrameStats struct {
+	// DrawCalls is the number of the executed draw-triangles commands.
+	DrawCalls int
+
+	// Vertices is the number of the vertices sent to GPU.
+	Vertices int
+
+	// ImageUploads is the number of the regions uploaded by write-pixels commands.
+	ImageUploads int
+
+	// UploadedBytes is the number of the bytes uploaded by write-pixels commands.
+	UploadedBytes int
+}
+
+var (
+	currentFrameStats FrameStats
+	lastFrameStats    FrameStats
+	frameStatsM       sync.Mutex
+)
+
+// LastFrameStats returns the statistics of the last completed frame.
+func LastFrameStats() FrameStats {
+	frameStatsM.Lock()
+	defer frameStatsM.Unlock()
+	return lastFrameStats
+}
+
+// recordCommand records the command to the statistics of the current frame.
+// recordCommand must be called on the render thread.
+func recordCommand(c command) {
+	switch c := c.(type) {
+	case *drawTrianglesCommand:
+		currentFrameStats.DrawCalls++
+		currentFrameStats.Vertices += c.numVertices() / graphics.VertexFloatCount
+	case *writePixelsCommand:
+		currentFrameStats.ImageUploads += len(c.args)
+		for _, a := range c.args {
+			currentFrameStats.UploadedBytes += c.dst.format.BytesPerPixel() * a.region.Dx() * a.region.Dy()
+		}
+	}
+}
+
+// endFrameStats finishes the statistics of the current frame.
+// endFrameStats must be called on the render thread.
+func endFrameStats() {
+	frameStatsM.Lock()
+	defer frameStatsM.Unlock()
+	lastFrameStats = currentFrameStats
+	currentFrameStats = FrameStats{}
+}
@@ -23,6 +23,24 @@ type Blend struct {
 	BlendOperationAlpha         BlendOperation
 }
 
+// IsDualSource reports whether the blend refers to the secondary source color.
+func (b Blend) IsDualSource() bool {
+	return b.BlendFactorSourceRGB.IsDualSource() ||
+		b.BlendFactorSourceAlpha.IsDualSource() ||
+		b.BlendFactorDestinationRGB.IsDualSource() ||
+		b.BlendFactorDestinationAlpha.IsDualSource()
+}
+
+// PrimarySourceOnly returns a new blend whose factors for the secondary source color are replaced with
+// the corresponding factors for the primary source color.
+func (b Blend) PrimarySourceOnly() Blend {
+	b.BlendFactorSourceRGB = b.BlendFactorSourceRGB.primarySource()
+	b.BlendFactorSourceAlpha = b.BlendFactorSourceAlpha.primarySource()
+	b.BlendFactorDestinationRGB = b.BlendFactorDestinationRGB.primarySource()
+	b.BlendFactorDestinationAlpha = b.BlendFactorDestinationAlpha.primarySource()
+	return b
+}
+
 type BlendFactor byte
 
 const (
@@ -37,8 +55,37 @@ const (
 	BlendFactorDestinationAlpha
 	BlendFactorOneMinusDestinationAlpha
 	BlendFactorSourceAlphaSaturated
+
+	// The factors below refer to the secondary source color for dual-source blending.
+	BlendFactorSourceColor1
+	BlendFactorOneMinusSourceColor1
+	BlendFactorSourceAlpha1
+	BlendFactorOneMinusSourceAlpha1
 )
 
+func (b BlendFactor) primarySource() BlendFactor {
+	switch b {
+	case BlendFactorSourceColor1:
+		return BlendFactorSourceColor
+	case BlendFactorOneMinusSourceColor1:
+		return BlendFactorOneMinusSourceColor
+	case BlendFactorSourceAlpha1:
+		return BlendFactorSourceAlpha
+	case BlendFactorOneMinusSourceAlpha1:
+		return BlendFactorOneMinusSourceAlpha
+	}
+	return b
+}
+
+// IsDualSource reports whether the factor refers to the secondary source color.
+func (b BlendFactor) IsDualSource() bool {
+	switch b {
+	case BlendFactorSourceColor1, BlendFactorOneMinusSourceColor1, BlendFactorSourceAlpha1, BlendFactorOneMinusSourceAlpha1:
+		return true
+	}
+	return false
+}
+
 type BlendOperation byte
 
 const (
@@ -67,6 +114,15 @@ var BlendClear = Blend{
 	BlendOperationAlpha:         BlendOperationAdd,
 }
 
+var BlendDestination = Blend{
+	BlendFactorSourceRGB:        BlendFactorZero,
+	BlendFactorSourceAlpha:      BlendFactorZero,
+	BlendFactorDestinationRGB:   BlendFactorOne,
+	BlendFactorDestinationAlpha: BlendFactorOne,
+	BlendOperationRGB:           BlendOperationAdd,
+	BlendOperationAlpha:         BlendOperationAdd,
+}
+
 var BlendCopy = Blend{
 	BlendFactorSourceRGB:        BlendFactorOne,
 	BlendFactorSourceAlpha:      BlendFactorOne,
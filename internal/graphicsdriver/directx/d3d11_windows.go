@@ -177,6 +177,7 @@ type _D3D11_FILTER int32
 
 const (
 	_D3D11_FILTER_MIN_MAG_MIP_POINT _D3D11_FILTER = 0
+	_D3D11_FILTER_ANISOTROPIC       _D3D11_FILTER = 0x55
 )
 
 type _D3D11_INPUT_CLASSIFICATION int32
@@ -208,6 +209,23 @@ const (
 	_D3D11_PRIMITIVE_TOPOLOGY_TRIANGLELIST _D3D11_PRIMITIVE_TOPOLOGY = 4
 )
 
+type _D3D11_QUERY int32
+
+const (
+	_D3D11_QUERY_EVENT               _D3D11_QUERY = 0
+	_D3D11_QUERY_OCCLUSION           _D3D11_QUERY = 1
+	_D3D11_QUERY_TIMESTAMP           _D3D11_QUERY = 2
+	_D3D11_QUERY_TIMESTAMP_DISJOINT  _D3D11_QUERY = 3
+	_D3D11_QUERY_PIPELINE_STATISTICS _D3D11_QUERY = 4
+	_D3D11_QUERY_OCCLUSION_PREDICATE _D3D11_QUERY = 5
+)
+
+type _D3D11_ASYNC_GETDATA_FLAG int32
+
+const (
+	_D3D11_ASYNC_GETDATA_DONOTFLUSH _D3D11_ASYNC_GETDATA_FLAG = 0x1
+)
+
 type _D3D11_RTV_DIMENSION int32
 
 const (
@@ -286,6 +304,18 @@ const (
 	_D3D11_TEXTURE_ADDRESS_MIRROR_ONCE _D3D11_TEXTURE_ADDRESS_MODE = 5
 )
 
+type _D3D11_UAV_DIMENSION int32
+
+const (
+	_D3D11_UAV_DIMENSION_UNKNOWN        _D3D11_UAV_DIMENSION = 0
+	_D3D11_UAV_DIMENSION_BUFFER         _D3D11_UAV_DIMENSION = 1
+	_D3D11_UAV_DIMENSION_TEXTURE1D      _D3D11_UAV_DIMENSION = 2
+	_D3D11_UAV_DIMENSION_TEXTURE1DARRAY _D3D11_UAV_DIMENSION = 3
+	_D3D11_UAV_DIMENSION_TEXTURE2D      _D3D11_UAV_DIMENSION = 4
+	_D3D11_UAV_DIMENSION_TEXTURE2DARRAY _D3D11_UAV_DIMENSION = 5
+	_D3D11_UAV_DIMENSION_TEXTURE3D      _D3D11_UAV_DIMENSION = 8
+)
+
 type _D3D11_USAGE int32
 
 const (
@@ -364,6 +394,16 @@ type _D3D11_MAPPED_SUBRESOURCE struct {
 	DepthPitch uint32
 }
 
+type _D3D11_QUERY_DATA_TIMESTAMP_DISJOINT struct {
+	Frequency uint64
+	Disjoint  _BOOL
+}
+
+type _D3D11_QUERY_DESC struct {
+	Query     _D3D11_QUERY
+	MiscFlags uint32
+}
+
 type _D3D11_RECT struct {
 	left   int32
 	top    int32
@@ -439,6 +479,12 @@ type _D3D11_TEXTURE2D_DESC struct {
 	MiscFlags      uint32
 }
 
+type _D3D11_UNORDERED_ACCESS_VIEW_DESC struct {
+	Format        _DXGI_FORMAT
+	ViewDimension _D3D11_UAV_DIMENSION
+	_             [3]uint32
+}
+
 type _D3D11_VIEWPORT struct {
 	TopLeftX float32
 	TopLeftY float32
@@ -575,6 +621,27 @@ type _ID3D11ClassLinkage_Vtbl struct {
 	CreateClassInstance uintptr
 }
 
+type _ID3D11ComputeShader struct {
+	vtbl *_ID3D11ComputeShader_Vtbl
+}
+
+type _ID3D11ComputeShader_Vtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+
+	// ID3D11DeviceChild
+	GetDevice               uintptr
+	GetPrivateData          uintptr
+	SetPrivateData          uintptr
+	SetPrivateDataInterface uintptr
+}
+
+func (i *_ID3D11ComputeShader) Release() uint32 {
+	r, _, _ := syscall.Syscall(i.vtbl.Release, 1, uintptr(unsafe.Pointer(i)), 0, 0)
+	return uint32(r)
+}
+
 type _ID3D11DepthStencilState struct {
 	vtbl *_ID3D11DepthStencilState_Vtbl
 }
@@ -698,6 +765,17 @@ func (i *_ID3D11Device) CreateBuffer(pDesc *_D3D11_BUFFER_DESC, pInitialData *_D
 	return buffer, nil
 }
 
+func (i *_ID3D11Device) CreateComputeShader(pShaderBytecode unsafe.Pointer, bytecodeLength uintptr, pClassLinkage *_ID3D11ClassLinkage) (*_ID3D11ComputeShader, error) {
+	var computeShader *_ID3D11ComputeShader
+	r, _, _ := syscall.Syscall6(i.vtbl.CreateComputeShader, 5, uintptr(unsafe.Pointer(i)),
+		uintptr(pShaderBytecode), bytecodeLength, uintptr(unsafe.Pointer(pClassLinkage)),
+		uintptr(unsafe.Pointer(&computeShader)), 0)
+	if uint32(r) != uint32(windows.S_OK) {
+		return nil, fmt.Errorf("directx: ID3D11Device::CreateComputeShader failed: %w", handleError(windows.Handle(uint32(r))))
+	}
+	return computeShader, nil
+}
+
 func (i *_ID3D11Device) CreateDepthStencilState(pDepthStencilDesc *_D3D11_DEPTH_STENCIL_DESC) (*_ID3D11DepthStencilState, error) {
 	var dss *_ID3D11DepthStencilState
 	r, _, _ := syscall.Syscall(i.vtbl.CreateDepthStencilState, 3, uintptr(unsafe.Pointer(i)),
@@ -750,6 +828,17 @@ func (i *_ID3D11Device) CreatePixelShader(pShaderBytecode unsafe.Pointer, byteco
 	return pixelShader, nil
 }
 
+func (i *_ID3D11Device) CreateQuery(pQueryDesc *_D3D11_QUERY_DESC) (*_ID3D11Query, error) {
+	var query *_ID3D11Query
+	r, _, _ := syscall.Syscall(i.vtbl.CreateQuery, 3, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pQueryDesc)), uintptr(unsafe.Pointer(&query)))
+	runtime.KeepAlive(pQueryDesc)
+	if uint32(r) != uint32(windows.S_OK) {
+		return nil, fmt.Errorf("directx: ID3D11Device::CreateQuery failed: %w", handleError(windows.Handle(uint32(r))))
+	}
+	return query, nil
+}
+
 func (i *_ID3D11Device) CreateRasterizerState(pRasterizerDesc *_D3D11_RASTERIZER_DESC) (*_ID3D11RasterizerState, error) {
 	var rs *_ID3D11RasterizerState
 	r, _, _ := syscall.Syscall(i.vtbl.CreateRasterizerState, 3, uintptr(unsafe.Pointer(i)),
@@ -811,6 +900,18 @@ func (i *_ID3D11Device) CreateTexture2D(pDesc *_D3D11_TEXTURE2D_DESC, pInitialDa
 	return texture, nil
 }
 
+func (i *_ID3D11Device) CreateUnorderedAccessView(pResource unsafe.Pointer, pDesc *_D3D11_UNORDERED_ACCESS_VIEW_DESC) (*_ID3D11UnorderedAccessView, error) {
+	var uaView *_ID3D11UnorderedAccessView
+	r, _, _ := syscall.Syscall6(i.vtbl.CreateUnorderedAccessView, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(pResource), uintptr(unsafe.Pointer(pDesc)), uintptr(unsafe.Pointer(&uaView)),
+		0, 0)
+	runtime.KeepAlive(pDesc)
+	if uint32(r) != uint32(windows.S_OK) {
+		return nil, fmt.Errorf("directx: ID3D11Device::CreateUnorderedAccessView failed: %w", handleError(windows.Handle(uint32(r))))
+	}
+	return uaView, nil
+}
+
 func (i *_ID3D11Device) CreateVertexShader(pShaderBytecode unsafe.Pointer, bytecodeLength uintptr, pClassLinkage *_ID3D11ClassLinkage) (*_ID3D11VertexShader, error) {
 	var vertexShader *_ID3D11VertexShader
 	r, _, _ := syscall.Syscall6(i.vtbl.CreateVertexShader, 5, uintptr(unsafe.Pointer(i)),
@@ -957,6 +1058,57 @@ type _ID3D11DeviceContext_Vtbl struct {
 	FinishCommandList                         uintptr
 }
 
+func (i *_ID3D11DeviceContext) Begin(pAsync *_ID3D11Query) {
+	_, _, _ = syscall.Syscall(i.vtbl.Begin, 2, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pAsync)), 0)
+	runtime.KeepAlive(pAsync)
+}
+
+func (i *_ID3D11DeviceContext) CSSetConstantBuffers(startSlot uint32, constantBuffers []*_ID3D11Buffer) {
+	var ppConstantBuffers **_ID3D11Buffer
+	if len(constantBuffers) > 0 {
+		ppConstantBuffers = &constantBuffers[0]
+	}
+	_, _, _ = syscall.Syscall6(i.vtbl.CSSetConstantBuffers, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(startSlot), uintptr(len(constantBuffers)), uintptr(unsafe.Pointer(ppConstantBuffers)),
+		0, 0)
+	runtime.KeepAlive(constantBuffers)
+}
+
+func (i *_ID3D11DeviceContext) CSSetShader(pComputeShader *_ID3D11ComputeShader, classInstances []*_ID3D11ClassInstance) {
+	var ppClassInstances **_ID3D11ClassInstance
+	if len(classInstances) > 0 {
+		ppClassInstances = &classInstances[0]
+	}
+	_, _, _ = syscall.Syscall6(i.vtbl.CSSetShader, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pComputeShader)), uintptr(unsafe.Pointer(ppClassInstances)), uintptr(len(classInstances)),
+		0, 0)
+	runtime.KeepAlive(pComputeShader)
+}
+
+func (i *_ID3D11DeviceContext) CSSetShaderResources(startSlot uint32, shaderResourceViews []*_ID3D11ShaderResourceView) {
+	var ppShaderResourceViews **_ID3D11ShaderResourceView
+	if len(shaderResourceViews) > 0 {
+		ppShaderResourceViews = &shaderResourceViews[0]
+	}
+	_, _, _ = syscall.Syscall6(i.vtbl.CSSetShaderResources, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(startSlot), uintptr(len(shaderResourceViews)), uintptr(unsafe.Pointer(ppShaderResourceViews)),
+		0, 0)
+	runtime.KeepAlive(shaderResourceViews)
+}
+
+func (i *_ID3D11DeviceContext) CSSetUnorderedAccessViews(startSlot uint32, unorderedAccessViews []*_ID3D11UnorderedAccessView) {
+	var ppUnorderedAccessViews **_ID3D11UnorderedAccessView
+	if len(unorderedAccessViews) > 0 {
+		ppUnorderedAccessViews = &unorderedAccessViews[0]
+	}
+	// pUAVInitialCounts is ignored for a texture UAV.
+	_, _, _ = syscall.Syscall6(i.vtbl.CSSetUnorderedAccessViews, 5, uintptr(unsafe.Pointer(i)),
+		uintptr(startSlot), uintptr(len(unorderedAccessViews)), uintptr(unsafe.Pointer(ppUnorderedAccessViews)),
+		0, 0)
+	runtime.KeepAlive(unorderedAccessViews)
+}
+
 func (i *_ID3D11DeviceContext) ClearState() {
 	_, _, _ = syscall.Syscall(i.vtbl.ClearState, 1, uintptr(unsafe.Pointer(i)),
 		0, 0)
@@ -969,6 +1121,13 @@ func (i *_ID3D11DeviceContext) ClearDepthStencilView(pDepthStencilView *_ID3D11D
 	runtime.KeepAlive(pDepthStencilView)
 }
 
+func (i *_ID3D11DeviceContext) CopyResource(pDstResource unsafe.Pointer, pSrcResource unsafe.Pointer) {
+	_, _, _ = syscall.Syscall(i.vtbl.CopyResource, 3, uintptr(unsafe.Pointer(i)),
+		uintptr(pDstResource), uintptr(pSrcResource))
+	runtime.KeepAlive(pDstResource)
+	runtime.KeepAlive(pSrcResource)
+}
+
 func (i *_ID3D11DeviceContext) CopySubresourceRegion(pDstResource unsafe.Pointer, dstSubresource uint32, dstX uint32, dstY uint32, dstZ uint32, pSrcResource unsafe.Pointer, srcSubresource uint32, pSrcBox *_D3D11_BOX) {
 	_, _, _ = syscall.Syscall9(i.vtbl.CopySubresourceRegion, 9, uintptr(unsafe.Pointer(i)),
 		uintptr(pDstResource), uintptr(dstSubresource), uintptr(dstX),
@@ -978,12 +1137,43 @@ func (i *_ID3D11DeviceContext) CopySubresourceRegion(pDstResource unsafe.Pointer
 	runtime.KeepAlive(pSrcResource)
 }
 
+func (i *_ID3D11DeviceContext) Dispatch(threadGroupCountX uint32, threadGroupCountY uint32, threadGroupCountZ uint32) {
+	_, _, _ = syscall.Syscall6(i.vtbl.Dispatch, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(threadGroupCountX), uintptr(threadGroupCountY), uintptr(threadGroupCountZ),
+		0, 0)
+}
+
 func (i *_ID3D11DeviceContext) DrawIndexed(indexCount uint32, startIndexLocation uint32, baseVertexLocation int32) {
 	_, _, _ = syscall.Syscall6(i.vtbl.DrawIndexed, 4, uintptr(unsafe.Pointer(i)),
 		uintptr(indexCount), uintptr(startIndexLocation), uintptr(baseVertexLocation),
 		0, 0)
 }
 
+func (i *_ID3D11DeviceContext) End(pAsync *_ID3D11Query) {
+	_, _, _ = syscall.Syscall(i.vtbl.End, 2, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pAsync)), 0)
+	runtime.KeepAlive(pAsync)
+}
+
+func (i *_ID3D11DeviceContext) Flush() {
+	_, _, _ = syscall.Syscall(i.vtbl.Flush, 1, uintptr(unsafe.Pointer(i)), 0, 0)
+}
+
+func (i *_ID3D11DeviceContext) GetData(pAsync *_ID3D11Query, pData unsafe.Pointer, dataSize uint32, getDataFlags _D3D11_ASYNC_GETDATA_FLAG) (bool, error) {
+	r, _, _ := syscall.Syscall6(i.vtbl.GetData, 5, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pAsync)), uintptr(pData), uintptr(dataSize), uintptr(getDataFlags),
+		0)
+	runtime.KeepAlive(pAsync)
+	switch uint32(r) {
+	case uint32(windows.S_OK):
+		return true, nil
+	case uint32(windows.S_FALSE):
+		return false, nil
+	default:
+		return false, fmt.Errorf("directx: ID3D11DeviceContext::GetData failed: %w", handleError(windows.Handle(uint32(r))))
+	}
+}
+
 func (i *_ID3D11DeviceContext) IASetIndexBuffer(pIndexBuffer *_ID3D11Buffer, format _DXGI_FORMAT, offset uint32) {
 	_, _, _ = syscall.Syscall6(i.vtbl.IASetIndexBuffer, 4, uintptr(unsafe.Pointer(i)),
 		uintptr(unsafe.Pointer(pIndexBuffer)), uintptr(format), uintptr(offset),
@@ -1201,6 +1391,32 @@ func (i *_ID3D11PixelShader) Release() uint32 {
 	return uint32(r)
 }
 
+type _ID3D11Query struct {
+	vtbl *_ID3D11Query_Vtbl
+}
+
+type _ID3D11Query_Vtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+
+	// ID3D11DeviceChild
+	GetDevice               uintptr
+	GetPrivateData          uintptr
+	SetPrivateData          uintptr
+	SetPrivateDataInterface uintptr
+
+	// ID3D11Asynchronous
+	GetDataSize uintptr
+
+	GetDesc uintptr
+}
+
+func (i *_ID3D11Query) Release() uint32 {
+	r, _, _ := syscall.Syscall(i.vtbl.Release, 1, uintptr(unsafe.Pointer(i)), 0, 0)
+	return uint32(r)
+}
+
 type _ID3D11RasterizerState struct {
 	vtbl *_ID3D11RasterizerState_Vtbl
 }
@@ -1327,6 +1543,32 @@ func (i *_ID3D11Texture2D) Release() uint32 {
 	return uint32(r)
 }
 
+type _ID3D11UnorderedAccessView struct {
+	vtbl *_ID3D11UnorderedAccessView_Vtbl
+}
+
+type _ID3D11UnorderedAccessView_Vtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+
+	// ID3D11DeviceChild
+	GetDevice               uintptr
+	GetPrivateData          uintptr
+	SetPrivateData          uintptr
+	SetPrivateDataInterface uintptr
+
+	// ID3D11View
+	GetResource uintptr
+
+	GetDesc uintptr
+}
+
+func (i *_ID3D11UnorderedAccessView) Release() uint32 {
+	r, _, _ := syscall.Syscall(i.vtbl.Release, 1, uintptr(unsafe.Pointer(i)), 0, 0)
+	return uint32(r)
+}
+
 type _ID3D11VertexShader struct {
 	vtbl *_ID3D11VertexShader_Vtbl
 }
@@ -219,6 +219,7 @@ type _D3D12_FILTER int32
 
 const (
 	_D3D12_FILTER_MIN_MAG_MIP_POINT _D3D12_FILTER = 0
+	_D3D12_FILTER_ANISOTROPIC       _D3D12_FILTER = 0x55
 )
 
 type _D3D12_HEAP_FLAGS int32
@@ -296,6 +297,21 @@ const (
 	_D3D12_PRIMITIVE_TOPOLOGY_TYPE_PATCH     _D3D12_PRIMITIVE_TOPOLOGY_TYPE = 4
 )
 
+type _D3D12_QUERY_HEAP_TYPE int32
+
+const (
+	_D3D12_QUERY_HEAP_TYPE_OCCLUSION _D3D12_QUERY_HEAP_TYPE = 0
+	_D3D12_QUERY_HEAP_TYPE_TIMESTAMP _D3D12_QUERY_HEAP_TYPE = 1
+)
+
+type _D3D12_QUERY_TYPE int32
+
+const (
+	_D3D12_QUERY_TYPE_OCCLUSION        _D3D12_QUERY_TYPE = 0
+	_D3D12_QUERY_TYPE_BINARY_OCCLUSION _D3D12_QUERY_TYPE = 1
+	_D3D12_QUERY_TYPE_TIMESTAMP        _D3D12_QUERY_TYPE = 2
+)
+
 type _D3D12_RESOURCE_BARRIER_FLAGS int32
 
 const (
@@ -542,6 +558,7 @@ var (
 	_IID_ID3D12Fence               = windows.GUID{Data1: 0x0a753dcf, Data2: 0xc4d8, Data3: 0x4b91, Data4: [...]byte{0xad, 0xf6, 0xbe, 0x5a, 0x60, 0xd9, 0x5a, 0x76}}
 	_IID_ID3D12GraphicsCommandList = windows.GUID{Data1: 0x5b160d0f, Data2: 0xac1b, Data3: 0x4185, Data4: [...]byte{0x8b, 0xa8, 0xb3, 0xae, 0x42, 0xa5, 0xa4, 0x55}}
 	_IID_ID3D12PipelineState       = windows.GUID{Data1: 0x765a30f3, Data2: 0xf624, Data3: 0x4c6f, Data4: [...]byte{0xa8, 0x28, 0xac, 0xe9, 0x48, 0x62, 0x24, 0x45}}
+	_IID_ID3D12QueryHeap           = windows.GUID{Data1: 0x0d9658ae, Data2: 0xed45, Data3: 0x469e, Data4: [...]byte{0xa6, 0x1d, 0x97, 0x0e, 0xc5, 0x83, 0xca, 0xb4}}
 	_IID_ID3D12Resource            = windows.GUID{Data1: 0x696442be, Data2: 0xa72e, Data3: 0x4059, Data4: [...]byte{0xbc, 0x79, 0x5b, 0x5c, 0x98, 0x04, 0x0f, 0xad}}
 	_IID_ID3D12RootSignature       = windows.GUID{Data1: 0xc54a6b66, Data2: 0x72df, Data3: 0x4ee8, Data4: [...]byte{0x8b, 0xe5, 0xa9, 0x46, 0xa1, 0x42, 0x92, 0x14}}
 )
@@ -972,6 +989,12 @@ type _D3D12_PLACED_SUBRESOURCE_FOOTPRINT struct {
 	Footprint _D3D12_SUBRESOURCE_FOOTPRINT
 }
 
+type _D3D12_QUERY_HEAP_DESC struct {
+	Type     _D3D12_QUERY_HEAP_TYPE
+	Count    uint32
+	NodeMask uint32
+}
+
 type _D3D12_RENDER_TARGET_BLEND_DESC struct {
 	BlendEnable           _BOOL
 	LogicOpEnable         _BOOL
@@ -1091,6 +1114,16 @@ func (i *_ID3D12CommandQueue) ExecuteCommandLists(ppCommandLists []*_ID3D12Graph
 	runtime.KeepAlive(ppCommandLists)
 }
 
+func (i *_ID3D12CommandQueue) GetTimestampFrequency() (uint64, error) {
+	var frequency uint64
+	r, _, _ := syscall.Syscall(i.vtbl.GetTimestampFrequency, 2, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(&frequency)), 0)
+	if uint32(r) != uint32(windows.S_OK) {
+		return 0, fmt.Errorf("directx: ID3D12CommandQueue::GetTimestampFrequency failed: %w", handleError(windows.Handle(uint32(r))))
+	}
+	return frequency, nil
+}
+
 func (i *_ID3D12CommandQueue) PresentX(planeCount uint32, pPlaneParameters *_D3D12XBOX_PRESENT_PLANE_PARAMETERS, pPresentParameters *_D3D12XBOX_PRESENT_PARAMETERS) error {
 	r, _, _ := syscall.Syscall6(i.vtbl.PresentX, 4, uintptr(unsafe.Pointer(i)), uintptr(planeCount), uintptr(unsafe.Pointer(pPlaneParameters)), uintptr(unsafe.Pointer(pPresentParameters)), 0, 0)
 	runtime.KeepAlive(pPlaneParameters)
@@ -1499,6 +1532,18 @@ func (i *_ID3D12Device) CreateGraphicsPipelineState(pDesc *_D3D12_GRAPHICS_PIPEL
 	return pipelineState, nil
 }
 
+func (i *_ID3D12Device) CreateQueryHeap(pDesc *_D3D12_QUERY_HEAP_DESC) (*_ID3D12QueryHeap, error) {
+	var queryHeap *_ID3D12QueryHeap
+	r, _, _ := syscall.Syscall6(i.vtbl.CreateQueryHeap, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pDesc)), uintptr(unsafe.Pointer(&_IID_ID3D12QueryHeap)), uintptr(unsafe.Pointer(&queryHeap)),
+		0, 0)
+	runtime.KeepAlive(pDesc)
+	if uint32(r) != uint32(windows.S_OK) {
+		return nil, fmt.Errorf("directx: ID3D12Device::CreateQueryHeap failed: %w", handleError(windows.Handle(uint32(r))))
+	}
+	return queryHeap, nil
+}
+
 func (i *_ID3D12Device) CreateRenderTargetView(pResource *_ID3D12Resource, pDesc *_D3D12_RENDER_TARGET_VIEW_DESC, destDescriptor _D3D12_CPU_DESCRIPTOR_HANDLE) {
 	_, _, _ = syscall.Syscall6(i.vtbl.CreateRenderTargetView, 4, uintptr(unsafe.Pointer(i)),
 		uintptr(unsafe.Pointer(pResource)), uintptr(unsafe.Pointer(pDesc)), destDescriptor.ptr,
@@ -1801,6 +1846,16 @@ func (i *_ID3D12GraphicsCommandList) DrawIndexedInstanced(indexCountPerInstance
 		uintptr(indexCountPerInstance), uintptr(instanceCount), uintptr(startIndexLocation), uintptr(baseVertexLocation), uintptr(startInstanceLocation))
 }
 
+func (i *_ID3D12GraphicsCommandList) EndQuery(pQueryHeap *_ID3D12QueryHeap, typ _D3D12_QUERY_TYPE, index uint32) {
+	if microsoftgdk.IsXbox() {
+		panic("directx: ID3D12GraphicsCommandList::EndQuery is not implemented for Xbox")
+	}
+	_, _, _ = syscall.Syscall6(i.vtbl.EndQuery, 4, uintptr(unsafe.Pointer(i)),
+		uintptr(unsafe.Pointer(pQueryHeap)), uintptr(typ), uintptr(index),
+		0, 0)
+	runtime.KeepAlive(pQueryHeap)
+}
+
 func (i *_ID3D12GraphicsCommandList) IASetIndexBuffer(pView *_D3D12_INDEX_BUFFER_VIEW) {
 	if microsoftgdk.IsXbox() {
 		_ID3D12GraphicsCommandList_IASetIndexBuffer(i, pView)
@@ -1884,6 +1939,25 @@ func (i *_ID3D12GraphicsCommandList) Reset(pAllocator *_ID3D12CommandAllocator,
 	return nil
 }
 
+func (i *_ID3D12GraphicsCommandList) ResolveQueryData(pQueryHeap *_ID3D12QueryHeap, typ _D3D12_QUERY_TYPE, startIndex uint32, numQueries uint32, pDestinationBuffer *_ID3D12Resource, alignedDestinationBufferOffset uint64) {
+	if microsoftgdk.IsXbox() {
+		panic("directx: ID3D12GraphicsCommandList::ResolveQueryData is not implemented for Xbox")
+	}
+	if is64bit {
+		_, _, _ = syscall.Syscall9(i.vtbl.ResolveQueryData, 7, uintptr(unsafe.Pointer(i)),
+			uintptr(unsafe.Pointer(pQueryHeap)), uintptr(typ), uintptr(startIndex),
+			uintptr(numQueries), uintptr(unsafe.Pointer(pDestinationBuffer)), uintptr(alignedDestinationBufferOffset),
+			0, 0)
+	} else {
+		_, _, _ = syscall.Syscall9(i.vtbl.ResolveQueryData, 8, uintptr(unsafe.Pointer(i)),
+			uintptr(unsafe.Pointer(pQueryHeap)), uintptr(typ), uintptr(startIndex),
+			uintptr(numQueries), uintptr(unsafe.Pointer(pDestinationBuffer)), uintptr(alignedDestinationBufferOffset),
+			uintptr(alignedDestinationBufferOffset>>32), 0)
+	}
+	runtime.KeepAlive(pQueryHeap)
+	runtime.KeepAlive(pDestinationBuffer)
+}
+
 func (i *_ID3D12GraphicsCommandList) ResourceBarrier(barriers []_D3D12_RESOURCE_BARRIER_Transition) {
 	if microsoftgdk.IsXbox() {
 		_ID3D12GraphicsCommandList_ResourceBarrier(i, barriers)
@@ -1996,6 +2070,27 @@ func (i *_ID3D12PipelineState) Release() uint32 {
 	return uint32(r)
 }
 
+type _ID3D12QueryHeap struct {
+	vtbl *_ID3D12QueryHeap_Vtbl
+}
+
+type _ID3D12QueryHeap_Vtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+
+	GetPrivateData          uintptr
+	SetPrivateData          uintptr
+	SetPrivateDataInterface uintptr
+	SetName                 uintptr
+	GetDevice               uintptr
+}
+
+func (i *_ID3D12QueryHeap) Release() uint32 {
+	r, _, _ := syscall.Syscall(i.vtbl.Release, 1, uintptr(unsafe.Pointer(i)), 0, 0)
+	return uint32(r)
+}
+
 type _ID3D12Resource struct {
 	vtbl *_ID3D12Resource_Vtbl
 }
@@ -92,7 +92,8 @@ const (
 
 	_DXGI_CREATE_FACTORY_DEBUG = 0x01
 
-	_DXGI_ERROR_NOT_FOUND = handleError(0x887A0002)
+	_DXGI_ERROR_NOT_FOUND         = handleError(0x887A0002)
+	_DXGI_ERROR_WAS_STILL_DRAWING = handleError(0x887A000A)
 
 	_DXGI_MWA_NO_ALT_ENTER      = 0x2
 	_DXGI_MWA_NO_WINDOW_CHANGES = 0x1
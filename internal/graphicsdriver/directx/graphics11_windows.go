@@ -15,7 +15,9 @@
 package directx
 
 import (
+	"errors"
 	"fmt"
+	"image"
 	"math"
 	"unsafe"
 
@@ -115,6 +117,20 @@ func blendFactorToBlend11(f graphicsdriver.BlendFactor, alpha bool) _D3D11_BLEND
 		return _D3D11_BLEND_INV_DEST_ALPHA
 	case graphicsdriver.BlendFactorSourceAlphaSaturated:
 		return _D3D11_BLEND_SRC_ALPHA_SAT
+	case graphicsdriver.BlendFactorSourceColor1:
+		if alpha {
+			return _D3D11_BLEND_SRC1_ALPHA
+		}
+		return _D3D11_BLEND_SRC1_COLOR
+	case graphicsdriver.BlendFactorOneMinusSourceColor1:
+		if alpha {
+			return _D3D11_BLEND_INV_SRC1_ALPHA
+		}
+		return _D3D11_BLEND_INV_SRC1_COLOR
+	case graphicsdriver.BlendFactorSourceAlpha1:
+		return _D3D11_BLEND_SRC1_ALPHA
+	case graphicsdriver.BlendFactorOneMinusSourceAlpha1:
+		return _D3D11_BLEND_INV_SRC1_ALPHA
 	default:
 		panic(fmt.Sprintf("directx: invalid blend factor: %d", f))
 	}
@@ -163,10 +179,14 @@ type graphics11 struct {
 	indexBuffer            *_ID3D11Buffer
 	indexBufferSizeInBytes uint32
 
-	rasterizerState    *_ID3D11RasterizerState
-	samplerState       *_ID3D11SamplerState
-	blendStates        map[blendStateKey]*_ID3D11BlendState
-	depthStencilStates map[stencilMode]*_ID3D11DepthStencilState
+	rasterizerState         *_ID3D11RasterizerState
+	samplerState            *_ID3D11SamplerState
+	anisotropicSamplerState *_ID3D11SamplerState
+	blendStates             map[blendStateKey]*_ID3D11BlendState
+	depthStencilStates      map[depthStencilStateKey]*_ID3D11DepthStencilState
+
+	// occlusionQuery is the occlusion query being recorded.
+	occlusionQuery *_ID3D11Query
 
 	vsyncEnabled bool
 	window       windows.HWND
@@ -280,7 +300,24 @@ func newGraphics11(useWARP bool, useDebugLayer bool) (gr11 *graphics11, ferr err
 		}
 		g.samplerState = s
 	}
-	g.deviceContext.PSSetSamplers(0, []*_ID3D11SamplerState{g.samplerState})
+	// The anisotropic sampler is used for shaderir.TexelAtAnisotropic.
+	if g.anisotropicSamplerState == nil {
+		s, err := g.device.CreateSamplerState(&_D3D11_SAMPLER_DESC{
+			Filter:         _D3D11_FILTER_ANISOTROPIC,
+			AddressU:       _D3D11_TEXTURE_ADDRESS_CLAMP,
+			AddressV:       _D3D11_TEXTURE_ADDRESS_CLAMP,
+			AddressW:       _D3D11_TEXTURE_ADDRESS_CLAMP,
+			MaxAnisotropy:  shaderir.MaxAnisotropy,
+			ComparisonFunc: _D3D11_COMPARISON_NEVER,
+			MinLOD:         -math.MaxFloat32,
+			MaxLOD:         math.MaxFloat32,
+		})
+		if err != nil {
+			return nil, err
+		}
+		g.anisotropicSamplerState = s
+	}
+	g.deviceContext.PSSetSamplers(0, []*_ID3D11SamplerState{g.samplerState, g.anisotropicSamplerState})
 
 	return g, nil
 }
@@ -400,7 +437,11 @@ func (g *graphics11) SetVertices(vertices []float32, indices []uint32) error {
 	return nil
 }
 
-func (g *graphics11) NewImage(width, height int) (graphicsdriver.Image, error) {
+func (g *graphics11) NewImage(width, height int, format graphicsdriver.PixelFormat) (graphicsdriver.Image, error) {
+	if format != graphicsdriver.PixelFormatRGBA8 {
+		return nil, fmt.Errorf("directx: pixel format %s is not supported yet", format)
+	}
+
 	t, err := g.device.CreateTexture2D(&_D3D11_TEXTURE2D_DESC{
 		Width:     uint32(graphics.InternalImageSize(width)),
 		Height:    uint32(graphics.InternalImageSize(height)),
@@ -490,6 +531,44 @@ func (g *graphics11) NeedsClearingScreen() bool {
 	return true
 }
 
+// ScreenColorSpace implements graphicsdriver.ScreenColorSpaceReporter.
+func (g *graphics11) ScreenColorSpace() graphicsdriver.ColorSpace {
+	// The swap chain always uses the default color space DXGI_COLOR_SPACE_RGB_FULL_G22_NONE_P709, which is sRGB.
+	return graphicsdriver.ColorSpaceSRGB
+}
+
+// IsFeatureAvailable implements graphicsdriver.FeatureReporter.
+func (g *graphics11) IsFeatureAvailable(feature graphicsdriver.Feature) bool {
+	switch feature {
+	case graphicsdriver.FeatureDepth, graphicsdriver.FeatureStencil, graphicsdriver.FeatureDualSourceBlending:
+		return true
+	case graphicsdriver.FeatureCompute:
+		// Compute shaders with cs_5_0 require the feature level 11.0.
+		return g.featureLevel == _D3D_FEATURE_LEVEL_11_0
+	default:
+		return false
+	}
+}
+
+// CopyImage implements graphicsdriver.ImageCopier.
+func (g *graphics11) CopyImage(dstID, srcID graphicsdriver.ImageID, dstPoint image.Point, srcRegion image.Rectangle) error {
+	dst := g.images[dstID]
+	src := g.images[srcID]
+	if dst.screen || src.screen {
+		return errors.New("directx: the images for CopyImage must not be the screen")
+	}
+
+	g.deviceContext.CopySubresourceRegion(unsafe.Pointer(dst.texture), 0, uint32(dstPoint.X), uint32(dstPoint.Y), 0, unsafe.Pointer(src.texture), 0, &_D3D11_BOX{
+		left:   uint32(srcRegion.Min.X),
+		top:    uint32(srcRegion.Min.Y),
+		front:  0,
+		right:  uint32(srcRegion.Max.X),
+		bottom: uint32(srcRegion.Max.Y),
+		back:   1,
+	})
+	return nil
+}
+
 func (g *graphics11) MaxImageSize() int {
 	switch g.featureLevel {
 	case _D3D_FEATURE_LEVEL_10_0:
@@ -503,6 +582,126 @@ func (g *graphics11) MaxImageSize() int {
 	}
 }
 
+// IsGPUTimerAvailable implements graphicsdriver.GPUTimer.
+func (g *graphics11) IsGPUTimerAvailable() bool {
+	return true
+}
+
+// RecordGPUTimestamp implements graphicsdriver.GPUTimer.
+func (g *graphics11) RecordGPUTimestamp() (graphicsdriver.GPUTimestamp, error) {
+	// A timestamp query must be enclosed by a disjoint query, which gives the frequency of the counter.
+	disjoint, err := g.device.CreateQuery(&_D3D11_QUERY_DESC{
+		Query: _D3D11_QUERY_TIMESTAMP_DISJOINT,
+	})
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := g.device.CreateQuery(&_D3D11_QUERY_DESC{
+		Query: _D3D11_QUERY_TIMESTAMP,
+	})
+	if err != nil {
+		disjoint.Release()
+		return nil, err
+	}
+
+	g.deviceContext.Begin(disjoint)
+	g.deviceContext.End(timestamp)
+	g.deviceContext.End(disjoint)
+
+	return &gpuTimestamp11{
+		deviceContext: g.deviceContext,
+		disjoint:      disjoint,
+		timestamp:     timestamp,
+	}, nil
+}
+
+// gpuTimestamp11 represents a timestamp being recorded with a timestamp query.
+type gpuTimestamp11 struct {
+	deviceContext *_ID3D11DeviceContext
+	disjoint      *_ID3D11Query
+	timestamp     *_ID3D11Query
+}
+
+// TryFinish implements graphicsdriver.GPUTimestamp.
+func (t *gpuTimestamp11) TryFinish() (uint64, bool, error) {
+	var ticks uint64
+	done, err := t.deviceContext.GetData(t.timestamp, unsafe.Pointer(&ticks), uint32(unsafe.Sizeof(ticks)), 0)
+	if err != nil {
+		return 0, false, err
+	}
+	if !done {
+		return 0, false, nil
+	}
+
+	var data _D3D11_QUERY_DATA_TIMESTAMP_DISJOINT
+	done, err = t.deviceContext.GetData(t.disjoint, unsafe.Pointer(&data), uint32(unsafe.Sizeof(data)), 0)
+	if err != nil {
+		return 0, false, err
+	}
+	if !done {
+		return 0, false, nil
+	}
+
+	t.timestamp.Release()
+	t.disjoint.Release()
+
+	// When the counter is disjoint, e.g., the GPU clock changed, the result might be inaccurate.
+	// There is no better way than using the result anyway.
+	if data.Frequency == 0 {
+		return 0, true, nil
+	}
+	return ticksToNanoseconds(ticks, data.Frequency), true, nil
+}
+
+// BeginOcclusionQuery implements graphicsdriver.OcclusionQuerier.
+func (g *graphics11) BeginOcclusionQuery() (graphicsdriver.OcclusionQuery, error) {
+	if g.occlusionQuery != nil {
+		return nil, errors.New("directx: occlusion queries cannot be nested")
+	}
+	q, err := g.device.CreateQuery(&_D3D11_QUERY_DESC{
+		Query: _D3D11_QUERY_OCCLUSION,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.deviceContext.Begin(q)
+	g.occlusionQuery = q
+	return &occlusionQuery11{
+		deviceContext: g.deviceContext,
+		query:         q,
+	}, nil
+}
+
+// EndOcclusionQuery implements graphicsdriver.OcclusionQuerier.
+func (g *graphics11) EndOcclusionQuery() error {
+	if g.occlusionQuery == nil {
+		return errors.New("directx: EndOcclusionQuery must be called after BeginOcclusionQuery")
+	}
+	g.deviceContext.End(g.occlusionQuery)
+	g.occlusionQuery = nil
+	return nil
+}
+
+// occlusionQuery11 represents an occlusion query with a query object.
+type occlusionQuery11 struct {
+	deviceContext *_ID3D11DeviceContext
+	query         *_ID3D11Query
+}
+
+// TryFinish implements graphicsdriver.OcclusionQuery.
+func (q *occlusionQuery11) TryFinish() (uint64, bool, error) {
+	var samples uint64
+	done, err := q.deviceContext.GetData(q.query, unsafe.Pointer(&samples), uint32(unsafe.Sizeof(samples)), 0)
+	if err != nil {
+		return 0, false, err
+	}
+	if !done {
+		return 0, false, nil
+	}
+	q.query.Release()
+	return samples, true, nil
+}
+
 func (g *graphics11) NewShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
 	vsh, psh, err := compileShader(program)
 	if err != nil {
@@ -510,17 +709,110 @@ func (g *graphics11) NewShader(program *shaderir.Program) (graphicsdriver.Shader
 	}
 
 	s := &shader11{
-		graphics:         g,
-		id:               g.genNextShaderID(),
-		uniformTypes:     program.Uniforms,
-		uniformOffsets:   hlsl.CalcUniformMemoryOffsets(program),
-		vertexShaderBlob: vsh,
-		pixelShaderBlob:  psh,
+		graphics:              g,
+		id:                    g.genNextShaderID(),
+		uniformTypes:          program.Uniforms,
+		uniformOffsets:        hlsl.CalcUniformMemoryOffsets(program),
+		vertexShaderBlob:      vsh,
+		pixelShaderBlob:       psh,
+		multipleRenderTargets: program.FragmentFunc.MultipleRenderTargets,
+		fragmentOutputCount:   program.FragmentFunc.OutputCount,
 	}
 	g.addShader(s)
 	return s, nil
 }
 
+// NewComputeShader implements graphicsdriver.ComputeDispatcher.
+func (g *graphics11) NewComputeShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
+	if g.featureLevel != _D3D_FEATURE_LEVEL_11_0 {
+		return nil, fmt.Errorf("directx: compute shaders require the feature level 11.0")
+	}
+
+	read, write := program.StorageImageAccesses()
+	readOnlyImages := make([]bool, program.StorageImageCount)
+	for i := range readOnlyImages {
+		// Loading from an RGBA8 UAV is not available with cs_5_0.
+		if read[i] && write[i] {
+			return nil, fmt.Errorf("directx: the storage image %d cannot be both read and written in a compute kernel", i)
+		}
+		readOnlyImages[i] = read[i] && !write[i]
+	}
+
+	csh, err := compileComputeShader(program)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &shader11{
+		graphics:          g,
+		id:                g.genNextShaderID(),
+		uniformTypes:      program.Uniforms,
+		uniformOffsets:    hlsl.CalcUniformMemoryOffsets(program),
+		computeShaderBlob: csh,
+		readOnlyImages:    readOnlyImages,
+	}
+	g.addShader(s)
+	return s, nil
+}
+
+// DispatchCompute implements graphicsdriver.ComputeDispatcher.
+func (g *graphics11) DispatchCompute(shaderID graphicsdriver.ShaderID, imageIDs []graphicsdriver.ImageID, uniforms []uint32, x, y, z int) error {
+	shader := g.shaders[shaderID]
+	if shader.computeShaderBlob == nil {
+		return fmt.Errorf("directx: the shader for DispatchCompute must be a compute kernel")
+	}
+	if len(imageIDs) != len(shader.readOnlyImages) {
+		return fmt.Errorf("directx: the number of the storage images must be %d but %d", len(shader.readOnlyImages), len(imageIDs))
+	}
+
+	// A resource cannot be bound as an output and an input at the same time. Unbind the render targets and the render sources first.
+	g.deviceContext.OMSetRenderTargets([]*_ID3D11RenderTargetView{nil}, nil)
+	srvs := [graphics.ShaderSrcImageCount]*_ID3D11ShaderResourceView{}
+	g.deviceContext.PSSetShaderResources(0, srvs[:])
+
+	csSRVs := make([]*_ID3D11ShaderResourceView, len(imageIDs))
+	uavs := make([]*_ID3D11UnorderedAccessView, len(imageIDs))
+	for i, id := range imageIDs {
+		img := g.images[id]
+		if img.screen {
+			return fmt.Errorf("directx: the screen cannot be a storage image")
+		}
+		if shader.readOnlyImages[i] {
+			srv, err := img.getShaderResourceView()
+			if err != nil {
+				return err
+			}
+			csSRVs[i] = srv
+			continue
+		}
+		uav, err := img.getUnorderedAccessView()
+		if err != nil {
+			return err
+		}
+		uavs[i] = uav
+	}
+
+	if err := shader.useCompute(uniforms); err != nil {
+		return err
+	}
+	g.deviceContext.CSSetShaderResources(0, csSRVs)
+	g.deviceContext.CSSetUnorderedAccessViews(0, uavs)
+
+	g.deviceContext.Dispatch(uint32(x), uint32(y), uint32(z))
+
+	// Unbind the storage images so that they can be used for rendering.
+	for i := range csSRVs {
+		csSRVs[i] = nil
+	}
+	for i := range uavs {
+		uavs[i] = nil
+	}
+	g.deviceContext.CSSetShaderResources(0, csSRVs)
+	g.deviceContext.CSSetUnorderedAccessViews(0, uavs)
+
+	return nil
+}
+
 func (g *graphics11) addShader(s *shader11) {
 	if g.shaders == nil {
 		g.shaders = map[graphicsdriver.ShaderID]*shader11{}
@@ -536,13 +828,54 @@ func (g *graphics11) removeShader(s *shader11) {
 	delete(g.shaders, s.id)
 }
 
-func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule) error {
+func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
+	var dstIDs [graphics.ShaderDstImageCount]graphicsdriver.ImageID
+	dstIDs[0] = dstID
+	return g.drawTriangles(dstIDs, srcIDs, shaderID, dstRegions, indexOffset, blend, uniforms, fillRule, depthMode, stencil)
+}
+
+// IsMultipleRenderTargetsAvailable implements graphicsdriver.MultipleRenderTargetsDrawer.
+func (g *graphics11) IsMultipleRenderTargetsAvailable() bool {
+	// All the feature levels support 8 render targets.
+	return true
+}
+
+// DrawTrianglesToMultipleTargets implements graphicsdriver.MultipleRenderTargetsDrawer.
+func (g *graphics11) DrawTrianglesToMultipleTargets(dstIDs [graphics.ShaderDstImageCount]graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
+	return g.drawTriangles(dstIDs, srcIDs, shaderID, dstRegions, indexOffset, blend, uniforms, fillRule, depthMode, stencil)
+}
+
+// drawTriangles draws triangles onto the destination images.
+// dstIDs[0] is the main destination, and the other valid IDs are the additional destinations for multiple render targets.
+func (g *graphics11) drawTriangles(dstIDs [graphics.ShaderDstImageCount]graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
 	// Remove bound textures first. This is needed to avoid warnings on the debugger.
 	g.deviceContext.OMSetRenderTargets([]*_ID3D11RenderTargetView{nil}, nil)
 	srvs := [graphics.ShaderSrcImageCount]*_ID3D11ShaderResourceView{}
 	g.deviceContext.PSSetShaderResources(0, srvs[:])
 
-	dst := g.images[dstID]
+	dst := g.images[dstIDs[0]]
+	shader := g.shaders[shaderID]
+
+	var additionalDsts []*image11
+	for _, id := range dstIDs[1:] {
+		if id == graphicsdriver.InvalidImageID {
+			continue
+		}
+		img := g.images[id]
+		if dst.screen || img.screen {
+			return fmt.Errorf("directx: the screen cannot be one of multiple render targets")
+		}
+		if img.width != dst.width || img.height != dst.height {
+			return fmt.Errorf("directx: multiple render targets must have the same size")
+		}
+		additionalDsts = append(additionalDsts, img)
+	}
+	if len(additionalDsts) > 0 {
+		if !shader.multipleRenderTargets || shader.fragmentOutputCount != len(additionalDsts)+1 {
+			return fmt.Errorf("directx: the shader must return %d colors for multiple render targets", len(additionalDsts)+1)
+		}
+	}
+
 	var srcs [graphics.ShaderSrcImageCount]*image11
 	for i, id := range srcIDs {
 		img := g.images[id]
@@ -564,12 +897,11 @@ func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphic
 		},
 	})
 
-	if err := dst.setAsRenderTarget(fillRule != graphicsdriver.FillRuleFillAll); err != nil {
+	if err := dst.setAsRenderTarget(additionalDsts, fillRule != graphicsdriver.FillRuleFillAll, depthMode != graphicsdriver.DepthModeNone || !stencil.IsZero()); err != nil {
 		return err
 	}
 
 	// Set the shader parameters.
-	shader := g.shaders[shaderID]
 	if err := shader.use(uniforms, srcs); err != nil {
 		return err
 	}
@@ -581,11 +913,11 @@ func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphic
 		}
 		g.deviceContext.OMSetBlendState(bs, nil, 0xffffffff)
 
-		dss, err := g.depthStencilState(noStencil)
+		dss, err := g.depthStencilState(noStencil, depthMode, stencil)
 		if err != nil {
 			return err
 		}
-		g.deviceContext.OMSetDepthStencilState(dss, 0)
+		g.deviceContext.OMSetDepthStencilState(dss, uint32(stencil.Ref))
 	}
 
 	for _, dstRegion := range dstRegions {
@@ -607,7 +939,7 @@ func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphic
 				return err
 			}
 			g.deviceContext.OMSetBlendState(bs, nil, 0xffffffff)
-			dss, err := g.depthStencilState(incrementStencil)
+			dss, err := g.depthStencilState(incrementStencil, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 			if err != nil {
 				return err
 			}
@@ -619,7 +951,7 @@ func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphic
 				return err
 			}
 			g.deviceContext.OMSetBlendState(bs, nil, 0xffffffff)
-			dss, err := g.depthStencilState(invertStencil)
+			dss, err := g.depthStencilState(invertStencil, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 			if err != nil {
 				return err
 			}
@@ -633,7 +965,7 @@ func (g *graphics11) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphic
 				return err
 			}
 			g.deviceContext.OMSetBlendState(bs, nil, 0xffffffff)
-			dss, err := g.depthStencilState(drawWithStencil)
+			dss, err := g.depthStencilState(drawWithStencil, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 			if err != nil {
 				return err
 			}
@@ -698,8 +1030,14 @@ func (g *graphics11) blendState(blend graphicsdriver.Blend, stencilMode stencilM
 	return bs, nil
 }
 
-func (g *graphics11) depthStencilState(mode stencilMode) (*_ID3D11DepthStencilState, error) {
-	if s, ok := g.depthStencilStates[mode]; ok {
+func (g *graphics11) depthStencilState(mode stencilMode, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) (*_ID3D11DepthStencilState, error) {
+	stencil.Ref = 0
+	key := depthStencilStateKey{
+		stencilMode: mode,
+		depthMode:   depthMode,
+		stencil:     stencil,
+	}
+	if s, ok := g.depthStencilStates[key]; ok {
 		return s, nil
 	}
 
@@ -738,14 +1076,78 @@ func (g *graphics11) depthStencilState(mode stencilMode) (*_ID3D11DepthStencilSt
 		desc.BackFace.StencilFunc = _D3D11_COMPARISON_NOT_EQUAL
 	}
 
+	switch depthMode {
+	case graphicsdriver.DepthModeTest:
+		desc.DepthEnable = 1
+		desc.DepthWriteMask = _D3D11_DEPTH_WRITE_MASK_ZERO
+	case graphicsdriver.DepthModeWrite:
+		desc.DepthEnable = 1
+		desc.DepthFunc = _D3D11_COMPARISON_ALWAYS
+	case graphicsdriver.DepthModeTestAndWrite:
+		desc.DepthEnable = 1
+	}
+
+	if !stencil.IsZero() {
+		desc.StencilEnable = 1
+		desc.FrontFace.StencilPassOp = stencilOpToStencilOp11(stencil.Op)
+		desc.FrontFace.StencilFunc = stencilFuncToComparisonFunc11(stencil.Func)
+		desc.BackFace = desc.FrontFace
+	}
+
 	s, err := g.device.CreateDepthStencilState(desc)
 	if err != nil {
 		return nil, err
 	}
 
 	if g.depthStencilStates == nil {
-		g.depthStencilStates = map[stencilMode]*_ID3D11DepthStencilState{}
+		g.depthStencilStates = map[depthStencilStateKey]*_ID3D11DepthStencilState{}
 	}
-	g.depthStencilStates[mode] = s
+	g.depthStencilStates[key] = s
 	return s, nil
 }
+
+func stencilFuncToComparisonFunc11(f graphicsdriver.StencilFunc) _D3D11_COMPARISON_FUNC {
+	switch f {
+	case graphicsdriver.StencilFuncAlways:
+		return _D3D11_COMPARISON_ALWAYS
+	case graphicsdriver.StencilFuncNever:
+		return _D3D11_COMPARISON_NEVER
+	case graphicsdriver.StencilFuncEqual:
+		return _D3D11_COMPARISON_EQUAL
+	case graphicsdriver.StencilFuncNotEqual:
+		return _D3D11_COMPARISON_NOT_EQUAL
+	case graphicsdriver.StencilFuncLess:
+		return _D3D11_COMPARISON_LESS
+	case graphicsdriver.StencilFuncLessEqual:
+		return _D3D11_COMPARISON_LESS_EQUAL
+	case graphicsdriver.StencilFuncGreater:
+		return _D3D11_COMPARISON_GREATER
+	case graphicsdriver.StencilFuncGreaterEqual:
+		return _D3D11_COMPARISON_GREATER_EQUAL
+	default:
+		panic(fmt.Sprintf("directx: invalid stencil func: %d", f))
+	}
+}
+
+func stencilOpToStencilOp11(o graphicsdriver.StencilOp) _D3D11_STENCIL_OP {
+	switch o {
+	case graphicsdriver.StencilOpKeep:
+		return _D3D11_STENCIL_OP_KEEP
+	case graphicsdriver.StencilOpZero:
+		return _D3D11_STENCIL_OP_ZERO
+	case graphicsdriver.StencilOpReplace:
+		return _D3D11_STENCIL_OP_REPLACE
+	case graphicsdriver.StencilOpIncrement:
+		return _D3D11_STENCIL_OP_INCR_SAT
+	case graphicsdriver.StencilOpIncrementWrap:
+		return _D3D11_STENCIL_OP_INCR
+	case graphicsdriver.StencilOpDecrement:
+		return _D3D11_STENCIL_OP_DECR_SAT
+	case graphicsdriver.StencilOpDecrementWrap:
+		return _D3D11_STENCIL_OP_DECR
+	case graphicsdriver.StencilOpInvert:
+		return _D3D11_STENCIL_OP_INVERT
+	default:
+		panic(fmt.Sprintf("directx: invalid stencil operation: %d", o))
+	}
+}
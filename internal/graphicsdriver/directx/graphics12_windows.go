@@ -103,9 +103,25 @@ type graphics12 struct {
 	suspendedCh  chan struct{}
 	resumeCh     chan struct{}
 
+	// timestampQueryHeaps are the query heaps for GPU timestamps.
+	timestampQueryHeaps []*timestampQueryHeap
+
+	// timestampFrequency is the frequency of the GPU timestamp counter.
+	timestampFrequency uint64
+
 	pipelineStates
 }
 
+// timestampQueryCount is the number of the timestamp queries in one query heap.
+const timestampQueryCount = 256
+
+// timestampQueryHeap is a query heap for timestamps and a buffer to read the results back.
+type timestampQueryHeap struct {
+	heap   *_ID3D12QueryHeap
+	buffer *_ID3D12Resource
+	used   [timestampQueryCount]bool
+}
+
 func newGraphics12(useWARP bool, useDebugLayer bool, featureLevel _D3D_FEATURE_LEVEL) (*graphics12, error) {
 	g := &graphics12{}
 
@@ -214,7 +230,7 @@ func (g *graphics12) initializeDesktop(useWARP bool, useDebugLayer bool, feature
 
 	// GetCopyableFootprints might return an invalid value with Wine (#2114).
 	// To check this early, call NewImage here.
-	i, err := g.NewImage(1, 1)
+	i, err := g.NewImage(1, 1, graphicsdriver.PixelFormatRGBA8)
 	if err != nil {
 		return err
 	}
@@ -952,7 +968,11 @@ func (g *graphics12) SetVertices(vertices []float32, indices []uint32) (ferr err
 	return nil
 }
 
-func (g *graphics12) NewImage(width, height int) (graphicsdriver.Image, error) {
+func (g *graphics12) NewImage(width, height int, format graphicsdriver.PixelFormat) (graphicsdriver.Image, error) {
+	if format != graphicsdriver.PixelFormatRGBA8 {
+		return nil, fmt.Errorf("directx: pixel format %s is not supported yet", format)
+	}
+
 	desc := _D3D12_RESOURCE_DESC{
 		Dimension:        _D3D12_RESOURCE_DIMENSION_TEXTURE2D,
 		Alignment:        0,
@@ -1059,10 +1079,125 @@ func (g *graphics12) NeedsClearingScreen() bool {
 	return true
 }
 
+// ScreenColorSpace implements graphicsdriver.ScreenColorSpaceReporter.
+func (g *graphics12) ScreenColorSpace() graphicsdriver.ColorSpace {
+	// The swap chain always uses the default color space DXGI_COLOR_SPACE_RGB_FULL_G22_NONE_P709, which is sRGB.
+	return graphicsdriver.ColorSpaceSRGB
+}
+
+// IsFeatureAvailable implements graphicsdriver.FeatureReporter.
+func (g *graphics12) IsFeatureAvailable(feature graphicsdriver.Feature) bool {
+	switch feature {
+	case graphicsdriver.FeatureDepth, graphicsdriver.FeatureStencil, graphicsdriver.FeatureDualSourceBlending:
+		return true
+	default:
+		return false
+	}
+}
+
 func (g *graphics12) MaxImageSize() int {
 	return _D3D12_REQ_TEXTURE2D_U_OR_V_DIMENSION
 }
 
+// IsGPUTimerAvailable implements graphicsdriver.GPUTimer.
+func (g *graphics12) IsGPUTimerAvailable() bool {
+	// Timestamp queries are not implemented for Xbox yet.
+	return !microsoftgdk.IsXbox()
+}
+
+// RecordGPUTimestamp implements graphicsdriver.GPUTimer.
+func (g *graphics12) RecordGPUTimestamp() (graphicsdriver.GPUTimestamp, error) {
+	if g.timestampFrequency == 0 {
+		f, err := g.commandQueue.GetTimestampFrequency()
+		if err != nil {
+			return nil, err
+		}
+		g.timestampFrequency = f
+	}
+
+	// As copyCommandList and drawCommandList are exclusive, flush the copy commands before recording a timestamp.
+	if err := g.flushCommandList(g.copyCommandList); err != nil {
+		return nil, err
+	}
+
+	heap, index, err := g.allocateTimestampQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	g.drawCommandList.EndQuery(heap.heap, _D3D12_QUERY_TYPE_TIMESTAMP, uint32(index))
+	g.drawCommandList.ResolveQueryData(heap.heap, _D3D12_QUERY_TYPE_TIMESTAMP, uint32(index), 1, heap.buffer, uint64(8*index))
+	g.needFlushDrawCommandList = true
+
+	return &gpuTimestamp12{
+		graphics: g,
+		heap:     heap,
+		index:    index,
+		// The current fence value is signaled after the current draw command list is executed.
+		fenceValue: g.fenceValues[g.frameIndex],
+	}, nil
+}
+
+func (g *graphics12) allocateTimestampQuery() (*timestampQueryHeap, int, error) {
+	for _, h := range g.timestampQueryHeaps {
+		for i, used := range h.used {
+			if used {
+				continue
+			}
+			h.used[i] = true
+			return h, i, nil
+		}
+	}
+
+	heap, err := g.device.CreateQueryHeap(&_D3D12_QUERY_HEAP_DESC{
+		Type:  _D3D12_QUERY_HEAP_TYPE_TIMESTAMP,
+		Count: timestampQueryCount,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	buffer, err := createBuffer(g.device, 8*timestampQueryCount, _D3D12_HEAP_TYPE_READBACK)
+	if err != nil {
+		heap.Release()
+		return nil, 0, err
+	}
+	h := &timestampQueryHeap{
+		heap:   heap,
+		buffer: buffer,
+	}
+	g.timestampQueryHeaps = append(g.timestampQueryHeaps, h)
+	h.used[0] = true
+	return h, 0, nil
+}
+
+// gpuTimestamp12 represents a timestamp being recorded with a timestamp query.
+type gpuTimestamp12 struct {
+	graphics   *graphics12
+	heap       *timestampQueryHeap
+	index      int
+	fenceValue uint64
+}
+
+// TryFinish implements graphicsdriver.GPUTimestamp.
+func (t *gpuTimestamp12) TryFinish() (uint64, bool, error) {
+	if t.graphics.fence.GetCompletedValue() < t.fenceValue {
+		return 0, false, nil
+	}
+
+	m, err := t.heap.buffer.Map(0, &_D3D12_RANGE{
+		Begin: uintptr(8 * t.index),
+		End:   uintptr(8 * (t.index + 1)),
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	ticks := unsafe.Slice((*uint64)(unsafe.Pointer(m)), timestampQueryCount)[t.index]
+	t.heap.buffer.Unmap(0, &_D3D12_RANGE{0, 0})
+	t.heap.used[t.index] = false
+
+	return ticksToNanoseconds(ticks, t.graphics.timestampFrequency), true, nil
+}
+
 func (g *graphics12) NewShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
 	vsh, psh, err := compileShader(program)
 	if err != nil {
@@ -1081,7 +1216,7 @@ func (g *graphics12) NewShader(program *shaderir.Program) (graphicsdriver.Shader
 	return s, nil
 }
 
-func (g *graphics12) DrawTriangles(dstID graphicsdriver.ImageID, srcs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule) error {
+func (g *graphics12) DrawTriangles(dstID graphicsdriver.ImageID, srcs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
 	if shaderID == graphicsdriver.InvalidShaderID {
 		return fmt.Errorf("directx: shader ID is invalid")
 	}
@@ -1124,7 +1259,7 @@ func (g *graphics12) DrawTriangles(dstID graphicsdriver.ImageID, srcs [graphics.
 		g.drawCommandList.ResourceBarrier(resourceBarriers)
 	}
 
-	if err := dst.setAsRenderTarget(g.drawCommandList, g.device, fillRule != graphicsdriver.FillRuleFillAll); err != nil {
+	if err := dst.setAsRenderTarget(g.drawCommandList, g.device, fillRule != graphicsdriver.FillRuleFillAll, depthMode != graphicsdriver.DepthModeNone || !stencil.IsZero()); err != nil {
 		return err
 	}
 
@@ -1157,7 +1292,7 @@ func (g *graphics12) DrawTriangles(dstID graphicsdriver.ImageID, srcs [graphics.
 		Format:         _DXGI_FORMAT_R32_UINT,
 	})
 
-	if err := g.pipelineStates.drawTriangles(g.device, g.drawCommandList, g.frameIndex, dst.screen, srcImages, shader, dstRegions, adjustedUniforms, blend, indexOffset, fillRule); err != nil {
+	if err := g.pipelineStates.drawTriangles(g.device, g.drawCommandList, g.frameIndex, dst.screen, srcImages, shader, dstRegions, adjustedUniforms, blend, indexOffset, fillRule, depthMode, stencil); err != nil {
 		return err
 	}
 
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/bits"
 	"os"
 	"runtime"
 	"strconv"
@@ -41,6 +42,14 @@ const (
 	drawWithStencil
 )
 
+// depthStencilStateKey is a key for a depth-stencil state.
+// Stencil's Ref is always 0 since the reference value is not a part of the state.
+type depthStencilStateKey struct {
+	stencilMode stencilMode
+	depthMode   graphicsdriver.DepthMode
+	stencil     graphicsdriver.Stencil
+}
+
 const frameCount = 2
 
 func pow2(x uint32) uint32 {
@@ -55,6 +64,16 @@ func pow2(x uint32) uint32 {
 	return p2
 }
 
+// ticksToNanoseconds converts the ticks of a GPU timestamp counter with the given frequency to nanoseconds.
+func ticksToNanoseconds(ticks, frequency uint64) uint64 {
+	hi, lo := bits.Mul64(ticks, uint64(time.Second))
+	if hi >= frequency {
+		return math.MaxUint64
+	}
+	ns, _ := bits.Div64(hi, lo, frequency)
+	return ns
+}
+
 func parseFeatureLevel(str string) (_D3D_FEATURE_LEVEL, bool) {
 	switch str {
 	case "11_0":
@@ -15,6 +15,7 @@
 package directx
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"unsafe"
@@ -35,6 +36,9 @@ type image11 struct {
 	renderTargetView   *_ID3D11RenderTargetView
 	stencilView        *_ID3D11DepthStencilView
 	shaderResourceView *_ID3D11ShaderResourceView
+
+	// unorderedAccessView is a view for compute kernels. The texture is recreated when this is created first.
+	unorderedAccessView *_ID3D11UnorderedAccessView
 }
 
 func (i *image11) internalSize() (int, int) {
@@ -74,9 +78,48 @@ func (i *image11) disposeBuffers() {
 		i.shaderResourceView.Release()
 		i.shaderResourceView = nil
 	}
+	if i.unorderedAccessView != nil {
+		i.unorderedAccessView.Release()
+		i.unorderedAccessView = nil
+	}
 }
 
 func (i *image11) ReadPixels(args []graphicsdriver.PixelsArgs) error {
+	staging, region, err := i.copyToStagingTexture(args)
+	if err != nil {
+		return err
+	}
+	defer staging.Release()
+
+	var mapped _D3D11_MAPPED_SUBRESOURCE
+	if err := i.graphics.deviceContext.Map(unsafe.Pointer(staging), 0, _D3D11_MAP_READ, 0, &mapped); err != nil {
+		return err
+	}
+	copyMappedPixels(args, &mapped, region)
+	i.graphics.deviceContext.Unmap(unsafe.Pointer(staging), 0)
+
+	return nil
+}
+
+// ReadPixelsAsync implements graphicsdriver.AsyncPixelsReader.
+func (i *image11) ReadPixelsAsync(args []graphicsdriver.PixelsArgs) (graphicsdriver.PendingPixels, error) {
+	staging, region, err := i.copyToStagingTexture(args)
+	if err != nil {
+		return nil, err
+	}
+	// Flush the commands so that the copy is completed eventually.
+	i.graphics.deviceContext.Flush()
+	return &pendingPixels11{
+		deviceContext: i.graphics.deviceContext,
+		args:          args,
+		staging:       staging,
+		region:        region,
+	}, nil
+}
+
+// copyToStagingTexture enqueues a copy of the union of the regions in args to a new staging texture.
+// The caller must release the returned texture.
+func (i *image11) copyToStagingTexture(args []graphicsdriver.PixelsArgs) (*_ID3D11Texture2D, image.Rectangle, error) {
 	var unionRegion image.Rectangle
 	for _, a := range args {
 		unionRegion = unionRegion.Union(a.Region)
@@ -98,9 +141,8 @@ func (i *image11) ReadPixels(args []graphicsdriver.PixelsArgs) error {
 		MiscFlags:      0,
 	}, nil)
 	if err != nil {
-		return err
+		return nil, image.Rectangle{}, err
 	}
-	defer staging.Release()
 
 	i.graphics.deviceContext.CopySubresourceRegion(unsafe.Pointer(staging), 0, 0, 0, 0, unsafe.Pointer(i.texture), 0, &_D3D11_BOX{
 		left:   uint32(unionRegion.Min.X),
@@ -111,11 +153,11 @@ func (i *image11) ReadPixels(args []graphicsdriver.PixelsArgs) error {
 		back:   1,
 	})
 
-	var mapped _D3D11_MAPPED_SUBRESOURCE
-	if err := i.graphics.deviceContext.Map(unsafe.Pointer(staging), 0, _D3D11_MAP_READ, 0, &mapped); err != nil {
-		return err
-	}
+	return staging, unionRegion, nil
+}
 
+// copyMappedPixels copies the pixels of the mapped staging texture for unionRegion to args' Pixels.
+func copyMappedPixels(args []graphicsdriver.PixelsArgs, mapped *_D3D11_MAPPED_SUBRESOURCE, unionRegion image.Rectangle) {
 	stride := int(mapped.RowPitch)
 	srcPix := unsafe.Slice((*byte)(mapped.pData), stride*unionRegion.Dy())
 	for _, a := range args {
@@ -129,10 +171,30 @@ func (i *image11) ReadPixels(args []graphicsdriver.PixelsArgs) error {
 			copy(a.Pixels[j*4*w:(j+1)*4*w], srcPix[offset+j*stride:])
 		}
 	}
+}
 
-	i.graphics.deviceContext.Unmap(unsafe.Pointer(staging), 0)
+// pendingPixels11 represents pixels being copied to a staging texture.
+type pendingPixels11 struct {
+	deviceContext *_ID3D11DeviceContext
+	args          []graphicsdriver.PixelsArgs
+	staging       *_ID3D11Texture2D
+	region        image.Rectangle
+}
 
-	return nil
+// TryFinish implements graphicsdriver.PendingPixels.
+func (p *pendingPixels11) TryFinish() (bool, error) {
+	var mapped _D3D11_MAPPED_SUBRESOURCE
+	if err := p.deviceContext.Map(unsafe.Pointer(p.staging), 0, _D3D11_MAP_READ, uint32(_D3D11_MAP_FLAG_DO_NOT_WAIT), &mapped); err != nil {
+		if errors.Is(err, _DXGI_ERROR_WAS_STILL_DRAWING) {
+			return false, nil
+		}
+		p.staging.Release()
+		return false, err
+	}
+	copyMappedPixels(p.args, &mapped, p.region)
+	p.deviceContext.Unmap(unsafe.Pointer(p.staging), 0)
+	p.staging.Release()
+	return true, nil
 }
 
 func (i *image11) WritePixels(args []graphicsdriver.PixelsArgs) error {
@@ -149,22 +211,42 @@ func (i *image11) WritePixels(args []graphicsdriver.PixelsArgs) error {
 	return nil
 }
 
-func (i *image11) setAsRenderTarget(useStencil bool) error {
-	if i.renderTargetView == nil {
-		rtv, err := i.graphics.device.CreateRenderTargetView(unsafe.Pointer(i.texture), nil)
-		if err != nil {
+func (i *image11) ensureRenderTargetView() error {
+	if i.renderTargetView != nil {
+		return nil
+	}
+	rtv, err := i.graphics.device.CreateRenderTargetView(unsafe.Pointer(i.texture), nil)
+	if err != nil {
+		return err
+	}
+	i.renderTargetView = rtv
+	return nil
+}
+
+// setAsRenderTarget sets the image as the render target.
+// additionalDsts are bound as the following render targets for multiple render targets.
+//
+// If useStencil is true, the stencil buffer is cleared for a fill rule.
+// If useDepthStencil is true, the depth-stencil buffer is bound with its content kept.
+func (i *image11) setAsRenderTarget(additionalDsts []*image11, useStencil bool, useDepthStencil bool) error {
+	if err := i.ensureRenderTargetView(); err != nil {
+		return err
+	}
+	rtvs := []*_ID3D11RenderTargetView{i.renderTargetView}
+	for _, img := range additionalDsts {
+		if err := img.ensureRenderTargetView(); err != nil {
 			return err
 		}
-		i.renderTargetView = rtv
+		rtvs = append(rtvs, img.renderTargetView)
 	}
 
-	if !useStencil {
-		i.graphics.deviceContext.OMSetRenderTargets([]*_ID3D11RenderTargetView{i.renderTargetView}, nil)
+	if !useStencil && !useDepthStencil {
+		i.graphics.deviceContext.OMSetRenderTargets(rtvs, nil)
 		return nil
 	}
 
 	if i.screen {
-		return fmt.Errorf("directx: a stencil buffer is not available for a screen image")
+		return fmt.Errorf("directx: a depth-stencil buffer is not available for a screen image")
 	}
 
 	if i.stencil == nil {
@@ -196,10 +278,15 @@ func (i *image11) setAsRenderTarget(useStencil bool) error {
 			return err
 		}
 		i.stencilView = sv
+
+		// Initialize the depth buffer with the farthest value (1), and the stencil buffer with 0.
+		i.graphics.deviceContext.ClearDepthStencilView(i.stencilView, uint8(_D3D11_CLEAR_DEPTH|_D3D11_CLEAR_STENCIL), 1, 0)
 	}
 
-	i.graphics.deviceContext.OMSetRenderTargets([]*_ID3D11RenderTargetView{i.renderTargetView}, i.stencilView)
-	i.graphics.deviceContext.ClearDepthStencilView(i.stencilView, uint8(_D3D11_CLEAR_STENCIL), 0, 0)
+	i.graphics.deviceContext.OMSetRenderTargets(rtvs, i.stencilView)
+	if useStencil {
+		i.graphics.deviceContext.ClearDepthStencilView(i.stencilView, uint8(_D3D11_CLEAR_STENCIL), 0, 0)
+	}
 
 	return nil
 }
@@ -214,3 +301,53 @@ func (i *image11) getShaderResourceView() (*_ID3D11ShaderResourceView, error) {
 	}
 	return i.shaderResourceView, nil
 }
+
+// getUnorderedAccessView returns a view to read and write the image in compute kernels.
+//
+// The texture is recreated with the unordered access bind flag at the first call,
+// as this flag is not specified at NewImage not to disable optimizations like compression.
+func (i *image11) getUnorderedAccessView() (*_ID3D11UnorderedAccessView, error) {
+	if i.unorderedAccessView != nil {
+		return i.unorderedAccessView, nil
+	}
+
+	w, h := i.internalSize()
+	t, err := i.graphics.device.CreateTexture2D(&_D3D11_TEXTURE2D_DESC{
+		Width:     uint32(w),
+		Height:    uint32(h),
+		MipLevels: 1,
+		ArraySize: 1,
+		Format:    _DXGI_FORMAT_R8G8B8A8_UNORM,
+		SampleDesc: _DXGI_SAMPLE_DESC{
+			Count:   1,
+			Quality: 0,
+		},
+		Usage:          _D3D11_USAGE_DEFAULT,
+		BindFlags:      uint32(_D3D11_BIND_SHADER_RESOURCE | _D3D11_BIND_RENDER_TARGET | _D3D11_BIND_UNORDERED_ACCESS),
+		CPUAccessFlags: 0,
+		MiscFlags:      0,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	i.graphics.deviceContext.CopyResource(unsafe.Pointer(t), unsafe.Pointer(i.texture))
+
+	// The views refer to the old texture. Recreate them lazily.
+	if i.renderTargetView != nil {
+		i.renderTargetView.Release()
+		i.renderTargetView = nil
+	}
+	if i.shaderResourceView != nil {
+		i.shaderResourceView.Release()
+		i.shaderResourceView = nil
+	}
+	i.texture.Release()
+	i.texture = t
+
+	uav, err := i.graphics.device.CreateUnorderedAccessView(unsafe.Pointer(i.texture), nil)
+	if err != nil {
+		return nil, err
+	}
+	i.unorderedAccessView = uav
+	return uav, nil
+}
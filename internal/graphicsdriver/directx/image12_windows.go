@@ -288,14 +288,18 @@ func (i *image12) internalSize() (int, int) {
 	return graphics.InternalImageSize(i.width), graphics.InternalImageSize(i.height)
 }
 
-func (i *image12) setAsRenderTarget(drawCommandList *_ID3D12GraphicsCommandList, device *_ID3D12Device, useStencil bool) error {
+// setAsRenderTarget sets the image as the render target.
+//
+// If useStencil is true, the stencil buffer is cleared for a fill rule.
+// If useDepthStencil is true, the depth-stencil buffer is bound with its content kept.
+func (i *image12) setAsRenderTarget(drawCommandList *_ID3D12GraphicsCommandList, device *_ID3D12Device, useStencil bool, useDepthStencil bool) error {
 	if err := i.ensureRenderTargetView(device); err != nil {
 		return err
 	}
 
 	if i.screen {
-		if useStencil {
-			return fmt.Errorf("directx: stencils are not available on the screen framebuffer")
+		if useStencil || useDepthStencil {
+			return fmt.Errorf("directx: depth-stencil buffers are not available on the screen framebuffer")
 		}
 		rtv, err := i.graphics.rtvDescriptorHeap.GetCPUDescriptorHandleForHeapStart()
 		if err != nil {
@@ -311,11 +315,12 @@ func (i *image12) setAsRenderTarget(drawCommandList *_ID3D12GraphicsCommandList,
 		return err
 	}
 
-	if !useStencil {
+	if !useStencil && !useDepthStencil {
 		drawCommandList.OMSetRenderTargets([]_D3D12_CPU_DESCRIPTOR_HANDLE{rtv}, false, nil)
 		return nil
 	}
 
+	initialized := i.dsvDescriptorHeap != nil
 	if err := i.ensureDepthStencilView(device); err != nil {
 		return err
 	}
@@ -325,7 +330,13 @@ func (i *image12) setAsRenderTarget(drawCommandList *_ID3D12GraphicsCommandList,
 	}
 	drawCommandList.OMSetStencilRef(0)
 	drawCommandList.OMSetRenderTargets([]_D3D12_CPU_DESCRIPTOR_HANDLE{rtv}, false, &dsv)
-	drawCommandList.ClearDepthStencilView(dsv, _D3D12_CLEAR_FLAG_STENCIL, 0, 0, nil)
+	if !initialized {
+		// Initialize the depth buffer with the farthest value (1), and the stencil buffer with 0.
+		drawCommandList.ClearDepthStencilView(dsv, _D3D12_CLEAR_FLAG_DEPTH|_D3D12_CLEAR_FLAG_STENCIL, 1, 0, nil)
+	}
+	if useStencil {
+		drawCommandList.ClearDepthStencilView(dsv, _D3D12_CLEAR_FLAG_STENCIL, 0, 0, nil)
+	}
 
 	return nil
 }
@@ -361,7 +372,7 @@ func (i *image12) ensureRenderTargetView(device *_ID3D12Device) error {
 
 func (i *image12) ensureDepthStencilView(device *_ID3D12Device) error {
 	if i.screen {
-		return fmt.Errorf("directx: stencils are not available on the screen framebuffer")
+		return fmt.Errorf("directx: depth-stencil buffers are not available on the screen framebuffer")
 	}
 
 	if i.dsvDescriptorHeap != nil {
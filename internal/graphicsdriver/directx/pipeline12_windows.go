@@ -21,6 +21,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 )
 
 var inputElementDescsForDX12 []_D3D12_INPUT_ELEMENT_DESC
@@ -116,6 +117,20 @@ func blendFactorToBlend12(f graphicsdriver.BlendFactor, alpha bool) _D3D12_BLEND
 		return _D3D12_BLEND_INV_DEST_ALPHA
 	case graphicsdriver.BlendFactorSourceAlphaSaturated:
 		return _D3D12_BLEND_SRC_ALPHA_SAT
+	case graphicsdriver.BlendFactorSourceColor1:
+		if alpha {
+			return _D3D12_BLEND_SRC1_ALPHA
+		}
+		return _D3D12_BLEND_SRC1_COLOR
+	case graphicsdriver.BlendFactorOneMinusSourceColor1:
+		if alpha {
+			return _D3D12_BLEND_INV_SRC1_ALPHA
+		}
+		return _D3D12_BLEND_INV_SRC1_COLOR
+	case graphicsdriver.BlendFactorSourceAlpha1:
+		return _D3D12_BLEND_SRC1_ALPHA
+	case graphicsdriver.BlendFactorOneMinusSourceAlpha1:
+		return _D3D12_BLEND_INV_SRC1_ALPHA
 	default:
 		panic(fmt.Sprintf("directx: invalid blend factor: %d", f))
 	}
@@ -145,6 +160,7 @@ type pipelineStates struct {
 	shaderDescriptorSize uint32
 
 	samplerDescriptorHeap *_ID3D12DescriptorHeap
+	samplerDescriptorSize uint32
 
 	constantBuffers    [frameCount][]*_ID3D12Resource
 	constantBufferMaps [frameCount][]uintptr
@@ -176,7 +192,7 @@ func (p *pipelineStates) initialize(device *_ID3D12Device) (ferr error) {
 
 	samplerH, err := device.CreateDescriptorHeap(&_D3D12_DESCRIPTOR_HEAP_DESC{
 		Type:           _D3D12_DESCRIPTOR_HEAP_TYPE_SAMPLER,
-		NumDescriptors: 1,
+		NumDescriptors: 2,
 		Flags:          _D3D12_DESCRIPTOR_HEAP_FLAG_SHADER_VISIBLE,
 		NodeMask:       0,
 	})
@@ -184,6 +200,7 @@ func (p *pipelineStates) initialize(device *_ID3D12Device) (ferr error) {
 		return err
 	}
 	p.samplerDescriptorHeap = samplerH
+	p.samplerDescriptorSize = device.GetDescriptorHandleIncrementSize(_D3D12_DESCRIPTOR_HEAP_TYPE_SAMPLER)
 
 	h, err := p.samplerDescriptorHeap.GetCPUDescriptorHandleForHeapStart()
 	if err != nil {
@@ -199,10 +216,23 @@ func (p *pipelineStates) initialize(device *_ID3D12Device) (ferr error) {
 		MaxLOD:         math.MaxFloat32,
 	}, h)
 
+	// The anisotropic sampler is used for shaderir.TexelAtAnisotropic.
+	h.Offset(1, p.samplerDescriptorSize)
+	device.CreateSampler(&_D3D12_SAMPLER_DESC{
+		Filter:         _D3D12_FILTER_ANISOTROPIC,
+		AddressU:       _D3D12_TEXTURE_ADDRESS_MODE_CLAMP,
+		AddressV:       _D3D12_TEXTURE_ADDRESS_MODE_CLAMP,
+		AddressW:       _D3D12_TEXTURE_ADDRESS_MODE_CLAMP,
+		MaxAnisotropy:  shaderir.MaxAnisotropy,
+		ComparisonFunc: _D3D12_COMPARISON_FUNC_NEVER,
+		MinLOD:         -math.MaxFloat32,
+		MaxLOD:         math.MaxFloat32,
+	}, h)
+
 	return nil
 }
 
-func (p *pipelineStates) drawTriangles(device *_ID3D12Device, commandList *_ID3D12GraphicsCommandList, frameIndex int, screen bool, srcs [graphics.ShaderSrcImageCount]*image12, shader *shader12, dstRegions []graphicsdriver.DstRegion, uniforms []uint32, blend graphicsdriver.Blend, indexOffset int, fillRule graphicsdriver.FillRule) error {
+func (p *pipelineStates) drawTriangles(device *_ID3D12Device, commandList *_ID3D12GraphicsCommandList, frameIndex int, screen bool, srcs [graphics.ShaderSrcImageCount]*image12, shader *shader12, dstRegions []graphicsdriver.DstRegion, uniforms []uint32, blend graphicsdriver.Blend, indexOffset int, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
 	idx := len(p.constantBuffers[frameIndex])
 	if idx >= numDescriptorsPerFrame {
 		return fmt.Errorf("directx: too many constant buffers")
@@ -312,11 +342,14 @@ func (p *pipelineStates) drawTriangles(device *_ID3D12Device, commandList *_ID3D
 	commandList.SetGraphicsRootDescriptorTable(2, sh)
 
 	if fillRule == graphicsdriver.FillRuleFillAll {
-		s, err := shader.pipelineState(blend, noStencil, screen)
+		s, err := shader.pipelineState(blend, noStencil, depthMode, stencil, screen)
 		if err != nil {
 			return err
 		}
 		commandList.SetPipelineState(s)
+		if !stencil.IsZero() {
+			commandList.OMSetStencilRef(uint32(stencil.Ref))
+		}
 	}
 
 	for _, dstRegion := range dstRegions {
@@ -332,14 +365,14 @@ func (p *pipelineStates) drawTriangles(device *_ID3D12Device, commandList *_ID3D
 		case graphicsdriver.FillRuleFillAll:
 			commandList.DrawIndexedInstanced(uint32(dstRegion.IndexCount), 1, uint32(indexOffset), 0, 0)
 		case graphicsdriver.FillRuleNonZero:
-			s, err := shader.pipelineState(blend, incrementStencil, screen)
+			s, err := shader.pipelineState(blend, incrementStencil, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, screen)
 			if err != nil {
 				return err
 			}
 			commandList.SetPipelineState(s)
 			commandList.DrawIndexedInstanced(uint32(dstRegion.IndexCount), 1, uint32(indexOffset), 0, 0)
 		case graphicsdriver.FillRuleEvenOdd:
-			s, err := shader.pipelineState(blend, invertStencil, screen)
+			s, err := shader.pipelineState(blend, invertStencil, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, screen)
 			if err != nil {
 				return err
 			}
@@ -348,7 +381,7 @@ func (p *pipelineStates) drawTriangles(device *_ID3D12Device, commandList *_ID3D
 		}
 
 		if fillRule != graphicsdriver.FillRuleFillAll {
-			s, err := shader.pipelineState(blend, drawWithStencil, screen)
+			s, err := shader.pipelineState(blend, drawWithStencil, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, screen)
 			if err != nil {
 				return err
 			}
@@ -382,8 +415,8 @@ func (p *pipelineStates) ensureRootSignature(device *_ID3D12Device) (rootSignatu
 		OffsetInDescriptorsFromTableStart: 1,
 	}
 	sampler := _D3D12_DESCRIPTOR_RANGE{
-		RangeType:                         _D3D12_DESCRIPTOR_RANGE_TYPE_SAMPLER, // s0
-		NumDescriptors:                    1,
+		RangeType:                         _D3D12_DESCRIPTOR_RANGE_TYPE_SAMPLER, // s0-s1
+		NumDescriptors:                    2,
 		BaseShaderRegister:                0,
 		RegisterSpace:                     0,
 		OffsetInDescriptorsFromTableStart: 0,
@@ -444,7 +477,7 @@ func (p *pipelineStates) ensureRootSignature(device *_ID3D12Device) (rootSignatu
 	return p.rootSignature, nil
 }
 
-func (p *pipelineStates) newPipelineState(device *_ID3D12Device, vsh, psh *_ID3DBlob, blend graphicsdriver.Blend, stencilMode stencilMode, screen bool) (state *_ID3D12PipelineState, ferr error) {
+func (p *pipelineStates) newPipelineState(device *_ID3D12Device, vsh, psh *_ID3DBlob, blend graphicsdriver.Blend, stencilMode stencilMode, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil, screen bool) (state *_ID3D12PipelineState, ferr error) {
 	rootSignature, err := p.ensureRootSignature(device)
 	if err != nil {
 		return nil, err
@@ -496,12 +529,30 @@ func (p *pipelineStates) newPipelineState(device *_ID3D12Device, vsh, psh *_ID3D
 		depthStencilDesc.BackFace.StencilFunc = _D3D12_COMPARISON_FUNC_NOT_EQUAL
 	}
 
+	switch depthMode {
+	case graphicsdriver.DepthModeTest:
+		depthStencilDesc.DepthEnable = 1
+		depthStencilDesc.DepthWriteMask = _D3D12_DEPTH_WRITE_MASK_ZERO
+	case graphicsdriver.DepthModeWrite:
+		depthStencilDesc.DepthEnable = 1
+		depthStencilDesc.DepthFunc = _D3D12_COMPARISON_FUNC_ALWAYS
+	case graphicsdriver.DepthModeTestAndWrite:
+		depthStencilDesc.DepthEnable = 1
+	}
+
+	if !stencil.IsZero() {
+		depthStencilDesc.StencilEnable = 1
+		depthStencilDesc.FrontFace.StencilPassOp = stencilOpToStencilOp12(stencil.Op)
+		depthStencilDesc.FrontFace.StencilFunc = stencilFuncToComparisonFunc12(stencil.Func)
+		depthStencilDesc.BackFace = depthStencilDesc.FrontFace
+	}
+
 	rtvFormat := _DXGI_FORMAT_R8G8B8A8_UNORM
 	if screen {
 		rtvFormat = _DXGI_FORMAT_B8G8R8A8_UNORM
 	}
 	dsvFormat := _DXGI_FORMAT_UNKNOWN
-	if stencilMode != noStencil {
+	if stencilMode != noStencil || depthMode != graphicsdriver.DepthModeNone || !stencil.IsZero() {
 		dsvFormat = _DXGI_FORMAT_D24_UNORM_S8_UINT
 	}
 
@@ -587,3 +638,49 @@ func (p *pipelineStates) resetConstantBuffers(frameIndex int) {
 	p.constantBuffers[frameIndex] = p.constantBuffers[frameIndex][:0]
 	p.constantBufferMaps[frameIndex] = p.constantBufferMaps[frameIndex][:0]
 }
+
+func stencilFuncToComparisonFunc12(f graphicsdriver.StencilFunc) _D3D12_COMPARISON_FUNC {
+	switch f {
+	case graphicsdriver.StencilFuncAlways:
+		return _D3D12_COMPARISON_FUNC_ALWAYS
+	case graphicsdriver.StencilFuncNever:
+		return _D3D12_COMPARISON_FUNC_NEVER
+	case graphicsdriver.StencilFuncEqual:
+		return _D3D12_COMPARISON_FUNC_EQUAL
+	case graphicsdriver.StencilFuncNotEqual:
+		return _D3D12_COMPARISON_FUNC_NOT_EQUAL
+	case graphicsdriver.StencilFuncLess:
+		return _D3D12_COMPARISON_FUNC_LESS
+	case graphicsdriver.StencilFuncLessEqual:
+		return _D3D12_COMPARISON_FUNC_LESS_EQUAL
+	case graphicsdriver.StencilFuncGreater:
+		return _D3D12_COMPARISON_FUNC_GREATER
+	case graphicsdriver.StencilFuncGreaterEqual:
+		return _D3D12_COMPARISON_FUNC_GREATER_EQUAL
+	default:
+		panic(fmt.Sprintf("directx: invalid stencil func: %d", f))
+	}
+}
+
+func stencilOpToStencilOp12(o graphicsdriver.StencilOp) _D3D12_STENCIL_OP {
+	switch o {
+	case graphicsdriver.StencilOpKeep:
+		return _D3D12_STENCIL_OP_KEEP
+	case graphicsdriver.StencilOpZero:
+		return _D3D12_STENCIL_OP_ZERO
+	case graphicsdriver.StencilOpReplace:
+		return _D3D12_STENCIL_OP_REPLACE
+	case graphicsdriver.StencilOpIncrement:
+		return _D3D12_STENCIL_OP_INCR_SAT
+	case graphicsdriver.StencilOpIncrementWrap:
+		return _D3D12_STENCIL_OP_INCR
+	case graphicsdriver.StencilOpDecrement:
+		return _D3D12_STENCIL_OP_DECR_SAT
+	case graphicsdriver.StencilOpDecrementWrap:
+		return _D3D12_STENCIL_OP_DECR
+	case graphicsdriver.StencilOpInvert:
+		return _D3D12_STENCIL_OP_INVERT
+	default:
+		panic(fmt.Sprintf("directx: invalid stencil operation: %d", o))
+	}
+}
@@ -34,6 +34,17 @@ type shader11 struct {
 	vertexShader   *_ID3D11VertexShader
 	pixelShader    *_ID3D11PixelShader
 	constantBuffer *_ID3D11Buffer
+
+	// multipleRenderTargets reports whether the fragment entry point returns colors for multiple render targets.
+	multipleRenderTargets bool
+	fragmentOutputCount   int
+
+	// computeShaderBlob, computeShader and readOnlyImages are used only for a compute kernel.
+	computeShaderBlob *_ID3DBlob
+	computeShader     *_ID3D11ComputeShader
+
+	// readOnlyImages reports whether each storage image is only read and then bound as an SRV.
+	readOnlyImages []bool
 }
 
 func (s *shader11) ID() graphicsdriver.ShaderID {
@@ -76,6 +87,14 @@ func (s *shader11) disposeImpl() {
 		s.constantBuffer.Release()
 		s.constantBuffer = nil
 	}
+	if s.computeShaderBlob != nil {
+		s.computeShaderBlob.Release()
+		s.computeShaderBlob = nil
+	}
+	if s.computeShader != nil {
+		s.computeShader.Release()
+		s.computeShader = nil
+	}
 }
 
 func (s *shader11) use(uniforms []uint32, srcs [graphics.ShaderSrcImageCount]*image11) error {
@@ -130,6 +149,37 @@ func (s *shader11) use(uniforms []uint32, srcs [graphics.ShaderSrcImageCount]*im
 	return nil
 }
 
+// useCompute sets the compute kernel and its parameters.
+// The storage images must already be bound by the caller.
+func (s *shader11) useCompute(uniforms []uint32) error {
+	cs, err := s.ensureComputeShader()
+	if err != nil {
+		return err
+	}
+	s.graphics.deviceContext.CSSetShader(cs, nil)
+
+	if len(s.uniformTypes) == 0 {
+		return nil
+	}
+
+	cb, err := s.ensureConstantBuffer()
+	if err != nil {
+		return err
+	}
+	s.graphics.deviceContext.CSSetConstantBuffers(0, []*_ID3D11Buffer{cb})
+
+	// Send the constant buffer data.
+	uniforms = adjustUniforms(s.uniformTypes, s.uniformOffsets, uniforms)
+	var mapped _D3D11_MAPPED_SUBRESOURCE
+	if err := s.graphics.deviceContext.Map(unsafe.Pointer(cb), 0, _D3D11_MAP_WRITE_DISCARD, 0, &mapped); err != nil {
+		return err
+	}
+	copy(unsafe.Slice((*uint32)(mapped.pData), len(uniforms)), uniforms)
+	s.graphics.deviceContext.Unmap(unsafe.Pointer(cb), 0)
+
+	return nil
+}
+
 func (s *shader11) ensureInputLayout() (*_ID3D11InputLayout, error) {
 	if s.inputLayout != nil {
 		return s.inputLayout, nil
@@ -169,6 +219,19 @@ func (s *shader11) ensurePixelShader() (*_ID3D11PixelShader, error) {
 	return ps, nil
 }
 
+func (s *shader11) ensureComputeShader() (*_ID3D11ComputeShader, error) {
+	if s.computeShader != nil {
+		return s.computeShader, nil
+	}
+
+	cs, err := s.graphics.device.CreateComputeShader(s.computeShaderBlob.GetBufferPointer(), s.computeShaderBlob.GetBufferSize(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.computeShader = cs
+	return cs, nil
+}
+
 func alignUp16(x uint32) uint32 {
 	if x%16 == 0 {
 		return x
@@ -20,9 +20,9 @@ import (
 )
 
 type pipelineStateKey struct {
-	blend       graphicsdriver.Blend
-	stencilMode stencilMode
-	screen      bool
+	blend        graphicsdriver.Blend
+	depthStencil depthStencilStateKey
+	screen       bool
 }
 
 type shader12 struct {
@@ -67,17 +67,23 @@ func (s *shader12) disposeImpl() {
 	}
 }
 
-func (s *shader12) pipelineState(blend graphicsdriver.Blend, stencilMode stencilMode, screen bool) (*_ID3D12PipelineState, error) {
+func (s *shader12) pipelineState(blend graphicsdriver.Blend, stencilMode stencilMode, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil, screen bool) (*_ID3D12PipelineState, error) {
+	// The stencil reference value is set by OMSetStencilRef and is not a part of a pipeline state.
+	stencil.Ref = 0
 	key := pipelineStateKey{
-		blend:       blend,
-		stencilMode: stencilMode,
-		screen:      screen,
+		blend: blend,
+		depthStencil: depthStencilStateKey{
+			stencilMode: stencilMode,
+			depthMode:   depthMode,
+			stencil:     stencil,
+		},
+		screen: screen,
 	}
 	if state, ok := s.pipelineStates[key]; ok {
 		return state, nil
 	}
 
-	state, err := s.graphics.pipelineStates.newPipelineState(s.graphics.device, s.vertexShader, s.pixelShader, blend, stencilMode, screen)
+	state, err := s.graphics.pipelineStates.newPipelineState(s.graphics.device, s.vertexShader, s.pixelShader, blend, stencilMode, depthMode, stencil, screen)
 	if err != nil {
 		return nil, err
 	}
@@ -22,16 +22,19 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir/hlsl"
 )
 
 const (
-	VertexShaderProfile = "vs_4_0"
-	PixelShaderProfile  = "ps_4_0"
+	VertexShaderProfile  = "vs_4_0"
+	PixelShaderProfile   = "ps_4_0"
+	ComputeShaderProfile = "cs_5_0"
 
-	VertexShaderEntryPoint = "VSMain"
-	PixelShaderEntryPoint  = "PSMain"
+	VertexShaderEntryPoint  = "VSMain"
+	PixelShaderEntryPoint   = "PSMain"
+	ComputeShaderEntryPoint = "CSMain"
 )
 
 type fxcPair struct {
@@ -89,7 +92,12 @@ func compileShader(program *shaderir.Program) (vsh, psh *_ID3DBlob, ferr error)
 		}
 	}()
 
-	if vshBin, pshBin := thePrecompiledFXCs.get(program.SourceHash); vshBin != nil && pshBin != nil {
+	vshBin, pshBin := thePrecompiledFXCs.get(program.SourceHash)
+	if vshBin == nil || pshBin == nil {
+		vshBin = graphicsdriver.ReadShaderCache(program.SourceHash, graphicsdriver.ShaderCacheFXCVertexSuffix)
+		pshBin = graphicsdriver.ReadShaderCache(program.SourceHash, graphicsdriver.ShaderCacheFXCPixelSuffix)
+	}
+	if vshBin != nil && pshBin != nil {
 		var err error
 		if vsh, err = _D3DCreateBlob(uint(len(vshBin))); err != nil {
 			return nil, nil, err
@@ -145,6 +153,15 @@ func compileShader(program *shaderir.Program) (vsh, psh *_ID3DBlob, ferr error)
 	return vsh, psh, nil
 }
 
+func compileComputeShader(program *shaderir.Program) (*_ID3DBlob, error) {
+	cs := hlsl.CompileCompute(program)
+	csh, err := _D3DCompile([]byte(cs), "shader", nil, nil, ComputeShaderEntryPoint, ComputeShaderProfile, uint32(_D3DCOMPILE_OPTIMIZATION_LEVEL3), 0)
+	if err != nil {
+		return nil, fmt.Errorf("directx: D3DCompile for CSMain failed, original source: %s, %w", cs, err)
+	}
+	return csh, nil
+}
+
 func constantBufferSize(uniformTypes []shaderir.Type, uniformOffsets []int) int {
 	var size int
 	for i, typ := range uniformTypes {
@@ -48,6 +48,145 @@ func (f FillRule) String() string {
 	}
 }
 
+// DepthMode represents how a depth buffer is used for rendering.
+//
+// The depth value of a vertex is the z value after applying the projection matrix to the vertex.
+type DepthMode int
+
+const (
+	// DepthModeNone indicates that the depth buffer is not used.
+	DepthModeNone DepthMode = iota
+
+	// DepthModeTest indicates that a fragment is rendered only when its depth value is less than the depth buffer's value.
+	// The depth buffer is not updated.
+	DepthModeTest
+
+	// DepthModeWrite indicates that all the fragments are rendered and their depth values are written to the depth buffer.
+	DepthModeWrite
+
+	// DepthModeTestAndWrite indicates that a fragment is rendered only when its depth value is less than the depth buffer's value,
+	// and the depth value is written to the depth buffer.
+	DepthModeTestAndWrite
+)
+
+func (d DepthMode) String() string {
+	switch d {
+	case DepthModeNone:
+		return "DepthModeNone"
+	case DepthModeTest:
+		return "DepthModeTest"
+	case DepthModeWrite:
+		return "DepthModeWrite"
+	case DepthModeTestAndWrite:
+		return "DepthModeTestAndWrite"
+	default:
+		return fmt.Sprintf("DepthMode(%d)", d)
+	}
+}
+
+// StencilFunc represents a comparison function for the stencil test.
+type StencilFunc int
+
+const (
+	StencilFuncAlways StencilFunc = iota
+	StencilFuncNever
+	StencilFuncEqual
+	StencilFuncNotEqual
+	StencilFuncLess
+	StencilFuncLessEqual
+	StencilFuncGreater
+	StencilFuncGreaterEqual
+)
+
+// StencilOp represents an operation to update the stencil buffer when a fragment passes the stencil and depth tests.
+type StencilOp int
+
+const (
+	StencilOpKeep StencilOp = iota
+	StencilOpZero
+	StencilOpReplace
+	StencilOpIncrement
+	StencilOpIncrementWrap
+	StencilOpDecrement
+	StencilOpDecrementWrap
+	StencilOpInvert
+)
+
+// Stencil represents how a stencil buffer is used for rendering.
+//
+// A fragment is rendered only when the comparison Ref Func (the stencil buffer's value) is true.
+// Then, Op is applied to the stencil buffer's value.
+//
+// The zero value means that the stencil buffer is not used.
+type Stencil struct {
+	Func StencilFunc
+	Op   StencilOp
+	Ref  uint8
+}
+
+// IsZero reports whether the stencil buffer is not used.
+func (s Stencil) IsZero() bool {
+	return s == Stencil{}
+}
+
+func (s Stencil) String() string {
+	return fmt.Sprintf("{func: %d, op: %d, ref: %d}", s.Func, s.Op, s.Ref)
+}
+
+// PixelFormat represents a pixel format of a texture.
+type PixelFormat int
+
+const (
+	// PixelFormatRGBA8 is a format with 8-bit unsigned normalized integers for each channel.
+	PixelFormatRGBA8 PixelFormat = iota
+
+	// PixelFormatRGBA16F is a format with 16-bit floating point values for each channel.
+	PixelFormatRGBA16F
+
+	// PixelFormatRGBA32F is a format with 32-bit floating point values for each channel.
+	PixelFormatRGBA32F
+
+	// PixelFormatRGBA16 is a format with 16-bit unsigned normalized integers for each channel.
+	PixelFormatRGBA16
+
+	// PixelFormatSRGBA8 is a format with 8-bit sRGB-encoded values for each color channel and a linear alpha channel.
+	// The values are decoded to the linear space when sampled, and encoded to sRGB when rendered.
+	PixelFormatSRGBA8
+)
+
+// IsFloat reports whether the format has floating point values.
+func (p PixelFormat) IsFloat() bool {
+	return p == PixelFormatRGBA16F || p == PixelFormatRGBA32F
+}
+
+// BytesPerPixel returns the number of bytes per pixel for pixels passed to WritePixels and ReadPixels.
+//
+// For PixelFormatRGBA16, a pixel consists of four uint16 values in the native byte order.
+// For the other formats, a pixel consists of four 8-bit values, even for the floating point formats.
+func (p PixelFormat) BytesPerPixel() int {
+	if p == PixelFormatRGBA16 {
+		return 8
+	}
+	return 4
+}
+
+func (p PixelFormat) String() string {
+	switch p {
+	case PixelFormatRGBA8:
+		return "PixelFormatRGBA8"
+	case PixelFormatRGBA16F:
+		return "PixelFormatRGBA16F"
+	case PixelFormatRGBA32F:
+		return "PixelFormatRGBA32F"
+	case PixelFormatRGBA16:
+		return "PixelFormatRGBA16"
+	case PixelFormatSRGBA8:
+		return "PixelFormatSRGBA8"
+	default:
+		return fmt.Sprintf("PixelFormat(%d)", p)
+	}
+}
+
 const (
 	InvalidImageID  = 0
 	InvalidShaderID = 0
@@ -59,7 +198,7 @@ type Graphics interface {
 	End(present bool) error
 	SetTransparent(transparent bool)
 	SetVertices(vertices []float32, indices []uint32) error
-	NewImage(width, height int) (Image, error)
+	NewImage(width, height int, format PixelFormat) (Image, error)
 	NewScreenFramebufferImage(width, height int) (Image, error)
 	SetVsyncEnabled(enabled bool)
 	NeedsClearingScreen() bool
@@ -68,7 +207,7 @@ type Graphics interface {
 	NewShader(program *shaderir.Program) (Shader, error)
 
 	// DrawTriangles draws an image onto another image with the given parameters.
-	DrawTriangles(dst ImageID, srcs [graphics.ShaderSrcImageCount]ImageID, shader ShaderID, dstRegions []DstRegion, indexOffset int, blend Blend, uniforms []uint32, fillRule FillRule) error
+	DrawTriangles(dst ImageID, srcs [graphics.ShaderSrcImageCount]ImageID, shader ShaderID, dstRegions []DstRegion, indexOffset int, blend Blend, uniforms []uint32, fillRule FillRule, depthMode DepthMode, stencil Stencil) error
 }
 
 type Resetter interface {
@@ -82,6 +221,16 @@ type Image interface {
 	WritePixels(args []PixelsArgs) error
 }
 
+// MultisampledImage is an optional interface for an Image that can be rendered with multisample anti-aliasing.
+// Only the OpenGL driver implements MultisampledImage.
+type MultisampledImage interface {
+	// SetSamples sets the number of samples per pixel.
+	// SetSamples must be called before the image is used as a render target.
+	//
+	// The rendering result is resolved automatically when the image is used as a source or its pixels are read.
+	SetSamples(samples int) error
+}
+
 type ImageID int
 
 type PixelsArgs struct {
@@ -89,6 +238,163 @@ type PixelsArgs struct {
 	Region image.Rectangle
 }
 
+// AsyncPixelsReader is an optional interface for an Image that can read pixels without stalling the GPU pipeline.
+// AsyncPixelsReader is implemented by the OpenGL and DirectX 11 drivers.
+type AsyncPixelsReader interface {
+	// ReadPixelsAsync starts reading pixels.
+	// The pixels are written to args' Pixels when the returned PendingPixels's TryFinish reports that reading is done.
+	ReadPixelsAsync(args []PixelsArgs) (PendingPixels, error)
+}
+
+// PendingPixels represents pixels being read asynchronously.
+type PendingPixels interface {
+	// TryFinish reports whether reading the pixels is done.
+	// When TryFinish returns true, the pixels are written and the resources for reading are released.
+	TryFinish() (bool, error)
+}
+
+// GPUTimer is an optional interface for Graphics that can record timestamps on the GPU.
+type GPUTimer interface {
+	// IsGPUTimerAvailable reports whether RecordGPUTimestamp is available in the current environment.
+	IsGPUTimerAvailable() bool
+
+	// RecordGPUTimestamp records a timestamp when all the preceding commands are completed on the GPU.
+	RecordGPUTimestamp() (GPUTimestamp, error)
+}
+
+// GPUTimestamp represents a timestamp being recorded on the GPU.
+type GPUTimestamp interface {
+	// TryFinish reports whether the timestamp is recorded, and returns the timestamp in nanoseconds.
+	// When TryFinish reports true, the resources for the timestamp are released.
+	TryFinish() (nanoseconds uint64, done bool, err error)
+}
+
+// OcclusionQuerier is an optional interface for Graphics that can count the samples rendered on the GPU.
+// OcclusionQuerier is implemented by the OpenGL and DirectX 11 drivers.
+type OcclusionQuerier interface {
+	// BeginOcclusionQuery starts counting the samples that pass the stencil and depth tests in the following commands.
+	// Occlusion queries cannot be nested.
+	BeginOcclusionQuery() (OcclusionQuery, error)
+
+	// EndOcclusionQuery ends counting the samples for the query started by the last BeginOcclusionQuery.
+	EndOcclusionQuery() error
+}
+
+// OcclusionQuery represents an occlusion query being processed on the GPU.
+type OcclusionQuery interface {
+	// TryFinish reports whether the result is available, and returns the number of the samples.
+	// In some environments, the number of the samples is 1 when any samples are rendered, or 0 otherwise.
+	// When TryFinish reports true, the resources for the query are released.
+	TryFinish() (samples uint64, done bool, err error)
+}
+
+// NativeTextureImporter is an optional interface for Graphics that can use a texture created outside of Ebitengine.
+// NativeTextureImporter is implemented by the OpenGL and Metal drivers.
+type NativeTextureImporter interface {
+	// NewImageFromNativeTexture creates an image referring to the given native texture.
+	// The texture must have the RGBA8 format and the given size.
+	// The texture is not released when the image is disposed.
+	NewImageFromNativeTexture(handle uintptr, width, height int) (Image, error)
+}
+
+// ImageCopier is an optional interface for Graphics that can copy pixels between images without the rendering pipeline.
+// ImageCopier is implemented by the OpenGL and DirectX 11 drivers.
+type ImageCopier interface {
+	// CopyImage copies the pixels in srcRegion of src to dst at dstPoint.
+	// The images must have the same pixel format, and dst must be neither the screen nor multisampled.
+	CopyImage(dst, src ImageID, dstPoint image.Point, srcRegion image.Rectangle) error
+}
+
+// ComputeDispatcher is an optional interface for Graphics that can execute compute kernels.
+type ComputeDispatcher interface {
+	// NewComputeShader creates a shader from a compute kernel program.
+	NewComputeShader(program *shaderir.Program) (Shader, error)
+
+	// DispatchCompute executes the compute kernel with x * y * z workgroups.
+	// images[i] is bound to the i-th storage image of the kernel.
+	// The images must have PixelFormatRGBA8, and must be neither the screen nor multisampled.
+	DispatchCompute(shader ShaderID, images []ImageID, uniforms []uint32, x, y, z int) error
+}
+
+// MultipleRenderTargetsDrawer is an optional interface for Graphics that can render to multiple images at once.
+// MultipleRenderTargetsDrawer is implemented by the OpenGL and DirectX 11 drivers.
+type MultipleRenderTargetsDrawer interface {
+	// IsMultipleRenderTargetsAvailable reports whether DrawTrianglesToMultipleTargets is available in the current environment.
+	IsMultipleRenderTargetsAvailable() bool
+
+	// DrawTrianglesToMultipleTargets is the same as DrawTriangles except that the i-th color of the shader is rendered to dsts[i].
+	// The number of the valid IDs in dsts must be the same as the number of the colors of the shader.
+	// The images must have the same size and the same pixel format, and must be neither the screen nor multisampled.
+	// The depth and stencil buffers of dsts[0] are used.
+	DrawTrianglesToMultipleTargets(dsts [graphics.ShaderDstImageCount]ImageID, srcs [graphics.ShaderSrcImageCount]ImageID, shader ShaderID, dstRegions []DstRegion, indexOffset int, blend Blend, uniforms []uint32, fillRule FillRule, depthMode DepthMode, stencil Stencil) error
+}
+
+// ScreenColorSpaceReporter is an optional interface for Graphics that can report the color space of the screen.
+type ScreenColorSpaceReporter interface {
+	// ScreenColorSpace returns the color space actually used for the screen.
+	// ScreenColorSpace might differ from the requested color space when the environment doesn't support it.
+	ScreenColorSpace() ColorSpace
+}
+
+// Feature represents an optional feature of a graphics driver.
+type Feature int
+
+const (
+	// FeatureDepth indicates that DrawTriangles can use a depth buffer with DepthModes other than DepthModeNone.
+	FeatureDepth Feature = iota
+
+	// FeatureStencil indicates that DrawTriangles can use a stencil buffer with a non-zero Stencil.
+	FeatureStencil
+
+	// FeatureFloatFormats indicates that NewImage can create a render target with PixelFormatRGBA16F or PixelFormatRGBA32F.
+	FeatureFloatFormats
+
+	// FeatureRGBA16 indicates that NewImage can create a render target with PixelFormatRGBA16.
+	FeatureRGBA16
+
+	// FeatureMultisampling indicates that an Image implements MultisampledImage and SetSamples works.
+	FeatureMultisampling
+
+	// FeatureDualSourceBlending indicates that DrawTriangles can use a Blend referring to the secondary source color.
+	FeatureDualSourceBlending
+
+	// FeatureCompute indicates that Graphics implements ComputeDispatcher and compute kernels can be executed.
+	FeatureCompute
+
+	// FeatureCount is the number of the features.
+	FeatureCount
+)
+
+func (f Feature) String() string {
+	switch f {
+	case FeatureDepth:
+		return "FeatureDepth"
+	case FeatureStencil:
+		return "FeatureStencil"
+	case FeatureFloatFormats:
+		return "FeatureFloatFormats"
+	case FeatureRGBA16:
+		return "FeatureRGBA16"
+	case FeatureMultisampling:
+		return "FeatureMultisampling"
+	case FeatureDualSourceBlending:
+		return "FeatureDualSourceBlending"
+	case FeatureCompute:
+		return "FeatureCompute"
+	default:
+		return fmt.Sprintf("Feature(%d)", f)
+	}
+}
+
+// FeatureReporter is an optional interface for Graphics that can report the available optional features.
+//
+// If Graphics doesn't implement FeatureReporter, no optional features are available.
+type FeatureReporter interface {
+	// IsFeatureAvailable reports whether the feature is available in the current environment.
+	// IsFeatureAvailable must be called after Initialize.
+	IsFeatureAvailable(feature Feature) bool
+}
+
 type Shader interface {
 	ID() ShaderID
 	Dispose()
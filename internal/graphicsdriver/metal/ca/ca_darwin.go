@@ -87,10 +87,9 @@ func NewMetalLayer(colorSpace graphicsdriver.ColorSpace) (MetalLayer, error) {
 	}
 
 	layer := objc.ID(objc.GetClass("CAMetalLayer")).Send(objc.RegisterName("new"))
-	// setColorspace: is available from iOS 13.0?
+	// setColorspace: is not available on old iOS versions.
 	// https://github.com/hajimehoshi/ebiten/commit/3af351a2aa31e30affd433429c42130015b302f3
-	// TODO: Enable this on iOS as well.
-	if runtime.GOOS != "ios" {
+	if IsColorspaceAvailable() {
 		// Dlsym returns pointer to symbol so dereference it.
 		colorspace, _, _ := purego.SyscallN(cgColorSpaceCreateWithName, **(**uintptr)(unsafe.Pointer(&colorSpaceSym)))
 		layer.Send(objc.RegisterName("setColorspace:"), colorspace)
@@ -99,6 +98,14 @@ func NewMetalLayer(colorSpace graphicsdriver.ColorSpace) (MetalLayer, error) {
 	return MetalLayer{layer}, nil
 }
 
+// IsColorspaceAvailable reports whether the color space of a Metal layer can be specified.
+// This is always true on macOS, and true on iOS 16.0 or later.
+//
+// Reference: https://developer.apple.com/documentation/quartzcore/cametallayer/1478154-colorspace?language=objc.
+func IsColorspaceAvailable() bool {
+	return objc.ID(objc.GetClass("CAMetalLayer")).Send(objc.RegisterName("instancesRespondToSelector:"), objc.RegisterName("setColorspace:")) != 0
+}
+
 // Layer implements the Layer interface.
 func (ml MetalLayer) Layer() unsafe.Pointer {
 	return *(*unsafe.Pointer)(unsafe.Pointer(&ml.metalLayer))
@@ -15,6 +15,7 @@
 package metal
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"math"
@@ -42,13 +43,17 @@ type Graphics struct {
 	rce  mtl.RenderCommandEncoder
 	dsss map[stencilMode]mtl.DepthStencilState
 
+	// depthStencilStates is the depth-stencil states for DepthModes and Stencils.
+	depthStencilStates map[depthStencilStateKey]mtl.DepthStencilState
+
 	screenDrawable ca.MetalDrawable
 
 	buffers       map[mtl.CommandBuffer][]mtl.Buffer
 	unusedBuffers map[mtl.Buffer]struct{}
 
-	lastDst      *Image
-	lastFillRule graphicsdriver.FillRule
+	lastDst          *Image
+	lastFillRule     graphicsdriver.FillRule
+	lastDepthStencil bool
 
 	vb mtl.Buffer
 	ib mtl.Buffer
@@ -63,6 +68,9 @@ type Graphics struct {
 	maxImageSize int
 	tmpTextures  []mtl.Texture
 
+	gpuTimerAvailable        bool
+	gpuTimerAvailableChecked bool
+
 	pool cocoa.NSAutoreleasePool
 }
 
@@ -75,6 +83,13 @@ const (
 	drawWithStencil
 )
 
+// depthStencilStateKey is a key for a depth-stencil state for DepthMode and Stencil.
+// Stencil's Ref is always 0 since the reference value is not a part of the state.
+type depthStencilStateKey struct {
+	depthMode graphicsdriver.DepthMode
+	stencil   graphicsdriver.Stencil
+}
+
 var (
 	systemDefaultDevice    mtl.Device
 	systemDefaultDeviceErr error
@@ -280,7 +295,11 @@ func (g *Graphics) genNextShaderID() graphicsdriver.ShaderID {
 	return g.nextShaderID
 }
 
-func (g *Graphics) NewImage(width, height int) (graphicsdriver.Image, error) {
+func (g *Graphics) NewImage(width, height int, format graphicsdriver.PixelFormat) (graphicsdriver.Image, error) {
+	if format != graphicsdriver.PixelFormatRGBA8 {
+		return nil, fmt.Errorf("metal: pixel format %s is not supported yet", format)
+	}
+
 	g.checkSize(width, height)
 	td := mtl.TextureDescriptor{
 		TextureType: mtl.TextureType2D,
@@ -302,6 +321,28 @@ func (g *Graphics) NewImage(width, height int) (graphicsdriver.Image, error) {
 	return i, nil
 }
 
+// NewImageFromNativeTexture implements graphicsdriver.NativeTextureImporter.
+func (g *Graphics) NewImageFromNativeTexture(handle uintptr, width, height int) (graphicsdriver.Image, error) {
+	if handle == 0 {
+		return nil, fmt.Errorf("metal: invalid texture: %d", handle)
+	}
+
+	g.checkSize(width, height)
+	t := mtl.NewTexture(objc.ID(handle))
+	// The texture is released at Dispose.
+	t.Retain()
+	i := &Image{
+		id:       g.genNextImageID(),
+		graphics: g,
+		width:    width,
+		height:   height,
+		texture:  t,
+		external: true,
+	}
+	g.addImage(i)
+	return i, nil
+}
+
 func (g *Graphics) NewScreenFramebufferImage(width, height int) (graphicsdriver.Image, error) {
 	g.view.setDrawableSize(width, height)
 	i := &Image{
@@ -357,6 +398,14 @@ func blendFactorToMetalBlendFactor(c graphicsdriver.BlendFactor) mtl.BlendFactor
 		return mtl.BlendFactorOneMinusDestinationAlpha
 	case graphicsdriver.BlendFactorSourceAlphaSaturated:
 		return mtl.BlendFactorSourceAlphaSaturated
+	case graphicsdriver.BlendFactorSourceColor1:
+		return mtl.BlendFactorSource1Color
+	case graphicsdriver.BlendFactorOneMinusSourceColor1:
+		return mtl.BlendFactorOneMinusSource1Color
+	case graphicsdriver.BlendFactorSourceAlpha1:
+		return mtl.BlendFactorSource1Alpha
+	case graphicsdriver.BlendFactorOneMinusSourceAlpha1:
+		return mtl.BlendFactorOneMinusSource1Alpha
 	default:
 		panic(fmt.Sprintf("metal: invalid blend factor: %d", c))
 	}
@@ -389,6 +438,10 @@ func (g *Graphics) Initialize() error {
 	if g.dsss == nil {
 		g.dsss = map[stencilMode]mtl.DepthStencilState{}
 	}
+	for _, dss := range g.depthStencilStates {
+		dss.Release()
+	}
+	g.depthStencilStates = map[depthStencilStateKey]mtl.DepthStencilState{}
 
 	if runtime.GOOS == "ios" {
 		// Initializing a Metal device and a layer must be done in the render thread on iOS.
@@ -402,6 +455,7 @@ func (g *Graphics) Initialize() error {
 
 	// The stencil reference value is always 0 (default).
 	g.dsss[noStencil] = g.view.getMTLDevice().NewDepthStencilStateWithDescriptor(mtl.DepthStencilDescriptor{
+		DepthCompareFunction: mtl.CompareFunctionAlways,
 		BackFaceStencil: mtl.StencilDescriptor{
 			StencilFailureOperation:   mtl.StencilOperationKeep,
 			DepthFailureOperation:     mtl.StencilOperationKeep,
@@ -416,6 +470,7 @@ func (g *Graphics) Initialize() error {
 		},
 	})
 	g.dsss[incrementStencil] = g.view.getMTLDevice().NewDepthStencilStateWithDescriptor(mtl.DepthStencilDescriptor{
+		DepthCompareFunction: mtl.CompareFunctionAlways,
 		BackFaceStencil: mtl.StencilDescriptor{
 			StencilFailureOperation:   mtl.StencilOperationKeep,
 			DepthFailureOperation:     mtl.StencilOperationKeep,
@@ -430,6 +485,7 @@ func (g *Graphics) Initialize() error {
 		},
 	})
 	g.dsss[invertStencil] = g.view.getMTLDevice().NewDepthStencilStateWithDescriptor(mtl.DepthStencilDescriptor{
+		DepthCompareFunction: mtl.CompareFunctionAlways,
 		BackFaceStencil: mtl.StencilDescriptor{
 			StencilFailureOperation:   mtl.StencilOperationKeep,
 			DepthFailureOperation:     mtl.StencilOperationKeep,
@@ -444,6 +500,7 @@ func (g *Graphics) Initialize() error {
 		},
 	})
 	g.dsss[drawWithStencil] = g.view.getMTLDevice().NewDepthStencilStateWithDescriptor(mtl.DepthStencilDescriptor{
+		DepthCompareFunction: mtl.CompareFunctionAlways,
 		BackFaceStencil: mtl.StencilDescriptor{
 			StencilFailureOperation:   mtl.StencilOperationKeep,
 			DepthFailureOperation:     mtl.StencilOperationKeep,
@@ -471,15 +528,24 @@ func (g *Graphics) flushRenderCommandEncoderIfNeeded() {
 	g.lastDst = nil
 }
 
-func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs [graphics.ShaderSrcImageCount]*Image, indexOffset int, shader *Shader, uniforms [][]uint32, blend graphicsdriver.Blend, fillRule graphicsdriver.FillRule) error {
+func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs [graphics.ShaderSrcImageCount]*Image, indexOffset int, shader *Shader, uniforms [][]uint32, blend graphicsdriver.Blend, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
+	if depthMode != graphicsdriver.DepthModeNone || !stencil.IsZero() {
+		if err := dst.ensureDepthStencil(); err != nil {
+			return err
+		}
+	}
+	// Once an image has a depth-stencil buffer, the buffer is always attached to keep its content.
+	useDepthStencil := dst.depthStencil != (mtl.Texture{})
+
 	// When preparing a stencil buffer, flush the current render command encoder
 	// to make sure the stencil buffer is cleared when loading.
 	// TODO: What about clearing the stencil buffer by vertices?
-	if g.lastDst != dst || g.lastFillRule != fillRule || fillRule != graphicsdriver.FillRuleFillAll {
+	if g.lastDst != dst || g.lastFillRule != fillRule || fillRule != graphicsdriver.FillRuleFillAll || g.lastDepthStencil != useDepthStencil {
 		g.flushRenderCommandEncoderIfNeeded()
 	}
 	g.lastDst = dst
 	g.lastFillRule = fillRule
+	g.lastDepthStencil = useDepthStencil
 
 	if g.rce == (mtl.RenderCommandEncoder{}) {
 		rpd := mtl.RenderPassDescriptor{}
@@ -501,7 +567,23 @@ func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs
 		rpd.ColorAttachments[0].Texture = t
 		rpd.ColorAttachments[0].ClearColor = mtl.ClearColor{}
 
-		if fillRule != graphicsdriver.FillRuleFillAll {
+		if useDepthStencil {
+			// The depth is cleared with 1 and the stencil is cleared with 0 for the first use.
+			load := mtl.LoadActionLoad
+			if !dst.depthStencilInitialized {
+				load = mtl.LoadActionClear
+				dst.depthStencilInitialized = true
+			}
+			rpd.DepthAttachment.LoadAction = load
+			rpd.DepthAttachment.StoreAction = mtl.StoreActionStore
+			rpd.DepthAttachment.Texture = dst.depthStencil
+			rpd.StencilAttachment.LoadAction = load
+			if fillRule != graphicsdriver.FillRuleFillAll {
+				rpd.StencilAttachment.LoadAction = mtl.LoadActionClear
+			}
+			rpd.StencilAttachment.StoreAction = mtl.StoreActionStore
+			rpd.StencilAttachment.Texture = dst.depthStencil
+		} else if fillRule != graphicsdriver.FillRuleFillAll {
 			dst.ensureStencil()
 			rpd.StencilAttachment.LoadAction = mtl.LoadActionClear
 			rpd.StencilAttachment.StoreAction = mtl.StoreActionDontCare
@@ -514,13 +596,18 @@ func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs
 		g.rce = g.cb.RenderCommandEncoderWithDescriptor(rpd)
 	}
 
+	// The depth values are in [0, 1] in the normalized device coordinate for the depth test.
+	zNear := -1.0
+	if depthMode != graphicsdriver.DepthModeNone {
+		zNear = 0
+	}
 	w, h := dst.internalSize()
 	g.rce.SetViewport(mtl.Viewport{
 		OriginX: 0,
 		OriginY: 0,
 		Width:   float64(w),
 		Height:  float64(h),
-		ZNear:   -1,
+		ZNear:   zNear,
 		ZFar:    1,
 	})
 	g.rce.SetVertexBuffer(g.vb, 0, 0)
@@ -549,32 +636,38 @@ func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs
 	)
 	switch fillRule {
 	case graphicsdriver.FillRuleFillAll:
-		s, err := shader.RenderPipelineState(&g.view, blend, noStencil, dst.screen)
+		s, err := shader.RenderPipelineState(&g.view, blend, noStencil, useDepthStencil, dst.screen)
 		if err != nil {
 			return err
 		}
 		noStencilRpss = s
 	case graphicsdriver.FillRuleNonZero:
-		s, err := shader.RenderPipelineState(&g.view, blend, incrementStencil, dst.screen)
+		s, err := shader.RenderPipelineState(&g.view, blend, incrementStencil, useDepthStencil, dst.screen)
 		if err != nil {
 			return err
 		}
 		incrementStencilRpss = s
 	case graphicsdriver.FillRuleEvenOdd:
-		s, err := shader.RenderPipelineState(&g.view, blend, invertStencil, dst.screen)
+		s, err := shader.RenderPipelineState(&g.view, blend, invertStencil, useDepthStencil, dst.screen)
 		if err != nil {
 			return err
 		}
 		invertStencilRpss = s
 	}
 	if fillRule != graphicsdriver.FillRuleFillAll {
-		s, err := shader.RenderPipelineState(&g.view, blend, drawWithStencil, dst.screen)
+		s, err := shader.RenderPipelineState(&g.view, blend, drawWithStencil, useDepthStencil, dst.screen)
 		if err != nil {
 			return err
 		}
 		drawWithStencilRpss = s
 	}
 
+	noStencilDss := g.dsss[noStencil]
+	if depthMode != graphicsdriver.DepthModeNone || !stencil.IsZero() {
+		noStencilDss = g.depthStencilState(depthMode, stencil)
+	}
+	g.rce.SetStencilReferenceValue(uint32(stencil.Ref))
+
 	for _, dstRegion := range dstRegions {
 		g.rce.SetScissorRect(mtl.ScissorRect{
 			X:      dstRegion.Region.Min.X,
@@ -585,7 +678,7 @@ func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs
 
 		switch fillRule {
 		case graphicsdriver.FillRuleFillAll:
-			g.rce.SetDepthStencilState(g.dsss[noStencil])
+			g.rce.SetDepthStencilState(noStencilDss)
 			g.rce.SetRenderPipelineState(noStencilRpss)
 			g.rce.DrawIndexedPrimitives(mtl.PrimitiveTypeTriangle, dstRegion.IndexCount, mtl.IndexTypeUInt32, g.ib, indexOffset*int(unsafe.Sizeof(uint32(0))))
 		case graphicsdriver.FillRuleNonZero:
@@ -609,7 +702,90 @@ func (g *Graphics) draw(dst *Image, dstRegions []graphicsdriver.DstRegion, srcs
 	return nil
 }
 
-func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule) error {
+// depthStencilState returns a depth-stencil state for the depth mode and the stencil.
+func (g *Graphics) depthStencilState(depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) mtl.DepthStencilState {
+	stencil.Ref = 0
+	key := depthStencilStateKey{
+		depthMode: depthMode,
+		stencil:   stencil,
+	}
+	if dss, ok := g.depthStencilStates[key]; ok {
+		return dss
+	}
+
+	dsd := mtl.DepthStencilDescriptor{
+		DepthCompareFunction: mtl.CompareFunctionAlways,
+	}
+	switch depthMode {
+	case graphicsdriver.DepthModeTest:
+		dsd.DepthCompareFunction = mtl.CompareFunctionLess
+	case graphicsdriver.DepthModeWrite:
+		dsd.DepthWriteEnabled = true
+	case graphicsdriver.DepthModeTestAndWrite:
+		dsd.DepthCompareFunction = mtl.CompareFunctionLess
+		dsd.DepthWriteEnabled = true
+	}
+	sd := mtl.StencilDescriptor{
+		StencilFailureOperation:   mtl.StencilOperationKeep,
+		DepthFailureOperation:     mtl.StencilOperationKeep,
+		DepthStencilPassOperation: stencilOperationToMetalStencilOperation(stencil.Op),
+		StencilCompareFunction:    stencilFuncToMetalCompareFunction(stencil.Func),
+	}
+	dsd.BackFaceStencil = sd
+	dsd.FrontFaceStencil = sd
+
+	dss := g.view.getMTLDevice().NewDepthStencilStateWithDescriptor(dsd)
+	g.depthStencilStates[key] = dss
+	return dss
+}
+
+func stencilFuncToMetalCompareFunction(f graphicsdriver.StencilFunc) mtl.CompareFunction {
+	switch f {
+	case graphicsdriver.StencilFuncAlways:
+		return mtl.CompareFunctionAlways
+	case graphicsdriver.StencilFuncNever:
+		return mtl.CompareFunctionNever
+	case graphicsdriver.StencilFuncEqual:
+		return mtl.CompareFunctionEqual
+	case graphicsdriver.StencilFuncNotEqual:
+		return mtl.CompareFunctionNotEqual
+	case graphicsdriver.StencilFuncLess:
+		return mtl.CompareFunctionLess
+	case graphicsdriver.StencilFuncLessEqual:
+		return mtl.CompareFunctionLessEqual
+	case graphicsdriver.StencilFuncGreater:
+		return mtl.CompareFunctionGreater
+	case graphicsdriver.StencilFuncGreaterEqual:
+		return mtl.CompareFunctionGreaterEqual
+	default:
+		panic(fmt.Sprintf("metal: invalid stencil func: %d", f))
+	}
+}
+
+func stencilOperationToMetalStencilOperation(o graphicsdriver.StencilOp) mtl.StencilOperation {
+	switch o {
+	case graphicsdriver.StencilOpKeep:
+		return mtl.StencilOperationKeep
+	case graphicsdriver.StencilOpZero:
+		return mtl.StencilOperationZero
+	case graphicsdriver.StencilOpReplace:
+		return mtl.StencilOperationReplace
+	case graphicsdriver.StencilOpIncrement:
+		return mtl.StencilOperationIncrementClamp
+	case graphicsdriver.StencilOpIncrementWrap:
+		return mtl.StencilOperationIncrementWrap
+	case graphicsdriver.StencilOpDecrement:
+		return mtl.StencilOperationDecrementClamp
+	case graphicsdriver.StencilOpDecrementWrap:
+		return mtl.StencilOperationDecrementWrap
+	case graphicsdriver.StencilOpInvert:
+		return mtl.StencilOperationInvert
+	default:
+		panic(fmt.Sprintf("metal: invalid stencil operation: %d", o))
+	}
+}
+
+func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
 	if shaderID == graphicsdriver.InvalidShaderID {
 		return fmt.Errorf("metal: shader ID is invalid")
 	}
@@ -625,21 +801,30 @@ func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.
 		srcs[i] = g.images[srcID]
 	}
 
-	uniformVars := make([][]uint32, len(g.shaders[shaderID].ir.Uniforms))
+	uniformVars := alignUniforms(g.shaders[shaderID].ir.Uniforms, uniforms)
 
-	// Set the additional uniform variables.
-	var idx int
-	for i, t := range g.shaders[shaderID].ir.Uniforms {
-		if i == graphics.ProjectionMatrixUniformVariableIndex {
-			// In Metal, the NDC's Y direction (upward) and the framebuffer's Y direction (downward) don't
-			// match. Then, the Y direction must be inverted.
-			// Invert the sign bits as float32 values.
-			uniforms[idx+1] ^= 1 << 31
-			uniforms[idx+5] ^= 1 << 31
-			uniforms[idx+9] ^= 1 << 31
-			uniforms[idx+13] ^= 1 << 31
-		}
+	// In Metal, the NDC's Y direction (upward) and the framebuffer's Y direction (downward) don't
+	// match. Then, the Y direction must be inverted.
+	// Invert the sign bits as float32 values.
+	proj := uniformVars[graphics.ProjectionMatrixUniformVariableIndex]
+	proj[1] ^= 1 << 31
+	proj[5] ^= 1 << 31
+	proj[9] ^= 1 << 31
+	proj[13] ^= 1 << 31
+
+	if err := g.draw(dst, dstRegions, srcs, indexOffset, g.shaders[shaderID], uniformVars, blend, fillRule, depthMode, stencil); err != nil {
+		return err
+	}
 
+	return nil
+}
+
+// alignUniforms splits the uniform values into the variables, and pads them to follow Metal's alignment rules.
+func alignUniforms(types []shaderir.Type, uniforms []uint32) [][]uint32 {
+	uniformVars := make([][]uint32, len(types))
+
+	var idx int
+	for i, t := range types {
 		n := t.Uint32Count()
 
 		switch t.Main {
@@ -685,13 +870,53 @@ func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.
 		idx += n
 	}
 
-	if err := g.draw(dst, dstRegions, srcs, indexOffset, g.shaders[shaderID], uniformVars, blend, fillRule); err != nil {
-		return err
+	return uniformVars
+}
+
+// DispatchCompute implements graphicsdriver.ComputeDispatcher.
+func (g *Graphics) DispatchCompute(shaderID graphicsdriver.ShaderID, imageIDs []graphicsdriver.ImageID, uniforms []uint32, x, y, z int) error {
+	shader := g.shaders[shaderID]
+	if !shader.ir.IsCompute() {
+		return fmt.Errorf("metal: the shader for DispatchCompute must be a compute kernel")
+	}
+	if len(imageIDs) != shader.ir.StorageImageCount {
+		return fmt.Errorf("metal: the number of the storage images must be %d but %d", shader.ir.StorageImageCount, len(imageIDs))
+	}
+
+	g.flushRenderCommandEncoderIfNeeded()
+
+	if g.cb == (mtl.CommandBuffer{}) {
+		g.cb = g.cq.CommandBuffer()
+	}
+
+	for _, id := range imageIDs {
+		img := g.images[id]
+		if img.screen || img.external {
+			return fmt.Errorf("metal: a storage image must be neither the screen nor an external texture")
+		}
+		img.ensureShaderWrite()
 	}
 
+	cce := g.cb.ComputeCommandEncoder()
+	cce.SetComputePipelineState(shader.cps)
+	for i, u := range alignUniforms(shader.ir.Uniforms, uniforms) {
+		cce.SetBytes(unsafe.Pointer(&u[0]), unsafe.Sizeof(u[0])*uintptr(len(u)), i)
+	}
+	for i, id := range imageIDs {
+		cce.SetTexture(g.images[id].texture, i)
+	}
+	size := shader.ir.ComputeFunc.WorkgroupSize
+	cce.DispatchThreadgroups(mtl.Size{Width: x, Height: y, Depth: z}, mtl.Size{Width: size[0], Height: size[1], Depth: size[2]})
+	cce.EndEncoding()
+
 	return nil
 }
 
+// NewComputeShader implements graphicsdriver.ComputeDispatcher.
+func (g *Graphics) NewComputeShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
+	return g.NewShader(program)
+}
+
 func (g *Graphics) SetVsyncEnabled(enabled bool) {
 	g.view.setDisplaySyncEnabled(enabled)
 }
@@ -700,6 +925,88 @@ func (g *Graphics) NeedsClearingScreen() bool {
 	return false
 }
 
+// ScreenColorSpace implements graphicsdriver.ScreenColorSpaceReporter.
+func (g *Graphics) ScreenColorSpace() graphicsdriver.ColorSpace {
+	// On old iOS versions, the color space of the layer cannot be set, and the content is treated as sRGB.
+	// See ca.NewMetalLayer.
+	if !ca.IsColorspaceAvailable() {
+		return graphicsdriver.ColorSpaceSRGB
+	}
+	if g.colorSpace == graphicsdriver.ColorSpaceSRGB {
+		return graphicsdriver.ColorSpaceSRGB
+	}
+	return graphicsdriver.ColorSpaceDisplayP3
+}
+
+// IsFeatureAvailable implements graphicsdriver.FeatureReporter.
+func (g *Graphics) IsFeatureAvailable(feature graphicsdriver.Feature) bool {
+	switch feature {
+	case graphicsdriver.FeatureDepth, graphicsdriver.FeatureStencil, graphicsdriver.FeatureDualSourceBlending, graphicsdriver.FeatureCompute:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsGPUTimerAvailable implements graphicsdriver.GPUTimer.
+func (g *Graphics) IsGPUTimerAvailable() bool {
+	if !g.gpuTimerAvailableChecked {
+		// GPUEndTime is available as of macOS 10.15 and iOS 10.3.
+		g.gpuTimerAvailable = g.cq.CommandBuffer().RespondsToSelector(objc.RegisterName("GPUEndTime"))
+		g.gpuTimerAvailableChecked = true
+	}
+	return g.gpuTimerAvailable
+}
+
+// RecordGPUTimestamp implements graphicsdriver.GPUTimer.
+//
+// Sampling a timestamp counter between commands is not available on Apple GPUs.
+// Instead, the current command buffer is committed, and the time when the GPU finished it is used as the timestamp.
+func (g *Graphics) RecordGPUTimestamp() (graphicsdriver.GPUTimestamp, error) {
+	if g.cb == (mtl.CommandBuffer{}) {
+		g.cb = g.cq.CommandBuffer()
+	}
+	cb := g.cb
+	cb.Retain()
+
+	bs, ok := g.buffers[cb]
+	g.flushIfNeeded(false)
+
+	// The following commands in the next command buffer might still use the current vertex and index buffers.
+	// Move the buffers to the next command buffer so that they are not reused until the next command buffer is completed.
+	if ok {
+		g.cb = g.cq.CommandBuffer()
+		g.cb.Retain()
+		g.buffers[g.cb] = bs
+		delete(g.buffers, cb)
+		cb.Release()
+	}
+
+	return &gpuTimestamp{
+		commandBuffer: cb,
+	}, nil
+}
+
+// gpuTimestamp represents a timestamp being recorded as the end time of a command buffer.
+type gpuTimestamp struct {
+	commandBuffer mtl.CommandBuffer
+}
+
+// TryFinish implements graphicsdriver.GPUTimestamp.
+func (t *gpuTimestamp) TryFinish() (uint64, bool, error) {
+	switch t.commandBuffer.Status() {
+	case mtl.CommandBufferStatusCompleted:
+		ns := uint64(t.commandBuffer.GPUEndTime() * 1e9)
+		t.commandBuffer.Release()
+		return ns, true, nil
+	case mtl.CommandBufferStatusError:
+		t.commandBuffer.Release()
+		return 0, false, errors.New("metal: the command buffer for a GPU timestamp failed")
+	default:
+		return 0, false, nil
+	}
+}
+
 func (g *Graphics) MaxImageSize() int {
 	if g.maxImageSize != 0 {
 		return g.maxImageSize
@@ -780,6 +1087,16 @@ type Image struct {
 	screen   bool
 	texture  mtl.Texture
 	stencil  mtl.Texture
+
+	// depthStencil is a buffer for both depth and stencil, used for DepthModes and Stencils.
+	depthStencil            mtl.Texture
+	depthStencilInitialized bool
+
+	// external reports whether the texture is created outside of Ebitengine.
+	external bool
+
+	// shaderWrite reports whether the texture can be written by compute kernels.
+	shaderWrite bool
 }
 
 func (i *Image) ID() graphicsdriver.ImageID {
@@ -787,7 +1104,7 @@ func (i *Image) ID() graphicsdriver.ImageID {
 }
 
 func (i *Image) internalSize() (int, int) {
-	if i.screen {
+	if i.screen || i.external {
 		return i.width, i.height
 	}
 	return graphics.InternalImageSize(i.width), graphics.InternalImageSize(i.height)
@@ -798,6 +1115,10 @@ func (i *Image) Dispose() {
 		i.stencil.Release()
 		i.stencil = mtl.Texture{}
 	}
+	if i.depthStencil != (mtl.Texture{}) {
+		i.depthStencil.Release()
+		i.depthStencil = mtl.Texture{}
+	}
 	if i.texture != (mtl.Texture{}) {
 		i.texture.Release()
 		i.texture = mtl.Texture{}
@@ -905,6 +1226,35 @@ func (i *Image) mtlTexture() mtl.Texture {
 	return i.texture
 }
 
+// ensureShaderWrite recreates the texture so that compute kernels can write it.
+// The shader write usage is not specified at NewImage, as this might disable some optimizations like lossless compression.
+func (i *Image) ensureShaderWrite() {
+	if i.shaderWrite {
+		return
+	}
+
+	g := i.graphics
+	w, h := i.internalSize()
+	td := mtl.TextureDescriptor{
+		TextureType: mtl.TextureType2D,
+		PixelFormat: mtl.PixelFormatRGBA8UNorm,
+		Width:       w,
+		Height:      h,
+		StorageMode: storageMode,
+		Usage:       mtl.TextureUsageShaderRead | mtl.TextureUsageShaderWrite | mtl.TextureUsageRenderTarget,
+	}
+	t := g.view.getMTLDevice().NewTextureWithDescriptor(td)
+
+	bce := g.cb.BlitCommandEncoder()
+	bce.CopyFromTexture(i.texture, 0, 0, mtl.Origin{}, mtl.Size{Width: w, Height: h, Depth: 1}, t, 0, 0, mtl.Origin{})
+	bce.EndEncoding()
+
+	// The old texture is still used by the command buffer. Release it after the command buffer is committed.
+	g.tmpTextures = append(g.tmpTextures, i.texture)
+	i.texture = t
+	i.shaderWrite = true
+}
+
 func (i *Image) ensureStencil() {
 	if i.stencil != (mtl.Texture{}) {
 		return
@@ -920,3 +1270,26 @@ func (i *Image) ensureStencil() {
 	}
 	i.stencil = i.graphics.view.getMTLDevice().NewTextureWithDescriptor(td)
 }
+
+// ensureDepthStencil ensures that the image has a buffer for both depth and stencil.
+func (i *Image) ensureDepthStencil() error {
+	if i.depthStencil != (mtl.Texture{}) {
+		return nil
+	}
+
+	if i.screen {
+		return errors.New("metal: a depth-stencil buffer is not available for the screen")
+	}
+
+	td := mtl.TextureDescriptor{
+		TextureType: mtl.TextureType2D,
+		PixelFormat: mtl.PixelFormatDepth32FloatStencil8,
+		Width:       graphics.InternalImageSize(i.width),
+		Height:      graphics.InternalImageSize(i.height),
+		StorageMode: mtl.StorageModePrivate,
+		Usage:       mtl.TextureUsageRenderTarget,
+	}
+	i.depthStencil = i.graphics.view.getMTLDevice().NewTextureWithDescriptor(td)
+	i.depthStencilInitialized = false
+	return nil
+}
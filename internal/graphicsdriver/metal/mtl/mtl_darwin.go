@@ -113,6 +113,8 @@ const (
 	PixelFormatBGRA8UNorm     PixelFormat = 80  // Ordinary format with four 8-bit normalized unsigned integer components in BGRA order.
 	PixelFormatBGRA8UNormSRGB PixelFormat = 81  // Ordinary format with four 8-bit normalized unsigned integer components in BGRA order with conversion between sRGB and linear space.
 	PixelFormatStencil8       PixelFormat = 253 // A pixel format with an 8-bit unsigned integer component, used for a stencil render target.
+
+	PixelFormatDepth32FloatStencil8 PixelFormat = 260 // A 40-bit combined depth and stencil pixel format with a 32-bit floating-point value for depth and an 8-bit unsigned integer for stencil.
 )
 
 // PrimitiveType defines geometric primitive types for drawing commands.
@@ -377,6 +379,9 @@ type RenderPipelineDescriptor struct {
 	// ColorAttachments is an array of attachments that store color data.
 	ColorAttachments [1]RenderPipelineColorAttachmentDescriptor
 
+	// DepthAttachmentPixelFormat is the pixel format of the attachment that stores depth data.
+	DepthAttachmentPixelFormat PixelFormat
+
 	// StencilAttachmentPixelFormat is the pixel format of the attachment that stores stencil data.
 	StencilAttachmentPixelFormat PixelFormat
 }
@@ -409,6 +414,9 @@ type RenderPassDescriptor struct {
 	// ColorAttachments is array of state information for attachments that store color data.
 	ColorAttachments [1]RenderPassColorAttachmentDescriptor
 
+	// DepthAttachment is state information for an attachment that stores depth data.
+	DepthAttachment RenderPassDepthAttachment
+
 	// StencilAttachment is state information for an attachment that stores stencil data.
 	StencilAttachment RenderPassStencilAttachment
 }
@@ -422,6 +430,16 @@ type RenderPassColorAttachmentDescriptor struct {
 	ClearColor ClearColor
 }
 
+// RenderPassDepthAttachment describes a depth render target that serves as the output
+// destination for depth pixels generated by a render pass.
+//
+// The clear value is always the default value 1.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlrenderpassdepthattachmentdescriptor?language=objc.
+type RenderPassDepthAttachment struct {
+	RenderPassAttachmentDescriptor
+}
+
 // RenderPassStencilAttachment describes a stencil render target that serves as the output
 // destination for stencil pixels generated by a render pass.
 //
@@ -511,6 +529,7 @@ var (
 	sel_setAlphaBlendOperation                                                                                                        = objc.RegisterName("setAlphaBlendOperation:")
 	sel_setRgbBlendOperation                                                                                                          = objc.RegisterName("setRgbBlendOperation:")
 	sel_setWriteMask                                                                                                                  = objc.RegisterName("setWriteMask:")
+	sel_setDepthAttachmentPixelFormat                                                                                                 = objc.RegisterName("setDepthAttachmentPixelFormat:")
 	sel_setStencilAttachmentPixelFormat                                                                                               = objc.RegisterName("setStencilAttachmentPixelFormat:")
 	sel_newRenderPipelineStateWithDescriptor_error                                                                                    = objc.RegisterName("newRenderPipelineStateWithDescriptor:error:")
 	sel_newBufferWithBytes_length_options                                                                                             = objc.RegisterName("newBufferWithBytes:length:options:")
@@ -531,7 +550,9 @@ var (
 	sel_commit                                                                                                                        = objc.RegisterName("commit")
 	sel_waitUntilCompleted                                                                                                            = objc.RegisterName("waitUntilCompleted")
 	sel_waitUntilScheduled                                                                                                            = objc.RegisterName("waitUntilScheduled")
+	sel_GPUEndTime                                                                                                                    = objc.RegisterName("GPUEndTime")
 	sel_renderCommandEncoderWithDescriptor                                                                                            = objc.RegisterName("renderCommandEncoderWithDescriptor:")
+	sel_depthAttachment                                                                                                               = objc.RegisterName("depthAttachment")
 	sel_stencilAttachment                                                                                                             = objc.RegisterName("stencilAttachment")
 	sel_setLoadAction                                                                                                                 = objc.RegisterName("setLoadAction:")
 	sel_setStoreAction                                                                                                                = objc.RegisterName("setStoreAction:")
@@ -547,6 +568,7 @@ var (
 	sel_setFragmentBytes_length_atIndex                                                                                               = objc.RegisterName("setFragmentBytes:length:atIndex:")
 	sel_setFragmentTexture_atIndex                                                                                                    = objc.RegisterName("setFragmentTexture:atIndex:")
 	sel_setBlendColorRedGreenBlueAlpha                                                                                                = objc.RegisterName("setBlendColorRed:green:blue:alpha:")
+	sel_setStencilReferenceValue                                                                                                      = objc.RegisterName("setStencilReferenceValue:")
 	sel_setDepthStencilState                                                                                                          = objc.RegisterName("setDepthStencilState:")
 	sel_drawPrimitives_vertexStart_vertexCount                                                                                        = objc.RegisterName("drawPrimitives:vertexStart:vertexCount:")
 	sel_drawIndexedPrimitives_indexCount_indexType_indexBuffer_indexBufferOffset                                                      = objc.RegisterName("drawIndexedPrimitives:indexCount:indexType:indexBuffer:indexBufferOffset:")
@@ -554,6 +576,8 @@ var (
 	sel_synchronizeTexture_slice_level                                                                                                = objc.RegisterName("synchronizeTexture:slice:level:")
 	sel_copyFromTexture_sourceSlice_sourceLevel_sourceOrigin_sourceSize_toTexture_destinationSlice_destinationLevel_destinationOrigin = objc.RegisterName("copyFromTexture:sourceSlice:sourceLevel:sourceOrigin:sourceSize:toTexture:destinationSlice:destinationLevel:destinationOrigin:")
 	sel_newFunctionWithName                                                                                                           = objc.RegisterName("newFunctionWithName:")
+	sel_setDepthCompareFunction                                                                                                       = objc.RegisterName("setDepthCompareFunction:")
+	sel_setDepthWriteEnabled                                                                                                          = objc.RegisterName("setDepthWriteEnabled:")
 	sel_backFaceStencil                                                                                                               = objc.RegisterName("backFaceStencil")
 	sel_frontFaceStencil                                                                                                              = objc.RegisterName("frontFaceStencil")
 	sel_setStencilFailureOperation                                                                                                    = objc.RegisterName("setStencilFailureOperation:")
@@ -563,6 +587,12 @@ var (
 	sel_newDepthStencilStateWithDescriptor                                                                                            = objc.RegisterName("newDepthStencilStateWithDescriptor:")
 	sel_replaceRegion_mipmapLevel_withBytes_bytesPerRow                                                                               = objc.RegisterName("replaceRegion:mipmapLevel:withBytes:bytesPerRow:")
 	sel_getBytes_bytesPerRow_fromRegion_mipmapLevel                                                                                   = objc.RegisterName("getBytes:bytesPerRow:fromRegion:mipmapLevel:")
+	sel_newComputePipelineStateWithFunction_error                                                                                     = objc.RegisterName("newComputePipelineStateWithFunction:error:")
+	sel_computeCommandEncoder                                                                                                         = objc.RegisterName("computeCommandEncoder")
+	sel_setComputePipelineState                                                                                                       = objc.RegisterName("setComputePipelineState:")
+	sel_setTexture_atIndex                                                                                                            = objc.RegisterName("setTexture:atIndex:")
+	sel_setBytes_length_atIndex                                                                                                       = objc.RegisterName("setBytes:length:atIndex:")
+	sel_dispatchThreadgroups_threadsPerThreadgroup                                                                                    = objc.RegisterName("dispatchThreadgroups:threadsPerThreadgroup:")
 	sel_respondsToSelector                                                                                                            = objc.RegisterName("respondsToSelector:")
 )
 
@@ -690,6 +720,7 @@ func (d Device) NewRenderPipelineStateWithDescriptor(rpd RenderPipelineDescripto
 	colorAttachments0.Send(sel_setAlphaBlendOperation, uintptr(rpd.ColorAttachments[0].AlphaBlendOperation))
 	colorAttachments0.Send(sel_setRgbBlendOperation, uintptr(rpd.ColorAttachments[0].RGBBlendOperation))
 	colorAttachments0.Send(sel_setWriteMask, uintptr(rpd.ColorAttachments[0].WriteMask))
+	renderPipelineDescriptor.Send(sel_setDepthAttachmentPixelFormat, uintptr(rpd.DepthAttachmentPixelFormat))
 	renderPipelineDescriptor.Send(sel_setStencilAttachmentPixelFormat, uintptr(rpd.StencilAttachmentPixelFormat))
 	var err cocoa.NSError
 	renderPipelineState := d.device.Send(sel_newRenderPipelineStateWithDescriptor_error,
@@ -704,6 +735,22 @@ func (d Device) NewRenderPipelineStateWithDescriptor(rpd RenderPipelineDescripto
 	return RenderPipelineState{renderPipelineState}, nil
 }
 
+// NewComputePipelineStateWithFunction creates a compute pipeline state object.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtldevice/1433395-newcomputepipelinestatewithfunct?language=objc.
+func (d Device) NewComputePipelineStateWithFunction(function Function) (ComputePipelineState, error) {
+	var err cocoa.NSError
+	computePipelineState := d.device.Send(sel_newComputePipelineStateWithFunction_error,
+		function.function,
+		unsafe.Pointer(&err),
+	)
+	if computePipelineState == 0 {
+		return ComputePipelineState{}, errors.New(cocoa.NSString{ID: err.Send(sel_localizedDescription)}.String())
+	}
+
+	return ComputePipelineState{computePipelineState}, nil
+}
+
 // NewBufferWithBytes allocates a new buffer of a given length and initializes its contents by copying existing data into it.
 //
 // Reference: https://developer.apple.com/documentation/metal/mtldevice/1433429-newbufferwithbytes?language=objc.
@@ -741,6 +788,8 @@ func (d Device) NewTextureWithDescriptor(td TextureDescriptor) Texture {
 // Reference: https://developer.apple.com/documentation/metal/mtldevice/1433412-newdepthstencilstatewithdescript?language=objc.
 func (d Device) NewDepthStencilStateWithDescriptor(dsd DepthStencilDescriptor) DepthStencilState {
 	depthStencilDescriptor := objc.ID(class_MTLDepthStencilDescriptor).Send(sel_new)
+	depthStencilDescriptor.Send(sel_setDepthCompareFunction, uintptr(dsd.DepthCompareFunction))
+	depthStencilDescriptor.Send(sel_setDepthWriteEnabled, dsd.DepthWriteEnabled)
 	backFaceStencil := depthStencilDescriptor.Send(sel_backFaceStencil)
 	backFaceStencil.Send(sel_setStencilFailureOperation, uintptr(dsd.BackFaceStencil.StencilFailureOperation))
 	backFaceStencil.Send(sel_setDepthFailureOperation, uintptr(dsd.BackFaceStencil.DepthFailureOperation))
@@ -844,6 +893,21 @@ func (cb CommandBuffer) WaitUntilScheduled() {
 	cb.commandBuffer.Send(sel_waitUntilScheduled)
 }
 
+// GPUEndTime returns the host time, in seconds, when the GPU finished executing the command buffer.
+// GPUEndTime is available as of macOS 10.15 and iOS 10.3.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcommandbuffer/2873107-gpuendtime?language=objc.
+func (cb CommandBuffer) GPUEndTime() float64 {
+	return objc.Send[float64](cb.commandBuffer, sel_GPUEndTime)
+}
+
+// RespondsToSelector returns a Boolean value that indicates whether the receiver implements or inherits a method that can respond to a specified message.
+//
+// Reference: https://developer.apple.com/documentation/objectivec/1418956-nsobject/1418583-respondstoselector?language=objc.
+func (cb CommandBuffer) RespondsToSelector(sel objc.SEL) bool {
+	return cb.commandBuffer.Send(sel_respondsToSelector, sel) != 0
+}
+
 // RenderCommandEncoderWithDescriptor creates a render command encoder from a descriptor.
 //
 // Reference: https://developer.apple.com/documentation/metal/mtlcommandbuffer/1442999-rendercommandencoderwithdescript?language=objc.
@@ -859,6 +923,10 @@ func (cb CommandBuffer) RenderCommandEncoderWithDescriptor(rpd RenderPassDescrip
 	inv.SetSelector(sel_setClearColor)
 	inv.SetArgumentAtIndex(unsafe.Pointer(&rpd.ColorAttachments[0].ClearColor), 2)
 	inv.Invoke()
+	var depthAttachment = renderPassDescriptor.Send(sel_depthAttachment)
+	depthAttachment.Send(sel_setLoadAction, int(rpd.DepthAttachment.LoadAction))
+	depthAttachment.Send(sel_setStoreAction, int(rpd.DepthAttachment.StoreAction))
+	depthAttachment.Send(sel_setTexture, rpd.DepthAttachment.Texture.texture)
 	var stencilAttachment = renderPassDescriptor.Send(sel_stencilAttachment)
 	stencilAttachment.Send(sel_setLoadAction, int(rpd.StencilAttachment.LoadAction))
 	stencilAttachment.Send(sel_setStoreAction, int(rpd.StencilAttachment.StoreAction))
@@ -877,6 +945,15 @@ func (cb CommandBuffer) BlitCommandEncoder() BlitCommandEncoder {
 	return BlitCommandEncoder{CommandEncoder{ce}}
 }
 
+// ComputeCommandEncoder creates an encoder object that can encode
+// compute commands into this command buffer.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcommandbuffer/1443044-computecommandencoder?language=objc.
+func (cb CommandBuffer) ComputeCommandEncoder() ComputeCommandEncoder {
+	ce := cb.commandBuffer.Send(sel_computeCommandEncoder)
+	return ComputeCommandEncoder{CommandEncoder{ce}}
+}
+
 // CommandEncoder is an encoder that writes sequential GPU commands
 // into a command buffer.
 //
@@ -967,6 +1044,13 @@ func (rce RenderCommandEncoder) SetDepthStencilState(depthStencilState DepthSten
 	rce.commandEncoder.Send(sel_setDepthStencilState, depthStencilState.depthStencilState)
 }
 
+// SetStencilReferenceValue sets a stencil reference value for both front and back stencil comparison tests.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlrendercommandencoder/1515697-setstencilreferencevalue?language=objc.
+func (rce RenderCommandEncoder) SetStencilReferenceValue(value uint32) {
+	rce.commandEncoder.Send(sel_setStencilReferenceValue, uintptr(value))
+}
+
 // DrawPrimitives renders one instance of primitives using vertex data
 // in contiguous array elements.
 //
@@ -1032,6 +1116,47 @@ func (bce BlitCommandEncoder) CopyFromTexture(sourceTexture Texture, sourceSlice
 	inv.Invoke()
 }
 
+// ComputeCommandEncoder is an encoder that specifies compute commands
+// and executes compute functions.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcomputecommandencoder?language=objc.
+type ComputeCommandEncoder struct {
+	CommandEncoder
+}
+
+// SetComputePipelineState sets the current compute pipeline state object.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcomputecommandencoder/1443140-setcomputepipelinestate?language=objc.
+func (cce ComputeCommandEncoder) SetComputePipelineState(cps ComputePipelineState) {
+	cce.commandEncoder.Send(sel_setComputePipelineState, cps.computePipelineState)
+}
+
+// SetTexture sets a texture for the compute function at an index in the texture argument table.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcomputecommandencoder/1443179-settexture?language=objc.
+func (cce ComputeCommandEncoder) SetTexture(texture Texture, index int) {
+	cce.commandEncoder.Send(sel_setTexture_atIndex, texture.texture, index)
+}
+
+// SetBytes sets a block of data for the compute function.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcomputecommandencoder/1443159-setbytes?language=objc.
+func (cce ComputeCommandEncoder) SetBytes(bytes unsafe.Pointer, length uintptr, index int) {
+	cce.commandEncoder.Send(sel_setBytes_length_atIndex, bytes, length, index)
+}
+
+// DispatchThreadgroups encodes a compute command using a grid aligned to threadgroup boundaries.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcomputecommandencoder/1443138-dispatchthreadgroups?language=objc.
+func (cce ComputeCommandEncoder) DispatchThreadgroups(threadgroupsPerGrid Size, threadsPerThreadgroup Size) {
+	inv := cocoa.NSInvocation_invocationWithMethodSignature(cocoa.NSMethodSignature_signatureWithObjCTypes("v@:{MTLSize=qqq}{MTLSize=qqq}"))
+	inv.SetTarget(cce.commandEncoder)
+	inv.SetSelector(sel_dispatchThreadgroups_threadsPerThreadgroup)
+	inv.SetArgumentAtIndex(unsafe.Pointer(&threadgroupsPerGrid), 2)
+	inv.SetArgumentAtIndex(unsafe.Pointer(&threadsPerThreadgroup), 3)
+	inv.Invoke()
+}
+
 // Library is a collection of compiled graphics or compute functions.
 //
 // Reference: https://developer.apple.com/documentation/metal/mtllibrary?language=objc.
@@ -1074,6 +1199,10 @@ func (t Texture) resource() unsafe.Pointer {
 	return *(*unsafe.Pointer)(unsafe.Pointer(&t.texture))
 }
 
+func (t Texture) Retain() {
+	t.texture.Send(sel_retain)
+}
+
 func (t Texture) Release() {
 	t.texture.Send(sel_release)
 }
@@ -1180,6 +1309,17 @@ func (r RenderPipelineState) Release() {
 	r.renderPipelineState.Send(sel_release)
 }
 
+// ComputePipelineState contains a compiled compute function.
+//
+// Reference: https://developer.apple.com/documentation/metal/mtlcomputepipelinestate?language=objc.
+type ComputePipelineState struct {
+	computePipelineState objc.ID
+}
+
+func (c ComputePipelineState) Release() {
+	c.computePipelineState.Send(sel_release)
+}
+
 // Region is a rectangular block of pixels in an image or texture,
 // defined by its upper-left corner and its size.
 //
@@ -1256,6 +1396,12 @@ func (d DepthStencilState) Release() {
 //
 // Reference: https://developer.apple.com/documentation/metal/mtldepthstencildescriptor?language=objc.
 type DepthStencilDescriptor struct {
+	// DepthCompareFunction is the comparison that is performed between a fragment's depth value and the depth value in the attachment.
+	DepthCompareFunction CompareFunction
+
+	// DepthWriteEnabled is a Boolean value that indicates whether depth values can be written to the depth attachment.
+	DepthWriteEnabled bool
+
 	// BackFaceStencil is the stencil descriptor for back-facing primitives.
 	BackFaceStencil StencilDescriptor
 
@@ -56,9 +56,10 @@ func RegisterPrecompiledLibrary(source []byte, bin []byte) {
 }
 
 type shaderRpsKey struct {
-	blend       graphicsdriver.Blend
-	stencilMode stencilMode
-	screen      bool
+	blend        graphicsdriver.Blend
+	stencilMode  stencilMode
+	depthStencil bool
+	screen       bool
 }
 
 type Shader struct {
@@ -70,6 +71,10 @@ type Shader struct {
 	vs   mtl.Function
 	rpss map[shaderRpsKey]mtl.RenderPipelineState
 
+	// cs and cps are used only for a compute kernel.
+	cs  mtl.Function
+	cps mtl.ComputePipelineState
+
 	libraryPrecompiled bool
 }
 
@@ -93,6 +98,12 @@ func (s *Shader) Dispose() {
 	for _, rps := range s.rpss {
 		rps.Release()
 	}
+	if s.ir.IsCompute() {
+		s.cps.Release()
+		s.cs.Release()
+		s.lib.Release()
+		return
+	}
 	s.vs.Release()
 	s.fs.Release()
 	// Do not release s.lib if this is precompiled. This is a shared precompiled library.
@@ -102,8 +113,16 @@ func (s *Shader) Dispose() {
 }
 
 func (s *Shader) init(device mtl.Device) error {
+	if s.ir.IsCompute() {
+		return s.initCompute(device)
+	}
+
 	var src string
-	if libBin := thePrecompiledLibraries.get(s.ir.SourceHash); len(libBin) > 0 {
+	libBin := thePrecompiledLibraries.get(s.ir.SourceHash)
+	if len(libBin) == 0 {
+		libBin = graphicsdriver.ReadShaderCache(s.ir.SourceHash, graphicsdriver.ShaderCacheMetalLibrarySuffix)
+	}
+	if len(libBin) > 0 {
 		lib, err := device.NewLibraryWithData(libBin)
 		if err != nil {
 			return err
@@ -137,11 +156,34 @@ func (s *Shader) init(device mtl.Device) error {
 	return nil
 }
 
-func (s *Shader) RenderPipelineState(view *view, blend graphicsdriver.Blend, stencilMode stencilMode, screen bool) (mtl.RenderPipelineState, error) {
+func (s *Shader) initCompute(device mtl.Device) error {
+	src := msl.CompileCompute(s.ir)
+	lib, err := device.NewLibraryWithSource(src, mtl.CompileOptions{})
+	if err != nil {
+		return fmt.Errorf("metal: device.MakeLibrary failed: %w, source: %s", err, src)
+	}
+	s.lib = lib
+
+	cs, err := s.lib.NewFunctionWithName(msl.ComputeName)
+	if err != nil {
+		return fmt.Errorf("metal: lib.MakeFunction for compute failed: %w, source: %s", err, src)
+	}
+	s.cs = cs
+
+	cps, err := device.NewComputePipelineStateWithFunction(s.cs)
+	if err != nil {
+		return fmt.Errorf("metal: device.MakeComputePipelineState failed: %w, source: %s", err, src)
+	}
+	s.cps = cps
+	return nil
+}
+
+func (s *Shader) RenderPipelineState(view *view, blend graphicsdriver.Blend, stencilMode stencilMode, depthStencil bool, screen bool) (mtl.RenderPipelineState, error) {
 	key := shaderRpsKey{
-		blend:       blend,
-		stencilMode: stencilMode,
-		screen:      screen,
+		blend:        blend,
+		stencilMode:  stencilMode,
+		depthStencil: depthStencil,
+		screen:       screen,
 	}
 	if rps, ok := s.rpss[key]; ok {
 		return rps, nil
@@ -151,7 +193,10 @@ func (s *Shader) RenderPipelineState(view *view, blend graphicsdriver.Blend, ste
 		VertexFunction:   s.vs,
 		FragmentFunction: s.fs,
 	}
-	if stencilMode != noStencil {
+	if depthStencil {
+		rpld.DepthAttachmentPixelFormat = mtl.PixelFormatDepth32FloatStencil8
+		rpld.StencilAttachmentPixelFormat = mtl.PixelFormatDepth32FloatStencil8
+	} else if stencilMode != noStencil {
 		rpld.StencilAttachmentPixelFormat = mtl.PixelFormatStencil8
 	}
 
@@ -22,6 +22,7 @@ import (
 	"image"
 	"runtime"
 	"sync"
+	"unsafe"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver/opengl/gl"
@@ -57,6 +58,14 @@ func convertBlendFactor(f graphicsdriver.BlendFactor) blendFactor {
 		return gl.ONE_MINUS_DST_ALPHA
 	case graphicsdriver.BlendFactorSourceAlphaSaturated:
 		return gl.SRC_ALPHA_SATURATE
+	case graphicsdriver.BlendFactorSourceColor1:
+		return gl.SRC1_COLOR
+	case graphicsdriver.BlendFactorOneMinusSourceColor1:
+		return gl.ONE_MINUS_SRC1_COLOR
+	case graphicsdriver.BlendFactorSourceAlpha1:
+		return gl.SRC1_ALPHA
+	case graphicsdriver.BlendFactorOneMinusSourceAlpha1:
+		return gl.ONE_MINUS_SRC1_ALPHA
 	default:
 		panic(fmt.Sprintf("opengl: invalid blend factor %d", f))
 	}
@@ -108,8 +117,17 @@ type context struct {
 	lastViewportWidth  int
 	lastViewportHeight int
 	lastBlend          graphicsdriver.Blend
+	lastSRGBEncoding   bool
 	maxTextureSize     int
 	maxTextureSizeOnce sync.Once
+	maxSamples         int
+	maxSamplesOnce     sync.Once
+	maxDrawBuffers     int
+	maxDrawBuffersOnce sync.Once
+	maxAnisotropy      int
+	maxAnisotropyOnce  sync.Once
+	computeAvailable   bool
+	computeOnce        sync.Once
 	initOnce           sync.Once
 }
 
@@ -175,6 +193,91 @@ func (c *context) getMaxTextureSize() int {
 	return c.maxTextureSize
 }
 
+func (c *context) getMaxSamples() int {
+	c.maxSamplesOnce.Do(func() {
+		c.maxSamples = c.ctx.GetInteger(gl.MAX_SAMPLES)
+	})
+	return c.maxSamples
+}
+
+// getMaxAnisotropy returns the maximum anisotropy of texture filtering.
+// getMaxAnisotropy returns 0 if anisotropic filtering is not available.
+func (c *context) getMaxAnisotropy() int {
+	c.maxAnisotropyOnce.Do(func() {
+		n := c.ctx.GetInteger(gl.MAX_TEXTURE_MAX_ANISOTROPY)
+		if n > shaderir.MaxAnisotropy {
+			n = shaderir.MaxAnisotropy
+		}
+		c.maxAnisotropy = n
+	})
+	return c.maxAnisotropy
+}
+
+// setTextureFilterAnisotropic sets the filter of the bound texture.
+// If anisotropic is false, the filter is nearest, which is the default filter for all the textures.
+func (c *context) setTextureFilterAnisotropic(anisotropic bool) {
+	if !anisotropic {
+		c.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		c.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		if c.getMaxAnisotropy() > 0 {
+			c.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY, 1)
+		}
+		return
+	}
+	c.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	c.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	// Without anisotropic filtering, the texture is still sampled with the linear filter.
+	if n := c.getMaxAnisotropy(); n > 0 {
+		c.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY, int32(n))
+	}
+}
+
+// isComputeAvailable reports whether compute shaders and image load/store are available.
+// Compute shaders require OpenGL 4.3. OpenGL ES 3.1 is not used since image load/store requires immutable textures there.
+func (c *context) isComputeAvailable() bool {
+	c.computeOnce.Do(func() {
+		if c.ctx.IsES() {
+			return
+		}
+		major := c.ctx.GetInteger(gl.MAJOR_VERSION)
+		minor := c.ctx.GetInteger(gl.MINOR_VERSION)
+		c.computeAvailable = major > 4 || (major == 4 && minor >= 3)
+	})
+	return c.computeAvailable
+}
+
+// getMaxDrawBuffers returns the maximum number of the color attachments that can be rendered at once.
+func (c *context) getMaxDrawBuffers() int {
+	c.maxDrawBuffersOnce.Do(func() {
+		n := c.ctx.GetInteger(gl.MAX_DRAW_BUFFERS)
+		if m := c.ctx.GetInteger(gl.MAX_COLOR_ATTACHMENTS); n > m {
+			n = m
+		}
+		c.maxDrawBuffers = n
+	})
+	return c.maxDrawBuffers
+}
+
+// attachAdditionalColorTextures attaches the textures to the currently bound framebuffer as the color attachments
+// from COLOR_ATTACHMENT1, and enables rendering to all the color attachments.
+func (c *context) attachAdditionalColorTextures(textures []textureNative) {
+	bufs := []uint32{gl.COLOR_ATTACHMENT0}
+	for i, t := range textures {
+		attachment := gl.COLOR_ATTACHMENT0 + uint32(i) + 1
+		c.ctx.FramebufferTexture2D(gl.FRAMEBUFFER, attachment, gl.TEXTURE_2D, uint32(t), 0)
+		bufs = append(bufs, attachment)
+	}
+	c.ctx.DrawBuffers(bufs)
+}
+
+// detachAdditionalColorTextures detaches the textures attached by attachAdditionalColorTextures from the currently bound framebuffer.
+func (c *context) detachAdditionalColorTextures(count int) {
+	for i := 0; i < count; i++ {
+		c.ctx.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0+uint32(i)+1, gl.TEXTURE_2D, 0, 0)
+	}
+	c.ctx.DrawBuffers([]uint32{gl.COLOR_ATTACHMENT0})
+}
+
 func (c *context) reset() error {
 	var err1 error
 	c.initOnce.Do(func() {
@@ -194,15 +297,36 @@ func (c *context) reset() error {
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
 	c.lastBlend = graphicsdriver.Blend{}
+	c.lastSRGBEncoding = false
 
 	c.ctx.Enable(gl.BLEND)
 	c.ctx.Enable(gl.SCISSOR_TEST)
+	if !c.ctx.IsES() {
+		c.ctx.Disable(gl.FRAMEBUFFER_SRGB)
+	}
 	c.blend(graphicsdriver.BlendSourceOver)
 	c.screenFramebuffer = framebufferNative(c.ctx.GetInteger(gl.FRAMEBUFFER_BINDING))
 	// TODO: Need to update screenFramebufferWidth/Height?
 	return nil
 }
 
+// setSRGBEncoding sets whether the output to a framebuffer with an sRGB format is encoded to sRGB.
+func (c *context) setSRGBEncoding(enabled bool) {
+	// In OpenGL ES, the output to a framebuffer with an sRGB format is always encoded.
+	if c.ctx.IsES() {
+		return
+	}
+	if c.lastSRGBEncoding == enabled {
+		return
+	}
+	c.lastSRGBEncoding = enabled
+	if enabled {
+		c.ctx.Enable(gl.FRAMEBUFFER_SRGB)
+	} else {
+		c.ctx.Disable(gl.FRAMEBUFFER_SRGB)
+	}
+}
+
 func (c *context) blend(blend graphicsdriver.Blend) {
 	if c.lastBlend == blend {
 		return
@@ -220,7 +344,29 @@ func (c *context) blend(blend graphicsdriver.Blend) {
 	)
 }
 
-func (c *context) newTexture(width, height int) (textureNative, error) {
+func (c *context) newTexture(width, height int, format graphicsdriver.PixelFormat) (textureNative, error) {
+	var internalFormat int32
+	var xtype uint32
+	switch format {
+	case graphicsdriver.PixelFormatRGBA8:
+		internalFormat = gl.RGBA
+		xtype = gl.UNSIGNED_BYTE
+	case graphicsdriver.PixelFormatRGBA16F:
+		internalFormat = gl.RGBA16F
+		xtype = gl.FLOAT
+	case graphicsdriver.PixelFormatRGBA32F:
+		internalFormat = gl.RGBA32F
+		xtype = gl.FLOAT
+	case graphicsdriver.PixelFormatRGBA16:
+		internalFormat = gl.RGBA16
+		xtype = gl.UNSIGNED_SHORT
+	case graphicsdriver.PixelFormatSRGBA8:
+		internalFormat = gl.SRGB8_ALPHA8
+		xtype = gl.UNSIGNED_BYTE
+	default:
+		return 0, fmt.Errorf("opengl: unexpected pixel format: %s", format)
+	}
+
 	t := c.ctx.CreateTexture()
 	if t <= 0 {
 		return 0, errors.New("opengl: creating texture failed")
@@ -242,34 +388,71 @@ func (c *context) newTexture(width, height int) (textureNative, error) {
 	// avoided.
 	//
 	// See also https://stackoverflow.com/questions/57734645.
-	c.ctx.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	c.ctx.TexImage2D(gl.TEXTURE_2D, 0, internalFormat, int32(width), int32(height), gl.RGBA, xtype, nil)
 
 	return textureNative(t), nil
 }
 
-func (c *context) framebufferPixels(buf []byte, f *framebuffer, region image.Rectangle) error {
-	if got, want := len(buf), 4*region.Dx()*region.Dy(); got != want {
+func (c *context) framebufferPixels(buf []byte, f *framebuffer, region image.Rectangle, format graphicsdriver.PixelFormat) error {
+	if got, want := len(buf), format.BytesPerPixel()*region.Dx()*region.Dy(); got != want {
 		return fmt.Errorf("opengl: len(buf) must be %d but was %d at framebufferPixels", got, want)
 	}
 
+	xtype := uint32(gl.UNSIGNED_BYTE)
+	if format == graphicsdriver.PixelFormatRGBA16 {
+		xtype = gl.UNSIGNED_SHORT
+	}
+
 	c.ctx.Flush()
 	c.bindFramebuffer(f.native)
 	x := int32(region.Min.X)
 	y := int32(region.Min.Y)
 	width := int32(region.Dx())
 	height := int32(region.Dy())
-	c.ctx.ReadPixels(buf, x, y, width, height, gl.RGBA, gl.UNSIGNED_BYTE)
+	c.ctx.ReadPixels(buf, x, y, width, height, gl.RGBA, xtype)
 	return nil
 }
 
-func (c *context) framebufferPixelsToBuffer(f *framebuffer, buffer buffer, width, height int) {
-	c.ctx.Flush()
+func (c *context) framebufferFloatPixels(buf []float32, f *framebuffer, region image.Rectangle) error {
+	if got, want := len(buf), 4*region.Dx()*region.Dy(); got != want {
+		return fmt.Errorf("opengl: len(buf) must be %d but was %d at framebufferFloatPixels", got, want)
+	}
 
+	c.ctx.Flush()
 	c.bindFramebuffer(f.native)
+	x := int32(region.Min.X)
+	y := int32(region.Min.Y)
+	width := int32(region.Dx())
+	height := int32(region.Dy())
+	c.ctx.ReadPixels(unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*4), x, y, width, height, gl.RGBA, gl.FLOAT)
+	return nil
+}
+
+// framebufferPixelsToBuffer starts reading the pixels of the region into a new pixel pack buffer and returns the buffer.
+// The pixels of an image with a floating point format are stored as 32-bit floating point values.
+func (c *context) framebufferPixelsToBuffer(f *framebuffer, region image.Rectangle, format graphicsdriver.PixelFormat) buffer {
+	xtype := uint32(gl.UNSIGNED_BYTE)
+	bpp := format.BytesPerPixel()
+	switch {
+	case format == graphicsdriver.PixelFormatRGBA16:
+		xtype = gl.UNSIGNED_SHORT
+	case format.IsFloat():
+		xtype = gl.FLOAT
+		bpp = 4 * 4
+	}
 
-	c.ctx.BindBuffer(gl.PIXEL_PACK_BUFFER, uint32(buffer))
-	c.ctx.ReadPixels(nil, 0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE)
+	b := c.ctx.CreateBuffer()
+	c.ctx.BindBuffer(gl.PIXEL_PACK_BUFFER, b)
+	c.ctx.BufferInit(gl.PIXEL_PACK_BUFFER, bpp*region.Dx()*region.Dy(), gl.STREAM_READ)
+
+	c.bindFramebuffer(f.native)
+	x := int32(region.Min.X)
+	y := int32(region.Min.Y)
+	width := int32(region.Dx())
+	height := int32(region.Dy())
+	c.ctx.ReadPixels(nil, x, y, width, height, gl.RGBA, xtype)
 	c.ctx.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+	return buffer(b)
 }
 
 func (c *context) deleteTexture(t textureNative) {
@@ -279,7 +462,7 @@ func (c *context) deleteTexture(t textureNative) {
 	c.ctx.DeleteTexture(uint32(t))
 }
 
-func (c *context) newRenderbuffer(width, height int) (renderbufferNative, error) {
+func (c *context) newRenderbuffer(width, height int, samples int) (renderbufferNative, error) {
 	r := c.ctx.CreateRenderbuffer()
 	if r <= 0 {
 		return 0, errors.New("opengl: creating renderbuffer failed")
@@ -311,11 +494,36 @@ func (c *context) newRenderbuffer(width, height int) (renderbufferNative, error)
 		// > Stencil formats can only be used for Textures if OpenGL 4.4 or ARB_texture_stencil8 is available.
 		stencilFormat = gl.DEPTH24_STENCIL8
 	}
-	c.ctx.RenderbufferStorage(gl.RENDERBUFFER, stencilFormat, int32(width), int32(height))
+	c.renderbufferStorage(stencilFormat, width, height, samples)
 
 	return renderbuffer, nil
 }
 
+func (c *context) newDepthStencilRenderbuffer(width, height int, samples int) (renderbufferNative, error) {
+	r := c.ctx.CreateRenderbuffer()
+	if r <= 0 {
+		return 0, errors.New("opengl: creating renderbuffer failed")
+	}
+
+	renderbuffer := renderbufferNative(r)
+	c.bindRenderbuffer(renderbuffer)
+
+	// GL_DEPTH24_STENCIL8 is available with OpenGL ES 3.0 and WebGL 2 as well.
+	c.renderbufferStorage(gl.DEPTH24_STENCIL8, width, height, samples)
+
+	return renderbuffer, nil
+}
+
+// renderbufferStorage allocates the storage of the bound renderbuffer.
+// If samples is more than 1, the storage is multisampled.
+func (c *context) renderbufferStorage(internalFormat uint32, width, height int, samples int) {
+	if samples > 1 {
+		c.ctx.RenderbufferStorageMultisample(gl.RENDERBUFFER, int32(samples), internalFormat, int32(width), int32(height))
+		return
+	}
+	c.ctx.RenderbufferStorage(gl.RENDERBUFFER, internalFormat, int32(width), int32(height))
+}
+
 func (c *context) deleteRenderbuffer(r renderbufferNative) {
 	if c.lastRenderbuffer == r {
 		c.lastRenderbuffer = 0
@@ -351,6 +559,76 @@ func (c *context) newFramebuffer(texture textureNative, width, height int) (*fra
 	}, nil
 }
 
+// newMultisampledFramebuffer creates a framebuffer with a multisampled color renderbuffer.
+func (c *context) newMultisampledFramebuffer(width, height int, samples int, format graphicsdriver.PixelFormat) (*framebuffer, renderbufferNative, error) {
+	var internalFormat uint32
+	switch format {
+	case graphicsdriver.PixelFormatRGBA8:
+		internalFormat = gl.RGBA8
+	case graphicsdriver.PixelFormatRGBA16F:
+		internalFormat = gl.RGBA16F
+	case graphicsdriver.PixelFormatRGBA32F:
+		internalFormat = gl.RGBA32F
+	case graphicsdriver.PixelFormatRGBA16:
+		internalFormat = gl.RGBA16
+	case graphicsdriver.PixelFormatSRGBA8:
+		internalFormat = gl.SRGB8_ALPHA8
+	default:
+		return nil, 0, fmt.Errorf("opengl: unexpected pixel format: %s", format)
+	}
+
+	r := c.ctx.CreateRenderbuffer()
+	if r <= 0 {
+		return nil, 0, errors.New("opengl: creating renderbuffer failed")
+	}
+	renderbuffer := renderbufferNative(r)
+	c.bindRenderbuffer(renderbuffer)
+	c.renderbufferStorage(internalFormat, width, height, samples)
+
+	f := c.ctx.CreateFramebuffer()
+	if f <= 0 {
+		c.deleteRenderbuffer(renderbuffer)
+		return nil, 0, fmt.Errorf("opengl: creating framebuffer failed: the returned value is not positive but %d", f)
+	}
+	c.bindFramebuffer(framebufferNative(f))
+
+	c.ctx.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, r)
+
+	if shouldCheckFramebufferStatus() {
+		if s := c.ctx.CheckFramebufferStatus(gl.FRAMEBUFFER); s != gl.FRAMEBUFFER_COMPLETE {
+			c.deleteFramebuffer(framebufferNative(f))
+			c.deleteRenderbuffer(renderbuffer)
+			return nil, 0, fmt.Errorf("opengl: creating multisampled framebuffer failed: %v", s)
+		}
+	}
+
+	return &framebuffer{
+		native:         framebufferNative(f),
+		viewportWidth:  width,
+		viewportHeight: height,
+	}, renderbuffer, nil
+}
+
+// blitFramebuffer copies the whole pixels of the framebuffer src to the framebuffer dst.
+// If src is multisampled, the samples are resolved.
+// blitFramebuffer copies the pixels in srcRegion of src to dst at dstPoint.
+func (c *context) blitFramebuffer(src, dst *framebuffer, dstPoint image.Point, srcRegion image.Rectangle) {
+	c.ctx.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(src.native))
+	c.ctx.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(dst.native))
+
+	// glBlitFramebuffer is affected by the scissor test.
+	c.ctx.Disable(gl.SCISSOR_TEST)
+	dstRegion := srcRegion.Sub(srcRegion.Min).Add(dstPoint)
+	c.ctx.BlitFramebuffer(
+		int32(srcRegion.Min.X), int32(srcRegion.Min.Y), int32(srcRegion.Max.X), int32(srcRegion.Max.Y),
+		int32(dstRegion.Min.X), int32(dstRegion.Min.Y), int32(dstRegion.Max.X), int32(dstRegion.Max.Y),
+		gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	c.ctx.Enable(gl.SCISSOR_TEST)
+
+	c.ctx.BindFramebuffer(gl.FRAMEBUFFER, uint32(dst.native))
+	c.lastFramebuffer = dst.native
+}
+
 func (c *context) bindStencilBuffer(f framebufferNative, r renderbufferNative) error {
 	c.bindFramebuffer(f)
 
@@ -365,6 +643,21 @@ func (c *context) bindStencilBuffer(f framebufferNative, r renderbufferNative) e
 	return nil
 }
 
+func (c *context) bindDepthStencilBuffer(f framebufferNative, r renderbufferNative) error {
+	c.bindFramebuffer(f)
+
+	c.ctx.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, uint32(r))
+	c.ctx.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.STENCIL_ATTACHMENT, gl.RENDERBUFFER, uint32(r))
+
+	if shouldCheckFramebufferStatus() {
+		if s := c.ctx.CheckFramebufferStatus(gl.FRAMEBUFFER); s != gl.FRAMEBUFFER_COMPLETE {
+			return fmt.Errorf("opengl: glFramebufferRenderbuffer failed: %d", s)
+		}
+	}
+
+	return nil
+}
+
 func (c *context) deleteFramebuffer(f framebufferNative) {
 	if f == c.screenFramebuffer {
 		return
@@ -392,7 +685,10 @@ func (c *context) newShader(shaderType uint32, source string) (shader, error) {
 	return shader(s), nil
 }
 
-func (c *context) newProgram(shaders []shader, attributes []string) (program, error) {
+// newProgram creates a program with the given shaders.
+// If dualSource is true, the fragment shader's second output is bound as the secondary color for dual-source blending.
+// If renderTargetCount is more than 1, the fragment shader's outputs are bound to the color attachments respectively.
+func (c *context) newProgram(shaders []shader, attributes []string, dualSource bool, renderTargetCount int) (program, error) {
 	p := c.ctx.CreateProgram()
 	if p == 0 {
 		return 0, errors.New("opengl: glCreateProgram failed")
@@ -406,6 +702,19 @@ func (c *context) newProgram(shaders []shader, attributes []string) (program, er
 		c.ctx.BindAttribLocation(p, uint32(i), name)
 	}
 
+	if dualSource {
+		c.ctx.BindFragDataLocationIndexed(p, 0, 0, "fragColor")
+		c.ctx.BindFragDataLocationIndexed(p, 0, 1, "fragColor1")
+	}
+
+	// For OpenGL ES, the locations are specified in the shader.
+	if renderTargetCount > 1 && !c.ctx.IsES() {
+		c.ctx.BindFragDataLocation(p, 0, "fragColor")
+		for i := 1; i < renderTargetCount; i++ {
+			c.ctx.BindFragDataLocation(p, uint32(i), fmt.Sprintf("fragColor%d", i))
+		}
+	}
+
 	c.ctx.LinkProgram(p)
 	return program(p), nil
 }
@@ -17,74 +17,121 @@
 package gl
 
 const (
-	ALWAYS                = 0x0207
-	ARRAY_BUFFER          = 0x8892
-	BACK                  = 0x0405
-	BLEND                 = 0x0BE2
-	CLAMP_TO_EDGE         = 0x812F
-	COLOR_ATTACHMENT0     = 0x8CE0
-	COMPILE_STATUS        = 0x8B81
-	DECR_WRAP             = 0x8508
-	DEPTH24_STENCIL8      = 0x88F0
-	DST_ALPHA             = 0x0304
-	DST_COLOR             = 0x0306
-	DYNAMIC_DRAW          = 0x88E8
-	ELEMENT_ARRAY_BUFFER  = 0x8893
-	FALSE                 = 0
-	FLOAT                 = 0x1406
-	FRAGMENT_SHADER       = 0x8B30
-	FRAMEBUFFER           = 0x8D40
-	FRAMEBUFFER_BINDING   = 0x8CA6
-	FRAMEBUFFER_COMPLETE  = 0x8CD5
-	FRONT                 = 0x0404
-	FRONT_AND_BACK        = 0x0408
-	FUNC_ADD              = 0x8006
-	FUNC_REVERSE_SUBTRACT = 0x800b
-	FUNC_SUBTRACT         = 0x800a
-	HIGH_FLOAT            = 0x8DF2
-	INCR_WRAP             = 0x8507
-	INFO_LOG_LENGTH       = 0x8B84
-	INVERT                = 0x150A
-	KEEP                  = 0x1E00
-	LINK_STATUS           = 0x8B82
-	MAX                   = 0x8008
-	MAX_TEXTURE_SIZE      = 0x0D33
-	MIN                   = 0x8007
-	NEAREST               = 0x2600
-	NO_ERROR              = 0
-	NOTEQUAL              = 0x0205
-	ONE                   = 1
-	ONE_MINUS_DST_ALPHA   = 0x0305
-	ONE_MINUS_DST_COLOR   = 0x0307
-	ONE_MINUS_SRC_ALPHA   = 0x0303
-	ONE_MINUS_SRC_COLOR   = 0x0301
-	PIXEL_PACK_BUFFER     = 0x88EB
-	PIXEL_UNPACK_BUFFER   = 0x88EC
-	READ_WRITE            = 0x88BA
-	RENDERBUFFER          = 0x8D41
-	RGBA                  = 0x1908
-	SCISSOR_TEST          = 0x0C11
-	SHORT                 = 0x1402
-	SRC_ALPHA             = 0x0302
-	SRC_ALPHA_SATURATE    = 0x0308
-	SRC_COLOR             = 0x0300
-	STENCIL_ATTACHMENT    = 0x8D20
-	STENCIL_BUFFER_BIT    = 0x0400
-	STENCIL_INDEX8        = 0x8D48
-	STENCIL_TEST          = 0x0B90
-	STREAM_DRAW           = 0x88E0
-	TEXTURE0              = 0x84C0
-	TEXTURE_2D            = 0x0DE1
-	TEXTURE_MAG_FILTER    = 0x2800
-	TEXTURE_MIN_FILTER    = 0x2801
-	TEXTURE_WRAP_S        = 0x2802
-	TEXTURE_WRAP_T        = 0x2803
-	TRIANGLES             = 0x0004
-	TRUE                  = 1
-	UNPACK_ALIGNMENT      = 0x0CF5
-	UNSIGNED_BYTE         = 0x1401
-	UNSIGNED_INT          = 0x1405
-	VERTEX_SHADER         = 0x8B31
-	WRITE_ONLY            = 0x88B9
-	ZERO                  = 0
+	ALL_BARRIER_BITS           = 0xFFFFFFFF
+	ALWAYS                     = 0x0207
+	ALREADY_SIGNALED           = 0x911A
+	ANY_SAMPLES_PASSED         = 0x8C2F
+	ARRAY_BUFFER               = 0x8892
+	BACK                       = 0x0405
+	BLEND                      = 0x0BE2
+	CLAMP_TO_EDGE              = 0x812F
+	COLOR_ATTACHMENT0          = 0x8CE0
+	COLOR_BUFFER_BIT           = 0x4000
+	COMPILE_STATUS             = 0x8B81
+	COMPUTE_SHADER             = 0x91B9
+	CONDITION_SATISFIED        = 0x911C
+	DECR                       = 0x1E03
+	DECR_WRAP                  = 0x8508
+	DEPTH24_STENCIL8           = 0x88F0
+	DEPTH_ATTACHMENT           = 0x8D00
+	DEPTH_BUFFER_BIT           = 0x0100
+	DEPTH_TEST                 = 0x0B71
+	DRAW_FRAMEBUFFER           = 0x8CA9
+	DST_ALPHA                  = 0x0304
+	DST_COLOR                  = 0x0306
+	DYNAMIC_DRAW               = 0x88E8
+	ELEMENT_ARRAY_BUFFER       = 0x8893
+	EQUAL                      = 0x0202
+	FALSE                      = 0
+	FLOAT                      = 0x1406
+	FRAGMENT_SHADER            = 0x8B30
+	FRAMEBUFFER                = 0x8D40
+	FRAMEBUFFER_BINDING        = 0x8CA6
+	FRAMEBUFFER_COMPLETE       = 0x8CD5
+	FRAMEBUFFER_SRGB           = 0x8DB9
+	FRONT                      = 0x0404
+	FRONT_AND_BACK             = 0x0408
+	FUNC_ADD                   = 0x8006
+	FUNC_REVERSE_SUBTRACT      = 0x800b
+	FUNC_SUBTRACT              = 0x800a
+	GEQUAL                     = 0x0206
+	GREATER                    = 0x0204
+	HIGH_FLOAT                 = 0x8DF2
+	INCR                       = 0x1E02
+	INCR_WRAP                  = 0x8507
+	INFO_LOG_LENGTH            = 0x8B84
+	INVERT                     = 0x150A
+	KEEP                       = 0x1E00
+	LEQUAL                     = 0x0203
+	LESS                       = 0x0201
+	LINEAR                     = 0x2601
+	LINK_STATUS                = 0x8B82
+	MAJOR_VERSION              = 0x821B
+	MAP_READ_BIT               = 0x0001
+	MAX                        = 0x8008
+	MAX_COLOR_ATTACHMENTS      = 0x8CDF
+	MAX_DRAW_BUFFERS           = 0x8824
+	MAX_SAMPLES                = 0x8D57
+	MAX_TEXTURE_MAX_ANISOTROPY = 0x84FF
+	MAX_TEXTURE_SIZE           = 0x0D33
+	MIN                        = 0x8007
+	MINOR_VERSION              = 0x821C
+	NEAREST                    = 0x2600
+	NEVER                      = 0x0200
+	NO_ERROR                   = 0
+	NOTEQUAL                   = 0x0205
+	ONE                        = 1
+	ONE_MINUS_DST_ALPHA        = 0x0305
+	ONE_MINUS_DST_COLOR        = 0x0307
+	ONE_MINUS_SRC1_ALPHA       = 0x88FB
+	ONE_MINUS_SRC1_COLOR       = 0x88FA
+	ONE_MINUS_SRC_ALPHA        = 0x0303
+	ONE_MINUS_SRC_COLOR        = 0x0301
+	PIXEL_PACK_BUFFER          = 0x88EB
+	PIXEL_UNPACK_BUFFER        = 0x88EC
+	QUERY_RESULT               = 0x8866
+	QUERY_RESULT_AVAILABLE     = 0x8867
+	READ_FRAMEBUFFER           = 0x8CA8
+	READ_WRITE                 = 0x88BA
+	RENDERBUFFER               = 0x8D41
+	REPLACE                    = 0x1E01
+	RGBA                       = 0x1908
+	RGBA16                     = 0x805B
+	RGBA16F                    = 0x881A
+	RGBA32F                    = 0x8814
+	RGBA8                      = 0x8058
+	SAMPLES_PASSED             = 0x8914
+	SCISSOR_TEST               = 0x0C11
+	SHORT                      = 0x1402
+	SRC1_ALPHA                 = 0x8589
+	SRGB8_ALPHA8               = 0x8C43
+	SRC1_COLOR                 = 0x88F9
+	SRC_ALPHA                  = 0x0302
+	SRC_ALPHA_SATURATE         = 0x0308
+	SRC_COLOR                  = 0x0300
+	STENCIL_ATTACHMENT         = 0x8D20
+	STENCIL_BUFFER_BIT         = 0x0400
+	STENCIL_INDEX8             = 0x8D48
+	STENCIL_TEST               = 0x0B90
+	STREAM_DRAW                = 0x88E0
+	STREAM_READ                = 0x88E1
+	SYNC_GPU_COMMANDS_COMPLETE = 0x9117
+	TEXTURE0                   = 0x84C0
+	TEXTURE_2D                 = 0x0DE1
+	TEXTURE_MAG_FILTER         = 0x2800
+	TEXTURE_MAX_ANISOTROPY     = 0x84FE
+	TEXTURE_MIN_FILTER         = 0x2801
+	TEXTURE_WRAP_S             = 0x2802
+	TEXTURE_WRAP_T             = 0x2803
+	TIMESTAMP                  = 0x8E28
+	TRIANGLES                  = 0x0004
+	TRUE                       = 1
+	UNPACK_ALIGNMENT           = 0x0CF5
+	UNSIGNED_BYTE              = 0x1401
+	UNSIGNED_INT               = 0x1405
+	UNSIGNED_SHORT             = 0x1403
+	VERTEX_SHADER              = 0x8B31
+	WAIT_FAILED                = 0x911D
+	WRITE_ONLY                 = 0x88B9
+	ZERO                       = 0
 )
@@ -45,6 +45,14 @@ func (d *DebugContext) AttachShader(arg0 uint32, arg1 uint32) {
 	}
 }
 
+func (d *DebugContext) BeginQuery(arg0 uint32, arg1 uint32) {
+	d.Context.BeginQuery(arg0, arg1)
+	fmt.Fprintln(os.Stderr, "BeginQuery")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at BeginQuery", e))
+	}
+}
+
 func (d *DebugContext) BindAttribLocation(arg0 uint32, arg1 uint32, arg2 string) {
 	d.Context.BindAttribLocation(arg0, arg1, arg2)
 	fmt.Fprintln(os.Stderr, "BindAttribLocation")
@@ -61,6 +69,22 @@ func (d *DebugContext) BindBuffer(arg0 uint32, arg1 uint32) {
 	}
 }
 
+func (d *DebugContext) BindFragDataLocation(arg0 uint32, arg1 uint32, arg2 string) {
+	d.Context.BindFragDataLocation(arg0, arg1, arg2)
+	fmt.Fprintln(os.Stderr, "BindFragDataLocation")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at BindFragDataLocation", e))
+	}
+}
+
+func (d *DebugContext) BindFragDataLocationIndexed(arg0 uint32, arg1 uint32, arg2 uint32, arg3 string) {
+	d.Context.BindFragDataLocationIndexed(arg0, arg1, arg2, arg3)
+	fmt.Fprintln(os.Stderr, "BindFragDataLocationIndexed")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at BindFragDataLocationIndexed", e))
+	}
+}
+
 func (d *DebugContext) BindFramebuffer(arg0 uint32, arg1 uint32) {
 	d.Context.BindFramebuffer(arg0, arg1)
 	fmt.Fprintln(os.Stderr, "BindFramebuffer")
@@ -69,6 +93,14 @@ func (d *DebugContext) BindFramebuffer(arg0 uint32, arg1 uint32) {
 	}
 }
 
+func (d *DebugContext) BindImageTexture(arg0 uint32, arg1 uint32, arg2 int32, arg3 bool, arg4 int32, arg5 uint32, arg6 uint32) {
+	d.Context.BindImageTexture(arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	fmt.Fprintln(os.Stderr, "BindImageTexture")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at BindImageTexture", e))
+	}
+}
+
 func (d *DebugContext) BindRenderbuffer(arg0 uint32, arg1 uint32) {
 	d.Context.BindRenderbuffer(arg0, arg1)
 	fmt.Fprintln(os.Stderr, "BindRenderbuffer")
@@ -109,6 +141,14 @@ func (d *DebugContext) BlendFuncSeparate(arg0 uint32, arg1 uint32, arg2 uint32,
 	}
 }
 
+func (d *DebugContext) BlitFramebuffer(arg0 int32, arg1 int32, arg2 int32, arg3 int32, arg4 int32, arg5 int32, arg6 int32, arg7 int32, arg8 uint32, arg9 uint32) {
+	d.Context.BlitFramebuffer(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
+	fmt.Fprintln(os.Stderr, "BlitFramebuffer")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at BlitFramebuffer", e))
+	}
+}
+
 func (d *DebugContext) BufferInit(arg0 uint32, arg1 int, arg2 uint32) {
 	d.Context.BufferInit(arg0, arg1, arg2)
 	fmt.Fprintln(os.Stderr, "BufferInit")
@@ -142,6 +182,15 @@ func (d *DebugContext) Clear(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) ClientWaitSync(arg0 uintptr, arg1 uint32, arg2 uint64) uint32 {
+	out0 := d.Context.ClientWaitSync(arg0, arg1, arg2)
+	fmt.Fprintln(os.Stderr, "ClientWaitSync")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at ClientWaitSync", e))
+	}
+	return out0
+}
+
 func (d *DebugContext) ColorMask(arg0 bool, arg1 bool, arg2 bool, arg3 bool) {
 	d.Context.ColorMask(arg0, arg1, arg2, arg3)
 	fmt.Fprintln(os.Stderr, "ColorMask")
@@ -185,6 +234,15 @@ func (d *DebugContext) CreateProgram() uint32 {
 	return out0
 }
 
+func (d *DebugContext) CreateQuery() uint32 {
+	out0 := d.Context.CreateQuery()
+	fmt.Fprintln(os.Stderr, "CreateQuery")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at CreateQuery", e))
+	}
+	return out0
+}
+
 func (d *DebugContext) CreateRenderbuffer() uint32 {
 	out0 := d.Context.CreateRenderbuffer()
 	fmt.Fprintln(os.Stderr, "CreateRenderbuffer")
@@ -245,6 +303,14 @@ func (d *DebugContext) DeleteProgram(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) DeleteQuery(arg0 uint32) {
+	d.Context.DeleteQuery(arg0)
+	fmt.Fprintln(os.Stderr, "DeleteQuery")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at DeleteQuery", e))
+	}
+}
+
 func (d *DebugContext) DeleteRenderbuffer(arg0 uint32) {
 	d.Context.DeleteRenderbuffer(arg0)
 	fmt.Fprintln(os.Stderr, "DeleteRenderbuffer")
@@ -261,6 +327,14 @@ func (d *DebugContext) DeleteShader(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) DeleteSync(arg0 uintptr) {
+	d.Context.DeleteSync(arg0)
+	fmt.Fprintln(os.Stderr, "DeleteSync")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at DeleteSync", e))
+	}
+}
+
 func (d *DebugContext) DeleteTexture(arg0 uint32) {
 	d.Context.DeleteTexture(arg0)
 	fmt.Fprintln(os.Stderr, "DeleteTexture")
@@ -277,6 +351,22 @@ func (d *DebugContext) DeleteVertexArray(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) DepthFunc(arg0 uint32) {
+	d.Context.DepthFunc(arg0)
+	fmt.Fprintln(os.Stderr, "DepthFunc")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at DepthFunc", e))
+	}
+}
+
+func (d *DebugContext) DepthMask(arg0 bool) {
+	d.Context.DepthMask(arg0)
+	fmt.Fprintln(os.Stderr, "DepthMask")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at DepthMask", e))
+	}
+}
+
 func (d *DebugContext) Disable(arg0 uint32) {
 	d.Context.Disable(arg0)
 	fmt.Fprintln(os.Stderr, "Disable")
@@ -293,6 +383,22 @@ func (d *DebugContext) DisableVertexAttribArray(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) DispatchCompute(arg0 uint32, arg1 uint32, arg2 uint32) {
+	d.Context.DispatchCompute(arg0, arg1, arg2)
+	fmt.Fprintln(os.Stderr, "DispatchCompute")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at DispatchCompute", e))
+	}
+}
+
+func (d *DebugContext) DrawBuffers(arg0 []uint32) {
+	d.Context.DrawBuffers(arg0)
+	fmt.Fprintln(os.Stderr, "DrawBuffers")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at DrawBuffers", e))
+	}
+}
+
 func (d *DebugContext) DrawElements(arg0 uint32, arg1 int32, arg2 uint32, arg3 int) {
 	d.Context.DrawElements(arg0, arg1, arg2, arg3)
 	fmt.Fprintln(os.Stderr, "DrawElements")
@@ -317,6 +423,23 @@ func (d *DebugContext) EnableVertexAttribArray(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) EndQuery(arg0 uint32) {
+	d.Context.EndQuery(arg0)
+	fmt.Fprintln(os.Stderr, "EndQuery")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at EndQuery", e))
+	}
+}
+
+func (d *DebugContext) FenceSync(arg0 uint32, arg1 uint32) uintptr {
+	out0 := d.Context.FenceSync(arg0, arg1)
+	fmt.Fprintln(os.Stderr, "FenceSync")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at FenceSync", e))
+	}
+	return out0
+}
+
 func (d *DebugContext) Flush() {
 	d.Context.Flush()
 	fmt.Fprintln(os.Stderr, "Flush")
@@ -341,6 +464,14 @@ func (d *DebugContext) FramebufferTexture2D(arg0 uint32, arg1 uint32, arg2 uint3
 	}
 }
 
+func (d *DebugContext) GetBufferSubData(arg0 uint32, arg1 int, arg2 []uint8) {
+	d.Context.GetBufferSubData(arg0, arg1, arg2)
+	fmt.Fprintln(os.Stderr, "GetBufferSubData")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at GetBufferSubData", e))
+	}
+}
+
 func (d *DebugContext) GetError() uint32 {
 	out0 := d.Context.GetError()
 	fmt.Fprintln(os.Stderr, "GetError")
@@ -374,6 +505,24 @@ func (d *DebugContext) GetProgrami(arg0 uint32, arg1 uint32) int {
 	return out0
 }
 
+func (d *DebugContext) GetQueryObjectui(arg0 uint32, arg1 uint32) uint32 {
+	out0 := d.Context.GetQueryObjectui(arg0, arg1)
+	fmt.Fprintln(os.Stderr, "GetQueryObjectui")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at GetQueryObjectui", e))
+	}
+	return out0
+}
+
+func (d *DebugContext) GetQueryObjectui64(arg0 uint32, arg1 uint32) uint64 {
+	out0 := d.Context.GetQueryObjectui64(arg0, arg1)
+	fmt.Fprintln(os.Stderr, "GetQueryObjectui64")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at GetQueryObjectui64", e))
+	}
+	return out0
+}
+
 func (d *DebugContext) GetShaderInfoLog(arg0 uint32) string {
 	out0 := d.Context.GetShaderInfoLog(arg0)
 	fmt.Fprintln(os.Stderr, "GetShaderInfoLog")
@@ -415,6 +564,11 @@ func (d *DebugContext) IsProgram(arg0 uint32) bool {
 	return out0
 }
 
+func (d *DebugContext) IsTimerQueryAvailable() bool {
+	out0 := d.Context.IsTimerQueryAvailable()
+	return out0
+}
+
 func (d *DebugContext) LinkProgram(arg0 uint32) {
 	d.Context.LinkProgram(arg0)
 	fmt.Fprintln(os.Stderr, "LinkProgram")
@@ -423,6 +577,14 @@ func (d *DebugContext) LinkProgram(arg0 uint32) {
 	}
 }
 
+func (d *DebugContext) MemoryBarrier(arg0 uint32) {
+	d.Context.MemoryBarrier(arg0)
+	fmt.Fprintln(os.Stderr, "MemoryBarrier")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at MemoryBarrier", e))
+	}
+}
+
 func (d *DebugContext) LoadFunctions() error {
 	out0 := d.Context.LoadFunctions()
 	return out0
@@ -436,6 +598,14 @@ func (d *DebugContext) PixelStorei(arg0 uint32, arg1 int32) {
 	}
 }
 
+func (d *DebugContext) QueryCounter(arg0 uint32, arg1 uint32) {
+	d.Context.QueryCounter(arg0, arg1)
+	fmt.Fprintln(os.Stderr, "QueryCounter")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at QueryCounter", e))
+	}
+}
+
 func (d *DebugContext) ReadPixels(arg0 []uint8, arg1 int32, arg2 int32, arg3 int32, arg4 int32, arg5 uint32, arg6 uint32) {
 	d.Context.ReadPixels(arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 	fmt.Fprintln(os.Stderr, "ReadPixels")
@@ -452,6 +622,14 @@ func (d *DebugContext) RenderbufferStorage(arg0 uint32, arg1 uint32, arg2 int32,
 	}
 }
 
+func (d *DebugContext) RenderbufferStorageMultisample(arg0 uint32, arg1 int32, arg2 uint32, arg3 int32, arg4 int32) {
+	d.Context.RenderbufferStorageMultisample(arg0, arg1, arg2, arg3, arg4)
+	fmt.Fprintln(os.Stderr, "RenderbufferStorageMultisample")
+	if e := d.Context.GetError(); e != NO_ERROR {
+		panic(fmt.Sprintf("gl: GetError() returned %d at RenderbufferStorageMultisample", e))
+	}
+}
+
 func (d *DebugContext) Scissor(arg0 int32, arg1 int32, arg2 int32, arg3 int32) {
 	d.Context.Scissor(arg0, arg1, arg2, arg3)
 	fmt.Fprintln(os.Stderr, "Scissor")
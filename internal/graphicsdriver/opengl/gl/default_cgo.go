@@ -8,6 +8,7 @@ package gl
 
 // #include <stdint.h>
 // #include <stdlib.h>
+// #include <string.h>
 //
 // typedef unsigned int GLenum;
 // typedef unsigned char GLboolean;
@@ -19,6 +20,8 @@ package gl
 // typedef char GLchar;
 // typedef ptrdiff_t GLintptr;
 // typedef ptrdiff_t GLsizeiptr;
+// typedef uint64_t GLuint64;
+// typedef struct __GLsync* GLsync;
 //
 // static void glowActiveTexture(uintptr_t fnptr, GLenum texture) {
 //   typedef void (*fn)(GLenum texture);
@@ -28,6 +31,10 @@ package gl
 //   typedef void (*fn)(GLuint program, GLuint shader);
 //   ((fn)(fnptr))(program, shader);
 // }
+// static void glowBeginQuery(uintptr_t fnptr, GLenum target, GLuint id) {
+//   typedef void (*fn)(GLenum target, GLuint id);
+//   ((fn)(fnptr))(target, id);
+// }
 // static void glowBindAttribLocation(uintptr_t fnptr, GLuint program, GLuint index, const GLchar* name) {
 //   typedef void (*fn)(GLuint program, GLuint index, const GLchar* name);
 //   ((fn)(fnptr))(program, index, name);
@@ -36,10 +43,22 @@ package gl
 //   typedef void (*fn)(GLenum target, GLuint buffer);
 //   ((fn)(fnptr))(target, buffer);
 // }
+// static void glowBindFragDataLocation(uintptr_t fnptr, GLuint program, GLuint colorNumber, const GLchar* name) {
+//   typedef void (*fn)(GLuint program, GLuint colorNumber, const GLchar* name);
+//   ((fn)(fnptr))(program, colorNumber, name);
+// }
+// static void glowBindFragDataLocationIndexed(uintptr_t fnptr, GLuint program, GLuint colorNumber, GLuint index, const GLchar* name) {
+//   typedef void (*fn)(GLuint program, GLuint colorNumber, GLuint index, const GLchar* name);
+//   ((fn)(fnptr))(program, colorNumber, index, name);
+// }
 // static void glowBindFramebuffer(uintptr_t fnptr, GLenum target, GLuint framebuffer) {
 //   typedef void (*fn)(GLenum target, GLuint framebuffer);
 //   ((fn)(fnptr))(target, framebuffer);
 // }
+// static void glowBindImageTexture(uintptr_t fnptr, GLuint unit, GLuint texture, GLint level, GLboolean layered, GLint layer, GLenum access, GLenum format) {
+//   typedef void (*fn)(GLuint unit, GLuint texture, GLint level, GLboolean layered, GLint layer, GLenum access, GLenum format);
+//   ((fn)(fnptr))(unit, texture, level, layered, layer, access, format);
+// }
 // static void glowBindRenderbuffer(uintptr_t fnptr, GLenum target, GLuint renderbuffer) {
 //   typedef void (*fn)(GLenum target, GLuint renderbuffer);
 //   ((fn)(fnptr))(target, renderbuffer);
@@ -60,6 +79,10 @@ package gl
 //   typedef void (*fn)(GLenum srcRGB, GLenum dstRGB, GLenum srcAlpha, GLenum dstAlpha);
 //   ((fn)(fnptr))(srcRGB, dstRGB, srcAlpha, dstAlpha);
 // }
+// static void glowBlitFramebuffer(uintptr_t fnptr, GLint srcX0, GLint srcY0, GLint srcX1, GLint srcY1, GLint dstX0, GLint dstY0, GLint dstX1, GLint dstY1, GLbitfield mask, GLenum filter) {
+//   typedef void (*fn)(GLint srcX0, GLint srcY0, GLint srcX1, GLint srcY1, GLint dstX0, GLint dstY0, GLint dstX1, GLint dstY1, GLbitfield mask, GLenum filter);
+//   ((fn)(fnptr))(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter);
+// }
 // static void glowBufferData(uintptr_t fnptr, GLenum target, GLsizeiptr size, const void* data, GLenum usage) {
 //   typedef void (*fn)(GLenum target, GLsizeiptr size, const void* data, GLenum usage);
 //   ((fn)(fnptr))(target, size, data, usage);
@@ -76,6 +99,10 @@ package gl
 //   typedef void (*fn)(GLbitfield mask);
 //   ((fn)(fnptr))(mask);
 // }
+// static GLenum glowClientWaitSync(uintptr_t fnptr, uintptr_t sync, GLbitfield flags, GLuint64 timeout) {
+//   typedef GLenum (*fn)(GLsync sync, GLbitfield flags, GLuint64 timeout);
+//   return ((fn)(fnptr))((GLsync)sync, flags, timeout);
+// }
 // static void glowColorMask(uintptr_t fnptr, GLboolean red, GLboolean green, GLboolean blue, GLboolean alpha) {
 //   typedef void (*fn)(GLboolean red, GLboolean green, GLboolean blue, GLboolean alpha);
 //   ((fn)(fnptr))(red, green, blue, alpha);
@@ -104,6 +131,10 @@ package gl
 //   typedef void (*fn)(GLuint program);
 //   ((fn)(fnptr))(program);
 // }
+// static void glowDeleteQueries(uintptr_t fnptr, GLsizei n, const GLuint* ids) {
+//   typedef void (*fn)(GLsizei n, const GLuint* ids);
+//   ((fn)(fnptr))(n, ids);
+// }
 // static void glowDeleteRenderbuffers(uintptr_t fnptr, GLsizei n, const GLuint* renderbuffers) {
 //   typedef void (*fn)(GLsizei n, const GLuint* renderbuffers);
 //   ((fn)(fnptr))(n, renderbuffers);
@@ -112,6 +143,10 @@ package gl
 //   typedef void (*fn)(GLuint shader);
 //   ((fn)(fnptr))(shader);
 // }
+// static void glowDeleteSync(uintptr_t fnptr, uintptr_t sync) {
+//   typedef void (*fn)(GLsync sync);
+//   ((fn)(fnptr))((GLsync)sync);
+// }
 // static void glowDeleteTextures(uintptr_t fnptr, GLsizei n, const GLuint* textures) {
 //   typedef void (*fn)(GLsizei n, const GLuint* textures);
 //   ((fn)(fnptr))(n, textures);
@@ -120,6 +155,14 @@ package gl
 //   typedef void (*fn)(GLsizei n, const GLuint* arrays);
 //   ((fn)(fnptr))(n, arrays);
 // }
+// static void glowDepthFunc(uintptr_t fnptr, GLenum func) {
+//   typedef void (*fn)(GLenum func);
+//   ((fn)(fnptr))(func);
+// }
+// static void glowDepthMask(uintptr_t fnptr, GLboolean flag) {
+//   typedef void (*fn)(GLboolean flag);
+//   ((fn)(fnptr))(flag);
+// }
 // static void glowDisable(uintptr_t fnptr, GLenum cap) {
 //   typedef void (*fn)(GLenum cap);
 //   ((fn)(fnptr))(cap);
@@ -128,6 +171,14 @@ package gl
 //   typedef void (*fn)(GLuint index);
 //   ((fn)(fnptr))(index);
 // }
+// static void glowDispatchCompute(uintptr_t fnptr, GLuint num_groups_x, GLuint num_groups_y, GLuint num_groups_z) {
+//   typedef void (*fn)(GLuint num_groups_x, GLuint num_groups_y, GLuint num_groups_z);
+//   ((fn)(fnptr))(num_groups_x, num_groups_y, num_groups_z);
+// }
+// static void glowDrawBuffers(uintptr_t fnptr, GLsizei n, const GLenum* bufs) {
+//   typedef void (*fn)(GLsizei n, const GLenum* bufs);
+//   ((fn)(fnptr))(n, bufs);
+// }
 // static void glowDrawElements(uintptr_t fnptr, GLenum mode, GLsizei count, GLenum type, const uintptr_t indices) {
 //   typedef void (*fn)(GLenum mode, GLsizei count, GLenum type, const uintptr_t indices);
 //   ((fn)(fnptr))(mode, count, type, indices);
@@ -140,6 +191,14 @@ package gl
 //   typedef void (*fn)(GLuint index);
 //   ((fn)(fnptr))(index);
 // }
+// static void glowEndQuery(uintptr_t fnptr, GLenum target) {
+//   typedef void (*fn)(GLenum target);
+//   ((fn)(fnptr))(target);
+// }
+// static uintptr_t glowFenceSync(uintptr_t fnptr, GLenum condition, GLbitfield flags) {
+//   typedef GLsync (*fn)(GLenum condition, GLbitfield flags);
+//   return (uintptr_t)((fn)(fnptr))(condition, flags);
+// }
 // static void glowFlush(uintptr_t fnptr) {
 //   typedef void (*fn)();
 //   ((fn)(fnptr))();
@@ -160,6 +219,10 @@ package gl
 //   typedef void (*fn)(GLsizei n, GLuint* framebuffers);
 //   ((fn)(fnptr))(n, framebuffers);
 // }
+// static void glowGenQueries(uintptr_t fnptr, GLsizei n, GLuint* ids) {
+//   typedef void (*fn)(GLsizei n, GLuint* ids);
+//   ((fn)(fnptr))(n, ids);
+// }
 // static void glowGenRenderbuffers(uintptr_t fnptr, GLsizei n, GLuint* renderbuffers) {
 //   typedef void (*fn)(GLsizei n, GLuint* renderbuffers);
 //   ((fn)(fnptr))(n, renderbuffers);
@@ -172,6 +235,17 @@ package gl
 //   typedef void (*fn)(GLsizei n, GLuint* arrays);
 //   ((fn)(fnptr))(n, arrays);
 // }
+// static void glowGetBufferSubData(uintptr_t fnptrMap, uintptr_t fnptrUnmap, GLenum target, GLintptr offset, GLsizeiptr size, void* data) {
+//   // glGetBufferSubData is not available in OpenGL ES. Map the buffer and copy the data instead.
+//   typedef void* (*mapFn)(GLenum target, GLintptr offset, GLsizeiptr length, GLbitfield access);
+//   typedef GLboolean (*unmapFn)(GLenum target);
+//   void* ptr = ((mapFn)(fnptrMap))(target, offset, size, 0x0001 /* GL_MAP_READ_BIT */);
+//   if (!ptr) {
+//     return;
+//   }
+//   memcpy(data, ptr, size);
+//   ((unmapFn)(fnptrUnmap))(target);
+// }
 // static GLenum glowGetError(uintptr_t fnptr) {
 //   typedef GLenum (*fn)();
 //   return ((fn)(fnptr))();
@@ -188,6 +262,14 @@ package gl
 //   typedef void (*fn)(GLuint program, GLenum pname, GLint* params);
 //   ((fn)(fnptr))(program, pname, params);
 // }
+// static void glowGetQueryObjectui64v(uintptr_t fnptr, GLuint id, GLenum pname, GLuint64* params) {
+//   typedef void (*fn)(GLuint id, GLenum pname, GLuint64* params);
+//   ((fn)(fnptr))(id, pname, params);
+// }
+// static void glowGetQueryObjectuiv(uintptr_t fnptr, GLuint id, GLenum pname, GLuint* params) {
+//   typedef void (*fn)(GLuint id, GLenum pname, GLuint* params);
+//   ((fn)(fnptr))(id, pname, params);
+// }
 // static void glowGetShaderInfoLog(uintptr_t fnptr, GLuint shader, GLsizei bufSize, GLsizei* length, GLchar* infoLog) {
 //   typedef void (*fn)(GLuint shader, GLsizei bufSize, GLsizei* length, GLchar* infoLog);
 //   ((fn)(fnptr))(shader, bufSize, length, infoLog);
@@ -208,10 +290,18 @@ package gl
 //   typedef void (*fn)(GLuint program);
 //   ((fn)(fnptr))(program);
 // }
+// static void glowMemoryBarrier(uintptr_t fnptr, GLbitfield barriers) {
+//   typedef void (*fn)(GLbitfield barriers);
+//   ((fn)(fnptr))(barriers);
+// }
 // static void glowPixelStorei(uintptr_t fnptr, GLenum pname, GLint param) {
 //   typedef void (*fn)(GLenum pname, GLint param);
 //   ((fn)(fnptr))(pname, param);
 // }
+// static void glowQueryCounter(uintptr_t fnptr, GLuint id, GLenum target) {
+//   typedef void (*fn)(GLuint id, GLenum target);
+//   ((fn)(fnptr))(id, target);
+// }
 // static void glowReadPixels(uintptr_t fnptr, GLint x, GLint y, GLsizei width, GLsizei height, GLenum format, GLenum type, void* pixels) {
 //   typedef void (*fn)(GLint x, GLint y, GLsizei width, GLsizei height, GLenum format, GLenum type, void* pixels);
 //   ((fn)(fnptr))(x, y, width, height, format, type, pixels);
@@ -220,6 +310,10 @@ package gl
 //   typedef void (*fn)(GLenum target, GLenum internalformat, GLsizei width, GLsizei height);
 //   ((fn)(fnptr))(target, internalformat, width, height);
 // }
+// static void glowRenderbufferStorageMultisample(uintptr_t fnptr, GLenum target, GLsizei samples, GLenum internalformat, GLsizei width, GLsizei height) {
+//   typedef void (*fn)(GLenum target, GLsizei samples, GLenum internalformat, GLsizei width, GLsizei height);
+//   ((fn)(fnptr))(target, samples, internalformat, width, height);
+// }
 // static void glowScissor(uintptr_t fnptr, GLint x, GLint y, GLsizei width, GLsizei height) {
 //   typedef void (*fn)(GLint x, GLint y, GLsizei width, GLsizei height);
 //   ((fn)(fnptr))(x, y, width, height);
@@ -316,78 +410,100 @@ import (
 )
 
 type defaultContext struct {
-	gpActiveTexture            C.uintptr_t
-	gpAttachShader             C.uintptr_t
-	gpBindAttribLocation       C.uintptr_t
-	gpBindBuffer               C.uintptr_t
-	gpBindFramebuffer          C.uintptr_t
-	gpBindRenderbuffer         C.uintptr_t
-	gpBindTexture              C.uintptr_t
-	gpBindVertexArray          C.uintptr_t
-	gpBlendEquationSeparate    C.uintptr_t
-	gpBlendFuncSeparate        C.uintptr_t
-	gpBufferData               C.uintptr_t
-	gpBufferSubData            C.uintptr_t
-	gpCheckFramebufferStatus   C.uintptr_t
-	gpClear                    C.uintptr_t
-	gpColorMask                C.uintptr_t
-	gpCompileShader            C.uintptr_t
-	gpCreateProgram            C.uintptr_t
-	gpCreateShader             C.uintptr_t
-	gpDeleteBuffers            C.uintptr_t
-	gpDeleteFramebuffers       C.uintptr_t
-	gpDeleteProgram            C.uintptr_t
-	gpDeleteRenderbuffers      C.uintptr_t
-	gpDeleteShader             C.uintptr_t
-	gpDeleteTextures           C.uintptr_t
-	gpDeleteVertexArrays       C.uintptr_t
-	gpDisable                  C.uintptr_t
-	gpDisableVertexAttribArray C.uintptr_t
-	gpDrawElements             C.uintptr_t
-	gpEnable                   C.uintptr_t
-	gpEnableVertexAttribArray  C.uintptr_t
-	gpFlush                    C.uintptr_t
-	gpFramebufferRenderbuffer  C.uintptr_t
-	gpFramebufferTexture2D     C.uintptr_t
-	gpGenBuffers               C.uintptr_t
-	gpGenFramebuffers          C.uintptr_t
-	gpGenRenderbuffers         C.uintptr_t
-	gpGenTextures              C.uintptr_t
-	gpGenVertexArrays          C.uintptr_t
-	gpGetError                 C.uintptr_t
-	gpGetIntegerv              C.uintptr_t
-	gpGetProgramInfoLog        C.uintptr_t
-	gpGetProgramiv             C.uintptr_t
-	gpGetShaderInfoLog         C.uintptr_t
-	gpGetShaderiv              C.uintptr_t
-	gpGetUniformLocation       C.uintptr_t
-	gpIsProgram                C.uintptr_t
-	gpLinkProgram              C.uintptr_t
-	gpPixelStorei              C.uintptr_t
-	gpReadPixels               C.uintptr_t
-	gpRenderbufferStorage      C.uintptr_t
-	gpScissor                  C.uintptr_t
-	gpShaderSource             C.uintptr_t
-	gpStencilFunc              C.uintptr_t
-	gpStencilOpSeparate        C.uintptr_t
-	gpTexImage2D               C.uintptr_t
-	gpTexParameteri            C.uintptr_t
-	gpTexSubImage2D            C.uintptr_t
-	gpUniform1fv               C.uintptr_t
-	gpUniform1i                C.uintptr_t
-	gpUniform1iv               C.uintptr_t
-	gpUniform2fv               C.uintptr_t
-	gpUniform2iv               C.uintptr_t
-	gpUniform3fv               C.uintptr_t
-	gpUniform3iv               C.uintptr_t
-	gpUniform4fv               C.uintptr_t
-	gpUniform4iv               C.uintptr_t
-	gpUniformMatrix2fv         C.uintptr_t
-	gpUniformMatrix3fv         C.uintptr_t
-	gpUniformMatrix4fv         C.uintptr_t
-	gpUseProgram               C.uintptr_t
-	gpVertexAttribPointer      C.uintptr_t
-	gpViewport                 C.uintptr_t
+	gpActiveTexture                  C.uintptr_t
+	gpAttachShader                   C.uintptr_t
+	gpBeginQuery                     C.uintptr_t
+	gpBindAttribLocation             C.uintptr_t
+	gpBindBuffer                     C.uintptr_t
+	gpBindFragDataLocation           C.uintptr_t
+	gpBindFragDataLocationIndexed    C.uintptr_t
+	gpBindFramebuffer                C.uintptr_t
+	gpBindImageTexture               C.uintptr_t
+	gpBindRenderbuffer               C.uintptr_t
+	gpBindTexture                    C.uintptr_t
+	gpBindVertexArray                C.uintptr_t
+	gpBlendEquationSeparate          C.uintptr_t
+	gpBlendFuncSeparate              C.uintptr_t
+	gpBlitFramebuffer                C.uintptr_t
+	gpBufferData                     C.uintptr_t
+	gpBufferSubData                  C.uintptr_t
+	gpCheckFramebufferStatus         C.uintptr_t
+	gpClear                          C.uintptr_t
+	gpClientWaitSync                 C.uintptr_t
+	gpColorMask                      C.uintptr_t
+	gpCompileShader                  C.uintptr_t
+	gpCreateProgram                  C.uintptr_t
+	gpCreateShader                   C.uintptr_t
+	gpDeleteBuffers                  C.uintptr_t
+	gpDeleteFramebuffers             C.uintptr_t
+	gpDeleteProgram                  C.uintptr_t
+	gpDeleteQueries                  C.uintptr_t
+	gpDeleteRenderbuffers            C.uintptr_t
+	gpDeleteShader                   C.uintptr_t
+	gpDeleteSync                     C.uintptr_t
+	gpDeleteTextures                 C.uintptr_t
+	gpDeleteVertexArrays             C.uintptr_t
+	gpDepthFunc                      C.uintptr_t
+	gpDepthMask                      C.uintptr_t
+	gpDisable                        C.uintptr_t
+	gpDisableVertexAttribArray       C.uintptr_t
+	gpDispatchCompute                C.uintptr_t
+	gpDrawBuffers                    C.uintptr_t
+	gpDrawElements                   C.uintptr_t
+	gpEnable                         C.uintptr_t
+	gpEnableVertexAttribArray        C.uintptr_t
+	gpEndQuery                       C.uintptr_t
+	gpFenceSync                      C.uintptr_t
+	gpFlush                          C.uintptr_t
+	gpFramebufferRenderbuffer        C.uintptr_t
+	gpFramebufferTexture2D           C.uintptr_t
+	gpGenBuffers                     C.uintptr_t
+	gpGenFramebuffers                C.uintptr_t
+	gpGenQueries                     C.uintptr_t
+	gpGenRenderbuffers               C.uintptr_t
+	gpGenTextures                    C.uintptr_t
+	gpGenVertexArrays                C.uintptr_t
+	gpGetError                       C.uintptr_t
+	gpGetIntegerv                    C.uintptr_t
+	gpGetProgramInfoLog              C.uintptr_t
+	gpGetProgramiv                   C.uintptr_t
+	gpGetQueryObjectui64v            C.uintptr_t
+	gpGetQueryObjectuiv              C.uintptr_t
+	gpGetShaderInfoLog               C.uintptr_t
+	gpGetShaderiv                    C.uintptr_t
+	gpGetUniformLocation             C.uintptr_t
+	gpIsProgram                      C.uintptr_t
+	gpLinkProgram                    C.uintptr_t
+	gpMapBufferRange                 C.uintptr_t
+	gpMemoryBarrier                  C.uintptr_t
+	gpPixelStorei                    C.uintptr_t
+	gpQueryCounter                   C.uintptr_t
+	gpReadPixels                     C.uintptr_t
+	gpRenderbufferStorage            C.uintptr_t
+	gpRenderbufferStorageMultisample C.uintptr_t
+	gpScissor                        C.uintptr_t
+	gpShaderSource                   C.uintptr_t
+	gpStencilFunc                    C.uintptr_t
+	gpStencilOpSeparate              C.uintptr_t
+	gpTexImage2D                     C.uintptr_t
+	gpTexParameteri                  C.uintptr_t
+	gpTexSubImage2D                  C.uintptr_t
+	gpUnmapBuffer                    C.uintptr_t
+	gpUniform1fv                     C.uintptr_t
+	gpUniform1i                      C.uintptr_t
+	gpUniform1iv                     C.uintptr_t
+	gpUniform2fv                     C.uintptr_t
+	gpUniform2iv                     C.uintptr_t
+	gpUniform3fv                     C.uintptr_t
+	gpUniform3iv                     C.uintptr_t
+	gpUniform4fv                     C.uintptr_t
+	gpUniform4iv                     C.uintptr_t
+	gpUniformMatrix2fv               C.uintptr_t
+	gpUniformMatrix3fv               C.uintptr_t
+	gpUniformMatrix4fv               C.uintptr_t
+	gpUseProgram                     C.uintptr_t
+	gpVertexAttribPointer            C.uintptr_t
+	gpViewport                       C.uintptr_t
 
 	isES bool
 }
@@ -419,6 +535,10 @@ func (c *defaultContext) AttachShader(program uint32, shader uint32) {
 	C.glowAttachShader(c.gpAttachShader, C.GLuint(program), C.GLuint(shader))
 }
 
+func (c *defaultContext) BeginQuery(target uint32, query uint32) {
+	C.glowBeginQuery(c.gpBeginQuery, C.GLenum(target), C.GLuint(query))
+}
+
 func (c *defaultContext) BindAttribLocation(program uint32, index uint32, name string) {
 	cname := C.CString(name)
 	defer C.free(unsafe.Pointer(cname))
@@ -429,10 +549,32 @@ func (c *defaultContext) BindBuffer(target uint32, buffer uint32) {
 	C.glowBindBuffer(c.gpBindBuffer, C.GLenum(target), C.GLuint(buffer))
 }
 
+func (c *defaultContext) BindFragDataLocation(program uint32, colorNumber uint32, name string) {
+	if c.gpBindFragDataLocation == 0 {
+		return
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.glowBindFragDataLocation(c.gpBindFragDataLocation, C.GLuint(program), C.GLuint(colorNumber), (*C.GLchar)(unsafe.Pointer(cname)))
+}
+
+func (c *defaultContext) BindFragDataLocationIndexed(program uint32, colorNumber uint32, index uint32, name string) {
+	if c.gpBindFragDataLocationIndexed == 0 {
+		return
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	C.glowBindFragDataLocationIndexed(c.gpBindFragDataLocationIndexed, C.GLuint(program), C.GLuint(colorNumber), C.GLuint(index), (*C.GLchar)(unsafe.Pointer(cname)))
+}
+
 func (c *defaultContext) BindFramebuffer(target uint32, framebuffer uint32) {
 	C.glowBindFramebuffer(c.gpBindFramebuffer, C.GLenum(target), C.GLuint(framebuffer))
 }
 
+func (c *defaultContext) BindImageTexture(unit uint32, texture uint32, level int32, layered bool, layer int32, access uint32, format uint32) {
+	C.glowBindImageTexture(c.gpBindImageTexture, C.GLuint(unit), C.GLuint(texture), C.GLint(level), C.GLboolean(boolToInt(layered)), C.GLint(layer), C.GLenum(access), C.GLenum(format))
+}
+
 func (c *defaultContext) BindRenderbuffer(target uint32, renderbuffer uint32) {
 	C.glowBindRenderbuffer(c.gpBindRenderbuffer, C.GLenum(target), C.GLuint(renderbuffer))
 }
@@ -453,6 +595,10 @@ func (c *defaultContext) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlph
 	C.glowBlendFuncSeparate(c.gpBlendFuncSeparate, C.GLenum(srcRGB), C.GLenum(dstRGB), C.GLenum(srcAlpha), C.GLenum(dstAlpha))
 }
 
+func (c *defaultContext) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+	C.glowBlitFramebuffer(c.gpBlitFramebuffer, C.GLint(srcX0), C.GLint(srcY0), C.GLint(srcX1), C.GLint(srcY1), C.GLint(dstX0), C.GLint(dstY0), C.GLint(dstX1), C.GLint(dstY1), C.GLbitfield(mask), C.GLenum(filter))
+}
+
 func (c *defaultContext) BufferInit(target uint32, size int, usage uint32) {
 	C.glowBufferData(c.gpBufferData, C.GLenum(target), C.GLsizeiptr(size), nil, C.GLenum(usage))
 }
@@ -471,6 +617,11 @@ func (c *defaultContext) Clear(mask uint32) {
 	C.glowClear(c.gpClear, C.GLbitfield(mask))
 }
 
+func (c *defaultContext) ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	ret := C.glowClientWaitSync(c.gpClientWaitSync, C.uintptr_t(sync), C.GLbitfield(flags), C.GLuint64(timeout))
+	return uint32(ret)
+}
+
 func (c *defaultContext) ColorMask(red bool, green bool, blue bool, alpha bool) {
 	C.glowColorMask(c.gpColorMask, C.GLboolean(boolToInt(red)), C.GLboolean(boolToInt(green)), C.GLboolean(boolToInt(blue)), C.GLboolean(boolToInt(alpha)))
 }
@@ -502,6 +653,12 @@ func (c *defaultContext) CreateRenderbuffer() uint32 {
 	return renderbuffer
 }
 
+func (c *defaultContext) CreateQuery() uint32 {
+	var query uint32
+	C.glowGenQueries(c.gpGenQueries, 1, (*C.GLuint)(unsafe.Pointer(&query)))
+	return query
+}
+
 func (c *defaultContext) CreateShader(xtype uint32) uint32 {
 	ret := C.glowCreateShader(c.gpCreateShader, C.GLenum(xtype))
 	return uint32(ret)
@@ -531,6 +688,10 @@ func (c *defaultContext) DeleteProgram(program uint32) {
 	C.glowDeleteProgram(c.gpDeleteProgram, C.GLuint(program))
 }
 
+func (c *defaultContext) DeleteQuery(query uint32) {
+	C.glowDeleteQueries(c.gpDeleteQueries, 1, (*C.GLuint)(unsafe.Pointer(&query)))
+}
+
 func (c *defaultContext) DeleteRenderbuffer(renderbuffer uint32) {
 	C.glowDeleteRenderbuffers(c.gpDeleteRenderbuffers, 1, (*C.GLuint)(unsafe.Pointer(&renderbuffer)))
 }
@@ -539,6 +700,10 @@ func (c *defaultContext) DeleteShader(shader uint32) {
 	C.glowDeleteShader(c.gpDeleteShader, C.GLuint(shader))
 }
 
+func (c *defaultContext) DeleteSync(sync uintptr) {
+	C.glowDeleteSync(c.gpDeleteSync, C.uintptr_t(sync))
+}
+
 func (c *defaultContext) DeleteTexture(texture uint32) {
 	C.glowDeleteTextures(c.gpDeleteTextures, 1, (*C.GLuint)(unsafe.Pointer(&texture)))
 }
@@ -547,6 +712,14 @@ func (c *defaultContext) DeleteVertexArray(array uint32) {
 	C.glowDeleteVertexArrays(c.gpDeleteVertexArrays, 1, (*C.GLuint)(unsafe.Pointer(&array)))
 }
 
+func (c *defaultContext) DepthFunc(xfunc uint32) {
+	C.glowDepthFunc(c.gpDepthFunc, C.GLenum(xfunc))
+}
+
+func (c *defaultContext) DepthMask(flag bool) {
+	C.glowDepthMask(c.gpDepthMask, C.GLboolean(boolToInt(flag)))
+}
+
 func (c *defaultContext) Disable(cap uint32) {
 	C.glowDisable(c.gpDisable, C.GLenum(cap))
 }
@@ -555,6 +728,14 @@ func (c *defaultContext) DisableVertexAttribArray(index uint32) {
 	C.glowDisableVertexAttribArray(c.gpDisableVertexAttribArray, C.GLuint(index))
 }
 
+func (c *defaultContext) DispatchCompute(numGroupsX uint32, numGroupsY uint32, numGroupsZ uint32) {
+	C.glowDispatchCompute(c.gpDispatchCompute, C.GLuint(numGroupsX), C.GLuint(numGroupsY), C.GLuint(numGroupsZ))
+}
+
+func (c *defaultContext) DrawBuffers(bufs []uint32) {
+	C.glowDrawBuffers(c.gpDrawBuffers, C.GLsizei(len(bufs)), (*C.GLenum)(unsafe.Pointer(&bufs[0])))
+}
+
 func (c *defaultContext) DrawElements(mode uint32, count int32, xtype uint32, offset int) {
 	C.glowDrawElements(c.gpDrawElements, C.GLenum(mode), C.GLsizei(count), C.GLenum(xtype), C.uintptr_t(offset))
 }
@@ -567,6 +748,15 @@ func (c *defaultContext) EnableVertexAttribArray(index uint32) {
 	C.glowEnableVertexAttribArray(c.gpEnableVertexAttribArray, C.GLuint(index))
 }
 
+func (c *defaultContext) EndQuery(target uint32) {
+	C.glowEndQuery(c.gpEndQuery, C.GLenum(target))
+}
+
+func (c *defaultContext) FenceSync(condition uint32, flags uint32) uintptr {
+	ret := C.glowFenceSync(c.gpFenceSync, C.GLenum(condition), C.GLbitfield(flags))
+	return uintptr(ret)
+}
+
 func (c *defaultContext) Flush() {
 	C.glowFlush(c.gpFlush)
 }
@@ -579,6 +769,10 @@ func (c *defaultContext) FramebufferTexture2D(target uint32, attachment uint32,
 	C.glowFramebufferTexture2D(c.gpFramebufferTexture2D, C.GLenum(target), C.GLenum(attachment), C.GLenum(textarget), C.GLuint(texture), C.GLint(level))
 }
 
+func (c *defaultContext) GetBufferSubData(target uint32, offset int, data []byte) {
+	C.glowGetBufferSubData(c.gpMapBufferRange, c.gpUnmapBuffer, C.GLenum(target), C.GLintptr(offset), C.GLsizeiptr(len(data)), unsafe.Pointer(&data[0]))
+}
+
 func (c *defaultContext) GetError() uint32 {
 	ret := C.glowGetError(c.gpGetError)
 	return uint32(ret)
@@ -606,6 +800,18 @@ func (c *defaultContext) GetProgrami(program uint32, pname uint32) int {
 	return int(dst)
 }
 
+func (c *defaultContext) GetQueryObjectui(query uint32, pname uint32) uint32 {
+	var dst uint32
+	C.glowGetQueryObjectuiv(c.gpGetQueryObjectuiv, C.GLuint(query), C.GLenum(pname), (*C.GLuint)(unsafe.Pointer(&dst)))
+	return dst
+}
+
+func (c *defaultContext) GetQueryObjectui64(query uint32, pname uint32) uint64 {
+	var dst uint64
+	C.glowGetQueryObjectui64v(c.gpGetQueryObjectui64v, C.GLuint(query), C.GLenum(pname), (*C.GLuint64)(unsafe.Pointer(&dst)))
+	return dst
+}
+
 func (c *defaultContext) GetShaderInfoLog(shader uint32) string {
 	bufSize := c.GetShaderi(shader, INFO_LOG_LENGTH)
 	if bufSize == 0 {
@@ -634,22 +840,43 @@ func (c *defaultContext) IsProgram(program uint32) bool {
 	return ret == TRUE
 }
 
+func (c *defaultContext) IsTimerQueryAvailable() bool {
+	return c.gpQueryCounter != 0 && c.gpGetQueryObjectui64v != 0
+}
+
 func (c *defaultContext) LinkProgram(program uint32) {
 	C.glowLinkProgram(c.gpLinkProgram, C.GLuint(program))
 }
 
+func (c *defaultContext) MemoryBarrier(barriers uint32) {
+	C.glowMemoryBarrier(c.gpMemoryBarrier, C.GLbitfield(barriers))
+}
+
 func (c *defaultContext) PixelStorei(pname uint32, param int32) {
 	C.glowPixelStorei(c.gpPixelStorei, C.GLenum(pname), C.GLint(param))
 }
 
+func (c *defaultContext) QueryCounter(query uint32, target uint32) {
+	C.glowQueryCounter(c.gpQueryCounter, C.GLuint(query), C.GLenum(target))
+}
+
 func (c *defaultContext) ReadPixels(dst []byte, x int32, y int32, width int32, height int32, format uint32, xtype uint32) {
-	C.glowReadPixels(c.gpReadPixels, C.GLint(x), C.GLint(y), C.GLsizei(width), C.GLsizei(height), C.GLenum(format), C.GLenum(xtype), unsafe.Pointer(&dst[0]))
+	// When dst is nil, the pixels are read into the buffer bound to PIXEL_PACK_BUFFER.
+	var ptr unsafe.Pointer
+	if dst != nil {
+		ptr = unsafe.Pointer(&dst[0])
+	}
+	C.glowReadPixels(c.gpReadPixels, C.GLint(x), C.GLint(y), C.GLsizei(width), C.GLsizei(height), C.GLenum(format), C.GLenum(xtype), ptr)
 }
 
 func (c *defaultContext) RenderbufferStorage(target uint32, internalformat uint32, width int32, height int32) {
 	C.glowRenderbufferStorage(c.gpRenderbufferStorage, C.GLenum(target), C.GLenum(internalformat), C.GLsizei(width), C.GLsizei(height))
 }
 
+func (c *defaultContext) RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32) {
+	C.glowRenderbufferStorageMultisample(c.gpRenderbufferStorageMultisample, C.GLenum(target), C.GLsizei(samples), C.GLenum(internalformat), C.GLsizei(width), C.GLsizei(height))
+}
+
 func (c *defaultContext) Scissor(x int32, y int32, width int32, height int32) {
 	C.glowScissor(c.gpScissor, C.GLint(x), C.GLint(y), C.GLsizei(width), C.GLsizei(height))
 }
@@ -762,18 +989,27 @@ func (c *defaultContext) LoadFunctions() error {
 
 	c.gpActiveTexture = C.uintptr_t(g.get("glActiveTexture"))
 	c.gpAttachShader = C.uintptr_t(g.get("glAttachShader"))
+	c.gpBeginQuery = C.uintptr_t(g.get("glBeginQuery"))
 	c.gpBindAttribLocation = C.uintptr_t(g.get("glBindAttribLocation"))
 	c.gpBindBuffer = C.uintptr_t(g.get("glBindBuffer"))
+	// glBindFragDataLocation is not available with OpenGL ES.
+	c.gpBindFragDataLocation = C.uintptr_t(g.getOptional("glBindFragDataLocation"))
+	// glBindFragDataLocationIndexed is not available with OpenGL ES and OpenGL 3.2.
+	c.gpBindFragDataLocationIndexed = C.uintptr_t(g.getOptional("glBindFragDataLocationIndexed"))
 	c.gpBindFramebuffer = C.uintptr_t(g.get("glBindFramebuffer"))
+	// glBindImageTexture is available as of OpenGL 4.2 and OpenGL ES 3.1.
+	c.gpBindImageTexture = C.uintptr_t(g.getOptional("glBindImageTexture"))
 	c.gpBindRenderbuffer = C.uintptr_t(g.get("glBindRenderbuffer"))
 	c.gpBindTexture = C.uintptr_t(g.get("glBindTexture"))
 	c.gpBindVertexArray = C.uintptr_t(g.get("glBindVertexArray"))
 	c.gpBlendEquationSeparate = C.uintptr_t(g.get("glBlendEquationSeparate"))
 	c.gpBlendFuncSeparate = C.uintptr_t(g.get("glBlendFuncSeparate"))
+	c.gpBlitFramebuffer = C.uintptr_t(g.get("glBlitFramebuffer"))
 	c.gpBufferData = C.uintptr_t(g.get("glBufferData"))
 	c.gpBufferSubData = C.uintptr_t(g.get("glBufferSubData"))
 	c.gpCheckFramebufferStatus = C.uintptr_t(g.get("glCheckFramebufferStatus"))
 	c.gpClear = C.uintptr_t(g.get("glClear"))
+	c.gpClientWaitSync = C.uintptr_t(g.get("glClientWaitSync"))
 	c.gpColorMask = C.uintptr_t(g.get("glColorMask"))
 	c.gpCompileShader = C.uintptr_t(g.get("glCompileShader"))
 	c.gpCreateProgram = C.uintptr_t(g.get("glCreateProgram"))
@@ -781,20 +1017,30 @@ func (c *defaultContext) LoadFunctions() error {
 	c.gpDeleteBuffers = C.uintptr_t(g.get("glDeleteBuffers"))
 	c.gpDeleteFramebuffers = C.uintptr_t(g.get("glDeleteFramebuffers"))
 	c.gpDeleteProgram = C.uintptr_t(g.get("glDeleteProgram"))
+	c.gpDeleteQueries = C.uintptr_t(g.get("glDeleteQueries"))
 	c.gpDeleteRenderbuffers = C.uintptr_t(g.get("glDeleteRenderbuffers"))
 	c.gpDeleteShader = C.uintptr_t(g.get("glDeleteShader"))
+	c.gpDeleteSync = C.uintptr_t(g.get("glDeleteSync"))
 	c.gpDeleteTextures = C.uintptr_t(g.get("glDeleteTextures"))
 	c.gpDeleteVertexArrays = C.uintptr_t(g.get("glDeleteVertexArrays"))
+	c.gpDepthFunc = C.uintptr_t(g.get("glDepthFunc"))
+	c.gpDepthMask = C.uintptr_t(g.get("glDepthMask"))
 	c.gpDisable = C.uintptr_t(g.get("glDisable"))
 	c.gpDisableVertexAttribArray = C.uintptr_t(g.get("glDisableVertexAttribArray"))
+	// glDispatchCompute is available as of OpenGL 4.3 and OpenGL ES 3.1.
+	c.gpDispatchCompute = C.uintptr_t(g.getOptional("glDispatchCompute"))
+	c.gpDrawBuffers = C.uintptr_t(g.get("glDrawBuffers"))
 	c.gpDrawElements = C.uintptr_t(g.get("glDrawElements"))
 	c.gpEnable = C.uintptr_t(g.get("glEnable"))
 	c.gpEnableVertexAttribArray = C.uintptr_t(g.get("glEnableVertexAttribArray"))
+	c.gpEndQuery = C.uintptr_t(g.get("glEndQuery"))
+	c.gpFenceSync = C.uintptr_t(g.get("glFenceSync"))
 	c.gpFlush = C.uintptr_t(g.get("glFlush"))
 	c.gpFramebufferRenderbuffer = C.uintptr_t(g.get("glFramebufferRenderbuffer"))
 	c.gpFramebufferTexture2D = C.uintptr_t(g.get("glFramebufferTexture2D"))
 	c.gpGenBuffers = C.uintptr_t(g.get("glGenBuffers"))
 	c.gpGenFramebuffers = C.uintptr_t(g.get("glGenFramebuffers"))
+	c.gpGenQueries = C.uintptr_t(g.get("glGenQueries"))
 	c.gpGenRenderbuffers = C.uintptr_t(g.get("glGenRenderbuffers"))
 	c.gpGenTextures = C.uintptr_t(g.get("glGenTextures"))
 	c.gpGenVertexArrays = C.uintptr_t(g.get("glGenVertexArrays"))
@@ -802,14 +1048,22 @@ func (c *defaultContext) LoadFunctions() error {
 	c.gpGetIntegerv = C.uintptr_t(g.get("glGetIntegerv"))
 	c.gpGetProgramInfoLog = C.uintptr_t(g.get("glGetProgramInfoLog"))
 	c.gpGetProgramiv = C.uintptr_t(g.get("glGetProgramiv"))
+	// glGetQueryObjectui64v and glQueryCounter are not available with OpenGL ES and OpenGL 3.2.
+	c.gpGetQueryObjectui64v = C.uintptr_t(g.getOptional("glGetQueryObjectui64v"))
+	c.gpGetQueryObjectuiv = C.uintptr_t(g.get("glGetQueryObjectuiv"))
 	c.gpGetShaderInfoLog = C.uintptr_t(g.get("glGetShaderInfoLog"))
 	c.gpGetShaderiv = C.uintptr_t(g.get("glGetShaderiv"))
 	c.gpGetUniformLocation = C.uintptr_t(g.get("glGetUniformLocation"))
 	c.gpIsProgram = C.uintptr_t(g.get("glIsProgram"))
 	c.gpLinkProgram = C.uintptr_t(g.get("glLinkProgram"))
+	c.gpMapBufferRange = C.uintptr_t(g.get("glMapBufferRange"))
+	// glMemoryBarrier is available as of OpenGL 4.2 and OpenGL ES 3.1.
+	c.gpMemoryBarrier = C.uintptr_t(g.getOptional("glMemoryBarrier"))
 	c.gpPixelStorei = C.uintptr_t(g.get("glPixelStorei"))
+	c.gpQueryCounter = C.uintptr_t(g.getOptional("glQueryCounter"))
 	c.gpReadPixels = C.uintptr_t(g.get("glReadPixels"))
 	c.gpRenderbufferStorage = C.uintptr_t(g.get("glRenderbufferStorage"))
+	c.gpRenderbufferStorageMultisample = C.uintptr_t(g.get("glRenderbufferStorageMultisample"))
 	c.gpScissor = C.uintptr_t(g.get("glScissor"))
 	c.gpShaderSource = C.uintptr_t(g.get("glShaderSource"))
 	c.gpStencilFunc = C.uintptr_t(g.get("glStencilFunc"))
@@ -817,6 +1071,7 @@ func (c *defaultContext) LoadFunctions() error {
 	c.gpTexImage2D = C.uintptr_t(g.get("glTexImage2D"))
 	c.gpTexParameteri = C.uintptr_t(g.get("glTexParameteri"))
 	c.gpTexSubImage2D = C.uintptr_t(g.get("glTexSubImage2D"))
+	c.gpUnmapBuffer = C.uintptr_t(g.get("glUnmapBuffer"))
 	c.gpUniform1fv = C.uintptr_t(g.get("glUniform1fv"))
 	c.gpUniform1i = C.uintptr_t(g.get("glUniform1i"))
 	c.gpUniform1iv = C.uintptr_t(g.get("glUniform1iv"))
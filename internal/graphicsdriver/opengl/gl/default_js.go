@@ -22,85 +22,101 @@ import (
 )
 
 type defaultContext struct {
-	fnActiveTexture            js.Value
-	fnAttachShader             js.Value
-	fnBindAttribLocation       js.Value
-	fnBindBuffer               js.Value
-	fnBindFramebuffer          js.Value
-	fnBindRenderbuffer         js.Value
-	fnBindTexture              js.Value
-	fnBindVertexArray          js.Value
-	fnBlendEquationSeparate    js.Value
-	fnBlendFuncSeparate        js.Value
-	fnBufferData               js.Value
-	fnBufferSubData            js.Value
-	fnCheckFramebufferStatus   js.Value
-	fnClear                    js.Value
-	fnColorMask                js.Value
-	fnCompileShader            js.Value
-	fnCreateBuffer             js.Value
-	fnCreateFramebuffer        js.Value
-	fnCreateProgram            js.Value
-	fnCreateRenderbuffer       js.Value
-	fnCreateShader             js.Value
-	fnCreateTexture            js.Value
-	fnCreateVertexArray        js.Value
-	fnDeleteBuffer             js.Value
-	fnDeleteFramebuffer        js.Value
-	fnDeleteProgram            js.Value
-	fnDeleteRenderbuffer       js.Value
-	fnDeleteShader             js.Value
-	fnDeleteTexture            js.Value
-	fnDeleteVertexArray        js.Value
-	fnDisable                  js.Value
-	fnDisableVertexAttribArray js.Value
-	fnDrawElements             js.Value
-	fnEnable                   js.Value
-	fnEnableVertexAttribArray  js.Value
-	fnFramebufferRenderbuffer  js.Value
-	fnFramebufferTexture2D     js.Value
-	fnFlush                    js.Value
-	fnGetError                 js.Value
-	fnGetParameter             js.Value
-	fnGetProgramInfoLog        js.Value
-	fnGetProgramParameter      js.Value
-	fnGetShaderInfoLog         js.Value
-	fnGetShaderParameter       js.Value
-	fnGetUniformLocation       js.Value
-	fnIsProgram                js.Value
-	fnLinkProgram              js.Value
-	fnPixelStorei              js.Value
-	fnReadPixels               js.Value
-	fnRenderbufferStorage      js.Value
-	fnScissor                  js.Value
-	fnShaderSource             js.Value
-	fnStencilFunc              js.Value
-	fnStencilMask              js.Value
-	fnStencilOpSeparate        js.Value
-	fnTexImage2D               js.Value
-	fnTexSubImage2D            js.Value
-	fnTexParameteri            js.Value
-	fnUniform1fv               js.Value
-	fnUniform1i                js.Value
-	fnUniform1iv               js.Value
-	fnUniform2fv               js.Value
-	fnUniform2iv               js.Value
-	fnUniform3fv               js.Value
-	fnUniform3iv               js.Value
-	fnUniform4fv               js.Value
-	fnUniform4iv               js.Value
-	fnUniformMatrix2fv         js.Value
-	fnUniformMatrix3fv         js.Value
-	fnUniformMatrix4fv         js.Value
-	fnUseProgram               js.Value
-	fnVertexAttribPointer      js.Value
-	fnViewport                 js.Value
+	fnActiveTexture                  js.Value
+	fnAttachShader                   js.Value
+	fnBeginQuery                     js.Value
+	fnBindAttribLocation             js.Value
+	fnBindBuffer                     js.Value
+	fnBindFramebuffer                js.Value
+	fnBindRenderbuffer               js.Value
+	fnBindTexture                    js.Value
+	fnBindVertexArray                js.Value
+	fnBlendEquationSeparate          js.Value
+	fnBlendFuncSeparate              js.Value
+	fnBlitFramebuffer                js.Value
+	fnBufferData                     js.Value
+	fnBufferSubData                  js.Value
+	fnCheckFramebufferStatus         js.Value
+	fnClear                          js.Value
+	fnClientWaitSync                 js.Value
+	fnColorMask                      js.Value
+	fnCompileShader                  js.Value
+	fnCreateBuffer                   js.Value
+	fnCreateFramebuffer              js.Value
+	fnCreateProgram                  js.Value
+	fnCreateQuery                    js.Value
+	fnCreateRenderbuffer             js.Value
+	fnCreateShader                   js.Value
+	fnCreateTexture                  js.Value
+	fnCreateVertexArray              js.Value
+	fnDeleteBuffer                   js.Value
+	fnDeleteFramebuffer              js.Value
+	fnDeleteProgram                  js.Value
+	fnDeleteQuery                    js.Value
+	fnDeleteRenderbuffer             js.Value
+	fnDeleteShader                   js.Value
+	fnDeleteSync                     js.Value
+	fnDeleteTexture                  js.Value
+	fnDeleteVertexArray              js.Value
+	fnDepthFunc                      js.Value
+	fnDepthMask                      js.Value
+	fnDisable                        js.Value
+	fnDisableVertexAttribArray       js.Value
+	fnDrawBuffers                    js.Value
+	fnDrawElements                   js.Value
+	fnEnable                         js.Value
+	fnEnableVertexAttribArray        js.Value
+	fnEndQuery                       js.Value
+	fnFenceSync                      js.Value
+	fnFramebufferRenderbuffer        js.Value
+	fnFramebufferTexture2D           js.Value
+	fnFlush                          js.Value
+	fnGetBufferSubData               js.Value
+	fnGetError                       js.Value
+	fnGetParameter                   js.Value
+	fnGetProgramInfoLog              js.Value
+	fnGetProgramParameter            js.Value
+	fnGetQueryParameter              js.Value
+	fnGetShaderInfoLog               js.Value
+	fnGetShaderParameter             js.Value
+	fnGetUniformLocation             js.Value
+	fnIsProgram                      js.Value
+	fnLinkProgram                    js.Value
+	fnPixelStorei                    js.Value
+	fnReadPixels                     js.Value
+	fnRenderbufferStorage            js.Value
+	fnRenderbufferStorageMultisample js.Value
+	fnScissor                        js.Value
+	fnShaderSource                   js.Value
+	fnStencilFunc                    js.Value
+	fnStencilMask                    js.Value
+	fnStencilOpSeparate              js.Value
+	fnTexImage2D                     js.Value
+	fnTexSubImage2D                  js.Value
+	fnTexParameteri                  js.Value
+	fnUniform1fv                     js.Value
+	fnUniform1i                      js.Value
+	fnUniform1iv                     js.Value
+	fnUniform2fv                     js.Value
+	fnUniform2iv                     js.Value
+	fnUniform3fv                     js.Value
+	fnUniform3iv                     js.Value
+	fnUniform4fv                     js.Value
+	fnUniform4iv                     js.Value
+	fnUniformMatrix2fv               js.Value
+	fnUniformMatrix3fv               js.Value
+	fnUniformMatrix4fv               js.Value
+	fnUseProgram                     js.Value
+	fnVertexAttribPointer            js.Value
+	fnViewport                       js.Value
 
 	buffers          values
 	framebuffers     values
 	programs         values
+	queries          values
 	renderbuffers    values
 	shaders          values
+	syncs            values
 	textures         values
 	vertexArrays     values
 	uniformLocations map[uint32]*values
@@ -150,79 +166,93 @@ func NewDefaultContext(v js.Value) (Context, error) {
 	// Passing a Go string to the JS world is expensive. This causes conversion to UTF-16 (#1438).
 	// In order to reduce the cost when calling functions, create the function objects by bind and use them.
 	g := &defaultContext{
-		fnActiveTexture:            v.Get("activeTexture").Call("bind", v),
-		fnAttachShader:             v.Get("attachShader").Call("bind", v),
-		fnBindAttribLocation:       v.Get("bindAttribLocation").Call("bind", v),
-		fnBindBuffer:               v.Get("bindBuffer").Call("bind", v),
-		fnBindFramebuffer:          v.Get("bindFramebuffer").Call("bind", v),
-		fnBindRenderbuffer:         v.Get("bindRenderbuffer").Call("bind", v),
-		fnBindTexture:              v.Get("bindTexture").Call("bind", v),
-		fnBindVertexArray:          v.Get("bindVertexArray").Call("bind", v),
-		fnBlendEquationSeparate:    v.Get("blendEquationSeparate").Call("bind", v),
-		fnBlendFuncSeparate:        v.Get("blendFuncSeparate").Call("bind", v),
-		fnBufferData:               v.Get("bufferData").Call("bind", v),
-		fnBufferSubData:            v.Get("bufferSubData").Call("bind", v),
-		fnCheckFramebufferStatus:   v.Get("checkFramebufferStatus").Call("bind", v),
-		fnClear:                    v.Get("clear").Call("bind", v),
-		fnColorMask:                v.Get("colorMask").Call("bind", v),
-		fnCompileShader:            v.Get("compileShader").Call("bind", v),
-		fnCreateBuffer:             v.Get("createBuffer").Call("bind", v),
-		fnCreateFramebuffer:        v.Get("createFramebuffer").Call("bind", v),
-		fnCreateProgram:            v.Get("createProgram").Call("bind", v),
-		fnCreateRenderbuffer:       v.Get("createRenderbuffer").Call("bind", v),
-		fnCreateShader:             v.Get("createShader").Call("bind", v),
-		fnCreateTexture:            v.Get("createTexture").Call("bind", v),
-		fnCreateVertexArray:        v.Get("createVertexArray").Call("bind", v),
-		fnDeleteBuffer:             v.Get("deleteBuffer").Call("bind", v),
-		fnDeleteFramebuffer:        v.Get("deleteFramebuffer").Call("bind", v),
-		fnDeleteProgram:            v.Get("deleteProgram").Call("bind", v),
-		fnDeleteRenderbuffer:       v.Get("deleteRenderbuffer").Call("bind", v),
-		fnDeleteShader:             v.Get("deleteShader").Call("bind", v),
-		fnDeleteTexture:            v.Get("deleteTexture").Call("bind", v),
-		fnDeleteVertexArray:        v.Get("deleteVertexArray").Call("bind", v),
-		fnDisable:                  v.Get("disable").Call("bind", v),
-		fnDisableVertexAttribArray: v.Get("disableVertexAttribArray").Call("bind", v),
-		fnDrawElements:             v.Get("drawElements").Call("bind", v),
-		fnEnable:                   v.Get("enable").Call("bind", v),
-		fnEnableVertexAttribArray:  v.Get("enableVertexAttribArray").Call("bind", v),
-		fnFramebufferRenderbuffer:  v.Get("framebufferRenderbuffer").Call("bind", v),
-		fnFramebufferTexture2D:     v.Get("framebufferTexture2D").Call("bind", v),
-		fnFlush:                    v.Get("flush").Call("bind", v),
-		fnGetError:                 v.Get("getError").Call("bind", v),
-		fnGetParameter:             v.Get("getParameter").Call("bind", v),
-		fnGetProgramInfoLog:        v.Get("getProgramInfoLog").Call("bind", v),
-		fnGetProgramParameter:      v.Get("getProgramParameter").Call("bind", v),
-		fnGetShaderInfoLog:         v.Get("getShaderInfoLog").Call("bind", v),
-		fnGetShaderParameter:       v.Get("getShaderParameter").Call("bind", v),
-		fnGetUniformLocation:       v.Get("getUniformLocation").Call("bind", v),
-		fnIsProgram:                v.Get("isProgram").Call("bind", v),
-		fnLinkProgram:              v.Get("linkProgram").Call("bind", v),
-		fnPixelStorei:              v.Get("pixelStorei").Call("bind", v),
-		fnReadPixels:               v.Get("readPixels").Call("bind", v),
-		fnRenderbufferStorage:      v.Get("renderbufferStorage").Call("bind", v),
-		fnScissor:                  v.Get("scissor").Call("bind", v),
-		fnShaderSource:             v.Get("shaderSource").Call("bind", v),
-		fnStencilFunc:              v.Get("stencilFunc").Call("bind", v),
-		fnStencilMask:              v.Get("stencilMask").Call("bind", v),
-		fnStencilOpSeparate:        v.Get("stencilOpSeparate").Call("bind", v),
-		fnTexImage2D:               v.Get("texImage2D").Call("bind", v),
-		fnTexSubImage2D:            v.Get("texSubImage2D").Call("bind", v),
-		fnTexParameteri:            v.Get("texParameteri").Call("bind", v),
-		fnUniform1fv:               v.Get("uniform1fv").Call("bind", v),
-		fnUniform1i:                v.Get("uniform1i").Call("bind", v),
-		fnUniform1iv:               v.Get("uniform1iv").Call("bind", v),
-		fnUniform2fv:               v.Get("uniform2fv").Call("bind", v),
-		fnUniform2iv:               v.Get("uniform2iv").Call("bind", v),
-		fnUniform3fv:               v.Get("uniform3fv").Call("bind", v),
-		fnUniform3iv:               v.Get("uniform3iv").Call("bind", v),
-		fnUniform4fv:               v.Get("uniform4fv").Call("bind", v),
-		fnUniform4iv:               v.Get("uniform4iv").Call("bind", v),
-		fnUniformMatrix2fv:         v.Get("uniformMatrix2fv").Call("bind", v),
-		fnUniformMatrix3fv:         v.Get("uniformMatrix3fv").Call("bind", v),
-		fnUniformMatrix4fv:         v.Get("uniformMatrix4fv").Call("bind", v),
-		fnUseProgram:               v.Get("useProgram").Call("bind", v),
-		fnVertexAttribPointer:      v.Get("vertexAttribPointer").Call("bind", v),
-		fnViewport:                 v.Get("viewport").Call("bind", v),
+		fnActiveTexture:                  v.Get("activeTexture").Call("bind", v),
+		fnAttachShader:                   v.Get("attachShader").Call("bind", v),
+		fnBeginQuery:                     v.Get("beginQuery").Call("bind", v),
+		fnBindAttribLocation:             v.Get("bindAttribLocation").Call("bind", v),
+		fnBindBuffer:                     v.Get("bindBuffer").Call("bind", v),
+		fnBindFramebuffer:                v.Get("bindFramebuffer").Call("bind", v),
+		fnBindRenderbuffer:               v.Get("bindRenderbuffer").Call("bind", v),
+		fnBindTexture:                    v.Get("bindTexture").Call("bind", v),
+		fnBindVertexArray:                v.Get("bindVertexArray").Call("bind", v),
+		fnBlendEquationSeparate:          v.Get("blendEquationSeparate").Call("bind", v),
+		fnBlendFuncSeparate:              v.Get("blendFuncSeparate").Call("bind", v),
+		fnBlitFramebuffer:                v.Get("blitFramebuffer").Call("bind", v),
+		fnBufferData:                     v.Get("bufferData").Call("bind", v),
+		fnBufferSubData:                  v.Get("bufferSubData").Call("bind", v),
+		fnCheckFramebufferStatus:         v.Get("checkFramebufferStatus").Call("bind", v),
+		fnClear:                          v.Get("clear").Call("bind", v),
+		fnClientWaitSync:                 v.Get("clientWaitSync").Call("bind", v),
+		fnColorMask:                      v.Get("colorMask").Call("bind", v),
+		fnCompileShader:                  v.Get("compileShader").Call("bind", v),
+		fnCreateBuffer:                   v.Get("createBuffer").Call("bind", v),
+		fnCreateFramebuffer:              v.Get("createFramebuffer").Call("bind", v),
+		fnCreateProgram:                  v.Get("createProgram").Call("bind", v),
+		fnCreateQuery:                    v.Get("createQuery").Call("bind", v),
+		fnCreateRenderbuffer:             v.Get("createRenderbuffer").Call("bind", v),
+		fnCreateShader:                   v.Get("createShader").Call("bind", v),
+		fnCreateTexture:                  v.Get("createTexture").Call("bind", v),
+		fnCreateVertexArray:              v.Get("createVertexArray").Call("bind", v),
+		fnDeleteBuffer:                   v.Get("deleteBuffer").Call("bind", v),
+		fnDeleteFramebuffer:              v.Get("deleteFramebuffer").Call("bind", v),
+		fnDeleteProgram:                  v.Get("deleteProgram").Call("bind", v),
+		fnDeleteQuery:                    v.Get("deleteQuery").Call("bind", v),
+		fnDeleteRenderbuffer:             v.Get("deleteRenderbuffer").Call("bind", v),
+		fnDeleteShader:                   v.Get("deleteShader").Call("bind", v),
+		fnDeleteSync:                     v.Get("deleteSync").Call("bind", v),
+		fnDeleteTexture:                  v.Get("deleteTexture").Call("bind", v),
+		fnDeleteVertexArray:              v.Get("deleteVertexArray").Call("bind", v),
+		fnDepthFunc:                      v.Get("depthFunc").Call("bind", v),
+		fnDepthMask:                      v.Get("depthMask").Call("bind", v),
+		fnDisable:                        v.Get("disable").Call("bind", v),
+		fnDisableVertexAttribArray:       v.Get("disableVertexAttribArray").Call("bind", v),
+		fnDrawBuffers:                    v.Get("drawBuffers").Call("bind", v),
+		fnDrawElements:                   v.Get("drawElements").Call("bind", v),
+		fnEnable:                         v.Get("enable").Call("bind", v),
+		fnEnableVertexAttribArray:        v.Get("enableVertexAttribArray").Call("bind", v),
+		fnEndQuery:                       v.Get("endQuery").Call("bind", v),
+		fnFenceSync:                      v.Get("fenceSync").Call("bind", v),
+		fnFramebufferRenderbuffer:        v.Get("framebufferRenderbuffer").Call("bind", v),
+		fnFramebufferTexture2D:           v.Get("framebufferTexture2D").Call("bind", v),
+		fnFlush:                          v.Get("flush").Call("bind", v),
+		fnGetBufferSubData:               v.Get("getBufferSubData").Call("bind", v),
+		fnGetError:                       v.Get("getError").Call("bind", v),
+		fnGetParameter:                   v.Get("getParameter").Call("bind", v),
+		fnGetProgramInfoLog:              v.Get("getProgramInfoLog").Call("bind", v),
+		fnGetProgramParameter:            v.Get("getProgramParameter").Call("bind", v),
+		fnGetQueryParameter:              v.Get("getQueryParameter").Call("bind", v),
+		fnGetShaderInfoLog:               v.Get("getShaderInfoLog").Call("bind", v),
+		fnGetShaderParameter:             v.Get("getShaderParameter").Call("bind", v),
+		fnGetUniformLocation:             v.Get("getUniformLocation").Call("bind", v),
+		fnIsProgram:                      v.Get("isProgram").Call("bind", v),
+		fnLinkProgram:                    v.Get("linkProgram").Call("bind", v),
+		fnPixelStorei:                    v.Get("pixelStorei").Call("bind", v),
+		fnReadPixels:                     v.Get("readPixels").Call("bind", v),
+		fnRenderbufferStorage:            v.Get("renderbufferStorage").Call("bind", v),
+		fnRenderbufferStorageMultisample: v.Get("renderbufferStorageMultisample").Call("bind", v),
+		fnScissor:                        v.Get("scissor").Call("bind", v),
+		fnShaderSource:                   v.Get("shaderSource").Call("bind", v),
+		fnStencilFunc:                    v.Get("stencilFunc").Call("bind", v),
+		fnStencilMask:                    v.Get("stencilMask").Call("bind", v),
+		fnStencilOpSeparate:              v.Get("stencilOpSeparate").Call("bind", v),
+		fnTexImage2D:                     v.Get("texImage2D").Call("bind", v),
+		fnTexSubImage2D:                  v.Get("texSubImage2D").Call("bind", v),
+		fnTexParameteri:                  v.Get("texParameteri").Call("bind", v),
+		fnUniform1fv:                     v.Get("uniform1fv").Call("bind", v),
+		fnUniform1i:                      v.Get("uniform1i").Call("bind", v),
+		fnUniform1iv:                     v.Get("uniform1iv").Call("bind", v),
+		fnUniform2fv:                     v.Get("uniform2fv").Call("bind", v),
+		fnUniform2iv:                     v.Get("uniform2iv").Call("bind", v),
+		fnUniform3fv:                     v.Get("uniform3fv").Call("bind", v),
+		fnUniform3iv:                     v.Get("uniform3iv").Call("bind", v),
+		fnUniform4fv:                     v.Get("uniform4fv").Call("bind", v),
+		fnUniform4iv:                     v.Get("uniform4iv").Call("bind", v),
+		fnUniformMatrix2fv:               v.Get("uniformMatrix2fv").Call("bind", v),
+		fnUniformMatrix3fv:               v.Get("uniformMatrix3fv").Call("bind", v),
+		fnUniformMatrix4fv:               v.Get("uniformMatrix4fv").Call("bind", v),
+		fnUseProgram:                     v.Get("useProgram").Call("bind", v),
+		fnVertexAttribPointer:            v.Get("vertexAttribPointer").Call("bind", v),
+		fnViewport:                       v.Get("viewport").Call("bind", v),
 	}
 
 	return g, nil
@@ -250,6 +280,10 @@ func (c *defaultContext) AttachShader(program uint32, shader uint32) {
 	c.fnAttachShader.Invoke(c.programs.get(program), c.shaders.get(shader))
 }
 
+func (c *defaultContext) BeginQuery(target uint32, query uint32) {
+	c.fnBeginQuery.Invoke(target, c.queries.get(query))
+}
+
 func (c *defaultContext) BindAttribLocation(program uint32, index uint32, name string) {
 	c.fnBindAttribLocation.Invoke(c.programs.get(program), index, name)
 }
@@ -258,10 +292,22 @@ func (c *defaultContext) BindBuffer(target uint32, buffer uint32) {
 	c.fnBindBuffer.Invoke(target, c.buffers.get(buffer))
 }
 
+func (c *defaultContext) BindFragDataLocation(program uint32, colorNumber uint32, name string) {
+	panic("gl: BindFragDataLocation is not available with WebGL")
+}
+
+func (c *defaultContext) BindFragDataLocationIndexed(program uint32, colorNumber uint32, index uint32, name string) {
+	panic("gl: BindFragDataLocationIndexed is not available with WebGL")
+}
+
 func (c *defaultContext) BindFramebuffer(target uint32, framebuffer uint32) {
 	c.fnBindFramebuffer.Invoke(target, c.framebuffers.get(framebuffer))
 }
 
+func (c *defaultContext) BindImageTexture(unit uint32, texture uint32, level int32, layered bool, layer int32, access uint32, format uint32) {
+	panic("gl: BindImageTexture is not available with WebGL")
+}
+
 func (c *defaultContext) BindRenderbuffer(target uint32, renderbuffer uint32) {
 	c.fnBindRenderbuffer.Invoke(target, c.renderbuffers.get(renderbuffer))
 }
@@ -282,6 +328,10 @@ func (c *defaultContext) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlph
 	c.fnBlendFuncSeparate.Invoke(srcRGB, dstRGB, srcAlpha, dstAlpha)
 }
 
+func (c *defaultContext) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+	c.fnBlitFramebuffer.Invoke(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
 func (c *defaultContext) BufferInit(target uint32, size int, usage uint32) {
 	c.fnBufferData.Invoke(target, size, usage)
 }
@@ -300,6 +350,10 @@ func (c *defaultContext) Clear(mask uint32) {
 	c.fnClear.Invoke(mask)
 }
 
+func (c *defaultContext) ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	return uint32(c.fnClientWaitSync.Invoke(c.syncs.get(uint32(sync)), flags, timeout).Int())
+}
+
 func (c *defaultContext) ColorMask(red, green, blue, alpha bool) {
 	c.fnColorMask.Invoke(red, green, blue, alpha)
 }
@@ -324,6 +378,10 @@ func (c *defaultContext) CreateRenderbuffer() uint32 {
 	return c.renderbuffers.create(c.fnCreateRenderbuffer.Invoke())
 }
 
+func (c *defaultContext) CreateQuery() uint32 {
+	return c.queries.create(c.fnCreateQuery.Invoke())
+}
+
 func (c *defaultContext) CreateShader(xtype uint32) uint32 {
 	return c.shaders.create(c.fnCreateShader.Invoke(xtype))
 }
@@ -352,6 +410,11 @@ func (c *defaultContext) DeleteProgram(program uint32) {
 	delete(c.uniformLocations, program)
 }
 
+func (c *defaultContext) DeleteQuery(query uint32) {
+	c.fnDeleteQuery.Invoke(c.queries.get(query))
+	c.queries.delete(query)
+}
+
 func (c *defaultContext) DeleteRenderbuffer(renderbuffer uint32) {
 	c.fnDeleteRenderbuffer.Invoke(c.renderbuffers.get(renderbuffer))
 	c.renderbuffers.delete(renderbuffer)
@@ -362,6 +425,11 @@ func (c *defaultContext) DeleteShader(shader uint32) {
 	c.shaders.delete(shader)
 }
 
+func (c *defaultContext) DeleteSync(sync uintptr) {
+	c.fnDeleteSync.Invoke(c.syncs.get(uint32(sync)))
+	c.syncs.delete(uint32(sync))
+}
+
 func (c *defaultContext) DeleteTexture(texture uint32) {
 	c.fnDeleteTexture.Invoke(c.textures.get(texture))
 	c.textures.delete(texture)
@@ -372,6 +440,14 @@ func (c *defaultContext) DeleteVertexArray(array uint32) {
 	c.textures.delete(array)
 }
 
+func (c *defaultContext) DepthFunc(func_ uint32) {
+	c.fnDepthFunc.Invoke(func_)
+}
+
+func (c *defaultContext) DepthMask(flag bool) {
+	c.fnDepthMask.Invoke(flag)
+}
+
 func (c *defaultContext) Disable(cap uint32) {
 	c.fnDisable.Invoke(cap)
 }
@@ -380,6 +456,18 @@ func (c *defaultContext) DisableVertexAttribArray(index uint32) {
 	c.fnDisableVertexAttribArray.Invoke(index)
 }
 
+func (c *defaultContext) DispatchCompute(numGroupsX uint32, numGroupsY uint32, numGroupsZ uint32) {
+	panic("gl: DispatchCompute is not available with WebGL")
+}
+
+func (c *defaultContext) DrawBuffers(bufs []uint32) {
+	arr := make([]any, len(bufs))
+	for i, b := range bufs {
+		arr[i] = b
+	}
+	c.fnDrawBuffers.Invoke(arr)
+}
+
 func (c *defaultContext) DrawElements(mode uint32, count int32, xtype uint32, offset int) {
 	c.fnDrawElements.Invoke(mode, count, xtype, offset)
 }
@@ -392,6 +480,14 @@ func (c *defaultContext) EnableVertexAttribArray(index uint32) {
 	c.fnEnableVertexAttribArray.Invoke(index)
 }
 
+func (c *defaultContext) EndQuery(target uint32) {
+	c.fnEndQuery.Invoke(target)
+}
+
+func (c *defaultContext) FenceSync(condition uint32, flags uint32) uintptr {
+	return uintptr(c.syncs.create(c.fnFenceSync.Invoke(condition, flags)))
+}
+
 func (c *defaultContext) Flush() {
 	c.fnFlush.Invoke()
 }
@@ -404,6 +500,13 @@ func (c *defaultContext) FramebufferTexture2D(target uint32, attachment uint32,
 	c.fnFramebufferTexture2D.Invoke(target, attachment, textarget, c.textures.get(texture), level)
 }
 
+func (c *defaultContext) GetBufferSubData(target uint32, offset int, data []byte) {
+	l := len(data)
+	arr := jsutil.TemporaryUint8ArrayFromUint8Slice(l, nil)
+	c.fnGetBufferSubData.Invoke(target, offset, arr, 0, l)
+	js.CopyBytesToGo(data, arr)
+}
+
 func (c *defaultContext) GetError() uint32 {
 	return uint32(c.fnGetError.Invoke().Int())
 }
@@ -417,7 +520,13 @@ func (c *defaultContext) GetInteger(pname uint32) int {
 			return 0
 		}
 		return int(id)
-	case MAX_TEXTURE_SIZE:
+	case MAX_COLOR_ATTACHMENTS, MAX_DRAW_BUFFERS, MAX_SAMPLES, MAX_TEXTURE_SIZE:
+		return ret.Int()
+	case MAX_TEXTURE_MAX_ANISOTROPY:
+		// The value is null without EXT_texture_filter_anisotropic.
+		if ret.Type() != js.TypeNumber {
+			return 0
+		}
 		return ret.Int()
 	default:
 		panic(fmt.Sprintf("gl: unexpected pname at GetInteger: %d", pname))
@@ -443,6 +552,25 @@ func (c *defaultContext) GetProgrami(program uint32, pname uint32) int {
 	}
 }
 
+func (c *defaultContext) GetQueryObjectui(query uint32, pname uint32) uint32 {
+	v := c.fnGetQueryParameter.Invoke(c.queries.get(query), pname)
+	switch v.Type() {
+	case js.TypeNumber:
+		return uint32(v.Int())
+	case js.TypeBoolean:
+		if v.Bool() {
+			return TRUE
+		}
+		return FALSE
+	default:
+		panic(fmt.Sprintf("gl: unexpected return type at GetQueryObjectui: %v", v))
+	}
+}
+
+func (c *defaultContext) GetQueryObjectui64(query uint32, pname uint32) uint64 {
+	panic("gl: GetQueryObjectui64 is not available with WebGL")
+}
+
 func (c *defaultContext) GetShaderInfoLog(shader uint32) string {
 	return c.fnGetShaderInfoLog.Invoke(c.shaders.get(shader)).String()
 }
@@ -481,21 +609,41 @@ func (c *defaultContext) IsProgram(program uint32) bool {
 	return c.fnIsProgram.Invoke(c.programs.get(program)).Bool()
 }
 
+func (c *defaultContext) IsTimerQueryAvailable() bool {
+	return false
+}
+
 func (c *defaultContext) LinkProgram(program uint32) {
 	c.fnLinkProgram.Invoke(c.programs.get(program))
 }
 
+func (c *defaultContext) MemoryBarrier(barriers uint32) {
+	panic("gl: MemoryBarrier is not available with WebGL")
+}
+
 func (c *defaultContext) PixelStorei(pname uint32, param int32) {
 	c.fnPixelStorei.Invoke(pname, param)
 }
 
+func (c *defaultContext) QueryCounter(query uint32, target uint32) {
+	panic("gl: QueryCounter is not available with WebGL")
+}
+
 func (c *defaultContext) ReadPixels(dst []byte, x int32, y int32, width int32, height int32, format uint32, xtype uint32) {
 	if dst == nil {
 		c.fnReadPixels.Invoke(x, y, width, height, format, xtype, 0)
 		return
 	}
 	p := jsutil.TemporaryUint8ArrayFromUint8Slice(len(dst), nil)
-	c.fnReadPixels.Invoke(x, y, width, height, format, xtype, p)
+	// WebGL requires a typed array matching with xtype. The array shares the same buffer with p.
+	arr := p
+	switch xtype {
+	case UNSIGNED_SHORT:
+		arr = jsutil.TemporaryUint16Array(len(dst)/2, nil)
+	case FLOAT:
+		arr = jsutil.TemporaryFloat32Array(len(dst)/4, nil)
+	}
+	c.fnReadPixels.Invoke(x, y, width, height, format, xtype, arr)
 	js.CopyBytesToGo(dst, p)
 }
 
@@ -503,6 +651,10 @@ func (c *defaultContext) RenderbufferStorage(target uint32, internalFormat uint3
 	c.fnRenderbufferStorage.Invoke(target, internalFormat, width, height)
 }
 
+func (c *defaultContext) RenderbufferStorageMultisample(target uint32, samples int32, internalFormat uint32, width int32, height int32) {
+	c.fnRenderbufferStorageMultisample.Invoke(target, samples, internalFormat, width, height)
+}
+
 func (c *defaultContext) Scissor(x, y, width, height int32) {
 	c.fnScissor.Invoke(x, y, width, height)
 }
@@ -532,6 +684,13 @@ func (c *defaultContext) TexParameteri(target uint32, pname uint32, param int32)
 
 func (c *defaultContext) TexSubImage2D(target uint32, level int32, xoffset int32, yoffset int32, width int32, height int32, format uint32, xtype uint32, pixels []byte) {
 	arr := jsutil.TemporaryUint8ArrayFromUint8Slice(len(pixels), pixels)
+	// WebGL requires a typed array matching with xtype. The array shares the same buffer with arr.
+	switch xtype {
+	case UNSIGNED_SHORT:
+		arr = jsutil.TemporaryUint16Array(len(pixels)/2, nil)
+	case FLOAT:
+		arr = jsutil.TemporaryFloat32Array(len(pixels)/4, nil)
+	}
 	// void texSubImage2D(GLenum target, GLint level, GLint xoffset, GLint yoffset,
 	//                    GLsizei width, GLsizei height,
 	//                    GLenum format, GLenum type, ArrayBufferView pixels, srcOffset);
@@ -24,78 +24,100 @@ import (
 )
 
 type defaultContext struct {
-	gpActiveTexture            uintptr
-	gpAttachShader             uintptr
-	gpBindAttribLocation       uintptr
-	gpBindBuffer               uintptr
-	gpBindFramebuffer          uintptr
-	gpBindRenderbuffer         uintptr
-	gpBindTexture              uintptr
-	gpBindVertexArray          uintptr
-	gpBlendEquationSeparate    uintptr
-	gpBlendFuncSeparate        uintptr
-	gpBufferData               uintptr
-	gpBufferSubData            uintptr
-	gpCheckFramebufferStatus   uintptr
-	gpClear                    uintptr
-	gpColorMask                uintptr
-	gpCompileShader            uintptr
-	gpCreateProgram            uintptr
-	gpCreateShader             uintptr
-	gpDeleteBuffers            uintptr
-	gpDeleteFramebuffers       uintptr
-	gpDeleteProgram            uintptr
-	gpDeleteRenderbuffers      uintptr
-	gpDeleteShader             uintptr
-	gpDeleteTextures           uintptr
-	gpDeleteVertexArrays       uintptr
-	gpDisable                  uintptr
-	gpDisableVertexAttribArray uintptr
-	gpDrawElements             uintptr
-	gpEnable                   uintptr
-	gpEnableVertexAttribArray  uintptr
-	gpFlush                    uintptr
-	gpFramebufferRenderbuffer  uintptr
-	gpFramebufferTexture2D     uintptr
-	gpGenBuffers               uintptr
-	gpGenFramebuffers          uintptr
-	gpGenRenderbuffers         uintptr
-	gpGenTextures              uintptr
-	gpGenVertexArrays          uintptr
-	gpGetError                 uintptr
-	gpGetIntegerv              uintptr
-	gpGetProgramInfoLog        uintptr
-	gpGetProgramiv             uintptr
-	gpGetShaderInfoLog         uintptr
-	gpGetShaderiv              uintptr
-	gpGetUniformLocation       uintptr
-	gpIsProgram                uintptr
-	gpLinkProgram              uintptr
-	gpPixelStorei              uintptr
-	gpReadPixels               uintptr
-	gpRenderbufferStorage      uintptr
-	gpScissor                  uintptr
-	gpShaderSource             uintptr
-	gpStencilFunc              uintptr
-	gpStencilOpSeparate        uintptr
-	gpTexImage2D               uintptr
-	gpTexParameteri            uintptr
-	gpTexSubImage2D            uintptr
-	gpUniform1fv               uintptr
-	gpUniform1i                uintptr
-	gpUniform1iv               uintptr
-	gpUniform2fv               uintptr
-	gpUniform2iv               uintptr
-	gpUniform3fv               uintptr
-	gpUniform3iv               uintptr
-	gpUniform4fv               uintptr
-	gpUniform4iv               uintptr
-	gpUniformMatrix2fv         uintptr
-	gpUniformMatrix3fv         uintptr
-	gpUniformMatrix4fv         uintptr
-	gpUseProgram               uintptr
-	gpVertexAttribPointer      uintptr
-	gpViewport                 uintptr
+	gpActiveTexture                  uintptr
+	gpAttachShader                   uintptr
+	gpBeginQuery                     uintptr
+	gpBindAttribLocation             uintptr
+	gpBindBuffer                     uintptr
+	gpBindFragDataLocation           uintptr
+	gpBindFragDataLocationIndexed    uintptr
+	gpBindFramebuffer                uintptr
+	gpBindImageTexture               uintptr
+	gpBindRenderbuffer               uintptr
+	gpBindTexture                    uintptr
+	gpBindVertexArray                uintptr
+	gpBlendEquationSeparate          uintptr
+	gpBlendFuncSeparate              uintptr
+	gpBlitFramebuffer                uintptr
+	gpBufferData                     uintptr
+	gpBufferSubData                  uintptr
+	gpCheckFramebufferStatus         uintptr
+	gpClear                          uintptr
+	gpClientWaitSync                 uintptr
+	gpColorMask                      uintptr
+	gpCompileShader                  uintptr
+	gpCreateProgram                  uintptr
+	gpCreateShader                   uintptr
+	gpDeleteBuffers                  uintptr
+	gpDeleteFramebuffers             uintptr
+	gpDeleteProgram                  uintptr
+	gpDeleteQueries                  uintptr
+	gpDeleteRenderbuffers            uintptr
+	gpDeleteShader                   uintptr
+	gpDeleteSync                     uintptr
+	gpDeleteTextures                 uintptr
+	gpDeleteVertexArrays             uintptr
+	gpDepthFunc                      uintptr
+	gpDepthMask                      uintptr
+	gpDisable                        uintptr
+	gpDisableVertexAttribArray       uintptr
+	gpDispatchCompute                uintptr
+	gpDrawBuffers                    uintptr
+	gpDrawElements                   uintptr
+	gpEnable                         uintptr
+	gpEnableVertexAttribArray        uintptr
+	gpEndQuery                       uintptr
+	gpFenceSync                      uintptr
+	gpFlush                          uintptr
+	gpFramebufferRenderbuffer        uintptr
+	gpFramebufferTexture2D           uintptr
+	gpGenBuffers                     uintptr
+	gpGenFramebuffers                uintptr
+	gpGenQueries                     uintptr
+	gpGenRenderbuffers               uintptr
+	gpGenTextures                    uintptr
+	gpGenVertexArrays                uintptr
+	gpGetError                       uintptr
+	gpGetIntegerv                    uintptr
+	gpGetProgramInfoLog              uintptr
+	gpGetProgramiv                   uintptr
+	gpGetQueryObjectui64v            uintptr
+	gpGetQueryObjectuiv              uintptr
+	gpGetShaderInfoLog               uintptr
+	gpGetShaderiv                    uintptr
+	gpGetUniformLocation             uintptr
+	gpIsProgram                      uintptr
+	gpLinkProgram                    uintptr
+	gpMapBufferRange                 uintptr
+	gpMemoryBarrier                  uintptr
+	gpPixelStorei                    uintptr
+	gpQueryCounter                   uintptr
+	gpReadPixels                     uintptr
+	gpRenderbufferStorage            uintptr
+	gpRenderbufferStorageMultisample uintptr
+	gpScissor                        uintptr
+	gpShaderSource                   uintptr
+	gpStencilFunc                    uintptr
+	gpStencilOpSeparate              uintptr
+	gpTexImage2D                     uintptr
+	gpTexParameteri                  uintptr
+	gpTexSubImage2D                  uintptr
+	gpUnmapBuffer                    uintptr
+	gpUniform1fv                     uintptr
+	gpUniform1i                      uintptr
+	gpUniform1iv                     uintptr
+	gpUniform2fv                     uintptr
+	gpUniform2iv                     uintptr
+	gpUniform3fv                     uintptr
+	gpUniform3iv                     uintptr
+	gpUniform4fv                     uintptr
+	gpUniform4iv                     uintptr
+	gpUniformMatrix2fv               uintptr
+	gpUniformMatrix3fv               uintptr
+	gpUniformMatrix4fv               uintptr
+	gpUseProgram                     uintptr
+	gpVertexAttribPointer            uintptr
+	gpViewport                       uintptr
 
 	isES bool
 }
@@ -127,6 +149,10 @@ func (c *defaultContext) AttachShader(program uint32, shader uint32) {
 	purego.SyscallN(c.gpAttachShader, uintptr(program), uintptr(shader))
 }
 
+func (c *defaultContext) BeginQuery(target uint32, query uint32) {
+	purego.SyscallN(c.gpBeginQuery, uintptr(target), uintptr(query))
+}
+
 func (c *defaultContext) BindAttribLocation(program uint32, index uint32, name string) {
 	cname, free := cStr(name)
 	defer free()
@@ -137,10 +163,32 @@ func (c *defaultContext) BindBuffer(target uint32, buffer uint32) {
 	purego.SyscallN(c.gpBindBuffer, uintptr(target), uintptr(buffer))
 }
 
+func (c *defaultContext) BindFragDataLocation(program uint32, colorNumber uint32, name string) {
+	if c.gpBindFragDataLocation == 0 {
+		return
+	}
+	cname, free := cStr(name)
+	defer free()
+	purego.SyscallN(c.gpBindFragDataLocation, uintptr(program), uintptr(colorNumber), uintptr(unsafe.Pointer(cname)))
+}
+
+func (c *defaultContext) BindFragDataLocationIndexed(program uint32, colorNumber uint32, index uint32, name string) {
+	if c.gpBindFragDataLocationIndexed == 0 {
+		return
+	}
+	cname, free := cStr(name)
+	defer free()
+	purego.SyscallN(c.gpBindFragDataLocationIndexed, uintptr(program), uintptr(colorNumber), uintptr(index), uintptr(unsafe.Pointer(cname)))
+}
+
 func (c *defaultContext) BindFramebuffer(target uint32, framebuffer uint32) {
 	purego.SyscallN(c.gpBindFramebuffer, uintptr(target), uintptr(framebuffer))
 }
 
+func (c *defaultContext) BindImageTexture(unit uint32, texture uint32, level int32, layered bool, layer int32, access uint32, format uint32) {
+	purego.SyscallN(c.gpBindImageTexture, uintptr(unit), uintptr(texture), uintptr(level), uintptr(boolToInt(layered)), uintptr(layer), uintptr(access), uintptr(format))
+}
+
 func (c *defaultContext) BindRenderbuffer(target uint32, renderbuffer uint32) {
 	purego.SyscallN(c.gpBindRenderbuffer, uintptr(target), uintptr(renderbuffer))
 }
@@ -161,6 +209,10 @@ func (c *defaultContext) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlph
 	purego.SyscallN(c.gpBlendFuncSeparate, uintptr(srcRGB), uintptr(dstRGB), uintptr(srcAlpha), uintptr(dstAlpha))
 }
 
+func (c *defaultContext) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+	purego.SyscallN(c.gpBlitFramebuffer, uintptr(srcX0), uintptr(srcY0), uintptr(srcX1), uintptr(srcY1), uintptr(dstX0), uintptr(dstY0), uintptr(dstX1), uintptr(dstY1), uintptr(mask), uintptr(filter))
+}
+
 func (c *defaultContext) BufferInit(target uint32, size int, usage uint32) {
 	purego.SyscallN(c.gpBufferData, uintptr(target), uintptr(size), 0, uintptr(usage))
 }
@@ -179,6 +231,11 @@ func (c *defaultContext) Clear(mask uint32) {
 	purego.SyscallN(c.gpClear, uintptr(mask))
 }
 
+func (c *defaultContext) ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	ret, _, _ := purego.SyscallN(c.gpClientWaitSync, sync, uintptr(flags), uintptr(timeout))
+	return uint32(ret)
+}
+
 func (c *defaultContext) ColorMask(red bool, green bool, blue bool, alpha bool) {
 	purego.SyscallN(c.gpColorMask, uintptr(boolToInt(red)), uintptr(boolToInt(green)), uintptr(boolToInt(blue)), uintptr(boolToInt(alpha)))
 }
@@ -210,6 +267,12 @@ func (c *defaultContext) CreateRenderbuffer() uint32 {
 	return renderbuffer
 }
 
+func (c *defaultContext) CreateQuery() uint32 {
+	var query uint32
+	purego.SyscallN(c.gpGenQueries, 1, uintptr(unsafe.Pointer(&query)))
+	return query
+}
+
 func (c *defaultContext) CreateShader(xtype uint32) uint32 {
 	ret, _, _ := purego.SyscallN(c.gpCreateShader, uintptr(xtype))
 	return uint32(ret)
@@ -239,6 +302,10 @@ func (c *defaultContext) DeleteProgram(program uint32) {
 	purego.SyscallN(c.gpDeleteProgram, uintptr(program))
 }
 
+func (c *defaultContext) DeleteQuery(query uint32) {
+	purego.SyscallN(c.gpDeleteQueries, 1, uintptr(unsafe.Pointer(&query)))
+}
+
 func (c *defaultContext) DeleteRenderbuffer(renderbuffer uint32) {
 	purego.SyscallN(c.gpDeleteRenderbuffers, 1, uintptr(unsafe.Pointer(&renderbuffer)))
 }
@@ -247,6 +314,10 @@ func (c *defaultContext) DeleteShader(shader uint32) {
 	purego.SyscallN(c.gpDeleteShader, uintptr(shader))
 }
 
+func (c *defaultContext) DeleteSync(sync uintptr) {
+	purego.SyscallN(c.gpDeleteSync, sync)
+}
+
 func (c *defaultContext) DeleteTexture(texture uint32) {
 	purego.SyscallN(c.gpDeleteTextures, 1, uintptr(unsafe.Pointer(&texture)))
 }
@@ -255,6 +326,14 @@ func (c *defaultContext) DeleteVertexArray(array uint32) {
 	purego.SyscallN(c.gpDeleteVertexArrays, 1, uintptr(unsafe.Pointer(&array)))
 }
 
+func (c *defaultContext) DepthFunc(xfunc uint32) {
+	purego.SyscallN(c.gpDepthFunc, uintptr(xfunc))
+}
+
+func (c *defaultContext) DepthMask(flag bool) {
+	purego.SyscallN(c.gpDepthMask, uintptr(boolToInt(flag)))
+}
+
 func (c *defaultContext) Disable(cap uint32) {
 	purego.SyscallN(c.gpDisable, uintptr(cap))
 }
@@ -263,6 +342,14 @@ func (c *defaultContext) DisableVertexAttribArray(index uint32) {
 	purego.SyscallN(c.gpDisableVertexAttribArray, uintptr(index))
 }
 
+func (c *defaultContext) DispatchCompute(numGroupsX uint32, numGroupsY uint32, numGroupsZ uint32) {
+	purego.SyscallN(c.gpDispatchCompute, uintptr(numGroupsX), uintptr(numGroupsY), uintptr(numGroupsZ))
+}
+
+func (c *defaultContext) DrawBuffers(bufs []uint32) {
+	purego.SyscallN(c.gpDrawBuffers, uintptr(len(bufs)), uintptr(unsafe.Pointer(&bufs[0])))
+}
+
 func (c *defaultContext) DrawElements(mode uint32, count int32, xtype uint32, offset int) {
 	purego.SyscallN(c.gpDrawElements, uintptr(mode), uintptr(count), uintptr(xtype), uintptr(offset))
 }
@@ -275,6 +362,15 @@ func (c *defaultContext) EnableVertexAttribArray(index uint32) {
 	purego.SyscallN(c.gpEnableVertexAttribArray, uintptr(index))
 }
 
+func (c *defaultContext) EndQuery(target uint32) {
+	purego.SyscallN(c.gpEndQuery, uintptr(target))
+}
+
+func (c *defaultContext) FenceSync(condition uint32, flags uint32) uintptr {
+	ret, _, _ := purego.SyscallN(c.gpFenceSync, uintptr(condition), uintptr(flags))
+	return ret
+}
+
 func (c *defaultContext) Flush() {
 	purego.SyscallN(c.gpFlush)
 }
@@ -287,6 +383,16 @@ func (c *defaultContext) FramebufferTexture2D(target uint32, attachment uint32,
 	purego.SyscallN(c.gpFramebufferTexture2D, uintptr(target), uintptr(attachment), uintptr(textarget), uintptr(texture), uintptr(level))
 }
 
+func (c *defaultContext) GetBufferSubData(target uint32, offset int, data []byte) {
+	// glGetBufferSubData is not available in OpenGL ES. Map the buffer and copy the data instead.
+	ptr, _, _ := purego.SyscallN(c.gpMapBufferRange, uintptr(target), uintptr(offset), uintptr(len(data)), MAP_READ_BIT)
+	if ptr == 0 {
+		return
+	}
+	copy(data, unsafe.Slice((*byte)(*(*unsafe.Pointer)(unsafe.Pointer(&ptr))), len(data)))
+	purego.SyscallN(c.gpUnmapBuffer, uintptr(target))
+}
+
 func (c *defaultContext) GetError() uint32 {
 	ret, _, _ := purego.SyscallN(c.gpGetError)
 	return uint32(ret)
@@ -314,6 +420,18 @@ func (c *defaultContext) GetProgrami(program uint32, pname uint32) int {
 	return int(dst)
 }
 
+func (c *defaultContext) GetQueryObjectui(query uint32, pname uint32) uint32 {
+	var dst uint32
+	purego.SyscallN(c.gpGetQueryObjectuiv, uintptr(query), uintptr(pname), uintptr(unsafe.Pointer(&dst)))
+	return dst
+}
+
+func (c *defaultContext) GetQueryObjectui64(query uint32, pname uint32) uint64 {
+	var dst uint64
+	purego.SyscallN(c.gpGetQueryObjectui64v, uintptr(query), uintptr(pname), uintptr(unsafe.Pointer(&dst)))
+	return dst
+}
+
 func (c *defaultContext) GetShaderInfoLog(shader uint32) string {
 	bufSize := c.GetShaderi(shader, INFO_LOG_LENGTH)
 	if bufSize == 0 {
@@ -342,22 +460,43 @@ func (c *defaultContext) IsProgram(program uint32) bool {
 	return byte(ret) != 0
 }
 
+func (c *defaultContext) IsTimerQueryAvailable() bool {
+	return c.gpQueryCounter != 0 && c.gpGetQueryObjectui64v != 0
+}
+
 func (c *defaultContext) LinkProgram(program uint32) {
 	purego.SyscallN(c.gpLinkProgram, uintptr(program))
 }
 
+func (c *defaultContext) MemoryBarrier(barriers uint32) {
+	purego.SyscallN(c.gpMemoryBarrier, uintptr(barriers))
+}
+
 func (c *defaultContext) PixelStorei(pname uint32, param int32) {
 	purego.SyscallN(c.gpPixelStorei, uintptr(pname), uintptr(param))
 }
 
+func (c *defaultContext) QueryCounter(query uint32, target uint32) {
+	purego.SyscallN(c.gpQueryCounter, uintptr(query), uintptr(target))
+}
+
 func (c *defaultContext) ReadPixels(dst []byte, x int32, y int32, width int32, height int32, format uint32, xtype uint32) {
-	purego.SyscallN(c.gpReadPixels, uintptr(x), uintptr(y), uintptr(width), uintptr(height), uintptr(format), uintptr(xtype), uintptr(unsafe.Pointer(&dst[0])))
+	// When dst is nil, the pixels are read into the buffer bound to PIXEL_PACK_BUFFER.
+	var ptr unsafe.Pointer
+	if dst != nil {
+		ptr = unsafe.Pointer(&dst[0])
+	}
+	purego.SyscallN(c.gpReadPixels, uintptr(x), uintptr(y), uintptr(width), uintptr(height), uintptr(format), uintptr(xtype), uintptr(ptr))
 }
 
 func (c *defaultContext) RenderbufferStorage(target uint32, internalformat uint32, width int32, height int32) {
 	purego.SyscallN(c.gpRenderbufferStorage, uintptr(target), uintptr(internalformat), uintptr(width), uintptr(height))
 }
 
+func (c *defaultContext) RenderbufferStorageMultisample(target uint32, samples int32, internalformat uint32, width int32, height int32) {
+	purego.SyscallN(c.gpRenderbufferStorageMultisample, uintptr(target), uintptr(samples), uintptr(internalformat), uintptr(width), uintptr(height))
+}
+
 func (c *defaultContext) Scissor(x int32, y int32, width int32, height int32) {
 	purego.SyscallN(c.gpScissor, uintptr(x), uintptr(y), uintptr(width), uintptr(height))
 }
@@ -470,18 +609,27 @@ func (c *defaultContext) LoadFunctions() error {
 
 	c.gpActiveTexture = g.get("glActiveTexture")
 	c.gpAttachShader = g.get("glAttachShader")
+	c.gpBeginQuery = g.get("glBeginQuery")
 	c.gpBindAttribLocation = g.get("glBindAttribLocation")
 	c.gpBindBuffer = g.get("glBindBuffer")
+	// glBindFragDataLocation is not available with OpenGL ES.
+	c.gpBindFragDataLocation = g.getOptional("glBindFragDataLocation")
+	// glBindFragDataLocationIndexed is not available with OpenGL ES and OpenGL 3.2.
+	c.gpBindFragDataLocationIndexed = g.getOptional("glBindFragDataLocationIndexed")
 	c.gpBindFramebuffer = g.get("glBindFramebuffer")
+	// glBindImageTexture is available as of OpenGL 4.2 and OpenGL ES 3.1.
+	c.gpBindImageTexture = g.getOptional("glBindImageTexture")
 	c.gpBindRenderbuffer = g.get("glBindRenderbuffer")
 	c.gpBindTexture = g.get("glBindTexture")
 	c.gpBindVertexArray = g.get("glBindVertexArray")
 	c.gpBlendEquationSeparate = g.get("glBlendEquationSeparate")
 	c.gpBlendFuncSeparate = g.get("glBlendFuncSeparate")
+	c.gpBlitFramebuffer = g.get("glBlitFramebuffer")
 	c.gpBufferData = g.get("glBufferData")
 	c.gpBufferSubData = g.get("glBufferSubData")
 	c.gpCheckFramebufferStatus = g.get("glCheckFramebufferStatus")
 	c.gpClear = g.get("glClear")
+	c.gpClientWaitSync = g.get("glClientWaitSync")
 	c.gpColorMask = g.get("glColorMask")
 	c.gpCompileShader = g.get("glCompileShader")
 	c.gpCreateProgram = g.get("glCreateProgram")
@@ -489,20 +637,30 @@ func (c *defaultContext) LoadFunctions() error {
 	c.gpDeleteBuffers = g.get("glDeleteBuffers")
 	c.gpDeleteFramebuffers = g.get("glDeleteFramebuffers")
 	c.gpDeleteProgram = g.get("glDeleteProgram")
+	c.gpDeleteQueries = g.get("glDeleteQueries")
 	c.gpDeleteRenderbuffers = g.get("glDeleteRenderbuffers")
 	c.gpDeleteShader = g.get("glDeleteShader")
+	c.gpDeleteSync = g.get("glDeleteSync")
 	c.gpDeleteTextures = g.get("glDeleteTextures")
 	c.gpDeleteVertexArrays = g.get("glDeleteVertexArrays")
+	c.gpDepthFunc = g.get("glDepthFunc")
+	c.gpDepthMask = g.get("glDepthMask")
 	c.gpDisable = g.get("glDisable")
 	c.gpDisableVertexAttribArray = g.get("glDisableVertexAttribArray")
+	// glDispatchCompute is available as of OpenGL 4.3 and OpenGL ES 3.1.
+	c.gpDispatchCompute = g.getOptional("glDispatchCompute")
+	c.gpDrawBuffers = g.get("glDrawBuffers")
 	c.gpDrawElements = g.get("glDrawElements")
 	c.gpEnable = g.get("glEnable")
 	c.gpEnableVertexAttribArray = g.get("glEnableVertexAttribArray")
+	c.gpEndQuery = g.get("glEndQuery")
+	c.gpFenceSync = g.get("glFenceSync")
 	c.gpFlush = g.get("glFlush")
 	c.gpFramebufferRenderbuffer = g.get("glFramebufferRenderbuffer")
 	c.gpFramebufferTexture2D = g.get("glFramebufferTexture2D")
 	c.gpGenBuffers = g.get("glGenBuffers")
 	c.gpGenFramebuffers = g.get("glGenFramebuffers")
+	c.gpGenQueries = g.get("glGenQueries")
 	c.gpGenRenderbuffers = g.get("glGenRenderbuffers")
 	c.gpGenTextures = g.get("glGenTextures")
 	c.gpGenVertexArrays = g.get("glGenVertexArrays")
@@ -510,14 +668,22 @@ func (c *defaultContext) LoadFunctions() error {
 	c.gpGetIntegerv = g.get("glGetIntegerv")
 	c.gpGetProgramInfoLog = g.get("glGetProgramInfoLog")
 	c.gpGetProgramiv = g.get("glGetProgramiv")
+	// glGetQueryObjectui64v and glQueryCounter are not available with OpenGL ES and OpenGL 3.2.
+	c.gpGetQueryObjectui64v = g.getOptional("glGetQueryObjectui64v")
+	c.gpGetQueryObjectuiv = g.get("glGetQueryObjectuiv")
 	c.gpGetShaderInfoLog = g.get("glGetShaderInfoLog")
 	c.gpGetShaderiv = g.get("glGetShaderiv")
 	c.gpGetUniformLocation = g.get("glGetUniformLocation")
 	c.gpIsProgram = g.get("glIsProgram")
 	c.gpLinkProgram = g.get("glLinkProgram")
+	c.gpMapBufferRange = g.get("glMapBufferRange")
+	// glMemoryBarrier is available as of OpenGL 4.2 and OpenGL ES 3.1.
+	c.gpMemoryBarrier = g.getOptional("glMemoryBarrier")
 	c.gpPixelStorei = g.get("glPixelStorei")
+	c.gpQueryCounter = g.getOptional("glQueryCounter")
 	c.gpReadPixels = g.get("glReadPixels")
 	c.gpRenderbufferStorage = g.get("glRenderbufferStorage")
+	c.gpRenderbufferStorageMultisample = g.get("glRenderbufferStorageMultisample")
 	c.gpScissor = g.get("glScissor")
 	c.gpShaderSource = g.get("glShaderSource")
 	c.gpStencilFunc = g.get("glStencilFunc")
@@ -525,6 +691,7 @@ func (c *defaultContext) LoadFunctions() error {
 	c.gpTexImage2D = g.get("glTexImage2D")
 	c.gpTexParameteri = g.get("glTexParameteri")
 	c.gpTexSubImage2D = g.get("glTexSubImage2D")
+	c.gpUnmapBuffer = g.get("glUnmapBuffer")
 	c.gpUniform1fv = g.get("glUniform1fv")
 	c.gpUniform1i = g.get("glUniform1i")
 	c.gpUniform1iv = g.get("glUniform1iv")
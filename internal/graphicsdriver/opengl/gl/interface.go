@@ -29,23 +29,30 @@ type Context interface {
 
 	ActiveTexture(texture uint32)
 	AttachShader(program uint32, shader uint32)
+	BeginQuery(target uint32, query uint32)
 	BindAttribLocation(program uint32, index uint32, name string)
 	BindBuffer(target uint32, buffer uint32)
+	BindFragDataLocation(program uint32, colorNumber uint32, name string)
+	BindFragDataLocationIndexed(program uint32, colorNumber uint32, index uint32, name string)
 	BindFramebuffer(target uint32, framebuffer uint32)
+	BindImageTexture(unit uint32, texture uint32, level int32, layered bool, layer int32, access uint32, format uint32)
 	BindRenderbuffer(target uint32, renderbuffer uint32)
 	BindTexture(target uint32, texture uint32)
 	BindVertexArray(array uint32)
 	BlendEquationSeparate(modeRGB uint32, modeAlpha uint32)
 	BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32, dstAlpha uint32)
+	BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32)
 	BufferInit(target uint32, size int, usage uint32)
 	BufferSubData(target uint32, offset int, data []byte)
 	CheckFramebufferStatus(target uint32) uint32
 	Clear(mask uint32)
+	ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32
 	ColorMask(red, green, blue, alpha bool)
 	CompileShader(shader uint32)
 	CreateBuffer() uint32
 	CreateFramebuffer() uint32
 	CreateProgram() uint32
+	CreateQuery() uint32
 	CreateRenderbuffer() uint32
 	CreateShader(xtype uint32) uint32
 	CreateTexture() uint32
@@ -53,30 +60,45 @@ type Context interface {
 	DeleteBuffer(buffer uint32)
 	DeleteFramebuffer(framebuffer uint32)
 	DeleteProgram(program uint32)
+	DeleteQuery(query uint32)
 	DeleteRenderbuffer(renderbuffer uint32)
 	DeleteShader(shader uint32)
+	DeleteSync(sync uintptr)
 	DeleteTexture(texture uint32)
 	DeleteVertexArray(array uint32)
+	DepthFunc(func_ uint32)
+	DepthMask(flag bool)
 	Disable(cap uint32)
 	DisableVertexAttribArray(index uint32)
+	DispatchCompute(numGroupsX uint32, numGroupsY uint32, numGroupsZ uint32)
+	DrawBuffers(bufs []uint32)
 	DrawElements(mode uint32, count int32, xtype uint32, offset int)
 	Enable(cap uint32)
 	EnableVertexAttribArray(index uint32)
+	EndQuery(target uint32)
+	FenceSync(condition uint32, flags uint32) uintptr
 	Flush()
 	FramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer uint32)
 	FramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture uint32, level int32)
+	GetBufferSubData(target uint32, offset int, data []byte)
 	GetError() uint32
 	GetInteger(pname uint32) int
 	GetProgramInfoLog(program uint32) string
 	GetProgrami(program uint32, pname uint32) int
+	GetQueryObjectui(query uint32, pname uint32) uint32
+	GetQueryObjectui64(query uint32, pname uint32) uint64
 	GetShaderInfoLog(shader uint32) string
 	GetShaderi(shader uint32, pname uint32) int
 	GetUniformLocation(program uint32, name string) int32
 	IsProgram(program uint32) bool
+	IsTimerQueryAvailable() bool
 	LinkProgram(program uint32)
+	MemoryBarrier(barriers uint32)
 	PixelStorei(pname uint32, param int32)
+	QueryCounter(query uint32, target uint32)
 	ReadPixels(dst []byte, x int32, y int32, width int32, height int32, format uint32, xtype uint32)
 	RenderbufferStorage(target uint32, internalFormat uint32, width int32, height int32)
+	RenderbufferStorageMultisample(target uint32, samples int32, internalFormat uint32, width int32, height int32)
 	Scissor(x, y, width, height int32)
 	ShaderSource(shader uint32, xstring string)
 	StencilFunc(func_ uint32, ref int32, mask uint32)
@@ -38,6 +38,16 @@ func (p *procAddressGetter) get(name string) uintptr {
 	return proc
 }
 
+// getOptional returns the address of the given function, or 0 if the function is not available.
+// Unlike get, getOptional doesn't make LoadFunctions fail.
+func (p *procAddressGetter) getOptional(name string) uintptr {
+	proc, err := p.ctx.getProcAddress(name)
+	if err != nil {
+		return 0
+	}
+	return proc
+}
+
 func (p *procAddressGetter) error() error {
 	return p.err
 }
@@ -17,7 +17,11 @@
 package opengl
 
 import (
+	"errors"
 	"fmt"
+	"image"
+	"math"
+	"runtime"
 	"unsafe"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
@@ -54,6 +58,12 @@ type Graphics struct {
 	// textureNative cannot be a map key unfortunately.
 	activatedTextures []activatedTexture
 
+	// colorBufferFloatAvailable reports whether EXT_color_buffer_float is enabled with OpenGL ES or WebGL.
+	colorBufferFloatAvailable bool
+
+	// textureNorm16Available reports whether EXT_texture_norm16 is enabled with OpenGL ES or WebGL.
+	textureNorm16Available bool
+
 	graphicsPlatform
 }
 
@@ -120,17 +130,18 @@ func (g *Graphics) genNextShaderID() graphicsdriver.ShaderID {
 	return g.nextShaderID
 }
 
-func (g *Graphics) NewImage(width, height int) (graphicsdriver.Image, error) {
+func (g *Graphics) NewImage(width, height int, format graphicsdriver.PixelFormat) (graphicsdriver.Image, error) {
 	i := &Image{
 		id:       g.genNextImageID(),
 		graphics: g,
 		width:    width,
 		height:   height,
+		format:   format,
 	}
 	w := graphics.InternalImageSize(width)
 	h := graphics.InternalImageSize(height)
 	g.checkSize(w, h)
-	t, err := g.context.newTexture(w, h)
+	t, err := g.context.newTexture(w, h, format)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +150,39 @@ func (g *Graphics) NewImage(width, height int) (graphicsdriver.Image, error) {
 	return i, nil
 }
 
+// NewImageFromNativeTexture implements graphicsdriver.NativeTextureImporter.
+func (g *Graphics) NewImageFromNativeTexture(handle uintptr, width, height int) (graphicsdriver.Image, error) {
+	// A WebGL texture is a JavaScript object and cannot be specified with an integer.
+	if runtime.GOOS == "js" {
+		return nil, errors.New("opengl: native textures are not available with WebGL")
+	}
+	if handle == 0 || uint64(handle) > math.MaxUint32 {
+		return nil, fmt.Errorf("opengl: invalid texture name: %d", handle)
+	}
+
+	g.checkSize(width, height)
+	t := textureNative(handle)
+
+	// Ebitengine's shaders assume the nearest filter and the clamp-to-edge address mode.
+	g.context.bindTexture(t)
+	g.context.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	g.context.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	g.context.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	g.context.ctx.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	i := &Image{
+		id:       g.genNextImageID(),
+		graphics: g,
+		texture:  t,
+		width:    width,
+		height:   height,
+		format:   graphicsdriver.PixelFormatRGBA8,
+		external: true,
+	}
+	g.addImage(i)
+	return i, nil
+}
+
 func (g *Graphics) NewScreenFramebufferImage(width, height int) (graphicsdriver.Image, error) {
 	g.checkSize(width, height)
 	i := &Image{
@@ -198,23 +242,86 @@ func (g *Graphics) uniformVariableName(idx int) string {
 	return name
 }
 
-func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule) error {
+func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
+	return g.drawTriangles([graphics.ShaderDstImageCount]graphicsdriver.ImageID{dstID}, srcIDs, shaderID, dstRegions, indexOffset, blend, uniforms, fillRule, depthMode, stencil)
+}
+
+// IsMultipleRenderTargetsAvailable implements graphicsdriver.MultipleRenderTargetsDrawer.
+func (g *Graphics) IsMultipleRenderTargetsAvailable() bool {
+	return g.context.getMaxDrawBuffers() >= graphics.ShaderDstImageCount
+}
+
+// DrawTrianglesToMultipleTargets implements graphicsdriver.MultipleRenderTargetsDrawer.
+func (g *Graphics) DrawTrianglesToMultipleTargets(dstIDs [graphics.ShaderDstImageCount]graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
+	if !g.IsMultipleRenderTargetsAvailable() {
+		return fmt.Errorf("opengl: multiple render targets are not available")
+	}
+	return g.drawTriangles(dstIDs, srcIDs, shaderID, dstRegions, indexOffset, blend, uniforms, fillRule, depthMode, stencil)
+}
+
+// drawTriangles draws triangles onto the destination images.
+// dstIDs[0] is the main destination, and the other valid IDs are the additional destinations for multiple render targets.
+func (g *Graphics) drawTriangles(dstIDs [graphics.ShaderDstImageCount]graphicsdriver.ImageID, srcIDs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shaderID graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
 	if shaderID == graphicsdriver.InvalidShaderID {
 		return fmt.Errorf("opengl: shader ID is invalid")
 	}
 
-	destination := g.images[dstID]
+	destination := g.images[dstIDs[0]]
+
+	var additionalTextures []textureNative
+	for _, id := range dstIDs[1:] {
+		if id == graphicsdriver.InvalidImageID {
+			continue
+		}
+		img := g.images[id]
+		if destination.screen || img.screen {
+			return fmt.Errorf("opengl: the screen cannot be one of multiple render targets")
+		}
+		if destination.samples > 1 || img.samples > 1 {
+			return fmt.Errorf("opengl: a multisampled image cannot be one of multiple render targets")
+		}
+		if img.width != destination.width || img.height != destination.height || img.format != destination.format {
+			return fmt.Errorf("opengl: multiple render targets must have the same size and the same format")
+		}
+		additionalTextures = append(additionalTextures, img.texture)
+	}
 
 	g.drawCalled = true
 
+	// Resolve multisampled source images before binding the destination framebuffer.
+	for _, srcID := range srcIDs {
+		if srcID == graphicsdriver.InvalidImageID {
+			continue
+		}
+		g.images[srcID].resolve()
+	}
+
 	if err := destination.setViewport(); err != nil {
 		return err
 	}
+	g.context.setSRGBEncoding(destination.format == graphicsdriver.PixelFormatSRGBA8)
 	g.context.blend(blend)
 
 	shader := g.shaders[shaderID]
 	program := shader.p
 
+	if len(additionalTextures) > 0 {
+		if !shader.ir.FragmentFunc.MultipleRenderTargets || shader.ir.FragmentFunc.OutputCount != len(additionalTextures)+1 {
+			return fmt.Errorf("opengl: the shader must return %d colors for multiple render targets", len(additionalTextures)+1)
+		}
+		g.context.attachAdditionalColorTextures(additionalTextures)
+		defer g.context.detachAdditionalColorTextures(len(additionalTextures))
+	}
+
+	if blend.IsDualSource() {
+		if g.context.ctx.IsES() {
+			return fmt.Errorf("opengl: dual-source blending is not supported with OpenGL ES")
+		}
+		if shader.ir.FragmentFunc.OutputCount < 2 {
+			return fmt.Errorf("opengl: dual-source blending requires a shader returning two colors")
+		}
+	}
+
 	ulen := len(shader.ir.Uniforms)
 	if cap(g.uniformVars) < ulen {
 		g.uniformVars = make([]uniformVariable, ulen)
@@ -241,6 +348,13 @@ func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.
 		g.uniformVars[idx].value[13] ^= 1 << 31
 	}
 
+	// In OpenGL, the NDC's Z range is [-1, 1] instead of [0, 1].
+	if depthMode != graphicsdriver.DepthModeNone {
+		const idx = graphics.ProjectionMatrixUniformVariableIndex
+		g.uniformVars[idx].value[10] = math.Float32bits(2)
+		g.uniformVars[idx].value[14] = math.Float32bits(-1)
+	}
+
 	var imgs [graphics.ShaderSrcImageCount]textureVariable
 	for i, srcID := range srcIDs {
 		if srcID == graphicsdriver.InvalidImageID {
@@ -248,6 +362,8 @@ func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.
 		}
 		imgs[i].valid = true
 		imgs[i].native = g.images[srcID].texture
+		imgs[i].image = g.images[srcID]
+		imgs[i].anisotropic = i < len(shader.anisotropicTextures) && shader.anisotropicTextures[i]
 	}
 
 	if err := g.useProgram(program, g.uniformVars, imgs); err != nil {
@@ -266,6 +382,33 @@ func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.
 		g.context.ctx.Enable(gl.STENCIL_TEST)
 	}
 
+	if depthMode != graphicsdriver.DepthModeNone {
+		if err := destination.ensureDepthStencilBuffer(); err != nil {
+			return err
+		}
+		g.context.ctx.Enable(gl.DEPTH_TEST)
+		switch depthMode {
+		case graphicsdriver.DepthModeTest:
+			g.context.ctx.DepthFunc(gl.LESS)
+			g.context.ctx.DepthMask(false)
+		case graphicsdriver.DepthModeWrite:
+			g.context.ctx.DepthFunc(gl.ALWAYS)
+			g.context.ctx.DepthMask(true)
+		case graphicsdriver.DepthModeTestAndWrite:
+			g.context.ctx.DepthFunc(gl.LESS)
+			g.context.ctx.DepthMask(true)
+		}
+	}
+
+	if !stencil.IsZero() {
+		if err := destination.ensureDepthStencilBuffer(); err != nil {
+			return err
+		}
+		g.context.ctx.Enable(gl.STENCIL_TEST)
+		g.context.ctx.StencilFunc(glStencilFunc(stencil.Func), int32(stencil.Ref), 0xff)
+		g.context.ctx.StencilOpSeparate(gl.FRONT_AND_BACK, gl.KEEP, gl.KEEP, glStencilOp(stencil.Op))
+	}
+
 	for _, dstRegion := range dstRegions {
 		g.context.ctx.Scissor(
 			int32(dstRegion.Region.Min.X),
@@ -302,9 +445,68 @@ func (g *Graphics) DrawTriangles(dstID graphicsdriver.ImageID, srcIDs [graphics.
 		g.context.ctx.Disable(gl.STENCIL_TEST)
 	}
 
+	if depthMode != graphicsdriver.DepthModeNone {
+		g.context.ctx.Disable(gl.DEPTH_TEST)
+		g.context.ctx.DepthMask(true)
+	}
+
+	if !stencil.IsZero() {
+		g.context.ctx.Disable(gl.STENCIL_TEST)
+	}
+
+	if destination.resolveFramebuffer != nil {
+		destination.resolveNeeded = true
+	}
+
 	return nil
 }
 
+func glStencilFunc(f graphicsdriver.StencilFunc) uint32 {
+	switch f {
+	case graphicsdriver.StencilFuncAlways:
+		return gl.ALWAYS
+	case graphicsdriver.StencilFuncNever:
+		return gl.NEVER
+	case graphicsdriver.StencilFuncEqual:
+		return gl.EQUAL
+	case graphicsdriver.StencilFuncNotEqual:
+		return gl.NOTEQUAL
+	case graphicsdriver.StencilFuncLess:
+		return gl.LESS
+	case graphicsdriver.StencilFuncLessEqual:
+		return gl.LEQUAL
+	case graphicsdriver.StencilFuncGreater:
+		return gl.GREATER
+	case graphicsdriver.StencilFuncGreaterEqual:
+		return gl.GEQUAL
+	default:
+		panic(fmt.Sprintf("opengl: invalid stencil func: %d", f))
+	}
+}
+
+func glStencilOp(op graphicsdriver.StencilOp) uint32 {
+	switch op {
+	case graphicsdriver.StencilOpKeep:
+		return gl.KEEP
+	case graphicsdriver.StencilOpZero:
+		return gl.ZERO
+	case graphicsdriver.StencilOpReplace:
+		return gl.REPLACE
+	case graphicsdriver.StencilOpIncrement:
+		return gl.INCR
+	case graphicsdriver.StencilOpIncrementWrap:
+		return gl.INCR_WRAP
+	case graphicsdriver.StencilOpDecrement:
+		return gl.DECR
+	case graphicsdriver.StencilOpDecrementWrap:
+		return gl.DECR_WRAP
+	case graphicsdriver.StencilOpInvert:
+		return gl.INVERT
+	default:
+		panic(fmt.Sprintf("opengl: invalid stencil op: %d", op))
+	}
+}
+
 func (g *Graphics) SetVsyncEnabled(enabled bool) {
 	g.vsync = enabled
 }
@@ -313,6 +515,28 @@ func (g *Graphics) NeedsClearingScreen() bool {
 	return true
 }
 
+// CopyImage implements graphicsdriver.ImageCopier.
+func (g *Graphics) CopyImage(dstID, srcID graphicsdriver.ImageID, dstPoint image.Point, srcRegion image.Rectangle) error {
+	dst := g.images[dstID]
+	src := g.images[srcID]
+	if dst.screen || dst.samples > 1 {
+		return fmt.Errorf("opengl: the destination image for CopyImage must be neither the screen nor multisampled")
+	}
+	if dst.format != src.format {
+		return fmt.Errorf("opengl: the pixel formats for CopyImage must be the same but %s and %s", dst.format, src.format)
+	}
+
+	sf, err := src.readFramebuffer()
+	if err != nil {
+		return err
+	}
+	if err := dst.ensureFramebuffer(); err != nil {
+		return err
+	}
+	g.context.blitFramebuffer(sf, dst.framebuffer, dstPoint, srcRegion)
+	return nil
+}
+
 func (g *Graphics) MaxImageSize() int {
 	return g.context.getMaxTextureSize()
 }
@@ -326,6 +550,68 @@ func (g *Graphics) NewShader(program *shaderir.Program) (graphicsdriver.Shader,
 	return s, nil
 }
 
+// NewComputeShader implements graphicsdriver.ComputeDispatcher.
+func (g *Graphics) NewComputeShader(program *shaderir.Program) (graphicsdriver.Shader, error) {
+	return g.NewShader(program)
+}
+
+// DispatchCompute implements graphicsdriver.ComputeDispatcher.
+func (g *Graphics) DispatchCompute(shaderID graphicsdriver.ShaderID, imageIDs []graphicsdriver.ImageID, uniforms []uint32, x, y, z int) error {
+	shader := g.shaders[shaderID]
+	if !shader.ir.IsCompute() {
+		return fmt.Errorf("opengl: the shader for DispatchCompute must be a compute kernel")
+	}
+	if len(imageIDs) != shader.ir.StorageImageCount {
+		return fmt.Errorf("opengl: the number of the storage images must be %d but %d", shader.ir.StorageImageCount, len(imageIDs))
+	}
+
+	ulen := len(shader.ir.Uniforms)
+	if cap(g.uniformVars) < ulen {
+		g.uniformVars = make([]uniformVariable, ulen)
+	} else {
+		g.uniformVars = g.uniformVars[:ulen]
+	}
+
+	var idx int
+	for i, typ := range shader.ir.Uniforms {
+		n := typ.Uint32Count()
+		g.uniformVars[i].name = g.uniformVariableName(i)
+		g.uniformVars[i].value = uniforms[idx : idx+n]
+		g.uniformVars[i].typ = typ
+		idx += n
+	}
+
+	if err := g.useProgram(shader.p, g.uniformVars, [graphics.ShaderSrcImageCount]textureVariable{}); err != nil {
+		return err
+	}
+
+	for i := range g.uniformVars {
+		g.uniformVars[i] = uniformVariable{}
+	}
+	g.uniformVars = g.uniformVars[:0]
+
+	for i, id := range imageIDs {
+		img := g.images[id]
+		if img.screen || img.samples > 1 {
+			return fmt.Errorf("opengl: a storage image must be neither the screen nor multisampled")
+		}
+		if img.format != graphicsdriver.PixelFormatRGBA8 {
+			return fmt.Errorf("opengl: the pixel format of a storage image must be %s but %s", graphicsdriver.PixelFormatRGBA8, img.format)
+		}
+		g.context.ctx.BindImageTexture(uint32(i), uint32(img.texture), 0, false, 0, gl.READ_WRITE, gl.RGBA8)
+	}
+
+	g.context.ctx.DispatchCompute(uint32(x), uint32(y), uint32(z))
+
+	// Make the results visible to the following draw calls, texture fetches and pixel reads.
+	g.context.ctx.MemoryBarrier(gl.ALL_BARRIER_BITS)
+
+	for i := range imageIDs {
+		g.context.ctx.BindImageTexture(uint32(i), 0, 0, false, 0, gl.READ_WRITE, gl.RGBA8)
+	}
+	return nil
+}
+
 func (g *Graphics) addShader(shader *Shader) {
 	if g.shaders == nil {
 		g.shaders = map[graphicsdriver.ShaderID]*Shader{}
@@ -339,3 +625,101 @@ func (g *Graphics) addShader(shader *Shader) {
 func (g *Graphics) removeShader(shader *Shader) {
 	delete(g.shaders, shader.id)
 }
+
+// IsFeatureAvailable implements graphicsdriver.FeatureReporter.
+func (g *Graphics) IsFeatureAvailable(feature graphicsdriver.Feature) bool {
+	switch feature {
+	case graphicsdriver.FeatureDepth, graphicsdriver.FeatureStencil:
+		return true
+	case graphicsdriver.FeatureFloatFormats:
+		// OpenGL ES and WebGL require EXT_color_buffer_float to render to floating point textures.
+		return !g.context.ctx.IsES() || g.colorBufferFloatAvailable
+	case graphicsdriver.FeatureRGBA16:
+		// OpenGL ES and WebGL require EXT_texture_norm16 to use 16-bit normalized textures.
+		return !g.context.ctx.IsES() || g.textureNorm16Available
+	case graphicsdriver.FeatureMultisampling:
+		return g.context.getMaxSamples() > 1
+	case graphicsdriver.FeatureDualSourceBlending:
+		return !g.context.ctx.IsES()
+	case graphicsdriver.FeatureCompute:
+		return g.context.isComputeAvailable()
+	default:
+		return false
+	}
+}
+
+// IsGPUTimerAvailable implements graphicsdriver.GPUTimer.
+func (g *Graphics) IsGPUTimerAvailable() bool {
+	return g.context.ctx.IsTimerQueryAvailable()
+}
+
+// RecordGPUTimestamp implements graphicsdriver.GPUTimer.
+func (g *Graphics) RecordGPUTimestamp() (graphicsdriver.GPUTimestamp, error) {
+	if !g.context.ctx.IsTimerQueryAvailable() {
+		return nil, fmt.Errorf("opengl: timer queries are not available")
+	}
+	q := g.context.ctx.CreateQuery()
+	g.context.ctx.QueryCounter(q, gl.TIMESTAMP)
+	return &gpuTimestamp{
+		context: &g.context,
+		query:   q,
+	}, nil
+}
+
+// gpuTimestamp represents a timestamp being recorded with a timer query.
+type gpuTimestamp struct {
+	context *context
+	query   uint32
+}
+
+// TryFinish implements graphicsdriver.GPUTimestamp.
+func (t *gpuTimestamp) TryFinish() (uint64, bool, error) {
+	ctx := t.context.ctx
+	if ctx.GetQueryObjectui(t.query, gl.QUERY_RESULT_AVAILABLE) == gl.FALSE {
+		return 0, false, nil
+	}
+	ns := ctx.GetQueryObjectui64(t.query, gl.QUERY_RESULT)
+	ctx.DeleteQuery(t.query)
+	return ns, true, nil
+}
+
+// BeginOcclusionQuery implements graphicsdriver.OcclusionQuerier.
+func (g *Graphics) BeginOcclusionQuery() (graphicsdriver.OcclusionQuery, error) {
+	q := g.context.ctx.CreateQuery()
+	g.context.ctx.BeginQuery(g.occlusionQueryTarget(), q)
+	return &occlusionQuery{
+		context: &g.context,
+		query:   q,
+	}, nil
+}
+
+// EndOcclusionQuery implements graphicsdriver.OcclusionQuerier.
+func (g *Graphics) EndOcclusionQuery() error {
+	g.context.ctx.EndQuery(g.occlusionQueryTarget())
+	return nil
+}
+
+func (g *Graphics) occlusionQueryTarget() uint32 {
+	// GL_SAMPLES_PASSED is not available with OpenGL ES and WebGL.
+	if g.context.ctx.IsES() {
+		return gl.ANY_SAMPLES_PASSED
+	}
+	return gl.SAMPLES_PASSED
+}
+
+// occlusionQuery represents an occlusion query with a query object.
+type occlusionQuery struct {
+	context *context
+	query   uint32
+}
+
+// TryFinish implements graphicsdriver.OcclusionQuery.
+func (q *occlusionQuery) TryFinish() (uint64, bool, error) {
+	ctx := q.context.ctx
+	if ctx.GetQueryObjectui(q.query, gl.QUERY_RESULT_AVAILABLE) == gl.FALSE {
+		return 0, false, nil
+	}
+	samples := ctx.GetQueryObjectui(q.query, gl.QUERY_RESULT)
+	ctx.DeleteQuery(q.query)
+	return uint64(samples), true, nil
+}
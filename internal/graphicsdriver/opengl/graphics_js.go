@@ -23,6 +23,7 @@ import (
 )
 
 type graphicsPlatform struct {
+	screenColorSpace graphicsdriver.ColorSpace
 }
 
 // NewGraphics creates an implementation of graphicsdriver.Graphics for OpenGL.
@@ -41,11 +42,30 @@ func NewGraphics(canvas js.Value, colorSpace graphicsdriver.ColorSpace) (graphic
 		return nil, fmt.Errorf("opengl: getContext for webgl2 failed")
 	}
 
-	switch colorSpace {
-	case graphicsdriver.ColorSpaceSRGB:
-		glContext.Set("drawingBufferColorSpace", "srgb")
-	case graphicsdriver.ColorSpaceDisplayP3:
-		glContext.Set("drawingBufferColorSpace", "display-p3")
+	// Enable rendering to floating point textures.
+	colorBufferFloatAvailable := glContext.Call("getExtension", "EXT_color_buffer_float").Truthy()
+	glContext.Call("getExtension", "EXT_float_blend")
+	// Enable 16-bit normalized textures.
+	textureNorm16Available := glContext.Call("getExtension", "EXT_texture_norm16").Truthy()
+	// Enable anisotropic texture filtering.
+	glContext.Call("getExtension", "EXT_texture_filter_anisotropic")
+
+	// drawingBufferColorSpace is not defined on some browsers. Check this before setting the value.
+	var screenColorSpace graphicsdriver.ColorSpace
+	if glContext.Get("drawingBufferColorSpace").Type() == js.TypeString {
+		switch colorSpace {
+		case graphicsdriver.ColorSpaceSRGB:
+			glContext.Set("drawingBufferColorSpace", "srgb")
+		case graphicsdriver.ColorSpaceDisplayP3:
+			glContext.Set("drawingBufferColorSpace", "display-p3")
+		}
+		// The value is not changed if the browser doesn't support the color space.
+		switch glContext.Get("drawingBufferColorSpace").String() {
+		case "srgb":
+			screenColorSpace = graphicsdriver.ColorSpaceSRGB
+		case "display-p3":
+			screenColorSpace = graphicsdriver.ColorSpaceDisplayP3
+		}
 	}
 
 	ctx, err := gl.NewDefaultContext(glContext)
@@ -53,7 +73,16 @@ func NewGraphics(canvas js.Value, colorSpace graphicsdriver.ColorSpace) (graphic
 		return nil, err
 	}
 
-	return newGraphics(ctx), nil
+	g := newGraphics(ctx)
+	g.screenColorSpace = screenColorSpace
+	g.colorBufferFloatAvailable = colorBufferFloatAvailable
+	g.textureNorm16Available = textureNorm16Available
+	return g, nil
+}
+
+// ScreenColorSpace implements graphicsdriver.ScreenColorSpaceReporter.
+func (g *Graphics) ScreenColorSpace() graphicsdriver.ColorSpace {
+	return g.screenColorSpace
 }
 
 func (g *Graphics) makeContextCurrent() error {
@@ -18,6 +18,8 @@ package opengl
 
 import (
 	"errors"
+	"image"
+	"unsafe"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
@@ -29,10 +31,31 @@ type Image struct {
 	graphics    *Graphics
 	texture     textureNative
 	stencil     renderbufferNative
+	depth       bool
 	framebuffer *framebuffer
 	width       int
 	height      int
 	screen      bool
+	format      graphicsdriver.PixelFormat
+
+	// filterAnisotropic reports whether the texture's filter is set for anisotropic filtering.
+	// Otherwise, the filter is nearest.
+	filterAnisotropic bool
+
+	// external reports whether the texture is created outside of Ebitengine.
+	// An external texture is not deleted at Dispose.
+	external bool
+
+	// samples is the number of samples per pixel for multisample anti-aliasing.
+	// If samples is more than 1, framebuffer is a multisampled framebuffer and is resolved to resolveFramebuffer
+	// that has the texture.
+	samples            int
+	msaaColor          renderbufferNative
+	resolveFramebuffer *framebuffer
+	resolveNeeded      bool
+
+	// tmpFloatPixels is a temporary buffer to convert pixels for an image with a floating point format.
+	tmpFloatPixels []float32
 }
 
 // framebuffer is a wrapper of OpenGL's framebuffer.
@@ -50,12 +73,18 @@ func (i *Image) Dispose() {
 	if i.framebuffer != nil {
 		i.graphics.context.deleteFramebuffer(i.framebuffer.native)
 	}
-	if i.texture != 0 {
+	if i.texture != 0 && !i.external {
 		i.graphics.context.deleteTexture(i.texture)
 	}
 	if i.stencil != 0 {
 		i.graphics.context.deleteRenderbuffer(i.stencil)
 	}
+	if i.resolveFramebuffer != nil {
+		i.graphics.context.deleteFramebuffer(i.resolveFramebuffer.native)
+	}
+	if i.msaaColor != 0 {
+		i.graphics.context.deleteRenderbuffer(i.msaaColor)
+	}
 
 	i.graphics.removeImage(i)
 }
@@ -68,18 +97,153 @@ func (i *Image) setViewport() error {
 	return nil
 }
 
-func (i *Image) ReadPixels(args []graphicsdriver.PixelsArgs) error {
+// SetSamples implements graphicsdriver.MultisampledImage.
+func (i *Image) SetSamples(samples int) error {
+	if i.screen {
+		return errors.New("opengl: multisampling is not available for the screen")
+	}
+	if i.framebuffer != nil {
+		return errors.New("opengl: SetSamples must be called before the image is used as a render target")
+	}
+	if m := i.graphics.context.getMaxSamples(); samples > m {
+		samples = m
+	}
+	i.samples = samples
+	return nil
+}
+
+// resolve resolves the multisampled framebuffer to the texture if needed.
+func (i *Image) resolve() {
+	if !i.resolveNeeded {
+		return
+	}
+	r := image.Rect(0, 0, i.framebuffer.viewportWidth, i.framebuffer.viewportHeight)
+	i.graphics.context.blitFramebuffer(i.framebuffer, i.resolveFramebuffer, image.Point{}, r)
+	i.resolveNeeded = false
+}
+
+// readFramebuffer returns the framebuffer to read the pixels from.
+func (i *Image) readFramebuffer() (*framebuffer, error) {
 	if err := i.ensureFramebuffer(); err != nil {
+		return nil, err
+	}
+	if i.resolveFramebuffer != nil {
+		i.resolve()
+		return i.resolveFramebuffer, nil
+	}
+	return i.framebuffer, nil
+}
+
+func (i *Image) ReadPixels(args []graphicsdriver.PixelsArgs) error {
+	f, err := i.readFramebuffer()
+	if err != nil {
 		return err
 	}
 	for _, arg := range args {
-		if err := i.graphics.context.framebufferPixels(arg.Pixels, i.framebuffer, arg.Region); err != nil {
+		if i.format.IsFloat() {
+			fs := i.ensureTmpFloatPixels(len(arg.Pixels))
+			if err := i.graphics.context.framebufferFloatPixels(fs, f, arg.Region); err != nil {
+				return err
+			}
+			floatsToBytes(arg.Pixels, fs)
+			continue
+		}
+		if err := i.graphics.context.framebufferPixels(arg.Pixels, f, arg.Region, i.format); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// ReadPixelsAsync implements graphicsdriver.AsyncPixelsReader.
+func (i *Image) ReadPixelsAsync(args []graphicsdriver.PixelsArgs) (graphicsdriver.PendingPixels, error) {
+	f, err := i.readFramebuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &i.graphics.context
+	p := &pendingPixels{
+		context: c,
+		args:    args,
+		format:  i.format,
+	}
+	for _, arg := range args {
+		p.buffers = append(p.buffers, c.framebufferPixelsToBuffer(f, arg.Region, i.format))
+	}
+	p.sync = c.ctx.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	// Flush the commands so that the fence is signaled eventually.
+	c.ctx.Flush()
+	return p, nil
+}
+
+// pendingPixels represents pixels being read into pixel pack buffers.
+type pendingPixels struct {
+	context *context
+	args    []graphicsdriver.PixelsArgs
+	buffers []buffer
+	sync    uintptr
+	format  graphicsdriver.PixelFormat
+}
+
+// TryFinish implements graphicsdriver.PendingPixels.
+func (p *pendingPixels) TryFinish() (bool, error) {
+	ctx := p.context.ctx
+
+	switch ctx.ClientWaitSync(p.sync, 0, 0) {
+	case gl.ALREADY_SIGNALED, gl.CONDITION_SATISFIED:
+	case gl.WAIT_FAILED:
+		p.dispose()
+		return false, errors.New("opengl: waiting for reading pixels failed")
+	default:
+		return false, nil
+	}
+
+	for idx, arg := range p.args {
+		ctx.BindBuffer(gl.PIXEL_PACK_BUFFER, uint32(p.buffers[idx]))
+		if p.format.IsFloat() {
+			fs := make([]float32, len(arg.Pixels))
+			ctx.GetBufferSubData(gl.PIXEL_PACK_BUFFER, 0, unsafe.Slice((*byte)(unsafe.Pointer(&fs[0])), len(fs)*4))
+			floatsToBytes(arg.Pixels, fs)
+		} else {
+			ctx.GetBufferSubData(gl.PIXEL_PACK_BUFFER, 0, arg.Pixels)
+		}
+	}
+	ctx.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+
+	p.dispose()
+	return true, nil
+}
+
+func (p *pendingPixels) dispose() {
+	p.context.ctx.DeleteSync(p.sync)
+	for _, b := range p.buffers {
+		p.context.ctx.DeleteBuffer(uint32(b))
+	}
+	p.buffers = nil
+}
+
+// floatsToBytes converts the floating point values to 8-bit values.
+// The values out of [0, 1] are clamped as the pixels are 8-bit values.
+func floatsToBytes(dst []byte, src []float32) {
+	for idx, v := range src {
+		if v < 0 {
+			v = 0
+		}
+		if v > 1 {
+			v = 1
+		}
+		dst[idx] = byte(v*0xff + 0.5)
+	}
+}
+
+func (i *Image) ensureTmpFloatPixels(n int) []float32 {
+	if len(i.tmpFloatPixels) < n {
+		i.tmpFloatPixels = make([]float32, n)
+	}
+	return i.tmpFloatPixels[:n]
+}
+
 func (i *Image) viewportSize() (int, int) {
 	if i.screen {
 		// The (default) framebuffer size can't be converted to a power of 2.
@@ -87,6 +251,10 @@ func (i *Image) viewportSize() (int, int) {
 		// Edge can't treat a bigger viewport than the drawing area (#71).
 		return i.width, i.height
 	}
+	if i.external {
+		// An external texture has exactly the given size.
+		return i.width, i.height
+	}
 	return graphics.InternalImageSize(i.width), graphics.InternalImageSize(i.height)
 }
 
@@ -105,6 +273,19 @@ func (i *Image) ensureFramebuffer() error {
 	if err != nil {
 		return err
 	}
+
+	if i.samples > 1 {
+		mf, r, err := i.graphics.context.newMultisampledFramebuffer(w, h, i.samples, i.format)
+		if err != nil {
+			i.graphics.context.deleteFramebuffer(f.native)
+			return err
+		}
+		i.framebuffer = mf
+		i.msaaColor = r
+		i.resolveFramebuffer = f
+		return nil
+	}
+
 	i.framebuffer = f
 	return nil
 }
@@ -118,7 +299,8 @@ func (i *Image) ensureStencilBuffer() error {
 		return err
 	}
 
-	r, err := i.graphics.context.newRenderbuffer(i.viewportSize())
+	w, h := i.viewportSize()
+	r, err := i.graphics.context.newRenderbuffer(w, h, i.samples)
 	if err != nil {
 		return err
 	}
@@ -130,10 +312,49 @@ func (i *Image) ensureStencilBuffer() error {
 	return nil
 }
 
+// ensureDepthStencilBuffer ensures that the image has a buffer for both depth and stencil.
+func (i *Image) ensureDepthStencilBuffer() error {
+	if i.stencil != 0 && i.depth {
+		return nil
+	}
+
+	if i.screen {
+		return errors.New("opengl: a depth-stencil buffer is not available for the screen")
+	}
+
+	if err := i.ensureFramebuffer(); err != nil {
+		return err
+	}
+
+	w, h := i.viewportSize()
+	r, err := i.graphics.context.newDepthStencilRenderbuffer(w, h, i.samples)
+	if err != nil {
+		return err
+	}
+	if i.stencil != 0 {
+		i.graphics.context.deleteRenderbuffer(i.stencil)
+	}
+	i.stencil = r
+	i.depth = true
+
+	if err := i.graphics.context.bindDepthStencilBuffer(i.framebuffer.native, i.stencil); err != nil {
+		return err
+	}
+
+	// Initialize the depth buffer with the farthest value (1), and the stencil buffer with 0.
+	i.graphics.context.ctx.Scissor(0, 0, int32(w), int32(h))
+	i.graphics.context.ctx.DepthMask(true)
+	i.graphics.context.ctx.Clear(gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+	return nil
+}
+
 func (i *Image) WritePixels(args []graphicsdriver.PixelsArgs) error {
 	if i.screen {
 		return errors.New("opengl: WritePixels cannot be called on the screen")
 	}
+	if i.samples > 1 {
+		return errors.New("opengl: WritePixels cannot be called on a multisampled image")
+	}
 	if len(args) == 0 {
 		return nil
 	}
@@ -151,7 +372,20 @@ func (i *Image) WritePixels(args []graphicsdriver.PixelsArgs) error {
 		y := int32(a.Region.Min.Y)
 		width := int32(a.Region.Dx())
 		height := int32(a.Region.Dy())
-		i.graphics.context.ctx.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, width, height, gl.RGBA, gl.UNSIGNED_BYTE, a.Pixels)
+		if i.format.IsFloat() {
+			// OpenGL ES doesn't allow uploading 8-bit values to a floating point texture. Convert them to float values.
+			fs := i.ensureTmpFloatPixels(len(a.Pixels))
+			for idx, v := range a.Pixels {
+				fs[idx] = float32(v) / 0xff
+			}
+			i.graphics.context.ctx.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, width, height, gl.RGBA, gl.FLOAT, unsafe.Slice((*byte)(unsafe.Pointer(&fs[0])), len(fs)*4))
+			continue
+		}
+		xtype := uint32(gl.UNSIGNED_BYTE)
+		if i.format == graphicsdriver.PixelFormatRGBA16 {
+			xtype = gl.UNSIGNED_SHORT
+		}
+		i.graphics.context.ctx.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, width, height, gl.RGBA, xtype, a.Pixels)
 	}
 
 	return nil
@@ -260,6 +260,12 @@ type uniformVariable struct {
 type textureVariable struct {
 	valid  bool
 	native textureNative
+
+	// image is the source image. image is used to keep track of the texture filter.
+	image *Image
+
+	// anisotropic reports whether the texture is sampled with the anisotropic filter.
+	anisotropic bool
 }
 
 func (g *Graphics) textureVariableName(idx int) string {
@@ -340,6 +346,12 @@ loop:
 		// Apparently, a texture must be bound every time. The cache is not used here.
 		g.context.bindTexture(t.native)
 
+		// The texture filter is a state of the texture. Update this only when necessary.
+		if t.image.filterAnisotropic != t.anisotropic {
+			g.context.setTextureFilterAnisotropic(t.anisotropic)
+			t.image.filterAnisotropic = t.anisotropic
+		}
+
 		idx++
 	}
 
@@ -31,13 +31,17 @@ type Shader struct {
 
 	ir *shaderir.Program
 	p  program
+
+	// anisotropicTextures reports whether each texture is sampled with the anisotropic filter.
+	anisotropicTextures []bool
 }
 
 func newShader(id graphicsdriver.ShaderID, graphics *Graphics, program *shaderir.Program) (*Shader, error) {
 	s := &Shader{
-		id:       id,
-		graphics: graphics,
-		ir:       program,
+		id:                  id,
+		graphics:            graphics,
+		ir:                  program,
+		anisotropicTextures: program.AnisotropicTextures(),
 	}
 	if err := s.compile(); err != nil {
 		return nil, err
@@ -55,6 +59,21 @@ func (s *Shader) Dispose() {
 }
 
 func (s *Shader) compile() error {
+	if s.ir.IsCompute() {
+		return s.compileCompute()
+	}
+
+	var dualSource bool
+	renderTargetCount := 1
+	if s.ir.FragmentFunc.MultipleRenderTargets {
+		renderTargetCount = s.ir.FragmentFunc.OutputCount
+	} else {
+		dualSource = s.ir.FragmentFunc.OutputCount > 1
+	}
+	if dualSource && s.graphics.context.ctx.IsES() {
+		return fmt.Errorf("opengl: a shader returning two colors for dual-source blending is not supported with OpenGL ES")
+	}
+
 	vssrc, fssrc := glsl.Compile(s.ir, s.graphics.context.glslVersion())
 
 	vs, err := s.graphics.context.newShader(gl.VERTEX_SHADER, vssrc)
@@ -69,7 +88,7 @@ func (s *Shader) compile() error {
 	}
 	defer s.graphics.context.ctx.DeleteShader(uint32(fs))
 
-	p, err := s.graphics.context.newProgram([]shader{vs, fs}, theArrayBufferLayout.names())
+	p, err := s.graphics.context.newProgram([]shader{vs, fs}, theArrayBufferLayout.names(), dualSource, renderTargetCount)
 	if err != nil {
 		return err
 	}
@@ -87,3 +106,32 @@ func (s *Shader) compile() error {
 	s.p = p
 	return nil
 }
+
+func (s *Shader) compileCompute() error {
+	if !s.graphics.context.isComputeAvailable() {
+		return fmt.Errorf("opengl: compute shaders require OpenGL 4.3 or later")
+	}
+
+	cssrc := glsl.CompileCompute(s.ir, s.graphics.context.glslVersion())
+
+	cs, err := s.graphics.context.newShader(gl.COMPUTE_SHADER, cssrc)
+	if err != nil {
+		return err
+	}
+	defer s.graphics.context.ctx.DeleteShader(uint32(cs))
+
+	p, err := s.graphics.context.newProgram([]shader{cs}, nil, false, 1)
+	if err != nil {
+		return err
+	}
+
+	if s.graphics.context.ctx.GetProgrami(uint32(p), gl.LINK_STATUS) == gl.FALSE {
+		programInfo := s.graphics.context.ctx.GetProgramInfoLog(uint32(p))
+		computeShaderInfo := s.graphics.context.ctx.GetShaderInfoLog(uint32(cs))
+		return fmt.Errorf("opengl: program error: %s\ncompute shader error: %s\ncompute shader source: %s",
+			programInfo, computeShaderInfo, cssrc)
+	}
+
+	s.p = p
+	return nil
+}
@@ -75,7 +75,11 @@ func (g *Graphics) SetVertices(vertices []float32, indices []uint32) error {
 	return nil
 }
 
-func (g *Graphics) NewImage(width, height int) (graphicsdriver.Image, error) {
+func (g *Graphics) NewImage(width, height int, format graphicsdriver.PixelFormat) (graphicsdriver.Image, error) {
+	if format != graphicsdriver.PixelFormatRGBA8 {
+		return nil, fmt.Errorf("playstation5: pixel format %s is not supported yet", format)
+	}
+
 	var id C.int
 	if err := C.ebitengine_NewImage(&id, C.int(width), C.int(height)); !C.ebitengine_IsErrorNil(&err) {
 		return nil, newPlaystation5Error("(*playstation5.Graphics).NewImage", err)
@@ -117,7 +121,17 @@ func (g *Graphics) NewShader(program *shaderir.Program) (graphicsdriver.Shader,
 	}, nil
 }
 
-func (g *Graphics) DrawTriangles(dst graphicsdriver.ImageID, srcs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shader graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule) error {
+func (g *Graphics) DrawTriangles(dst graphicsdriver.ImageID, srcs [graphics.ShaderSrcImageCount]graphicsdriver.ImageID, shader graphicsdriver.ShaderID, dstRegions []graphicsdriver.DstRegion, indexOffset int, blend graphicsdriver.Blend, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) error {
+	if depthMode != graphicsdriver.DepthModeNone {
+		return fmt.Errorf("playstation5: depth buffers are not supported yet")
+	}
+	if !stencil.IsZero() {
+		return fmt.Errorf("playstation5: stencil buffers are not supported yet")
+	}
+	if blend.IsDualSource() {
+		return fmt.Errorf("playstation5: dual-source blending is not supported yet")
+	}
+
 	cSrcs := make([]C.int, len(srcs))
 	for i, src := range srcs {
 		cSrcs[i] = C.int(src)
@@ -0,0 +1,66 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphicsdriver
+
+import (
+	"io/fs"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+// The file name suffixes of precompiled shader binaries in a shader cache.
+// A file name is the source hash of a shader followed by the suffix.
+const (
+	ShaderCacheMetalLibrarySuffix = ".metallib"
+	ShaderCacheFXCVertexSuffix    = ".vs.fxc"
+	ShaderCacheFXCPixelSuffix     = ".ps.fxc"
+)
+
+var (
+	shaderCache  fs.FS
+	shaderCacheM sync.Mutex
+)
+
+// SetShaderCache sets a file system having precompiled shader binaries.
+// If fsys is nil, the shader cache is not used.
+func SetShaderCache(fsys fs.FS) {
+	shaderCacheM.Lock()
+	defer shaderCacheM.Unlock()
+	shaderCache = fsys
+}
+
+// ReadShaderCache reads a precompiled shader binary for the source hash with the given file name suffix.
+//
+// ReadShaderCache returns nil if the shader cache is not set or the binary doesn't exist.
+func ReadShaderCache(hash shaderir.SourceHash, suffix string) []byte {
+	// A zero hash means that the shader doesn't correspond to a source as it is, e.g., a shader with runtime-sized arrays.
+	if hash == (shaderir.SourceHash{}) {
+		return nil
+	}
+
+	shaderCacheM.Lock()
+	fsys := shaderCache
+	shaderCacheM.Unlock()
+
+	if fsys == nil {
+		return nil
+	}
+	bin, err := fs.ReadFile(fsys, hash.String()+suffix)
+	if err != nil {
+		return nil
+	}
+	return bin
+}
@@ -22,6 +22,7 @@ var (
 	object       = js.Global().Get("Object")
 	arrayBuffer  = js.Global().Get("ArrayBuffer")
 	uint8Array   = js.Global().Get("Uint8Array")
+	uint16Array  = js.Global().Get("Uint16Array")
 	float32Array = js.Global().Get("Float32Array")
 	int32Array   = js.Global().Get("Int32Array")
 )
@@ -37,6 +38,9 @@ var (
 	// temporaryUint8Array is a Uint8ArrayBuffer whose underlying buffer is always temporaryArrayBuffer.
 	temporaryUint8Array = uint8Array.New(temporaryArrayBuffer)
 
+	// temporaryUint16Array is a Uint16ArrayBuffer whose underlying buffer is always temporaryArrayBuffer.
+	temporaryUint16Array = uint16Array.New(temporaryArrayBuffer)
+
 	// temporaryFloat32Array is a Float32ArrayBuffer whose underlying buffer is always temporaryArrayBuffer.
 	temporaryFloat32Array = float32Array.New(temporaryArrayBuffer)
 
@@ -52,6 +56,7 @@ func ensureTemporaryArrayBufferSize(byteLength int) {
 		temporaryArrayBufferByteLength = bufl
 		temporaryArrayBuffer = arrayBuffer.New(bufl)
 		temporaryUint8Array = uint8Array.New(temporaryArrayBuffer)
+		temporaryUint16Array = uint16Array.New(temporaryArrayBuffer)
 		temporaryFloat32Array = float32Array.New(temporaryArrayBuffer)
 		temporaryInt32Array = int32Array.New(temporaryArrayBuffer)
 	}
@@ -84,6 +89,15 @@ func TemporaryUint8ArrayFromFloat32Slice(minLength int, data []float32) js.Value
 	return temporaryUint8Array
 }
 
+// TemporaryUint16Array returns a Uint16Array whose length is at least minLength.
+// Be careful that the length can exceed the given minLength.
+// data must be a slice of a numeric type for initialization, or nil if you don't need initialization.
+func TemporaryUint16Array(minLength int, data []uint16) js.Value {
+	ensureTemporaryArrayBufferSize(minLength * 2)
+	copySliceToTemporaryArrayBuffer(data)
+	return temporaryUint16Array
+}
+
 // TemporaryFloat32Array returns a Float32Array whose length is at least minLength.
 // Be careful that the length can exceed the given minLength.
 // data must be a slice of a numeric type for initialization, or nil if you don't need initialization.
@@ -39,16 +39,18 @@ type Mipmap struct {
 	width     int
 	height    int
 	imageType atlas.ImageType
+	format    graphicsdriver.PixelFormat
 	orig      *buffered.Image
 	imgs      map[int]*buffered.Image
 }
 
-func New(width, height int, imageType atlas.ImageType) *Mipmap {
+func New(width, height int, imageType atlas.ImageType, format graphicsdriver.PixelFormat) *Mipmap {
 	return &Mipmap{
 		width:     width,
 		height:    height,
-		orig:      buffered.NewImage(width, height, imageType),
+		orig:      buffered.NewImage(width, height, imageType, format),
 		imageType: imageType,
+		format:    format,
 	}
 }
 
@@ -65,7 +67,28 @@ func (m *Mipmap) ReadPixels(graphicsDriver graphicsdriver.Graphics, pixels []byt
 	return m.orig.ReadPixels(graphicsDriver, pixels, region)
 }
 
-func (m *Mipmap) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Mipmap, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *atlas.Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, canSkipMipmap bool) {
+func (m *Mipmap) ReadPixelsAsync(pixels []byte, region image.Rectangle, callback func(err error)) {
+	m.orig.ReadPixelsAsync(pixels, region, callback)
+}
+
+func (m *Mipmap) SetSamples(samples int) {
+	m.orig.SetSamples(samples)
+}
+
+func (m *Mipmap) SetNativeTexture(handle uintptr) {
+	m.orig.SetNativeTexture(handle)
+}
+
+func (m *Mipmap) PinToAtlas() {
+	m.orig.PinToAtlas()
+}
+
+func (m *Mipmap) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Mipmap, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *atlas.Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil, canSkipMipmap bool) {
+	m.DrawTrianglesToMultipleTargets([graphics.ShaderDstImageCount - 1]*Mipmap{}, srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil, canSkipMipmap)
+}
+
+// DrawTrianglesToMultipleTargets draws triangles to the mipmap and the additional mipmaps at once.
+func (m *Mipmap) DrawTrianglesToMultipleTargets(additionalDsts [graphics.ShaderDstImageCount - 1]*Mipmap, srcs [graphics.ShaderSrcImageCount]*Mipmap, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *atlas.Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil, canSkipMipmap bool) {
 	if len(indices) == 0 {
 		return
 	}
@@ -123,10 +146,47 @@ func (m *Mipmap) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Mipmap, verti
 		imgs[i] = src.orig
 	}
 
-	m.orig.DrawTriangles(imgs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule)
+	if additionalDsts[0] == nil {
+		m.orig.DrawTriangles(imgs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+		m.deallocateMipmaps()
+		return
+	}
+
+	var dsts [graphics.ShaderDstImageCount - 1]*buffered.Image
+	for i, dst := range additionalDsts {
+		if dst == nil {
+			continue
+		}
+		dsts[i] = dst.orig
+	}
+	m.orig.DrawTrianglesToMultipleTargets(dsts, imgs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, depthMode, stencil)
+	m.deallocateMipmaps()
+	for _, dst := range additionalDsts {
+		if dst == nil {
+			continue
+		}
+		dst.deallocateMipmaps()
+	}
+}
+
+func (m *Mipmap) CopyFrom(src *Mipmap, dstPoint image.Point, srcRegion image.Rectangle) {
+	m.orig.CopyFrom(src.orig, dstPoint, srcRegion)
 	m.deallocateMipmaps()
 }
 
+// DispatchCompute executes the compute kernel shader with x * y * z workgroups.
+// The original images of mipmaps are bound to the storage images of the kernel in order.
+func DispatchCompute(shader *atlas.Shader, mipmaps []*Mipmap, uniforms []uint32, x, y, z int) {
+	imgs := make([]*buffered.Image, len(mipmaps))
+	for k, m := range mipmaps {
+		imgs[k] = m.orig
+	}
+	buffered.DispatchCompute(shader, imgs, uniforms, x, y, z)
+	for _, m := range mipmaps {
+		m.deallocateMipmaps()
+	}
+}
+
 func (m *Mipmap) setImg(level int, img *buffered.Image) {
 	if m.imgs == nil {
 		m.imgs = map[int]*buffered.Image{}
@@ -181,10 +241,10 @@ func (m *Mipmap) level(level int) *buffered.Image {
 		return nil
 	}
 
-	s := buffered.NewImage(w2, h2, m.imageType)
+	s := buffered.NewImage(w2, h2, m.imageType, m.format)
 
 	dstRegion := image.Rect(0, 0, w2, h2)
-	s.DrawTriangles([graphics.ShaderSrcImageCount]*buffered.Image{src}, vs, is, graphicsdriver.BlendCopy, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.LinearFilterShader, nil, graphicsdriver.FillRuleFillAll)
+	s.DrawTriangles([graphics.ShaderSrcImageCount]*buffered.Image{src}, vs, is, graphicsdriver.BlendCopy, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{}, atlas.LinearFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{})
 	m.setImg(level, s)
 
 	return m.imgs[level]
@@ -205,6 +265,12 @@ func (m *Mipmap) Deallocate() {
 	m.orig.Deallocate()
 }
 
+// DeallocateMipmaps deallocates the mipmap images except for the original image.
+// The mipmap images are created again lazily when needed.
+func (m *Mipmap) DeallocateMipmaps() {
+	m.deallocateMipmaps()
+}
+
 func (m *Mipmap) deallocateMipmaps() {
 	for _, img := range m.imgs {
 		if img != nil {
@@ -0,0 +1,255 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+const (
+	debugfName = "Debugf"
+
+	// DebugMaxComponentCount is the maximum number of the value components in total for one Debugf call.
+	DebugMaxComponentCount = 4
+
+	debugEncodeFuncName = "__debugEncode"
+)
+
+// debugEncodeFunc encodes a Debugf call index and a value into a color so that the value can be read from an RGBA8 image.
+//
+// The red channel is the call index + 1. 0 means that no Debugf is called.
+// The green channel is the sign bit and the biased exponent. 0 means the value is 0.
+// The blue and the alpha channels are the upper and lower bytes of the 16-bit mantissa.
+// See DecodeDebugValue for decoding.
+const debugEncodeFunc = `
+func __debugEncode(index float, v float) vec4 {
+	e := 0.0
+	m := 0.0
+	if v != 0 {
+		a := abs(v)
+		e = floor(log2(a))
+		f := a / exp2(e)
+		// Correct the errors of log2.
+		if f >= 2 {
+			e += 1
+			f /= 2
+		}
+		if f < 1 {
+			e -= 1
+			f *= 2
+		}
+		e = clamp(e, -63, 63) + 64
+		if v < 0 {
+			e += 128
+		}
+		m = floor((f - 1) * 65536)
+	}
+	return vec4(index, e, floor(m/256), mod(m, 256)) / 255
+}
+`
+
+// DecodeDebugValue decodes a pixel rendered by a shader compiled with CompileForDebug.
+//
+// DecodeDebugValue returns the index of the Debugf call and the value.
+// If no Debugf is called for the pixel, DecodeDebugValue returns false.
+func DecodeDebugValue(pixel [4]byte) (index int, value float64, ok bool) {
+	if pixel[0] == 0 {
+		return 0, 0, false
+	}
+	index = int(pixel[0]) - 1
+	if pixel[1] == 0 {
+		return index, 0, true
+	}
+	e := int(pixel[1]&0x7f) - 64
+	m := float64(int(pixel[2])<<8|int(pixel[3])) / 65536
+	value = math.Ldexp(1+m, e)
+	if pixel[1]&0x80 != 0 {
+		value = -value
+	}
+	return index, value, true
+}
+
+// CompileForDebug compiles a shader to inspect the values of the Debugf calls.
+//
+// The fragment entry point returns the encoded value of the component-th value component at the first executed Debugf call.
+// The result is decoded by DecodeDebugValue.
+func CompileForDebug(src []byte, vertexEntry, fragmentEntry string, textureCount int, arrayLengths map[string]int, component int) (*shaderir.Program, error) {
+	if component < 0 || component >= DebugMaxComponentCount {
+		return nil, fmt.Errorf("shader: component must be in [0, %d) but %d", DebugMaxComponentCount, component)
+	}
+	_, compute, err := ParseComputeDirective(src)
+	if err != nil {
+		return nil, err
+	}
+	if compute {
+		return nil, fmt.Errorf("shader: a compute kernel with //kage:compute cannot be compiled for debugging")
+	}
+	src = append(src[:len(src):len(src)], debugEncodeFunc...)
+	p, err := compile(src, vertexEntry, fragmentEntry, "", textureCount, arrayLengths, component)
+	if err != nil {
+		return nil, err
+	}
+	// The program doesn't correspond to the source as it is.
+	p.SourceHash = shaderir.SourceHash{}
+	return p, nil
+}
+
+func (cs *compileState) isDebugfCall(call *ast.CallExpr) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok || ident.Name != debugfName {
+		return false
+	}
+	// A user-defined function has priority.
+	if _, ok := cs.findFunction(debugfName); ok {
+		return false
+	}
+	return true
+}
+
+// parseDebugf parses a Debugf call like `Debugf("pos: %v", pos)`.
+//
+// Without a debugging compilation, a Debugf call is only type-checked and removed.
+// With a debugging compilation, a Debugf call is converted to a return statement with the encoded value.
+func (cs *compileState) parseDebugf(block *block, fname string, call *ast.CallExpr, inParams, outParams []variable, returnType shaderir.Type) ([]shaderir.Stmt, bool) {
+	if fname != cs.fragmentEntry {
+		cs.addError(call.Pos(), fmt.Sprintf("%s is available only in the fragment entry point", debugfName))
+		return nil, false
+	}
+	if !(len(outParams) == 0 && returnType.Main == shaderir.Vec4) && !(len(outParams) == 1 && outParams[0].typ.Main == shaderir.Vec4) {
+		cs.addError(call.Pos(), fmt.Sprintf("%s is available only in the fragment entry point returning one vec4 value", debugfName))
+		return nil, false
+	}
+	if len(call.Args) == 0 {
+		cs.addError(call.Pos(), fmt.Sprintf("not enough arguments in call to %s", debugfName))
+		return nil, false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		cs.addError(call.Args[0].Pos(), fmt.Sprintf("the first argument of %s must be a string literal", debugfName))
+		return nil, false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		cs.addError(lit.Pos(), fmt.Sprintf("invalid string literal: %s", lit.Value))
+		return nil, false
+	}
+
+	// Each element of components is the expression of one value component.
+	var components []ast.Expr
+	var argTypes []shaderir.Type
+	for _, arg := range call.Args[1:] {
+		_, ts, _, ok := cs.parseExpr(block, fname, arg, true)
+		if !ok {
+			return nil, false
+		}
+		if len(ts) != 1 {
+			cs.addError(arg.Pos(), "multiple-value context is not available at an argument of "+debugfName)
+			return nil, false
+		}
+		t := ts[0]
+		switch t.Main {
+		case shaderir.Float, shaderir.Int:
+			components = append(components, debugFloatExpr(arg, t.Main == shaderir.Int))
+		case shaderir.Vec2, shaderir.Vec3, shaderir.Vec4, shaderir.IVec2, shaderir.IVec3, shaderir.IVec4:
+			for i := 0; i < t.VectorElementCount(); i++ {
+				e := &ast.SelectorExpr{
+					X: arg,
+					Sel: &ast.Ident{
+						NamePos: arg.End(),
+						Name:    string("xyzw"[i]),
+					},
+				}
+				components = append(components, debugFloatExpr(e, t.IsIntVector()))
+			}
+		default:
+			cs.addError(arg.Pos(), fmt.Sprintf("%s cannot take a value of %s", debugfName, t.String()))
+			return nil, false
+		}
+		argTypes = append(argTypes, t)
+	}
+	if len(components) > DebugMaxComponentCount {
+		cs.addError(call.Pos(), fmt.Sprintf("%s can take at most %d value components in total but %d", debugfName, DebugMaxComponentCount, len(components)))
+		return nil, false
+	}
+
+	// A function body might be parsed more than once. Register the call only once.
+	index, ok := cs.debugCallIndices[call.Pos()]
+	if !ok {
+		index = len(cs.ir.DebugCalls)
+		if index >= 255 {
+			cs.addError(call.Pos(), fmt.Sprintf("too many %s calls", debugfName))
+			return nil, false
+		}
+		if cs.debugCallIndices == nil {
+			cs.debugCallIndices = map[token.Pos]int{}
+		}
+		cs.debugCallIndices[call.Pos()] = index
+		cs.ir.DebugCalls = append(cs.ir.DebugCalls, shaderir.DebugCall{
+			Format:   format,
+			ArgTypes: argTypes,
+		})
+	}
+
+	if cs.debugComponent < 0 {
+		return nil, true
+	}
+
+	var v ast.Expr = &ast.BasicLit{
+		ValuePos: call.Pos(),
+		Kind:     token.FLOAT,
+		Value:    "0.0",
+	}
+	if cs.debugComponent < len(components) {
+		v = components[cs.debugComponent]
+	}
+	return cs.parseStmt(block, fname, &ast.ReturnStmt{
+		Return: call.Pos(),
+		Results: []ast.Expr{
+			&ast.CallExpr{
+				Fun: &ast.Ident{
+					NamePos: call.Pos(),
+					Name:    debugEncodeFuncName,
+				},
+				Args: []ast.Expr{
+					&ast.BasicLit{
+						ValuePos: call.Pos(),
+						Kind:     token.FLOAT,
+						Value:    fmt.Sprintf("%d.0", index+1),
+					},
+					v,
+				},
+			},
+		},
+	}, inParams, outParams, returnType)
+}
+
+func debugFloatExpr(expr ast.Expr, isInt bool) ast.Expr {
+	if !isInt {
+		return expr
+	}
+	return &ast.CallExpr{
+		Fun: &ast.Ident{
+			NamePos: expr.Pos(),
+			Name:    "float",
+		},
+		Args: []ast.Expr{expr},
+	}
+}
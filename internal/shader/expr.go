@@ -36,6 +36,28 @@ func canTruncateToFloat(v gconstant.Value) bool {
 
 var textureVariableRe = regexp.MustCompile(`\A__t(\d+)\z`)
 
+// maxStorageImageCount is the maximum number of the storage images a compute kernel can use.
+const maxStorageImageCount = 8
+
+// storageImageIndex returns the index of a storage image specified by a constant integer expression.
+func storageImageIndex(expr *shaderir.Expr, typ *shaderir.Type) (int, bool) {
+	if expr.Const == nil {
+		return 0, false
+	}
+	if typ.Main != shaderir.None && typ.Main != shaderir.Int {
+		return 0, false
+	}
+	v := gconstant.ToInt(expr.Const)
+	if v.Kind() != gconstant.Int {
+		return 0, false
+	}
+	idx, ok := gconstant.Int64Val(v)
+	if !ok || idx < 0 || idx >= maxStorageImageCount {
+		return 0, false
+	}
+	return int(idx), true
+}
+
 func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, markLocalVariableUsed bool) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
 	switch e := expr.(type) {
 	case *ast.BasicLit:
@@ -102,7 +124,12 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			op = token.QUO_ASSIGN
 		}
 
-		op2, ok := shaderir.OpFromToken(e.Op, lhst, rhst)
+		// x &^ y is treated as x & ^y.
+		irOp := e.Op
+		if irOp == token.AND_NOT {
+			irOp = token.AND
+		}
+		op2, ok := shaderir.OpFromToken(irOp, lhst, rhst)
 		if !ok {
 			cs.addError(e.Pos(), fmt.Sprintf("unexpected operator: %s", e.Op))
 			return nil, nil, nil, false
@@ -176,6 +203,10 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			}, []shaderir.Type{t}, stmts, true
 		}
 
+		if e.Op == token.AND_NOT {
+			rhs[0] = complementExpr(rhs[0])
+		}
+
 		return []shaderir.Expr{
 			{
 				Type:  shaderir.Binary,
@@ -185,6 +216,16 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		}, []shaderir.Type{t}, stmts, true
 
 	case *ast.CallExpr:
+		// An array of a struct doesn't exist as a value. Evaluate len and cap for it here.
+		if l, ok := cs.structArrayLength(block, e); ok {
+			return []shaderir.Expr{
+				{
+					Type:  shaderir.NumberExpr,
+					Const: gconstant.MakeInt64(int64(l)),
+				},
+			}, []shaderir.Type{{Main: shaderir.Int}}, nil, true
+		}
+
 		var (
 			callee shaderir.Expr
 			args   []shaderir.Expr
@@ -228,6 +269,14 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 		// For built-in functions, we can call this in this position. Return an expression for the function
 		// call.
 		if callee.Type == shaderir.BuiltinFuncExpr {
+			if cs.computeEntry != "" {
+				switch callee.BuiltinFunc {
+				case shaderir.Dfdx, shaderir.Dfdy, shaderir.Fwidth, shaderir.TexelAt, shaderir.TexelAtLod, shaderir.TexelFetch, shaderir.TexelAtAnisotropic, shaderir.DiscardF:
+					cs.addError(e.Pos(), fmt.Sprintf("%s is not available in a compute kernel", callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+			}
+
 			// Process compile-time evaluations.
 			switch callee.BuiltinFunc {
 			case shaderir.Len, shaderir.Cap:
@@ -239,10 +288,16 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					cs.addError(e.Pos(), fmt.Sprintf("%s takes an array but %s", callee.BuiltinFunc, argts[0].String()))
 					return nil, nil, nil, false
 				}
+				length := argts[0].Length
+				if args[0].Type == shaderir.UniformVariable {
+					if l, ok := cs.runtimeSizedUniformLength(cs.ir.UniformNames[args[0].Index]); ok {
+						length = l
+					}
+				}
 				return []shaderir.Expr{
 					{
 						Type:  shaderir.NumberExpr,
-						Const: gconstant.MakeInt64(int64(argts[0].Length)),
+						Const: gconstant.MakeInt64(int64(length)),
 					},
 				}, []shaderir.Type{{Main: shaderir.Int}}, stmts, true
 			case shaderir.BoolF:
@@ -407,7 +462,7 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					argts[i] = shaderir.Type{Main: shaderir.Float}
 				}
 				finalType = shaderir.Type{Main: shaderir.Mat4}
-			case shaderir.TexelAt:
+			case shaderir.TexelAt, shaderir.TexelAtAnisotropic:
 				if len(args) != 2 {
 					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 2 but %d", callee.BuiltinFunc, len(args)))
 					return nil, nil, nil, false
@@ -421,6 +476,38 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					return nil, nil, nil, false
 				}
 				finalType = shaderir.Type{Main: shaderir.Vec4}
+			case shaderir.TexelAtLod:
+				if len(args) != 3 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 3 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				if argts[0].Main != shaderir.Texture {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as texture value in argument to %s", argts[0].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if argts[1].Main != shaderir.Vec2 {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as vec2 value in argument to %s", argts[1].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if argts[2].Main != shaderir.Float {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as float value in argument to %s", argts[2].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				finalType = shaderir.Type{Main: shaderir.Vec4}
+			case shaderir.TexelFetch:
+				if len(args) != 2 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 2 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				if argts[0].Main != shaderir.Texture {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as texture value in argument to %s", argts[0].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if argts[1].Main != shaderir.IVec2 {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as ivec2 value in argument to %s", argts[1].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				finalType = shaderir.Type{Main: shaderir.Vec4}
 			case shaderir.DiscardF:
 				if len(args) != 0 {
 					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 0 but %d", callee.BuiltinFunc, len(args)))
@@ -434,6 +521,80 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 					Type: shaderir.Discard,
 				})
 				return nil, nil, stmts, true
+			case shaderir.ImageLoad, shaderir.ImageStore:
+				if cs.computeEntry == "" {
+					cs.addError(e.Pos(), fmt.Sprintf("%s is available only in a compute kernel", callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				n := 2
+				if callee.BuiltinFunc == shaderir.ImageStore {
+					n = 3
+				}
+				if len(args) != n {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be %d but %d", callee.BuiltinFunc, n, len(args)))
+					return nil, nil, nil, false
+				}
+				idx, ok := storageImageIndex(&args[0], &argts[0])
+				if !ok {
+					cs.addError(e.Pos(), fmt.Sprintf("the first argument for %s must be a constant integer in [0, %d)", callee.BuiltinFunc, maxStorageImageCount))
+					return nil, nil, nil, false
+				}
+				if argts[1].Main != shaderir.IVec2 {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as ivec2 value in argument to %s", argts[1].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if cs.ir.StorageImageCount <= idx {
+					cs.ir.StorageImageCount = idx + 1
+				}
+				args[0] = shaderir.Expr{
+					Type:  shaderir.StorageImageVariable,
+					Index: idx,
+				}
+
+				if callee.BuiltinFunc == shaderir.ImageLoad {
+					return []shaderir.Expr{
+						{
+							Type:  shaderir.Call,
+							Exprs: append([]shaderir.Expr{callee}, args...),
+						},
+					}, []shaderir.Type{{Main: shaderir.Vec4}}, stmts, true
+				}
+
+				if argts[2].Main != shaderir.Vec4 {
+					cs.addError(e.Pos(), fmt.Sprintf("cannot use %s as vec4 value in argument to %s", argts[2].String(), callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				stmts = append(stmts, shaderir.Stmt{
+					Type: shaderir.ExprStmt,
+					Exprs: []shaderir.Expr{
+						{
+							Type:  shaderir.Call,
+							Exprs: append([]shaderir.Expr{callee}, args...),
+						},
+					},
+				})
+				return nil, nil, stmts, true
+			case shaderir.Barrier:
+				if cs.computeEntry == "" {
+					cs.addError(e.Pos(), fmt.Sprintf("%s is available only in a compute kernel", callee.BuiltinFunc))
+					return nil, nil, nil, false
+				}
+				if len(args) != 0 {
+					cs.addError(e.Pos(), fmt.Sprintf("number of %s's arguments must be 0 but %d", callee.BuiltinFunc, len(args)))
+					return nil, nil, nil, false
+				}
+				stmts = append(stmts, shaderir.Stmt{
+					Type: shaderir.ExprStmt,
+					Exprs: []shaderir.Expr{
+						{
+							Type: shaderir.Call,
+							Exprs: []shaderir.Expr{
+								callee,
+							},
+						},
+					},
+				})
+				return nil, nil, stmts, true
 
 			case shaderir.Clamp, shaderir.Mix, shaderir.Smoothstep, shaderir.Faceforward, shaderir.Refract:
 				// 3 arguments
@@ -896,6 +1057,22 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				},
 			}, []shaderir.Type{cs.ir.Uniforms[i]}, nil, true
 		}
+		if i, ok := cs.findSharedVariable(e.Name); ok {
+			return []shaderir.Expr{
+				{
+					Type:  shaderir.SharedVariable,
+					Index: i,
+				},
+			}, []shaderir.Type{cs.ir.SharedVars[i]}, nil, true
+		}
+		if i, ok := cs.findConstArray(e.Name); ok {
+			return []shaderir.Expr{
+				{
+					Type:  shaderir.ConstArrayVariable,
+					Index: i,
+				},
+			}, []shaderir.Type{cs.ir.ConstArrays[i].Type}, nil, true
+		}
 		if f, ok := shaderir.ParseBuiltinFunc(e.Name); ok {
 			return []shaderir.Expr{
 				{
@@ -921,12 +1098,20 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 				},
 			}, []shaderir.Type{{Main: shaderir.Bool}}, nil, true
 		}
+		if cs.isStructUniformVariable(e.Name) {
+			cs.addError(e.Pos(), fmt.Sprintf("a struct uniform variable cannot be used as a value: %s", e.Name))
+			return nil, nil, nil, false
+		}
 		cs.addError(e.Pos(), fmt.Sprintf("unexpected identifier: %s", e.Name))
 
 	case *ast.ParenExpr:
 		return cs.parseExpr(block, fname, e.X, markLocalVariableUsed)
 
 	case *ast.SelectorExpr:
+		if path, idx, ok := cs.structUniformPath(block, e.X); ok {
+			return cs.parseStructUniformMember(block, fname, e, path, idx)
+		}
+
 		exprs, types, stmts, ok := cs.parseExpr(block, fname, e.X, true)
 		if !ok {
 			return nil, nil, nil, false
@@ -997,6 +1182,17 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			return nil, nil, nil, false
 		}
 
+		if e.Op == token.XOR {
+			if t := ts[0]; t.Main != shaderir.Int && !t.IsIntVector() && (t.Main != shaderir.None || exprs[0].Const == nil || exprs[0].Const.Kind() != gconstant.Int) {
+				name := t.String()
+				if t.Main == shaderir.None && exprs[0].Const != nil {
+					name = fmt.Sprintf("untyped constant %s", exprs[0].Const.String())
+				}
+				cs.addError(e.Pos(), fmt.Sprintf("invalid operation: operator ^ not defined on %s", name))
+				return nil, nil, nil, false
+			}
+		}
+
 		if exprs[0].Const != nil {
 			v := gconstant.UnaryOp(e.Op, exprs[0].Const, 0)
 			// Use the original type as it is.
@@ -1017,6 +1213,8 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 			op = shaderir.Sub
 		case token.NOT:
 			op = shaderir.NotOp
+		case token.XOR:
+			op = shaderir.ComplementOp
 		default:
 			cs.addError(e.Pos(), fmt.Sprintf("unexpected operator: %s", e.Op))
 			return nil, nil, nil, false
@@ -1190,6 +1388,109 @@ func (cs *compileState) parseExpr(block *block, fname string, expr ast.Expr, mar
 	return nil, nil, nil, false
 }
 
+// structUniformPath returns the name of the struct the given expression represents,
+// like "Mat" for `Mat` and "Lights.Inner" for `Lights[i].Inner`.
+// If the struct is an element of a struct array, structUniformPath also returns the index expression.
+func (cs *compileState) structUniformPath(block *block, expr ast.Expr) (string, ast.Expr, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if _, ok := cs.structPaths[e.Name]; !ok {
+			return "", nil, false
+		}
+		if _, _, ok := block.findLocalVariable(e.Name, false); ok {
+			return "", nil, false
+		}
+		return e.Name, nil, true
+	case *ast.IndexExpr:
+		id, ok := e.X.(*ast.Ident)
+		if !ok {
+			return "", nil, false
+		}
+		if _, ok := cs.structArrayLengths[id.Name]; !ok {
+			return "", nil, false
+		}
+		if _, _, ok := block.findLocalVariable(id.Name, false); ok {
+			return "", nil, false
+		}
+		return id.Name, e.Index, true
+	case *ast.ParenExpr:
+		return cs.structUniformPath(block, e.X)
+	case *ast.SelectorExpr:
+		path, idx, ok := cs.structUniformPath(block, e.X)
+		if !ok {
+			return "", nil, false
+		}
+		path += "." + e.Sel.Name
+		if _, ok := cs.structPaths[path]; ok {
+			return path, idx, true
+		}
+	}
+	return "", nil, false
+}
+
+// parseStructUniformMember parses a selector expression for a member of a struct uniform variable.
+func (cs *compileState) parseStructUniformMember(block *block, fname string, e *ast.SelectorExpr, path string, idx ast.Expr) ([]shaderir.Expr, []shaderir.Type, []shaderir.Stmt, bool) {
+	name := path + "." + e.Sel.Name
+	i, ok := cs.findUniformVariable(name)
+	if !ok {
+		if _, ok := cs.structPaths[name]; ok {
+			cs.addError(e.Pos(), fmt.Sprintf("a struct uniform variable cannot be used as a value: %s", name))
+			return nil, nil, nil, false
+		}
+		cs.addError(e.Pos(), fmt.Sprintf("%s has no field %s", path, e.Sel.Name))
+		return nil, nil, nil, false
+	}
+
+	expr := shaderir.Expr{
+		Type:  shaderir.UniformVariable,
+		Index: i,
+	}
+	t := cs.ir.Uniforms[i]
+	if idx == nil {
+		return []shaderir.Expr{expr}, []shaderir.Type{t}, nil, true
+	}
+
+	// A member of an element of a struct array is an element of the flattened array.
+	exprs, _, stmts, ok := cs.parseExpr(block, fname, idx, true)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	if len(exprs) != 1 {
+		cs.addError(e.Pos(), "multiple-value context is not available at an index expression")
+		return nil, nil, nil, false
+	}
+	if exprs[0].Const != nil && !canTruncateToInteger(exprs[0].Const) {
+		cs.addError(e.Pos(), fmt.Sprintf("constant %s truncated to integer", exprs[0].Const.String()))
+		return nil, nil, nil, false
+	}
+	return []shaderir.Expr{
+		{
+			Type:  shaderir.Index,
+			Exprs: []shaderir.Expr{expr, exprs[0]},
+		},
+	}, []shaderir.Type{t.Sub[0]}, stmts, true
+}
+
+// structArrayLength returns the length if the given expression is len or cap for a struct array uniform variable.
+func (cs *compileState) structArrayLength(block *block, e *ast.CallExpr) (int, bool) {
+	f, ok := e.Fun.(*ast.Ident)
+	if !ok || (f.Name != "len" && f.Name != "cap") || len(e.Args) != 1 {
+		return 0, false
+	}
+	id, ok := e.Args[0].(*ast.Ident)
+	if !ok {
+		return 0, false
+	}
+	l, ok := cs.structArrayLengths[id.Name]
+	if !ok {
+		return 0, false
+	}
+	if _, _, ok := block.findLocalVariable(id.Name, false); ok {
+		return 0, false
+	}
+	return l, true
+}
+
 func isValidSwizzling(swizzling string, t shaderir.Type) bool {
 	if !shaderir.IsValidSwizzling(swizzling) {
 		return false
@@ -1272,3 +1573,18 @@ func resolveConstKind(exprs []shaderir.Expr, ts []shaderir.Type) (kind gconstant
 
 	return gconstant.Int, true
 }
+
+// complementExpr returns the bitwise complement of the integer expression.
+func complementExpr(expr shaderir.Expr) shaderir.Expr {
+	if expr.Const != nil {
+		return shaderir.Expr{
+			Type:  shaderir.NumberExpr,
+			Const: gconstant.UnaryOp(token.XOR, expr.Const, 0),
+		}
+	}
+	return shaderir.Expr{
+		Type:  shaderir.Unary,
+		Op:    shaderir.ComplementOp,
+		Exprs: []shaderir.Expr{expr},
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ImportResolver returns the source of a module imported by the //kage:import directive.
+type ImportResolver func(path string) ([]byte, error)
+
+var (
+	reImport          = regexp.MustCompile(`^[ \t\r]*//kage:import(?:[ \t]+(.*?))?[ \t\r]*$`)
+	reModuleDirective = regexp.MustCompile(`^[ \t\r]*//kage:(unit|compute)\b`)
+)
+
+// ExpandImports resolves the //kage:import directives in src, and returns the source including the imported modules.
+//
+// The directive is like '//kage:import "mylib/noise"'. A module is a Kage source with a package clause.
+// The top-level declarations of a module are available in the importing source as they are, without a package name.
+// A module can import other modules. Each module is included only once even if it is imported multiple times.
+//
+// The line information of the imported modules is kept with //line directives so that the compiler reports errors with the module paths.
+//
+// If src has no //kage:import directives, ExpandImports returns src as it is.
+// If src has //kage:import directives and resolve is nil, ExpandImports returns an error.
+func ExpandImports(src []byte, resolve ImportResolver) ([]byte, error) {
+	paths, body, err := parseImports(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return src, nil
+	}
+	if resolve == nil {
+		return nil, fmt.Errorf("shader: //kage:import requires an import resolver")
+	}
+
+	head, tail, tailLine, err := splitAtPackageClause("", body)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &importExpander{
+		resolve: resolve,
+		visited: map[string]struct{}{},
+	}
+	for _, path := range paths {
+		if err := e.expand(path, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(head)
+	buf.Write(e.buf.Bytes())
+	// Restore the line information of src. An empty file name means the original file name.
+	fmt.Fprintf(&buf, "//line :%d\n", tailLine)
+	buf.Write(tail)
+	return buf.Bytes(), nil
+}
+
+type importExpander struct {
+	resolve ImportResolver
+	visited map[string]struct{}
+	buf     bytes.Buffer
+}
+
+func (e *importExpander) expand(path string, importing []string) error {
+	for i, p := range importing {
+		if p == path {
+			return fmt.Errorf("shader: import cycle: %s", strings.Join(append(importing[i:], path), " -> "))
+		}
+	}
+	if _, ok := e.visited[path]; ok {
+		return nil
+	}
+
+	src, err := e.resolve(path)
+	if err != nil {
+		return fmt.Errorf("shader: resolving the module %q failed: %w", path, err)
+	}
+
+	paths, body, err := parseImports(src)
+	if err != nil {
+		return fmt.Errorf("shader: module %q: %w", path, err)
+	}
+	if m := findModuleDirective(body); m != "" {
+		return fmt.Errorf("shader: module %q: //kage:%s is not available in a module", path, m)
+	}
+
+	// Dependencies come first so that their constants and types are available in this module.
+	importing = append(importing, path)
+	for _, p := range paths {
+		if err := e.expand(p, importing); err != nil {
+			return err
+		}
+	}
+
+	_, tail, tailLine, err := splitAtPackageClause(path, body)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&e.buf, "//line %s:%d\n", path, tailLine)
+	e.buf.Write(tail)
+	if len(tail) > 0 && tail[len(tail)-1] != '\n' {
+		e.buf.WriteByte('\n')
+	}
+	e.visited[path] = struct{}{}
+	return nil
+}
+
+// ParseImportDirectives returns the paths of the //kage:import directives in src.
+func ParseImportDirectives(src []byte) ([]string, error) {
+	paths, _, err := parseImports(src)
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// parseImports returns the paths of the //kage:import directives in src, and src where the directives are replaced with empty lines.
+func parseImports(src []byte) ([]string, []byte, error) {
+	var paths []string
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	for i, l := range lines {
+		m := reImport.FindSubmatch(bytes.TrimSuffix(l, []byte("\n")))
+		if m == nil {
+			continue
+		}
+		path, err := strconv.Unquote(string(m[1]))
+		if err != nil || path == "" {
+			return nil, nil, fmt.Errorf("shader: invalid value for //kage:import: %s", m[1])
+		}
+		paths = append(paths, path)
+		if bytes.HasSuffix(l, []byte("\n")) {
+			lines[i] = []byte("\n")
+		} else {
+			lines[i] = nil
+		}
+	}
+	if len(paths) == 0 {
+		return nil, src, nil
+	}
+	return paths, bytes.Join(lines, nil), nil
+}
+
+func findModuleDirective(src []byte) string {
+	for _, l := range bytes.Split(src, []byte("\n")) {
+		if m := reModuleDirective.FindSubmatch(l); m != nil {
+			return string(m[1])
+		}
+	}
+	return ""
+}
+
+// splitAtPackageClause splits src after the line of the package clause.
+// splitAtPackageClause also returns the line number of the beginning of tail.
+func splitAtPackageClause(path string, src []byte) (head, tail []byte, tailLine int, err error) {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, path, src, parser.PackageClauseOnly)
+	if err != nil {
+		if path == "" {
+			return nil, nil, 0, err
+		}
+		return nil, nil, 0, fmt.Errorf("shader: module %q: %w", path, err)
+	}
+	end := fs.Position(f.Name.End())
+	offset := end.Offset
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		offset += i + 1
+	} else {
+		offset = len(src)
+	}
+	head = make([]byte, offset, offset+1)
+	copy(head, src[:offset])
+	if len(head) == 0 || head[len(head)-1] != '\n' {
+		head = append(head, '\n')
+	}
+	return head, src[offset:], end.Line + 1, nil
+}
@@ -23,11 +23,15 @@ import (
 	"go/parser"
 	"go/token"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 )
 
+// MaxRenderTargetCount is the maximum number of the members of a struct the fragment entry point returns for multiple render targets.
+const MaxRenderTargetCount = 4
+
 type variable struct {
 	name           string
 	typ            shaderir.Type
@@ -40,6 +44,23 @@ type constant struct {
 	value gconstant.Value
 }
 
+// structType is a struct type for uniform variables and the result of the fragment entry point.
+// A struct uniform variable is flattened into uniform variables for its members.
+type structType struct {
+	name   string
+	fields []structField
+}
+
+type structField struct {
+	name string
+
+	// typ is the type of the field. typ is valid only when st is nil.
+	typ shaderir.Type
+
+	// st is the struct type of the field if the field is a struct.
+	st *structType
+}
+
 type function struct {
 	name string
 
@@ -51,8 +72,37 @@ type compileState struct {
 
 	vertexEntry   string
 	fragmentEntry string
+	computeEntry  string
 	unit          shaderir.Unit
 
+	sharedVarNames []string
+
+	constArrayNames []string
+
+	// arrayLengths is the lengths of the runtime-sized uniform arrays.
+	arrayLengths map[string]int
+
+	runtimeSizedUniformNames []string
+
+	// structs is the struct types declared at the global scope.
+	structs []*structType
+
+	// structPaths is the names of the struct uniform variables and their struct members like "Mat" and "Mat.Inner".
+	structPaths map[string]struct{}
+
+	// structArrayLengths is the lengths of the uniform variables of struct arrays.
+	structArrayLengths map[string]int
+
+	// fragmentOutputStruct is the struct type the fragment entry point returns for multiple render targets.
+	// fragmentOutputStruct is nil if the fragment entry point doesn't return a struct.
+	fragmentOutputStruct *structType
+
+	// debugComponent is the value component to output at Debugf calls. If debugComponent is negative, Debugf calls are removed.
+	debugComponent int
+
+	// debugCallIndices is the indices of the Debugf calls by their positions.
+	debugCallIndices map[token.Pos]int
+
 	ir shaderir.Program
 
 	funcs []function
@@ -80,6 +130,56 @@ func (cs *compileState) findUniformVariable(name string) (int, bool) {
 	return 0, false
 }
 
+// runtimeSizedUniformLength returns the length of the runtime-sized uniform array specified at the compilation.
+// For a member of a struct array like "Lights.Color", the length of the struct array is returned.
+func (cs *compileState) runtimeSizedUniformLength(name string) (int, bool) {
+	for _, n := range cs.runtimeSizedUniformNames {
+		if n == name {
+			root, _, _ := strings.Cut(name, ".")
+			return cs.arrayLengths[root], true
+		}
+	}
+	return 0, false
+}
+
+func (cs *compileState) findStructType(name string) (*structType, bool) {
+	for _, st := range cs.structs {
+		if st.name == name {
+			return st, true
+		}
+	}
+	return nil, false
+}
+
+// isStructUniformVariable reports whether the name is a uniform variable of a struct or a struct array.
+func (cs *compileState) isStructUniformVariable(name string) bool {
+	if _, ok := cs.structPaths[name]; ok {
+		return true
+	}
+	if _, ok := cs.structArrayLengths[name]; ok {
+		return true
+	}
+	return false
+}
+
+func (cs *compileState) findSharedVariable(name string) (int, bool) {
+	for i, n := range cs.sharedVarNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (cs *compileState) findConstArray(name string) (int, bool) {
+	for i, n := range cs.constArrayNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 type typ struct {
 	name string
 	ir   shaderir.Type
@@ -183,24 +283,84 @@ func (p *ParseError) Error() string {
 }
 
 func Compile(src []byte, vertexEntry, fragmentEntry string, textureCount int) (*shaderir.Program, error) {
+	return CompileWithArrayLengths(src, vertexEntry, fragmentEntry, textureCount, nil)
+}
+
+// CompileWithArrayLengths compiles a shader with the lengths of the runtime-sized uniform arrays.
+//
+// A runtime-sized uniform array is a uniform variable declared as an array without a length like `var Lights []vec4`.
+// arrayLengths is the lengths of the arrays by their names. If a length is not specified, the length is 0.
+// len for a runtime-sized uniform array returns the given length.
+func CompileWithArrayLengths(src []byte, vertexEntry, fragmentEntry string, textureCount int, arrayLengths map[string]int) (*shaderir.Program, error) {
+	_, compute, err := ParseComputeDirective(src)
+	if err != nil {
+		return nil, err
+	}
+	if compute {
+		return nil, fmt.Errorf("shader: a compute kernel with //kage:compute must be compiled by CompileCompute")
+	}
+	return compile(src, vertexEntry, fragmentEntry, "", textureCount, arrayLengths, -1)
+}
+
+// CompileCompute compiles a compute kernel with the //kage:compute directive.
+//
+// The entry point takes at most three ivec3 values: the global invocation ID, the local invocation ID in the workgroup,
+// and the workgroup ID. The entry point doesn't return any values.
+// A compute kernel reads and writes pixels of storage images with imageLoad and imageStore.
+func CompileCompute(src []byte, computeEntry string) (*shaderir.Program, error) {
+	workgroupSize, compute, err := ParseComputeDirective(src)
+	if err != nil {
+		return nil, err
+	}
+	if !compute {
+		return nil, fmt.Errorf("shader: a compute kernel must have //kage:compute")
+	}
+	p, err := compile(src, "", "", computeEntry, 0, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	p.ComputeFunc.WorkgroupSize = workgroupSize
+	return p, nil
+}
+
+// compile compiles a shader.
+//
+// debugComponent is the value component to output at Debugf calls. If debugComponent is negative, Debugf calls are removed.
+func compile(src []byte, vertexEntry, fragmentEntry, computeEntry string, textureCount int, arrayLengths map[string]int, debugComponent int) (*shaderir.Program, error) {
+	// The imports must be already expanded by ExpandImports.
+	if _, err := ExpandImports(src, nil); err != nil {
+		return nil, err
+	}
+
 	unit, err := ParseCompilerDirectives(src)
 	if err != nil {
 		return nil, err
 	}
 
 	fs := token.NewFileSet()
-	f, err := parser.ParseFile(fs, "", src, parser.AllErrors)
+	// Parse comments for the //kage:shared directives.
+	f, err := parser.ParseFile(fs, "", src, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
 
 	s := &compileState{
-		fs:            fs,
-		vertexEntry:   vertexEntry,
-		fragmentEntry: fragmentEntry,
-		unit:          unit,
+		fs:             fs,
+		vertexEntry:    vertexEntry,
+		fragmentEntry:  fragmentEntry,
+		computeEntry:   computeEntry,
+		unit:           unit,
+		arrayLengths:   arrayLengths,
+		debugComponent: debugComponent,
 	}
 	s.ir.SourceHash = shaderir.CalcSourceHash(src)
+	for _, l := range arrayLengths {
+		// A precompiled binary is for the default lengths. Don't use the source hash to avoid picking it.
+		if l > 0 {
+			s.ir.SourceHash = shaderir.SourceHash{}
+			break
+		}
+	}
 	s.global.ir = &shaderir.Block{}
 	s.parse(f)
 
@@ -250,6 +410,55 @@ func ParseCompilerDirectives(src []byte) (shaderir.Unit, error) {
 	return unit, nil
 }
 
+// ParseComputeDirective parses the //kage:compute directive, and returns the workgroup size.
+//
+// The directive is like '//kage:compute 8 8 1' and specifies the workgroup size for each dimension.
+// The omitted sizes are 1.
+// ParseComputeDirective reports false when the directive doesn't exist.
+func ParseComputeDirective(src []byte) (workgroupSize [3]int, ok bool, err error) {
+	reCompute := regexp.MustCompile(`^[ \t\r\n]*//kage:compute((?:[ \t]+[^ \t\r\n]+)*)[ \t\r\n]*$`)
+
+	buf := bytes.NewBuffer(src)
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		m := reCompute.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		if ok {
+			return [3]int{}, false, fmt.Errorf("shader: at most one //kage:compute can exist in a shader")
+		}
+		sizes := strings.Fields(m[1])
+		if len(sizes) > 3 {
+			return [3]int{}, false, fmt.Errorf("shader: too many values for //kage:compute: %s", strings.TrimSpace(m[1]))
+		}
+		workgroupSize = [3]int{1, 1, 1}
+		for i, str := range sizes {
+			n, err := strconv.Atoi(str)
+			if err != nil || n <= 0 {
+				return [3]int{}, false, fmt.Errorf("shader: invalid value for //kage:compute: %s", str)
+			}
+			workgroupSize[i] = n
+		}
+		ok = true
+	}
+
+	return workgroupSize, ok, nil
+}
+
+// HasSharedDirective reports whether the comments have the //kage:shared directive.
+func HasSharedDirective(comments *ast.CommentGroup) bool {
+	if comments == nil {
+		return false
+	}
+	for _, c := range comments.List {
+		if strings.TrimSpace(c.Text) == "//kage:shared" {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *compileState) addError(pos token.Pos, str string) {
 	p := s.fs.Position(pos)
 	s.errs = append(s.errs, fmt.Sprintf("%s: %s", p, str))
@@ -287,6 +496,11 @@ func (cs *compileState) parse(f *ast.File) {
 	}
 	cs.ir.UniformNames = unames
 	cs.ir.Uniforms = utypes
+	for i, u := range cs.ir.UniformNames {
+		if _, ok := cs.runtimeSizedUniformLength(u); ok {
+			cs.ir.RuntimeSizedUniforms = append(cs.ir.RuntimeSizedUniforms, i)
+		}
+	}
 
 	// Parse function names so that any other function call the others.
 	// The function data is provisional and will be updated soon.
@@ -322,6 +536,21 @@ func (cs *compileState) parse(f *ast.File) {
 			fragmentReturnType = ret
 			continue
 		}
+		if n == cs.computeEntry {
+			// The compute entry point is treated as a regular function, and ComputeFunc calls this.
+			if len(inParams) > 3 {
+				cs.addError(d.Pos(), "compute entry point must take at most three ivec3 values")
+			}
+			for _, p := range inParams {
+				if p.typ.Main != shaderir.IVec3 {
+					cs.addError(d.Pos(), "compute entry point must take at most three ivec3 values")
+					break
+				}
+			}
+			if len(outParams) > 0 || ret.Main != shaderir.None {
+				cs.addError(d.Pos(), "compute entry point must not return any values")
+			}
+		}
 
 		var inT, outT []shaderir.Type
 		for _, v := range inParams {
@@ -360,8 +589,23 @@ func (cs *compileState) parse(f *ast.File) {
 		if vertexOutParams[0].typ.Main != shaderir.Vec4 {
 			cs.addError(0, "vertex entry point must have at least one returning vec4 value for a position")
 		}
-		if len(fragmentOutParams) != 0 || fragmentReturnType.Main != shaderir.Vec4 {
-			cs.addError(0, "fragment entry point must have one returning vec4 value for a color")
+		switch {
+		case cs.fragmentOutputStruct != nil:
+			// The result struct is already checked at parseFragmentOutputStruct.
+		case len(fragmentOutParams) == 0:
+			if fragmentReturnType.Main != shaderir.Vec4 {
+				cs.addError(0, "fragment entry point must have one returning vec4 value for a color")
+			}
+		case len(fragmentOutParams) == 2:
+			// The second color is for dual-source blending.
+			for _, p := range fragmentOutParams {
+				if p.typ.Main != shaderir.Vec4 {
+					cs.addError(0, "fragment entry point must have one returning vec4 value for a color, or two returning vec4 values for dual-source blending")
+					break
+				}
+			}
+		default:
+			cs.addError(0, "fragment entry point must have one returning vec4 value for a color, or two returning vec4 values for dual-source blending")
 		}
 	}
 
@@ -399,6 +643,457 @@ func (cs *compileState) parse(f *ast.File) {
 	for _, f := range cs.funcs {
 		cs.ir.Funcs = append(cs.ir.Funcs, f.ir)
 	}
+
+	if cs.computeEntry != "" {
+		if i, ok := cs.findFunction(cs.computeEntry); ok {
+			// For parameters of a compute func, see the comment in internal/shaderir/program.go.
+			call := []shaderir.Expr{
+				{
+					Type:  shaderir.FunctionExpr,
+					Index: i,
+				},
+			}
+			for j := range cs.funcs[i].ir.InParams {
+				call = append(call, shaderir.Expr{
+					Type:  shaderir.LocalVariable,
+					Index: j,
+				})
+			}
+			cs.ir.ComputeFunc.Block = &shaderir.Block{
+				LocalVarIndexOffset: 3,
+				Stmts: []shaderir.Stmt{
+					{
+						Type: shaderir.ExprStmt,
+						Exprs: []shaderir.Expr{
+							{
+								Type:  shaderir.Call,
+								Exprs: call,
+							},
+						},
+					},
+				},
+			}
+		}
+	}
+}
+
+func (cs *compileState) addSharedVariables(s *ast.ValueSpec, vs []variable, inits []shaderir.Expr) bool {
+	if cs.computeEntry == "" {
+		cs.addError(s.Pos(), "a shared variable is available only in a compute kernel")
+		return false
+	}
+	if len(inits) > 0 {
+		cs.addError(s.Pos(), "a shared variable cannot have initial values")
+		return false
+	}
+	for _, v := range vs {
+		_, uniform := cs.findUniformVariable(v.name)
+		_, shared := cs.findSharedVariable(v.name)
+		if uniform || shared {
+			cs.addError(s.Pos(), fmt.Sprintf("%s redeclared in this block", v.name))
+			return false
+		}
+		cs.sharedVarNames = append(cs.sharedVarNames, v.name)
+		cs.ir.SharedVars = append(cs.ir.SharedVars, v.typ)
+	}
+	return true
+}
+
+// addConstArrays adds read-only global arrays initialized with constant values like lookup tables.
+// The values are embedded in the shader, and are not uploaded as uniform variables.
+func (cs *compileState) addConstArrays(b *block, s *ast.ValueSpec) bool {
+	if len(s.Names) != len(s.Values) {
+		cs.addError(s.Pos(), fmt.Sprintf("assignment mismatch: %d variables but %d values", len(s.Names), len(s.Values)))
+		return false
+	}
+
+	var declType shaderir.Type
+	if s.Type != nil {
+		t, ok := cs.parseType(b, "", s.Type)
+		if !ok {
+			return false
+		}
+		declType = t
+	}
+
+	for i, n := range s.Names {
+		name := n.Name
+		if _, ok := b.findConstant(name); ok || cs.isUniformOrSharedVariableDeclared(name) {
+			cs.addError(n.Pos(), fmt.Sprintf("%s redeclared in this block", name))
+			return false
+		}
+
+		lit, ok := s.Values[i].(*ast.CompositeLit)
+		if !ok || lit.Type == nil {
+			cs.addError(s.Values[i].Pos(), fmt.Sprintf("a global variable must be initialized with an array literal: %s", name))
+			return false
+		}
+		t, ok := cs.parseType(b, "", lit.Type)
+		if !ok {
+			return false
+		}
+		if t.Main != shaderir.Array {
+			cs.addError(lit.Pos(), fmt.Sprintf("a global variable must be initialized with an array literal: %s", name))
+			return false
+		}
+		if t.Length == -1 {
+			t.Length = len(lit.Elts)
+		}
+		if s.Type != nil && !declType.Equal(&t) {
+			cs.addError(lit.Pos(), fmt.Sprintf("cannot use %s as %s value in variable declaration", t.String(), declType.String()))
+			return false
+		}
+		if len(lit.Elts) > t.Length {
+			cs.addError(lit.Pos(), fmt.Sprintf("array index %d out of bounds [0:%d]", t.Length, t.Length))
+			return false
+		}
+
+		elm := t.Sub[0]
+		var n int
+		switch elm.Main {
+		case shaderir.Int, shaderir.Float:
+			n = 1
+		case shaderir.Vec2, shaderir.Vec3, shaderir.Vec4, shaderir.IVec2, shaderir.IVec3, shaderir.IVec4:
+			n = elm.VectorElementCount()
+		default:
+			cs.addError(lit.Pos(), fmt.Sprintf("an element of a global array must be an int, a float, or a vector but %s", elm.String()))
+			return false
+		}
+
+		values := make([]gconstant.Value, 0, t.Length*n)
+		for _, e := range lit.Elts {
+			vs, ok := cs.parseConstArrayElement(b, e, elm)
+			if !ok {
+				return false
+			}
+			values = append(values, vs...)
+		}
+		// The rest of the elements are zero values.
+		zero := gconstant.MakeFloat64(0)
+		if elm.Main == shaderir.Int || elm.IsIntVector() {
+			zero = gconstant.MakeInt64(0)
+		}
+		for len(values) < t.Length*n {
+			values = append(values, zero)
+		}
+
+		cs.constArrayNames = append(cs.constArrayNames, name)
+		cs.ir.ConstArrays = append(cs.ir.ConstArrays, shaderir.ConstArray{
+			Type:   t,
+			Values: values,
+		})
+	}
+	return true
+}
+
+// parseConstArrayElement parses an element of a global array literal, and returns the constant values of the components.
+// A vector element must be a constructor call with constant arguments like vec2(1, 2).
+func (cs *compileState) parseConstArrayElement(b *block, e ast.Expr, elm shaderir.Type) ([]gconstant.Value, bool) {
+	exprs, _, _, ok := cs.parseExpr(b, "", e, true)
+	if !ok {
+		return nil, false
+	}
+	if len(exprs) != 1 {
+		cs.addError(e.Pos(), "multiple-value context is not available at a composite literal")
+		return nil, false
+	}
+
+	var consts []gconstant.Value
+	if elm.Main == shaderir.Int || elm.Main == shaderir.Float {
+		consts = []gconstant.Value{exprs[0].Const}
+	} else {
+		expr := exprs[0]
+		if expr.Type != shaderir.Call || expr.Exprs[0].Type != shaderir.BuiltinFuncExpr || string(expr.Exprs[0].BuiltinFunc) != elm.String() {
+			cs.addError(e.Pos(), fmt.Sprintf("an element of a global array must be a constant or a %s constructor with constants", elm.String()))
+			return nil, false
+		}
+		args := expr.Exprs[1:]
+		n := elm.VectorElementCount()
+		switch len(args) {
+		case 1:
+			for i := 0; i < n; i++ {
+				consts = append(consts, args[0].Const)
+			}
+		case n:
+			for _, a := range args {
+				consts = append(consts, a.Const)
+			}
+		default:
+			cs.addError(e.Pos(), fmt.Sprintf("an element of a global array must be a constant or a %s constructor with constants", elm.String()))
+			return nil, false
+		}
+	}
+
+	for i, c := range consts {
+		if c == nil {
+			cs.addError(e.Pos(), "an element of a global array must be a constant")
+			return nil, false
+		}
+		if elm.Main == shaderir.Int || elm.IsIntVector() {
+			if !canTruncateToInteger(c) {
+				cs.addError(e.Pos(), fmt.Sprintf("constant %s truncated to integer", c.String()))
+				return nil, false
+			}
+			consts[i] = gconstant.ToInt(c)
+			continue
+		}
+		if !canTruncateToFloat(c) {
+			cs.addError(e.Pos(), fmt.Sprintf("constant %s truncated to float", c.String()))
+			return nil, false
+		}
+		consts[i] = gconstant.ToFloat(c)
+	}
+	return consts, true
+}
+
+// addRuntimeSizedUniforms adds uniform variables of arrays without lengths.
+// The lengths are given at the compilation.
+func (cs *compileState) addRuntimeSizedUniforms(b *block, s *ast.ValueSpec, t *ast.ArrayType) bool {
+	if len(s.Values) > 0 {
+		cs.addError(s.Pos(), "a uniform variable cannot have initial values")
+		return false
+	}
+
+	elm, ok := cs.parseType(b, "", t.Elt)
+	if !ok {
+		return false
+	}
+	if elm.Main == shaderir.Array {
+		cs.addError(t.Pos(), "an element of a runtime-sized array cannot be an array")
+		return false
+	}
+
+	for _, n := range s.Names {
+		name := n.Name
+		if name[0] < 'A' || 'Z' < name[0] {
+			cs.addError(n.Pos(), fmt.Sprintf("global variables must be exposed: %s", name))
+			return false
+		}
+		if cs.isUniformOrSharedVariableDeclared(name) {
+			cs.addError(s.Pos(), fmt.Sprintf("%s redeclared in this block", name))
+			return false
+		}
+
+		l := cs.arrayLengths[name]
+		if l < 0 {
+			cs.addError(s.Pos(), fmt.Sprintf("length of %s must not be negative: %d", name, l))
+			return false
+		}
+		// An array with no elements is not allowed in the shading languages.
+		if l == 0 {
+			l = 1
+		}
+		cs.ir.UniformNames = append(cs.ir.UniformNames, name)
+		cs.ir.Uniforms = append(cs.ir.Uniforms, shaderir.Type{
+			Main:   shaderir.Array,
+			Length: l,
+			Sub:    []shaderir.Type{elm},
+		})
+		cs.runtimeSizedUniformNames = append(cs.runtimeSizedUniformNames, name)
+	}
+	return true
+}
+
+func (cs *compileState) isUniformOrSharedVariableDeclared(name string) bool {
+	if _, ok := cs.findUniformVariable(name); ok {
+		return true
+	}
+	if _, ok := cs.findSharedVariable(name); ok {
+		return true
+	}
+	if _, ok := cs.findConstArray(name); ok {
+		return true
+	}
+	return cs.isStructUniformVariable(name)
+}
+
+// addStructType adds a struct type declared at the global scope.
+func (cs *compileState) addStructType(b *block, s *ast.TypeSpec, t *ast.StructType) bool {
+	if b != &cs.global {
+		cs.addError(s.Pos(), "a struct type can be declared only at the global scope")
+		return false
+	}
+
+	name := s.Name.Name
+	if _, ok := cs.findStructType(name); ok {
+		cs.addError(s.Pos(), fmt.Sprintf("%s redeclared in this block", name))
+		return false
+	}
+	for _, t := range b.types {
+		if t.name == name {
+			cs.addError(s.Pos(), fmt.Sprintf("%s redeclared in this block", name))
+			return false
+		}
+	}
+
+	st := &structType{
+		name: name,
+	}
+	for _, f := range t.Fields.List {
+		if len(f.Names) == 0 {
+			cs.addError(f.Pos(), "an embedded field is not implemented")
+			return false
+		}
+
+		var field structField
+		if id, ok := f.Type.(*ast.Ident); ok {
+			if fst, ok := cs.findStructType(id.Name); ok {
+				field.st = fst
+			}
+		}
+		if field.st == nil {
+			t, ok := cs.parseType(b, "", f.Type)
+			if !ok {
+				return false
+			}
+			field.typ = t
+		}
+
+		for _, n := range f.Names {
+			for _, f := range st.fields {
+				if f.name == n.Name {
+					cs.addError(n.Pos(), fmt.Sprintf("duplicate field %s", n.Name))
+					return false
+				}
+			}
+			field.name = n.Name
+			st.fields = append(st.fields, field)
+		}
+	}
+	cs.structs = append(cs.structs, st)
+	return true
+}
+
+// structUniformType returns the struct type if the given type is a struct or an array of a struct.
+func (cs *compileState) structUniformType(expr ast.Expr) (*structType, *ast.ArrayType, bool) {
+	arr, _ := expr.(*ast.ArrayType)
+	if arr != nil {
+		expr = arr.Elt
+	}
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil, nil, false
+	}
+	st, ok := cs.findStructType(id.Name)
+	if !ok {
+		return nil, nil, false
+	}
+	return st, arr, true
+}
+
+// addStructUniforms adds uniform variables of a struct or an array of a struct.
+// A struct is flattened into uniform variables for its members like "Mat.Color".
+// An array of a struct is flattened into arrays for its members.
+// An array without a length is a runtime-sized array.
+func (cs *compileState) addStructUniforms(b *block, s *ast.ValueSpec, st *structType, arr *ast.ArrayType) bool {
+	if len(s.Values) > 0 {
+		cs.addError(s.Pos(), "a uniform variable cannot have initial values")
+		return false
+	}
+
+	var length int
+	if arr != nil && arr.Len != nil {
+		if _, ok := arr.Len.(*ast.Ellipsis); ok {
+			cs.addError(arr.Pos(), "array length must be specified")
+			return false
+		}
+		exprs, _, _, ok := cs.parseExpr(b, "", arr.Len, true)
+		if !ok {
+			return false
+		}
+		if len(exprs) != 1 || exprs[0].Type != shaderir.NumberExpr {
+			cs.addError(arr.Pos(), "length of array must be a constant number")
+			return false
+		}
+		l, ok := gconstant.Int64Val(exprs[0].Const)
+		if !ok || l <= 0 {
+			cs.addError(arr.Pos(), "length of array must be a positive integer")
+			return false
+		}
+		length = int(l)
+	}
+
+	for _, n := range s.Names {
+		name := n.Name
+		if name[0] < 'A' || 'Z' < name[0] {
+			cs.addError(n.Pos(), fmt.Sprintf("global variables must be exposed: %s", name))
+			return false
+		}
+		if cs.isUniformOrSharedVariableDeclared(name) {
+			cs.addError(s.Pos(), fmt.Sprintf("%s redeclared in this block", name))
+			return false
+		}
+
+		if arr == nil {
+			if cs.structPaths == nil {
+				cs.structPaths = map[string]struct{}{}
+			}
+			cs.structPaths[name] = struct{}{}
+			if !cs.flattenStructUniform(n.Pos(), name, st, 0, false) {
+				return false
+			}
+			continue
+		}
+
+		l := length
+		runtimeSized := arr.Len == nil
+		if runtimeSized {
+			l = cs.arrayLengths[name]
+			if l < 0 {
+				cs.addError(s.Pos(), fmt.Sprintf("length of %s must not be negative: %d", name, l))
+				return false
+			}
+		}
+		if cs.structArrayLengths == nil {
+			cs.structArrayLengths = map[string]int{}
+		}
+		cs.structArrayLengths[name] = l
+		// An array with no elements is not allowed in the shading languages.
+		if l == 0 {
+			l = 1
+		}
+		if !cs.flattenStructUniform(n.Pos(), name, st, l, runtimeSized) {
+			return false
+		}
+	}
+	return true
+}
+
+// flattenStructUniform adds uniform variables for the members of a struct uniform variable.
+// If length is positive, the members are arrays with the length.
+func (cs *compileState) flattenStructUniform(pos token.Pos, prefix string, st *structType, length int, runtimeSized bool) bool {
+	for _, f := range st.fields {
+		name := prefix + "." + f.name
+		if f.st != nil {
+			if cs.structPaths == nil {
+				cs.structPaths = map[string]struct{}{}
+			}
+			cs.structPaths[name] = struct{}{}
+			if !cs.flattenStructUniform(pos, name, f.st, length, runtimeSized) {
+				return false
+			}
+			continue
+		}
+
+		t := f.typ
+		if length > 0 {
+			if t.Main == shaderir.Array {
+				cs.addError(pos, fmt.Sprintf("array of array is forbidden: %s", name))
+				return false
+			}
+			t = shaderir.Type{
+				Main:   shaderir.Array,
+				Length: length,
+				Sub:    []shaderir.Type{t},
+			}
+		}
+		cs.ir.UniformNames = append(cs.ir.UniformNames, name)
+		cs.ir.Uniforms = append(cs.ir.Uniforms, t)
+		if runtimeSized {
+			cs.runtimeSizedUniformNames = append(cs.runtimeSizedUniformNames, name)
+		}
+	}
+	return true
 }
 
 func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderir.Stmt, bool) {
@@ -411,6 +1106,13 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 			// TODO: Parse other types
 			for _, s := range d.Specs {
 				s := s.(*ast.TypeSpec)
+				if st, ok := s.Type.(*ast.StructType); ok {
+					if !cs.addStructType(b, s, st) {
+						return nil, false
+					}
+					continue
+				}
+
 				t, ok := cs.parseType(b, fname, s.Type)
 				if !ok {
 					return nil, false
@@ -430,6 +1132,15 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 		case token.CONST:
 			for _, s := range d.Specs {
 				s := s.(*ast.ValueSpec)
+				// A global constant of an array is treated in the same way as a global variable with initial values.
+				if b == &cs.global && len(s.Values) > 0 {
+					if _, ok := s.Values[0].(*ast.CompositeLit); ok {
+						if !cs.addConstArrays(b, s) {
+							return nil, false
+						}
+						continue
+					}
+				}
 				cs, ok := cs.parseConstant(b, fname, s)
 				if !ok {
 					return nil, false
@@ -439,6 +1150,33 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 		case token.VAR:
 			for _, s := range d.Specs {
 				s := s.(*ast.ValueSpec)
+				if b == &cs.global {
+					// A global variable with initial values is a read-only array like a lookup table.
+					// A shared variable cannot have initial values, and this is checked later.
+					if len(s.Values) > 0 && !((d.Lparen == token.NoPos && HasSharedDirective(d.Doc)) || HasSharedDirective(s.Doc)) {
+						if !cs.addConstArrays(b, s) {
+							return nil, false
+						}
+						continue
+					}
+					if st, arr, ok := cs.structUniformType(s.Type); ok {
+						if (d.Lparen == token.NoPos && HasSharedDirective(d.Doc)) || HasSharedDirective(s.Doc) {
+							cs.addError(s.Pos(), "a shared variable cannot be a struct")
+							return nil, false
+						}
+						if !cs.addStructUniforms(b, s, st, arr) {
+							return nil, false
+						}
+						continue
+					}
+				}
+				if t, ok := s.Type.(*ast.ArrayType); ok && t.Len == nil && b == &cs.global {
+					if !cs.addRuntimeSizedUniforms(b, s, t) {
+						return nil, false
+					}
+					continue
+				}
+
 				vs, inits, ss, ok := cs.parseVariable(b, fname, s)
 				if !ok {
 					return nil, false
@@ -446,9 +1184,13 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 
 				stmts = append(stmts, ss...)
 				if b == &cs.global {
-					if len(inits) > 0 {
-						cs.addError(s.Pos(), "a uniform variable cannot have initial values")
-						return nil, false
+					// A variable with the //kage:shared directive is shared in a workgroup of a compute kernel.
+					// The directive can be put for either the declaration or the spec.
+					if (d.Lparen == token.NoPos && HasSharedDirective(d.Doc)) || HasSharedDirective(s.Doc) {
+						if !cs.addSharedVariables(s, vs, inits) {
+							return nil, false
+						}
+						continue
 					}
 
 					// TODO: Should rhs be ignored?
@@ -464,6 +1206,10 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 								return nil, false
 							}
 						}
+						if _, ok := cs.findSharedVariable(v.name); ok || cs.isStructUniformVariable(v.name) {
+							cs.addError(s.Pos(), fmt.Sprintf("%s redeclared in this block", v.name))
+							return nil, false
+						}
 						cs.ir.UniformNames = append(cs.ir.UniformNames, v.name)
 						cs.ir.Uniforms = append(cs.ir.Uniforms, v.typ)
 					}
@@ -510,6 +1256,24 @@ func (cs *compileState) parseDecl(b *block, fname string, d ast.Decl) ([]shaderi
 			cs.ir.VertexFunc.Block = f.ir.Block
 		case cs.fragmentEntry:
 			cs.ir.FragmentFunc.Block = f.ir.Block
+			cs.ir.FragmentFunc.OutputCount = 1
+			cs.ir.FragmentFunc.MultipleRenderTargets = cs.fragmentOutputStruct != nil
+			if n := len(f.ir.OutParams); n > 0 {
+				// The fragment entry point returns multiple colors.
+				// Treat the out-params as local variables of the entry point, and return them at the return statements.
+				b := f.ir.Block
+				b.LocalVarIndexOffset -= n
+				b.LocalVars = append(append([]shaderir.Type{}, f.ir.OutParams...), b.LocalVars...)
+				var exprs []shaderir.Expr
+				for i := 0; i < n; i++ {
+					exprs = append(exprs, shaderir.Expr{
+						Type:  shaderir.LocalVariable,
+						Index: len(f.ir.InParams) + i,
+					})
+				}
+				setReturnExprs(b, exprs)
+				cs.ir.FragmentFunc.OutputCount = n
+			}
 		default:
 			// The function is already registered for their names.
 			for i := range cs.funcs {
@@ -769,6 +1533,15 @@ func (cs *compileState) parseFuncParams(block *block, fname string, d *ast.FuncD
 		return
 	}
 
+	if fname == cs.fragmentEntry && len(d.Type.Results.List) == 1 {
+		if id, ok := d.Type.Results.List[0].Type.(*ast.Ident); ok {
+			if st, ok := cs.findStructType(id.Name); ok {
+				out, ret = cs.parseFragmentOutputStruct(d.Type.Results.List[0], st)
+				return
+			}
+		}
+	}
+
 	for _, f := range d.Type.Results.List {
 		t, ok := cs.parseType(block, fname, f.Type)
 		if !ok {
@@ -805,6 +1578,40 @@ func (cs *compileState) parseFuncParams(block *block, fname string, d *ast.FuncD
 	return
 }
 
+// parseFragmentOutputStruct parses the result of the fragment entry point returning a struct for multiple render targets.
+// The members of the struct are treated as returning values in the member order.
+func (cs *compileState) parseFragmentOutputStruct(f *ast.Field, st *structType) (out []variable, ret shaderir.Type) {
+	if len(f.Names) > 0 {
+		cs.addError(f.Pos(), fmt.Sprintf("a named result of a struct type is not implemented: %s", st.name))
+		return
+	}
+	if len(st.fields) == 0 {
+		cs.addError(f.Pos(), fmt.Sprintf("%s must have at least one member to be the result of %s", st.name, cs.fragmentEntry))
+		return
+	}
+	if len(st.fields) > MaxRenderTargetCount {
+		cs.addError(f.Pos(), fmt.Sprintf("%s must have at most %d members to be the result of %s", st.name, MaxRenderTargetCount, cs.fragmentEntry))
+		return
+	}
+	for _, field := range st.fields {
+		if field.st != nil || field.typ.Main != shaderir.Vec4 {
+			cs.addError(f.Pos(), fmt.Sprintf("all the members of %s must be vec4 to be the result of %s: %s", st.name, cs.fragmentEntry, field.name))
+			return
+		}
+	}
+	cs.fragmentOutputStruct = st
+
+	if len(st.fields) == 1 {
+		return nil, shaderir.Type{Main: shaderir.Vec4}
+	}
+	for range st.fields {
+		out = append(out, variable{
+			typ: shaderir.Type{Main: shaderir.Vec4},
+		})
+	}
+	return out, shaderir.Type{}
+}
+
 func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool) {
 	if d.Name == nil {
 		cs.addError(d.Pos(), "function must have a name")
@@ -885,6 +1692,19 @@ func (cs *compileState) parseFunc(block *block, d *ast.FuncDecl) (function, bool
 	}, true
 }
 
+// setReturnExprs sets the given expressions to all the return statements in the block recursively.
+func setReturnExprs(block *shaderir.Block, exprs []shaderir.Expr) {
+	for i := range block.Stmts {
+		s := &block.Stmts[i]
+		if s.Type == shaderir.Return {
+			s.Exprs = exprs
+		}
+		for _, b := range s.Blocks {
+			setReturnExprs(b, exprs)
+		}
+	}
+}
+
 func (cs *compileState) parseBlock(outer *block, fname string, stmts []ast.Stmt, inParams, outParams []variable, returnType shaderir.Type, checkLocalVariableUsage bool) (*block, bool) {
 	var vars []variable
 	if outer == &cs.global {
@@ -199,3 +199,356 @@ func TestCompile(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileCompute(t *testing.T) {
+	p, err := shader.CompileCompute([]byte(`//kage:compute 8 8
+
+package main
+
+var Scale float
+
+//kage:shared
+var tile [64]vec4
+
+func Compute(id ivec3, localID ivec3) {
+	i := localID.y*8 + localID.x
+	tile[i] = imageLoad(0, id.xy)
+	barrier()
+	imageStore(1, id.xy, tile[63-i]*Scale)
+}
+`), "Compute")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := p.StorageImageCount, 2; got != want {
+		t.Errorf("StorageImageCount: got: %d, want: %d", got, want)
+	}
+	if got, want := len(p.SharedVars), 1; got != want {
+		t.Errorf("len(SharedVars): got: %d, want: %d", got, want)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		src   string
+		lines []string
+	}{
+		{
+			name: "GLSL",
+			src:  glsl.CompileCompute(p, glsl.GLSLVersionDefault),
+			lines: []string{
+				"layout(local_size_x = 8, local_size_y = 8, local_size_z = 1) in;",
+				"layout(rgba8, binding = 0) uniform readonly image2D I0;",
+				"layout(rgba8, binding = 1) uniform writeonly image2D I1;",
+				"shared vec4 W0[64];",
+				"\tF0(ivec3(gl_GlobalInvocationID), ivec3(gl_LocalInvocationID));",
+			},
+		},
+		{
+			name: "HLSL",
+			src:  hlsl.CompileCompute(p),
+			lines: []string{
+				"Texture2D<float4> I0 : register(t0);",
+				"RWTexture2D<unorm float4> I1 : register(u1);",
+				"groupshared float4 W0[64];",
+				"[numthreads(8, 8, 1)]",
+				"\tGroupMemoryBarrierWithGroupSync();",
+			},
+		},
+		{
+			name: "Metal",
+			src:  msl.CompileCompute(p),
+			lines: []string{
+				"kernel void Compute(",
+				"\ttexture2d<float, access::read> I0 [[texture(0)]],",
+				"\ttexture2d<float, access::write> I1 [[texture(1)]]) {",
+				"\tthreadgroup array<float4, 64> W0;",
+				"\tthreadgroup_barrier(mem_flags::mem_threadgroup);",
+			},
+		},
+	} {
+		lines := strings.Split(tc.src, "\n")
+		for _, want := range tc.lines {
+			var found bool
+			for _, l := range lines {
+				if l == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("%s: %q is not found in the output:\n%s", tc.name, want, tc.src)
+			}
+		}
+	}
+}
+
+func TestCompileDualSource(t *testing.T) {
+	p, err := shader.Compile([]byte(`package main
+
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, color
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) (vec4, vec4) {
+	if color.a == 0 {
+		discard()
+	}
+	return color, vec4(color.a)
+}
+`), "Vertex", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, glslFS := glsl.Compile(p, glsl.GLSLVersionDefault)
+	_, hlslPS, _ := hlsl.Compile(p)
+
+	for _, tc := range []struct {
+		name  string
+		src   string
+		lines []string
+	}{
+		{
+			name: "GLSL",
+			src:  glslFS,
+			lines: []string{
+				"out vec4 fragColor1;",
+			},
+		},
+		{
+			name: "HLSL",
+			src:  hlslPS,
+			lines: []string{
+				"\tfloat4 C0 : SV_Target0;",
+				"\tfloat4 C1 : SV_Target1;",
+				"PSOutput PSMain(Varyings varyings) {",
+				"\t\treturn (PSOutput)0;",
+				"\treturn psOut;",
+			},
+		},
+		{
+			name: "Metal",
+			src:  msl.Compile(p),
+			lines: []string{
+				"\tfloat4 C0 [[color(0), index(0)]];",
+				"\tfloat4 C1 [[color(0), index(1)]];",
+				"fragment FragmentOut Fragment(",
+				"\t\treturn FragmentOut{};",
+			},
+		},
+	} {
+		lines := strings.Split(tc.src, "\n")
+		for _, want := range tc.lines {
+			var found bool
+			for _, l := range lines {
+				if l == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("%s: %q is not found in the output:\n%s", tc.name, want, tc.src)
+			}
+		}
+	}
+}
+
+func TestExpandImports(t *testing.T) {
+	modules := map[string]string{
+		"lib/color": `package color
+
+const Gray = 0.5
+
+func Luminance(c vec3) float {
+	return dot(c, vec3(0.299, 0.587, 0.114))
+}
+`,
+		"lib/noise": `package noise
+
+//kage:import "lib/color"
+
+func Noise(p vec2) float {
+	return fract(sin(dot(p, vec2(12.9898, 78.233))) * 43758.5453) * Gray
+}
+`,
+		"lib/error": `package error
+
+func Broken() float {
+	return undefined
+}
+`,
+		"lib/cycle1": `package cycle1
+
+//kage:import "lib/cycle2"
+`,
+		"lib/cycle2": `package cycle2
+
+//kage:import "lib/cycle1"
+`,
+		"lib/unit": `//kage:unit pixels
+
+package unit
+`,
+	}
+	resolve := func(path string) ([]byte, error) {
+		m, ok := modules[path]
+		if !ok {
+			return nil, fmt.Errorf("not found: %s", path)
+		}
+		return []byte(m), nil
+	}
+
+	src := []byte(`//kage:unit pixels
+
+package main
+
+//kage:import "lib/noise"
+//kage:import "lib/color"
+
+func Foo(c vec4) float {
+	return Noise(c.xy) + Luminance(c.rgb) + Gray
+}
+`)
+	expanded, err := shader.ExpandImports(src, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Count(string(expanded), "func Luminance"), 1; got != want {
+		t.Errorf("strings.Count(expanded, \"func Luminance\"): got: %d, want: %d", got, want)
+	}
+	if _, err := shader.Compile(expanded, "", "", 0); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := shader.Compile(src, "", "", 0); err == nil {
+		t.Errorf("Compile with unexpanded imports must return an error but not")
+	}
+	if _, err := shader.ExpandImports(src, nil); err == nil {
+		t.Errorf("ExpandImports without a resolver must return an error but not")
+	}
+
+	// An error in a module must be reported with the module path and the line in the module.
+	expanded, err = shader.ExpandImports([]byte(`package main
+
+//kage:import "lib/error"
+
+func Foo() float {
+	return Broken()
+}
+`), resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shader.Compile(expanded, "", "", 0); err == nil {
+		t.Errorf("Compile must return an error but not")
+	} else if got, want := err.Error(), "lib/error:4:"; !strings.HasPrefix(got, want) {
+		t.Errorf("err.Error(): got: %s, want: prefix %s", got, want)
+	}
+
+	// An error in the main source must be reported with the original line.
+	expanded, err = shader.ExpandImports([]byte(`package main
+
+//kage:import "lib/color"
+
+func Foo() float {
+	return undefined
+}
+`), resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shader.Compile(expanded, "", "", 0); err == nil {
+		t.Errorf("Compile must return an error but not")
+	} else if got, want := err.Error(), "6:"; !strings.HasPrefix(got, want) {
+		t.Errorf("err.Error(): got: %s, want: prefix %s", got, want)
+	}
+
+	for _, path := range []string{"lib/cycle1", "lib/unit", "lib/missing"} {
+		if _, err := shader.ExpandImports([]byte(fmt.Sprintf("package main\n\n//kage:import %q\n", path)), resolve); err == nil {
+			t.Errorf("ExpandImports with %s must return an error but not", path)
+		}
+	}
+}
+
+func TestDecodeDebugValue(t *testing.T) {
+	cases := []struct {
+		pixel [4]byte
+		index int
+		value float64
+		ok    bool
+	}{
+		{pixel: [4]byte{0, 0, 0, 0}, ok: false},
+		{pixel: [4]byte{1, 0, 0, 0}, index: 0, value: 0, ok: true},
+		{pixel: [4]byte{1, 64, 0, 0}, index: 0, value: 1, ok: true},
+		{pixel: [4]byte{2, 64 + 1 + 128, 0xc0, 0}, index: 1, value: -3.5, ok: true},
+		{pixel: [4]byte{3, 64 - 2, 0x80, 0}, index: 2, value: 0.375, ok: true},
+	}
+	for _, c := range cases {
+		index, value, ok := shader.DecodeDebugValue(c.pixel)
+		if ok != c.ok {
+			t.Errorf("DecodeDebugValue(%v): ok: got: %t, want: %t", c.pixel, ok, c.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if index != c.index || value != c.value {
+			t.Errorf("DecodeDebugValue(%v): got: (%d, %v), want: (%d, %v)", c.pixel, index, value, c.index, c.value)
+		}
+	}
+}
+
+func TestCompileForDebug(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	if srcPos.x > 0 {
+		Debugf("x: %v", srcPos.x)
+	}
+	Debugf("pos: %v, i: %d", srcPos, int(color.r))
+	return color
+}
+`)
+
+	p, err := shader.Compile(src, "", "Fragment", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(p.DebugCalls), 2; got != want {
+		t.Fatalf("len(p.DebugCalls): got: %d, want: %d", got, want)
+	}
+	if got, want := p.DebugCalls[1].Format, "pos: %v, i: %d"; got != want {
+		t.Errorf("p.DebugCalls[1].Format: got: %q, want: %q", got, want)
+	}
+	if got, want := len(p.DebugCalls[1].ArgTypes), 2; got != want {
+		t.Errorf("len(p.DebugCalls[1].ArgTypes): got: %d, want: %d", got, want)
+	}
+	funcCount := len(p.Funcs)
+
+	for c := 0; c < shader.DebugMaxComponentCount; c++ {
+		p, err := shader.CompileForDebug(src, "", "Fragment", 0, nil, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(p.DebugCalls), 2; got != want {
+			t.Errorf("len(p.DebugCalls): got: %d, want: %d", got, want)
+		}
+		if p.SourceHash != (shaderir.SourceHash{}) {
+			t.Errorf("p.SourceHash must be zero")
+		}
+		// The encoding function is added.
+		if got, want := len(p.Funcs), funcCount+1; got != want {
+			t.Errorf("len(p.Funcs): got: %d, want: %d", got, want)
+		}
+		if _, fs := glsl.Compile(p, glsl.GLSLVersionDefault); !strings.Contains(fs, "return F0(2.0, ") {
+			t.Errorf("the second Debugf call must be converted to a return statement:\n%s", fs)
+		}
+	}
+
+	if _, err := shader.CompileForDebug(src, "", "Fragment", 0, nil, shader.DebugMaxComponentCount); err == nil {
+		t.Errorf("CompileForDebug with an invalid component must return an error")
+	}
+}
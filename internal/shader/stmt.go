@@ -77,6 +77,10 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				cs.addError(stmt.Pos(), "a uniform variable cannot be assigned")
 				return nil, false
 			}
+			if isConstArrayExpr(&lhs[0]) {
+				cs.addError(stmt.Pos(), "a constant array cannot be assigned")
+				return nil, false
+			}
 
 			var op shaderir.Op
 			switch stmt.Tok {
@@ -100,6 +104,9 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				op = shaderir.Or
 			case token.XOR_ASSIGN:
 				op = shaderir.Xor
+			case token.AND_NOT_ASSIGN:
+				// x &^= y is treated as x &= ^y.
+				op = shaderir.And
 			case token.SHL_ASSIGN:
 				op = shaderir.LeftShift
 			case token.SHR_ASSIGN:
@@ -117,11 +124,8 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				if op == shaderir.And || op == shaderir.Or || op == shaderir.Xor || op == shaderir.LeftShift || op == shaderir.RightShift {
 					if lts[0].Main != shaderir.Int && !lts[0].IsIntVector() {
 						cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", stmt.Tok, lts[0].String()))
+						return nil, false
 					}
-					if rts[0].Main != shaderir.Int && !rts[0].IsIntVector() {
-						cs.addError(stmt.Pos(), fmt.Sprintf("invalid operation: operator %s not defined on %s", stmt.Tok, rts[0].String()))
-					}
-					return nil, false
 				}
 				if lts[0].Main == shaderir.Int && rhs[0].Const != nil {
 					if !cs.forceToInt(stmt, &rhs[0]) {
@@ -189,6 +193,10 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 				return nil, false
 			}
 
+			if stmt.Tok == token.AND_NOT_ASSIGN {
+				rhs[0] = complementExpr(rhs[0])
+			}
+
 			stmts = append(stmts, shaderir.Stmt{
 				Type: shaderir.Assign,
 				Exprs: []shaderir.Expr{
@@ -231,6 +239,13 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 		}
 		stmts = append(stmts, ss...)
 
+	case *ast.SwitchStmt:
+		ss, ok := cs.parseSwitch(block, fname, stmt, inParams, outParams, returnType)
+		if !ok {
+			return nil, false
+		}
+		stmts = append(stmts, ss...)
+
 	case *ast.IfStmt:
 		if stmt.Init != nil {
 			init := stmt.Init
@@ -301,6 +316,10 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 			return nil, false
 		}
 		stmts = append(stmts, ss...)
+		if isConstArrayExpr(&exprs[0]) {
+			cs.addError(stmt.Pos(), "a constant array cannot be assigned")
+			return nil, false
+		}
 		var op shaderir.Op
 		switch stmt.Tok {
 		case token.INC:
@@ -337,6 +356,14 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 		})
 
 	case *ast.ReturnStmt:
+		if fname == cs.fragmentEntry && cs.fragmentOutputStruct != nil {
+			s, ok := cs.expandFragmentOutputStruct(stmt)
+			if !ok {
+				return nil, false
+			}
+			stmt = s
+		}
+
 		if len(stmt.Results) != len(outParams) && len(stmt.Results) != 1 {
 			if !(len(stmt.Results) == 0 && len(outParams) > 0 && outParams[0].name != "") {
 				// TODO: Check variable shadowings.
@@ -456,10 +483,19 @@ func (cs *compileState) parseStmt(block *block, fname string, stmt ast.Stmt, inP
 		}
 
 	case *ast.ExprStmt:
-		if _, ok := stmt.X.(*ast.CallExpr); !ok {
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok {
 			cs.addError(stmt.Pos(), "the statement is evaluated but not used")
 			return nil, false
 		}
+		if cs.isDebugfCall(call) {
+			ss, ok := cs.parseDebugf(block, fname, call, inParams, outParams, returnType)
+			if !ok {
+				return nil, false
+			}
+			stmts = append(stmts, ss...)
+			break
+		}
 
 		exprs, _, ss, ok := cs.parseExpr(block, fname, stmt.X, true)
 		if !ok {
@@ -575,6 +611,10 @@ func (cs *compileState) assign(block *block, fname string, pos token.Pos, lhs, r
 				return false
 			}
 
+			if isConstArrayExpr(&l[0]) {
+				cs.addError(pos, "a constant array cannot be assigned")
+				return nil, false
+			}
 			if isAssignmentForbidden(&l[0]) {
 				cs.addError(pos, "a uniform variable cannot be assigned")
 				return nil, false
@@ -748,7 +788,7 @@ func canAssign(lt *shaderir.Type, rt *shaderir.Type, rc gconstant.Value) bool {
 }
 
 func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt, inParams, outParams []variable, returnType shaderir.Type, checkLocalVariableUsage bool) ([]shaderir.Stmt, bool) {
-	msg := "for-statement must follow this format: for (varname) := (constant); (varname) (op) (constant); (varname) (op) (constant) { ..."
+	msg := "for-statement must follow this format: for (varname) := (constant); (varname) (op) (bound); (varname) (op) (constant) { ..."
 	if stmt.Init == nil {
 		cs.addError(stmt.Pos(), msg)
 		return nil, false
@@ -784,7 +824,8 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 		return nil, false
 	}
 	varidx := ss[0].Exprs[0].Index
-	if ss[0].Exprs[1].Const == nil {
+	// A non-constant value might be converted to an unknown constant at the assignment.
+	if ss[0].Exprs[1].Const == nil || ss[0].Exprs[1].Const.Kind() == gconstant.Unknown {
 		cs.addError(stmt.Pos(), msg)
 		return nil, false
 	}
@@ -830,11 +871,25 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 		cs.addError(stmt.Pos(), msg)
 		return nil, false
 	}
-	if exprs[0].Exprs[1].Const == nil {
+	end := exprs[0].Exprs[1].Const
+	var endExprs []shaderir.Expr
+	if end != nil && end.Kind() == gconstant.Unknown {
 		cs.addError(stmt.Pos(), msg)
 		return nil, false
 	}
-	end := exprs[0].Exprs[1].Const
+	if end == nil {
+		// A non-constant bound is evaluated at every iteration.
+		// To ensure the loop terminates, the counter must move toward the bound monotonically.
+		if op == shaderir.EqualOp || op == shaderir.NotEqualOp {
+			cs.addError(stmt.Pos(), "for-statement's condition with a non-constant bound must have one of these operators: <, <=, >, >=")
+			return nil, false
+		}
+		if usesLocalVariable(&exprs[0].Exprs[1], varidx) {
+			cs.addError(stmt.Pos(), "for-statement's bound cannot use the counter variable")
+			return nil, false
+		}
+		endExprs = []shaderir.Expr{exprs[0].Exprs[1]}
+	}
 
 	postSs, ok := cs.parseStmt(pseudoBlock, fname, stmt.Post, inParams, outParams, returnType)
 	if !ok {
@@ -881,6 +936,13 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 		cs.addError(stmt.Pos(), "for-statement's post statement must have one of these operators: +=, -=, ++, --")
 		return nil, false
 	}
+	if endExprs != nil {
+		inc := op == shaderir.LessThanOp || op == shaderir.LessThanEqualOp
+		if s := gconstant.Sign(delta); (inc && s <= 0) || (!inc && s >= 0) {
+			cs.addError(stmt.Pos(), "for-statement's counter must move toward the non-constant bound")
+			return nil, false
+		}
+	}
 
 	b, ok := cs.parseBlock(pseudoBlock, fname, []ast.Stmt{stmt.Body}, inParams, outParams, returnType, true)
 	if !ok {
@@ -901,6 +963,7 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 	return []shaderir.Stmt{
 		{
 			Type:        shaderir.For,
+			Exprs:       endExprs,
 			Blocks:      []*shaderir.Block{bodyir},
 			ForVarType:  vartype,
 			ForVarIndex: varidx,
@@ -911,3 +974,397 @@ func (cs *compileState) parseFor(block *block, fname string, stmt *ast.ForStmt,
 		},
 	}, true
 }
+
+// usesLocalVariable reports whether the expression refers to the local variable at the index.
+func usesLocalVariable(expr *shaderir.Expr, index int) bool {
+	if expr.Type == shaderir.LocalVariable && expr.Index == index {
+		return true
+	}
+	for i := range expr.Exprs {
+		if usesLocalVariable(&expr.Exprs[i], index) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *compileState) parseSwitch(block *block, fname string, stmt *ast.SwitchStmt, inParams, outParams []variable, returnType shaderir.Type) ([]shaderir.Stmt, bool) {
+	if stmt.Init != nil {
+		init := stmt.Init
+		stmt.Init = nil
+		b, ok := cs.parseBlock(block, fname, []ast.Stmt{init, stmt}, inParams, outParams, returnType, true)
+		if !ok {
+			return nil, false
+		}
+		return []shaderir.Stmt{
+			{
+				Type:   shaderir.BlockStmt,
+				Blocks: []*shaderir.Block{b.ir},
+			},
+		}, true
+	}
+
+	var clauses []*ast.CaseClause
+	var hasDefault bool
+	for _, s := range stmt.Body.List {
+		c := s.(*ast.CaseClause)
+		if c.List == nil {
+			if hasDefault {
+				cs.addError(c.Pos(), "multiple defaults in switch")
+				return nil, false
+			}
+			hasDefault = true
+		}
+		if len(c.Body) > 0 {
+			if b, ok := c.Body[len(c.Body)-1].(*ast.BranchStmt); ok && b.Tok == token.FALLTHROUGH {
+				cs.addError(b.Pos(), "fallthrough is not implemented")
+				return nil, false
+			}
+		}
+		clauses = append(clauses, c)
+	}
+
+	if stmt.Tag != nil {
+		ss, ok, native := cs.parseIntegerSwitch(block, fname, stmt, clauses, inParams, outParams, returnType)
+		if !ok {
+			return nil, false
+		}
+		if native {
+			return ss, true
+		}
+	}
+	return cs.parseSwitchAsIf(block, fname, stmt, clauses, inParams, outParams, returnType)
+}
+
+// parseIntegerSwitch parses a switch statement with an integer tag and integer constant case values.
+// Such a switch statement is converted to a switch statement in the shading languages.
+// If the switch statement doesn't satisfy the conditions, parseIntegerSwitch returns false as native.
+func (cs *compileState) parseIntegerSwitch(block *block, fname string, stmt *ast.SwitchStmt, clauses []*ast.CaseClause, inParams, outParams []variable, returnType shaderir.Type) (stmts []shaderir.Stmt, ok bool, native bool) {
+	tags, ts, ss, ok := cs.parseExpr(block, fname, stmt.Tag, true)
+	if !ok {
+		return nil, false, false
+	}
+	if len(tags) != 1 {
+		cs.addError(stmt.Tag.Pos(), "multiple-value context is not available at a switch tag")
+		return nil, false, false
+	}
+	tag := tags[0]
+	if tag.Const != nil {
+		if !canTruncateToInteger(tag.Const) {
+			return nil, true, false
+		}
+		tag.Const = gconstant.ToInt(tag.Const)
+	} else if ts[0].Main != shaderir.Int {
+		return nil, true, false
+	}
+
+	var cases [][]gconstant.Value
+	for _, c := range clauses {
+		if c.List == nil {
+			cases = append(cases, nil)
+			continue
+		}
+		vals := []gconstant.Value{}
+		for _, e := range c.List {
+			exprs, _, _, ok := cs.parseExpr(block, fname, e, true)
+			if !ok {
+				return nil, false, false
+			}
+			if len(exprs) != 1 || exprs[0].Const == nil || !canTruncateToInteger(exprs[0].Const) {
+				return nil, true, false
+			}
+			v := gconstant.ToInt(exprs[0].Const)
+			for _, vs := range cases {
+				for _, v2 := range vs {
+					if gconstant.Compare(v, token.EQL, v2) {
+						cs.addError(e.Pos(), fmt.Sprintf("duplicate case %s in expression switch", v.String()))
+						return nil, false, false
+					}
+				}
+			}
+			for _, v2 := range vals {
+				if gconstant.Compare(v, token.EQL, v2) {
+					cs.addError(e.Pos(), fmt.Sprintf("duplicate case %s in expression switch", v.String()))
+					return nil, false, false
+				}
+			}
+			vals = append(vals, v)
+		}
+		cases = append(cases, vals)
+	}
+
+	var bs []*shaderir.Block
+	for _, c := range clauses {
+		b, ok := cs.parseBlock(block, fname, trimTrailingBreak(c.Body), inParams, outParams, returnType, true)
+		if !ok {
+			return nil, false, false
+		}
+		bs = append(bs, b.ir)
+	}
+
+	stmts = append(stmts, ss...)
+	stmts = append(stmts, shaderir.Stmt{
+		Type:        shaderir.Switch,
+		Exprs:       []shaderir.Expr{tag},
+		Blocks:      bs,
+		SwitchCases: cases,
+	})
+	return stmts, true, true
+}
+
+// parseSwitchAsIf parses a switch statement as if-else statements.
+//
+// For example,
+//
+//	switch tag {
+//	case a, b:
+//		S1
+//	default:
+//		S2
+//	}
+//
+// is converted to
+//
+//	{
+//		__switchTag := tag
+//		if __switchTag == a || __switchTag == b {
+//			S1
+//		} else {
+//			S2
+//		}
+//	}
+func (cs *compileState) parseSwitchAsIf(block *block, fname string, stmt *ast.SwitchStmt, clauses []*ast.CaseClause, inParams, outParams []variable, returnType shaderir.Type) ([]shaderir.Stmt, bool) {
+	const tagName = "__switchTag"
+
+	var list []ast.Stmt
+	if stmt.Tag != nil {
+		list = append(list, &ast.AssignStmt{
+			Lhs:    []ast.Expr{&ast.Ident{NamePos: stmt.Tag.Pos(), Name: tagName}},
+			TokPos: stmt.Tag.Pos(),
+			Tok:    token.DEFINE,
+			Rhs:    []ast.Expr{stmt.Tag},
+		})
+	}
+
+	var defaultBody []ast.Stmt
+	var cases []*ast.CaseClause
+	for _, c := range clauses {
+		body := trimTrailingBreak(c.Body)
+		// Other breaks cannot be converted as a break in an if statement means a break of the outer loop.
+		if pos, ok := findBreak(body); ok {
+			cs.addError(pos, "break in a switch statement is available only at the end of a clause when the tag is not an integer or a case value is not an integer constant")
+			return nil, false
+		}
+		if c.List == nil {
+			defaultBody = body
+			if defaultBody == nil {
+				defaultBody = []ast.Stmt{}
+			}
+			continue
+		}
+		c := *c
+		c.Body = body
+		cases = append(cases, &c)
+	}
+
+	var s ast.Stmt
+	if defaultBody != nil {
+		s = &ast.BlockStmt{
+			List: defaultBody,
+		}
+	}
+	for i := len(cases) - 1; i >= 0; i-- {
+		c := cases[i]
+		var cond ast.Expr
+		for _, e := range c.List {
+			if stmt.Tag != nil {
+				e = &ast.BinaryExpr{
+					X:     &ast.Ident{NamePos: e.Pos(), Name: tagName},
+					OpPos: e.Pos(),
+					Op:    token.EQL,
+					Y:     e,
+				}
+			}
+			if cond == nil {
+				cond = e
+				continue
+			}
+			cond = &ast.BinaryExpr{
+				X:     cond,
+				OpPos: e.Pos(),
+				Op:    token.LOR,
+				Y:     e,
+			}
+		}
+		ifStmt := &ast.IfStmt{
+			If:   c.Case,
+			Cond: cond,
+			Body: &ast.BlockStmt{
+				Lbrace: c.Colon,
+				List:   c.Body,
+			},
+		}
+		if s != nil {
+			ifStmt.Else = s
+		}
+		s = ifStmt
+	}
+	if s != nil {
+		list = append(list, s)
+	}
+	if stmt.Tag != nil && len(cases) == 0 {
+		// Use the tag variable to avoid an error for an unused variable.
+		list = append(list, &ast.AssignStmt{
+			Lhs:    []ast.Expr{&ast.Ident{NamePos: stmt.Tag.Pos(), Name: "_"}},
+			TokPos: stmt.Tag.Pos(),
+			Tok:    token.ASSIGN,
+			Rhs:    []ast.Expr{&ast.Ident{NamePos: stmt.Tag.Pos(), Name: tagName}},
+		})
+	}
+
+	b, ok := cs.parseBlock(block, fname, list, inParams, outParams, returnType, true)
+	if !ok {
+		return nil, false
+	}
+	return []shaderir.Stmt{
+		{
+			Type:   shaderir.BlockStmt,
+			Blocks: []*shaderir.Block{b.ir},
+		},
+	}, true
+}
+
+// trimTrailingBreak removes a break statement at the end of a case clause, which is redundant.
+func trimTrailingBreak(stmts []ast.Stmt) []ast.Stmt {
+	if len(stmts) == 0 {
+		return stmts
+	}
+	if b, ok := stmts[len(stmts)-1].(*ast.BranchStmt); ok && b.Tok == token.BREAK && b.Label == nil {
+		return stmts[:len(stmts)-1]
+	}
+	return stmts
+}
+
+// findBreak returns the position of a break statement for the current context in the given statements.
+// Break statements in nested for and switch statements are ignored.
+func findBreak(stmts []ast.Stmt) (token.Pos, bool) {
+	var pos token.Pos
+	for _, s := range stmts {
+		ast.Inspect(s, func(n ast.Node) bool {
+			if pos.IsValid() {
+				return false
+			}
+			switch n := n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.FuncLit:
+				return false
+			case *ast.BranchStmt:
+				if n.Tok == token.BREAK {
+					pos = n.Pos()
+				}
+			}
+			return true
+		})
+	}
+	return pos, pos.IsValid()
+}
+
+// expandFragmentOutputStruct converts a return statement with a composite literal of the fragment entry point's output struct
+// like `return Output{Color: c, Normal: n}` to a return statement with the member values in the member order.
+// An omitted member is zero.
+func (cs *compileState) expandFragmentOutputStruct(stmt *ast.ReturnStmt) (*ast.ReturnStmt, bool) {
+	st := cs.fragmentOutputStruct
+	if len(stmt.Results) != 1 {
+		cs.addError(stmt.Pos(), fmt.Sprintf("%s must return one value of %s", cs.fragmentEntry, st.name))
+		return nil, false
+	}
+	lit, ok := stmt.Results[0].(*ast.CompositeLit)
+	if !ok {
+		cs.addError(stmt.Results[0].Pos(), fmt.Sprintf("%s must return a composite literal like %s{...}", cs.fragmentEntry, st.name))
+		return nil, false
+	}
+	if id, ok := lit.Type.(*ast.Ident); !ok || id.Name != st.name {
+		cs.addError(lit.Pos(), fmt.Sprintf("%s must return a composite literal like %s{...}", cs.fragmentEntry, st.name))
+		return nil, false
+	}
+
+	results := make([]ast.Expr, len(st.fields))
+	if len(lit.Elts) > 0 {
+		if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); keyed {
+			for _, e := range lit.Elts {
+				kv, ok := e.(*ast.KeyValueExpr)
+				if !ok {
+					cs.addError(e.Pos(), "mixture of field:value and value elements in struct literal")
+					return nil, false
+				}
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					cs.addError(kv.Key.Pos(), "invalid field name in struct literal")
+					return nil, false
+				}
+				idx := -1
+				for i, f := range st.fields {
+					if f.name == key.Name {
+						idx = i
+						break
+					}
+				}
+				if idx < 0 {
+					cs.addError(key.Pos(), fmt.Sprintf("unknown field %s in struct literal of type %s", key.Name, st.name))
+					return nil, false
+				}
+				if results[idx] != nil {
+					cs.addError(key.Pos(), fmt.Sprintf("duplicate field name %s in struct literal", key.Name))
+					return nil, false
+				}
+				results[idx] = kv.Value
+			}
+		} else {
+			for _, e := range lit.Elts {
+				if _, ok := e.(*ast.KeyValueExpr); ok {
+					cs.addError(e.Pos(), "mixture of field:value and value elements in struct literal")
+					return nil, false
+				}
+			}
+			if len(lit.Elts) != len(st.fields) {
+				cs.addError(lit.Pos(), fmt.Sprintf("the number of values in struct literal of type %s must be %d but %d", st.name, len(st.fields), len(lit.Elts)))
+				return nil, false
+			}
+			copy(results, lit.Elts)
+		}
+	}
+
+	for i := range results {
+		if results[i] != nil {
+			continue
+		}
+		results[i] = &ast.CallExpr{
+			Fun: &ast.Ident{
+				NamePos: lit.Rbrace,
+				Name:    "vec4",
+			},
+			Args: []ast.Expr{
+				&ast.BasicLit{
+					ValuePos: lit.Rbrace,
+					Kind:     token.INT,
+					Value:    "0",
+				},
+			},
+		}
+	}
+
+	return &ast.ReturnStmt{
+		Return:  stmt.Return,
+		Results: results,
+	}, true
+}
+
+// isConstArrayExpr reports whether e is a constant array or a part of it.
+func isConstArrayExpr(e *shaderir.Expr) bool {
+	switch e.Type {
+	case shaderir.ConstArrayVariable:
+		return true
+	case shaderir.FieldSelector, shaderir.Index:
+		return isConstArrayExpr(&e.Exprs[0])
+	}
+	return false
+}
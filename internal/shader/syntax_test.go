@@ -16,6 +16,7 @@ package shader_test
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -1688,6 +1689,21 @@ func TestSyntaxBitwiseOperatorAssign(t *testing.T) {
 		{stmt: "a := vec2(1); a ^= vec2(2)", err: true},
 		{stmt: "a := mat2(1); a ^= 2", err: true},
 		{stmt: "a := mat2(1); a ^= mat2(2)", err: true},
+
+		{stmt: "a := 1; a &^= 2", err: false},
+		{stmt: "a := 1; a &^= 2.0", err: false},
+		{stmt: "const c = 2; a := 1; a &^= c", err: false},
+		{stmt: "const c float = 2; a := 1; a &^= c", err: true},
+		{stmt: "a := 1; a &^= int(2)", err: false},
+		{stmt: "a := 1; a &^= vec2(2)", err: true},
+		{stmt: "a := 1; a &^= ivec2(2)", err: true},
+		{stmt: "a := 1.0; a &^= 2", err: true},
+		{stmt: "a := ivec2(1); a &^= 2", err: false},
+		{stmt: "a := ivec2(1); a &^= int(2)", err: false},
+		{stmt: "a := ivec2(1); a &^= ivec2(1)", err: false},
+		{stmt: "a := ivec2(1); a &^= ivec3(1)", err: true},
+		{stmt: "a := vec2(1); a &^= vec2(2)", err: true},
+		{stmt: "a := mat2(1); a &^= mat2(2)", err: true},
 	}
 
 	for _, c := range cases {
@@ -4218,6 +4234,30 @@ func TestSyntaxBitwiseOperator(t *testing.T) {
 		{stmt: "_ = mat2(0) ^ mat2(1)", err: true},
 		{stmt: "_ = mat3(0) ^ mat3(1)", err: true},
 		{stmt: "_ = mat4(0) ^ mat4(1)", err: true},
+
+		{stmt: "_ = false &^ true", err: true},
+		{stmt: "_ = 3 &^ 1", err: false},
+		{stmt: "_ = int(0) &^ int(1)", err: false},
+		{stmt: "_ = float(0) &^ float(1)", err: true},
+		{stmt: "_ = vec2(0) &^ vec2(1)", err: true},
+		{stmt: "_ = ivec2(0) &^ ivec2(1)", err: false},
+		{stmt: "_ = ivec3(0) &^ ivec3(1)", err: false},
+		{stmt: "_ = ivec4(0) &^ ivec4(1)", err: false},
+		{stmt: "_ = ivec2(0) &^ int(1)", err: false},
+		{stmt: "_ = int(0) &^ ivec2(1)", err: false},
+		{stmt: "_ = ivec2(0) &^ ivec3(1)", err: true},
+		{stmt: "_ = mat2(0) &^ mat2(1)", err: true},
+
+		{stmt: "_ = ^1", err: false},
+		{stmt: "_ = ^1.0", err: true},
+		{stmt: "_ = ^int(1)", err: false},
+		{stmt: "_ = ^float(1)", err: true},
+		{stmt: "_ = ^true", err: true},
+		{stmt: "_ = ^ivec2(1)", err: false},
+		{stmt: "_ = ^ivec3(1)", err: false},
+		{stmt: "_ = ^ivec4(1)", err: false},
+		{stmt: "_ = ^vec2(1)", err: true},
+		{stmt: "_ = ^mat2(1)", err: true},
 	}
 
 	for _, c := range cases {
@@ -4427,3 +4467,824 @@ func Foo() float {
 		t.Error(err)
 	}
 }
+
+func TestSyntaxDualSourceFragment(t *testing.T) {
+	const vertex = `
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, color
+}
+`
+
+	p, err := compileToIR([]byte(`package main
+` + vertex + `
+func Fragment(dstPos vec4, srcPos vec2, color vec4) (vec4, vec4) {
+	if color.a == 0 {
+		discard()
+	}
+	return color, vec4(color.a)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.FragmentFunc.OutputCount, 2; got != want {
+		t.Errorf("got: %d, want: %d", got, want)
+	}
+
+	if _, err := compileToIR([]byte(`package main
+` + vertex + `
+func Fragment(dstPos vec4, srcPos vec2, color vec4) (vec4, vec4, vec4) {
+	return color, color, color
+}
+`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+
+	if _, err := compileToIR([]byte(`package main
+` + vertex + `
+func Fragment(dstPos vec4, srcPos vec2, color vec4) (vec4, float) {
+	return color, 1
+}
+`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+}
+
+func TestSyntaxCompute(t *testing.T) {
+	cases := []struct {
+		src           string
+		workgroupSize [3]int
+		err           bool
+	}{
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}`,
+			workgroupSize: [3]int{1, 1, 1},
+		},
+		{
+			src: `//kage:compute 8 4
+
+package main
+
+func Compute(id ivec3, localID ivec3, groupID ivec3) {
+	imageStore(0, id.xy, imageLoad(1, localID.xy+groupID.xy))
+}`,
+			workgroupSize: [3]int{8, 4, 1},
+		},
+		{
+			src: `//kage:compute 8 8 1
+
+package main
+
+//kage:shared
+var tile [64]vec4
+
+func Compute(id ivec3, localID ivec3) {
+	i := localID.y*8 + localID.x
+	tile[i] = imageLoad(0, id.xy)
+	barrier()
+	imageStore(0, id.xy, tile[63-i])
+}`,
+			workgroupSize: [3]int{8, 8, 1},
+		},
+		{
+			src: `package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute 0
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute 1 1 1 1
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec2) {
+	imageStore(0, id, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec3) vec4 {
+	return vec4(1)
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	i := 0
+	imageStore(i, id.xy, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(8, id.xy, vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, vec2(id.xy), vec4(1))
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+//kage:shared
+var tile [64]vec4 = [64]vec4{}
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, tile[0])
+}`,
+			err: true,
+		},
+		{
+			src: `//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(dfdx(1.0)))
+}`,
+			err: true,
+		},
+	}
+	for _, c := range cases {
+		p, err := shader.CompileCompute([]byte(c.src), "Compute")
+		if err != nil {
+			if !c.err {
+				t.Errorf("error must be nil but non-nil: %v\n%s", err, c.src)
+			}
+			continue
+		}
+		if c.err {
+			t.Errorf("error must be non-nil but was nil\n%s", c.src)
+			continue
+		}
+		if !p.IsCompute() {
+			t.Errorf("p.IsCompute() must be true but not\n%s", c.src)
+		}
+		if got, want := p.ComputeFunc.WorkgroupSize, c.workgroupSize; got != want {
+			t.Errorf("got: %v, want: %v\n%s", got, want, c.src)
+		}
+	}
+
+	// A compute kernel cannot be compiled as a vertex and fragment shader.
+	if _, err := compileToIR([]byte(`//kage:compute
+
+package main
+
+func Compute(id ivec3) {
+	imageStore(0, id.xy, vec4(1))
+}`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+
+	// Compute-only features are not available in a fragment shader.
+	if _, err := compileToIR([]byte(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	barrier()
+	return color
+}`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+	if _, err := compileToIR([]byte(`package main
+
+//kage:shared
+var tile [64]vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return tile[0]
+}`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+}
+
+func TestSyntaxRuntimeSizedUniformArray(t *testing.T) {
+	const src = `package main
+
+var Foo []vec4
+var Bar float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var c vec4
+	for i := 0; i < len(Foo); i++ {
+		c += Foo[i]
+	}
+	return c * Bar
+}
+`
+
+	cases := []struct {
+		lengths    map[string]int
+		wantLength int
+	}{
+		{nil, 1},
+		{map[string]int{"Foo": 0}, 1},
+		{map[string]int{"Foo": 1}, 1},
+		{map[string]int{"Foo": 5}, 5},
+	}
+	for _, c := range cases {
+		p, err := shader.CompileWithArrayLengths([]byte(src), "Vertex", "Fragment", 0, c.lengths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := p.UniformNames, []string{"Foo", "Bar"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("p.UniformNames: got: %v, want: %v", got, want)
+		}
+		if got, want := p.RuntimeSizedUniforms, []int{0}; !reflect.DeepEqual(got, want) {
+			t.Errorf("p.RuntimeSizedUniforms: got: %v, want: %v", got, want)
+		}
+		if got, want := p.Uniforms[0].Length, c.wantLength; got != want {
+			t.Errorf("p.Uniforms[0].Length with %v: got: %d, want: %d", c.lengths, got, want)
+		}
+	}
+
+	for _, src := range []string{
+		`package main
+
+var foo []vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0)
+}
+`,
+		`package main
+
+var Foo [][2]vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0)
+}
+`,
+		`package main
+
+var Foo []vec4
+var Foo vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0)
+}
+`,
+		`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var foo []vec4
+	return vec4(0)
+}
+`,
+	} {
+		if _, err := compileToIR([]byte(src)); err == nil {
+			t.Errorf("error must be non-nil but was nil:\n%s", src)
+		}
+	}
+}
+
+func TestSyntaxStructUniform(t *testing.T) {
+	p, err := compileToIR([]byte(`package main
+
+type Inner struct {
+	Scale float
+}
+
+type Material struct {
+	Color vec4
+	Inner Inner
+}
+
+type Light struct {
+	Pos   vec2
+	Color vec4
+}
+
+var Mat Material
+var Lights [3]Light
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := Mat.Color * Mat.Inner.Scale
+	for i := 0; i < len(Lights); i++ {
+		c += Lights[i].Color * Lights[i].Pos.x
+	}
+	return c
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.UniformNames, []string{"Mat.Color", "Mat.Inner.Scale", "Lights.Pos", "Lights.Color"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.UniformNames: got: %v, want: %v", got, want)
+	}
+	if got, want := p.Uniforms[3].String(), "[3]vec4"; got != want {
+		t.Errorf("p.Uniforms[3]: got: %s, want: %s", got, want)
+	}
+
+	p, err = shader.CompileWithArrayLengths([]byte(`package main
+
+type Light struct {
+	Pos   vec2
+	Color vec4
+}
+
+var Lights []Light
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var c vec4
+	for i := 0; i < len(Lights); i++ {
+		c += Lights[i].Color
+	}
+	return c
+}
+`), "Vertex", "Fragment", 0, map[string]int{"Lights": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.RuntimeSizedUniforms, []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.RuntimeSizedUniforms: got: %v, want: %v", got, want)
+	}
+	if got, want := p.Uniforms[1].String(), "[5]vec4"; got != want {
+		t.Errorf("p.Uniforms[1]: got: %s, want: %s", got, want)
+	}
+
+	for _, src := range []string{
+		// A struct cannot be used as a value.
+		`type Foo struct {
+	Color vec4
+}
+
+var Foo0 Foo
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	f := Foo0
+	return f.Color
+}
+`,
+		// A struct type cannot be used for a local variable.
+		`type Foo struct {
+	Color vec4
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var f Foo
+	return vec4(0)
+}
+`,
+		// A missing field.
+		`type Foo struct {
+	Color vec4
+}
+
+var Foo0 Foo
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Foo0.Colour
+}
+`,
+		// An array of a struct having an array.
+		`type Foo struct {
+	Colors [2]vec4
+}
+
+var Foo0 [2]Foo
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(0)
+}
+`,
+		// A struct type in a function.
+		`func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	type Foo struct {
+		Color vec4
+	}
+	return vec4(0)
+}
+`,
+	} {
+		if _, err := compileToIR([]byte("package main\n\n" + src)); err == nil {
+			t.Errorf("error must be non-nil but was nil:\n%s", src)
+		}
+	}
+}
+
+func TestSyntaxForWithNonConstantBound(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "for i := 0; i < N; i++ {}", err: false},
+		{stmt: "for i := 0; i <= N*2; i += 2 {}", err: false},
+		{stmt: "for i := N; i >= 0; i-- {}", err: true},
+		{stmt: "for i := 10; i > N; i-- {}", err: false},
+		{stmt: "for i := 0.0; i < F; i += 0.5 {}", err: false},
+		{stmt: "for i := 0; i < n; i++ {}", err: false},
+		{stmt: "for i := 0; i < N; i-- {}", err: true},
+		{stmt: "for i := 0; i > N; i++ {}", err: true},
+		{stmt: "for i := 0; i != N; i++ {}", err: true},
+		{stmt: "for i := 0; i == N; i++ {}", err: true},
+		{stmt: "for i := 0; i < i+N; i++ {}", err: true},
+		{stmt: "for i := 0; i < F; i++ {}", err: true},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+var N int
+var F float
+
+func Foo() {
+	n := N + 1
+	_ = n
+	%s
+}`, c.stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", c.stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return an error but returned %v", c.stmt, err)
+		}
+	}
+}
+
+func TestSyntaxSwitch(t *testing.T) {
+	cases := []struct {
+		stmt string
+		err  bool
+	}{
+		{stmt: "switch N { case 0: x = 1; case 1, 2: x = 2; default: x = 3 }", err: false},
+		{stmt: "switch y := N * 2; y { case 0: x = y }", err: false},
+		{stmt: "switch N { case 0: x = 1; break }", err: false},
+		{stmt: "switch N { case 0, 0: x = 1 }", err: true},
+		{stmt: "switch N { case 0: x = 1; case 0: x = 2 }", err: true},
+		{stmt: "switch N { default: x = 1; default: x = 2 }", err: true},
+		{stmt: "switch N { case 0: x = 1; fallthrough; case 1: x = 2 }", err: true},
+		{stmt: "switch N { case M: x = 1; case 1: x = 2 }", err: false},
+		{stmt: "switch F { case 0.5: x = 1; default: x = 2 }", err: false},
+		{stmt: "switch F { default: x = 1 }", err: false},
+		{stmt: "switch { case N < 0: x = 1; case F > 1: x = 2 }", err: false},
+		{stmt: "switch { case N < 0: x = 1; break }", err: false},
+		{stmt: "switch { case N < 0: if F > 1 { break }; x = 1 }", err: true},
+		{stmt: "switch { case N < 0: for i := 0; i < 4; i++ { break } }", err: false},
+		{stmt: "switch F { case 1: x = 1; case N: x = 2 }", err: true},
+		{stmt: "switch N { case 0: var z int = 1; x = z; case 1: var z float = 1; x = int(z) }", err: false},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+var N int
+var M int
+var F float
+
+func Foo() int {
+	x := 0
+	%s
+	return x
+}`, c.stmt)
+		_, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", c.stmt)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return an error but returned %v", c.stmt, err)
+		}
+	}
+}
+
+func TestSyntaxDebugf(t *testing.T) {
+	cases := []struct {
+		src string
+		err bool
+	}{
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf("%v %v", srcPos, color.rg); return color }`, err: false},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf("no values"); return color }`, err: false},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf("%v", ivec3(1)); return color }`, err: false},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf("%v %v", srcPos, color); return color }`, err: true},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf("%v", mat2(1)); return color }`, err: true},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf("%v", true); return color }`, err: true},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf(1); return color }`, err: true},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Debugf(); return color }`, err: true},
+		{src: `func Fragment(dstPos vec4, srcPos vec2, color vec4) (vec4, vec4) { Debugf("x"); return color, color }`, err: true},
+		{src: `func Foo() { Debugf("x") }; func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 { Foo(); return color }`, err: true},
+	}
+
+	for _, c := range cases {
+		src := fmt.Sprintf(`package main
+
+%s`, c.src)
+		_, err := shader.Compile([]byte(src), "", "Fragment", 0)
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", c.src)
+		} else if err != nil && !c.err {
+			t.Errorf("%s must not return an error but returned %v", c.src, err)
+		}
+	}
+}
+
+func TestSyntaxMultipleRenderTargets(t *testing.T) {
+	const vertex = `
+func Vertex(dstPos vec2, srcPos vec2, color vec4) (vec4, vec2, vec4) {
+	return vec4(dstPos, 0, 1), srcPos, color
+}
+`
+
+	cases := []struct {
+		stmt  string
+		count int
+		err   bool
+	}{
+		{stmt: "return Output{Color: color, Normal: vec4(0, 0, 1, 1), Emissive: vec4(1)}", count: 3, err: false},
+		{stmt: "return Output{Normal: vec4(0, 0, 1, 1), Color: color}", count: 3, err: false},
+		{stmt: "return Output{color, vec4(0, 0, 1, 1), vec4(1)}", count: 3, err: false},
+		{stmt: "return Output{}", count: 3, err: false},
+		{stmt: "return Output{color, vec4(1)}", err: true},
+		{stmt: "return Output{Color: color, vec4(1), vec4(1)}", err: true},
+		{stmt: "return Output{Color: color, Color: color}", err: true},
+		{stmt: "return Output{Foo: color}", err: true},
+		{stmt: "return color", err: true},
+	}
+
+	for _, c := range cases {
+		stmt := c.stmt
+		src := `package main
+
+type Output struct {
+	Color    vec4
+	Normal   vec4
+	Emissive vec4
+}
+` + vertex + `
+func Fragment(dstPos vec4, srcPos vec2, color vec4) Output {
+	` + stmt + `
+}
+`
+		p, err := compileToIR([]byte(src))
+		if err == nil && c.err {
+			t.Errorf("%s must return an error but does not", stmt)
+			continue
+		}
+		if err != nil && !c.err {
+			t.Errorf("%s must not return nil but returned %v", stmt, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got, want := p.FragmentFunc.OutputCount, c.count; got != want {
+			t.Errorf("%s: OutputCount: got: %d, want: %d", stmt, got, want)
+		}
+		if !p.FragmentFunc.MultipleRenderTargets {
+			t.Errorf("%s: MultipleRenderTargets must be true", stmt)
+		}
+		for i := 0; i < c.count; i++ {
+			if got := p.FragmentOutputProgram(i).FragmentFunc; got.OutputCount != 0 || got.MultipleRenderTargets {
+				t.Errorf("%s: FragmentOutputProgram(%d) must return one color but OutputCount: %d, MultipleRenderTargets: %t", stmt, i, got.OutputCount, got.MultipleRenderTargets)
+			}
+		}
+	}
+
+	if _, err := compileToIR([]byte(`package main
+
+type Output struct {
+	Color vec4
+	Depth float
+}
+` + vertex + `
+func Fragment(dstPos vec4, srcPos vec2, color vec4) Output {
+	return Output{Color: color}
+}
+`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+
+	if _, err := compileToIR([]byte(`package main
+
+type Output struct {
+	C0, C1, C2, C3, C4 vec4
+}
+` + vertex + `
+func Fragment(dstPos vec4, srcPos vec2, color vec4) Output {
+	return Output{}
+}
+`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+
+	if _, err := compileToIR([]byte(`package main
+
+type Output struct {
+	Color vec4
+}
+` + vertex + `
+func Foo() Output {
+	return Output{}
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`)); err == nil {
+		t.Errorf("error must be non-nil but was nil")
+	}
+}
+
+func TestSyntaxConstArray(t *testing.T) {
+	p, err := compileToIR([]byte(`package main
+
+var Bayer = [4]float{0, 2, 3, 1}
+
+var offsets = [...]vec2{vec2(-1, 0), vec2(1)}
+
+const indices = [3]int{2}
+
+var Foo float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	i := int(dstPos.x)
+	return vec4(Bayer[i]*offsets[indices[i]], float(len(Bayer)), Foo)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.UniformNames, []string{"Foo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.UniformNames: got: %v, want: %v", got, want)
+	}
+	if got, want := len(p.ConstArrays), 3; got != want {
+		t.Fatalf("len(p.ConstArrays): got: %d, want: %d", got, want)
+	}
+	for i, want := range []string{"[4]float", "[2]vec2", "[3]int"} {
+		if got := p.ConstArrays[i].Type.String(); got != want {
+			t.Errorf("p.ConstArrays[%d].Type: got: %s, want: %s", i, got, want)
+		}
+	}
+	var got []string
+	for _, v := range p.ConstArrays[1].Values {
+		got = append(got, v.String())
+	}
+	if want := []string{"-1", "0", "1", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.ConstArrays[1].Values: got: %v, want: %v", got, want)
+	}
+	got = nil
+	for _, v := range p.ConstArrays[2].Values {
+		got = append(got, v.String())
+	}
+	if want := []string{"2", "0", "0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.ConstArrays[2].Values: got: %v, want: %v", got, want)
+	}
+
+	for _, src := range []string{
+		// A non-constant element.
+		`package main
+
+var Foo float
+var Bar = [2]float{Foo, 1}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(Bar[0])
+}
+`,
+		// A non-array value.
+		`package main
+
+var Bar = vec4(1)
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Bar
+}
+`,
+		// Too many elements.
+		`package main
+
+var Bar = [2]float{1, 2, 3}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(Bar[0])
+}
+`,
+		// A mismatched type.
+		`package main
+
+var Bar [3]float = [2]float{1, 2}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(Bar[0])
+}
+`,
+		// A truncated integer.
+		`package main
+
+var Bar = [2]int{1, 2.5}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(float(Bar[0]))
+}
+`,
+		// An unsupported element type.
+		`package main
+
+var Bar = [1]mat2{mat2(1)}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(Bar[0][0][0])
+}
+`,
+		// A redeclared name.
+		`package main
+
+var Bar float
+var Bar = [2]float{1, 2}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(Bar[0])
+}
+`,
+		// Assignments.
+		`package main
+
+var Bar = [2]float{1, 2}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	Bar[0] = 1
+	return vec4(Bar[0])
+}
+`,
+		`package main
+
+var Bar = [2]vec2{vec2(1), vec2(2)}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	Bar[0].x += 1
+	return vec4(Bar[0], 0, 1)
+}
+`,
+		`package main
+
+var Bar = [2]int{1, 2}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	Bar[1]++
+	return vec4(float(Bar[0]))
+}
+`,
+	} {
+		if _, err := compileToIR([]byte(src)); err == nil {
+			t.Errorf("error must be non-nil but was nil:\n%s", src)
+		}
+	}
+}
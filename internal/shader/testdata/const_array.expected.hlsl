@@ -0,0 +1,9 @@
+static const float C0[4] = {0.0, 2.0, 3.0, 1.0};
+static const float2 C1[3] = {float2(-1.0, 0.0), float2(1.0, 1.0), float2(0.0, 5.0000000000e-01)};
+static const int C2[3] = {2, 0, 1};
+
+float2 F0(in int l0);
+
+float2 F0(in int l0) {
+	return ((C0)[l0]) * ((C1)[(C2)[l0]]);
+}
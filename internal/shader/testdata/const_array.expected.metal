@@ -0,0 +1,9 @@
+constant array<float, 4> C0 = {0.0, 2.0, 3.0, 1.0};
+constant array<float2, 3> C1 = {float2(-1.0, 0.0), float2(1.0, 1.0), float2(0.0, 5.0000000000e-01)};
+constant array<int, 3> C2 = {2, 0, 1};
+
+float2 F0(int l0);
+
+float2 F0(int l0) {
+	return ((C0)[l0]) * ((C1)[(C2)[l0]]);
+}
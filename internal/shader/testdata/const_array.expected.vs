@@ -0,0 +1,9 @@
+const float C0[4] = float[4](0.0, 2.0, 3.0, 1.0);
+const vec2 C1[3] = vec2[3](vec2(-1.0, 0.0), vec2(1.0, 1.0), vec2(0.0, 5.0000000000e-01));
+const int C2[3] = int[3](2, 0, 1);
+
+vec2 F0(in int l0);
+
+vec2 F0(in int l0) {
+	return ((C0)[l0]) * ((C1)[(C2)[l0]]);
+}
@@ -0,0 +1,11 @@
+package main
+
+var Bayer = [4]float{0, 2, 3, 1}
+
+var offsets = [...]vec2{vec2(-1, 0), vec2(1), vec2(0, 0.5)}
+
+const Indices = [3]int{2, 0, 1}
+
+func Foo(i int) vec2 {
+	return Bayer[i] * offsets[Indices[i]]
+}
@@ -0,0 +1,8 @@
+void F0(in float2 l0, out float2 l1, out float2 l2, out float2 l3);
+
+void F0(in float2 l0, out float2 l1, out float2 l2, out float2 l3) {
+	l1 = ddx(l0);
+	l2 = ddy(l0);
+	l3 = fwidth(l0);
+	return;
+}
@@ -0,0 +1,8 @@
+void F0(float2 l0, thread float2& l1, thread float2& l2, thread float2& l3);
+
+void F0(float2 l0, thread float2& l1, thread float2& l2, thread float2& l3) {
+	l1 = dfdx(l0);
+	l2 = dfdy(l0);
+	l3 = fwidth(l0);
+	return;
+}
@@ -0,0 +1,8 @@
+void F0(in vec2 l0, out vec2 l1, out vec2 l2, out vec2 l3);
+
+void F0(in vec2 l0, out vec2 l1, out vec2 l2, out vec2 l3) {
+	l1 = dFdx(l0);
+	l2 = dFdy(l0);
+	l3 = fwidth(l0);
+	return;
+}
@@ -0,0 +1,5 @@
+package main
+
+func Foo(x vec2) (vec2, vec2, vec2) {
+	return dfdx(x), dfdy(x), fwidth(x)
+}
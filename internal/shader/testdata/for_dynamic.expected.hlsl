@@ -0,0 +1,15 @@
+cbuffer Uniforms : register(b0) {
+	int U0 : packoffset(c0);
+}
+
+int F0(void);
+
+int F0(void) {
+	int l0 = 0;
+	l0 = 0;
+	[loop]
+	for (int l1 = 0; l1 < U0; l1++) {
+		l0 = (l0) + (l1);
+	}
+	return l0;
+}
@@ -0,0 +1,10 @@
+int F0(constant int& U0);
+
+int F0(constant int& U0) {
+	int l0 = 0;
+	l0 = 0;
+	for (int l1 = 0; l1 < U0; l1++) {
+		l0 = (l0) + (l1);
+	}
+	return l0;
+}
@@ -0,0 +1,11 @@
+package main
+
+var N int
+
+func Foo() int {
+	sum := 0
+	for i := 0; i < N; i++ {
+		sum += i
+	}
+	return sum
+}
@@ -0,0 +1,35 @@
+cbuffer Uniforms : register(b0) {
+	int U0 : packoffset(c0);
+}
+
+int F0(in int l0);
+
+int F0(in int l0) {
+	int l1 = 0;
+	l1 = 0;
+	switch (l0) {
+	case 0: {
+		l1 = 1;
+		break;
+	}
+	case 1:
+	case 2: {
+		l1 = 2;
+		break;
+	}
+	default: {
+		l1 = 3;
+		break;
+	}
+	}
+	{
+		if ((l0) < (U0)) {
+			l1 = (l1) + (4);
+		} else {
+			if ((l0) > (U0)) {
+				l1 = (l1) + (5);
+			}
+		}
+	}
+	return l1;
+}
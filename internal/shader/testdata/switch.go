@@ -0,0 +1,23 @@
+package main
+
+var N int
+
+func Foo(x int) int {
+	r := 0
+	switch x {
+	case 0:
+		r = 1
+	case 1, 2:
+		r = 2
+		break
+	default:
+		r = 3
+	}
+	switch {
+	case x < N:
+		r += 4
+	case x > N:
+		r += 5
+	}
+	return r
+}
@@ -52,6 +52,10 @@ func (cs *compileState) parseType(block *block, fname string, expr ast.Expr) (sh
 		case "mat4":
 			return shaderir.Type{Main: shaderir.Mat4}, true
 		default:
+			if _, ok := cs.findStructType(t.Name); ok {
+				cs.addError(t.Pos(), fmt.Sprintf("a struct type is available only for uniform variables and the result of %s: %s", cs.fragmentEntry, t.Name))
+				return shaderir.Type{}, false
+			}
 			cs.addError(t.Pos(), fmt.Sprintf("unexpected type: %s", t.Name))
 			return shaderir.Type{}, false
 		}
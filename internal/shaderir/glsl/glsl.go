@@ -95,6 +95,31 @@ out vec4 fragColor;`
 	return prelude
 }
 
+func ComputePrelude(version GLSLVersion) string {
+	var prefix string
+	switch version {
+	case GLSLVersionDefault:
+		prefix = `#version 430` + "\n\n"
+	case GLSLVersionES300:
+		prefix = `#version 310 es` + "\n\n"
+	}
+	prelude := prefix + `#if defined(GL_ES)
+precision highp float;
+precision highp int;
+precision highp image2D;
+#else
+#define lowp
+#define mediump
+#define highp
+#endif
+
+void barrierShared(void) {
+	memoryBarrierShared();
+	barrier();
+}`
+	return prelude
+}
+
 type compileContext struct {
 	version     GLSLVersion
 	structNames map[string]string
@@ -145,6 +170,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 				vslines = append(vslines, fmt.Sprintf("out %s;", c.varDecl(p, &t, fmt.Sprintf("V%d", i))))
 			}
 		}
+		vslines = append(vslines, c.constArrays(p)...)
 
 		var funcs []*shaderir.Func
 		if p.VertexFunc.Block != nil {
@@ -218,6 +244,23 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 	var fslines []string
 	{
 		fslines = append(fslines, strings.Split(FragmentPrelude(version), "\n")...)
+		// For multiple render targets with GLSL ES, the locations of the outputs must be specified explicitly.
+		// For OpenGL, the locations are specified by glBindFragDataLocation.
+		explicitLocation := p.FragmentFunc.MultipleRenderTargets && p.FragmentFunc.OutputCount > 1 && version == GLSLVersionES300
+		if explicitLocation {
+			for i, l := range fslines {
+				if l == "out vec4 fragColor;" {
+					fslines[i] = "layout(location = 0) " + l
+				}
+			}
+		}
+		for i := 1; i < p.FragmentFunc.OutputCount; i++ {
+			if explicitLocation {
+				fslines = append(fslines, fmt.Sprintf("layout(location = %d) out vec4 fragColor%d;", i, i))
+				continue
+			}
+			fslines = append(fslines, fmt.Sprintf("out vec4 fragColor%d;", i))
+		}
 		fslines = append(fslines, "", "{{.Structs}}")
 		if len(p.Uniforms) > 0 || p.TextureCount > 0 || len(p.Varyings) > 0 {
 			fslines = append(fslines, "")
@@ -231,6 +274,7 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 				fslines = append(fslines, fmt.Sprintf("in %s;", c.varDecl(p, &t, fmt.Sprintf("V%d", i))))
 			}
 		}
+		fslines = append(fslines, c.constArrays(p)...)
 
 		var funcs []*shaderir.Func
 		if p.VertexFunc.Block != nil {
@@ -295,6 +339,102 @@ func Compile(p *shaderir.Program, version GLSLVersion) (vertexShader, fragmentSh
 	return vs, fs
 }
 
+// CompileCompute compiles a compute kernel.
+//
+// For OpenGL ES, the shader requires GLSL ES 3.1 even if GLSLVersionES300 is specified.
+func CompileCompute(p *shaderir.Program, version GLSLVersion) (computeShader string) {
+	c := &compileContext{
+		version:     version,
+		structNames: map[string]string{},
+		unit:        p.Unit,
+	}
+
+	var lines []string
+	lines = append(lines, strings.Split(ComputePrelude(version), "\n")...)
+	if version == GLSLVersionDefault {
+		lines = append(lines, "", utilFunctions)
+	}
+	size := p.ComputeFunc.WorkgroupSize
+	lines = append(lines, "", fmt.Sprintf("layout(local_size_x = %d, local_size_y = %d, local_size_z = %d) in;", size[0], size[1], size[2]))
+	lines = append(lines, "", "{{.Structs}}")
+	if len(p.Uniforms) > 0 || p.StorageImageCount > 0 || len(p.SharedVars) > 0 {
+		lines = append(lines, "")
+		for i, t := range p.Uniforms {
+			lines = append(lines, fmt.Sprintf("uniform %s;", c.varDecl(p, &t, fmt.Sprintf("U%d", i))))
+		}
+		read, write := p.StorageImageAccesses()
+		for i := 0; i < p.StorageImageCount; i++ {
+			// With OpenGL ES, an image with the rgba8 format must be either readonly or writeonly.
+			var access string
+			switch {
+			case read[i] && !write[i]:
+				access = "readonly "
+			case !read[i] && write[i]:
+				access = "writeonly "
+			}
+			lines = append(lines, fmt.Sprintf("layout(rgba8, binding = %[1]d) uniform %[2]simage2D I%[1]d;", i, access))
+		}
+		for i, t := range p.SharedVars {
+			lines = append(lines, fmt.Sprintf("shared %s;", c.varDecl(p, &t, fmt.Sprintf("W%d", i))))
+		}
+	}
+	lines = append(lines, c.constArrays(p)...)
+
+	var funcs []*shaderir.Func
+	if p.ComputeFunc.Block != nil {
+		funcs = p.ReachableFuncsFromBlock(p.ComputeFunc.Block)
+	} else {
+		// When a compute entry point is not defined, allow to put all the functions. This is useful for testing.
+		funcs = make([]*shaderir.Func, 0, len(p.Funcs))
+		for _, f := range p.Funcs {
+			f := f
+			funcs = append(funcs, &f)
+		}
+	}
+	if len(funcs) > 0 {
+		lines = append(lines, "")
+		for _, f := range funcs {
+			lines = append(lines, c.function(p, f, true)...)
+		}
+		for _, f := range funcs {
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, c.function(p, f, false)...)
+		}
+	}
+
+	if p.ComputeFunc.Block != nil && len(p.ComputeFunc.Block.Stmts) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "void main(void) {")
+		lines = append(lines, c.block(p, p.ComputeFunc.Block, p.ComputeFunc.Block, 0)...)
+		lines = append(lines, "}")
+	}
+
+	cs := strings.Join(lines, "\n")
+
+	// Struct types are determined after converting the program.
+	if len(c.structTypes) > 0 {
+		var stlines []string
+		for i, t := range c.structTypes {
+			stlines = append(stlines, fmt.Sprintf("struct S%d {", i))
+			for j, st := range t.Sub {
+				stlines = append(stlines, fmt.Sprintf("\t%s;", c.varDecl(p, &st, fmt.Sprintf("M%d", j))))
+			}
+			stlines = append(stlines, "};")
+		}
+		cs = strings.ReplaceAll(cs, "{{.Structs}}", strings.Join(stlines, "\n"))
+	} else {
+		cs = strings.ReplaceAll(cs, "{{.Structs}}", "")
+	}
+
+	nls := regexp.MustCompile(`\n\n+`)
+	cs = nls.ReplaceAllString(cs, "\n\n")
+	cs = strings.TrimSpace(cs) + "\n"
+
+	return cs
+}
+
 func (c *compileContext) typ(p *shaderir.Program, t *shaderir.Type) (string, string) {
 	switch t.Main {
 	case shaderir.None:
@@ -318,6 +458,33 @@ func (c *compileContext) varDecl(p *shaderir.Program, t *shaderir.Type, varname
 	}
 }
 
+// constArrays returns the lines to declare the constant arrays.
+func (c *compileContext) constArrays(p *shaderir.Program) []string {
+	if len(p.ConstArrays) == 0 {
+		return nil
+	}
+	lines := []string{""}
+	for i := range p.ConstArrays {
+		a := &p.ConstArrays[i]
+		elms := make([]string, a.Type.Length)
+		for j := range elms {
+			vs := a.ElementValues(j)
+			if len(vs) == 1 {
+				elms[j] = constantToNumberLiteral(vs[0])
+				continue
+			}
+			strs := make([]string, len(vs))
+			for k, v := range vs {
+				strs[k] = constantToNumberLiteral(v)
+			}
+			elms[j] = fmt.Sprintf("%s(%s)", basicTypeString(a.Type.Sub[0].Main), strings.Join(strs, ", "))
+		}
+		t0, t1 := typeString(&a.Type)
+		lines = append(lines, fmt.Sprintf("const %s = %s%s(%s);", c.varDecl(p, &a.Type, fmt.Sprintf("C%d", i)), t0, t1, strings.Join(elms, ", ")))
+	}
+	return lines
+}
+
 func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 	switch t.Main {
 	case shaderir.None:
@@ -422,6 +589,17 @@ func (c *compileContext) localVariableName(p *shaderir.Program, topBlock *shader
 		default:
 			return fmt.Sprintf("l%d", idx-(nv+1))
 		}
+	case p.ComputeFunc.Block:
+		switch idx {
+		case 0:
+			return "ivec3(gl_GlobalInvocationID)"
+		case 1:
+			return "ivec3(gl_LocalInvocationID)"
+		case 2:
+			return "ivec3(gl_WorkGroupID)"
+		default:
+			return fmt.Sprintf("l%d", idx-3)
+		}
 	default:
 		return fmt.Sprintf("l%d", idx)
 	}
@@ -473,6 +651,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			return fmt.Sprintf("U%d", e.Index)
 		case shaderir.TextureVariable:
 			return fmt.Sprintf("T%d", e.Index)
+		case shaderir.SharedVariable:
+			return fmt.Sprintf("W%d", e.Index)
+		case shaderir.ConstArrayVariable:
+			return fmt.Sprintf("C%d", e.Index)
+		case shaderir.StorageImageVariable:
+			return fmt.Sprintf("I%d", e.Index)
 		case shaderir.LocalVariable:
 			return c.localVariableName(p, topBlock, e.Index)
 		case shaderir.StructMember:
@@ -489,7 +673,7 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 		case shaderir.Unary:
 			var op string
 			switch e.Op {
-			case shaderir.Add, shaderir.Sub, shaderir.NotOp:
+			case shaderir.Add, shaderir.Sub, shaderir.NotOp, shaderir.ComplementOp:
 				op = opString(e.Op)
 			default:
 				op = fmt.Sprintf("?(unexpected op: %d)", e.Op)
@@ -554,6 +738,26 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				lines = append(lines, c.block(p, topBlock, s.Blocks[1], level+1)...)
 			}
 			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.Switch:
+			lines = append(lines, fmt.Sprintf("%sswitch (%s) {", idt, expr(&s.Exprs[0])))
+			for i, b := range s.Blocks {
+				vals := s.SwitchCases[i]
+				if vals == nil {
+					lines = append(lines, fmt.Sprintf("%sdefault: {", idt))
+				} else {
+					for j, v := range vals {
+						if j < len(vals)-1 {
+							lines = append(lines, fmt.Sprintf("%scase %s:", idt, constantToNumberLiteral(v)))
+							continue
+						}
+						lines = append(lines, fmt.Sprintf("%scase %s: {", idt, constantToNumberLiteral(v)))
+					}
+				}
+				lines = append(lines, c.block(p, topBlock, b, level+1)...)
+				lines = append(lines, fmt.Sprintf("%s\tbreak;", idt))
+				lines = append(lines, fmt.Sprintf("%s}", idt))
+			}
+			lines = append(lines, fmt.Sprintf("%s}", idt))
 		case shaderir.For:
 			v := c.localVariableName(p, topBlock, s.ForVarIndex)
 			var delta string
@@ -583,7 +787,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 
 			t := s.ForVarType
 			init := constantToNumberLiteral(s.ForInit)
-			end := constantToNumberLiteral(s.ForEnd)
+			var end string
+			if s.ForEnd != nil {
+				end = constantToNumberLiteral(s.ForEnd)
+			} else {
+				end = expr(&s.Exprs[0])
+			}
 			t0, t1 := typeString(&t)
 			lines = append(lines, fmt.Sprintf("%sfor (%s %s%s = %s; %s %s %s; %s) {", idt, t0, v, t1, init, v, op, end, delta))
 			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
@@ -594,6 +803,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			lines = append(lines, idt+"break;")
 		case shaderir.Return:
 			switch {
+			case topBlock == p.FragmentFunc.Block && len(s.Exprs) > 1:
+				lines = append(lines, fmt.Sprintf("%sfragColor = %s;", idt, expr(&s.Exprs[0])))
+				for i := 1; i < len(s.Exprs); i++ {
+					lines = append(lines, fmt.Sprintf("%sfragColor%d = %s;", idt, i, expr(&s.Exprs[i])))
+				}
+				lines = append(lines, idt+"return;")
 			case topBlock == p.FragmentFunc.Block:
 				lines = append(lines, fmt.Sprintf("%sfragColor = %s;", idt, expr(&s.Exprs[0])))
 				// The 'return' statement is not required so far, as the fragment entrypoint has only one sentence so far. See adjustProgram implementation.
@@ -604,7 +819,11 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			}
 		case shaderir.Discard:
 			// 'discard' is invoked only in the fragment shader entry point.
-			lines = append(lines, idt+"discard;", idt+"return vec4(0.0);")
+			if topBlock == p.FragmentFunc.Block {
+				lines = append(lines, idt+"discard;", idt+"return;")
+			} else {
+				lines = append(lines, idt+"discard;", idt+"return vec4(0.0);")
+			}
 		default:
 			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
 		}
@@ -618,6 +837,13 @@ func adjustProgram(p *shaderir.Program) *shaderir.Program {
 		return p
 	}
 
+	// A fragment entry point with multiple colors assigns the colors to the out variables directly.
+	// The indirect call is not used as the function would have to return multiple values.
+	// This is used for dual-source blending and multiple render targets.
+	if p.FragmentFunc.OutputCount > 1 {
+		return p
+	}
+
 	// Shallow-clone the program in order not to modify p itself.
 	newP := *p
 
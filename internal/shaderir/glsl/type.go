@@ -60,6 +60,8 @@ func opString(op shaderir.Op) string {
 		return "&&"
 	case shaderir.OrOr:
 		return "||"
+	case shaderir.ComplementOp:
+		return "~"
 	}
 	return fmt.Sprintf("?(unexpected operator: %d)", op)
 }
@@ -121,11 +123,24 @@ func (c *compileContext) builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "dFdx"
 	case shaderir.Dfdy:
 		return "dFdy"
+	case shaderir.Barrier:
+		return "barrierShared"
 	case shaderir.TexelAt:
 		if c.unit == shaderir.Pixels {
 			return "texelFetch"
 		}
 		return "texture"
+	case shaderir.TexelAtLod:
+		// In the pixel mode, a texture is fetched without a sampler, and the level of detail is not used.
+		if c.unit == shaderir.Pixels {
+			return "texelFetch"
+		}
+		return "textureLod"
+	case shaderir.TexelFetch:
+		return "texelFetch"
+	case shaderir.TexelAtAnisotropic:
+		// The position is always in texels. The texture's filter is set by the driver.
+		return "texture"
 	default:
 		return string(f)
 	}
@@ -27,6 +27,7 @@ import (
 
 const (
 	vsOut = "varyings"
+	psOut = "psOut"
 )
 
 type compileContext struct {
@@ -81,8 +82,6 @@ float4x4 float4x4FromScalar(float x) {
 }`
 
 func Compile(p *shaderir.Program) (vertexShader, pixelShader, prelude string) {
-	offsets := CalcUniformMemoryOffsets(p)
-
 	c := &compileContext{
 		unit: p.Unit,
 	}
@@ -114,24 +113,7 @@ func Compile(p *shaderir.Program) (vertexShader, pixelShader, prelude string) {
 
 	lines = append(lines, "", "{{.Structs}}")
 
-	if len(p.Uniforms) > 0 {
-		lines = append(lines, "")
-		lines = append(lines, "cbuffer Uniforms : register(b0) {")
-		for i, t := range p.Uniforms {
-			// packingoffset is not mandatory, but this is useful to ensure the correct offset is used.
-			offset := fmt.Sprintf("c%d", offsets[i]/boundaryInBytes)
-			switch offsets[i] % boundaryInBytes {
-			case 4:
-				offset += ".y"
-			case 8:
-				offset += ".z"
-			case 12:
-				offset += ".w"
-			}
-			lines = append(lines, fmt.Sprintf("\t%s : packoffset(%s);", c.varDecl(p, &t, fmt.Sprintf("U%d", i)), offset))
-		}
-		lines = append(lines, "}")
-	}
+	lines = append(lines, c.uniformBuffer(p)...)
 
 	if p.TextureCount > 0 {
 		lines = append(lines, "")
@@ -141,7 +123,9 @@ func Compile(p *shaderir.Program) (vertexShader, pixelShader, prelude string) {
 		if c.unit == shaderir.Texels {
 			lines = append(lines, "SamplerState samp : register(s0);")
 		}
+		lines = append(lines, "SamplerState sampAnisotropic : register(s1);")
 	}
+	lines = append(lines, c.constArrays(p)...)
 
 	vslines := make([]string, len(lines))
 	copy(vslines, lines)
@@ -225,7 +209,19 @@ func Compile(p *shaderir.Program) (vertexShader, pixelShader, prelude string) {
 	}
 	if p.FragmentFunc.Block != nil && len(p.FragmentFunc.Block.Stmts) > 0 {
 		pslines = append(pslines, "")
-		pslines = append(pslines, fmt.Sprintf("float4 PSMain(Varyings %s) : SV_TARGET {", vsOut))
+		if p.FragmentFunc.OutputCount > 1 {
+			// A fragment entry point with multiple colors returns a struct.
+			// For dual-source blending, SV_Target1 is the secondary source color of the first render target.
+			pslines = append(pslines, "struct PSOutput {")
+			for i := 0; i < p.FragmentFunc.OutputCount; i++ {
+				pslines = append(pslines, fmt.Sprintf("\tfloat4 C%[1]d : SV_Target%[1]d;", i))
+			}
+			pslines = append(pslines, "};", "")
+			pslines = append(pslines, fmt.Sprintf("PSOutput PSMain(Varyings %s) {", vsOut))
+			pslines = append(pslines, fmt.Sprintf("\tPSOutput %s;", psOut))
+		} else {
+			pslines = append(pslines, fmt.Sprintf("float4 PSMain(Varyings %s) : SV_TARGET {", vsOut))
+		}
 		pslines = append(pslines, c.block(p, p.FragmentFunc.Block, p.FragmentFunc.Block, 0)...)
 		pslines = append(pslines, "}")
 	}
@@ -265,6 +261,120 @@ func Compile(p *shaderir.Program) (vertexShader, pixelShader, prelude string) {
 	return
 }
 
+// CompileCompute compiles a compute kernel.
+//
+// The i-th storage image is bound to the SRV slot i if the image is only read, or to the UAV slot i otherwise.
+func CompileCompute(p *shaderir.Program) (computeShader string) {
+	c := &compileContext{
+		unit: p.Unit,
+	}
+
+	var lines []string
+	lines = append(lines, strings.Split(utilFuncs, "\n")...)
+	lines = append(lines, "", "{{.Structs}}")
+	lines = append(lines, c.uniformBuffer(p)...)
+
+	if p.StorageImageCount > 0 || len(p.SharedVars) > 0 {
+		lines = append(lines, "")
+		read, write := p.StorageImageAccesses()
+		for i := 0; i < p.StorageImageCount; i++ {
+			// Loading from an RGBA8 UAV requires typed UAV loads. Use an SRV for an image that is only read.
+			if read[i] && !write[i] {
+				lines = append(lines, fmt.Sprintf("Texture2D<float4> I%[1]d : register(t%[1]d);", i))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("RWTexture2D<unorm float4> I%[1]d : register(u%[1]d);", i))
+		}
+		for i, t := range p.SharedVars {
+			lines = append(lines, fmt.Sprintf("groupshared %s;", c.varDecl(p, &t, fmt.Sprintf("W%d", i))))
+		}
+	}
+	lines = append(lines, c.constArrays(p)...)
+
+	var funcs []*shaderir.Func
+	if p.ComputeFunc.Block != nil {
+		funcs = p.ReachableFuncsFromBlock(p.ComputeFunc.Block)
+	} else {
+		// Use all the functions for testing.
+		funcs = make([]*shaderir.Func, 0, len(p.Funcs))
+		for _, f := range p.Funcs {
+			f := f
+			funcs = append(funcs, &f)
+		}
+	}
+	if len(funcs) > 0 {
+		lines = append(lines, "")
+		for _, f := range funcs {
+			lines = append(lines, c.function(p, f, true)...)
+		}
+		for _, f := range funcs {
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, c.function(p, f, false)...)
+		}
+	}
+	if p.ComputeFunc.Block != nil && len(p.ComputeFunc.Block.Stmts) > 0 {
+		size := p.ComputeFunc.WorkgroupSize
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("[numthreads(%d, %d, %d)]", size[0], size[1], size[2]))
+		lines = append(lines, "void CSMain(uint3 globalID : SV_DispatchThreadID, uint3 localID : SV_GroupThreadID, uint3 groupID : SV_GroupID) {")
+		lines = append(lines, c.block(p, p.ComputeFunc.Block, p.ComputeFunc.Block, 0)...)
+		lines = append(lines, "}")
+	}
+
+	computeShader = strings.Join(lines, "\n")
+
+	// Struct types are determined after converting the program.
+	if len(c.structTypes) > 0 {
+		var stlines []string
+		for i, t := range c.structTypes {
+			stlines = append(stlines, fmt.Sprintf("struct S%d {", i))
+			for j, st := range t.Sub {
+				stlines = append(stlines, fmt.Sprintf("\t%s;", c.varDecl(p, &st, fmt.Sprintf("M%d", j))))
+			}
+			stlines = append(stlines, "};")
+		}
+		computeShader = strings.ReplaceAll(computeShader, "{{.Structs}}", strings.Join(stlines, "\n"))
+	} else {
+		computeShader = strings.ReplaceAll(computeShader, "{{.Structs}}", "")
+	}
+
+	nls := regexp.MustCompile(`\n\n+`)
+	computeShader = nls.ReplaceAllString(computeShader, "\n\n")
+	computeShader = strings.TrimSpace(computeShader) + "\n"
+
+	return computeShader
+}
+
+// uniformBuffer returns the lines to declare the constant buffer for the uniform variables.
+func (c *compileContext) uniformBuffer(p *shaderir.Program) []string {
+	if len(p.Uniforms) == 0 {
+		return nil
+	}
+
+	offsets := CalcUniformMemoryOffsets(p)
+
+	var lines []string
+	lines = append(lines, "")
+	lines = append(lines, "cbuffer Uniforms : register(b0) {")
+	for i, t := range p.Uniforms {
+		// packingoffset is not mandatory, but this is useful to ensure the correct offset is used.
+		offset := fmt.Sprintf("c%d", offsets[i]/boundaryInBytes)
+		switch offsets[i] % boundaryInBytes {
+		case 4:
+			offset += ".y"
+		case 8:
+			offset += ".z"
+		case 12:
+			offset += ".w"
+		}
+		lines = append(lines, fmt.Sprintf("\t%s : packoffset(%s);", c.varDecl(p, &t, fmt.Sprintf("U%d", i)), offset))
+	}
+	lines = append(lines, "}")
+	return lines
+}
+
 func (c *compileContext) typ(p *shaderir.Program, t *shaderir.Type) (string, string) {
 	switch t.Main {
 	case shaderir.None:
@@ -288,6 +398,32 @@ func (c *compileContext) varDecl(p *shaderir.Program, t *shaderir.Type, varname
 	}
 }
 
+// constArrays returns the lines to declare the constant arrays.
+func (c *compileContext) constArrays(p *shaderir.Program) []string {
+	if len(p.ConstArrays) == 0 {
+		return nil
+	}
+	lines := []string{""}
+	for i := range p.ConstArrays {
+		a := &p.ConstArrays[i]
+		elms := make([]string, a.Type.Length)
+		for j := range elms {
+			vs := a.ElementValues(j)
+			if len(vs) == 1 {
+				elms[j] = constantToNumberLiteral(vs[0])
+				continue
+			}
+			strs := make([]string, len(vs))
+			for k, v := range vs {
+				strs[k] = constantToNumberLiteral(v)
+			}
+			elms[j] = fmt.Sprintf("%s(%s)", basicTypeString(a.Type.Sub[0].Main), strings.Join(strs, ", "))
+		}
+		lines = append(lines, fmt.Sprintf("static const %s = {%s};", c.varDecl(p, &a.Type, fmt.Sprintf("C%d", i)), strings.Join(elms, ", ")))
+	}
+	return lines
+}
+
 func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 	switch t.Main {
 	case shaderir.None:
@@ -390,6 +526,17 @@ func (c *compileContext) localVariableName(p *shaderir.Program, topBlock *shader
 		default:
 			return fmt.Sprintf("l%d", idx-(nv+1))
 		}
+	case p.ComputeFunc.Block:
+		switch idx {
+		case 0:
+			return "int3(globalID)"
+		case 1:
+			return "int3(localID)"
+		case 2:
+			return "int3(groupID)"
+		default:
+			return fmt.Sprintf("l%d", idx-3)
+		}
 	default:
 		return fmt.Sprintf("l%d", idx)
 	}
@@ -441,6 +588,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			return fmt.Sprintf("U%d", e.Index)
 		case shaderir.TextureVariable:
 			return fmt.Sprintf("T%d", e.Index)
+		case shaderir.SharedVariable:
+			return fmt.Sprintf("W%d", e.Index)
+		case shaderir.ConstArrayVariable:
+			return fmt.Sprintf("C%d", e.Index)
+		case shaderir.StorageImageVariable:
+			return fmt.Sprintf("I%d", e.Index)
 		case shaderir.LocalVariable:
 			return c.localVariableName(p, topBlock, e.Index)
 		case shaderir.StructMember:
@@ -457,7 +610,7 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 		case shaderir.Unary:
 			var op string
 			switch e.Op {
-			case shaderir.Add, shaderir.Sub, shaderir.NotOp:
+			case shaderir.Add, shaderir.Sub, shaderir.NotOp, shaderir.ComplementOp:
 				op = opString(e.Op)
 			default:
 				op = fmt.Sprintf("?(unexpected op: %d)", e.Op)
@@ -513,6 +666,24 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 					default:
 						panic(fmt.Sprintf("hlsl: unexpected unit: %d", p.Unit))
 					}
+				case shaderir.TexelAtLod:
+					switch c.unit {
+					case shaderir.Pixels:
+						// In the pixel mode, a texture is fetched without a sampler, and the level of detail is not used.
+						return fmt.Sprintf("%s.Load(int3(%s, 0))", args[0], args[1])
+					case shaderir.Texels:
+						return fmt.Sprintf("%s.SampleLevel(samp, %s, %s)", args[0], args[1], args[2])
+					default:
+						panic(fmt.Sprintf("hlsl: unexpected unit: %d", p.Unit))
+					}
+				case shaderir.TexelFetch:
+					return fmt.Sprintf("%s.Load(int3(%s, 0))", args[0], args[1])
+				case shaderir.TexelAtAnisotropic:
+					return fmt.Sprintf("%s.Sample(sampAnisotropic, %s)", args[0], args[1])
+				case shaderir.ImageLoad:
+					return fmt.Sprintf("%s[uint2(%s)]", args[0], args[1])
+				case shaderir.ImageStore:
+					return fmt.Sprintf("%s[uint2(%s)] = %s", args[0], args[1], args[2])
 				}
 			}
 			return fmt.Sprintf("%s(%s)", expr(&e.Exprs[0]), strings.Join(args, ", "))
@@ -556,6 +727,26 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				lines = append(lines, c.block(p, topBlock, s.Blocks[1], level+1)...)
 			}
 			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.Switch:
+			lines = append(lines, fmt.Sprintf("%sswitch (%s) {", idt, expr(&s.Exprs[0])))
+			for i, b := range s.Blocks {
+				vals := s.SwitchCases[i]
+				if vals == nil {
+					lines = append(lines, fmt.Sprintf("%sdefault: {", idt))
+				} else {
+					for j, v := range vals {
+						if j < len(vals)-1 {
+							lines = append(lines, fmt.Sprintf("%scase %s:", idt, constantToNumberLiteral(v)))
+							continue
+						}
+						lines = append(lines, fmt.Sprintf("%scase %s: {", idt, constantToNumberLiteral(v)))
+					}
+				}
+				lines = append(lines, c.block(p, topBlock, b, level+1)...)
+				lines = append(lines, fmt.Sprintf("%s\tbreak;", idt))
+				lines = append(lines, fmt.Sprintf("%s}", idt))
+			}
+			lines = append(lines, fmt.Sprintf("%s}", idt))
 		case shaderir.For:
 			v := c.localVariableName(p, topBlock, s.ForVarIndex)
 			var delta string
@@ -585,7 +776,14 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 
 			t := s.ForVarType
 			init := constantToNumberLiteral(s.ForInit)
-			end := constantToNumberLiteral(s.ForEnd)
+			var end string
+			if s.ForEnd != nil {
+				end = constantToNumberLiteral(s.ForEnd)
+			} else {
+				end = expr(&s.Exprs[0])
+				// A loop with a non-constant bound cannot be unrolled.
+				lines = append(lines, idt+"[loop]")
+			}
 			t0, t1 := typeString(&t)
 			lines = append(lines, fmt.Sprintf("%sfor (%s %s%s = %s; %s %s %s; %s) {", idt, t0, v, t1, init, v, op, end, delta))
 			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
@@ -598,6 +796,11 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			switch {
 			case topBlock == p.VertexFunc.Block:
 				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, vsOut))
+			case topBlock == p.FragmentFunc.Block && len(s.Exprs) > 1:
+				for i := range s.Exprs {
+					lines = append(lines, fmt.Sprintf("%s%s.C%d = %s;", idt, psOut, i, expr(&s.Exprs[i])))
+				}
+				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, psOut))
 			case len(s.Exprs) == 0:
 				lines = append(lines, idt+"return;")
 			default:
@@ -605,7 +808,11 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			}
 		case shaderir.Discard:
 			// 'discard' is invoked only in the fragment shader entry point.
-			lines = append(lines, idt+"discard;", idt+"return float4(0.0, 0.0, 0.0, 0.0);")
+			if p.FragmentFunc.OutputCount > 1 {
+				lines = append(lines, idt+"discard;", idt+"return (PSOutput)0;")
+			} else {
+				lines = append(lines, idt+"discard;", idt+"return float4(0.0, 0.0, 0.0, 0.0);")
+			}
 		default:
 			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
 		}
@@ -60,6 +60,8 @@ func opString(op shaderir.Op) string {
 		return "&&"
 	case shaderir.OrOr:
 		return "||"
+	case shaderir.ComplementOp:
+		return "~"
 	}
 	return fmt.Sprintf("?(unexpected operator: %d)", op)
 }
@@ -145,6 +147,14 @@ func (c *compileContext) builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "ddy"
 	case shaderir.TexelAt:
 		return "?(__texelAt)"
+	case shaderir.TexelAtLod:
+		return "?(__texelAtLod)"
+	case shaderir.TexelFetch:
+		return "?(__texelFetch)"
+	case shaderir.TexelAtAnisotropic:
+		return "?(__texelAtAnisotropic)"
+	case shaderir.Barrier:
+		return "GroupMemoryBarrierWithGroupSync"
 	default:
 		return string(f)
 	}
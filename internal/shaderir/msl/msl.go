@@ -26,7 +26,8 @@ import (
 )
 
 const (
-	vertexOut = "varyings"
+	vertexOut   = "varyings"
+	fragmentOut = "FragmentOut"
 )
 
 type compileContext struct {
@@ -62,12 +63,16 @@ T mod(T x, U y) {
 
 constexpr sampler texture_sampler{filter::nearest};`
 	}
+	str += fmt.Sprintf(`
+
+constexpr sampler texture_sampler_anisotropic(filter::linear, address::clamp_to_edge, max_anisotropy(%d));`, shaderir.MaxAnisotropy)
 	return str
 }
 
 const (
 	VertexName   = "Vertex"
 	FragmentName = "Fragment"
+	ComputeName  = "Compute"
 )
 
 func Compile(p *shaderir.Program) (shader string) {
@@ -78,6 +83,7 @@ func Compile(p *shaderir.Program) (shader string) {
 	var lines []string
 	lines = append(lines, strings.Split(Prelude(p.Unit), "\n")...)
 	lines = append(lines, "", "{{.Structs}}")
+	lines = append(lines, c.constArrays(p)...)
 
 	if len(p.Attributes) > 0 {
 		lines = append(lines, "")
@@ -136,8 +142,23 @@ func Compile(p *shaderir.Program) (shader string) {
 
 	if p.FragmentFunc.Block != nil && len(p.FragmentFunc.Block.Stmts) > 0 {
 		lines = append(lines, "")
+		outType := "float4"
+		if p.FragmentFunc.OutputCount > 1 {
+			// A fragment entry point with multiple colors returns a struct.
+			// For dual-source blending, the colors are the primary and the secondary source colors of the first render target.
+			outType = fragmentOut
+			lines = append(lines, fmt.Sprintf("struct %s {", fragmentOut))
+			for i := 0; i < p.FragmentFunc.OutputCount; i++ {
+				if p.FragmentFunc.MultipleRenderTargets {
+					lines = append(lines, fmt.Sprintf("\tfloat4 C%[1]d [[color(%[1]d)]];", i))
+				} else {
+					lines = append(lines, fmt.Sprintf("\tfloat4 C%[1]d [[color(0), index(%[1]d)]];", i))
+				}
+			}
+			lines = append(lines, "};", "")
+		}
 		lines = append(lines,
-			fmt.Sprintf("fragment float4 %s(", FragmentName),
+			fmt.Sprintf("fragment %s %s(", outType, FragmentName),
 			"\tVaryings varyings [[stage_in]]")
 		for i, u := range p.Uniforms {
 			lines[len(lines)-1] += ","
@@ -176,6 +197,101 @@ func Compile(p *shaderir.Program) (shader string) {
 	return ls
 }
 
+// CompileCompute compiles a compute kernel.
+//
+// In Metal, the workgroup (threadgroup) size is not a part of a kernel and must be specified when dispatching.
+// The i-th storage image is bound to the texture index i.
+func CompileCompute(p *shaderir.Program) (shader string) {
+	c := &compileContext{
+		structNames: map[string]string{},
+	}
+
+	var lines []string
+	lines = append(lines, strings.Split(Prelude(p.Unit), "\n")...)
+	lines = append(lines, "", "{{.Structs}}")
+	lines = append(lines, c.constArrays(p)...)
+
+	var funcs []*shaderir.Func
+	if p.ComputeFunc.Block != nil {
+		funcs = p.ReachableFuncsFromBlock(p.ComputeFunc.Block)
+	} else {
+		// When a compute entry point is not defined, allow to put all the functions. This is useful for testing.
+		funcs = make([]*shaderir.Func, 0, len(p.Funcs))
+		for _, f := range p.Funcs {
+			f := f
+			funcs = append(funcs, &f)
+		}
+	}
+	if len(funcs) > 0 {
+		lines = append(lines, "")
+		for _, f := range funcs {
+			lines = append(lines, c.function(p, f, true)...)
+		}
+		for _, f := range funcs {
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, c.function(p, f, false)...)
+		}
+	}
+
+	if p.ComputeFunc.Block != nil && len(p.ComputeFunc.Block.Stmts) > 0 {
+		lines = append(lines, "")
+		lines = append(lines,
+			fmt.Sprintf("kernel void %s(", ComputeName),
+			"\tuint3 globalID [[thread_position_in_grid]],",
+			"\tuint3 localID [[thread_position_in_threadgroup]],",
+			"\tuint3 groupID [[threadgroup_position_in_grid]]")
+		for i, u := range p.Uniforms {
+			lines[len(lines)-1] += ","
+			lines = append(lines, fmt.Sprintf("\tconstant %s [[buffer(%d)]]", c.varDecl(p, &u, fmt.Sprintf("U%d", i), true), i))
+		}
+		read, write := p.StorageImageAccesses()
+		for i := 0; i < p.StorageImageCount; i++ {
+			// access::read_write requires read-write texture tier 2 for RGBA8. Avoid this whenever possible.
+			access := "read_write"
+			switch {
+			case read[i] && !write[i]:
+				access = "read"
+			case !read[i] && write[i]:
+				access = "write"
+			}
+			lines[len(lines)-1] += ","
+			lines = append(lines, fmt.Sprintf("\ttexture2d<float, access::%[2]s> I%[1]d [[texture(%[1]d)]]", i, access))
+		}
+		lines[len(lines)-1] += ") {"
+		// In Metal, threadgroup variables must be declared in a kernel function.
+		for i, t := range p.SharedVars {
+			lines = append(lines, fmt.Sprintf("\tthreadgroup %s;", c.varDecl(p, &t, fmt.Sprintf("W%d", i), false)))
+		}
+		lines = append(lines, c.block(p, p.ComputeFunc.Block, p.ComputeFunc.Block, 0)...)
+		lines = append(lines, "}")
+	}
+
+	ls := strings.Join(lines, "\n")
+
+	// Struct types are determined after converting the program.
+	if len(c.structTypes) > 0 {
+		var stlines []string
+		for i, t := range c.structTypes {
+			stlines = append(stlines, fmt.Sprintf("struct S%d {", i))
+			for j, st := range t.Sub {
+				stlines = append(stlines, fmt.Sprintf("\t%s;", c.varDecl(p, &st, fmt.Sprintf("M%d", j), false)))
+			}
+			stlines = append(stlines, "};")
+		}
+		ls = strings.ReplaceAll(ls, "{{.Structs}}", strings.Join(stlines, "\n"))
+	} else {
+		ls = strings.ReplaceAll(ls, "{{.Structs}}", "")
+	}
+
+	nls := regexp.MustCompile(`\n\n+`)
+	ls = nls.ReplaceAllString(ls, "\n\n")
+	ls = strings.TrimSpace(ls) + "\n"
+
+	return ls
+}
+
 func (c *compileContext) typ(p *shaderir.Program, t *shaderir.Type) string {
 	switch t.Main {
 	case shaderir.None:
@@ -203,6 +319,33 @@ func (c *compileContext) varDecl(p *shaderir.Program, t *shaderir.Type, varname
 	}
 }
 
+// constArrays returns the lines to declare the constant arrays.
+// In Metal, a program scope variable must be in the constant address space.
+func (c *compileContext) constArrays(p *shaderir.Program) []string {
+	if len(p.ConstArrays) == 0 {
+		return nil
+	}
+	lines := []string{""}
+	for i := range p.ConstArrays {
+		a := &p.ConstArrays[i]
+		elms := make([]string, a.Type.Length)
+		for j := range elms {
+			vs := a.ElementValues(j)
+			if len(vs) == 1 {
+				elms[j] = constantToNumberLiteral(vs[0])
+				continue
+			}
+			strs := make([]string, len(vs))
+			for k, v := range vs {
+				strs[k] = constantToNumberLiteral(v)
+			}
+			elms[j] = fmt.Sprintf("%s(%s)", basicTypeString(a.Type.Sub[0].Main), strings.Join(strs, ", "))
+		}
+		lines = append(lines, fmt.Sprintf("constant %s = {%s};", c.varDecl(p, &a.Type, fmt.Sprintf("C%d", i), false), strings.Join(elms, ", ")))
+	}
+	return lines
+}
+
 func (c *compileContext) varInit(p *shaderir.Program, t *shaderir.Type) string {
 	switch t.Main {
 	case shaderir.None:
@@ -235,6 +378,12 @@ func (c *compileContext) function(p *shaderir.Program, f *shaderir.Func, prototy
 	for i := 0; i < p.TextureCount; i++ {
 		args = append(args, fmt.Sprintf("texture2d<float> T%d", i))
 	}
+	for i := 0; i < p.StorageImageCount; i++ {
+		args = append(args, fmt.Sprintf("texture2d<float, access::read_write> I%d", i))
+	}
+	for i, t := range p.SharedVars {
+		args = append(args, "threadgroup "+c.varDecl(p, &t, fmt.Sprintf("W%d", i), true))
+	}
 
 	var idx int
 	for _, t := range f.InParams {
@@ -310,6 +459,17 @@ func localVariableName(p *shaderir.Program, topBlock *shaderir.Block, idx int) s
 		default:
 			return fmt.Sprintf("l%d", idx-(nv+1))
 		}
+	case p.ComputeFunc.Block:
+		switch idx {
+		case 0:
+			return "int3(globalID)"
+		case 1:
+			return "int3(localID)"
+		case 2:
+			return "int3(groupID)"
+		default:
+			return fmt.Sprintf("l%d", idx-3)
+		}
 	default:
 		return fmt.Sprintf("l%d", idx)
 	}
@@ -353,6 +513,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			return fmt.Sprintf("U%d", e.Index)
 		case shaderir.TextureVariable:
 			return fmt.Sprintf("T%d", e.Index)
+		case shaderir.SharedVariable:
+			return fmt.Sprintf("W%d", e.Index)
+		case shaderir.ConstArrayVariable:
+			return fmt.Sprintf("C%d", e.Index)
+		case shaderir.StorageImageVariable:
+			return fmt.Sprintf("I%d", e.Index)
 		case shaderir.LocalVariable:
 			return localVariableName(p, topBlock, e.Index)
 		case shaderir.StructMember:
@@ -369,7 +535,7 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 		case shaderir.Unary:
 			var op string
 			switch e.Op {
-			case shaderir.Add, shaderir.Sub, shaderir.NotOp:
+			case shaderir.Add, shaderir.Sub, shaderir.NotOp, shaderir.ComplementOp:
 				op = opString(e.Op)
 			default:
 				op = fmt.Sprintf("?(unexpected op: %d)", e.Op)
@@ -395,6 +561,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				for i := 0; i < p.TextureCount; i++ {
 					args = append(args, fmt.Sprintf("T%d", i))
 				}
+				for i := 0; i < p.StorageImageCount; i++ {
+					args = append(args, fmt.Sprintf("I%d", i))
+				}
+				for i := range p.SharedVars {
+					args = append(args, fmt.Sprintf("W%d", i))
+				}
 			}
 			for _, exp := range e.Exprs[1:] {
 				args = append(args, expr(&exp))
@@ -409,6 +581,30 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 					panic(fmt.Sprintf("msl: unexpected unit: %d", p.Unit))
 				}
 			}
+			if callee.Type == shaderir.BuiltinFuncExpr {
+				switch callee.BuiltinFunc {
+				case shaderir.TexelAtLod:
+					switch p.Unit {
+					case shaderir.Texels:
+						return fmt.Sprintf("%s.sample(texture_sampler, %s, level(%s))", args[0], args[1], args[2])
+					case shaderir.Pixels:
+						// In the pixel mode, a texture is fetched without a sampler, and the level of detail is not used.
+						return fmt.Sprintf("%s.read(static_cast<uint2>(%s))", args[0], args[1])
+					default:
+						panic(fmt.Sprintf("msl: unexpected unit: %d", p.Unit))
+					}
+				case shaderir.TexelFetch:
+					return fmt.Sprintf("%s.read(static_cast<uint2>(%s))", args[0], args[1])
+				case shaderir.TexelAtAnisotropic:
+					return fmt.Sprintf("%s.sample(texture_sampler_anisotropic, %s)", args[0], args[1])
+				case shaderir.ImageLoad:
+					return fmt.Sprintf("%s.read(static_cast<uint2>(%s))", args[0], args[1])
+				case shaderir.ImageStore:
+					return fmt.Sprintf("%s.write(%s, static_cast<uint2>(%s))", args[0], args[2], args[1])
+				case shaderir.Barrier:
+					return "threadgroup_barrier(mem_flags::mem_threadgroup)"
+				}
+			}
 			return fmt.Sprintf("%s(%s)", expr(&callee), strings.Join(args, ", "))
 		case shaderir.FieldSelector:
 			return fmt.Sprintf("(%s).%s", expr(&e.Exprs[0]), expr(&e.Exprs[1]))
@@ -451,6 +647,26 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 				lines = append(lines, c.block(p, topBlock, s.Blocks[1], level+1)...)
 			}
 			lines = append(lines, fmt.Sprintf("%s}", idt))
+		case shaderir.Switch:
+			lines = append(lines, fmt.Sprintf("%sswitch (%s) {", idt, expr(&s.Exprs[0])))
+			for i, b := range s.Blocks {
+				vals := s.SwitchCases[i]
+				if vals == nil {
+					lines = append(lines, fmt.Sprintf("%sdefault: {", idt))
+				} else {
+					for j, v := range vals {
+						if j < len(vals)-1 {
+							lines = append(lines, fmt.Sprintf("%scase %s:", idt, constantToNumberLiteral(v)))
+							continue
+						}
+						lines = append(lines, fmt.Sprintf("%scase %s: {", idt, constantToNumberLiteral(v)))
+					}
+				}
+				lines = append(lines, c.block(p, topBlock, b, level+1)...)
+				lines = append(lines, fmt.Sprintf("%s\tbreak;", idt))
+				lines = append(lines, fmt.Sprintf("%s}", idt))
+			}
+			lines = append(lines, fmt.Sprintf("%s}", idt))
 		case shaderir.For:
 			v := localVariableName(p, topBlock, s.ForVarIndex)
 			var delta string
@@ -480,7 +696,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 
 			t := s.ForVarType
 			init := constantToNumberLiteral(s.ForInit)
-			end := constantToNumberLiteral(s.ForEnd)
+			var end string
+			if s.ForEnd != nil {
+				end = constantToNumberLiteral(s.ForEnd)
+			} else {
+				end = expr(&s.Exprs[0])
+			}
 			ts := typeString(&t, false)
 			lines = append(lines, fmt.Sprintf("%sfor (%s %s = %s; %s %s %s; %s) {", idt, ts, v, init, v, op, end, delta))
 			lines = append(lines, c.block(p, topBlock, s.Blocks[0], level+1)...)
@@ -493,6 +714,12 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			switch {
 			case topBlock == p.VertexFunc.Block:
 				lines = append(lines, fmt.Sprintf("%sreturn %s;", idt, vertexOut))
+			case topBlock == p.FragmentFunc.Block && len(s.Exprs) > 1:
+				var exprs []string
+				for i := range s.Exprs {
+					exprs = append(exprs, expr(&s.Exprs[i]))
+				}
+				lines = append(lines, fmt.Sprintf("%sreturn %s{%s};", idt, fragmentOut, strings.Join(exprs, ", ")))
 			case len(s.Exprs) == 0:
 				lines = append(lines, idt+"return;")
 			default:
@@ -500,7 +727,11 @@ func (c *compileContext) block(p *shaderir.Program, topBlock, block *shaderir.Bl
 			}
 		case shaderir.Discard:
 			// 'discard' is invoked only in the fragment shader entry point.
-			lines = append(lines, idt+"discard_fragment();", idt+"return float4(0.0);")
+			if p.FragmentFunc.OutputCount > 1 {
+				lines = append(lines, idt+"discard_fragment();", fmt.Sprintf("%sreturn %s{};", idt, fragmentOut))
+			} else {
+				lines = append(lines, idt+"discard_fragment();", idt+"return float4(0.0);")
+			}
 		default:
 			lines = append(lines, fmt.Sprintf("%s?(unexpected stmt: %d)", idt, s.Type))
 		}
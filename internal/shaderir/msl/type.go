@@ -60,6 +60,8 @@ func opString(op shaderir.Op) string {
 		return "&&"
 	case shaderir.OrOr:
 		return "||"
+	case shaderir.ComplementOp:
+		return "~"
 	}
 	return fmt.Sprintf("?(unexpected operator: %d)", op)
 }
@@ -151,6 +153,12 @@ func builtinFuncString(f shaderir.BuiltinFunc) string {
 		return "rsqrt"
 	case shaderir.TexelAt:
 		return "?(__texelAt)"
+	case shaderir.TexelAtLod:
+		return "?(__texelAtLod)"
+	case shaderir.TexelFetch:
+		return "?(__texelFetch)"
+	case shaderir.TexelAtAnisotropic:
+		return "?(__texelAtAnisotropic)"
 	}
 	return string(f)
 }
@@ -17,6 +17,7 @@ package shaderir
 
 import (
 	"encoding/hex"
+	"fmt"
 	"go/constant"
 	"go/token"
 	"hash/fnv"
@@ -46,6 +47,30 @@ func (s SourceHash) String() string {
 	return hex.EncodeToString(s[:])
 }
 
+// DebugCall represents a Debugf call in a shader.
+type DebugCall struct {
+	// Format is the format string.
+	Format string
+
+	// ArgTypes is the types of the arguments except for the format.
+	ArgTypes []Type
+}
+
+// ConstArray is a read-only global array initialized with constant values.
+type ConstArray struct {
+	Type Type
+
+	// Values is the values of the elements.
+	// If the element type is a vector, Values is the components of the elements in order.
+	Values []constant.Value
+}
+
+// ElementValues returns the values of the index-th element.
+func (c *ConstArray) ElementValues(index int) []constant.Value {
+	n := len(c.Values) / c.Type.Length
+	return c.Values[index*n : (index+1)*n]
+}
+
 type Program struct {
 	UniformNames []string
 	Uniforms     []Type
@@ -57,6 +82,26 @@ type Program struct {
 	FragmentFunc FragmentFunc
 	Unit         Unit
 
+	// ComputeFunc is the entry point of a compute kernel.
+	// A program has either ComputeFunc or VertexFunc and FragmentFunc.
+	ComputeFunc ComputeFunc
+
+	// StorageImageCount is the number of the storage images a compute kernel reads and writes.
+	StorageImageCount int
+
+	// SharedVars is the variables shared in a workgroup of a compute kernel.
+	SharedVars []Type
+
+	// ConstArrays is the read-only global arrays initialized with constant values like lookup tables.
+	ConstArrays []ConstArray
+
+	// RuntimeSizedUniforms is the indices of the uniform variables declared as arrays without lengths.
+	// Such an array has the length given at the compilation, or 1 if the given length is 0.
+	RuntimeSizedUniforms []int
+
+	// DebugCalls is the Debugf calls in the fragment entry point in the order of the indices.
+	DebugCalls []DebugCall
+
 	SourceHash SourceHash
 
 	uniformFactors []uint32
@@ -77,13 +122,82 @@ type Func struct {
 // varying variables.
 type VertexFunc struct {
 	Block *Block
+
+	// TransformsPosition reports whether the vertex func transforms the positions with a user function.
+	// If TransformsPosition is true, the region to render cannot be determined from the vertices.
+	TransformsPosition bool
 }
 
 // FragmentFunc takes pseudo params, and the number is len(varyings) + 2.
 // If index == 0, the param represents the coordinate of the fragment (gl_FragCoord in GLSL).
 // If 0 < index <= len(varyings), the param represents (index-1)th varying variable.
+//
+// If the fragment func returns multiple colors, Return statements in the block have the same number of expressions.
 type FragmentFunc struct {
 	Block *Block
+
+	// OutputCount is the number of the colors the fragment func returns.
+	// If OutputCount is 0 or 1, the fragment func returns one color.
+	// If OutputCount is 2 and MultipleRenderTargets is false, the second color is the secondary source color for dual-source blending.
+	OutputCount int
+
+	// MultipleRenderTargets reports whether the colors the fragment func returns are rendered to multiple render targets.
+	// The i-th color is rendered to the i-th render target.
+	MultipleRenderTargets bool
+}
+
+// ComputeFunc takes pseudo params, and the number is 3.
+// If index == 0, the param represents the global invocation ID in ivec3 (gl_GlobalInvocationID in GLSL).
+// If index == 1, the param represents the local invocation ID in the workgroup in ivec3 (gl_LocalInvocationID in GLSL).
+// If index == 2, the param represents the workgroup ID in ivec3 (gl_WorkGroupID in GLSL).
+type ComputeFunc struct {
+	Block *Block
+
+	// WorkgroupSize is the number of the invocations in a workgroup for each dimension.
+	WorkgroupSize [3]int
+}
+
+// IsCompute reports whether the program is a compute kernel.
+func (p *Program) IsCompute() bool {
+	return p.ComputeFunc.Block != nil
+}
+
+// FragmentOutputProgram returns a new program whose fragment func returns only the index-th color of p's fragment func.
+//
+// FragmentOutputProgram is used to render multiple render targets one by one.
+func (p *Program) FragmentOutputProgram(index int) *Program {
+	if index < 0 || index >= p.FragmentFunc.OutputCount {
+		panic(fmt.Sprintf("shaderir: index must be in [0, %d) but %d", p.FragmentFunc.OutputCount, index))
+	}
+
+	newP := *p
+	newP.FragmentFunc = FragmentFunc{
+		Block: selectReturnExpr(p.FragmentFunc.Block, index),
+	}
+	// The program doesn't correspond to the source as it is.
+	newP.SourceHash = SourceHash{}
+	newP.uniformFactors = nil
+	return &newP
+}
+
+// selectReturnExpr returns a copy of the block whose return statements return only the index-th expression.
+func selectReturnExpr(block *Block, index int) *Block {
+	newB := *block
+	newB.Stmts = make([]Stmt, len(block.Stmts))
+	for i, s := range block.Stmts {
+		if s.Type == Return && len(s.Exprs) > index {
+			s.Exprs = []Expr{s.Exprs[index]}
+		}
+		if len(s.Blocks) > 0 {
+			blocks := make([]*Block, len(s.Blocks))
+			for j, b := range s.Blocks {
+				blocks[j] = selectReturnExpr(b, index)
+			}
+			s.Blocks = blocks
+		}
+		newB.Stmts[i] = s
+	}
+	return &newB
 }
 
 type Block struct {
@@ -99,10 +213,20 @@ type Stmt struct {
 	ForVarType  Type
 	ForVarIndex int
 	ForInit     constant.Value
-	ForEnd      constant.Value
-	ForOp       Op
-	ForDelta    constant.Value
-	InitIndex   int
+
+	// ForEnd is the constant bound of a for-loop.
+	// If ForEnd is nil, the bound is not a constant and Exprs[0] is the bound expression.
+	// The bound expression is evaluated at every iteration.
+	ForEnd constant.Value
+
+	ForOp     Op
+	ForDelta  constant.Value
+	InitIndex int
+
+	// SwitchCases is the integer constant values for each case clause of a switch statement.
+	// SwitchCases[i] is for Blocks[i]. nil represents the default clause.
+	// Exprs[0] is the tag. A case clause doesn't fall through to the next clause.
+	SwitchCases [][]constant.Value
 }
 
 type StmtType int
@@ -118,6 +242,7 @@ const (
 	Break
 	Return
 	Discard
+	Switch
 )
 
 type Expr struct {
@@ -148,6 +273,9 @@ const (
 	Call
 	FieldSelector
 	Index
+	SharedVariable
+	StorageImageVariable
+	ConstArrayVariable
 )
 
 type Op int
@@ -175,6 +303,9 @@ const (
 	Or
 	AndAnd
 	OrOr
+
+	// ComplementOp is a unary bitwise complement operator for integers like ^x in Go.
+	ComplementOp
 )
 
 func OpFromToken(t token.Token, lhs, rhs Type) (Op, bool) {
@@ -293,6 +424,15 @@ const (
 	Fwidth      BuiltinFunc = "fwidth"
 	DiscardF    BuiltinFunc = "discard"
 	TexelAt     BuiltinFunc = "__texelAt"
+	TexelAtLod  BuiltinFunc = "__texelAtLod"
+	TexelFetch  BuiltinFunc = "__texelFetch"
+	ImageLoad   BuiltinFunc = "imageLoad"
+	ImageStore  BuiltinFunc = "imageStore"
+	Barrier     BuiltinFunc = "barrier"
+
+	// TexelAtAnisotropic samples a texture with the anisotropic filtering of the graphics driver.
+	// The position is always in texels regardless of the unit.
+	TexelAtAnisotropic BuiltinFunc = "__texelAtAnisotropic"
 )
 
 func ParseBuiltinFunc(str string) (BuiltinFunc, bool) {
@@ -350,7 +490,13 @@ func ParseBuiltinFunc(str string) (BuiltinFunc, bool) {
 		Dfdy,
 		Fwidth,
 		DiscardF,
-		TexelAt:
+		TexelAt,
+		TexelAtLod,
+		TexelFetch,
+		TexelAtAnisotropic,
+		ImageLoad,
+		ImageStore,
+		Barrier:
 		return BuiltinFunc(str), true
 	}
 	return "", false
@@ -449,6 +595,52 @@ func walkExprsInExpr(f func(expr *Expr), expr *Expr) {
 	}
 }
 
+// MaxAnisotropy is the maximum anisotropy of the sampler for TexelAtAnisotropic.
+const MaxAnisotropy = 16
+
+// AnisotropicTextures reports whether each texture is sampled by TexelAtAnisotropic.
+func (p *Program) AnisotropicTextures() []bool {
+	textures := make([]bool, p.TextureCount)
+	f := func(expr *Expr) {
+		if expr.Type != Call || expr.Exprs[0].Type != BuiltinFuncExpr {
+			return
+		}
+		if expr.Exprs[0].BuiltinFunc != TexelAtAnisotropic {
+			return
+		}
+		if expr.Exprs[1].Type != TextureVariable {
+			return
+		}
+		textures[expr.Exprs[1].Index] = true
+	}
+	for _, fn := range p.Funcs {
+		walkExprs(f, fn.Block)
+	}
+	walkExprs(f, p.FragmentFunc.Block)
+	return textures
+}
+
+// StorageImageAccesses reports whether each storage image is read by imageLoad and written by imageStore.
+func (p *Program) StorageImageAccesses() (read, write []bool) {
+	read = make([]bool, p.StorageImageCount)
+	write = make([]bool, p.StorageImageCount)
+	f := func(expr *Expr) {
+		if expr.Type != Call || expr.Exprs[0].Type != BuiltinFuncExpr {
+			return
+		}
+		switch expr.Exprs[0].BuiltinFunc {
+		case ImageLoad:
+			read[expr.Exprs[1].Index] = true
+		case ImageStore:
+			write[expr.Exprs[1].Index] = true
+		}
+	}
+	for _, fn := range p.Funcs {
+		walkExprs(f, fn.Block)
+	}
+	return read, write
+}
+
 func (p *Program) appendReachableUniformVariablesFromBlock(indices []int, block *Block) []int {
 	indexToFunc := map[int]*Func{}
 	for _, f := range p.Funcs {
@@ -486,6 +678,7 @@ func (p *Program) FilterUniformVariables(uniforms []uint32) {
 	if p.uniformFactors == nil {
 		indices := p.appendReachableUniformVariablesFromBlock(nil, p.VertexFunc.Block)
 		indices = p.appendReachableUniformVariablesFromBlock(indices, p.FragmentFunc.Block)
+		indices = p.appendReachableUniformVariablesFromBlock(indices, p.ComputeFunc.Block)
 		reachableUniforms := make([]bool, len(p.Uniforms))
 		for _, idx := range indices {
 			reachableUniforms[idx] = true
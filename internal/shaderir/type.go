@@ -241,6 +241,13 @@ func (p *Program) LocalVariableType(topBlock, block *Block, idx int) Type {
 		default:
 			return localVariableType(p, topBlock, block, idx-(nv+1))
 		}
+	case p.ComputeFunc.Block:
+		switch {
+		case idx < 3:
+			return Type{Main: IVec3}
+		default:
+			return localVariableType(p, topBlock, block, idx-3)
+		}
 	default:
 		return localVariableType(p, topBlock, block, idx)
 	}
@@ -15,12 +15,15 @@
 package ui
 
 import (
+	stdcontext "context"
+	"image"
 	"math"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
 	"github.com/hajimehoshi/ebiten/v2/internal/clock"
 	"github.com/hajimehoshi/ebiten/v2/internal/debug"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
 	"github.com/hajimehoshi/ebiten/v2/internal/hook"
 )
@@ -36,14 +39,20 @@ type Game interface {
 	Layout(outsideWidth, outsideHeight float64) (screenWidth, screenHeight float64)
 	UpdateInputState(fn func(*InputState))
 	Update() error
-	DrawOffscreen() error
+	CommitDrawState()
+	DamagedRegion() (image.Rectangle, bool)
+	DrawOffscreen(region image.Rectangle) error
 	DrawFinalScreen(scale, offsetX, offsetY float64)
 }
 
 type context struct {
 	game Game
 
-	updateCalled bool
+	updateCalled    bool
+	pipelinedUpdate bool
+
+	// integerScale indicates whether the offscreen is scaled by an integer scale onto the screen when possible.
+	integerScale bool
 
 	offscreen *Image
 	screen    *Image
@@ -56,15 +65,20 @@ type context struct {
 	isOffscreenModified bool
 	lastDrawTime        time.Time
 
+	// offscreenRecreated indicates whether the offscreen is newly created and needs to be redrawn entirely.
+	offscreenRecreated bool
+
 	skipCount int
 
 	funcsInFrameCh chan func()
 }
 
-func newContext(game Game) *context {
+func newContext(game Game, pipelinedUpdate bool, integerScale bool) *context {
 	return &context{
-		game:           game,
-		funcsInFrameCh: make(chan func()),
+		game:            game,
+		pipelinedUpdate: pipelinedUpdate,
+		integerScale:    integerScale,
+		funcsInFrameCh:  make(chan func()),
 	}
 }
 
@@ -97,17 +111,25 @@ func (c *context) updateFrameImpl(graphicsDriver graphicsdriver.Graphics, update
 
 	debug.FrameLogf("----\n")
 
-	if err := atlas.BeginFrame(graphicsDriver); err != nil {
+	// Attribute the time to the phases of the game loop for runtime/trace and pprof.
+	ctx, endFrameTask := debug.StartFrameTask(stdcontext.Background())
+	defer endFrameTask()
+
+	if err := debug.Do(ctx, debug.PhaseAtlas, func() error {
+		return atlas.BeginFrame(graphicsDriver)
+	}); err != nil {
 		return err
 	}
 
 	defer func() {
-		if err1 := atlas.EndFrame(); err1 != nil && err == nil {
+		if err1 := debug.Do(ctx, debug.PhaseAtlas, atlas.EndFrame); err1 != nil && err == nil {
 			err = err1
 			return
 		}
 
-		if err1 := atlas.SwapBuffers(graphicsDriver); err1 != nil && err == nil {
+		if err1 := debug.Do(ctx, debug.PhasePresent, func() error {
+			return atlas.SwapBuffers(graphicsDriver)
+		}); err1 != nil && err == nil {
 			err = err1
 			return
 		}
@@ -118,6 +140,9 @@ func (c *context) updateFrameImpl(graphicsDriver graphicsdriver.Graphics, update
 		return err
 	}
 
+	// Notify the pixels read asynchronously in the previous frames.
+	graphicscommand.CallReadPixelsAsyncCallbacks()
+
 	// ForceUpdate can be invoked even if the context is not initialized yet (#1591).
 	if w, h := c.layoutGame(outsideWidth, outsideHeight, deviceScaleFactor); w == 0 || h == 0 {
 		return nil
@@ -129,13 +154,72 @@ func (c *context) updateFrameImpl(graphicsDriver graphicsdriver.Graphics, update
 	}
 
 	// Ensure that Update is called once before Draw so that Update can be used for initialization.
-	if !c.updateCalled && updateCount == 0 {
-		updateCount = 1
+	// The first Update is never pipelined so that Draw can use the initialized state.
+	pipelined := c.pipelinedUpdate && c.updateCalled
+	if !c.updateCalled {
+		if updateCount == 0 {
+			updateCount = 1
+		}
 		c.updateCalled = true
 	}
 	debug.FrameLogf("Update count per frame: %d\n", updateCount)
 
-	// Update the game.
+	if !pipelined || updateCount == 0 {
+		// Update the game.
+		if err := c.updateGame(ctx, updateCount, ui); err != nil {
+			return err
+		}
+
+		// Update window icons during a frame, since an icon might be *ebiten.Image and
+		// getting pixels from it needs to be in a frame (#1468).
+		if err := ui.updateIconIfNeeded(); err != nil {
+			return err
+		}
+
+		c.game.CommitDrawState()
+
+		// Draw the game.
+		if err := c.drawGame(ctx, graphicsDriver, ui, forceDraw); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	// In the pipelined mode, Draw draws the state committed at the previous frame while Update updates the game concurrently.
+	// The Update calls must finish before the frame ends, as Update might use functions that must be called in a frame.
+	c.game.CommitDrawState()
+
+	updateErrCh := make(chan error, 1)
+	go func() {
+		updateErrCh <- c.updateGame(ctx, updateCount, ui)
+	}()
+
+	drawErr := c.drawGame(ctx, graphicsDriver, ui, forceDraw)
+	if err := <-updateErrCh; err != nil {
+		return err
+	}
+	if drawErr != nil {
+		return drawErr
+	}
+
+	if err := ui.updateIconIfNeeded(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *context) updateGame(ctx stdcontext.Context, updateCount int, ui *UserInterface) error {
+	if updateCount == 0 {
+		return nil
+	}
+	return debug.Do(ctx, debug.PhaseUpdate, func() error {
+		return c.updateGameImpl(updateCount, ui)
+	})
+}
+
+func (c *context) updateGameImpl(updateCount int, ui *UserInterface) error {
 	for i := 0; i < updateCount; i++ {
 		// Read the input state and use it for one tick to give a consistent result for one tick (#2496, #2501).
 		c.game.UpdateInputState(func(inputState *InputState) {
@@ -156,18 +240,6 @@ func (c *context) updateFrameImpl(graphicsDriver graphicsdriver.Graphics, update
 
 		ui.tick.Add(1)
 	}
-
-	// Update window icons during a frame, since an icon might be *ebiten.Image and
-	// getting pixels from it needs to be in a frame (#1468).
-	if err := ui.updateIconIfNeeded(); err != nil {
-		return err
-	}
-
-	// Draw the game.
-	if err := c.drawGame(graphicsDriver, ui, forceDraw); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -179,19 +251,36 @@ func (c *context) newOffscreenImage(w, h int) *Image {
 	return img
 }
 
-func (c *context) drawGame(graphicsDriver graphicsdriver.Graphics, ui *UserInterface, forceDraw bool) error {
+func (c *context) drawGame(ctx stdcontext.Context, graphicsDriver graphicsdriver.Graphics, ui *UserInterface, forceDraw bool) error {
+	return debug.Do(ctx, debug.PhaseDraw, func() error {
+		return c.drawGameImpl(graphicsDriver, ui, forceDraw)
+	})
+}
+
+func (c *context) drawGameImpl(graphicsDriver graphicsdriver.Graphics, ui *UserInterface, forceDraw bool) error {
 	// isOffscreenModified is updated when an offscreen's modifyCallback.
 	c.isOffscreenModified = false
 
+	// If the game reports a damaged region, only the region is cleared and redrawn.
+	// The whole offscreen is redrawn when the offscreen is newly created or the drawing is forced.
+	region := image.Rect(0, 0, c.offscreen.width, c.offscreen.height)
+	if r, ok := c.game.DamagedRegion(); ok && !c.offscreenRecreated && !forceDraw {
+		region = r.Intersect(region)
+	}
+	c.offscreenRecreated = false
+
 	// Even though updateCount == 0, the offscreen is cleared and Draw is called.
 	// Draw should not update the game state and then the screen should not be updated without Update, but
 	// users might want to process something at Draw with the time intervals of FPS.
-	if ui.IsScreenClearedEveryFrame() {
-		c.offscreen.clear()
-	}
+	// An exception is an empty damaged region, where nothing needs to be redrawn.
+	if !region.Empty() {
+		if ui.IsScreenClearedEveryFrame() {
+			c.offscreen.Fill(0, 0, 0, 0, region)
+		}
 
-	if err := c.game.DrawOffscreen(); err != nil {
-		return err
+		if err := c.game.DrawOffscreen(region); err != nil {
+			return err
+		}
 	}
 
 	const maxSkipCount = 4
@@ -258,6 +347,7 @@ func (c *context) layoutGame(outsideWidth, outsideHeight float64, deviceScaleFac
 	}
 	if c.offscreen == nil {
 		c.offscreen = c.newOffscreenImage(ow, oh)
+		c.offscreenRecreated = true
 	}
 
 	return ow, oh
@@ -282,6 +372,9 @@ func (c *context) screenScaleAndOffsets() (scale, offsetX, offsetY float64) {
 	scaleX := c.screenWidth / c.offscreenWidth
 	scaleY := c.screenHeight / c.offscreenHeight
 	scale = math.Min(scaleX, scaleY)
+	if c.integerScale && scale >= 1 {
+		scale = math.Floor(scale)
+	}
 	width := c.offscreenWidth * scale
 	height := c.offscreenHeight * scale
 	offsetX = (c.screenWidth - width) / 2
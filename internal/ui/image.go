@@ -41,6 +41,11 @@ type Image struct {
 	width     int
 	height    int
 	imageType atlas.ImageType
+	format    graphicsdriver.PixelFormat
+	samples   int
+
+	// nativeTexture is a handle of a native texture that the image refers to.
+	nativeTexture uintptr
 
 	// lastBlend is the lastly-used blend for mipmap.Image.
 	lastBlend graphicsdriver.Blend
@@ -55,13 +60,14 @@ type Image struct {
 	tmpVerticesForFill []float32
 }
 
-func (u *UserInterface) NewImage(width, height int, imageType atlas.ImageType) *Image {
+func (u *UserInterface) NewImage(width, height int, imageType atlas.ImageType, format graphicsdriver.PixelFormat) *Image {
 	return &Image{
 		ui:        u,
-		mipmap:    mipmap.New(width, height, imageType),
+		mipmap:    mipmap.New(width, height, imageType, format),
 		width:     width,
 		height:    height,
 		imageType: imageType,
+		format:    format,
 		lastBlend: graphicsdriver.BlendSourceOver,
 	}
 }
@@ -76,7 +82,14 @@ func (i *Image) Deallocate() {
 	i.mipmap.Deallocate()
 }
 
-func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, canSkipMipmap bool, antialias bool) {
+func (i *Image) DeallocateMipmaps() {
+	if i.mipmap == nil {
+		return
+	}
+	i.mipmap.DeallocateMipmaps()
+}
+
+func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil, canSkipMipmap bool, antialias bool) {
 	if i.modifyCallback != nil {
 		i.modifyCallback()
 	}
@@ -103,7 +116,97 @@ func (i *Image) DrawTriangles(srcs [graphics.ShaderSrcImageCount]*Image, vertice
 		srcMipmaps[i] = src.mipmap
 	}
 
-	i.mipmap.DrawTriangles(srcMipmaps, vertices, indices, blend, dstRegion, srcRegions, shader.shader, uniforms, fillRule, canSkipMipmap)
+	i.mipmap.DrawTriangles(srcMipmaps, vertices, indices, blend, dstRegion, srcRegions, shader.shader, uniforms, fillRule, depthMode, stencil, canSkipMipmap)
+}
+
+// DrawTrianglesToMultipleTargets draws triangles to the image and the additional images at once.
+// The k-th output of the shader's fragment entry point is rendered to the image when k is 0, or to additionalDsts[k-1] otherwise.
+func (i *Image) DrawTrianglesToMultipleTargets(additionalDsts [graphics.ShaderDstImageCount - 1]*Image, srcs [graphics.ShaderSrcImageCount]*Image, vertices []float32, indices []uint32, blend graphicsdriver.Blend, dstRegion image.Rectangle, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, shader *Shader, uniforms []uint32, fillRule graphicsdriver.FillRule, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil, canSkipMipmap bool) {
+	var dstMipmaps [graphics.ShaderDstImageCount - 1]*mipmap.Mipmap
+	for k, dst := range append([]*Image{i}, additionalDsts[:]...) {
+		if dst == nil {
+			continue
+		}
+		if dst.modifyCallback != nil {
+			dst.modifyCallback()
+		}
+		dst.lastBlend = blend
+		dst.flushBufferIfNeeded()
+		if k > 0 {
+			dstMipmaps[k-1] = dst.mipmap
+		}
+	}
+
+	var srcMipmaps [graphics.ShaderSrcImageCount]*mipmap.Mipmap
+	for i, src := range srcs {
+		if src == nil {
+			continue
+		}
+		src.flushBufferIfNeeded()
+		srcMipmaps[i] = src.mipmap
+	}
+
+	i.mipmap.DrawTrianglesToMultipleTargets(dstMipmaps, srcMipmaps, vertices, indices, blend, dstRegion, srcRegions, shader.shader, uniforms, fillRule, depthMode, stencil, canSkipMipmap)
+}
+
+func (i *Image) CopyFrom(src *Image, dstPoint image.Point, srcRegion image.Rectangle) {
+	if i.modifyCallback != nil {
+		i.modifyCallback()
+	}
+	i.flushBufferIfNeeded()
+	src.flushBufferIfNeeded()
+	i.mipmap.CopyFrom(src.mipmap, dstPoint, srcRegion)
+}
+
+// DispatchCompute executes the compute kernel shader with x * y * z workgroups.
+// images are bound to the storage images of the kernel in order.
+func DispatchCompute(shader *Shader, images []*Image, uniforms []uint32, x, y, z int) {
+	mipmaps := make([]*mipmap.Mipmap, len(images))
+	for k, img := range images {
+		if img.modifyCallback != nil {
+			img.modifyCallback()
+		}
+		img.flushBufferIfNeeded()
+		mipmaps[k] = img.mipmap
+	}
+	mipmap.DispatchCompute(shader.shader, mipmaps, uniforms, x, y, z)
+}
+
+// Resize reallocates the image with the given size.
+// The pixels in the region shared by the old size and the new size are preserved.
+func (i *Image) Resize(width, height int) {
+	if i.imageType == atlas.ImageTypeScreen {
+		panic("ui: the screen image cannot be resized")
+	}
+	if i.nativeTexture != 0 {
+		panic("ui: an image referring to a native texture cannot be resized")
+	}
+	if i.modifyCallback != nil {
+		i.modifyCallback()
+	}
+	i.flushBufferIfNeeded()
+	if i.bigOffscreenBuffer != nil {
+		i.bigOffscreenBuffer.deallocate()
+		i.bigOffscreenBuffer = nil
+	}
+
+	m := mipmap.New(width, height, i.imageType, i.format)
+	if i.samples > 1 {
+		m.SetSamples(i.samples)
+	}
+	w, h := i.width, i.height
+	if w > width {
+		w = width
+	}
+	if h > height {
+		h = height
+	}
+	m.CopyFrom(i.mipmap, image.Point{}, image.Rect(0, 0, w, h))
+	i.mipmap.Deallocate()
+
+	i.mipmap = m
+	i.width = width
+	i.height = height
 }
 
 func (i *Image) WritePixels(pix []byte, region image.Rectangle) {
@@ -130,6 +233,35 @@ func (i *Image) ReadPixels(pixels []byte, region image.Rectangle) {
 	}
 }
 
+// ReadPixelsAsync starts reading the pixels without waiting for the GPU.
+//
+// callback is called at a later frame after the pixels are read. pixels must not be accessed until then.
+func (i *Image) ReadPixelsAsync(pixels []byte, region image.Rectangle, callback func(err error)) {
+	i.flushBigOffscreenBufferIfNeeded()
+	i.mipmap.ReadPixelsAsync(pixels, region, callback)
+}
+
+// SetSamples sets the number of samples per pixel for multisample anti-aliasing.
+// SetSamples must be called before the image is used.
+//
+// Only the OpenGL driver renders a multisampled image with multiple samples.
+// With the other drivers, the image is rendered with one sample per pixel.
+func (i *Image) SetSamples(samples int) {
+	i.mipmap.SetSamples(samples)
+	i.samples = samples
+}
+
+// SetNativeTexture makes the image refer to the given native texture.
+func (i *Image) SetNativeTexture(handle uintptr) {
+	i.mipmap.SetNativeTexture(handle)
+	i.nativeTexture = handle
+}
+
+// PinToAtlas puts the image onto a texture atlas and keeps it there whenever possible.
+func (i *Image) PinToAtlas() {
+	i.mipmap.PinToAtlas()
+}
+
 func (i *Image) DumpScreenshot(name string, blackbg bool) (string, error) {
 	i.flushBufferIfNeeded()
 	return i.ui.dumpScreenshot(i.mipmap, name, blackbg)
@@ -173,7 +305,42 @@ func (i *Image) Fill(r, g, b, a float32, region image.Rectangle) {
 		blend = graphicsdriver.BlendSourceOver
 	}
 	// i.lastBlend is updated in DrawTriangles.
-	i.DrawTriangles(srcs, i.tmpVerticesForFill, is, blend, region, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, true, false)
+	i.DrawTriangles(srcs, i.tmpVerticesForFill, is, blend, region, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, true, false)
+}
+
+// ClearDepth clears the depth buffer in the region with the farthest value.
+// The colors are not changed.
+func (i *Image) ClearDepth(region image.Rectangle) {
+	i.clearDepthStencil(region, graphicsdriver.DepthModeWrite, graphicsdriver.Stencil{})
+}
+
+// ClearStencil clears the stencil buffer in the region with 0.
+// The colors are not changed.
+func (i *Image) ClearStencil(region image.Rectangle) {
+	i.clearDepthStencil(region, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{
+		Func: graphicsdriver.StencilFuncAlways,
+		Op:   graphicsdriver.StencilOpZero,
+	})
+}
+
+func (i *Image) clearDepthStencil(region image.Rectangle, depthMode graphicsdriver.DepthMode, stencil graphicsdriver.Stencil) {
+	if len(i.tmpVerticesForFill) < 4*graphics.VertexFloatCount {
+		i.tmpVerticesForFill = make([]float32, 4*graphics.VertexFloatCount)
+	}
+	// i.tmpVerticesForFill can be reused as this is sent to DrawTriangles immediately.
+	graphics.QuadVerticesFromSrcAndMatrix(
+		i.tmpVerticesForFill,
+		1, 1, float32(i.ui.whiteImage.width-1), float32(i.ui.whiteImage.height-1),
+		float32(i.width), 0, 0, float32(i.height), 0, 0,
+		0, 0, 0, 0)
+	// The first custom value is the depth value. Use the farthest value.
+	for j := 0; j < 4; j++ {
+		i.tmpVerticesForFill[j*graphics.VertexFloatCount+8] = 1
+	}
+	is := graphics.QuadIndices()
+
+	srcs := [graphics.ShaderSrcImageCount]*Image{i.ui.whiteImage}
+	i.DrawTriangles(srcs, i.tmpVerticesForFill, is, graphicsdriver.BlendDestination, region, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, depthMode, stencil, true, false)
 }
 
 type bigOffscreenImage struct {
@@ -225,7 +392,7 @@ func (i *bigOffscreenImage) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Im
 	}
 
 	if i.image == nil {
-		i.image = i.ui.NewImage(i.region.Dx()*bigOffscreenScale, i.region.Dy()*bigOffscreenScale, i.imageType)
+		i.image = i.ui.NewImage(i.region.Dx()*bigOffscreenScale, i.region.Dy()*bigOffscreenScale, i.imageType, i.orig.format)
 	}
 
 	// Copy the current rendering result to get the correct blending result.
@@ -242,7 +409,7 @@ func (i *bigOffscreenImage) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Im
 			1, 1, 1, 1)
 		is := graphics.QuadIndices()
 		dstRegion := image.Rect(0, 0, i.region.Dx()*bigOffscreenScale, i.region.Dy()*bigOffscreenScale)
-		i.image.DrawTriangles(srcs, i.tmpVerticesForCopying, is, graphicsdriver.BlendCopy, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, true, false)
+		i.image.DrawTriangles(srcs, i.tmpVerticesForCopying, is, graphicsdriver.BlendCopy, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{}, NearestFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, true, false)
 	}
 
 	for idx := 0; idx < len(vertices); idx += graphics.VertexFloatCount {
@@ -258,7 +425,7 @@ func (i *bigOffscreenImage) drawTriangles(srcs [graphics.ShaderSrcImageCount]*Im
 	dstRegion.Max.X *= bigOffscreenScale
 	dstRegion.Max.Y *= bigOffscreenScale
 
-	i.image.DrawTriangles(srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, canSkipMipmap, false)
+	i.image.DrawTriangles(srcs, vertices, indices, blend, dstRegion, srcRegions, shader, uniforms, fillRule, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, canSkipMipmap, false)
 	i.dirty = true
 }
 
@@ -290,7 +457,7 @@ func (i *bigOffscreenImage) flush() {
 	if i.blend != graphicsdriver.BlendSourceOver {
 		blend = graphicsdriver.BlendCopy
 	}
-	i.orig.DrawTriangles(srcs, i.tmpVerticesForFlushing, is, blend, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{}, LinearFilterShader, nil, graphicsdriver.FillRuleFillAll, true, false)
+	i.orig.DrawTriangles(srcs, i.tmpVerticesForFlushing, is, blend, dstRegion, [graphics.ShaderSrcImageCount]image.Rectangle{}, LinearFilterShader, nil, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, true, false)
 
 	i.image.clear()
 	i.dirty = false
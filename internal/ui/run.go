@@ -22,11 +22,14 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
 	"github.com/hajimehoshi/ebiten/v2/internal/thread"
 )
 
 func (u *UserInterface) Run(game Game, options *RunOptions) error {
+	atlas.SetPolicy(&options.AtlasPolicy)
+
 	if options.SingleThread || buildTagSingleThread || runtime.GOOS == "js" {
 		return u.runSingleThread(game, options)
 	}
@@ -37,7 +40,7 @@ func (u *UserInterface) runMultiThread(game Game, options *RunOptions) error {
 	u.mainThread = thread.NewOSThread()
 	graphicscommand.SetOSThreadAsRenderThread()
 
-	u.context = newContext(game)
+	u.context = newContext(game, options.PipelinedUpdate, options.IntegerScale)
 
 	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
 	defer cancel()
@@ -83,7 +86,8 @@ func (u *UserInterface) runSingleThread(game Game, options *RunOptions) error {
 	u.setRunning(true)
 	defer u.setRunning(false)
 
-	u.context = newContext(game)
+	// The pipelined update is disabled in the single thread mode, as graphics functions might be called from another goroutine.
+	u.context = newContext(game, false, options.IntegerScale)
 
 	if err := u.initOnMainThread(options); err != nil {
 		return err
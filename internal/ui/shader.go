@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
@@ -30,14 +31,29 @@ type Shader struct {
 	uniformNames       []string
 	uniformTypes       []shaderir.Type
 	uniformUint32Count int
+
+	// runtimeSized reports whether the uniform variable is a runtime-sized array.
+	runtimeSized []bool
 }
 
 func NewShader(ir *shaderir.Program) *Shader {
-	return &Shader{
+	// A compute kernel doesn't have the preserved uniform variables.
+	offset := graphics.PreservedUniformVariablesCount
+	if ir.IsCompute() {
+		offset = 0
+	}
+	s := &Shader{
 		shader:       atlas.NewShader(ir),
-		uniformNames: ir.UniformNames[graphics.PreservedUniformVariablesCount:],
-		uniformTypes: ir.Uniforms[graphics.PreservedUniformVariablesCount:],
+		uniformNames: ir.UniformNames[offset:],
+		uniformTypes: ir.Uniforms[offset:],
+	}
+	if len(ir.RuntimeSizedUniforms) > 0 {
+		s.runtimeSized = make([]bool, len(s.uniformNames))
+		for _, i := range ir.RuntimeSizedUniforms {
+			s.runtimeSized[i-offset] = true
+		}
 	}
+	return s
 }
 
 func (s *Shader) Deallocate() {
@@ -64,50 +80,17 @@ func (s *Shader) AppendUniforms(dst []uint32, uniforms map[string]any) []uint32
 	idx := origLen
 	for i, name := range s.uniformNames {
 		typ := s.uniformTypes[i]
+		runtimeSized := s.runtimeSized != nil && s.runtimeSized[i]
 
 		// Ignore if an unused name is specified (#2710).
-		if uv, ok := uniforms[name]; ok {
-			v := reflect.ValueOf(uv)
-			t := v.Type()
-			switch t.Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				if typ.Uint32Count() != 1 {
-					panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
-				}
-				dst[idx] = uint32(v.Int())
-			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-				if typ.Uint32Count() != 1 {
-					panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
-				}
-				dst[idx] = uint32(v.Uint())
-			case reflect.Float32, reflect.Float64:
-				if typ.Uint32Count() != 1 {
-					panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
-				}
-				dst[idx] = math.Float32bits(float32(v.Float()))
-			case reflect.Slice, reflect.Array:
-				l := v.Len()
-				if typ.Uint32Count() != l {
-					panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
-				}
-				switch t.Elem().Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					for i := 0; i < l; i++ {
-						dst[idx+i] = uint32(v.Index(i).Int())
-					}
-				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-					for i := 0; i < l; i++ {
-						dst[idx+i] = uint32(v.Index(i).Uint())
-					}
-				case reflect.Float32, reflect.Float64:
-					for i := 0; i < l; i++ {
-						dst[idx+i] = math.Float32bits(float32(v.Index(i).Float()))
-					}
-				default:
-					panic(fmt.Sprintf("ui: unexpected uniform value type: %s (%s)", name, v.Kind().String()))
-				}
-			default:
-				panic(fmt.Sprintf("ui: unexpected uniform value type: %s (%s)", name, v.Kind().String()))
+		if root, path, ok := strings.Cut(name, "."); ok {
+			// A member of a struct is looked up from the value for the struct.
+			if uv, ok := uniforms[root]; ok {
+				setStructMemberUniformValue(dst[idx:], name, typ, reflect.ValueOf(uv), path, runtimeSized)
+			}
+		} else if uv, ok := uniforms[name]; ok {
+			if !setUniformValueFast(dst[idx:], name, typ, uv, runtimeSized) {
+				setUniformValue(dst[idx:], name, typ, reflect.ValueOf(uv), runtimeSized)
 			}
 		}
 
@@ -116,3 +99,145 @@ func (s *Shader) AppendUniforms(dst []uint32, uniforms map[string]any) []uint32
 
 	return dst
 }
+
+// setUniformValueFast sets the value v for the uniform variable to dst without reflection for common large values.
+// setUniformValueFast returns false if v is not such a value.
+func setUniformValueFast(dst []uint32, name string, typ shaderir.Type, v any, allowShorter bool) bool {
+	switch v := v.(type) {
+	case []float32:
+		checkUniformValueLength(name, typ, len(v), allowShorter)
+		for i, f := range v {
+			dst[i] = math.Float32bits(f)
+		}
+		return true
+	case [][16]float32:
+		// This is typical for a mat4 array like bone matrices.
+		checkUniformValueLength(name, typ, len(v)*16, allowShorter)
+		for i, m := range v {
+			d := dst[i*16 : i*16+16]
+			for j, f := range m {
+				d[j] = math.Float32bits(f)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func checkUniformValueLength(name string, typ shaderir.Type, length int, allowShorter bool) {
+	// A runtime-sized array might be longer than the given values as an array cannot be empty.
+	// The rest is filled with zeros.
+	if typ.Uint32Count() != length && !(allowShorter && length < typ.Uint32Count()) {
+		panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
+	}
+}
+
+// setUniformValue sets the value v for the uniform variable to dst.
+// If allowShorter is true, the value can be shorter than the type, and the rest is not updated.
+func setUniformValue(dst []uint32, name string, typ shaderir.Type, v reflect.Value, allowShorter bool) {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ.Uint32Count() != 1 {
+			panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
+		}
+		dst[0] = uint32(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if typ.Uint32Count() != 1 {
+			panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
+		}
+		dst[0] = uint32(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		if typ.Uint32Count() != 1 {
+			panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
+		}
+		dst[0] = math.Float32bits(float32(v.Float()))
+	case reflect.Slice, reflect.Array:
+		// An array of arrays like [64][16]float32 is flattened.
+		if t.Elem().Kind() == reflect.Array {
+			n := t.Elem().Len()
+			checkUniformValueLength(name, typ, v.Len()*n, allowShorter)
+			for i := 0; i < v.Len(); i++ {
+				setUniformElements(dst[i*n:], name, v.Index(i))
+			}
+			return
+		}
+		checkUniformValueLength(name, typ, v.Len(), allowShorter)
+		setUniformElements(dst, name, v)
+	default:
+		panic(fmt.Sprintf("ui: unexpected uniform value type: %s (%s)", name, v.Kind().String()))
+	}
+}
+
+// setUniformElements sets the elements of the slice or array value v to dst.
+func setUniformElements(dst []uint32, name string, v reflect.Value) {
+	l := v.Len()
+	switch v.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for i := 0; i < l; i++ {
+			dst[i] = uint32(v.Index(i).Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		for i := 0; i < l; i++ {
+			dst[i] = uint32(v.Index(i).Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		for i := 0; i < l; i++ {
+			dst[i] = math.Float32bits(float32(v.Index(i).Float()))
+		}
+	default:
+		panic(fmt.Sprintf("ui: unexpected uniform value type: %s (%s)", name, v.Kind().String()))
+	}
+}
+
+// setStructMemberUniformValue sets the member value at path of the struct value v to dst.
+// If v is a slice or an array, v is a struct array and the member values of all the elements are set.
+func setStructMemberUniformValue(dst []uint32, name string, typ shaderir.Type, v reflect.Value, path string, runtimeSized bool) {
+	v = indirectUniformValue(v)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		if m, ok := structMemberUniformValue(name, v, path); ok {
+			setUniformValue(dst, name, typ, m, false)
+		}
+		return
+	}
+
+	l := v.Len()
+	if typ.Main != shaderir.Array || (typ.Length != l && !(runtimeSized && l < typ.Length)) {
+		panic(fmt.Sprintf("ui: unexpected uniform value for %s (%s)", name, typ.String()))
+	}
+	n := typ.Sub[0].Uint32Count()
+	for i := 0; i < l; i++ {
+		if m, ok := structMemberUniformValue(name, v.Index(i), path); ok {
+			setUniformValue(dst[i*n:], name, typ.Sub[0], m, false)
+		}
+	}
+}
+
+// structMemberUniformValue returns the member value at path like "Inner.Color" of the struct value v.
+// v must be a struct or a map with string keys.
+func structMemberUniformValue(name string, v reflect.Value, path string) (reflect.Value, bool) {
+	for path != "" {
+		var field string
+		field, path, _ = strings.Cut(path, ".")
+		v = indirectUniformValue(v)
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(field)
+		case reflect.Map:
+			v = v.MapIndex(reflect.ValueOf(field))
+		default:
+			panic(fmt.Sprintf("ui: unexpected uniform value type for a struct: %s (%s)", name, v.Kind().String()))
+		}
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return indirectUniformValue(v), true
+}
+
+func indirectUniformValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	return v
+}
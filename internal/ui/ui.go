@@ -78,6 +78,7 @@ type UserInterface struct {
 
 	isScreenClearedEveryFrame atomic.Bool
 	graphicsLibrary           atomic.Int32
+	screenColorSpace          atomic.Int32
 	running                   atomic.Bool
 	terminated                atomic.Bool
 	tick                      atomic.Uint64
@@ -112,7 +113,7 @@ func newUserInterface() (*UserInterface, error) {
 	u.isScreenClearedEveryFrame.Store(true)
 	u.graphicsLibrary.Store(int32(GraphicsLibraryUnknown))
 
-	u.whiteImage = u.NewImage(3, 3, atlas.ImageTypeRegular)
+	u.whiteImage = u.NewImage(3, 3, atlas.ImageTypeRegular, graphicsdriver.PixelFormatRGBA8)
 	pix := make([]byte, 4*u.whiteImage.width*u.whiteImage.height)
 	for i := range pix {
 		pix[i] = 0xff
@@ -179,6 +180,9 @@ type RunOptions struct {
 	SingleThread      bool
 	DisableHiDPI      bool
 	ColorSpace        graphicsdriver.ColorSpace
+	PipelinedUpdate   bool
+	AtlasPolicy       atlas.Policy
+	IntegerScale      bool
 	X11ClassName      string
 	X11InstanceName   string
 }
@@ -218,6 +222,20 @@ func (u *UserInterface) GraphicsLibrary() GraphicsLibrary {
 	return GraphicsLibrary(u.graphicsLibrary.Load())
 }
 
+func (u *UserInterface) setScreenColorSpace(graphicsDriver graphicsdriver.Graphics) {
+	r, ok := graphicsDriver.(graphicsdriver.ScreenColorSpaceReporter)
+	if !ok {
+		return
+	}
+	u.screenColorSpace.Store(int32(r.ScreenColorSpace()))
+}
+
+// ScreenColorSpace returns the color space of the screen.
+// ScreenColorSpace returns graphicsdriver.ColorSpaceDefault when the color space is unknown.
+func (u *UserInterface) ScreenColorSpace() graphicsdriver.ColorSpace {
+	return graphicsdriver.ColorSpace(u.screenColorSpace.Load())
+}
+
 func (u *UserInterface) isRunning() bool {
 	return u.running.Load() && !u.isTerminated()
 }
@@ -1096,6 +1096,7 @@ func (u *UserInterface) initOnMainThread(options *RunOptions) error {
 	}
 	u.graphicsDriver = g
 	u.setGraphicsLibrary(lib)
+	u.setScreenColorSpace(g)
 	u.graphicsDriver.SetTransparent(options.ScreenTransparent)
 
 	// internal/glfw is customized and the default client API is NoAPI, not OpenGLAPI.
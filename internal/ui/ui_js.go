@@ -780,6 +780,7 @@ func (u *UserInterface) initOnMainThread(options *RunOptions) error {
 	}
 	u.graphicsDriver = g
 	u.setGraphicsLibrary(lib)
+	u.setScreenColorSpace(g)
 
 	if bodyStyle := document.Get("body").Get("style"); options.ScreenTransparent {
 		bodyStyle.Set("backgroundColor", "transparent")
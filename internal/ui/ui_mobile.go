@@ -24,6 +24,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
 	"github.com/hajimehoshi/ebiten/v2/internal/gamepad"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
@@ -137,11 +138,12 @@ func (u *UserInterface) runMobile(game Game, options *RunOptions) (err error) {
 	}()
 
 	graphicscommand.SetOSThreadAsRenderThread()
+	atlas.SetPolicy(&options.AtlasPolicy)
 
 	u.setRunning(true)
 	defer u.setRunning(false)
 
-	u.context = newContext(game)
+	u.context = newContext(game, options.PipelinedUpdate, options.IntegerScale)
 
 	g, lib, err := newGraphicsDriver(&graphicsDriverCreatorImpl{
 		colorSpace: options.ColorSpace,
@@ -151,6 +153,7 @@ func (u *UserInterface) runMobile(game Game, options *RunOptions) (err error) {
 	}
 	u.graphicsDriver = g
 	u.setGraphicsLibrary(lib)
+	u.setScreenColorSpace(g)
 	close(u.graphicsLibraryInitCh)
 
 	for {
@@ -0,0 +1,220 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kage provides functions to parse and format Kage shader sources for tools like linters and editors.
+//
+// Kage's syntax is a subset of Go's syntax. Parse returns the directives and the top-level declarations of a source,
+// and the Go syntax tree for further inspection.
+// Parse doesn't check types and semantics. Use ebiten.NewShader or ebiten.NewComputeShader to compile a shader.
+package kage
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/shader"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+)
+
+// Unit is the unit of positions in a shader specified by the //kage:unit directive.
+type Unit int
+
+const (
+	// UnitTexels is the texel unit. This is the default unit when a shader doesn't have a //kage:unit directive.
+	UnitTexels Unit = iota
+
+	// UnitPixels is the pixel unit.
+	UnitPixels
+)
+
+// File is a parsed Kage source.
+type File struct {
+	// Unit is the unit specified by the //kage:unit directive.
+	Unit Unit
+
+	// Compute reports whether the source is a compute kernel with the //kage:compute directive.
+	Compute bool
+
+	// WorkgroupSize is the workgroup size specified by the //kage:compute directive.
+	// WorkgroupSize is valid only when Compute is true.
+	WorkgroupSize [3]int
+
+	// Imports is the paths of the //kage:import directives.
+	Imports []string
+
+	// Uniforms is the uniform variables.
+	Uniforms []Var
+
+	// SharedVars is the variables with the //kage:shared directive in a compute kernel.
+	SharedVars []Var
+
+	// Funcs is the top-level functions.
+	Funcs []Func
+
+	// Syntax is the Go syntax tree of the source including the comments.
+	Syntax *ast.File
+
+	// FileSet is the file set for the positions in Syntax.
+	FileSet *token.FileSet
+}
+
+// Var is a variable or a parameter.
+type Var struct {
+	// Name is the variable name. Name is empty for an unnamed parameter.
+	Name string
+
+	// Type is the type as written in the source like "vec4" or "[4]mat4".
+	Type string
+
+	// Pos is the position of the variable.
+	Pos token.Position
+}
+
+// Func is a top-level function.
+type Func struct {
+	// Name is the function name.
+	Name string
+
+	// Params is the parameters.
+	Params []Var
+
+	// Results is the results.
+	Results []Var
+
+	// Pos is the position of the function name.
+	Pos token.Position
+}
+
+// Parse parses a Kage source.
+//
+// filename is used only for the positions and can be empty.
+// The //kage:import directives are not resolved.
+func Parse(filename string, src []byte) (*File, error) {
+	unit, err := shader.ParseCompilerDirectives(src)
+	if err != nil {
+		return nil, err
+	}
+	workgroupSize, compute, err := shader.ParseComputeDirective(src)
+	if err != nil {
+		return nil, err
+	}
+	imports, err := shader.ParseImportDirectives(src)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{
+		Compute:       compute,
+		WorkgroupSize: workgroupSize,
+		Imports:       imports,
+		Syntax:        f,
+		FileSet:       fset,
+	}
+	if unit == shaderir.Pixels {
+		file.Unit = UnitPixels
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range d.Specs {
+				s := spec.(*ast.ValueSpec)
+				// A global variable with initial values is a constant array.
+				if len(s.Values) > 0 {
+					continue
+				}
+				vs := file.vars(s.Names, s.Type)
+				if (d.Lparen == token.NoPos && shader.HasSharedDirective(d.Doc)) || shader.HasSharedDirective(s.Doc) {
+					file.SharedVars = append(file.SharedVars, vs...)
+					continue
+				}
+				file.Uniforms = append(file.Uniforms, vs...)
+			}
+		case *ast.FuncDecl:
+			if d.Recv != nil {
+				continue
+			}
+			fn := Func{
+				Name:   d.Name.Name,
+				Params: file.fieldVars(d.Type.Params),
+				Pos:    fset.Position(d.Name.Pos()),
+			}
+			if d.Type.Results != nil {
+				fn.Results = file.fieldVars(d.Type.Results)
+			}
+			file.Funcs = append(file.Funcs, fn)
+		}
+	}
+
+	return file, nil
+}
+
+func (f *File) vars(names []*ast.Ident, typ ast.Expr) []Var {
+	t := f.typeString(typ)
+	vs := make([]Var, 0, len(names))
+	for _, n := range names {
+		vs = append(vs, Var{
+			Name: n.Name,
+			Type: t,
+			Pos:  f.FileSet.Position(n.Pos()),
+		})
+	}
+	return vs
+}
+
+func (f *File) fieldVars(fields *ast.FieldList) []Var {
+	var vs []Var
+	for _, field := range fields.List {
+		if len(field.Names) == 0 {
+			vs = append(vs, Var{
+				Type: f.typeString(field.Type),
+				Pos:  f.FileSet.Position(field.Type.Pos()),
+			})
+			continue
+		}
+		vs = append(vs, f.vars(field.Names, field.Type)...)
+	}
+	return vs
+}
+
+func (f *File) typeString(typ ast.Expr) string {
+	if typ == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, f.FileSet, typ); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// Format formats a Kage source in the canonical style, which is the same as gofmt's.
+//
+// The comments including the directives are kept.
+func Format(src []byte) ([]byte, error) {
+	return format.Source(src)
+}
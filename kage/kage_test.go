@@ -0,0 +1,185 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kage_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/kage"
+)
+
+func varStrings(vs []kage.Var) []string {
+	var strs []string
+	for _, v := range vs {
+		strs = append(strs, v.Name+" "+v.Type)
+	}
+	return strs
+}
+
+func TestParse(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+//kage:import "lib/noise"
+
+package main
+
+var Time float
+var (
+	Cursor, Size vec2
+	Bones        [4]mat4
+)
+
+var Bayer = [4]float{0, 2, 3, 1}
+
+const Scale = 2
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return imageSrc0At(srcPos) * Time
+}
+
+func split(v vec4) (rgb vec3, a float) {
+	return v.rgb, v.a
+}
+`)
+	f, err := kage.Parse("shader.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := f.Unit, kage.UnitPixels; got != want {
+		t.Errorf("Unit: got: %v, want: %v", got, want)
+	}
+	if f.Compute {
+		t.Errorf("Compute: got: true, want: false")
+	}
+	if got, want := f.Imports, []string{"lib/noise"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Imports: got: %v, want: %v", got, want)
+	}
+	if got, want := varStrings(f.Uniforms), []string{"Time float", "Cursor vec2", "Size vec2", "Bones [4]mat4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Uniforms: got: %v, want: %v", got, want)
+	}
+	if got, want := f.Uniforms[1].Pos.String(), "shader.go:8:2"; got != want {
+		t.Errorf("Uniforms[1].Pos: got: %s, want: %s", got, want)
+	}
+
+	if got, want := len(f.Funcs), 2; got != want {
+		t.Fatalf("len(Funcs): got: %d, want: %d", got, want)
+	}
+	fn := f.Funcs[0]
+	if got, want := fn.Name, "Fragment"; got != want {
+		t.Errorf("Funcs[0].Name: got: %s, want: %s", got, want)
+	}
+	if got, want := varStrings(fn.Params), []string{"dstPos vec4", "srcPos vec2", "color vec4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Funcs[0].Params: got: %v, want: %v", got, want)
+	}
+	if got, want := varStrings(fn.Results), []string{" vec4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Funcs[0].Results: got: %v, want: %v", got, want)
+	}
+	if got, want := varStrings(f.Funcs[1].Results), []string{"rgb vec3", "a float"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Funcs[1].Results: got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseCompute(t *testing.T) {
+	src := []byte(`//kage:compute 8 8
+
+package main
+
+//kage:shared
+var Tile [64]vec4
+
+var Strength float
+
+func Compute(id ivec3) {
+}
+`)
+	f, err := kage.Parse("", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Compute {
+		t.Errorf("Compute: got: false, want: true")
+	}
+	if got, want := f.WorkgroupSize, [3]int{8, 8, 1}; got != want {
+		t.Errorf("WorkgroupSize: got: %v, want: %v", got, want)
+	}
+	if got, want := f.Unit, kage.UnitTexels; got != want {
+		t.Errorf("Unit: got: %v, want: %v", got, want)
+	}
+	if got, want := varStrings(f.SharedVars), []string{"Tile [64]vec4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SharedVars: got: %v, want: %v", got, want)
+	}
+	if got, want := varStrings(f.Uniforms), []string{"Strength float"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Uniforms: got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, src := range []string{
+		`package main
+
+func Fragment(dstPos vec4 {
+}
+`,
+		`//kage:unit meters
+
+package main
+`,
+		`//kage:import noise
+
+package main
+`,
+	} {
+		if _, err := kage.Parse("", []byte(src)); err == nil {
+			t.Errorf("Parse(%q) must return an error", src)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	src := []byte(`//kage:unit pixels
+
+package main
+
+var   Time float
+
+func Fragment(dstPos vec4,srcPos vec2, color vec4) vec4 {
+// Comment
+return imageSrc0At(srcPos)*Time
+}
+`)
+	want := `//kage:unit pixels
+
+package main
+
+var Time float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	// Comment
+	return imageSrc0At(srcPos) * Time
+}
+`
+	got, err := kage.Format(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := kage.Format([]byte("package main\n\nfunc {")); err == nil {
+		t.Errorf("Format must return an error for an invalid source")
+	}
+}
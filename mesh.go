@@ -0,0 +1,190 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+)
+
+// Mesh is a reusable set of vertices and indices for DrawMesh and DrawMeshShader.
+//
+// A Mesh owns its vertex and index buffers.
+// You can fill a Mesh once, or mutate a part of it, and draw it repeatedly without allocating slices every frame.
+//
+// A Mesh is not concurrent-safe.
+type Mesh struct {
+	vertices []Vertex
+	indices  []uint16
+
+	// indicesValidated reports whether all the indices are known to be in range of the vertices.
+	indicesValidated bool
+}
+
+// NewMesh creates a new mesh with the given numbers of vertices and indices.
+// All the vertices and the indices are zero values.
+//
+// If vertexCount or indexCount is negative, or vertexCount is more than 65536, NewMesh panics.
+func NewMesh(vertexCount, indexCount int) *Mesh {
+	m := &Mesh{}
+	m.Resize(vertexCount, indexCount)
+	return m
+}
+
+// VertexCount returns the number of the vertices.
+func (m *Mesh) VertexCount() int {
+	return len(m.vertices)
+}
+
+// IndexCount returns the number of the indices.
+func (m *Mesh) IndexCount() int {
+	return len(m.indices)
+}
+
+// Resize changes the numbers of the vertices and the indices.
+//
+// The existing vertices and indices are kept as much as possible.
+// Added vertices and indices are zero values.
+// The underlying buffers are reused when their capacities are enough.
+//
+// If vertexCount or indexCount is negative, or vertexCount is more than 65536, Resize panics.
+func (m *Mesh) Resize(vertexCount, indexCount int) {
+	if vertexCount < 0 || indexCount < 0 {
+		panic(fmt.Sprintf("ebiten: vertexCount (%d) and indexCount (%d) must not be negative", vertexCount, indexCount))
+	}
+	// A vertex is specified by an uint16 index, so more vertices are never used.
+	if vertexCount > 1<<16 {
+		panic(fmt.Sprintf("ebiten: vertexCount must be less than or equal to 65536 but was %d", vertexCount))
+	}
+
+	// Shrunk vertices or added zero indices might make the indices out of range.
+	if vertexCount < len(m.vertices) || indexCount > len(m.indices) {
+		m.indicesValidated = false
+	}
+
+	m.vertices = resizeMeshSlice(m.vertices, vertexCount)
+	m.indices = resizeMeshSlice(m.indices, indexCount)
+}
+
+func resizeMeshSlice[T any](s []T, n int) []T {
+	if cap(s) < n {
+		s2 := make([]T, n)
+		copy(s2, s)
+		return s2
+	}
+	l := len(s)
+	s = s[:n]
+	var zero T
+	for i := l; i < n; i++ {
+		s[i] = zero
+	}
+	return s
+}
+
+// Vertices returns the vertices of the mesh.
+//
+// The returned slice shares the buffer with the mesh, so you can modify the vertices through the slice directly.
+// The returned slice is valid until Resize is called.
+func (m *Mesh) Vertices() []Vertex {
+	return m.vertices
+}
+
+// SetVertices copies the given vertices to the mesh's vertices starting at offset.
+//
+// If the vertices don't fit in the mesh, SetVertices panics.
+func (m *Mesh) SetVertices(offset int, vertices []Vertex) {
+	if offset < 0 || offset+len(vertices) > len(m.vertices) {
+		panic(fmt.Sprintf("ebiten: the vertices [%d, %d) are out of range [0, %d)", offset, offset+len(vertices), len(m.vertices)))
+	}
+	copy(m.vertices[offset:], vertices)
+}
+
+// SetIndices copies the given indices to the mesh's indices starting at offset.
+//
+// If the indices don't fit in the mesh, or a value in indices is out of range of the vertices, SetIndices panics.
+func (m *Mesh) SetIndices(offset int, indices []uint16) {
+	if offset < 0 || offset+len(indices) > len(m.indices) {
+		panic(fmt.Sprintf("ebiten: the indices [%d, %d) are out of range [0, %d)", offset, offset+len(indices), len(m.indices)))
+	}
+	for i, idx := range indices {
+		if int(idx) >= len(m.vertices) {
+			panic(fmt.Sprintf("ebiten: indices[%d] must be less than the vertex count (%d) but was %d", i, len(m.vertices), idx))
+		}
+	}
+	copy(m.indices[offset:], indices)
+}
+
+// validate checks that the mesh can be drawn.
+// The range check of the indices is skipped unless the mesh has been resized since the last check.
+func (m *Mesh) validate() {
+	if len(m.indices)%3 != 0 {
+		panic("ebiten: the index count of the mesh must be multiple of 3")
+	}
+	if m.indicesValidated {
+		return
+	}
+	for i, idx := range m.indices {
+		if int(idx) >= len(m.vertices) {
+			panic(fmt.Sprintf("ebiten: indices[%d] must be less than the vertex count (%d) but was %d", i, len(m.vertices), idx))
+		}
+	}
+	m.indicesValidated = true
+}
+
+// DrawMesh draws the triangles of the given mesh.
+//
+// DrawMesh works in the same way as DrawTriangles with the mesh's vertices and indices,
+// but doesn't check the range of the indices every time.
+//
+// If the index count of the mesh is not multiple of 3, DrawMesh panics.
+//
+// When the given image is disposed, DrawMesh panics.
+//
+// When the image i is disposed, DrawMesh does nothing.
+func (i *Image) DrawMesh(mesh *Mesh, img *Image, options *DrawTrianglesOptions) {
+	i.copyCheck()
+
+	if img != nil && img.isDisposed() {
+		panic("ebiten: the given image to DrawMesh must not be disposed")
+	}
+	if i.isDisposed() {
+		return
+	}
+
+	mesh.validate()
+	i.drawTriangles(mesh.vertices, i.copyIndices16(mesh.indices), img, options)
+}
+
+// DrawMeshShader draws the triangles of the given mesh with the specified shader.
+//
+// DrawMeshShader works in the same way as DrawTrianglesShader with the mesh's vertices and indices,
+// but doesn't check the range of the indices every time.
+//
+// If the index count of the mesh is not multiple of 3, DrawMeshShader panics.
+//
+// When the image i is disposed, DrawMeshShader does nothing.
+func (i *Image) DrawMeshShader(mesh *Mesh, shader *Shader, options *DrawTrianglesShaderOptions) {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
+	}
+
+	if shader.isDisposed() {
+		panic("ebiten: the given shader to DrawMeshShader must not be disposed")
+	}
+
+	mesh.validate()
+	i.drawTrianglesShader(mesh.vertices, i.copyIndices16(mesh.indices), shader, options)
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func quadVertices(x0, y0, x1, y1 float32) []ebiten.Vertex {
+	return []ebiten.Vertex{
+		{DstX: x0, DstY: y0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: x1, DstY: y0, SrcX: 1, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: x0, DstY: y1, SrcX: 0, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: x1, DstY: y1, SrcX: 1, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+}
+
+func TestMeshDraw(t *testing.T) {
+	src := ebiten.NewImage(1, 1)
+	src.Fill(color.RGBA{R: 0xff, A: 0xff})
+
+	mesh := ebiten.NewMesh(8, 12)
+	mesh.SetVertices(0, quadVertices(0, 0, 8, 8))
+	mesh.SetVertices(4, quadVertices(8, 8, 16, 16))
+	mesh.SetIndices(0, []uint16{0, 1, 2, 1, 2, 3, 4, 5, 6, 5, 6, 7})
+
+	dst := ebiten.NewImage(16, 16)
+	dst.DrawMesh(mesh, src, nil)
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if (i < 8) == (j < 8) {
+				want = color.RGBA{R: 0xff, A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// Mutate the vertices in place and draw the mesh again.
+	vs := mesh.Vertices()
+	for i := 4; i < 8; i++ {
+		vs[i].DstX -= 8
+	}
+	dst.Clear()
+	dst.DrawMesh(mesh, src, nil)
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if i < 8 {
+				want = color.RGBA{R: 0xff, A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestMeshResize(t *testing.T) {
+	mesh := ebiten.NewMesh(4, 6)
+	mesh.SetVertices(0, quadVertices(0, 0, 1, 1))
+	mesh.SetIndices(0, []uint16{0, 1, 2, 1, 2, 3})
+
+	mesh.Resize(8, 3)
+	if got, want := mesh.VertexCount(), 8; got != want {
+		t.Errorf("mesh.VertexCount(): got: %d, want: %d", got, want)
+	}
+	if got, want := mesh.IndexCount(), 3; got != want {
+		t.Errorf("mesh.IndexCount(): got: %d, want: %d", got, want)
+	}
+	if got, want := mesh.Vertices()[1].DstX, float32(1); got != want {
+		t.Errorf("mesh.Vertices()[1].DstX: got: %f, want: %f", got, want)
+	}
+	if got, want := mesh.Vertices()[5], (ebiten.Vertex{}); got != want {
+		t.Errorf("mesh.Vertices()[5]: got: %v, want: %v", got, want)
+	}
+}
+
+func TestMeshSetIndicesOutOfRange(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("SetIndices must panic but not")
+		}
+	}()
+
+	mesh := ebiten.NewMesh(4, 6)
+	mesh.SetIndices(0, []uint16{0, 1, 2, 1, 2, 4})
+}
+
+func TestMeshDrawShrunkVertices(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawMesh must panic but not")
+		}
+	}()
+
+	mesh := ebiten.NewMesh(4, 6)
+	mesh.SetIndices(0, []uint16{0, 1, 2, 1, 2, 3})
+	mesh.Resize(3, 6)
+
+	dst := ebiten.NewImage(16, 16)
+	src := ebiten.NewImage(16, 16)
+	dst.DrawMesh(mesh, src, nil)
+}
+
+func BenchmarkDrawMesh(b *testing.B) {
+	const quadCount = 1024
+
+	src := ebiten.NewImage(1, 1)
+	dst := ebiten.NewImage(256, 256)
+
+	mesh := ebiten.NewMesh(4*quadCount, 6*quadCount)
+	for i := 0; i < quadCount; i++ {
+		x, y := float32(i%32)*8, float32(i/32)*8
+		mesh.SetVertices(4*i, quadVertices(x, y, x+8, y+8))
+		idx := uint16(4 * i)
+		mesh.SetIndices(6*i, []uint16{idx, idx + 1, idx + 2, idx + 1, idx + 2, idx + 3})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst.DrawMesh(mesh, src, nil)
+	}
+}
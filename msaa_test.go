@@ -0,0 +1,103 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func skipIfMultisamplingIsNotAvailable(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureMultisampling) {
+		t.Skip("multisampled images are not available")
+	}
+}
+
+func TestImageMultisampled(t *testing.T) {
+	skipIfMultisamplingIsNotAvailable(t)
+
+	const w, h = 16, 16
+	dst := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+		Samples: 4,
+	})
+
+	src := ebiten.NewImage(1, 1)
+	src.Fill(color.White)
+
+	// Draw a triangle whose hypotenuse crosses the pixels diagonally.
+	vs := []ebiten.Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: 0, SrcX: 1, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: 0, DstY: h, SrcX: 0, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+	dst.DrawTriangles(vs, []uint16{0, 1, 2}, src, nil)
+
+	if got, want := dst.At(2, 2), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(2, 2): got: %v, want: %v", got, want)
+	}
+	if got, want := dst.At(13, 13), (color.RGBA{}); got != want {
+		t.Errorf("dst.At(13, 13): got: %v, want: %v", got, want)
+	}
+
+	// A pixel on the edge should be partially covered.
+	_, _, _, a := dst.At(8, 7).RGBA()
+	if a == 0 || a == 0xffff {
+		t.Errorf("dst.At(8, 7): alpha must be partially covered but %d", a)
+	}
+
+	// Use the multisampled image as a source.
+	dst2 := ebiten.NewImage(w, h)
+	dst2.DrawImage(dst, nil)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if got, want := dst2.At(i, j), dst.At(i, j); got != want {
+				t.Errorf("dst2.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+// TestImageMultisampledWritePixels runs even without multisampling, where the image falls back to one sample per pixel.
+func TestImageMultisampledWritePixels(t *testing.T) {
+	const w, h = 16, 16
+	dst := ebiten.NewImageWithOptions(image.Rect(0, 0, w, h), &ebiten.NewImageOptions{
+		Samples: 4,
+	})
+
+	pix := make([]byte, 4*w*h)
+	for i := range pix {
+		pix[i] = byte(i)
+	}
+	dst.WritePixels(pix)
+
+	got := make([]byte, 4*w*h)
+	dst.ReadPixels(got)
+	for i := range got {
+		if got[i] != pix[i] {
+			t.Fatalf("pixels[%d]: got: %d, want: %d", i, got[i], pix[i])
+		}
+	}
+
+	dst.SubImage(image.Rect(4, 4, 8, 8)).(*ebiten.Image).Fill(color.RGBA{R: 0xff, A: 0xff})
+	if got, want := dst.At(5, 5), (color.RGBA{R: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(5, 5): got: %v, want: %v", got, want)
+	}
+	if got, want := dst.At(0, 0), (color.RGBA{R: pix[0], G: pix[1], B: pix[2], A: pix[3]}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
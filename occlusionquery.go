@@ -0,0 +1,98 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicscommand"
+)
+
+// OcclusionQuery represents a query counting the samples rendered on the GPU.
+//
+// An occlusion query is useful to skip drawing objects hidden behind others.
+// For example, draw a bounding box of a group of sprites with a depth test and an occlusion query,
+// and skip drawing the sprites in the later frames if no samples are rendered.
+type OcclusionQuery struct {
+	query *graphicscommand.OcclusionQuery
+	ended bool
+}
+
+var activeOcclusionQuery *OcclusionQuery
+
+// BeginOcclusionQuery starts an occlusion query.
+//
+// The samples rendered by the draw calls between BeginOcclusionQuery and the returned query's End are counted.
+// A sample is counted when it passes the stencil test and the depth test.
+// The counted samples include the ones for any images.
+//
+// The results are read without stalling the GPU, so a result is available a few frames later.
+//
+// Occlusion queries cannot be nested. If there is an active query, BeginOcclusionQuery panics.
+//
+// Occlusion queries are currently available only with OpenGL (including OpenGL ES and WebGL) and DirectX 11.
+// With Metal and DirectX 12, the result is available immediately and SampleCount returns -1.
+func BeginOcclusionQuery() *OcclusionQuery {
+	if activeOcclusionQuery != nil {
+		panic("ebiten: BeginOcclusionQuery cannot be called while another occlusion query is active")
+	}
+	q := &OcclusionQuery{
+		query: &graphicscommand.OcclusionQuery{},
+	}
+	activeOcclusionQuery = q
+	atlas.BeginOcclusionQuery(q.query)
+	return q
+}
+
+// End ends the occlusion query.
+//
+// If the query is not active, End panics.
+func (q *OcclusionQuery) End() {
+	if activeOcclusionQuery != q {
+		panic("ebiten: End must be called for the active occlusion query")
+	}
+	activeOcclusionQuery = nil
+	q.ended = true
+	atlas.EndOcclusionQuery()
+}
+
+// ResultAvailable reports whether the result of the query is available.
+//
+// ResultAvailable returns false until End is called.
+func (q *OcclusionQuery) ResultAvailable() bool {
+	if !q.ended {
+		return false
+	}
+	_, ok := q.query.Result()
+	return ok
+}
+
+// SampleCount returns the number of the samples rendered between BeginOcclusionQuery and End.
+//
+// SampleCount returns 0 if the result is not available yet. Use ResultAvailable to check the availability.
+//
+// With OpenGL ES and WebGL, the number is not accurate and SampleCount returns 1 if any samples are rendered.
+//
+// If occlusion queries are not available in the current environment, e.g. with Metal or DirectX 12,
+// SampleCount returns -1.
+func (q *OcclusionQuery) SampleCount() int {
+	if !q.ended {
+		return 0
+	}
+	n, ok := q.query.Result()
+	if !ok {
+		return 0
+	}
+	return int(n)
+}
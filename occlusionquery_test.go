@@ -0,0 +1,56 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestOcclusionQuery(t *testing.T) {
+	dst := ebiten.NewImage(16, 16)
+	q := ebiten.BeginOcclusionQuery()
+	if q.ResultAvailable() {
+		t.Errorf("ResultAvailable(): got: true, want: false")
+	}
+	dst.Fill(color.White)
+	q.End()
+
+	// Flush the commands.
+	if got, want := dst.At(0, 0), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	if !q.ResultAvailable() {
+		return
+	}
+	if got := q.SampleCount(); got == 0 {
+		t.Errorf("SampleCount(): got: 0, want: non-zero")
+	}
+}
+
+func TestOcclusionQueryNested(t *testing.T) {
+	q := ebiten.BeginOcclusionQuery()
+	defer q.End()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("BeginOcclusionQuery must panic but not")
+		}
+	}()
+	ebiten.BeginOcclusionQuery()
+}
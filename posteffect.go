@@ -0,0 +1,113 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/atlas"
+)
+
+// PostEffect is a shader pass applied to the offscreen before the final screen is rendered.
+type PostEffect struct {
+	// Shader is the shader of the pass.
+	//
+	// The source image 0 of the shader is the result of the previous pass, or the offscreen for the first pass.
+	// The shader is applied to the whole region, and the result has the same size as the offscreen.
+	// The result replaces the destination pixels without blending.
+	Shader *Shader
+
+	// Uniforms is a set of uniform variables for the shader.
+	// See DrawRectShaderOptions's Uniforms for details.
+	Uniforms map[string]any
+
+	// Images are the source images 1, 2, and 3 of the shader.
+	// The images must have the same size as the offscreen.
+	Images [3]*Image
+}
+
+var thePostEffects atomic.Pointer[[]PostEffect]
+
+// SetPostEffects sets the shader passes applied to the offscreen before the final screen is rendered.
+//
+// The effects are applied in order. Each effect takes the result of the previous effect as the source image 0,
+// so effects like a CRT filter, bloom, and color grading can be stacked.
+// The intermediate images are allocated and reused automatically.
+//
+// If the game implements FinalScreenDrawer, the offscreen passed to DrawFinalScreen is the result of the effects.
+//
+// If effects is empty, no post effects are applied. No post effects are applied by default.
+//
+// SetPostEffects copies the slice, but doesn't copy the uniform maps.
+// Call SetPostEffects again with new maps to update uniform values like time.
+//
+// SetPostEffects is concurrent-safe.
+func SetPostEffects(effects []PostEffect) {
+	if len(effects) == 0 {
+		thePostEffects.Store(nil)
+		return
+	}
+	for _, e := range effects {
+		if e.Shader == nil {
+			panic("ebiten: PostEffect's Shader must not be nil")
+		}
+	}
+	es := make([]PostEffect, len(effects))
+	copy(es, effects)
+	thePostEffects.Store(&es)
+}
+
+// applyPostEffects applies the post effects to the offscreen, and returns the result.
+// If there are no post effects, applyPostEffects returns the offscreen as it is.
+func (g *gameForUI) applyPostEffects() *Image {
+	p := thePostEffects.Load()
+	if p == nil {
+		return g.offscreen
+	}
+
+	bounds := g.offscreen.Bounds()
+	src := g.offscreen
+	for i, e := range *p {
+		// Use two images alternately as the destination and the source.
+		dst := g.postEffectImages[i%2]
+		if dst == nil || dst.Bounds() != bounds {
+			if dst != nil {
+				dst.Deallocate()
+			}
+			// Keep the image unmanaged for the screen shader as well as the offscreen.
+			dst = newImage(bounds, atlas.ImageTypeUnmanaged, g.offscreen.format)
+			g.postEffectImages[i%2] = dst
+		}
+
+		op := &DrawRectShaderOptions{}
+		op.Blend = BlendCopy
+		op.Uniforms = e.Uniforms
+		op.Images[0] = src
+		copy(op.Images[1:], e.Images[:])
+		dst.DrawRectShader(bounds.Dx(), bounds.Dy(), e.Shader, op)
+		src = dst
+	}
+	return src
+}
+
+func (g *gameForUI) deallocatePostEffectImages() {
+	for i, img := range g.postEffectImages {
+		if img == nil {
+			continue
+		}
+		img.Deallocate()
+		g.postEffectImages[i] = nil
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"sync/atomic"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/clock"
-	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
 	"github.com/hajimehoshi/ebiten/v2/internal/ui"
 )
 
@@ -131,6 +130,40 @@ type FinalScreenDrawer interface {
 	DrawFinalScreen(screen FinalScreen, offscreen *Image, geoM GeoM)
 }
 
+// DrawStateCommitter is an optional interface for a game to hand off its state from Update to Draw.
+// This is useful especially with RunGameOptions.PipelinedUpdate.
+type DrawStateCommitter interface {
+	// CommitDrawState is called every frame after all the Update calls of the frame finish and before Draw is called.
+	// CommitDrawState should copy the state that Draw reads, so that Draw doesn't have to read the state that Update writes.
+	//
+	// CommitDrawState is called on the same goroutine as Draw.
+	CommitDrawState()
+}
+
+// DamageReporter is an optional interface for a game to report the region of the screen to be redrawn.
+// This is useful to reduce GPU work for games whose screen is mostly static, like board games, editors and UIs.
+//
+// If a game implements DamageReporter, Draw is called with a sub-image of the screen for the damaged region.
+// The coordinates of the sub-image are the same as the screen's, and drawing outside the region is clipped.
+// Use the size returned by Layout instead of the screen's bounds to lay out the screen.
+//
+// If the screen is cleared every frame, only the damaged region is cleared, and the rest of the screen is kept as it was.
+//
+// When the damaged region is not empty, the whole screen is still composited and presented,
+// as partial presentation is not available on all the platforms.
+// When Damage keeps returning an empty rectangle for a while, presenting the screen is skipped,
+// which is useful to save GPU work and battery for a static screen.
+type DamageReporter interface {
+	// Damage returns the region of the screen that needs to be redrawn in the current frame.
+	// Damage is called every frame before Draw, on the same goroutine as Draw.
+	//
+	// If Damage returns an empty rectangle, Draw is not called in the frame.
+	//
+	// Ebitengine might ignore the returned region and redraw the whole screen,
+	// e.g. when the screen is created for the first time or resized.
+	Damage() image.Rectangle
+}
+
 // DefaultTPS represents a default ticks per second, that represents how many times game updating happens in a second.
 const DefaultTPS = clock.DefaultTPS
 
@@ -179,12 +212,13 @@ func IsScreenClearedEveryFrame() bool {
 // The "screen" filter is a box filter from game to display resolution.
 //
 // If disabled, nearest-neighbor filtering will be used for scaling instead.
+// SetScreenFilterEnabled affects only when RunGameOptions's ScreenFilter is ScreenFilterDefault.
 //
 // The default state is true.
 //
 // SetScreenFilterEnabled is concurrent-safe, but takes effect only at the next Draw call.
 //
-// Deprecated: as of v2.5. Use FinalScreenDrawer instead.
+// Deprecated: as of v2.5. Use RunGameOptions's ScreenFilter or FinalScreenDrawer instead.
 func SetScreenFilterEnabled(enabled bool) {
 	screenFilterEnabled.Store(enabled)
 }
@@ -286,12 +320,46 @@ type RunGameOptions struct {
 
 	// ColorSpace indicates the color space of the screen.
 	//
-	// ColorSpace is available only with some graphics libraries (macOS Metal and WebGL so far).
-	// Otherwise, ColorSpace is ignored.
+	// ColorSpace is available only with some graphics libraries (Metal on macOS and iOS 16.0 or later, and WebGL so far).
+	// Otherwise, ColorSpace is ignored. For example, DirectX always uses sRGB, and HDR10 output is not supported.
+	// Use ScreenColorSpace to know the color space actually used.
+	//
+	// ColorSpaceLinearSRGB is an exception and changes how the screen is rendered. See the comment of ColorSpaceLinearSRGB.
 	//
 	// The default (zero) value is ColorSpaceDefault, which means that color space depends on the environment.
 	ColorSpace ColorSpace
 
+	// PipelinedUpdate indicates whether the pipelined update mode is used or not.
+	//
+	// In the pipelined update mode, Update calls for a frame run on another goroutine concurrently with Draw,
+	// which draws the state of the previous frame.
+	// This utilizes multicore CPUs better for games whose Update is the bottleneck,
+	// in the expense of one frame of latency.
+	//
+	// The data hand-off rules in the pipelined update mode are:
+	//
+	//   - Draw must not read the data that Update writes. Use DrawStateCommitter to copy the data for Draw.
+	//   - CommitDrawState is called on Draw's goroutine while Update is not running.
+	//   - Update and Draw must not use the same *Image concurrently. Using different images is fine.
+	//   - Input functions like IsKeyPressed can be called from both Update and Draw.
+	//
+	// The first Update call is never pipelined, so Update can still be used for initialization.
+	//
+	// PipelinedUpdate is ignored in the single thread mode and on browsers.
+	//
+	// The default (zero) value is false, which means that Update and Draw are called on the same goroutine in turn.
+	PipelinedUpdate bool
+
+	// Atlas is options for the internal texture atlases.
+	//
+	// The default (zero) value uses the default values for all the options.
+	Atlas AtlasOptions
+
+	// ScreenFilter is the type of filter to render the offscreen onto the final screen.
+	//
+	// The default (zero) value is ScreenFilterDefault.
+	ScreenFilter ScreenFilter
+
 	// X11DisplayName is a class name in the ICCCM WM_CLASS window property.
 	X11ClassName string
 
@@ -310,7 +378,7 @@ type RunGameOptions struct {
 // The argument screen represents the final screen. The argument offscreen is an offscreen modified at Draw.
 // If game does not implement FinalScreenDrawer, the default rendering for the final screen is used.
 //
-// game's functions are called on the same goroutine.
+// game's functions are called on the same goroutine, unless options.PipelinedUpdate is true.
 //
 // On browsers, it is strongly recommended to use iframe if you embed an Ebitengine application in your website.
 //
@@ -340,7 +408,7 @@ func RunGameWithOptions(game Game, options *RunGameOptions) error {
 	op := toUIRunOptions(options)
 	// This is necessary to change the result of IsScreenTransparent.
 	screenTransparent.Store(op.ScreenTransparent)
-	g := newGameForUI(game, op.ScreenTransparent)
+	g := newGameForUI(game, op.ScreenTransparent, options)
 
 	if err := ui.Get().Run(g, op); err != nil {
 		if errors.Is(err, Termination) {
@@ -722,7 +790,10 @@ func toUIRunOptions(options *RunGameOptions) *ui.RunOptions {
 		SkipTaskbar:       options.SkipTaskbar,
 		SingleThread:      options.SingleThread,
 		DisableHiDPI:      options.DisableHiDPI,
-		ColorSpace:        graphicsdriver.ColorSpace(options.ColorSpace),
+		ColorSpace:        options.ColorSpace.internalColorSpace(),
+		PipelinedUpdate:   options.PipelinedUpdate,
+		AtlasPolicy:       options.Atlas.internalPolicy(),
+		IntegerScale:      options.ScreenFilter == ScreenFilterIntegerNearest,
 		X11ClassName:      options.X11ClassName,
 		X11InstanceName:   options.X11InstanceName,
 	}
@@ -16,10 +16,16 @@ package ebiten
 
 import (
 	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/hajimehoshi/ebiten/v2/internal/builtinshader"
 	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+	"github.com/hajimehoshi/ebiten/v2/internal/shader"
 	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
 	"github.com/hajimehoshi/ebiten/v2/internal/ui"
 )
@@ -30,22 +36,294 @@ import (
 type Shader struct {
 	shader *ui.Shader
 	unit   shaderir.Unit
+
+	// dualSource reports whether the shader returns a secondary source color for dual-source blending.
+	dualSource bool
+
+	// renderTargetCount is the number of the images the shader renders to at once.
+	renderTargetCount int
+
+	// compile compiles the shader with the lengths of the runtime-sized uniform arrays.
+	// compile is nil if the shader has no runtime-sized uniform arrays.
+	compile func(arrayLengths map[string]int) (*shaderir.Program, error)
+
+	runtimeSizedUniforms []runtimeSizedUniform
+
+	// variants is the shaders compiled for the lengths of the runtime-sized uniform arrays.
+	variants map[string]*Shader
+
+	tmpLengths []byte
+
+	// importResolver is the import resolver used at the creation. importResolver is used at ReplaceShader.
+	importResolver shader.ImportResolver
+
+	// src is the source with the expanded imports. src is used to compile the shaders for debugging.
+	src []byte
+
+	// arrayLengths is the lengths of the runtime-sized uniform arrays this shader is compiled with.
+	arrayLengths map[string]int
+
+	debugCalls   []shaderir.DebugCall
+	debugShaders [shader.DebugMaxComponentCount]*ui.Shader
+}
+
+type runtimeSizedUniform struct {
+	name string
+
+	// elementUint32Count is the number of uint32 values for one element.
+	elementUint32Count int
+
+	// structArray reports whether the array is an array of a struct.
+	// The length is the number of the elements of the given value.
+	structArray bool
 }
 
 // NewShader compiles a shader program in the shading language Kage, and returns the result.
 //
 // If the compilation fails, NewShader returns an error.
 //
+// A uniform variable can be an array without a length like `var Lights []vec4`.
+// The length is determined by the value given at drawing, and len for the array returns the length.
+// A shader is compiled for each distinct length internally and the result is cached.
+// The maximum size of uniform variables depends on the environment.
+//
+// A global variable initialized with an array literal of constants like `var Bayer = [16]float{0, 8, 2, 10, ...}` is a read-only lookup table.
+// The values are embedded in the shader and are not uploaded at drawing. The element type must be int, float, ivecN, or vecN.
+// A vector element is written with a constructor like vec2(1, 2). `const` is also available instead of `var` for such an array.
+//
+// A for-loop's condition can compare the counter with a non-constant value like a uniform variable.
+// The value is evaluated at every iteration, and such a loop is not unrolled.
+// The counter must move toward the value so that the loop always terminates.
+//
+// The bitwise operators &, |, ^, &^, <<, >> and the unary operator ^ are available for int and ivecN values.
+// Integer uniform variables like `var TileIndex int` or `var Flags ivec4` take integer values.
+//
+// A switch statement is available. fallthrough is not supported.
+// If the tag is not an integer or a case value is not an integer constant, break is available only at the end of a clause.
+//
+// A shader can import common functions from other sources with a directive like `//kage:import "mylib/noise"`.
+// Use NewShaderWithOptions with an import resolver for such a shader.
+//
+// Debugf like `Debugf("pos: %v", srcPos)` outputs values in the Fragment function. See SetShaderDebug.
+//
+// The Fragment function can return a struct of up to 4 vec4 members to render to multiple images at once.
+// The i-th member is rendered to the destination image when i is 0, or to DrawTrianglesShaderOptions.AdditionalDstImages[i-1] otherwise.
+//
+//	type Output struct {
+//		Color  vec4
+//		Normal vec4
+//	}
+//
+//	func Fragment(dstPos vec4, srcPos vec2, color vec4) Output {
+//		return Output{Color: color, Normal: vec4(0, 0, 1, 1)}
+//	}
+//
+// An optional VertexPosition function transforms the destination position of each vertex on the GPU.
+// pos is the vertex position in pixels relative to the upper-left corner of the destination image's bounds.
+// custom is Custom0-3 and custom1 is Custom4-7 of the vertex.
+// For example, 2D skeletal animation can be skinned with bone matrices given as a uniform variable:
+//
+//	var Bones [64]mat4
+//
+//	func VertexPosition(pos vec2, custom vec4, custom1 vec4) vec2 {
+//		// custom1.xy is bone indices and custom1.zw is their weights.
+//		p := Bones[int(custom1.x)]*vec4(pos, 0, 1)*custom1.z + Bones[int(custom1.y)]*vec4(pos, 0, 1)*custom1.w
+//		return p.xy
+//	}
+//
 // For the details about the shader, see https://ebitengine.org/en/documents/shader.html.
 func NewShader(src []byte) (*Shader, error) {
+	return NewShaderWithOptions(src, nil)
+}
+
+// NewShaderOptions represents options for NewShaderWithOptions.
+type NewShaderOptions struct {
+	// ImportResolver returns the source of a module imported by a `//kage:import` directive.
+	// The argument is the path specified in the directive.
+	//
+	// A module is a Kage source with a package clause, and can import other modules.
+	// The top-level declarations of a module are available in the importing shader as they are, without a package name.
+	// Each module is included only once even if it is imported multiple times.
+	//
+	// If ImportResolver is nil, a shader with `//kage:import` directives cannot be compiled.
+	ImportResolver func(path string) ([]byte, error)
+}
+
+// NewShaderWithOptions compiles a shader program in the shading language Kage with the given options, and returns the result.
+//
+// If options is nil, NewShaderWithOptions behaves as NewShader.
+//
+// If the compilation fails, NewShaderWithOptions returns an error.
+func NewShaderWithOptions(src []byte, options *NewShaderOptions) (*Shader, error) {
+	var resolve shader.ImportResolver
+	if options != nil {
+		resolve = options.ImportResolver
+	}
+	src, err := shader.ExpandImports(src, resolve)
+	if err != nil {
+		return nil, err
+	}
+
 	ir, err := graphics.CompileShader(src)
 	if err != nil {
 		return nil, err
 	}
-	return &Shader{
-		shader: ui.NewShader(ir),
-		unit:   ir.Unit,
-	}, nil
+	s := &Shader{
+		shader:            ui.NewShader(ir),
+		unit:              ir.Unit,
+		dualSource:        ir.FragmentFunc.OutputCount > 1 && !ir.FragmentFunc.MultipleRenderTargets,
+		renderTargetCount: renderTargetCount(ir),
+		importResolver:    resolve,
+		src:               src,
+		debugCalls:        ir.DebugCalls,
+	}
+	s.initRuntimeSizedUniforms(ir, func(arrayLengths map[string]int) (*shaderir.Program, error) {
+		return graphics.CompileShaderWithArrayLengths(src, arrayLengths)
+	})
+	return s, nil
+}
+
+// ReplaceShader compiles the source and replaces the shader program of old with the result in place.
+//
+// All the references to old are kept valid, and the following draw calls with old use the new program.
+// The draw calls before ReplaceShader are not affected.
+// The import resolver given at the creation of old is used for the new source.
+//
+// ReplaceShader is useful to reload a shader while the game is running.
+// ebitenutil.ShaderWatcher reloads shaders automatically when their files are modified.
+//
+// If the compilation fails, ReplaceShader returns an error and old is kept as it is.
+//
+// If old is disposed, ReplaceShader returns an error.
+func ReplaceShader(old *Shader, src []byte) error {
+	if old.isDisposed() {
+		return fmt.Errorf("ebiten: the shader is already disposed")
+	}
+
+	s, err := NewShaderWithOptions(src, &NewShaderOptions{
+		ImportResolver: old.importResolver,
+	})
+	if err != nil {
+		return err
+	}
+
+	old.deallocate()
+	*old = *s
+	return nil
+}
+
+// SetShaderCache sets a file system having precompiled shader binaries generated by the kagec command.
+//
+// When a shader is compiled for the GPU, its precompiled binary is used if the file system has it.
+// This eliminates the hitch of the shader compilation at the first use.
+// If the binary doesn't exist, the shader is compiled as usual.
+// The binaries are identified by the shader sources. If a source is modified, its binary is just ignored.
+//
+// The binaries depend on the Ebitengine version. Generate the binaries again when updating Ebitengine.
+//
+// SetShaderCache should be called before creating shaders.
+// If fsys is nil, the shader cache is not used.
+//
+// The shader cache is currently available only with Metal and DirectX 11/12.
+// Otherwise, SetShaderCache does nothing.
+//
+// For the usage of kagec, run `go run github.com/hajimehoshi/ebiten/v2/cmd/kagec -h`.
+func SetShaderCache(fsys fs.FS) {
+	graphicsdriver.SetShaderCache(fsys)
+}
+
+func renderTargetCount(ir *shaderir.Program) int {
+	if !ir.FragmentFunc.MultipleRenderTargets || ir.FragmentFunc.OutputCount == 0 {
+		return 1
+	}
+	return ir.FragmentFunc.OutputCount
+}
+
+func (s *Shader) initRuntimeSizedUniforms(ir *shaderir.Program, compile func(arrayLengths map[string]int) (*shaderir.Program, error)) {
+	if len(ir.RuntimeSizedUniforms) == 0 {
+		return
+	}
+	s.compile = compile
+	for _, i := range ir.RuntimeSizedUniforms {
+		name := ir.UniformNames[i]
+		// A member of a struct array like "Lights.Color" has the length of the struct array "Lights".
+		if root, _, ok := strings.Cut(name, "."); ok {
+			if len(s.runtimeSizedUniforms) > 0 && s.runtimeSizedUniforms[len(s.runtimeSizedUniforms)-1].name == root {
+				continue
+			}
+			s.runtimeSizedUniforms = append(s.runtimeSizedUniforms, runtimeSizedUniform{
+				name:        root,
+				structArray: true,
+			})
+			continue
+		}
+		s.runtimeSizedUniforms = append(s.runtimeSizedUniforms, runtimeSizedUniform{
+			name:               name,
+			elementUint32Count: ir.Uniforms[i].Sub[0].Uint32Count(),
+		})
+	}
+}
+
+// variant returns the shader compiled for the lengths of the runtime-sized uniform arrays in uniforms.
+func (s *Shader) variant(uniforms map[string]any) *Shader {
+	if s.compile == nil {
+		return s
+	}
+
+	s.tmpLengths = s.tmpLengths[:0]
+	var lengths map[string]int
+	for _, u := range s.runtimeSizedUniforms {
+		var l int
+		if v, ok := uniforms[u.name]; ok {
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				panic(fmt.Sprintf("ebiten: the uniform variable %s must be a slice or an array", u.name))
+			}
+			if u.structArray {
+				l = rv.Len()
+			} else {
+				if rv.Len()%u.elementUint32Count != 0 {
+					panic(fmt.Sprintf("ebiten: the length of the uniform variable %s must be a multiple of %d but %d", u.name, u.elementUint32Count, rv.Len()))
+				}
+				l = rv.Len() / u.elementUint32Count
+			}
+		}
+		if l > 0 {
+			if lengths == nil {
+				lengths = map[string]int{}
+			}
+			lengths[u.name] = l
+		}
+		s.tmpLengths = strconv.AppendInt(s.tmpLengths, int64(l), 10)
+		s.tmpLengths = append(s.tmpLengths, ',')
+	}
+
+	// The shader itself is compiled with the length 0 for all the arrays.
+	if lengths == nil {
+		return s
+	}
+	if v, ok := s.variants[string(s.tmpLengths)]; ok {
+		return v
+	}
+
+	ir, err := s.compile(lengths)
+	if err != nil {
+		panic(fmt.Sprintf("ebiten: compiling a shader for the runtime-sized uniform arrays failed: %v", err))
+	}
+	v := &Shader{
+		shader:            ui.NewShader(ir),
+		unit:              s.unit,
+		dualSource:        s.dualSource,
+		renderTargetCount: s.renderTargetCount,
+		src:               s.src,
+		arrayLengths:      lengths,
+		debugCalls:        ir.DebugCalls,
+	}
+	if s.variants == nil {
+		s.variants = map[string]*Shader{}
+	}
+	s.variants[string(s.tmpLengths)] = v
+	return v
 }
 
 // Dispose disposes the shader program.
@@ -53,8 +331,10 @@ func NewShader(src []byte) (*Shader, error) {
 //
 // Deprecated: as of v2.7. Use Deallocate instead.
 func (s *Shader) Dispose() {
-	s.shader.Deallocate()
+	s.deallocate()
 	s.shader = nil
+	s.variants = nil
+	s.debugShaders = [shader.DebugMaxComponentCount]*ui.Shader{}
 }
 
 func (s *Shader) isDisposed() bool {
@@ -74,7 +354,20 @@ func (s *Shader) Deallocate() {
 	if s.shader == nil {
 		return
 	}
+	s.deallocate()
+}
+
+// deallocate deallocates the internal states of the shader, its variants, and its shaders for debugging.
+func (s *Shader) deallocate() {
 	s.shader.Deallocate()
+	for _, ds := range s.debugShaders {
+		if ds != nil {
+			ds.Deallocate()
+		}
+	}
+	for _, v := range s.variants {
+		v.deallocate()
+	}
 }
 
 func (s *Shader) appendUniforms(dst []uint32, uniforms map[string]any) []uint32 {
@@ -82,11 +375,11 @@ func (s *Shader) appendUniforms(dst []uint32, uniforms map[string]any) []uint32
 }
 
 var (
-	builtinShaders  [builtinshader.FilterCount][builtinshader.AddressCount][2]*Shader
+	builtinShaders  [builtinshader.FilterCount][builtinshader.AddressCount][builtinshader.AddressCount][2][2]*Shader
 	builtinShadersM sync.Mutex
 )
 
-func builtinShader(filter builtinshader.Filter, address builtinshader.Address, useColorM bool) *Shader {
+func builtinShader(filter builtinshader.Filter, addressX, addressY builtinshader.Address, useColorM, gammaCorrect bool) *Shader {
 	builtinShadersM.Lock()
 	defer builtinShadersM.Unlock()
 
@@ -94,20 +387,25 @@ func builtinShader(filter builtinshader.Filter, address builtinshader.Address, u
 	if useColorM {
 		c = 1
 	}
-	if s := builtinShaders[filter][address][c]; s != nil {
+	var l int
+	if gammaCorrect {
+		l = 1
+	}
+	if s := builtinShaders[filter][addressX][addressY][c][l]; s != nil {
 		return s
 	}
 
 	var shader *Shader
-	if address == builtinshader.AddressUnsafe && !useColorM {
+	if addressX == builtinshader.AddressUnsafe && addressY == builtinshader.AddressUnsafe && !useColorM && !gammaCorrect {
 		switch filter {
 		case builtinshader.FilterNearest:
 			shader = &Shader{shader: ui.NearestFilterShader}
 		case builtinshader.FilterLinear:
 			shader = &Shader{shader: ui.LinearFilterShader}
 		}
-	} else {
-		src := builtinshader.ShaderSource(filter, address, useColorM)
+	}
+	if shader == nil {
+		src := builtinshader.ShaderSource(filter, addressX, addressY, useColorM, gammaCorrect)
 		s, err := NewShader(src)
 		if err != nil {
 			panic(fmt.Sprintf("ebiten: NewShader for a built-in shader failed: %v", err))
@@ -115,6 +413,6 @@ func builtinShader(filter builtinshader.Filter, address builtinshader.Address, u
 		shader = s
 	}
 
-	builtinShaders[filter][address][c] = shader
+	builtinShaders[filter][addressX][addressY][c][l] = shader
 	return shader
 }
@@ -19,6 +19,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"reflect"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -2300,6 +2301,108 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
 	}
 }
 
+func TestShaderConstArray(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+var Bayer = [16]int{
+	0, 8, 2, 10,
+	12, 4, 14, 6,
+	3, 11, 1, 9,
+	15, 7, 13, 5,
+}
+
+const colors = [2]vec2{vec2(1, 0), vec2(0.5)}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	p := ivec2(dstPos.xy - imageDstOrigin())
+	v := float(Bayer[(p.y%4)*4+p.x%4]) / 16
+	return vec4(v, colors[p.x%2], 1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(w, h)
+	dst.DrawRectShader(w, h, s, nil)
+
+	bayer := [16]int{0, 8, 2, 10, 12, 4, 14, 6, 3, 11, 1, 9, 15, 7, 13, 5}
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{R: uint8(bayer[(j%4)*4+i%4] * 0xff / 16), A: 0xff}
+			if i%2 == 0 {
+				want.G = 0xff
+			} else {
+				want.G = 0x80
+				want.B = 0x80
+			}
+			if !sameColors(got, want, 2) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestShaderBitwiseClearAndComplementOperator(t *testing.T) {
+	const w, h = 16, 16
+
+	src := ebiten.NewImage(w, h)
+	src.Fill(color.RGBA{R: 0x24, G: 0x3f, B: 0x6a, A: 0xff})
+
+	for _, assign := range []bool{false, true} {
+		assign := assign
+		name := "op"
+		if assign {
+			name = "op+assign"
+		}
+		t.Run(name, func(t *testing.T) {
+			var code string
+			if assign {
+				code = `	v.rgb &^= 0x0f
+	v.rgb = ^v.rgb
+	v.rgb &= 0xff`
+			} else {
+				code = `	v.rgb = v.rgb &^ 0x0f
+	v.rgb = ^v.rgb & 0xff`
+			}
+
+			s, err := ebiten.NewShader([]byte(fmt.Sprintf(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	v := ivec4(imageSrc0At(srcPos) * 0xff)
+%s
+	return vec4(v) / 0xff;
+}
+`, code)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dst := ebiten.NewImage(w, h)
+			op := &ebiten.DrawRectShaderOptions{}
+			op.Images[0] = src
+			dst.DrawRectShader(w, h, s, op)
+
+			for j := 0; j < h; j++ {
+				for i := 0; i < w; i++ {
+					got := dst.At(i, j).(color.RGBA)
+					want := color.RGBA{R: 0xdf, G: 0xcf, B: 0x9f, A: 0xff}
+					if !sameColors(got, want, 2) {
+						t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestShaderDispose(t *testing.T) {
 	const w, h = 16, 16
 
@@ -2683,6 +2786,52 @@ func Fragment(dstPos vec4, srcPos vec2, color vec4, custom vec4) vec4 {
 	}
 }
 
+func TestShaderMoreCustomValues(t *testing.T) {
+	const w, h = 16, 16
+
+	dst := ebiten.NewImage(w, h)
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4, custom0 vec4, custom1 vec4) vec4 {
+	return custom1
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clr := color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0x40}
+	vs := []ebiten.Vertex{
+		{DstX: 0, DstY: 0},
+		{DstX: w, DstY: 0},
+		{DstX: 0, DstY: h},
+		{DstX: w, DstY: h},
+	}
+	for i := range vs {
+		vs[i].Custom0 = 1
+		vs[i].Custom1 = 1
+		vs[i].Custom2 = 1
+		vs[i].Custom3 = 1
+		vs[i].Custom4 = float32(clr.R) / 0xff
+		vs[i].Custom5 = float32(clr.G) / 0xff
+		vs[i].Custom6 = float32(clr.B) / 0xff
+		vs[i].Custom7 = float32(clr.A) / 0xff
+	}
+	dst.DrawTrianglesShader(vs, []uint16{0, 1, 2, 1, 2, 3}, s, nil)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := clr
+			if !sameColors(got, want, 2) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
 func TestShaderFragmentLessArguments(t *testing.T) {
 	const w, h = 16, 16
 
@@ -2785,3 +2934,730 @@ func Fragment(dstPos vec4, srcPos vec2) vec4 {
 		}
 	}
 }
+
+func TestShaderDualSourceBlend(t *testing.T) {
+	const w, h = 16, 16
+
+	dst := ebiten.NewImage(w, h)
+	dst.Fill(color.White)
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) (vec4, vec4) {
+	return vec4(0, 0, 0, 1), vec4(1, 0, 0, 1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Blend = ebiten.Blend{
+		BlendFactorSourceRGB:        ebiten.BlendFactorOne,
+		BlendFactorSourceAlpha:      ebiten.BlendFactorOne,
+		BlendFactorDestinationRGB:   ebiten.BlendFactorOneMinusSourceColor1,
+		BlendFactorDestinationAlpha: ebiten.BlendFactorOneMinusSourceAlpha1,
+		BlendOperationRGB:           ebiten.BlendOperationAdd,
+		BlendOperationAlpha:         ebiten.BlendOperationAdd,
+	}
+	dst.DrawRectShader(w, h, s, op)
+
+	want := color.RGBA{G: 0xff, B: 0xff, A: 0xff}
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureDualSourceBlending) {
+		// Without dual-source blending, the primary source color is used instead of the secondary one.
+		want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestShaderDualSourceBlendWithSingleColor(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawRectShader must panic but not")
+		}
+	}()
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(16, 16)
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Blend = ebiten.Blend{
+		BlendFactorSourceRGB:        ebiten.BlendFactorOne,
+		BlendFactorSourceAlpha:      ebiten.BlendFactorOne,
+		BlendFactorDestinationRGB:   ebiten.BlendFactorOneMinusSourceColor1,
+		BlendFactorDestinationAlpha: ebiten.BlendFactorOneMinusSourceAlpha1,
+	}
+	dst.DrawRectShader(16, 16, s, op)
+}
+
+func TestShaderMultipleRenderTargets(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+type Output struct {
+	Color    vec4
+	Normal   vec4
+	Emissive vec4
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) Output {
+	return Output{
+		Color:    imageSrc0At(srcPos),
+		Emissive: vec4(0, 0, 1, 1),
+		Normal:   vec4(0, 1, 0, 1),
+	}
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := ebiten.NewImage(w, h)
+	src.Fill(color.RGBA{R: 0xff, A: 0xff})
+
+	dst := ebiten.NewImage(w, h)
+	normal := ebiten.NewImage(w, h)
+	emissive := ebiten.NewImage(w, h)
+
+	vs := []ebiten.Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: 0, SrcX: w, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: 0, DstY: h, SrcX: 0, SrcY: h, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: w, DstY: h, SrcX: w, SrcY: h, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+	is := []uint16{0, 1, 2, 1, 2, 3}
+	op := &ebiten.DrawTrianglesShaderOptions{}
+	op.Images[0] = src
+	op.AdditionalDstImages[0] = normal
+	op.AdditionalDstImages[1] = emissive
+	dst.DrawTrianglesShader(vs, is, s, op)
+
+	for _, tc := range []struct {
+		name string
+		img  *ebiten.Image
+		want color.RGBA
+	}{
+		{name: "dst", img: dst, want: color.RGBA{R: 0xff, A: 0xff}},
+		{name: "normal", img: normal, want: color.RGBA{G: 0xff, A: 0xff}},
+		{name: "emissive", img: emissive, want: color.RGBA{B: 0xff, A: 0xff}},
+	} {
+		for j := 0; j < h; j++ {
+			for i := 0; i < w; i++ {
+				got := tc.img.At(i, j).(color.RGBA)
+				if got != tc.want {
+					t.Errorf("%s.At(%d, %d): got: %v, want: %v", tc.name, i, j, got, tc.want)
+				}
+			}
+		}
+	}
+
+	// The images must be usable as usual after rendering with multiple render targets.
+	dst.DrawImage(normal, nil)
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{G: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestShaderMultipleRenderTargetsWithWrongImageCount(t *testing.T) {
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+type Output struct {
+	Color  vec4
+	Normal vec4
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) Output {
+	return Output{Color: color, Normal: color}
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(16, 16)
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("DrawRectShader must panic but not")
+			}
+		}()
+		dst.DrawRectShader(16, 16, s, nil)
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("DrawTrianglesShader must panic but not")
+			}
+		}()
+		vs := []ebiten.Vertex{{}, {DstX: 16}, {DstY: 16}}
+		dst.DrawTrianglesShader(vs, []uint16{0, 1, 2}, s, nil)
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("DrawTrianglesShader must panic but not")
+			}
+		}()
+		vs := []ebiten.Vertex{{}, {DstX: 16}, {DstY: 16}}
+		op := &ebiten.DrawTrianglesShaderOptions{}
+		op.AdditionalDstImages[0] = ebiten.NewImage(8, 8)
+		dst.DrawTrianglesShader(vs, []uint16{0, 1, 2}, s, op)
+	}()
+}
+
+func TestShaderEightSourceImages(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return imageSrc0At(srcPos) + imageSrc1At(srcPos) + imageSrc2At(srcPos) + imageSrc3At(srcPos) +
+		imageSrc4At(srcPos) + imageSrc5At(srcPos) + imageSrc6At(srcPos) + imageSrc7At(srcPos)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := &ebiten.DrawRectShaderOptions{}
+	for i := 0; i < 8; i++ {
+		img := ebiten.NewImage(w, h)
+		img.Fill(color.RGBA{R: 0x08, G: byte(2 * i), A: 0x10})
+		if i < len(op.Images) {
+			op.Images[i] = img
+		} else {
+			op.ExtraImages[i-len(op.Images)] = img
+		}
+	}
+
+	dst := ebiten.NewImage(w, h)
+	dst.DrawRectShader(w, h, s, op)
+
+	want := color.RGBA{R: 0x40, G: 2 * (0 + 1 + 2 + 3 + 4 + 5 + 6 + 7), A: 0x80}
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if got := dst.At(i, j); got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestShaderRuntimeSizedUniformArray(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+var Colors []vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var c vec4
+	for i := 0; i < len(Colors); i++ {
+		c += Colors[i]
+	}
+	return c
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(w, h)
+	for _, n := range []int{0, 1, 3, 1, 0} {
+		colors := make([]float32, 4*n)
+		for i := 0; i < n; i++ {
+			colors[4*i] = 0x10 / 255.0
+			colors[4*i+3] = 0x20 / 255.0
+		}
+
+		dst.Clear()
+		op := &ebiten.DrawRectShaderOptions{}
+		op.Uniforms = map[string]any{
+			"Colors": colors,
+		}
+		dst.DrawRectShader(w, h, s, op)
+
+		want := color.RGBA{R: byte(0x10 * n), A: byte(0x20 * n)}
+		for j := 0; j < h; j++ {
+			for i := 0; i < w; i++ {
+				if got := dst.At(i, j).(color.RGBA); !sameColors(got, want, 1) {
+					t.Errorf("n: %d, dst.At(%d, %d): got: %v, want: %v", n, i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestShaderStructUniform(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+type Material struct {
+	Color vec4
+	Scale float
+}
+
+type Light struct {
+	Color vec4
+}
+
+var Mat Material
+var Lights []Light
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := Mat.Color * Mat.Scale
+	for i := 0; i < len(Lights); i++ {
+		c += Lights[i].Color
+	}
+	return c
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type material struct {
+		Color [4]float32
+		Scale float32
+	}
+
+	dst := ebiten.NewImage(w, h)
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Uniforms = map[string]any{
+		"Mat": material{
+			Color: [4]float32{0x10 / 255.0, 0, 0, 0x10 / 255.0},
+			Scale: 2,
+		},
+		"Lights": []map[string]any{
+			{"Color": []float32{0, 0x10 / 255.0, 0, 0x10 / 255.0}},
+			{"Color": []float32{0, 0, 0x10 / 255.0, 0x10 / 255.0}},
+		},
+	}
+	dst.DrawRectShader(w, h, s, op)
+
+	want := color.RGBA{R: 0x20, G: 0x10, B: 0x10, A: 0x40}
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if got := dst.At(i, j).(color.RGBA); !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestShaderTexelAt(t *testing.T) {
+	const w, h = 16, 16
+
+	src := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (j*w + i)
+			pix[idx] = byte(i)
+			pix[idx+1] = byte(j)
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix)
+
+	for _, tc := range []struct {
+		unit string
+		pos  string
+	}{
+		{
+			unit: "pixels",
+			pos:  "srcPos - imageSrc0Origin()",
+		},
+		{
+			unit: "texels",
+			pos:  "(srcPos - imageSrc0Origin()) * imageSrcTextureSize()",
+		},
+	} {
+		tc := tc
+		t.Run(tc.unit, func(t *testing.T) {
+			s, err := ebiten.NewShader([]byte(fmt.Sprintf(`//kage:unit %s
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	// Fetch the mirrored texel.
+	p := ivec2(floor(%s))
+	return imageSrc0TexelAt(ivec2(7, 7) - p)
+}
+`, tc.unit, tc.pos)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Use a sub-image to check that the position is relative to the image's origin.
+			const ox, oy = 4, 6
+			dst := ebiten.NewImage(8, 8)
+			op := &ebiten.DrawRectShaderOptions{}
+			op.Images[0] = src.SubImage(image.Rect(ox, oy, ox+8, oy+8)).(*ebiten.Image)
+			dst.DrawRectShader(8, 8, s, op)
+
+			for j := 0; j < 8; j++ {
+				for i := 0; i < 8; i++ {
+					got := dst.At(i, j)
+					want := color.RGBA{R: byte(ox + 7 - i), G: byte(oy + 7 - j), A: 0xff}
+					if got != want {
+						t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestShaderAtLod(t *testing.T) {
+	const w, h = 16, 16
+
+	src := ebiten.NewImage(w, h)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			idx := 4 * (j*w + i)
+			pix[idx] = byte(16 * i)
+			pix[idx+1] = byte(16 * j)
+			pix[idx+3] = 0xff
+		}
+	}
+	src.WritePixels(pix)
+
+	for _, unit := range []string{"pixels", "texels"} {
+		unit := unit
+		t.Run(unit, func(t *testing.T) {
+			s, err := ebiten.NewShader([]byte(fmt.Sprintf(`//kage:unit %s
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	// Sample the texture in non-uniform control flow.
+	if color.r < 0.5 {
+		return vec4(0, 0, 1, 1)
+	}
+	return imageSrc0AtLod(srcPos, 0)
+}
+`, unit)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			const ox, oy = 4, 6
+			dst := ebiten.NewImage(8, 8)
+			op := &ebiten.DrawTrianglesShaderOptions{}
+			op.Images[0] = src.SubImage(image.Rect(ox, oy, ox+8, oy+8)).(*ebiten.Image)
+			// The left half has colors with R = 0.
+			vs := []ebiten.Vertex{
+				{DstX: 0, DstY: 0, SrcX: ox, SrcY: oy, ColorR: 0, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 4, DstY: 0, SrcX: ox + 4, SrcY: oy, ColorR: 0, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 0, DstY: 8, SrcX: ox, SrcY: oy + 8, ColorR: 0, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 4, DstY: 8, SrcX: ox + 4, SrcY: oy + 8, ColorR: 0, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 4, DstY: 0, SrcX: ox + 4, SrcY: oy, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 8, DstY: 0, SrcX: ox + 8, SrcY: oy, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 4, DstY: 8, SrcX: ox + 4, SrcY: oy + 8, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+				{DstX: 8, DstY: 8, SrcX: ox + 8, SrcY: oy + 8, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			}
+			is := []uint16{0, 1, 2, 1, 2, 3, 4, 5, 6, 5, 6, 7}
+			dst.DrawTrianglesShader(vs, is, s, op)
+
+			for j := 0; j < 8; j++ {
+				for i := 0; i < 8; i++ {
+					got := dst.At(i, j)
+					want := color.RGBA{B: 0xff, A: 0xff}
+					if i >= 4 {
+						want = color.RGBA{R: byte(16 * (ox + i)), G: byte(16 * (oy + j)), A: 0xff}
+					}
+					if got != want {
+						t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestShaderImport(t *testing.T) {
+	modules := map[string]string{
+		"mylib/color": `package color
+
+func Invert(c vec4) vec4 {
+	return vec4(c.a-c.rgb, c.a)
+}
+`,
+	}
+	src := []byte(`//kage:unit pixels
+
+package main
+
+//kage:import "mylib/color"
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Invert(imageSrc0At(srcPos))
+}
+`)
+
+	if _, err := ebiten.NewShader(src); err == nil {
+		t.Errorf("NewShader with //kage:import must return an error but not")
+	}
+
+	s, err := ebiten.NewShaderWithOptions(src, &ebiten.NewShaderOptions{
+		ImportResolver: func(path string) ([]byte, error) {
+			m, ok := modules[path]
+			if !ok {
+				return nil, fmt.Errorf("module not found: %s", path)
+			}
+			return []byte(m), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const w, h = 16, 16
+	src0 := ebiten.NewImage(w, h)
+	src0.Fill(color.RGBA{R: 0x40, G: 0x80, B: 0xc0, A: 0xff})
+	dst := ebiten.NewImage(w, h)
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Images[0] = src0
+	dst.DrawRectShader(w, h, s, op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{R: 0xbf, G: 0x7f, B: 0x3f, A: 0xff}
+			if !sameColors(got, want, 1) {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestReplaceShader(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return vec4(1, 0, 0, 1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := ebiten.NewImage(w, h)
+	dst.DrawRectShader(w, h, s, nil)
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{R: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+
+	// An invalid source must not affect the shader.
+	if err := ebiten.ReplaceShader(s, []byte(`package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return undefined
+}
+`)); err == nil {
+		t.Errorf("ReplaceShader with an invalid source must return an error but not")
+	}
+
+	if err := ebiten.ReplaceShader(s, []byte(`//kage:unit pixels
+
+package main
+
+var Color vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return Color
+}
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	dst.Clear()
+	dst.DrawRectShader(w, h, s, &ebiten.DrawRectShaderOptions{
+		Uniforms: map[string]any{
+			"Color": []float32{0, 1, 0, 1},
+		},
+	})
+	if got, want := dst.At(0, 0).(color.RGBA), (color.RGBA{G: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(0, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestShaderDebugf(t *testing.T) {
+	const w, h = 16, 16
+
+	s, err := ebiten.NewShader([]byte(`//kage:unit pixels
+
+package main
+
+var Value float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	if Value >= 0 {
+		Debugf("positive: %v", vec2(Value, 2))
+	} else {
+		Debugf("negative: %d %.2f", int(Value*4), Value)
+	}
+	return vec4(1)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []string
+	ebiten.SetShaderDebug(&ebiten.ShaderDebugOptions{
+		X: 10,
+		Y: 3,
+		Output: func(message string) {
+			msgs = append(msgs, message)
+		},
+	})
+	defer ebiten.SetShaderDebug(nil)
+
+	dst := ebiten.NewImage(w, h)
+	dst.DrawRectShader(w, h, s, &ebiten.DrawRectShaderOptions{
+		Uniforms: map[string]any{
+			"Value": -1.25,
+		},
+	})
+	if got, want := msgs, []string{"negative: -5 -1.25"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	// Debugging must not affect the result.
+	if got, want := dst.At(10, 3).(color.RGBA), (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); got != want {
+		t.Errorf("dst.At(10, 3): got: %v, want: %v", got, want)
+	}
+
+	// A sub-image is inspected at the same position of the original image.
+	msgs = nil
+	dst.SubImage(image.Rect(0, 2, 8, 8)).(*ebiten.Image).DrawRectShader(8, 6, s, nil)
+	if len(msgs) != 0 {
+		t.Errorf("got: %q, want: no messages", msgs)
+	}
+	ebiten.SetShaderDebug(&ebiten.ShaderDebugOptions{
+		X: 3,
+		Y: 5,
+		Output: func(message string) {
+			msgs = append(msgs, message)
+		},
+	})
+	dst.SubImage(image.Rect(0, 2, 8, 8)).(*ebiten.Image).DrawRectShader(8, 6, s, nil)
+	if got, want := msgs, []string{"positive: [0 2]"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestShaderVertexPosition(t *testing.T) {
+	const w, h = 32, 16
+
+	for _, unit := range []string{"pixels", "texels"} {
+		unit := unit
+		for _, bones := range []any{
+			[][16]float32{
+				{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+				{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 8, 0, 0, 1},
+			},
+			[2][16]float32{
+				{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+				{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 8, 0, 0, 1},
+			},
+		} {
+			bones := bones
+			t.Run(fmt.Sprintf("%s/%T", unit, bones), func(t *testing.T) {
+				s, err := ebiten.NewShader([]byte(fmt.Sprintf(`//kage:unit %s
+
+package main
+
+var Bones [2]mat4
+
+func VertexPosition(pos vec2, custom vec4, custom1 vec4) vec2 {
+	return (Bones[int(custom1.x)] * vec4(pos, 0, 1)).xy
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	return color
+}
+`, unit)))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				dst := ebiten.NewImage(w, h)
+				// The positions given to VertexPosition are relative to the sub-image's upper-left corner.
+				// The vertices with the bone 1 are moved by (8, 0).
+				sub := dst.SubImage(image.Rect(8, 0, 24, 16)).(*ebiten.Image)
+				vs := []ebiten.Vertex{
+					{DstX: 8, DstY: 0},
+					{DstX: 16, DstY: 0},
+					{DstX: 8, DstY: 16},
+					{DstX: 16, DstY: 16},
+				}
+				for i := range vs {
+					vs[i].ColorR = 1
+					vs[i].ColorG = 1
+					vs[i].ColorB = 1
+					vs[i].ColorA = 1
+					vs[i].Custom4 = 1
+				}
+				op := &ebiten.DrawTrianglesShaderOptions{}
+				op.Uniforms = map[string]any{
+					"Bones": bones,
+				}
+				sub.DrawTrianglesShader(vs, []uint16{0, 1, 2, 1, 2, 3}, s, op)
+
+				for j := 0; j < h; j++ {
+					for i := 0; i < w; i++ {
+						got := dst.At(i, j).(color.RGBA)
+						var want color.RGBA
+						if 16 <= i && i < 24 {
+							want = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+						}
+						if got != want {
+							t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2/internal/graphics"
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+	"github.com/hajimehoshi/ebiten/v2/internal/shader"
+	"github.com/hajimehoshi/ebiten/v2/internal/shaderir"
+	"github.com/hajimehoshi/ebiten/v2/internal/ui"
+)
+
+// ShaderDebugOptions represents options for SetShaderDebug.
+type ShaderDebugOptions struct {
+	// X and Y are the position to inspect on destination images.
+	X int
+	Y int
+
+	// Output is called with a formatted message of a Debugf call.
+	// If Output is nil, the message is printed by the log package.
+	Output func(message string)
+}
+
+var theShaderDebugOptions atomic.Pointer[ShaderDebugOptions]
+
+// SetShaderDebug enables Debugf in Kage shaders with the given options.
+// If options is nil, Debugf is disabled. Debugf is disabled by default.
+//
+// Debugf outputs values at the specified position on a destination image:
+//
+//	func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+//		Debugf("srcPos: %v, color: %v", srcPos, color.rg)
+//		// ...
+//	}
+//
+// The first argument is a format string literal for the fmt package.
+// The other arguments are int, float, ivecN, or vecN values, and the number of their components must be at most 4 in total.
+// An int value is formatted as an int, a float value as a float32, and a vector as a slice.
+// A value has about 5 significant digits.
+//
+// Debugf is available only in the Fragment function returning one vec4 value.
+// A message is output for each draw call covering the position, and only for the first Debugf call executed for the pixel.
+//
+// When Debugf is enabled, a draw call with a shader having Debugf calls draws the triangles again for debugging
+// and reads the pixel from the GPU. This is slow. The depth and stencil tests are ignored for debugging.
+// When Debugf is disabled, Debugf calls are removed at the compilation and don't have any costs.
+//
+// SetShaderDebug is concurrent-safe.
+func SetShaderDebug(options *ShaderDebugOptions) {
+	if options == nil {
+		theShaderDebugOptions.Store(nil)
+		return
+	}
+	o := *options
+	theShaderDebugOptions.Store(&o)
+}
+
+// shaderDebugImage is the image to render the debugging values. shaderDebugImage is reused for all the debugging.
+var shaderDebugImage *Image
+
+// debug renders the triangles with the shader for debugging and outputs the values of Debugf.
+//
+// The arguments are the same as the ones for the draw call with the shader.
+// debug doesn't modify the arguments.
+func (s *Shader) debug(dst *Image, srcs [graphics.ShaderSrcImageCount]*ui.Image, vertices []float32, indices []uint32, srcRegions [graphics.ShaderSrcImageCount]image.Rectangle, uniforms []uint32) {
+	if len(s.debugCalls) == 0 {
+		return
+	}
+	options := theShaderDebugOptions.Load()
+	if options == nil {
+		return
+	}
+	if !image.Pt(options.X, options.Y).In(dst.Bounds()) {
+		return
+	}
+
+	orig := dst
+	if dst.isSubImage() {
+		orig = dst.original
+	}
+	size := orig.Bounds().Size()
+	if shaderDebugImage == nil || shaderDebugImage.Bounds().Size() != size {
+		if shaderDebugImage != nil {
+			shaderDebugImage.Deallocate()
+		}
+		shaderDebugImage = NewImageWithOptions(image.Rectangle{Max: size}, &NewImageOptions{
+			Unmanaged: true,
+		})
+	}
+
+	x, y := dst.adjustPosition(options.X, options.Y)
+	var call *shaderir.DebugCall
+	var values []float64
+	for c := 0; c < shader.DebugMaxComponentCount; c++ {
+		ds, err := s.debugShader(c)
+		if err != nil {
+			panic(fmt.Sprintf("ebiten: compiling a shader for debugging failed: %v", err))
+		}
+		// Drawing might modify the vertices. Use a copy.
+		vs := make([]float32, len(vertices))
+		copy(vs, vertices)
+		shaderDebugImage.Clear()
+		shaderDebugImage.image.DrawTriangles(srcs, vs, indices, graphicsdriver.BlendCopy, dst.adjustedBounds(), srcRegions, ds, uniforms, graphicsdriver.FillRuleFillAll, graphicsdriver.DepthModeNone, graphicsdriver.Stencil{}, true, false)
+
+		var pix [4]byte
+		shaderDebugImage.image.ReadPixels(pix[:], image.Rect(x, y, x+1, y+1))
+		index, v, ok := shader.DecodeDebugValue(pix)
+		if !ok || index >= len(s.debugCalls) {
+			return
+		}
+		if call == nil {
+			call = &s.debugCalls[index]
+		}
+		values = append(values, v)
+		if len(values) >= debugComponentCount(call) {
+			break
+		}
+	}
+
+	msg := formatDebugValues(call, values)
+	if options.Output != nil {
+		options.Output(msg)
+		return
+	}
+	log.Print(msg)
+}
+
+// debugShader returns the shader to output the component-th value component of Debugf calls.
+func (s *Shader) debugShader(component int) (*ui.Shader, error) {
+	if s.debugShaders[component] != nil {
+		return s.debugShaders[component], nil
+	}
+	ir, err := graphics.CompileShaderForDebug(s.src, s.arrayLengths, component)
+	if err != nil {
+		return nil, err
+	}
+	s.debugShaders[component] = ui.NewShader(ir)
+	return s.debugShaders[component], nil
+}
+
+func debugComponentCount(call *shaderir.DebugCall) int {
+	var n int
+	for _, t := range call.ArgTypes {
+		switch t.Main {
+		case shaderir.Float, shaderir.Int:
+			n++
+		default:
+			n += t.VectorElementCount()
+		}
+	}
+	return n
+}
+
+func formatDebugValues(call *shaderir.DebugCall, values []float64) string {
+	args := make([]any, 0, len(call.ArgTypes))
+	for _, t := range call.ArgTypes {
+		switch t.Main {
+		case shaderir.Float:
+			args = append(args, float32(values[0]))
+			values = values[1:]
+		case shaderir.Int:
+			args = append(args, int(math.Round(values[0])))
+			values = values[1:]
+		default:
+			n := t.VectorElementCount()
+			if t.IsIntVector() {
+				vs := make([]int, n)
+				for i := range vs {
+					vs[i] = int(math.Round(values[i]))
+				}
+				args = append(args, vs)
+			} else {
+				vs := make([]float32, n)
+				for i := range vs {
+					vs[i] = float32(values[i])
+				}
+				args = append(args, vs)
+			}
+			values = values[n:]
+		}
+	}
+	return fmt.Sprintf(call.Format, args...)
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/internal/graphicsdriver"
+)
+
+// StencilFunc is a comparison function for the stencil test.
+//
+// A fragment is rendered only when the comparison between the reference value (StencilRef)
+// and the value in the stencil buffer is true.
+type StencilFunc int
+
+const (
+	// StencilFuncAlways means that the stencil test always passes.
+	StencilFuncAlways StencilFunc = StencilFunc(graphicsdriver.StencilFuncAlways)
+
+	// StencilFuncNever means that the stencil test never passes.
+	StencilFuncNever StencilFunc = StencilFunc(graphicsdriver.StencilFuncNever)
+
+	// StencilFuncEqual means that the stencil test passes when the reference value equals to the stencil value.
+	StencilFuncEqual StencilFunc = StencilFunc(graphicsdriver.StencilFuncEqual)
+
+	// StencilFuncNotEqual means that the stencil test passes when the reference value doesn't equal to the stencil value.
+	StencilFuncNotEqual StencilFunc = StencilFunc(graphicsdriver.StencilFuncNotEqual)
+
+	// StencilFuncLess means that the stencil test passes when the reference value is less than the stencil value.
+	StencilFuncLess StencilFunc = StencilFunc(graphicsdriver.StencilFuncLess)
+
+	// StencilFuncLessEqual means that the stencil test passes when the reference value is less than or equal to the stencil value.
+	StencilFuncLessEqual StencilFunc = StencilFunc(graphicsdriver.StencilFuncLessEqual)
+
+	// StencilFuncGreater means that the stencil test passes when the reference value is greater than the stencil value.
+	StencilFuncGreater StencilFunc = StencilFunc(graphicsdriver.StencilFuncGreater)
+
+	// StencilFuncGreaterEqual means that the stencil test passes when the reference value is greater than or equal to the stencil value.
+	StencilFuncGreaterEqual StencilFunc = StencilFunc(graphicsdriver.StencilFuncGreaterEqual)
+)
+
+// StencilOp is an operation to update the stencil buffer when a fragment passes the stencil test (and the depth test if enabled).
+type StencilOp int
+
+const (
+	// StencilOpKeep keeps the stencil value.
+	StencilOpKeep StencilOp = StencilOp(graphicsdriver.StencilOpKeep)
+
+	// StencilOpZero sets the stencil value to 0.
+	StencilOpZero StencilOp = StencilOp(graphicsdriver.StencilOpZero)
+
+	// StencilOpReplace sets the stencil value to the reference value.
+	StencilOpReplace StencilOp = StencilOp(graphicsdriver.StencilOpReplace)
+
+	// StencilOpIncrement increments the stencil value. The value is clamped to 255.
+	StencilOpIncrement StencilOp = StencilOp(graphicsdriver.StencilOpIncrement)
+
+	// StencilOpIncrementWrap increments the stencil value. The value wraps to 0 when exceeding 255.
+	StencilOpIncrementWrap StencilOp = StencilOp(graphicsdriver.StencilOpIncrementWrap)
+
+	// StencilOpDecrement decrements the stencil value. The value is clamped to 0.
+	StencilOpDecrement StencilOp = StencilOp(graphicsdriver.StencilOpDecrement)
+
+	// StencilOpDecrementWrap decrements the stencil value. The value wraps to 255 when going below 0.
+	StencilOpDecrementWrap StencilOp = StencilOp(graphicsdriver.StencilOpDecrementWrap)
+
+	// StencilOpInvert inverts the bits of the stencil value.
+	StencilOpInvert StencilOp = StencilOp(graphicsdriver.StencilOpInvert)
+)
+
+// ClearStencil resets the stencil buffer of the image with 0.
+// The colors of the image are not changed.
+//
+// ClearStencil affects only the image's bounds. If the image is a sub-image, only the sub-image's region is reset.
+//
+// If the image doesn't have a stencil buffer, ClearStencil panics.
+//
+// When the image is disposed, ClearStencil does nothing.
+func (i *Image) ClearStencil() {
+	i.copyCheck()
+
+	if i.isDisposed() {
+		return
+	}
+	if !i.hasStencil() {
+		panic("ebiten: ClearStencil cannot be called on an image without a stencil buffer")
+	}
+
+	i.image.ClearStencil(i.adjustedBounds())
+}
+
+func (i *Image) hasStencil() bool {
+	if i.isSubImage() {
+		return i.original.stencil
+	}
+	return i.stencil
+}
+
+// stencilState returns the internal stencil state for rendering onto the image.
+//
+// stencilState panics if the stencil buffer cannot be used with the given options.
+func (i *Image) stencilState(f StencilFunc, op StencilOp, ref uint8, fillRule FillRule, antialias bool) graphicsdriver.Stencil {
+	if f == StencilFuncAlways && op == StencilOpKeep {
+		return graphicsdriver.Stencil{}
+	}
+
+	if !i.hasStencil() {
+		panic("ebiten: StencilFunc and StencilOp are available only for an image with a stencil buffer")
+	}
+	if fillRule != FillRuleFillAll {
+		panic("ebiten: StencilFunc and StencilOp cannot be used with FillRuleNonZero or FillRuleEvenOdd")
+	}
+	if antialias {
+		panic("ebiten: StencilFunc and StencilOp cannot be used with AntiAlias")
+	}
+
+	return graphicsdriver.Stencil{
+		Func: graphicsdriver.StencilFunc(f),
+		Op:   graphicsdriver.StencilOp(op),
+		Ref:  ref,
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func skipIfStencilIsNotAvailable(t *testing.T) {
+	if !ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureStencil) {
+		t.Skip("stencil buffers are not available")
+	}
+}
+
+func TestImageStencil(t *testing.T) {
+	skipIfStencilIsNotAvailable(t)
+
+	dst := ebiten.NewImageWithOptions(image.Rect(0, 0, 16, 16), &ebiten.NewImageOptions{
+		Stencil: true,
+	})
+
+	src := ebiten.NewImage(1, 1)
+	is := []uint16{0, 1, 2, 1, 2, 3}
+
+	// Write the mask to the left half without changing the colors.
+	dst.DrawTriangles(quadVertices(0, 0, 8, 16), is, src, &ebiten.DrawTrianglesOptions{
+		Blend:       ebiten.BlendDestination,
+		StencilOp:   ebiten.StencilOpReplace,
+		StencilRef:  1,
+		StencilFunc: ebiten.StencilFuncAlways,
+	})
+
+	// Draw red only where the mask is set.
+	src.Fill(color.RGBA{R: 0xff, A: 0xff})
+	dst.DrawTriangles(quadVertices(0, 0, 16, 16), is, src, &ebiten.DrawTrianglesOptions{
+		StencilFunc: ebiten.StencilFuncEqual,
+		StencilRef:  1,
+	})
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if i < 8 {
+				want = color.RGBA{R: 0xff, A: 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// After clearing the stencil buffer, nothing passes the test.
+	dst.Clear()
+	dst.ClearStencil()
+	dst.DrawTriangles(quadVertices(0, 0, 16, 16), is, src, &ebiten.DrawTrianglesOptions{
+		StencilFunc: ebiten.StencilFuncEqual,
+		StencilRef:  1,
+	})
+	if got, want := dst.At(4, 4), (color.RGBA{}); got != want {
+		t.Errorf("dst.At(4, 4): got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageStencilFallback(t *testing.T) {
+	if ebiten.IsGraphicsFeatureAvailable(ebiten.GraphicsFeatureStencil) {
+		t.Skip("stencil buffers are available")
+	}
+
+	dst := ebiten.NewImageWithOptions(image.Rect(0, 0, 16, 16), &ebiten.NewImageOptions{
+		Stencil: true,
+	})
+	src := ebiten.NewImage(1, 1)
+	src.Fill(color.RGBA{R: 0xff, A: 0xff})
+
+	// Without stencil buffers, the stencil test always passes.
+	dst.DrawTriangles(quadVertices(0, 0, 16, 16), []uint16{0, 1, 2, 1, 2, 3}, src, &ebiten.DrawTrianglesOptions{
+		StencilFunc: ebiten.StencilFuncEqual,
+		StencilRef:  1,
+	})
+	if got, want := dst.At(8, 8), (color.RGBA{R: 0xff, A: 0xff}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestImageStencilWithoutStencilBuffer(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("DrawTriangles must panic but not")
+		}
+	}()
+
+	dst := ebiten.NewImage(16, 16)
+	src := ebiten.NewImage(1, 1)
+	dst.DrawTriangles(quadVertices(0, 0, 16, 16), []uint16{0, 1, 2, 1, 2, 3}, src, &ebiten.DrawTrianglesOptions{
+		StencilFunc: ebiten.StencilFuncEqual,
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Antialias represents an antialiasing mode for rendering glyphs.
+type Antialias int
+
+const (
+	// AntialiasGrayscale is the default antialiasing mode.
+	// Glyph images are grayscale images i.e. RGBA values are the same.
+	AntialiasGrayscale Antialias = iota
+
+	// AntialiasSubpixelRGB is the subpixel (LCD) antialiasing mode for horizontal RGB displays.
+	// Each color component of a glyph image represents the coverage of the corresponding subpixel.
+	AntialiasSubpixelRGB
+
+	// AntialiasSubpixelBGR is the subpixel (LCD) antialiasing mode for horizontal BGR displays.
+	// Each color component of a glyph image represents the coverage of the corresponding subpixel.
+	AntialiasSubpixelBGR
+)
+
+func (a Antialias) isSubpixel() bool {
+	return a == AntialiasSubpixelRGB || a == AntialiasSubpixelBGR
+}
+
+// lcdFilterWeights is a low-pass filter to reduce color fringes of subpixel rendering.
+// This is the same as FreeType's default LCD filter (FT_LCD_FILTER_DEFAULT) approximately.
+var lcdFilterWeights = [...]int{1, 2, 3, 2, 1}
+
+// subpixelCoverages converts a horizontally 3x-scaled alpha image to an RGBA image with per-component coverages.
+// The width of the result is the width of src divided by 3.
+func subpixelCoverages(src *image.Alpha, antialias Antialias) *image.RGBA {
+	w, h := src.Bounds().Dx()/3, src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	var sum int
+	for _, v := range lcdFilterWeights {
+		sum += v
+	}
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			var cs [3]int
+			for c := 0; c < 3; c++ {
+				x := 3*i + c
+				var v int
+				for k, weight := range lcdFilterWeights {
+					xx := x + k - len(lcdFilterWeights)/2
+					if xx < 0 || xx >= 3*w {
+						continue
+					}
+					v += weight * int(src.Pix[j*src.Stride+xx])
+				}
+				cs[c] = v / sum
+			}
+			if antialias == AntialiasSubpixelBGR {
+				cs[0], cs[2] = cs[2], cs[0]
+			}
+			a := cs[0]
+			if a < cs[1] {
+				a = cs[1]
+			}
+			if a < cs[2] {
+				a = cs[2]
+			}
+			p := dst.Pix[j*dst.Stride+4*i:]
+			p[0] = byte(cs[0])
+			p[1] = byte(cs[1])
+			p[2] = byte(cs[2])
+			p[3] = byte(a)
+		}
+	}
+	return dst
+}
+
+// subpixelMaskBlend multiplies the destination by (1 - the source color) for each component.
+var subpixelMaskBlend = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorZero,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorZero,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorOneMinusSourceColor,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOneMinusSourceAlpha,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+// drawGlyphs draws the glyphs on dst.
+// The glyphs for which skip returns true are not drawn.
+//
+// Glyphs with subpixel antialiasing are rendered with per-component alpha blending.
+// This is done in two passes: the first pass multiplies the destination by (1 - coverage × alpha) for each component,
+// and the second pass adds coverage × color to the destination.
+// In this case, options.Blend is ignored.
+func drawGlyphs(dst *ebiten.Image, glyphs []Glyph, options *ebiten.DrawImageOptions, skip func(g *Glyph) bool) {
+	drawOp := *options
+	geoM := options.GeoM
+
+	var hasSubpixel bool
+	for i := range glyphs {
+		g := &glyphs[i]
+		if g.Image == nil {
+			continue
+		}
+		if skip != nil && skip(g) {
+			continue
+		}
+		if g.Antialias.isSubpixel() {
+			hasSubpixel = true
+			continue
+		}
+		drawOp.GeoM.Reset()
+		drawOp.GeoM.Translate(g.X, g.Y)
+		drawOp.GeoM.Concat(geoM)
+		dst.DrawImage(g.Image, &drawOp)
+	}
+
+	if !hasSubpixel {
+		return
+	}
+
+	// Render all the subpixel glyphs in each pass in order to batch draw calls.
+	a := options.ColorScale.A()
+	for pass := 0; pass < 2; pass++ {
+		drawOp := *options
+		if pass == 0 {
+			drawOp.ColorScale.Reset()
+			drawOp.ColorScale.Scale(a, a, a, a)
+			drawOp.Blend = subpixelMaskBlend
+		} else {
+			drawOp.Blend = ebiten.BlendLighter
+		}
+		for i := range glyphs {
+			g := &glyphs[i]
+			if g.Image == nil || !g.Antialias.isSubpixel() {
+				continue
+			}
+			if skip != nil && skip(g) {
+				continue
+			}
+			drawOp.GeoM.Reset()
+			drawOp.GeoM.Translate(g.X, g.Y)
+			drawOp.GeoM.Concat(geoM)
+			dst.DrawImage(g.Image, &drawOp)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var _ Face = (*BMFontFace)(nil)
+
+type bmFontChar struct {
+	x        int
+	y        int
+	width    int
+	height   int
+	xoffset  int
+	yoffset  int
+	xadvance int
+	page     int
+}
+
+type bmFontKerningKey struct {
+	first  rune
+	second rune
+}
+
+// BMFontFace is a Face implementation for AngelCode BMFont bitmap fonts.
+// BMFontFace renders prebaked glyph images as they are without any rasterization.
+// BMFontFace must not be copied by value.
+//
+// The glyph images of BMFontFace are taken from the page images directly.
+// Unlike the other faces, the glyph images might not be grayscale if the page images are colored.
+type BMFontFace struct {
+	lineHeight int
+	base       int
+
+	chars    map[rune]*bmFontChar
+	kernings map[bmFontKerningKey]int
+
+	pageSources []image.Image
+	pages       []*ebiten.Image
+	glyphImages map[rune]*ebiten.Image
+
+	addr *BMFontFace
+
+	m sync.Mutex
+}
+
+// NewBMFontFace parses an AngelCode BMFont descriptor file (.fnt) at name in fsys and returns a BMFontFace.
+//
+// Both the text format and the XML format of the descriptor are supported.
+// The page images are read from fsys at the paths relative to the descriptor file,
+// and are decoded by image.Decode.
+// Then, the corresponding image decoders like image/png must be registered in advance.
+func NewBMFontFace(fsys fs.FS, name string) (*BMFontFace, error) {
+	bs, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &BMFontFace{
+		chars:    map[rune]*bmFontChar{},
+		kernings: map[bmFontKerningKey]int{},
+	}
+	f.addr = f
+
+	var pageFiles map[int]string
+	if bytes.HasPrefix(bytes.TrimSpace(bs), []byte("<")) {
+		pageFiles, err = f.parseXML(bs)
+	} else {
+		pageFiles, err = f.parseText(bs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.pageSources = make([]image.Image, len(pageFiles))
+	for id, file := range pageFiles {
+		if id < 0 || id >= len(pageFiles) {
+			return nil, fmt.Errorf("text: invalid page ID %d at NewBMFontFace", id)
+		}
+		r, err := fsys.Open(path.Join(path.Dir(name), file))
+		if err != nil {
+			return nil, err
+		}
+		img, _, err := image.Decode(r)
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+		f.pageSources[id] = img
+	}
+
+	for r, c := range f.chars {
+		if c.page < 0 || c.page >= len(f.pageSources) {
+			return nil, fmt.Errorf("text: the character %U refers an invalid page %d at NewBMFontFace", r, c.page)
+		}
+	}
+
+	return f, nil
+}
+
+func (b *BMFontFace) parseText(bs []byte) (map[int]string, error) {
+	pageFiles := map[int]string{}
+
+	s := bufio.NewScanner(bytes.NewReader(bs))
+	for s.Scan() {
+		tag, attrs, err := parseBMFontTextLine(s.Text())
+		if err != nil {
+			return nil, err
+		}
+		if err := b.addElement(tag, func(key string) string { return attrs[key] }, pageFiles); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return pageFiles, nil
+}
+
+func parseBMFontTextLine(line string) (string, map[string]string, error) {
+	line = strings.TrimSpace(line)
+	tag, rest, _ := strings.Cut(line, " ")
+
+	attrs := map[string]string{}
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			return "", nil, fmt.Errorf("text: invalid BMFont line: %q", line)
+		}
+		key = strings.TrimSpace(key)
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				return "", nil, fmt.Errorf("text: unterminated quoted value in BMFont line: %q", line)
+			}
+			attrs[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+		v, r, _ := strings.Cut(value, " ")
+		attrs[key] = v
+		rest = r
+	}
+	return tag, attrs, nil
+}
+
+type bmFontXMLAttrs struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+}
+
+func (b *bmFontXMLAttrs) get(key string) string {
+	for _, a := range b.Attrs {
+		if a.Name.Local == key {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (b *BMFontFace) parseXML(bs []byte) (map[int]string, error) {
+	var doc struct {
+		Common bmFontXMLAttrs `xml:"common"`
+		Pages  struct {
+			Page []bmFontXMLAttrs `xml:"page"`
+		} `xml:"pages"`
+		Chars struct {
+			Char []bmFontXMLAttrs `xml:"char"`
+		} `xml:"chars"`
+		Kernings struct {
+			Kerning []bmFontXMLAttrs `xml:"kerning"`
+		} `xml:"kernings"`
+	}
+	if err := xml.Unmarshal(bs, &doc); err != nil {
+		return nil, err
+	}
+
+	pageFiles := map[int]string{}
+	if err := b.addElement("common", doc.Common.get, pageFiles); err != nil {
+		return nil, err
+	}
+	for _, p := range doc.Pages.Page {
+		if err := b.addElement("page", p.get, pageFiles); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range doc.Chars.Char {
+		if err := b.addElement("char", c.get, pageFiles); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range doc.Kernings.Kerning {
+		if err := b.addElement("kerning", k.get, pageFiles); err != nil {
+			return nil, err
+		}
+	}
+	return pageFiles, nil
+}
+
+func (b *BMFontFace) addElement(tag string, attr func(key string) string, pageFiles map[int]string) error {
+	var err error
+	atoi := func(key string) int {
+		if err != nil {
+			return 0
+		}
+		v := attr(key)
+		if v == "" {
+			return 0
+		}
+		var n int
+		n, err = strconv.Atoi(v)
+		if err != nil {
+			err = fmt.Errorf("text: invalid value %q for %s.%s in BMFont: %w", v, tag, key, err)
+		}
+		return n
+	}
+
+	switch tag {
+	case "common":
+		b.lineHeight = atoi("lineHeight")
+		b.base = atoi("base")
+		if atoi("packed") != 0 {
+			return fmt.Errorf("text: packed BMFont pages are not supported")
+		}
+	case "page":
+		pageFiles[atoi("id")] = attr("file")
+	case "char":
+		id := atoi("id")
+		b.chars[rune(id)] = &bmFontChar{
+			x:        atoi("x"),
+			y:        atoi("y"),
+			width:    atoi("width"),
+			height:   atoi("height"),
+			xoffset:  atoi("xoffset"),
+			yoffset:  atoi("yoffset"),
+			xadvance: atoi("xadvance"),
+			page:     atoi("page"),
+		}
+	case "kerning":
+		b.kernings[bmFontKerningKey{
+			first:  rune(atoi("first")),
+			second: rune(atoi("second")),
+		}] = atoi("amount")
+	}
+	return err
+}
+
+func (b *BMFontFace) copyCheck() {
+	if b.addr != b {
+		panic("text: illegal use of non-zero BMFontFace copied by value")
+	}
+}
+
+// Metrics implements Face.
+func (b *BMFontFace) Metrics() Metrics {
+	b.copyCheck()
+
+	return Metrics{
+		HAscent:  float64(b.base),
+		HDescent: float64(b.lineHeight - b.base),
+	}
+}
+
+func (b *BMFontFace) char(r rune) (*bmFontChar, rune, bool) {
+	if c, ok := b.chars[r]; ok {
+		return c, r, true
+	}
+	// The character -1 is used as a fallback for missing characters by convention.
+	if c, ok := b.chars[-1]; ok {
+		return c, -1, true
+	}
+	return nil, 0, false
+}
+
+// advance implements Face.
+func (b *BMFontFace) advance(text string) float64 {
+	b.copyCheck()
+
+	var a int
+	prevR := rune(-1)
+	for _, r := range text {
+		if prevR >= 0 {
+			a += b.kernings[bmFontKerningKey{first: prevR, second: r}]
+		}
+		if c, _, ok := b.char(r); ok {
+			a += c.xadvance
+		}
+		prevR = r
+	}
+	return float64(a)
+}
+
+// hasGlyph implements Face.
+func (b *BMFontFace) hasGlyph(r rune) bool {
+	b.copyCheck()
+
+	_, ok := b.chars[r]
+	return ok
+}
+
+// glyphImage returns a glyph image and its page image.
+func (b *BMFontFace) glyphImage(r rune, c *bmFontChar) (*ebiten.Image, *ebiten.Image) {
+	if c.width == 0 || c.height == 0 {
+		return nil, nil
+	}
+
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if img, ok := b.glyphImages[r]; ok {
+		return img, b.pages[c.page]
+	}
+
+	if b.pages == nil {
+		b.pages = make([]*ebiten.Image, len(b.pageSources))
+	}
+	if b.pages[c.page] == nil {
+		b.pages[c.page] = ebiten.NewImageFromImage(b.pageSources[c.page])
+	}
+
+	if b.glyphImages == nil {
+		b.glyphImages = map[rune]*ebiten.Image{}
+	}
+	img := b.pages[c.page].SubImage(image.Rect(c.x, c.y, c.x+c.width, c.y+c.height)).(*ebiten.Image)
+	b.glyphImages[r] = img
+	return img, b.pages[c.page]
+}
+
+// appendGlyphsForLine implements Face.
+func (b *BMFontFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffset int, originX, originY float64) []Glyph {
+	b.copyCheck()
+
+	// Bitmap glyphs are always rendered at integer positions.
+	originX = math.Floor(originX)
+	originY = math.Floor(originY)
+
+	prevR := rune(-1)
+	for i, r := range line {
+		if prevR >= 0 {
+			originX += float64(b.kernings[bmFontKerningKey{first: prevR, second: r}])
+		}
+		prevR = r
+
+		_, size := utf8.DecodeRuneInString(line[i:])
+		c, id, ok := b.char(r)
+		if !ok {
+			// Append a glyph even if there is no corresponding character.
+			// This is necessary to return index information.
+			glyphs = append(glyphs, Glyph{
+				StartIndexInBytes: indexOffset + i,
+				EndIndexInBytes:   indexOffset + i + size,
+				X:                 originX,
+				Y:                 originY,
+				OriginX:           originX,
+				OriginY:           originY,
+			})
+			continue
+		}
+
+		img, page := b.glyphImage(id, c)
+		glyphs = append(glyphs, Glyph{
+			StartIndexInBytes: indexOffset + i,
+			EndIndexInBytes:   indexOffset + i + size,
+			Image:             img,
+			X:                 originX + float64(c.xoffset),
+			Y:                 originY - float64(b.base) + float64(c.yoffset),
+			OriginX:           originX,
+			OriginY:           originY,
+			atlas:             page,
+		})
+		originX += float64(c.xadvance)
+	}
+
+	return glyphs
+}
+
+// appendVectorPathForLine implements Face.
+func (b *BMFontFace) appendVectorPathForLine(path *vector.Path, line string, originX, originY float64) {
+}
+
+// appendGlyphVectorPathsForLine implements Face.
+func (b *BMFontFace) appendGlyphVectorPathsForLine(paths []GlyphVectorPath, line string, indexOffset int, originX, originY float64) []GlyphVectorPath {
+	return paths
+}
+
+// direction implements Face.
+func (b *BMFontFace) direction() Direction {
+	return DirectionLeftToRight
+}
+
+// private implements Face.
+func (b *BMFontFace) private() {
+}
@@ -0,0 +1,263 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image"
+	"unicode/utf8"
+
+	"github.com/go-text/typesetting/segmenter"
+)
+
+// Cluster represents one grapheme cluster in a laid-out text.
+//
+// A grapheme cluster is what a user perceives as one character, e.g. a base letter and its combining marks, or an emoji sequence.
+type Cluster struct {
+	// StartIndexInBytes is the start index in bytes for the given string at AppendClusters.
+	StartIndexInBytes int
+
+	// EndIndexInBytes is the end index in bytes for the given string at AppendClusters.
+	EndIndexInBytes int
+
+	// LineIndex is the index of the line including this cluster.
+	LineIndex int
+
+	// OriginX is the X position of the origin of this cluster.
+	// For a horizontal-direction face, this is the left edge of the cluster's advance region regardless of the direction.
+	OriginX float64
+
+	// OriginY is the Y position of the origin of this cluster.
+	// For a vertical-direction face, this is the top edge of the cluster's advance region.
+	OriginY float64
+
+	// Advance is the advance of this cluster in the primary direction.
+	//
+	// If one glyph covers multiple clusters like a ligature, the glyph's advance is divided equally among the clusters.
+	Advance float64
+
+	// Bounds is the bounding box of the rendered glyph images of this cluster.
+	// Bounds is empty if the cluster has no visible glyphs, e.g. a space.
+	//
+	// If one glyph covers multiple clusters, all the clusters have the same bounds of the glyph.
+	Bounds image.Rectangle
+}
+
+// LineMetrics represents the metrics of one line in a laid-out text.
+type LineMetrics struct {
+	// StartIndexInBytes is the start index in bytes for the given string at AppendLineMetrics.
+	StartIndexInBytes int
+
+	// EndIndexInBytes is the end index in bytes for the given string at AppendLineMetrics.
+	// The newline character is not included.
+	EndIndexInBytes int
+
+	// OriginX is the X position of the origin of this line.
+	OriginX float64
+
+	// OriginY is the Y position of the origin of this line.
+	OriginY float64
+
+	// Advance is the advance of this line in the primary direction.
+	Advance float64
+
+	// Ascent is the distance from the top of this line to its baseline in the secondary direction.
+	//
+	// Ascent is the maximum value among the faces actually used in this line.
+	// For example, when a MultiFace is used, only the faces used for the characters in this line are taken into account.
+	Ascent float64
+
+	// Descent is the distance from the bottom of this line to its baseline in the secondary direction.
+	//
+	// Descent is the maximum value among the faces actually used in this line.
+	Descent float64
+
+	// LineGap is the recommended amount of space between this line and the next line.
+	//
+	// LineGap is the maximum value among the faces actually used in this line.
+	LineGap float64
+}
+
+// AppendClusters appends grapheme clusters of the given text to the given slice and returns a slice.
+//
+// The clusters are in the logical order, i.e. the order in the given text, even for right-to-left texts.
+//
+// For the details of options, see Draw function.
+//
+// AppendClusters is concurrent-safe.
+func AppendClusters(clusters []Cluster, text string, face Face, options *LayoutOptions) []Cluster {
+	horizontal := face.direction().isHorizontal()
+
+	var glyphs []Glyph
+	var lineIndex int
+	forEachLine(text, face, options, func(line string, indexOffset int, originX, originY float64) {
+		defer func() {
+			lineIndex++
+		}()
+
+		if line == "" {
+			return
+		}
+
+		glyphs = face.appendGlyphsForLine(glyphs[:0], line, indexOffset, originX, originY)
+
+		start := len(clusters)
+		clusters = appendEmptyClusters(clusters, line, indexOffset, lineIndex)
+		cs := clusters[start:]
+
+		lineEnd := originY + face.advance(line)
+		if horizontal {
+			lineEnd = originX + face.advance(line)
+		}
+
+		hasOrigin := make([]bool, len(cs))
+		for i, g := range glyphs {
+			// Calculate the glyph's advance from the next glyph's origin.
+			// The glyphs are in the visual order.
+			var adv float64
+			if i < len(glyphs)-1 {
+				if horizontal {
+					adv = glyphs[i+1].OriginX - g.OriginX
+				} else {
+					adv = glyphs[i+1].OriginY - g.OriginY
+				}
+			} else {
+				if horizontal {
+					adv = lineEnd - g.OriginX
+				} else {
+					adv = lineEnd - g.OriginY
+				}
+			}
+
+			var bounds image.Rectangle
+			if g.Image != nil {
+				b := g.Image.Bounds()
+				bounds = image.Rect(int(g.X), int(g.Y), int(g.X)+b.Dx(), int(g.Y)+b.Dy())
+			}
+
+			// Find the clusters covered by this glyph.
+			var cstart, cend int
+			for cstart = 0; cstart < len(cs); cstart++ {
+				if cs[cstart].EndIndexInBytes > g.StartIndexInBytes {
+					break
+				}
+			}
+			for cend = cstart; cend < len(cs); cend++ {
+				if cs[cend].StartIndexInBytes >= g.EndIndexInBytes {
+					break
+				}
+			}
+			n := cend - cstart
+			if n == 0 {
+				continue
+			}
+
+			for j := cstart; j < cend; j++ {
+				k := j - cstart
+				if face.direction() == DirectionRightToLeft {
+					k = n - 1 - k
+				}
+				x, y := g.OriginX, g.OriginY
+				if horizontal {
+					x += adv * float64(k) / float64(n)
+				} else {
+					y += adv * float64(k) / float64(n)
+				}
+
+				c := &cs[j]
+				if !hasOrigin[j] || (horizontal && x < c.OriginX) || (!horizontal && y < c.OriginY) {
+					c.OriginX = x
+					c.OriginY = y
+					hasOrigin[j] = true
+				}
+				c.Advance += adv / float64(n)
+				c.Bounds = c.Bounds.Union(bounds)
+			}
+		}
+
+		// Clusters without glyphs are put at the line origin.
+		for i := range cs {
+			if hasOrigin[i] {
+				continue
+			}
+			cs[i].OriginX = originX
+			cs[i].OriginY = originY
+		}
+	})
+	return clusters
+}
+
+func appendEmptyClusters(clusters []Cluster, line string, indexOffset int, lineIndex int) []Cluster {
+	var seg segmenter.Segmenter
+	seg.Init([]rune(line))
+
+	byteIndex := indexOffset
+	iter := seg.GraphemeIterator()
+	for iter.Next() {
+		g := iter.Grapheme()
+		var n int
+		for _, r := range g.Text {
+			n += utf8.RuneLen(r)
+		}
+		clusters = append(clusters, Cluster{
+			StartIndexInBytes: byteIndex,
+			EndIndexInBytes:   byteIndex + n,
+			LineIndex:         lineIndex,
+		})
+		byteIndex += n
+	}
+	return clusters
+}
+
+// AppendLineMetrics appends the metrics of lines of the given text to the given slice and returns a slice.
+//
+// Lines are separated by the '\n' newline character.
+//
+// For the details of options, see Draw function.
+//
+// AppendLineMetrics is concurrent-safe.
+func AppendLineMetrics(lines []LineMetrics, text string, face Face, options *LayoutOptions) []LineMetrics {
+	forEachLine(text, face, options, func(line string, indexOffset int, originX, originY float64) {
+		m := metricsForLine(face, line)
+		l := LineMetrics{
+			StartIndexInBytes: indexOffset,
+			EndIndexInBytes:   indexOffset + len(line),
+			OriginX:           originX,
+			OriginY:           originY,
+			Advance:           face.advance(line),
+		}
+		if face.direction().isHorizontal() {
+			l.Ascent = m.HAscent
+			l.Descent = m.HDescent
+			l.LineGap = m.HLineGap
+		} else {
+			l.Ascent = m.VAscent
+			l.Descent = m.VDescent
+			l.LineGap = m.VLineGap
+		}
+		lines = append(lines, l)
+	})
+	return lines
+}
+
+// metricsForLine returns the metrics of the faces actually used to render the given line.
+func metricsForLine(face Face, line string) Metrics {
+	switch f := face.(type) {
+	case *MultiFace:
+		return f.metricsForLine(line)
+	case *LimitedFace:
+		return metricsForLine(f.face, f.unicodeRanges.filter(line))
+	}
+	return face.Metrics()
+}
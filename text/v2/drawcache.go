@@ -0,0 +1,323 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type drawCacheKey struct {
+	face    Face
+	text    string
+	options LayoutOptions
+}
+
+type drawCacheValue struct {
+	faceState string
+	glyphs    []Glyph
+	atime     int64
+
+	// batches is the vertices of the glyphs per atlas image.
+	batches []*drawBatch
+}
+
+// drawCache is a cache of laid-out glyphs for Draw.
+//
+// A cached entry holds the glyph images, so the glyph images are not released while the entry is alive
+// even after they are evicted from the glyph image caches.
+type drawCache struct {
+	entries map[drawCacheKey]*drawCacheValue
+	atime   int64
+
+	m sync.Mutex
+}
+
+var theDrawCache drawCache
+
+// draw draws the given text with the cached glyphs.
+//
+// The glyphs sharing the same atlas image are drawn with one DrawTriangles32 call per pass using the cached vertices.
+func (d *drawCache) draw(dst *ebiten.Image, text string, face Face, layoutOptions *LayoutOptions, drawOptions *ebiten.DrawImageOptions) {
+	// An entry is never modified after its creation, then drawing it doesn't need the lock.
+	e := d.entry(text, face, layoutOptions)
+
+	if !canDrawBatch(drawOptions) {
+		drawGlyphs(dst, e.glyphs, drawOptions, nil)
+		return
+	}
+
+	var tmp []ebiten.Vertex
+	for _, b := range e.batches {
+		tmp = b.draw(dst, drawOptions, tmp)
+	}
+	// Draw the glyphs without an atlas image one by one.
+	drawGlyphs(dst, e.glyphs, drawOptions, func(g *Glyph) bool {
+		return g.atlas != nil
+	})
+}
+
+func (d *drawCache) entry(text string, face Face, options *LayoutOptions) *drawCacheValue {
+	key := drawCacheKey{
+		face:    face,
+		text:    text,
+		options: *options,
+	}
+	state := faceState(face)
+
+	d.m.Lock()
+	// A face might be modified after the glyphs are cached, e.g. GoTextFace.Size might be changed.
+	if e, ok := d.entries[key]; ok && e.faceState == state {
+		e.atime = now()
+		d.m.Unlock()
+		return e
+	}
+	d.m.Unlock()
+
+	// Laying out the glyphs might take time. Do this without the lock.
+	glyphs := AppendGlyphs(nil, text, face, options)
+	e := &drawCacheValue{
+		faceState: state,
+		glyphs:    glyphs,
+		batches:   newDrawBatches(glyphs),
+	}
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	n := now()
+	e.atime = n
+
+	if d.entries == nil {
+		d.entries = map[drawCacheKey]*drawCacheValue{}
+	}
+	d.entries[key] = e
+
+	// Clean up old entries.
+	if d.atime < n {
+		const cacheSoftLimit = 512
+		if len(d.entries) > cacheSoftLimit {
+			for key, e := range d.entries {
+				// 60 is an arbitrary number.
+				if e.atime >= n-60 {
+					continue
+				}
+				delete(d.entries, key)
+			}
+		}
+	}
+	d.atime = n
+
+	return e
+}
+
+// canDrawBatch reports whether the options can be applied to a batch.
+func canDrawBatch(options *ebiten.DrawImageOptions) bool {
+	// A repeated address would sample the other glyphs in the atlas.
+	if options.Address == ebiten.AddressRepeat || options.AddressX == ebiten.AddressRepeat || options.AddressY == ebiten.AddressRepeat {
+		return false
+	}
+	// A color matrix is applied to straight-alpha colors before the color scale, which the vertex colors cannot represent.
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 5; i++ {
+			var e float64
+			if i == j {
+				e = 1
+			}
+			if options.ColorM.Element(j, i) != e {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// drawBatch is the vertices of laid-out glyphs sharing the same atlas image
+// to draw them with one DrawTriangles32 call per pass.
+type drawBatch struct {
+	// atlas is the image that the glyph images are sub-images of.
+	atlas *ebiten.Image
+
+	// vertices and indices are for the glyphs without subpixel antialiasing.
+	// The destination positions are in the layout coordinate.
+	vertices []ebiten.Vertex
+	indices  []uint32
+
+	// subpixelVertices and subpixelIndices are for the glyphs with subpixel antialiasing.
+	subpixelVertices []ebiten.Vertex
+	subpixelIndices  []uint32
+}
+
+func newDrawBatches(glyphs []Glyph) []*drawBatch {
+	var batches []*drawBatch
+	for i := range glyphs {
+		g := &glyphs[i]
+		if g.Image == nil || g.atlas == nil {
+			continue
+		}
+
+		var b *drawBatch
+		for _, bb := range batches {
+			if bb.atlas == g.atlas {
+				b = bb
+				break
+			}
+		}
+		if b == nil {
+			b = &drawBatch{
+				atlas: g.atlas,
+			}
+			batches = append(batches, b)
+		}
+
+		r := g.Image.Bounds()
+		if g.Antialias.isSubpixel() {
+			b.subpixelVertices, b.subpixelIndices = appendQuad(b.subpixelVertices, b.subpixelIndices, g.X, g.Y, r)
+			continue
+		}
+		b.vertices, b.indices = appendQuad(b.vertices, b.indices, g.X, g.Y, r)
+	}
+	return batches
+}
+
+// appendQuad appends the vertices and the indices of a quad at (x, y) for the source region r.
+func appendQuad(vertices []ebiten.Vertex, indices []uint32, x, y float64, r image.Rectangle) ([]ebiten.Vertex, []uint32) {
+	base := uint32(len(vertices))
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 2; i++ {
+			vertices = append(vertices, ebiten.Vertex{
+				DstX: float32(x + float64(i*r.Dx())),
+				DstY: float32(y + float64(j*r.Dy())),
+				SrcX: float32(r.Min.X + i*r.Dx()),
+				SrcY: float32(r.Min.Y + j*r.Dy()),
+			})
+		}
+	}
+	indices = append(indices, base, base+1, base+2, base+1, base+2, base+3)
+	return vertices, indices
+}
+
+// draw draws the batch and returns the temporary buffer for the vertices.
+func (b *drawBatch) draw(dst *ebiten.Image, options *ebiten.DrawImageOptions, tmp []ebiten.Vertex) []ebiten.Vertex {
+	op := &ebiten.DrawTrianglesOptions{
+		ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha,
+		CompositeMode:  options.CompositeMode,
+		Blend:          options.Blend,
+		Filter:         options.Filter,
+		Address:        options.Address,
+		AddressX:       options.AddressX,
+		AddressY:       options.AddressY,
+		GammaCorrect:   options.GammaCorrect,
+		ClipRect:       options.ClipRect,
+	}
+
+	if len(b.indices) > 0 {
+		tmp = appendTransformedVertices(tmp[:0], b.vertices, &options.GeoM, &options.ColorScale)
+		dst.DrawTriangles32(tmp, b.indices, b.atlas, op)
+	}
+
+	if len(b.subpixelIndices) == 0 {
+		return tmp
+	}
+
+	// Render all the subpixel glyphs in each pass. See also drawGlyphs.
+	a := options.ColorScale.A()
+	for pass := 0; pass < 2; pass++ {
+		cs := options.ColorScale
+		if pass == 0 {
+			cs.Reset()
+			cs.Scale(a, a, a, a)
+			op.Blend = subpixelMaskBlend
+		} else {
+			op.Blend = ebiten.BlendLighter
+		}
+		tmp = appendTransformedVertices(tmp[:0], b.subpixelVertices, &options.GeoM, &cs)
+		dst.DrawTriangles32(tmp, b.subpixelIndices, b.atlas, op)
+	}
+	return tmp
+}
+
+func appendTransformedVertices(dst []ebiten.Vertex, vertices []ebiten.Vertex, geoM *ebiten.GeoM, colorScale *ebiten.ColorScale) []ebiten.Vertex {
+	r, g, b, a := colorScale.R(), colorScale.G(), colorScale.B(), colorScale.A()
+	for _, v := range vertices {
+		x, y := geoM.Apply(float64(v.DstX), float64(v.DstY))
+		v.DstX = float32(x)
+		v.DstY = float32(y)
+		v.ColorR = r
+		v.ColorG = g
+		v.ColorB = b
+		v.ColorA = a
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+func (d *drawCache) invalidate(text string, face Face, options *LayoutOptions) {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	key := drawCacheKey{
+		face:    face,
+		text:    text,
+		options: *options,
+	}
+	delete(d.entries, key)
+}
+
+// faceState returns a string representing the face's states that affect the layout.
+func faceState(face Face) string {
+	var sb strings.Builder
+	appendFaceState(&sb, face)
+	return sb.String()
+}
+
+func appendFaceState(sb *strings.Builder, face Face) {
+	switch f := face.(type) {
+	case *GoTextFace:
+		k := f.outputCacheKey("")
+		_, _ = fmt.Fprintf(sb, "(%p,%d,%v,%s,%s,%q,%q,%d)", f.Source, k.direction, k.size, k.language, k.script, k.variations, k.features, f.Antialias)
+	case *MultiFace:
+		sb.WriteString("(")
+		for _, f := range f.faces {
+			_, _ = fmt.Fprintf(sb, "%p", f)
+			appendFaceState(sb, f)
+		}
+		sb.WriteString(")")
+	case *LimitedFace:
+		_, _ = fmt.Fprintf(sb, "(%p,%d", f.face, len(f.unicodeRanges.ranges))
+		appendFaceState(sb, f.face)
+		sb.WriteString(")")
+	}
+}
+
+// InvalidateDrawCache removes the cached layout for the given text, face and options.
+//
+// Draw caches the laid-out glyphs and their vertices for a text, a face and layout options,
+// and reuses them at the next call with the same arguments.
+// A cached entry is removed automatically when it is not used for a while,
+// but you can call InvalidateDrawCache to remove it explicitly,
+// e.g. when the text is dynamically generated and will never be drawn again.
+//
+// InvalidateDrawCache is concurrent-safe.
+func InvalidateDrawCache(text string, face Face, options *LayoutOptions) {
+	if options == nil {
+		options = &LayoutOptions{}
+	}
+	theDrawCache.invalidate(text, face, options)
+}
@@ -39,6 +39,10 @@ func init() {
 
 type glyphImageCacheEntry struct {
 	image *ebiten.Image
+
+	// atlas is the image that image is a sub-image of. atlas can be nil.
+	atlas *ebiten.Image
+
 	atime int64
 }
 
@@ -48,7 +52,8 @@ type glyphImageCache[Key comparable] struct {
 	m     sync.Mutex
 }
 
-func (g *glyphImageCache[Key]) getOrCreate(face Face, key Key, create func() *ebiten.Image) *ebiten.Image {
+// getOrCreate returns a glyph image and the atlas image of the glyph image.
+func (g *glyphImageCache[Key]) getOrCreate(face Face, key Key, create func() (*ebiten.Image, *ebiten.Image)) (*ebiten.Image, *ebiten.Image) {
 	g.m.Lock()
 	defer g.m.Unlock()
 
@@ -57,16 +62,17 @@ func (g *glyphImageCache[Key]) getOrCreate(face Face, key Key, create func() *eb
 	e, ok := g.cache[key]
 	if ok {
 		e.atime = n
-		return e.image
+		return e.image, e.atlas
 	}
 
 	if g.cache == nil {
 		g.cache = map[Key]*glyphImageCacheEntry{}
 	}
 
-	img := create()
+	img, atlas := create()
 	e = &glyphImageCacheEntry{
 		image: img,
+		atlas: atlas,
 	}
 	if img != nil {
 		e.atime = n
@@ -97,5 +103,5 @@ func (g *glyphImageCache[Key]) getOrCreate(face Face, key Key, create func() *eb
 
 	g.atime = n
 
-	return img
+	return img, atlas
 }
@@ -0,0 +1,113 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image"
+	"image/draw"
+	"runtime"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/internal/packing"
+)
+
+// glyphAtlasPageSize is the size of a page image of the glyph atlas.
+const glyphAtlasPageSize = 1024
+
+// glyphAtlasPadding is the transparent padding around each glyph image to avoid bleeding by filters.
+const glyphAtlasPadding = 1
+
+type glyphAtlasPage struct {
+	image *ebiten.Image
+	page  *packing.Page
+}
+
+// glyphAtlas packs glyph images into shared page images,
+// so that the glyphs in the same page can be rendered with one DrawTriangles32 call.
+type glyphAtlas struct {
+	pages []*glyphAtlasPage
+	m     sync.Mutex
+}
+
+var theGlyphAtlas glyphAtlas
+
+// newImage creates a glyph image with the content of src.
+//
+// newImage returns a sub-image of a page image and the page image.
+// If src is too big for a page, newImage returns an independent image and nil.
+//
+// The region of the sub-image is released when the sub-image is GCed,
+// so the sub-image is valid as long as a user holds it.
+func (g *glyphAtlas) newImage(src image.Image) (*ebiten.Image, *ebiten.Image) {
+	b := src.Bounds()
+	w := b.Dx() + 2*glyphAtlasPadding
+	h := b.Dy() + 2*glyphAtlasPadding
+	if w > glyphAtlasPageSize || h > glyphAtlasPageSize {
+		return ebiten.NewImageFromImage(src), nil
+	}
+
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	var page *glyphAtlasPage
+	var node *packing.Node
+	for _, p := range g.pages {
+		if n := p.page.Alloc(w, h); n != nil {
+			page = p
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		page = &glyphAtlasPage{
+			image: ebiten.NewImage(glyphAtlasPageSize, glyphAtlasPageSize),
+			page:  packing.NewPage(glyphAtlasPageSize, glyphAtlasPageSize, glyphAtlasPageSize),
+		}
+		node = page.page.Alloc(w, h)
+		g.pages = append(g.pages, page)
+	}
+
+	// Write the padding as well, as the region might have been used by another glyph.
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, image.Rect(glyphAtlasPadding, glyphAtlasPadding, w-glyphAtlasPadding, h-glyphAtlasPadding), src, b.Min, draw.Src)
+	r := node.Region()
+	page.image.SubImage(r).(*ebiten.Image).WritePixels(dst.Pix)
+
+	img := page.image.SubImage(r.Inset(glyphAtlasPadding)).(*ebiten.Image)
+	runtime.SetFinalizer(img, func(*ebiten.Image) {
+		g.free(page, node)
+	})
+	return img, page.image
+}
+
+func (g *glyphAtlas) free(page *glyphAtlasPage, node *packing.Node) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	page.page.Free(node)
+	if !page.page.IsEmpty() {
+		return
+	}
+
+	// An empty page is never referred by any glyph images.
+	page.image.Deallocate()
+	for i, p := range g.pages {
+		if p == page {
+			g.pages = append(g.pages[:i], g.pages[i+1:]...)
+			break
+		}
+	}
+}
@@ -58,6 +58,15 @@ type GoTextFace struct {
 	// If this is empty, the script is guessed from the specified language.
 	Script language.Script
 
+	// Antialias is the antialiasing mode for rendering glyphs.
+	// The default (zero) value is AntialiasGrayscale.
+	//
+	// A subpixel antialiasing mode improves the clarity of small texts on LCD displays,
+	// but the result is optimized only for the physical pixels.
+	// A subpixel antialiasing mode should not be used when the rendered text is scaled or rotated,
+	// or the screen is scaled by a non-integer factor.
+	Antialias Antialias
+
 	variations []font.Variation
 	features   []shaping.FontFeature
 
@@ -308,7 +317,7 @@ func (g *GoTextFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffse
 			X: glyph.shapingGlyph.XOffset,
 			Y: -glyph.shapingGlyph.YOffset,
 		})
-		img, imgX, imgY := g.glyphImage(glyph, o)
+		img, atlas, imgX, imgY := g.glyphImage(glyph, o)
 		// Append a glyph even if img is nil.
 		// This is necessary to return index information for control characters.
 		glyphs = append(glyphs, Glyph{
@@ -322,6 +331,8 @@ func (g *GoTextFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffse
 			OriginY:           fixed26_6ToFloat64(origin.Y),
 			OriginOffsetX:     fixed26_6ToFloat64(glyph.shapingGlyph.XOffset),
 			OriginOffsetY:     fixed26_6ToFloat64(-glyph.shapingGlyph.YOffset),
+			Antialias:         g.Antialias,
+			atlas:             atlas,
 		})
 		origin = origin.Add(fixed.Point26_6{
 			X: glyph.shapingGlyph.XAdvance,
@@ -332,7 +343,7 @@ func (g *GoTextFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffse
 	return glyphs
 }
 
-func (g *GoTextFace) glyphImage(glyph glyph, origin fixed.Point26_6) (*ebiten.Image, int, int) {
+func (g *GoTextFace) glyphImage(glyph glyph, origin fixed.Point26_6) (*ebiten.Image, *ebiten.Image, int, int) {
 	if g.direction().isHorizontal() {
 		origin.X = adjustGranularity(origin.X, g)
 		origin.Y &^= ((1 << 6) - 1)
@@ -351,14 +362,19 @@ func (g *GoTextFace) glyphImage(glyph glyph, origin fixed.Point26_6) (*ebiten.Im
 		xoffset:    subpixelOffset.X,
 		yoffset:    subpixelOffset.Y,
 		variations: g.ensureVariationsString(),
+		antialias:  g.Antialias,
 	}
-	img := g.Source.getOrCreateGlyphImage(g, key, func() *ebiten.Image {
-		return segmentsToImage(glyph.scaledSegments, subpixelOffset, b)
+	img, atlas := g.Source.getOrCreateGlyphImage(g, key, func() (*ebiten.Image, *ebiten.Image) {
+		return segmentsToImage(glyph.scaledSegments, subpixelOffset, b, g.Antialias)
 	})
 
 	imgX := (origin.X + b.Min.X).Floor()
 	imgY := (origin.Y + b.Min.Y).Floor()
-	return img, imgX, imgY
+	if g.Antialias.isSubpixel() {
+		// A subpixel glyph image has an additional margin on the left side for the LCD filter.
+		imgX--
+	}
+	return img, atlas, imgX, imgY
 }
 
 // appendVectorPathForLine implements Face.
@@ -369,12 +385,42 @@ func (g *GoTextFace) appendVectorPathForLine(path *vector.Path, line string, ori
 	}
 	_, gs := g.Source.shape(line, g)
 	for _, glyph := range gs {
-		appendVectorPathFromSegments(path, glyph.scaledSegments, fixed26_6ToFloat32(origin.X), fixed26_6ToFloat32(origin.Y))
+		appendVectorPathFromSegments(path, glyph.scaledSegments, fixed26_6ToFloat32(origin.X), fixed26_6ToFloat32(origin.Y), false)
+		origin = origin.Add(fixed.Point26_6{
+			X: glyph.shapingGlyph.XAdvance,
+			Y: -glyph.shapingGlyph.YAdvance,
+		})
+	}
+}
+
+// appendGlyphVectorPathsForLine implements Face.
+func (g *GoTextFace) appendGlyphVectorPathsForLine(paths []GlyphVectorPath, line string, indexOffset int, originX, originY float64) []GlyphVectorPath {
+	origin := fixed.Point26_6{
+		X: float64ToFixed26_6(originX),
+		Y: float64ToFixed26_6(originY),
+	}
+	_, gs := g.Source.shape(line, g)
+	for _, glyph := range gs {
+		o := origin.Add(fixed.Point26_6{
+			X: glyph.shapingGlyph.XOffset,
+			Y: -glyph.shapingGlyph.YOffset,
+		})
+		var path vector.Path
+		appendVectorPathFromSegments(&path, glyph.scaledSegments, fixed26_6ToFloat32(o.X), fixed26_6ToFloat32(o.Y), true)
+		paths = append(paths, GlyphVectorPath{
+			StartIndexInBytes: indexOffset + glyph.startIndex,
+			EndIndexInBytes:   indexOffset + glyph.endIndex,
+			GID:               uint32(glyph.shapingGlyph.GlyphID),
+			OriginX:           fixed26_6ToFloat64(origin.X),
+			OriginY:           fixed26_6ToFloat64(origin.Y),
+			Path:              &path,
+		})
 		origin = origin.Add(fixed.Point26_6{
 			X: glyph.shapingGlyph.XAdvance,
 			Y: -glyph.shapingGlyph.YAdvance,
 		})
 	}
+	return paths
 }
 
 // direction implements Face.
@@ -75,14 +75,14 @@ func segmentsToBounds(segs []api.Segment) fixed.Rectangle26_6 {
 	}
 }
 
-func segmentsToImage(segs []api.Segment, subpixelOffset fixed.Point26_6, glyphBounds fixed.Rectangle26_6) *ebiten.Image {
+func segmentsToImage(segs []api.Segment, subpixelOffset fixed.Point26_6, glyphBounds fixed.Rectangle26_6, antialias Antialias) (*ebiten.Image, *ebiten.Image) {
 	if len(segs) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	w, h := (glyphBounds.Max.X - glyphBounds.Min.X).Ceil(), (glyphBounds.Max.Y - glyphBounds.Min.Y).Ceil()
 	if w == 0 || h == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Add always 1 to the size.
@@ -93,24 +93,33 @@ func segmentsToImage(segs []api.Segment, subpixelOffset fixed.Point26_6, glyphBo
 	biasX := fixed26_6ToFloat32(-glyphBounds.Min.X + subpixelOffset.X)
 	biasY := fixed26_6ToFloat32(-glyphBounds.Min.Y + subpixelOffset.Y)
 
-	rast := gvector.NewRasterizer(w, h)
+	// For subpixel antialiasing, rasterize the glyph with the 3x horizontal resolution.
+	// Add a margin of 1 pixel on the both sides for the LCD filter.
+	scaleX := float32(1)
+	if antialias.isSubpixel() {
+		scaleX = 3
+		biasX++
+		w += 2
+	}
+
+	rast := gvector.NewRasterizer(w*int(scaleX), h)
 	rast.DrawOp = draw.Src
 	for _, seg := range segs {
 		switch seg.Op {
 		case api.SegmentOpMoveTo:
-			rast.MoveTo(seg.Args[0].X+biasX, seg.Args[0].Y+biasY)
+			rast.MoveTo((seg.Args[0].X+biasX)*scaleX, seg.Args[0].Y+biasY)
 		case api.SegmentOpLineTo:
-			rast.LineTo(seg.Args[0].X+biasX, seg.Args[0].Y+biasY)
+			rast.LineTo((seg.Args[0].X+biasX)*scaleX, seg.Args[0].Y+biasY)
 		case api.SegmentOpQuadTo:
 			rast.QuadTo(
-				seg.Args[0].X+biasX, seg.Args[0].Y+biasY,
-				seg.Args[1].X+biasX, seg.Args[1].Y+biasY,
+				(seg.Args[0].X+biasX)*scaleX, seg.Args[0].Y+biasY,
+				(seg.Args[1].X+biasX)*scaleX, seg.Args[1].Y+biasY,
 			)
 		case api.SegmentOpCubeTo:
 			rast.CubeTo(
-				seg.Args[0].X+biasX, seg.Args[0].Y+biasY,
-				seg.Args[1].X+biasX, seg.Args[1].Y+biasY,
-				seg.Args[2].X+biasX, seg.Args[2].Y+biasY,
+				(seg.Args[0].X+biasX)*scaleX, seg.Args[0].Y+biasY,
+				(seg.Args[1].X+biasX)*scaleX, seg.Args[1].Y+biasY,
+				(seg.Args[2].X+biasX)*scaleX, seg.Args[2].Y+biasY,
 			)
 		}
 	}
@@ -120,15 +129,27 @@ func segmentsToImage(segs []api.Segment, subpixelOffset fixed.Point26_6, glyphBo
 	// See also https://github.com/go-text/typesetting/issues/122.
 	rast.ClosePath()
 
+	if antialias.isSubpixel() {
+		a := image.NewAlpha(image.Rect(0, 0, w*3, h))
+		rast.Draw(a, a.Bounds(), image.Opaque, image.Point{})
+		return theGlyphAtlas.newImage(subpixelCoverages(a, antialias))
+	}
+
 	dst := image.NewRGBA(image.Rect(0, 0, w, h))
 	rast.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
-	return ebiten.NewImageFromImage(dst)
+	return theGlyphAtlas.newImage(dst)
 }
 
-func appendVectorPathFromSegments(path *vector.Path, segs []api.Segment, x, y float32) {
-	for _, seg := range segs {
+// appendVectorPathFromSegments appends the segments to the path.
+//
+// If closeContours is true, each contour is closed so that the contour can be stroked correctly.
+func appendVectorPathFromSegments(path *vector.Path, segs []api.Segment, x, y float32, closeContours bool) {
+	for i, seg := range segs {
 		switch seg.Op {
 		case api.SegmentOpMoveTo:
+			if closeContours && i > 0 {
+				path.Close()
+			}
 			path.MoveTo(seg.Args[0].X+x, seg.Args[0].Y+y)
 		case api.SegmentOpLineTo:
 			path.LineTo(seg.Args[0].X+x, seg.Args[0].Y+y)
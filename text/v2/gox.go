@@ -110,7 +110,7 @@ func (s *GoXFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffset i
 		if prevR >= 0 {
 			origin.X += s.f.Kern(prevR, r)
 		}
-		img, imgX, imgY, a := s.glyphImage(r, origin)
+		img, atlas, imgX, imgY, a := s.glyphImage(r, origin)
 
 		// Adjust the position to the integers.
 		// The current glyph images assume that they are rendered on integer positions so far.
@@ -128,6 +128,7 @@ func (s *GoXFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffset i
 			OriginY:           fixed26_6ToFloat64(origin.Y),
 			OriginOffsetX:     0,
 			OriginOffsetY:     0,
+			atlas:             atlas,
 		})
 		origin.X += a
 		prevR = r
@@ -136,7 +137,7 @@ func (s *GoXFace) appendGlyphsForLine(glyphs []Glyph, line string, indexOffset i
 	return glyphs
 }
 
-func (s *GoXFace) glyphImage(r rune, origin fixed.Point26_6) (*ebiten.Image, int, int, fixed.Int26_6) {
+func (s *GoXFace) glyphImage(r rune, origin fixed.Point26_6) (*ebiten.Image, *ebiten.Image, int, int, fixed.Int26_6) {
 	// Assume that GoXFace's direction is always horizontal.
 	origin.X = adjustGranularity(origin.X, s)
 	origin.Y &^= ((1 << 6) - 1)
@@ -150,18 +151,18 @@ func (s *GoXFace) glyphImage(r rune, origin fixed.Point26_6) (*ebiten.Image, int
 		rune:    r,
 		xoffset: subpixelOffset.X,
 	}
-	img := s.glyphImageCache.getOrCreate(s, key, func() *ebiten.Image {
+	img, atlas := s.glyphImageCache.getOrCreate(s, key, func() (*ebiten.Image, *ebiten.Image) {
 		return s.glyphImageImpl(r, subpixelOffset, b)
 	})
 	imgX := (origin.X + b.Min.X).Floor()
 	imgY := (origin.Y + b.Min.Y).Floor()
-	return img, imgX, imgY, a
+	return img, atlas, imgX, imgY, a
 }
 
-func (s *GoXFace) glyphImageImpl(r rune, subpixelOffset fixed.Point26_6, glyphBounds fixed.Rectangle26_6) *ebiten.Image {
+func (s *GoXFace) glyphImageImpl(r rune, subpixelOffset fixed.Point26_6, glyphBounds fixed.Rectangle26_6) (*ebiten.Image, *ebiten.Image) {
 	w, h := (glyphBounds.Max.X - glyphBounds.Min.X).Ceil(), (glyphBounds.Max.Y - glyphBounds.Min.Y).Ceil()
 	if w == 0 || h == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Add always 1 to the size.
@@ -182,7 +183,7 @@ func (s *GoXFace) glyphImageImpl(r rune, subpixelOffset fixed.Point26_6, glyphBo
 	}
 	d.DrawString(string(r))
 
-	return ebiten.NewImageFromImage(rgba)
+	return theGlyphAtlas.newImage(rgba)
 }
 
 // direction implements Face.
@@ -194,6 +195,11 @@ func (s *GoXFace) direction() Direction {
 func (s *GoXFace) appendVectorPathForLine(path *vector.Path, line string, originX, originY float64) {
 }
 
+// appendGlyphVectorPathsForLine implements Face.
+func (s *GoXFace) appendGlyphVectorPathsForLine(paths []GlyphVectorPath, line string, indexOffset int, originX, originY float64) []GlyphVectorPath {
+	return paths
+}
+
 // Metrics implements Face.
 func (s *GoXFace) private() {
 }
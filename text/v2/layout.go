@@ -73,6 +73,10 @@ type LayoutOptions struct {
 // As the cache capacity has limit, it is not guaranteed that all the glyphs for runes given at Draw are cached.
 //
 // It is OK to call Draw with a same text and a same face at every frame in terms of performance.
+// Draw caches the laid-out glyphs and their vertices for the same text, the same face and the same layout options,
+// so drawing a static text repeatedly is done with one batched draw call without walking the glyphs again.
+// The cached layout is removed when it is not used for a while. See also InvalidateDrawCache.
+// Note that the cache is effective only when the same Face object is reused.
 //
 // Draw is concurrent-safe.
 //
@@ -100,6 +104,11 @@ type LayoutOptions struct {
 // If the vertical alignment is top, the rendering region's top Y comes to the destination image's origin (0, 0).
 // If the vertical alignment is center, the rendering region's middle Y comes to the origin.
 // If the vertical alignment is bottom, the rendering region's bottom Y comes to the origin.
+//
+// # Subpixel antialiasing
+//
+// Glyphs with a subpixel antialiasing mode like AntialiasSubpixelRGB are rendered with per-component alpha blending.
+// In this case, DrawImageOptions.Blend is ignored for such glyphs.
 func Draw(dst *ebiten.Image, text string, face Face, options *DrawOptions) {
 	var layoutOp LayoutOptions
 	var drawOp ebiten.DrawImageOptions
@@ -109,17 +118,7 @@ func Draw(dst *ebiten.Image, text string, face Face, options *DrawOptions) {
 		drawOp = options.DrawImageOptions
 	}
 
-	geoM := drawOp.GeoM
-
-	for _, g := range AppendGlyphs(nil, text, face, &layoutOp) {
-		if g.Image == nil {
-			continue
-		}
-		drawOp.GeoM.Reset()
-		drawOp.GeoM.Translate(g.X, g.Y)
-		drawOp.GeoM.Concat(geoM)
-		dst.DrawImage(g.Image, &drawOp)
-	}
+	theDrawCache.draw(dst, text, face, &layoutOp, &drawOp)
 }
 
 // AppendGlyphs appends glyphs to the given slice and returns a slice.
@@ -144,6 +143,20 @@ func AppendVectorPath(path *vector.Path, text string, face Face, options *Layout
 	})
 }
 
+// AppendGlyphVectorPaths appends vector paths for each glyph to the given slice and returns a slice.
+//
+// AppendGlyphVectorPaths is useful to stroke, clip, morph or animate each glyph as geometry.
+// AppendGlyphVectorPaths appends an element even for a glyph without an outline, like a space, with an empty path.
+//
+// AppendGlyphVectorPaths works only when the face is *GoTextFace or a composite face using *GoTextFace so far.
+// For other types, AppendGlyphVectorPaths appends nothing.
+func AppendGlyphVectorPaths(paths []GlyphVectorPath, text string, face Face, options *LayoutOptions) []GlyphVectorPath {
+	forEachLine(text, face, options, func(line string, indexOffset int, originX, originY float64) {
+		paths = face.appendGlyphVectorPathsForLine(paths, line, indexOffset, originX, originY)
+	})
+	return paths
+}
+
 // appendGlyphs appends glyphs to the given slice and returns a slice.
 //
 // appendGlyphs assumes the text is rendered with the position (x, y).
@@ -79,6 +79,41 @@ func (m *MultiFace) Metrics() Metrics {
 	return mt
 }
 
+// metricsForLine returns the metrics of the faces used for the given line.
+// If the line is empty, metricsForLine returns the same value as Metrics.
+func (m *MultiFace) metricsForLine(line string) Metrics {
+	if line == "" {
+		return m.Metrics()
+	}
+
+	var mt Metrics
+	for _, c := range m.splitText(line) {
+		if c.faceIndex == -1 {
+			continue
+		}
+		mt1 := metricsForLine(m.faces[c.faceIndex], line[c.textStartIndex:c.textEndIndex])
+		if mt1.HLineGap > mt.HLineGap {
+			mt.HLineGap = mt1.HLineGap
+		}
+		if mt1.HAscent > mt.HAscent {
+			mt.HAscent = mt1.HAscent
+		}
+		if mt1.HDescent > mt.HDescent {
+			mt.HDescent = mt1.HDescent
+		}
+		if mt1.VLineGap > mt.VLineGap {
+			mt.VLineGap = mt1.VLineGap
+		}
+		if mt1.VAscent > mt.VAscent {
+			mt.VAscent = mt1.VAscent
+		}
+		if mt1.VDescent > mt.VDescent {
+			mt.VDescent = mt1.VDescent
+		}
+	}
+	return mt
+}
+
 // advance implements Face.
 func (m *MultiFace) advance(text string) float64 {
 	var a float64
@@ -138,6 +173,25 @@ func (m *MultiFace) appendVectorPathForLine(path *vector.Path, line string, orig
 	}
 }
 
+// appendGlyphVectorPathsForLine implements Face.
+func (m *MultiFace) appendGlyphVectorPathsForLine(paths []GlyphVectorPath, line string, indexOffset int, originX, originY float64) []GlyphVectorPath {
+	for _, c := range m.splitText(line) {
+		if c.faceIndex == -1 {
+			continue
+		}
+		f := m.faces[c.faceIndex]
+		t := line[c.textStartIndex:c.textEndIndex]
+		paths = f.appendGlyphVectorPathsForLine(paths, t, indexOffset, originX, originY)
+		if a := f.advance(t); f.direction().isHorizontal() {
+			originX += a
+		} else {
+			originY += a
+		}
+		indexOffset += len(t)
+	}
+	return paths
+}
+
 // direction implements Face.
 func (m *MultiFace) direction() Direction {
 	if len(m.faces) == 0 {
@@ -0,0 +1,104 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Layout is a text laid out in advance.
+//
+// Layout is useful to reveal a text incrementally like a typewriter effect.
+// As the whole text is shaped only once at NewLayout, the glyph positions don't change
+// even when ligatures or kernings are involved, unlike drawing substrings of the text.
+type Layout struct {
+	glyphs    []Glyph
+	clusters  []Cluster
+	direction Direction
+}
+
+// NewLayout lays out the given text and returns a Layout.
+//
+// For the details of options, see Draw function.
+func NewLayout(text string, face Face, options *LayoutOptions) *Layout {
+	return &Layout{
+		glyphs:    AppendGlyphs(nil, text, face, options),
+		clusters:  AppendClusters(nil, text, face, options),
+		direction: face.direction(),
+	}
+}
+
+// ClusterCount returns the number of grapheme clusters in the text.
+func (l *Layout) ClusterCount() int {
+	return len(l.clusters)
+}
+
+// Cluster returns the i-th grapheme cluster in the logical order.
+func (l *Layout) Cluster(i int) Cluster {
+	return l.clusters[i]
+}
+
+// Cut returns the cut position when the first count grapheme clusters are revealed.
+//
+// indexInBytes is the end index in bytes of the revealed text.
+// (x, y) is the position where the next cluster would start, which is useful e.g. to put a cursor.
+// The position doesn't include the translation by DrawImageOptions.GeoM at Draw.
+//
+// If count is out of range, count is clamped.
+func (l *Layout) Cut(count int) (indexInBytes int, x, y float64) {
+	if len(l.clusters) == 0 {
+		return 0, 0, 0
+	}
+	if count <= 0 {
+		c := l.clusters[0]
+		if l.direction == DirectionRightToLeft {
+			return c.StartIndexInBytes, c.OriginX + c.Advance, c.OriginY
+		}
+		return c.StartIndexInBytes, c.OriginX, c.OriginY
+	}
+	if count > len(l.clusters) {
+		count = len(l.clusters)
+	}
+	c := l.clusters[count-1]
+	switch l.direction {
+	case DirectionLeftToRight:
+		return c.EndIndexInBytes, c.OriginX + c.Advance, c.OriginY
+	case DirectionRightToLeft:
+		return c.EndIndexInBytes, c.OriginX, c.OriginY
+	default:
+		return c.EndIndexInBytes, c.OriginX, c.OriginY + c.Advance
+	}
+}
+
+// Draw draws the first count grapheme clusters of the text on the given destination image dst.
+//
+// A glyph is rendered only when all the clusters the glyph covers are revealed.
+// For example, a ligature glyph for two clusters appears when the second cluster is revealed.
+//
+// options.GeoM is an additional geometry transformation like Draw function.
+func (l *Layout) Draw(dst *ebiten.Image, count int, options *ebiten.DrawImageOptions) {
+	if count <= 0 {
+		return
+	}
+	end, _, _ := l.Cut(count)
+
+	var drawOp ebiten.DrawImageOptions
+	if options != nil {
+		drawOp = *options
+	}
+	drawGlyphs(dst, l.glyphs, &drawOp, func(g *Glyph) bool {
+		return g.EndIndexInBytes > end
+	})
+}
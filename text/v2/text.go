@@ -38,6 +38,7 @@ type Face interface {
 
 	appendGlyphsForLine(glyphs []Glyph, line string, indexOffset int, originX, originY float64) []Glyph
 	appendVectorPathForLine(path *vector.Path, line string, originX, originY float64)
+	appendGlyphVectorPathsForLine(paths []GlyphVectorPath, line string, indexOffset int, originX, originY float64) []GlyphVectorPath
 
 	direction() Direction
 
@@ -125,13 +126,20 @@ type Glyph struct {
 	GID uint32
 
 	// Image is a rasterized glyph image.
-	// Image is a grayscale image i.e. RGBA values are the same.
+	// Image is a grayscale image i.e. RGBA values are the same, unless Antialias is a subpixel antialiasing mode.
 	//
 	// Image should be used as a render source and must not be modified.
 	//
 	// Image can be nil.
 	Image *ebiten.Image
 
+	// Antialias is the antialiasing mode of Image.
+	//
+	// If Antialias is a subpixel antialiasing mode, Image is not a grayscale image,
+	// and each color component represents the coverage of the corresponding subpixel.
+	// Such an image must be rendered with per-component alpha blending. See Draw for the details.
+	Antialias Antialias
+
 	// X is the X position to render this glyph.
 	// The position is determined in a sequence of characters given at AppendGlyphs.
 	// The position's origin is the first character's origin position.
@@ -155,6 +163,35 @@ type Glyph struct {
 	// OriginOffsetY is the adjustment value to the Y position of the origin of this glyph.
 	// OriginOffsetY is usually 0, but can be non-zero for some special glyphs or glyphs in the vertical text layout.
 	OriginOffsetY float64
+
+	// atlas is the image that Image is a sub-image of.
+	// Glyphs sharing the same atlas can be rendered with one DrawTriangles32 call.
+	// atlas can be nil.
+	atlas *ebiten.Image
+}
+
+// GlyphVectorPath represents a vector path of a glyph.
+type GlyphVectorPath struct {
+	// StartIndexInBytes is the start index in bytes for the given string at AppendGlyphVectorPaths.
+	StartIndexInBytes int
+
+	// EndIndexInBytes is the end index in bytes for the given string at AppendGlyphVectorPaths.
+	EndIndexInBytes int
+
+	// GID is an ID for a glyph of TrueType or OpenType font. GID is valid when the face is GoTextFace.
+	GID uint32
+
+	// OriginX is the X position of the origin of this glyph.
+	OriginX float64
+
+	// OriginY is the Y position of the origin of this glyph.
+	OriginY float64
+
+	// Path is the outline of this glyph.
+	// Path is in the same coordinate as AppendVectorPath, i.e. Path is already placed at the glyph's position.
+	//
+	// Path is a new path for each glyph, and can be modified.
+	Path *vector.Path
 }
 
 // Advance returns the advanced distance from the origin position when rendering the given text with the given face.
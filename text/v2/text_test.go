@@ -15,19 +15,24 @@
 package text_test
 
 import (
+	"bytes"
 	"image"
 	"image/color"
+	"image/png"
 	"regexp"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/hajimehoshi/bitmapfont/v3"
 	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
 	"golang.org/x/image/math/fixed"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	t "github.com/hajimehoshi/ebiten/v2/internal/testing"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 func TestMain(m *testing.M) {
@@ -371,3 +376,359 @@ func TestDrawOptionsNotModified(t *testing.T) {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
 }
+
+func TestAppendClusters(t *testing.T) {
+	const sampleText = "e\u0301x\nab"
+
+	f := text.NewGoXFace(bitmapfont.Face)
+	cs := text.AppendClusters(nil, sampleText, f, &text.LayoutOptions{
+		LineSpacing: 20,
+	})
+
+	type cluster struct {
+		start int
+		end   int
+		line  int
+	}
+	var got []cluster
+	for _, c := range cs {
+		got = append(got, cluster{
+			start: c.StartIndexInBytes,
+			end:   c.EndIndexInBytes,
+			line:  c.LineIndex,
+		})
+	}
+	want := []cluster{
+		{start: 0, end: 3, line: 0},
+		{start: 3, end: 4, line: 0},
+		{start: 5, end: 6, line: 1},
+		{start: 6, end: 7, line: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("clusters[%d]: got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+
+	if got, want := cs[0].Advance+cs[1].Advance, text.Advance("e\u0301x", f); got != want {
+		t.Errorf("advance: got: %v, want: %v", got, want)
+	}
+	if got, want := cs[1].OriginX, cs[0].OriginX+cs[0].Advance; got != want {
+		t.Errorf("cs[1].OriginX: got: %v, want: %v", got, want)
+	}
+	if got, want := cs[2].OriginY-cs[0].OriginY, 20.0; got != want {
+		t.Errorf("cs[2].OriginY - cs[0].OriginY: got: %v, want: %v", got, want)
+	}
+}
+
+func TestAppendLineMetrics(t *testing.T) {
+	const sampleText = "Hello\n\nWorld"
+
+	f := text.NewGoXFace(bitmapfont.Face)
+	m := f.Metrics()
+	ls := text.AppendLineMetrics(nil, sampleText, f, &text.LayoutOptions{
+		LineSpacing: 20,
+	})
+	if got, want := len(ls), 3; got != want {
+		t.Fatalf("len(lines): got: %d, want: %d", got, want)
+	}
+	for i, l := range ls {
+		if got, want := l.OriginY, m.HAscent+20*float64(i); got != want {
+			t.Errorf("lines[%d].OriginY: got: %v, want: %v", i, got, want)
+		}
+		if got, want := l.Ascent, m.HAscent; got != want {
+			t.Errorf("lines[%d].Ascent: got: %v, want: %v", i, got, want)
+		}
+		if got, want := l.Descent, m.HDescent; got != want {
+			t.Errorf("lines[%d].Descent: got: %v, want: %v", i, got, want)
+		}
+		if got, want := l.Advance, text.Advance(sampleText[l.StartIndexInBytes:l.EndIndexInBytes], f); got != want {
+			t.Errorf("lines[%d].Advance: got: %v, want: %v", i, got, want)
+		}
+	}
+	if got, want := ls[2].StartIndexInBytes, 7; got != want {
+		t.Errorf("lines[2].StartIndexInBytes: got: %d, want: %d", got, want)
+	}
+}
+
+func TestBMFontFace(t *testing.T) {
+	const fnt = `info face="Test" size=8
+common lineHeight=10 base=8 scaleW=16 scaleH=8 pages=1 packed=0
+page id=0 file="test.png"
+chars count=2
+char id=65 x=0 y=0 width=8 height=8 xoffset=0 yoffset=0 xadvance=8 page=0 chnl=15
+char id=66 x=8 y=0 width=8 height=8 xoffset=1 yoffset=2 xadvance=9 page=0 chnl=15
+kernings count=1
+kerning first=65 second=66 amount=-2
+`
+
+	page := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 16; i++ {
+			page.Set(i, j, color.White)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, page); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{
+		"fonts/test.fnt": &fstest.MapFile{Data: []byte(fnt)},
+		"fonts/test.png": &fstest.MapFile{Data: buf.Bytes()},
+	}
+	f, err := text.NewBMFontFace(fsys, "fonts/test.fnt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := f.Metrics(), (text.Metrics{HAscent: 8, HDescent: 2}); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := text.Advance("AB", f), 8.0-2.0+9.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	gs := text.AppendGlyphs(nil, "AB", f, nil)
+	if got, want := len(gs), 2; got != want {
+		t.Fatalf("got: %d, want: %d", got, want)
+	}
+	if got, want := gs[1].X, 8.0-2.0+1.0; got != want {
+		t.Errorf("gs[1].X: got: %v, want: %v", got, want)
+	}
+	if got, want := gs[1].Y, 2.0; got != want {
+		t.Errorf("gs[1].Y: got: %v, want: %v", got, want)
+	}
+	if got, want := gs[1].Image.Bounds(), image.Rect(8, 0, 16, 8); got != want {
+		t.Errorf("gs[1].Image.Bounds(): got: %v, want: %v", got, want)
+	}
+}
+
+func TestLayoutCut(t *testing.T) {
+	f := text.NewGoXFace(bitmapfont.Face)
+	l := text.NewLayout("ab\nc", f, nil)
+	if got, want := l.ClusterCount(), 3; got != want {
+		t.Fatalf("ClusterCount(): got: %d, want: %d", got, want)
+	}
+
+	idx, x, _ := l.Cut(0)
+	if idx != 0 || x != 0 {
+		t.Errorf("Cut(0): got: (%d, %v), want: (0, 0)", idx, x)
+	}
+	idx, x, _ = l.Cut(1)
+	if got, want := x, text.Advance("a", f); idx != 1 || got != want {
+		t.Errorf("Cut(1): got: (%d, %v), want: (1, %v)", idx, got, want)
+	}
+	idx, x, y := l.Cut(3)
+	if got, want := x, text.Advance("c", f); idx != 4 || got != want {
+		t.Errorf("Cut(3): got: (%d, %v), want: (4, %v)", idx, got, want)
+	}
+	if got, want := y, l.Cluster(2).OriginY; got != want {
+		t.Errorf("Cut(3): got y: %v, want: %v", got, want)
+	}
+	if got, want := l.Cluster(2).LineIndex, 1; got != want {
+		t.Errorf("Cluster(2).LineIndex: got: %d, want: %d", got, want)
+	}
+}
+
+func TestLayoutDraw(t *testing.T) {
+	f := text.NewGoXFace(&testGoXFace{})
+	l := text.NewLayout("aa", f, nil)
+	dst := ebiten.NewImage(testGoXFaceSize*2, testGoXFaceSize)
+	l.Draw(dst, 1, nil)
+
+	for j := 0; j < testGoXFaceSize; j++ {
+		for i := 0; i < testGoXFaceSize*2; i++ {
+			got := dst.At(i, j)
+			var want color.RGBA
+			if i < testGoXFaceSize {
+				want = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80}
+			}
+			if got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestDrawCacheWithModifiedFace(t *testing.T) {
+	source, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &text.GoTextFace{
+		Source: source,
+		Size:   10,
+	}
+	dst0 := ebiten.NewImage(100, 40)
+	text.Draw(dst0, "Hello", f, nil)
+
+	// Modifying the face must not reuse the cached layout.
+	f.Size = 20
+	dst1 := ebiten.NewImage(100, 40)
+	text.Draw(dst1, "Hello", f, nil)
+
+	dst2 := ebiten.NewImage(100, 40)
+	text.Draw(dst2, "Hello", &text.GoTextFace{
+		Source: source,
+		Size:   20,
+	}, nil)
+
+	for j := 0; j < 40; j++ {
+		for i := 0; i < 100; i++ {
+			got := dst1.At(i, j)
+			want := dst2.At(i, j)
+			if got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	text.InvalidateDrawCache("Hello", f, nil)
+}
+
+func TestDrawBatch(t *testing.T) {
+	f := text.NewGoXFace(bitmapfont.Face)
+	const str = "Hello, World!"
+
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(3, 4)
+	op.ColorScale.ScaleWithColor(color.RGBA{R: 0x80, G: 0x40, B: 0x20, A: 0x80})
+
+	// Draw the glyphs one by one.
+	expected := ebiten.NewImage(100, 30)
+	for _, g := range text.AppendGlyphs(nil, str, f, &op.LayoutOptions) {
+		if g.Image == nil {
+			continue
+		}
+		dop := &ebiten.DrawImageOptions{}
+		dop.GeoM.Translate(g.X, g.Y)
+		dop.GeoM.Concat(op.GeoM)
+		dop.ColorScale = op.ColorScale
+		expected.DrawImage(g.Image, dop)
+	}
+
+	// The second and later draws use the cached vertices.
+	for n := 0; n < 3; n++ {
+		dst := ebiten.NewImage(100, 30)
+		text.Draw(dst, str, f, op)
+		for j := 0; j < 30; j++ {
+			for i := 0; i < 100; i++ {
+				if got, want := dst.At(i, j), expected.At(i, j); got != want {
+					t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+				}
+			}
+		}
+	}
+
+	text.InvalidateDrawCache(str, f, &op.LayoutOptions)
+}
+
+func TestSubpixelAntialias(t *testing.T) {
+	source, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &text.GoTextFace{
+		Source:    source,
+		Size:      12,
+		Antialias: text.AntialiasSubpixelRGB,
+	}
+	for _, g := range text.AppendGlyphs(nil, "Hello", f, nil) {
+		if got, want := g.Antialias, text.AntialiasSubpixelRGB; got != want {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	}
+
+	dst := ebiten.NewImage(50, 20)
+	dst.Fill(color.Black)
+	text.Draw(dst, "Hello", f, nil)
+
+	var colored bool
+	for j := 0; j < 20; j++ {
+		for i := 0; i < 50; i++ {
+			c := dst.At(i, j).(color.RGBA)
+			if c.A != 0xff {
+				t.Errorf("At(%d, %d): got: %v, want: opaque", i, j, c)
+			}
+			if c.R != c.B {
+				colored = true
+			}
+		}
+	}
+	if !colored {
+		t.Errorf("subpixel antialiasing must render colored pixels")
+	}
+}
+
+func TestAppendGlyphVectorPaths(t *testing.T) {
+	const sampleText = "ab\nc"
+
+	source, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &text.GoTextFace{
+		Source: source,
+		Size:   32,
+	}
+	op := &text.LayoutOptions{
+		LineSpacing: 40,
+	}
+	paths := text.AppendGlyphVectorPaths(nil, sampleText, f, op)
+
+	type glyph struct {
+		start int
+		end   int
+	}
+	var got []glyph
+	for _, p := range paths {
+		got = append(got, glyph{
+			start: p.StartIndexInBytes,
+			end:   p.EndIndexInBytes,
+		})
+	}
+	want := []glyph{
+		{start: 0, end: 1},
+		{start: 1, end: 2},
+		{start: 3, end: 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("paths[%d]: got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+
+	if got, want := paths[2].OriginY-paths[0].OriginY, 40.0; got != want {
+		t.Errorf("paths[2].OriginY - paths[0].OriginY: got: %v, want: %v", got, want)
+	}
+
+	// The glyph paths must be the same as the path for the whole text.
+	var whole vector.Path
+	text.AppendVectorPath(&whole, sampleText, f, op)
+	wantVs, _ := whole.AppendVerticesAndIndicesForFilling(nil, nil)
+	var gotVs []ebiten.Vertex
+	for i, p := range paths {
+		vs, _ := p.Path.AppendVerticesAndIndicesForFilling(nil, nil)
+		if len(vs) == 0 {
+			t.Errorf("paths[%d].Path is empty", i)
+		}
+		gotVs = append(gotVs, vs...)
+	}
+	if len(gotVs) != len(wantVs) {
+		t.Fatalf("the number of vertices: got: %d, want: %d", len(gotVs), len(wantVs))
+	}
+	for i := range gotVs {
+		if gotVs[i] != wantVs[i] {
+			t.Errorf("vertices[%d]: got: %v, want: %v", i, gotVs[i], wantVs[i])
+		}
+	}
+}
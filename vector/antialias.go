@@ -0,0 +1,120 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"image"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// AntiAliasQuality represents the quality of anti-aliasing.
+type AntiAliasQuality int
+
+const (
+	// AntiAliasQualityDefault uses the anti-aliasing of DrawTriangles, which renders with 2x2 supersampling.
+	AntiAliasQualityDefault AntiAliasQuality = iota
+
+	// AntiAliasQualityHigh renders with 4x4 supersampling on an offscreen image.
+	// AntiAliasQualityHigh makes smoother edges especially for thin strokes and small shapes,
+	// but costs more memory and fill rate than AntiAliasQualityDefault.
+	//
+	// If the shape is too big, AntiAliasQualityDefault is used instead.
+	AntiAliasQualityHigh
+)
+
+const (
+	supersamplingScale   = 4
+	maxSupersamplingSize = 4096
+)
+
+var (
+	// supersamplingImages are offscreen images for AntiAliasQualityHigh.
+	// supersamplingImages[0] is for the 4x scale, and supersamplingImages[1] is for the 2x scale.
+	supersamplingImages   [2]*ebiten.Image
+	supersamplingVertices []ebiten.Vertex
+	supersamplingM        sync.Mutex
+)
+
+// drawTrianglesWithSupersampling draws the triangles with 4x4 supersampling.
+// drawTrianglesWithSupersampling returns false if the triangles are too big for supersampling.
+func drawTrianglesWithSupersampling(dst *ebiten.Image, vs []ebiten.Vertex, is []uint16, shader *ebiten.Shader, uniforms map[string]any, img *ebiten.Image, fillRule ebiten.FillRule, blend ebiten.Blend) bool {
+	minX, minY, maxX, maxY := vs[0].DstX, vs[0].DstY, vs[0].DstX, vs[0].DstY
+	for _, v := range vs[1:] {
+		minX = min32(minX, v.DstX)
+		minY = min32(minY, v.DstY)
+		maxX = max32(maxX, v.DstX)
+		maxY = max32(maxY, v.DstY)
+	}
+	r := image.Rect(int(math.Floor(float64(minX))), int(math.Floor(float64(minY))), int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))))
+	r = r.Inset(-1).Intersect(dst.Bounds())
+	if r.Empty() {
+		return true
+	}
+	w, h := r.Dx()*supersamplingScale, r.Dy()*supersamplingScale
+	if w > maxSupersamplingSize || h > maxSupersamplingSize {
+		return false
+	}
+
+	supersamplingM.Lock()
+	defer supersamplingM.Unlock()
+
+	big := ensureSupersamplingImage(0, w, h)
+	half := ensureSupersamplingImage(1, w/2, h/2)
+	big.Clear()
+	half.Clear()
+
+	// Copy the vertices not to modify the given vertices, which might be reused like CompiledPath.
+	supersamplingVertices = append(supersamplingVertices[:0], vs...)
+	for i := range supersamplingVertices {
+		supersamplingVertices[i].DstX = (supersamplingVertices[i].DstX - float32(r.Min.X)) * supersamplingScale
+		supersamplingVertices[i].DstY = (supersamplingVertices[i].DstY - float32(r.Min.Y)) * supersamplingScale
+	}
+	drawTriangles(big, supersamplingVertices, is, shader, uniforms, img, fillRule, ebiten.Blend{}, false)
+
+	// Downscale the image twice by 1/2 with the linear filter. Each downscaling averages 2x2 pixels exactly.
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(0.5, 0.5)
+	op.Filter = ebiten.FilterLinear
+	half.DrawImage(big, op)
+
+	op.GeoM.Translate(float64(r.Min.X), float64(r.Min.Y))
+	op.Blend = blend
+	dst.DrawImage(half, op)
+	return true
+}
+
+// ensureSupersamplingImage returns an offscreen image with the size (w, h) at the index.
+func ensureSupersamplingImage(index int, w, h int) *ebiten.Image {
+	img := supersamplingImages[index]
+	if img == nil || img.Bounds().Dx() < w || img.Bounds().Dy() < h {
+		// Allocate a bigger image so that the image can be reused for various sizes.
+		newW, newH := w, h
+		if img != nil {
+			if b := img.Bounds(); b.Dx() > newW {
+				newW = b.Dx()
+			}
+			if b := img.Bounds(); b.Dy() > newH {
+				newH = b.Dy()
+			}
+			img.Deallocate()
+		}
+		img = ebiten.NewImage(newW, newH)
+		supersamplingImages[index] = img
+	}
+	return img.SubImage(image.Rect(0, 0, w, h)).(*ebiten.Image)
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Batch batches many fills and strokes into a few DrawTriangles calls.
+//
+// FillPath and StrokePath of the package render each shape with one draw call, as each shape needs its own stencil buffer.
+// Batch instead triangulates each shape into non-overlapping triangles on CPU, and accumulates them.
+// This is efficient for many simple shapes like UI, but triangulating a complex path is expensive.
+//
+// The accumulated triangles are rendered onto the destination image when
+//
+//   - Flush is called,
+//   - a shape with a different Blend, Paint, AntiAlias or AntiAliasQuality in DrawPathOptions is added, or
+//   - the number of the accumulated vertices exceeds the limitation of the indices.
+//
+// ColorScale in DrawPathOptions can vary for each shape without flushing.
+//
+// As the rendering is delayed, call Flush before rendering onto the destination image in other ways,
+// or before reading the pixels of the destination image. Otherwise, the rendering order is not guaranteed.
+type Batch struct {
+	dst *ebiten.Image
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+	options  DrawPathOptions
+
+	tmpVertices []ebiten.Vertex
+	tmpIndices  []uint16
+	tmpPath     Path
+}
+
+// NewBatch creates a new Batch for the destination image dst.
+func NewBatch(dst *ebiten.Image) *Batch {
+	return &Batch{
+		dst: dst,
+	}
+}
+
+// FillPath adds a fill of the path to the batch.
+//
+// Only FillRule in fillOptions is used. If fillOptions is nil, the default options are used.
+func (b *Batch) FillPath(path *Path, fillOptions *FillOptions, drawPathOptions *DrawPathOptions) {
+	var fillRule FillRule
+	if fillOptions != nil {
+		fillRule = fillOptions.FillRule
+	}
+	b.tmpVertices, b.tmpIndices = path.AppendVerticesAndIndicesForTriangulation(b.tmpVertices[:0], b.tmpIndices[:0], fillRule)
+	b.add(b.tmpVertices, b.tmpIndices, drawPathOptions)
+}
+
+// StrokePath adds a stroke of the path to the batch.
+//
+// The overlapping triangles of the stroke are merged so that a translucent stroke is rendered correctly.
+// The strokes of different StrokePath calls are not merged, and their overlaps are rendered multiple times.
+func (b *Batch) StrokePath(path *Path, strokeOptions *StrokeOptions, drawPathOptions *DrawPathOptions) {
+	b.tmpVertices, b.tmpIndices = path.AppendVerticesAndIndicesForStroke(b.tmpVertices[:0], b.tmpIndices[:0], strokeOptions)
+
+	// All the triangles for a stroke are clockwise. Merge them with FillRuleNonZero.
+	b.tmpPath.reset()
+	for i := 0; i < len(b.tmpIndices); i += 3 {
+		v0, v1, v2 := b.tmpVertices[b.tmpIndices[i]], b.tmpVertices[b.tmpIndices[i+1]], b.tmpVertices[b.tmpIndices[i+2]]
+		b.tmpPath.MoveTo(v0.DstX, v0.DstY)
+		b.tmpPath.LineTo(v1.DstX, v1.DstY)
+		b.tmpPath.LineTo(v2.DstX, v2.DstY)
+		b.tmpPath.Close()
+	}
+	b.tmpVertices, b.tmpIndices = b.tmpPath.AppendVerticesAndIndicesForTriangulation(b.tmpVertices[:0], b.tmpIndices[:0], FillRuleNonZero)
+	b.add(b.tmpVertices, b.tmpIndices, drawPathOptions)
+}
+
+func (b *Batch) add(vs []ebiten.Vertex, is []uint16, options *DrawPathOptions) {
+	if len(is) == 0 {
+		return
+	}
+	if options == nil {
+		options = &DrawPathOptions{}
+	}
+	if len(b.indices) > 0 {
+		if b.options.Blend != options.Blend || b.options.Paint != options.Paint || b.options.AntiAlias != options.AntiAlias || b.options.AntiAliasQuality != options.AntiAliasQuality {
+			b.Flush()
+		} else if len(b.vertices)+len(vs) > math.MaxUint16+1 {
+			b.Flush()
+		}
+	}
+	if len(vs) > math.MaxUint16+1 {
+		// The shape is too big to be batched.
+		setVertexColors(vs, options.ColorScale)
+		drawColoredVerticesForPath(b.dst, vs, is, ebiten.FillRuleFillAll, false, options)
+		return
+	}
+
+	b.options = *options
+	base := uint16(len(b.vertices))
+	n := len(b.vertices)
+	b.vertices = append(b.vertices, vs...)
+	setVertexColors(b.vertices[n:], options.ColorScale)
+	for _, idx := range is {
+		b.indices = append(b.indices, base+idx)
+	}
+}
+
+// Flush renders the accumulated triangles onto the destination image.
+func (b *Batch) Flush() {
+	if len(b.indices) == 0 {
+		return
+	}
+	drawColoredVerticesForPath(b.dst, b.vertices, b.indices, ebiten.FillRuleFillAll, false, &b.options)
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+	b.options = DrawPathOptions{}
+}
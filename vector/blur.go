@@ -0,0 +1,203 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// maxBlurSigma is the maximum standard deviation of the gaussian in the blur shader.
+// A bigger blur is rendered on a downscaled image.
+const maxBlurSigma = 4
+
+// BlurOptions is options to render a blurred path.
+type BlurOptions struct {
+	// Radius is the blur radius in pixels, like the blur radius of CSS's box-shadow.
+	// The standard deviation of the gaussian is Radius/2.
+	//
+	// The default (zero) value is 0, which means no blur.
+	Radius float32
+
+	// OffsetX is the offset of the rendering in the X direction in pixels.
+	//
+	// The default (zero) value is 0.
+	OffsetX float32
+
+	// OffsetY is the offset of the rendering in the Y direction in pixels.
+	//
+	// The default (zero) value is 0.
+	OffsetY float32
+}
+
+// FillPathWithBlur fills the specified path with a gaussian blur.
+//
+// FillPathWithBlur is useful to render a soft drop shadow. For example, call FillPathWithBlur with a translucent black
+// ColorScale and an offset, and then call FillPath for the same path.
+//
+// The path is rendered to an offscreen image, which is blurred and then rendered onto dst with the Blend of drawPathOptions.
+// A big blur radius is processed on a downscaled image, and then the cost doesn't grow much with the radius.
+//
+// If blurOptions is nil, FillPathWithBlur works in the same way as FillPath.
+func FillPathWithBlur(dst *ebiten.Image, path *Path, fillOptions *FillOptions, drawPathOptions *DrawPathOptions, blurOptions *BlurOptions) {
+	if blurOptions == nil {
+		FillPath(dst, path, fillOptions, drawPathOptions)
+		return
+	}
+	if drawPathOptions == nil {
+		drawPathOptions = &DrawPathOptions{}
+	}
+
+	sigma := float64(blurOptions.Radius) / 2
+	if sigma < 0 {
+		sigma = 0
+	}
+	margin := int(math.Ceil(sigma * 3))
+
+	// Calculate the region to render in the path coordinates.
+	vs, _ := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	if len(vs) == 0 {
+		return
+	}
+	minX, minY, maxX, maxY := vs[0].DstX, vs[0].DstY, vs[0].DstX, vs[0].DstY
+	for _, v := range vs[1:] {
+		minX = min32(minX, v.DstX)
+		minY = min32(minY, v.DstY)
+		maxX = max32(maxX, v.DstX)
+		maxY = max32(maxY, v.DstY)
+	}
+	r := image.Rect(int(math.Floor(float64(minX))), int(math.Floor(float64(minY))), int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))))
+	r = r.Inset(-margin - 1)
+	// The region outside of the destination doesn't have to be rendered.
+	dr := dst.Bounds().Sub(image.Pt(int(math.Floor(float64(blurOptions.OffsetX))), int(math.Floor(float64(blurOptions.OffsetY)))))
+	r = r.Intersect(dr.Inset(-margin - 1))
+	if r.Empty() {
+		return
+	}
+
+	// The offscreen image has the same coordinates as the path.
+	src := ebiten.NewImageWithOptions(r, nil)
+	defer src.Deallocate()
+	FillPath(src, path, fillOptions, &DrawPathOptions{
+		AntiAlias:  drawPathOptions.AntiAlias,
+		ColorScale: drawPathOptions.ColorScale,
+		Paint:      drawPathOptions.Paint,
+	})
+
+	// Downscale the image so that the standard deviation fits with the shader.
+	img := src
+	scale := 1
+	for sigma/float64(scale) > maxBlurSigma {
+		b := img.Bounds()
+		w, h := (b.Dx()+1)/2, (b.Dy()+1)/2
+		half := ebiten.NewImage(w, h)
+		defer half.Deallocate()
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(0.5, 0.5)
+		op.Filter = ebiten.FilterLinear
+		half.DrawImage(img, op)
+		img = half
+		scale *= 2
+	}
+
+	if sigma > 0 {
+		b := img.Bounds()
+		tmp := ebiten.NewImage(b.Dx(), b.Dy())
+		defer tmp.Deallocate()
+		blurred := ebiten.NewImage(b.Dx(), b.Dy())
+		defer blurred.Deallocate()
+
+		s := float32(sigma / float64(scale))
+		op := &ebiten.DrawRectShaderOptions{}
+		op.Images[0] = img
+		op.Uniforms = map[string]any{
+			"Direction": []float32{1, 0},
+			"Sigma":     s,
+		}
+		tmp.DrawRectShader(b.Dx(), b.Dy(), blurShader(), op)
+
+		op.Images[0] = tmp
+		op.Uniforms = map[string]any{
+			"Direction": []float32{0, 1},
+			"Sigma":     s,
+		}
+		blurred.DrawRectShader(b.Dx(), b.Dy(), blurShader(), op)
+		img = blurred
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(scale), float64(scale))
+	op.GeoM.Translate(float64(r.Min.X)+float64(blurOptions.OffsetX), float64(r.Min.Y)+float64(blurOptions.OffsetY))
+	if scale > 1 {
+		op.Filter = ebiten.FilterLinear
+	}
+	op.Blend = drawPathOptions.Blend
+	dst.DrawImage(img, op)
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+var (
+	theBlurShader     *ebiten.Shader
+	theBlurShaderOnce sync.Once
+)
+
+func blurShader() *ebiten.Shader {
+	theBlurShaderOnce.Do(func() {
+		s, err := ebiten.NewShader([]byte(blurShaderSource))
+		if err != nil {
+			panic(fmt.Sprintf("vector: compiling the blur shader failed: %v", err))
+		}
+		theBlurShader = s
+	})
+	return theBlurShader
+}
+
+// blurShaderSource is a shader for a one-dimensional gaussian blur.
+// The loop range covers 3 * maxBlurSigma.
+const blurShaderSource = `//kage:unit pixels
+
+package main
+
+var Direction vec2
+var Sigma float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var sum vec4
+	var total float
+	for i := -12; i <= 12; i++ {
+		w := exp(-float(i*i) / (2 * Sigma * Sigma))
+		sum += imageSrc0At(srcPos+Direction*float(i)) * w
+		total += w
+	}
+	return sum / total
+}
+`
@@ -0,0 +1,313 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+	"sort"
+)
+
+// Union returns a new path representing the area covered by a or b.
+//
+// a and b are regarded as filled areas with options.FillRule.
+// Curves are flattened, and open subpaths are regarded as closed, in the same way as FillPath.
+// If options is nil, FillRuleNonZero is used.
+//
+// The subpaths of the returned path are closed, and their outer boundaries are clockwise.
+// The returned path can be filled with either FillRuleNonZero or FillRuleEvenOdd.
+//
+// The time complexity is O(n²) where n is the number of the flattened segments.
+func Union(a, b *Path, options *FillOptions) *Path {
+	return booleanOperation(a, b, options, func(inA, inB bool) bool {
+		return inA || inB
+	})
+}
+
+// Intersect returns a new path representing the area covered by both a and b.
+//
+// For the details of the arguments and the result, see Union.
+func Intersect(a, b *Path, options *FillOptions) *Path {
+	return booleanOperation(a, b, options, func(inA, inB bool) bool {
+		return inA && inB
+	})
+}
+
+// Difference returns a new path representing the area covered by a but not by b.
+//
+// For the details of the arguments and the result, see Union.
+func Difference(a, b *Path, options *FillOptions) *Path {
+	return booleanOperation(a, b, options, func(inA, inB bool) bool {
+		return inA && !inB
+	})
+}
+
+// Xor returns a new path representing the area covered by exactly one of a and b.
+//
+// For the details of the arguments and the result, see Union.
+func Xor(a, b *Path, options *FillOptions) *Path {
+	return booleanOperation(a, b, options, func(inA, inB bool) bool {
+		return inA != inB
+	})
+}
+
+type bpoint struct {
+	x float64
+	y float64
+}
+
+type bsegment struct {
+	p0 bpoint
+	p1 bpoint
+}
+
+// bsplit is a split position of a segment.
+type bsplit struct {
+	t  float64
+	pt bpoint
+}
+
+const booleanEpsilon = 1e-9
+
+// appendSegmentsForFilling appends the segments of the filled area of the path.
+func appendSegmentsForFilling(segs []bsegment, path *Path) []bsegment {
+	if path == nil {
+		return segs
+	}
+	for _, s := range path.ensureSubpaths() {
+		if s.pointCount() < 3 {
+			continue
+		}
+		for i := 0; i < s.pointCount(); i++ {
+			p0 := s.points[i]
+			var p1 point
+			if i < s.pointCount()-1 {
+				p1 = s.points[i+1]
+			} else {
+				// Regard an open subpath as closed.
+				p1 = s.points[0]
+			}
+			if p0 == p1 {
+				continue
+			}
+			segs = append(segs, bsegment{
+				p0: bpoint{x: float64(p0.x), y: float64(p0.y)},
+				p1: bpoint{x: float64(p1.x), y: float64(p1.y)},
+			})
+		}
+	}
+	return segs
+}
+
+// isInside reports whether pt is inside the area enclosed by the segments.
+func isInside(pt bpoint, segs []bsegment, fillRule FillRule) bool {
+	var w int
+	for _, s := range segs {
+		c := (s.p1.x-s.p0.x)*(pt.y-s.p0.y) - (pt.x-s.p0.x)*(s.p1.y-s.p0.y)
+		if s.p0.y <= pt.y {
+			if s.p1.y > pt.y && c > 0 {
+				w++
+			}
+		} else {
+			if s.p1.y <= pt.y && c < 0 {
+				w--
+			}
+		}
+	}
+	if fillRule == FillRuleEvenOdd {
+		return w%2 != 0
+	}
+	return w != 0
+}
+
+func lerpBPoint(p0, p1 bpoint, t float64) bpoint {
+	return bpoint{
+		x: p0.x + (p1.x-p0.x)*t,
+		y: p0.y + (p1.y-p0.y)*t,
+	}
+}
+
+// projectToSegment returns the parameter of the projection of pt onto s.
+func projectToSegment(pt bpoint, s bsegment) float64 {
+	dx, dy := s.p1.x-s.p0.x, s.p1.y-s.p0.y
+	return ((pt.x-s.p0.x)*dx + (pt.y-s.p0.y)*dy) / (dx*dx + dy*dy)
+}
+
+// splitSegments splits the segments at all the intersections.
+func splitSegments(segs []bsegment) []bsegment {
+	splits := make([][]bsplit, len(segs))
+
+	for i := range segs {
+		si := segs[i]
+		di := bpoint{x: si.p1.x - si.p0.x, y: si.p1.y - si.p0.y}
+		li := math.Hypot(di.x, di.y)
+		for j := i + 1; j < len(segs); j++ {
+			sj := segs[j]
+			dj := bpoint{x: sj.p1.x - sj.p0.x, y: sj.p1.y - sj.p0.y}
+			lj := math.Hypot(dj.x, dj.y)
+
+			denom := di.x*dj.y - di.y*dj.x
+			if math.Abs(denom) <= booleanEpsilon*li*lj {
+				// The segments are parallel. If they are collinear, split them at each other's end points.
+				if math.Abs(di.x*(sj.p0.y-si.p0.y)-di.y*(sj.p0.x-si.p0.x)) > booleanEpsilon*li*li {
+					continue
+				}
+				for _, pt := range [...]bpoint{sj.p0, sj.p1} {
+					if t := projectToSegment(pt, si); t > booleanEpsilon && t < 1-booleanEpsilon {
+						splits[i] = append(splits[i], bsplit{t: t, pt: pt})
+					}
+				}
+				for _, pt := range [...]bpoint{si.p0, si.p1} {
+					if t := projectToSegment(pt, sj); t > booleanEpsilon && t < 1-booleanEpsilon {
+						splits[j] = append(splits[j], bsplit{t: t, pt: pt})
+					}
+				}
+				continue
+			}
+
+			d := bpoint{x: sj.p0.x - si.p0.x, y: sj.p0.y - si.p0.y}
+			ti := (d.x*dj.y - d.y*dj.x) / denom
+			tj := (d.x*di.y - d.y*di.x) / denom
+			if ti < -booleanEpsilon || ti > 1+booleanEpsilon || tj < -booleanEpsilon || tj > 1+booleanEpsilon {
+				continue
+			}
+
+			// Use exactly the same point for the both segments so that the split segments can be connected later.
+			// If the intersection is at an end point, use the end point.
+			var pt bpoint
+			switch {
+			case ti <= booleanEpsilon:
+				pt = si.p0
+			case ti >= 1-booleanEpsilon:
+				pt = si.p1
+			case tj <= booleanEpsilon:
+				pt = sj.p0
+			case tj >= 1-booleanEpsilon:
+				pt = sj.p1
+			default:
+				pt = lerpBPoint(si.p0, si.p1, ti)
+			}
+			if ti > booleanEpsilon && ti < 1-booleanEpsilon {
+				splits[i] = append(splits[i], bsplit{t: ti, pt: pt})
+			}
+			if tj > booleanEpsilon && tj < 1-booleanEpsilon {
+				splits[j] = append(splits[j], bsplit{t: tj, pt: pt})
+			}
+		}
+	}
+
+	var result []bsegment
+	for i, s := range segs {
+		ss := splits[i]
+		sort.Slice(ss, func(a, b int) bool {
+			return ss[a].t < ss[b].t
+		})
+		p0 := s.p0
+		for _, split := range ss {
+			if split.pt == p0 {
+				continue
+			}
+			result = append(result, bsegment{p0: p0, p1: split.pt})
+			p0 = split.pt
+		}
+		if p0 != s.p1 {
+			result = append(result, bsegment{p0: p0, p1: s.p1})
+		}
+	}
+	return result
+}
+
+func booleanOperation(a, b *Path, options *FillOptions, op func(inA, inB bool) bool) *Path {
+	var fillRule FillRule
+	if options != nil {
+		fillRule = options.FillRule
+	}
+
+	segsA := appendSegmentsForFilling(nil, a)
+	segsB := appendSegmentsForFilling(nil, b)
+
+	all := make([]bsegment, 0, len(segsA)+len(segsB))
+	all = append(all, segsA...)
+	all = append(all, segsB...)
+
+	// Pick the split segments on the boundary of the result area.
+	// A segment is on the boundary when one side is inside the result and the other side is not.
+	// Each picked segment is oriented so that the inside is on its left side in the math coordinate,
+	// which is clockwise in the screen coordinate.
+	var boundary []bsegment
+	seen := map[bsegment]struct{}{}
+	for _, s := range splitSegments(all) {
+		dx, dy := s.p1.x-s.p0.x, s.p1.y-s.p0.y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		m := lerpBPoint(s.p0, s.p1, 0.5)
+		const offset = 1e-4
+		n := bpoint{x: -dy / l * offset, y: dx / l * offset}
+		left := bpoint{x: m.x + n.x, y: m.y + n.y}
+		right := bpoint{x: m.x - n.x, y: m.y - n.y}
+		inLeft := op(isInside(left, segsA, fillRule), isInside(left, segsB, fillRule))
+		inRight := op(isInside(right, segsA, fillRule), isInside(right, segsB, fillRule))
+		if inLeft == inRight {
+			continue
+		}
+		if !inLeft {
+			s.p0, s.p1 = s.p1, s.p0
+		}
+		// Coincident segments from a and b must not be duplicated.
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		boundary = append(boundary, s)
+	}
+
+	// Connect the boundary segments into closed subpaths.
+	outgoings := map[bpoint][]int{}
+	for i, s := range boundary {
+		outgoings[s.p0] = append(outgoings[s.p0], i)
+	}
+	used := make([]bool, len(boundary))
+	var result Path
+	for i := range boundary {
+		if used[i] {
+			continue
+		}
+		start := boundary[i].p0
+		result.MoveTo(float32(start.x), float32(start.y))
+		cur := i
+		for {
+			used[cur] = true
+			end := boundary[cur].p1
+			if end == start {
+				break
+			}
+			result.LineTo(float32(end.x), float32(end.y))
+			next := -1
+			for _, j := range outgoings[end] {
+				if !used[j] {
+					next = j
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			cur = next
+		}
+		result.Close()
+	}
+	return &result
+}
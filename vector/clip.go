@@ -0,0 +1,116 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ClipStack is a stack of clip paths to limit rendering to arbitrary shapes.
+//
+// Push returns an offscreen image. Anything drawn to the image, by DrawImage, DrawTriangles, FillPath and so on,
+// is clipped by the path and rendered onto the underlying image when Pop is called.
+// Clip paths can be nested, and then the rendering is limited to the intersection of the clip paths.
+//
+// A ClipStack reuses its offscreen images. Using the same ClipStack every frame is more efficient than creating a new one.
+type ClipStack struct {
+	dst    *ebiten.Image
+	layers []clipLayer
+	pool   []*ebiten.Image
+}
+
+type clipLayer struct {
+	image *ebiten.Image
+	mask  *ebiten.Image
+}
+
+// NewClipStack creates a new ClipStack for the destination image dst.
+func NewClipStack(dst *ebiten.Image) *ClipStack {
+	return &ClipStack{
+		dst: dst,
+	}
+}
+
+// Image returns the image to draw to.
+// Image returns the offscreen image of the last Push, or the destination image if no clip path is pushed.
+func (c *ClipStack) Image() *ebiten.Image {
+	if len(c.layers) == 0 {
+		return c.dst
+	}
+	return c.layers[len(c.layers)-1].image
+}
+
+// Push pushes a clip path, and returns an offscreen image to draw to.
+// Push is equivalent to calling Image after pushing the clip path.
+//
+// The returned image has the same bounds as the destination image, and is cleared.
+//
+// If fillOptions is nil, the default options are used.
+// antiAlias is whether the edges of the clip path are anti-aliased.
+func (c *ClipStack) Push(path *Path, fillOptions *FillOptions, antiAlias bool) *ebiten.Image {
+	mask := c.allocImage()
+	FillPath(mask, path, fillOptions, &DrawPathOptions{
+		AntiAlias: antiAlias,
+	})
+	c.layers = append(c.layers, clipLayer{
+		image: c.allocImage(),
+		mask:  mask,
+	})
+	return c.Image()
+}
+
+// Pop pops the last clip path, and renders the offscreen image onto the underlying image with the clip path.
+//
+// Pop panics if there is no clip path.
+func (c *ClipStack) Pop() {
+	if len(c.layers) == 0 {
+		panic("vector: Pop is called without Push")
+	}
+	l := c.layers[len(c.layers)-1]
+	c.layers[len(c.layers)-1] = clipLayer{}
+	c.layers = c.layers[:len(c.layers)-1]
+
+	b := c.dst.Bounds()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(b.Min.X), float64(b.Min.Y))
+	op.Blend = ebiten.BlendDestinationIn
+	l.image.DrawImage(l.mask, op)
+
+	op.Blend = ebiten.Blend{}
+	c.Image().DrawImage(l.image, op)
+
+	c.releaseImage(l.mask)
+	c.releaseImage(l.image)
+}
+
+// Len returns the number of the pushed clip paths.
+func (c *ClipStack) Len() int {
+	return len(c.layers)
+}
+
+func (c *ClipStack) allocImage() *ebiten.Image {
+	if len(c.pool) > 0 {
+		img := c.pool[len(c.pool)-1]
+		c.pool[len(c.pool)-1] = nil
+		c.pool = c.pool[:len(c.pool)-1]
+		return img
+	}
+	return ebiten.NewImageWithOptions(c.dst.Bounds(), nil)
+}
+
+func (c *ClipStack) releaseImage(img *ebiten.Image) {
+	img.Clear()
+	c.pool = append(c.pool, img)
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// CompiledPath is a path with cached vertices and indices for filling and stroking.
+//
+// Tessellating a complex path every frame can be expensive.
+// CompiledPath tessellates the path only at the first rendering, and reuses the result at the following renderings.
+// The fill is tessellated again only when the fill algorithm or the flattening tolerance is changed,
+// and the stroke is tessellated again only when the stroke options are changed.
+type CompiledPath struct {
+	path Path
+
+	fillVertices  []ebiten.Vertex
+	fillIndices   []uint16
+	fillAlgorithm FillAlgorithm
+	fillTolerance float32
+	fillValid     bool
+
+	strokeVertices []ebiten.Vertex
+	strokeIndices  []uint16
+	strokeOptions  StrokeOptions
+	strokeValid    bool
+}
+
+// NewCompiledPath creates a new CompiledPath from the given path.
+//
+// The path is copied, and modifying the path after NewCompiledPath doesn't affect the CompiledPath.
+func NewCompiledPath(path *Path) *CompiledPath {
+	c := &CompiledPath{}
+	c.path.AddPath(path, nil)
+	return c
+}
+
+// Fill fills the path with the specified options, in the same way as FillPath.
+func (c *CompiledPath) Fill(dst *ebiten.Image, fillOptions *FillOptions, drawPathOptions *DrawPathOptions) {
+	if fillOptions == nil {
+		fillOptions = &FillOptions{}
+	}
+	if drawPathOptions == nil {
+		drawPathOptions = &DrawPathOptions{}
+	}
+
+	if !c.fillValid || c.fillAlgorithm != fillOptions.Algorithm || c.fillTolerance != fillOptions.FlatteningTolerance {
+		c.fillVertices, c.fillIndices = fillOptions.appendVerticesAndIndices(&c.path, c.fillVertices[:0], c.fillIndices[:0])
+		c.fillAlgorithm = fillOptions.Algorithm
+		c.fillTolerance = fillOptions.FlatteningTolerance
+		c.fillValid = true
+	}
+
+	// drawVerticesForPath modifies only the source positions and the colors of the vertices, then the vertices can be reused.
+	drawVerticesForPath(dst, c.fillVertices, c.fillIndices, fillOptions.FillRule.ebitenFillRule(), c.fillAlgorithm == FillAlgorithmGPUCurve, drawPathOptions)
+}
+
+// Stroke strokes the path with the specified options, in the same way as StrokePath.
+func (c *CompiledPath) Stroke(dst *ebiten.Image, strokeOptions *StrokeOptions, drawPathOptions *DrawPathOptions) {
+	if strokeOptions == nil {
+		return
+	}
+	if drawPathOptions == nil {
+		drawPathOptions = &DrawPathOptions{}
+	}
+
+	if !c.strokeValid || !c.strokeOptions.equals(strokeOptions) {
+		c.strokeVertices, c.strokeIndices = c.path.AppendVerticesAndIndicesForStroke(c.strokeVertices[:0], c.strokeIndices[:0], strokeOptions)
+		c.strokeOptions = *strokeOptions
+		// Copy the slices not to be affected by the caller's modification.
+		c.strokeOptions.DashArray = append([]float32(nil), strokeOptions.DashArray...)
+		c.strokeOptions.WidthProfile = append([]float32(nil), strokeOptions.WidthProfile...)
+		c.strokeValid = true
+	}
+
+	drawVerticesForPath(dst, c.strokeVertices, c.strokeIndices, ebiten.FillRuleNonZero, false, drawPathOptions)
+}
+
+func (s *StrokeOptions) equals(other *StrokeOptions) bool {
+	if s.Width != other.Width || s.LineCap != other.LineCap || s.LineJoin != other.LineJoin || s.MiterLimit != other.MiterLimit || s.DashOffset != other.DashOffset || s.FlatteningTolerance != other.FlatteningTolerance {
+		return false
+	}
+	return float32sEqual(s.DashArray, other.DashArray) && float32sEqual(s.WidthProfile, other.WidthProfile)
+}
+
+func float32sEqual(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
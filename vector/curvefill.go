@@ -0,0 +1,179 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// cubicToQuadTolerance is the allowed distance in pixels between a cubic curve and its approximation with quadratic curves.
+const cubicToQuadTolerance = 0.1
+
+// appendVerticesAndIndicesForCurveFilling appends vertices and indices to fill this path with FillAlgorithmGPUCurve.
+//
+// The polygon of the end points of each subpath is rendered as a triangle fan, and each quadratic curve is rendered as
+// a triangle of its control points. The vertices of the curve triangles have Custom0 and Custom1 as the canonical
+// coordinates (u, v) of the quadratic curve v = u^2, and Custom2 as 1. A shader discards the pixels
+// where u^2 - v > 0, i.e., outside of the curve.
+func (p *Path) appendVerticesAndIndicesForCurveFilling(vertices []ebiten.Vertex, indices []uint16) ([]ebiten.Vertex, []uint16) {
+	var cur point
+	var first, prev uint16
+	var count int
+	open := false
+
+	appendVertex := func(pt point, u, v, curve float32) uint16 {
+		idx := uint16(len(vertices))
+		vertices = append(vertices, ebiten.Vertex{
+			DstX:    pt.x,
+			DstY:    pt.y,
+			ColorR:  1,
+			ColorG:  1,
+			ColorB:  1,
+			ColorA:  1,
+			Custom0: u,
+			Custom1: v,
+			Custom2: curve,
+		})
+		return idx
+	}
+	startPolygon := func(pt point) {
+		first = appendVertex(pt, 0, 0, 0)
+		prev = first
+		count = 1
+		open = true
+	}
+	lineTo := func(pt point) {
+		if !open {
+			startPolygon(pt)
+			return
+		}
+		idx := appendVertex(pt, 0, 0, 0)
+		if count >= 2 {
+			indices = append(indices, first, prev, idx)
+		}
+		prev = idx
+		count++
+	}
+	quadTo := func(p0, p1, p2 point) {
+		i0 := appendVertex(p0, 0, 0, 1)
+		i1 := appendVertex(p1, 0.5, 0, 1)
+		i2 := appendVertex(p2, 1, 1, 1)
+		indices = append(indices, i0, i1, i2)
+		lineTo(p2)
+	}
+
+	for _, op := range p.ops {
+		switch op.typ {
+		case opTypeMoveTo:
+			startPolygon(op.p1)
+			cur = op.p1
+		case opTypeLineTo:
+			lineTo(op.p1)
+			cur = op.p1
+		case opTypeQuadTo:
+			quadTo(cur, op.p1, op.p2)
+			cur = op.p2
+		case opTypeCubicTo:
+			appendQuadsForCubic(cur, op.p1, op.p2, op.p3, quadTo)
+			cur = op.p3
+		case opTypeClose:
+			open = false
+			cur = point{}
+		}
+	}
+	return vertices, indices
+}
+
+// appendQuadsForCubic approximates the cubic curve with quadratic curves, and calls f for each quadratic curve.
+func appendQuadsForCubic(p0, p1, p2, p3 point, f func(p0, p1, p2 point)) {
+	// The distance between a cubic curve and the quadratic curve with the control point (3(p1+p2)-(p0+p3))/4 is
+	// at most sqrt(3)/36 * |p3 - 3p2 + 3p1 - p0|. Splitting the cubic curve into n pieces reduces the distance by 1/n^3.
+	dx := float64(p3.x - 3*p2.x + 3*p1.x - p0.x)
+	dy := float64(p3.y - 3*p2.y + 3*p1.y - p0.y)
+	d := math.Sqrt(3) / 36 * math.Hypot(dx, dy)
+	n := int(math.Ceil(math.Cbrt(d / cubicToQuadTolerance)))
+	if n < 1 {
+		n = 1
+	}
+	if n > 32 {
+		n = 32
+	}
+
+	eval := func(t float32) (pt point, deriv point) {
+		mt := 1 - t
+		pt = point{
+			x: mt*mt*mt*p0.x + 3*mt*mt*t*p1.x + 3*mt*t*t*p2.x + t*t*t*p3.x,
+			y: mt*mt*mt*p0.y + 3*mt*mt*t*p1.y + 3*mt*t*t*p2.y + t*t*t*p3.y,
+		}
+		deriv = point{
+			x: 3 * (mt*mt*(p1.x-p0.x) + 2*mt*t*(p2.x-p1.x) + t*t*(p3.x-p2.x)),
+			y: 3 * (mt*mt*(p1.y-p0.y) + 2*mt*t*(p2.y-p1.y) + t*t*(p3.y-p2.y)),
+		}
+		return
+	}
+
+	q0 := p0
+	_, d0 := eval(0)
+	for i := 1; i <= n; i++ {
+		t := float32(i) / float32(n)
+		q3, d3 := eval(t)
+		if i == n {
+			q3 = p3
+		}
+		// The control points of the piece of the cubic curve.
+		s := 1 / float32(3*n)
+		q1 := point{x: q0.x + d0.x*s, y: q0.y + d0.y*s}
+		q2 := point{x: q3.x - d3.x*s, y: q3.y - d3.y*s}
+		f(q0, point{
+			x: (3*(q1.x+q2.x) - (q0.x + q3.x)) / 4,
+			y: (3*(q1.y+q2.y) - (q0.y + q3.y)) / 4,
+		}, q3)
+		q0, d0 = q3, d3
+	}
+}
+
+var (
+	theCurveFillShader     *ebiten.Shader
+	theCurveFillShaderOnce sync.Once
+)
+
+func curveFillShader() *ebiten.Shader {
+	theCurveFillShaderOnce.Do(func() {
+		s, err := ebiten.NewShader([]byte(curveFillShaderSource))
+		if err != nil {
+			panic(fmt.Sprintf("vector: compiling the curve fill shader failed: %v", err))
+		}
+		theCurveFillShader = s
+	})
+	return theCurveFillShader
+}
+
+// curveFillShaderSource is a shader to render a solid color with FillAlgorithmGPUCurve.
+// The paint shaders have the same discarding logic.
+const curveFillShaderSource = `//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4, custom vec4) vec4 {
+	if custom.z > 0 && custom.x*custom.x-custom.y > 0 {
+		discard()
+	}
+	return color
+}
+`
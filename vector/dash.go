@@ -0,0 +1,181 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+)
+
+// dashPattern returns a normalized dash pattern.
+// dashPattern returns nil if the stroke should be solid.
+func dashPattern(dashArray []float32) []float32 {
+	if len(dashArray) == 0 {
+		return nil
+	}
+	var total float32
+	for _, d := range dashArray {
+		if d < 0 {
+			return nil
+		}
+		total += d
+	}
+	if total == 0 {
+		return nil
+	}
+	if len(dashArray)%2 == 1 {
+		pattern := make([]float32, 0, len(dashArray)*2)
+		pattern = append(pattern, dashArray...)
+		pattern = append(pattern, dashArray...)
+		return pattern
+	}
+	return dashArray
+}
+
+// dashSubpaths splits the subpaths into dashes.
+// The returned subpaths are open, unless a subpath is entirely covered by one dash.
+func dashSubpaths(subpaths []subpath, dashArray []float32, dashOffset float32) []subpath {
+	pattern := dashPattern(dashArray)
+	if pattern == nil {
+		return subpaths
+	}
+
+	var total float32
+	for _, d := range pattern {
+		total += d
+	}
+
+	var dashes []subpath
+	for _, s := range subpaths {
+		if s.pointCount() < 2 {
+			continue
+		}
+
+		// Find the start position in the pattern.
+		phase := float32(math.Mod(float64(dashOffset), float64(total)))
+		if phase < 0 {
+			phase += total
+		}
+		idx := 0
+		// A zero-length dash at the start position is not skipped.
+		for phase > pattern[idx] || (phase == pattern[idx] && phase > 0) {
+			phase -= pattern[idx]
+			idx = (idx + 1) % len(pattern)
+		}
+		rem := pattern[idx] - phase
+		on := idx%2 == 0
+
+		var length float32
+		for i := 0; i < s.pointCount()-1; i++ {
+			p0, p1 := s.points[i], s.points[i+1]
+			length += float32(math.Hypot(float64(p1.x-p0.x), float64(p1.y-p0.y)))
+		}
+
+		startIdx := len(dashes)
+		startsOn := on
+		// toggled reports whether the dash state has changed at least once in this subpath.
+		var toggled bool
+
+		var cur []point
+		// curStart is the length from the start of the subpath to the start of the current dash.
+		var curStart float32
+		if on {
+			cur = append(cur, s.points[0])
+		}
+		emit := func(dir point) {
+			if len(cur) == 0 {
+				return
+			}
+			// A zero-length dash is still rendered with caps, e.g. dots with LineCapRound.
+			// Make a tiny segment along the path to determine the direction of the caps.
+			if len(cur) == 1 || (len(cur) == 2 && cur[0] == cur[1]) {
+				p := cur[0]
+				cur = []point{p, {x: p.x + dir.x*1e-3, y: p.y + dir.y*1e-3}}
+			}
+			dashes = append(dashes, subpath{
+				points:    cur,
+				dashStart: curStart,
+				dashTotal: length,
+			})
+			cur = nil
+		}
+
+		// acc is the length from the start of the subpath to the start of the current segment.
+		var acc float32
+
+		for i := 0; i < s.pointCount()-1; i++ {
+			p0, p1 := s.points[i], s.points[i+1]
+			l := float32(math.Hypot(float64(p1.x-p0.x), float64(p1.y-p0.y)))
+			if l == 0 {
+				continue
+			}
+			dir := point{x: (p1.x - p0.x) / l, y: (p1.y - p0.y) / l}
+
+			var t float32
+			for l-t > rem {
+				t += rem
+				pt := point{x: p0.x + dir.x*t, y: p0.y + dir.y*t}
+				if on {
+					cur = append(cur, pt)
+					emit(dir)
+				} else {
+					cur = append(cur[:0], pt)
+					curStart = acc + t
+				}
+				on = !on
+				toggled = true
+				idx = (idx + 1) % len(pattern)
+				rem = pattern[idx]
+			}
+			rem -= l - t
+			if on {
+				cur = append(cur, p1)
+			}
+			acc += l
+		}
+
+		if on && len(cur) > 0 {
+			if !toggled {
+				// The whole subpath is one dash, and keep it as it is.
+				dashes = append(dashes, s)
+				cur = nil
+				continue
+			}
+			// For a closed subpath, the last dash and the first dash are connected at the start point
+			// so that the joint is rendered there.
+			if s.closed && startsOn && len(dashes) > startIdx {
+				first := dashes[startIdx]
+				merged := make([]point, 0, len(cur)+len(first.points)-1)
+				merged = append(merged, cur...)
+				merged = append(merged, first.points[1:]...)
+				dashes[startIdx] = subpath{
+					points:    merged,
+					dashStart: curStart,
+					dashTotal: length,
+				}
+				cur = nil
+				continue
+			}
+			lp := s.points[s.pointCount()-1]
+			pp := s.points[s.pointCount()-2]
+			l := float32(math.Hypot(float64(lp.x-pp.x), float64(lp.y-pp.y)))
+			dir := point{}
+			if l > 0 {
+				dir = point{x: (lp.x - pp.x) / l, y: (lp.y - pp.y) / l}
+			}
+			emit(dir)
+		}
+	}
+	return dashes
+}
@@ -14,9 +14,9 @@
 
 package vector
 
-type Point struct {
-	X, Y float32
-}
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
 
 func IsPointCloseToSegment(p, p0, p1 Point, allow float32) bool {
 	return isPointCloseToSegment(point{
@@ -30,3 +30,7 @@ func IsPointCloseToSegment(p, p0, p1 Point, allow float32) bool {
 		y: p1.Y,
 	}, allow)
 }
+
+func (p *Path) AppendVerticesAndIndicesForCurveFilling(vertices []ebiten.Vertex, indices []uint16) ([]ebiten.Vertex, []uint16) {
+	return p.appendVerticesAndIndicesForCurveFilling(vertices, indices)
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+// Contains reports whether the point (x, y) is inside the filled area of the path with the given fill rule.
+//
+// Curves are flattened, and open subpaths are regarded as closed, in the same way as FillPath.
+func (p *Path) Contains(x, y float32, fillRule FillRule) bool {
+	segs := appendSegmentsForFilling(nil, p)
+	return isInside(bpoint{x: float64(x), y: float64(y)}, segs, fillRule)
+}
+
+// StrokeContains reports whether the point (x, y) is inside the stroke of the path with the given stroke options.
+//
+// StrokeContains tests the same triangles as StrokePath renders, including line caps, line joins, and dashes.
+func (p *Path) StrokeContains(x, y float32, strokeOptions *StrokeOptions) bool {
+	vs, is := p.AppendVerticesAndIndicesForStroke(nil, nil, strokeOptions)
+	pt := point{x: x, y: y}
+	for i := 0; i < len(is); i += 3 {
+		v0, v1, v2 := vs[is[i]], vs[is[i+1]], vs[is[i+2]]
+		p0 := point{x: v0.DstX, y: v0.DstY}
+		p1 := point{x: v1.DstX, y: v1.DstY}
+		p2 := point{x: v2.DstX, y: v2.DstY}
+		if isPointInTriangle(pt, p0, p1, p2) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPointInTriangle reports whether pt is inside the triangle or on its edges, regardless of the triangle's direction.
+func isPointInTriangle(pt, p0, p1, p2 point) bool {
+	c0 := cross(point{x: p1.x - p0.x, y: p1.y - p0.y}, point{x: pt.x - p0.x, y: pt.y - p0.y})
+	c1 := cross(point{x: p2.x - p1.x, y: p2.y - p1.y}, point{x: pt.x - p1.x, y: pt.y - p1.y})
+	c2 := cross(point{x: p0.x - p2.x, y: p0.y - p2.y}, point{x: pt.x - p2.x, y: pt.y - p2.y})
+	return (c0 >= 0 && c1 >= 0 && c2 >= 0) || (c0 <= 0 && c1 <= 0 && c2 <= 0)
+}
@@ -0,0 +1,386 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package svgpath provides a parser for SVG path data.
+package svgpath
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Builder is an interface to receive path segments.
+//
+// *vector.Path implements Builder.
+type Builder interface {
+	MoveTo(x, y float32)
+	LineTo(x, y float32)
+	QuadTo(x1, y1, x2, y2 float32)
+	CubicTo(x1, y1, x2, y2, x3, y3 float32)
+	Close()
+}
+
+type parser struct {
+	d   string
+	pos int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.d) && isSpace(p.d[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) skipSeparators() {
+	p.skipSpaces()
+	if p.pos < len(p.d) && p.d[p.pos] == ',' {
+		p.pos++
+		p.skipSpaces()
+	}
+}
+
+// hasNumber reports whether a number follows.
+func (p *parser) hasNumber() bool {
+	p.skipSeparators()
+	if p.pos >= len(p.d) {
+		return false
+	}
+	c := p.d[p.pos]
+	return c == '+' || c == '-' || c == '.' || ('0' <= c && c <= '9')
+}
+
+func (p *parser) number() (float64, error) {
+	p.skipSeparators()
+	start := p.pos
+	if p.pos < len(p.d) && (p.d[p.pos] == '+' || p.d[p.pos] == '-') {
+		p.pos++
+	}
+	var digits bool
+	for p.pos < len(p.d) && '0' <= p.d[p.pos] && p.d[p.pos] <= '9' {
+		p.pos++
+		digits = true
+	}
+	// A number like "1.5.5" is two numbers "1.5" and ".5".
+	if p.pos < len(p.d) && p.d[p.pos] == '.' {
+		p.pos++
+		for p.pos < len(p.d) && '0' <= p.d[p.pos] && p.d[p.pos] <= '9' {
+			p.pos++
+			digits = true
+		}
+	}
+	if !digits {
+		return 0, fmt.Errorf("vector: a number is expected at %d in the path data", start)
+	}
+	if p.pos < len(p.d) && (p.d[p.pos] == 'e' || p.d[p.pos] == 'E') {
+		// An exponent must have digits. Otherwise, 'e' is not a part of the number.
+		i := p.pos + 1
+		if i < len(p.d) && (p.d[i] == '+' || p.d[i] == '-') {
+			i++
+		}
+		if i < len(p.d) && '0' <= p.d[i] && p.d[i] <= '9' {
+			for i < len(p.d) && '0' <= p.d[i] && p.d[i] <= '9' {
+				i++
+			}
+			p.pos = i
+		}
+	}
+	v, err := strconv.ParseFloat(p.d[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("vector: invalid number at %d in the path data: %w", start, err)
+	}
+	return v, nil
+}
+
+// flag parses an arc flag. A flag can be written without separators, e.g. "a1 1 0 00 1 1".
+func (p *parser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.pos >= len(p.d) {
+		return false, fmt.Errorf("vector: a flag is expected at %d in the path data", p.pos)
+	}
+	switch p.d[p.pos] {
+	case '0':
+		p.pos++
+		return false, nil
+	case '1':
+		p.pos++
+		return true, nil
+	}
+	return false, fmt.Errorf("vector: a flag is expected at %d in the path data", p.pos)
+}
+
+func (p *parser) numbers(vs []float64) error {
+	for i := range vs {
+		v, err := p.number()
+		if err != nil {
+			return err
+		}
+		vs[i] = v
+	}
+	return nil
+}
+
+// Parse parses the SVG path data d and sends the segments to b.
+//
+// Elliptical arcs are converted to cubic Bézier curves.
+//
+// Even if an error occurs, the segments before the error are sent to b, as SVG renders a path up to an error.
+func Parse(d string, b Builder) error {
+	p := &parser{d: d}
+
+	var cmd byte
+	// (cx, cy) is the current point, and (sx, sy) is the start point of the current subpath.
+	var cx, cy, sx, sy float64
+	// (rx, ry) is the reflected control point for S and T commands.
+	var rx, ry float64
+	var prev byte
+	var vs [7]float64
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.d) {
+			return nil
+		}
+
+		c := p.d[p.pos]
+		switch {
+		case ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			cmd = c
+			p.pos++
+		case cmd == 0:
+			return fmt.Errorf("vector: a command is expected at %d in the path data", p.pos)
+		case cmd == 'Z' || cmd == 'z':
+			return fmt.Errorf("vector: a command is expected at %d in the path data", p.pos)
+		}
+
+		rel := 'a' <= cmd && cmd <= 'z'
+		var ox, oy float64
+		if rel {
+			ox, oy = cx, cy
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			if err := p.numbers(vs[:2]); err != nil {
+				return err
+			}
+			cx, cy = ox+vs[0], oy+vs[1]
+			sx, sy = cx, cy
+			b.MoveTo(float32(cx), float32(cy))
+			// Subsequent pairs of coordinates are implicit LineTo commands.
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+			prev = 'M'
+			continue
+		case 'L', 'l':
+			if err := p.numbers(vs[:2]); err != nil {
+				return err
+			}
+			cx, cy = ox+vs[0], oy+vs[1]
+			b.LineTo(float32(cx), float32(cy))
+		case 'H', 'h':
+			if err := p.numbers(vs[:1]); err != nil {
+				return err
+			}
+			cx = ox + vs[0]
+			b.LineTo(float32(cx), float32(cy))
+		case 'V', 'v':
+			if err := p.numbers(vs[:1]); err != nil {
+				return err
+			}
+			cy = oy + vs[0]
+			b.LineTo(float32(cx), float32(cy))
+		case 'C', 'c':
+			if err := p.numbers(vs[:6]); err != nil {
+				return err
+			}
+			b.CubicTo(float32(ox+vs[0]), float32(oy+vs[1]), float32(ox+vs[2]), float32(oy+vs[3]), float32(ox+vs[4]), float32(oy+vs[5]))
+			rx, ry = ox+vs[2], oy+vs[3]
+			cx, cy = ox+vs[4], oy+vs[5]
+		case 'S', 's':
+			if err := p.numbers(vs[:4]); err != nil {
+				return err
+			}
+			x1, y1 := cx, cy
+			if prev == 'C' || prev == 'S' {
+				x1, y1 = 2*cx-rx, 2*cy-ry
+			}
+			b.CubicTo(float32(x1), float32(y1), float32(ox+vs[0]), float32(oy+vs[1]), float32(ox+vs[2]), float32(oy+vs[3]))
+			rx, ry = ox+vs[0], oy+vs[1]
+			cx, cy = ox+vs[2], oy+vs[3]
+		case 'Q', 'q':
+			if err := p.numbers(vs[:4]); err != nil {
+				return err
+			}
+			b.QuadTo(float32(ox+vs[0]), float32(oy+vs[1]), float32(ox+vs[2]), float32(oy+vs[3]))
+			rx, ry = ox+vs[0], oy+vs[1]
+			cx, cy = ox+vs[2], oy+vs[3]
+		case 'T', 't':
+			if err := p.numbers(vs[:2]); err != nil {
+				return err
+			}
+			x1, y1 := cx, cy
+			if prev == 'Q' || prev == 'T' {
+				x1, y1 = 2*cx-rx, 2*cy-ry
+			}
+			b.QuadTo(float32(x1), float32(y1), float32(ox+vs[0]), float32(oy+vs[1]))
+			rx, ry = x1, y1
+			cx, cy = ox+vs[0], oy+vs[1]
+		case 'A', 'a':
+			if err := p.numbers(vs[:3]); err != nil {
+				return err
+			}
+			largeArc, err := p.flag()
+			if err != nil {
+				return err
+			}
+			sweep, err := p.flag()
+			if err != nil {
+				return err
+			}
+			if err := p.numbers(vs[3:5]); err != nil {
+				return err
+			}
+			x, y := ox+vs[3], oy+vs[4]
+			ArcTo(b, cx, cy, vs[0], vs[1], vs[2]*math.Pi/180, largeArc, sweep, x, y)
+			cx, cy = x, y
+		case 'Z', 'z':
+			b.Close()
+			cx, cy = sx, sy
+		default:
+			return fmt.Errorf("vector: unknown command %q at %d in the path data", cmd, p.pos-1)
+		}
+
+		switch cmd {
+		case 'C', 'c':
+			prev = 'C'
+		case 'S', 's':
+			prev = 'S'
+		case 'Q', 'q':
+			prev = 'Q'
+		case 'T', 't':
+			prev = 'T'
+		default:
+			prev = 0
+		}
+
+		if cmd != 'Z' && cmd != 'z' && !p.hasNumber() {
+			// The next token must be a command.
+			cmd = 0
+		}
+	}
+}
+
+// ArcTo sends cubic Bézier curves approximating an elliptical arc from (x0, y0) to (x, y) to b,
+// in the same way as the SVG's arc command.
+//
+// rx and ry are the radii, and phi is the rotation of the X axis of the ellipse in radians.
+// largeArc and sweep are the large-arc flag and the sweep flag. sweep is true for the clockwise direction on the screen.
+//
+// For the algorithm, see https://www.w3.org/TR/SVG2/implnote.html#ArcImplementationNotes.
+func ArcTo(b Builder, x0, y0, rx, ry, phi float64, largeArc, sweep bool, x, y float64) {
+	if x0 == x && y0 == y {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		b.LineTo(float32(x), float32(y))
+		return
+	}
+
+	sinPhi, cosPhi := math.Sincos(phi)
+
+	// Step 1: Compute (x1', y1').
+	dx2, dy2 := (x0-x)/2, (y0-y)/2
+	x1p := cosPhi*dx2 + sinPhi*dy2
+	y1p := -sinPhi*dx2 + cosPhi*dy2
+
+	// Correct out-of-range radii.
+	if l := x1p*x1p/(rx*rx) + y1p*y1p/(ry*ry); l > 1 {
+		s := math.Sqrt(l)
+		rx *= s
+		ry *= s
+	}
+
+	// Step 2: Compute (cx', cy').
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	var coef float64
+	if num > 0 && den > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * y1p / ry
+	cyp := -coef * ry * x1p / rx
+
+	// Step 3: Compute (cx, cy).
+	cx := cosPhi*cxp - sinPhi*cyp + (x0+x)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y0+y)/2
+
+	// Step 4: Compute the start angle and the sweep angle.
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	ux, uy := (x1p-cxp)/rx, (y1p-cyp)/ry
+	vx, vy := (-x1p-cxp)/rx, (-y1p-cyp)/ry
+	theta1 := angle(1, 0, ux, uy)
+	dtheta := angle(ux, uy, vx, vy)
+	if !sweep && dtheta > 0 {
+		dtheta -= 2 * math.Pi
+	} else if sweep && dtheta < 0 {
+		dtheta += 2 * math.Pi
+	}
+
+	// Split the arc into segments of at most 90 degrees, and approximate each segment with a cubic Bézier curve.
+	n := int(math.Ceil(math.Abs(dtheta) / (math.Pi / 2)))
+	if n < 1 {
+		n = 1
+	}
+	delta := dtheta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(delta/4)
+
+	point := func(theta float64) (float64, float64) {
+		s, c := math.Sincos(theta)
+		return cx + rx*c*cosPhi - ry*s*sinPhi, cy + rx*c*sinPhi + ry*s*cosPhi
+	}
+	deriv := func(theta float64) (float64, float64) {
+		s, c := math.Sincos(theta)
+		return -rx*s*cosPhi - ry*c*sinPhi, -rx*s*sinPhi + ry*c*cosPhi
+	}
+
+	t := theta1
+	px, py := x0, y0
+	for i := 0; i < n; i++ {
+		t1 := t + delta
+		dx0, dy0 := deriv(t)
+		qx, qy := point(t1)
+		if i == n-1 {
+			// Use the exact end point to avoid accumulating errors.
+			qx, qy = x, y
+		}
+		dx1, dy1 := deriv(t1)
+		b.CubicTo(float32(px+k*dx0), float32(py+k*dy0), float32(qx-k*dx1), float32(qy-k*dy1), float32(qx), float32(qy))
+		px, py = qx, qy
+		t = t1
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svgpath_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/vector/internal/svgpath"
+)
+
+type recorder struct {
+	strings.Builder
+}
+
+func (r *recorder) MoveTo(x, y float32) {
+	fmt.Fprintf(r, "M%g,%g ", x, y)
+}
+
+func (r *recorder) LineTo(x, y float32) {
+	fmt.Fprintf(r, "L%g,%g ", x, y)
+}
+
+func (r *recorder) QuadTo(x1, y1, x2, y2 float32) {
+	fmt.Fprintf(r, "Q%g,%g,%g,%g ", x1, y1, x2, y2)
+}
+
+func (r *recorder) CubicTo(x1, y1, x2, y2, x3, y3 float32) {
+	fmt.Fprintf(r, "C%g,%g,%g,%g,%g,%g ", x1, y1, x2, y2, x3, y3)
+}
+
+func (r *recorder) Close() {
+	fmt.Fprint(r, "Z ")
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		d    string
+		want string
+		err  bool
+	}{
+		{
+			d:    "M10 20 L30 40",
+			want: "M10,20 L30,40",
+		},
+		{
+			d:    "m10,20 30,40 h10 v-10 z l1 1",
+			want: "M10,20 L40,60 L50,60 L50,50 Z L11,21",
+		},
+		{
+			d:    "M0 0H10V10H0Z",
+			want: "M0,0 L10,0 L10,10 L0,10 Z",
+		},
+		{
+			// Numbers without separators.
+			d:    "M1.5.5-1-2e1 3",
+			want: "M1.5,0.5 L-1,-20",
+			err:  true,
+		},
+		{
+			d:    "M0 0C1 2 3 4 5 6S9 10 11 12",
+			want: "M0,0 C1,2,3,4,5,6 C7,8,9,10,11,12",
+		},
+		{
+			d:    "M0 0Q1 2 3 4T7 8",
+			want: "M0,0 Q1,2,3,4 Q5,6,7,8",
+		},
+		{
+			d:    "M0 0S1 2 3 4",
+			want: "M0,0 C0,0,1,2,3,4",
+		},
+		{
+			d:    "M0 0 X1 2",
+			want: "M0,0",
+			err:  true,
+		},
+		{
+			d:   "10 20",
+			err: true,
+		},
+		{
+			d:    "M0 0 L1",
+			want: "M0,0",
+			err:  true,
+		},
+	}
+	for _, tc := range testCases {
+		var r recorder
+		err := svgpath.Parse(tc.d, &r)
+		if got, want := strings.TrimSpace(r.String()), tc.want; got != want {
+			t.Errorf("Parse(%q): got: %q, want: %q", tc.d, got, want)
+		}
+		if tc.err && err == nil {
+			t.Errorf("Parse(%q): an error is expected", tc.d)
+		}
+		if !tc.err && err != nil {
+			t.Errorf("Parse(%q): got error: %v", tc.d, err)
+		}
+	}
+}
+
+type endPointRecorder struct {
+	x, y   float32
+	curves int
+	maxR   float64
+	minR   float64
+}
+
+func (r *endPointRecorder) MoveTo(x, y float32) {}
+
+func (r *endPointRecorder) LineTo(x, y float32) {
+	r.x, r.y = x, y
+}
+
+func (r *endPointRecorder) QuadTo(x1, y1, x2, y2 float32) {
+	r.x, r.y = x2, y2
+}
+
+func (r *endPointRecorder) CubicTo(x1, y1, x2, y2, x3, y3 float32) {
+	r.x, r.y = x3, y3
+	r.curves++
+	d := math.Hypot(float64(x3), float64(y3-10))
+	if r.curves == 1 || d > r.maxR {
+		r.maxR = d
+	}
+	if r.curves == 1 || d < r.minR {
+		r.minR = d
+	}
+}
+
+func (r *endPointRecorder) Close() {}
+
+func TestParseArc(t *testing.T) {
+	// A half circle from (0, 0) to (0, 20) with the radius 10, centered at (0, 10).
+	var r endPointRecorder
+	if err := svgpath.Parse("M0 0A10 10 0 0 1 0 20", &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.x != 0 || r.y != 20 {
+		t.Errorf("got: (%v, %v), want: (0, 20)", r.x, r.y)
+	}
+	if r.curves != 2 {
+		t.Errorf("got: %d, want: 2", r.curves)
+	}
+	if math.Abs(r.maxR-10) > 1e-4 || math.Abs(r.minR-10) > 1e-4 {
+		t.Errorf("got: [%v, %v], want: 10", r.minR, r.maxR)
+	}
+
+	// Compact flags, and too small radii are scaled up.
+	r = endPointRecorder{}
+	if err := svgpath.Parse("M0 0a1 1 0 1120 0", &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.x != 20 || r.y != 0 {
+		t.Errorf("got: (%v, %v), want: (20, 0)", r.x, r.y)
+	}
+}
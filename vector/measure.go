@@ -0,0 +1,97 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+)
+
+func segmentLength(p0, p1 point) float32 {
+	return float32(math.Hypot(float64(p1.x-p0.x), float64(p1.y-p0.y)))
+}
+
+// Length returns the total length of the path.
+//
+// Curves are measured after they are flattened, in the same way as rendering.
+// The length of a subpath includes the closing segment if the subpath is closed.
+// Moves between subpaths are not counted.
+func (p *Path) Length() float32 {
+	var l float32
+	for _, s := range p.ensureSubpaths() {
+		for i := 0; i < s.pointCount()-1; i++ {
+			l += segmentLength(s.points[i], s.points[i+1])
+		}
+	}
+	return l
+}
+
+// segmentAtLength returns the segment at the given distance from the start of the path, and the ratio in the segment.
+// The subpaths are regarded as connected in order.
+// distance is clamped to [0, Length()].
+func (p *Path) segmentAtLength(distance float32) (p0, p1 point, t float32, ok bool) {
+	if distance < 0 {
+		distance = 0
+	}
+	var last []point
+	for _, s := range p.ensureSubpaths() {
+		for i := 0; i < s.pointCount()-1; i++ {
+			p0, p1 := s.points[i], s.points[i+1]
+			l := segmentLength(p0, p1)
+			if l == 0 {
+				continue
+			}
+			if distance <= l {
+				return p0, p1, distance / l, true
+			}
+			distance -= l
+			last = s.points[i : i+2]
+		}
+	}
+	if last == nil {
+		return point{}, point{}, 0, false
+	}
+	// distance exceeds the length.
+	return last[0], last[1], 1, true
+}
+
+// PointAtLength returns the position at the given distance along the path from the start.
+//
+// distance is clamped to [0, Length()].
+// If the path has no segments, PointAtLength returns the start point of the path if exists, or (0, 0).
+func (p *Path) PointAtLength(distance float32) (x, y float32) {
+	p0, p1, t, ok := p.segmentAtLength(distance)
+	if !ok {
+		if ss := p.ensureSubpaths(); len(ss) > 0 && ss[0].pointCount() > 0 {
+			pt := ss[0].points[0]
+			return pt.x, pt.y
+		}
+		return 0, 0
+	}
+	return p0.x + (p1.x-p0.x)*t, p0.y + (p1.y-p0.y)*t
+}
+
+// TangentAtLength returns the unit tangent vector at the given distance along the path from the start.
+// The tangent vector points to the direction of the path.
+//
+// distance is clamped to [0, Length()].
+// If the path has no segments, TangentAtLength returns (0, 0).
+func (p *Path) TangentAtLength(distance float32) (dx, dy float32) {
+	p0, p1, _, ok := p.segmentAtLength(distance)
+	if !ok {
+		return 0, 0
+	}
+	l := segmentLength(p0, p1)
+	return (p1.x - p0.x) / l, (p1.y - p0.y) / l
+}
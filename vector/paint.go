@@ -0,0 +1,231 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Paint is a source of colors to fill or stroke a path.
+//
+// Paint is implemented by *LinearGradient, *RadialGradient, *ConicGradient, and *ImagePattern.
+type Paint interface {
+	// shader returns a shader, its uniform variables, and its source image to render the paint.
+	// The shader receives the path coordinates as srcPos and the color scale as color.
+	// If the source image is not nil, srcPos is offset by imageSrc0Origin().
+	// If the shader is nil, nothing is rendered.
+	shader() (*ebiten.Shader, map[string]any, *ebiten.Image)
+}
+
+// SpreadMode represents how a gradient is rendered outside of the range [0, 1].
+type SpreadMode int
+
+const (
+	// SpreadModePad extends the colors at the ends of the gradient.
+	SpreadModePad SpreadMode = iota
+
+	// SpreadModeRepeat repeats the gradient.
+	SpreadModeRepeat
+
+	// SpreadModeReflect repeats the gradient, reflecting it at every boundary.
+	SpreadModeReflect
+)
+
+// ColorStop is a color at a position in a gradient.
+type ColorStop struct {
+	// Offset is the position of the color stop in the range [0, 1].
+	Offset float32
+
+	// Color is the color at the position.
+	Color color.Color
+}
+
+// MaxColorStopCount is the maximum number of color stops in a gradient.
+const MaxColorStopCount = 16
+
+// LinearGradient is a Paint with a linear gradient from (X0, Y0) to (X1, Y1).
+type LinearGradient struct {
+	X0 float32
+	Y0 float32
+	X1 float32
+	Y1 float32
+
+	// ColorStops is the color stops of the gradient.
+	// The offsets must be in an ascending order.
+	ColorStops []ColorStop
+
+	// SpreadMode is the spread mode of the gradient.
+	//
+	// The default (zero) value is SpreadModePad.
+	SpreadMode SpreadMode
+}
+
+func (l *LinearGradient) shader() (*ebiten.Shader, map[string]any, *ebiten.Image) {
+	return gradientShader(), gradientUniforms(gradientTypeLinear, [4]float32{l.X0, l.Y0, l.X1, l.Y1}, l.ColorStops, l.SpreadMode), nil
+}
+
+// RadialGradient is a Paint with a radial gradient centered at (CenterX, CenterY) with the radius Radius.
+type RadialGradient struct {
+	CenterX float32
+	CenterY float32
+	Radius  float32
+
+	// ColorStops is the color stops of the gradient.
+	// The offsets must be in an ascending order.
+	ColorStops []ColorStop
+
+	// SpreadMode is the spread mode of the gradient.
+	//
+	// The default (zero) value is SpreadModePad.
+	SpreadMode SpreadMode
+}
+
+func (r *RadialGradient) shader() (*ebiten.Shader, map[string]any, *ebiten.Image) {
+	return gradientShader(), gradientUniforms(gradientTypeRadial, [4]float32{r.CenterX, r.CenterY, r.Radius, 0}, r.ColorStops, r.SpreadMode), nil
+}
+
+// ConicGradient is a Paint with a conic (sweep) gradient centered at (CenterX, CenterY).
+//
+// The gradient goes around the center clockwise, starting at StartAngle in radians.
+// The angle 0 is the direction of the positive X axis.
+type ConicGradient struct {
+	CenterX    float32
+	CenterY    float32
+	StartAngle float32
+
+	// ColorStops is the color stops of the gradient.
+	// The offsets must be in an ascending order.
+	ColorStops []ColorStop
+
+	// SpreadMode is not used for ConicGradient, as a conic gradient always covers exactly one turn.
+}
+
+func (c *ConicGradient) shader() (*ebiten.Shader, map[string]any, *ebiten.Image) {
+	return gradientShader(), gradientUniforms(gradientTypeConic, [4]float32{c.CenterX, c.CenterY, c.StartAngle, 0}, c.ColorStops, SpreadModePad), nil
+}
+
+type gradientType int
+
+const (
+	gradientTypeLinear gradientType = iota
+	gradientTypeRadial
+	gradientTypeConic
+)
+
+func gradientUniforms(typ gradientType, params [4]float32, stops []ColorStop, spread SpreadMode) map[string]any {
+	if len(stops) > MaxColorStopCount {
+		panic(fmt.Sprintf("vector: the number of color stops must be less than or equal to %d but was %d", MaxColorStopCount, len(stops)))
+	}
+
+	offsets := make([]float32, MaxColorStopCount)
+	colors := make([]float32, 4*MaxColorStopCount)
+	for i, s := range stops {
+		offsets[i] = s.Offset
+		// Use premultiplied-alpha colors to interpolate colors correctly.
+		r, g, b, a := s.Color.RGBA()
+		colors[4*i] = float32(r) / 0xffff
+		colors[4*i+1] = float32(g) / 0xffff
+		colors[4*i+2] = float32(b) / 0xffff
+		colors[4*i+3] = float32(a) / 0xffff
+	}
+
+	return map[string]any{
+		"Type":      int(typ),
+		"Spread":    int(spread),
+		"Params":    params[:],
+		"StopCount": len(stops),
+		"Offsets":   offsets,
+		"Colors":    colors,
+	}
+}
+
+var (
+	theGradientShader     *ebiten.Shader
+	theGradientShaderOnce sync.Once
+)
+
+func gradientShader() *ebiten.Shader {
+	theGradientShaderOnce.Do(func() {
+		s, err := ebiten.NewShader([]byte(gradientShaderSource))
+		if err != nil {
+			panic(fmt.Sprintf("vector: compiling the gradient shader failed: %v", err))
+		}
+		theGradientShader = s
+	})
+	return theGradientShader
+}
+
+const gradientShaderSource = `//kage:unit pixels
+
+package main
+
+var Type int
+var Spread int
+var Params vec4
+var StopCount int
+var Offsets [16]float
+var Colors [16]vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4, custom vec4) vec4 {
+	if custom.z > 0 && custom.x*custom.x-custom.y > 0 {
+		// Outside of a curve with FillAlgorithmGPUCurve.
+		discard()
+	}
+	var t float
+	if Type == 0 {
+		d := Params.zw - Params.xy
+		if dd := dot(d, d); dd > 0 {
+			t = dot(srcPos-Params.xy, d) / dd
+		}
+	} else if Type == 1 {
+		if Params.z > 0 {
+			t = length(srcPos-Params.xy) / Params.z
+		}
+	} else {
+		v := srcPos - Params.xy
+		t = fract((atan2(v.y, v.x) - Params.z) / (2 * 3.14159265358979))
+	}
+
+	if Spread == 1 {
+		t = fract(t)
+	} else if Spread == 2 {
+		t = 1 - abs(mod(t, 2)-1)
+	} else {
+		t = clamp(t, 0, 1)
+	}
+
+	clr := Colors[0]
+	for i := 1; i < 16; i++ {
+		if i >= StopCount {
+			break
+		}
+		o0 := Offsets[i-1]
+		o1 := Offsets[i]
+		if t >= o1 {
+			clr = Colors[i]
+			continue
+		}
+		if t > o0 {
+			clr = mix(Colors[i-1], Colors[i], (t-o0)/(o1-o0))
+		}
+		break
+	}
+	return clr * color
+}
+`
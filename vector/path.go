@@ -21,6 +21,7 @@ import (
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector/internal/svgpath"
 )
 
 // Direction represents clockwise or counterclockwise.
@@ -63,6 +64,13 @@ type point struct {
 type subpath struct {
 	points []point
 	closed bool
+
+	// dashStart is the length from the start of the original subpath to the start of this dash.
+	dashStart float32
+
+	// dashTotal is the length of the original subpath of this dash.
+	// dashTotal is 0 if this subpath is not a dash.
+	dashTotal float32
 }
 
 // reset resets the subpath.
@@ -70,6 +78,8 @@ type subpath struct {
 func (s *subpath) reset() {
 	s.points = s.points[:0]
 	s.closed = false
+	s.dashStart = 0
+	s.dashTotal = 0
 }
 
 func (s subpath) pointCount() int {
@@ -110,8 +120,14 @@ type Path struct {
 	ops []op
 
 	subpaths []subpath
+
+	// flatteningTolerance is the flattening tolerance of subpaths.
+	flatteningTolerance float32
 }
 
+// defaultFlatteningTolerance is the default tolerance in pixels to flatten curves into line segments.
+const defaultFlatteningTolerance = 0.5
+
 // reset resets the path.
 // reset doesn't release the allocated memory so that the memory can be reused.
 func (p *Path) reset() {
@@ -133,9 +149,25 @@ func (p *Path) appendNewSubpath(pt point) {
 }
 
 func (p *Path) ensureSubpaths() []subpath {
-	if len(p.subpaths) > 0 || len(p.ops) == 0 {
+	return p.ensureSubpathsWithTolerance(0)
+}
+
+// ensureSubpathsWithTolerance returns the flattened subpaths with the given tolerance.
+// If tolerance is 0 or less, the default tolerance is used.
+func (p *Path) ensureSubpathsWithTolerance(tolerance float32) []subpath {
+	if tolerance <= 0 {
+		tolerance = defaultFlatteningTolerance
+	}
+	if len(p.ops) == 0 {
 		return p.subpaths
 	}
+	if len(p.subpaths) > 0 {
+		if p.flatteningTolerance == tolerance {
+			return p.subpaths
+		}
+		p.subpaths = p.subpaths[:0]
+	}
+	p.flatteningTolerance = tolerance
 
 	var cur point
 	for _, op := range p.ops {
@@ -147,10 +179,10 @@ func (p *Path) ensureSubpaths() []subpath {
 			p.lineTo(op.p1)
 			cur = op.p1
 		case opTypeQuadTo:
-			p.quadTo(cur, op.p1, op.p2, 0)
+			p.quadTo(cur, op.p1, op.p2, tolerance, 0)
 			cur = op.p2
 		case opTypeCubicTo:
-			p.cubicTo(cur, op.p1, op.p2, op.p3, 0)
+			p.cubicTo(cur, op.p1, op.p2, op.p3, tolerance, 0)
 			cur = op.p3
 		case opTypeClose:
 			p.close()
@@ -256,12 +288,12 @@ func crossingPointForTwoLines(p00, p01, p10, p11 point) point {
 	}
 }
 
-func (p *Path) quadTo(p0, p1, p2 point, level int) {
+func (p *Path) quadTo(p0, p1, p2 point, tolerance float32, level int) {
 	if level > 10 {
 		return
 	}
 
-	if isPointCloseToSegment(p1, p0, p2, 0.5) {
+	if isPointCloseToSegment(p1, p0, p2, tolerance) {
 		p.lineTo(p2)
 		return
 	}
@@ -278,16 +310,16 @@ func (p *Path) quadTo(p0, p1, p2 point, level int) {
 		x: (p01.x + p12.x) / 2,
 		y: (p01.y + p12.y) / 2,
 	}
-	p.quadTo(p0, p01, p012, level+1)
-	p.quadTo(p012, p12, p2, level+1)
+	p.quadTo(p0, p01, p012, tolerance, level+1)
+	p.quadTo(p012, p12, p2, tolerance, level+1)
 }
 
-func (p *Path) cubicTo(p0, p1, p2, p3 point, level int) {
+func (p *Path) cubicTo(p0, p1, p2, p3 point, tolerance float32, level int) {
 	if level > 10 {
 		return
 	}
 
-	if isPointCloseToSegment(p1, p0, p3, 0.5) && isPointCloseToSegment(p2, p0, p3, 0.5) {
+	if isPointCloseToSegment(p1, p0, p3, tolerance) && isPointCloseToSegment(p2, p0, p3, tolerance) {
 		p.lineTo(p3)
 		return
 	}
@@ -316,8 +348,8 @@ func (p *Path) cubicTo(p0, p1, p2, p3 point, level int) {
 		x: (p012.x + p123.x) / 2,
 		y: (p012.y + p123.y) / 2,
 	}
-	p.cubicTo(p0, p01, p012, p0123, level+1)
-	p.cubicTo(p0123, p123, p23, p3, level+1)
+	p.cubicTo(p0, p01, p012, p0123, tolerance, level+1)
+	p.cubicTo(p0123, p123, p23, p3, tolerance, level+1)
 }
 
 func normalize(p point) point {
@@ -480,6 +512,84 @@ func (p *Path) Arc(x, y, radius, startAngle, endAngle float32, dir Direction) {
 	p.CubicTo(cx0, cy0, cx1, cy1, x1, y1)
 }
 
+// Ellipse adds an elliptical arc to the path.
+// (x, y) is the center of the ellipse, and radiusX and radiusY are the radii of the ellipse.
+// rotation is the rotation of the ellipse in radians.
+//
+// startAngle and endAngle are the angles of the parametric form of the ellipse before the rotation,
+// in the same way as CanvasRenderingContext2D's ellipse.
+// For a circle (radiusX == radiusY), Ellipse works in the same way as Arc.
+func (p *Path) Ellipse(x, y, radiusX, radiusY, rotation, startAngle, endAngle float32, dir Direction) {
+	// Adjust the angles in the same way as Arc.
+	var da float64
+	if dir == Clockwise {
+		for startAngle > endAngle {
+			endAngle += 2 * math.Pi
+		}
+		da = float64(endAngle - startAngle)
+	} else {
+		for startAngle < endAngle {
+			startAngle += 2 * math.Pi
+		}
+		da = -float64(startAngle - endAngle)
+	}
+	if da >= 2*math.Pi {
+		da = 2 * math.Pi
+	} else if da <= -2*math.Pi {
+		da = -2 * math.Pi
+	}
+
+	sinR, cosR := math.Sincos(float64(rotation))
+	rx, ry := float64(radiusX), float64(radiusY)
+	pt := func(theta float64) (float64, float64) {
+		s, c := math.Sincos(theta)
+		return float64(x) + rx*c*cosR - ry*s*sinR, float64(y) + rx*c*sinR + ry*s*cosR
+	}
+	deriv := func(theta float64) (float64, float64) {
+		s, c := math.Sincos(theta)
+		return -rx*s*cosR - ry*c*sinR, -rx*s*sinR + ry*c*cosR
+	}
+
+	a := float64(startAngle)
+	x0, y0 := pt(a)
+	p.LineTo(float32(x0), float32(y0))
+
+	// Split the arc into segments of at most 90 degrees, and approximate each segment with a cubic Bézier curve.
+	n := int(math.Ceil(math.Abs(da) / (math.Pi / 2)))
+	if n < 1 {
+		return
+	}
+	delta := da / float64(n)
+	k := math.Tan(delta/4) * 4 / 3
+	for i := 0; i < n; i++ {
+		a1 := a + delta
+		dx0, dy0 := deriv(a)
+		x1, y1 := pt(a1)
+		dx1, dy1 := deriv(a1)
+		p.CubicTo(float32(x0+k*dx0), float32(y0+k*dy0), float32(x1-k*dx1), float32(y1-k*dy1), float32(x1), float32(y1))
+		a, x0, y0 = a1, x1, y1
+	}
+}
+
+// EllipticalArcTo adds an elliptical arc from the current position to (x, y) to the path,
+// in the same way as SVG path data's arc command.
+//
+// radiusX and radiusY are the radii of the ellipse, and rotation is the rotation of the ellipse in radians.
+// If largeArc is true, the arc larger than 180 degrees is chosen. Otherwise, the smaller arc is chosen.
+// If sweep is true, the arc is drawn clockwise. Otherwise, the arc is drawn counterclockwise.
+// If the radii are too small to connect the two points, the radii are scaled up.
+// If either radius is 0, EllipticalArcTo works in the same way as LineTo.
+//
+// If p doesn't have any subpaths or the last subpath is closed, EllipticalArcTo works in the same way as LineTo.
+func (p *Path) EllipticalArcTo(radiusX, radiusY, rotation float32, largeArc, sweep bool, x, y float32) {
+	p0, ok := p.currentPosition()
+	if !ok {
+		p.LineTo(x, y)
+		return
+	}
+	svgpath.ArcTo(p, float64(p0.x), float64(p0.y), float64(radiusX), float64(radiusY), float64(rotation), largeArc, sweep, float64(x), float64(y))
+}
+
 func (p *Path) close() {
 	if len(p.subpaths) == 0 {
 		return
@@ -499,10 +609,14 @@ func (p *Path) close() {
 // The returned vertices and indices should be rendered with a solid (non-transparent) color with the default Blend (source-over).
 // Otherwise, there is no guarantee about the rendering result.
 func (p *Path) AppendVerticesAndIndicesForFilling(vertices []ebiten.Vertex, indices []uint16) ([]ebiten.Vertex, []uint16) {
+	return p.appendVerticesAndIndicesForFilling(vertices, indices, 0)
+}
+
+func (p *Path) appendVerticesAndIndicesForFilling(vertices []ebiten.Vertex, indices []uint16, flatteningTolerance float32) ([]ebiten.Vertex, []uint16) {
 	// TODO: Add tests.
 
 	base := uint16(len(vertices))
-	for _, subpath := range p.ensureSubpaths() {
+	for _, subpath := range p.ensureSubpathsWithTolerance(flatteningTolerance) {
 		if subpath.pointCount() < 3 {
 			continue
 		}
@@ -568,6 +682,94 @@ type StrokeOptions struct {
 	//
 	// The default (zero) value is 0.
 	MiterLimit float32
+
+	// DashArray is the lengths of dashes and gaps in pixels, alternately.
+	// If DashArray has an odd number of values, the values are repeated to make an even number of values.
+	// A zero-length dash is rendered only with its line caps, e.g. a dot with LineCapRound.
+	// Each dash is rendered with line caps even when the subpath is marked as closed.
+	//
+	// If DashArray is empty, has a negative value, or has only zeros, the stroke is solid.
+	//
+	// The default (zero) value is nil.
+	DashArray []float32
+
+	// DashOffset is the distance in pixels into the dash pattern at which the dash pattern starts.
+	// Changing DashOffset every frame makes the dashes move along the path, like 'marching ants'.
+	//
+	// The default (zero) value is 0.
+	DashOffset float32
+
+	// WidthProfile is the multipliers of Width along each subpath, for a stroke whose width varies.
+	// The values are placed at equal intervals from the start to the end of a subpath, and linearly interpolated.
+	// For example, []float32{1, 0} makes a tapered stroke, and []float32{0, 1, 0} makes a stroke thick in the middle.
+	// A negative value is treated as 0.
+	//
+	// With DashArray, the profile is applied to the original subpath, not to each dash.
+	//
+	// If WidthProfile is empty, the width is constant.
+	//
+	// The default (zero) value is nil.
+	WidthProfile []float32
+
+	// FlatteningTolerance is the maximum distance in pixels between a curve and the line segments approximating the curve.
+	// A smaller value makes smoother curves with more triangles, and a bigger value makes fewer triangles.
+	//
+	// The default (zero) value is 0, which means 0.5.
+	FlatteningTolerance float32
+}
+
+// appendWidths appends the stroke widths at the points of the subpath, and returns them.
+// appendWidths returns nil if the width is constant.
+func (s *StrokeOptions) appendWidths(widths []float32, subpath subpath) []float32 {
+	if len(s.WidthProfile) == 0 {
+		return nil
+	}
+
+	dists := widths
+	var d float32
+	for i, pt := range subpath.points {
+		if i > 0 {
+			prev := subpath.points[i-1]
+			d += float32(math.Hypot(float64(pt.x-prev.x), float64(pt.y-prev.y)))
+		}
+		dists = append(dists, d)
+	}
+
+	start, total := subpath.dashStart, subpath.dashTotal
+	if total == 0 {
+		start, total = 0, d
+	}
+	for i := range dists {
+		var t float32
+		if total > 0 {
+			t = (start + dists[i]) / total
+		}
+		dists[i] = s.Width * s.widthMultiplier(t)
+	}
+	return dists
+}
+
+// widthMultiplier returns the multiplier of the stroke width at t (0 <= t <= 1) of a subpath.
+func (s *StrokeOptions) widthMultiplier(t float32) float32 {
+	profile := s.WidthProfile
+	var m float32
+	if len(profile) == 1 || t <= 0 {
+		m = profile[0]
+	} else if t >= 1 {
+		m = profile[len(profile)-1]
+	} else {
+		f := t * float32(len(profile)-1)
+		i := int(f)
+		if i >= len(profile)-1 {
+			i = len(profile) - 2
+		}
+		f -= float32(i)
+		m = profile[i]*(1-f) + profile[i+1]*f
+	}
+	if m < 0 {
+		return 0
+	}
+	return m
 }
 
 // AppendVerticesAndIndicesForStroke appends vertices and indices to render a stroke of this path and returns them.
@@ -584,12 +786,22 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 	}
 
 	var rects [][4]point
+	var widths []float32
 	var tmpPath Path
-	for _, subpath := range p.ensureSubpaths() {
+	for _, subpath := range dashSubpaths(p.ensureSubpathsWithTolerance(op.FlatteningTolerance), op.DashArray, op.DashOffset) {
 		if subpath.pointCount() < 2 {
 			continue
 		}
 
+		widths = op.appendWidths(widths[:0], subpath)
+		// widthAt returns the stroke width at the i-th point.
+		widthAt := func(i int) float32 {
+			if widths == nil {
+				return op.Width
+			}
+			return widths[i]
+		}
+
 		rects = rects[:0]
 		for i := 0; i < subpath.pointCount()-1; i++ {
 			pt := subpath.points[i]
@@ -598,8 +810,10 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 			dx := nextPt.x - pt.x
 			dy := nextPt.y - pt.y
 			dist := float32(math.Sqrt(float64(dx*dx + dy*dy)))
-			extX := (dy) * op.Width / 2 / dist
-			extY := (-dx) * op.Width / 2 / dist
+			extX := (dy) * widthAt(i) / 2 / dist
+			extY := (-dx) * widthAt(i) / 2 / dist
+			nextExtX := (dy) * widthAt(i+1) / 2 / dist
+			nextExtY := (-dx) * widthAt(i+1) / 2 / dist
 
 			rects = append(rects, [4]point{
 				{
@@ -607,16 +821,16 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 					y: pt.y + extY,
 				},
 				{
-					x: nextPt.x + extX,
-					y: nextPt.y + extY,
+					x: nextPt.x + nextExtX,
+					y: nextPt.y + nextExtY,
 				},
 				{
 					x: pt.x - extX,
 					y: pt.y - extY,
 				},
 				{
-					x: nextPt.x - extX,
-					y: nextPt.y - extY,
+					x: nextPt.x - nextExtX,
+					y: nextPt.y - nextExtY,
 				},
 			})
 		}
@@ -708,9 +922,9 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 				tmpPath.reset()
 				tmpPath.MoveTo(c.x, c.y)
 				if da < math.Pi {
-					tmpPath.Arc(c.x, c.y, op.Width/2, a0, a1, Clockwise)
+					tmpPath.Arc(c.x, c.y, widthAt(i+1)/2, a0, a1, Clockwise)
 				} else {
-					tmpPath.Arc(c.x, c.y, op.Width/2, a0+math.Pi, a1+math.Pi, CounterClockwise)
+					tmpPath.Arc(c.x, c.y, widthAt(i+1)/2, a0+math.Pi, a1+math.Pi, CounterClockwise)
 				}
 				vertices, indices = tmpPath.AppendVerticesAndIndicesForFilling(vertices, indices)
 			}
@@ -740,7 +954,7 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 				// Arc
 				tmpPath.reset()
 				tmpPath.MoveTo(startR[0].x, startR[0].y)
-				tmpPath.Arc(c.x, c.y, op.Width/2, a, a+math.Pi, CounterClockwise)
+				tmpPath.Arc(c.x, c.y, widthAt(0)/2, a, a+math.Pi, CounterClockwise)
 				vertices, indices = tmpPath.AppendVerticesAndIndicesForFilling(vertices, indices)
 			}
 			{
@@ -752,7 +966,7 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 				// Arc
 				tmpPath.reset()
 				tmpPath.MoveTo(endR[1].x, endR[1].y)
-				tmpPath.Arc(c.x, c.y, op.Width/2, a, a+math.Pi, Clockwise)
+				tmpPath.Arc(c.x, c.y, widthAt(subpath.pointCount()-1)/2, a, a+math.Pi, Clockwise)
 				vertices, indices = tmpPath.AppendVerticesAndIndicesForFilling(vertices, indices)
 			}
 
@@ -761,7 +975,7 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 			{
 				a := math.Atan2(float64(startR[0].y-startR[1].y), float64(startR[0].x-startR[1].x))
 				s, c := math.Sincos(a)
-				dx, dy := float32(c)*op.Width/2, float32(s)*op.Width/2
+				dx, dy := float32(c)*widthAt(0)/2, float32(s)*widthAt(0)/2
 
 				// Quadrilateral
 				tmpPath.reset()
@@ -774,7 +988,8 @@ func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indic
 			{
 				a := math.Atan2(float64(endR[1].y-endR[0].y), float64(endR[1].x-endR[0].x))
 				s, c := math.Sincos(a)
-				dx, dy := float32(c)*op.Width/2, float32(s)*op.Width/2
+				w := widthAt(subpath.pointCount() - 1)
+				dx, dy := float32(c)*w/2, float32(s)*w/2
 
 				// Quadrilateral
 				tmpPath.reset()
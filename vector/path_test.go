@@ -15,8 +15,10 @@
 package vector_test
 
 import (
+	"math"
 	"testing"
 
+	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -86,3 +88,856 @@ func TestIsPointCloseToSegment(t *testing.T) {
 		}
 	}
 }
+
+func TestDashedStroke(t *testing.T) {
+	testCases := []struct {
+		dashArray  []float32
+		dashOffset float32
+		want       [][2]float32
+	}{
+		{
+			dashArray: nil,
+			want:      [][2]float32{{0, 100}},
+		},
+		{
+			dashArray: []float32{10, 10},
+			want:      [][2]float32{{0, 10}, {20, 30}, {40, 50}, {60, 70}, {80, 90}},
+		},
+		{
+			dashArray:  []float32{10, 10},
+			dashOffset: 5,
+			want:       [][2]float32{{0, 5}, {15, 25}, {35, 45}, {55, 65}, {75, 85}, {95, 100}},
+		},
+		{
+			dashArray:  []float32{10, 10},
+			dashOffset: -5,
+			want:       [][2]float32{{5, 15}, {25, 35}, {45, 55}, {65, 75}, {85, 95}},
+		},
+		{
+			// An odd number of values are repeated.
+			dashArray: []float32{30},
+			want:      [][2]float32{{0, 30}, {60, 90}},
+		},
+		{
+			// A negative value makes the stroke solid.
+			dashArray: []float32{10, -10},
+			want:      [][2]float32{{0, 100}},
+		},
+	}
+
+	for _, tc := range testCases {
+		var path vector.Path
+		path.MoveTo(0, 0)
+		path.LineTo(100, 0)
+		vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+			Width:      2,
+			DashArray:  tc.dashArray,
+			DashOffset: tc.dashOffset,
+		})
+		// Each dash is rendered as a rectangle with 4 vertices and 6 indices.
+		if got, want := len(vs), 4*len(tc.want); got != want {
+			t.Fatalf("dashArray: %v, dashOffset: %v: len(vertices): got: %d, want: %d", tc.dashArray, tc.dashOffset, got, want)
+		}
+		if got, want := len(is), 6*len(tc.want); got != want {
+			t.Fatalf("dashArray: %v, dashOffset: %v: len(indices): got: %d, want: %d", tc.dashArray, tc.dashOffset, got, want)
+		}
+		for i, w := range tc.want {
+			if got0, got1 := vs[4*i].DstX, vs[4*i+1].DstX; !nearlyEqual(got0, w[0]) || !nearlyEqual(got1, w[1]) {
+				t.Errorf("dashArray: %v, dashOffset: %v: dash #%d: got: (%v, %v), want: (%v, %v)", tc.dashArray, tc.dashOffset, i, got0, got1, w[0], w[1])
+			}
+		}
+	}
+}
+
+func TestDashedStrokeDot(t *testing.T) {
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(100, 0)
+
+	// Zero-length dashes with butt caps are not visible.
+	vs, _ := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+		Width:     2,
+		DashArray: []float32{0, 10},
+	})
+	var maxWidth float32
+	for i := 0; i < len(vs); i += 4 {
+		if w := vs[i+1].DstX - vs[i].DstX; w > maxWidth {
+			maxWidth = w
+		}
+	}
+	if maxWidth > 0.01 {
+		t.Errorf("got: %v, want: <= 0.01", maxWidth)
+	}
+
+	// Zero-length dashes with square caps are visible.
+	vs, _ = path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+		Width:     2,
+		LineCap:   vector.LineCapSquare,
+		DashArray: []float32{0, 10},
+	})
+	var minX, maxX float32
+	for _, v := range vs {
+		if v.DstX > 5 {
+			continue
+		}
+		if v.DstX < minX {
+			minX = v.DstX
+		}
+		if v.DstX > maxX {
+			maxX = v.DstX
+		}
+	}
+	if !nearlyEqual(minX, -1) || maxX < 0.99 {
+		t.Errorf("got: (%v, %v), want: (-1, 1)", minX, maxX)
+	}
+}
+
+func nearlyEqual(a, b float32) bool {
+	return a-b < 1e-3 && b-a < 1e-3
+}
+
+func rectPath(x, y, width, height float32) *vector.Path {
+	var p vector.Path
+	p.MoveTo(x, y)
+	p.LineTo(x+width, y)
+	p.LineTo(x+width, y+height)
+	p.LineTo(x, y+height)
+	p.Close()
+	return &p
+}
+
+// signedArea returns the signed area of the filled path, which is positive when the path is clockwise.
+func signedArea(path *vector.Path) float32 {
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	return signedAreaForVertices(vs, is)
+}
+
+func signedAreaForVertices(vs []ebiten.Vertex, is []uint16) float32 {
+	var a float32
+	for i := 0; i < len(is); i += 3 {
+		v0, v1, v2 := vs[is[i]], vs[is[i+1]], vs[is[i+2]]
+		a += ((v1.DstX-v0.DstX)*(v2.DstY-v0.DstY) - (v2.DstX-v0.DstX)*(v1.DstY-v0.DstY)) / 2
+	}
+	return a
+}
+
+func TestBooleanOperations(t *testing.T) {
+	testCases := []struct {
+		name           string
+		a              *vector.Path
+		b              *vector.Path
+		wantUnion      float32
+		wantIntersect  float32
+		wantDifference float32
+		wantXor        float32
+	}{
+		{
+			name:           "overlapping",
+			a:              rectPath(0, 0, 10, 10),
+			b:              rectPath(5, 5, 10, 10),
+			wantUnion:      175,
+			wantIntersect:  25,
+			wantDifference: 75,
+			wantXor:        150,
+		},
+		{
+			name:           "adjacent",
+			a:              rectPath(0, 0, 10, 10),
+			b:              rectPath(10, 0, 10, 10),
+			wantUnion:      200,
+			wantIntersect:  0,
+			wantDifference: 100,
+			wantXor:        200,
+		},
+		{
+			name:           "identical",
+			a:              rectPath(0, 0, 10, 10),
+			b:              rectPath(0, 0, 10, 10),
+			wantUnion:      100,
+			wantIntersect:  100,
+			wantDifference: 0,
+			wantXor:        0,
+		},
+		{
+			name:           "hole",
+			a:              rectPath(0, 0, 10, 10),
+			b:              rectPath(2, 2, 4, 4),
+			wantUnion:      100,
+			wantIntersect:  16,
+			wantDifference: 84,
+			wantXor:        84,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, want := signedArea(vector.Union(tc.a, tc.b, nil)), tc.wantUnion; !nearlyEqual(got, want) {
+				t.Errorf("Union: got: %v, want: %v", got, want)
+			}
+			if got, want := signedArea(vector.Intersect(tc.a, tc.b, nil)), tc.wantIntersect; !nearlyEqual(got, want) {
+				t.Errorf("Intersect: got: %v, want: %v", got, want)
+			}
+			if got, want := signedArea(vector.Difference(tc.a, tc.b, nil)), tc.wantDifference; !nearlyEqual(got, want) {
+				t.Errorf("Difference: got: %v, want: %v", got, want)
+			}
+			if got, want := signedArea(vector.Xor(tc.a, tc.b, nil)), tc.wantXor; !nearlyEqual(got, want) {
+				t.Errorf("Xor: got: %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBooleanOperationsFillRule(t *testing.T) {
+	// Two overlapping squares in one path. The overlapped area is a hole with FillRuleEvenOdd.
+	var a vector.Path
+	a.MoveTo(0, 0)
+	a.LineTo(10, 0)
+	a.LineTo(10, 10)
+	a.LineTo(0, 10)
+	a.Close()
+	a.MoveTo(5, 5)
+	a.LineTo(15, 5)
+	a.LineTo(15, 15)
+	a.LineTo(5, 15)
+	a.Close()
+	b := rectPath(0, 0, 20, 20)
+
+	if got, want := signedArea(vector.Intersect(&a, b, nil)), float32(175); !nearlyEqual(got, want) {
+		t.Errorf("FillRuleNonZero: got: %v, want: %v", got, want)
+	}
+	if got, want := signedArea(vector.Intersect(&a, b, &vector.FillOptions{FillRule: vector.FillRuleEvenOdd})), float32(150); !nearlyEqual(got, want) {
+		t.Errorf("FillRuleEvenOdd: got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseSVGPathData(t *testing.T) {
+	path, err := vector.ParseSVGPathData("M0 0h10v10h-10z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := signedArea(path), float32(100); !nearlyEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	// A path up to an error is returned.
+	path, err = vector.ParseSVGPathData("M0 0h10v10h-10z L")
+	if err == nil {
+		t.Errorf("an error is expected")
+	}
+	if got, want := signedArea(path), float32(100); !nearlyEqual(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func pathBounds(path *vector.Path) (minX, minY, maxX, maxY float32) {
+	vs, _ := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	return pathBoundsForVertices(vs)
+}
+
+func TestEllipse(t *testing.T) {
+	var path vector.Path
+	path.Ellipse(50, 50, 20, 10, math.Pi/2, 0, 2*math.Pi, vector.Clockwise)
+	path.Close()
+
+	if got, want := signedArea(&path), float32(math.Pi*20*10); math.Abs(float64(got-want)) > float64(want)*0.03 {
+		t.Errorf("area: got: %v, want: %v", got, want)
+	}
+	// The ellipse is rotated by 90 degrees.
+	minX, minY, maxX, maxY := pathBounds(&path)
+	if !nearlyEqual(minX, 40) || !nearlyEqual(maxX, 60) || !nearlyEqual(minY, 30) || !nearlyEqual(maxY, 70) {
+		t.Errorf("bounds: got: (%v, %v)-(%v, %v), want: (40, 30)-(60, 70)", minX, minY, maxX, maxY)
+	}
+
+	path = vector.Path{}
+	path.Ellipse(0, 0, 20, 10, 0, 2*math.Pi, 0, vector.CounterClockwise)
+	path.Close()
+	if got, want := signedArea(&path), float32(-math.Pi*20*10); math.Abs(float64(got-want)) > -float64(want)*0.03 {
+		t.Errorf("area: got: %v, want: %v", got, want)
+	}
+}
+
+func TestEllipticalArcTo(t *testing.T) {
+	testCases := []struct {
+		largeArc bool
+		sweep    bool
+		wantMinY float32
+		wantMaxY float32
+	}{
+		{largeArc: false, sweep: true, wantMinY: -10, wantMaxY: 0},
+		{largeArc: false, sweep: false, wantMinY: 0, wantMaxY: 10},
+		// With the radius 20, the large arc goes around the farther side.
+		{largeArc: true, sweep: true, wantMinY: -20 - 10*float32(math.Sqrt(3)), wantMaxY: 0},
+	}
+	for _, tc := range testCases {
+		radius := float32(10)
+		if tc.largeArc {
+			radius = 20
+		}
+		var path vector.Path
+		path.MoveTo(0, 0)
+		path.EllipticalArcTo(radius, radius, 0, tc.largeArc, tc.sweep, 20, 0)
+		minX, minY, maxX, maxY := pathBounds(&path)
+		if !nearlyEqual(minY, tc.wantMinY) || !nearlyEqual(maxY, tc.wantMaxY) {
+			t.Errorf("largeArc: %v, sweep: %v: y range: got: [%v, %v], want: [%v, %v]", tc.largeArc, tc.sweep, minY, maxY, tc.wantMinY, tc.wantMaxY)
+		}
+		if !tc.largeArc && (!nearlyEqual(minX, 0) || !nearlyEqual(maxX, 20)) {
+			t.Errorf("largeArc: %v, sweep: %v: x range: got: [%v, %v], want: [0, 20]", tc.largeArc, tc.sweep, minX, maxX)
+		}
+	}
+
+	// Too small radii are scaled up.
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.EllipticalArcTo(1, 1, 0, false, true, 20, 0)
+	if _, minY, _, _ := pathBounds(&path); !nearlyEqual(minY, -10) {
+		t.Errorf("got: %v, want: -10", minY)
+	}
+}
+
+func TestRoundedRect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  *vector.RoundedRectOptions
+		wantArea float32
+	}{
+		{
+			name:     "nil",
+			options:  nil,
+			wantArea: 100 * 50,
+		},
+		{
+			name: "circular",
+			options: &vector.RoundedRectOptions{
+				TopLeftRadius:     10,
+				TopRightRadius:    0,
+				BottomRightRadius: 20,
+				BottomLeftRadius:  5,
+			},
+			wantArea: 100*50 - (4-math.Pi)/4*(10*10+20*20+5*5),
+		},
+		{
+			// The radii are scaled down by 0.5 as the sum of the radii of the right side is 100.
+			name: "scaled",
+			options: &vector.RoundedRectOptions{
+				TopRightRadius:    50,
+				BottomRightRadius: 50,
+			},
+			wantArea: 100*50 - (4-math.Pi)/4*(25*25+25*25),
+		},
+		{
+			// The area of a superellipse |x|^4 + |y|^4 <= 1 is about 3.708.
+			name: "superellipse",
+			options: &vector.RoundedRectOptions{
+				TopLeftRadius:        20,
+				TopRightRadius:       20,
+				BottomRightRadius:    20,
+				BottomLeftRadius:     20,
+				SuperellipseExponent: 4,
+			},
+			wantArea: 100*50 - (4-3.708)*20*20,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path vector.Path
+			path.RoundedRect(10, 20, 100, 50, tc.options)
+			if got, want := signedArea(&path), tc.wantArea; math.Abs(float64(got-want)) > 0.005*float64(want) {
+				t.Errorf("area: got: %v, want: %v", got, want)
+			}
+			minX, minY, maxX, maxY := pathBounds(&path)
+			if !nearlyEqual(minX, 10) || !nearlyEqual(minY, 20) || !nearlyEqual(maxX, 110) || !nearlyEqual(maxY, 70) {
+				t.Errorf("bounds: got: (%v, %v)-(%v, %v), want: (10, 20)-(110, 70)", minX, minY, maxX, maxY)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.QuadTo(10, 10, 0, 10)
+	path.Close()
+
+	var geoM ebiten.GeoM
+	geoM.Scale(2, 3)
+	geoM.Rotate(math.Pi / 2)
+	geoM.Translate(100, 200)
+
+	var added vector.Path
+	added.AddPath(&path, &vector.AddPathOptions{GeoM: geoM})
+	path.Transform(geoM)
+
+	for _, p := range []*vector.Path{&path, &added} {
+		// The area of the original shape is 100 - 100/6, and the determinant of the matrix is 6.
+		// As the curve is flattened after the transformation, the area is close to the exact value.
+		if got, want := signedArea(p), float32((100-100.0/6)*6); math.Abs(float64(got-want)) > 0.01*float64(want) {
+			t.Errorf("area: got: %v, want: %v", got, want)
+		}
+		minX, minY, maxX, maxY := pathBounds(p)
+		if !nearlyEqual(minX, 70) || !nearlyEqual(minY, 200) || !nearlyEqual(maxX, 100) || !nearlyEqual(maxY, 220) {
+			t.Errorf("bounds: got: (%v, %v)-(%v, %v), want: (70, 200)-(100, 220)", minX, minY, maxX, maxY)
+		}
+	}
+
+	// The stroke width is not affected by the transform.
+	var line vector.Path
+	line.MoveTo(0, 0)
+	line.LineTo(10, 0)
+	var scale ebiten.GeoM
+	scale.Scale(10, 10)
+	line.Transform(scale)
+	vs, _ := line.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{Width: 2})
+	_, minY, _, maxY := pathBoundsForVertices(vs)
+	if !nearlyEqual(minY, -1) || !nearlyEqual(maxY, 1) {
+		t.Errorf("stroke: got: [%v, %v], want: [-1, 1]", minY, maxY)
+	}
+}
+
+func pathBoundsForVertices(vs []ebiten.Vertex) (minX, minY, maxX, maxY float32) {
+	for i, v := range vs {
+		if i == 0 || v.DstX < minX {
+			minX = v.DstX
+		}
+		if i == 0 || v.DstY < minY {
+			minY = v.DstY
+		}
+		if i == 0 || v.DstX > maxX {
+			maxX = v.DstX
+		}
+		if i == 0 || v.DstY > maxY {
+			maxY = v.DstY
+		}
+	}
+	return
+}
+
+func TestPathMeasurement(t *testing.T) {
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.LineTo(10, 10)
+	// A move between subpaths is not counted.
+	path.MoveTo(100, 100)
+	path.LineTo(100, 120)
+
+	if got, want := path.Length(), float32(40); !nearlyEqual(got, want) {
+		t.Errorf("Length(): got: %v, want: %v", got, want)
+	}
+
+	testCases := []struct {
+		distance float32
+		x, y     float32
+		dx, dy   float32
+	}{
+		{distance: -10, x: 0, y: 0, dx: 1, dy: 0},
+		{distance: 0, x: 0, y: 0, dx: 1, dy: 0},
+		{distance: 5, x: 5, y: 0, dx: 1, dy: 0},
+		{distance: 15, x: 10, y: 5, dx: 0, dy: 1},
+		{distance: 30, x: 100, y: 110, dx: 0, dy: 1},
+		{distance: 100, x: 100, y: 120, dx: 0, dy: 1},
+	}
+	for _, tc := range testCases {
+		if x, y := path.PointAtLength(tc.distance); !nearlyEqual(x, tc.x) || !nearlyEqual(y, tc.y) {
+			t.Errorf("PointAtLength(%v): got: (%v, %v), want: (%v, %v)", tc.distance, x, y, tc.x, tc.y)
+		}
+		if dx, dy := path.TangentAtLength(tc.distance); !nearlyEqual(dx, tc.dx) || !nearlyEqual(dy, tc.dy) {
+			t.Errorf("TangentAtLength(%v): got: (%v, %v), want: (%v, %v)", tc.distance, dx, dy, tc.dx, tc.dy)
+		}
+	}
+
+	// A closed subpath includes the closing segment.
+	var circle vector.Path
+	circle.Arc(0, 0, 10, 0, 2*math.Pi, vector.Clockwise)
+	circle.Close()
+	if got, want := circle.Length(), float32(2*math.Pi*10); math.Abs(float64(got-want)) > 0.02*float64(want) {
+		t.Errorf("Length(): got: %v, want: %v", got, want)
+	}
+
+	var empty vector.Path
+	if got := empty.Length(); got != 0 {
+		t.Errorf("Length(): got: %v, want: 0", got)
+	}
+	if x, y := empty.PointAtLength(1); x != 0 || y != 0 {
+		t.Errorf("PointAtLength(1): got: (%v, %v), want: (0, 0)", x, y)
+	}
+}
+
+func TestContains(t *testing.T) {
+	// Two overlapping squares. The overlapped area is a hole with FillRuleEvenOdd.
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(10, 0)
+	path.LineTo(10, 10)
+	path.LineTo(0, 10)
+	path.Close()
+	path.MoveTo(5, 5)
+	path.LineTo(15, 5)
+	path.LineTo(15, 15)
+	// An open subpath is regarded as closed.
+	path.LineTo(5, 15)
+
+	testCases := []struct {
+		x, y        float32
+		wantNonZero bool
+		wantEvenOdd bool
+	}{
+		{x: 2, y: 2, wantNonZero: true, wantEvenOdd: true},
+		{x: 7, y: 7, wantNonZero: true, wantEvenOdd: false},
+		{x: 12, y: 12, wantNonZero: true, wantEvenOdd: true},
+		{x: 12, y: 2, wantNonZero: false, wantEvenOdd: false},
+		{x: -1, y: 5, wantNonZero: false, wantEvenOdd: false},
+	}
+	for _, tc := range testCases {
+		if got := path.Contains(tc.x, tc.y, vector.FillRuleNonZero); got != tc.wantNonZero {
+			t.Errorf("Contains(%v, %v, FillRuleNonZero): got: %v, want: %v", tc.x, tc.y, got, tc.wantNonZero)
+		}
+		if got := path.Contains(tc.x, tc.y, vector.FillRuleEvenOdd); got != tc.wantEvenOdd {
+			t.Errorf("Contains(%v, %v, FillRuleEvenOdd): got: %v, want: %v", tc.x, tc.y, got, tc.wantEvenOdd)
+		}
+	}
+}
+
+func TestStrokeContains(t *testing.T) {
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(20, 0)
+
+	testCases := []struct {
+		x, y    float32
+		lineCap vector.LineCap
+		dashes  []float32
+		want    bool
+	}{
+		{x: 10, y: 1.5, want: true},
+		{x: 10, y: 2.5, want: false},
+		{x: -1, y: 0, lineCap: vector.LineCapButt, want: false},
+		{x: -1, y: 0, lineCap: vector.LineCapSquare, want: true},
+		{x: -1, y: 1, lineCap: vector.LineCapRound, want: true},
+		{x: -1.8, y: 1.8, lineCap: vector.LineCapRound, want: false},
+		{x: 5, y: 0, dashes: []float32{4, 4}, want: false},
+		{x: 9, y: 0, dashes: []float32{4, 4}, want: true},
+	}
+	for _, tc := range testCases {
+		op := &vector.StrokeOptions{
+			Width:     4,
+			LineCap:   tc.lineCap,
+			DashArray: tc.dashes,
+		}
+		if got := path.StrokeContains(tc.x, tc.y, op); got != tc.want {
+			t.Errorf("StrokeContains(%v, %v) with LineCap %d and DashArray %v: got: %v, want: %v", tc.x, tc.y, tc.lineCap, tc.dashes, got, tc.want)
+		}
+	}
+}
+
+func TestCurveFilling(t *testing.T) {
+	// The area of a quadratic curve segment is 2/3 of the area of the triangle of its control points.
+	curveFillingArea := func(path *vector.Path) float32 {
+		vs, is := path.AppendVerticesAndIndicesForCurveFilling(nil, nil)
+		var a float32
+		for i := 0; i < len(is); i += 3 {
+			v0, v1, v2 := vs[is[i]], vs[is[i+1]], vs[is[i+2]]
+			ta := ((v1.DstX-v0.DstX)*(v2.DstY-v0.DstY) - (v2.DstX-v0.DstX)*(v1.DstY-v0.DstY)) / 2
+			if v0.Custom2 > 0 {
+				ta *= 2.0 / 3.0
+			}
+			a += ta
+		}
+		return a
+	}
+
+	var quad vector.Path
+	quad.MoveTo(0, 0)
+	quad.QuadTo(10, 20, 20, 0)
+	quad.Close()
+
+	var circle vector.Path
+	circle.Arc(50, 50, 40, 0, 2*math.Pi, vector.Clockwise)
+	circle.Close()
+
+	var rect vector.Path
+	rect.MoveTo(0, 0)
+	rect.LineTo(10, 0)
+	rect.LineTo(10, 10)
+	rect.LineTo(0, 10)
+	rect.Close()
+
+	testCases := []struct {
+		name string
+		path *vector.Path
+		want float32
+	}{
+		{
+			name: "quad",
+			path: &quad,
+			want: -400.0 / 3,
+		},
+		{
+			name: "circle",
+			path: &circle,
+			want: math.Pi * 40 * 40,
+		},
+		{
+			name: "rect",
+			path: &rect,
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := curveFillingArea(tc.path)
+			if math.Abs(float64(got-tc.want)) > math.Abs(float64(tc.want))*0.001 {
+				t.Errorf("got: %f, want: %f", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVariableWidthStroke(t *testing.T) {
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(50, 0)
+	path.LineTo(100, 0)
+
+	testCases := []struct {
+		name    string
+		options vector.StrokeOptions
+		want    float32
+	}{
+		{
+			name: "constant",
+			options: vector.StrokeOptions{
+				Width:        10,
+				WidthProfile: []float32{1},
+			},
+			want: 1000,
+		},
+		{
+			name: "tapered",
+			options: vector.StrokeOptions{
+				Width:        10,
+				WidthProfile: []float32{1, 0},
+			},
+			want: 500,
+		},
+		{
+			name: "thick in the middle",
+			options: vector.StrokeOptions{
+				Width:        10,
+				WidthProfile: []float32{0, 1, 0},
+			},
+			want: 500,
+		},
+		{
+			name: "dashed",
+			options: vector.StrokeOptions{
+				Width:        10,
+				WidthProfile: []float32{1, 0},
+				DashArray:    []float32{50, 50},
+			},
+			// The profile is applied to the whole subpath, then the first dash narrows from 10 to 5.
+			want: 375,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, &tc.options)
+			if got := signedAreaForVertices(vs, is); math.Abs(float64(got-tc.want)) > 0.5 {
+				t.Errorf("got: %f, want: %f", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSimplifyPoints(t *testing.T) {
+	testCases := []struct {
+		name      string
+		points    []vector.Point
+		tolerance float32
+		want      []vector.Point
+	}{
+		{
+			name:      "empty",
+			points:    nil,
+			tolerance: 1,
+			want:      nil,
+		},
+		{
+			name:      "noisy line",
+			points:    []vector.Point{{0, 0}, {10, 0.5}, {20, -0.5}, {30, 0.2}, {40, 0}},
+			tolerance: 1,
+			want:      []vector.Point{{0, 0}, {40, 0}},
+		},
+		{
+			name:      "corner",
+			points:    []vector.Point{{0, 0}, {10, 0.1}, {20, 0}, {20, 10}, {20.1, 20}},
+			tolerance: 1,
+			want:      []vector.Point{{0, 0}, {20, 0}, {20.1, 20}},
+		},
+		{
+			name:      "zero tolerance",
+			points:    []vector.Point{{0, 0}, {10, 0.5}, {20, 0}},
+			tolerance: 0,
+			want:      []vector.Point{{0, 0}, {10, 0.5}, {20, 0}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := vector.SimplifyPoints(tc.points, tc.tolerance)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got: %v, want: %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("got: %v, want: %v", got, tc.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCatmullRom(t *testing.T) {
+	var line vector.Path
+	line.CatmullRom([]vector.Point{{0, 0}, {10, 0}, {10, 0}, {20, 0}}, &vector.CatmullRomOptions{
+		Parameterization: vector.CatmullRomParameterizationUniform,
+	})
+	if got, want := line.Length(), float32(20); !nearlyEqual(got, want) {
+		t.Errorf("line.Length(): got: %f, want: %f", got, want)
+	}
+
+	const r = 100
+	var points []vector.Point
+	for i := 0; i < 16; i++ {
+		s, c := math.Sincos(2 * math.Pi * float64(i) / 16)
+		points = append(points, vector.Point{X: float32(r * c), Y: float32(r * s)})
+	}
+	for _, param := range []vector.CatmullRomParameterization{
+		vector.CatmullRomParameterizationCentripetal,
+		vector.CatmullRomParameterizationUniform,
+		vector.CatmullRomParameterizationChordal,
+	} {
+		var circle vector.Path
+		circle.CatmullRom(points, &vector.CatmullRomOptions{
+			Parameterization: param,
+			Closed:           true,
+		})
+		if got, want := circle.Length(), float32(2*math.Pi*r); math.Abs(float64(got-want)) > float64(want)*0.02 {
+			t.Errorf("circle.Length() (parameterization: %d): got: %f, want: %f", param, got, want)
+		}
+		if got, want := signedArea(&circle), float32(math.Pi*r*r); math.Abs(float64(got-want)) > float64(want)*0.02 {
+			t.Errorf("signedArea(circle) (parameterization: %d): got: %f, want: %f", param, got, want)
+		}
+	}
+}
+
+func TestFlatteningTolerance(t *testing.T) {
+	var path vector.Path
+	path.Arc(100, 100, 80, 0, 2*math.Pi, vector.Clockwise)
+	path.Close()
+
+	var prevCount int
+	for _, tolerance := range []float32{4, 1, 0, 0.1} {
+		vs, _ := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+			Width:               1,
+			FlatteningTolerance: tolerance,
+		})
+		if prevCount > 0 && len(vs) <= prevCount {
+			t.Errorf("tolerance: %f: the number of vertices must be increased: %d (previous: %d)", tolerance, len(vs), prevCount)
+		}
+		prevCount = len(vs)
+	}
+
+	// The default tolerance is 0.5.
+	vs0, _ := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+		Width: 1,
+	})
+	vs1, _ := path.AppendVerticesAndIndicesForStroke(nil, nil, &vector.StrokeOptions{
+		Width:               1,
+		FlatteningTolerance: 0.5,
+	})
+	if len(vs0) != len(vs1) {
+		t.Errorf("the number of vertices with the default tolerance: got: %d, want: %d", len(vs0), len(vs1))
+	}
+}
+
+func TestTriangulation(t *testing.T) {
+	// A rectangle with a hole in the same direction.
+	withHole := rectPath(0, 0, 30, 30)
+	withHole.AddPath(rectPath(10, 10, 10, 10), nil)
+
+	// A pentagram, whose center is covered twice.
+	var star vector.Path
+	for i := 0; i < 5; i++ {
+		s, c := math.Sincos(float64(i)*4*math.Pi/5 - math.Pi/2)
+		x, y := float32(100+80*c), float32(100+80*s)
+		if i == 0 {
+			star.MoveTo(x, y)
+		} else {
+			star.LineTo(x, y)
+		}
+	}
+	star.Close()
+
+	var circle vector.Path
+	circle.Arc(50, 50, 40, 0, 2*math.Pi, vector.Clockwise)
+	circle.Close()
+
+	testCases := []struct {
+		name     string
+		path     *vector.Path
+		fillRule vector.FillRule
+	}{
+		{
+			name:     "hole nonzero",
+			path:     withHole,
+			fillRule: vector.FillRuleNonZero,
+		},
+		{
+			name:     "hole evenodd",
+			path:     withHole,
+			fillRule: vector.FillRuleEvenOdd,
+		},
+		{
+			name:     "star nonzero",
+			path:     &star,
+			fillRule: vector.FillRuleNonZero,
+		},
+		{
+			name:     "star evenodd",
+			path:     &star,
+			fillRule: vector.FillRuleEvenOdd,
+		},
+		{
+			name:     "circle",
+			path:     &circle,
+			fillRule: vector.FillRuleNonZero,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vs, is := tc.path.AppendVerticesAndIndicesForTriangulation(nil, nil, tc.fillRule)
+			for i := 0; i < len(is); i += 3 {
+				if a := signedAreaForVertices(vs, is[i:i+3]); a < -1e-3 {
+					t.Errorf("triangle %d is counterclockwise: area: %f", i/3, a)
+				}
+			}
+			// The area without overlaps must be the same as the area calculated by the boolean operation.
+			want := signedArea(vector.Union(tc.path, nil, &vector.FillOptions{
+				FillRule: tc.fillRule,
+			}))
+			if got := signedAreaForVertices(vs, is); math.Abs(float64(got-want)) > 0.1 {
+				t.Errorf("area: got: %f, want: %f", got, want)
+			}
+		})
+	}
+
+	// Check the areas explicitly.
+	vs, is := withHole.AppendVerticesAndIndicesForTriangulation(nil, nil, vector.FillRuleNonZero)
+	if got, want := signedAreaForVertices(vs, is), float32(900); !nearlyEqual(got, want) {
+		t.Errorf("area with FillRuleNonZero: got: %f, want: %f", got, want)
+	}
+	vs, is = withHole.AppendVerticesAndIndicesForTriangulation(nil, nil, vector.FillRuleEvenOdd)
+	if got, want := signedAreaForVertices(vs, is), float32(800); !nearlyEqual(got, want) {
+		t.Errorf("area with FillRuleEvenOdd: got: %f, want: %f", got, want)
+	}
+}
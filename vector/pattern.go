@@ -0,0 +1,208 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// RepeatMode represents how an image pattern is rendered outside of the image.
+type RepeatMode int
+
+const (
+	// RepeatModeNone renders nothing outside of the image.
+	RepeatModeNone RepeatMode = iota
+
+	// RepeatModeRepeat repeats the image.
+	RepeatModeRepeat
+
+	// RepeatModeReflect repeats the image, reflecting it at every boundary.
+	RepeatModeReflect
+
+	// RepeatModePad extends the pixels at the edges of the image.
+	RepeatModePad
+)
+
+// ImagePattern is a Paint with an image.
+type ImagePattern struct {
+	// Image is the source image of the pattern.
+	Image *ebiten.Image
+
+	// GeoM is a geometry matrix to transform the image into the path coordinates.
+	// If GeoM is not invertible, nothing is rendered.
+	//
+	// The default (zero) value is identity, which puts the upper-left corner of the image at (0, 0).
+	GeoM ebiten.GeoM
+
+	// RepeatX is the repeat mode in the X direction of the image.
+	//
+	// The default (zero) value is RepeatModeNone.
+	RepeatX RepeatMode
+
+	// RepeatY is the repeat mode in the Y direction of the image.
+	//
+	// The default (zero) value is RepeatModeNone.
+	RepeatY RepeatMode
+
+	// Filter is a type of texture filter.
+	//
+	// The default (zero) value is ebiten.FilterNearest.
+	Filter ebiten.Filter
+}
+
+func (i *ImagePattern) shader() (*ebiten.Shader, map[string]any, *ebiten.Image) {
+	if i.Image == nil {
+		return nil, nil, nil
+	}
+	g := i.GeoM
+	if !g.IsInvertible() {
+		return nil, nil, nil
+	}
+	g.Invert()
+
+	var filter int
+	switch i.Filter {
+	case ebiten.FilterNearest:
+		filter = 0
+	case ebiten.FilterLinear, ebiten.FilterBicubic, ebiten.FilterPixelArt:
+		// The other filters are not supported for patterns. Use the linear filter instead.
+		filter = 1
+	case ebiten.FilterAnisotropic:
+		filter = 2
+	default:
+		panic(fmt.Sprintf("vector: invalid filter: %d", i.Filter))
+	}
+
+	return patternShader(), map[string]any{
+		"Row0":    []float32{float32(g.Element(0, 0)), float32(g.Element(0, 1)), float32(g.Element(0, 2))},
+		"Row1":    []float32{float32(g.Element(1, 0)), float32(g.Element(1, 1)), float32(g.Element(1, 2))},
+		"RepeatX": int(i.RepeatX),
+		"RepeatY": int(i.RepeatY),
+		"Filter":  filter,
+	}, i.Image
+}
+
+var (
+	thePatternShader     *ebiten.Shader
+	thePatternShaderOnce sync.Once
+)
+
+func patternShader() *ebiten.Shader {
+	thePatternShaderOnce.Do(func() {
+		s, err := ebiten.NewShader([]byte(patternShaderSource))
+		if err != nil {
+			panic(fmt.Sprintf("vector: compiling the pattern shader failed: %v", err))
+		}
+		thePatternShader = s
+	})
+	return thePatternShader
+}
+
+// The shader samples the texels by itself instead of using the built-in filters,
+// in order to apply the repeat modes before filtering.
+const patternShaderSource = `//kage:unit pixels
+
+package main
+
+var Row0 vec3
+var Row1 vec3
+var RepeatX int
+var RepeatY int
+var Filter int
+
+// wrap returns the wrapped texel index, or -1 if there is no texel.
+func wrap(i float, size float, mode int) float {
+	if mode == 0 {
+		if i < 0 || i >= size {
+			return -1
+		}
+		return i
+	}
+	if mode == 1 {
+		return mod(i, size)
+	}
+	if mode == 2 {
+		m := mod(i, 2*size)
+		if m >= size {
+			return 2*size - 1 - m
+		}
+		return m
+	}
+	return clamp(i, 0, size-1)
+}
+
+func texel(i vec2) vec4 {
+	if i.x < 0 || i.y < 0 {
+		return vec4(0)
+	}
+	return imageSrc0UnsafeAt(imageSrc0Origin() + i + 0.5)
+}
+
+func linearAt(u vec2) vec4 {
+	size := imageSrc0Size()
+	v := u - 0.5
+	t := floor(v)
+	f := v - t
+	x0 := wrap(t.x, size.x, RepeatX)
+	x1 := wrap(t.x+1, size.x, RepeatX)
+	y0 := wrap(t.y, size.y, RepeatY)
+	y1 := wrap(t.y+1, size.y, RepeatY)
+	c0 := mix(texel(vec2(x0, y0)), texel(vec2(x1, y0)), f.x)
+	c1 := mix(texel(vec2(x0, y1)), texel(vec2(x1, y1)), f.x)
+	return mix(c0, c1, f.y)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4, custom vec4) vec4 {
+	if custom.z > 0 && custom.x*custom.x-custom.y > 0 {
+		// Outside of a curve with FillAlgorithmGPUCurve.
+		discard()
+	}
+	p := srcPos - imageSrc0Origin()
+	u := vec2(dot(Row0, vec3(p, 1)), dot(Row1, vec3(p, 1)))
+	size := imageSrc0Size()
+
+	if Filter == 0 {
+		t := floor(u)
+		return texel(vec2(wrap(t.x, size.x, RepeatX), wrap(t.y, size.y, RepeatY))) * color
+	}
+
+	if Filter == 2 {
+		// The sampler of the graphics driver cannot apply the repeat modes.
+		// Take multiple linear samples along the major axis of the pixel's footprint in the image instead.
+		dx := dfdx(u)
+		dy := dfdy(u)
+		lx := length(dx)
+		ly := length(dy)
+		axis := dx
+		if lx < ly {
+			axis = dy
+		}
+		n := int(clamp(ceil(max(lx, ly)/max(min(lx, ly), 1)), 1, 16))
+		clr := vec4(0)
+		for i := 0; i < 16; i++ {
+			if i >= n {
+				break
+			}
+			clr += linearAt(u + axis*((float(i)+0.5)/float(n)-0.5))
+		}
+		return clr / float(n) * color
+	}
+
+	return linearAt(u) * color
+}
+`
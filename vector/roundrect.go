@@ -0,0 +1,135 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+)
+
+// RoundedRectOptions represents options for a rounded rectangle.
+type RoundedRectOptions struct {
+	// TopLeftRadius is the radius of the top-left corner.
+	TopLeftRadius float32
+
+	// TopRightRadius is the radius of the top-right corner.
+	TopRightRadius float32
+
+	// BottomRightRadius is the radius of the bottom-right corner.
+	BottomRightRadius float32
+
+	// BottomLeftRadius is the radius of the bottom-left corner.
+	BottomLeftRadius float32
+
+	// SuperellipseExponent is the exponent n of the superellipse |x|^n + |y|^n = 1 for the corners.
+	// 2 makes circular corners, and a bigger value makes smoother and squarer corners like a squircle.
+	// A typical value for a squircle is 4 or 5.
+	//
+	// The default (zero) value is 0, which is treated as 2 (circular corners).
+	SuperellipseExponent float32
+}
+
+// RoundedRect adds a closed subpath of a rounded rectangle to the path.
+// (x, y) is the upper-left corner of the rectangle, and the subpath goes clockwise.
+//
+// If the sum of the radii of two adjacent corners exceeds the length of the side,
+// all the radii are scaled down in the same way as CSS's border-radius.
+//
+// If options is nil, RoundedRect adds a rectangle without rounded corners.
+func (p *Path) RoundedRect(x, y, width, height float32, options *RoundedRectOptions) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	if options == nil {
+		options = &RoundedRectOptions{}
+	}
+
+	radius := func(r float32) float64 {
+		if r < 0 {
+			return 0
+		}
+		return float64(r)
+	}
+	tl := radius(options.TopLeftRadius)
+	tr := radius(options.TopRightRadius)
+	br := radius(options.BottomRightRadius)
+	bl := radius(options.BottomLeftRadius)
+
+	w, h := float64(width), float64(height)
+	scale := 1.0
+	for _, s := range [...]struct {
+		length float64
+		radii  float64
+	}{
+		{w, tl + tr},
+		{h, tr + br},
+		{w, br + bl},
+		{h, bl + tl},
+	} {
+		if s.radii > 0 && s.length/s.radii < scale {
+			scale = s.length / s.radii
+		}
+	}
+	tl *= scale
+	tr *= scale
+	br *= scale
+	bl *= scale
+
+	n := float64(options.SuperellipseExponent)
+	if n == 0 {
+		n = 2
+	}
+
+	x0, y0 := float64(x), float64(y)
+	x1, y1 := x0+w, y0+h
+	p.MoveTo(float32(x0+tl), float32(y0))
+	p.LineTo(float32(x1-tr), float32(y0))
+	p.roundedCorner(x1-tr, y0+tr, tr, -math.Pi/2, n)
+	p.LineTo(float32(x1), float32(y1-br))
+	p.roundedCorner(x1-br, y1-br, br, 0, n)
+	p.LineTo(float32(x0+bl), float32(y1))
+	p.roundedCorner(x0+bl, y1-bl, bl, math.Pi/2, n)
+	p.LineTo(float32(x0), float32(y0+tl))
+	p.roundedCorner(x0+tl, y0+tl, tl, math.Pi, n)
+	p.Close()
+}
+
+// roundedCorner adds a quarter of a superellipse with the exponent n centered at (cx, cy) from startAngle clockwise.
+func (p *Path) roundedCorner(cx, cy, radius float64, startAngle float64, n float64) {
+	if radius == 0 {
+		p.LineTo(float32(cx), float32(cy))
+		return
+	}
+	if n == 2 {
+		p.Arc(float32(cx), float32(cy), float32(radius), float32(startAngle), float32(startAngle+math.Pi/2), Clockwise)
+		return
+	}
+
+	// Approximate the superellipse with line segments.
+	// The number of the segments is determined by the radius, like the flattening of curves.
+	count := int(math.Ceil(math.Sqrt(radius) * 2))
+	if count < 4 {
+		count = 4
+	}
+	pow := func(v float64) float64 {
+		if v < 0 {
+			return -math.Pow(-v, 2/n)
+		}
+		return math.Pow(v, 2/n)
+	}
+	for i := 0; i <= count; i++ {
+		s, c := math.Sincos(startAngle + math.Pi/2*float64(i)/float64(count))
+		p.LineTo(float32(cx+radius*pow(c)), float32(cy+radius*pow(s)))
+	}
+}
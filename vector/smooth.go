@@ -0,0 +1,223 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+)
+
+// Point represents a point.
+type Point struct {
+	X float32
+	Y float32
+}
+
+// SimplifyPoints simplifies the polyline of the points with the Ramer-Douglas-Peucker algorithm,
+// and returns the points of the simplified polyline.
+// The first and the last points are always kept.
+//
+// tolerance is the maximum distance in pixels between the original polyline and the simplified polyline.
+//
+// SimplifyPoints is useful to reduce the points of freehand drawing input before building a path.
+// SimplifyPoints doesn't modify the given slice.
+func SimplifyPoints(points []Point, tolerance float32) []Point {
+	if len(points) <= 2 {
+		return append([]Point(nil), points...)
+	}
+
+	keep := make([]bool, len(points))
+	keep[0] = true
+	keep[len(points)-1] = true
+
+	type span struct {
+		first, last int
+	}
+	stack := []span{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		var maxDist float32
+		maxIdx := -1
+		for i := s.first + 1; i < s.last; i++ {
+			if d := distanceToSegment(points[i], points[s.first], points[s.last]); d > maxDist {
+				maxDist = d
+				maxIdx = i
+			}
+		}
+		if maxIdx < 0 || maxDist <= tolerance {
+			continue
+		}
+		keep[maxIdx] = true
+		stack = append(stack, span{s.first, maxIdx}, span{maxIdx, s.last})
+	}
+
+	var result []Point
+	for i, pt := range points {
+		if keep[i] {
+			result = append(result, pt)
+		}
+	}
+	return result
+}
+
+// distanceToSegment returns the distance between the point p and the segment p0-p1.
+func distanceToSegment(p, p0, p1 Point) float32 {
+	dx, dy := float64(p1.X-p0.X), float64(p1.Y-p0.Y)
+	px, py := float64(p.X-p0.X), float64(p.Y-p0.Y)
+	if l := dx*dx + dy*dy; l > 0 {
+		t := (px*dx + py*dy) / l
+		if t > 1 {
+			t = 1
+		} else if t < 0 {
+			t = 0
+		}
+		px -= t * dx
+		py -= t * dy
+	}
+	return float32(math.Hypot(px, py))
+}
+
+// CatmullRomParameterization represents the parameterization of a Catmull-Rom spline.
+type CatmullRomParameterization int
+
+const (
+	// CatmullRomParameterizationCentripetal is the centripetal parameterization (alpha = 0.5).
+	// The curve doesn't have cusps or self-intersections within a segment.
+	CatmullRomParameterizationCentripetal CatmullRomParameterization = iota
+
+	// CatmullRomParameterizationUniform is the uniform parameterization (alpha = 0).
+	CatmullRomParameterizationUniform
+
+	// CatmullRomParameterizationChordal is the chordal parameterization (alpha = 1).
+	CatmullRomParameterizationChordal
+)
+
+func (c CatmullRomParameterization) alpha() float64 {
+	switch c {
+	case CatmullRomParameterizationUniform:
+		return 0
+	case CatmullRomParameterizationChordal:
+		return 1
+	default:
+		return 0.5
+	}
+}
+
+// CatmullRomOptions represents options for a Catmull-Rom spline.
+type CatmullRomOptions struct {
+	// Parameterization is the parameterization of the spline.
+	//
+	// The default (zero) value is CatmullRomParameterizationCentripetal.
+	Parameterization CatmullRomParameterization
+
+	// Closed indicates whether the spline is closed.
+	// If Closed is true, the spline also goes through the last point to the first point smoothly, and the subpath is closed.
+	//
+	// The default (zero) value is false.
+	Closed bool
+}
+
+// CatmullRom adds a new subpath of a Catmull-Rom spline going through all the points.
+// Each segment of the spline is converted into a cubic Bézier curve.
+//
+// CatmullRom is useful to build a smooth path from sample points, like freehand drawing input, roads or rivers.
+//
+// If options is nil, the default options are used.
+func (p *Path) CatmullRom(points []Point, options *CatmullRomOptions) {
+	if options == nil {
+		options = &CatmullRomOptions{}
+	}
+
+	// Remove consecutive duplicated points, which make the spline degenerated.
+	pts := make([]Point, 0, len(points))
+	for _, pt := range points {
+		if len(pts) > 0 && pts[len(pts)-1] == pt {
+			continue
+		}
+		pts = append(pts, pt)
+	}
+	if options.Closed && len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	if len(pts) == 0 {
+		return
+	}
+
+	p.MoveTo(pts[0].X, pts[0].Y)
+	if len(pts) == 1 {
+		return
+	}
+
+	n := len(pts)
+	at := func(i int) Point {
+		if options.Closed {
+			return pts[(i%n+n)%n]
+		}
+		// Extrapolate the end points by reflection.
+		if i < 0 {
+			return Point{X: 2*pts[0].X - pts[1].X, Y: 2*pts[0].Y - pts[1].Y}
+		}
+		if i >= n {
+			return Point{X: 2*pts[n-1].X - pts[n-2].X, Y: 2*pts[n-1].Y - pts[n-2].Y}
+		}
+		return pts[i]
+	}
+
+	alpha := options.Parameterization.alpha()
+	segCount := n - 1
+	if options.Closed {
+		segCount = n
+	}
+	for i := 0; i < segCount; i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+		c1, c2 := catmullRomControlPoints(p0, p1, p2, p3, alpha)
+		p.CubicTo(c1.X, c1.Y, c2.X, c2.Y, p2.X, p2.Y)
+	}
+	if options.Closed {
+		p.Close()
+	}
+}
+
+// catmullRomControlPoints returns the control points of the cubic Bézier curve equivalent to
+// the Catmull-Rom spline segment between p1 and p2.
+//
+// See "On the parameterization of Catmull-Rom curves" by Cem Yuksel, Scott Schaefer and John Keyser.
+func catmullRomControlPoints(p0, p1, p2, p3 Point, alpha float64) (Point, Point) {
+	dist := func(a, b Point) float64 {
+		return math.Pow(math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y)), alpha)
+	}
+	d1, d2, d3 := dist(p0, p1), dist(p1, p2), dist(p2, p3)
+
+	c1 := p1
+	if d1 > 0 {
+		a := 2*d1*d1 + 3*d1*d2 + d2*d2
+		b := 3 * d1 * (d1 + d2)
+		c1 = Point{
+			X: float32((d1*d1*float64(p2.X) - d2*d2*float64(p0.X) + a*float64(p1.X)) / b),
+			Y: float32((d1*d1*float64(p2.Y) - d2*d2*float64(p0.Y) + a*float64(p1.Y)) / b),
+		}
+	}
+	c2 := p2
+	if d3 > 0 {
+		a := 2*d3*d3 + 3*d3*d2 + d2*d2
+		b := 3 * d3 * (d3 + d2)
+		c2 = Point{
+			X: float32((d3*d3*float64(p1.X) - d2*d2*float64(p3.X) + a*float64(p2.X)) / b),
+			Y: float32((d3*d3*float64(p1.Y) - d2*d2*float64(p3.Y) + a*float64(p2.Y)) / b),
+		}
+	}
+	return c1, c2
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svg
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func (d *Document) ShapeCount() int {
+	return len(d.shapes)
+}
+
+// ShapeFill returns the premultiplied fill color of the i-th shape.
+func (d *Document) ShapeFill(i int) (ebiten.ColorScale, bool) {
+	if d.shapes[i].fill == nil {
+		return ebiten.ColorScale{}, false
+	}
+	return *d.shapes[i].fill, true
+}
+
+func (d *Document) ShapeGeoM(i int) ebiten.GeoM {
+	return d.shapes[i].geoM
+}
+
+func ParseColor(str string) (color.NRGBA, bool) {
+	return parseColor(str)
+}
+
+func ParseTransform(str string) (ebiten.GeoM, bool) {
+	return parseTransform(str)
+}
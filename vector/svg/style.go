@@ -0,0 +1,453 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svg
+
+import (
+	"encoding/xml"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type paintType int
+
+const (
+	paintTypeNone paintType = iota
+	paintTypeColor
+	paintTypeCurrentColor
+)
+
+type paint struct {
+	typ   paintType
+	color color.NRGBA
+}
+
+func (p paint) colorScale(ctx *context, opacity float64) (ebiten.ColorScale, bool) {
+	var clr color.NRGBA
+	switch p.typ {
+	case paintTypeNone:
+		return ebiten.ColorScale{}, false
+	case paintTypeColor:
+		clr = p.color
+	case paintTypeCurrentColor:
+		clr = ctx.color
+	}
+	a := float32(clr.A) / 0xff * float32(opacity*ctx.opacity)
+	if a <= 0 {
+		return ebiten.ColorScale{}, false
+	}
+	var cs ebiten.ColorScale
+	cs.Scale(float32(clr.R)/0xff*a, float32(clr.G)/0xff*a, float32(clr.B)/0xff*a, a)
+	return cs, true
+}
+
+// context is a set of the states of an element.
+type context struct {
+	geoM ebiten.GeoM
+
+	color         color.NRGBA
+	fill          paint
+	fillOpacity   float64
+	fillRule      vector.FillRule
+	stroke        paint
+	strokeOpacity float64
+	strokeWidth   float64
+	lineCap       vector.LineCap
+	lineJoin      vector.LineJoin
+	miterLimit    float64
+	dashArray     []float32
+	dashOffset    float64
+
+	// opacity is the accumulated opacity of the ancestors.
+	// SVG applies a group opacity to the rendered group, but this package applies it to each shape for simplicity.
+	opacity float64
+
+	display string
+}
+
+func newRootContext() *context {
+	return &context{
+		color:         color.NRGBA{0, 0, 0, 0xff},
+		fill:          paint{typ: paintTypeColor, color: color.NRGBA{0, 0, 0, 0xff}},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		miterLimit:    4,
+		opacity:       1,
+	}
+}
+
+func (c *context) child(attrs []xml.Attr) *context {
+	ctx := *c
+	// display is not inherited.
+	ctx.display = ""
+
+	for _, a := range attrs {
+		if a.Name.Local == "transform" {
+			if g, ok := parseTransform(a.Value); ok {
+				g.Concat(c.geoM)
+				ctx.geoM = g
+			}
+			continue
+		}
+		ctx.setProperty(a.Name.Local, a.Value)
+	}
+
+	// A style attribute has a higher priority than presentation attributes.
+	if v, ok := attr(attrs, "style"); ok {
+		for _, decl := range strings.Split(v, ";") {
+			name, value, ok := strings.Cut(decl, ":")
+			if !ok {
+				continue
+			}
+			ctx.setProperty(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+	return &ctx
+}
+
+func (c *context) setProperty(name, value string) {
+	value = strings.TrimSpace(value)
+	if value == "inherit" {
+		return
+	}
+
+	switch name {
+	case "color":
+		if clr, ok := parseColor(value); ok {
+			c.color = clr
+		}
+	case "fill":
+		if p, ok := parsePaint(value); ok {
+			c.fill = p
+		}
+	case "fill-opacity":
+		if v, ok := parseOpacity(value); ok {
+			c.fillOpacity = v
+		}
+	case "fill-rule":
+		switch value {
+		case "nonzero":
+			c.fillRule = vector.FillRuleNonZero
+		case "evenodd":
+			c.fillRule = vector.FillRuleEvenOdd
+		}
+	case "stroke":
+		if p, ok := parsePaint(value); ok {
+			c.stroke = p
+		}
+	case "stroke-opacity":
+		if v, ok := parseOpacity(value); ok {
+			c.strokeOpacity = v
+		}
+	case "stroke-width":
+		if v, ok := parseLength(value); ok && v >= 0 {
+			c.strokeWidth = v
+		}
+	case "stroke-linecap":
+		switch value {
+		case "butt":
+			c.lineCap = vector.LineCapButt
+		case "round":
+			c.lineCap = vector.LineCapRound
+		case "square":
+			c.lineCap = vector.LineCapSquare
+		}
+	case "stroke-linejoin":
+		switch value {
+		case "miter", "miter-clip", "arcs":
+			c.lineJoin = vector.LineJoinMiter
+		case "round":
+			c.lineJoin = vector.LineJoinRound
+		case "bevel":
+			c.lineJoin = vector.LineJoinBevel
+		}
+	case "stroke-miterlimit":
+		if v, err := strconv.ParseFloat(value, 64); err == nil && v >= 1 {
+			c.miterLimit = v
+		}
+	case "stroke-dasharray":
+		if value == "none" {
+			c.dashArray = nil
+			break
+		}
+		vs := parseNumbers(value)
+		c.dashArray = make([]float32, len(vs))
+		for i, v := range vs {
+			c.dashArray[i] = float32(v)
+		}
+	case "stroke-dashoffset":
+		if v, ok := parseLength(value); ok {
+			c.dashOffset = v
+		}
+	case "opacity":
+		if v, ok := parseOpacity(value); ok {
+			c.opacity *= v
+		}
+	case "display":
+		c.display = value
+	}
+}
+
+func attr(attrs []xml.Attr, name string) (string, bool) {
+	for _, a := range attrs {
+		// Ignore namespaced attributes like xlink:href.
+		if a.Name.Local == name && (a.Name.Space == "" || a.Name.Space == "http://www.w3.org/2000/svg") {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func parseOpacity(str string) (float64, bool) {
+	var v float64
+	if strings.HasSuffix(str, "%") {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(str, "%"), 64)
+		if err != nil {
+			return 0, false
+		}
+		v = f / 100
+	} else {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return 0, false
+		}
+		v = f
+	}
+	return math.Min(math.Max(v, 0), 1), true
+}
+
+// parseLength parses a length in pixels. Percentages are not supported.
+func parseLength(str string) (float64, bool) {
+	str = strings.TrimSpace(str)
+	scale := 1.0
+	for _, u := range []struct {
+		name  string
+		scale float64
+	}{
+		{"px", 1},
+		{"pt", 96.0 / 72.0},
+		{"pc", 96.0 / 6.0},
+		{"in", 96},
+		{"cm", 96 / 2.54},
+		{"mm", 96 / 25.4},
+	} {
+		if strings.HasSuffix(str, u.name) {
+			str = strings.TrimSuffix(str, u.name)
+			scale = u.scale
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v * scale, true
+}
+
+// parseNumbers parses a list of numbers separated by spaces or commas.
+func parseNumbers(str string) []float64 {
+	fields := strings.FieldsFunc(str, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	vs := make([]float64, 0, len(fields))
+	for _, f := range fields {
+		v, ok := parseLength(f)
+		if !ok {
+			break
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// parseTransform parses a transform attribute, e.g. "translate(10 20) rotate(45)".
+func parseTransform(str string) (ebiten.GeoM, bool) {
+	var geoM ebiten.GeoM
+	for {
+		str = strings.TrimLeft(str, " \t\n\r,")
+		if str == "" {
+			return geoM, true
+		}
+		name, rest, ok := strings.Cut(str, "(")
+		if !ok {
+			return ebiten.GeoM{}, false
+		}
+		args, rest, ok := strings.Cut(rest, ")")
+		if !ok {
+			return ebiten.GeoM{}, false
+		}
+		str = rest
+		vs := parseNumbers(args)
+
+		var g ebiten.GeoM
+		switch strings.TrimSpace(name) {
+		case "matrix":
+			if len(vs) != 6 {
+				return ebiten.GeoM{}, false
+			}
+			g.SetElement(0, 0, vs[0])
+			g.SetElement(1, 0, vs[1])
+			g.SetElement(0, 1, vs[2])
+			g.SetElement(1, 1, vs[3])
+			g.SetElement(0, 2, vs[4])
+			g.SetElement(1, 2, vs[5])
+		case "translate":
+			switch len(vs) {
+			case 1:
+				g.Translate(vs[0], 0)
+			case 2:
+				g.Translate(vs[0], vs[1])
+			default:
+				return ebiten.GeoM{}, false
+			}
+		case "scale":
+			switch len(vs) {
+			case 1:
+				g.Scale(vs[0], vs[0])
+			case 2:
+				g.Scale(vs[0], vs[1])
+			default:
+				return ebiten.GeoM{}, false
+			}
+		case "rotate":
+			switch len(vs) {
+			case 1:
+				g.Rotate(vs[0] * math.Pi / 180)
+			case 3:
+				g.Translate(-vs[1], -vs[2])
+				g.Rotate(vs[0] * math.Pi / 180)
+				g.Translate(vs[1], vs[2])
+			default:
+				return ebiten.GeoM{}, false
+			}
+		case "skewX":
+			if len(vs) != 1 {
+				return ebiten.GeoM{}, false
+			}
+			g.SetElement(0, 1, math.Tan(vs[0]*math.Pi/180))
+		case "skewY":
+			if len(vs) != 1 {
+				return ebiten.GeoM{}, false
+			}
+			g.SetElement(1, 0, math.Tan(vs[0]*math.Pi/180))
+		default:
+			return ebiten.GeoM{}, false
+		}
+
+		// The transforms are applied from the last one.
+		g.Concat(geoM)
+		geoM = g
+	}
+}
+
+func parsePaint(str string) (paint, bool) {
+	switch str {
+	case "none":
+		return paint{typ: paintTypeNone}, true
+	case "currentColor":
+		return paint{typ: paintTypeCurrentColor}, true
+	}
+	if strings.HasPrefix(str, "url(") {
+		// Paint servers like gradients are not supported. Use the fallback value if exists.
+		_, fallback, _ := strings.Cut(str, ")")
+		fallback = strings.TrimSpace(fallback)
+		if fallback == "" {
+			return paint{typ: paintTypeNone}, true
+		}
+		return parsePaint(fallback)
+	}
+	clr, ok := parseColor(str)
+	if !ok {
+		return paint{}, false
+	}
+	return paint{typ: paintTypeColor, color: clr}, true
+}
+
+func parseColor(str string) (color.NRGBA, bool) {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "#") {
+		hex := str[1:]
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		switch len(hex) {
+		case 3:
+			return color.NRGBA{byte(v>>8&0xf) * 0x11, byte(v>>4&0xf) * 0x11, byte(v&0xf) * 0x11, 0xff}, true
+		case 4:
+			return color.NRGBA{byte(v>>12&0xf) * 0x11, byte(v>>8&0xf) * 0x11, byte(v>>4&0xf) * 0x11, byte(v&0xf) * 0x11}, true
+		case 6:
+			return color.NRGBA{byte(v >> 16), byte(v >> 8), byte(v), 0xff}, true
+		case 8:
+			return color.NRGBA{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}, true
+		}
+		return color.NRGBA{}, false
+	}
+
+	for _, prefix := range []string{"rgb(", "rgba("} {
+		if !strings.HasPrefix(str, prefix) {
+			continue
+		}
+		if !strings.HasSuffix(str, ")") {
+			return color.NRGBA{}, false
+		}
+		args := str[len(prefix) : len(str)-1]
+		fields := strings.FieldsFunc(args, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '/'
+		})
+		if len(fields) != 3 && len(fields) != 4 {
+			return color.NRGBA{}, false
+		}
+		var vs [4]byte
+		vs[3] = 0xff
+		for i, f := range fields {
+			var v float64
+			if strings.HasSuffix(f, "%") {
+				p, err := strconv.ParseFloat(strings.TrimSuffix(f, "%"), 64)
+				if err != nil {
+					return color.NRGBA{}, false
+				}
+				v = p / 100 * 0xff
+			} else {
+				p, err := strconv.ParseFloat(f, 64)
+				if err != nil {
+					return color.NRGBA{}, false
+				}
+				v = p
+				// The alpha value is in [0, 1].
+				if i == 3 {
+					v *= 0xff
+				}
+			}
+			vs[i] = byte(math.Round(math.Min(math.Max(v, 0), 0xff)))
+		}
+		return color.NRGBA{vs[0], vs[1], vs[2], vs[3]}, true
+	}
+
+	if str == "transparent" {
+		return color.NRGBA{}, true
+	}
+	if c, ok := colornames.Map[strings.ToLower(str)]; ok {
+		return color.NRGBA{c.R, c.G, c.B, c.A}, true
+	}
+	return color.NRGBA{}, false
+}
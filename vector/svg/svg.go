@@ -0,0 +1,408 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package svg provides a minimal SVG renderer.
+//
+// The supported elements are svg, g, path, rect, circle, ellipse, line, polyline, and polygon.
+// Fills and strokes with solid colors, opacities, fill rules, line caps, line joins, dash patterns, and transforms are supported.
+// The other elements like text, gradients, clip paths, masks, and filters are ignored.
+//
+// This package is under experiments and the API might be changed with breaking backward compatibility.
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/hajimehoshi/ebiten/v2/vector/internal/svgpath"
+)
+
+// Document is a parsed SVG document.
+type Document struct {
+	width  float64
+	height float64
+	shapes []*shape
+
+	image *ebiten.Image
+}
+
+// Parse parses an SVG document.
+func Parse(r io.Reader) (*Document, error) {
+	d := &Document{}
+
+	dec := xml.NewDecoder(r)
+	// Some SVG files use entities defined in DTDs. Accept unknown entities as they are.
+	dec.Strict = false
+
+	var stack []*context
+	var root bool
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("svg: %w", err)
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			var parent *context
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			} else {
+				if t.Name.Local != "svg" {
+					return nil, fmt.Errorf("svg: the root element must be svg but was %s", t.Name.Local)
+				}
+				parent = newRootContext()
+			}
+
+			ctx := parent.child(t.Attr)
+			if ctx.display == "none" {
+				if err := dec.Skip(); err != nil {
+					return nil, fmt.Errorf("svg: %w", err)
+				}
+				continue
+			}
+
+			switch t.Name.Local {
+			case "svg":
+				if !root {
+					root = true
+					d.initRoot(ctx, t.Attr)
+				}
+			case "g":
+			case "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+				if s := newShape(t.Name.Local, t.Attr, ctx); s != nil {
+					d.shapes = append(d.shapes, s)
+				}
+			default:
+				// Skip unsupported elements including their children, e.g. defs and text.
+				if err := dec.Skip(); err != nil {
+					return nil, fmt.Errorf("svg: %w", err)
+				}
+				continue
+			}
+			stack = append(stack, ctx)
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if !root {
+		return nil, fmt.Errorf("svg: no svg element")
+	}
+	return d, nil
+}
+
+func (d *Document) initRoot(ctx *context, attrs []xml.Attr) {
+	var vbX, vbY, vbW, vbH float64
+	var hasViewBox bool
+	if v, ok := attr(attrs, "viewBox"); ok {
+		if vs := parseNumbers(v); len(vs) == 4 && vs[2] > 0 && vs[3] > 0 {
+			vbX, vbY, vbW, vbH = vs[0], vs[1], vs[2], vs[3]
+			hasViewBox = true
+		}
+	}
+
+	d.width, d.height = vbW, vbH
+	if v, ok := attr(attrs, "width"); ok {
+		if w, ok := parseLength(v); ok {
+			d.width = w
+		}
+	}
+	if v, ok := attr(attrs, "height"); ok {
+		if h, ok := parseLength(v); ok {
+			d.height = h
+		}
+	}
+
+	if !hasViewBox {
+		return
+	}
+
+	// Map the view box to the viewport.
+	sx, sy := d.width/vbW, d.height/vbH
+	var alignX, alignY float64
+	par, _ := attr(attrs, "preserveAspectRatio")
+	fields := strings.Fields(par)
+	align := "xMidYMid"
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if align != "none" {
+		slice := len(fields) > 1 && fields[1] == "slice"
+		s := math.Min(sx, sy)
+		if slice {
+			s = math.Max(sx, sy)
+		}
+		sx, sy = s, s
+		switch {
+		case strings.HasPrefix(align, "xMin"):
+			alignX = 0
+		case strings.HasPrefix(align, "xMax"):
+			alignX = 1
+		default:
+			alignX = 0.5
+		}
+		switch {
+		case strings.HasSuffix(align, "YMin"):
+			alignY = 0
+		case strings.HasSuffix(align, "YMax"):
+			alignY = 1
+		default:
+			alignY = 0.5
+		}
+	}
+
+	var g ebiten.GeoM
+	g.Translate(-vbX, -vbY)
+	g.Scale(sx, sy)
+	g.Translate((d.width-vbW*sx)*alignX, (d.height-vbH*sy)*alignY)
+	g.Concat(ctx.geoM)
+	ctx.geoM = g
+}
+
+// Size returns the size of the document in pixels.
+func (d *Document) Size() (width, height float64) {
+	return d.width, d.height
+}
+
+// DrawOptions represents options to render a document.
+type DrawOptions struct {
+	// GeoM is a geometry matrix to draw.
+	// The default (zero) value is identity, which draws the document at (0, 0).
+	GeoM ebiten.GeoM
+
+	// ColorScale is a scale of colors.
+	// The default (zero) value is identity, which is (1, 1, 1, 1).
+	ColorScale ebiten.ColorScale
+
+	// AntiAlias is whether the document is rendered with anti-aliasing.
+	// The default (zero) value is false.
+	AntiAlias bool
+}
+
+// Draw draws the document on dst.
+//
+// The tessellated paths are cached for the last GeoM, and Draw is efficient when GeoM is not changed.
+func (d *Document) Draw(dst *ebiten.Image, options *DrawOptions) {
+	if options == nil {
+		options = &DrawOptions{}
+	}
+	for _, s := range d.shapes {
+		s.draw(dst, options)
+	}
+}
+
+// Image returns an image of the document rendered with anti-aliasing, scaled to the given size.
+//
+// The image is cached, and the document is rendered again only when the size is changed.
+// The returned image must not be modified or deallocated.
+func (d *Document) Image(width, height int) *ebiten.Image {
+	if d.image != nil {
+		if b := d.image.Bounds(); b.Dx() == width && b.Dy() == height {
+			return d.image
+		}
+		d.image.Deallocate()
+	}
+	d.image = ebiten.NewImage(width, height)
+	op := &DrawOptions{}
+	if d.width > 0 && d.height > 0 {
+		op.GeoM.Scale(float64(width)/d.width, float64(height)/d.height)
+	}
+	op.AntiAlias = true
+	d.Draw(d.image, op)
+	return d.image
+}
+
+type shape struct {
+	// localPath is the path in the local coordinate.
+	localPath *vector.Path
+	geoM      ebiten.GeoM
+
+	fill          *ebiten.ColorScale
+	fillRule      vector.FillRule
+	stroke        *ebiten.ColorScale
+	strokeOptions vector.StrokeOptions
+
+	// path is the path transformed by pathGeoM.
+	path     *vector.Path
+	pathGeoM ebiten.GeoM
+}
+
+func (s *shape) transformedPath(geoM ebiten.GeoM) *vector.Path {
+	if s.path != nil && s.pathGeoM == geoM {
+		return s.path
+	}
+	var p vector.Path
+	p.AddPath(s.localPath, &vector.AddPathOptions{GeoM: geoM})
+	s.path = &p
+	s.pathGeoM = geoM
+	return s.path
+}
+
+func (s *shape) draw(dst *ebiten.Image, options *DrawOptions) {
+	geoM := s.geoM
+	geoM.Concat(options.GeoM)
+	path := s.transformedPath(geoM)
+
+	if s.fill != nil {
+		op := &vector.DrawPathOptions{}
+		op.AntiAlias = options.AntiAlias
+		op.ColorScale = *s.fill
+		op.ColorScale.ScaleWithColorScale(options.ColorScale)
+		vector.FillPath(dst, path, &vector.FillOptions{FillRule: s.fillRule}, op)
+	}
+
+	if s.stroke != nil {
+		op := &vector.DrawPathOptions{}
+		op.AntiAlias = options.AntiAlias
+		op.ColorScale = *s.stroke
+		op.ColorScale.ScaleWithColorScale(options.ColorScale)
+
+		// A stroke is scaled by the transform. As the stroke is made from the transformed path,
+		// the width is scaled by the average scale of the transform. This is not accurate for non-uniform scales.
+		scale := float32(math.Sqrt(math.Abs(geoM.Element(0, 0)*geoM.Element(1, 1) - geoM.Element(0, 1)*geoM.Element(1, 0))))
+		so := s.strokeOptions
+		so.Width *= scale
+		if len(so.DashArray) > 0 {
+			so.DashArray = make([]float32, len(s.strokeOptions.DashArray))
+			for i, v := range s.strokeOptions.DashArray {
+				so.DashArray[i] = v * scale
+			}
+			so.DashOffset *= scale
+		}
+		vector.StrokePath(dst, path, &so, op)
+	}
+}
+
+func newShape(name string, attrs []xml.Attr, ctx *context) *shape {
+	num := func(name string) float64 {
+		v, _ := attr(attrs, name)
+		l, _ := parseLength(v)
+		return l
+	}
+
+	var r vector.Path
+	switch name {
+	case "path":
+		d, _ := attr(attrs, "d")
+		// Render the path up to an error, as SVG does.
+		_ = svgpath.Parse(d, &r)
+
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		_, hasRx := attr(attrs, "rx")
+		_, hasRy := attr(attrs, "ry")
+		rx, ry := num("rx"), num("ry")
+		if !hasRx {
+			rx = ry
+		}
+		if !hasRy {
+			ry = rx
+		}
+		rx = math.Min(math.Max(rx, 0), w/2)
+		ry = math.Min(math.Max(ry, 0), h/2)
+		if rx == 0 || ry == 0 {
+			r.MoveTo(float32(x), float32(y))
+			r.LineTo(float32(x+w), float32(y))
+			r.LineTo(float32(x+w), float32(y+h))
+			r.LineTo(float32(x), float32(y+h))
+			r.Close()
+			break
+		}
+		r.MoveTo(float32(x+rx), float32(y))
+		r.LineTo(float32(x+w-rx), float32(y))
+		svgpath.ArcTo(&r, x+w-rx, y, rx, ry, 0, false, true, x+w, y+ry)
+		r.LineTo(float32(x+w), float32(y+h-ry))
+		svgpath.ArcTo(&r, x+w, y+h-ry, rx, ry, 0, false, true, x+w-rx, y+h)
+		r.LineTo(float32(x+rx), float32(y+h))
+		svgpath.ArcTo(&r, x+rx, y+h, rx, ry, 0, false, true, x, y+h-ry)
+		r.LineTo(float32(x), float32(y+ry))
+		svgpath.ArcTo(&r, x, y+ry, rx, ry, 0, false, true, x+rx, y)
+		r.Close()
+
+	case "circle", "ellipse":
+		cx, cy := num("cx"), num("cy")
+		var rx, ry float64
+		if name == "circle" {
+			rx = num("r")
+			ry = rx
+		} else {
+			rx, ry = num("rx"), num("ry")
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		r.MoveTo(float32(cx+rx), float32(cy))
+		svgpath.ArcTo(&r, cx+rx, cy, rx, ry, 0, false, true, cx-rx, cy)
+		svgpath.ArcTo(&r, cx-rx, cy, rx, ry, 0, false, true, cx+rx, cy)
+		r.Close()
+
+	case "line":
+		r.MoveTo(float32(num("x1")), float32(num("y1")))
+		r.LineTo(float32(num("x2")), float32(num("y2")))
+
+	case "polyline", "polygon":
+		v, _ := attr(attrs, "points")
+		vs := parseNumbers(v)
+		if len(vs) < 4 {
+			return nil
+		}
+		r.MoveTo(float32(vs[0]), float32(vs[1]))
+		for i := 2; i+1 < len(vs); i += 2 {
+			r.LineTo(float32(vs[i]), float32(vs[i+1]))
+		}
+		if name == "polygon" {
+			r.Close()
+		}
+	}
+
+	s := &shape{
+		localPath: &r,
+		geoM:      ctx.geoM,
+		fillRule:  ctx.fillRule,
+	}
+	// A line has no area to fill.
+	if c, ok := ctx.fill.colorScale(ctx, ctx.fillOpacity); ok && name != "line" {
+		s.fill = &c
+	}
+	if c, ok := ctx.stroke.colorScale(ctx, ctx.strokeOpacity); ok && ctx.strokeWidth > 0 {
+		s.stroke = &c
+		s.strokeOptions = vector.StrokeOptions{
+			Width:      float32(ctx.strokeWidth),
+			LineCap:    ctx.lineCap,
+			LineJoin:   ctx.lineJoin,
+			MiterLimit: float32(ctx.miterLimit),
+			DashArray:  ctx.dashArray,
+			DashOffset: float32(ctx.dashOffset),
+		}
+	}
+	if s.fill == nil && s.stroke == nil {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svg_test
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/vector/svg"
+)
+
+const testSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100" viewBox="0 0 100 50">
+  <defs>
+    <linearGradient id="g"><stop offset="0" stop-color="red"/></linearGradient>
+  </defs>
+  <rect x="0" y="0" width="100" height="50" fill="#0000ff"/>
+  <g transform="translate(10 20)" fill="red" opacity="0.5">
+    <circle cx="0" cy="0" r="5"/>
+    <path d="M0 0h10v10z" style="fill: rgb(0, 255, 0)"/>
+    <line x1="0" y1="0" x2="10" y2="10"/>
+    <line x1="0" y1="0" x2="10" y2="10" stroke="black"/>
+  </g>
+  <rect width="10" height="10" display="none"/>
+  <text>Hello</text>
+</svg>`
+
+func TestParse(t *testing.T) {
+	doc, err := svg.Parse(strings.NewReader(testSVG))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w, h := doc.Size(); w != 200 || h != 100 {
+		t.Errorf("Size(): got: (%v, %v), want: (200, 100)", w, h)
+	}
+
+	// A line without a stroke, a hidden rect, and unsupported elements are not shapes.
+	if got, want := doc.ShapeCount(), 4; got != want {
+		t.Fatalf("ShapeCount(): got: %d, want: %d", got, want)
+	}
+
+	// The view box is scaled by 2.
+	g := doc.ShapeGeoM(1)
+	if x, y := g.Apply(0, 0); x != 20 || y != 40 {
+		t.Errorf("ShapeGeoM(1).Apply(0, 0): got: (%v, %v), want: (20, 40)", x, y)
+	}
+
+	for i, want := range []struct {
+		r, g, b, a float32
+	}{
+		{0, 0, 1, 1},
+		{0.5, 0, 0, 0.5},
+		{0, 0.5, 0, 0.5},
+	} {
+		c, ok := doc.ShapeFill(i)
+		if !ok {
+			t.Errorf("ShapeFill(%d): no fill", i)
+			continue
+		}
+		if c.R() != want.r || c.G() != want.g || c.B() != want.b || c.A() != want.a {
+			t.Errorf("ShapeFill(%d): got: %v, want: %v", i, c, want)
+		}
+	}
+	if _, ok := doc.ShapeFill(3); ok {
+		t.Errorf("ShapeFill(3): a line must not have a fill")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := svg.Parse(strings.NewReader(`<html></html>`)); err == nil {
+		t.Errorf("an error is expected for a non-SVG document")
+	}
+	if _, err := svg.Parse(strings.NewReader(``)); err == nil {
+		t.Errorf("an error is expected for an empty document")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	testCases := []struct {
+		str  string
+		want color.NRGBA
+		ok   bool
+	}{
+		{"#f00", color.NRGBA{0xff, 0, 0, 0xff}, true},
+		{"#00ff0080", color.NRGBA{0, 0xff, 0, 0x80}, true},
+		{"rgb(0, 0, 255)", color.NRGBA{0, 0, 0xff, 0xff}, true},
+		{"rgba(100%, 0%, 0%, 0.5)", color.NRGBA{0xff, 0, 0, 0x80}, true},
+		{"orange", color.NRGBA{0xff, 0xa5, 0, 0xff}, true},
+		{"transparent", color.NRGBA{}, true},
+		{"#12", color.NRGBA{}, false},
+		{"unknown", color.NRGBA{}, false},
+	}
+	for _, tc := range testCases {
+		got, ok := svg.ParseColor(tc.str)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("ParseColor(%q): got: (%v, %v), want: (%v, %v)", tc.str, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	testCases := []struct {
+		str   string
+		x, y  float64
+		wantX float64
+		wantY float64
+	}{
+		{"translate(10,0) scale(2)", 1, 0, 12, 0},
+		{"scale(2) translate(10 0)", 1, 0, 22, 0},
+		{"rotate(90, 10, 0)", 20, 0, 10, 10},
+		{"matrix(1 0 0 1 5 6)", 1, 1, 6, 7},
+		{"skewX(45)", 0, 1, 1, 1},
+	}
+	for _, tc := range testCases {
+		g, ok := svg.ParseTransform(tc.str)
+		if !ok {
+			t.Errorf("ParseTransform(%q) failed", tc.str)
+			continue
+		}
+		x, y := g.Apply(tc.x, tc.y)
+		if math.Abs(x-tc.wantX) > 1e-9 || math.Abs(y-tc.wantY) > 1e-9 {
+			t.Errorf("ParseTransform(%q).Apply(%v, %v): got: (%v, %v), want: (%v, %v)", tc.str, tc.x, tc.y, x, y, tc.wantX, tc.wantY)
+		}
+	}
+	if _, ok := svg.ParseTransform("unknown(1)"); ok {
+		t.Errorf("ParseTransform must fail for an unknown transform")
+	}
+}
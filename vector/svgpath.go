@@ -0,0 +1,32 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/vector/internal/svgpath"
+)
+
+// ParseSVGPathData parses SVG path data, which is the value of the `d` attribute of a `path` element, and returns a new path.
+//
+// All the commands of SVG path data are supported. Elliptical arcs are converted to cubic Bézier curves.
+//
+// If d has an error, ParseSVGPathData returns the path up to the error with the error, in the same way as SVG renderers.
+func ParseSVGPathData(d string) (*Path, error) {
+	var p Path
+	if err := svgpath.Parse(d, &p); err != nil {
+		return &p, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func transformPoint(pt point, geoM *ebiten.GeoM) point {
+	x, y := geoM.Apply(float64(pt.x), float64(pt.y))
+	return point{x: float32(x), y: float32(y)}
+}
+
+func transformOp(o op, geoM *ebiten.GeoM) op {
+	switch o.typ {
+	case opTypeMoveTo, opTypeLineTo:
+		o.p1 = transformPoint(o.p1, geoM)
+	case opTypeQuadTo:
+		o.p1 = transformPoint(o.p1, geoM)
+		o.p2 = transformPoint(o.p2, geoM)
+	case opTypeCubicTo:
+		o.p1 = transformPoint(o.p1, geoM)
+		o.p2 = transformPoint(o.p2, geoM)
+		o.p3 = transformPoint(o.p3, geoM)
+	}
+	return o
+}
+
+// Transform transforms all the segments of the path with the given geometry matrix.
+//
+// As the curves are transformed before they are flattened, Transform keeps the curves smooth even when they are scaled up.
+// Strokes of the transformed path have a constant width regardless of the matrix,
+// as the stroke width is applied after the transformation.
+func (p *Path) Transform(geoM ebiten.GeoM) {
+	p.subpaths = p.subpaths[:0]
+	for i := range p.ops {
+		// Transforming the control points of a Bézier curve is the same as transforming the curve.
+		p.ops[i] = transformOp(p.ops[i], &geoM)
+	}
+}
+
+// AddPathOptions represents options for AddPath.
+type AddPathOptions struct {
+	// GeoM is a geometry matrix to transform the added path.
+	//
+	// The default (zero) value is identity.
+	GeoM ebiten.GeoM
+}
+
+// AddPath adds the segments of src to the path, transformed by options.GeoM.
+// src is not modified.
+//
+// AddPath is useful to draw the same path at different positions, scales, or rotations:
+// keep an original path, and make a transformed path from it every time.
+func (p *Path) AddPath(src *Path, options *AddPathOptions) {
+	if options == nil {
+		options = &AddPathOptions{}
+	}
+	p.subpaths = p.subpaths[:0]
+	for _, o := range src.ops {
+		p.ops = append(p.ops, transformOp(o, &options.GeoM))
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The Ebitengine Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// AppendVerticesAndIndicesForTriangulation appends vertices and indices of non-overlapping triangles to fill this path,
+// and returns them.
+// AppendVerticesAndIndicesForTriangulation works in a similar way to the built-in append function.
+// If the arguments are nils, AppendVerticesAndIndicesForTriangulation returns new slices.
+//
+// The returned vertice's SrcX and SrcY are 0, and ColorR, ColorG, ColorB, and ColorA are 1.
+//
+// Unlike AppendVerticesAndIndicesForFilling, the returned triangles cover the filled area exactly once based on fillRule,
+// including a concave polygon, a polygon with holes, and a self-intersecting polygon.
+// Then, the returned values can be rendered by DrawTriangles or DrawTrianglesShader
+// with FillRuleFillAll, any Blend, a translucent color and a custom shader.
+// All the triangles are clockwise.
+//
+// Curves are flattened, and open subpaths are regarded as closed, in the same way as FillPath.
+//
+// The time complexity is O(n²) where n is the number of the flattened segments.
+func (p *Path) AppendVerticesAndIndicesForTriangulation(vertices []ebiten.Vertex, indices []uint16, fillRule FillRule) ([]ebiten.Vertex, []uint16) {
+	segs := splitSegments(appendSegmentsForFilling(nil, p))
+
+	// Decompose the area into trapezoids with horizontal lines at all the end points.
+	// As the split segments don't cross each other, the order of the segments in each slab is well-defined.
+	var ys []float64
+	for _, s := range segs {
+		ys = append(ys, s.p0.y, s.p1.y)
+	}
+	sort.Float64s(ys)
+	ys = uniqueFloat64s(ys)
+
+	type crossing struct {
+		x0, x1 float64
+		dir    int
+	}
+	var crossings []crossing
+	for i := 0; i < len(ys)-1; i++ {
+		y0, y1 := ys[i], ys[i+1]
+		crossings = crossings[:0]
+		for _, s := range segs {
+			dir := 1
+			top, bottom := s.p0, s.p1
+			if top.y > bottom.y {
+				top, bottom = bottom, top
+				dir = -1
+			}
+			if top.y > y0 || bottom.y < y1 || top.y == bottom.y {
+				continue
+			}
+			crossings = append(crossings, crossing{
+				x0:  xAtY(top, bottom, y0),
+				x1:  xAtY(top, bottom, y1),
+				dir: dir,
+			})
+		}
+		sort.Slice(crossings, func(a, b int) bool {
+			return crossings[a].x0+crossings[a].x1 < crossings[b].x0+crossings[b].x1
+		})
+
+		var winding int
+		var left crossing
+		var inside bool
+		for _, c := range crossings {
+			winding += c.dir
+			in := winding != 0
+			if fillRule == FillRuleEvenOdd {
+				in = winding%2 != 0
+			}
+			if in == inside {
+				continue
+			}
+			inside = in
+			if in {
+				left = c
+				continue
+			}
+
+			base := uint16(len(vertices))
+			for _, pt := range [...]bpoint{
+				{x: left.x0, y: y0},
+				{x: c.x0, y: y0},
+				{x: c.x1, y: y1},
+				{x: left.x1, y: y1},
+			} {
+				vertices = append(vertices, ebiten.Vertex{
+					DstX:   float32(pt.x),
+					DstY:   float32(pt.y),
+					ColorR: 1,
+					ColorG: 1,
+					ColorB: 1,
+					ColorA: 1,
+				})
+			}
+			indices = append(indices, base, base+1, base+2, base, base+2, base+3)
+		}
+	}
+	return vertices, indices
+}
+
+// xAtY returns the X position of the segment top-bottom at y.
+func xAtY(top, bottom bpoint, y float64) float64 {
+	if y <= top.y {
+		return top.x
+	}
+	if y >= bottom.y {
+		return bottom.x
+	}
+	return top.x + (bottom.x-top.x)*(y-top.y)/(bottom.y-top.y)
+}
+
+// uniqueFloat64s removes the consecutive duplicated values from the sorted slice.
+func uniqueFloat64s(values []float64) []float64 {
+	if len(values) == 0 {
+		return values
+	}
+	result := values[:1]
+	for _, v := range values[1:] {
+		if v != result[len(result)-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
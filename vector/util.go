@@ -15,6 +15,7 @@
 package vector
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"math"
@@ -67,6 +68,224 @@ func drawVerticesForUtil(dst *ebiten.Image, vs []ebiten.Vertex, is []uint16, clr
 	dst.DrawTriangles(vs, is, whiteSubImage, op)
 }
 
+// FillRule is the rule whether an overlapped region is rendered or not.
+type FillRule int
+
+const (
+	// FillRuleNonZero means that triangles are rendered based on the non-zero rule.
+	// If and only if the number of overlaps is not 0, the region is rendered.
+	FillRuleNonZero FillRule = iota
+
+	// FillRuleEvenOdd means that triangles are rendered based on the even-odd rule.
+	// If and only if the number of overlaps is odd, the region is rendered.
+	FillRuleEvenOdd
+)
+
+func (f FillRule) ebitenFillRule() ebiten.FillRule {
+	switch f {
+	case FillRuleEvenOdd:
+		return ebiten.FillRuleEvenOdd
+	default:
+		return ebiten.FillRuleNonZero
+	}
+}
+
+// FillAlgorithm is the algorithm to fill a path.
+type FillAlgorithm int
+
+const (
+	// FillAlgorithmFlatten flattens the curves into line segments on CPU,
+	// and fills the polygon with the stencil buffer.
+	FillAlgorithmFlatten FillAlgorithm = iota
+
+	// FillAlgorithmGPUCurve fills the path without flattening the curves on CPU.
+	// The polygon of the end points is filled with the stencil buffer, and each quadratic curve is rendered as one triangle
+	// whose pixels outside of the curve are discarded by the shader.
+	// A cubic curve is approximated with a few quadratic curves.
+	//
+	// FillAlgorithmGPUCurve is useful for a huge path with many curves, especially when the path changes every frame,
+	// as the CPU cost and the number of the vertices are much smaller than FillAlgorithmFlatten.
+	FillAlgorithmGPUCurve
+)
+
+// FillOptions is options to fill a path.
+type FillOptions struct {
+	// FillRule is the rule whether an overlapped region is rendered or not.
+	//
+	// The default (zero) value is FillRuleNonZero.
+	FillRule FillRule
+
+	// Algorithm is the algorithm to fill the path.
+	//
+	// The default (zero) value is FillAlgorithmFlatten.
+	Algorithm FillAlgorithm
+
+	// FlatteningTolerance is the maximum distance in pixels between a curve and the line segments approximating the curve.
+	// A smaller value makes smoother curves with more triangles, and a bigger value makes fewer triangles.
+	// FlatteningTolerance is used only with FillAlgorithmFlatten.
+	//
+	// The default (zero) value is 0, which means 0.5.
+	FlatteningTolerance float32
+}
+
+func (f *FillOptions) appendVerticesAndIndices(path *Path, vertices []ebiten.Vertex, indices []uint16) ([]ebiten.Vertex, []uint16) {
+	switch f.Algorithm {
+	case FillAlgorithmFlatten:
+		return path.appendVerticesAndIndicesForFilling(vertices, indices, f.FlatteningTolerance)
+	case FillAlgorithmGPUCurve:
+		return path.appendVerticesAndIndicesForCurveFilling(vertices, indices)
+	default:
+		panic(fmt.Sprintf("vector: invalid fill algorithm: %d", f.Algorithm))
+	}
+}
+
+// DrawPathOptions is options to draw a path.
+type DrawPathOptions struct {
+	// AntiAlias is whether the path is drawn with anti-aliasing.
+	//
+	// The default (zero) value is false.
+	AntiAlias bool
+
+	// AntiAliasQuality is the quality of anti-aliasing.
+	// AntiAliasQuality is used only when AntiAlias is true.
+	//
+	// The default (zero) value is AntiAliasQualityDefault.
+	AntiAliasQuality AntiAliasQuality
+
+	// ColorScale is the color scale to apply to the path.
+	// If Paint is nil, the path is drawn with the color of ColorScale.
+	//
+	// The default (zero) value is identity, which is (1, 1, 1, 1) (white).
+	ColorScale ebiten.ColorScale
+
+	// Blend is the blend mode to apply to the path.
+	//
+	// The default (zero) value is the regular alpha blending.
+	Blend ebiten.Blend
+
+	// Paint is the source of colors of the path.
+	// The colors of Paint are multiplied by ColorScale.
+	//
+	// The default (zero) value is nil, which means a solid color.
+	Paint Paint
+}
+
+// FillPath fills the specified path with the specified options.
+func FillPath(dst *ebiten.Image, path *Path, fillOptions *FillOptions, drawPathOptions *DrawPathOptions) {
+	if fillOptions == nil {
+		fillOptions = &FillOptions{}
+	}
+	if drawPathOptions == nil {
+		drawPathOptions = &DrawPathOptions{}
+	}
+
+	useCachedVerticesAndIndices(func(vs []ebiten.Vertex, is []uint16) ([]ebiten.Vertex, []uint16) {
+		vs, is = fillOptions.appendVerticesAndIndices(path, vs, is)
+		drawVerticesForPath(dst, vs, is, fillOptions.FillRule.ebitenFillRule(), fillOptions.Algorithm == FillAlgorithmGPUCurve, drawPathOptions)
+		return vs, is
+	})
+}
+
+// StrokePath strokes the specified path with the specified options.
+func StrokePath(dst *ebiten.Image, path *Path, strokeOptions *StrokeOptions, drawPathOptions *DrawPathOptions) {
+	if drawPathOptions == nil {
+		drawPathOptions = &DrawPathOptions{}
+	}
+
+	useCachedVerticesAndIndices(func(vs []ebiten.Vertex, is []uint16) ([]ebiten.Vertex, []uint16) {
+		vs, is = path.AppendVerticesAndIndicesForStroke(vs, is, strokeOptions)
+		// All the triangles for a stroke are clockwise. With FillRuleNonZero, each pixel is rendered only once
+		// even if the triangles overlap. This is necessary to render a translucent stroke correctly.
+		drawVerticesForPath(dst, vs, is, ebiten.FillRuleNonZero, false, drawPathOptions)
+		return vs, is
+	})
+}
+
+// drawVerticesForPath draws the vertices for a path.
+// If curve is true, the vertices are ones for FillAlgorithmGPUCurve.
+func drawVerticesForPath(dst *ebiten.Image, vs []ebiten.Vertex, is []uint16, fillRule ebiten.FillRule, curve bool, options *DrawPathOptions) {
+	setVertexColors(vs, options.ColorScale)
+	drawColoredVerticesForPath(dst, vs, is, fillRule, curve, options)
+}
+
+// setVertexColors sets the colors of the vertices with the given color scale.
+func setVertexColors(vs []ebiten.Vertex, colorScale ebiten.ColorScale) {
+	r, g, b, a := colorScale.R(), colorScale.G(), colorScale.B(), colorScale.A()
+	for i := range vs {
+		vs[i].ColorR = r
+		vs[i].ColorG = g
+		vs[i].ColorB = b
+		vs[i].ColorA = a
+	}
+}
+
+// drawColoredVerticesForPath draws the vertices for a path in the same way as drawVerticesForPath,
+// but uses the colors of the vertices instead of options.ColorScale.
+func drawColoredVerticesForPath(dst *ebiten.Image, vs []ebiten.Vertex, is []uint16, fillRule ebiten.FillRule, curve bool, options *DrawPathOptions) {
+	if len(is) == 0 {
+		return
+	}
+
+	var shader *ebiten.Shader
+	var uniforms map[string]any
+	var img *ebiten.Image
+	// The offset to make srcPos - imageSrc0Origin() the path coordinates in the shader.
+	var offsetX, offsetY float32
+	if options.Paint != nil {
+		shader, uniforms, img = options.Paint.shader()
+		if shader == nil {
+			return
+		}
+		if img != nil {
+			b := img.Bounds()
+			offsetX, offsetY = float32(b.Min.X), float32(b.Min.Y)
+		}
+	}
+
+	for i := range vs {
+		if options.Paint != nil {
+			// A paint shader receives the path coordinates as the source positions.
+			vs[i].SrcX = vs[i].DstX + offsetX
+			vs[i].SrcY = vs[i].DstY + offsetY
+		} else {
+			vs[i].SrcX = 1
+			vs[i].SrcY = 1
+		}
+	}
+
+	if options.Paint == nil && curve {
+		shader = curveFillShader()
+	}
+
+	if options.AntiAlias && options.AntiAliasQuality == AntiAliasQualityHigh {
+		if drawTrianglesWithSupersampling(dst, vs, is, shader, uniforms, img, fillRule, options.Blend) {
+			return
+		}
+	}
+	drawTriangles(dst, vs, is, shader, uniforms, img, fillRule, options.Blend, options.AntiAlias)
+}
+
+// drawTriangles draws the triangles with the shader, or with a solid color if shader is nil.
+func drawTriangles(dst *ebiten.Image, vs []ebiten.Vertex, is []uint16, shader *ebiten.Shader, uniforms map[string]any, img *ebiten.Image, fillRule ebiten.FillRule, blend ebiten.Blend, antialias bool) {
+	if shader != nil {
+		op := &ebiten.DrawTrianglesShaderOptions{}
+		op.Uniforms = uniforms
+		op.Images[0] = img
+		op.Blend = blend
+		op.FillRule = fillRule
+		op.AntiAlias = antialias
+		dst.DrawTrianglesShader(vs, is, shader, op)
+		return
+	}
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	op.Blend = blend
+	op.FillRule = fillRule
+	op.AntiAlias = antialias
+	dst.DrawTriangles(vs, is, whiteSubImage, op)
+}
+
 // StrokeLine strokes a line (x0, y0)-(x1, y1) with the specified width and color.
 //
 // clr has be to be a solid (non-transparent) color.
@@ -15,7 +15,10 @@
 package vector_test
 
 import (
+	"fmt"
+	"image"
 	"image/color"
+	"math"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -35,3 +38,342 @@ func TestLine0(t *testing.T) {
 		t.Errorf("got: %v, want: %v", got, want)
 	}
 }
+
+func TestFillPathWithLinearGradient(t *testing.T) {
+	dst := ebiten.NewImage(16, 16)
+
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(16, 0)
+	path.LineTo(16, 16)
+	path.LineTo(0, 16)
+	path.Close()
+
+	op := &vector.DrawPathOptions{}
+	op.Paint = &vector.LinearGradient{
+		X0: 0,
+		Y0: 0,
+		X1: 16,
+		Y1: 0,
+		ColorStops: []vector.ColorStop{
+			{Offset: 0, Color: color.RGBA{0, 0, 0, 0xff}},
+			{Offset: 1, Color: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		},
+	}
+	vector.FillPath(dst, &path, nil, op)
+
+	for i := 1; i < 16; i++ {
+		c0 := dst.At(i-1, 8).(color.RGBA)
+		c1 := dst.At(i, 8).(color.RGBA)
+		if c0.A != 0xff || c1.A != 0xff {
+			t.Errorf("At(%d, 8): alpha must be opaque: %v, %v", i, c0, c1)
+		}
+		if c0.R >= c1.R {
+			t.Errorf("At(%d, 8): got: %v, want: brighter than %v", i, c1, c0)
+		}
+	}
+}
+
+func TestStrokePathTranslucent(t *testing.T) {
+	dst := ebiten.NewImage(16, 16)
+
+	var path vector.Path
+	path.MoveTo(2, 8)
+	path.LineTo(8, 8)
+	path.LineTo(8, 14)
+
+	op := &vector.DrawPathOptions{}
+	op.ColorScale.ScaleAlpha(0.5)
+	vector.StrokePath(dst, &path, &vector.StrokeOptions{Width: 4}, op)
+
+	// Overlapped triangles at the joint must not be rendered twice.
+	if got, want := dst.At(8, 8), dst.At(5, 8); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestFillPathWithImagePattern(t *testing.T) {
+	// Use a sub-image to test the offset of the source image.
+	src := ebiten.NewImage(4, 4)
+	src.Set(1, 1, color.RGBA{0xff, 0, 0, 0xff})
+	src.Set(2, 1, color.RGBA{0, 0xff, 0, 0xff})
+	src.Set(1, 2, color.RGBA{0, 0, 0xff, 0xff})
+	src.Set(2, 2, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	pattern := src.SubImage(image.Rect(1, 1, 3, 3)).(*ebiten.Image)
+
+	var path vector.Path
+	path.MoveTo(0, 0)
+	path.LineTo(16, 0)
+	path.LineTo(16, 16)
+	path.LineTo(0, 16)
+	path.Close()
+
+	for _, repeat := range []vector.RepeatMode{vector.RepeatModeNone, vector.RepeatModeRepeat, vector.RepeatModeReflect, vector.RepeatModePad} {
+		dst := ebiten.NewImage(16, 16)
+		p := &vector.ImagePattern{
+			Image:   pattern,
+			RepeatX: repeat,
+			RepeatY: repeat,
+		}
+		p.GeoM.Scale(2, 2)
+		vector.FillPath(dst, &path, nil, &vector.DrawPathOptions{
+			Paint: p,
+		})
+
+		red := color.RGBA{0xff, 0, 0, 0xff}
+		green := color.RGBA{0, 0xff, 0, 0xff}
+		blue := color.RGBA{0, 0, 0xff, 0xff}
+		white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+		want := map[image.Point]color.RGBA{
+			{0, 0}: red,
+			{3, 1}: green,
+			{1, 3}: blue,
+			{3, 3}: white,
+		}
+		switch repeat {
+		case vector.RepeatModeNone:
+			want[image.Pt(5, 1)] = color.RGBA{}
+			want[image.Pt(1, 5)] = color.RGBA{}
+		case vector.RepeatModeRepeat:
+			want[image.Pt(5, 1)] = red
+			want[image.Pt(7, 5)] = white
+		case vector.RepeatModeReflect:
+			want[image.Pt(5, 1)] = green
+			want[image.Pt(7, 1)] = red
+		case vector.RepeatModePad:
+			want[image.Pt(15, 1)] = green
+			want[image.Pt(15, 15)] = white
+		}
+		for pos, w := range want {
+			if got := dst.At(pos.X, pos.Y); got != w {
+				t.Errorf("repeat: %d, At(%d, %d): got: %v, want: %v", repeat, pos.X, pos.Y, got, w)
+			}
+		}
+	}
+}
+
+func TestCompiledPath(t *testing.T) {
+	var path vector.Path
+	path.MoveTo(2, 2)
+	path.LineTo(14, 2)
+	path.LineTo(14, 14)
+	path.Close()
+
+	compiled := vector.NewCompiledPath(&path)
+	// Modifying the original path must not affect the compiled path.
+	path.LineTo(2, 14)
+
+	want := ebiten.NewImage(16, 16)
+	var tri vector.Path
+	tri.MoveTo(2, 2)
+	tri.LineTo(14, 2)
+	tri.LineTo(14, 14)
+	tri.Close()
+	vector.FillPath(want, &tri, nil, nil)
+	vector.StrokePath(want, &tri, &vector.StrokeOptions{Width: 2}, &vector.DrawPathOptions{ColorScale: redColorScale()})
+
+	for i := 0; i < 2; i++ {
+		got := ebiten.NewImage(16, 16)
+		compiled.Fill(got, nil, nil)
+		compiled.Stroke(got, &vector.StrokeOptions{Width: 2}, &vector.DrawPathOptions{ColorScale: redColorScale()})
+		for j := 0; j < 16; j++ {
+			for i := 0; i < 16; i++ {
+				if got, want := got.At(i, j), want.At(i, j); got != want {
+					t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+				}
+			}
+		}
+	}
+
+	// Changing the stroke options updates the cached stroke.
+	got := ebiten.NewImage(16, 16)
+	compiled.Stroke(got, &vector.StrokeOptions{Width: 4}, nil)
+	if got, want := got.At(8, 0), (color.RGBA{0xff, 0xff, 0xff, 0xff}); got != want {
+		t.Errorf("At(8, 0): got: %v, want: %v", got, want)
+	}
+}
+
+func redColorScale() ebiten.ColorScale {
+	var cs ebiten.ColorScale
+	cs.Scale(1, 0, 0, 1)
+	return cs
+}
+
+func TestFillPathWithGPUCurve(t *testing.T) {
+	var path vector.Path
+	path.Arc(16, 16, 12, 0, 2*math.Pi, vector.Clockwise)
+	path.Close()
+
+	dst := ebiten.NewImage(32, 32)
+	vector.FillPath(dst, &path, &vector.FillOptions{
+		Algorithm: vector.FillAlgorithmGPUCurve,
+	}, nil)
+
+	for _, tc := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{16, 16, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{16, 5, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{26, 16, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{2, 2, color.RGBA{}},
+		{29, 29, color.RGBA{}},
+		// Outside of the circle, near the curve.
+		{26, 26, color.RGBA{}},
+	} {
+		if got := dst.At(tc.x, tc.y); got != tc.want {
+			t.Errorf("At(%d, %d): got: %v, want: %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestClipStack(t *testing.T) {
+	dst := ebiten.NewImage(16, 16)
+	clip := vector.NewClipStack(dst)
+
+	var left vector.Path
+	left.MoveTo(0, 0)
+	left.LineTo(8, 0)
+	left.LineTo(8, 16)
+	left.LineTo(0, 16)
+	left.Close()
+
+	var top vector.Path
+	top.MoveTo(0, 0)
+	top.LineTo(16, 0)
+	top.LineTo(16, 8)
+	top.LineTo(0, 8)
+	top.Close()
+
+	clip.Push(&left, nil, false)
+	img := clip.Push(&top, nil, false)
+	if got, want := clip.Len(), 2; got != want {
+		t.Errorf("Len(): got: %d, want: %d", got, want)
+	}
+	img.Fill(color.White)
+	clip.Pop()
+	clip.Pop()
+	if got, want := clip.Image(), dst; got != want {
+		t.Errorf("Image(): got: %v, want: %v", got, want)
+	}
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{}
+			if i < 8 && j < 8 {
+				want = color.RGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			if got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestFillPathWithBlur(t *testing.T) {
+	for _, radius := range []float32{4, 32} {
+		radius := radius
+		t.Run(fmt.Sprintf("radius=%f", radius), func(t *testing.T) {
+			const size = 256
+			dst := ebiten.NewImage(size, size)
+
+			var path vector.Path
+			path.MoveTo(64, 64)
+			path.LineTo(192, 64)
+			path.LineTo(192, 192)
+			path.LineTo(64, 192)
+			path.Close()
+
+			vector.FillPathWithBlur(dst, &path, nil, nil, &vector.BlurOptions{
+				Radius:  radius,
+				OffsetX: 8,
+				OffsetY: 8,
+			})
+
+			alpha := func(x, y int) int {
+				_, _, _, a := dst.At(x, y).RGBA()
+				return int(a >> 8)
+			}
+			// The center is opaque.
+			if got := alpha(136, 136); got < 0xf8 {
+				t.Errorf("alpha at the center: got: %d, want: >= %d", got, 0xf8)
+			}
+			// The edge is about the half.
+			if got := alpha(136, 72); got < 0x60 || got > 0xa0 {
+				t.Errorf("alpha at the edge: got: %d, want: about %d", got, 0x80)
+			}
+			// Far from the path is transparent.
+			if got := alpha(136, 72-int(radius)*2); got > 0x08 {
+				t.Errorf("alpha far from the edge: got: %d, want: <= %d", got, 0x08)
+			}
+		})
+	}
+}
+
+func TestAntiAliasQualityHigh(t *testing.T) {
+	dst := ebiten.NewImage(16, 16)
+
+	// A triangle whose diagonal edge crosses pixels.
+	var path vector.Path
+	path.MoveTo(2, 2)
+	path.LineTo(14, 2)
+	path.LineTo(2, 14)
+	path.Close()
+	vector.FillPath(dst, &path, nil, &vector.DrawPathOptions{
+		AntiAlias:        true,
+		AntiAliasQuality: vector.AntiAliasQualityHigh,
+	})
+
+	alpha := func(x, y int) int {
+		_, _, _, a := dst.At(x, y).RGBA()
+		return int(a >> 8)
+	}
+	if got, want := alpha(4, 4), 0xff; got != want {
+		t.Errorf("alpha at (4, 4): got: %d, want: %d", got, want)
+	}
+	if got, want := alpha(12, 12), 0; got != want {
+		t.Errorf("alpha at (12, 12): got: %d, want: %d", got, want)
+	}
+	// The pixel (7, 8) is exactly halved by the diagonal edge.
+	if got := alpha(7, 8); got < 0x70 || got > 0x90 {
+		t.Errorf("alpha at (7, 8): got: %d, want: about %d", got, 0x80)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var rect0, rect1 vector.Path
+	rect0.MoveTo(2, 2)
+	rect0.LineTo(10, 2)
+	rect0.LineTo(10, 10)
+	rect0.LineTo(2, 10)
+	rect0.Close()
+	rect1.MoveTo(6, 6)
+	rect1.LineTo(14, 6)
+	rect1.LineTo(14, 14)
+	rect1.LineTo(6, 14)
+	rect1.Close()
+
+	var cs ebiten.ColorScale
+	cs.ScaleAlpha(0.5)
+
+	want := ebiten.NewImage(16, 16)
+	vector.FillPath(want, &rect0, nil, &vector.DrawPathOptions{ColorScale: redColorScale()})
+	vector.FillPath(want, &rect1, nil, &vector.DrawPathOptions{ColorScale: cs})
+	vector.StrokePath(want, &rect1, &vector.StrokeOptions{Width: 2}, &vector.DrawPathOptions{ColorScale: cs})
+
+	got := ebiten.NewImage(16, 16)
+	b := vector.NewBatch(got)
+	b.FillPath(&rect0, nil, &vector.DrawPathOptions{ColorScale: redColorScale()})
+	b.FillPath(&rect1, nil, &vector.DrawPathOptions{ColorScale: cs})
+	b.StrokePath(&rect1, &vector.StrokeOptions{Width: 2}, &vector.DrawPathOptions{ColorScale: cs})
+	b.Flush()
+
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			if got, want := got.At(i, j), want.At(i, j); got != want {
+				t.Errorf("At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}